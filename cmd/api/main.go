@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,29 +11,78 @@ import (
 	"syscall"
 	"time"
 
+	listingsapp "dongome/internal/listings/app"
+	listingsdomain "dongome/internal/listings/domain"
+	listingsinfra "dongome/internal/listings/infra"
+	transactionsapp "dongome/internal/transactions/app"
+	transactionsdomain "dongome/internal/transactions/domain"
+	transactionsinfra "dongome/internal/transactions/infra"
 	"dongome/internal/users/app"
 	"dongome/internal/users/domain"
 	"dongome/internal/users/infra"
+	"dongome/pkg/auth"
+	"dongome/pkg/cache"
+	"dongome/pkg/clock"
 	"dongome/pkg/config"
 	"dongome/pkg/db"
+	"dongome/pkg/diagnostics"
 	"dongome/pkg/events"
+	"dongome/pkg/i18n"
+	"dongome/pkg/jobs"
 	"dongome/pkg/logger"
+	"dongome/pkg/metering"
+	"dongome/pkg/momo"
+	"dongome/pkg/money"
+	"dongome/pkg/mtls"
+	"dongome/pkg/payments"
+	"dongome/pkg/rules"
+	"dongome/pkg/sla"
+	"dongome/pkg/status"
+	"dongome/pkg/storage"
+	"dongome/pkg/webhookauth"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// How often latency statistics and API usage are persisted
+const (
+	slaFlushInterval   = time.Minute
+	usageFlushInterval = time.Minute
+)
+
+// statusStaleAfter is how many check intervals may pass without a health
+// check before the status page reports a component unknown
+const statusStaleAfter = 3
+
+// subscribedEventTypes lists the events this process consumes; keep in sync with setupEventSubscriptions
+var subscribedEventTypes = []string{
+	domain.UserRegisteredEvent,
+	domain.UserEmailVerifiedEvent,
+	domain.VerificationResentEvent,
+}
+
 func main() {
+	checkMode := flag.Bool("check", false, "validate configuration and dependencies, print a report and exit")
+	checkFormat := flag.String("check-format", "text", "self-check report format (text or json)")
+	flag.Parse()
+
 	// Load configuration
 	cfg := config.LoadConfig()
 
 	// Initialize logger
-	if err := logger.Initialize(cfg.Server.Mode); err != nil {
+	if err := logger.Configure(diagnostics.LoggerConfig("dongome-api", cfg)); err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 	defer logger.Sync()
 
-	logger.Info("Starting Dongome API server")
+	if *checkMode {
+		code := diagnostics.RunSelfCheck("dongome-api", cfg, subscribedEventTypes, *checkFormat, os.Stdout)
+		logger.Sync()
+		os.Exit(code)
+	}
+
+	logger.Info("Starting Dongome API server", diagnostics.BannerFields("dongome-api", cfg)...)
 
 	// Initialize database
 	database, err := db.NewDatabase(&cfg.Database)
@@ -45,10 +95,47 @@ func main() {
 	if err := database.AutoMigrate(
 		&domain.User{},
 		&domain.SellerProfile{},
+		&domain.DataExport{},
+		&domain.UserBlock{},
+		&domain.UserPreferences{},
+		&domain.Address{},
+		&domain.SellerActivity{},
+		&domain.VerificationReminder{},
+		&domain.SellerFollow{},
+		&domain.ReferralCode{},
+		&domain.Referral{},
+		&listingsdomain.Category{},
+		&listingsdomain.CategoryTranslation{},
+		&listingsdomain.AttributeLabel{},
+		&listingsdomain.Listing{},
+		&listingsdomain.ListingImage{},
+		&listingsdomain.ListingAttribute{},
+		&listingsdomain.ListingTag{},
+		&listingsdomain.OwnershipTransfer{},
+		&listingsdomain.OwnershipRecord{},
+		&listingsdomain.ListingPromotion{},
+		&listingsdomain.ListingReport{},
+		&listingsdomain.ListingImport{},
+		&listingsdomain.ListingImportRow{},
+		&listingsdomain.PriceChange{},
+		&listingsdomain.Region{},
+		&listingsdomain.City{},
+		&listingsdomain.Area{},
+		&sla.WindowRecord{},
+		&metering.UsageRecord{},
+		&status.CheckRecord{},
+		&rules.Rule{},
 	); err != nil {
 		logger.Fatal("Failed to migrate database", zap.Error(err))
 	}
 
+	// Initialize cache
+	redisCache, err := cache.NewRedisCache(context.Background(), &cfg.Redis)
+	if err != nil {
+		logger.Fatal("Failed to connect to Redis", zap.Error(err))
+	}
+	defer redisCache.Close()
+
 	// Initialize NATS event bus
 	eventBus, err := events.NewNATSEventBus(cfg.NATS.URL)
 	if err != nil {
@@ -58,12 +145,226 @@ func main() {
 
 	// Initialize repositories
 	userRepo := infra.NewUserGORMRepository(database.DB)
+	exportRepo := infra.NewDataExportGORMRepository(database.DB)
+	blockRepo := infra.NewUserBlockGORMRepository(database.DB)
+	preferencesRepo := infra.NewUserPreferencesGORMRepository(database.DB)
+	addressRepo := infra.NewAddressGORMRepository(database.DB)
+	activityRepo := infra.NewSellerActivityGORMRepository(database.DB)
+	reminderRepo := infra.NewVerificationReminderGORMRepository(database.DB)
+	followRepo := infra.NewSellerFollowGORMRepository(database.DB)
+	referralRepo := infra.NewReferralGORMRepository(database.DB)
+	listingRepo := listingsinfra.NewListingGORMRepository(database.DB)
+	transferRepo := listingsinfra.NewOwnershipTransferGORMRepository(database.DB)
+	locationRepo := listingsinfra.NewLocationGORMRepository(database.DB)
+	questionRepo := listingsinfra.NewListingQuestionGORMRepository(database.DB)
+	categoryRepo := listingsinfra.NewCategoryGORMRepository(database.DB)
+	translationRepo := listingsinfra.NewTranslationGORMRepository(database.DB)
+	stagedImageRepo := listingsinfra.NewStagedImageGORMRepository(database.DB)
+	sellerCardRepo := listingsinfra.NewSellerCardGORMRepository(database.DB)
+	rankingPolicyRepo := listingsinfra.NewRankingPolicyGORMRepository(database.DB)
+	orderRepo := transactionsinfra.NewOrderGORMRepository(database.DB)
+	escrowRepo := transactionsinfra.NewEscrowGORMRepository(database.DB)
+	walletRepo := transactionsinfra.NewWalletGORMRepository(database.DB)
+	fraudRepo := transactionsinfra.NewFraudGORMRepository(database.DB)
+
+	// Initialize business rules, so administrators can change policies without a deploy
+	ruleEngine := rules.NewEngine(rules.NewGORMStore(database.DB))
+	ruleEngine.Register(listingsapp.MaxActiveListingsDefinition(cfg.Listings.MaxActivePerSeller), listingsinfra.NewSellerQuotaHistory(database.DB))
+	ruleEngine.Register(transactionsapp.PaymentWindowDefinition(int(cfg.Checkout.PaymentTimeout.Minutes())), transactionsinfra.NewPaymentWindowHistory(database.DB))
+	ruleEngine.Register(app.AutoSuspendDefinition(), infra.NewAutoSuspendHistory(database.DB))
+	ruleEngine.Register(transactionsapp.FraudScoreDefinition(), transactionsinfra.NewFraudScoreHistory(fraudRepo))
+	if err := ruleEngine.Reload(context.Background()); err != nil {
+		logger.Warn("Failed to load business rules, using defaults", zap.Error(err))
+	}
 
 	// Initialize services
-	userService := app.NewUserService(userRepo, eventBus)
+	userService := app.NewUserService(userRepo, eventBus, clock.System())
+	exportService := app.NewDataExportService(userRepo, exportRepo, infra.NewFileExportArchive(cfg.Exports.Dir), eventBus)
+	blockService := app.NewBlockService(userRepo, blockRepo, eventBus, ruleEngine, clock.System())
+	preferencesService := app.NewPreferencesService(userRepo, preferencesRepo, eventBus)
+	badgeService := app.NewBadgeService(activityRepo, eventBus)
+	reminderService := app.NewVerificationReminderService(userRepo, reminderRepo, eventBus, cfg.Reminders.MaxPerUser, clock.System())
+	followService := app.NewFollowService(userRepo, followRepo, blockRepo, preferencesRepo, eventBus)
+	referralService := app.NewReferralService(referralRepo, eventBus)
+	presenceService := app.NewPresenceService(redisCache, preferencesRepo)
+	savedSearchService := listingsapp.NewSavedSearchService(listingsinfra.NewSavedSearchGORMRepository(database.DB), listingRepo, preferencesService, eventBus)
+	questionService := listingsapp.NewQuestionService(questionRepo, listingRepo, listingsinfra.NewContactDetailsModerator(), preferencesService, eventBus)
+	reportService := listingsapp.NewReportService(listingsinfra.NewListingReportGORMRepository(database.DB), listingRepo, eventBus, cfg.Listings.ReportThreshold, clock.System())
+	locationService := listingsapp.NewLocationService(locationRepo, listingRepo)
+	addressService := app.NewAddressService(addressRepo, locationService)
+	sellerProfileService := app.NewSellerProfileService(userRepo, locationService, eventBus, clock.System())
+	transferService := listingsapp.NewOwnershipTransferService(listingRepo, transferRepo, userService, eventBus)
+	imageStore, err := newImageStore(cfg.Uploads)
+	if err != nil {
+		logger.Fatal("Failed to set up photo storage", zap.Error(err))
+	}
+	imageService := listingsapp.NewImageService(stagedImageRepo, listingRepo, imageStore, eventBus)
+	translationService := listingsapp.NewTranslationService(translationRepo, categoryRepo, redisCache)
+	categoryService := listingsapp.NewCategoryService(categoryRepo, translationService, redisCache)
+	suggestionService := listingsapp.NewSuggestionService(categoryRepo, listingsinfra.NewTemplateSuggester())
+	publicationService := listingsapp.NewPublicationService(listingRepo, categoryRepo, locationService, listingsinfra.NewContactDetailsModerator(), listingsinfra.NewTemplateSuggester(), listingsapp.NewRulePublicationPolicy(sellerCardRepo, ruleEngine))
+	counterRepo := listingsinfra.NewListingCounterGORMRepository(database.DB)
+	promotionRanker := listingsapp.NewPromotionRanker(cfg.Listings.PromotedPerPage, clock.System())
+	listingService := listingsapp.NewListingService(listingRepo, questionRepo, eventBus, badgeService, sellerCardRepo, counterRepo, followService, publicationService, listingsinfra.NewPriceHistoryGORMRepository(database.DB), promotionRanker, clock.System())
+	scheduleService := listingsapp.NewListingScheduleService(listingRepo, eventBus, listingsdomain.SchedulingLimits{
+		MaxScheduled: cfg.Schedule.MaxScheduled,
+		MaxLeadTime:  cfg.Schedule.MaxLeadTime,
+	}, clock.System())
+	renewalService := listingsapp.NewListingRenewalService(listingRepo, eventBus, publicationService, listingsdomain.RenewalLimits{
+		Window:      cfg.Listings.RenewalWindow,
+		MaxRenewals: cfg.Listings.MaxRenewals,
+	}, clock.System())
+	promotionPackages := make([]listingsdomain.PromotionPackage, 0, len(cfg.Listings.PromotionPackages))
+	for _, pkg := range cfg.Listings.PromotionPackages {
+		promotionPackages = append(promotionPackages, listingsdomain.PromotionPackage{ID: pkg.ID, Name: pkg.Name, Days: pkg.Days, Price: money.FromMajor(pkg.Price, pkg.Currency)})
+	}
+	momoClient := momo.NewClient(momo.Config{
+		BaseURL:                     cfg.MoMo.BaseURL,
+		SubscriptionKey:             cfg.MoMo.SubscriptionKey,
+		APIUser:                     cfg.MoMo.APIKey,
+		APIKey:                      cfg.MoMo.APISecret,
+		TargetEnvironment:           cfg.MoMo.TargetEnvironment,
+		Timeout:                     cfg.MoMo.Timeout,
+		DisbursementSubscriptionKey: cfg.MoMo.DisbursementSubscriptionKey,
+		DisbursementAPIUser:         cfg.MoMo.DisbursementAPIKey,
+		DisbursementAPIKey:          cfg.MoMo.DisbursementAPISecret,
+	})
+	promotionService := listingsapp.NewPromotionService(listingRepo, listingsinfra.NewListingPromotionGORMRepository(database.DB), listingsinfra.NewMoMoPaymentGateway(momoClient, cfg.MoMo.PromotionCallbackURL), eventBus, promotionPackages, clock.System())
+	rankingService := listingsapp.NewRankingService(sellerCardRepo, rankingPolicyRepo, app.NewSellerEngagementSource(activityRepo))
+	paymentProviders := newPaymentProviders(cfg, momoClient)
+	couponService := transactionsapp.NewCouponService(transactionsinfra.NewCouponGORMRepository(database.DB), clock.System())
+	fraudService := transactionsapp.NewFraudService(fraudRepo, orderRepo, userService, addressService, ruleEngine, cfg.Fraud.HoldScore, cfg.Fraud.ReviewWindow, clock.System())
+	orderService := transactionsapp.NewOrderService(orderRepo, listingService, preferencesService, paymentProviders, eventBus, cfg.Checkout.PaymentTimeout, cfg.Checkout.ResumeURL, ruleEngine, couponService, fraudService, clock.System())
+	// Wallet top-ups are collected through the Collection API, but settled by
+	// the worker rather than by callbacks, and withdrawals sent like payouts
+	var topUps transactionsapp.PaymentGateway
+	if cfg.MoMo.SubscriptionKey != "" && cfg.MoMo.APIKey != "" {
+		topUps = transactionsinfra.NewMoMoPaymentGateway(momoClient, "")
+	}
+	var withdrawals transactionsapp.PayoutGateway
+	if cfg.MoMo.DisbursementSubscriptionKey != "" && cfg.MoMo.DisbursementAPIKey != "" {
+		withdrawals = transactionsinfra.NewMoMoPaymentGateway(momoClient, cfg.MoMo.CallbackURL)
+	}
+	walletService := transactionsapp.NewWalletService(walletRepo, orderRepo, orderService, topUps, withdrawals, clock.System())
+	refundRepo := transactionsinfra.NewRefundGORMRepository(database.DB)
+	refundService := transactionsapp.NewRefundService(refundRepo, orderRepo, escrowRepo, paymentProviders, walletService, preferencesService, eventBus, clock.System())
+	escrowService := transactionsapp.NewEscrowService(escrowRepo, orderRepo, listingService, eventBus, cfg.Escrow.HoldPeriod, cfg.Escrow.ConfirmWindow, clock.System())
+	// Payout numbers are verified through the Disbursement API, so sellers
+	// cannot register them until its user is set
+	var payouts transactionsapp.PayoutGateway
+	if cfg.MoMo.DisbursementSubscriptionKey != "" && cfg.MoMo.DisbursementAPIKey != "" {
+		payouts = transactionsinfra.NewMoMoPaymentGateway(momoClient, cfg.MoMo.CallbackURL)
+	}
+	payoutWeekday, _ := transactionsdomain.ParseWeekday(cfg.Payouts.Weekday) // validated with the config
+	payoutService := transactionsapp.NewPayoutService(transactionsinfra.NewPayoutGORMRepository(database.DB), escrowRepo, orderRepo, refundRepo, payouts, preferencesService, eventBus, transactionsdomain.PayoutPolicy{
+		DefaultSchedule: transactionsdomain.PayoutSchedule(cfg.Payouts.DefaultSchedule),
+		Weekday:         payoutWeekday,
+	}, clock.System())
+	claimService := transactionsapp.NewClaimService(transactionsinfra.NewClaimGORMRepository(database.DB), orderRepo, escrowRepo, refundRepo, preferencesService, eventBus, clock.System())
+	orderHistoryService := transactionsapp.NewOrderHistoryService(orderRepo, escrowRepo, refundRepo)
+	sagaOrchestrator := transactionsapp.NewSagaOrchestrator(transactionsinfra.NewSagaGORMRepository(database.DB), orderRepo, listingService, couponService, eventBus, cfg.Checkout.SagaGrace, clock.System())
+
+	// Serve listing search from Elasticsearch or OpenSearch when a cluster is configured
+	// Exchange rates show prices in the currency each buyer prefers
+	var rateSource listingsapp.ExchangeRateSource
+	if cfg.Currency.RatesURL != "" {
+		rateSource = listingsinfra.NewHTTPExchangeRateSource(cfg.Currency.RatesURL, cfg.Currency.Timeout)
+	}
+	currencyService := listingsapp.NewCurrencyService(rateSource, preferencesService, redisCache, cfg.Currency.RatesTTL, clock.System())
+
+	var searchService *listingsapp.SearchService
+	if cfg.Search.URL != "" {
+		listingIndex := listingsinfra.NewElasticsearchListingIndex(cfg.Search.URL, cfg.Search.Index, cfg.Search.Username, cfg.Search.Password, cfg.Search.Timeout)
+		if err := listingIndex.EnsureIndex(context.Background()); err != nil {
+			logger.Warn("Failed to create the search index", zap.Error(err))
+		}
+		searchService = listingsapp.NewSearchService(listingIndex, listingRepo, sellerCardRepo, counterRepo, promotionRanker)
+	}
+	// Reindexing can only be submitted when there is an index to refresh
+	var listingIndexer listingsapp.ListingIndexer
+	if searchService != nil {
+		listingIndexer = searchService
+	}
+	bulkOperationService := listingsapp.NewBulkOperationService(listingsinfra.NewBulkOperationGORMRepository(database.DB), listingRepo, categoryRepo, eventBus, listingIndexer)
+	importService := listingsapp.NewListingImportService(listingsinfra.NewListingImportGORMRepository(database.DB), listingRepo, categoryRepo, locationService, listingsinfra.NewTemplateSuggester(), eventBus, clock.System())
+
+	// Initialize authentication
+	tokens := auth.NewTokenManager(cfg.JWT.Secret, time.Duration(cfg.JWT.Expiration)*time.Hour, clock.System())
 
 	// Initialize handlers
-	userHandler := infra.NewUserHandler(userService)
+	userHandler := infra.NewUserHandler(userService, presenceService, tokens)
+	adminUserHandler := infra.NewAdminUserHandler(userService)
+	exportHandler := infra.NewDataExportHandler(exportService)
+	blockHandler := infra.NewBlockHandler(blockService)
+	preferencesHandler := infra.NewPreferencesHandler(preferencesService)
+	addressHandler := infra.NewAddressHandler(addressService)
+	sellerProfileHandler := infra.NewSellerProfileHandler(sellerProfileService)
+	internalUserHandler := infra.NewInternalUserHandler(userService)
+	reminderHandler := infra.NewVerificationReminderHandler(reminderService)
+	followHandler := infra.NewFollowHandler(followService)
+	referralHandler := infra.NewReferralHandler(referralService)
+	transferHandler := listingsinfra.NewOwnershipTransferHandler(transferService)
+	listingHandler := listingsinfra.NewListingHandler(listingService)
+	importHandler := listingsinfra.NewListingImportHandler(importService)
+	statsRepo := listingsinfra.NewListingStatsGORMRepository(database.DB)
+	analyticsHandler := listingsinfra.NewSellerAnalyticsHandler(listingsapp.NewAnalyticsService(statsRepo, listingRepo, clock.System()))
+	searchHandler := listingsinfra.NewListingSearchHandler(listingService, translationService, searchService, currencyService)
+	similarHandler := listingsinfra.NewSimilarListingHandler(listingsapp.NewSimilarListingService(listingRepo, redisCache), translationService, currencyService)
+	trendingHandler := listingsinfra.NewTrendingListingHandler(listingsapp.NewTrendingListingService(statsRepo, listingRepo, redisCache, clock.System()), translationService, currencyService)
+	currencyHandler := listingsinfra.NewCurrencyHandler(currencyService)
+	sitemapHandler := listingsinfra.NewSitemapHandler(listingsapp.NewSitemapService(listingRepo, imageStore, redisCache, cfg.Sitemap.SiteURL, cfg.Sitemap.FeedSize, clock.System()))
+	tagService := listingsapp.NewTagService(listingsinfra.NewTagGORMRepository(database.DB), listingRepo, eventBus, redisCache, clock.System())
+	tagHandler := listingsinfra.NewTagHandler(tagService)
+	adminTagHandler := listingsinfra.NewAdminTagHandler(tagService)
+	locationHandler := listingsinfra.NewLocationHandler(locationService)
+	categoryHandler := listingsinfra.NewCategoryHandler(categoryService)
+	adminCategoryHandler := listingsinfra.NewAdminCategoryHandler(categoryService)
+	adminTranslationHandler := listingsinfra.NewAdminTranslationHandler(translationService)
+	adminLocationHandler := listingsinfra.NewAdminLocationHandler(locationService)
+	questionHandler := listingsinfra.NewQuestionHandler(questionService)
+	offerHandler := listingsinfra.NewOfferHandler(listingsapp.NewOfferService(listingsinfra.NewOfferGORMRepository(database.DB), listingRepo, preferencesService, eventBus, clock.System()))
+	savedSearchHandler := listingsinfra.NewSavedSearchHandler(savedSearchService)
+	shareLinkHandler := listingsinfra.NewShareLinkHandler(listingsapp.NewShareLinkService(listingsinfra.NewShareLinkGORMRepository(database.DB), listingRepo, cfg.ShareLinks.BaseURL, cfg.Sitemap.SiteURL, clock.System()))
+	listingTemplateHandler := listingsinfra.NewListingTemplateHandler(listingsapp.NewListingTemplateService(listingsinfra.NewListingTemplateGORMRepository(database.DB), listingRepo, eventBus, clock.System()))
+	adminQuestionHandler := listingsinfra.NewAdminQuestionHandler(questionService)
+	reportHandler := listingsinfra.NewReportHandler(reportService)
+	adminReportHandler := listingsinfra.NewAdminReportHandler(reportService)
+	scheduleHandler := listingsinfra.NewListingScheduleHandler(scheduleService)
+	renewalHandler := listingsinfra.NewListingRenewalHandler(renewalService)
+	suggestionHandler := listingsinfra.NewSuggestionHandler(suggestionService)
+	publicationHandler := listingsinfra.NewPublicationHandler(publicationService)
+	imageHandler := listingsinfra.NewImageHandler(imageService)
+	adminRankingHandler := listingsinfra.NewAdminRankingHandler(rankingService)
+	adminBulkOperationHandler := listingsinfra.NewAdminBulkOperationHandler(bulkOperationService)
+	orderHandler := transactionsinfra.NewOrderHandler(orderService)
+	orderHistoryHandler := transactionsinfra.NewOrderHistoryHandler(orderHistoryService)
+	escrowHandler := transactionsinfra.NewEscrowHandler(escrowService)
+	refundHandler := transactionsinfra.NewRefundHandler(refundService)
+	payoutHandler := transactionsinfra.NewPayoutHandler(payoutService)
+	walletHandler := transactionsinfra.NewWalletHandler(walletService)
+	claimHandler := transactionsinfra.NewClaimHandler(claimService)
+	adminOrderHandler := transactionsinfra.NewAdminOrderHandler(orderService)
+	adminSagaHandler := transactionsinfra.NewAdminSagaHandler(sagaOrchestrator)
+	adminCouponHandler := transactionsinfra.NewAdminCouponHandler(couponService)
+	adminFraudHandler := transactionsinfra.NewAdminFraudHandler(fraudService, orderService)
+	adminClaimHandler := transactionsinfra.NewAdminClaimHandler(claimService)
+	promotionHandler := listingsinfra.NewPromotionHandler(promotionService)
+	webhookVerifier := webhookauth.NewVerifier(redisCache, cfg.Webhooks.MaxClockSkew)
+	paymentCallbackHandler := transactionsinfra.NewPaymentCallbackHandler(orderService, claimService, paymentProviders, webhookVerifier, webhookauth.MoMo(cfg.MoMo.CallbackSecret))
+
+	// Initialize latency budget instrumentation
+	slaRecorder := sla.NewRecorder(diagnostics.Version, sla.DefaultBudgets)
+	slaStore := sla.NewGORMStore(database.DB)
+	slaReportHandler := sla.NewReportHandler(slaStore, sla.DefaultBudgets)
+
+	// Initialize API usage metering
+	usageMeter := metering.NewMeter()
+	usageStore := metering.NewGORMStore(database.DB)
+	usageHandler := metering.NewUsageHandler(usageStore)
+
+	// Public status page data, from the health checks the worker records
+	statusHandler := status.NewHandler(status.NewGORMStore(database.DB), statusStaleAfter*cfg.Status.CheckInterval)
+	ruleHandler := rules.NewHandler(ruleEngine)
 
 	// Setup Gin router
 	if cfg.Server.Mode == "production" {
@@ -71,22 +372,122 @@ func main() {
 	}
 
 	router := gin.New()
-	router.Use(gin.Logger())
+	router.Use(logger.Middleware())
 	router.Use(gin.Recovery())
+	router.Use(sla.Middleware(slaRecorder))
+	router.Use(metering.Middleware(usageMeter))
+	router.Use(i18n.Middleware())
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"status":    "healthy",
 			"timestamp": time.Now().UTC(),
-			"version":   "1.0.0",
+			"version":   diagnostics.Version,
 		})
 	})
 
+	statusHandler.RegisterRoutes(router)
+	sitemapHandler.RegisterRoutes(router)
+	shareLinkHandler.RegisterRedirectRoutes(router)
+
+	// Uploaded photos, for the file image store
+	if cfg.Uploads.S3.Bucket == "" {
+		router.Static("/media", cfg.Uploads.Dir)
+	}
+
 	// API routes
-	v1 := router.Group("/api/v1")
+	v1 := router.Group("/api/v1", auth.IdentifyUser(tokens))
 	{
 		userHandler.RegisterRoutes(v1)
+		sellerProfileHandler.RegisterRoutes(v1)
+		searchHandler.RegisterRoutes(v1)
+		similarHandler.RegisterRoutes(v1)
+		trendingHandler.RegisterRoutes(v1)
+		shareLinkHandler.RegisterRoutes(v1)
+		currencyHandler.RegisterRoutes(v1)
+		tagHandler.RegisterRoutes(v1)
+		locationHandler.RegisterRoutes(v1)
+		categoryHandler.RegisterRoutes(v1)
+		questionHandler.RegisterRoutes(v1)
+		if cfg.MoMo.CallbackSecret != "" {
+			paymentCallbackHandler.RegisterMoMoRoutes(v1)
+			promotionHandler.RegisterCallbackRoutes(v1, webhookVerifier, webhookauth.MoMo(cfg.MoMo.CallbackSecret))
+		} else {
+			logger.Warn("momo.callback_secret is not set; Mobile Money payment callbacks are disabled")
+		}
+		// Paystack signs its webhooks with the secret key
+		if cfg.Payments.Paystack.SecretKey != "" {
+			paymentCallbackHandler.RegisterPaystackRoutes(v1)
+		} else {
+			logger.Warn("payments.paystack.secret_key is not set; Paystack webhooks are disabled")
+		}
+		if cfg.Payments.Flutterwave.SecretKey != "" && cfg.Payments.Flutterwave.WebhookHash != "" {
+			paymentCallbackHandler.RegisterFlutterwaveRoutes(v1)
+		} else {
+			logger.Warn("payments.flutterwave.secret_key or webhook_hash is not set; Flutterwave webhooks are disabled")
+		}
+
+		// Authenticated routes
+		authenticated := v1.Group("", auth.RequireAuth(tokens), auth.ApplyUserLocale(userService), auth.RequireActiveSession(userService), auth.TrackActivity(presenceService))
+		userHandler.RegisterAuthenticatedRoutes(authenticated)
+		exportHandler.RegisterRoutes(authenticated)
+		sellerProfileHandler.RegisterAuthenticatedRoutes(authenticated)
+		blockHandler.RegisterRoutes(authenticated)
+		preferencesHandler.RegisterRoutes(authenticated)
+		addressHandler.RegisterRoutes(authenticated)
+		transferHandler.RegisterRoutes(authenticated)
+		followHandler.RegisterRoutes(authenticated)
+		referralHandler.RegisterRoutes(authenticated)
+		listingHandler.RegisterRoutes(authenticated)
+		importHandler.RegisterRoutes(authenticated)
+		analyticsHandler.RegisterRoutes(authenticated)
+		savedSearchHandler.RegisterRoutes(authenticated)
+		listingTemplateHandler.RegisterRoutes(authenticated)
+		shareLinkHandler.RegisterAuthenticatedRoutes(authenticated)
+		searchHandler.RegisterFeedRoutes(authenticated)
+		orderHandler.RegisterRoutes(authenticated)
+		orderHistoryHandler.RegisterRoutes(authenticated)
+		escrowHandler.RegisterRoutes(authenticated)
+		refundHandler.RegisterRoutes(authenticated)
+		payoutHandler.RegisterRoutes(authenticated)
+		walletHandler.RegisterRoutes(authenticated)
+		claimHandler.RegisterRoutes(authenticated)
+		questionHandler.RegisterAuthenticatedRoutes(authenticated)
+		tagHandler.RegisterAuthenticatedRoutes(authenticated)
+		offerHandler.RegisterRoutes(authenticated)
+		reportHandler.RegisterRoutes(authenticated)
+		scheduleHandler.RegisterRoutes(authenticated)
+		renewalHandler.RegisterRoutes(authenticated)
+		if cfg.MoMo.SubscriptionKey != "" && cfg.MoMo.APIKey != "" {
+			promotionHandler.RegisterRoutes(authenticated)
+		} else {
+			logger.Warn("momo.subscription_key or momo.api_key is not set; listing promotions are not sold")
+		}
+		suggestionHandler.RegisterRoutes(authenticated)
+		publicationHandler.RegisterRoutes(authenticated)
+		imageHandler.RegisterRoutes(authenticated)
+
+		// Admin routes
+		admin := v1.Group("/admin", auth.RequireAuth(tokens), auth.ApplyUserLocale(userService), auth.RequireActiveSession(userService), auth.TrackActivity(presenceService), auth.RequireRole(string(domain.UserRoleAdmin)))
+		adminUserHandler.RegisterRoutes(admin)
+		reminderHandler.RegisterRoutes(admin)
+		adminLocationHandler.RegisterRoutes(admin)
+		adminCategoryHandler.RegisterRoutes(admin)
+		adminTagHandler.RegisterRoutes(admin)
+		adminTranslationHandler.RegisterRoutes(admin)
+		adminOrderHandler.RegisterRoutes(admin)
+		adminSagaHandler.RegisterRoutes(admin)
+		adminCouponHandler.RegisterRoutes(admin)
+		adminFraudHandler.RegisterRoutes(admin)
+		adminClaimHandler.RegisterRoutes(admin)
+		adminQuestionHandler.RegisterRoutes(admin)
+		adminReportHandler.RegisterRoutes(admin)
+		adminRankingHandler.RegisterRoutes(admin)
+		adminBulkOperationHandler.RegisterRoutes(admin)
+		slaReportHandler.RegisterRoutes(admin)
+		usageHandler.RegisterRoutes(admin)
+		ruleHandler.RegisterRoutes(admin)
 	}
 
 	// Setup server
@@ -98,17 +499,44 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	// Start server in a goroutine
+	// Setup the internal listener for service-to-service calls
+	internalServer, err := mtls.NewServer(&cfg.Internal, mtls.NewMetrics(), func(r *gin.RouterGroup) {
+		internalUserHandler.RegisterRoutes(r)
+	})
+	if err != nil {
+		logger.Fatal("Failed to load internal TLS certificates", zap.Error(err))
+	}
+
+	// Start servers in goroutines
 	go func() {
 		logger.Info(fmt.Sprintf("Server starting on port %s", cfg.Server.Port))
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("Failed to start server", zap.Error(err))
 		}
 	}()
+	go func() {
+		logger.Info(fmt.Sprintf("Internal server starting on port %s", cfg.Internal.Port),
+			zap.Bool("mtls", cfg.Internal.TLS.Enabled))
+		if err := internalServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("Failed to start internal server", zap.Error(err))
+		}
+	}()
 
 	// Setup event subscriptions
 	setupEventSubscriptions(eventBus)
 
+	// Persist latency statistics and API usage periodically so reports outlive deploys
+	scheduler := jobs.NewScheduler()
+	scheduler.Every("sla-flush", slaFlushInterval, func(ctx context.Context) error {
+		return slaStore.SaveWindow(slaRecorder.Drain())
+	})
+	scheduler.Every("api-usage-flush", usageFlushInterval, func(ctx context.Context) error {
+		return usageStore.Add(usageMeter.Drain())
+	})
+	// Pick up business rules changed through other instances
+	scheduler.Every("rules-reload", cfg.Rules.ReloadInterval, ruleEngine.Reload)
+	scheduler.Start(context.Background())
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -123,10 +551,81 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
+	if err := internalServer.Shutdown(ctx); err != nil {
+		logger.Error("Internal server forced to shutdown", zap.Error(err))
+	}
+
+	scheduler.Stop()
+	if err := slaStore.SaveWindow(slaRecorder.Drain()); err != nil {
+		logger.Error("Failed to persist latency statistics", zap.Error(err))
+	}
+	if err := usageStore.Add(usageMeter.Drain()); err != nil {
+		logger.Error("Failed to persist API usage", zap.Error(err))
+	}
 
 	logger.Info("Server shutdown complete")
 }
 
+// newPaymentProviders registers the payment providers orders are paid and
+// refunded through. MoMo takes Mobile Money payments once the Collection API
+// user is set and refunds them once the Disbursement API user is; Paystack
+// and Flutterwave are registered once their secret keys are set, taking the
+// methods payments.methods gives them.
+func newPaymentProviders(cfg *config.Config, momoClient *momo.Client) *payments.Registry {
+	methodsOf := func(provider string) []payments.Method {
+		var methods []payments.Method
+		for method, name := range cfg.Payments.Methods {
+			if name == provider {
+				methods = append(methods, payments.Method(method))
+			}
+		}
+		return methods
+	}
+
+	registry := payments.NewRegistry()
+	collection := cfg.MoMo.SubscriptionKey != "" && cfg.MoMo.APIKey != ""
+	disbursement := cfg.MoMo.DisbursementSubscriptionKey != "" && cfg.MoMo.DisbursementAPIKey != ""
+	if collection {
+		registry.Use(payments.NewMoMo(momoClient, cfg.MoMo.CallbackURL), methodsOf(payments.ProviderMoMo)...)
+	} else if disbursement {
+		registry.Use(payments.NewMoMo(momoClient, cfg.MoMo.CallbackURL))
+	}
+	if cfg.Payments.Paystack.SecretKey != "" {
+		registry.Use(payments.NewPaystack(payments.PaystackConfig{
+			BaseURL:     cfg.Payments.Paystack.BaseURL,
+			SecretKey:   cfg.Payments.Paystack.SecretKey,
+			CallbackURL: cfg.Payments.ReturnURL,
+			Timeout:     cfg.Payments.Paystack.Timeout,
+		}), methodsOf(payments.ProviderPaystack)...)
+	}
+	if cfg.Payments.Flutterwave.SecretKey != "" {
+		registry.Use(payments.NewFlutterwave(payments.FlutterwaveConfig{
+			BaseURL:     cfg.Payments.Flutterwave.BaseURL,
+			SecretKey:   cfg.Payments.Flutterwave.SecretKey,
+			WebhookHash: cfg.Payments.Flutterwave.WebhookHash,
+			RedirectURL: cfg.Payments.ReturnURL,
+			Timeout:     cfg.Payments.Flutterwave.Timeout,
+		}), methodsOf(payments.ProviderFlutterwave)...)
+	}
+	return registry
+}
+
+// newImageStore stores photos in the configured bucket, or on disk when none is set
+func newImageStore(cfg config.UploadsConfig) (listingsapp.ImageStore, error) {
+	if cfg.S3.Bucket == "" {
+		return listingsinfra.NewFileImageStore(cfg.Dir, cfg.BaseURL), nil
+	}
+	return storage.NewS3Store(storage.S3Config{
+		Endpoint:  cfg.S3.Endpoint,
+		Region:    cfg.S3.Region,
+		Bucket:    cfg.S3.Bucket,
+		AccessKey: cfg.S3.AccessKey,
+		SecretKey: cfg.S3.SecretKey,
+		PathStyle: cfg.S3.PathStyle,
+		PublicURL: cfg.S3.PublicURL,
+	}, cfg.S3.Timeout)
+}
+
 // setupEventSubscriptions sets up NATS event subscriptions for cross-bounded context communication
 func setupEventSubscriptions(eventBus events.EventBus) {
 	// Subscribe to UserRegistered events for notifications
@@ -141,14 +640,21 @@ func setupEventSubscriptions(eventBus events.EventBus) {
 		logger.Error("Failed to subscribe to UserEmailVerified events", zap.Error(err))
 	}
 
+	// Subscribe to VerificationResent events to send the new verification email
+	err = eventBus.Subscribe(domain.VerificationResentEvent, handleVerificationResent)
+	if err != nil {
+		logger.Error("Failed to subscribe to VerificationResent events", zap.Error(err))
+	}
+
 	logger.Info("Event subscriptions setup complete")
 }
 
 // Event handlers for demonstration of cross-bounded context communication
 func handleUserRegistered(ctx context.Context, event *events.Event) error {
 	logger.Info("Handling UserRegistered event",
-		zap.String("event_id", event.ID),
-		zap.String("user_id", event.AggregateID))
+		logger.EventID(event.ID),
+		logger.TraceID(ctx),
+		logger.UserID(event.AggregateID))
 
 	var userData domain.UserRegistered
 	if err := events.ParseEventData(event, &userData); err != nil {
@@ -173,8 +679,9 @@ func handleUserRegistered(ctx context.Context, event *events.Event) error {
 
 func handleUserEmailVerified(ctx context.Context, event *events.Event) error {
 	logger.Info("Handling UserEmailVerified event",
-		zap.String("event_id", event.ID),
-		zap.String("user_id", event.AggregateID))
+		logger.EventID(event.ID),
+		logger.TraceID(ctx),
+		logger.UserID(event.AggregateID))
 
 	var userData domain.UserEmailVerified
 	if err := events.ParseEventData(event, &userData); err != nil {
@@ -192,3 +699,24 @@ func handleUserEmailVerified(ctx context.Context, event *events.Event) error {
 
 	return nil
 }
+
+func handleVerificationResent(ctx context.Context, event *events.Event) error {
+	logger.Info("Handling VerificationResent event",
+		logger.EventID(event.ID),
+		logger.TraceID(ctx),
+		logger.UserID(event.AggregateID))
+
+	var userData domain.VerificationResent
+	if err := events.ParseEventData(event, &userData); err != nil {
+		return err
+	}
+
+	// In a real application, this would send the verification email with a
+	// link containing the token and its expiry
+
+	logger.Info("VerificationResent event processed successfully",
+		zap.String("user_email", userData.Email),
+		zap.Time("expires_at", userData.ExpiresAt))
+
+	return nil
+}
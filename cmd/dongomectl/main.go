@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"dongome/pkg/anonymize"
+	"dongome/pkg/backup"
+	"dongome/pkg/config"
+	"dongome/pkg/db"
+	"dongome/pkg/diagnostics"
+	"dongome/pkg/logger"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// imageCheckTimeout bounds each request made when checking restored images
+const imageCheckTimeout = 10 * time.Second
+
+const usage = `Usage: dongomectl <command> [flags]
+
+Commands:
+  backup                         take a consistent backup of the database
+  list                           list complete backups, newest first
+  verify  -id ID                 restore a backup into the scratch database and check it
+  restore -id ID [-force] [-check-images]
+                                 restore a backup into the configured database
+  prune   [-keep N]              delete old backups, keeping the newest N and the newest verified
+  anonymize -id ID [-force] [-password PASSWORD]
+                                 clone a backup into the configured staging database and
+                                 pseudonymize its personal data
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.LoadConfig()
+	if err := logger.Configure(diagnostics.LoggerConfig("dongomectl", cfg)); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	database, err := db.NewDatabase(&cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer database.Close()
+
+	service := backup.NewService(database.DB, &cfg.Database, &cfg.Backup, backup.ExecRunner{}, diagnostics.Version)
+	ctx := context.Background()
+
+	command, args := os.Args[1], os.Args[2:]
+	var result interface{}
+	switch command {
+	case "backup":
+		result, err = service.Backup(ctx)
+	case "list":
+		result, err = service.List()
+	case "verify":
+		flags := flag.NewFlagSet("verify", flag.ExitOnError)
+		id := flags.String("id", "", "backup to verify")
+		flags.Parse(args)
+		result, err = service.Verify(ctx, *id)
+	case "restore":
+		flags := flag.NewFlagSet("restore", flag.ExitOnError)
+		id := flags.String("id", "", "backup to restore")
+		force := flags.Bool("force", false, "drop existing data in the target database first")
+		checkImages := flags.Bool("check-images", false, "check that every referenced image is still in object storage")
+		flags.Parse(args)
+		result, err = restore(ctx, service, *id, *force, *checkImages)
+	case "prune":
+		flags := flag.NewFlagSet("prune", flag.ExitOnError)
+		keep := flags.Int("keep", cfg.Backup.Retention, "number of backups to keep")
+		flags.Parse(args)
+		result, err = service.Prune(*keep)
+	case "anonymize":
+		flags := flag.NewFlagSet("anonymize", flag.ExitOnError)
+		id := flags.String("id", "", "backup to clone")
+		force := flags.Bool("force", false, "drop existing data in the staging database first")
+		password := flags.String("password", "", "password set on every account; empty disables password logins")
+		flags.Parse(args)
+		result, err = cloneAnonymized(ctx, cfg, database, service, *id, *force, *password)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		logger.Error("Command failed", zap.String("command", command), zap.Error(err))
+		logger.Sync()
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		os.Exit(1)
+	}
+}
+
+// restoreResult reports a restore and any images missing from object storage
+type restoreResult struct {
+	Backup        *backup.Manifest `json:"backup"`
+	MissingImages []string         `json:"missing_images,omitempty"`
+}
+
+func restore(ctx context.Context, service *backup.Service, id string, force, checkImages bool) (*restoreResult, error) {
+	manifest, err := service.Restore(ctx, id, force)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &restoreResult{Backup: manifest}
+	if checkImages {
+		result.MissingImages = backup.MissingImages(ctx, &http.Client{Timeout: imageCheckTimeout}, manifest)
+		if len(result.MissingImages) > 0 {
+			logger.Warn("Restored data refers to images missing from object storage",
+				zap.Int("missing", len(result.MissingImages)))
+		}
+	}
+	return result, nil
+}
+
+// cloneAnonymized restores a backup into the configured database and
+// pseudonymizes its personal data, to give staging realistic but safe data
+func cloneAnonymized(ctx context.Context, cfg *config.Config, database *db.Database, service *backup.Service, id string, force bool, password string) (*anonymize.Summary, error) {
+	if cfg.Server.Mode == "production" {
+		return nil, fmt.Errorf("refusing to anonymize a production database")
+	}
+	if cfg.Anonymize.Key == "" {
+		return nil, fmt.Errorf("anonymize.key is required")
+	}
+
+	var passwordHash string
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		passwordHash = string(hash)
+	}
+
+	if _, err := service.Restore(ctx, id, force); err != nil {
+		return nil, err
+	}
+
+	anonymizer := anonymize.NewAnonymizer(database.DB, anonymize.NewPseudonymizer(cfg.Anonymize.Key), anonymize.DefaultRules, passwordHash)
+	summary, err := anonymizer.Run(ctx)
+	if err != nil {
+		// Never leave unanonymized production data behind
+		if wipeErr := service.Wipe(ctx); wipeErr != nil {
+			logger.Error("Failed to wipe partially anonymized database", zap.Error(wipeErr))
+		}
+		return nil, err
+	}
+	return summary, nil
+}
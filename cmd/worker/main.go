@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -10,23 +13,155 @@ import (
 
 	"go.uber.org/zap"
 
+	listingsapp "dongome/internal/listings/app"
+	listingsdomain "dongome/internal/listings/domain"
+	listingsinfra "dongome/internal/listings/infra"
+	transactionsapp "dongome/internal/transactions/app"
+	transactionsdomain "dongome/internal/transactions/domain"
+	transactionsinfra "dongome/internal/transactions/infra"
+	"dongome/internal/users/app"
 	"dongome/internal/users/domain"
+	"dongome/internal/users/infra"
+	"dongome/pkg/backup"
+	"dongome/pkg/cache"
+	"dongome/pkg/clock"
 	"dongome/pkg/config"
+	"dongome/pkg/db"
+	"dongome/pkg/diagnostics"
+	"dongome/pkg/errors"
 	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/jobs"
 	"dongome/pkg/logger"
+	"dongome/pkg/momo"
+	"dongome/pkg/payments"
+	"dongome/pkg/status"
+	"dongome/pkg/storage"
 )
 
+// erasureBatchSize limits how many deleted accounts are anonymized per run
+const erasureBatchSize = 100
+
+// phoneNormalizationBatchSize is how many users are read per batch when normalizing phone numbers
+const phoneNormalizationBatchSize = 500
+
+// badgeRefreshBatchSize is how many sellers are read per batch when refreshing badges
+const badgeRefreshBatchSize = 200
+
+// reminderBatchSize limits how many verification reminders are sent per run
+const reminderBatchSize = 200
+
+// abandonBatchSize limits how many unpaid orders are abandoned per run
+const abandonBatchSize = 200
+
+// publishBatchSize limits how many scheduled listings are published per run
+const publishBatchSize = 200
+
+// rankingBatchSize limits how many sellers are ranked per batch
+const rankingBatchSize = 200
+
+// imageCleanupBatchSize limits how many unattached photos are deleted per run
+const imageCleanupBatchSize = 500
+
+// reindexBatchSize limits how many listings are read per batch when rebuilding the search index
+const reindexBatchSize = 500
+
+// sagaBatchSize limits how many timed-out checkout sagas and failed compensations are handled per run
+const sagaBatchSize = 200
+
+// escrowBatchSize limits how many escrowed payments are released per run
+const escrowBatchSize = 200
+
+// payoutBatchSize limits how many sellers are paid per run
+const payoutBatchSize = 100
+
+// paymentCheckBatchSize limits how many order payments, refunds, payouts and
+// wallet transfers are looked up per run
+const paymentCheckBatchSize = 100
+
+// promotionBatchSize limits how many lapsed listing promotions are ended per run
+const promotionBatchSize = 200
+
+// bulkOperationBatchSize limits how many listings of each bulk operation are processed per run
+const bulkOperationBatchSize = 100
+
+// importBatchSize limits how many rows of each listing import are processed per run
+const importBatchSize = 100
+
+// expiryBatchSize limits how many lapsed listings are expired per run
+const expiryBatchSize = 200
+
+// offerExpiryBatchSize limits how many lapsed offers are closed per run
+const offerExpiryBatchSize = 200
+
+// draftCleanupBatchSize limits how many stale drafts are deleted per run
+const draftCleanupBatchSize = 200
+
+// edgeWarmTimeout bounds each CDN asset request made while warming caches
+const edgeWarmTimeout = 10 * time.Second
+
+// subscribedEventTypes lists the events this process consumes; keep in sync with setupEventSubscriptions
+var subscribedEventTypes = []string{
+	domain.UserRegisteredEvent,
+	domain.UserEmailVerifiedEvent,
+	domain.UserUpgradedToSellerEvent,
+	domain.UserDeletedEvent,
+	domain.UserSuspendedEvent,
+	domain.UserActivatedEvent,
+	domain.UserRestoredEvent,
+	domain.DataExportRequestedEvent,
+	domain.UserMergedEvent,
+	domain.SellerVerifiedEvent,
+	domain.SellerVerificationRejectedEvent,
+	domain.SaleCompletedEvent,
+	domain.ReviewSubmittedEvent,
+	domain.SellerRespondedEvent,
+	domain.ConversationStartedEvent,
+	domain.SellerRatingChangedEvent,
+	listingsdomain.ListingPromotedEvent,
+	listingsdomain.ListingPromotionPaidEvent,
+	listingsdomain.ListingTrendingUpdatedEvent,
+	listingsdomain.ListingActivatedEvent,
+	listingsdomain.ListingChangedEvent,
+	listingsdomain.ListingViewedEvent,
+	listingsdomain.ListingFavoritedEvent,
+	listingsdomain.ListingUnfavoritedEvent,
+	listingsdomain.ListingOwnerChangedEvent,
+	listingsdomain.ListingTransferCompletedEvent,
+	listingsdomain.ListingImageUploadedEvent,
+	transactionsdomain.OrderCreatedEvent,
+	transactionsdomain.OrderAbandonedEvent,
+	transactionsdomain.OrderResumedEvent,
+	transactionsdomain.OrderReleasedEvent,
+	transactionsdomain.OrderDeclinedEvent,
+	transactionsdomain.OrderPaidEvent,
+	transactionsdomain.OrderCompletedEvent,
+	transactionsdomain.EscrowRefundedEvent,
+}
+
 func main() {
+	checkMode := flag.Bool("check", false, "validate configuration and dependencies, print a report and exit")
+	checkFormat := flag.String("check-format", "text", "self-check report format (text or json)")
+	normalizePhones := flag.Bool("normalize-phones", false, "rewrite stored phone numbers in E.164 form, print a report and exit")
+	dryRun := flag.Bool("dry-run", false, "with -normalize-phones, report the changes without writing them")
+	flag.Parse()
+
 	// Load configuration
 	cfg := config.LoadConfig()
 
 	// Initialize logger
-	if err := logger.Initialize(cfg.Server.Mode); err != nil {
+	if err := logger.Configure(diagnostics.LoggerConfig("dongome-worker", cfg)); err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 	defer logger.Sync()
 
-	logger.Info("Starting Dongome Worker")
+	if *checkMode {
+		code := diagnostics.RunSelfCheck("dongome-worker", cfg, subscribedEventTypes, *checkFormat, os.Stdout)
+		logger.Sync()
+		os.Exit(code)
+	}
+
+	logger.Info("Starting Dongome Worker", diagnostics.BannerFields("dongome-worker", cfg)...)
 
 	// Initialize NATS event bus
 	eventBus, err := events.NewNATSEventBus(cfg.NATS.URL)
@@ -35,8 +170,404 @@ func main() {
 	}
 	defer eventBus.Close()
 
+	// Initialize database
+	database, err := db.NewDatabase(&cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer database.Close()
+
+	// Initialize cache
+	listingCache, err := cache.NewRedisCache(context.Background(), &cfg.Redis)
+	if err != nil {
+		logger.Fatal("Failed to connect to Redis", zap.Error(err))
+	}
+	defer listingCache.Close()
+
+	// Initialize repositories
+	userRepo := infra.NewUserGORMRepository(database.DB)
+	preferencesRepo := infra.NewUserPreferencesGORMRepository(database.DB)
+	listingRepo := listingsinfra.NewListingGORMRepository(database.DB)
+
+	// Initialize services
+	userService := app.NewUserService(userRepo, eventBus, clock.System())
+	activityRepo := infra.NewSellerActivityGORMRepository(database.DB)
+	badgeService := app.NewBadgeService(activityRepo, eventBus)
+	reminderService := app.NewVerificationReminderService(userRepo, infra.NewVerificationReminderGORMRepository(database.DB), eventBus, cfg.Reminders.MaxPerUser, clock.System())
+	followService := app.NewFollowService(userRepo, infra.NewSellerFollowGORMRepository(database.DB), infra.NewUserBlockGORMRepository(database.DB), preferencesRepo, eventBus)
+	referralService := app.NewReferralService(infra.NewReferralGORMRepository(database.DB), eventBus)
+	listingService := listingsapp.NewListingService(listingRepo, nil, eventBus, nil, nil, nil, nil, nil, nil, nil, clock.System())
+	preferencesService := app.NewPreferencesService(userRepo, preferencesRepo, eventBus)
+	savedSearchService := listingsapp.NewSavedSearchService(listingsinfra.NewSavedSearchGORMRepository(database.DB), listingRepo, preferencesService, eventBus)
+	orderRepo := transactionsinfra.NewOrderGORMRepository(database.DB)
+	momoClient := momo.NewClient(momo.Config{
+		BaseURL:                     cfg.MoMo.BaseURL,
+		SubscriptionKey:             cfg.MoMo.SubscriptionKey,
+		APIUser:                     cfg.MoMo.APIKey,
+		APIKey:                      cfg.MoMo.APISecret,
+		TargetEnvironment:           cfg.MoMo.TargetEnvironment,
+		Timeout:                     cfg.MoMo.Timeout,
+		DisbursementSubscriptionKey: cfg.MoMo.DisbursementSubscriptionKey,
+		DisbursementAPIUser:         cfg.MoMo.DisbursementAPIKey,
+		DisbursementAPIKey:          cfg.MoMo.DisbursementAPISecret,
+	})
+	// Late payments and refunds are looked up with the provider they went
+	// through
+	paymentProviders := newPaymentProviders(cfg, momoClient)
+	// Abandoned and cancelled orders release their coupons
+	couponService := transactionsapp.NewCouponService(transactionsinfra.NewCouponGORMRepository(database.DB), clock.System())
+	orderService := transactionsapp.NewOrderService(
+		orderRepo,
+		listingService,
+		preferencesService,
+		paymentProviders,
+		eventBus,
+		cfg.Checkout.PaymentTimeout,
+		cfg.Checkout.ResumeURL,
+		nil,
+		couponService,
+		nil,
+		clock.System(),
+	)
+	escrowRepo := transactionsinfra.NewEscrowGORMRepository(database.DB)
+	escrowService := transactionsapp.NewEscrowService(escrowRepo, orderRepo, listingService, eventBus, cfg.Escrow.HoldPeriod, cfg.Escrow.ConfirmWindow, clock.System())
+	refundRepo := transactionsinfra.NewRefundGORMRepository(database.DB)
+	// Wallet top-ups are looked up through the Collection API, and
+	// withdrawals like payouts
+	var topUps transactionsapp.PaymentGateway
+	if cfg.MoMo.SubscriptionKey != "" && cfg.MoMo.APIKey != "" {
+		topUps = transactionsinfra.NewMoMoPaymentGateway(momoClient, "")
+	}
+	var withdrawals transactionsapp.PayoutGateway
+	if cfg.MoMo.DisbursementSubscriptionKey != "" && cfg.MoMo.DisbursementAPIKey != "" {
+		withdrawals = transactionsinfra.NewMoMoPaymentGateway(momoClient, cfg.MoMo.CallbackURL)
+	}
+	walletRepo := transactionsinfra.NewWalletGORMRepository(database.DB)
+	walletService := transactionsapp.NewWalletService(walletRepo, orderRepo, orderService, topUps, withdrawals, clock.System())
+	accountMergeService := transactionsapp.NewAccountMergeService(transactionsinfra.NewAccountMergeGORMRepository(database.DB), walletRepo, clock.System())
+	refundService := transactionsapp.NewRefundService(refundRepo, orderRepo, escrowRepo, paymentProviders, walletService, preferencesService, eventBus, clock.System())
+	// Payouts are sent through the Disbursement API like withdrawals
+	var payouts transactionsapp.PayoutGateway
+	if withdrawals != nil {
+		payouts = transactionsinfra.NewMoMoPaymentGateway(momoClient, cfg.MoMo.CallbackURL)
+	}
+	payoutWeekday, _ := transactionsdomain.ParseWeekday(cfg.Payouts.Weekday) // validated with the config
+	payoutService := transactionsapp.NewPayoutService(transactionsinfra.NewPayoutGORMRepository(database.DB), escrowRepo, orderRepo, refundRepo, payouts, preferencesService, eventBus, transactionsdomain.PayoutPolicy{
+		DefaultSchedule: transactionsdomain.PayoutSchedule(cfg.Payouts.DefaultSchedule),
+		Weekday:         payoutWeekday,
+	}, clock.System())
+	sagaOrchestrator := transactionsapp.NewSagaOrchestrator(transactionsinfra.NewSagaGORMRepository(database.DB), orderRepo, listingService, couponService, eventBus, cfg.Checkout.SagaGrace, clock.System())
+	scheduleService := listingsapp.NewListingScheduleService(listingRepo, eventBus, listingsdomain.SchedulingLimits{
+		MaxScheduled: cfg.Schedule.MaxScheduled,
+		MaxLeadTime:  cfg.Schedule.MaxLeadTime,
+	}, clock.System())
+	imageStore, err := newImageStore(cfg.Uploads)
+	if err != nil {
+		logger.Fatal("Failed to set up photo storage", zap.Error(err))
+	}
+	imageService := listingsapp.NewImageService(
+		listingsinfra.NewStagedImageGORMRepository(database.DB),
+		listingRepo,
+		imageStore,
+		eventBus,
+	)
+	promotionService := listingsapp.NewPromotionService(listingRepo, listingsinfra.NewListingPromotionGORMRepository(database.DB), nil, eventBus, nil, clock.System())
+	sellerCardRepo := listingsinfra.NewSellerCardGORMRepository(database.DB)
+	sellerCardService := listingsapp.NewSellerCardService(sellerCardRepo)
+	counterRepo := listingsinfra.NewListingCounterGORMRepository(database.DB)
+	// Buffer view counts in Redis when they are flushed to the database in batches
+	var viewBuffer cache.Counters
+	if cfg.Listings.ViewFlushInterval > 0 {
+		viewBuffer = listingCache
+	}
+	counterService := listingsapp.NewCounterService(counterRepo, listingCache, viewBuffer, cfg.Listings.ViewDedupWindow, clock.System())
+	offerService := listingsapp.NewOfferService(listingsinfra.NewOfferGORMRepository(database.DB), listingRepo, preferencesService, eventBus, clock.System())
+	statsRepo := listingsinfra.NewListingStatsGORMRepository(database.DB)
+	analyticsService := listingsapp.NewAnalyticsService(statsRepo, listingRepo, clock.System())
+	trendingService := listingsapp.NewTrendingListingService(statsRepo, listingRepo, listingCache, clock.System())
+	rankingService := listingsapp.NewRankingService(
+		sellerCardRepo,
+		listingsinfra.NewRankingPolicyGORMRepository(database.DB),
+		app.NewSellerEngagementSource(activityRepo),
+	)
+	listingCacheService := listingsapp.NewListingCacheService(listingRepo, listingCache, listingsinfra.NewHTTPEdgeWarmer(edgeWarmTimeout))
+	similarListingService := listingsapp.NewSimilarListingService(listingRepo, listingCache)
+	exportService := app.NewDataExportService(
+		userRepo,
+		infra.NewDataExportGORMRepository(database.DB),
+		infra.NewFileExportArchive(cfg.Exports.Dir),
+		eventBus,
+		listingsapp.NewListingExportSource(listingRepo),
+	)
+
+	// Keep the search index in sync when listing search is served from a cluster
+	var searchService *listingsapp.SearchService
+	if cfg.Search.URL != "" {
+		listingIndex := listingsinfra.NewElasticsearchListingIndex(cfg.Search.URL, cfg.Search.Index, cfg.Search.Username, cfg.Search.Password, cfg.Search.Timeout)
+		if err := listingIndex.EnsureIndex(context.Background()); err != nil {
+			logger.Warn("Failed to create the search index", zap.Error(err))
+		}
+		searchService = listingsapp.NewSearchService(listingIndex, listingRepo, sellerCardRepo, counterRepo, nil)
+	}
+	var listingIndexer listingsapp.ListingIndexer
+	if searchService != nil {
+		listingIndexer = searchService
+	}
+	categoryRepo := listingsinfra.NewCategoryGORMRepository(database.DB)
+	bulkOperationService := listingsapp.NewBulkOperationService(listingsinfra.NewBulkOperationGORMRepository(database.DB), listingRepo, categoryRepo, eventBus, listingIndexer)
+	importService := listingsapp.NewListingImportService(
+		listingsinfra.NewListingImportGORMRepository(database.DB),
+		listingRepo,
+		categoryRepo,
+		listingsapp.NewLocationService(listingsinfra.NewLocationGORMRepository(database.DB), listingRepo),
+		listingsinfra.NewTemplateSuggester(),
+		eventBus,
+		clock.System(),
+	)
+
+	// Make the resized copies of listing photos when the tools to do it are installed
+	var imageProcessingService *listingsapp.ImageProcessingService
+	if cfg.Uploads.ProcessImages {
+		processor := listingsinfra.NewWebPImageProcessor(cfg.Uploads.WebPQuality)
+		if err := processor.CheckTools(); err != nil {
+			logger.Warn("Photo processing is disabled", zap.Error(err))
+		} else {
+			imageProcessingService = listingsapp.NewImageProcessingService(listingRepo, imageStore, processor)
+		}
+	}
+
+	if *normalizePhones {
+		os.Exit(runPhoneNormalization(userService, *dryRun))
+	}
+
 	// Setup event subscriptions
-	setupEventSubscriptions(eventBus)
+	setupEventSubscriptions(eventBus, listingService, listingCacheService, similarListingService, sellerCardService, exportService, badgeService, reminderService, followService, referralService, orderService, sagaOrchestrator, escrowService, refundService, accountMergeService, searchService, imageProcessingService, promotionService, counterService, analyticsService, savedSearchService)
+
+	// Setup scheduled jobs
+	scheduler := jobs.NewScheduler()
+	setupScheduledJobs(scheduler, userService, badgeService)
+	if cfg.Reminders.Interval > 0 {
+		scheduler.Every("verification-reminders", cfg.Reminders.Interval, func(ctx context.Context) error {
+			count, err := reminderService.SendDueReminders(ctx, reminderBatchSize)
+			if count > 0 {
+				logger.Info("Sent verification reminders", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Checkout.AbandonInterval > 0 {
+		scheduler.Every("abandoned-checkouts", cfg.Checkout.AbandonInterval, func(ctx context.Context) error {
+			count, err := orderService.AbandonExpiredOrders(ctx, abandonBatchSize)
+			if count > 0 {
+				logger.Info("Abandoned unpaid orders", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Checkout.PaymentCheckInterval > 0 {
+		scheduler.Every("payment-checks", cfg.Checkout.PaymentCheckInterval, func(ctx context.Context) error {
+			count, err := orderService.CheckPendingPayments(ctx, paymentCheckBatchSize)
+			if count > 0 {
+				logger.Info("Settled order payments with late webhooks", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Checkout.PaymentCheckInterval > 0 {
+		scheduler.Every("refund-checks", cfg.Checkout.PaymentCheckInterval, func(ctx context.Context) error {
+			count, err := refundService.CheckPendingRefunds(ctx, paymentCheckBatchSize)
+			if count > 0 {
+				logger.Info("Settled order refunds", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Checkout.PaymentCheckInterval > 0 && (topUps != nil || withdrawals != nil) {
+		scheduler.Every("wallet-checks", cfg.Checkout.PaymentCheckInterval, func(ctx context.Context) error {
+			count, err := walletService.CheckPendingTransfers(ctx, paymentCheckBatchSize)
+			if count > 0 {
+				logger.Info("Settled wallet top-ups and withdrawals", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Escrow.ReleaseInterval > 0 {
+		scheduler.Every("escrow-releases", cfg.Escrow.ReleaseInterval, func(ctx context.Context) error {
+			count, err := escrowService.ReleaseDueEscrows(ctx, escrowBatchSize)
+			if count > 0 {
+				logger.Info("Released escrowed payments to sellers", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Payouts.Interval > 0 && payouts != nil {
+		scheduler.Every("payouts", cfg.Payouts.Interval, func(ctx context.Context) error {
+			count, err := payoutService.RunPayouts(ctx, payoutBatchSize)
+			if count > 0 {
+				logger.Info("Paid out released funds to sellers", zap.Int("count", count))
+			}
+			return err
+		})
+		scheduler.Every("payout-checks", cfg.Payouts.Interval, func(ctx context.Context) error {
+			count, err := payoutService.CheckPendingPayouts(ctx, paymentCheckBatchSize)
+			if count > 0 {
+				logger.Info("Settled seller payouts", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Checkout.SagaInterval > 0 {
+		scheduler.Every("checkout-sagas", cfg.Checkout.SagaInterval, func(ctx context.Context) error {
+			timedOut, err := sagaOrchestrator.TimeOutSagas(ctx, sagaBatchSize)
+			if timedOut > 0 {
+				logger.Info("Compensated timed-out checkout sagas", zap.Int("count", timedOut))
+			}
+			if err != nil {
+				return err
+			}
+			retried, err := sagaOrchestrator.RetryCompensations(ctx, sagaBatchSize)
+			if retried > 0 {
+				logger.Info("Retried checkout saga compensations", zap.Int("count", retried))
+			}
+			return err
+		})
+	}
+	if cfg.Schedule.Interval > 0 {
+		scheduler.Every("scheduled-listings", cfg.Schedule.Interval, func(ctx context.Context) error {
+			count, err := scheduleService.PublishDueListings(ctx, publishBatchSize)
+			if count > 0 {
+				logger.Info("Published scheduled listings", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Listings.RankingInterval > 0 {
+		scheduler.Every("seller-ranking", cfg.Listings.RankingInterval, func(ctx context.Context) error {
+			summary, err := rankingService.RecalculatePenalties(ctx, rankingBatchSize)
+			if summary != nil && summary.Changed > 0 {
+				logger.Info("Updated seller ranking penalties",
+					zap.Int("changed", summary.Changed),
+					zap.Int("penalized", summary.Penalized))
+			}
+			return err
+		})
+	}
+	if cfg.Listings.PromotionInterval > 0 {
+		scheduler.Every("listing-promotions", cfg.Listings.PromotionInterval, func(ctx context.Context) error {
+			count, err := promotionService.EndLapsedPromotions(ctx, promotionBatchSize)
+			if count > 0 {
+				logger.Info("Ended lapsed listing promotions", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Listings.ExpiryInterval > 0 {
+		scheduler.Every("listing-expiry", cfg.Listings.ExpiryInterval, func(ctx context.Context) error {
+			count, err := listingService.ExpireListings(ctx, expiryBatchSize)
+			if count > 0 {
+				logger.Info("Expired lapsed listings", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Listings.OfferExpiryInterval > 0 {
+		scheduler.Every("offer-expiry", cfg.Listings.OfferExpiryInterval, func(ctx context.Context) error {
+			count, err := offerService.ExpireOffers(ctx, offerExpiryBatchSize)
+			if count > 0 {
+				logger.Info("Closed lapsed offers", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Listings.DraftCleanupInterval > 0 {
+		scheduler.Every("stale-drafts", cfg.Listings.DraftCleanupInterval, func(ctx context.Context) error {
+			count, err := listingService.DeleteStaleDrafts(ctx, cfg.Listings.DraftRetention, draftCleanupBatchSize)
+			if count > 0 {
+				logger.Info("Deleted stale drafts", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Listings.ViewFlushInterval > 0 {
+		scheduler.Every("listing-views", cfg.Listings.ViewFlushInterval, func(ctx context.Context) error {
+			flushed, err := counterService.FlushViews(ctx)
+			if len(flushed) > 0 {
+				logger.Info("Flushed buffered listing views", zap.Int("listings", len(flushed)))
+			}
+			// Views written to the counters count toward the sellers' analytics
+			// even when the rest of the flush failed
+			if analyticsErr := analyticsService.RecordViews(ctx, flushed); analyticsErr != nil && err == nil {
+				err = analyticsErr
+			}
+			return err
+		})
+	}
+	if cfg.Listings.TrendingInterval > 0 {
+		scheduler.Every("trending-listings", cfg.Listings.TrendingInterval, func(ctx context.Context) error {
+			_, err := trendingService.RefreshTrending(ctx)
+			return err
+		})
+	}
+	if cfg.Listings.ImportInterval > 0 {
+		scheduler.Every("listing-imports", cfg.Listings.ImportInterval, func(ctx context.Context) error {
+			count, err := importService.RunImports(ctx, importBatchSize)
+			if count > 0 {
+				logger.Info("Processed rows of listing imports", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Listings.BulkOperationInterval > 0 {
+		scheduler.Every("bulk-operations", cfg.Listings.BulkOperationInterval, func(ctx context.Context) error {
+			count, err := bulkOperationService.RunOperations(ctx, bulkOperationBatchSize)
+			if count > 0 {
+				logger.Info("Processed listings of bulk operations", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if searchService != nil && cfg.Search.ReindexInterval > 0 {
+		scheduler.Every("search-reindex", cfg.Search.ReindexInterval, func(ctx context.Context) error {
+			count, err := searchService.Reindex(ctx, reindexBatchSize)
+			logger.Info("Rebuilt the search index", zap.Int("listings", count))
+			return err
+		})
+	}
+	if cfg.Currency.RatesURL != "" && cfg.Currency.RefreshInterval > 0 {
+		currencyService := listingsapp.NewCurrencyService(listingsinfra.NewHTTPExchangeRateSource(cfg.Currency.RatesURL, cfg.Currency.Timeout), nil, listingCache, cfg.Currency.RatesTTL, clock.System())
+		scheduler.Every("exchange-rates", cfg.Currency.RefreshInterval, func(ctx context.Context) error {
+			_, err := currencyService.RefreshRates(ctx)
+			return err
+		})
+	}
+	if cfg.Sitemap.SiteURL != "" && cfg.Sitemap.Interval > 0 {
+		sitemapService := listingsapp.NewSitemapService(listingRepo, imageStore, listingCache, cfg.Sitemap.SiteURL, cfg.Sitemap.FeedSize, clock.System())
+		scheduler.Every("sitemap", cfg.Sitemap.Interval, func(ctx context.Context) error {
+			version, err := sitemapService.Generate(ctx)
+			if version != nil {
+				logger.Info("Generated the sitemap and listing feeds", zap.Int("listings", version.Listings))
+			}
+			return err
+		})
+	}
+	if cfg.Uploads.CleanupInterval > 0 {
+		scheduler.Every("staged-image-cleanup", cfg.Uploads.CleanupInterval, func(ctx context.Context) error {
+			count, err := imageService.CleanupExpiredImages(ctx, imageCleanupBatchSize)
+			if count > 0 {
+				logger.Info("Deleted unattached photos", zap.Int("count", count))
+			}
+			return err
+		})
+	}
+	if cfg.Status.CheckInterval > 0 {
+		statusMonitor := newStatusMonitor(status.NewGORMStore(database.DB), cfg)
+		scheduler.Every("status-checks", cfg.Status.CheckInterval, statusMonitor.Run)
+	}
+	if cfg.Backup.Interval > 0 {
+		backupService := backup.NewService(database.DB, &cfg.Database, &cfg.Backup, backup.ExecRunner{}, diagnostics.Version)
+		scheduler.Every("database-backup", cfg.Backup.Interval, runScheduledBackup(backupService, cfg.Backup))
+	}
+	scheduler.Start(context.Background())
 
 	logger.Info("Worker is ready and listening for events")
 
@@ -46,57 +577,413 @@ func main() {
 	<-quit
 
 	logger.Info("Worker shutting down...")
+	scheduler.Stop()
 	logger.Info("Worker shutdown complete")
 }
 
+// runPhoneNormalization normalizes stored phone numbers, prints the summary
+// as JSON and returns the process exit code
+// newStatusMonitor checks the components shown on the status page: the API,
+// the Mobile Money API, the search cluster when search is served from one,
+// and event processing
+func newStatusMonitor(store status.Store, cfg *config.Config) *status.Monitor {
+	client := &http.Client{Timeout: cfg.Status.Timeout}
+	monitor := status.NewMonitor(store, cfg.Status.Retention)
+	monitor.Add(status.ComponentAPI, status.HTTPCheck(client, cfg.Status.APIURL, cfg.Status.SlowThreshold))
+	// The client resolves the API of the target environment when no URL is set
+	momoURL := momo.NewClient(momo.Config{BaseURL: cfg.MoMo.BaseURL, TargetEnvironment: cfg.MoMo.TargetEnvironment}).BaseURL()
+	monitor.Add(status.ComponentPayments, status.ReachabilityCheck(client, momoURL, cfg.Status.SlowThreshold))
+	if cfg.Search.URL != "" {
+		monitor.Add(status.ComponentSearch, status.ClusterHealthCheck(client, cfg.Search.URL, cfg.Search.Username, cfg.Search.Password))
+	}
+	monitor.Add(status.ComponentEvents, status.EventLagCheck(cfg.NATS.URL, cfg.Status.LagThreshold))
+	return monitor
+}
+
+// newImageStore stores photos in the configured bucket, or on disk when none is set
+// newPaymentProviders registers the payment providers orders are paid and
+// refunded through. MoMo takes Mobile Money payments once the Collection API
+// user is set and refunds them once the Disbursement API user is; Paystack
+// and Flutterwave are registered once their secret keys are set, taking the
+// methods payments.methods gives them.
+func newPaymentProviders(cfg *config.Config, momoClient *momo.Client) *payments.Registry {
+	methodsOf := func(provider string) []payments.Method {
+		var methods []payments.Method
+		for method, name := range cfg.Payments.Methods {
+			if name == provider {
+				methods = append(methods, payments.Method(method))
+			}
+		}
+		return methods
+	}
+
+	registry := payments.NewRegistry()
+	collection := cfg.MoMo.SubscriptionKey != "" && cfg.MoMo.APIKey != ""
+	disbursement := cfg.MoMo.DisbursementSubscriptionKey != "" && cfg.MoMo.DisbursementAPIKey != ""
+	if collection {
+		registry.Use(payments.NewMoMo(momoClient, cfg.MoMo.CallbackURL), methodsOf(payments.ProviderMoMo)...)
+	} else if disbursement {
+		registry.Use(payments.NewMoMo(momoClient, cfg.MoMo.CallbackURL))
+	}
+	if cfg.Payments.Paystack.SecretKey != "" {
+		registry.Use(payments.NewPaystack(payments.PaystackConfig{
+			BaseURL:     cfg.Payments.Paystack.BaseURL,
+			SecretKey:   cfg.Payments.Paystack.SecretKey,
+			CallbackURL: cfg.Payments.ReturnURL,
+			Timeout:     cfg.Payments.Paystack.Timeout,
+		}), methodsOf(payments.ProviderPaystack)...)
+	}
+	if cfg.Payments.Flutterwave.SecretKey != "" {
+		registry.Use(payments.NewFlutterwave(payments.FlutterwaveConfig{
+			BaseURL:     cfg.Payments.Flutterwave.BaseURL,
+			SecretKey:   cfg.Payments.Flutterwave.SecretKey,
+			WebhookHash: cfg.Payments.Flutterwave.WebhookHash,
+			RedirectURL: cfg.Payments.ReturnURL,
+			Timeout:     cfg.Payments.Flutterwave.Timeout,
+		}), methodsOf(payments.ProviderFlutterwave)...)
+	}
+	return registry
+}
+
+func newImageStore(cfg config.UploadsConfig) (listingsapp.ImageStore, error) {
+	if cfg.S3.Bucket == "" {
+		return listingsinfra.NewFileImageStore(cfg.Dir, cfg.BaseURL), nil
+	}
+	return storage.NewS3Store(storage.S3Config{
+		Endpoint:  cfg.S3.Endpoint,
+		Region:    cfg.S3.Region,
+		Bucket:    cfg.S3.Bucket,
+		AccessKey: cfg.S3.AccessKey,
+		SecretKey: cfg.S3.SecretKey,
+		PathStyle: cfg.S3.PathStyle,
+		PublicURL: cfg.S3.PublicURL,
+	}, cfg.S3.Timeout)
+}
+
+func runPhoneNormalization(userService *app.UserService, dryRun bool) int {
+	summary, err := userService.NormalizePhoneNumbers(context.Background(), phoneNormalizationBatchSize, dryRun)
+	if err != nil {
+		logger.Error("Phone number normalization failed", zap.Error(err))
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(summary); err != nil {
+		return 1
+	}
+
+	logger.Info("Phone number normalization complete",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("scanned", summary.Scanned),
+		zap.Int("normalized", summary.Normalized),
+		zap.Int("invalid", len(summary.Invalid)),
+		zap.Int("conflicts", len(summary.Conflicts)))
+	return 0
+}
+
+// setupScheduledJobs registers periodic background jobs
+func setupScheduledJobs(scheduler *jobs.Scheduler, userService *app.UserService, badgeService *app.BadgeService) {
+	// Anonymize deleted accounts once their erasure grace period has passed
+	scheduler.Every("user-erasure", time.Hour, func(ctx context.Context) error {
+		count, err := userService.AnonymizeDeletedUsers(ctx, erasureBatchSize)
+		if count > 0 {
+			logger.Info("Anonymized deleted users", zap.Int("count", count))
+		}
+		return err
+	})
+
+	// Re-evaluate seller badges so fast responder badges lapse with old activity
+	scheduler.Every("seller-badges", 24*time.Hour, func(ctx context.Context) error {
+		count, err := badgeService.RefreshAll(ctx, badgeRefreshBatchSize)
+		logger.Info("Refreshed seller badges", zap.Int("sellers", count))
+		return err
+	})
+}
+
+// runScheduledBackup takes a backup, test-restores it into the scratch
+// database when one is configured, and applies the retention policy
+func runScheduledBackup(backupService *backup.Service, cfg config.BackupConfig) jobs.JobFunc {
+	return func(ctx context.Context) error {
+		manifest, err := backupService.Backup(ctx)
+		if err != nil {
+			return err
+		}
+
+		if cfg.VerifyDatabase != "" {
+			if _, err := backupService.Verify(ctx, manifest.ID); err != nil {
+				logger.Error("Backup verification failed", zap.String("backup_id", manifest.ID), zap.Error(err))
+				return err
+			}
+		}
+
+		deleted, err := backupService.Prune(cfg.Retention)
+		if len(deleted) > 0 {
+			logger.Info("Pruned old backups", zap.Strings("backup_ids", deleted))
+		}
+		return err
+	}
+}
+
 // setupEventSubscriptions sets up NATS event subscriptions for background processing
-func setupEventSubscriptions(eventBus events.EventBus) {
+func setupEventSubscriptions(eventBus events.EventBus, listingService *listingsapp.ListingService, listingCacheService *listingsapp.ListingCacheService, similarListingService *listingsapp.SimilarListingService, sellerCardService *listingsapp.SellerCardService, exportService *app.DataExportService, badgeService *app.BadgeService, reminderService *app.VerificationReminderService, followService *app.FollowService, referralService *app.ReferralService, orderService *transactionsapp.OrderService, sagaOrchestrator *transactionsapp.SagaOrchestrator, escrowService *transactionsapp.EscrowService, refundService *transactionsapp.RefundService, accountMergeService *transactionsapp.AccountMergeService, searchService *listingsapp.SearchService, imageProcessingService *listingsapp.ImageProcessingService, promotionService *listingsapp.PromotionService, counterService *listingsapp.CounterService, analyticsService *listingsapp.AnalyticsService, savedSearchService *listingsapp.SavedSearchService) {
 	// Subscribe to UserRegistered events for background processing
-	err := eventBus.Subscribe(domain.UserRegisteredEvent, handleUserRegisteredBackground)
+	err := eventBus.Subscribe(domain.UserRegisteredEvent, handleUserRegisteredBackground(reminderService, referralService))
 	if err != nil {
 		logger.Error("Failed to subscribe to UserRegistered events", zap.Error(err))
 	}
 
+	// Subscribe to UserEmailVerified events to stop verification reminders
+	err = eventBus.Subscribe(domain.UserEmailVerifiedEvent, handleUserEmailVerified(reminderService))
+	if err != nil {
+		logger.Error("Failed to subscribe to UserEmailVerified events", zap.Error(err))
+	}
+
 	// Subscribe to UserUpgradedToSeller events
 	err = eventBus.Subscribe(domain.UserUpgradedToSellerEvent, handleUserUpgradedToSeller)
 	if err != nil {
 		logger.Error("Failed to subscribe to UserUpgradedToSeller events", zap.Error(err))
 	}
 
+	// Subscribe to UserDeleted events to cascade deactivation of listings
+	err = eventBus.Subscribe(domain.UserDeletedEvent, handleUserDeleted(listingService))
+	if err != nil {
+		logger.Error("Failed to subscribe to UserDeleted events", zap.Error(err))
+	}
+
+	// Subscribe to UserRestored events to put restored sellers' listings back live
+	err = eventBus.Subscribe(domain.UserRestoredEvent, handleUserRestored(listingService))
+	if err != nil {
+		logger.Error("Failed to subscribe to UserRestored events", zap.Error(err))
+	}
+
+	// Subscribe to UserSuspended events to take suspended sellers' listings down
+	err = eventBus.Subscribe(domain.UserSuspendedEvent, handleUserSuspended(listingService))
+	if err != nil {
+		logger.Error("Failed to subscribe to UserSuspended events", zap.Error(err))
+	}
+
+	// Subscribe to UserActivated events to put listings held by a suspension back live
+	err = eventBus.Subscribe(domain.UserActivatedEvent, handleUserActivated(listingService))
+	if err != nil {
+		logger.Error("Failed to subscribe to UserActivated events", zap.Error(err))
+	}
+
+	// Subscribe to DataExportRequested events to assemble export archives
+	err = eventBus.Subscribe(domain.DataExportRequestedEvent, handleDataExportRequested(exportService))
+	if err != nil {
+		logger.Error("Failed to subscribe to DataExportRequested events", zap.Error(err))
+	}
+
+	// Subscribe to UserMerged events to move listings, orders and wallet balances to the primary account
+	err = eventBus.Subscribe(domain.UserMergedEvent, handleUserMerged(listingService, accountMergeService))
+	if err != nil {
+		logger.Error("Failed to subscribe to UserMerged events", zap.Error(err))
+	}
+
+	// Subscribe to SellerVerified events to award the verified badge, mark the
+	// seller card verified and put listings held by a rejection back live
+	err = eventBus.Subscribe(domain.SellerVerifiedEvent, handleSellerVerified(badgeService, sellerCardService, listingService))
+	if err != nil {
+		logger.Error("Failed to subscribe to SellerVerified events", zap.Error(err))
+	}
+
+	// Subscribe to SellerVerificationRejected events to take rejected sellers' listings down
+	err = eventBus.Subscribe(domain.SellerVerificationRejectedEvent, handleSellerVerificationRejected(listingService))
+	if err != nil {
+		logger.Error("Failed to subscribe to SellerVerificationRejected events", zap.Error(err))
+	}
+
+	// Subscribe to SaleCompleted, ReviewSubmitted and SellerResponded events to update seller badges
+	err = eventBus.Subscribe(domain.SaleCompletedEvent, handleSaleCompleted(badgeService))
+	if err != nil {
+		logger.Error("Failed to subscribe to SaleCompleted events", zap.Error(err))
+	}
+
+	err = eventBus.Subscribe(domain.ReviewSubmittedEvent, handleReviewSubmitted(badgeService))
+	if err != nil {
+		logger.Error("Failed to subscribe to ReviewSubmitted events", zap.Error(err))
+	}
+
+	err = eventBus.Subscribe(domain.SellerRespondedEvent, handleSellerResponded(badgeService))
+	if err != nil {
+		logger.Error("Failed to subscribe to SellerResponded events", zap.Error(err))
+	}
+
+	// Subscribe to ConversationStarted events to track seller response rates for ranking
+	err = eventBus.Subscribe(domain.ConversationStartedEvent, handleConversationStarted(badgeService, analyticsService))
+	if err != nil {
+		logger.Error("Failed to subscribe to ConversationStarted events", zap.Error(err))
+	}
+
+	// Subscribe to SellerRatingChanged events to keep the seller cards shown on listings current
+	err = eventBus.Subscribe(domain.SellerRatingChangedEvent, handleSellerRatingChanged(sellerCardService))
+	if err != nil {
+		logger.Error("Failed to subscribe to SellerRatingChanged events", zap.Error(err))
+	}
+
+	// Subscribe to ListingPromoted events to warm caches before the promotion traffic arrives
+	err = eventBus.Subscribe(listingsdomain.ListingPromotedEvent, handleListingPromoted(listingCacheService))
+	if err != nil {
+		logger.Error("Failed to subscribe to ListingPromoted events", zap.Error(err))
+	}
+
+	// Subscribe to ListingPromotionPaid events to promote listings once their promotion is paid for
+	err = eventBus.Subscribe(listingsdomain.ListingPromotionPaidEvent, handleListingPromotionPaid(promotionService))
+	if err != nil {
+		logger.Error("Failed to subscribe to ListingPromotionPaid events", zap.Error(err))
+	}
+
+	// Subscribe to ListingTrendingUpdated events to warm caches for trending listings
+	err = eventBus.Subscribe(listingsdomain.ListingTrendingUpdatedEvent, handleListingTrendingUpdated(listingCacheService))
+	if err != nil {
+		logger.Error("Failed to subscribe to ListingTrendingUpdated events", zap.Error(err))
+	}
+
+	// Subscribe to ListingViewed events to count views outside the listings table
+	err = eventBus.Subscribe(listingsdomain.ListingViewedEvent, handleListingViewed(counterService, analyticsService))
+	if err != nil {
+		logger.Error("Failed to subscribe to ListingViewed events", zap.Error(err))
+	}
+
+	// Subscribe to ListingFavorited and ListingUnfavorited events to count favorites
+	err = eventBus.Subscribe(listingsdomain.ListingFavoritedEvent, handleListingFavorited(counterService, analyticsService, 1))
+	if err != nil {
+		logger.Error("Failed to subscribe to ListingFavorited events", zap.Error(err))
+	}
+
+	err = eventBus.Subscribe(listingsdomain.ListingUnfavoritedEvent, handleListingFavorited(counterService, analyticsService, -1))
+	if err != nil {
+		logger.Error("Failed to subscribe to ListingUnfavorited events", zap.Error(err))
+	}
+
+	// Subscribe to ListingActivated events to notify the seller's followers
+	err = eventBus.Subscribe(listingsdomain.ListingActivatedEvent, handleListingActivated(followService, savedSearchService, searchService))
+	if err != nil {
+		logger.Error("Failed to subscribe to ListingActivated events", zap.Error(err))
+	}
+
+	// Subscribe to OrderCreated events to start the checkout saga of each order
+	err = eventBus.Subscribe(transactionsdomain.OrderCreatedEvent, handleOrderCreated(sagaOrchestrator))
+	if err != nil {
+		logger.Error("Failed to subscribe to OrderCreated events", zap.Error(err))
+	}
+
+	// Subscribe to OrderAbandoned events to release the listing, remind the buyer and undo the checkout saga
+	err = eventBus.Subscribe(transactionsdomain.OrderAbandonedEvent, handleOrderAbandoned(orderService, sagaOrchestrator))
+	if err != nil {
+		logger.Error("Failed to subscribe to OrderAbandoned events", zap.Error(err))
+	}
+
+	// Subscribe to OrderResumed events to run the checkout saga of a resumed order again
+	err = eventBus.Subscribe(transactionsdomain.OrderResumedEvent, handleOrderResumed(sagaOrchestrator))
+	if err != nil {
+		logger.Error("Failed to subscribe to OrderResumed events", zap.Error(err))
+	}
+
+	// Subscribe to OrderReleased events to give the checkout saga of an order
+	// let through on review its new payment deadline
+	err = eventBus.Subscribe(transactionsdomain.OrderReleasedEvent, handleOrderReleased(sagaOrchestrator))
+	if err != nil {
+		logger.Error("Failed to subscribe to OrderReleased events", zap.Error(err))
+	}
+
+	// Subscribe to OrderDeclined events to undo the checkout saga of an order
+	// cancelled on review
+	err = eventBus.Subscribe(transactionsdomain.OrderDeclinedEvent, handleOrderDeclined(sagaOrchestrator))
+	if err != nil {
+		logger.Error("Failed to subscribe to OrderDeclined events", zap.Error(err))
+	}
+
+	// Subscribe to OrderPaid events to complete the checkout saga and the referrals of first-time buyers
+	err = eventBus.Subscribe(transactionsdomain.OrderPaidEvent, handleOrderPaid(referralService, sagaOrchestrator, escrowService, analyticsService))
+	if err != nil {
+		logger.Error("Failed to subscribe to OrderPaid events", zap.Error(err))
+	}
+
+	// Subscribe to OrderCompleted events to mark the listing of each completed order sold
+	err = eventBus.Subscribe(transactionsdomain.OrderCompletedEvent, handleOrderCompleted(listingService))
+	if err != nil {
+		logger.Error("Failed to subscribe to OrderCompleted events", zap.Error(err))
+	}
+
+	err = eventBus.Subscribe(transactionsdomain.EscrowRefundedEvent, handleEscrowRefunded(refundService))
+	if err != nil {
+		logger.Error("Failed to subscribe to EscrowRefunded events", zap.Error(err))
+	}
+
+	setupListingChanges(eventBus, searchService, similarListingService)
+
+	// Subscribe to ListingImageUploaded events to make the copies of new photos
+	if imageProcessingService != nil {
+		err = eventBus.Subscribe(listingsdomain.ListingImageUploadedEvent, handleListingImageUploaded(imageProcessingService, listingCacheService))
+		if err != nil {
+			logger.Error("Failed to subscribe to ListingImageUploaded events", zap.Error(err))
+		}
+	}
+
 	logger.Info("Worker event subscriptions setup complete")
 }
 
 // Background event handlers
-func handleUserRegisteredBackground(ctx context.Context, event *events.Event) error {
-	logger.Info("Worker handling UserRegistered event",
-		zap.String("event_id", event.ID),
-		zap.String("user_id", event.AggregateID))
 
-	var userData domain.UserRegistered
-	if err := events.ParseEventData(event, &userData); err != nil {
-		return err
-	}
+// handleUserRegisteredBackground starts the verification reminder sequence of
+// a new user and credits their referral
+func handleUserRegisteredBackground(reminderService *app.VerificationReminderService, referralService *app.ReferralService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling UserRegistered event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.UserID(event.AggregateID))
 
-	// Background processing tasks:
-	// 1. Send verification email
-	// 2. Add to marketing automation
-	// 3. Update analytics
-	// 4. Initialize user preferences
-	// 5. Create user folder structure
+		var userData domain.UserRegistered
+		if err := events.ParseEventData(event, &userData); err != nil {
+			return err
+		}
 
-	// Simulate some background work
-	time.Sleep(100 * time.Millisecond)
+		// Background processing tasks:
+		// 1. Send verification email
+		// 2. Add to marketing automation
+		// 3. Update analytics
+		// 4. Initialize user preferences
+		// 5. Create user folder structure
 
-	logger.Info("Worker completed UserRegistered background processing",
-		zap.String("user_email", userData.Email))
+		if err := reminderService.ScheduleReminders(ctx, userData.UserID, userData.Timestamp); err != nil {
+			return err
+		}
+		if err := referralService.AttributeReferral(ctx, userData.UserID, userData.ReferralCode); err != nil {
+			return err
+		}
 
-	return nil
+		logger.Info("Worker completed UserRegistered background processing",
+			zap.String("user_email", userData.Email))
+
+		return nil
+	}
+}
+
+// handleUserEmailVerified stops the verification reminders of a verified user
+func handleUserEmailVerified(reminderService *app.VerificationReminderService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling UserEmailVerified event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.UserID(event.AggregateID))
+
+		var userData domain.UserEmailVerified
+		if err := events.ParseEventData(event, &userData); err != nil {
+			return err
+		}
+
+		return reminderService.StopReminders(ctx, userData.UserID)
+	}
 }
 
 func handleUserUpgradedToSeller(ctx context.Context, event *events.Event) error {
 	logger.Info("Worker handling UserUpgradedToSeller event",
-		zap.String("event_id", event.ID),
-		zap.String("user_id", event.AggregateID))
+		logger.EventID(event.ID),
+		logger.TraceID(ctx),
+		logger.UserID(event.AggregateID))
 
 	var userData domain.UserUpgradedToSeller
 	if err := events.ParseEventData(event, &userData); err != nil {
@@ -116,3 +1003,866 @@ func handleUserUpgradedToSeller(ctx context.Context, event *events.Event) error
 
 	return nil
 }
+
+// handleUserDeleted takes a deleted seller's live listings down until the
+// account is restored
+func handleUserDeleted(listingService *listingsapp.ListingService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling UserDeleted event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.UserID(event.AggregateID))
+
+		var userData domain.UserDeleted
+		if err := events.ParseEventData(event, &userData); err != nil {
+			return err
+		}
+
+		count, err := listingService.HoldSellerListings(ctx, userData.UserID, listingsdomain.ListingHoldSellerDeleted)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Worker completed UserDeleted background processing",
+			logger.UserID(userData.UserID.String()),
+			zap.Int("listings_held", count))
+
+		return nil
+	}
+}
+
+// handleUserRestored puts the listings taken down when the account was
+// deleted back live
+func handleUserRestored(listingService *listingsapp.ListingService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling UserRestored event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.UserID(event.AggregateID))
+
+		var userData domain.UserRestored
+		if err := events.ParseEventData(event, &userData); err != nil {
+			return err
+		}
+
+		count, err := listingService.ReleaseSellerListings(ctx, userData.UserID, listingsdomain.ListingHoldSellerDeleted)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Worker completed UserRestored background processing",
+			logger.UserID(userData.UserID.String()),
+			zap.Int("listings_released", count))
+
+		return nil
+	}
+}
+
+// handleUserSuspended takes a suspended seller's live listings down, so
+// buyers stop finding them while the seller cannot answer
+func handleUserSuspended(listingService *listingsapp.ListingService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling UserSuspended event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.UserID(event.AggregateID))
+
+		var userData domain.UserSuspended
+		if err := events.ParseEventData(event, &userData); err != nil {
+			return err
+		}
+
+		count, err := listingService.HoldSellerListings(ctx, userData.UserID, listingsdomain.ListingHoldSellerSuspended)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Worker completed UserSuspended background processing",
+			logger.UserID(userData.UserID.String()),
+			zap.Int("listings_held", count))
+
+		return nil
+	}
+}
+
+// handleUserActivated puts the listings taken down by a suspension back live
+// once it is lifted
+func handleUserActivated(listingService *listingsapp.ListingService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling UserActivated event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.UserID(event.AggregateID))
+
+		var userData domain.UserActivated
+		if err := events.ParseEventData(event, &userData); err != nil {
+			return err
+		}
+
+		count, err := listingService.ReleaseSellerListings(ctx, userData.UserID, listingsdomain.ListingHoldSellerSuspended)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Worker completed UserActivated background processing",
+			logger.UserID(userData.UserID.String()),
+			zap.Int("listings_released", count))
+
+		return nil
+	}
+}
+
+// handleDataExportRequested assembles a user's data export archive
+func handleDataExportRequested(exportService *app.DataExportService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling DataExportRequested event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.UserID(event.AggregateID))
+
+		var exportData domain.DataExportRequested
+		if err := events.ParseEventData(event, &exportData); err != nil {
+			return err
+		}
+
+		if err := exportService.ProcessExport(ctx, exportData.ExportID); err != nil {
+			return err
+		}
+
+		logger.Info("Worker completed DataExportRequested background processing",
+			zap.String("export_id", exportData.ExportID))
+
+		return nil
+	}
+}
+
+// handleUserMerged moves the duplicate account's listings, orders and
+// everything hanging off them, and wallet balance to the primary account
+func handleUserMerged(listingService *listingsapp.ListingService, accountMergeService *transactionsapp.AccountMergeService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling UserMerged event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.UserID(event.AggregateID))
+
+		var mergeData domain.UserMerged
+		if err := events.ParseEventData(event, &mergeData); err != nil {
+			return err
+		}
+
+		count, err := listingService.ReassignSellerListings(ctx, mergeData.DuplicateUserID, mergeData.PrimaryUserID)
+		if err != nil {
+			return err
+		}
+		result, err := accountMergeService.MergeAccounts(ctx, event.ID, mergeData.DuplicateUserID, mergeData.PrimaryUserID)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Worker completed UserMerged background processing",
+			zap.String("primary_user_id", mergeData.PrimaryUserID.String()),
+			zap.String("duplicate_user_id", mergeData.DuplicateUserID.String()),
+			zap.Int("listings_reassigned", count),
+			zap.Int64("orders_reassigned", result.OrdersMoved),
+			zap.String("wallet_moved", result.WalletMoved.String()))
+
+		return nil
+	}
+}
+
+// handleSellerVerified re-evaluates the badges of a newly verified seller,
+// marks their seller card verified and puts listings taken down by an earlier
+// rejection back live
+func handleSellerVerified(badgeService *app.BadgeService, sellerCardService *listingsapp.SellerCardService, listingService *listingsapp.ListingService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling SellerVerified event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.UserID(event.AggregateID))
+
+		var sellerData domain.SellerVerified
+		if err := events.ParseEventData(event, &sellerData); err != nil {
+			return err
+		}
+
+		if err := sellerCardService.MarkVerified(ctx, sellerData.UserID, sellerData.Timestamp); err != nil {
+			return err
+		}
+		if _, err := listingService.ReleaseSellerListings(ctx, sellerData.UserID, listingsdomain.ListingHoldSellerRejected); err != nil {
+			return err
+		}
+		return badgeService.RefreshSeller(ctx, sellerData.UserID)
+	}
+}
+
+// handleSellerVerificationRejected takes a rejected seller's live listings
+// down until they are verified
+func handleSellerVerificationRejected(listingService *listingsapp.ListingService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling SellerVerificationRejected event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.UserID(event.AggregateID))
+
+		var sellerData domain.SellerVerificationRejected
+		if err := events.ParseEventData(event, &sellerData); err != nil {
+			return err
+		}
+
+		count, err := listingService.HoldSellerListings(ctx, sellerData.UserID, listingsdomain.ListingHoldSellerRejected)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Worker completed SellerVerificationRejected background processing",
+			logger.UserID(sellerData.UserID.String()),
+			zap.Int("listings_held", count))
+
+		return nil
+	}
+}
+
+// handleSellerRatingChanged copies a seller's current standing to their seller card
+func handleSellerRatingChanged(sellerCardService *listingsapp.SellerCardService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling SellerRatingChanged event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			zap.String("seller_id", event.AggregateID))
+
+		var ratingData domain.SellerRatingChanged
+		if err := events.ParseEventData(event, &ratingData); err != nil {
+			return err
+		}
+
+		badges := make([]string, 0, len(ratingData.Badges))
+		for _, badge := range ratingData.Badges {
+			badges = append(badges, string(badge))
+		}
+		return sellerCardService.UpdateStats(ctx, listingsapp.UpdateSellerCardCommand{
+			SellerID:            ratingData.SellerID,
+			Verified:            ratingData.Verified,
+			Rating:              ratingData.Rating,
+			TotalReviews:        ratingData.TotalReviews,
+			ResponseTimeMinutes: ratingData.ResponseTimeMinutes,
+			TrustLevel:          string(ratingData.TrustLevel),
+			Badges:              badges,
+			AsOf:                ratingData.Timestamp,
+		})
+	}
+}
+
+// handleSaleCompleted counts a completed sale towards the seller's badges
+func handleSaleCompleted(badgeService *app.BadgeService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling SaleCompleted event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.TransactionID(event.AggregateID))
+
+		var saleData domain.SaleCompleted
+		if err := events.ParseEventData(event, &saleData); err != nil {
+			return err
+		}
+
+		return badgeService.RecordActivity(ctx, app.RecordSellerActivityCommand{
+			EventID:    event.ID,
+			SellerID:   saleData.SellerID,
+			Kind:       domain.ActivitySale,
+			Value:      1,
+			OccurredAt: saleData.Timestamp,
+		})
+	}
+}
+
+// handleReviewSubmitted counts a review's rating towards the seller's badges
+func handleReviewSubmitted(badgeService *app.BadgeService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling ReviewSubmitted event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			zap.String("review_id", event.AggregateID))
+
+		var reviewData domain.ReviewSubmitted
+		if err := events.ParseEventData(event, &reviewData); err != nil {
+			return err
+		}
+
+		return badgeService.RecordActivity(ctx, app.RecordSellerActivityCommand{
+			EventID:    event.ID,
+			SellerID:   reviewData.SellerID,
+			Kind:       domain.ActivityReview,
+			Value:      float64(reviewData.Rating),
+			OccurredAt: reviewData.Timestamp,
+		})
+	}
+}
+
+// handleSellerResponded counts a seller's response time towards their badges
+func handleSellerResponded(badgeService *app.BadgeService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling SellerResponded event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			zap.String("conversation_id", event.AggregateID))
+
+		var responseData domain.SellerResponded
+		if err := events.ParseEventData(event, &responseData); err != nil {
+			return err
+		}
+
+		return badgeService.RecordActivity(ctx, app.RecordSellerActivityCommand{
+			EventID:    event.ID,
+			SellerID:   responseData.SellerID,
+			Kind:       domain.ActivityResponse,
+			Value:      float64(responseData.ResponseSeconds) / 60,
+			OccurredAt: responseData.Timestamp,
+		})
+	}
+}
+
+// handleConversationStarted counts a buyer inquiry towards the seller's
+// response rate, and towards the analytics of the listing it is about
+func handleConversationStarted(badgeService *app.BadgeService, analyticsService *listingsapp.AnalyticsService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling ConversationStarted event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			zap.String("conversation_id", event.AggregateID))
+
+		var conversationData domain.ConversationStarted
+		if err := events.ParseEventData(event, &conversationData); err != nil {
+			return err
+		}
+
+		err := badgeService.RecordActivity(ctx, app.RecordSellerActivityCommand{
+			EventID:    event.ID,
+			SellerID:   conversationData.SellerID,
+			Kind:       domain.ActivityInquiry,
+			OccurredAt: conversationData.Timestamp,
+		})
+		if err != nil || conversationData.ListingID == "" {
+			return err
+		}
+		return analyticsService.RecordActivity(ctx, event.ID, conversationData.ListingID, conversationData.SellerID, listingsdomain.ListingActivity{Messages: 1}, conversationData.Timestamp)
+	}
+}
+
+// handleListingPromoted warms the detail cache and CDN edges of a promoted listing
+func handleListingPromoted(listingCacheService *listingsapp.ListingCacheService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling ListingPromoted event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.ListingID(event.AggregateID))
+
+		var promotionData listingsdomain.ListingPromoted
+		if err := events.ParseEventData(event, &promotionData); err != nil {
+			return err
+		}
+
+		summary, err := listingCacheService.WarmListings(ctx, []ids.ListingID{promotionData.ListingID})
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Worker completed ListingPromoted cache warming",
+			logger.ListingID(promotionData.ListingID.String()),
+			zap.Int("images_warmed", summary.ImagesWarmed),
+			zap.Int("images_failed", summary.ImagesFailed))
+
+		return nil
+	}
+}
+
+// handleListingPromotionPaid promotes a listing for the time its seller paid
+// for. Promotions already applied are skipped, so redelivered events are safe.
+func handleListingPromotionPaid(promotionService *listingsapp.PromotionService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling ListingPromotionPaid event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.ListingID(event.AggregateID))
+
+		var promotionData listingsdomain.ListingPromotionPaid
+		if err := events.ParseEventData(event, &promotionData); err != nil {
+			return err
+		}
+
+		applied, err := promotionService.ApplyPromotion(ctx, promotionData.PromotionID)
+		if isDomainError(err, errors.ErrCodeNotFound) {
+			// The promotion or its listing is gone; retrying will not bring it back
+			logger.Warn("Skipping promotion of a missing listing",
+				logger.ListingID(promotionData.ListingID.String()),
+				zap.String("promotion_id", promotionData.PromotionID),
+				zap.Error(err))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if applied {
+			logger.Info("Worker completed ListingPromotionPaid promotion",
+				logger.ListingID(promotionData.ListingID.String()),
+				zap.Int("days", promotionData.Days))
+		}
+		return nil
+	}
+}
+
+// handleListingTrendingUpdated warms the detail cache and CDN edges of trending listings
+func handleListingTrendingUpdated(listingCacheService *listingsapp.ListingCacheService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling ListingTrendingUpdated event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx))
+
+		var trendingData listingsdomain.ListingTrendingUpdated
+		if err := events.ParseEventData(event, &trendingData); err != nil {
+			return err
+		}
+
+		summary, err := listingCacheService.WarmListings(ctx, trendingData.ListingIDs)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Worker completed ListingTrendingUpdated cache warming",
+			zap.Int("listings_warmed", summary.Warmed),
+			zap.Int("listings_evicted", summary.Evicted),
+			zap.Int("images_warmed", summary.ImagesWarmed),
+			zap.Int("images_failed", summary.ImagesFailed))
+
+		return nil
+	}
+}
+
+// handleListingViewed counts a view of a listing, in Redis when views are
+// flushed in batches. Views written straight away also count toward the
+// seller's analytics. Views are too frequent to log one by one at info level.
+func handleListingViewed(counterService *listingsapp.CounterService, analyticsService *listingsapp.AnalyticsService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		var viewData listingsdomain.ListingViewed
+		if err := events.ParseEventData(event, &viewData); err != nil {
+			return err
+		}
+
+		written, err := counterService.RecordView(ctx, viewData.ListingID, viewData.ViewerKey, viewData.Timestamp)
+		if isDomainError(err, errors.ErrCodeValidation) {
+			// The listing was deleted since it was viewed; retrying will not bring it back
+			logger.Debug("Skipping view of a missing listing",
+				logger.EventID(event.ID),
+				logger.ListingID(viewData.ListingID.String()))
+			return nil
+		}
+		if err != nil || !written {
+			return err
+		}
+		return analyticsService.RecordViews(ctx, map[ids.ListingID]int64{viewData.ListingID: 1})
+	}
+}
+
+// handleListingFavorited counts a buyer adding a listing to their favorites,
+// or taking it off them when delta is negative, in the listing's counters and
+// the seller's analytics
+func handleListingFavorited(counterService *listingsapp.CounterService, analyticsService *listingsapp.AnalyticsService, delta int64) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling listing favorite event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.EventAction(event.Type),
+			logger.ListingID(event.AggregateID))
+
+		var favoriteData listingsdomain.ListingFavorited
+		if err := events.ParseEventData(event, &favoriteData); err != nil {
+			return err
+		}
+
+		err := counterService.RecordFavorite(ctx, favoriteData.ListingID, delta, favoriteData.Timestamp)
+		if isDomainError(err, errors.ErrCodeValidation) {
+			logger.Debug("Skipping favorite of a missing listing",
+				logger.EventID(event.ID),
+				logger.ListingID(favoriteData.ListingID.String()))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return analyticsService.RecordActivity(ctx, event.ID, favoriteData.ListingID, favoriteData.SellerID, listingsdomain.ListingActivity{Favorites: delta}, favoriteData.Timestamp)
+	}
+}
+
+// handleListingActivated indexes the new listing for search, when search is
+// served from a cluster, and notifies the seller's followers and the buyers
+// whose saved searches it matches
+func handleListingActivated(followService *app.FollowService, savedSearchService *listingsapp.SavedSearchService, searchService *listingsapp.SearchService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling ListingActivated event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.ListingID(event.AggregateID))
+
+		var listingData listingsdomain.ListingActivated
+		if err := events.ParseEventData(event, &listingData); err != nil {
+			return err
+		}
+
+		if searchService != nil {
+			if err := searchService.IndexListing(ctx, listingData.ListingID); err != nil {
+				return err
+			}
+		}
+
+		count, err := followService.NotifyFollowers(ctx, app.NotifyFollowersCommand{
+			ListingID: listingData.ListingID,
+			SellerID:  listingData.SellerID,
+			Title:     listingData.Title,
+			Price:     listingData.Price,
+		})
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Worker completed ListingActivated follower notifications",
+			logger.ListingID(listingData.ListingID.String()),
+			zap.Int("followers_notified", count))
+
+		matched, err := savedSearchService.NotifyMatches(ctx, listingData.ListingID)
+		if err != nil {
+			if isDomainError(err, errors.ErrCodeNotFound) {
+				logger.Warn("Skipping saved search alerts for a listing that no longer exists",
+					logger.ListingID(listingData.ListingID.String()))
+				return nil
+			}
+			return err
+		}
+
+		logger.Info("Worker completed ListingActivated saved search alerts",
+			logger.ListingID(listingData.ListingID.String()),
+			zap.Int("buyers_alerted", matched))
+
+		return nil
+	}
+}
+
+// setupListingChanges subscribes to the listing events that change what
+// searches find, so the search index follows the database when listing
+// search is served from a cluster, and drops the cached similar listings of
+// changed listings. ListingActivated is indexed by handleListingActivated, as
+// each event type has one consumer.
+func setupListingChanges(eventBus events.EventBus, searchService *listingsapp.SearchService, similarListingService *listingsapp.SimilarListingService) {
+	err := eventBus.Subscribe(listingsdomain.ListingChangedEvent, handleListingChanged(searchService, similarListingService))
+	if err != nil {
+		logger.Error("Failed to subscribe to ListingChanged events", zap.Error(err))
+	}
+
+	if searchService == nil {
+		return
+	}
+
+	err = eventBus.Subscribe(listingsdomain.ListingOwnerChangedEvent, handleListingIndexed(searchService))
+	if err != nil {
+		logger.Error("Failed to subscribe to ListingOwnerChanged events", zap.Error(err))
+	}
+
+	err = eventBus.Subscribe(listingsdomain.ListingTransferCompletedEvent, handleListingTransferIndexed(searchService))
+	if err != nil {
+		logger.Error("Failed to subscribe to ListingTransferCompleted events", zap.Error(err))
+	}
+}
+
+// handleListingChanged drops the cached similar listings of the event's
+// listing and refreshes its copy in the search index, if there is one
+func handleListingChanged(searchService *listingsapp.SearchService, similarListingService *listingsapp.SimilarListingService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling ListingChanged event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.ListingID(event.AggregateID))
+
+		listingID := ids.ListingID(event.AggregateID)
+		if err := similarListingService.InvalidateSimilarListings(ctx, listingID); err != nil {
+			return err
+		}
+		if searchService == nil {
+			return nil
+		}
+		return searchService.IndexListing(ctx, listingID)
+	}
+}
+
+// handleListingIndexed refreshes the search index's copy of the event's listing
+func handleListingIndexed(searchService *listingsapp.SearchService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker indexing listing for search",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.EventAction(event.Type),
+			logger.ListingID(event.AggregateID))
+
+		return searchService.IndexListing(ctx, ids.ListingID(event.AggregateID))
+	}
+}
+
+// handleListingTransferIndexed refreshes the search index's copies of the
+// listings moved by an ownership transfer
+func handleListingTransferIndexed(searchService *listingsapp.SearchService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling ListingTransferCompleted event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx))
+
+		var transferData listingsdomain.ListingTransferCompleted
+		if err := events.ParseEventData(event, &transferData); err != nil {
+			return err
+		}
+
+		return searchService.IndexListings(ctx, transferData.ListingIDs)
+	}
+}
+
+// handleListingImageUploaded makes the resized copies of a listing photo and
+// drops the cached listing detail still pointing at the original
+func handleListingImageUploaded(imageProcessingService *listingsapp.ImageProcessingService, listingCacheService *listingsapp.ListingCacheService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling ListingImageUploaded event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.ListingID(event.AggregateID))
+
+		var imageData listingsdomain.ListingImageUploaded
+		if err := events.ParseEventData(event, &imageData); err != nil {
+			return err
+		}
+
+		processed, err := imageProcessingService.ProcessListingImage(ctx, imageData.ListingID, imageData.ImageID)
+		if err != nil {
+			return err
+		}
+		if !processed {
+			return nil
+		}
+
+		logger.Info("Worker processed listing photo",
+			logger.ListingID(imageData.ListingID.String()),
+			zap.String("image_id", imageData.ImageID))
+
+		return listingCacheService.InvalidateListing(ctx, imageData.ListingID)
+	}
+}
+
+// handleOrderCreated starts the checkout saga of a new order
+func handleOrderCreated(sagaOrchestrator *transactionsapp.SagaOrchestrator) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling OrderCreated event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.OrderID(event.AggregateID))
+
+		var orderData transactionsdomain.OrderCreated
+		if err := events.ParseEventData(event, &orderData); err != nil {
+			return err
+		}
+
+		_, err := sagaOrchestrator.StartCheckout(ctx, orderData.OrderID)
+		if isDomainError(err, errors.ErrCodeNotFound) {
+			// The order is gone; retrying will not bring it back
+			return nil
+		}
+		return err
+	}
+}
+
+// handleOrderAbandoned releases the listing of an abandoned order, sends
+// the buyer a link back to the payment and undoes the order's checkout saga
+func handleOrderAbandoned(orderService *transactionsapp.OrderService, sagaOrchestrator *transactionsapp.SagaOrchestrator) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling OrderAbandoned event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.OrderID(event.AggregateID))
+
+		var orderData transactionsdomain.OrderAbandoned
+		if err := events.ParseEventData(event, &orderData); err != nil {
+			return err
+		}
+
+		if err := orderService.RecoverAbandonedCheckout(ctx, orderData.OrderID); err != nil {
+			return err
+		}
+
+		_, err := sagaOrchestrator.AbandonCheckout(ctx, orderData.OrderID)
+		if isDomainError(err, errors.ErrCodeNotFound) {
+			// Orders placed before checkout sagas have none
+			return nil
+		}
+		return err
+	}
+}
+
+// handleOrderResumed runs the checkout saga of an order the buyer came back to again
+func handleOrderResumed(sagaOrchestrator *transactionsapp.SagaOrchestrator) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling OrderResumed event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.OrderID(event.AggregateID))
+
+		var orderData transactionsdomain.OrderResumed
+		if err := events.ParseEventData(event, &orderData); err != nil {
+			return err
+		}
+
+		_, err := sagaOrchestrator.ResumeCheckout(ctx, orderData.OrderID)
+		if isDomainError(err, errors.ErrCodeNotFound) {
+			return nil
+		}
+		return err
+	}
+}
+
+// handleOrderReleased moves the payment deadline of the checkout saga of an
+// order an administrator let through on review
+func handleOrderReleased(sagaOrchestrator *transactionsapp.SagaOrchestrator) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling OrderReleased event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.OrderID(event.AggregateID))
+
+		var orderData transactionsdomain.OrderReleased
+		if err := events.ParseEventData(event, &orderData); err != nil {
+			return err
+		}
+
+		_, err := sagaOrchestrator.ReleaseCheckout(ctx, orderData.OrderID)
+		if isDomainError(err, errors.ErrCodeNotFound) {
+			return nil
+		}
+		return err
+	}
+}
+
+// handleOrderDeclined undoes the checkout saga of an order an administrator
+// cancelled on review, releasing its listing
+func handleOrderDeclined(sagaOrchestrator *transactionsapp.SagaOrchestrator) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling OrderDeclined event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.OrderID(event.AggregateID))
+
+		var orderData transactionsdomain.OrderDeclined
+		if err := events.ParseEventData(event, &orderData); err != nil {
+			return err
+		}
+
+		_, err := sagaOrchestrator.DeclineCheckout(ctx, orderData.OrderID)
+		if isDomainError(err, errors.ErrCodeNotFound) {
+			return nil
+		}
+		return err
+	}
+}
+
+// handleOrderPaid holds the payment in escrow, completes the order's checkout
+// saga, counts the sale in the seller's analytics and completes the referral
+// of a buyer making their first purchase
+func handleOrderPaid(referralService *app.ReferralService, sagaOrchestrator *transactionsapp.SagaOrchestrator, escrowService *transactionsapp.EscrowService, analyticsService *listingsapp.AnalyticsService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling OrderPaid event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.OrderID(event.AggregateID))
+
+		var orderData transactionsdomain.OrderPaid
+		if err := events.ParseEventData(event, &orderData); err != nil {
+			return err
+		}
+
+		if _, err := escrowService.HoldPayment(ctx, orderData.OrderID); err != nil {
+			return err
+		}
+
+		// A payment arriving after the checkout was undone leaves the saga
+		// for an administrator
+		_, err := sagaOrchestrator.CompletePayment(ctx, orderData.OrderID)
+		if isDomainError(err, errors.ErrCodeConflict) {
+			logger.Warn("Order paid after its checkout saga was compensated",
+				logger.OrderID(orderData.OrderID.String()),
+				zap.Error(err))
+		} else if err != nil && !isDomainError(err, errors.ErrCodeNotFound) {
+			return err
+		}
+
+		err = analyticsService.RecordActivity(ctx, event.ID, orderData.ListingID, orderData.SellerID, listingsdomain.ListingActivity{Orders: 1}, orderData.Timestamp)
+		if err != nil {
+			return err
+		}
+
+		return referralService.CompleteReferral(ctx, app.CompleteReferralCommand{
+			BuyerID: orderData.BuyerID,
+			OrderID: orderData.OrderID,
+			Amount:  orderData.Amount,
+		})
+	}
+}
+
+// handleOrderCompleted marks the listing of an order whose funds were
+// released to the seller sold
+func handleOrderCompleted(listingService *listingsapp.ListingService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling OrderCompleted event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.OrderID(event.AggregateID))
+
+		var orderData transactionsdomain.OrderCompleted
+		if err := events.ParseEventData(event, &orderData); err != nil {
+			return err
+		}
+
+		// The sale stands even if the listing's status cannot record it
+		_, err := listingService.RecordSale(ctx, orderData.ListingID, orderData.BuyerID)
+		if isDomainError(err, errors.ErrCodeValidation) {
+			logger.Warn("Listing of a completed order cannot be marked sold",
+				logger.OrderID(orderData.OrderID.String()),
+				zap.Error(err))
+			return nil
+		}
+		return err
+	}
+}
+
+// handleEscrowRefunded sends the buyer of a cancelled order their money
+// back. Orders not paid by Mobile Money, or whose refunds cannot be sent
+// here, are left for an administrator to refund by hand.
+func handleEscrowRefunded(refundService *transactionsapp.RefundService) events.EventHandler {
+	return func(ctx context.Context, event *events.Event) error {
+		logger.Info("Worker handling EscrowRefunded event",
+			logger.EventID(event.ID),
+			logger.TraceID(ctx),
+			logger.OrderID(event.AggregateID))
+
+		var escrowData transactionsdomain.EscrowChanged
+		if err := events.ParseEventData(event, &escrowData); err != nil {
+			return err
+		}
+
+		_, err := refundService.RefundCancelledOrder(ctx, escrowData.OrderID, escrowData.EscrowID, escrowData.Reason)
+		if isDomainError(err, errors.ErrCodeConflict) {
+			logger.Warn("Cancelled order cannot be refunded through Mobile Money",
+				logger.OrderID(escrowData.OrderID.String()),
+				zap.Error(err))
+			return nil
+		}
+		return err
+	}
+}
+
+// isDomainError checks if err is a domain error with the given code
+func isDomainError(err error, code errors.ErrorCode) bool {
+	domainErr, ok := err.(*errors.DomainError)
+	return ok && domainErr.Code == code
+}
@@ -86,7 +86,7 @@ func simulateUserRegistration(eventBus events.EventBus) {
 	// Create the event
 	event, err := events.NewEvent(
 		domain.UserRegisteredEvent,
-		userRegisteredData.UserID,
+		userRegisteredData.UserID.String(),
 		userRegisteredData,
 	)
 	if err != nil {
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// defaultAnalyticsDays is the period seller analytics cover when the query
+// does not say
+const defaultAnalyticsDays = 30
+
+// SellerAnalyticsQuery represents the days seller analytics cover, both
+// included. They default to the last 30 days.
+type SellerAnalyticsQuery struct {
+	From time.Time `form:"from" time_format:"2006-01-02"`
+	To   time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+// AnalyticsService projects what buyers do with listings into daily stats,
+// from events, and serves sellers their analytics from those stats
+type AnalyticsService struct {
+	statsRepo   domain.ListingStatsRepository
+	listingRepo domain.ListingRepository
+	clock       clock.Clock
+}
+
+// NewAnalyticsService creates a new analytics service. The listing repository
+// is only used to find the sellers of viewed listings.
+func NewAnalyticsService(statsRepo domain.ListingStatsRepository, listingRepo domain.ListingRepository, clk clock.Clock) *AnalyticsService {
+	return &AnalyticsService{
+		statsRepo:   statsRepo,
+		listingRepo: listingRepo,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// RecordActivity adds the activity of an event to the stats of a listing
+// for the day it happened. A redelivered event counts once.
+func (s *AnalyticsService) RecordActivity(ctx context.Context, eventID string, listingID ids.ListingID, sellerID ids.UserID, activity domain.ListingActivity, at time.Time) error {
+	if eventID == "" || listingID == "" || sellerID == "" {
+		return errors.ValidationError("event id, listing id and seller id are required")
+	}
+	if at.IsZero() {
+		at = s.clock.Now()
+	}
+
+	return db.WithRetry(ctx, func() error {
+		_, err := s.statsRepo.Record(eventID, listingID, sellerID, domain.AnalyticsDay(at), activity, s.clock.Now())
+		return err
+	})
+}
+
+// RecordViews adds views flushed from the view buffer to the stats of the
+// listings for today. Views of listings that no longer exist are dropped.
+func (s *AnalyticsService) RecordViews(ctx context.Context, views map[ids.ListingID]int64) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	listingIDs := make([]ids.ListingID, 0, len(views))
+	for listingID := range views {
+		listingIDs = append(listingIDs, listingID)
+	}
+	listings, err := s.listingRepo.FindByIDs(listingIDs)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	for _, listing := range listings {
+		activity := domain.ListingActivity{Views: views[listing.ID]}
+		err := db.WithRetry(ctx, func() error {
+			_, err := s.statsRepo.Record("", listing.ID, listing.SellerID, domain.AnalyticsDay(now), activity, now)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSellerAnalytics returns the activity of a seller's listings over the
+// query's days, in total, per day and per listing
+func (s *AnalyticsService) GetSellerAnalytics(ctx context.Context, sellerID ids.UserID, query SellerAnalyticsQuery) (*domain.SellerAnalytics, error) {
+	if sellerID == "" {
+		return nil, errors.ValidationError("seller id is required")
+	}
+
+	from, to, err := query.days(s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.statsRepo.FindBySeller(sellerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewSellerAnalytics(sellerID, from, to, stats), nil
+}
+
+// days returns the first and last days the query covers, checking they are
+// in order and at most MaxAnalyticsDays apart
+func (query SellerAnalyticsQuery) days(now time.Time) (time.Time, time.Time, error) {
+	to := query.To
+	if to.IsZero() {
+		to = now
+	}
+	to = domain.AnalyticsDay(to)
+	from := query.From
+	if from.IsZero() {
+		from = to.AddDate(0, 0, 1-defaultAnalyticsDays)
+	}
+	from = domain.AnalyticsDay(from)
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.ValidationError("from must be before to")
+	}
+	if to.Sub(from) >= domain.MaxAnalyticsDays*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.ValidationError("analytics can cover at most 90 days")
+	}
+	return from, to, nil
+}
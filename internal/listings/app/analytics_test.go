@@ -0,0 +1,50 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsService_ProjectsActivityIntoSellerAnalytics(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFrozen(time.Date(2026, time.March, 10, 18, 0, 0, 0, time.UTC))
+	phone := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	other := newActiveListing(t, "seller-b", "Laptop", domain.ConditionGood)
+	service := app.NewAnalyticsService(newFakeListingStatsRepository(), newFakeListingRepository(phone, other), clk)
+
+	yesterday := clk.Now().AddDate(0, 0, -1)
+	require.NoError(t, service.RecordViews(ctx, map[ids.ListingID]int64{phone.ID: 40, other.ID: 5, "deleted": 3}))
+	require.NoError(t, service.RecordActivity(ctx, "event-1", phone.ID, phone.SellerID, domain.ListingActivity{Messages: 1}, yesterday))
+	require.NoError(t, service.RecordActivity(ctx, "event-2", phone.ID, phone.SellerID, domain.ListingActivity{Orders: 1}, clk.Now()))
+	// A redelivered event counts once
+	require.NoError(t, service.RecordActivity(ctx, "event-2", phone.ID, phone.SellerID, domain.ListingActivity{Orders: 1}, clk.Now()))
+	assert.Error(t, service.RecordActivity(ctx, "", phone.ID, phone.SellerID, domain.ListingActivity{Orders: 1}, clk.Now()))
+
+	analytics, err := service.GetSellerAnalytics(ctx, "seller-a", app.SellerAnalyticsQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-02-09", analytics.From, "the last 30 days by default")
+	assert.Equal(t, "2026-03-10", analytics.To)
+	assert.Len(t, analytics.Days, 30)
+	assert.Equal(t, domain.ListingActivity{Views: 40, Messages: 1, Orders: 1}, analytics.Totals.ListingActivity)
+	assert.Equal(t, 0.025, analytics.Totals.Conversion)
+	require.Len(t, analytics.Listings, 1, "only the seller's own listings")
+	assert.Equal(t, phone.ID, analytics.Listings[0].ListingID)
+	require.Len(t, analytics.Listings[0].Days, 2)
+	assert.Equal(t, int64(1), analytics.Listings[0].Days[0].Messages)
+
+	_, err = service.GetSellerAnalytics(ctx, "seller-a", app.SellerAnalyticsQuery{From: clk.Now(), To: yesterday})
+	assert.Error(t, err)
+	_, err = service.GetSellerAnalytics(ctx, "seller-a", app.SellerAnalyticsQuery{From: clk.Now().AddDate(0, 0, -90)})
+	assert.Error(t, err, "at most 90 days")
+	_, err = service.GetSellerAnalytics(ctx, "seller-a", app.SellerAnalyticsQuery{From: clk.Now().AddDate(0, 0, -89)})
+	assert.NoError(t, err)
+}
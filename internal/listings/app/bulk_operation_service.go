@@ -0,0 +1,484 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// bulkOperationsPerRun is how many unfinished bulk operations each run works on
+const bulkOperationsPerRun = 5
+
+// ListingIndexer refreshes the search index's copy of a listing. It is
+// implemented by SearchService.
+type ListingIndexer interface {
+	IndexListing(ctx context.Context, listingID ids.ListingID) error
+}
+
+// SubmitBulkOperationCommand represents an administrator submitting a bulk
+// operation. TargetID is the seller or category it covers, depending on Kind;
+// kinds that select listings pick them by ListingIDs or Filter instead.
+// CategoryID is the category move_category moves listings to.
+type SubmitBulkOperationCommand struct {
+	AdminID    ids.UserID                `json:"-"`
+	Kind       domain.BulkOperationKind  `json:"kind" binding:"required"`
+	TargetID   string                    `json:"target_id"`
+	ListingIDs []ids.ListingID           `json:"listing_ids" binding:"omitempty,max=1000"`
+	Filter     *domain.BulkListingFilter `json:"filter"`
+	CategoryID string                    `json:"category_id"`
+	Reason     string                    `json:"reason" binding:"max=500"`
+}
+
+// ListBulkOperationsQuery represents the query to list bulk operations
+type ListBulkOperationsQuery struct {
+	Status domain.BulkOperationStatus `form:"status" binding:"omitempty,oneof=pending running completed cancelled"`
+	Limit  int                        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int                        `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ListBulkOperationItemsQuery represents the query to list the per-listing
+// results of a bulk operation
+type ListBulkOperationItemsQuery struct {
+	Status domain.BulkItemStatus `form:"status" binding:"omitempty,oneof=pending succeeded skipped failed"`
+	Limit  int                   `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int                   `form:"offset" binding:"omitempty,min=0"`
+}
+
+// BulkOperations represents a page of bulk operations
+type BulkOperations struct {
+	Operations []*domain.BulkOperation `json:"operations"`
+	Total      int64                   `json:"total"`
+	Limit      int                     `json:"limit"`
+	Offset     int                     `json:"offset"`
+}
+
+// BulkOperationItems represents a page of a bulk operation's per-listing results
+type BulkOperationItems struct {
+	Items  []*domain.BulkOperationItem `json:"items"`
+	Total  int64                       `json:"total"`
+	Limit  int                         `json:"limit"`
+	Offset int                         `json:"offset"`
+}
+
+// BulkOperationService runs administrator actions over many listings in the
+// background. Operations are submitted from the API and worked through by
+// the worker in batches, recording a result per listing.
+type BulkOperationService struct {
+	operationRepo domain.BulkOperationRepository
+	listingRepo   domain.ListingRepository
+	categoryRepo  domain.CategoryRepository
+	eventBus      events.EventBus
+	indexer       ListingIndexer
+}
+
+// NewBulkOperationService creates a new bulk operation service. indexer may
+// be nil when listing search is not served from a search index, in which
+// case reindexing cannot be submitted.
+func NewBulkOperationService(operationRepo domain.BulkOperationRepository, listingRepo domain.ListingRepository, categoryRepo domain.CategoryRepository, eventBus events.EventBus, indexer ListingIndexer) *BulkOperationService {
+	return &BulkOperationService{
+		operationRepo: operationRepo,
+		listingRepo:   listingRepo,
+		categoryRepo:  categoryRepo,
+		eventBus:      eventBus,
+		indexer:       indexer,
+	}
+}
+
+// Submit queues a bulk operation for the worker and returns it pending
+func (s *BulkOperationService) Submit(ctx context.Context, cmd SubmitBulkOperationCommand) (*domain.BulkOperation, error) {
+	if cmd.Kind == domain.BulkReindexCategory && s.indexer == nil {
+		return nil, errors.ValidationError("search index is not configured")
+	}
+
+	var operation *domain.BulkOperation
+	var err error
+	if cmd.Kind.SelectsListings() {
+		selection := domain.BulkListingSelection{ListingIDs: cmd.ListingIDs, Filter: cmd.Filter}
+		operation, err = domain.NewListingsBulkOperation(cmd.Kind, selection, cmd.CategoryID, cmd.AdminID, cmd.Reason, time.Now())
+	} else {
+		operation, err = domain.NewBulkOperation(cmd.Kind, cmd.TargetID, cmd.AdminID, cmd.Reason, time.Now())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if operation.Kind == domain.BulkMoveCategory {
+		if _, err := s.categoryRepo.FindByID(operation.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+	if err := db.WithRetry(ctx, func() error { return s.operationRepo.Save(operation) }); err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
+
+// GetOperation retrieves a bulk operation with its progress
+func (s *BulkOperationService) GetOperation(ctx context.Context, operationID string) (*domain.BulkOperation, error) {
+	return s.operationRepo.FindByID(operationID)
+}
+
+// ListOperations lists bulk operations, newest first
+func (s *BulkOperationService) ListOperations(ctx context.Context, query ListBulkOperationsQuery) (*BulkOperations, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	operations, total, err := s.operationRepo.FindAll(query.Status, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &BulkOperations{
+		Operations: operations,
+		Total:      total,
+		Limit:      limit,
+		Offset:     query.Offset,
+	}, nil
+}
+
+// ListItems lists the per-listing results of a bulk operation
+func (s *BulkOperationService) ListItems(ctx context.Context, operationID string, query ListBulkOperationItemsQuery) (*BulkOperationItems, error) {
+	if _, err := s.operationRepo.FindByID(operationID); err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	items, total, err := s.operationRepo.FindItems(operationID, query.Status, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &BulkOperationItems{
+		Items:  items,
+		Total:  total,
+		Limit:  limit,
+		Offset: query.Offset,
+	}, nil
+}
+
+// Cancel stops a pending or running bulk operation. The batch being worked on
+// finishes, and listings already processed keep their changes.
+func (s *BulkOperationService) Cancel(ctx context.Context, operationID string, adminID ids.UserID) (*domain.BulkOperation, error) {
+	operation, err := s.operationRepo.FindByID(operationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := operation.Cancel(adminID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var cancelled bool
+	err = db.WithRetry(ctx, func() error {
+		var err error
+		cancelled, err = s.operationRepo.SaveCancellation(operation)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, errors.ConflictError("bulk operation has already finished")
+	}
+	return operation, nil
+}
+
+// RunOperations works on the oldest unfinished bulk operations, starting
+// pending ones and processing up to batchSize listings of each. It returns
+// how many listings were processed; operations not finished are picked up
+// again on the next run.
+func (s *BulkOperationService) RunOperations(ctx context.Context, batchSize int) (int, error) {
+	operations, err := s.operationRepo.FindUnfinished(bulkOperationsPerRun)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, operation := range operations {
+		count, err := s.runOperation(ctx, operation, batchSize)
+		processed += count
+		if err != nil {
+			return processed, err
+		}
+	}
+	return processed, nil
+}
+
+// runOperation starts an operation if it is pending, then processes a batch
+// of its listings and records its progress
+func (s *BulkOperationService) runOperation(ctx context.Context, operation *domain.BulkOperation, batchSize int) (int, error) {
+	if operation.Status == domain.BulkOperationPending {
+		started, err := s.start(ctx, operation)
+		if err != nil || !started {
+			return 0, err
+		}
+	}
+
+	items, err := s.operationRepo.FindPendingItems(operation.ID, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range items {
+		status, err := s.apply(ctx, operation, item.ListingID)
+		item.Finish(status, err, time.Now())
+		if err := db.WithRetry(ctx, func() error { return s.operationRepo.UpdateItem(item) }); err != nil {
+			return 0, err
+		}
+	}
+
+	counts, err := s.operationRepo.CountItems(operation.ID)
+	if err != nil {
+		return len(items), err
+	}
+	operation.RecordProgress(counts, time.Now())
+	err = db.WithRetry(ctx, func() error {
+		_, err := s.operationRepo.SaveProgress(operation)
+		return err
+	})
+	return len(items), err
+}
+
+// start fixes the listings a pending operation covers and marks it running.
+// It reports false if the operation was cancelled meanwhile.
+func (s *BulkOperationService) start(ctx context.Context, operation *domain.BulkOperation) (bool, error) {
+	listingIDs, err := s.coveredListings(operation)
+	if err != nil {
+		return false, err
+	}
+	if err := operation.Start(len(listingIDs), time.Now()); err != nil {
+		return false, err
+	}
+
+	items := domain.NewBulkOperationItems(operation.ID, listingIDs)
+	if err := db.WithRetry(ctx, func() error { return s.operationRepo.SaveItems(items) }); err != nil {
+		return false, err
+	}
+	var started bool
+	err = db.WithRetry(ctx, func() error {
+		var err error
+		started, err = s.operationRepo.SaveProgress(operation)
+		return err
+	})
+	return started, err
+}
+
+// coveredListings lists the IDs of the listings an operation applies to
+func (s *BulkOperationService) coveredListings(operation *domain.BulkOperation) ([]ids.ListingID, error) {
+	if operation.Kind.SelectsListings() {
+		return s.selectedListings(operation)
+	}
+
+	find := func(limit, offset int) ([]*domain.Listing, error) {
+		return s.listingRepo.FindBySeller(ids.UserID(operation.TargetID), limit, offset)
+	}
+	if operation.Kind == domain.BulkReindexCategory {
+		find = func(limit, offset int) ([]*domain.Listing, error) {
+			return s.listingRepo.FindByCategory(operation.TargetID, limit, offset)
+		}
+	}
+
+	var listingIDs []ids.ListingID
+	for offset := 0; ; offset += sellerBatchSize {
+		listings, err := find(sellerBatchSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, listing := range listings {
+			listingIDs = append(listingIDs, listing.ID)
+		}
+		if len(listings) < sellerBatchSize {
+			return listingIDs, nil
+		}
+	}
+}
+
+// selectedListings lists the IDs of the listings an operation picked, once
+// each. Listings picked by filter are those matching it when it starts.
+func (s *BulkOperationService) selectedListings(operation *domain.BulkOperation) ([]ids.ListingID, error) {
+	if operation.Filter == nil {
+		var listingIDs []ids.ListingID
+		seen := make(map[ids.ListingID]bool, len(operation.ListingIDs))
+		for _, listingID := range operation.ListingIDs {
+			if !seen[listingID] {
+				seen[listingID] = true
+				listingIDs = append(listingIDs, listingID)
+			}
+		}
+		return listingIDs, nil
+	}
+
+	var listingIDs []ids.ListingID
+	for offset := 0; ; offset += sellerBatchSize {
+		page, err := s.listingRepo.FindIDsByFilter(*operation.Filter, sellerBatchSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		listingIDs = append(listingIDs, page...)
+		if len(page) < sellerBatchSize {
+			return listingIDs, nil
+		}
+	}
+}
+
+// apply carries out an operation on one listing
+func (s *BulkOperationService) apply(ctx context.Context, operation *domain.BulkOperation, listingID ids.ListingID) (domain.BulkItemStatus, error) {
+	switch operation.Kind {
+	case domain.BulkSuspendSellerListings:
+		return s.changeListing(ctx, listingID, func(listing *domain.Listing) bool {
+			return listing.Hold(domain.ListingHoldAdminSuspended)
+		})
+	case domain.BulkRestoreSellerListings:
+		return s.changeListing(ctx, listingID, func(listing *domain.Listing) bool {
+			return listing.Release(domain.ListingHoldAdminSuspended)
+		})
+	case domain.BulkReindexCategory:
+		if s.indexer == nil {
+			return domain.BulkItemFailed, errors.UnavailableError("search index is not configured")
+		}
+		if err := s.indexer.IndexListing(ctx, listingID); err != nil {
+			return domain.BulkItemFailed, err
+		}
+		return domain.BulkItemSucceeded, nil
+	case domain.BulkDeactivateListings:
+		return s.deactivateListing(ctx, listingID)
+	case domain.BulkDeleteListings:
+		return s.deleteListing(ctx, operation, listingID)
+	case domain.BulkMoveCategory:
+		return s.moveListing(ctx, operation, listingID)
+	}
+	return domain.BulkItemFailed, errors.ValidationError("unknown bulk operation kind")
+}
+
+// changeListing applies a change to a listing, saving and announcing it if
+// the change reports the listing changed
+func (s *BulkOperationService) changeListing(ctx context.Context, listingID ids.ListingID, change func(*domain.Listing) bool) (domain.BulkItemStatus, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return domain.BulkItemFailed, err
+	}
+	if !change(listing) {
+		return domain.BulkItemSkipped, nil
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return domain.BulkItemFailed, err
+	}
+	if err := publishListingChanged(ctx, s.eventBus, listing); err != nil {
+		return domain.BulkItemFailed, err
+	}
+	return domain.BulkItemSucceeded, nil
+}
+
+// deactivateListing takes a listing off the marketplace as its seller would.
+// Listings that are not live are skipped, and listings a buyer is paying for
+// are left alone.
+func (s *BulkOperationService) deactivateListing(ctx context.Context, listingID ids.ListingID) (domain.BulkItemStatus, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return domain.BulkItemFailed, err
+	}
+	if listing.Status == domain.ListingStatusInactive || !listing.Status.CanTransitionTo(domain.ListingStatusInactive) {
+		return domain.BulkItemSkipped, nil
+	}
+	if listing.IsReserved() {
+		return domain.BulkItemFailed, errors.ConflictError("listing is reserved by a buyer")
+	}
+
+	if err := listing.Deactivate(); err != nil {
+		return domain.BulkItemFailed, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return domain.BulkItemFailed, err
+	}
+	err = s.publish(ctx, domain.ListingDeactivatedEvent, listing, domain.ListingDeactivated{
+		ListingID: listing.ID,
+		SellerID:  listing.SellerID,
+		Timestamp: listing.UpdatedAt,
+	})
+	if err != nil {
+		return domain.BulkItemFailed, err
+	}
+	return domain.BulkItemSucceeded, nil
+}
+
+// deleteListing deletes a listing for good. Listings already gone are
+// skipped, and listings a buyer is paying for are left alone.
+func (s *BulkOperationService) deleteListing(ctx context.Context, operation *domain.BulkOperation, listingID ids.ListingID) (domain.BulkItemStatus, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return domain.BulkItemSkipped, nil
+		}
+		return domain.BulkItemFailed, err
+	}
+	if listing.IsReserved() {
+		return domain.BulkItemFailed, errors.ConflictError("listing is reserved by a buyer")
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Delete(listing.ID) }); err != nil {
+		return domain.BulkItemFailed, err
+	}
+	err = s.publish(ctx, domain.ListingDeletedEvent, listing, domain.ListingDeleted{
+		ListingID: listing.ID,
+		SellerID:  listing.SellerID,
+		Reason:    operation.Reason,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return domain.BulkItemFailed, err
+	}
+	return domain.BulkItemSucceeded, nil
+}
+
+// moveListing moves a listing to the operation's category. Listings already
+// in it are skipped.
+func (s *BulkOperationService) moveListing(ctx context.Context, operation *domain.BulkOperation, listingID ids.ListingID) (domain.BulkItemStatus, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return domain.BulkItemFailed, err
+	}
+	if listing.CategoryID == operation.CategoryID {
+		return domain.BulkItemSkipped, nil
+	}
+
+	details := listing.Details()
+	details.CategoryID = operation.CategoryID
+	if err := listing.UpdateDetails(details); err != nil {
+		return domain.BulkItemFailed, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return domain.BulkItemFailed, err
+	}
+	err = s.publish(ctx, domain.ListingUpdatedEvent, listing, domain.ListingUpdated{
+		ListingID:  listing.ID,
+		SellerID:   listing.SellerID,
+		CategoryID: listing.CategoryID,
+		Title:      listing.Title,
+		Price:      listing.Price,
+		Status:     listing.Status,
+		Timestamp:  listing.UpdatedAt,
+	})
+	if err != nil {
+		return domain.BulkItemFailed, err
+	}
+	return domain.BulkItemSucceeded, nil
+}
+
+// publish publishes an event about a listing changed by an operation,
+// followed by ListingChanged so copies of the listing are refreshed
+func (s *BulkOperationService) publish(ctx context.Context, eventType string, listing *domain.Listing, data interface{}) error {
+	event, err := events.NewEvent(eventType, listing.ID.String(), data)
+	if err != nil {
+		return err
+	}
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return err
+	}
+	return publishListingChanged(ctx, s.eventBus, listing)
+}
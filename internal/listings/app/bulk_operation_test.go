@@ -0,0 +1,278 @@
+package app_test
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkOperationService_SuspendAndRestoreSellerListings(t *testing.T) {
+	phone := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	laptop := newActiveListing(t, "seller-a", "Laptop", domain.ConditionGood)
+	draft := newDraftListing(t, "seller-a", "Draft")
+	other := newActiveListing(t, "seller-b", "Other", domain.ConditionGood)
+	operations := newFakeBulkOperationRepository()
+	bus := &fakeEventBus{}
+	service := app.NewBulkOperationService(operations, newFakeListingRepository(phone, laptop, draft, other), nil, bus, nil)
+	ctx := context.Background()
+
+	operation, err := service.Submit(ctx, app.SubmitBulkOperationCommand{
+		AdminID:  "admin-1",
+		Kind:     domain.BulkSuspendSellerListings,
+		TargetID: "seller-a",
+		Reason:   "fraud report",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkOperationPending, operation.Status)
+
+	// The worker starts the operation and works through it a batch at a time
+	processed, err := service.RunOperations(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	progress, err := service.GetOperation(ctx, operation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkOperationRunning, progress.Status)
+	assert.Equal(t, 3, progress.Total)
+	assert.Equal(t, 2, progress.Processed)
+
+	processed, err = service.RunOperations(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	progress, err = service.GetOperation(ctx, operation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkOperationCompleted, progress.Status)
+	assert.Equal(t, 2, progress.Succeeded)
+	assert.Equal(t, 1, progress.Skipped, "the draft was not live")
+
+	assert.True(t, phone.IsHeld())
+	assert.True(t, laptop.IsHeld())
+	assert.False(t, other.IsHeld())
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 2)
+
+	skipped, err := service.ListItems(ctx, operation.ID, app.ListBulkOperationItemsQuery{Status: domain.BulkItemSkipped})
+	require.NoError(t, err)
+	require.Len(t, skipped.Items, 1)
+	assert.Equal(t, draft.ID, skipped.Items[0].ListingID)
+
+	// Restoring puts the suspended listings back live
+	_, err = service.Submit(ctx, app.SubmitBulkOperationCommand{
+		AdminID:  "admin-1",
+		Kind:     domain.BulkRestoreSellerListings,
+		TargetID: "seller-a",
+	})
+	require.NoError(t, err)
+	_, err = service.RunOperations(ctx, 10)
+	require.NoError(t, err)
+	assert.True(t, phone.IsActive())
+	assert.True(t, laptop.IsActive())
+
+	list, err := service.ListOperations(ctx, app.ListBulkOperationsQuery{Status: domain.BulkOperationCompleted})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), list.Total)
+	assert.Equal(t, 20, list.Limit)
+}
+
+func TestBulkOperationService_Cancel(t *testing.T) {
+	var listings []*domain.Listing
+	for i := 0; i < 3; i++ {
+		listings = append(listings, newActiveListing(t, "seller-a", "Phone", domain.ConditionGood))
+	}
+	service := app.NewBulkOperationService(newFakeBulkOperationRepository(), newFakeListingRepository(listings...), nil, &fakeEventBus{}, nil)
+	ctx := context.Background()
+
+	operation, err := service.Submit(ctx, app.SubmitBulkOperationCommand{
+		AdminID:  "admin-1",
+		Kind:     domain.BulkSuspendSellerListings,
+		TargetID: "seller-a",
+	})
+	require.NoError(t, err)
+	processed, err := service.RunOperations(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	cancelled, err := service.Cancel(ctx, operation.ID, "admin-2")
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkOperationCancelled, cancelled.Status)
+
+	// The worker stops, and the listings processed keep their changes
+	processed, err = service.RunOperations(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+	held := 0
+	for _, listing := range listings {
+		if listing.IsHeld() {
+			held++
+		}
+	}
+	assert.Equal(t, 1, held)
+
+	_, err = service.Cancel(ctx, operation.ID, "admin-2")
+	assert.Error(t, err, "finished operations cannot be cancelled")
+	_, err = service.Cancel(ctx, "missing-operation", "admin-2")
+	assert.Error(t, err)
+}
+
+func TestBulkOperationService_ReindexCategory(t *testing.T) {
+	phone := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	laptop := newActiveListing(t, "seller-b", "Laptop", domain.ConditionGood)
+	laptop.CategoryID = "category-2"
+	repo := newFakeListingRepository(phone, laptop)
+	ctx := context.Background()
+	cmd := app.SubmitBulkOperationCommand{
+		AdminID:  "admin-1",
+		Kind:     domain.BulkReindexCategory,
+		TargetID: "category-1",
+	}
+
+	// Without a search index there is nothing to reindex
+	_, err := app.NewBulkOperationService(newFakeBulkOperationRepository(), repo, nil, &fakeEventBus{}, nil).Submit(ctx, cmd)
+	assert.Error(t, err)
+
+	indexer := &fakeListingIndexer{}
+	service := app.NewBulkOperationService(newFakeBulkOperationRepository(), repo, nil, &fakeEventBus{}, indexer)
+	operation, err := service.Submit(ctx, cmd)
+	require.NoError(t, err)
+	_, err = service.RunOperations(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []ids.ListingID{phone.ID}, indexer.indexed)
+
+	// Failures are recorded per listing rather than failing the run
+	indexer.err = stderrors.New("cluster unreachable")
+	failing, err := service.Submit(ctx, cmd)
+	require.NoError(t, err)
+	_, err = service.RunOperations(ctx, 10)
+	require.NoError(t, err)
+	progress, err := service.GetOperation(ctx, failing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkOperationCompleted, progress.Status)
+	assert.Equal(t, 1, progress.Failed)
+	items, err := service.ListItems(ctx, failing.ID, app.ListBulkOperationItemsQuery{})
+	require.NoError(t, err)
+	require.Len(t, items.Items, 1)
+	assert.Equal(t, "cluster unreachable", items.Items[0].Error)
+
+	progress, err = service.GetOperation(ctx, operation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, progress.Succeeded)
+}
+
+func TestBulkOperationService_DeactivateListingsByID(t *testing.T) {
+	phone := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	reserved := newActiveListing(t, "seller-a", "Laptop", domain.ConditionGood)
+	require.NoError(t, reserved.Reserve("buyer-1", time.Now().Add(time.Hour), time.Now()))
+	draft := newDraftListing(t, "seller-b", "Draft")
+	bus := &fakeEventBus{}
+	service := app.NewBulkOperationService(newFakeBulkOperationRepository(), newFakeListingRepository(phone, reserved, draft), nil, bus, nil)
+	ctx := context.Background()
+
+	_, err := service.Submit(ctx, app.SubmitBulkOperationCommand{AdminID: "admin-1", Kind: domain.BulkDeactivateListings})
+	assert.Error(t, err, "listings are picked by ID or filter")
+
+	operation, err := service.Submit(ctx, app.SubmitBulkOperationCommand{
+		AdminID:    "admin-1",
+		Kind:       domain.BulkDeactivateListings,
+		ListingIDs: []ids.ListingID{phone.ID, reserved.ID, draft.ID, phone.ID},
+	})
+	require.NoError(t, err)
+	_, err = service.RunOperations(ctx, 10)
+	require.NoError(t, err)
+
+	progress, err := service.GetOperation(ctx, operation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkOperationCompleted, progress.Status)
+	assert.Equal(t, 3, progress.Total, "listings picked twice are covered once")
+	assert.Equal(t, 1, progress.Succeeded)
+	assert.Equal(t, 1, progress.Skipped, "the draft was not live")
+	assert.Equal(t, 1, progress.Failed, "the buyer paying for the laptop keeps it")
+
+	assert.Equal(t, domain.ListingStatusInactive, phone.Status)
+	assert.True(t, reserved.IsActive())
+	assert.Len(t, bus.eventsOfType(domain.ListingDeactivatedEvent), 1)
+	assert.Len(t, bus.eventsOfType(domain.ListingStatusChangedEvent), 1)
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 1)
+}
+
+func TestBulkOperationService_DeleteListingsByFilter(t *testing.T) {
+	sold := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	require.NoError(t, sold.MarkAsSold())
+	live := newActiveListing(t, "seller-a", "Laptop", domain.ConditionGood)
+	other := newActiveListing(t, "seller-b", "Tablet", domain.ConditionGood)
+	require.NoError(t, other.MarkAsSold())
+	repo := newFakeListingRepository(sold, live, other)
+	bus := &fakeEventBus{}
+	service := app.NewBulkOperationService(newFakeBulkOperationRepository(), repo, nil, bus, nil)
+	ctx := context.Background()
+
+	_, err := service.Submit(ctx, app.SubmitBulkOperationCommand{
+		AdminID: "admin-1",
+		Kind:    domain.BulkDeleteListings,
+		Filter:  &domain.BulkListingFilter{},
+	})
+	assert.Error(t, err, "a filter matching every listing is refused")
+
+	_, err = service.Submit(ctx, app.SubmitBulkOperationCommand{
+		AdminID: "admin-1",
+		Kind:    domain.BulkDeleteListings,
+		Filter:  &domain.BulkListingFilter{SellerID: "seller-a", Status: domain.ListingStatusSold},
+		Reason:  "spam account",
+	})
+	require.NoError(t, err)
+	_, err = service.RunOperations(ctx, 10)
+	require.NoError(t, err)
+
+	_, err = repo.FindByID(sold.ID)
+	assert.Error(t, err)
+	_, err = repo.FindByID(live.ID)
+	assert.NoError(t, err)
+	_, err = repo.FindByID(other.ID)
+	assert.NoError(t, err)
+	deleted := bus.eventsOfType(domain.ListingDeletedEvent)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, sold.ID.String(), deleted[0].AggregateID)
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 1, "the search index drops the listing")
+}
+
+func TestBulkOperationService_MoveCategory(t *testing.T) {
+	phone := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	laptop := newActiveListing(t, "seller-b", "Laptop", domain.ConditionGood)
+	moved := newActiveListing(t, "seller-b", "Tablet", domain.ConditionGood)
+	moved.CategoryID = "category-2"
+	categories := newFakeCategoryRepository(&domain.Category{ID: "category-2", Name: "Phones", IsActive: true})
+	bus := &fakeEventBus{}
+	service := app.NewBulkOperationService(newFakeBulkOperationRepository(), newFakeListingRepository(phone, laptop, moved), categories, bus, nil)
+	ctx := context.Background()
+
+	cmd := app.SubmitBulkOperationCommand{
+		AdminID:    "admin-1",
+		Kind:       domain.BulkMoveCategory,
+		ListingIDs: []ids.ListingID{phone.ID, laptop.ID, moved.ID},
+	}
+	_, err := service.Submit(ctx, cmd)
+	assert.Error(t, err, "the category to move to is required")
+	cmd.CategoryID = "missing-category"
+	_, err = service.Submit(ctx, cmd)
+	assert.Error(t, err)
+
+	cmd.CategoryID = "category-2"
+	operation, err := service.Submit(ctx, cmd)
+	require.NoError(t, err)
+	_, err = service.RunOperations(ctx, 10)
+	require.NoError(t, err)
+
+	progress, err := service.GetOperation(ctx, operation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, progress.Succeeded)
+	assert.Equal(t, 1, progress.Skipped, "the tablet was already there")
+	assert.Equal(t, "category-2", phone.CategoryID)
+	assert.Equal(t, "category-2", laptop.CategoryID)
+	assert.Len(t, bus.eventsOfType(domain.ListingUpdatedEvent), 2)
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 2)
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// listingDetailTTL is how long a listing detail stays cached
+const listingDetailTTL = 15 * time.Minute
+
+// EdgeWarmer primes CDN edge caches for public asset URLs. It returns the
+// number of URLs that were primed.
+type EdgeWarmer interface {
+	Warm(ctx context.Context, urls []string) (int, error)
+}
+
+// WarmSummary reports the outcome of a cache warming run
+type WarmSummary struct {
+	Warmed       int `json:"warmed"`
+	Evicted      int `json:"evicted"`
+	ImagesWarmed int `json:"images_warmed"`
+	ImagesFailed int `json:"images_failed"`
+}
+
+// ListingCacheService serves listing details through a read-through cache
+// and warms it for listings that are about to draw traffic
+type ListingCacheService struct {
+	listingRepo domain.ListingRepository
+	cache       cache.Cache
+	edges       EdgeWarmer
+}
+
+// NewListingCacheService creates a new listing cache service
+func NewListingCacheService(listingRepo domain.ListingRepository, cache cache.Cache, edges EdgeWarmer) *ListingCacheService {
+	return &ListingCacheService{
+		listingRepo: listingRepo,
+		cache:       cache,
+		edges:       edges,
+	}
+}
+
+// ListingDetailKey returns the cache key of a listing's detail
+func ListingDetailKey(listingID ids.ListingID) string {
+	return "listing:detail:" + listingID.String()
+}
+
+// GetListing returns an active listing, from the cache when possible. Cache
+// failures fall back to the database.
+func (s *ListingCacheService) GetListing(ctx context.Context, listingID ids.ListingID) (*domain.Listing, error) {
+	var cached domain.Listing
+	if err := s.cache.Get(ctx, ListingDetailKey(listingID), &cached); err == nil && cached.IsActive() {
+		return &cached, nil
+	}
+
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if !listing.IsActive() {
+		return nil, errors.NotFoundError("listing not found")
+	}
+
+	_ = s.cache.Set(ctx, ListingDetailKey(listing.ID), listing, listingDetailTTL)
+	return listing, nil
+}
+
+// WarmListings caches the details of the given listings and primes the CDN
+// edges for their images. Listings that are gone or no longer active are
+// evicted instead. Image failures are counted but do not fail the run.
+func (s *ListingCacheService) WarmListings(ctx context.Context, listingIDs []ids.ListingID) (*WarmSummary, error) {
+	summary := &WarmSummary{}
+	for _, listingID := range listingIDs {
+		listing, err := s.listingRepo.FindByID(listingID)
+		if err != nil {
+			if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeNotFound {
+				return summary, err
+			}
+		}
+
+		if listing == nil || !listing.IsActive() {
+			if err := s.cache.Delete(ctx, ListingDetailKey(listingID)); err != nil {
+				return summary, err
+			}
+			summary.Evicted++
+			continue
+		}
+
+		if err := s.cache.Set(ctx, ListingDetailKey(listing.ID), listing, listingDetailTTL); err != nil {
+			return summary, err
+		}
+		summary.Warmed++
+
+		urls := make([]string, 0, len(listing.Images))
+		for _, image := range listing.Images {
+			urls = append(urls, image.URL)
+		}
+		if len(urls) == 0 {
+			continue
+		}
+
+		warmed, _ := s.edges.Warm(ctx, urls)
+		summary.ImagesWarmed += warmed
+		summary.ImagesFailed += len(urls) - warmed
+	}
+
+	return summary, nil
+}
+
+// InvalidateListing removes a listing's detail from the cache
+func (s *ListingCacheService) InvalidateListing(ctx context.Context, listingID ids.ListingID) error {
+	return s.cache.Delete(ctx, ListingDetailKey(listingID))
+}
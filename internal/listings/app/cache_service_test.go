@@ -0,0 +1,65 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListingCacheService_WarmListings(t *testing.T) {
+	promoted := newActiveListing(t, "seller-a", "Promoted phone", domain.ConditionNew)
+	promoted.Images = []domain.ListingImage{
+		{URL: "https://cdn.example.com/a.jpg"},
+		{URL: "https://cdn.example.com/b.jpg"},
+	}
+	draft, err := domain.NewListing("seller-a", "category-1", "Draft", "", money.Cedis(50), domain.ConditionNew, domain.Location{})
+	require.NoError(t, err)
+
+	store := newFakeCache()
+	require.NoError(t, store.Set(context.Background(), app.ListingDetailKey(draft.ID), draft, 0))
+
+	edges := &fakeEdgeWarmer{failing: map[string]bool{"https://cdn.example.com/b.jpg": true}}
+	service := app.NewListingCacheService(newFakeListingRepository(promoted, draft), store, edges)
+
+	summary, err := service.WarmListings(context.Background(), []ids.ListingID{promoted.ID, draft.ID, "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Warmed)
+	assert.Equal(t, 2, summary.Evicted)
+	assert.Equal(t, 1, summary.ImagesWarmed)
+	assert.Equal(t, 1, summary.ImagesFailed)
+
+	assert.True(t, store.has(app.ListingDetailKey(promoted.ID)))
+	assert.False(t, store.has(app.ListingDetailKey(draft.ID)))
+	assert.Equal(t, []string{"https://cdn.example.com/a.jpg"}, edges.warmed)
+}
+
+func TestListingCacheService_GetListing(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	repo := newFakeListingRepository(listing)
+	store := newFakeCache()
+	service := app.NewListingCacheService(repo, store, &fakeEdgeWarmer{})
+	ctx := context.Background()
+
+	// A miss loads from the repository and fills the cache
+	found, err := service.GetListing(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, listing.ID, found.ID)
+	assert.True(t, store.has(app.ListingDetailKey(listing.ID)))
+
+	// A hit is served without the repository
+	require.NoError(t, repo.Delete(listing.ID))
+	cached, err := service.GetListing(ctx, listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, listing.Title, cached.Title)
+
+	require.NoError(t, service.InvalidateListing(ctx, listing.ID))
+	_, err = service.GetListing(ctx, listing.ID)
+	assert.Error(t, err)
+}
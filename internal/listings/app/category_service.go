@@ -0,0 +1,265 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+)
+
+// categoryTreeKey is the cache key of the public category tree in a locale
+func categoryTreeKey(locale i18n.Locale) string {
+	return "categories:tree:" + string(locale)
+}
+
+// categoryTreeTTL is how long the public category tree stays cached. Changes
+// made through CategoryService evict it straight away.
+const categoryTreeTTL = time.Hour
+
+// CreateCategoryCommand represents the command to add a category
+type CreateCategoryCommand struct {
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	ImageURL    string  `json:"image_url" binding:"omitempty,url"`
+	ParentID    *string `json:"parent_id" binding:"omitempty,uuid"`
+}
+
+// UpdateCategoryCommand represents the command to change a category. Fields
+// left out are not changed; an empty parent_id moves the category to the root.
+type UpdateCategoryCommand struct {
+	CategoryID  string  `json:"-"`
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	ImageURL    *string `json:"image_url" binding:"omitempty,url"`
+	ParentID    *string `json:"parent_id" binding:"omitempty,uuid"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// ReorderCategoriesCommand represents the command to order the subcategories
+// of a parent, or the root categories when ParentID is empty
+type ReorderCategoriesCommand struct {
+	ParentID    *string  `json:"parent_id" binding:"omitempty,uuid"`
+	CategoryIDs []string `json:"category_ids" binding:"required,min=1,dive,uuid"`
+}
+
+// CategoryService handles category hierarchy use cases
+type CategoryService struct {
+	categoryRepo domain.CategoryRepository
+	translations *TranslationService
+	cache        cache.Cache
+}
+
+// NewCategoryService creates a new category service. Without translations,
+// category trees are always in the default locale.
+func NewCategoryService(categoryRepo domain.CategoryRepository, translations *TranslationService, cache cache.Cache) *CategoryService {
+	return &CategoryService{
+		categoryRepo: categoryRepo,
+		translations: translations,
+		cache:        cache,
+	}
+}
+
+// CategoryTree returns the active categories nested under their parents, with
+// their names in the given locale, from the cache when possible. Cache
+// failures fall back to the database.
+func (s *CategoryService) CategoryTree(ctx context.Context, locale i18n.Locale) ([]*domain.CategoryNode, error) {
+	var cached []*domain.CategoryNode
+	if err := s.cache.Get(ctx, categoryTreeKey(locale), &cached); err == nil {
+		return cached, nil
+	}
+
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	tree := domain.BuildCategoryTree(categories, false)
+	if s.translations != nil {
+		translations, err := s.translations.Translations(ctx, locale)
+		if err != nil {
+			return nil, err
+		}
+		translations.LocalizeTree(tree)
+	}
+
+	_ = s.cache.Set(ctx, categoryTreeKey(locale), tree, categoryTreeTTL)
+	return tree, nil
+}
+
+// FullCategoryTree returns every category, including inactive ones, for
+// administrators. It is never cached.
+func (s *CategoryService) FullCategoryTree(ctx context.Context) ([]*domain.CategoryNode, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return domain.BuildCategoryTree(categories, true), nil
+}
+
+// CreateCategory adds a category after its future siblings
+func (s *CategoryService) CreateCategory(ctx context.Context, cmd CreateCategoryCommand) (*domain.Category, error) {
+	parentID := emptyToNil(cmd.ParentID)
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	if parentID != nil && findCategory(categories, *parentID) == nil {
+		return nil, errors.NotFoundError("parent category not found")
+	}
+
+	category, err := domain.NewCategory(cmd.Name, cmd.Description, cmd.ImageURL, parentID, nextCategoryPosition(categories, parentID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.categoryRepo.Save(category) }); err != nil {
+		return nil, err
+	}
+	invalidateLocalized(ctx, s.cache)
+	return category, nil
+}
+
+// UpdateCategory changes a category. Moving it under another parent places it
+// after its new siblings and is refused if it would create a cycle.
+func (s *CategoryService) UpdateCategory(ctx context.Context, cmd UpdateCategoryCommand) (*domain.Category, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	category := findCategory(categories, cmd.CategoryID)
+	if category == nil {
+		return nil, errors.NotFoundError("category not found")
+	}
+
+	name, description, imageURL := category.Name, category.Description, category.ImageURL
+	if cmd.Name != nil {
+		name = *cmd.Name
+	}
+	if cmd.Description != nil {
+		description = *cmd.Description
+	}
+	if cmd.ImageURL != nil {
+		imageURL = *cmd.ImageURL
+	}
+	if err := category.Rename(name, description, imageURL); err != nil {
+		return nil, err
+	}
+
+	if cmd.ParentID != nil {
+		parentID := emptyToNil(cmd.ParentID)
+		if !category.HasParent(parentID) {
+			if parentID != nil && findCategory(categories, *parentID) == nil {
+				return nil, errors.NotFoundError("parent category not found")
+			}
+			if err := category.MoveTo(parentID, categories); err != nil {
+				return nil, err
+			}
+			category.SetPosition(nextCategoryPosition(categories, parentID))
+		}
+	}
+
+	if cmd.IsActive != nil {
+		category.SetActive(*cmd.IsActive)
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.categoryRepo.Update(category) }); err != nil {
+		return nil, err
+	}
+	invalidateLocalized(ctx, s.cache)
+	return category, nil
+}
+
+// DeactivateCategory hides a category and everything under it from sellers
+// and buyers. Listings already in it stay, but cannot be published until they
+// move to an active category.
+func (s *CategoryService) DeactivateCategory(ctx context.Context, categoryID string) (*domain.Category, error) {
+	category, err := s.categoryRepo.FindByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if !category.IsActive {
+		return category, nil
+	}
+
+	category.SetActive(false)
+	if err := db.WithRetry(ctx, func() error { return s.categoryRepo.Update(category) }); err != nil {
+		return nil, err
+	}
+	invalidateLocalized(ctx, s.cache)
+	return category, nil
+}
+
+// ReorderCategories sets the order of a parent's subcategories. Every
+// subcategory of the parent must be listed exactly once.
+func (s *CategoryService) ReorderCategories(ctx context.Context, cmd ReorderCategoriesCommand) ([]*domain.Category, error) {
+	parentID := emptyToNil(cmd.ParentID)
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	siblings := make(map[string]*domain.Category)
+	for _, category := range categories {
+		if category.HasParent(parentID) {
+			siblings[category.ID] = category
+		}
+	}
+	if len(cmd.CategoryIDs) != len(siblings) {
+		return nil, errors.ValidationError("category_ids must list every subcategory of the parent exactly once")
+	}
+
+	ordered := make([]*domain.Category, 0, len(cmd.CategoryIDs))
+	seen := make(map[string]bool, len(cmd.CategoryIDs))
+	for _, id := range cmd.CategoryIDs {
+		category, ok := siblings[id]
+		if !ok || seen[id] {
+			return nil, errors.ValidationError("category_ids must list every subcategory of the parent exactly once")
+		}
+		seen[id] = true
+		ordered = append(ordered, category)
+	}
+
+	for position, category := range ordered {
+		if category.Position == position {
+			continue
+		}
+		category.SetPosition(position)
+		if err := db.WithRetry(ctx, func() error { return s.categoryRepo.Update(category) }); err != nil {
+			return nil, err
+		}
+	}
+	invalidateLocalized(ctx, s.cache)
+	return ordered, nil
+}
+
+// findCategory returns the category with the given ID, or nil
+func findCategory(categories []*domain.Category, id string) *domain.Category {
+	for _, category := range categories {
+		if category.ID == id {
+			return category
+		}
+	}
+	return nil
+}
+
+// nextCategoryPosition returns the position after the last subcategory of a parent
+func nextCategoryPosition(categories []*domain.Category, parentID *string) int {
+	next := 0
+	for _, category := range categories {
+		if category.HasParent(parentID) && category.Position >= next {
+			next = category.Position + 1
+		}
+	}
+	return next
+}
+
+// emptyToNil treats an empty ID as no ID
+func emptyToNil(id *string) *string {
+	if id == nil || *id == "" {
+		return nil
+	}
+	return id
+}
@@ -0,0 +1,145 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryService_CachesTreeUntilChanged(t *testing.T) {
+	repo := newFakeCategoryRepository()
+	cache := newFakeCache()
+	service := app.NewCategoryService(repo, nil, cache)
+	ctx := context.Background()
+
+	electronics, err := service.CreateCategory(ctx, app.CreateCategoryCommand{Name: "Electronics"})
+	require.NoError(t, err)
+	phones, err := service.CreateCategory(ctx, app.CreateCategoryCommand{Name: "Phones", ParentID: &electronics.ID})
+	require.NoError(t, err)
+	laptops, err := service.CreateCategory(ctx, app.CreateCategoryCommand{Name: "Laptops", ParentID: &electronics.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, laptops.Position, "new categories go after their siblings")
+
+	missing := "00000000-0000-0000-0000-000000000000"
+	_, err = service.CreateCategory(ctx, app.CreateCategoryCommand{Name: "Tablets", ParentID: &missing})
+	assert.Error(t, err)
+
+	tree, err := service.CategoryTree(ctx, i18n.DefaultLocale)
+	require.NoError(t, err)
+	require.Len(t, tree, 1)
+	assert.Equal(t, 2, tree[0].ChildCount)
+	assert.Equal(t, phones.ID, tree[0].Children[0].ID)
+	assert.True(t, cache.has("categories:tree:en"))
+
+	// Reordering evicts the cached tree
+	_, err = service.ReorderCategories(ctx, app.ReorderCategoriesCommand{ParentID: &electronics.ID, CategoryIDs: []string{laptops.ID, phones.ID}})
+	require.NoError(t, err)
+	assert.False(t, cache.has("categories:tree:en"))
+
+	tree, err = service.CategoryTree(ctx, i18n.DefaultLocale)
+	require.NoError(t, err)
+	assert.Equal(t, laptops.ID, tree[0].Children[0].ID)
+
+	// Deactivated categories drop out of the public tree but not the admin one
+	_, err = service.DeactivateCategory(ctx, phones.ID)
+	require.NoError(t, err)
+	tree, err = service.CategoryTree(ctx, i18n.DefaultLocale)
+	require.NoError(t, err)
+	assert.Equal(t, 1, tree[0].ChildCount)
+
+	full, err := service.FullCategoryTree(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, full[0].ChildCount)
+}
+
+func TestCategoryService_UpdateAndReorderValidation(t *testing.T) {
+	service := app.NewCategoryService(newFakeCategoryRepository(), nil, newFakeCache())
+	ctx := context.Background()
+
+	electronics, err := service.CreateCategory(ctx, app.CreateCategoryCommand{Name: "Electronics"})
+	require.NoError(t, err)
+	phones, err := service.CreateCategory(ctx, app.CreateCategoryCommand{Name: "Phones", ParentID: &electronics.ID})
+	require.NoError(t, err)
+	vehicles, err := service.CreateCategory(ctx, app.CreateCategoryCommand{Name: "Vehicles"})
+	require.NoError(t, err)
+
+	// A category cannot be moved under its own subcategory
+	_, err = service.UpdateCategory(ctx, app.UpdateCategoryCommand{CategoryID: electronics.ID, ParentID: &phones.ID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be moved under itself")
+
+	// An empty parent moves the category to the root, after the other roots
+	root := ""
+	name := "Mobile Phones"
+	moved, err := service.UpdateCategory(ctx, app.UpdateCategoryCommand{CategoryID: phones.ID, Name: &name, ParentID: &root})
+	require.NoError(t, err)
+	assert.Nil(t, moved.ParentID)
+	assert.Equal(t, "Mobile Phones", moved.Name)
+	assert.Equal(t, 2, moved.Position)
+
+	// Reordering must name every sibling exactly once
+	_, err = service.ReorderCategories(ctx, app.ReorderCategoriesCommand{CategoryIDs: []string{vehicles.ID, electronics.ID}})
+	assert.Error(t, err)
+	_, err = service.ReorderCategories(ctx, app.ReorderCategoriesCommand{CategoryIDs: []string{vehicles.ID, vehicles.ID, electronics.ID}})
+	assert.Error(t, err)
+	ordered, err := service.ReorderCategories(ctx, app.ReorderCategoriesCommand{CategoryIDs: []string{phones.ID, vehicles.ID, electronics.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, ordered[0].Position)
+	assert.Equal(t, 2, electronics.Position)
+}
+
+func TestCategoryService_TranslatesTree(t *testing.T) {
+	categoryRepo := newFakeCategoryRepository()
+	cache := newFakeCache()
+	translations := app.NewTranslationService(&fakeTranslationRepository{}, categoryRepo, cache)
+	service := app.NewCategoryService(categoryRepo, translations, cache)
+	ctx := context.Background()
+
+	electronics, err := service.CreateCategory(ctx, app.CreateCategoryCommand{Name: "Electronics"})
+	require.NoError(t, err)
+	phones, err := service.CreateCategory(ctx, app.CreateCategoryCommand{Name: "Phones", ParentID: &electronics.ID})
+	require.NoError(t, err)
+
+	_, err = translations.SetCategoryTranslation(ctx, app.SetCategoryTranslationCommand{CategoryID: phones.ID, Locale: "fr", Name: "Téléphones"})
+	require.NoError(t, err)
+	_, err = translations.SetCategoryTranslation(ctx, app.SetCategoryTranslationCommand{CategoryID: phones.ID, Locale: "en", Name: "Mobiles"})
+	assert.Error(t, err, "default names are set on the category")
+
+	tree, err := service.CategoryTree(ctx, "fr")
+	require.NoError(t, err)
+	assert.Equal(t, "Electronics", tree[0].Name, "untranslated categories keep their default name")
+	assert.Equal(t, "Téléphones", tree[0].Children[0].Name)
+	assert.True(t, cache.has("categories:tree:fr"))
+
+	tree, err = service.CategoryTree(ctx, i18n.DefaultLocale)
+	require.NoError(t, err)
+	assert.Equal(t, "Phones", tree[0].Children[0].Name)
+
+	// Translating evicts the cached trees of every locale
+	_, err = translations.SetCategoryTranslation(ctx, app.SetCategoryTranslationCommand{CategoryID: electronics.ID, Locale: "fr", Name: "Électronique"})
+	require.NoError(t, err)
+	assert.False(t, cache.has("categories:tree:fr"))
+	assert.False(t, cache.has("categories:tree:en"))
+	tree, err = service.CategoryTree(ctx, "fr")
+	require.NoError(t, err)
+	assert.Equal(t, "Électronique", tree[0].Name)
+
+	// Listings show their category and attributes in the locale, falling back
+	// to the default label
+	_, err = translations.SetAttributeLabel(ctx, app.SetAttributeLabelCommand{Key: "RAM", Locale: "en", Label: "Memory"})
+	require.NoError(t, err)
+	_, err = translations.SetAttributeLabel(ctx, app.SetAttributeLabelCommand{Key: "color", Locale: "fr", Label: "Couleur"})
+	require.NoError(t, err)
+	listing := &domain.Listing{CategoryID: phones.ID, Attributes: []domain.ListingAttribute{{Key: "ram"}, {Key: "color"}, {Key: "storage"}}}
+	translations.LocalizeListings(ctx, "fr", listing)
+	assert.Equal(t, "Téléphones", listing.CategoryName)
+	assert.Equal(t, "Memory", listing.Attributes[0].Label)
+	assert.Equal(t, "Couleur", listing.Attributes[1].Label)
+	assert.Equal(t, "storage", listing.Attributes[2].Label)
+}
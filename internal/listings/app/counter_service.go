@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// pendingViewsKey is the hash buffering view counts by listing until the
+// worker flushes them
+const pendingViewsKey = "listing:views:pending"
+
+// viewedKeyPrefix prefixes the cache keys marking that a viewer has just
+// viewed a listing
+const viewedKeyPrefix = "listing:viewed:"
+
+// CounterService maintains the view and favorite counters of listings from
+// events, so counting never writes to the listing itself
+type CounterService struct {
+	counterRepo domain.ListingCounterRepository
+	cache       cache.Cache
+	views       cache.Counters
+	viewWindow  time.Duration
+	clock       clock.Clock
+}
+
+// NewCounterService creates a new counter service. With a cache, a viewer's
+// views of a listing count once per viewWindow. With views, view counts are
+// buffered there and written by FlushViews instead of one at a time.
+func NewCounterService(counterRepo domain.ListingCounterRepository, cache cache.Cache, views cache.Counters, viewWindow time.Duration, clk clock.Clock) *CounterService {
+	return &CounterService{
+		counterRepo: counterRepo,
+		cache:       cache,
+		views:       views,
+		viewWindow:  viewWindow,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// RecordView counts a view of a listing by a viewer, unless the viewer has
+// viewed it within the view window. Views without a viewer always count. It
+// reports whether the view was written to the listing's counters, rather
+// than skipped as a repeat or buffered for FlushViews. A redelivered event
+// may count twice; view counts are a popularity signal, not a ledger.
+func (s *CounterService) RecordView(ctx context.Context, listingID ids.ListingID, viewerKey string, at time.Time) (bool, error) {
+	if listingID == "" {
+		return false, errors.ValidationError("listing ID is required")
+	}
+
+	if s.cache != nil && viewerKey != "" && s.viewWindow > 0 {
+		first, err := s.cache.SetNX(ctx, viewedKeyPrefix+listingID.String()+":"+viewerKey, true, s.viewWindow)
+		if err != nil {
+			return false, err
+		}
+		if !first {
+			return false, nil
+		}
+	}
+
+	if s.views != nil {
+		return false, s.views.IncrementField(ctx, pendingViewsKey, listingID.String(), 1)
+	}
+	err := db.WithRetry(ctx, func() error { return s.counterRepo.Add(listingID, 1, 0, at) })
+	return err == nil, err
+}
+
+// RecordFavorite counts a buyer adding a listing to their favorites, or
+// taking it off them when delta is negative
+func (s *CounterService) RecordFavorite(ctx context.Context, listingID ids.ListingID, delta int64, at time.Time) error {
+	if listingID == "" {
+		return errors.ValidationError("listing ID is required")
+	}
+	return db.WithRetry(ctx, func() error { return s.counterRepo.Add(listingID, 0, delta, at) })
+}
+
+// FlushViews adds the buffered view counts to the listings' counters, one
+// write per listing, and returns the views written by listing. Counts that
+// could not be written are buffered again for the next flush; those of
+// listings that no longer exist are dropped.
+func (s *CounterService) FlushViews(ctx context.Context) (map[ids.ListingID]int64, error) {
+	flushed := make(map[ids.ListingID]int64)
+	if s.views == nil {
+		return flushed, nil
+	}
+
+	pending, err := s.views.TakeFields(ctx, pendingViewsKey)
+	if err != nil {
+		return flushed, err
+	}
+	listingIDs := make([]string, 0, len(pending))
+	for listingID, views := range pending {
+		if views > 0 {
+			listingIDs = append(listingIDs, listingID)
+		}
+	}
+	sort.Strings(listingIDs)
+
+	now := s.clock.Now()
+	for i, listingID := range listingIDs {
+		views := pending[listingID]
+		err := db.WithRetry(ctx, func() error { return s.counterRepo.Add(ids.ListingID(listingID), views, 0, now) })
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeValidation {
+			// The listing was deleted since it was viewed
+			continue
+		}
+		if err != nil {
+			for _, unwritten := range listingIDs[i:] {
+				_ = s.views.IncrementField(ctx, pendingViewsKey, unwritten, pending[unwritten])
+			}
+			return flushed, err
+		}
+		flushed[ids.ListingID(listingID)] = views
+	}
+	return flushed, nil
+}
+
+// attachCounters fills in the view and favorite counts of each listing, with
+// one lookup for the whole page. Nothing is attached when there are no counters.
+func attachCounters(counterRepo domain.ListingCounterRepository, listings []*domain.Listing) error {
+	if counterRepo == nil || len(listings) == 0 {
+		return nil
+	}
+
+	listingIDs := make([]ids.ListingID, 0, len(listings))
+	for _, listing := range listings {
+		listingIDs = append(listingIDs, listing.ID)
+	}
+	counters, err := counterRepo.FindByListingIDs(listingIDs)
+	if err != nil {
+		return err
+	}
+	byListing := make(map[ids.ListingID]*domain.ListingCounters, len(counters))
+	for _, counter := range counters {
+		byListing[counter.ListingID] = counter
+	}
+	for _, listing := range listings {
+		if counter, ok := byListing[listing.ID]; ok {
+			listing.ViewsCount = counter.Views
+			listing.FavoritesCount = counter.Favorites
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounterService_RecordView(t *testing.T) {
+	counters := newFakeListingCounterRepository()
+	service := app.NewCounterService(counters, nil, nil, 0, nil)
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		written, err := service.RecordView(ctx, "listing-1", "viewer-1", now)
+		require.NoError(t, err)
+		assert.True(t, written)
+	}
+	require.Contains(t, counters.counters, ids.ListingID("listing-1"))
+	assert.Equal(t, int64(2), counters.counters["listing-1"].Views)
+	assert.Zero(t, counters.counters["listing-1"].Favorites)
+
+	_, err := service.RecordView(ctx, "", "viewer-1", now)
+	assert.Error(t, err)
+}
+
+func TestCounterService_FlushViews(t *testing.T) {
+	counters := newFakeListingCounterRepository(&domain.ListingCounters{ListingID: "listing-1", Views: 10})
+	buffer := newFakeCache()
+	service := app.NewCounterService(counters, buffer, buffer, 30*time.Minute, nil)
+	ctx := context.Background()
+	now := time.Now()
+
+	// A viewer's repeated views count once; views without a viewer always count
+	for _, viewer := range []string{"viewer-1", "viewer-1", "viewer-2", "", ""} {
+		written, err := service.RecordView(ctx, "listing-1", viewer, now)
+		require.NoError(t, err)
+		assert.False(t, written)
+	}
+	_, err := service.RecordView(ctx, "listing-2", "viewer-1", now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), counters.counters["listing-1"].Views, "views are buffered until flushed")
+
+	counters.addErr = errors.New("database is down")
+	_, err = service.FlushViews(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, int64(10), counters.counters["listing-1"].Views)
+
+	// Views that could not be written are flushed next time
+	counters.addErr = nil
+	flushed, err := service.FlushViews(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[ids.ListingID]int64{"listing-1": 4, "listing-2": 1}, flushed)
+	assert.Equal(t, int64(14), counters.counters["listing-1"].Views)
+	assert.Equal(t, int64(1), counters.counters["listing-2"].Views)
+
+	flushed, err = service.FlushViews(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, flushed)
+	assert.Equal(t, int64(14), counters.counters["listing-1"].Views)
+}
+
+func TestListingService_GetListing_CountsViewsThroughEvents(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	updatedAt := listing.UpdatedAt
+	counters := newFakeListingCounterRepository(&domain.ListingCounters{ListingID: listing.ID, Views: 7, Favorites: 2})
+	bus := &fakeEventBus{}
+	service := app.NewListingService(newFakeListingRepository(listing), nil, bus, nil, nil, counters, nil, nil, nil, nil, nil)
+
+	detail, err := service.GetListing(context.Background(), listing.ID, "viewer-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), detail.Listing.ViewsCount)
+	assert.Equal(t, int64(2), detail.Listing.FavoritesCount)
+
+	// The view is counted by the worker, not by writing to the listing
+	viewed := bus.eventsOfType(domain.ListingViewedEvent)
+	require.Len(t, viewed, 1)
+	assert.Equal(t, listing.ID.String(), viewed[0].AggregateID)
+	assert.Equal(t, updatedAt, listing.UpdatedAt)
+}
+
+func TestListingService_SearchListings_AttachesCountersBeforeCursor(t *testing.T) {
+	var listings []*domain.Listing
+	var counters []*domain.ListingCounters
+	for i := 0; i < 3; i++ {
+		listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+		listings = append(listings, listing)
+		counters = append(counters, &domain.ListingCounters{ListingID: listing.ID, Views: int64(10 * (i + 1))})
+	}
+	service := app.NewListingService(newFakeListingRepository(listings...), nil, &fakeEventBus{}, nil, nil, newFakeListingCounterRepository(counters...), nil, nil, nil, nil, nil)
+
+	results, err := service.SearchListings(context.Background(), app.SearchListingsQuery{Sort: "most_viewed", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, results.Listings, 1)
+	assert.NotZero(t, results.Listings[0].ViewsCount)
+
+	cursor, err := domain.DecodeListingCursor(results.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, float64(results.Listings[0].ViewsCount), cursor.Value)
+}
+
+func TestSearchService_IndexListing_IndexesViews(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	index := newFakeListingIndex()
+	counters := newFakeListingCounterRepository(&domain.ListingCounters{ListingID: listing.ID, Views: 42})
+	service := app.NewSearchService(index, newFakeListingRepository(listing), nil, counters, nil)
+
+	require.NoError(t, service.IndexListing(context.Background(), listing.ID))
+	require.Contains(t, index.docs, listing.ID)
+	assert.Equal(t, int64(42), index.docs[listing.ID].ViewsCount)
+}
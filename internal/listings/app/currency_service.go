@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/clock"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// Cache keys of the exchange rates. The fresh copy expires after the rates
+// TTL; the last known copy is kept to fall back on while the rates provider
+// cannot be reached.
+const (
+	exchangeRatesKey     = "currency:rates"
+	lastExchangeRatesKey = "currency:rates:last"
+)
+
+// ExchangeRateSource fetches the latest exchange rates against a base currency
+type ExchangeRateSource interface {
+	LatestRates(ctx context.Context, base string) (*domain.ExchangeRates, error)
+}
+
+// CurrencyPreferences tells which currency a user wants prices shown in. It
+// is implemented by the users context.
+type CurrencyPreferences interface {
+	PreferredCurrency(ctx context.Context, userID ids.UserID) (string, error)
+}
+
+// Currencies represents the currencies prices can be shown in and their
+// current exchange rates
+type Currencies struct {
+	Supported []string              `json:"supported"`
+	Rates     *domain.ExchangeRates `json:"rates,omitempty"`
+}
+
+// CurrencyService converts listing prices into the currency each buyer
+// prefers, with exchange rates fetched from a rates provider and cached
+type CurrencyService struct {
+	source      ExchangeRateSource
+	preferences CurrencyPreferences
+	cache       cache.Cache
+	ttl         time.Duration
+	clock       clock.Clock
+}
+
+// NewCurrencyService creates a new currency service. Rates are fetched again
+// once they are older than ttl. source may be nil where no rates provider is
+// configured; prices are then only shown in the currency they are listed in.
+func NewCurrencyService(source ExchangeRateSource, preferences CurrencyPreferences, cache cache.Cache, ttl time.Duration, clk clock.Clock) *CurrencyService {
+	return &CurrencyService{
+		source:      source,
+		preferences: preferences,
+		cache:       cache,
+		ttl:         ttl,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// ListCurrencies lists the supported currencies with the current exchange
+// rates, if any could be found
+func (s *CurrencyService) ListCurrencies(ctx context.Context) *Currencies {
+	currencies := &Currencies{Supported: money.SupportedCurrencies}
+	if rates, err := s.ExchangeRates(ctx); err == nil {
+		currencies.Rates = rates
+	}
+	return currencies
+}
+
+// ExchangeRates returns the cached exchange rates, fetching them when they
+// have expired. When they cannot be fetched, the last known rates are used.
+func (s *CurrencyService) ExchangeRates(ctx context.Context) (*domain.ExchangeRates, error) {
+	var rates domain.ExchangeRates
+	if err := s.cache.Get(ctx, exchangeRatesKey, &rates); err == nil {
+		return &rates, nil
+	}
+
+	fetched, err := s.RefreshRates(ctx)
+	if err == nil {
+		return fetched, nil
+	}
+	if cacheErr := s.cache.Get(ctx, lastExchangeRatesKey, &rates); cacheErr == nil {
+		return &rates, nil
+	}
+	return nil, err
+}
+
+// RefreshRates fetches the latest exchange rates and caches them
+func (s *CurrencyService) RefreshRates(ctx context.Context) (*domain.ExchangeRates, error) {
+	if s.source == nil {
+		return nil, errors.NotFoundError("exchange rates are not available")
+	}
+
+	rates, err := s.source.LatestRates(ctx, money.DefaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if rates.FetchedAt.IsZero() {
+		rates.FetchedAt = s.clock.Now()
+	}
+	_ = s.cache.Set(ctx, exchangeRatesKey, rates, s.ttl)
+	_ = s.cache.Set(ctx, lastExchangeRatesKey, rates, 0)
+	return rates, nil
+}
+
+// DisplayCurrency picks the currency to show prices in: the one asked for,
+// else the user's preferred currency, else none, which shows prices as listed
+func (s *CurrencyService) DisplayCurrency(ctx context.Context, userID ids.UserID, requested string) string {
+	if money.IsSupported(requested) {
+		return requested
+	}
+	if userID == "" || s.preferences == nil {
+		return ""
+	}
+	currency, err := s.preferences.PreferredCurrency(ctx, userID)
+	if err != nil {
+		return ""
+	}
+	return currency
+}
+
+// ConvertListings fills in the display price of the listings priced in
+// another currency than the one given. Without a currency or exchange rates
+// the listings are shown as listed.
+func (s *CurrencyService) ConvertListings(ctx context.Context, currency string, listings ...*domain.Listing) {
+	if currency == "" {
+		return
+	}
+	needed := false
+	for _, listing := range listings {
+		if listing.Price.Currency != currency {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return
+	}
+
+	rates, err := s.ExchangeRates(ctx)
+	if err != nil {
+		return
+	}
+	rates.ConvertPrices(currency, listings)
+}
@@ -0,0 +1,92 @@
+package app_test
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRateSource serves fixed rates, or fails once down
+type fakeRateSource struct {
+	rates   map[string]float64
+	fetches int
+	down    bool
+}
+
+func (s *fakeRateSource) LatestRates(ctx context.Context, base string) (*domain.ExchangeRates, error) {
+	s.fetches++
+	if s.down {
+		return nil, stderrors.New("rates provider unreachable")
+	}
+	return &domain.ExchangeRates{Base: base, Rates: s.rates}, nil
+}
+
+type fakeCurrencyPreferences map[ids.UserID]string
+
+func (p fakeCurrencyPreferences) PreferredCurrency(ctx context.Context, userID ids.UserID) (string, error) {
+	return p[userID], nil
+}
+
+func TestCurrencyService_ConvertListings(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeRateSource{rates: map[string]float64{"USD": 0.08, "EUR": 0.07}}
+	service := app.NewCurrencyService(source, nil, newFakeCache(), time.Hour, nil)
+
+	cedis := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	dollars := newActiveListing(t, "seller-b", "Laptop", domain.ConditionGood)
+	dollars.Price = money.New(5000, "USD")
+
+	service.ConvertListings(ctx, "USD", cedis, dollars)
+	require.NotNil(t, cedis.DisplayPrice)
+	assert.Equal(t, money.New(800, "USD"), *cedis.DisplayPrice)
+	assert.Nil(t, dollars.DisplayPrice, "listings in the display currency are shown as listed")
+
+	service.ConvertListings(ctx, "EUR", dollars)
+	require.NotNil(t, dollars.DisplayPrice)
+	assert.Equal(t, money.New(4375, "EUR"), *dollars.DisplayPrice, "rates cross through the cedi")
+
+	assert.Equal(t, 1, source.fetches, "rates are fetched once and cached")
+}
+
+func TestCurrencyService_ExchangeRatesFallBackToLastKnown(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeRateSource{rates: map[string]float64{"USD": 0.08}}
+	cache := newFakeCache()
+	service := app.NewCurrencyService(source, nil, cache, time.Hour, nil)
+
+	_, err := service.RefreshRates(ctx)
+	require.NoError(t, err)
+
+	// The fresh rates expire while the provider is down
+	require.NoError(t, cache.Delete(ctx, "currency:rates"))
+	source.down = true
+
+	rates, err := service.ExchangeRates(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0.08, rates.Rates["USD"])
+
+	unconfigured := app.NewCurrencyService(nil, nil, newFakeCache(), time.Hour, nil)
+	listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	unconfigured.ConvertListings(ctx, "USD", listing)
+	assert.Nil(t, listing.DisplayPrice, "without rates prices are shown as listed")
+}
+
+func TestCurrencyService_DisplayCurrency(t *testing.T) {
+	ctx := context.Background()
+	preferences := fakeCurrencyPreferences{"buyer-1": "GBP"}
+	service := app.NewCurrencyService(nil, preferences, newFakeCache(), time.Hour, nil)
+
+	assert.Equal(t, "EUR", service.DisplayCurrency(ctx, "buyer-1", "EUR"), "the requested currency wins")
+	assert.Equal(t, "GBP", service.DisplayCurrency(ctx, "buyer-1", ""))
+	assert.Equal(t, "GBP", service.DisplayCurrency(ctx, "buyer-1", "XYZ"), "unsupported currencies are ignored")
+	assert.Equal(t, "", service.DisplayCurrency(ctx, "", ""))
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+)
+
+// ListingExportSource contributes a seller's listings to user data exports
+type ListingExportSource struct {
+	listingRepo domain.ListingRepository
+}
+
+// NewListingExportSource creates a new listing export source
+func NewListingExportSource(listingRepo domain.ListingRepository) *ListingExportSource {
+	return &ListingExportSource{
+		listingRepo: listingRepo,
+	}
+}
+
+// Name returns the export section name
+func (s *ListingExportSource) Name() string {
+	return "listings"
+}
+
+// ExportUserData returns all listings created by the user
+func (s *ListingExportSource) ExportUserData(ctx context.Context, userID ids.UserID) (interface{}, error) {
+	all := []*domain.Listing{}
+	for offset := 0; ; offset += sellerBatchSize {
+		listings, err := s.listingRepo.FindBySeller(userID, sellerBatchSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, listings...)
+
+		if len(listings) < sellerBatchSize {
+			return all, nil
+		}
+	}
+}
@@ -0,0 +1,2121 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/imaging"
+	"dongome/pkg/rules"
+	"dongome/pkg/storage"
+)
+
+// fakeListingRepository is an in-memory ListingRepository
+type fakeListingRepository struct {
+	mu           sync.Mutex
+	listings     map[ids.ListingID]*domain.Listing
+	lastCriteria domain.ListingSearchCriteria
+}
+
+func newFakeListingRepository(listings ...*domain.Listing) *fakeListingRepository {
+	repo := &fakeListingRepository{listings: make(map[ids.ListingID]*domain.Listing)}
+	for _, listing := range listings {
+		// Listings start out as if loaded, without the status changes made
+		// setting them up
+		listing.TakeStatusChanges()
+		repo.listings[listing.ID] = listing
+	}
+	return repo
+}
+
+func (r *fakeListingRepository) Save(listing *domain.Listing) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listings[listing.ID] = listing
+	return nil
+}
+
+func (r *fakeListingRepository) FindByID(id ids.ListingID) (*domain.Listing, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if listing, ok := r.listings[id]; ok {
+		return listing, nil
+	}
+	return nil, errors.NotFoundError("listing not found")
+}
+
+func (r *fakeListingRepository) FindByIDs(listingIDs []ids.ListingID) ([]*domain.Listing, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var listings []*domain.Listing
+	for _, id := range listingIDs {
+		if listing, ok := r.listings[id]; ok {
+			listings = append(listings, listing)
+		}
+	}
+	return listings, nil
+}
+
+func (r *fakeListingRepository) FindAttributes(listingIDs []ids.ListingID) ([]domain.ListingAttribute, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var attributes []domain.ListingAttribute
+	for _, id := range listingIDs {
+		if listing, ok := r.listings[id]; ok {
+			attributes = append(attributes, listing.Attributes...)
+		}
+	}
+	return attributes, nil
+}
+
+func (r *fakeListingRepository) FindBySeller(sellerID ids.UserID, limit, offset int) ([]*domain.Listing, error) {
+	return r.filter(func(l *domain.Listing) bool { return l.SellerID == sellerID }, limit, offset), nil
+}
+
+func (r *fakeListingRepository) FindByCategory(categoryID string, limit, offset int) ([]*domain.Listing, error) {
+	return r.filter(func(l *domain.Listing) bool { return l.CategoryID == categoryID }, limit, offset), nil
+}
+
+func (r *fakeListingRepository) FindIDsByFilter(filter domain.BulkListingFilter, limit, offset int) ([]ids.ListingID, error) {
+	matches := r.filter(func(l *domain.Listing) bool {
+		return (filter.SellerID == "" || l.SellerID == filter.SellerID) &&
+			(filter.CategoryID == "" || l.CategoryID == filter.CategoryID) &&
+			(filter.Status == "" || l.Status == filter.Status) &&
+			(filter.Region == "" || l.Location.Region == filter.Region)
+	}, 0, 0)
+	listingIDs := make([]ids.ListingID, 0, len(matches))
+	for _, listing := range matches {
+		listingIDs = append(listingIDs, listing.ID)
+	}
+	sort.Slice(listingIDs, func(i, j int) bool { return listingIDs[i] < listingIDs[j] })
+	if offset >= len(listingIDs) {
+		return nil, nil
+	}
+	listingIDs = listingIDs[offset:]
+	if len(listingIDs) > limit {
+		listingIDs = listingIDs[:limit]
+	}
+	return listingIDs, nil
+}
+
+func (r *fakeListingRepository) Search(criteria domain.ListingSearchCriteria, limit, offset int) ([]*domain.Listing, error) {
+	matches := r.filter(matchCriteria(criteria), 0, 0)
+	if order := criteria.SortOrder(); order.SupportsCursor() {
+		sortListings(matches, order)
+	} else if order == domain.ListingSortDistance {
+		near := criteria.Near
+		sort.Slice(matches, func(i, j int) bool {
+			return matches[i].Location.DistanceKm(near.Latitude, near.Longitude) < matches[j].Location.DistanceKm(near.Latitude, near.Longitude)
+		})
+	}
+	if criteria.After != nil {
+		var after []*domain.Listing
+		for _, listing := range matches {
+			if criteria.After.Precedes(listing) {
+				after = append(after, listing)
+			}
+		}
+		matches = after
+	}
+	if offset >= len(matches) {
+		return nil, nil
+	}
+	matches = matches[offset:]
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (r *fakeListingRepository) FindByCriteria(criteria domain.ListingSearchCriteria, limit, offset int) ([]*domain.Listing, int64, error) {
+	r.mu.Lock()
+	r.lastCriteria = criteria
+	r.mu.Unlock()
+
+	all := r.filter(matchCriteria(criteria), 0, 0)
+	page, err := r.Search(criteria, limit, offset)
+	return page, int64(len(all)), err
+}
+
+// matchCriteria mirrors the repository's filters that tests rely on
+func matchCriteria(criteria domain.ListingSearchCriteria) func(*domain.Listing) bool {
+	return func(l *domain.Listing) bool {
+		if !l.IsActive() {
+			return false
+		}
+		if criteria.SellerID != "" && l.SellerID != criteria.SellerID {
+			return false
+		}
+		if len(criteria.SellerIDs) > 0 && !contains(criteria.SellerIDs, l.SellerID) {
+			return false
+		}
+		if criteria.CategoryID != "" && l.CategoryID != criteria.CategoryID {
+			return false
+		}
+		if criteria.Condition != "" && l.Condition != criteria.Condition {
+			return false
+		}
+		if criteria.MinPrice != nil && l.Price.Major() < *criteria.MinPrice {
+			return false
+		}
+		if criteria.MaxPrice != nil && l.Price.Major() > *criteria.MaxPrice {
+			return false
+		}
+		if criteria.Tag != "" && !hasTag(l, criteria.Tag) {
+			return false
+		}
+		if criteria.Near != nil && !criteria.Near.Contains(l.Location) {
+			return false
+		}
+		if criteria.PromotedAt != nil && !l.IsPromotedAt(*criteria.PromotedAt) {
+			return false
+		}
+		return true
+	}
+}
+
+// sortListings orders listings the way a cursor walks them
+func sortListings(listings []*domain.Listing, order domain.ListingSort) {
+	sort.Slice(listings, func(i, j int) bool {
+		return domain.NewListingCursor(listings[i], order).Precedes(listings[j])
+	})
+}
+
+func hasTag(listing *domain.Listing, name string) bool {
+	for _, tag := range listing.Tags {
+		if tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *fakeListingRepository) Facets(criteria domain.ListingSearchCriteria) (*domain.SearchFacets, error) {
+	return &domain.SearchFacets{}, nil
+}
+
+func (r *fakeListingRepository) CountByLocation(region string) ([]domain.FacetCount, error) {
+	counts := make(map[string]int64)
+	for _, listing := range r.filter(func(l *domain.Listing) bool { return l.IsActive() }, 0, 0) {
+		switch {
+		case region == "":
+			counts[listing.Location.Region]++
+		case listing.Location.Region == region:
+			counts[listing.Location.City]++
+		}
+	}
+
+	var facets []domain.FacetCount
+	for value, count := range counts {
+		facets = append(facets, domain.FacetCount{Value: value, Count: count})
+	}
+	return facets, nil
+}
+
+func (r *fakeListingRepository) FindDueScheduled(now time.Time, limit int) ([]*domain.Listing, error) {
+	return r.filter(func(l *domain.Listing) bool { return l.IsScheduled() && !l.PublishAt.After(now) }, limit, 0), nil
+}
+
+func (r *fakeListingRepository) FindLapsedPromotions(now time.Time, limit int) ([]*domain.Listing, error) {
+	return r.filter(func(l *domain.Listing) bool {
+		return l.IsPromoted && (l.PromotedUntil == nil || !l.PromotedUntil.After(now))
+	}, limit, 0), nil
+}
+
+func (r *fakeListingRepository) FindLapsedListings(now time.Time, limit int) ([]*domain.Listing, error) {
+	return r.filter(func(l *domain.Listing) bool {
+		return l.Status == domain.ListingStatusActive && !l.ExpiresAt.After(now) && !l.IsReserved()
+	}, limit, 0), nil
+}
+
+func (r *fakeListingRepository) FindStaleDrafts(before time.Time, limit int) ([]*domain.Listing, error) {
+	return r.filter(func(l *domain.Listing) bool {
+		return l.Status == domain.ListingStatusDraft && l.PublishAt == nil && l.UpdatedAt.Before(before)
+	}, limit, 0), nil
+}
+
+func (r *fakeListingRepository) CountScheduledBySeller(sellerID ids.UserID) (int64, error) {
+	scheduled := r.filter(func(l *domain.Listing) bool { return l.SellerID == sellerID && l.IsScheduled() }, 0, 0)
+	return int64(len(scheduled)), nil
+}
+
+func (r *fakeListingRepository) CountActiveBySeller(sellerID ids.UserID) (int64, error) {
+	active := r.filter(func(l *domain.Listing) bool { return l.SellerID == sellerID && l.Status == domain.ListingStatusActive }, 0, 0)
+	return int64(len(active)), nil
+}
+
+func (r *fakeListingRepository) Update(listing *domain.Listing) error {
+	return r.Save(listing)
+}
+
+func (r *fakeListingRepository) UpdateImage(image *domain.ListingImage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	listing, ok := r.listings[image.ListingID]
+	if !ok {
+		return errors.NotFoundError("listing not found")
+	}
+	if existing := listing.Image(image.ID); existing != nil {
+		*existing = *image
+	}
+	return nil
+}
+
+func (r *fakeListingRepository) Delete(id ids.ListingID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.listings, id)
+	return nil
+}
+
+// filter returns the matching listings, paginated when limit is positive
+func (r *fakeListingRepository) filter(match func(*domain.Listing) bool, limit, offset int) []*domain.Listing {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matches []*domain.Listing
+	for _, listing := range r.listings {
+		if match(listing) {
+			matches = append(matches, listing)
+		}
+	}
+	if offset >= len(matches) {
+		return nil
+	}
+	matches = matches[offset:]
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// fakeEventBus records published events
+type fakeEventBus struct {
+	mu        sync.Mutex
+	published []*events.Event
+}
+
+func (b *fakeEventBus) Publish(ctx context.Context, event *events.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, event)
+	return nil
+}
+
+func (b *fakeEventBus) Subscribe(eventType string, handler events.EventHandler) error {
+	return nil
+}
+
+func (b *fakeEventBus) Close() error {
+	return nil
+}
+
+// eventsOfType returns the published events of the given type
+func (b *fakeEventBus) eventsOfType(eventType string) []*events.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var matching []*events.Event
+	for _, event := range b.published {
+		if event.Type == eventType {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+// fakeLocationRepository is an in-memory LocationRepository
+type fakeLocationRepository struct {
+	mu      sync.Mutex
+	regions map[string]*domain.Region
+}
+
+func newFakeLocationRepository() *fakeLocationRepository {
+	return &fakeLocationRepository{regions: make(map[string]*domain.Region)}
+}
+
+func (r *fakeLocationRepository) FindRegions() ([]*domain.Region, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var regions []*domain.Region
+	for _, region := range r.regions {
+		regions = append(regions, region)
+	}
+	return regions, nil
+}
+
+func (r *fakeLocationRepository) FindRegion(slug string) (*domain.Region, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if region, ok := r.regions[slug]; ok {
+		return region, nil
+	}
+	return nil, errors.NotFoundError("region not found")
+}
+
+func (r *fakeLocationRepository) SaveTree(regions []*domain.Region) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, region := range regions {
+		r.regions[region.Slug] = region
+	}
+	return nil
+}
+
+// fakeCache is an in-memory Cache that stores values as JSON, and Counters
+type fakeCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+	fields  map[string]map[string]int64
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{entries: make(map[string][]byte)}
+}
+
+func (c *fakeCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.entries[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *fakeCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = data
+	return nil
+}
+
+func (c *fakeCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return false, nil
+	}
+	c.entries[key] = data
+	return true, nil
+}
+
+func (c *fakeCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+func (c *fakeCache) IncrementField(ctx context.Context, key, field string, n int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fields == nil {
+		c.fields = make(map[string]map[string]int64)
+	}
+	if c.fields[key] == nil {
+		c.fields[key] = make(map[string]int64)
+	}
+	c.fields[key][field] += n
+	return nil
+}
+
+func (c *fakeCache) TakeFields(ctx context.Context, key string) (map[string]int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fields := c.fields[key]
+	delete(c.fields, key)
+	return fields, nil
+}
+
+func (c *fakeCache) Close() error {
+	return nil
+}
+
+func (c *fakeCache) has(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.entries[key]
+	return ok
+}
+
+// fakeEdgeWarmer records warmed URLs and fails the ones listed in failing
+type fakeEdgeWarmer struct {
+	mu      sync.Mutex
+	warmed  []string
+	failing map[string]bool
+}
+
+func (w *fakeEdgeWarmer) Warm(ctx context.Context, urls []string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var err error
+	count := 0
+	for _, url := range urls {
+		if w.failing[url] {
+			err = stderrors.New("edge unavailable")
+			continue
+		}
+		w.warmed = append(w.warmed, url)
+		count++
+	}
+	return count, err
+}
+
+// fakeListingQuestionRepository is an in-memory ListingQuestionRepository
+type fakeListingQuestionRepository struct {
+	mu        sync.Mutex
+	questions []*domain.ListingQuestion
+}
+
+func (r *fakeListingQuestionRepository) Save(question *domain.ListingQuestion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.questions = append(r.questions, question)
+	return nil
+}
+
+func (r *fakeListingQuestionRepository) FindByID(id string) (*domain.ListingQuestion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, question := range r.questions {
+		if question.ID == id {
+			return question, nil
+		}
+	}
+	return nil, errors.NotFoundError("question not found")
+}
+
+func (r *fakeListingQuestionRepository) FindByListing(listingID ids.ListingID, status domain.QuestionStatus, limit, offset int) ([]*domain.ListingQuestion, int64, error) {
+	return r.page(func(q *domain.ListingQuestion) bool { return q.ListingID == listingID && q.Status == status }, limit, offset)
+}
+
+func (r *fakeListingQuestionRepository) FindTopAnswered(listingID ids.ListingID, limit int) ([]*domain.ListingQuestion, error) {
+	questions, _, err := r.page(func(q *domain.ListingQuestion) bool {
+		return q.ListingID == listingID && q.IsVisible() && q.IsAnswered()
+	}, limit, 0)
+	return questions, err
+}
+
+func (r *fakeListingQuestionRepository) FindByStatus(status domain.QuestionStatus, limit, offset int) ([]*domain.ListingQuestion, int64, error) {
+	return r.page(func(q *domain.ListingQuestion) bool { return q.Status == status }, limit, offset)
+}
+
+func (r *fakeListingQuestionRepository) Update(question *domain.ListingQuestion) error {
+	return nil
+}
+
+// page returns the matching questions, newest first
+func (r *fakeListingQuestionRepository) page(match func(*domain.ListingQuestion) bool, limit, offset int) ([]*domain.ListingQuestion, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matched []*domain.ListingQuestion
+	for i := len(r.questions) - 1; i >= 0; i-- {
+		if match(r.questions[i]) {
+			matched = append(matched, r.questions[i])
+		}
+	}
+	total := int64(len(matched))
+	if offset >= len(matched) {
+		return []*domain.ListingQuestion{}, total, nil
+	}
+	matched = matched[offset:]
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+// fakeOfferRepository is an in-memory OfferRepository
+type fakeOfferRepository struct {
+	mu     sync.Mutex
+	offers []*domain.Offer
+}
+
+func (r *fakeOfferRepository) Save(offer *domain.Offer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.offers = append(r.offers, offer)
+	return nil
+}
+
+func (r *fakeOfferRepository) FindByID(id string) (*domain.Offer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, offer := range r.offers {
+		if offer.ID == id {
+			return offer, nil
+		}
+	}
+	return nil, errors.NotFoundError("offer not found")
+}
+
+func (r *fakeOfferRepository) FindOpen(listingID ids.ListingID, buyerID ids.UserID, now time.Time) ([]*domain.Offer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var open []*domain.Offer
+	for _, offer := range r.offers {
+		if offer.ListingID == listingID && offer.BuyerID == buyerID && offer.IsOpen() && !offer.IsExpired(now) {
+			open = append(open, offer)
+		}
+	}
+	return open, nil
+}
+
+func (r *fakeOfferRepository) FindByListing(listingID ids.ListingID, buyerID ids.UserID, limit, offset int) ([]*domain.Offer, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matched []*domain.Offer
+	for i := len(r.offers) - 1; i >= 0; i-- {
+		offer := r.offers[i]
+		if offer.ListingID == listingID && (buyerID.IsZero() || offer.BuyerID == buyerID) {
+			matched = append(matched, offer)
+		}
+	}
+	total := int64(len(matched))
+	if offset >= len(matched) {
+		return []*domain.Offer{}, total, nil
+	}
+	matched = matched[offset:]
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+func (r *fakeOfferRepository) FindExpiredOpen(now time.Time, limit int) ([]*domain.Offer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var expired []*domain.Offer
+	for _, offer := range r.offers {
+		if offer.IsExpired(now) && len(expired) < limit {
+			expired = append(expired, offer)
+		}
+	}
+	return expired, nil
+}
+
+func (r *fakeOfferRepository) Update(offer *domain.Offer) error {
+	return nil
+}
+
+// fixedPublicationPolicy gives every seller the same limits
+type fixedPublicationPolicy domain.PublicationLimits
+
+func (p fixedPublicationPolicy) LimitsFor(ctx context.Context, sellerID ids.UserID) (domain.PublicationLimits, error) {
+	return domain.PublicationLimits(p), nil
+}
+
+// fakeRuleStore is an in-memory rules.Store
+type fakeRuleStore struct {
+	rules map[string]*rules.Rule
+}
+
+func newFakeRuleStore() *fakeRuleStore {
+	return &fakeRuleStore{rules: make(map[string]*rules.Rule)}
+}
+
+func (s *fakeRuleStore) FindAll() ([]*rules.Rule, error) {
+	var all []*rules.Rule
+	for _, rule := range s.rules {
+		all = append(all, rule)
+	}
+	return all, nil
+}
+
+func (s *fakeRuleStore) Save(rule *rules.Rule) error {
+	s.rules[rule.Key] = rule
+	return nil
+}
+
+func (s *fakeRuleStore) Delete(key string) error {
+	delete(s.rules, key)
+	return nil
+}
+
+// fakeModerator flags text containing a blocked word
+type fakeModerator struct {
+	blocked string
+}
+
+func (m *fakeModerator) Review(ctx context.Context, text string) (app.ModerationResult, error) {
+	if m.blocked != "" && strings.Contains(text, m.blocked) {
+		return app.ModerationResult{Flagged: true, Reason: "contains " + m.blocked}, nil
+	}
+	return app.ModerationResult{}, nil
+}
+
+// fakeNotificationPreferences reports notification channels; users not
+// listed get email and push
+type fakeNotificationPreferences struct {
+	channels map[ids.UserID][]string
+}
+
+func (p *fakeNotificationPreferences) NotificationChannels(ctx context.Context, userID ids.UserID, category string) ([]string, error) {
+	if channels, ok := p.channels[userID]; ok {
+		return channels, nil
+	}
+	return []string{"email", "push"}, nil
+}
+
+// fakeCategoryRepository is an in-memory CategoryRepository
+type fakeCategoryRepository struct {
+	categories map[string]*domain.Category
+}
+
+func newFakeCategoryRepository(categories ...*domain.Category) *fakeCategoryRepository {
+	repo := &fakeCategoryRepository{categories: make(map[string]*domain.Category)}
+	for _, category := range categories {
+		repo.categories[category.ID] = category
+	}
+	return repo
+}
+
+func (r *fakeCategoryRepository) Save(category *domain.Category) error {
+	r.categories[category.ID] = category
+	return nil
+}
+
+func (r *fakeCategoryRepository) FindByID(id string) (*domain.Category, error) {
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, errors.NotFoundError("category not found")
+	}
+	return category, nil
+}
+
+func (r *fakeCategoryRepository) FindAll() ([]*domain.Category, error) {
+	var categories []*domain.Category
+	for _, category := range r.categories {
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
+
+func (r *fakeCategoryRepository) FindByParent(parentID string) ([]*domain.Category, error) {
+	var categories []*domain.Category
+	for _, category := range r.categories {
+		if category.ParentID != nil && *category.ParentID == parentID {
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+func (r *fakeCategoryRepository) Update(category *domain.Category) error {
+	r.categories[category.ID] = category
+	return nil
+}
+
+func (r *fakeCategoryRepository) Delete(id string) error {
+	delete(r.categories, id)
+	return nil
+}
+
+// fakeSuggester records the last request it was asked about. Attributes
+// named in expects are reported missing when a request lacks them.
+type fakeSuggester struct {
+	last    domain.SuggestionRequest
+	expects []string
+}
+
+func (s *fakeSuggester) Suggest(ctx context.Context, req domain.SuggestionRequest) (*domain.ListingSuggestion, error) {
+	s.last = req
+	var missing []string
+	for _, name := range s.expects {
+		if req.Attributes[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	return &domain.ListingSuggestion{Title: req.Attributes["brand"], MissingAttributes: missing, Source: "fake"}, nil
+}
+
+// fakeStagedImageRepository is an in-memory StagedImageRepository
+type fakeStagedImageRepository struct {
+	images map[string]*domain.StagedImage
+}
+
+func newFakeStagedImageRepository() *fakeStagedImageRepository {
+	return &fakeStagedImageRepository{images: make(map[string]*domain.StagedImage)}
+}
+
+func (r *fakeStagedImageRepository) Save(image *domain.StagedImage) error {
+	r.images[image.ID] = image
+	return nil
+}
+
+func (r *fakeStagedImageRepository) FindByIDs(ownerID ids.UserID, imageIDs []string) ([]*domain.StagedImage, error) {
+	var images []*domain.StagedImage
+	for _, id := range imageIDs {
+		if image, ok := r.images[id]; ok && image.OwnerID == ownerID {
+			images = append(images, image)
+		}
+	}
+	return images, nil
+}
+
+func (r *fakeStagedImageRepository) CountPendingByOwner(ownerID ids.UserID, now time.Time) (int64, error) {
+	var count int64
+	for _, image := range r.images {
+		if image.OwnerID == ownerID && !image.IsAttached() && !image.Expired(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeStagedImageRepository) FindExpired(now time.Time, limit int) ([]*domain.StagedImage, error) {
+	var images []*domain.StagedImage
+	for _, image := range r.images {
+		if image.Expired(now) && len(images) < limit {
+			images = append(images, image)
+		}
+	}
+	return images, nil
+}
+
+func (r *fakeStagedImageRepository) UpdateAll(images []*domain.StagedImage) error {
+	for _, image := range images {
+		r.images[image.ID] = image
+	}
+	return nil
+}
+
+func (r *fakeStagedImageRepository) Delete(id string) error {
+	delete(r.images, id)
+	return nil
+}
+
+// fakeImageStore keeps stored photos in memory
+type fakeImageStore struct {
+	files map[string][]byte
+}
+
+func newFakeImageStore() *fakeImageStore {
+	return &fakeImageStore{files: make(map[string][]byte)}
+}
+
+func (s *fakeImageStore) Put(ctx context.Context, key, contentType string, content io.Reader) (string, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", err
+	}
+	s.files[key] = data
+	return "https://media.example.com/" + key, nil
+}
+
+func (s *fakeImageStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.files[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *fakeImageStore) Delete(ctx context.Context, key string) error {
+	delete(s.files, key)
+	return nil
+}
+
+// fakeDirectUploadStore is a fakeImageStore clients can upload to directly
+type fakeDirectUploadStore struct {
+	*fakeImageStore
+	contentTypes map[string]string
+}
+
+func newFakeDirectUploadStore() *fakeDirectUploadStore {
+	return &fakeDirectUploadStore{fakeImageStore: newFakeImageStore(), contentTypes: make(map[string]string)}
+}
+
+// upload stores a photo the way a client holding a presigned request would
+func (s *fakeDirectUploadStore) upload(key, contentType, content string) {
+	s.files[key] = []byte(content)
+	s.contentTypes[key] = contentType
+}
+
+func (s *fakeDirectUploadStore) URL(key string) string {
+	return "https://media.example.com/" + key
+}
+
+func (s *fakeDirectUploadStore) PresignPut(key, contentType string, size int64, expires time.Duration) *storage.PresignedRequest {
+	return &storage.PresignedRequest{
+		Method:    http.MethodPut,
+		URL:       s.URL(key) + "?signature=test",
+		Headers:   map[string]string{"Content-Type": contentType, "Content-Length": strconv.FormatInt(size, 10)},
+		ExpiresAt: time.Now().Add(expires),
+	}
+}
+
+func (s *fakeDirectUploadStore) Stat(ctx context.Context, key string) (*storage.ObjectInfo, error) {
+	data, ok := s.files[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &storage.ObjectInfo{Size: int64(len(data)), ContentType: s.contentTypes[key]}, nil
+}
+
+// fakeImageProcessor makes a copy of each spec named after it, and cannot
+// read photos whose content is "unreadable"
+type fakeImageProcessor struct{}
+
+func (p *fakeImageProcessor) Process(ctx context.Context, original []byte, specs []domain.ImageVariantSpec) ([]app.ProcessedImage, error) {
+	if string(original) == "unreadable" {
+		return nil, imaging.ErrUnsupportedFormat
+	}
+	processed := make([]app.ProcessedImage, 0, len(specs))
+	for _, spec := range specs {
+		processed = append(processed, app.ProcessedImage{
+			Name:    spec.Name,
+			Width:   spec.MaxEdge,
+			Height:  spec.MaxEdge / 2,
+			Content: []byte(spec.Name + ":" + string(original)),
+		})
+	}
+	return processed, nil
+}
+
+// fakeSellerCardRepository is an in-memory SellerCardRepository
+type fakeSellerCardRepository struct {
+	mu      sync.Mutex
+	cards   map[ids.UserID]*domain.SellerCard
+	lookups int
+}
+
+func newFakeSellerCardRepository(cards ...*domain.SellerCard) *fakeSellerCardRepository {
+	repo := &fakeSellerCardRepository{cards: make(map[ids.UserID]*domain.SellerCard)}
+	for _, card := range cards {
+		repo.cards[card.SellerID] = card
+	}
+	return repo
+}
+
+func (r *fakeSellerCardRepository) FindBySellerIDs(sellerIDs []ids.UserID) ([]*domain.SellerCard, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups++
+	var cards []*domain.SellerCard
+	for _, id := range sellerIDs {
+		if card, ok := r.cards[id]; ok {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+func (r *fakeSellerCardRepository) SaveStats(card *domain.SellerCard) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.cards[card.SellerID]
+	if !ok {
+		r.cards[card.SellerID] = card
+		return nil
+	}
+	if !existing.StatsAsOf.Before(card.StatsAsOf) {
+		return nil
+	}
+	verified := existing.Verified || card.Verified
+	factor, reasons, rankedAt := existing.RankingFactor, existing.RankingReasons, existing.RankedAt
+	*existing = *card
+	existing.Verified = verified
+	existing.RankingFactor, existing.RankingReasons, existing.RankedAt = factor, reasons, rankedAt
+	return nil
+}
+
+func (r *fakeSellerCardRepository) MarkVerified(sellerID ids.UserID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	card, ok := r.cards[sellerID]
+	if !ok {
+		card = domain.NewSellerCard(sellerID, at)
+		r.cards[sellerID] = card
+	}
+	card.Verified = true
+	card.UpdatedAt = at
+	return nil
+}
+
+func (r *fakeSellerCardRepository) SaveRanking(sellerID ids.UserID, factor float64, reasons []string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	card, ok := r.cards[sellerID]
+	if !ok {
+		card = domain.NewSellerCard(sellerID, at)
+		r.cards[sellerID] = card
+	}
+	card.RankingFactor = factor
+	card.RankingReasons = reasons
+	card.RankedAt = &at
+	card.UpdatedAt = at
+	return nil
+}
+
+func (r *fakeSellerCardRepository) FindPenalized(limit, offset int) ([]*domain.SellerCard, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var penalized []*domain.SellerCard
+	for _, card := range r.cards {
+		if card.RankingFactor < 1 {
+			penalized = append(penalized, card)
+		}
+	}
+	sort.Slice(penalized, func(i, j int) bool {
+		if penalized[i].RankingFactor != penalized[j].RankingFactor {
+			return penalized[i].RankingFactor < penalized[j].RankingFactor
+		}
+		return penalized[i].SellerID < penalized[j].SellerID
+	})
+	total := int64(len(penalized))
+	if offset >= len(penalized) {
+		return []*domain.SellerCard{}, total, nil
+	}
+	penalized = penalized[offset:]
+	if len(penalized) > limit {
+		penalized = penalized[:limit]
+	}
+	return penalized, total, nil
+}
+
+// fakeRankingPolicyRepository is an in-memory RankingPolicyRepository
+type fakeRankingPolicyRepository struct {
+	policy *domain.RankingPolicy
+}
+
+func (r *fakeRankingPolicyRepository) Find() (*domain.RankingPolicy, error) {
+	if r.policy == nil {
+		return nil, errors.NotFoundError("ranking policy not found")
+	}
+	return r.policy, nil
+}
+
+func (r *fakeRankingPolicyRepository) Save(policy *domain.RankingPolicy) error {
+	r.policy = policy
+	return nil
+}
+
+// fakeSellerEngagementSource serves a fixed list of sellers ordered by ID
+type fakeSellerEngagementSource struct {
+	sellers []domain.SellerEngagement
+}
+
+func (s *fakeSellerEngagementSource) SellerEngagement(ctx context.Context, afterID ids.UserID, limit int, since time.Time) ([]domain.SellerEngagement, error) {
+	var batch []domain.SellerEngagement
+	for _, seller := range s.sellers {
+		if seller.SellerID > afterID && len(batch) < limit {
+			batch = append(batch, seller)
+		}
+	}
+	return batch, nil
+}
+
+// fakePublicationValidator blocks the listings it has an error for
+type fakePublicationValidator struct {
+	blocked map[ids.ListingID]string
+}
+
+func (v *fakePublicationValidator) ValidateListing(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID) (*domain.PublicationReport, error) {
+	report := domain.NewPublicationReport(listingID)
+	if message, ok := v.blocked[listingID]; ok {
+		report.AddError(domain.IssueImagesRequired, "images", message)
+	}
+	return report, nil
+}
+
+// fakeTranslationRepository is an in-memory TranslationRepository
+type fakeTranslationRepository struct {
+	translations []*domain.CategoryTranslation
+	labels       []*domain.AttributeLabel
+}
+
+func (r *fakeTranslationRepository) FindCategoryTranslations(locale string) ([]*domain.CategoryTranslation, error) {
+	var translations []*domain.CategoryTranslation
+	for _, translation := range r.translations {
+		if translation.Locale == locale {
+			translations = append(translations, translation)
+		}
+	}
+	return translations, nil
+}
+
+func (r *fakeTranslationRepository) FindCategoryTranslationsByCategory(categoryID string) ([]*domain.CategoryTranslation, error) {
+	var translations []*domain.CategoryTranslation
+	for _, translation := range r.translations {
+		if translation.CategoryID == categoryID {
+			translations = append(translations, translation)
+		}
+	}
+	return translations, nil
+}
+
+func (r *fakeTranslationRepository) SaveCategoryTranslation(translation *domain.CategoryTranslation) error {
+	_ = r.DeleteCategoryTranslation(translation.CategoryID, translation.Locale)
+	r.translations = append(r.translations, translation)
+	return nil
+}
+
+func (r *fakeTranslationRepository) DeleteCategoryTranslation(categoryID, locale string) error {
+	for i, translation := range r.translations {
+		if translation.CategoryID == categoryID && translation.Locale == locale {
+			r.translations = append(r.translations[:i], r.translations[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeTranslationRepository) FindAttributeLabels(locale string) ([]*domain.AttributeLabel, error) {
+	var labels []*domain.AttributeLabel
+	for _, label := range r.labels {
+		if locale == "" || label.Locale == locale {
+			labels = append(labels, label)
+		}
+	}
+	return labels, nil
+}
+
+func (r *fakeTranslationRepository) SaveAttributeLabel(label *domain.AttributeLabel) error {
+	_ = r.DeleteAttributeLabel(label.Key, label.Locale)
+	r.labels = append(r.labels, label)
+	return nil
+}
+
+func (r *fakeTranslationRepository) DeleteAttributeLabel(key, locale string) error {
+	for i, label := range r.labels {
+		if label.Key == key && label.Locale == locale {
+			r.labels = append(r.labels[:i], r.labels[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// fakeListingIndex is an in-memory ListingIndex whose searches return the
+// preset hits
+type fakeListingIndex struct {
+	mu   sync.Mutex
+	docs map[ids.ListingID]*domain.ListingDocument
+	hits []ids.ListingID
+}
+
+func newFakeListingIndex(hits ...ids.ListingID) *fakeListingIndex {
+	return &fakeListingIndex{docs: make(map[ids.ListingID]*domain.ListingDocument), hits: hits}
+}
+
+func (i *fakeListingIndex) Index(ctx context.Context, doc *domain.ListingDocument) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.docs[doc.ID] = doc
+	return nil
+}
+
+func (i *fakeListingIndex) Remove(ctx context.Context, listingID ids.ListingID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.docs, listingID)
+	return nil
+}
+
+func (i *fakeListingIndex) Search(ctx context.Context, criteria domain.ListingSearchCriteria, limit, offset int) (*domain.ListingHits, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	hits := &domain.ListingHits{Total: int64(len(i.hits))}
+	if offset < len(i.hits) {
+		hits.IDs = i.hits[offset:]
+	}
+	if len(hits.IDs) > limit {
+		hits.IDs = hits.IDs[:limit]
+	}
+	return hits, nil
+}
+
+// fakeListingPromotionRepository is an in-memory ListingPromotionRepository
+type fakeListingPromotionRepository struct {
+	mu         sync.Mutex
+	promotions map[string]*domain.ListingPromotion
+}
+
+func newFakeListingPromotionRepository() *fakeListingPromotionRepository {
+	return &fakeListingPromotionRepository{promotions: make(map[string]*domain.ListingPromotion)}
+}
+
+func (r *fakeListingPromotionRepository) Save(promotion *domain.ListingPromotion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.promotions[promotion.ID] = promotion
+	return nil
+}
+
+func (r *fakeListingPromotionRepository) FindByID(id string) (*domain.ListingPromotion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	promotion, ok := r.promotions[id]
+	if !ok {
+		return nil, errors.NotFoundError("promotion not found")
+	}
+	return promotion, nil
+}
+
+func (r *fakeListingPromotionRepository) Update(promotion *domain.ListingPromotion) error {
+	return r.Save(promotion)
+}
+
+// fakePaymentGateway records payment requests, failing them with err when set
+type fakePaymentGateway struct {
+	requests []app.PaymentRequest
+	err      error
+}
+
+func (g *fakePaymentGateway) RequestPayment(ctx context.Context, payment app.PaymentRequest) (string, error) {
+	if g.err != nil {
+		return "", g.err
+	}
+	g.requests = append(g.requests, payment)
+	return "reference-" + strconv.Itoa(len(g.requests)), nil
+}
+
+type fakeListingCounterRepository struct {
+	mu       sync.Mutex
+	counters map[ids.ListingID]*domain.ListingCounters
+	// addErr, when set, fails every Add
+	addErr error
+}
+
+func newFakeListingCounterRepository(counters ...*domain.ListingCounters) *fakeListingCounterRepository {
+	repo := &fakeListingCounterRepository{counters: make(map[ids.ListingID]*domain.ListingCounters)}
+	for _, counter := range counters {
+		repo.counters[counter.ListingID] = counter
+	}
+	return repo
+}
+
+func (r *fakeListingCounterRepository) FindByListingIDs(listingIDs []ids.ListingID) ([]*domain.ListingCounters, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var counters []*domain.ListingCounters
+	for _, id := range listingIDs {
+		if counter, ok := r.counters[id]; ok {
+			copied := *counter
+			counters = append(counters, &copied)
+		}
+	}
+	return counters, nil
+}
+
+func (r *fakeListingCounterRepository) Add(listingID ids.ListingID, views, favorites int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.addErr != nil {
+		return r.addErr
+	}
+	counter, ok := r.counters[listingID]
+	if !ok {
+		counter = &domain.ListingCounters{ListingID: listingID}
+		r.counters[listingID] = counter
+	}
+	counter.Views += views
+	counter.Favorites = max(counter.Favorites+favorites, 0)
+	counter.UpdatedAt = at
+	return nil
+}
+
+type fakeBulkOperationRepository struct {
+	mu         sync.Mutex
+	operations map[string]*domain.BulkOperation
+	items      map[string][]*domain.BulkOperationItem
+}
+
+func newFakeBulkOperationRepository() *fakeBulkOperationRepository {
+	return &fakeBulkOperationRepository{
+		operations: make(map[string]*domain.BulkOperation),
+		items:      make(map[string][]*domain.BulkOperationItem),
+	}
+}
+
+func (r *fakeBulkOperationRepository) Save(operation *domain.BulkOperation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *operation
+	r.operations[operation.ID] = &copied
+	return nil
+}
+
+func (r *fakeBulkOperationRepository) FindByID(id string) (*domain.BulkOperation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	operation, ok := r.operations[id]
+	if !ok {
+		return nil, errors.NotFoundError("bulk operation not found")
+	}
+	copied := *operation
+	return &copied, nil
+}
+
+func (r *fakeBulkOperationRepository) FindAll(status domain.BulkOperationStatus, limit, offset int) ([]*domain.BulkOperation, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var operations []*domain.BulkOperation
+	for _, operation := range r.operations {
+		if status == "" || operation.Status == status {
+			copied := *operation
+			operations = append(operations, &copied)
+		}
+	}
+	total := int64(len(operations))
+	if offset >= len(operations) {
+		return nil, total, nil
+	}
+	operations = operations[offset:]
+	if len(operations) > limit {
+		operations = operations[:limit]
+	}
+	return operations, total, nil
+}
+
+func (r *fakeBulkOperationRepository) FindUnfinished(limit int) ([]*domain.BulkOperation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var operations []*domain.BulkOperation
+	for _, operation := range r.operations {
+		if !operation.IsFinished() && len(operations) < limit {
+			copied := *operation
+			operations = append(operations, &copied)
+		}
+	}
+	return operations, nil
+}
+
+func (r *fakeBulkOperationRepository) SaveProgress(operation *domain.BulkOperation) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.operations[operation.ID]; !ok || stored.Status == domain.BulkOperationCancelled {
+		return false, nil
+	}
+	copied := *operation
+	r.operations[operation.ID] = &copied
+	return true, nil
+}
+
+func (r *fakeBulkOperationRepository) SaveCancellation(operation *domain.BulkOperation) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.operations[operation.ID]
+	if !ok || stored.IsFinished() {
+		return false, nil
+	}
+	stored.Status = operation.Status
+	stored.CancelledBy = operation.CancelledBy
+	stored.FinishedAt = operation.FinishedAt
+	return true, nil
+}
+
+func (r *fakeBulkOperationRepository) SaveItems(items []*domain.BulkOperationItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, item := range items {
+		copied := *item
+		r.items[item.OperationID] = append(r.items[item.OperationID], &copied)
+	}
+	return nil
+}
+
+func (r *fakeBulkOperationRepository) FindPendingItems(operationID string, limit int) ([]*domain.BulkOperationItem, error) {
+	items, _, err := r.FindItems(operationID, domain.BulkItemPending, limit, 0)
+	return items, err
+}
+
+func (r *fakeBulkOperationRepository) FindItems(operationID string, status domain.BulkItemStatus, limit, offset int) ([]*domain.BulkOperationItem, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var items []*domain.BulkOperationItem
+	for _, item := range r.items[operationID] {
+		if status == "" || item.Status == status {
+			copied := *item
+			items = append(items, &copied)
+		}
+	}
+	total := int64(len(items))
+	if offset >= len(items) {
+		return nil, total, nil
+	}
+	items = items[offset:]
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, total, nil
+}
+
+func (r *fakeBulkOperationRepository) CountItems(operationID string) (map[domain.BulkItemStatus]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[domain.BulkItemStatus]int)
+	for _, item := range r.items[operationID] {
+		counts[item.Status]++
+	}
+	return counts, nil
+}
+
+func (r *fakeBulkOperationRepository) UpdateItem(item *domain.BulkOperationItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, stored := range r.items[item.OperationID] {
+		if stored.ListingID == item.ListingID {
+			copied := *item
+			r.items[item.OperationID][i] = &copied
+			return nil
+		}
+	}
+	return errors.NotFoundError("bulk operation item not found")
+}
+
+type fakeListingIndexer struct {
+	mu      sync.Mutex
+	indexed []ids.ListingID
+	err     error
+}
+
+func (i *fakeListingIndexer) IndexListing(ctx context.Context, listingID ids.ListingID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.err != nil {
+		return i.err
+	}
+	i.indexed = append(i.indexed, listingID)
+	return nil
+}
+
+// fakeSavedSearchRepository is an in-memory SavedSearchRepository. Its
+// alert candidates are every search with alerts enabled; the service
+// checks the filters itself.
+type fakeSavedSearchRepository struct {
+	mu       sync.Mutex
+	searches map[string]*domain.SavedSearch
+}
+
+func newFakeSavedSearchRepository(searches ...*domain.SavedSearch) *fakeSavedSearchRepository {
+	repo := &fakeSavedSearchRepository{searches: make(map[string]*domain.SavedSearch)}
+	for _, search := range searches {
+		repo.searches[search.ID] = search
+	}
+	return repo
+}
+
+func (r *fakeSavedSearchRepository) Save(search *domain.SavedSearch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *search
+	r.searches[search.ID] = &copied
+	return nil
+}
+
+func (r *fakeSavedSearchRepository) Update(search *domain.SavedSearch) error {
+	return r.Save(search)
+}
+
+func (r *fakeSavedSearchRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.searches, id)
+	return nil
+}
+
+func (r *fakeSavedSearchRepository) FindByID(id string) (*domain.SavedSearch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	search, ok := r.searches[id]
+	if !ok {
+		return nil, errors.NotFoundError("saved search not found")
+	}
+	copied := *search
+	return &copied, nil
+}
+
+func (r *fakeSavedSearchRepository) FindByUser(userID ids.UserID) ([]*domain.SavedSearch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var searches []*domain.SavedSearch
+	for _, search := range r.searches {
+		if search.UserID == userID {
+			copied := *search
+			searches = append(searches, &copied)
+		}
+	}
+	sort.Slice(searches, func(i, j int) bool { return searches[i].CreatedAt.After(searches[j].CreatedAt) })
+	return searches, nil
+}
+
+func (r *fakeSavedSearchRepository) CountByUser(userID ids.UserID) (int64, error) {
+	searches, err := r.FindByUser(userID)
+	return int64(len(searches)), err
+}
+
+func (r *fakeSavedSearchRepository) FindAlertCandidates(listing *domain.Listing, afterID string, limit int) ([]*domain.SavedSearch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var searches []*domain.SavedSearch
+	for _, search := range r.searches {
+		if search.AlertsEnabled && search.ID > afterID {
+			copied := *search
+			searches = append(searches, &copied)
+		}
+	}
+	sort.Slice(searches, func(i, j int) bool { return searches[i].ID < searches[j].ID })
+	if len(searches) > limit {
+		searches = searches[:limit]
+	}
+	return searches, nil
+}
+
+func (r *fakeSavedSearchRepository) MarkAlerted(searchIDs []string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range searchIDs {
+		if search, ok := r.searches[id]; ok {
+			search.LastAlertedAt = &at
+		}
+	}
+	return nil
+}
+
+// fakeListingReportRepository is an in-memory ListingReportRepository
+type fakeListingReportRepository struct {
+	mu      sync.Mutex
+	reports map[string]*domain.ListingReport
+}
+
+func newFakeListingReportRepository() *fakeListingReportRepository {
+	return &fakeListingReportRepository{reports: make(map[string]*domain.ListingReport)}
+}
+
+func (r *fakeListingReportRepository) Save(report *domain.ListingReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *report
+	r.reports[report.ID] = &copied
+	return nil
+}
+
+func (r *fakeListingReportRepository) Update(report *domain.ListingReport) error {
+	return r.Save(report)
+}
+
+func (r *fakeListingReportRepository) FindByID(id string) (*domain.ListingReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report, ok := r.reports[id]
+	if !ok {
+		return nil, errors.NotFoundError("listing report not found")
+	}
+	copied := *report
+	return &copied, nil
+}
+
+func (r *fakeListingReportRepository) HasReported(listingID ids.ListingID, reporterID ids.UserID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, report := range r.reports {
+		if report.ListingID == listingID && report.ReporterID == reporterID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeListingReportRepository) FindOpenByListing(listingID ids.ListingID) ([]*domain.ListingReport, error) {
+	return r.filter(func(report *domain.ListingReport) bool {
+		return report.ListingID == listingID && report.IsOpen()
+	}), nil
+}
+
+func (r *fakeListingReportRepository) FindByStatus(status domain.ReportStatus, limit, offset int) ([]*domain.ListingReport, int64, error) {
+	reports := r.filter(func(report *domain.ListingReport) bool { return report.Status == status })
+	total := int64(len(reports))
+	if offset >= len(reports) {
+		return nil, total, nil
+	}
+	reports = reports[offset:]
+	if len(reports) > limit {
+		reports = reports[:limit]
+	}
+	return reports, total, nil
+}
+
+// filter returns copies of the matching reports, oldest first
+func (r *fakeListingReportRepository) filter(match func(*domain.ListingReport) bool) []*domain.ListingReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var reports []*domain.ListingReport
+	for _, report := range r.reports {
+		if match(report) {
+			copied := *report
+			reports = append(reports, &copied)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].CreatedAt.Before(reports[j].CreatedAt) })
+	return reports
+}
+
+// fakeListingImportRepository is an in-memory ListingImportRepository
+type fakeListingImportRepository struct {
+	mu      sync.Mutex
+	imports map[string]*domain.ListingImport
+	rows    map[string][]*domain.ListingImportRow
+}
+
+func newFakeListingImportRepository() *fakeListingImportRepository {
+	return &fakeListingImportRepository{
+		imports: make(map[string]*domain.ListingImport),
+		rows:    make(map[string][]*domain.ListingImportRow),
+	}
+}
+
+func (r *fakeListingImportRepository) Save(listingImport *domain.ListingImport, rows []*domain.ListingImportRow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *listingImport
+	r.imports[listingImport.ID] = &copied
+	for _, row := range rows {
+		copiedRow := *row
+		r.rows[row.ImportID] = append(r.rows[row.ImportID], &copiedRow)
+	}
+	return nil
+}
+
+func (r *fakeListingImportRepository) Update(listingImport *domain.ListingImport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *listingImport
+	r.imports[listingImport.ID] = &copied
+	return nil
+}
+
+func (r *fakeListingImportRepository) FindByID(id string) (*domain.ListingImport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	listingImport, ok := r.imports[id]
+	if !ok {
+		return nil, errors.NotFoundError("import not found")
+	}
+	copied := *listingImport
+	return &copied, nil
+}
+
+func (r *fakeListingImportRepository) FindBySeller(sellerID ids.UserID, limit, offset int) ([]*domain.ListingImport, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var imports []*domain.ListingImport
+	for _, listingImport := range r.imports {
+		if listingImport.SellerID == sellerID {
+			copied := *listingImport
+			imports = append(imports, &copied)
+		}
+	}
+	total := int64(len(imports))
+	if offset >= len(imports) {
+		return nil, total, nil
+	}
+	imports = imports[offset:]
+	if len(imports) > limit {
+		imports = imports[:limit]
+	}
+	return imports, total, nil
+}
+
+func (r *fakeListingImportRepository) FindUnfinished(limit int) ([]*domain.ListingImport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var imports []*domain.ListingImport
+	for _, listingImport := range r.imports {
+		if !listingImport.IsFinished() && len(imports) < limit {
+			copied := *listingImport
+			imports = append(imports, &copied)
+		}
+	}
+	return imports, nil
+}
+
+func (r *fakeListingImportRepository) FindPendingRows(importID string, limit int) ([]*domain.ListingImportRow, error) {
+	rows, _, err := r.FindRows(importID, domain.ImportRowPending, limit, 0)
+	return rows, err
+}
+
+func (r *fakeListingImportRepository) FindRows(importID string, status domain.ImportRowStatus, limit, offset int) ([]*domain.ListingImportRow, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rows []*domain.ListingImportRow
+	for _, row := range r.rows[importID] {
+		if status == "" || row.Status == status {
+			copied := *row
+			rows = append(rows, &copied)
+		}
+	}
+	total := int64(len(rows))
+	if offset >= len(rows) {
+		return nil, total, nil
+	}
+	rows = rows[offset:]
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, total, nil
+}
+
+func (r *fakeListingImportRepository) CountRows(importID string) (map[domain.ImportRowStatus]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[domain.ImportRowStatus]int)
+	for _, row := range r.rows[importID] {
+		counts[row.Status]++
+	}
+	return counts, nil
+}
+
+func (r *fakeListingImportRepository) UpdateRow(row *domain.ListingImportRow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, stored := range r.rows[row.ImportID] {
+		if stored.Line == row.Line {
+			copied := *row
+			r.rows[row.ImportID][i] = &copied
+			return nil
+		}
+	}
+	return errors.NotFoundError("import row not found")
+}
+
+// fakePriceHistoryRepository is an in-memory PriceHistoryRepository
+type fakePriceHistoryRepository struct {
+	changes []*domain.PriceChange
+}
+
+func (r *fakePriceHistoryRepository) Save(change *domain.PriceChange) error {
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func (r *fakePriceHistoryRepository) FindByListing(listingID ids.ListingID, limit int) ([]*domain.PriceChange, error) {
+	var changes []*domain.PriceChange
+	for i := len(r.changes) - 1; i >= 0 && len(changes) < limit; i-- {
+		if r.changes[i].ListingID == listingID {
+			changes = append(changes, r.changes[i])
+		}
+	}
+	return changes, nil
+}
+
+type fakeListingStatsRepository struct {
+	mu     sync.Mutex
+	stats  map[string]*domain.ListingDailyStats
+	events map[string]bool
+}
+
+func newFakeListingStatsRepository() *fakeListingStatsRepository {
+	return &fakeListingStatsRepository{
+		stats:  make(map[string]*domain.ListingDailyStats),
+		events: make(map[string]bool),
+	}
+}
+
+func (r *fakeListingStatsRepository) Record(eventID string, listingID ids.ListingID, sellerID ids.UserID, day time.Time, activity domain.ListingActivity, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if eventID != "" {
+		if r.events[eventID] {
+			return false, nil
+		}
+		r.events[eventID] = true
+	}
+
+	key := listingID.String() + "/" + day.Format(domain.AnalyticsDayFormat)
+	stats, ok := r.stats[key]
+	if !ok {
+		stats = &domain.ListingDailyStats{ListingID: listingID, Day: day}
+		r.stats[key] = stats
+	}
+	stats.SellerID = sellerID
+	stats.Views += activity.Views
+	stats.Favorites += activity.Favorites
+	stats.Messages += activity.Messages
+	stats.Orders += activity.Orders
+	stats.UpdatedAt = at
+	return true, nil
+}
+
+func (r *fakeListingStatsRepository) FindBySeller(sellerID ids.UserID, from, to time.Time) ([]*domain.ListingDailyStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var stats []*domain.ListingDailyStats
+	for _, stat := range r.stats {
+		if stat.SellerID == sellerID && !stat.Day.Before(from) && !stat.Day.After(to) {
+			copied := *stat
+			stats = append(stats, &copied)
+		}
+	}
+	return stats, nil
+}
+
+func (r *fakeListingStatsRepository) FindActiveSince(from time.Time) ([]*domain.ListingDailyStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var stats []*domain.ListingDailyStats
+	for _, stat := range r.stats {
+		if !stat.Day.Before(from) {
+			copied := *stat
+			stats = append(stats, &copied)
+		}
+	}
+	return stats, nil
+}
+
+// fakeTagRepository is an in-memory TagRepository. Tags are used by the
+// listings they are set on, whatever their status.
+type fakeTagRepository struct {
+	mu        sync.Mutex
+	tags      map[string]*domain.ListingTag
+	relations map[ids.ListingID][]string
+	trending  int
+}
+
+func newFakeTagRepository(tags ...*domain.ListingTag) *fakeTagRepository {
+	repo := &fakeTagRepository{tags: make(map[string]*domain.ListingTag), relations: make(map[ids.ListingID][]string)}
+	for _, tag := range tags {
+		repo.tags[tag.ID] = tag
+	}
+	return repo
+}
+
+func (r *fakeTagRepository) Save(tag *domain.ListingTag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.tags {
+		if existing.Name == tag.Name {
+			return errors.ConflictError("a tag with this name already exists")
+		}
+	}
+	r.tags[tag.ID] = tag
+	return nil
+}
+
+func (r *fakeTagRepository) FindByID(id string) (*domain.ListingTag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tag, ok := r.tags[id]; ok {
+		return tag, nil
+	}
+	return nil, errors.NotFoundError("tag not found")
+}
+
+func (r *fakeTagRepository) FindByNames(names []string) ([]*domain.ListingTag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tags []*domain.ListingTag
+	for _, tag := range r.tags {
+		if contains(names, tag.Name) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func (r *fakeTagRepository) List(prefix string, limit, offset int) ([]*domain.TagCount, int64, error) {
+	counts := r.counts(prefix)
+	total := int64(len(counts))
+	if offset > len(counts) {
+		offset = len(counts)
+	}
+	counts = counts[offset:]
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts, total, nil
+}
+
+func (r *fakeTagRepository) FindTrending(since time.Time, limit int) ([]*domain.TagCount, error) {
+	r.mu.Lock()
+	r.trending++
+	r.mu.Unlock()
+	return r.FindPopular("", "", limit)
+}
+
+func (r *fakeTagRepository) FindPopular(categoryID, prefix string, limit int) ([]*domain.TagCount, error) {
+	var used []*domain.TagCount
+	for _, count := range r.counts(prefix) {
+		if count.Listings > 0 {
+			used = append(used, count)
+		}
+	}
+	if len(used) > limit {
+		used = used[:limit]
+	}
+	return used, nil
+}
+
+func (r *fakeTagRepository) FindListingIDs(tagID string) ([]ids.ListingID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var listingIDs []ids.ListingID
+	for listingID, tagIDs := range r.relations {
+		if contains(tagIDs, tagID) {
+			listingIDs = append(listingIDs, listingID)
+		}
+	}
+	return listingIDs, nil
+}
+
+func (r *fakeTagRepository) SetListingTags(listingID ids.ListingID, tagIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.relations[listingID] = append([]string(nil), tagIDs...)
+	return nil
+}
+
+func (r *fakeTagRepository) Update(tag *domain.ListingTag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tags[tag.ID] = tag
+	return nil
+}
+
+func (r *fakeTagRepository) Merge(targetID string, sourceIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for listingID, tagIDs := range r.relations {
+		var merged []string
+		for _, tagID := range tagIDs {
+			if contains(sourceIDs, tagID) {
+				tagID = targetID
+			}
+			if !contains(merged, tagID) {
+				merged = append(merged, tagID)
+			}
+		}
+		r.relations[listingID] = merged
+	}
+	for _, sourceID := range sourceIDs {
+		delete(r.tags, sourceID)
+	}
+	return nil
+}
+
+func (r *fakeTagRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tags, id)
+	for listingID, tagIDs := range r.relations {
+		var kept []string
+		for _, tagID := range tagIDs {
+			if tagID != id {
+				kept = append(kept, tagID)
+			}
+		}
+		r.relations[listingID] = kept
+	}
+	return nil
+}
+
+// counts counts the listings of the tags starting with prefix, most used first
+func (r *fakeTagRepository) counts(prefix string) []*domain.TagCount {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var counts []*domain.TagCount
+	for _, tag := range r.tags {
+		if !strings.HasPrefix(tag.Name, prefix) {
+			continue
+		}
+		count := &domain.TagCount{ListingTag: *tag}
+		for _, tagIDs := range r.relations {
+			if contains(tagIDs, tag.ID) {
+				count.Listings++
+			}
+		}
+		counts = append(counts, count)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Listings != counts[j].Listings {
+			return counts[i].Listings > counts[j].Listings
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+// fakeListingTemplateRepository is an in-memory ListingTemplateRepository
+type fakeListingTemplateRepository struct {
+	mu        sync.Mutex
+	templates map[string]*domain.ListingTemplate
+}
+
+func newFakeListingTemplateRepository() *fakeListingTemplateRepository {
+	return &fakeListingTemplateRepository{templates: make(map[string]*domain.ListingTemplate)}
+}
+
+func (r *fakeListingTemplateRepository) Save(template *domain.ListingTemplate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *template
+	r.templates[template.ID] = &copied
+	return nil
+}
+
+func (r *fakeListingTemplateRepository) Update(template *domain.ListingTemplate) error {
+	return r.Save(template)
+}
+
+func (r *fakeListingTemplateRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.templates, id)
+	return nil
+}
+
+func (r *fakeListingTemplateRepository) FindByID(id string) (*domain.ListingTemplate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	template, ok := r.templates[id]
+	if !ok {
+		return nil, errors.NotFoundError("listing template not found")
+	}
+	copied := *template
+	return &copied, nil
+}
+
+func (r *fakeListingTemplateRepository) FindBySeller(sellerID ids.UserID) ([]*domain.ListingTemplate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var templates []*domain.ListingTemplate
+	for _, template := range r.templates {
+		if template.SellerID == sellerID {
+			copied := *template
+			templates = append(templates, &copied)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].CreatedAt.After(templates[j].CreatedAt) })
+	return templates, nil
+}
+
+func (r *fakeListingTemplateRepository) CountBySeller(sellerID ids.UserID) (int64, error) {
+	templates, err := r.FindBySeller(sellerID)
+	return int64(len(templates)), err
+}
+
+// fakeShareLinkRepository is an in-memory ShareLinkRepository
+type fakeShareLinkRepository struct {
+	mu     sync.Mutex
+	links  map[string]*domain.ShareLink
+	clicks map[string]*domain.ShareLinkDailyClicks
+}
+
+func newFakeShareLinkRepository() *fakeShareLinkRepository {
+	return &fakeShareLinkRepository{
+		links:  make(map[string]*domain.ShareLink),
+		clicks: make(map[string]*domain.ShareLinkDailyClicks),
+	}
+}
+
+func (r *fakeShareLinkRepository) Save(link *domain.ShareLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.links {
+		if existing.Code == link.Code || (existing.ListingID == link.ListingID && existing.Channel == link.Channel) {
+			return errors.ConflictError("resource already exists")
+		}
+	}
+	copied := *link
+	r.links[link.Code] = &copied
+	return nil
+}
+
+func (r *fakeShareLinkRepository) FindByCode(code string) (*domain.ShareLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link, ok := r.links[code]
+	if !ok {
+		return nil, errors.NotFoundError("share link not found")
+	}
+	copied := *link
+	return &copied, nil
+}
+
+func (r *fakeShareLinkRepository) FindByListingAndChannel(listingID ids.ListingID, channel domain.ShareChannel) (*domain.ShareLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, link := range r.links {
+		if link.ListingID == listingID && link.Channel == channel {
+			copied := *link
+			return &copied, nil
+		}
+	}
+	return nil, errors.NotFoundError("share link not found")
+}
+
+func (r *fakeShareLinkRepository) FindByListing(listingID ids.ListingID) ([]*domain.ShareLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var links []*domain.ShareLink
+	for _, link := range r.links {
+		if link.ListingID == listingID {
+			copied := *link
+			links = append(links, &copied)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Channel < links[j].Channel })
+	return links, nil
+}
+
+func (r *fakeShareLinkRepository) RecordClick(code string, day time.Time, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := code + "/" + day.Format(domain.AnalyticsDayFormat)
+	if r.clicks[key] == nil {
+		r.clicks[key] = &domain.ShareLinkDailyClicks{Code: code, Day: day}
+	}
+	r.clicks[key].Clicks++
+	r.clicks[key].UpdatedAt = at
+	return nil
+}
+
+func (r *fakeShareLinkRepository) FindClicks(codes []string, from, to time.Time) ([]*domain.ShareLinkDailyClicks, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wanted := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		wanted[code] = true
+	}
+	var clicks []*domain.ShareLinkDailyClicks
+	for _, click := range r.clicks {
+		if wanted[click.Code] && !click.Day.Before(from) && !click.Day.After(to) {
+			copied := *click
+			clicks = append(clicks, &copied)
+		}
+	}
+	return clicks, nil
+}
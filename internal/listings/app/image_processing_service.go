@@ -0,0 +1,114 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/imaging"
+	"dongome/pkg/storage"
+)
+
+// ProcessedImage is a resized WebP copy of a photo
+type ProcessedImage struct {
+	Name    string
+	Width   int
+	Height  int
+	Content []byte
+}
+
+// ImageProcessor makes resized WebP copies of a photo, stripped of the
+// metadata the original carried. Content that is not a photo it can read is
+// reported as imaging.ErrUnsupportedFormat.
+type ImageProcessor interface {
+	Process(ctx context.Context, original []byte, specs []domain.ImageVariantSpec) ([]ProcessedImage, error)
+}
+
+// ImageProcessingService makes the resized copies of listing photos after
+// they are added, and serves them in place of the originals
+type ImageProcessingService struct {
+	listingRepo domain.ListingRepository
+	store       ImageStore
+	processor   ImageProcessor
+}
+
+// NewImageProcessingService creates a new image processing service
+func NewImageProcessingService(listingRepo domain.ListingRepository, store ImageStore, processor ImageProcessor) *ImageProcessingService {
+	return &ImageProcessingService{
+		listingRepo: listingRepo,
+		store:       store,
+		processor:   processor,
+	}
+}
+
+// ProcessListingImage stores the copies of one of a listing's photos, records
+// their URLs on the photo and deletes the original, so its EXIF and GPS
+// metadata are no longer served. It returns whether the photo was processed;
+// photos already processed, removed, whose original is gone or cannot be
+// read are skipped, so the same event can safely be handled twice.
+func (s *ImageProcessingService) ProcessListingImage(ctx context.Context, listingID ids.ListingID, imageID string) (bool, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	image := listing.Image(imageID)
+	if image == nil || image.IsProcessed() || image.StorageKey == "" {
+		return false, nil
+	}
+
+	original, err := s.readOriginal(ctx, image.StorageKey)
+	if err == storage.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	processed, err := s.processor.Process(ctx, original, domain.ImageVariantSpecs)
+	if stderrors.Is(err, imaging.ErrUnsupportedFormat) {
+		// Retrying would fail the same way; the original stays in use
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	variants := make([]domain.ImageVariant, 0, len(processed))
+	for _, result := range processed {
+		url, err := s.store.Put(ctx, domain.ImageVariantKey(image.StorageKey, result.Name), "image/webp", bytes.NewReader(result.Content))
+		if err != nil {
+			return false, err
+		}
+		variants = append(variants, domain.ImageVariant{Name: result.Name, URL: url, Width: result.Width, Height: result.Height})
+	}
+
+	if err := image.SetVariants(variants, time.Now()); err != nil {
+		return false, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.UpdateImage(image) }); err != nil {
+		return false, err
+	}
+	return true, s.store.Delete(ctx, image.StorageKey)
+}
+
+// readOriginal reads the uploaded photo stored under key
+func (s *ImageProcessingService) readOriginal(ctx context.Context, key string) ([]byte, error) {
+	content, err := s.store.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	var original bytes.Buffer
+	if _, err := original.ReadFrom(content); err != nil {
+		return nil, err
+	}
+	return original.Bytes(), nil
+}
@@ -0,0 +1,367 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/storage"
+)
+
+// uploadURLExpiry is how long a presigned photo upload can be used
+const uploadURLExpiry = 15 * time.Minute
+
+// ImageStore stores uploaded photos and returns the URL they are served
+// from. The built-in store writes to a local directory; object storage is
+// plugged in behind the same interface.
+type ImageStore interface {
+	Put(ctx context.Context, key, contentType string, content io.Reader) (string, error)
+	// Open reads the photo stored under key, or returns storage.ErrNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// DirectUploadStore is an ImageStore that sellers' apps can upload photos to
+// directly through presigned requests, sparing the API the photo bytes
+type DirectUploadStore interface {
+	ImageStore
+	URL(key string) string
+	PresignPut(key, contentType string, size int64, expires time.Duration) *storage.PresignedRequest
+	Stat(ctx context.Context, key string) (*storage.ObjectInfo, error)
+}
+
+// ImageUpload is a photo file received by the staging upload endpoint
+type ImageUpload struct {
+	ContentType string
+	Size        int64
+	Content     io.Reader
+}
+
+// AttachImagesCommand represents the command to attach staged photos to a
+// listing, in the order given
+type AttachImagesCommand struct {
+	ListingID ids.ListingID `json:"-"`
+	SellerID  ids.UserID    `json:"-"`
+	ImageIDs  []string      `json:"image_ids" binding:"required,min=1,max=20,dive,required"`
+}
+
+// RequestImageUploadsCommand represents the command to get presigned uploads
+// for photos of one of the seller's listings
+type RequestImageUploadsCommand struct {
+	ListingID ids.ListingID        `json:"-"`
+	SellerID  ids.UserID           `json:"-"`
+	Images    []ImageUploadRequest `json:"images" binding:"required,min=1,max=20,dive"`
+}
+
+// ImageUploadRequest describes a photo the seller's app is about to upload
+type ImageUploadRequest struct {
+	ContentType string `json:"content_type" binding:"required"`
+	Size        int64  `json:"size" binding:"required,gt=0"`
+}
+
+// ImageUploadURL is a staged photo with the request that uploads it
+type ImageUploadURL struct {
+	Image  *domain.StagedImage       `json:"image"`
+	Upload *storage.PresignedRequest `json:"upload"`
+}
+
+// ImageService handles staged photo uploads and attaching them to listings
+type ImageService struct {
+	stagedRepo  domain.StagedImageRepository
+	listingRepo domain.ListingRepository
+	store       ImageStore
+	eventBus    events.EventBus
+}
+
+// NewImageService creates a new image service
+func NewImageService(stagedRepo domain.StagedImageRepository, listingRepo domain.ListingRepository, store ImageStore, eventBus events.EventBus) *ImageService {
+	return &ImageService{
+		stagedRepo:  stagedRepo,
+		listingRepo: listingRepo,
+		store:       store,
+		eventBus:    eventBus,
+	}
+}
+
+// StageImages stores photos uploaded ahead of a listing and returns their
+// temporary asset IDs. Photos not attached to a listing within
+// StagedImageTTL are cleaned up.
+func (s *ImageService) StageImages(ctx context.Context, ownerID ids.UserID, uploads []ImageUpload) ([]*domain.StagedImage, error) {
+	if len(uploads) == 0 {
+		return nil, errors.ValidationError("at least one photo is required")
+	}
+	if len(uploads) > domain.MaxImagesPerUpload {
+		return nil, errors.ValidationError(fmt.Sprintf("at most %d photos can be uploaded at once", domain.MaxImagesPerUpload))
+	}
+	for _, upload := range uploads {
+		if err := domain.ValidateImage(upload.ContentType, upload.Size); err != nil {
+			return nil, err
+		}
+	}
+
+	pending, err := s.stagedRepo.CountPendingByOwner(ownerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if pending+int64(len(uploads)) > domain.MaxStagedImagesPerOwner {
+		return nil, errors.ValidationError(fmt.Sprintf("at most %d photos can wait to be attached, attach some to a listing first", domain.MaxStagedImagesPerOwner))
+	}
+
+	staged := make([]*domain.StagedImage, 0, len(uploads))
+	for _, upload := range uploads {
+		id := domain.NewStagedImageID()
+		key := ownerID.String() + "/" + id + domain.ImageContentTypes[upload.ContentType]
+
+		url, err := s.store.Put(ctx, key, upload.ContentType, upload.Content)
+		if err != nil {
+			return staged, err
+		}
+
+		image, err := domain.NewStagedImage(id, ownerID, key, url, upload.ContentType, upload.Size)
+		if err != nil {
+			return staged, err
+		}
+
+		if err := db.WithRetry(ctx, func() error { return s.stagedRepo.Save(image) }); err != nil {
+			// Don't leave an untracked file behind
+			_ = s.store.Delete(ctx, key)
+			return staged, err
+		}
+		staged = append(staged, image)
+	}
+	return staged, nil
+}
+
+// RequestImageUploads stages photos the seller's app uploads straight to
+// storage and returns the presigned request for each. The photos are added to
+// the listing by ConfirmImageUploads once they are uploaded.
+func (s *ImageService) RequestImageUploads(ctx context.Context, cmd RequestImageUploadsCommand) ([]*ImageUploadURL, error) {
+	store, ok := s.store.(DirectUploadStore)
+	if !ok {
+		return nil, errors.UnavailableError("direct photo uploads are not available")
+	}
+	if len(cmd.Images) == 0 {
+		return nil, errors.ValidationError("at least one photo is required")
+	}
+	if len(cmd.Images) > domain.MaxImagesPerUpload {
+		return nil, errors.ValidationError(fmt.Sprintf("at most %d photos can be uploaded at once", domain.MaxImagesPerUpload))
+	}
+	for _, image := range cmd.Images {
+		if err := domain.ValidateImage(image.ContentType, image.Size); err != nil {
+			return nil, err
+		}
+	}
+
+	listing, err := s.sellerListing(cmd.ListingID, cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(listing.Images)+len(cmd.Images) > domain.MaxListingImages {
+		return nil, errors.ValidationError(fmt.Sprintf("a listing can have at most %d photos", domain.MaxListingImages))
+	}
+
+	pending, err := s.stagedRepo.CountPendingByOwner(cmd.SellerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if pending+int64(len(cmd.Images)) > domain.MaxStagedImagesPerOwner {
+		return nil, errors.ValidationError(fmt.Sprintf("at most %d photos can wait to be attached, attach some to a listing first", domain.MaxStagedImagesPerOwner))
+	}
+
+	uploads := make([]*ImageUploadURL, 0, len(cmd.Images))
+	for _, request := range cmd.Images {
+		id := domain.NewStagedImageID()
+		key := cmd.SellerID.String() + "/" + id + domain.ImageContentTypes[request.ContentType]
+
+		image, err := domain.NewAwaitingImage(id, cmd.SellerID, key, store.URL(key), request.ContentType, request.Size)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.WithRetry(ctx, func() error { return s.stagedRepo.Save(image) }); err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, &ImageUploadURL{
+			Image:  image,
+			Upload: store.PresignPut(key, request.ContentType, request.Size, uploadURLExpiry),
+		})
+	}
+	return uploads, nil
+}
+
+// ConfirmImageUploads checks that photos requested with RequestImageUploads
+// reached storage and adds them to the listing, in the order given, like
+// AttachImages
+func (s *ImageService) ConfirmImageUploads(ctx context.Context, cmd AttachImagesCommand) (*domain.Listing, error) {
+	store, ok := s.store.(DirectUploadStore)
+	if !ok {
+		return nil, errors.UnavailableError("direct photo uploads are not available")
+	}
+	if _, err := s.sellerListing(cmd.ListingID, cmd.SellerID); err != nil {
+		return nil, err
+	}
+
+	found, err := s.stagedRepo.FindByIDs(cmd.SellerID, uniqueIDs(cmd.ImageIDs))
+	if err != nil {
+		return nil, err
+	}
+	var confirmed []*domain.StagedImage
+	for _, image := range found {
+		if !image.AwaitingUpload {
+			continue
+		}
+		info, err := store.Stat(ctx, image.StorageKey)
+		if err == storage.ErrNotFound {
+			return nil, errors.ValidationError(fmt.Sprintf("photo %s has not been uploaded yet", image.ID))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := image.ConfirmUpload(info.ContentType, info.Size); err != nil {
+			return nil, err
+		}
+		confirmed = append(confirmed, image)
+	}
+
+	if len(confirmed) > 0 {
+		if err := db.WithRetry(ctx, func() error { return s.stagedRepo.UpdateAll(confirmed) }); err != nil {
+			return nil, err
+		}
+	}
+	return s.AttachImages(ctx, cmd)
+}
+
+// AttachImages adds staged photos to one of the seller's listings, after the
+// photos it already has. Photos already on the listing are skipped, so the
+// same request can safely be retried.
+func (s *ImageService) AttachImages(ctx context.Context, cmd AttachImagesCommand) (*domain.Listing, error) {
+	listing, err := s.sellerListing(cmd.ListingID, cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	imageIDs := uniqueIDs(cmd.ImageIDs)
+	found, err := s.stagedRepo.FindByIDs(cmd.SellerID, imageIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.StagedImage, len(found))
+	for _, image := range found {
+		byID[image.ID] = image
+	}
+
+	now := time.Now()
+	images := make([]*domain.StagedImage, 0, len(imageIDs))
+	added := 0
+	for _, id := range imageIDs {
+		image, ok := byID[id]
+		if !ok {
+			return nil, errors.NotFoundError(fmt.Sprintf("photo %s not found", id))
+		}
+		if err := image.AttachTo(listing.ID, now); err != nil {
+			return nil, err
+		}
+		if !listing.HasImage(image) {
+			added++
+		}
+		images = append(images, image)
+	}
+	if len(listing.Images)+added > domain.MaxListingImages {
+		return nil, errors.ValidationError(fmt.Sprintf("a listing can have at most %d photos", domain.MaxListingImages))
+	}
+
+	// Mark the photos attached first so cleanup never removes a photo the
+	// listing is about to use
+	if err := db.WithRetry(ctx, func() error { return s.stagedRepo.UpdateAll(images) }); err != nil {
+		return nil, err
+	}
+
+	if added == 0 {
+		return listing, nil
+	}
+	uploaded := make([]domain.ListingImage, 0, added)
+	for _, image := range images {
+		if !listing.HasImage(image) {
+			uploaded = append(uploaded, listing.AddStagedImage(image))
+		}
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return nil, err
+	}
+
+	// Publish ListingImageUploaded events so the worker processes the photos
+	for _, image := range uploaded {
+		event, err := events.NewEvent(
+			domain.ListingImageUploadedEvent,
+			listing.ID.String(),
+			domain.ListingImageUploaded{
+				ListingID:  listing.ID,
+				ImageID:    image.ID,
+				StorageKey: image.StorageKey,
+				Timestamp:  now,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.eventBus.Publish(ctx, event); err != nil {
+			return nil, err
+		}
+	}
+	return listing, nil
+}
+
+// CleanupExpiredImages deletes up to limit photos that were never attached
+// to a listing within StagedImageTTL and returns how many were removed
+func (s *ImageService) CleanupExpiredImages(ctx context.Context, limit int) (int, error) {
+	images, err := s.stagedRepo.FindExpired(time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, image := range images {
+		if err := s.store.Delete(ctx, image.StorageKey); err != nil {
+			return removed, err
+		}
+		if err := db.WithRetry(ctx, func() error { return s.stagedRepo.Delete(image.ID) }); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// sellerListing finds one of the seller's listings that photos can be added to
+func (s *ImageService) sellerListing(listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID != sellerID {
+		return nil, errors.ForbiddenError("listing does not belong to the seller")
+	}
+	if listing.Status == domain.ListingStatusSold {
+		return nil, errors.ValidationError("photos cannot be added to a sold listing")
+	}
+	return listing, nil
+}
+
+// uniqueIDs drops repeated IDs, keeping the first occurrence
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
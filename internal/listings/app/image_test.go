@@ -0,0 +1,242 @@
+package app_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newImageUpload(content string) app.ImageUpload {
+	return app.ImageUpload{ContentType: "image/jpeg", Size: int64(len(content)), Content: strings.NewReader(content)}
+}
+
+func TestImageService_StageAndAttach(t *testing.T) {
+	listing := newDraftListing(t, "seller-a", "Phone")
+	listings := newFakeListingRepository(listing)
+	staged := newFakeStagedImageRepository()
+	store := newFakeImageStore()
+	service := app.NewImageService(staged, listings, store, &fakeEventBus{})
+	ctx := context.Background()
+
+	images, err := service.StageImages(ctx, "seller-a", []app.ImageUpload{newImageUpload("front"), newImageUpload("back")})
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	assert.Len(t, store.files, 2)
+	assert.False(t, images[0].IsAttached())
+
+	// Only JPEG, PNG and WebP photos are accepted
+	_, err = service.StageImages(ctx, "seller-a", []app.ImageUpload{{ContentType: "application/pdf", Size: 3, Content: strings.NewReader("pdf")}})
+	assert.Error(t, err)
+
+	// Photos can only be attached by their owner to their own listing
+	_, err = service.AttachImages(ctx, app.AttachImagesCommand{ListingID: listing.ID, SellerID: "seller-b", ImageIDs: []string{images[0].ID}})
+	assert.Error(t, err)
+
+	// Photos are added in the order given
+	updated, err := service.AttachImages(ctx, app.AttachImagesCommand{ListingID: listing.ID, SellerID: "seller-a", ImageIDs: []string{images[1].ID, images[0].ID}})
+	require.NoError(t, err)
+	require.Len(t, updated.Images, 2)
+	assert.Equal(t, images[1].URL, updated.Images[0].URL)
+	assert.Equal(t, images[0].URL, updated.Images[1].URL)
+	assert.True(t, images[0].IsAttached())
+
+	// Retrying the same request adds nothing
+	updated, err = service.AttachImages(ctx, app.AttachImagesCommand{ListingID: listing.ID, SellerID: "seller-a", ImageIDs: []string{images[0].ID}})
+	require.NoError(t, err)
+	assert.Len(t, updated.Images, 2)
+
+	// A photo attached to one listing cannot be attached to another
+	other := newDraftListing(t, "seller-a", "Laptop")
+	require.NoError(t, listings.Save(other))
+	_, err = service.AttachImages(ctx, app.AttachImagesCommand{ListingID: other.ID, SellerID: "seller-a", ImageIDs: []string{images[0].ID}})
+	assert.Error(t, err)
+
+	// Unknown photos are not found
+	_, err = service.AttachImages(ctx, app.AttachImagesCommand{ListingID: listing.ID, SellerID: "seller-a", ImageIDs: []string{"missing"}})
+	assert.Error(t, err)
+}
+
+func TestImageService_CleanupExpiredImages(t *testing.T) {
+	listing := newDraftListing(t, "seller-a", "Phone")
+	staged := newFakeStagedImageRepository()
+	store := newFakeImageStore()
+	service := app.NewImageService(staged, newFakeListingRepository(listing), store, &fakeEventBus{})
+	ctx := context.Background()
+
+	images, err := service.StageImages(ctx, "seller-a", []app.ImageUpload{newImageUpload("kept"), newImageUpload("orphan"), newImageUpload("fresh")})
+	require.NoError(t, err)
+	_, err = service.AttachImages(ctx, app.AttachImagesCommand{ListingID: listing.ID, SellerID: "seller-a", ImageIDs: []string{images[0].ID}})
+	require.NoError(t, err)
+
+	// The first two photos were uploaded more than a day ago
+	images[0].ExpiresAt = time.Now().Add(-time.Minute)
+	images[1].ExpiresAt = time.Now().Add(-time.Minute)
+
+	removed, err := service.CleanupExpiredImages(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.NotContains(t, store.files, images[1].StorageKey)
+	assert.Contains(t, store.files, images[0].StorageKey, "attached photos are kept")
+	assert.Contains(t, store.files, images[2].StorageKey, "photos still waiting are kept")
+
+	// Expired photos can no longer be attached
+	images[2].ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = service.AttachImages(ctx, app.AttachImagesCommand{ListingID: listing.ID, SellerID: "seller-a", ImageIDs: []string{images[2].ID}})
+	assert.Error(t, err)
+	assert.Len(t, listing.Images, 1)
+}
+
+func TestImageService_DirectUploads(t *testing.T) {
+	listing := newDraftListing(t, "seller-a", "Phone")
+	staged := newFakeStagedImageRepository()
+	store := newFakeDirectUploadStore()
+	service := app.NewImageService(staged, newFakeListingRepository(listing), store, &fakeEventBus{})
+	ctx := context.Background()
+
+	uploads, err := service.RequestImageUploads(ctx, app.RequestImageUploadsCommand{
+		ListingID: listing.ID,
+		SellerID:  "seller-a",
+		Images:    []app.ImageUploadRequest{{ContentType: "image/jpeg", Size: 5}, {ContentType: "image/png", Size: 4}},
+	})
+	require.NoError(t, err)
+	require.Len(t, uploads, 2)
+	assert.True(t, uploads[0].Image.AwaitingUpload)
+	assert.Equal(t, "image/jpeg", uploads[0].Upload.Headers["Content-Type"])
+	assert.Equal(t, "5", uploads[0].Upload.Headers["Content-Length"])
+
+	// Photos cannot be confirmed before they reach storage
+	confirm := app.AttachImagesCommand{ListingID: listing.ID, SellerID: "seller-a", ImageIDs: []string{uploads[1].Image.ID, uploads[0].Image.ID}}
+	_, err = service.ConfirmImageUploads(ctx, confirm)
+	assert.Error(t, err)
+
+	// Nor attached as if they had been uploaded through the API
+	_, err = service.AttachImages(ctx, confirm)
+	assert.Error(t, err)
+
+	// Once uploaded they are added in the order given
+	store.upload(uploads[0].Image.StorageKey, "image/jpeg", "front")
+	store.upload(uploads[1].Image.StorageKey, "image/png", "back")
+	updated, err := service.ConfirmImageUploads(ctx, confirm)
+	require.NoError(t, err)
+	require.Len(t, updated.Images, 2)
+	assert.Equal(t, uploads[1].Image.URL, updated.Images[0].URL)
+	assert.Equal(t, uploads[0].Image.URL, updated.Images[1].URL)
+	assert.False(t, uploads[0].Image.AwaitingUpload)
+
+	// What reached storage must still be a photo
+	more, err := service.RequestImageUploads(ctx, app.RequestImageUploadsCommand{
+		ListingID: listing.ID,
+		SellerID:  "seller-a",
+		Images:    []app.ImageUploadRequest{{ContentType: "image/jpeg", Size: 5}},
+	})
+	require.NoError(t, err)
+	store.upload(more[0].Image.StorageKey, "text/html", "<html>")
+	_, err = service.ConfirmImageUploads(ctx, app.AttachImagesCommand{ListingID: listing.ID, SellerID: "seller-a", ImageIDs: []string{more[0].Image.ID}})
+	assert.Error(t, err)
+}
+
+func TestImageService_DirectUploadLimits(t *testing.T) {
+	listing := newDraftListing(t, "seller-a", "Phone")
+	listings := newFakeListingRepository(listing)
+	ctx := context.Background()
+
+	// Stores that cannot presign requests do not offer direct uploads
+	service := app.NewImageService(newFakeStagedImageRepository(), listings, newFakeImageStore(), &fakeEventBus{})
+	_, err := service.RequestImageUploads(ctx, app.RequestImageUploadsCommand{
+		ListingID: listing.ID,
+		SellerID:  "seller-a",
+		Images:    []app.ImageUploadRequest{{ContentType: "image/jpeg", Size: 5}},
+	})
+	assert.Error(t, err)
+
+	service = app.NewImageService(newFakeStagedImageRepository(), listings, newFakeDirectUploadStore(), &fakeEventBus{})
+
+	// Only the listing's owner can upload photos to it
+	_, err = service.RequestImageUploads(ctx, app.RequestImageUploadsCommand{
+		ListingID: listing.ID,
+		SellerID:  "seller-b",
+		Images:    []app.ImageUploadRequest{{ContentType: "image/jpeg", Size: 5}},
+	})
+	assert.Error(t, err)
+
+	// Only photos are accepted
+	_, err = service.RequestImageUploads(ctx, app.RequestImageUploadsCommand{
+		ListingID: listing.ID,
+		SellerID:  "seller-a",
+		Images:    []app.ImageUploadRequest{{ContentType: "application/pdf", Size: 5}},
+	})
+	assert.Error(t, err)
+
+	// A listing cannot be given more photos than it can hold
+	requests := make([]app.ImageUploadRequest, domain.MaxListingImages+1)
+	for i := range requests {
+		requests[i] = app.ImageUploadRequest{ContentType: "image/jpeg", Size: 5}
+	}
+	_, err = service.RequestImageUploads(ctx, app.RequestImageUploadsCommand{ListingID: listing.ID, SellerID: "seller-a", Images: requests})
+	assert.Error(t, err)
+}
+
+func TestImageProcessingService_ProcessListingImage(t *testing.T) {
+	listing := newDraftListing(t, "seller-a", "Phone")
+	listings := newFakeListingRepository(listing)
+	store := newFakeImageStore()
+	bus := &fakeEventBus{}
+	images := app.NewImageService(newFakeStagedImageRepository(), listings, store, bus)
+	service := app.NewImageProcessingService(listings, store, &fakeImageProcessor{})
+	ctx := context.Background()
+
+	staged, err := images.StageImages(ctx, "seller-a", []app.ImageUpload{newImageUpload("front"), newImageUpload("unreadable")})
+	require.NoError(t, err)
+	_, err = images.AttachImages(ctx, app.AttachImagesCommand{ListingID: listing.ID, SellerID: "seller-a", ImageIDs: []string{staged[0].ID, staged[1].ID}})
+	require.NoError(t, err)
+
+	// Every added photo is announced for processing
+	published := bus.eventsOfType(domain.ListingImageUploadedEvent)
+	require.Len(t, published, 2)
+	var uploaded domain.ListingImageUploaded
+	require.NoError(t, events.ParseEventData(published[0], &uploaded))
+	assert.Equal(t, listing.Images[0].ID, uploaded.ImageID)
+	assert.Equal(t, staged[0].StorageKey, uploaded.StorageKey)
+
+	processed, err := service.ProcessListingImage(ctx, listing.ID, uploaded.ImageID)
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	// The copies are stored next to the original, which is deleted
+	image := listing.Images[0]
+	require.Len(t, image.Variants, len(domain.ImageVariantSpecs))
+	assert.NotNil(t, image.ProcessedAt)
+	assert.Equal(t, "thumbnail:front", string(store.files[domain.ImageVariantKey(staged[0].StorageKey, domain.ImageVariantThumbnail)]))
+	assert.NotContains(t, store.files, staged[0].StorageKey)
+	assert.Equal(t, "https://media.example.com/"+domain.ImageVariantKey(staged[0].StorageKey, domain.ImageVariantFull), image.URL)
+
+	// Handling the event again changes nothing
+	processed, err = service.ProcessListingImage(ctx, listing.ID, uploaded.ImageID)
+	require.NoError(t, err)
+	assert.False(t, processed)
+
+	// Retrying the attach request does not add the processed photo again
+	updated, err := images.AttachImages(ctx, app.AttachImagesCommand{ListingID: listing.ID, SellerID: "seller-a", ImageIDs: []string{staged[0].ID}})
+	require.NoError(t, err)
+	assert.Len(t, updated.Images, 2)
+
+	// Photos that cannot be read keep their original
+	processed, err = service.ProcessListingImage(ctx, listing.ID, listing.Images[1].ID)
+	require.NoError(t, err)
+	assert.False(t, processed)
+	assert.Equal(t, staged[1].URL, listing.Images[1].URL)
+	assert.Contains(t, store.files, staged[1].StorageKey)
+
+	// Photos of listings that are gone are skipped
+	processed, err = service.ProcessListingImage(ctx, "missing", uploaded.ImageID)
+	require.NoError(t, err)
+	assert.False(t, processed)
+}
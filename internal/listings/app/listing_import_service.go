@@ -0,0 +1,302 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// listingImportsPerRun is how many unfinished imports each run works on
+const listingImportsPerRun = 5
+
+// SubmitListingImportCommand represents a seller uploading a CSV file of listings
+type SubmitListingImportCommand struct {
+	SellerID ids.UserID
+	FileName string
+	Content  io.Reader
+}
+
+// ListListingImportsQuery represents the query to list a seller's imports
+type ListListingImportsQuery struct {
+	SellerID ids.UserID `form:"-"`
+	Limit    int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset   int        `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ListImportRowsQuery represents the query to list the per-row results of an import
+type ListImportRowsQuery struct {
+	Status domain.ImportRowStatus `form:"status" binding:"omitempty,oneof=pending created failed"`
+	Limit  int                    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int                    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ListingImports represents a page of a seller's imports
+type ListingImports struct {
+	Imports []*domain.ListingImport `json:"imports"`
+	Total   int64                   `json:"total"`
+	Limit   int                     `json:"limit"`
+	Offset  int                     `json:"offset"`
+}
+
+// ListingImportRows represents a page of an import's per-row results
+type ListingImportRows struct {
+	Rows   []*domain.ListingImportRow `json:"rows"`
+	Total  int64                      `json:"total"`
+	Limit  int                        `json:"limit"`
+	Offset int                        `json:"offset"`
+}
+
+// ListingImportService turns files of listings uploaded by sellers into
+// draft listings. Files are checked and stored from the API and worked
+// through by the worker in batches, recording a result per row.
+type ListingImportService struct {
+	importRepo   domain.ListingImportRepository
+	listingRepo  domain.ListingRepository
+	categoryRepo domain.CategoryRepository
+	locations    LocationValidator
+	suggester    ListingSuggester
+	eventBus     events.EventBus
+	clock        clock.Clock
+}
+
+// NewListingImportService creates a new listing import service. Rows are
+// checked against the attributes the suggester's template for their category
+// uses. clk may be nil to use the system clock.
+func NewListingImportService(importRepo domain.ListingImportRepository, listingRepo domain.ListingRepository, categoryRepo domain.CategoryRepository, locations LocationValidator, suggester ListingSuggester, eventBus events.EventBus, clk clock.Clock) *ListingImportService {
+	return &ListingImportService{
+		importRepo:   importRepo,
+		listingRepo:  listingRepo,
+		categoryRepo: categoryRepo,
+		locations:    locations,
+		suggester:    suggester,
+		eventBus:     eventBus,
+		clock:        clock.OrSystem(clk),
+	}
+}
+
+// SubmitImport reads a CSV file of listings and queues its rows for the
+// worker. The file is refused as a whole if it cannot be read or lacks a
+// required column; problems with single rows are reported per row once the
+// worker gets to them.
+func (s *ListingImportService) SubmitImport(ctx context.Context, cmd SubmitListingImportCommand) (*domain.ListingImport, error) {
+	rows, err := readImportRows(cmd.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	listingImport, importRows, err := domain.NewListingImport(cmd.SellerID, cmd.FileName, rows, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.importRepo.Save(listingImport, importRows) }); err != nil {
+		return nil, err
+	}
+	return listingImport, nil
+}
+
+// GetImport retrieves one of the seller's imports with its progress
+func (s *ListingImportService) GetImport(ctx context.Context, importID string, sellerID ids.UserID) (*domain.ListingImport, error) {
+	listingImport, err := s.importRepo.FindByID(importID)
+	if err != nil {
+		return nil, err
+	}
+	if listingImport.SellerID != sellerID {
+		return nil, errors.ForbiddenError("import does not belong to the seller")
+	}
+	return listingImport, nil
+}
+
+// ListImports lists the seller's imports, newest first
+func (s *ListingImportService) ListImports(ctx context.Context, query ListListingImportsQuery) (*ListingImports, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	imports, total, err := s.importRepo.FindBySeller(query.SellerID, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &ListingImports{
+		Imports: imports,
+		Total:   total,
+		Limit:   limit,
+		Offset:  query.Offset,
+	}, nil
+}
+
+// ListRows lists the per-row results of one of the seller's imports, in file order
+func (s *ListingImportService) ListRows(ctx context.Context, importID string, sellerID ids.UserID, query ListImportRowsQuery) (*ListingImportRows, error) {
+	if _, err := s.GetImport(ctx, importID, sellerID); err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	rows, total, err := s.importRepo.FindRows(importID, query.Status, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &ListingImportRows{
+		Rows:   rows,
+		Total:  total,
+		Limit:  limit,
+		Offset: query.Offset,
+	}, nil
+}
+
+// RunImports works on the oldest unfinished imports, importing up to
+// batchSize rows of each. It returns how many rows were processed; imports
+// not finished are picked up again on the next run.
+func (s *ListingImportService) RunImports(ctx context.Context, batchSize int) (int, error) {
+	imports, err := s.importRepo.FindUnfinished(listingImportsPerRun)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, listingImport := range imports {
+		count, err := s.runImport(ctx, listingImport, batchSize)
+		processed += count
+		if err != nil {
+			return processed, err
+		}
+	}
+	return processed, nil
+}
+
+// runImport imports a batch of an import's rows and records its progress
+func (s *ListingImportService) runImport(ctx context.Context, listingImport *domain.ListingImport, batchSize int) (int, error) {
+	rows, err := s.importRepo.FindPendingRows(listingImport.ID, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		listing, err := s.importRow(ctx, listingImport.SellerID, row)
+		if err != nil {
+			row.Finish("", err, s.clock.Now())
+		} else {
+			row.Finish(listing.ID, nil, s.clock.Now())
+		}
+		if err := db.WithRetry(ctx, func() error { return s.importRepo.UpdateRow(row) }); err != nil {
+			return 0, err
+		}
+	}
+
+	counts, err := s.importRepo.CountRows(listingImport.ID)
+	if err != nil {
+		return len(rows), err
+	}
+	listingImport.RecordProgress(counts, s.clock.Now())
+	err = db.WithRetry(ctx, func() error { return s.importRepo.Update(listingImport) })
+	return len(rows), err
+}
+
+// importRow checks one row and saves it as a draft listing of the seller
+func (s *ListingImportService) importRow(ctx context.Context, sellerID ids.UserID, row *domain.ListingImportRow) (*domain.Listing, error) {
+	details, attributes, err := row.Listing()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := categoryPath(s.categoryRepo, details.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if attributes, err = normalizeAttributes(attributes); err != nil {
+		return nil, err
+	}
+	suggestion, err := s.suggester.Suggest(ctx, domain.SuggestionRequest{
+		CategoryPath: path,
+		Condition:    details.Condition,
+		Attributes:   attributes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(suggestion.MissingAttributes) > 0 {
+		missing := append([]string(nil), suggestion.MissingAttributes...)
+		sort.Strings(missing)
+		return nil, errors.ValidationError("missing attributes: " + strings.Join(missing, ", "))
+	}
+	if details.Location, err = s.locations.ValidateLocation(ctx, details.Location); err != nil {
+		return nil, err
+	}
+
+	listing, err := domain.NewListing(sellerID, details.CategoryID, details.Title, details.Description, details.Price, details.Condition, details.Location)
+	if err != nil {
+		return nil, err
+	}
+	listing.IsNegotiable = details.IsNegotiable
+	addAttributes(listing, attributes)
+
+	if err := saveNewListing(ctx, s.listingRepo, s.eventBus, listing); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// readImportRows reads a CSV file into rows of cells keyed by column. The
+// first line is the header; rows without any cell filled in are skipped.
+// Spreadsheets saved as CSV start with a byte order mark, which is dropped.
+func readImportRows(content io.Reader) ([]domain.ImportRecord, error) {
+	reader := csv.NewReader(content)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.ValidationError("import file must be a CSV file with a header row")
+	}
+	columns := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		columns[i] = domain.ImportColumn(strings.TrimPrefix(name, "\ufeff"))
+		if columns[i] == "" || seen[columns[i]] {
+			return nil, errors.ValidationError("import file columns must be named and unique")
+		}
+		seen[columns[i]] = true
+	}
+	for _, required := range domain.ImportRequiredColumns {
+		if !seen[required] {
+			return nil, errors.ValidationError(fmt.Sprintf("import file is missing the %s column", required))
+		}
+	}
+
+	var rows []domain.ImportRecord
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, errors.ValidationError(fmt.Sprintf("import file could not be read: %v", err))
+		}
+		if len(rows) == domain.MaxImportRows {
+			return nil, errors.ValidationError(fmt.Sprintf("an import file can hold at most %d listings", domain.MaxImportRows))
+		}
+
+		values := make(map[string]string, len(columns))
+		for i, cell := range record {
+			if cell = strings.TrimSpace(cell); cell != "" {
+				values[columns[i]] = cell
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, domain.ImportRecord{Line: line, Values: values})
+	}
+}
@@ -0,0 +1,96 @@
+package app_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListingImportService_ImportsRowsInBatches(t *testing.T) {
+	ctx := context.Background()
+	locations := app.NewLocationService(newFakeLocationRepository(), newFakeListingRepository())
+	_, err := locations.ImportLocations(ctx, app.ImportLocationsCommand{Regions: []app.RegionInput{
+		{Name: "Greater Accra", Cities: []app.CityInput{{Name: "Accra"}}},
+	}})
+	require.NoError(t, err)
+
+	listings := newFakeListingRepository()
+	imports := newFakeListingImportRepository()
+	bus := &fakeEventBus{}
+	suggester := &fakeSuggester{expects: []string{"brand"}}
+	service := app.NewListingImportService(imports, listings, newFakeCategoryRepository(newCategory("phones", "Mobile Phones", nil)), locations, suggester, bus, nil)
+
+	file := "\ufeffTitle,Category_ID,Price,Condition,Region,City,Brand,is_negotiable\n" +
+		"Samsung Galaxy S21,phones,2500,like_new,greater accra,accra,Samsung,false\n" +
+		",,,,,,,\n" +
+		"iPhone 12,phones,3000,good,Greater Accra,Accra,,\n" +
+		"Tecno Spark,phones,cheap,good,Greater Accra,Accra,Tecno,\n" +
+		"Nokia 3310,missing,100,good,Greater Accra,Accra,Nokia,\n"
+	listingImport, err := service.SubmitImport(ctx, app.SubmitListingImportCommand{SellerID: "seller-a", FileName: "phones.csv", Content: strings.NewReader(file)})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingImportPending, listingImport.Status)
+	assert.Equal(t, 4, listingImport.Total, "blank rows are skipped")
+
+	// Only the seller can follow their import
+	_, err = service.GetImport(ctx, listingImport.ID, "seller-b")
+	assert.Error(t, err)
+
+	processed, err := service.RunImports(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	progress, err := service.GetImport(ctx, listingImport.ID, "seller-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingImportPending, progress.Status)
+	assert.Equal(t, 2, progress.Processed)
+
+	_, err = service.RunImports(ctx, 2)
+	require.NoError(t, err)
+	progress, err = service.GetImport(ctx, listingImport.ID, "seller-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingImportCompleted, progress.Status)
+	assert.Equal(t, 1, progress.Created)
+	assert.Equal(t, 3, progress.Failed)
+
+	rows, err := service.ListRows(ctx, listingImport.ID, "seller-a", app.ListImportRowsQuery{})
+	require.NoError(t, err)
+	require.Len(t, rows.Rows, 4)
+	assert.Equal(t, []int{2, 4, 5, 6}, []int{rows.Rows[0].Line, rows.Rows[1].Line, rows.Rows[2].Line, rows.Rows[3].Line})
+	assert.Equal(t, domain.ImportRowCreated, rows.Rows[0].Status)
+	assert.Equal(t, "missing attributes: brand", rows.Rows[1].Error)
+	assert.Equal(t, "price must be a number", rows.Rows[2].Error)
+	assert.Equal(t, "category not found", rows.Rows[3].Error)
+
+	// The created listing is a draft of the seller with the row's details
+	require.NotNil(t, rows.Rows[0].ListingID)
+	listing, err := listings.FindByID(*rows.Rows[0].ListingID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusDraft, listing.Status)
+	assert.Equal(t, "seller-a", string(listing.SellerID))
+	assert.Equal(t, "Greater Accra", listing.Location.Region)
+	assert.False(t, listing.IsNegotiable)
+	require.Len(t, listing.Attributes, 1)
+	assert.Equal(t, "brand", listing.Attributes[0].Key)
+	assert.Len(t, bus.eventsOfType(domain.ListingCreatedEvent), 1)
+}
+
+func TestListingImportService_RejectsUnreadableFiles(t *testing.T) {
+	service := app.NewListingImportService(newFakeListingImportRepository(), newFakeListingRepository(), newFakeCategoryRepository(), nil, &fakeSuggester{}, &fakeEventBus{}, nil)
+	ctx := context.Background()
+
+	for name, file := range map[string]string{
+		"empty":            "",
+		"missing column":   "title,price,condition,region,city\nPhone,100,good,Greater Accra,Accra\n",
+		"duplicate column": "title,title,category_id,price,condition,region,city\n",
+		"no rows":          "title,category_id,price,condition,region,city\n",
+		"ragged row":       "title,category_id,price,condition,region,city\nPhone,phones\n",
+	} {
+		_, err := service.SubmitImport(ctx, app.SubmitListingImportCommand{SellerID: "seller-a", Content: strings.NewReader(file)})
+		assert.Error(t, err, name)
+	}
+}
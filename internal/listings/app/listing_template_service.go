@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// SaveListingTemplateCommand represents the command to save a listing
+// template or replace what a template presets
+type SaveListingTemplateCommand struct {
+	SellerID    ids.UserID        `json:"-"`
+	Name        string            `json:"name" binding:"required,max=100"`
+	CategoryID  string            `json:"category_id" binding:"required,uuid"`
+	Description string            `json:"description"`
+	Condition   string            `json:"condition" binding:"omitempty,oneof=new like_new good fair poor for_parts"`
+	Attributes  map[string]string `json:"attributes"`
+}
+
+// CreateFromTemplateCommand represents the command to start a draft listing
+// from one of the seller's templates. Fields given here are used instead of
+// the template's, and attributes are added to the template's, replacing
+// those of the same name.
+type CreateFromTemplateCommand struct {
+	TemplateID   string            `json:"-"`
+	SellerID     ids.UserID        `json:"-"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Price        float64           `json:"price" binding:"omitempty,gt=0"`
+	Currency     string            `json:"currency" binding:"omitempty,oneof=GHS USD EUR GBP NGN"`
+	Condition    string            `json:"condition" binding:"omitempty,oneof=new like_new good fair poor for_parts"`
+	Location     domain.Location   `json:"location"`
+	IsNegotiable *bool             `json:"is_negotiable"`
+	Attributes   map[string]string `json:"attributes"`
+}
+
+// ListingTemplateService lets sellers keep listing templates and start new
+// listings from them, so repeat sellers do not fill in the same details
+// every time
+type ListingTemplateService struct {
+	templateRepo domain.ListingTemplateRepository
+	listingRepo  domain.ListingRepository
+	eventBus     events.EventBus
+	clock        clock.Clock
+}
+
+// NewListingTemplateService creates a new listing template service
+func NewListingTemplateService(templateRepo domain.ListingTemplateRepository, listingRepo domain.ListingRepository, eventBus events.EventBus, clk clock.Clock) *ListingTemplateService {
+	return &ListingTemplateService{
+		templateRepo: templateRepo,
+		listingRepo:  listingRepo,
+		eventBus:     eventBus,
+		clock:        clock.OrSystem(clk),
+	}
+}
+
+// CreateTemplate saves a listing template for a seller
+func (s *ListingTemplateService) CreateTemplate(ctx context.Context, cmd SaveListingTemplateCommand) (*domain.ListingTemplate, error) {
+	count, err := s.templateRepo.CountBySeller(cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= domain.MaxListingTemplatesPerSeller {
+		return nil, errors.ConflictError("listing template limit reached")
+	}
+
+	template, err := domain.NewListingTemplate(cmd.SellerID, cmd.details(), s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.templateRepo.Save(template) }); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// ListTemplates lists a seller's listing templates, newest first
+func (s *ListingTemplateService) ListTemplates(ctx context.Context, sellerID ids.UserID) ([]*domain.ListingTemplate, error) {
+	return s.templateRepo.FindBySeller(sellerID)
+}
+
+// GetTemplate retrieves one of a seller's listing templates
+func (s *ListingTemplateService) GetTemplate(ctx context.Context, templateID string, sellerID ids.UserID) (*domain.ListingTemplate, error) {
+	return s.ownedTemplate(templateID, sellerID)
+}
+
+// UpdateTemplate replaces what one of a seller's listing templates presets.
+// Listings already made from it are not changed.
+func (s *ListingTemplateService) UpdateTemplate(ctx context.Context, templateID string, cmd SaveListingTemplateCommand) (*domain.ListingTemplate, error) {
+	template, err := s.ownedTemplate(templateID, cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := template.Update(cmd.details(), s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.templateRepo.Update(template) }); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// DeleteTemplate deletes one of a seller's listing templates
+func (s *ListingTemplateService) DeleteTemplate(ctx context.Context, templateID string, sellerID ids.UserID) error {
+	if _, err := s.ownedTemplate(templateID, sellerID); err != nil {
+		return err
+	}
+	return db.WithRetry(ctx, func() error { return s.templateRepo.Delete(templateID) })
+}
+
+// CreateListingFromTemplate starts a draft listing from one of the seller's
+// templates, with the command's details on top of the template's. Like any
+// draft, it is checked in full when it is published.
+func (s *ListingTemplateService) CreateListingFromTemplate(ctx context.Context, cmd CreateFromTemplateCommand) (*domain.Listing, error) {
+	template, err := s.ownedTemplate(cmd.TemplateID, cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	listing, err := domain.NewDraft(cmd.SellerID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	details := listing.Details()
+	details.CategoryID = template.CategoryID
+	details.Title = strings.TrimSpace(cmd.Title)
+	details.Description = template.Description
+	if description := strings.TrimSpace(cmd.Description); description != "" {
+		details.Description = description
+	}
+	details.Price = money.FromMajor(cmd.Price, listingCurrency(cmd.Currency))
+	details.Condition = template.Condition
+	if cmd.Condition != "" {
+		details.Condition = domain.Condition(cmd.Condition)
+	}
+	details.Location = cmd.Location
+	if cmd.IsNegotiable != nil {
+		details.IsNegotiable = *cmd.IsNegotiable
+	}
+	if err := listing.UpdateDetails(details); err != nil {
+		return nil, err
+	}
+	addAttributes(listing, template.AttributesWith(cmd.Attributes))
+
+	if err := saveNewListing(ctx, s.listingRepo, s.eventBus, listing); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// ownedTemplate finds a listing template, checking it belongs to the seller
+func (s *ListingTemplateService) ownedTemplate(templateID string, sellerID ids.UserID) (*domain.ListingTemplate, error) {
+	template, err := s.templateRepo.FindByID(templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.SellerID != sellerID {
+		return nil, errors.ForbiddenError("listing template does not belong to the seller")
+	}
+	return template, nil
+}
+
+// details converts the command into what the template presets
+func (cmd SaveListingTemplateCommand) details() domain.ListingTemplateDetails {
+	return domain.ListingTemplateDetails{
+		Name:        cmd.Name,
+		CategoryID:  cmd.CategoryID,
+		Description: cmd.Description,
+		Condition:   domain.Condition(cmd.Condition),
+		Attributes:  cmd.Attributes,
+	}
+}
@@ -0,0 +1,112 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const templateCategoryID = "7f1c2a9e-4b7d-4c8e-9a51-2d3e4f5a6b7c"
+
+func TestListingTemplateService_CRUD(t *testing.T) {
+	service := app.NewListingTemplateService(newFakeListingTemplateRepository(), newFakeListingRepository(), &fakeEventBus{}, clock.NewFrozen(time.Now()))
+	ctx := context.Background()
+
+	template, err := service.CreateTemplate(ctx, app.SaveListingTemplateCommand{
+		SellerID:    "seller-a",
+		Name:        "Phones",
+		CategoryID:  templateCategoryID,
+		Description: " Comes with a charger. ",
+		Attributes:  map[string]string{" brand ": " Samsung "},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Comes with a charger.", template.Description)
+	assert.Equal(t, map[string]string{"brand": "Samsung"}, template.Attributes)
+
+	// Only the owner can see, change or delete it
+	_, err = service.GetTemplate(ctx, template.ID, "seller-b")
+	assert.Error(t, err)
+	_, err = service.UpdateTemplate(ctx, template.ID, app.SaveListingTemplateCommand{SellerID: "seller-b", Name: "Mine now", CategoryID: templateCategoryID})
+	assert.Error(t, err)
+
+	updated, err := service.UpdateTemplate(ctx, template.ID, app.SaveListingTemplateCommand{SellerID: "seller-a", Name: "Used phones", CategoryID: templateCategoryID, Condition: "good"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ConditionGood, updated.Condition)
+	assert.Empty(t, updated.Attributes)
+
+	listed, err := service.ListTemplates(ctx, "seller-a")
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+
+	assert.Error(t, service.DeleteTemplate(ctx, template.ID, "seller-b"))
+	require.NoError(t, service.DeleteTemplate(ctx, template.ID, "seller-a"))
+	_, err = service.GetTemplate(ctx, template.ID, "seller-a")
+	assert.Error(t, err)
+}
+
+func TestListingTemplateService_LimitsTemplatesPerSeller(t *testing.T) {
+	service := app.NewListingTemplateService(newFakeListingTemplateRepository(), newFakeListingRepository(), &fakeEventBus{}, nil)
+	ctx := context.Background()
+
+	for i := 0; i < domain.MaxListingTemplatesPerSeller; i++ {
+		_, err := service.CreateTemplate(ctx, app.SaveListingTemplateCommand{SellerID: "seller-a", Name: "Template", CategoryID: templateCategoryID})
+		require.NoError(t, err)
+	}
+	_, err := service.CreateTemplate(ctx, app.SaveListingTemplateCommand{SellerID: "seller-a", Name: "One too many", CategoryID: templateCategoryID})
+	assert.Error(t, err)
+}
+
+func TestListingTemplateService_CreateListingFromTemplate(t *testing.T) {
+	listings := newFakeListingRepository()
+	bus := &fakeEventBus{}
+	service := app.NewListingTemplateService(newFakeListingTemplateRepository(), listings, bus, nil)
+	ctx := context.Background()
+
+	template, err := service.CreateTemplate(ctx, app.SaveListingTemplateCommand{
+		SellerID:    "seller-a",
+		Name:        "Phones",
+		CategoryID:  templateCategoryID,
+		Description: "Comes with a charger and the original box.",
+		Condition:   "like_new",
+		Attributes:  map[string]string{"brand": "Samsung", "storage": "64GB"},
+	})
+	require.NoError(t, err)
+
+	listing, err := service.CreateListingFromTemplate(ctx, app.CreateFromTemplateCommand{
+		TemplateID: template.ID,
+		SellerID:   "seller-a",
+		Title:      "Samsung Galaxy S21",
+		Price:      2500,
+		Condition:  "good",
+		Attributes: map[string]string{"storage": "128GB", "colour": "Black"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusDraft, listing.Status)
+	assert.Equal(t, templateCategoryID, listing.CategoryID)
+	assert.Equal(t, "Samsung Galaxy S21", listing.Title)
+	assert.Equal(t, "Comes with a charger and the original box.", listing.Description)
+	assert.Equal(t, money.Cedis(2500), listing.Price)
+	assert.Equal(t, domain.ConditionGood, listing.Condition, "the request's condition wins")
+
+	attributes := make(map[string]string)
+	for _, attribute := range listing.Attributes {
+		attributes[attribute.Key] = attribute.Value
+	}
+	assert.Equal(t, map[string]string{"brand": "Samsung", "storage": "128GB", "colour": "Black"}, attributes)
+
+	_, err = listings.FindByID(listing.ID)
+	require.NoError(t, err)
+	assert.Len(t, bus.eventsOfType(domain.ListingCreatedEvent), 1)
+
+	// Another seller cannot post from the template
+	_, err = service.CreateListingFromTemplate(ctx, app.CreateFromTemplateCommand{TemplateID: template.ID, SellerID: "seller-b"})
+	assert.Error(t, err)
+}
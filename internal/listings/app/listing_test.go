@@ -0,0 +1,300 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListingService_CreateAndUpdateListing(t *testing.T) {
+	repo := newFakeListingRepository()
+	bus := &fakeEventBus{}
+	service := app.NewListingService(repo, nil, bus, nil, nil, nil, nil, &fakePublicationValidator{}, nil, nil, nil)
+	ctx := context.Background()
+
+	negotiable := false
+	listing, err := service.CreateListing(ctx, app.CreateListingCommand{
+		SellerID:     "seller-a",
+		CategoryID:   "category-1",
+		Title:        "  Samsung Galaxy S21  ",
+		Price:        2500,
+		Condition:    "like_new",
+		Location:     domain.Location{Region: "Greater Accra", City: "Accra"},
+		IsNegotiable: &negotiable,
+		Attributes:   map[string]string{"storage": "128GB", "brand": "Samsung"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusDraft, listing.Status)
+	assert.Equal(t, "Samsung Galaxy S21", listing.Title)
+	assert.False(t, listing.IsNegotiable)
+	require.Len(t, listing.Attributes, 2)
+	assert.Equal(t, "brand", listing.Attributes[0].Key)
+	assert.Len(t, bus.eventsOfType(domain.ListingCreatedEvent), 1)
+
+	// Only the seller can change the listing, and only the given fields change
+	price := 2300.0
+	_, err = service.UpdateListing(ctx, app.UpdateListingCommand{ListingID: listing.ID, SellerID: "seller-b", Price: &price})
+	assert.Error(t, err)
+
+	updated, err := service.UpdateListing(ctx, app.UpdateListingCommand{ListingID: listing.ID, SellerID: "seller-a", Price: &price})
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(2300), updated.Price)
+	assert.Equal(t, "Samsung Galaxy S21", updated.Title)
+	assert.Len(t, bus.eventsOfType(domain.ListingUpdatedEvent), 1)
+
+	// Drafts may be left incomplete until they are published, but prices are never negative
+	negative := -1.0
+	_, err = service.UpdateListing(ctx, app.UpdateListingCommand{ListingID: listing.ID, SellerID: "seller-a", Price: &negative})
+	assert.Error(t, err)
+	zero := 0.0
+	_, err = service.UpdateListing(ctx, app.UpdateListingCommand{ListingID: listing.ID, SellerID: "seller-a", Price: &zero})
+	require.NoError(t, err)
+	_, err = service.ActivateListing(ctx, listing.ID, "seller-a")
+	assert.Error(t, err)
+
+	page, err := service.ListSellerListings(ctx, app.ListSellerListingsQuery{SellerID: "seller-a"})
+	require.NoError(t, err)
+	assert.Len(t, page.Listings, 1, "drafts are listed for their seller")
+}
+
+func TestListingService_ListingLifecycle(t *testing.T) {
+	ready := newDraftListing(t, "seller-a", "Samsung Galaxy S21")
+	incomplete := newDraftListing(t, "seller-a", "Laptop")
+	repo := newFakeListingRepository(ready, incomplete)
+	bus := &fakeEventBus{}
+	publication := &fakePublicationValidator{blocked: map[ids.ListingID]string{incomplete.ID: "add at least one photo"}}
+	service := app.NewListingService(repo, nil, bus, nil, nil, nil, nil, publication, nil, nil, nil)
+	ctx := context.Background()
+
+	// Listings that break the publication rules stay drafts
+	_, err := service.ActivateListing(ctx, incomplete.ID, "seller-a")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "add at least one photo")
+	assert.Equal(t, domain.ListingStatusDraft, incomplete.Status)
+
+	require.NoError(t, ready.Schedule(time.Now().Add(24*time.Hour), time.Now()))
+	listing, err := service.ActivateListing(ctx, ready.ID, "seller-a")
+	require.NoError(t, err)
+	assert.True(t, listing.IsActive())
+	assert.Nil(t, listing.PublishAt, "activating a scheduled draft publishes it now")
+	assert.Len(t, bus.eventsOfType(domain.ListingActivatedEvent), 1)
+
+	// A listing a buyer is paying for cannot be taken down or sold elsewhere
+	require.NoError(t, listing.Reserve("buyer-1", time.Now().Add(time.Hour), time.Now()))
+	_, err = service.DeactivateListing(ctx, ready.ID, "seller-a")
+	assert.Error(t, err)
+	_, err = service.MarkListingSold(ctx, ready.ID, "seller-a")
+	assert.Error(t, err)
+	listing.ReleaseReservation("buyer-1")
+
+	listing, err = service.DeactivateListing(ctx, ready.ID, "seller-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusInactive, listing.Status)
+	assert.Len(t, bus.eventsOfType(domain.ListingDeactivatedEvent), 1)
+
+	listing, err = service.MarkListingSold(ctx, ready.ID, "seller-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusSold, listing.Status)
+	assert.Len(t, bus.eventsOfType(domain.ListingSoldEvent), 1)
+	assert.Len(t, bus.eventsOfType(domain.ListingStatusChangedEvent), 3, "activated, taken down and sold")
+
+	_, err = service.DeactivateListing(ctx, ready.ID, "seller-a")
+	assert.Error(t, err, "sold listings stay sold")
+	title := "Samsung Galaxy S21 Ultra"
+	_, err = service.UpdateListing(ctx, app.UpdateListingCommand{ListingID: ready.ID, SellerID: "seller-a", Title: &title})
+	assert.Error(t, err)
+
+	// Drafts were never published, so they cannot be sold
+	_, err = service.MarkListingSold(ctx, incomplete.ID, "seller-a")
+	assert.Error(t, err)
+}
+
+func TestListingService_RecordSale(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	require.NoError(t, listing.Reserve("buyer-1", time.Now().Add(time.Hour), time.Now()))
+	bus := &fakeEventBus{}
+	service := app.NewListingService(newFakeListingRepository(listing), nil, bus, nil, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	// The buyer's reservation does not stand in the way of their own sale
+	sold, err := service.RecordSale(ctx, listing.ID, "buyer-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusSold, sold.Status)
+	assert.False(t, sold.IsReserved())
+
+	// Redelivered events change nothing, and gone listings are left alone
+	_, err = service.RecordSale(ctx, listing.ID, "buyer-1")
+	require.NoError(t, err)
+	assert.Len(t, bus.eventsOfType(domain.ListingSoldEvent), 1)
+	gone, err := service.RecordSale(ctx, "missing", "buyer-1")
+	require.NoError(t, err)
+	assert.Nil(t, gone)
+}
+
+func TestListingService_ExpireListings(t *testing.T) {
+	lapsed := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	lapsed.ExpiresAt = time.Now().Add(-time.Hour)
+	reserved := newActiveListing(t, "seller-a", "Laptop", domain.ConditionGood)
+	require.NoError(t, reserved.Reserve("buyer-1", time.Now().Add(time.Hour), time.Now()))
+	reserved.ExpiresAt = time.Now().Add(-time.Hour)
+	live := newActiveListing(t, "seller-a", "Tablet", domain.ConditionGood)
+	repo := newFakeListingRepository(lapsed, reserved, live)
+	bus := &fakeEventBus{}
+	service := app.NewListingService(repo, nil, bus, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	count, err := service.ExpireListings(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, domain.ListingStatusExpired, lapsed.Status)
+	assert.Equal(t, domain.ListingStatusActive, reserved.Status, "listings a buyer is paying for wait for the reservation to end")
+	assert.True(t, live.IsActive())
+
+	expired := bus.eventsOfType(domain.ListingExpiredEvent)
+	require.Len(t, expired, 1)
+	assert.Equal(t, lapsed.ID.String(), expired[0].AggregateID)
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 1)
+
+	// Expired listings are not expired again
+	count, err = service.ExpireListings(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestListingService_DraftAutosaveAndPublish(t *testing.T) {
+	repo := newFakeListingRepository()
+	bus := &fakeEventBus{}
+	service := app.NewListingService(repo, nil, bus, nil, nil, nil, nil, &fakePublicationValidator{}, nil, nil, nil)
+	ctx := context.Background()
+
+	// A draft can be started with nothing filled in
+	draft, err := service.CreateDraft(ctx, app.CreateDraftCommand{SellerID: "seller-a", Title: "  Laptop  "})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusDraft, draft.Status)
+	assert.Equal(t, "Laptop", draft.Title)
+	assert.Empty(t, draft.CategoryID)
+	assert.Len(t, bus.eventsOfType(domain.ListingCreatedEvent), 1)
+
+	_, err = service.ActivateListing(ctx, draft.ID, "seller-a")
+	require.Error(t, err, "incomplete drafts cannot be published")
+	assert.Equal(t, domain.ListingStatusDraft, draft.Status)
+
+	// The seller fills it in bit by bit, then publishes it
+	categoryID := "category-1"
+	_, err = service.UpdateListing(ctx, app.UpdateListingCommand{ListingID: draft.ID, SellerID: "seller-a", CategoryID: &categoryID})
+	require.NoError(t, err)
+	price := 1800.0
+	condition := "good"
+	_, err = service.UpdateListing(ctx, app.UpdateListingCommand{ListingID: draft.ID, SellerID: "seller-a", Price: &price, Condition: &condition})
+	require.NoError(t, err)
+
+	published, err := service.ActivateListing(ctx, draft.ID, "seller-a")
+	require.NoError(t, err)
+	assert.True(t, published.IsActive())
+}
+
+func TestListingService_PriceHistory(t *testing.T) {
+	draft := newDraftListing(t, "seller-a", "Laptop")
+	live := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	repo := newFakeListingRepository(draft, live)
+	bus := &fakeEventBus{}
+	history := &fakePriceHistoryRepository{}
+	service := app.NewListingService(repo, nil, bus, nil, nil, nil, nil, nil, history, nil, nil)
+	ctx := context.Background()
+
+	// Buyers never saw a draft's price, so its changes are not kept
+	price := draft.Price.Major() - 100
+	_, err := service.UpdateListing(ctx, app.UpdateListingCommand{ListingID: draft.ID, SellerID: "seller-a", Price: &price})
+	require.NoError(t, err)
+	assert.Empty(t, history.changes)
+
+	original := live.Price.Major()
+	for _, price := range []float64{original - 50, original - 80} {
+		_, err := service.UpdateListing(ctx, app.UpdateListingCommand{ListingID: live.ID, SellerID: "seller-a", Price: &price})
+		require.NoError(t, err)
+	}
+	title := "Phone with case"
+	_, err = service.UpdateListing(ctx, app.UpdateListingCommand{ListingID: live.ID, SellerID: "seller-a", Title: &title})
+	require.NoError(t, err)
+
+	changed := bus.eventsOfType(domain.ListingPriceChangedEvent)
+	require.Len(t, changed, 2, "only price changes are recorded")
+
+	result, err := service.GetPriceHistory(ctx, live.ID)
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(original-80), result.Price)
+	require.Len(t, result.Changes, 2)
+	assert.Equal(t, money.Cedis(original-50), result.Changes[0].OldPrice, "the most recent change comes first")
+	assert.Equal(t, money.Cedis(original-80), result.Changes[0].NewPrice)
+	assert.True(t, result.Changes[0].IsDrop())
+	assert.Equal(t, money.Cedis(original), result.Changes[1].OldPrice)
+
+	// Drafts have no public price history
+	_, err = service.GetPriceHistory(ctx, draft.ID)
+	assert.Error(t, err)
+}
+
+func TestListingService_DeleteStaleDrafts(t *testing.T) {
+	stale := newDraftListing(t, "seller-a", "Phone")
+	stale.UpdatedAt = time.Now().Add(-40 * 24 * time.Hour)
+	scheduled := newDraftListing(t, "seller-a", "Laptop")
+	require.NoError(t, scheduled.Schedule(time.Now().Add(time.Hour), time.Now()))
+	scheduled.UpdatedAt = stale.UpdatedAt
+	recent := newDraftListing(t, "seller-a", "Tablet")
+	live := newActiveListing(t, "seller-a", "Camera", domain.ConditionGood)
+	live.UpdatedAt = stale.UpdatedAt
+	repo := newFakeListingRepository(stale, scheduled, recent, live)
+	service := app.NewListingService(repo, nil, &fakeEventBus{}, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	count, err := service.DeleteStaleDrafts(context.Background(), 30*24*time.Hour, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	_, err = repo.FindByID(stale.ID)
+	assert.Error(t, err)
+	for _, kept := range []*domain.Listing{scheduled, recent, live} {
+		_, err = repo.FindByID(kept.ID)
+		assert.NoError(t, err, "scheduled drafts, recent drafts and live listings are kept")
+	}
+}
+
+func TestListingService_HoldAndReleaseSellerListings(t *testing.T) {
+	live := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	paused := newActiveListing(t, "seller-a", "Laptop", domain.ConditionGood)
+	require.NoError(t, paused.Deactivate())
+	other := newActiveListing(t, "seller-b", "Camera", domain.ConditionGood)
+	repo := newFakeListingRepository(live, paused, other)
+	bus := &fakeEventBus{}
+	service := app.NewListingService(repo, nil, bus, nil, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	count, err := service.HoldSellerListings(ctx, "seller-a", domain.ListingHoldSellerSuspended)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, domain.ListingStatusInactive, live.Status)
+	assert.True(t, other.IsActive())
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 1)
+
+	// Held listings cannot be put back live by the seller
+	_, err = service.ActivateListing(ctx, live.ID, "seller-a")
+	assert.Error(t, err)
+
+	// Lifting another hold changes nothing
+	count, err = service.ReleaseSellerListings(ctx, "seller-a", domain.ListingHoldSellerRejected)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// Only the listings taken down by the hold go back live
+	count, err = service.ReleaseSellerListings(ctx, "seller-a", domain.ListingHoldSellerSuspended)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.True(t, live.IsActive())
+	assert.Equal(t, domain.ListingStatusInactive, paused.Status)
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 2)
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"context"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+)
+
+// ImportLocationsCommand represents the command to import location reference data
+type ImportLocationsCommand struct {
+	Regions []RegionInput `json:"regions" binding:"required,min=1,dive"`
+}
+
+// RegionInput represents a region and its cities in an import
+type RegionInput struct {
+	Name   string      `json:"name" binding:"required"`
+	Cities []CityInput `json:"cities" binding:"dive"`
+}
+
+// CityInput represents a city and its areas in an import
+type CityInput struct {
+	Name  string   `json:"name" binding:"required"`
+	Areas []string `json:"areas"`
+}
+
+// RegionSummary represents a region with its active listing count
+type RegionSummary struct {
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	ListingCount int64  `json:"listing_count"`
+}
+
+// CitySummary represents a city with its areas and active listing count
+type CitySummary struct {
+	Name         string   `json:"name"`
+	Slug         string   `json:"slug"`
+	Areas        []string `json:"areas"`
+	ListingCount int64    `json:"listing_count"`
+}
+
+// ImportSummary reports the regions, cities and areas covered by an import
+type ImportSummary struct {
+	Regions int `json:"regions"`
+	Cities  int `json:"cities"`
+	Areas   int `json:"areas"`
+}
+
+// LocationService handles location reference data use cases
+type LocationService struct {
+	locationRepo domain.LocationRepository
+	listingRepo  domain.ListingRepository
+}
+
+// NewLocationService creates a new location service
+func NewLocationService(locationRepo domain.LocationRepository, listingRepo domain.ListingRepository) *LocationService {
+	return &LocationService{
+		locationRepo: locationRepo,
+		listingRepo:  listingRepo,
+	}
+}
+
+// ListRegions lists all regions with their active listing counts
+func (s *LocationService) ListRegions(ctx context.Context) ([]RegionSummary, error) {
+	regions, err := s.locationRepo.FindRegions()
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.listingRepo.CountByLocation("")
+	if err != nil {
+		return nil, err
+	}
+	bySlug := countsBySlug(counts)
+
+	summaries := make([]RegionSummary, 0, len(regions))
+	for _, region := range regions {
+		summaries = append(summaries, RegionSummary{
+			Name:         region.Name,
+			Slug:         region.Slug,
+			ListingCount: bySlug[region.Slug],
+		})
+	}
+	return summaries, nil
+}
+
+// ListCities lists the cities of a region with their areas and active listing counts
+func (s *LocationService) ListCities(ctx context.Context, region string) ([]CitySummary, error) {
+	found, err := s.locationRepo.FindRegion(domain.Slugify(region))
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.listingRepo.CountByLocation(found.Name)
+	if err != nil {
+		return nil, err
+	}
+	bySlug := countsBySlug(counts)
+
+	summaries := make([]CitySummary, 0, len(found.Cities))
+	for _, city := range found.Cities {
+		areas := make([]string, 0, len(city.Areas))
+		for _, area := range city.Areas {
+			areas = append(areas, area.Name)
+		}
+		summaries = append(summaries, CitySummary{
+			Name:         city.Name,
+			Slug:         city.Slug,
+			Areas:        areas,
+			ListingCount: bySlug[city.Slug],
+		})
+	}
+	return summaries, nil
+}
+
+// ValidateLocation checks a listing location against the reference data and
+// returns it with canonical region, city and area names
+func (s *LocationService) ValidateLocation(ctx context.Context, location domain.Location) (domain.Location, error) {
+	if location.Region == "" || location.City == "" {
+		return location, errors.ValidationError("region and city are required")
+	}
+
+	region, err := s.locationRepo.FindRegion(domain.Slugify(location.Region))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return location, errors.ValidationError("unknown region " + location.Region)
+		}
+		return location, err
+	}
+
+	return region.Canonicalize(location)
+}
+
+// ImportLocations merges regions, cities and areas into the reference data.
+// Existing entries are kept, so imports can be re-run safely.
+func (s *LocationService) ImportLocations(ctx context.Context, cmd ImportLocationsCommand) (*ImportSummary, error) {
+	existing, err := s.locationRepo.FindRegions()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, region := range existing {
+		known[region.Slug] = true
+	}
+
+	regions := make(map[string]*domain.Region)
+	var ordered []*domain.Region
+	for _, input := range cmd.Regions {
+		slug := domain.Slugify(input.Name)
+
+		region, ok := regions[slug]
+		if !ok {
+			if known[slug] {
+				region, err = s.locationRepo.FindRegion(slug)
+			} else {
+				region, err = domain.NewRegion(input.Name)
+			}
+			if err != nil {
+				return nil, err
+			}
+			regions[slug] = region
+			ordered = append(ordered, region)
+		}
+
+		for _, cityInput := range input.Cities {
+			city, err := region.AddCity(cityInput.Name)
+			if err != nil {
+				return nil, err
+			}
+			for _, areaName := range cityInput.Areas {
+				if _, err := city.AddArea(areaName); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.locationRepo.SaveTree(ordered) }); err != nil {
+		return nil, err
+	}
+
+	summary := &ImportSummary{}
+	for _, region := range ordered {
+		summary.Regions++
+		summary.Cities += len(region.Cities)
+		for _, city := range region.Cities {
+			summary.Areas += len(city.Areas)
+		}
+	}
+	return summary, nil
+}
+
+// countsBySlug indexes location listing counts by the slug of the location name
+func countsBySlug(counts []domain.FacetCount) map[string]int64 {
+	bySlug := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		bySlug[domain.Slugify(count.Value)] += count.Count
+	}
+	return bySlug
+}
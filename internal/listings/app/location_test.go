@@ -0,0 +1,79 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationService_ImportAndBrowse(t *testing.T) {
+	accraListing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	repo := newFakeListingRepository(accraListing)
+	service := app.NewLocationService(newFakeLocationRepository(), repo)
+	ctx := context.Background()
+
+	summary, err := service.ImportLocations(ctx, app.ImportLocationsCommand{Regions: []app.RegionInput{
+		{Name: "Greater Accra", Cities: []app.CityInput{
+			{Name: "Accra", Areas: []string{"Osu", "East Legon"}},
+			{Name: "Tema"},
+		}},
+		{Name: "Ashanti", Cities: []app.CityInput{{Name: "Kumasi"}}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Regions)
+	assert.Equal(t, 3, summary.Cities)
+	assert.Equal(t, 2, summary.Areas)
+
+	// Re-importing merges into the existing tree
+	summary, err = service.ImportLocations(ctx, app.ImportLocationsCommand{Regions: []app.RegionInput{
+		{Name: "greater accra", Cities: []app.CityInput{{Name: "Accra", Areas: []string{"Osu", "Madina"}}}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Regions)
+	assert.Equal(t, 2, summary.Cities)
+	assert.Equal(t, 3, summary.Areas)
+
+	regions, err := service.ListRegions(ctx)
+	require.NoError(t, err)
+	require.Len(t, regions, 2)
+	for _, region := range regions {
+		if region.Slug == "greater-accra" {
+			assert.Equal(t, int64(1), region.ListingCount)
+		} else {
+			assert.Equal(t, int64(0), region.ListingCount)
+		}
+	}
+
+	cities, err := service.ListCities(ctx, "greater-accra")
+	require.NoError(t, err)
+	require.Len(t, cities, 2)
+
+	_, err = service.ListCities(ctx, "nowhere")
+	assert.Error(t, err)
+}
+
+func TestLocationService_ValidateLocation(t *testing.T) {
+	service := app.NewLocationService(newFakeLocationRepository(), newFakeListingRepository())
+	ctx := context.Background()
+
+	_, err := service.ImportLocations(ctx, app.ImportLocationsCommand{Regions: []app.RegionInput{
+		{Name: "Greater Accra", Cities: []app.CityInput{{Name: "Accra", Areas: []string{"Osu"}}}},
+	}})
+	require.NoError(t, err)
+
+	location, err := service.ValidateLocation(ctx, domain.Location{Region: "greater accra", City: "accra", Area: "osu"})
+	require.NoError(t, err)
+	assert.Equal(t, "Greater Accra", location.Region)
+	assert.Equal(t, "Accra", location.City)
+	assert.Equal(t, "Osu", location.Area)
+
+	_, err = service.ValidateLocation(ctx, domain.Location{Region: "Atlantis", City: "Accra"})
+	assert.Error(t, err)
+	_, err = service.ValidateLocation(ctx, domain.Location{Region: "Greater Accra"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,271 @@
+package app
+
+import (
+	"context"
+	"strconv"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// offersCategory is the notification category of offers and counter-offers
+const offersCategory = "offers"
+
+// MakeOfferCommand represents the command for a buyer to make an offer on a listing
+type MakeOfferCommand struct {
+	ListingID ids.ListingID `json:"-"`
+	BuyerID   ids.UserID    `json:"-"`
+	// Amount is in major units of the listing's currency, such as cedis
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// CounterOfferCommand represents the command to counter the other party's
+// last amount with another
+type CounterOfferCommand struct {
+	OfferID string     `json:"-"`
+	UserID  ids.UserID `json:"-"`
+	// Amount is in major units of the listing's currency, such as cedis
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// ListOffersQuery represents the query to page through the offers on a listing
+type ListOffersQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ListingOffers represents a page of offers on a listing
+type ListingOffers struct {
+	Offers []*domain.Offer `json:"offers"`
+	Total  int64           `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+// OfferService handles the negotiation of offers on negotiable listings
+type OfferService struct {
+	offerRepo   domain.OfferRepository
+	listingRepo domain.ListingRepository
+	preferences NotificationPreferences
+	eventBus    events.EventBus
+	clock       clock.Clock
+}
+
+// NewOfferService creates a new offer service. clk may be nil to use the
+// system clock.
+func NewOfferService(offerRepo domain.OfferRepository, listingRepo domain.ListingRepository, preferences NotificationPreferences, eventBus events.EventBus, clk clock.Clock) *OfferService {
+	return &OfferService{
+		offerRepo:   offerRepo,
+		listingRepo: listingRepo,
+		preferences: preferences,
+		eventBus:    eventBus,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// MakeOffer makes a buyer's offer on a listing. A buyer has at most one open
+// offer on a listing at a time.
+func (s *OfferService) MakeOffer(ctx context.Context, cmd MakeOfferCommand) (*domain.Offer, error) {
+	now := s.clock.Now()
+	listing, err := s.listingRepo.FindByID(cmd.ListingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Amounts are read exactly, so the seller sees the amount the buyer offered
+	amount, err := money.ParseMajor(strconv.FormatFloat(cmd.Amount, 'f', -1, 64), listing.Price.Currency)
+	if err != nil {
+		return nil, err
+	}
+	offer, err := domain.NewOffer(listing, cmd.BuyerID, amount, now)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := s.offerRepo.FindOpen(cmd.ListingID, cmd.BuyerID, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(open) > 0 {
+		return nil, errors.ConflictError("you already have an open offer on this listing")
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.offerRepo.Save(offer) }); err != nil {
+		return nil, err
+	}
+
+	if err := s.announce(ctx, domain.ListingOfferMadeEvent, offer, offer.BuyerID); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// AcceptOffer accepts the other party's last amount. Sellers can only accept
+// while the listing is still available.
+func (s *OfferService) AcceptOffer(ctx context.Context, offerID string, userID ids.UserID) (*domain.Offer, error) {
+	offer, err := s.offerFor(offerID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	listing, err := s.listingRepo.FindByID(offer.ListingID)
+	if err != nil {
+		return nil, err
+	}
+	if !listing.IsActive() {
+		return nil, errors.ValidationError("listing is not available")
+	}
+
+	if err := offer.Accept(userID, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	return offer, s.reply(ctx, domain.ListingOfferAcceptedEvent, offer, userID)
+}
+
+// CounterOffer counters the other party's last amount with another
+func (s *OfferService) CounterOffer(ctx context.Context, cmd CounterOfferCommand) (*domain.Offer, error) {
+	offer, err := s.offerFor(cmd.OfferID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := money.ParseMajor(strconv.FormatFloat(cmd.Amount, 'f', -1, 64), offer.Amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := offer.Counter(cmd.UserID, amount, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	return offer, s.reply(ctx, domain.ListingOfferCounteredEvent, offer, cmd.UserID)
+}
+
+// DeclineOffer turns down the other party's last amount, ending the negotiation
+func (s *OfferService) DeclineOffer(ctx context.Context, offerID string, userID ids.UserID) (*domain.Offer, error) {
+	offer, err := s.offerFor(offerID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := offer.Decline(userID, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	return offer, s.reply(ctx, domain.ListingOfferDeclinedEvent, offer, userID)
+}
+
+// GetOffer retrieves an offer the user is the buyer or the seller of
+func (s *OfferService) GetOffer(ctx context.Context, offerID string, userID ids.UserID) (*domain.Offer, error) {
+	return s.offerFor(offerID, userID)
+}
+
+// ListOffers lists the offers on a listing, newest first. The seller sees
+// every offer; anyone else only their own.
+func (s *OfferService) ListOffers(ctx context.Context, listingID ids.ListingID, userID ids.UserID, query ListOffersQuery) (*ListingOffers, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	buyerID := userID
+	if listing.SellerID == userID {
+		buyerID = ""
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	offers, total, err := s.offerRepo.FindByListing(listingID, buyerID, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListingOffers{
+		Offers: offers,
+		Total:  total,
+		Limit:  limit,
+		Offset: query.Offset,
+	}, nil
+}
+
+// ExpireOffers closes up to limit open offers that lapsed without a reply and
+// returns how many were closed. The party that was waiting for the reply is
+// told.
+func (s *OfferService) ExpireOffers(ctx context.Context, limit int) (int, error) {
+	now := s.clock.Now()
+	offers, err := s.offerRepo.FindExpiredOpen(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, offer := range offers {
+		awaited := offer.AwaitingReplyFrom()
+		if err := offer.Expire(now); err != nil {
+			continue
+		}
+		if err := s.reply(ctx, domain.ListingOfferExpiredEvent, offer, awaited); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// offerFor finds an offer the user is a party to. Offers of others are not
+// found, so their existence is not revealed.
+func (s *OfferService) offerFor(offerID string, userID ids.UserID) (*domain.Offer, error) {
+	offer, err := s.offerRepo.FindByID(offerID)
+	if err != nil {
+		return nil, err
+	}
+	if !offer.Involves(userID) {
+		return nil, errors.NotFoundError("offer not found")
+	}
+	return offer, nil
+}
+
+// reply saves a changed offer and tells the other party what the user did
+func (s *OfferService) reply(ctx context.Context, eventType string, offer *domain.Offer, userID ids.UserID) error {
+	if err := db.WithRetry(ctx, func() error { return s.offerRepo.Update(offer) }); err != nil {
+		return err
+	}
+	return s.announce(ctx, eventType, offer, userID)
+}
+
+// announce publishes an offer event for the party other than the one who acted
+func (s *OfferService) announce(ctx context.Context, eventType string, offer *domain.Offer, actorID ids.UserID) error {
+	recipientID := offer.OtherParty(actorID)
+	channels, err := s.preferences.NotificationChannels(ctx, recipientID, offersCategory)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeNotFound {
+			return err
+		}
+		channels = nil
+	}
+
+	event, err := events.NewEvent(
+		eventType,
+		offer.ListingID.String(),
+		domain.ListingOffer{
+			OfferID:     offer.ID,
+			ListingID:   offer.ListingID,
+			SellerID:    offer.SellerID,
+			BuyerID:     offer.BuyerID,
+			Amount:      offer.Amount,
+			Status:      offer.Status,
+			ExpiresAt:   offer.ExpiresAt,
+			RecipientID: recipientID,
+			Channels:    channels,
+			Timestamp:   s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
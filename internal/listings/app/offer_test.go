@@ -0,0 +1,137 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfferService_Negotiation(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	offers := &fakeOfferRepository{}
+	bus := &fakeEventBus{}
+	preferences := &fakeNotificationPreferences{channels: map[ids.UserID][]string{"buyer-a": {"push"}}}
+	service := app.NewOfferService(offers, newFakeListingRepository(listing), preferences, bus, nil)
+
+	// Amounts are not rounded, so fractions of a pesewa are refused
+	_, err := service.MakeOffer(context.Background(), app.MakeOfferCommand{ListingID: listing.ID, BuyerID: "buyer-a", Amount: 0.004})
+	assert.Error(t, err)
+	_, err = service.MakeOffer(context.Background(), app.MakeOfferCommand{ListingID: listing.ID, BuyerID: "buyer-a", Amount: 69.999})
+	assert.Error(t, err)
+
+	offer, err := service.MakeOffer(context.Background(), app.MakeOfferCommand{ListingID: listing.ID, BuyerID: "buyer-a", Amount: 70})
+	require.NoError(t, err)
+
+	made := bus.eventsOfType(domain.ListingOfferMadeEvent)
+	require.Len(t, made, 1)
+	var madeData domain.ListingOffer
+	require.NoError(t, events.ParseEventData(made[0], &madeData))
+	assert.Equal(t, ids.UserID("seller-a"), madeData.RecipientID)
+	assert.Equal(t, []string{"email", "push"}, madeData.Channels)
+
+	// One open offer per buyer and listing
+	_, err = service.MakeOffer(context.Background(), app.MakeOfferCommand{ListingID: listing.ID, BuyerID: "buyer-a", Amount: 75})
+	assert.Error(t, err)
+
+	_, err = service.CounterOffer(context.Background(), app.CounterOfferCommand{OfferID: offer.ID, UserID: "seller-a", Amount: 89.999})
+	assert.Error(t, err)
+	countered, err := service.CounterOffer(context.Background(), app.CounterOfferCommand{OfferID: offer.ID, UserID: "seller-a", Amount: 90})
+	require.NoError(t, err)
+	assert.Equal(t, domain.OfferStatusCountered, countered.Status)
+
+	counters := bus.eventsOfType(domain.ListingOfferCounteredEvent)
+	require.Len(t, counters, 1)
+	var counterData domain.ListingOffer
+	require.NoError(t, events.ParseEventData(counters[0], &counterData))
+	assert.Equal(t, ids.UserID("buyer-a"), counterData.RecipientID)
+	assert.Equal(t, []string{"push"}, counterData.Channels)
+	assert.Equal(t, money.Cedis(90), counterData.Amount)
+
+	// Others cannot see or reply to the offer
+	_, err = service.AcceptOffer(context.Background(), offer.ID, "buyer-b")
+	assert.Error(t, err)
+	_, err = service.GetOffer(context.Background(), offer.ID, "buyer-b")
+	assert.Error(t, err)
+
+	accepted, err := service.AcceptOffer(context.Background(), offer.ID, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.OfferStatusAccepted, accepted.Status)
+	assert.Len(t, bus.eventsOfType(domain.ListingOfferAcceptedEvent), 1)
+}
+
+func TestOfferService_AcceptRequiresAvailableListing(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	service := app.NewOfferService(&fakeOfferRepository{}, newFakeListingRepository(listing), &fakeNotificationPreferences{}, &fakeEventBus{}, nil)
+
+	offer, err := service.MakeOffer(context.Background(), app.MakeOfferCommand{ListingID: listing.ID, BuyerID: "buyer-a", Amount: 70})
+	require.NoError(t, err)
+
+	require.NoError(t, listing.MarkAsSold())
+	_, err = service.AcceptOffer(context.Background(), offer.ID, "seller-a")
+	assert.Error(t, err)
+	assert.True(t, offer.IsOpen())
+
+	// Declining needs no available listing
+	declined, err := service.DeclineOffer(context.Background(), offer.ID, "seller-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.OfferStatusDeclined, declined.Status)
+}
+
+func TestOfferService_ListOffers(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	service := app.NewOfferService(&fakeOfferRepository{}, newFakeListingRepository(listing), &fakeNotificationPreferences{}, &fakeEventBus{}, nil)
+
+	for _, buyerID := range []ids.UserID{"buyer-a", "buyer-b"} {
+		_, err := service.MakeOffer(context.Background(), app.MakeOfferCommand{ListingID: listing.ID, BuyerID: buyerID, Amount: 70})
+		require.NoError(t, err)
+	}
+
+	all, err := service.ListOffers(context.Background(), listing.ID, "seller-a", app.ListOffersQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), all.Total)
+
+	own, err := service.ListOffers(context.Background(), listing.ID, "buyer-b", app.ListOffersQuery{})
+	require.NoError(t, err)
+	require.Len(t, own.Offers, 1)
+	assert.Equal(t, ids.UserID("buyer-b"), own.Offers[0].BuyerID)
+}
+
+func TestOfferService_ExpireOffers(t *testing.T) {
+	clk := clock.NewFrozen(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	bus := &fakeEventBus{}
+	service := app.NewOfferService(&fakeOfferRepository{}, newFakeListingRepository(listing), &fakeNotificationPreferences{}, bus, clk)
+
+	offer, err := service.MakeOffer(context.Background(), app.MakeOfferCommand{ListingID: listing.ID, BuyerID: "buyer-a", Amount: 70})
+	require.NoError(t, err)
+
+	count, err := service.ExpireOffers(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	clk.Advance(domain.OfferExpiry + time.Minute)
+	count, err = service.ExpireOffers(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, domain.OfferStatusExpired, offer.Status)
+
+	// The buyer who waited for the seller's reply is told
+	expired := bus.eventsOfType(domain.ListingOfferExpiredEvent)
+	require.Len(t, expired, 1)
+	var data domain.ListingOffer
+	require.NoError(t, events.ParseEventData(expired[0], &data))
+	assert.Equal(t, ids.UserID("buyer-a"), data.RecipientID)
+
+	// A lapsed offer does not stop the buyer from making another
+	_, err = service.MakeOffer(context.Background(), app.MakeOfferCommand{ListingID: listing.ID, BuyerID: "buyer-a", Amount: 75})
+	assert.NoError(t, err)
+}
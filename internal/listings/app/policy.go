@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+	"dongome/pkg/rules"
+)
+
+// MaxActiveListingsRule is the business rule deciding how many listings a
+// seller can have live at once; zero means no cap
+const MaxActiveListingsRule = "listings.max_active_per_seller"
+
+// MaxActiveListingsDefinition declares MaxActiveListingsRule. Until an
+// administrator changes it, every seller gets defaultCap.
+func MaxActiveListingsDefinition(defaultCap int) rules.Definition {
+	return rules.Definition{
+		Key:         MaxActiveListingsRule,
+		Description: "How many listings a seller can have live at once; 0 means no cap",
+		Kind:        rules.KindNumber,
+		Variables: []rules.Variable{
+			{Name: "verified", Kind: rules.KindBool, Description: "the seller is verified"},
+			{Name: "rating", Kind: rules.KindNumber, Description: "the seller's average rating, from 0 to 5"},
+			{Name: "total_reviews", Kind: rules.KindNumber, Description: "how many reviews the seller has"},
+			{Name: "trust_level", Kind: rules.KindString, Description: "the seller's trust level, such as new or trusted"},
+		},
+		Default: strconv.Itoa(defaultCap),
+	}
+}
+
+// SellerCardFacts are the facts about a seller that listing rules use
+func SellerCardFacts(card *domain.SellerCard) rules.Facts {
+	return rules.Facts{
+		"verified":      card.Verified,
+		"rating":        card.Rating,
+		"total_reviews": card.TotalReviews,
+		"trust_level":   card.TrustLevel,
+	}
+}
+
+// PolicyRules evaluates business rules administrators can change. It is
+// implemented by rules.Engine.
+type PolicyRules interface {
+	Number(key string, facts rules.Facts) float64
+}
+
+// PublicationPolicy decides the publication limits of each seller
+type PublicationPolicy interface {
+	LimitsFor(ctx context.Context, sellerID ids.UserID) (domain.PublicationLimits, error)
+}
+
+// RulePublicationPolicy takes each seller's publication limits from
+// MaxActiveListingsRule, evaluated against their seller card
+type RulePublicationPolicy struct {
+	sellerCards domain.SellerCardRepository
+	rules       PolicyRules
+}
+
+// NewRulePublicationPolicy creates a publication policy backed by business rules
+func NewRulePublicationPolicy(sellerCards domain.SellerCardRepository, rules PolicyRules) *RulePublicationPolicy {
+	return &RulePublicationPolicy{
+		sellerCards: sellerCards,
+		rules:       rules,
+	}
+}
+
+// LimitsFor evaluates the seller's limits. Sellers without a card yet are
+// treated as new, unreviewed sellers.
+func (p *RulePublicationPolicy) LimitsFor(ctx context.Context, sellerID ids.UserID) (domain.PublicationLimits, error) {
+	cards, err := p.sellerCards.FindBySellerIDs([]ids.UserID{sellerID})
+	if err != nil {
+		return domain.PublicationLimits{}, err
+	}
+	card := domain.NewSellerCard(sellerID, time.Now())
+	if len(cards) > 0 {
+		card = cards[0]
+	}
+
+	maxActive := int(p.rules.Number(MaxActiveListingsRule, SellerCardFacts(card)))
+	if maxActive < 0 {
+		maxActive = 0
+	}
+	return domain.PublicationLimits{MaxActiveListings: maxActive}, nil
+}
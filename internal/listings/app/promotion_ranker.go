@@ -0,0 +1,92 @@
+package app
+
+import (
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+)
+
+// Promoted listing placement
+const (
+	// promotedCandidateLimit is how many promoted listings matching a search
+	// take turns in its sponsored slots
+	promotedCandidateLimit = 50
+	// organicPerSponsored is the fewest results a page must ask for per
+	// sponsored slot, so small pages are not mostly sponsored
+	organicPerSponsored = 5
+	// promotedRotation is how often the promoted listings shown first move on
+	promotedRotation = time.Minute
+)
+
+// PromotionRanker mixes promoted listings into the pages of marketplace
+// searches. Results keep their organic order; the promoted listings matching
+// the search fill a few sponsored slots spread over each page, taking turns
+// so that every paying seller is seen. Promotions whose time is up are not
+// shown, even before the worker ends them.
+type PromotionRanker struct {
+	perPage int
+	clock   clock.Clock
+}
+
+// NewPromotionRanker creates a promotion ranker showing at most perPage
+// promoted listings on a page; zero shows none
+func NewPromotionRanker(perPage int, clk clock.Clock) *PromotionRanker {
+	return &PromotionRanker{
+		perPage: perPage,
+		clock:   clock.OrSystem(clk),
+	}
+}
+
+// promotedCriteria returns the criteria finding the promoted listings that
+// may be shown with a search, and whether the search shows any. Only
+// marketplace searches in the default order do, and only on pages reached by
+// offset: an explicit sort, a storefront or a walk by cursor is left as is.
+func (r *PromotionRanker) promotedCriteria(criteria domain.ListingSearchCriteria) (domain.ListingSearchCriteria, bool) {
+	if r == nil || r.perPage <= 0 {
+		return criteria, false
+	}
+	if criteria.Sort != "" && criteria.Sort != domain.ListingSortRelevance {
+		return criteria, false
+	}
+	if criteria.Near != nil || criteria.SellerID != "" || len(criteria.SellerIDs) > 0 || criteria.After != nil {
+		return criteria, false
+	}
+
+	now := r.clock.Now()
+	criteria.PromotedAt = &now
+	return criteria, true
+}
+
+// mix places the promoted listings due on the page at offset among its
+// organic results. Each page shows the next promoted listings in turn, so
+// none is shown twice while paging, and the turn starts further along every
+// promotedRotation.
+func (r *PromotionRanker) mix(organic, promoted []*domain.Listing, limit, offset int) []*domain.Listing {
+	now := r.clock.Now()
+	candidates := make([]*domain.Listing, 0, len(promoted))
+	for _, listing := range promoted {
+		if listing.IsActiveAt(now) && listing.IsPromotedAt(now) {
+			candidates = append(candidates, listing)
+		}
+	}
+
+	slots := r.perPage
+	if most := limit / organicPerSponsored; slots > most {
+		slots = most
+	}
+	if slots < 1 {
+		slots = 1
+	}
+	first := offset / limit * slots
+	if first >= len(candidates) {
+		return organic
+	}
+
+	turn := int(now.Unix() / int64(promotedRotation.Seconds()))
+	sponsored := make([]*domain.Listing, 0, slots)
+	for i := first; i < len(candidates) && len(sponsored) < slots; i++ {
+		sponsored = append(sponsored, candidates[(i+turn)%len(candidates)])
+	}
+	return domain.PlaceSponsored(organic, sponsored)
+}
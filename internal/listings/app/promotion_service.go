@@ -0,0 +1,256 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// Statuses of MoMo payment callbacks
+const (
+	promotionPaymentSuccessful = "SUCCESSFUL"
+	promotionPaymentFailed     = "FAILED"
+)
+
+// PaymentRequest asks a payer to pay from their Mobile Money wallet
+type PaymentRequest struct {
+	// Reference is returned with the payment's result to tell what it was for
+	Reference   string
+	Amount      money.Money
+	Payer       string
+	Description string
+}
+
+// PaymentGateway requests Mobile Money payments. The result arrives later
+// through a payment callback.
+type PaymentGateway interface {
+	RequestPayment(ctx context.Context, payment PaymentRequest) (string, error)
+}
+
+// PromoteListingCommand represents the command to buy a promotion package for
+// a listing, paid from the given phone number
+type PromoteListingCommand struct {
+	ListingID ids.ListingID `json:"-"`
+	SellerID  ids.UserID    `json:"-"`
+	PackageID string        `json:"package_id" binding:"required"`
+	Phone     string        `json:"phone" binding:"required,e164"`
+}
+
+// PromotionPaymentCallbackCommand represents a MoMo payment callback.
+// ExternalID is the promotion the payment was requested for.
+type PromotionPaymentCallbackCommand struct {
+	ExternalID             string `json:"externalId" binding:"required,uuid"`
+	FinancialTransactionID string `json:"financialTransactionId"`
+	Amount                 string `json:"amount" binding:"required"`
+	Currency               string `json:"currency" binding:"required"`
+	Status                 string `json:"status" binding:"required"`
+}
+
+// PromotionService handles sellers paying to promote their listings
+type PromotionService struct {
+	listingRepo   domain.ListingRepository
+	promotionRepo domain.ListingPromotionRepository
+	payments      PaymentGateway
+	eventBus      events.EventBus
+	packages      []domain.PromotionPackage
+	clock         clock.Clock
+}
+
+// NewPromotionService creates a new promotion service selling the given
+// packages. payments may be nil where promotions are not sold, such as in
+// the worker. clk may be nil to use the system clock.
+func NewPromotionService(listingRepo domain.ListingRepository, promotionRepo domain.ListingPromotionRepository, payments PaymentGateway, eventBus events.EventBus, packages []domain.PromotionPackage, clk clock.Clock) *PromotionService {
+	return &PromotionService{
+		listingRepo:   listingRepo,
+		promotionRepo: promotionRepo,
+		payments:      payments,
+		eventBus:      eventBus,
+		packages:      packages,
+		clock:         clock.OrSystem(clk),
+	}
+}
+
+// ListPackages lists the promotion packages on sale
+func (s *PromotionService) ListPackages(ctx context.Context) []domain.PromotionPackage {
+	return s.packages
+}
+
+// PromoteListing starts the purchase of a promotion package for one of the
+// seller's live listings and asks the seller to approve the payment on their
+// phone. The listing is promoted once the payment is confirmed.
+func (s *PromotionService) PromoteListing(ctx context.Context, cmd PromoteListingCommand) (*domain.ListingPromotion, error) {
+	pkg, err := domain.FindPromotionPackage(s.packages, cmd.PackageID)
+	if err != nil {
+		return nil, err
+	}
+	listing, err := s.listingRepo.FindByID(cmd.ListingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID != cmd.SellerID {
+		return nil, errors.ForbiddenError("listing does not belong to the seller")
+	}
+
+	now := s.clock.Now()
+	// MoMo takes phone numbers without the leading +
+	promotion, err := domain.NewListingPromotion(listing, pkg, strings.TrimPrefix(cmd.Phone, "+"), now)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.promotionRepo.Save(promotion) }); err != nil {
+		return nil, err
+	}
+
+	reference, err := s.payments.RequestPayment(ctx, PaymentRequest{
+		Reference:   promotion.ID,
+		Amount:      promotion.Amount,
+		Payer:       promotion.Payer,
+		Description: fmt.Sprintf("%s promotion", pkg.Name),
+	})
+	if err != nil {
+		promotion.MarkFailed(now)
+		_ = db.WithRetry(ctx, func() error { return s.promotionRepo.Update(promotion) })
+		return nil, err
+	}
+
+	promotion.PaymentReference = reference
+	if err := db.WithRetry(ctx, func() error { return s.promotionRepo.Update(promotion) }); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// HandlePaymentCallback applies a payment callback whose signature has been
+// verified. A successful payment of the full price publishes
+// ListingPromotionPaid so the worker promotes the listing; repeated callbacks
+// change nothing.
+func (s *PromotionService) HandlePaymentCallback(ctx context.Context, cmd PromotionPaymentCallbackCommand) (*domain.ListingPromotion, error) {
+	promotion, err := s.promotionRepo.FindByID(cmd.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	switch cmd.Status {
+	case promotionPaymentSuccessful:
+	case promotionPaymentFailed:
+		if promotion.MarkFailed(now) {
+			if err := db.WithRetry(ctx, func() error { return s.promotionRepo.Update(promotion) }); err != nil {
+				return nil, err
+			}
+		}
+		return promotion, nil
+	default:
+		return promotion, nil
+	}
+
+	amount, err := money.ParseMajor(cmd.Amount, cmd.Currency)
+	if err != nil || !amount.Equal(promotion.Amount) {
+		return nil, errors.ValidationError("payment amount does not match the promotion")
+	}
+	paid, err := promotion.MarkPaid(cmd.FinancialTransactionID, now)
+	if err != nil || !paid {
+		return promotion, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.promotionRepo.Update(promotion) }); err != nil {
+		return nil, err
+	}
+
+	event, err := events.NewEvent(
+		domain.ListingPromotionPaidEvent,
+		promotion.ListingID.String(),
+		domain.ListingPromotionPaid{
+			PromotionID: promotion.ID,
+			ListingID:   promotion.ListingID,
+			SellerID:    promotion.SellerID,
+			Days:        promotion.Days,
+			Amount:      promotion.Amount,
+			Timestamp:   now,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// ApplyPromotion promotes the listing of a paid promotion for the time paid
+// for, on top of any promotion still running, and publishes ListingPromoted.
+// It reports false for promotions not paid or already applied, so the same
+// event can safely be handled twice.
+func (s *PromotionService) ApplyPromotion(ctx context.Context, promotionID string) (bool, error) {
+	promotion, err := s.promotionRepo.FindByID(promotionID)
+	if err != nil {
+		return false, err
+	}
+	now := s.clock.Now()
+	if !promotion.MarkApplied(now) {
+		return false, nil
+	}
+	listing, err := s.listingRepo.FindByID(promotion.ListingID)
+	if err != nil {
+		return false, err
+	}
+
+	listing.Promote(promotion.Duration(), now)
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return false, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.promotionRepo.Update(promotion) }); err != nil {
+		return false, err
+	}
+
+	event, err := events.NewEvent(
+		domain.ListingPromotedEvent,
+		listing.ID.String(),
+		domain.ListingPromoted{
+			ListingID:     listing.ID,
+			SellerID:      listing.SellerID,
+			PromotedUntil: *listing.PromotedUntil,
+			Timestamp:     now,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return false, err
+	}
+	return true, publishListingChanged(ctx, s.eventBus, listing)
+}
+
+// EndLapsedPromotions stops promoting up to limit listings whose paid time is
+// up and returns how many changed
+func (s *PromotionService) EndLapsedPromotions(ctx context.Context, limit int) (int, error) {
+	now := s.clock.Now()
+	listings, err := s.listingRepo.FindLapsedPromotions(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	ended := 0
+	for _, listing := range listings {
+		if !listing.Unpromote(now) {
+			continue
+		}
+		if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+			return ended, err
+		}
+		if err := publishListingChanged(ctx, s.eventBus, listing); err != nil {
+			return ended, err
+		}
+		ended++
+	}
+	return ended, nil
+}
@@ -0,0 +1,147 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/errors"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPromotionPackages = []domain.PromotionPackage{
+	{ID: "week", Name: "7 days", Days: 7, Price: money.Cedis(10)},
+}
+
+func TestPromotionService_PromoteListing(t *testing.T) {
+	listing := newDraftListing(t, "seller-a", "Phone")
+	require.NoError(t, listing.Activate())
+	draft := newDraftListing(t, "seller-a", "Laptop")
+
+	promotions := newFakeListingPromotionRepository()
+	payments := &fakePaymentGateway{}
+	service := app.NewPromotionService(newFakeListingRepository(listing, draft), promotions, payments, &fakeEventBus{}, testPromotionPackages, nil)
+	ctx := context.Background()
+
+	// Only the seller can promote their live listings, with a package on sale
+	_, err := service.PromoteListing(ctx, app.PromoteListingCommand{ListingID: listing.ID, SellerID: "seller-b", PackageID: "week", Phone: "+233241234567"})
+	assert.Error(t, err)
+	_, err = service.PromoteListing(ctx, app.PromoteListingCommand{ListingID: draft.ID, SellerID: "seller-a", PackageID: "week", Phone: "+233241234567"})
+	assert.Error(t, err)
+	_, err = service.PromoteListing(ctx, app.PromoteListingCommand{ListingID: listing.ID, SellerID: "seller-a", PackageID: "year", Phone: "+233241234567"})
+	assert.Error(t, err)
+	assert.Empty(t, payments.requests)
+
+	promotion, err := service.PromoteListing(ctx, app.PromoteListingCommand{ListingID: listing.ID, SellerID: "seller-a", PackageID: "week", Phone: "+233241234567"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.PromotionStatusPending, promotion.Status)
+	assert.Equal(t, "reference-1", promotion.PaymentReference)
+	require.Len(t, payments.requests, 1)
+	assert.Equal(t, promotion.ID, payments.requests[0].Reference)
+	assert.Equal(t, "233241234567", payments.requests[0].Payer)
+	assert.Equal(t, money.Cedis(10), payments.requests[0].Amount)
+
+	// The listing is not promoted until the payment is confirmed
+	assert.False(t, listing.IsPromoted)
+
+	// A payment that cannot be requested fails the promotion
+	payments.err = errors.UnavailableError("mobile money payments are temporarily unavailable")
+	_, err = service.PromoteListing(ctx, app.PromoteListingCommand{ListingID: listing.ID, SellerID: "seller-a", PackageID: "week", Phone: "+233241234567"})
+	assert.Error(t, err)
+	failed := 0
+	for _, p := range promotions.promotions {
+		if p.Status == domain.PromotionStatusFailed {
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed)
+}
+
+func TestPromotionService_PaymentPromotesListing(t *testing.T) {
+	listing := newDraftListing(t, "seller-a", "Phone")
+	require.NoError(t, listing.Activate())
+
+	bus := &fakeEventBus{}
+	service := app.NewPromotionService(newFakeListingRepository(listing), newFakeListingPromotionRepository(), &fakePaymentGateway{}, bus, testPromotionPackages, nil)
+	ctx := context.Background()
+	promotion, err := service.PromoteListing(ctx, app.PromoteListingCommand{ListingID: listing.ID, SellerID: "seller-a", PackageID: "week", Phone: "+233241234567"})
+	require.NoError(t, err)
+
+	// Underpaying is refused
+	_, err = service.HandlePaymentCallback(ctx, app.PromotionPaymentCallbackCommand{ExternalID: promotion.ID, Amount: "1", Currency: "GHS", Status: "SUCCESSFUL"})
+	assert.Error(t, err)
+
+	paid, err := service.HandlePaymentCallback(ctx, app.PromotionPaymentCallbackCommand{ExternalID: promotion.ID, FinancialTransactionID: "ftx-1", Amount: "10.00", Currency: "GHS", Status: "SUCCESSFUL"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.PromotionStatusPaid, paid.Status)
+
+	// Repeated callbacks publish nothing more
+	_, err = service.HandlePaymentCallback(ctx, app.PromotionPaymentCallbackCommand{ExternalID: promotion.ID, FinancialTransactionID: "ftx-1", Amount: "10.00", Currency: "GHS", Status: "SUCCESSFUL"})
+	require.NoError(t, err)
+	require.Len(t, bus.eventsOfType(domain.ListingPromotionPaidEvent), 1)
+
+	applied, err := service.ApplyPromotion(ctx, promotion.ID)
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assert.True(t, listing.IsPromoted)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), *listing.PromotedUntil, time.Minute)
+	assert.Len(t, bus.eventsOfType(domain.ListingPromotedEvent), 1)
+
+	// A redelivered event does not promote the listing twice
+	applied, err = service.ApplyPromotion(ctx, promotion.ID)
+	require.NoError(t, err)
+	assert.False(t, applied)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), *listing.PromotedUntil, time.Minute)
+}
+
+func TestPromotionService_FailedPayment(t *testing.T) {
+	listing := newDraftListing(t, "seller-a", "Phone")
+	require.NoError(t, listing.Activate())
+
+	bus := &fakeEventBus{}
+	service := app.NewPromotionService(newFakeListingRepository(listing), newFakeListingPromotionRepository(), &fakePaymentGateway{}, bus, testPromotionPackages, nil)
+	ctx := context.Background()
+	promotion, err := service.PromoteListing(ctx, app.PromoteListingCommand{ListingID: listing.ID, SellerID: "seller-a", PackageID: "week", Phone: "+233241234567"})
+	require.NoError(t, err)
+
+	failed, err := service.HandlePaymentCallback(ctx, app.PromotionPaymentCallbackCommand{ExternalID: promotion.ID, Amount: "10.00", Currency: "GHS", Status: "FAILED"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.PromotionStatusFailed, failed.Status)
+	assert.Empty(t, bus.eventsOfType(domain.ListingPromotionPaidEvent))
+
+	applied, err := service.ApplyPromotion(ctx, promotion.ID)
+	require.NoError(t, err)
+	assert.False(t, applied)
+	assert.False(t, listing.IsPromoted)
+}
+
+func TestPromotionService_EndLapsedPromotions(t *testing.T) {
+	clk := clock.NewFrozen(time.Now())
+	lapsed := newDraftListing(t, "seller-a", "Phone")
+	require.NoError(t, lapsed.Activate())
+	lapsed.Promote(time.Hour, clk.Now())
+	running := newDraftListing(t, "seller-a", "Laptop")
+	require.NoError(t, running.Activate())
+	running.Promote(3*time.Hour, clk.Now())
+
+	bus := &fakeEventBus{}
+	service := app.NewPromotionService(newFakeListingRepository(lapsed, running), newFakeListingPromotionRepository(), nil, bus, nil, clk)
+
+	count, err := service.EndLapsedPromotions(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, count, "promotions run for the time paid for")
+
+	clk.Advance(2 * time.Hour)
+	count, err = service.EndLapsedPromotions(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.False(t, lapsed.IsPromoted)
+	assert.True(t, running.IsPromoted)
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 1)
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// LocationValidator checks a listing location against the location reference
+// data. It is implemented by LocationService.
+type LocationValidator interface {
+	ValidateLocation(ctx context.Context, location domain.Location) (domain.Location, error)
+}
+
+// PublicationService checks listings against the rules they must pass to go live
+type PublicationService struct {
+	listingRepo  domain.ListingRepository
+	categoryRepo domain.CategoryRepository
+	locations    LocationValidator
+	moderator    ContentModerator
+	suggester    ListingSuggester
+	policy       PublicationPolicy
+}
+
+// NewPublicationService creates a new publication service
+func NewPublicationService(listingRepo domain.ListingRepository, categoryRepo domain.CategoryRepository, locations LocationValidator, moderator ContentModerator, suggester ListingSuggester, policy PublicationPolicy) *PublicationService {
+	return &PublicationService{
+		listingRepo:  listingRepo,
+		categoryRepo: categoryRepo,
+		locations:    locations,
+		moderator:    moderator,
+		suggester:    suggester,
+		policy:       policy,
+	}
+}
+
+// ValidateListing runs every publication rule against one of the seller's
+// listings and reports what blocks it from going live and what would improve
+// it. Nothing about the listing is changed.
+func (s *PublicationService) ValidateListing(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID) (*domain.PublicationReport, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID != sellerID {
+		return nil, errors.ForbiddenError("listing does not belong to the seller")
+	}
+
+	limits, err := s.policy.LimitsFor(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.listingRepo.CountActiveBySeller(sellerID)
+	if err != nil {
+		return nil, err
+	}
+	report := listing.CheckPublication(limits, active)
+
+	// Drafts without a category are reported by CheckPublication
+	var path []string
+	if listing.CategoryID != "" {
+		path, err = categoryPath(s.categoryRepo, listing.CategoryID)
+		if err != nil {
+			if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeNotFound {
+				return nil, err
+			}
+			report.AddError(domain.IssueInvalidCategory, "category_id", "choose a category that is still available")
+		}
+	}
+
+	if _, err := s.locations.ValidateLocation(ctx, listing.Location); err != nil {
+		domainErr, ok := err.(*errors.DomainError)
+		if !ok || domainErr.Code != errors.ErrCodeValidation {
+			return nil, err
+		}
+		report.AddError(domain.IssueInvalidLocation, "location", domainErr.Message)
+	}
+
+	texts := []struct{ field, text string }{
+		{"title", listing.Title},
+		{"description", listing.Description},
+	}
+	for _, t := range texts {
+		result, err := s.moderator.Review(ctx, t.text)
+		if err != nil {
+			return nil, err
+		}
+		if result.Flagged {
+			report.AddWarning(domain.IssueContactDetails, t.field, fmt.Sprintf("%s %s, keep contact details in chat", t.field, result.Reason))
+		}
+	}
+
+	// Attributes the category's template expects but the listing lacks
+	if path != nil {
+		attributes := make(map[string]string, len(listing.Attributes))
+		for _, attribute := range listing.Attributes {
+			attributes[strings.ToLower(strings.TrimSpace(attribute.Key))] = attribute.Value
+		}
+		suggestion, err := s.suggester.Suggest(ctx, domain.SuggestionRequest{
+			CategoryPath: path,
+			Condition:    listing.Condition,
+			Attributes:   attributes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range suggestion.MissingAttributes {
+			report.AddWarning(domain.IssueMissingAttribute, "attributes."+name, fmt.Sprintf("add the %s so buyers can compare listings", name))
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,92 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/rules"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicationService_ValidateListing(t *testing.T) {
+	ctx := context.Background()
+	phones := newCategory("category-1", "Mobile Phones", nil)
+	locations := app.NewLocationService(newFakeLocationRepository(), newFakeListingRepository())
+	_, err := locations.ImportLocations(ctx, app.ImportLocationsCommand{Regions: []app.RegionInput{
+		{Name: "Greater Accra", Cities: []app.CityInput{{Name: "Accra"}}},
+	}})
+	require.NoError(t, err)
+
+	listing := newDraftListing(t, "seller-a", "Samsung Galaxy S21")
+	listing.Description = "Call 024 123 4567 for the best price on this phone"
+	listing.AddImage("https://cdn.example.com/1.jpg", "")
+	listing.Attributes = []domain.ListingAttribute{{Key: "Brand", Value: "Samsung"}}
+	live := newActiveListing(t, "seller-a", "Laptop", domain.ConditionGood)
+	repo := newFakeListingRepository(listing, live)
+	suggester := &fakeSuggester{}
+	service := app.NewPublicationService(repo, newFakeCategoryRepository(phones), locations, &fakeModerator{blocked: "024"}, suggester,
+		fixedPublicationPolicy{MaxActiveListings: 2})
+
+	// Only the seller can validate their listing
+	_, err = service.ValidateListing(ctx, listing.ID, "seller-b")
+	assert.Error(t, err)
+
+	report, err := service.ValidateListing(ctx, listing.ID, "seller-a")
+	require.NoError(t, err)
+	assert.True(t, report.Publishable)
+	assert.Contains(t, issueCodes(report.Warnings), domain.IssueContactDetails)
+	assert.Equal(t, []string{"Mobile Phones"}, suggester.last.CategoryPath)
+	assert.Equal(t, map[string]string{"brand": "Samsung"}, suggester.last.Attributes)
+
+	// An unknown location, a removed category and a full quota all block it
+	listing.Location = domain.Location{Region: "Atlantis", City: "Accra"}
+	listing.CategoryID = "missing"
+	repo.Save(newActiveListing(t, "seller-a", "Tablet", domain.ConditionGood))
+	report, err = service.ValidateListing(ctx, listing.ID, "seller-a")
+	require.NoError(t, err)
+	assert.False(t, report.Publishable)
+	assert.ElementsMatch(t, []string{domain.IssueInvalidLocation, domain.IssueInvalidCategory, domain.IssueQuotaExceeded}, issueCodes(report.Errors))
+
+	// Validating changes nothing
+	stored, err := repo.FindByID(listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusDraft, stored.Status)
+}
+
+func issueCodes(issues []domain.PublicationIssue) []string {
+	codes := []string{}
+	for _, issue := range issues {
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+func TestRulePublicationPolicy_LimitsFor(t *testing.T) {
+	verified := domain.NewSellerCard("seller-verified", time.Now())
+	verified.Verified = true
+	cards := newFakeSellerCardRepository(verified)
+
+	engine := rules.NewEngine(newFakeRuleStore())
+	engine.Register(app.MaxActiveListingsDefinition(50), nil)
+	policy := app.NewRulePublicationPolicy(cards, engine)
+	ctx := context.Background()
+
+	limits, err := policy.LimitsFor(ctx, "seller-new")
+	require.NoError(t, err)
+	assert.Equal(t, 50, limits.MaxActiveListings, "the configured cap applies until the rule is changed")
+
+	_, err = engine.Set(ctx, app.MaxActiveListingsRule, "verified ? 200 : min(20, 5 + total_reviews)", "admin-1")
+	require.NoError(t, err)
+
+	limits, err = policy.LimitsFor(ctx, "seller-verified")
+	require.NoError(t, err)
+	assert.Equal(t, 200, limits.MaxActiveListings)
+	limits, err = policy.LimitsFor(ctx, "seller-new")
+	require.NoError(t, err)
+	assert.Equal(t, 5, limits.MaxActiveListings, "sellers without a card are new and unreviewed")
+}
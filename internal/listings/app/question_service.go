@@ -0,0 +1,331 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// questionsCategory is the notification category of listing questions and answers
+const questionsCategory = "listing_questions"
+
+// ModerationResult is the outcome of reviewing user-written text
+type ModerationResult struct {
+	// Flagged text is held for a moderator instead of being published
+	Flagged bool
+	Reason  string
+}
+
+// ContentModerator reviews questions and answers before they are published
+type ContentModerator interface {
+	Review(ctx context.Context, text string) (ModerationResult, error)
+}
+
+// NotificationPreferences tells which channels a user wants a category of
+// notifications on. It is implemented by the users context.
+type NotificationPreferences interface {
+	NotificationChannels(ctx context.Context, userID ids.UserID, category string) ([]string, error)
+}
+
+// AskQuestionCommand represents the command to ask a public question about a listing
+type AskQuestionCommand struct {
+	ListingID ids.ListingID `json:"-"`
+	AskerID   ids.UserID    `json:"-"`
+	Question  string        `json:"question" binding:"required"`
+}
+
+// AnswerQuestionCommand represents the command for a seller to answer a question
+type AnswerQuestionCommand struct {
+	QuestionID string     `json:"-"`
+	SellerID   ids.UserID `json:"-"`
+	Answer     string     `json:"answer" binding:"required"`
+}
+
+// ListQuestionsQuery represents the query to page through questions
+type ListQuestionsQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ModerationQueueQuery represents the query to list questions by moderation status
+type ModerationQueueQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=published pending_review hidden"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ModerateQuestionCommand represents a moderator's decision on a question
+type ModerateQuestionCommand struct {
+	QuestionID string `json:"-"`
+	Reason     string `json:"reason"`
+}
+
+// ListingQuestions represents a page of listing questions
+type ListingQuestions struct {
+	Questions []*domain.ListingQuestion `json:"questions"`
+	Total     int64                     `json:"total"`
+	Limit     int                       `json:"limit"`
+	Offset    int                       `json:"offset"`
+}
+
+// QuestionService handles public listing questions and answers
+type QuestionService struct {
+	questionRepo domain.ListingQuestionRepository
+	listingRepo  domain.ListingRepository
+	moderator    ContentModerator
+	preferences  NotificationPreferences
+	eventBus     events.EventBus
+}
+
+// NewQuestionService creates a new question service. moderator may be nil to
+// publish everything.
+func NewQuestionService(questionRepo domain.ListingQuestionRepository, listingRepo domain.ListingRepository, moderator ContentModerator, preferences NotificationPreferences, eventBus events.EventBus) *QuestionService {
+	return &QuestionService{
+		questionRepo: questionRepo,
+		listingRepo:  listingRepo,
+		moderator:    moderator,
+		preferences:  preferences,
+		eventBus:     eventBus,
+	}
+}
+
+// AskQuestion posts a question about a listing. Questions the moderator flags
+// are held for review and the seller is only told once they are published.
+func (s *QuestionService) AskQuestion(ctx context.Context, cmd AskQuestionCommand) (*domain.ListingQuestion, error) {
+	listing, err := s.listingRepo.FindByID(cmd.ListingID)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := domain.NewListingQuestion(listing, cmd.AskerID, cmd.Question)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.moderate(ctx, question, question.Question); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.questionRepo.Save(question) }); err != nil {
+		return nil, err
+	}
+
+	if err := s.announce(ctx, question, listing); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// AnswerQuestion records the seller's answer to a question about their listing
+func (s *QuestionService) AnswerQuestion(ctx context.Context, cmd AnswerQuestionCommand) (*domain.ListingQuestion, error) {
+	question, err := s.questionRepo.FindByID(cmd.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	listing, err := s.listingRepo.FindByID(question.ListingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := question.SetAnswer(listing, cmd.SellerID, cmd.Answer); err != nil {
+		return nil, err
+	}
+
+	if err := s.moderate(ctx, question, *question.Answer); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.questionRepo.Update(question) }); err != nil {
+		return nil, err
+	}
+
+	if err := s.announce(ctx, question, listing); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// ListQuestions lists the published questions of a listing, newest first
+func (s *QuestionService) ListQuestions(ctx context.Context, listingID ids.ListingID, query ListQuestionsQuery) (*ListingQuestions, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	questions, total, err := s.questionRepo.FindByListing(listingID, domain.QuestionStatusPublished, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListingQuestions{
+		Questions: questions,
+		Total:     total,
+		Limit:     limit,
+		Offset:    query.Offset,
+	}, nil
+}
+
+// ModerationQueue lists questions by moderation status, pending review by default
+func (s *QuestionService) ModerationQueue(ctx context.Context, query ModerationQueueQuery) (*ListingQuestions, error) {
+	status := domain.QuestionStatus(query.Status)
+	if status == "" {
+		status = domain.QuestionStatusPendingReview
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	questions, total, err := s.questionRepo.FindByStatus(status, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListingQuestions{
+		Questions: questions,
+		Total:     total,
+		Limit:     limit,
+		Offset:    query.Offset,
+	}, nil
+}
+
+// PublishQuestion approves a question held for review or restores a hidden one
+func (s *QuestionService) PublishQuestion(ctx context.Context, questionID string) (*domain.ListingQuestion, error) {
+	question, err := s.questionRepo.FindByID(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.IsVisible() {
+		return question, nil
+	}
+	wasPending := question.Status == domain.QuestionStatusPendingReview
+
+	question.Publish()
+	if err := db.WithRetry(ctx, func() error { return s.questionRepo.Update(question) }); err != nil {
+		return nil, err
+	}
+
+	// Held questions were never announced, so the seller or asker is told now
+	if wasPending {
+		listing, err := s.listingRepo.FindByID(question.ListingID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.announce(ctx, question, listing); err != nil {
+			return nil, err
+		}
+	}
+	return question, nil
+}
+
+// HideQuestion removes a question from public view
+func (s *QuestionService) HideQuestion(ctx context.Context, cmd ModerateQuestionCommand) (*domain.ListingQuestion, error) {
+	question, err := s.questionRepo.FindByID(cmd.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	question.Hide(cmd.Reason)
+	if err := db.WithRetry(ctx, func() error { return s.questionRepo.Update(question) }); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// moderate holds the question for review if the moderator flags the text
+func (s *QuestionService) moderate(ctx context.Context, question *domain.ListingQuestion, text string) error {
+	if s.moderator == nil {
+		return nil
+	}
+
+	result, err := s.moderator.Review(ctx, text)
+	if err != nil {
+		return err
+	}
+	if result.Flagged {
+		question.HoldForReview(result.Reason)
+	}
+	return nil
+}
+
+// announce publishes the event matching the question's state: flagged for
+// moderators, answered for the asker, or asked for the seller
+func (s *QuestionService) announce(ctx context.Context, question *domain.ListingQuestion, listing *domain.Listing) error {
+	var event *events.Event
+	var err error
+
+	switch {
+	case question.Status == domain.QuestionStatusPendingReview:
+		event, err = events.NewEvent(
+			domain.ListingQuestionFlaggedEvent,
+			question.ListingID.String(),
+			domain.ListingQuestionFlagged{
+				QuestionID: question.ID,
+				ListingID:  question.ListingID,
+				Reason:     question.ModerationReason,
+				Timestamp:  time.Now(),
+			},
+		)
+	case !question.IsVisible():
+		return nil
+	case question.IsAnswered():
+		channels, chErr := s.channels(ctx, question.AskerID)
+		if chErr != nil {
+			return chErr
+		}
+		event, err = events.NewEvent(
+			domain.ListingQuestionAnsweredEvent,
+			question.ListingID.String(),
+			domain.ListingQuestionAnswered{
+				QuestionID: question.ID,
+				ListingID:  question.ListingID,
+				SellerID:   listing.SellerID,
+				AskerID:    question.AskerID,
+				Answer:     *question.Answer,
+				Channels:   channels,
+				Timestamp:  time.Now(),
+			},
+		)
+	default:
+		channels, chErr := s.channels(ctx, listing.SellerID)
+		if chErr != nil {
+			return chErr
+		}
+		event, err = events.NewEvent(
+			domain.ListingQuestionAskedEvent,
+			question.ListingID.String(),
+			domain.ListingQuestionAsked{
+				QuestionID: question.ID,
+				ListingID:  question.ListingID,
+				SellerID:   listing.SellerID,
+				AskerID:    question.AskerID,
+				Question:   question.Question,
+				Channels:   channels,
+				Timestamp:  time.Now(),
+			},
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// channels returns the channels a user wants question notifications on
+func (s *QuestionService) channels(ctx context.Context, userID ids.UserID) ([]string, error) {
+	channels, err := s.preferences.NotificationChannels(ctx, userID, questionsCategory)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return channels, nil
+}
@@ -0,0 +1,124 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestionService_AskAndAnswer(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	questions := &fakeListingQuestionRepository{}
+	bus := &fakeEventBus{}
+	preferences := &fakeNotificationPreferences{channels: map[ids.UserID][]string{"buyer-a": {"push"}}}
+	service := app.NewQuestionService(questions, newFakeListingRepository(listing), &fakeModerator{}, preferences, bus)
+
+	question, err := service.AskQuestion(context.Background(), app.AskQuestionCommand{
+		ListingID: listing.ID,
+		AskerID:   "buyer-a",
+		Question:  "Is the battery original?",
+	})
+	require.NoError(t, err)
+	assert.True(t, question.IsVisible())
+
+	asked := bus.eventsOfType(domain.ListingQuestionAskedEvent)
+	require.Len(t, asked, 1)
+	var askedData domain.ListingQuestionAsked
+	require.NoError(t, events.ParseEventData(asked[0], &askedData))
+	assert.Equal(t, ids.UserID("seller-a"), askedData.SellerID)
+	assert.Equal(t, []string{"email", "push"}, askedData.Channels)
+
+	// Only the seller can answer
+	_, err = service.AnswerQuestion(context.Background(), app.AnswerQuestionCommand{QuestionID: question.ID, SellerID: "buyer-b", Answer: "Yes"})
+	assert.Error(t, err)
+
+	answered, err := service.AnswerQuestion(context.Background(), app.AnswerQuestionCommand{QuestionID: question.ID, SellerID: "seller-a", Answer: "Yes, never replaced"})
+	require.NoError(t, err)
+	assert.True(t, answered.IsAnswered())
+
+	answers := bus.eventsOfType(domain.ListingQuestionAnsweredEvent)
+	require.Len(t, answers, 1)
+	var answerData domain.ListingQuestionAnswered
+	require.NoError(t, events.ParseEventData(answers[0], &answerData))
+	assert.Equal(t, ids.UserID("buyer-a"), answerData.AskerID)
+	assert.Equal(t, []string{"push"}, answerData.Channels)
+
+	page, err := service.ListQuestions(context.Background(), listing.ID, app.ListQuestionsQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+}
+
+func TestQuestionService_ModerationHoldsQuestion(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	questions := &fakeListingQuestionRepository{}
+	bus := &fakeEventBus{}
+	service := app.NewQuestionService(questions, newFakeListingRepository(listing), &fakeModerator{blocked: "whatsapp"}, &fakeNotificationPreferences{}, bus)
+
+	question, err := service.AskQuestion(context.Background(), app.AskQuestionCommand{
+		ListingID: listing.ID,
+		AskerID:   "buyer-a",
+		Question:  "Can we talk on whatsapp?",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuestionStatusPendingReview, question.Status)
+	assert.Len(t, bus.eventsOfType(domain.ListingQuestionFlaggedEvent), 1)
+	// The seller is not told about a held question
+	assert.Empty(t, bus.eventsOfType(domain.ListingQuestionAskedEvent))
+
+	page, err := service.ListQuestions(context.Background(), listing.ID, app.ListQuestionsQuery{})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+
+	queue, err := service.ModerationQueue(context.Background(), app.ModerationQueueQuery{})
+	require.NoError(t, err)
+	require.Len(t, queue.Questions, 1)
+
+	// Approving the question publishes it and tells the seller
+	published, err := service.PublishQuestion(context.Background(), question.ID)
+	require.NoError(t, err)
+	assert.True(t, published.IsVisible())
+	assert.Len(t, bus.eventsOfType(domain.ListingQuestionAskedEvent), 1)
+
+	hidden, err := service.HideQuestion(context.Background(), app.ModerateQuestionCommand{QuestionID: question.ID, Reason: "spam"})
+	require.NoError(t, err)
+	assert.False(t, hidden.IsVisible())
+}
+
+func TestListingService_GetListingIncludesTopQuestions(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	listings := newFakeListingRepository(listing)
+	questions := &fakeListingQuestionRepository{}
+	questionService := app.NewQuestionService(questions, listings, nil, &fakeNotificationPreferences{}, &fakeEventBus{})
+
+	for i, text := range []string{"Is it unlocked?", "Any scratches?", "Does it have a box?", "Is the price firm?", "Still available?"} {
+		question, err := questionService.AskQuestion(context.Background(), app.AskQuestionCommand{ListingID: listing.ID, AskerID: "buyer-a", Question: text})
+		require.NoError(t, err)
+		if i < 4 {
+			_, err = questionService.AnswerQuestion(context.Background(), app.AnswerQuestionCommand{QuestionID: question.ID, SellerID: "seller-a", Answer: "Yes"})
+			require.NoError(t, err)
+		}
+	}
+
+	service := app.NewListingService(listings, questions, &fakeEventBus{}, nil, nil, nil, nil, nil, nil, nil, nil)
+	detail, err := service.GetListing(context.Background(), listing.ID, "viewer-1")
+	require.NoError(t, err)
+	assert.Equal(t, listing.ID, detail.ID)
+	assert.Len(t, detail.Questions, 3)
+	for _, question := range detail.Questions {
+		assert.True(t, question.IsAnswered())
+	}
+	assert.Equal(t, int64(5), detail.QuestionCount)
+
+	draft, err := domain.NewListing("seller-a", "category-1", "Draft", "", money.Cedis(50), domain.ConditionNew, domain.Location{})
+	require.NoError(t, err)
+	require.NoError(t, listings.Save(draft))
+	_, err = service.GetListing(context.Background(), draft.ID, "viewer-1")
+	assert.Error(t, err)
+}
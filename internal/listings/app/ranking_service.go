@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// SellerEngagementSource reports when sellers last logged in and how they
+// answer buyers. It is implemented by the users context.
+type SellerEngagementSource interface {
+	// SellerEngagement returns the next batch of sellers after afterID ordered
+	// by ID, counting inquiries and responses since the given time
+	SellerEngagement(ctx context.Context, afterID ids.UserID, limit int, since time.Time) ([]domain.SellerEngagement, error)
+}
+
+// UpdateRankingPolicyCommand represents an administrator's change to the
+// ranking penalties
+type UpdateRankingPolicyCommand struct {
+	StaleAfterDays      int        `json:"stale_after_days" binding:"min=0"`
+	StalePenalty        float64    `json:"stale_penalty" binding:"required"`
+	MinResponseRate     float64    `json:"min_response_rate" binding:"min=0,max=1"`
+	MinInquiries        int        `json:"min_inquiries" binding:"min=0"`
+	UnresponsivePenalty float64    `json:"unresponsive_penalty" binding:"required"`
+	ResponseWindowDays  int        `json:"response_window_days" binding:"required"`
+	UpdatedBy           ids.UserID `json:"-"`
+}
+
+// ListPenalizedSellersQuery represents the query to list demoted sellers
+type ListPenalizedSellersQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// SellerRanking is a seller's ranking penalty and the reasons for it
+type SellerRanking struct {
+	SellerID ids.UserID `json:"seller_id"`
+	Factor   float64    `json:"factor"`
+	Reasons  []string   `json:"reasons"`
+	RankedAt *time.Time `json:"ranked_at"`
+}
+
+// PenalizedSellers is a page of demoted sellers
+type PenalizedSellers struct {
+	Sellers []SellerRanking `json:"sellers"`
+	Total   int64           `json:"total"`
+	Limit   int             `json:"limit"`
+	Offset  int             `json:"offset"`
+}
+
+// RankingSummary reports the outcome of a ranking run
+type RankingSummary struct {
+	Sellers   int `json:"sellers"`
+	Penalized int `json:"penalized"`
+	Changed   int `json:"changed"`
+}
+
+// RankingService demotes listings of stale or unresponsive sellers in search
+type RankingService struct {
+	cardRepo   domain.SellerCardRepository
+	policyRepo domain.RankingPolicyRepository
+	engagement SellerEngagementSource
+}
+
+// NewRankingService creates a new ranking service
+func NewRankingService(cardRepo domain.SellerCardRepository, policyRepo domain.RankingPolicyRepository, engagement SellerEngagementSource) *RankingService {
+	return &RankingService{
+		cardRepo:   cardRepo,
+		policyRepo: policyRepo,
+		engagement: engagement,
+	}
+}
+
+// GetPolicy returns the ranking policy, or the defaults if no administrator
+// has changed it
+func (s *RankingService) GetPolicy(ctx context.Context) (*domain.RankingPolicy, error) {
+	policy, err := s.policyRepo.Find()
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return domain.DefaultRankingPolicy(), nil
+		}
+		return nil, err
+	}
+	return policy, nil
+}
+
+// UpdatePolicy replaces the ranking policy. Penalties are applied on the next
+// ranking run.
+func (s *RankingService) UpdatePolicy(ctx context.Context, cmd UpdateRankingPolicyCommand) (*domain.RankingPolicy, error) {
+	policy := &domain.RankingPolicy{
+		ID:                  domain.DefaultRankingPolicyID,
+		StaleAfterDays:      cmd.StaleAfterDays,
+		StalePenalty:        cmd.StalePenalty,
+		MinResponseRate:     cmd.MinResponseRate,
+		MinInquiries:        cmd.MinInquiries,
+		UnresponsivePenalty: cmd.UnresponsivePenalty,
+		ResponseWindowDays:  cmd.ResponseWindowDays,
+		UpdatedBy:           cmd.UpdatedBy,
+		UpdatedAt:           time.Now(),
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.policyRepo.Save(policy) }); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// RecalculatePenalties applies the ranking policy to every seller's latest
+// engagement, storing penalties that changed
+func (s *RankingService) RecalculatePenalties(ctx context.Context, batchSize int) (*RankingSummary, error) {
+	policy, err := s.GetPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	since := policy.ResponseWindowStart(now)
+	summary := &RankingSummary{}
+	var afterID ids.UserID
+	for {
+		batch, err := s.engagement.SellerEngagement(ctx, afterID, batchSize, since)
+		if err != nil {
+			return summary, err
+		}
+		if len(batch) == 0 {
+			return summary, nil
+		}
+
+		cards, err := s.cardRepo.FindBySellerIDs(sellerIDsOfEngagement(batch))
+		if err != nil {
+			return summary, err
+		}
+		current := make(map[ids.UserID]*domain.SellerCard, len(cards))
+		for _, card := range cards {
+			current[card.SellerID] = card
+		}
+
+		for _, engagement := range batch {
+			factor, reasons := policy.Penalty(engagement, now)
+			summary.Sellers++
+			if factor < 1 {
+				summary.Penalized++
+			}
+
+			if !rankingChanged(current[engagement.SellerID], factor, reasons) {
+				continue
+			}
+
+			err := db.WithRetry(ctx, func() error {
+				return s.cardRepo.SaveRanking(engagement.SellerID, factor, reasons, now)
+			})
+			if err != nil {
+				return summary, err
+			}
+			summary.Changed++
+		}
+
+		afterID = batch[len(batch)-1].SellerID
+		if len(batch) < batchSize {
+			return summary, nil
+		}
+	}
+}
+
+// ListPenalizedSellers lists the sellers whose listings are demoted, most
+// demoted first
+func (s *RankingService) ListPenalizedSellers(ctx context.Context, query ListPenalizedSellersQuery) (*PenalizedSellers, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	cards, total, err := s.cardRepo.FindPenalized(limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	sellers := make([]SellerRanking, 0, len(cards))
+	for _, card := range cards {
+		sellers = append(sellers, SellerRanking{
+			SellerID: card.SellerID,
+			Factor:   card.RankingFactor,
+			Reasons:  card.RankingReasons,
+			RankedAt: card.RankedAt,
+		})
+	}
+	return &PenalizedSellers{
+		Sellers: sellers,
+		Total:   total,
+		Limit:   limit,
+		Offset:  query.Offset,
+	}, nil
+}
+
+// rankingChanged reports whether a seller's penalty differs from the one on
+// their card. Sellers without a card have no penalty yet.
+func rankingChanged(card *domain.SellerCard, factor float64, reasons []string) bool {
+	if card == nil {
+		return factor != 1
+	}
+	return card.RankingFactor != factor || !slices.Equal(card.RankingReasons, reasons)
+}
+
+// sellerIDsOfEngagement returns the IDs of the sellers in a batch
+func sellerIDsOfEngagement(batch []domain.SellerEngagement) []ids.UserID {
+	sellerIDs := make([]ids.UserID, 0, len(batch))
+	for _, engagement := range batch {
+		sellerIDs = append(sellerIDs, engagement.SellerID)
+	}
+	return sellerIDs
+}
@@ -0,0 +1,98 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankingService_RecalculatePenalties(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-24 * time.Hour)
+	longAgo := now.AddDate(0, 0, -90)
+
+	engagement := &fakeSellerEngagementSource{sellers: []domain.SellerEngagement{
+		{SellerID: "seller-a", LastLoginAt: &recent, Inquiries: 10, Responses: 9},
+		{SellerID: "seller-b", LastLoginAt: &longAgo},
+		{SellerID: "seller-c", LastLoginAt: &recent, Inquiries: 10, Responses: 2},
+		{SellerID: "seller-d", Inquiries: 3, Responses: 0},
+	}}
+	cards := newFakeSellerCardRepository()
+	service := app.NewRankingService(cards, &fakeRankingPolicyRepository{}, engagement)
+	ctx := context.Background()
+
+	summary, err := service.RecalculatePenalties(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 4, summary.Sellers)
+	assert.Equal(t, 3, summary.Penalized)
+	assert.Equal(t, 3, summary.Changed, "sellers in good standing keep their card untouched")
+
+	penalized, err := service.ListPenalizedSellers(ctx, app.ListPenalizedSellersQuery{})
+	require.NoError(t, err)
+	require.Len(t, penalized.Sellers, 3)
+	assert.Equal(t, int64(3), penalized.Total)
+	// Too few inquiries to judge seller-d's response rate, but they never logged in
+	assert.Equal(t, ids.UserID("seller-b"), penalized.Sellers[0].SellerID)
+	assert.Equal(t, []string{domain.RankingReasonStale}, penalized.Sellers[0].Reasons)
+	assert.Equal(t, ids.UserID("seller-d"), penalized.Sellers[1].SellerID)
+	assert.Equal(t, ids.UserID("seller-c"), penalized.Sellers[2].SellerID)
+	assert.Equal(t, []string{domain.RankingReasonUnresponsive}, penalized.Sellers[2].Reasons)
+	assert.InDelta(t, 0.7, penalized.Sellers[2].Factor, 1e-9)
+
+	// A run with nothing new leaves the cards alone
+	summary, err = service.RecalculatePenalties(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.Changed)
+
+	// Penalties are lifted once the seller logs in again
+	engagement.sellers[1].LastLoginAt = &recent
+	summary, err = service.RecalculatePenalties(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Changed)
+	found, err := cards.FindBySellerIDs([]ids.UserID{"seller-b"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, 1.0, found[0].RankingFactor)
+	assert.Empty(t, found[0].RankingReasons)
+}
+
+func TestRankingService_Policy(t *testing.T) {
+	service := app.NewRankingService(newFakeSellerCardRepository(), &fakeRankingPolicyRepository{}, &fakeSellerEngagementSource{})
+	ctx := context.Background()
+
+	policy, err := service.GetPolicy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultRankingPolicy(), policy)
+
+	_, err = service.UpdatePolicy(ctx, app.UpdateRankingPolicyCommand{
+		StaleAfterDays:      14,
+		StalePenalty:        1.5,
+		UnresponsivePenalty: 0.8,
+		ResponseWindowDays:  30,
+	})
+	assert.Error(t, err, "a penalty cannot promote listings")
+
+	_, err = service.UpdatePolicy(ctx, app.UpdateRankingPolicyCommand{
+		StaleAfterDays:      14,
+		StalePenalty:        0.25,
+		MinResponseRate:     0.6,
+		MinInquiries:        3,
+		UnresponsivePenalty: 0.8,
+		ResponseWindowDays:  14,
+		UpdatedBy:           "admin-1",
+	})
+	require.NoError(t, err)
+
+	policy, err = service.GetPolicy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 14, policy.StaleAfterDays)
+	assert.Equal(t, 0.25, policy.StalePenalty)
+	assert.Equal(t, ids.UserID("admin-1"), policy.UpdatedBy)
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// ListingRenewalService handles keeping listings up past their expiry and
+// relisting sold or expired ones
+type ListingRenewalService struct {
+	listingRepo domain.ListingRepository
+	eventBus    events.EventBus
+	publication PublicationValidator
+	limits      domain.RenewalLimits
+	clock       clock.Clock
+}
+
+// NewListingRenewalService creates a new listing renewal service. publication
+// may be nil when expired listings go back live without checks, and clk may
+// be nil to use the system clock.
+func NewListingRenewalService(listingRepo domain.ListingRepository, eventBus events.EventBus, publication PublicationValidator, limits domain.RenewalLimits, clk clock.Clock) *ListingRenewalService {
+	return &ListingRenewalService{
+		listingRepo: listingRepo,
+		eventBus:    eventBus,
+		publication: publication,
+		limits:      limits,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// RenewListing pushes back when one of the seller's listings expires. Expired
+// listings go back live, so they must pass the publication rules again.
+func (s *ListingRenewalService) RenewListing(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.sellerListing(listingID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if listing.Status != domain.ListingStatusActive && s.publication != nil {
+		report, err := s.publication.ValidateListing(ctx, listingID, sellerID)
+		if err != nil {
+			return nil, err
+		}
+		if !report.Publishable {
+			return nil, errors.ValidationError(report.Errors[0].Message)
+		}
+	}
+
+	if err := listing.Renew(s.limits, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return nil, err
+	}
+	if err := publishListingChanged(ctx, s.eventBus, listing); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// RelistListing copies one of the seller's sold or expired listings into a
+// new draft, which the seller can change before activating it
+func (s *ListingRenewalService) RelistListing(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.sellerListing(listingID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	draft, err := listing.Relist(s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Save(draft) }); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// sellerListing loads a listing and checks it belongs to the seller
+func (s *ListingRenewalService) sellerListing(listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID != sellerID {
+		return nil, errors.ForbiddenError("listing does not belong to the seller")
+	}
+	return listing, nil
+}
@@ -0,0 +1,77 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListingRenewalService_RenewListing(t *testing.T) {
+	expiring := newDraftListing(t, "seller-a", "Phone")
+	require.NoError(t, expiring.Activate())
+	expiring.ExpiresAt = time.Now().Add(time.Hour)
+	expired := newDraftListing(t, "seller-a", "Laptop")
+	expired.Status = domain.ListingStatusExpired
+	expired.ExpiresAt = time.Now().Add(-time.Hour)
+
+	bus := &fakeEventBus{}
+	publication := &fakePublicationValidator{blocked: map[ids.ListingID]string{expired.ID: "add at least one photo"}}
+	service := app.NewListingRenewalService(newFakeListingRepository(expiring, expired), bus, publication, domain.RenewalLimits{Window: 24 * time.Hour, MaxRenewals: 1}, nil)
+	ctx := context.Background()
+
+	// Only the seller can renew their listing
+	_, err := service.RenewListing(ctx, expiring.ID, "seller-b")
+	assert.Error(t, err)
+
+	listing, err := service.RenewListing(ctx, expiring.ID, "seller-a")
+	require.NoError(t, err)
+	assert.True(t, listing.ExpiresAt.After(time.Now().AddDate(0, 0, domain.ListingLifetimeDays)))
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 1)
+
+	// The only renewal is used up
+	_, err = service.RenewListing(ctx, expiring.ID, "seller-a")
+	assert.Error(t, err)
+
+	// Expired listings go back live only if they pass the publication rules
+	_, err = service.RenewListing(ctx, expired.ID, "seller-a")
+	assert.Error(t, err)
+	assert.Equal(t, domain.ListingStatusExpired, expired.Status)
+	delete(publication.blocked, expired.ID)
+	listing, err = service.RenewListing(ctx, expired.ID, "seller-a")
+	require.NoError(t, err)
+	assert.True(t, listing.IsActive())
+}
+
+func TestListingRenewalService_RelistListing(t *testing.T) {
+	sold := newDraftListing(t, "seller-a", "Phone")
+	require.NoError(t, sold.Activate())
+	require.NoError(t, sold.MarkAsSold())
+	live := newDraftListing(t, "seller-a", "Laptop")
+	require.NoError(t, live.Activate())
+
+	repo := newFakeListingRepository(sold, live)
+	service := app.NewListingRenewalService(repo, &fakeEventBus{}, nil, domain.RenewalLimits{}, nil)
+	ctx := context.Background()
+
+	_, err := service.RelistListing(ctx, sold.ID, "seller-b")
+	assert.Error(t, err)
+	_, err = service.RelistListing(ctx, live.ID, "seller-a")
+	assert.Error(t, err)
+
+	draft, err := service.RelistListing(ctx, sold.ID, "seller-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusDraft, draft.Status)
+	assert.Equal(t, sold.ID, *draft.RelistedFrom)
+
+	stored, err := repo.FindByID(draft.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Phone", stored.Title)
+	assert.Equal(t, domain.ListingStatusSold, sold.Status)
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// ReportListingCommand represents the command to report a listing
+type ReportListingCommand struct {
+	ListingID  ids.ListingID `json:"-"`
+	ReporterID ids.UserID    `json:"-"`
+	Reason     string        `json:"reason" binding:"required,oneof=scam prohibited_item wrong_category"`
+	Details    string        `json:"details" binding:"max=1000"`
+}
+
+// ResolveReportCommand represents a moderator's decision on a report
+type ResolveReportCommand struct {
+	ReportID   string     `json:"-"`
+	AdminID    ids.UserID `json:"-"`
+	Resolution string     `json:"resolution" binding:"required,oneof=dismiss uphold"`
+	Note       string     `json:"note" binding:"max=1000"`
+}
+
+// ReportQueueQuery represents the query to list reports by status
+type ReportQueueQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=open dismissed upheld"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ListingReports represents a page of listing reports
+type ListingReports struct {
+	Reports []*domain.ListingReport `json:"reports"`
+	Total   int64                   `json:"total"`
+	Limit   int                     `json:"limit"`
+	Offset  int                     `json:"offset"`
+}
+
+// ReportService handles users reporting listings and moderators reviewing
+// the reports
+type ReportService struct {
+	reportRepo  domain.ListingReportRepository
+	listingRepo domain.ListingRepository
+	eventBus    events.EventBus
+	threshold   int
+	clock       clock.Clock
+}
+
+// NewReportService creates a new report service. A listing with threshold
+// open reports from different users is taken down until a moderator reviews
+// them; zero leaves every listing up. clk may be nil to use the system clock.
+func NewReportService(reportRepo domain.ListingReportRepository, listingRepo domain.ListingRepository, eventBus events.EventBus, threshold int, clk clock.Clock) *ReportService {
+	return &ReportService{
+		reportRepo:  reportRepo,
+		listingRepo: listingRepo,
+		eventBus:    eventBus,
+		threshold:   threshold,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// ReportListing records a user's report of a live listing and takes the
+// listing down once enough users have reported it
+func (s *ReportService) ReportListing(ctx context.Context, cmd ReportListingCommand) (*domain.ListingReport, error) {
+	listing, err := s.listingRepo.FindByID(cmd.ListingID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := domain.NewListingReport(listing, cmd.ReporterID, domain.ReportReason(cmd.Reason), cmd.Details, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	reported, err := s.reportRepo.HasReported(cmd.ListingID, cmd.ReporterID)
+	if err != nil {
+		return nil, err
+	}
+	if reported {
+		return nil, errors.ConflictError("you have already reported this listing")
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.reportRepo.Save(report) }); err != nil {
+		return nil, err
+	}
+
+	if s.threshold == 0 {
+		return report, nil
+	}
+	open, err := s.reportRepo.FindOpenByListing(cmd.ListingID)
+	if err != nil {
+		return nil, err
+	}
+	if len(open) >= s.threshold && listing.Hold(domain.ListingHoldReported) {
+		if err := s.updateListing(ctx, listing); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// ReportQueue lists reports by status, open reports by default
+func (s *ReportService) ReportQueue(ctx context.Context, query ReportQueueQuery) (*ListingReports, error) {
+	status := domain.ReportStatus(query.Status)
+	if status == "" {
+		status = domain.ReportStatusOpen
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	reports, total, err := s.reportRepo.FindByStatus(status, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListingReports{
+		Reports: reports,
+		Total:   total,
+		Limit:   limit,
+		Offset:  query.Offset,
+	}, nil
+}
+
+// ResolveReport records a moderator's decision on a report. Upholding a
+// report takes the listing down for good and settles the listing's other
+// open reports with it. A listing taken down by reports goes back live once
+// every report of it has been dismissed.
+func (s *ReportService) ResolveReport(ctx context.Context, cmd ResolveReportCommand) (*domain.ListingReport, error) {
+	report, err := s.reportRepo.FindByID(cmd.ReportID)
+	if err != nil {
+		return nil, err
+	}
+	listing, err := s.listingRepo.FindByID(report.ListingID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	resolution := domain.ReportResolution(cmd.Resolution)
+	if err := report.Resolve(resolution, cmd.AdminID, cmd.Note, now); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.reportRepo.Update(report) }); err != nil {
+		return nil, err
+	}
+
+	open, err := s.reportRepo.FindOpenByListing(report.ListingID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	if resolution == domain.ReportResolutionUphold {
+		for _, other := range open {
+			if err := other.Resolve(resolution, cmd.AdminID, cmd.Note, now); err != nil {
+				return nil, err
+			}
+			if err := db.WithRetry(ctx, func() error { return s.reportRepo.Update(other) }); err != nil {
+				return nil, err
+			}
+		}
+		// Hold before releasing, so the listing never goes back live in between
+		changed = listing.Hold(domain.ListingHoldRemoved)
+		changed = listing.Release(domain.ListingHoldReported) || changed
+	} else if len(open) == 0 {
+		changed = listing.Release(domain.ListingHoldReported)
+	}
+
+	if changed {
+		if err := s.updateListing(ctx, listing); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// updateListing saves a listing taken down or put back by moderation and
+// tells the read models
+func (s *ReportService) updateListing(ctx context.Context, listing *domain.Listing) error {
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return err
+	}
+	return publishListingChanged(ctx, s.eventBus, listing)
+}
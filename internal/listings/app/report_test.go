@@ -0,0 +1,102 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportService_ReportListingTakesItDownAtThreshold(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	reports := newFakeListingReportRepository()
+	bus := &fakeEventBus{}
+	clk := clock.NewFrozen(time.Now())
+	service := app.NewReportService(reports, newFakeListingRepository(listing), bus, 2, clk)
+	ctx := context.Background()
+
+	report, err := service.ReportListing(ctx, app.ReportListingCommand{ListingID: listing.ID, ReporterID: "buyer-a", Reason: "scam", Details: "  Asked me to pay outside the app  "})
+	require.NoError(t, err)
+	assert.Equal(t, "Asked me to pay outside the app", report.Details)
+	assert.True(t, listing.IsActive())
+
+	// Each user counts once
+	_, err = service.ReportListing(ctx, app.ReportListingCommand{ListingID: listing.ID, ReporterID: "buyer-a", Reason: "scam"})
+	assert.Error(t, err)
+	_, err = service.ReportListing(ctx, app.ReportListingCommand{ListingID: listing.ID, ReporterID: "seller-a", Reason: "scam"})
+	assert.Error(t, err, "sellers cannot report their own listings")
+
+	clk.Advance(time.Minute)
+	_, err = service.ReportListing(ctx, app.ReportListingCommand{ListingID: listing.ID, ReporterID: "buyer-b", Reason: "prohibited_item"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusInactive, listing.Status)
+	assert.Equal(t, []domain.ListingHold{domain.ListingHoldReported}, listing.Holds)
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 1)
+
+	queue, err := service.ReportQueue(ctx, app.ReportQueueQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), queue.Total)
+	assert.Equal(t, ids.UserID("buyer-a"), queue.Reports[0].ReporterID)
+}
+
+func TestReportService_DismissingEveryReportPutsListingBack(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	reports := newFakeListingReportRepository()
+	service := app.NewReportService(reports, newFakeListingRepository(listing), &fakeEventBus{}, 2, nil)
+	ctx := context.Background()
+
+	first, err := service.ReportListing(ctx, app.ReportListingCommand{ListingID: listing.ID, ReporterID: "buyer-a", Reason: "wrong_category"})
+	require.NoError(t, err)
+	second, err := service.ReportListing(ctx, app.ReportListingCommand{ListingID: listing.ID, ReporterID: "buyer-b", Reason: "wrong_category"})
+	require.NoError(t, err)
+	require.True(t, listing.IsHeld())
+
+	dismissed, err := service.ResolveReport(ctx, app.ResolveReportCommand{ReportID: first.ID, AdminID: "admin-1", Resolution: "dismiss", Note: "Right category"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReportStatusDismissed, dismissed.Status)
+	assert.True(t, listing.IsHeld(), "the listing waits for every report to be reviewed")
+
+	_, err = service.ResolveReport(ctx, app.ResolveReportCommand{ReportID: first.ID, AdminID: "admin-1", Resolution: "uphold"})
+	assert.Error(t, err, "resolved reports stay resolved")
+
+	_, err = service.ResolveReport(ctx, app.ResolveReportCommand{ReportID: second.ID, AdminID: "admin-1", Resolution: "dismiss"})
+	require.NoError(t, err)
+	assert.True(t, listing.IsActive())
+	assert.Empty(t, listing.Holds)
+}
+
+func TestReportService_UpholdingReportRemovesListing(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	reports := newFakeListingReportRepository()
+	bus := &fakeEventBus{}
+	service := app.NewReportService(reports, newFakeListingRepository(listing), bus, 3, nil)
+	ctx := context.Background()
+
+	first, err := service.ReportListing(ctx, app.ReportListingCommand{ListingID: listing.ID, ReporterID: "buyer-a", Reason: "scam"})
+	require.NoError(t, err)
+	second, err := service.ReportListing(ctx, app.ReportListingCommand{ListingID: listing.ID, ReporterID: "buyer-b", Reason: "scam"})
+	require.NoError(t, err)
+	require.True(t, listing.IsActive(), "below the threshold listings stay up")
+
+	_, err = service.ResolveReport(ctx, app.ResolveReportCommand{ReportID: first.ID, AdminID: "admin-1", Resolution: "uphold", Note: "Known scam"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusInactive, listing.Status)
+	assert.Equal(t, []domain.ListingHold{domain.ListingHoldRemoved}, listing.Holds)
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 1)
+
+	other, err := reports.FindByID(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReportStatusUpheld, other.Status, "the listing's other reports are settled with it")
+
+	// The seller cannot put a removed listing back up
+	listingService := app.NewListingService(newFakeListingRepository(listing), nil, bus, nil, nil, nil, nil, &fakePublicationValidator{}, nil, nil, nil)
+	_, err = listingService.ActivateListing(ctx, listing.ID, "seller-a")
+	assert.Error(t, err)
+}
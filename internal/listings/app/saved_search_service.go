@@ -0,0 +1,256 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// savedSearchesCategory is the notification category of saved search alerts
+const savedSearchesCategory = "saved_searches"
+
+// savedSearchBatchSize is how many saved searches are evaluated against a new listing at a time
+const savedSearchBatchSize = 200
+
+// SaveSearchCommand represents the command to save a search or replace a
+// saved search's query and filters
+type SaveSearchCommand struct {
+	UserID     ids.UserID `json:"-"`
+	Name       string     `json:"name" binding:"required,max=100"`
+	Query      string     `json:"q" binding:"max=255"`
+	CategoryID string     `json:"category_id" binding:"omitempty,uuid"`
+	Condition  string     `json:"condition" binding:"omitempty,oneof=new like_new good fair poor for_parts"`
+	MinPrice   *float64   `json:"min_price" binding:"omitempty,min=0"`
+	MaxPrice   *float64   `json:"max_price" binding:"omitempty,min=0"`
+	Region     string     `json:"region" binding:"max=100"`
+	City       string     `json:"city" binding:"max=100"`
+	Negotiable *bool      `json:"negotiable"`
+	Latitude   *float64   `json:"lat" binding:"omitempty,min=-90,max=90"`
+	Longitude  *float64   `json:"lng" binding:"omitempty,min=-180,max=180"`
+	RadiusKm   float64    `json:"radius_km" binding:"omitempty,gt=0,max=200"`
+	// AlertsEnabled defaults to true: saved searches alert about new matches
+	AlertsEnabled *bool `json:"alerts_enabled"`
+}
+
+// SavedSearchService lets buyers save searches and alerts them when newly
+// activated listings match
+type SavedSearchService struct {
+	savedSearchRepo domain.SavedSearchRepository
+	listingRepo     domain.ListingRepository
+	preferences     NotificationPreferences
+	eventBus        events.EventBus
+}
+
+// NewSavedSearchService creates a new saved search service
+func NewSavedSearchService(savedSearchRepo domain.SavedSearchRepository, listingRepo domain.ListingRepository, preferences NotificationPreferences, eventBus events.EventBus) *SavedSearchService {
+	return &SavedSearchService{
+		savedSearchRepo: savedSearchRepo,
+		listingRepo:     listingRepo,
+		preferences:     preferences,
+		eventBus:        eventBus,
+	}
+}
+
+// CreateSavedSearch saves a search for a user
+func (s *SavedSearchService) CreateSavedSearch(ctx context.Context, cmd SaveSearchCommand) (*domain.SavedSearch, error) {
+	count, err := s.savedSearchRepo.CountByUser(cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= domain.MaxSavedSearchesPerUser {
+		return nil, errors.ConflictError("saved search limit reached")
+	}
+
+	search, err := domain.NewSavedSearch(cmd.UserID, cmd.Name, cmd.filters(), cmd.alertsEnabled(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.savedSearchRepo.Save(search) }); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// ListSavedSearches lists a user's saved searches, newest first
+func (s *SavedSearchService) ListSavedSearches(ctx context.Context, userID ids.UserID) ([]*domain.SavedSearch, error) {
+	return s.savedSearchRepo.FindByUser(userID)
+}
+
+// GetSavedSearch retrieves one of a user's saved searches
+func (s *SavedSearchService) GetSavedSearch(ctx context.Context, searchID string, userID ids.UserID) (*domain.SavedSearch, error) {
+	return s.ownedSearch(searchID, userID)
+}
+
+// UpdateSavedSearch replaces the name, query, filters and alert setting of a saved search
+func (s *SavedSearchService) UpdateSavedSearch(ctx context.Context, searchID string, cmd SaveSearchCommand) (*domain.SavedSearch, error) {
+	search, err := s.ownedSearch(searchID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := search.Update(cmd.Name, cmd.filters(), cmd.alertsEnabled(), time.Now()); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.savedSearchRepo.Update(search) }); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// DeleteSavedSearch deletes one of a user's saved searches
+func (s *SavedSearchService) DeleteSavedSearch(ctx context.Context, searchID string, userID ids.UserID) error {
+	if _, err := s.ownedSearch(searchID, userID); err != nil {
+		return err
+	}
+	return db.WithRetry(ctx, func() error { return s.savedSearchRepo.Delete(searchID) })
+}
+
+// NotifyMatches alerts the buyers whose saved searches match a newly
+// activated listing, once per buyer however many of their searches match.
+// Sellers are not alerted about their own listings, nor buyers who turned
+// saved search notifications off. It returns how many buyers were alerted.
+func (s *SavedSearchService) NotifyMatches(ctx context.Context, listingID ids.ListingID) (int, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return 0, err
+	}
+	if listing.Status != domain.ListingStatusActive {
+		return 0, nil
+	}
+
+	alerted := make(map[ids.UserID]bool)
+	afterID := ""
+	for {
+		searches, err := s.savedSearchRepo.FindAlertCandidates(listing, afterID, savedSearchBatchSize)
+		if err != nil {
+			return len(alerted), err
+		}
+		if len(searches) == 0 {
+			return len(alerted), nil
+		}
+
+		alerts, err := s.alerts(ctx, listing, searches, alerted)
+		if err != nil {
+			return len(alerted), err
+		}
+		if len(alerts) > 0 {
+			if err := s.publishMatched(ctx, listing, alerts); err != nil {
+				return len(alerted), err
+			}
+			searchIDs := make([]string, 0, len(alerts))
+			for _, alert := range alerts {
+				searchIDs = append(searchIDs, alert.SavedSearchID)
+			}
+			if err := db.WithRetry(ctx, func() error { return s.savedSearchRepo.MarkAlerted(searchIDs, time.Now()) }); err != nil {
+				return len(alerted), err
+			}
+		}
+
+		if len(searches) < savedSearchBatchSize {
+			return len(alerted), nil
+		}
+		afterID = searches[len(searches)-1].ID
+	}
+}
+
+// alerts picks the buyers of a batch of saved searches to alert about a
+// listing, adding them to alerted so each buyer is alerted once
+func (s *SavedSearchService) alerts(ctx context.Context, listing *domain.Listing, searches []*domain.SavedSearch, alerted map[ids.UserID]bool) ([]domain.SavedSearchAlert, error) {
+	var alerts []domain.SavedSearchAlert
+	for _, search := range searches {
+		if search.UserID == listing.SellerID || alerted[search.UserID] || !search.Matches(listing) {
+			continue
+		}
+		channels, err := s.channels(ctx, search.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if len(channels) == 0 {
+			continue
+		}
+		alerted[search.UserID] = true
+		alerts = append(alerts, domain.SavedSearchAlert{
+			UserID:        search.UserID,
+			SavedSearchID: search.ID,
+			SearchName:    search.Name,
+			Channels:      channels,
+		})
+	}
+	return alerts, nil
+}
+
+// channels returns the channels a user wants saved search alerts on
+func (s *SavedSearchService) channels(ctx context.Context, userID ids.UserID) ([]string, error) {
+	channels, err := s.preferences.NotificationChannels(ctx, userID, savedSearchesCategory)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return channels, nil
+}
+
+// publishMatched publishes a SavedSearchMatched event for a batch of buyers
+func (s *SavedSearchService) publishMatched(ctx context.Context, listing *domain.Listing, alerts []domain.SavedSearchAlert) error {
+	event, err := events.NewEvent(
+		domain.SavedSearchMatchedEvent,
+		listing.ID.String(),
+		domain.SavedSearchMatched{
+			ListingID: listing.ID,
+			SellerID:  listing.SellerID,
+			Title:     listing.Title,
+			Price:     listing.Price,
+			Alerts:    alerts,
+			Timestamp: time.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// ownedSearch finds a saved search, checking it belongs to the user
+func (s *SavedSearchService) ownedSearch(searchID string, userID ids.UserID) (*domain.SavedSearch, error) {
+	search, err := s.savedSearchRepo.FindByID(searchID)
+	if err != nil {
+		return nil, err
+	}
+	if search.UserID != userID {
+		return nil, errors.ForbiddenError("saved search does not belong to the user")
+	}
+	return search, nil
+}
+
+// filters converts the command into saved search filters, searching near a
+// point within the default radius when none is given
+func (cmd SaveSearchCommand) filters() domain.SavedSearchFilters {
+	filters := domain.SavedSearchFilters{
+		Query:      cmd.Query,
+		CategoryID: cmd.CategoryID,
+		Condition:  domain.Condition(cmd.Condition),
+		MinPrice:   cmd.MinPrice,
+		MaxPrice:   cmd.MaxPrice,
+		Region:     cmd.Region,
+		City:       cmd.City,
+		Negotiable: cmd.Negotiable,
+		Latitude:   cmd.Latitude,
+		Longitude:  cmd.Longitude,
+		RadiusKm:   cmd.RadiusKm,
+	}
+	if filters.Latitude != nil && filters.RadiusKm == 0 {
+		filters.RadiusKm = defaultSearchRadiusKm
+	}
+	return filters
+}
+
+// alertsEnabled reports whether the command turns alerts on, as it does by default
+func (cmd SaveSearchCommand) alertsEnabled() bool {
+	return cmd.AlertsEnabled == nil || *cmd.AlertsEnabled
+}
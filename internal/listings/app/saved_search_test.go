@@ -0,0 +1,118 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedSearchService_CRUD(t *testing.T) {
+	searches := newFakeSavedSearchRepository()
+	service := app.NewSavedSearchService(searches, newFakeListingRepository(), &fakeNotificationPreferences{}, &fakeEventBus{})
+	ctx := context.Background()
+	lat, lng := 5.6, -0.19
+
+	search, err := service.CreateSavedSearch(ctx, app.SaveSearchCommand{
+		UserID:    "buyer-a",
+		Name:      "Phones nearby",
+		Query:     "phone",
+		Latitude:  &lat,
+		Longitude: &lng,
+	})
+	require.NoError(t, err)
+	assert.True(t, search.AlertsEnabled)
+	assert.Equal(t, float64(10), search.Filters.RadiusKm, "the default radius applies")
+
+	// Only the owner can see, change or delete it
+	_, err = service.GetSavedSearch(ctx, search.ID, "buyer-b")
+	assert.Error(t, err)
+	alerts := false
+	_, err = service.UpdateSavedSearch(ctx, search.ID, app.SaveSearchCommand{UserID: "buyer-b", Name: "Mine now"})
+	assert.Error(t, err)
+
+	updated, err := service.UpdateSavedSearch(ctx, search.ID, app.SaveSearchCommand{UserID: "buyer-a", Name: "Laptops", Query: "laptop", AlertsEnabled: &alerts})
+	require.NoError(t, err)
+	assert.Equal(t, "laptop", updated.Filters.Query)
+	assert.Nil(t, updated.Filters.Near())
+	assert.False(t, updated.AlertsEnabled)
+
+	listed, err := service.ListSavedSearches(ctx, "buyer-a")
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+
+	assert.Error(t, service.DeleteSavedSearch(ctx, search.ID, "buyer-b"))
+	require.NoError(t, service.DeleteSavedSearch(ctx, search.ID, "buyer-a"))
+	_, err = service.GetSavedSearch(ctx, search.ID, "buyer-a")
+	assert.Error(t, err)
+}
+
+func TestSavedSearchService_LimitsSearchesPerUser(t *testing.T) {
+	service := app.NewSavedSearchService(newFakeSavedSearchRepository(), newFakeListingRepository(), &fakeNotificationPreferences{}, &fakeEventBus{})
+	ctx := context.Background()
+
+	for i := 0; i < domain.MaxSavedSearchesPerUser; i++ {
+		_, err := service.CreateSavedSearch(ctx, app.SaveSearchCommand{UserID: "buyer-a", Name: "Search"})
+		require.NoError(t, err)
+	}
+	_, err := service.CreateSavedSearch(ctx, app.SaveSearchCommand{UserID: "buyer-a", Name: "One too many"})
+	assert.Error(t, err)
+}
+
+func TestSavedSearchService_NotifyMatches(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	now := time.Now()
+	newSearch := func(userID ids.UserID, query string) *domain.SavedSearch {
+		search, err := domain.NewSavedSearch(userID, "Search "+query, domain.SavedSearchFilters{Query: query}, true, now)
+		require.NoError(t, err)
+		return search
+	}
+	phones := newSearch("buyer-a", "phone")
+	alsoPhones := newSearch("buyer-a", "used")
+	laptops := newSearch("buyer-b", "laptop")
+	own := newSearch("seller-a", "phone")
+	muted := newSearch("buyer-c", "phone")
+	silent := newSearch("buyer-d", "phone")
+	silent.AlertsEnabled = false
+
+	searches := newFakeSavedSearchRepository(phones, alsoPhones, laptops, own, muted, silent)
+	bus := &fakeEventBus{}
+	preferences := &fakeNotificationPreferences{channels: map[ids.UserID][]string{"buyer-c": {}}}
+	service := app.NewSavedSearchService(searches, newFakeListingRepository(listing), preferences, bus)
+
+	alerted, err := service.NotifyMatches(context.Background(), listing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, alerted)
+
+	matched := bus.eventsOfType(domain.SavedSearchMatchedEvent)
+	require.Len(t, matched, 1)
+	var data domain.SavedSearchMatched
+	require.NoError(t, events.ParseEventData(matched[0], &data))
+	assert.Equal(t, listing.ID, data.ListingID)
+	require.Len(t, data.Alerts, 1)
+	assert.Equal(t, ids.UserID("buyer-a"), data.Alerts[0].UserID)
+	assert.Equal(t, []string{"email", "push"}, data.Alerts[0].Channels)
+
+	stored, err := searches.FindByID(data.Alerts[0].SavedSearchID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.LastAlertedAt)
+}
+
+func TestSavedSearchService_NotifyMatchesSkipsInactiveListings(t *testing.T) {
+	draft := newDraftListing(t, "seller-a", "Used phone")
+	search, err := domain.NewSavedSearch("buyer-a", "Phones", domain.SavedSearchFilters{Query: "phone"}, true, time.Now())
+	require.NoError(t, err)
+	bus := &fakeEventBus{}
+	service := app.NewSavedSearchService(newFakeSavedSearchRepository(search), newFakeListingRepository(draft), &fakeNotificationPreferences{}, bus)
+
+	alerted, err := service.NotifyMatches(context.Background(), draft.ID)
+	require.NoError(t, err)
+	assert.Zero(t, alerted)
+	assert.Empty(t, bus.eventsOfType(domain.SavedSearchMatchedEvent))
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// ScheduleListingCommand represents the command to schedule a draft to go live.
+// Scheduling an already scheduled listing moves its publish time.
+type ScheduleListingCommand struct {
+	ListingID ids.ListingID `json:"-"`
+	SellerID  ids.UserID    `json:"-"`
+	PublishAt time.Time     `json:"publish_at" binding:"required"`
+}
+
+// ListingScheduleService handles publishing listings at a future time
+type ListingScheduleService struct {
+	listingRepo domain.ListingRepository
+	eventBus    events.EventBus
+	limits      domain.SchedulingLimits
+	clock       clock.Clock
+}
+
+// NewListingScheduleService creates a new listing schedule service. clk may
+// be nil to use the system clock.
+func NewListingScheduleService(listingRepo domain.ListingRepository, eventBus events.EventBus, limits domain.SchedulingLimits, clk clock.Clock) *ListingScheduleService {
+	return &ListingScheduleService{
+		listingRepo: listingRepo,
+		eventBus:    eventBus,
+		limits:      limits,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// ScheduleListing sets one of the seller's drafts to go live at the requested time
+func (s *ListingScheduleService) ScheduleListing(ctx context.Context, cmd ScheduleListingCommand) (*domain.Listing, error) {
+	listing, err := s.sellerListing(cmd.ListingID, cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled, err := s.listingRepo.CountScheduledBySeller(cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.IsScheduled() {
+		// Rescheduling does not take up another slot
+		scheduled--
+	}
+	now := s.clock.Now()
+	if err := s.limits.Check(cmd.PublishAt, now, scheduled); err != nil {
+		return nil, err
+	}
+
+	if err := listing.Schedule(cmd.PublishAt, now); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// CancelSchedule stops one of the seller's scheduled listings from going live,
+// leaving it as a draft
+func (s *ListingScheduleService) CancelSchedule(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.sellerListing(listingID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := listing.CancelSchedule(s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// PublishDueListings activates up to limit scheduled listings whose publish
+// time has come and returns how many went live
+func (s *ListingScheduleService) PublishDueListings(ctx context.Context, limit int) (int, error) {
+	now := s.clock.Now()
+	listings, err := s.listingRepo.FindDueScheduled(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, listing := range listings {
+		if err := listing.PublishScheduled(now); err != nil {
+			return published, err
+		}
+		if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+			return published, err
+		}
+		if err := publishStatusChanges(ctx, s.eventBus, listing); err != nil {
+			return published, err
+		}
+
+		// Publish ListingActivated event so the seller's followers hear of it
+		event, err := events.NewEvent(
+			domain.ListingActivatedEvent,
+			listing.ID.String(),
+			domain.ListingActivated{
+				ListingID: listing.ID,
+				SellerID:  listing.SellerID,
+				Title:     listing.Title,
+				Price:     listing.Price,
+				Timestamp: now,
+			},
+		)
+		if err != nil {
+			return published, err
+		}
+
+		if err := s.eventBus.Publish(ctx, event); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// sellerListing loads a listing and checks it belongs to the seller
+func (s *ListingScheduleService) sellerListing(listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID != sellerID {
+		return nil, errors.ForbiddenError("listing does not belong to the seller")
+	}
+	return listing, nil
+}
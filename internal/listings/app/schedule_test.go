@@ -0,0 +1,84 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDraftListing(t *testing.T, sellerID ids.UserID, title string) *domain.Listing {
+	t.Helper()
+	listing, err := domain.NewListing(sellerID, "category-1", title, "", money.Cedis(100), domain.ConditionGood,
+		domain.Location{Region: "Greater Accra", City: "Accra"})
+	require.NoError(t, err)
+	return listing
+}
+
+func TestListingScheduleService_ScheduleListing(t *testing.T) {
+	first := newDraftListing(t, "seller-a", "Phone")
+	second := newDraftListing(t, "seller-a", "Laptop")
+	repo := newFakeListingRepository(first, second)
+	clk := clock.NewFrozen(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	service := app.NewListingScheduleService(repo, &fakeEventBus{}, domain.SchedulingLimits{MaxScheduled: 1, MaxLeadTime: 7 * 24 * time.Hour}, clk)
+	ctx := context.Background()
+
+	// Only the seller can schedule their listing
+	_, err := service.ScheduleListing(ctx, app.ScheduleListingCommand{ListingID: first.ID, SellerID: "seller-b", PublishAt: clk.Now().Add(time.Hour)})
+	assert.Error(t, err)
+
+	listing, err := service.ScheduleListing(ctx, app.ScheduleListingCommand{ListingID: first.ID, SellerID: "seller-a", PublishAt: clk.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.True(t, listing.IsScheduled())
+
+	// The seller's only slot is taken...
+	_, err = service.ScheduleListing(ctx, app.ScheduleListingCommand{ListingID: second.ID, SellerID: "seller-a", PublishAt: clk.Now().Add(time.Hour)})
+	assert.Error(t, err)
+
+	// ...but the scheduled listing can still be moved
+	publishAt := clk.Now().Add(48 * time.Hour)
+	listing, err = service.ScheduleListing(ctx, app.ScheduleListingCommand{ListingID: first.ID, SellerID: "seller-a", PublishAt: publishAt})
+	require.NoError(t, err)
+	assert.True(t, publishAt.Equal(*listing.PublishAt))
+
+	// Cancelling frees the slot
+	_, err = service.CancelSchedule(ctx, first.ID, "seller-a")
+	require.NoError(t, err)
+	_, err = service.ScheduleListing(ctx, app.ScheduleListingCommand{ListingID: second.ID, SellerID: "seller-a", PublishAt: clk.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+}
+
+func TestListingScheduleService_PublishDueListings(t *testing.T) {
+	clk := clock.NewFrozen(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	due := newDraftListing(t, "seller-a", "Phone")
+	due.PublishAt = timePtr(clk.Now().Add(-time.Minute))
+	later := newDraftListing(t, "seller-a", "Laptop")
+	later.PublishAt = timePtr(clk.Now().Add(time.Hour))
+	unscheduled := newDraftListing(t, "seller-a", "Tablet")
+
+	bus := &fakeEventBus{}
+	service := app.NewListingScheduleService(newFakeListingRepository(due, later, unscheduled), bus, domain.SchedulingLimits{}, clk)
+
+	published, err := service.PublishDueListings(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.True(t, due.IsActive())
+	assert.True(t, later.IsScheduled())
+	assert.Equal(t, domain.ListingStatusDraft, unscheduled.Status)
+
+	activated := bus.eventsOfType(domain.ListingActivatedEvent)
+	require.Len(t, activated, 1)
+	assert.Equal(t, due.ID.String(), activated[0].AggregateID)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,243 @@
+package app
+
+import (
+	"context"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// SearchService serves listing search from a search index such as
+// Elasticsearch or OpenSearch, and keeps the index in sync with listings.
+// The index only finds listings; they are loaded from the database, so
+// listings that stopped being active before the index caught up are left out.
+type SearchService struct {
+	index       domain.ListingIndex
+	listingRepo domain.ListingRepository
+	sellerCards domain.SellerCardRepository
+	counters    domain.ListingCounterRepository
+	promotions  *PromotionRanker
+}
+
+// NewSearchService creates a new search service. sellerCards may be nil, in
+// which case listings are indexed without seller ranking penalties and shown
+// without seller cards, and so may counters, in which case listings are
+// indexed and shown without views, and promotions, in which case searches
+// are shown without sponsored listings.
+func NewSearchService(index domain.ListingIndex, listingRepo domain.ListingRepository, sellerCards domain.SellerCardRepository, counters domain.ListingCounterRepository, promotions *PromotionRanker) *SearchService {
+	return &SearchService{
+		index:       index,
+		listingRepo: listingRepo,
+		sellerCards: sellerCards,
+		counters:    counters,
+		promotions:  promotions,
+	}
+}
+
+// SearchListings searches the active listings of the whole marketplace, or of
+// one seller when the query names them
+func (s *SearchService) SearchListings(ctx context.Context, query SearchListingsQuery) (*ListingSearchResults, error) {
+	criteria, err := query.criteria()
+	if err != nil {
+		return nil, err
+	}
+	criteria.SellerID = query.SellerID
+
+	return s.search(ctx, criteria, query.Limit, query.Offset)
+}
+
+// SearchSellerListings searches the active listings of a single seller's storefront
+func (s *SearchService) SearchSellerListings(ctx context.Context, sellerID ids.UserID, query SearchListingsQuery) (*ListingSearchResults, error) {
+	if sellerID == "" {
+		return nil, errors.ValidationError("seller ID is required")
+	}
+
+	criteria, err := query.criteria()
+	if err != nil {
+		return nil, err
+	}
+	criteria.SellerID = sellerID
+
+	return s.search(ctx, criteria, query.Limit, query.Offset)
+}
+
+// search finds a page of listings in the index and loads them in the index's order
+func (s *SearchService) search(ctx context.Context, criteria domain.ListingSearchCriteria, limit, offset int) (*ListingSearchResults, error) {
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	// One listing more than the page tells whether there is a next page
+	hits, err := s.index.Search(ctx, criteria, limit+1, offset)
+	if err != nil {
+		return nil, err
+	}
+	more := len(hits.IDs) > limit
+	if more {
+		hits.IDs = hits.IDs[:limit]
+	}
+
+	listings, err := s.loadHits(hits.IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := attachCounters(s.counters, listings); err != nil {
+		return nil, err
+	}
+	var nextCursor string
+	if more && len(listings) > 0 {
+		if sort := criteria.SortOrder(); sort.SupportsCursor() {
+			nextCursor = domain.NewListingCursor(listings[len(listings)-1], sort).Encode()
+		}
+	}
+
+	if promotedCriteria, ok := s.promotions.promotedCriteria(criteria); ok {
+		promotedHits, err := s.index.Search(ctx, promotedCriteria, promotedCandidateLimit, 0)
+		if err != nil {
+			return nil, err
+		}
+		promoted, err := s.loadHits(promotedHits.IDs)
+		if err != nil {
+			return nil, err
+		}
+		if err := attachCounters(s.counters, promoted); err != nil {
+			return nil, err
+		}
+		listings = s.promotions.mix(listings, promoted, limit, offset)
+	}
+
+	if err := attachSellerCards(s.sellerCards, listings); err != nil {
+		return nil, err
+	}
+	attachDistances(criteria, listings)
+
+	facets := hits.Facets
+	if facets == nil {
+		facets = &domain.SearchFacets{}
+	}
+	return &ListingSearchResults{
+		Listings:   listings,
+		Total:      hits.Total,
+		Facets:     facets,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// loadHits loads the listings found in the index, in the index's order.
+// Listings that stopped being active before the index caught up are left out.
+func (s *SearchService) loadHits(listingIDs []ids.ListingID) ([]*domain.Listing, error) {
+	found, err := s.listingRepo.FindByIDs(listingIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[ids.ListingID]*domain.Listing, len(found))
+	for _, listing := range found {
+		byID[listing.ID] = listing
+	}
+	listings := make([]*domain.Listing, 0, len(listingIDs))
+	for _, id := range listingIDs {
+		if listing, ok := byID[id]; ok && listing.IsActive() {
+			listings = append(listings, listing)
+		}
+	}
+	return listings, nil
+}
+
+// IndexListing brings the index's copy of a listing up to date. Listings that
+// are gone or no longer active are removed from the index.
+func (s *SearchService) IndexListing(ctx context.Context, listingID ids.ListingID) error {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return s.index.Remove(ctx, listingID)
+		}
+		return err
+	}
+	if !listing.IsActive() {
+		return s.index.Remove(ctx, listingID)
+	}
+	if err := attachCounters(s.counters, []*domain.Listing{listing}); err != nil {
+		return err
+	}
+
+	card, err := s.sellerCard(listing.SellerID)
+	if err != nil {
+		return err
+	}
+	return s.index.Index(ctx, domain.NewListingDocument(listing, card))
+}
+
+// IndexListings brings the index's copies of several listings up to date
+func (s *SearchService) IndexListings(ctx context.Context, listingIDs []ids.ListingID) error {
+	for _, listingID := range listingIDs {
+		if err := s.IndexListing(ctx, listingID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reindex copies every active listing into the index, in batches, picking up
+// changes no event was published for, such as view counts and seller ranking
+// penalties. It returns the number of listings indexed.
+func (s *SearchService) Reindex(ctx context.Context, batchSize int) (int, error) {
+	criteria := domain.ListingSearchCriteria{Sort: domain.ListingSortNewest}
+	indexed := 0
+	for {
+		listings, err := s.listingRepo.Search(criteria, batchSize, 0)
+		if err != nil {
+			return indexed, err
+		}
+
+		cards, err := s.sellerCardsOf(listings)
+		if err != nil {
+			return indexed, err
+		}
+		if err := attachCounters(s.counters, listings); err != nil {
+			return indexed, err
+		}
+		for _, listing := range listings {
+			if err := s.index.Index(ctx, domain.NewListingDocument(listing, cards[listing.SellerID])); err != nil {
+				return indexed, err
+			}
+			indexed++
+		}
+
+		if len(listings) < batchSize {
+			return indexed, nil
+		}
+		criteria.After = domain.NewListingCursor(listings[len(listings)-1], domain.ListingSortNewest)
+	}
+}
+
+// sellerCard looks up a seller's card, or nil if they have none
+func (s *SearchService) sellerCard(sellerID ids.UserID) (*domain.SellerCard, error) {
+	if s.sellerCards == nil {
+		return nil, nil
+	}
+	cards, err := s.sellerCards.FindBySellerIDs([]ids.UserID{sellerID})
+	if err != nil || len(cards) == 0 {
+		return nil, err
+	}
+	return cards[0], nil
+}
+
+// sellerCardsOf looks up the cards of the listings' sellers by seller
+func (s *SearchService) sellerCardsOf(listings []*domain.Listing) (map[ids.UserID]*domain.SellerCard, error) {
+	bySeller := make(map[ids.UserID]*domain.SellerCard)
+	if s.sellerCards == nil || len(listings) == 0 {
+		return bySeller, nil
+	}
+	cards, err := s.sellerCards.FindBySellerIDs(sellerIDsOf(listings))
+	if err != nil {
+		return nil, err
+	}
+	for _, card := range cards {
+		bySeller[card.SellerID] = card
+	}
+	return bySeller, nil
+}
@@ -0,0 +1,389 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newActiveListing(t *testing.T, sellerID ids.UserID, title string, condition domain.Condition) *domain.Listing {
+	t.Helper()
+	listing, err := domain.NewListing(sellerID, "category-1", title, "", money.Cedis(100), condition,
+		domain.Location{Region: "Greater Accra", City: "Accra"})
+	require.NoError(t, err)
+	require.NoError(t, listing.Activate())
+	return listing
+}
+
+func TestListingService_SearchSellerListings(t *testing.T) {
+	phone := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	laptop := newActiveListing(t, "seller-a", "New laptop", domain.ConditionNew)
+	draft, err := domain.NewListing("seller-a", "category-1", "Draft", "", money.Cedis(50), domain.ConditionNew, domain.Location{})
+	require.NoError(t, err)
+	other := newActiveListing(t, "seller-b", "Other phone", domain.ConditionGood)
+
+	repo := newFakeListingRepository(phone, laptop, draft, other)
+	service := app.NewListingService(repo, nil, &fakeEventBus{}, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	results, err := service.SearchSellerListings(context.Background(), "seller-a", app.SearchListingsQuery{
+		Query: "  phone ",
+		Sort:  "price_asc",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), results.Total)
+	assert.Len(t, results.Listings, 2)
+	assert.NotNil(t, results.Facets)
+	assert.Equal(t, 20, results.Limit)
+
+	// The search is scoped to the seller and carries the filters through
+	assert.Equal(t, ids.UserID("seller-a"), repo.lastCriteria.SellerID)
+	assert.Equal(t, "phone", repo.lastCriteria.Query)
+	assert.Equal(t, domain.ListingSortPriceAsc, repo.lastCriteria.Sort)
+
+	results, err = service.SearchSellerListings(context.Background(), "seller-a", app.SearchListingsQuery{
+		Condition: "new",
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, results.Listings, 1)
+	assert.Equal(t, laptop.ID, results.Listings[0].ID)
+}
+
+func TestListingService_SearchSellerListings_InvalidPriceRange(t *testing.T) {
+	service := app.NewListingService(newFakeListingRepository(), nil, &fakeEventBus{}, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	minPrice, maxPrice := 500.0, 100.0
+	_, err := service.SearchSellerListings(context.Background(), "seller-a", app.SearchListingsQuery{
+		MinPrice: &minPrice,
+		MaxPrice: &maxPrice,
+	})
+	assert.Error(t, err)
+
+	_, err = service.SearchSellerListings(context.Background(), "", app.SearchListingsQuery{})
+	assert.Error(t, err)
+}
+
+func TestListingService_SearchListings_CursorPagination(t *testing.T) {
+	var listings []*domain.Listing
+	for i, price := range []float64{300, 100, 500, 100, 200} {
+		listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+		listing.Price = money.Cedis(price)
+		listing.ViewsCount = int64(i)
+		listings = append(listings, listing)
+	}
+	repo := newFakeListingRepository(listings...)
+	service := app.NewListingService(repo, nil, &fakeEventBus{}, nil, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	var prices []float64
+	seen := make(map[ids.ListingID]bool)
+	query := app.SearchListingsQuery{Sort: "price_asc", Limit: 2}
+	for page := 0; page < 5; page++ {
+		results, err := service.SearchListings(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), results.Total, "the total covers every page")
+		for _, listing := range results.Listings {
+			assert.False(t, seen[listing.ID], "pages never overlap")
+			seen[listing.ID] = true
+			prices = append(prices, listing.Price.Major())
+		}
+		if results.NextCursor == "" {
+			break
+		}
+		query.Cursor = results.NextCursor
+	}
+	assert.Equal(t, []float64{100, 100, 200, 300, 500}, prices)
+
+	// A cursor only resumes the sort it was issued for
+	_, err := service.SearchListings(ctx, app.SearchListingsQuery{Sort: "most_viewed", Cursor: query.Cursor})
+	assert.Error(t, err)
+	_, err = service.SearchListings(ctx, app.SearchListingsQuery{Sort: "price_asc", Cursor: query.Cursor, Offset: 2})
+	assert.Error(t, err)
+	_, err = service.SearchListings(ctx, app.SearchListingsQuery{Cursor: "not-a-cursor"})
+	assert.Error(t, err)
+
+	// Relevance is paged with offsets only
+	results, err := service.SearchListings(ctx, app.SearchListingsQuery{Query: "phone", Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, results.Listings, 2)
+	assert.Empty(t, results.NextCursor)
+}
+
+func TestListingService_SearchListings_Filters(t *testing.T) {
+	phone := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	laptop := newActiveListing(t, "seller-b", "New laptop", domain.ConditionNew)
+	laptop.Price = money.Cedis(900)
+	repo := newFakeListingRepository(phone, laptop)
+	service := app.NewListingService(repo, nil, &fakeEventBus{}, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	minPrice := 500.0
+	negotiable := true
+	results, err := service.SearchListings(context.Background(), app.SearchListingsQuery{
+		SellerID:   "seller-b",
+		MinPrice:   &minPrice,
+		Negotiable: &negotiable,
+		Sort:       "most_viewed",
+	})
+	require.NoError(t, err)
+	require.Len(t, results.Listings, 1)
+	assert.Equal(t, laptop.ID, results.Listings[0].ID)
+	assert.Equal(t, ids.UserID("seller-b"), repo.lastCriteria.SellerID)
+	assert.Equal(t, domain.ListingSortMostViewed, repo.lastCriteria.Sort)
+	assert.Equal(t, &negotiable, repo.lastCriteria.Negotiable)
+}
+
+// fakeSellerTrustSource returns fixed seller trust
+type fakeSellerTrustSource struct {
+	trust map[ids.UserID]*domain.SellerTrust
+}
+
+func (s *fakeSellerTrustSource) SellerTrust(ctx context.Context, sellerIDs []ids.UserID) (map[ids.UserID]*domain.SellerTrust, error) {
+	return s.trust, nil
+}
+
+func TestListingService_SearchSellerListings_SellerTrust(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	card := domain.NewSellerCard("seller-a", time.Now())
+	card.TrustLevel = "trusted"
+	card.Badges = []string{"verified", "top_rated"}
+	card.Rating = 4.8
+	cards := newFakeSellerCardRepository(card)
+	trust := &fakeSellerTrustSource{trust: map[ids.UserID]*domain.SellerTrust{"seller-a": {TrustLevel: "new"}}}
+	service := app.NewListingService(newFakeListingRepository(listing), nil, &fakeEventBus{}, trust, cards, nil, nil, nil, nil, nil, nil)
+
+	// Search results read the seller card read model, not the users context
+	results, err := service.SearchSellerListings(context.Background(), "seller-a", app.SearchListingsQuery{})
+	require.NoError(t, err)
+	require.Len(t, results.Listings, 1)
+	assert.Equal(t, &domain.SellerTrust{TrustLevel: "trusted", Badges: []string{"verified", "top_rated"}}, results.Listings[0].SellerTrust)
+	assert.Equal(t, 4.8, results.Listings[0].Seller.Rating)
+	assert.Equal(t, 1, cards.lookups, "one lookup per page")
+}
+
+// fakeFollowedSellers returns fixed followed sellers per follower
+type fakeFollowedSellers struct {
+	follows map[ids.UserID][]ids.UserID
+}
+
+func (f *fakeFollowedSellers) FollowedSellerIDs(ctx context.Context, followerID ids.UserID) ([]ids.UserID, error) {
+	return f.follows[followerID], nil
+}
+
+func TestListingService_FollowedSellerFeed(t *testing.T) {
+	phone := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	laptop := newActiveListing(t, "seller-b", "New laptop", domain.ConditionNew)
+	other := newActiveListing(t, "seller-c", "Other phone", domain.ConditionGood)
+
+	repo := newFakeListingRepository(phone, laptop, other)
+	followed := &fakeFollowedSellers{follows: map[ids.UserID][]ids.UserID{"buyer-1": {"seller-a", "seller-b"}}}
+	service := app.NewListingService(repo, nil, &fakeEventBus{}, nil, nil, nil, followed, nil, nil, nil, nil)
+
+	results, err := service.FollowedSellerFeed(context.Background(), "buyer-1", app.SearchListingsQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), results.Total)
+	assert.ElementsMatch(t, []ids.UserID{"seller-a", "seller-b"}, repo.lastCriteria.SellerIDs)
+
+	// Users who follow nobody get an empty feed rather than the whole marketplace
+	results, err = service.FollowedSellerFeed(context.Background(), "buyer-2", app.SearchListingsQuery{})
+	require.NoError(t, err)
+	assert.Zero(t, results.Total)
+	assert.Empty(t, results.Listings)
+}
+
+func TestSearchService_SearchListings_KeepsIndexOrder(t *testing.T) {
+	first := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	second := newActiveListing(t, "seller-b", "Phone case", domain.ConditionNew)
+	sold := newActiveListing(t, "seller-a", "Old phone", domain.ConditionFair)
+	require.NoError(t, sold.MarkAsSold())
+
+	// The index lags behind: it still holds the sold listing and one that was deleted
+	index := newFakeListingIndex(second.ID, sold.ID, "deleted-listing", first.ID)
+	cards := newFakeSellerCardRepository(&domain.SellerCard{SellerID: "seller-a", RankingFactor: 1})
+	service := app.NewSearchService(index, newFakeListingRepository(first, second, sold), cards, nil, nil)
+
+	results, err := service.SearchListings(context.Background(), app.SearchListingsQuery{Query: "phone", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results.Listings, 2)
+	assert.Equal(t, second.ID, results.Listings[0].ID)
+	assert.Equal(t, first.ID, results.Listings[1].ID)
+	assert.NotNil(t, results.Listings[1].Seller)
+	assert.NotNil(t, results.Facets)
+	assert.Empty(t, results.NextCursor)
+
+	results, err = service.SearchListings(context.Background(), app.SearchListingsQuery{Sort: "newest", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, results.Listings, 1)
+	assert.NotEmpty(t, results.NextCursor)
+}
+
+func TestSearchService_IndexListing(t *testing.T) {
+	listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	repo := newFakeListingRepository(listing)
+	index := newFakeListingIndex()
+	cards := newFakeSellerCardRepository(&domain.SellerCard{SellerID: "seller-a", RankingFactor: 0.5})
+	service := app.NewSearchService(index, repo, cards, nil, nil)
+
+	require.NoError(t, service.IndexListing(context.Background(), listing.ID))
+	require.Contains(t, index.docs, listing.ID)
+	assert.Equal(t, 0.5, index.docs[listing.ID].RankingFactor)
+	assert.Equal(t, "Greater Accra", index.docs[listing.ID].Region)
+
+	// Listings that are no longer active, or gone, leave the index
+	require.NoError(t, listing.MarkAsSold())
+	require.NoError(t, service.IndexListing(context.Background(), listing.ID))
+	assert.NotContains(t, index.docs, listing.ID)
+
+	require.NoError(t, service.IndexListing(context.Background(), "missing-listing"))
+}
+
+func TestSearchService_Reindex(t *testing.T) {
+	var listings []*domain.Listing
+	for i := 0; i < 5; i++ {
+		listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+		listing.CreatedAt = time.Now().Add(-time.Duration(i) * time.Hour)
+		listings = append(listings, listing)
+	}
+	index := newFakeListingIndex()
+	service := app.NewSearchService(index, newFakeListingRepository(listings...), nil, nil, nil)
+
+	count, err := service.Reindex(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+	assert.Len(t, index.docs, 5)
+	for _, doc := range index.docs {
+		assert.Equal(t, 1.0, doc.RankingFactor)
+	}
+}
+
+func TestListingService_SearchListings_Near(t *testing.T) {
+	far := newActiveListing(t, "seller-a", "Phone in Tema", domain.ConditionGood)
+	far.Location = domain.Location{Region: "Greater Accra", City: "Tema", Latitude: 5.6698, Longitude: -0.0166}
+	close := newActiveListing(t, "seller-a", "Phone in Osu", domain.ConditionGood)
+	close.Location.Latitude, close.Location.Longitude = 5.5560, -0.1820
+	closer := newActiveListing(t, "seller-b", "Phone in Adabraka", domain.ConditionGood)
+	closer.Location.Latitude, closer.Location.Longitude = 5.5600, -0.2100
+	unpinned := newActiveListing(t, "seller-b", "Phone", domain.ConditionGood)
+
+	service := app.NewListingService(newFakeListingRepository(far, close, closer, unpinned), nil, &fakeEventBus{}, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	lat, lng := 5.5610, -0.2050
+	results, err := service.SearchListings(context.Background(), app.SearchListingsQuery{Latitude: &lat, Longitude: &lng})
+	require.NoError(t, err)
+	require.Len(t, results.Listings, 2)
+	assert.Equal(t, closer.ID, results.Listings[0].ID)
+	assert.Equal(t, close.ID, results.Listings[1].ID)
+	require.NotNil(t, results.Listings[0].DistanceKm)
+	assert.Less(t, *results.Listings[0].DistanceKm, *results.Listings[1].DistanceKm)
+	assert.Empty(t, results.NextCursor)
+
+	results, err = service.SearchListings(context.Background(), app.SearchListingsQuery{Latitude: &lat, Longitude: &lng, RadiusKm: 50})
+	require.NoError(t, err)
+	require.Len(t, results.Listings, 3)
+	assert.Equal(t, far.ID, results.Listings[2].ID)
+}
+
+func TestListingService_SearchListings_InvalidNear(t *testing.T) {
+	service := app.NewListingService(newFakeListingRepository(), nil, &fakeEventBus{}, nil, nil, nil, nil, nil, nil, nil, nil)
+	lat := 5.56
+
+	for _, query := range []app.SearchListingsQuery{
+		{Latitude: &lat},
+		{RadiusKm: 5},
+		{Sort: "distance"},
+	} {
+		_, err := service.SearchListings(context.Background(), query)
+		assert.Error(t, err)
+	}
+}
+
+func TestListingService_SearchListings_ShowsSponsoredListings(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFrozen(time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC))
+	var listings []*domain.Listing
+	for i := 0; i < 12; i++ {
+		listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+		listing.CreatedAt = clk.Now().Add(-time.Duration(i+1) * time.Hour)
+		listings = append(listings, listing)
+	}
+	older := newActiveListing(t, "seller-b", "Laptop", domain.ConditionGood)
+	older.CreatedAt = clk.Now().AddDate(0, 0, -2)
+	older.Promote(24*time.Hour, clk.Now())
+	newer := newActiveListing(t, "seller-c", "Bag", domain.ConditionGood)
+	newer.CreatedAt = clk.Now().AddDate(0, 0, -1)
+	newer.Promote(24*time.Hour, clk.Now())
+	lapsed := newActiveListing(t, "seller-d", "Shoes", domain.ConditionGood)
+	lapsed.Promote(time.Hour, clk.Now().Add(-2*time.Hour))
+
+	repo := newFakeListingRepository(append(listings, older, newer, lapsed)...)
+	service := app.NewListingService(repo, nil, &fakeEventBus{}, nil, nil, nil, nil, nil, nil, app.NewPromotionRanker(2, clk), clk)
+	sponsoredOn := func(results *app.ListingSearchResults) map[int]ids.ListingID {
+		sponsored := make(map[int]ids.ListingID)
+		for i, listing := range results.Listings {
+			if listing.Sponsored {
+				sponsored[i] = listing.ID
+			}
+		}
+		return sponsored
+	}
+
+	// Sponsored slots are spread over the page; lapsed promotions get none
+	results, err := service.SearchListings(ctx, app.SearchListingsQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, results.Listings, 12)
+	assert.Equal(t, map[int]ids.ListingID{0: newer.ID, 6: older.ID}, sponsoredOn(results))
+	assert.Equal(t, int64(15), results.Total, "the total counts organic results")
+
+	// Promoted listings take turns at the top
+	clk.Advance(time.Minute)
+	results, err = service.SearchListings(ctx, app.SearchListingsQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]ids.ListingID{0: older.ID, 6: newer.ID}, sponsoredOn(results))
+
+	// Each promoted listing is shown once while paging
+	results, err = service.SearchListings(ctx, app.SearchListingsQuery{Limit: 10, Offset: 10})
+	require.NoError(t, err)
+	assert.Empty(t, sponsoredOn(results))
+
+	// Small pages show fewer sponsored listings
+	results, err = service.SearchListings(ctx, app.SearchListingsQuery{Limit: 5})
+	require.NoError(t, err)
+	assert.Len(t, sponsoredOn(results), 1)
+
+	// Explicit sorts and storefronts are left as they are
+	results, err = service.SearchListings(ctx, app.SearchListingsQuery{Limit: 10, Sort: "price_asc"})
+	require.NoError(t, err)
+	assert.Empty(t, sponsoredOn(results))
+	results, err = service.SearchSellerListings(ctx, "seller-b", app.SearchListingsQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, sponsoredOn(results))
+}
+
+func TestSearchService_SearchListings_ShowsSponsoredListings(t *testing.T) {
+	clk := clock.NewFrozen(time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC))
+	first := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	second := newActiveListing(t, "seller-b", "Phone case", domain.ConditionNew)
+	promoted := newActiveListing(t, "seller-c", "Phone charger", domain.ConditionNew)
+	promoted.Promote(24*time.Hour, clk.Now())
+
+	// The fake index finds every listing for the promoted search too; only
+	// the listing promoted right now is sponsored
+	index := newFakeListingIndex(first.ID, second.ID, promoted.ID)
+	service := app.NewSearchService(index, newFakeListingRepository(first, second, promoted), nil, nil, app.NewPromotionRanker(2, clk))
+
+	results, err := service.SearchListings(context.Background(), app.SearchListingsQuery{Query: "phone", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results.Listings, 3)
+	assert.Equal(t, promoted.ID, results.Listings[0].ID)
+	assert.True(t, results.Listings[0].Sponsored)
+	assert.False(t, promoted.Sponsored)
+	assert.Equal(t, first.ID, results.Listings[1].ID)
+}
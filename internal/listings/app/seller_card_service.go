@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// UpdateSellerCardCommand carries a seller's latest stats, as computed by the
+// users context at AsOf
+type UpdateSellerCardCommand struct {
+	SellerID            ids.UserID
+	Verified            bool
+	Rating              float64
+	TotalReviews        int
+	ResponseTimeMinutes float64
+	TrustLevel          string
+	Badges              []string
+	AsOf                time.Time
+}
+
+// SellerCardService maintains the seller cards shown with listings from
+// events published by the users context
+type SellerCardService struct {
+	cardRepo domain.SellerCardRepository
+}
+
+// NewSellerCardService creates a new seller card service
+func NewSellerCardService(cardRepo domain.SellerCardRepository) *SellerCardService {
+	return &SellerCardService{
+		cardRepo: cardRepo,
+	}
+}
+
+// UpdateStats stores a seller's latest stats on their card. Stats older than
+// the ones already stored are ignored, so redelivered or reordered events
+// are safe to apply.
+func (s *SellerCardService) UpdateStats(ctx context.Context, cmd UpdateSellerCardCommand) error {
+	if cmd.SellerID == "" {
+		return errors.ValidationError("seller id is required")
+	}
+
+	now := time.Now()
+	card := domain.NewSellerCard(cmd.SellerID, now)
+	card.Verified = cmd.Verified
+	card.Rating = cmd.Rating
+	card.TotalReviews = cmd.TotalReviews
+	card.ResponseTimeMinutes = cmd.ResponseTimeMinutes
+	if cmd.TrustLevel != "" {
+		card.TrustLevel = cmd.TrustLevel
+	}
+	if cmd.Badges != nil {
+		card.Badges = cmd.Badges
+	}
+	card.StatsAsOf = cmd.AsOf
+	if card.StatsAsOf.IsZero() {
+		card.StatsAsOf = now
+	}
+
+	return db.WithRetry(ctx, func() error { return s.cardRepo.SaveStats(card) })
+}
+
+// MarkVerified shows a seller as verified on their card
+func (s *SellerCardService) MarkVerified(ctx context.Context, sellerID ids.UserID, at time.Time) error {
+	if sellerID == "" {
+		return errors.ValidationError("seller id is required")
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return db.WithRetry(ctx, func() error { return s.cardRepo.MarkVerified(sellerID, at) })
+}
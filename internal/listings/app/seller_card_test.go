@@ -0,0 +1,69 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSellerCardService_UpdateStatsIgnoresOlderStats(t *testing.T) {
+	repo := newFakeSellerCardRepository()
+	service := app.NewSellerCardService(repo)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, service.UpdateStats(ctx, app.UpdateSellerCardCommand{
+		SellerID:     "seller-a",
+		Rating:       4.5,
+		TotalReviews: 12,
+		TrustLevel:   "trusted",
+		Badges:       []string{"top_rated"},
+		AsOf:         now,
+	}))
+
+	// A redelivered event computed earlier must not roll the card back
+	require.NoError(t, service.UpdateStats(ctx, app.UpdateSellerCardCommand{
+		SellerID:     "seller-a",
+		Rating:       3.0,
+		TotalReviews: 4,
+		AsOf:         now.Add(-time.Hour),
+	}))
+
+	cards, err := repo.FindBySellerIDs([]ids.UserID{"seller-a"})
+	require.NoError(t, err)
+	require.Len(t, cards, 1)
+	assert.Equal(t, 4.5, cards[0].Rating)
+	assert.Equal(t, 12, cards[0].TotalReviews)
+	assert.Equal(t, "trusted", cards[0].TrustLevel)
+	assert.Equal(t, []string{"top_rated"}, cards[0].Badges)
+}
+
+func TestSellerCardService_VerificationIsKept(t *testing.T) {
+	repo := newFakeSellerCardRepository()
+	service := app.NewSellerCardService(repo)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, service.MarkVerified(ctx, "seller-a", now))
+	require.NoError(t, service.UpdateStats(ctx, app.UpdateSellerCardCommand{
+		SellerID:     "seller-a",
+		Rating:       4.0,
+		TotalReviews: 2,
+		AsOf:         now.Add(time.Minute),
+	}))
+
+	cards, err := repo.FindBySellerIDs([]ids.UserID{"seller-a"})
+	require.NoError(t, err)
+	require.Len(t, cards, 1)
+	assert.True(t, cards[0].Verified)
+	assert.Equal(t, 4.0, cards[0].Rating)
+	assert.Equal(t, "new", cards[0].TrustLevel)
+
+	assert.Error(t, service.UpdateStats(ctx, app.UpdateSellerCardCommand{}))
+}
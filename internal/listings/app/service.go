@@ -0,0 +1,1103 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// sellerBatchSize is the page size used when walking a seller's listings
+const sellerBatchSize = 100
+
+// defaultPageSize is the page size used when a query does not specify one
+const defaultPageSize = 20
+
+// defaultSearchRadiusKm is the radius of searches near a point that do not specify one
+const defaultSearchRadiusKm = 10
+
+// topQuestionsLimit is how many answered questions are shown with a listing
+const topQuestionsLimit = 3
+
+// SearchListingsQuery represents the query to search active listings. The
+// price range may also be given as price[gte] and price[lte].
+type SearchListingsQuery struct {
+	Query      string     `form:"q"`
+	SellerID   ids.UserID `form:"seller_id" binding:"omitempty,uuid"`
+	CategoryID string     `form:"category_id"`
+	Condition  string     `form:"condition" binding:"omitempty,oneof=new like_new good fair poor for_parts"`
+	MinPrice   *float64   `form:"min_price" filter:"price,gte" binding:"omitempty,min=0"`
+	MaxPrice   *float64   `form:"max_price" filter:"price,lte" binding:"omitempty,min=0"`
+	Region     string     `form:"region"`
+	City       string     `form:"city"`
+	Negotiable *bool      `form:"negotiable"`
+	Tag        string     `form:"tag"`
+	Latitude   *float64   `form:"lat" binding:"omitempty,min=-90,max=90"`
+	Longitude  *float64   `form:"lng" binding:"omitempty,min=-180,max=180"`
+	RadiusKm   float64    `form:"radius_km" binding:"omitempty,gt=0,max=200"`
+	Sort       string     `form:"sort" binding:"omitempty,oneof=relevance newest price_asc price_desc most_viewed distance"`
+	Limit      int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset     int        `form:"offset" binding:"omitempty,min=0"`
+	// Cursor is the next_cursor of the previous page, an alternative to offset
+	Cursor string `form:"cursor"`
+}
+
+// CreateListingCommand represents the command to create a draft listing.
+// Price is in major units of Currency, such as cedis, and Currency is GHS
+// when not given.
+type CreateListingCommand struct {
+	SellerID     ids.UserID        `json:"-"`
+	CategoryID   string            `json:"category_id" binding:"required,uuid"`
+	Title        string            `json:"title" binding:"required"`
+	Description  string            `json:"description"`
+	Price        float64           `json:"price" binding:"required,gt=0"`
+	Currency     string            `json:"currency" binding:"omitempty,oneof=GHS USD EUR GBP NGN"`
+	Condition    string            `json:"condition" binding:"required,oneof=new like_new good fair poor for_parts"`
+	Location     domain.Location   `json:"location"`
+	IsNegotiable *bool             `json:"is_negotiable"`
+	Attributes   map[string]string `json:"attributes"`
+}
+
+// CreateDraftCommand represents the command to start a draft listing. Every
+// field is optional; the draft is checked in full when it is published.
+type CreateDraftCommand struct {
+	SellerID     ids.UserID        `json:"-"`
+	CategoryID   string            `json:"category_id" binding:"omitempty,uuid"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Price        float64           `json:"price" binding:"omitempty,gt=0"`
+	Currency     string            `json:"currency" binding:"omitempty,oneof=GHS USD EUR GBP NGN"`
+	Condition    string            `json:"condition" binding:"omitempty,oneof=new like_new good fair poor for_parts"`
+	Location     domain.Location   `json:"location"`
+	IsNegotiable *bool             `json:"is_negotiable"`
+	Attributes   map[string]string `json:"attributes"`
+}
+
+// UpdateListingCommand represents the command to change a listing. Fields
+// left out are not changed. A new Currency reprices the listing, at Price if
+// given and at the same amount otherwise.
+type UpdateListingCommand struct {
+	ListingID    ids.ListingID    `json:"-"`
+	SellerID     ids.UserID       `json:"-"`
+	CategoryID   *string          `json:"category_id" binding:"omitempty,uuid"`
+	Title        *string          `json:"title"`
+	Description  *string          `json:"description"`
+	Price        *float64         `json:"price" binding:"omitempty,gt=0"`
+	Currency     *string          `json:"currency" binding:"omitempty,oneof=GHS USD EUR GBP NGN"`
+	Condition    *string          `json:"condition" binding:"omitempty,oneof=new like_new good fair poor for_parts"`
+	Location     *domain.Location `json:"location"`
+	IsNegotiable *bool            `json:"is_negotiable"`
+}
+
+// ListSellerListingsQuery represents the query to list a seller's own
+// listings in every status
+type ListSellerListingsQuery struct {
+	SellerID ids.UserID `form:"-"`
+	Limit    int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset   int        `form:"offset" binding:"omitempty,min=0"`
+}
+
+// SellerListings represents a page of a seller's own listings
+type SellerListings struct {
+	Listings []*domain.Listing `json:"listings"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
+
+// ListingSearchResults represents a page of search results with facets
+type ListingSearchResults struct {
+	Listings []*domain.Listing    `json:"listings"`
+	Total    int64                `json:"total"`
+	Facets   *domain.SearchFacets `json:"facets"`
+	Limit    int                  `json:"limit"`
+	Offset   int                  `json:"offset"`
+	// NextCursor fetches the next page; it is empty on the last page and
+	// when sorting by relevance
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListingDetail represents a listing as shown on its own page, with its most
+// recently answered public questions
+type ListingDetail struct {
+	*domain.Listing
+	Questions     []*domain.ListingQuestion `json:"questions"`
+	QuestionCount int64                     `json:"question_count"`
+}
+
+// PriceHistory represents a listing's current price and its price changes,
+// most recent first
+type PriceHistory struct {
+	ListingID ids.ListingID         `json:"listing_id"`
+	Price     money.Money           `json:"price"`
+	Changes   []*domain.PriceChange `json:"changes"`
+}
+
+// SellerTrustSource looks up the trust level and badges of sellers
+type SellerTrustSource interface {
+	SellerTrust(ctx context.Context, sellerIDs []ids.UserID) (map[ids.UserID]*domain.SellerTrust, error)
+}
+
+// FollowedSellersSource looks up the sellers a user follows
+type FollowedSellersSource interface {
+	FollowedSellerIDs(ctx context.Context, followerID ids.UserID) ([]ids.UserID, error)
+}
+
+// PublicationValidator runs the publication rules against one of a seller's
+// listings. It is implemented by PublicationService.
+type PublicationValidator interface {
+	ValidateListing(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID) (*domain.PublicationReport, error)
+}
+
+// ListingService handles listing-related use cases
+type ListingService struct {
+	listingRepo     domain.ListingRepository
+	questionRepo    domain.ListingQuestionRepository
+	eventBus        events.EventBus
+	sellerTrust     SellerTrustSource
+	sellerCards     domain.SellerCardRepository
+	counters        domain.ListingCounterRepository
+	followedSellers FollowedSellersSource
+	publication     PublicationValidator
+	priceHistory    domain.PriceHistoryRepository
+	promotions      *PromotionRanker
+	clock           clock.Clock
+}
+
+// NewListingService creates a new listing service. questionRepo, sellerTrust,
+// sellerCards, counters and followedSellers may be nil when listings are not
+// served to buyers, publication may be nil when sellers do not publish
+// listings, priceHistory may be nil when sellers do not change prices, and
+// promotions may be nil to show searches without sponsored listings. clk may
+// be nil to use the system clock.
+func NewListingService(listingRepo domain.ListingRepository, questionRepo domain.ListingQuestionRepository, eventBus events.EventBus, sellerTrust SellerTrustSource, sellerCards domain.SellerCardRepository, counters domain.ListingCounterRepository, followedSellers FollowedSellersSource, publication PublicationValidator, priceHistory domain.PriceHistoryRepository, promotions *PromotionRanker, clk clock.Clock) *ListingService {
+	return &ListingService{
+		listingRepo:     listingRepo,
+		questionRepo:    questionRepo,
+		eventBus:        eventBus,
+		sellerTrust:     sellerTrust,
+		sellerCards:     sellerCards,
+		counters:        counters,
+		followedSellers: followedSellers,
+		publication:     publication,
+		priceHistory:    priceHistory,
+		promotions:      promotions,
+		clock:           clock.OrSystem(clk),
+	}
+}
+
+// CreateListing creates a draft listing for the seller. Drafts are checked
+// against the publication rules when they are activated.
+func (s *ListingService) CreateListing(ctx context.Context, cmd CreateListingCommand) (*domain.Listing, error) {
+	listing, err := domain.NewListing(
+		cmd.SellerID,
+		cmd.CategoryID,
+		strings.TrimSpace(cmd.Title),
+		strings.TrimSpace(cmd.Description),
+		money.FromMajor(cmd.Price, listingCurrency(cmd.Currency)),
+		domain.Condition(cmd.Condition),
+		cmd.Location,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.IsNegotiable != nil {
+		listing.IsNegotiable = *cmd.IsNegotiable
+	}
+	addAttributes(listing, cmd.Attributes)
+
+	if err := saveNewListing(ctx, s.listingRepo, s.eventBus, listing); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// CreateDraft saves whatever the seller has filled in so far as a draft.
+// The seller keeps changing it with UpdateListing and publishes it with
+// ActivateListing once it is complete.
+func (s *ListingService) CreateDraft(ctx context.Context, cmd CreateDraftCommand) (*domain.Listing, error) {
+	listing, err := domain.NewDraft(cmd.SellerID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	details := listing.Details()
+	details.CategoryID = cmd.CategoryID
+	details.Title = strings.TrimSpace(cmd.Title)
+	details.Description = strings.TrimSpace(cmd.Description)
+	details.Price = money.FromMajor(cmd.Price, listingCurrency(cmd.Currency))
+	details.Condition = domain.Condition(cmd.Condition)
+	details.Location = cmd.Location
+	if cmd.IsNegotiable != nil {
+		details.IsNegotiable = *cmd.IsNegotiable
+	}
+	if err := listing.UpdateDetails(details); err != nil {
+		return nil, err
+	}
+	addAttributes(listing, cmd.Attributes)
+
+	if err := saveNewListing(ctx, s.listingRepo, s.eventBus, listing); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// listingCurrency returns the currency a new listing is priced in
+func listingCurrency(currency string) string {
+	if currency == "" {
+		return money.DefaultCurrency
+	}
+	return currency
+}
+
+// addAttributes adds the seller's attributes to a new listing in name order,
+// so the listing reads the same every time
+func addAttributes(listing *domain.Listing, attributes map[string]string) {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		listing.AddAttribute(strings.TrimSpace(key), strings.TrimSpace(attributes[key]))
+	}
+}
+
+// saveNewListing stores a new listing and publishes ListingCreated
+func saveNewListing(ctx context.Context, listingRepo domain.ListingRepository, eventBus events.EventBus, listing *domain.Listing) error {
+	if err := db.WithRetry(ctx, func() error { return listingRepo.Save(listing) }); err != nil {
+		return err
+	}
+
+	event, err := events.NewEvent(domain.ListingCreatedEvent, listing.ID.String(), domain.ListingCreated{
+		ListingID:  listing.ID,
+		SellerID:   listing.SellerID,
+		CategoryID: listing.CategoryID,
+		Title:      listing.Title,
+		Price:      listing.Price,
+		Timestamp:  listing.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	return eventBus.Publish(ctx, event)
+}
+
+// UpdateListing changes the details of one of the seller's listings
+func (s *ListingService) UpdateListing(ctx context.Context, cmd UpdateListingCommand) (*domain.Listing, error) {
+	listing, err := s.sellerListing(cmd.ListingID, cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := listing.Details()
+	if cmd.CategoryID != nil {
+		details.CategoryID = *cmd.CategoryID
+	}
+	if cmd.Title != nil {
+		details.Title = strings.TrimSpace(*cmd.Title)
+	}
+	if cmd.Description != nil {
+		details.Description = strings.TrimSpace(*cmd.Description)
+	}
+	if cmd.Currency != nil {
+		details.Price.Currency = *cmd.Currency
+	}
+	if cmd.Price != nil {
+		details.Price = money.FromMajor(*cmd.Price, details.Price.Currency)
+	}
+	if cmd.Condition != nil {
+		details.Condition = domain.Condition(*cmd.Condition)
+	}
+	if cmd.Location != nil {
+		details.Location = *cmd.Location
+	}
+	if cmd.IsNegotiable != nil {
+		details.IsNegotiable = *cmd.IsNegotiable
+	}
+	oldPrice := listing.Price
+	if err := listing.UpdateDetails(details); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return nil, err
+	}
+	if !listing.Price.Equal(oldPrice) && listing.Status != domain.ListingStatusDraft {
+		if err := s.recordPriceChange(ctx, listing, oldPrice); err != nil {
+			return nil, err
+		}
+	}
+	err = s.publishEvent(ctx, domain.ListingUpdatedEvent, listing, domain.ListingUpdated{
+		ListingID:  listing.ID,
+		SellerID:   listing.SellerID,
+		CategoryID: listing.CategoryID,
+		Title:      listing.Title,
+		Price:      listing.Price,
+		Status:     listing.Status,
+		Timestamp:  listing.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.publishChanged(ctx, listing); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// recordPriceChange adds a change of a published listing's price to its
+// history and publishes ListingPriceChanged
+func (s *ListingService) recordPriceChange(ctx context.Context, listing *domain.Listing, oldPrice money.Money) error {
+	change := domain.NewPriceChange(listing, oldPrice, s.clock.Now())
+	if s.priceHistory != nil {
+		if err := db.WithRetry(ctx, func() error { return s.priceHistory.Save(change) }); err != nil {
+			return err
+		}
+	}
+
+	return s.publishEvent(ctx, domain.ListingPriceChangedEvent, listing, domain.ListingPriceChanged{
+		ListingID: listing.ID,
+		SellerID:  listing.SellerID,
+		Title:     listing.Title,
+		OldPrice:  change.OldPrice,
+		NewPrice:  change.NewPrice,
+		Timestamp: change.ChangedAt,
+	})
+}
+
+// ActivateListing puts one of the seller's listings live for its full
+// lifetime once it passes the publication rules. Activating a scheduled
+// draft publishes it now instead.
+func (s *ListingService) ActivateListing(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.sellerListing(listingID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.IsActive() {
+		return listing, nil
+	}
+
+	if s.publication != nil {
+		report, err := s.publication.ValidateListing(ctx, listingID, sellerID)
+		if err != nil {
+			return nil, err
+		}
+		if !report.Publishable {
+			return nil, errors.ValidationError(report.Errors[0].Message)
+		}
+	}
+
+	now := s.clock.Now()
+	if listing.IsScheduled() {
+		if err := listing.CancelSchedule(now); err != nil {
+			return nil, err
+		}
+	}
+	if err := listing.Activate(); err != nil {
+		return nil, err
+	}
+	listing.ExpiresAt = now.AddDate(0, 0, domain.ListingLifetimeDays)
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return nil, err
+	}
+	if err := publishStatusChanges(ctx, s.eventBus, listing); err != nil {
+		return nil, err
+	}
+
+	// Publish ListingActivated event so the seller's followers hear of it
+	event, err := events.NewEvent(
+		domain.ListingActivatedEvent,
+		listing.ID.String(),
+		domain.ListingActivated{
+			ListingID: listing.ID,
+			SellerID:  listing.SellerID,
+			Title:     listing.Title,
+			Price:     listing.Price,
+			Timestamp: now,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// DeactivateListing takes one of the seller's listings off the marketplace.
+// Listings a buyer is paying for cannot be taken down.
+func (s *ListingService) DeactivateListing(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.sellerListing(listingID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case listing.Status == domain.ListingStatusInactive:
+		return listing, nil
+	case listing.IsReserved():
+		return nil, errors.ConflictError("listing is reserved by a buyer")
+	}
+
+	if err := listing.Deactivate(); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return nil, err
+	}
+	err = s.publishEvent(ctx, domain.ListingDeactivatedEvent, listing, domain.ListingDeactivated{
+		ListingID: listing.ID,
+		SellerID:  listing.SellerID,
+		Timestamp: listing.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.publishChanged(ctx, listing); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// MarkListingSold records that one of the seller's published listings was
+// sold outside the marketplace checkout
+func (s *ListingService) MarkListingSold(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.sellerListing(listingID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case listing.Status == domain.ListingStatusSold:
+		return listing, nil
+	case listing.IsReserved():
+		return nil, errors.ConflictError("listing is reserved by a buyer")
+	}
+
+	return s.sell(ctx, listing)
+}
+
+// RecordSale marks a listing sold through the marketplace checkout, once the
+// buyer's order is complete. Listings already sold or gone are left alone.
+func (s *ListingService) RecordSale(ctx context.Context, listingID ids.ListingID, buyerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if listing.Status == domain.ListingStatusSold {
+		return listing, nil
+	}
+
+	listing.ReleaseReservation(buyerID)
+	return s.sell(ctx, listing)
+}
+
+// sell marks a listing sold and announces it
+func (s *ListingService) sell(ctx context.Context, listing *domain.Listing) (*domain.Listing, error) {
+	if err := listing.MarkAsSold(); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return nil, err
+	}
+	err := s.publishEvent(ctx, domain.ListingSoldEvent, listing, domain.ListingSold{
+		ListingID: listing.ID,
+		SellerID:  listing.SellerID,
+		Price:     listing.Price,
+		Timestamp: listing.UpdatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.publishChanged(ctx, listing); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// ExpireListings records that live listings past the end of their lifetime
+// have expired, up to limit of them, and returns how many were. Listings a
+// buyer is paying for are left until the reservation ends.
+func (s *ListingService) ExpireListings(ctx context.Context, limit int) (int, error) {
+	now := s.clock.Now()
+	listings, err := s.listingRepo.FindLapsedListings(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, listing := range listings {
+		expiredAt := listing.ExpiresAt
+		if !listing.Expire(now) {
+			continue
+		}
+		if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+			return expired, err
+		}
+		err := s.publishEvent(ctx, domain.ListingExpiredEvent, listing, domain.ListingExpired{
+			ListingID: listing.ID,
+			SellerID:  listing.SellerID,
+			ExpiredAt: expiredAt,
+			Timestamp: now,
+		})
+		if err != nil {
+			return expired, err
+		}
+		if err := s.publishChanged(ctx, listing); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// DeleteStaleDrafts deletes unscheduled drafts their sellers have not saved
+// for maxAge, up to limit of them, and returns how many were deleted.
+// Drafts were never live, so nothing else needs to hear about it.
+func (s *ListingService) DeleteStaleDrafts(ctx context.Context, maxAge time.Duration, limit int) (int, error) {
+	drafts, err := s.listingRepo.FindStaleDrafts(s.clock.Now().Add(-maxAge), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, draft := range drafts {
+		if err := db.WithRetry(ctx, func() error { return s.listingRepo.Delete(draft.ID) }); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// GetPriceHistory returns how the price of an active listing has changed
+// since it was published, most recent change first
+func (s *ListingService) GetPriceHistory(ctx context.Context, listingID ids.ListingID) (*PriceHistory, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if !listing.IsActive() {
+		return nil, errors.NotFoundError("listing not found")
+	}
+
+	history := &PriceHistory{
+		ListingID: listing.ID,
+		Price:     listing.Price,
+		Changes:   []*domain.PriceChange{},
+	}
+	if s.priceHistory == nil {
+		return history, nil
+	}
+	changes, err := s.priceHistory.FindByListing(listing.ID, domain.MaxPriceHistory)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) > 0 {
+		history.Changes = changes
+	}
+	return history, nil
+}
+
+// GetSellerListing returns one of the seller's listings in any status
+func (s *ListingService) GetSellerListing(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	return s.sellerListing(listingID, sellerID)
+}
+
+// ListSellerListings lists a seller's own listings in every status, newest first
+func (s *ListingService) ListSellerListings(ctx context.Context, query ListSellerListingsQuery) (*SellerListings, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	listings, err := s.listingRepo.FindBySeller(query.SellerID, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	if listings == nil {
+		listings = []*domain.Listing{}
+	}
+
+	return &SellerListings{
+		Listings: listings,
+		Limit:    limit,
+		Offset:   query.Offset,
+	}, nil
+}
+
+// GetListing returns an active listing with its seller trust, counters and
+// top answered questions, and publishes ListingViewed so the view is counted.
+// viewerKey identifies the viewer, so repeated views are counted once.
+func (s *ListingService) GetListing(ctx context.Context, listingID ids.ListingID, viewerKey string) (*ListingDetail, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if !listing.IsActive() {
+		return nil, errors.NotFoundError("listing not found")
+	}
+
+	if err := s.attachSellerTrust(ctx, []*domain.Listing{listing}); err != nil {
+		return nil, err
+	}
+	if err := attachCounters(s.counters, []*domain.Listing{listing}); err != nil {
+		return nil, err
+	}
+	s.publishViewed(ctx, listing, viewerKey)
+
+	detail := &ListingDetail{Listing: listing, Questions: []*domain.ListingQuestion{}}
+	if s.questionRepo == nil {
+		return detail, nil
+	}
+
+	questions, err := s.questionRepo.FindTopAnswered(listing.ID, topQuestionsLimit)
+	if err != nil {
+		return nil, err
+	}
+	_, count, err := s.questionRepo.FindByListing(listing.ID, domain.QuestionStatusPublished, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	detail.Questions = questions
+	detail.QuestionCount = count
+	return detail, nil
+}
+
+// HoldSellerListings takes a seller's live listings down for a reason tied to
+// their account, such as a suspension, and returns how many changed
+func (s *ListingService) HoldSellerListings(ctx context.Context, sellerID ids.UserID, reason domain.ListingHold) (int, error) {
+	return s.updateSellerListings(ctx, sellerID, func(listing *domain.Listing) bool {
+		return listing.Hold(reason)
+	})
+}
+
+// ReleaseSellerListings lifts a hold from a seller's listings, putting those
+// held for no other reason back live, and returns how many changed
+func (s *ListingService) ReleaseSellerListings(ctx context.Context, sellerID ids.UserID, reason domain.ListingHold) (int, error) {
+	return s.updateSellerListings(ctx, sellerID, func(listing *domain.Listing) bool {
+		return listing.Release(reason)
+	})
+}
+
+// updateSellerListings applies a change to every listing of a seller, saving
+// and announcing the listings it reports changed
+func (s *ListingService) updateSellerListings(ctx context.Context, sellerID ids.UserID, change func(*domain.Listing) bool) (int, error) {
+	changed := 0
+	for offset := 0; ; offset += sellerBatchSize {
+		listings, err := s.listingRepo.FindBySeller(sellerID, sellerBatchSize, offset)
+		if err != nil {
+			return changed, err
+		}
+
+		for _, listing := range listings {
+			if !change(listing) {
+				continue
+			}
+
+			if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+				return changed, err
+			}
+			if err := s.publishChanged(ctx, listing); err != nil {
+				return changed, err
+			}
+			changed++
+		}
+
+		if len(listings) < sellerBatchSize {
+			return changed, nil
+		}
+	}
+}
+
+// ReassignSellerListings moves every listing of one seller to another seller
+func (s *ListingService) ReassignSellerListings(ctx context.Context, fromSellerID, toSellerID ids.UserID) (int, error) {
+	reassigned := 0
+	for {
+		// Reassigned listings drop out of the result set, so always read the first page
+		listings, err := s.listingRepo.FindBySeller(fromSellerID, sellerBatchSize, 0)
+		if err != nil {
+			return reassigned, err
+		}
+
+		for _, listing := range listings {
+			if err := listing.ReassignSeller(toSellerID); err != nil {
+				return reassigned, err
+			}
+			if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+				return reassigned, err
+			}
+			if err := s.publishChanged(ctx, listing); err != nil {
+				return reassigned, err
+			}
+			reassigned++
+		}
+
+		if len(listings) < sellerBatchSize {
+			return reassigned, nil
+		}
+	}
+}
+
+// ReserveListing holds an active listing for a buyer until the given time, so
+// nobody else can buy it while they pay
+func (s *ListingService) ReserveListing(ctx context.Context, listingID ids.ListingID, buyerID ids.UserID, until time.Time) (*domain.Listing, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := listing.Reserve(buyerID, until, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// ReleaseListing lifts a buyer's reservation of a listing. Listings that are
+// gone or no longer reserved for the buyer are left alone.
+func (s *ListingService) ReleaseListing(ctx context.Context, listingID ids.ListingID, buyerID ids.UserID) error {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if !listing.ReleaseReservation(buyerID) {
+		return nil
+	}
+	return db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) })
+}
+
+// SearchListings searches the active listings of the whole marketplace, or of
+// one seller when the query names them
+func (s *ListingService) SearchListings(ctx context.Context, query SearchListingsQuery) (*ListingSearchResults, error) {
+	criteria, err := query.criteria()
+	if err != nil {
+		return nil, err
+	}
+	criteria.SellerID = query.SellerID
+
+	return s.search(ctx, criteria, query.Limit, query.Offset)
+}
+
+// SearchSellerListings searches the active listings of a single seller's storefront
+func (s *ListingService) SearchSellerListings(ctx context.Context, sellerID ids.UserID, query SearchListingsQuery) (*ListingSearchResults, error) {
+	if sellerID == "" {
+		return nil, errors.ValidationError("seller ID is required")
+	}
+
+	criteria, err := query.criteria()
+	if err != nil {
+		return nil, err
+	}
+	criteria.SellerID = sellerID
+
+	return s.search(ctx, criteria, query.Limit, query.Offset)
+}
+
+// FollowedSellerFeed lists the active listings of the sellers a user follows.
+// Like any search without a text query, it is ordered newest first by default.
+func (s *ListingService) FollowedSellerFeed(ctx context.Context, followerID ids.UserID, query SearchListingsQuery) (*ListingSearchResults, error) {
+	if s.followedSellers == nil {
+		return nil, errors.UnavailableError("followed-seller feed is not available")
+	}
+
+	criteria, err := query.criteria()
+	if err != nil {
+		return nil, err
+	}
+	criteria.SellerIDs, err = s.followedSellers.FollowedSellerIDs(ctx, followerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(criteria.SellerIDs) == 0 {
+		limit := query.Limit
+		if limit == 0 {
+			limit = defaultPageSize
+		}
+		return &ListingSearchResults{
+			Listings: []*domain.Listing{},
+			Facets:   &domain.SearchFacets{},
+			Limit:    limit,
+			Offset:   query.Offset,
+		}, nil
+	}
+
+	return s.search(ctx, criteria, query.Limit, query.Offset)
+}
+
+// search runs a listing search and computes facets over all matches
+func (s *ListingService) search(ctx context.Context, criteria domain.ListingSearchCriteria, limit, offset int) (*ListingSearchResults, error) {
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	// One listing more than the page tells whether there is a next page
+	listings, total, err := s.listingRepo.FindByCriteria(criteria, limit+1, offset)
+	if err != nil {
+		return nil, err
+	}
+	// Counters are needed before the cursor, which resumes by view count
+	if err := attachCounters(s.counters, listings); err != nil {
+		return nil, err
+	}
+	var nextCursor string
+	if len(listings) > limit {
+		listings = listings[:limit]
+		if sort := criteria.SortOrder(); sort.SupportsCursor() {
+			nextCursor = domain.NewListingCursor(listings[limit-1], sort).Encode()
+		}
+	}
+
+	facets, err := s.listingRepo.Facets(criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	if promotedCriteria, ok := s.promotions.promotedCriteria(criteria); ok {
+		promoted, err := s.listingRepo.Search(promotedCriteria, promotedCandidateLimit, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := attachCounters(s.counters, promoted); err != nil {
+			return nil, err
+		}
+		listings = s.promotions.mix(listings, promoted, limit, offset)
+	}
+
+	if err := s.attachSellerCards(listings); err != nil {
+		return nil, err
+	}
+	attachDistances(criteria, listings)
+
+	return &ListingSearchResults{
+		Listings:   listings,
+		Total:      total,
+		Facets:     facets,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// attachSellerTrust fills in the seller trust shown with each listing
+func (s *ListingService) attachSellerTrust(ctx context.Context, listings []*domain.Listing) error {
+	if s.sellerTrust == nil || len(listings) == 0 {
+		return nil
+	}
+
+	trust, err := s.sellerTrust.SellerTrust(ctx, sellerIDsOf(listings))
+	if err != nil {
+		return err
+	}
+	for _, listing := range listings {
+		listing.SellerTrust = trust[listing.SellerID]
+	}
+	return nil
+}
+
+// attachSellerCards fills in the seller shown on each listing card from the
+// seller card read model, with one lookup for the whole page
+func (s *ListingService) attachSellerCards(listings []*domain.Listing) error {
+	return attachSellerCards(s.sellerCards, listings)
+}
+
+// attachDistances fills in how far each listing is from the point of a search near one
+func attachDistances(criteria domain.ListingSearchCriteria, listings []*domain.Listing) {
+	if criteria.Near == nil {
+		return
+	}
+	for _, listing := range listings {
+		distance := listing.Location.DistanceKm(criteria.Near.Latitude, criteria.Near.Longitude)
+		listing.DistanceKm = &distance
+	}
+}
+
+// attachSellerCards fills in the seller shown on each listing card. Nothing is
+// attached when there is no seller card read model.
+func attachSellerCards(sellerCards domain.SellerCardRepository, listings []*domain.Listing) error {
+	if sellerCards == nil || len(listings) == 0 {
+		return nil
+	}
+
+	cards, err := sellerCards.FindBySellerIDs(sellerIDsOf(listings))
+	if err != nil {
+		return err
+	}
+	bySeller := make(map[ids.UserID]*domain.SellerCard, len(cards))
+	for _, card := range cards {
+		bySeller[card.SellerID] = card
+	}
+	for _, listing := range listings {
+		if card, ok := bySeller[listing.SellerID]; ok {
+			listing.Seller = card
+			listing.SellerTrust = card.Trust()
+		}
+	}
+	return nil
+}
+
+// sellerListing loads a listing and checks it belongs to the seller
+func (s *ListingService) sellerListing(listingID ids.ListingID, sellerID ids.UserID) (*domain.Listing, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID != sellerID {
+		return nil, errors.ForbiddenError("listing does not belong to the seller")
+	}
+	return listing, nil
+}
+
+// publishEvent publishes an event about a listing
+func (s *ListingService) publishEvent(ctx context.Context, eventType string, listing *domain.Listing, data interface{}) error {
+	event, err := events.NewEvent(eventType, listing.ID.String(), data)
+	if err != nil {
+		return err
+	}
+	return s.eventBus.Publish(ctx, event)
+}
+
+// publishChanged publishes ListingChanged so copies of the listing, such as
+// the search index, are refreshed
+func (s *ListingService) publishChanged(ctx context.Context, listing *domain.Listing) error {
+	return publishListingChanged(ctx, s.eventBus, listing)
+}
+
+// publishViewed publishes ListingViewed for a listing. A view lost to a
+// failed publish is not worth failing the read for.
+func (s *ListingService) publishViewed(ctx context.Context, listing *domain.Listing, viewerKey string) {
+	event, err := events.NewEvent(
+		domain.ListingViewedEvent,
+		listing.ID.String(),
+		domain.ListingViewed{
+			ListingID: listing.ID,
+			ViewerKey: viewerKey,
+			Timestamp: time.Now(),
+		},
+	)
+	if err != nil {
+		return
+	}
+	_ = s.eventBus.Publish(ctx, event)
+}
+
+// publishListingChanged publishes ListingChanged for a listing, after the
+// listing's status changes
+func publishListingChanged(ctx context.Context, eventBus events.EventBus, listing *domain.Listing) error {
+	if err := publishStatusChanges(ctx, eventBus, listing); err != nil {
+		return err
+	}
+	event, err := events.NewEvent(
+		domain.ListingChangedEvent,
+		listing.ID.String(),
+		domain.ListingChanged{
+			ListingID: listing.ID,
+			SellerID:  listing.SellerID,
+			Status:    listing.Status,
+			Timestamp: listing.UpdatedAt,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return eventBus.Publish(ctx, event)
+}
+
+// publishStatusChanges publishes ListingStatusChanged for each status change
+// of a listing since it was loaded
+func publishStatusChanges(ctx context.Context, eventBus events.EventBus, listing *domain.Listing) error {
+	for _, change := range listing.TakeStatusChanges() {
+		event, err := events.NewEvent(domain.ListingStatusChangedEvent, listing.ID.String(), change)
+		if err != nil {
+			return err
+		}
+		if err := eventBus.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sellerIDsOf returns the distinct sellers of the listings, in order
+func sellerIDsOf(listings []*domain.Listing) []ids.UserID {
+	var sellerIDs []ids.UserID
+	seen := make(map[ids.UserID]bool)
+	for _, listing := range listings {
+		if !seen[listing.SellerID] {
+			seen[listing.SellerID] = true
+			sellerIDs = append(sellerIDs, listing.SellerID)
+		}
+	}
+	return sellerIDs
+}
+
+// criteria converts the query into domain search criteria
+func (q SearchListingsQuery) criteria() (domain.ListingSearchCriteria, error) {
+	if q.MinPrice != nil && q.MaxPrice != nil && *q.MinPrice > *q.MaxPrice {
+		return domain.ListingSearchCriteria{}, errors.ValidationError("min_price cannot be greater than max_price")
+	}
+
+	criteria := domain.ListingSearchCriteria{
+		Query:      strings.TrimSpace(q.Query),
+		CategoryID: q.CategoryID,
+		Condition:  domain.Condition(q.Condition),
+		MinPrice:   q.MinPrice,
+		MaxPrice:   q.MaxPrice,
+		Region:     q.Region,
+		City:       q.City,
+		Negotiable: q.Negotiable,
+		Tag:        domain.NormalizeTagName(q.Tag),
+		Sort:       domain.ListingSort(q.Sort),
+	}
+
+	switch {
+	case (q.Latitude == nil) != (q.Longitude == nil):
+		return domain.ListingSearchCriteria{}, errors.ValidationError("lat and lng must be given together")
+	case q.Latitude != nil:
+		criteria.Near = &domain.GeoRadius{Latitude: *q.Latitude, Longitude: *q.Longitude, RadiusKm: q.RadiusKm}
+		if criteria.Near.RadiusKm == 0 {
+			criteria.Near.RadiusKm = defaultSearchRadiusKm
+		}
+	case q.RadiusKm != 0:
+		return domain.ListingSearchCriteria{}, errors.ValidationError("radius_km requires lat and lng")
+	case criteria.Sort == domain.ListingSortDistance:
+		return domain.ListingSearchCriteria{}, errors.ValidationError("sorting by distance requires lat and lng")
+	}
+
+	if q.Cursor != "" {
+		if q.Offset > 0 {
+			return domain.ListingSearchCriteria{}, errors.ValidationError("cursor and offset cannot be combined")
+		}
+		cursor, err := domain.DecodeListingCursor(q.Cursor)
+		if err != nil {
+			return domain.ListingSearchCriteria{}, err
+		}
+		if cursor.Sort != criteria.SortOrder() {
+			return domain.ListingSearchCriteria{}, errors.ValidationError("cursor does not match the sort order")
+		}
+		criteria.After = cursor
+	}
+	return criteria, nil
+}
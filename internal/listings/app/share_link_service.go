@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// shareLinkAttempts is how many codes are tried before creating a share link gives up
+const shareLinkAttempts = 5
+
+// CreateShareLinkCommand represents the command to get a listing's short
+// link for a channel
+type CreateShareLinkCommand struct {
+	ListingID ids.ListingID `json:"-"`
+	Channel   string        `json:"channel" binding:"required,oneof=whatsapp facebook twitter telegram sms email copy"`
+}
+
+// ShareLinkService hands out short links to listings, one per channel they
+// are shared on, counts the clicks on them and shows sellers how their
+// listings are shared
+type ShareLinkService struct {
+	linkRepo    domain.ShareLinkRepository
+	listingRepo domain.ListingRepository
+	baseURL     string
+	siteURL     string
+	clock       clock.Clock
+}
+
+// NewShareLinkService creates a new share link service. Short links are
+// served on baseURL and lead to listing pages on siteURL.
+func NewShareLinkService(linkRepo domain.ShareLinkRepository, listingRepo domain.ListingRepository, baseURL, siteURL string, clk clock.Clock) *ShareLinkService {
+	return &ShareLinkService{
+		linkRepo:    linkRepo,
+		listingRepo: listingRepo,
+		baseURL:     baseURL,
+		siteURL:     siteURL,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// CreateShareLink returns the short link of an active listing on a channel,
+// creating it the first time the listing is shared there
+func (s *ShareLinkService) CreateShareLink(ctx context.Context, cmd CreateShareLinkCommand) (*domain.ShareLink, error) {
+	listing, err := s.listingRepo.FindByID(cmd.ListingID)
+	if err != nil {
+		return nil, err
+	}
+	if !listing.IsActiveAt(s.clock.Now()) {
+		return nil, errors.NotFoundError("listing not found")
+	}
+
+	channel := domain.ShareChannel(cmd.Channel)
+	for attempt := 0; attempt < shareLinkAttempts; attempt++ {
+		existing, err := s.linkRepo.FindByListingAndChannel(listing.ID, channel)
+		if err == nil {
+			existing.URL = domain.ShortLinkURL(s.baseURL, existing.Code)
+			return existing, nil
+		}
+		if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeNotFound {
+			return nil, err
+		}
+
+		link, err := domain.NewShareLink(listing.ID, channel, s.clock.Now())
+		if err != nil {
+			return nil, err
+		}
+		err = db.WithRetry(ctx, func() error { return s.linkRepo.Save(link) })
+		if err == nil {
+			link.URL = domain.ShortLinkURL(s.baseURL, link.Code)
+			return link, nil
+		}
+		// A conflict means the code is taken or another request created the
+		// listing's link first; the next attempt finds out which
+		if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeConflict {
+			return nil, err
+		}
+	}
+	return nil, errors.UnavailableError("could not create a share link, please try again")
+}
+
+// FollowShareLink returns the page of the listing a short link leads to,
+// counting the click on the link's channel. Bots fetching the link to
+// preview it are not counted.
+func (s *ShareLinkService) FollowShareLink(ctx context.Context, code, userAgent string) (string, error) {
+	link, err := s.linkRepo.FindByCode(code)
+	if err != nil {
+		return "", err
+	}
+
+	if !domain.IsLinkPreviewer(userAgent) {
+		now := s.clock.Now()
+		if err := db.WithRetry(ctx, func() error { return s.linkRepo.RecordClick(link.Code, domain.AnalyticsDay(now), now) }); err != nil {
+			return "", err
+		}
+	}
+	return domain.ListingPageURL(s.siteURL, link.ListingID), nil
+}
+
+// GetShareStats returns the clicks on the share links of one of a seller's
+// listings over the query's days, which default to the last 30
+func (s *ShareLinkService) GetShareStats(ctx context.Context, listingID ids.ListingID, sellerID ids.UserID, query SellerAnalyticsQuery) (*domain.ShareStats, error) {
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID != sellerID {
+		return nil, errors.ForbiddenError("listing does not belong to the seller")
+	}
+
+	from, to, err := query.days(s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.linkRepo.FindByListing(listing.ID)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(links))
+	for _, link := range links {
+		link.URL = domain.ShortLinkURL(s.baseURL, link.Code)
+		codes = append(codes, link.Code)
+	}
+	clicks, err := s.linkRepo.FindClicks(codes, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewShareStats(listing.ID, links, clicks, from, to), nil
+}
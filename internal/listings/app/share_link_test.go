@@ -0,0 +1,82 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareLinkService_SharesOneLinkPerChannel(t *testing.T) {
+	ctx := context.Background()
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	service := app.NewShareLinkService(newFakeShareLinkRepository(), newFakeListingRepository(listing), "https://dongo.me/", "https://dongome.com", nil)
+
+	whatsapp, err := service.CreateShareLink(ctx, app.CreateShareLinkCommand{ListingID: listing.ID, Channel: "whatsapp"})
+	require.NoError(t, err)
+	assert.Len(t, whatsapp.Code, domain.ShareLinkCodeLength)
+	assert.Equal(t, "https://dongo.me/l/"+whatsapp.Code, whatsapp.URL)
+
+	again, err := service.CreateShareLink(ctx, app.CreateShareLinkCommand{ListingID: listing.ID, Channel: "whatsapp"})
+	require.NoError(t, err)
+	assert.Equal(t, whatsapp.Code, again.Code, "everyone sharing on a channel gets the same link")
+
+	facebook, err := service.CreateShareLink(ctx, app.CreateShareLinkCommand{ListingID: listing.ID, Channel: "facebook"})
+	require.NoError(t, err)
+	assert.NotEqual(t, whatsapp.Code, facebook.Code)
+
+	draft, err := domain.NewDraft("seller-a", time.Now())
+	require.NoError(t, err)
+	service = app.NewShareLinkService(newFakeShareLinkRepository(), newFakeListingRepository(draft), "https://dongo.me", "https://dongome.com", nil)
+	_, err = service.CreateShareLink(ctx, app.CreateShareLinkCommand{ListingID: draft.ID, Channel: "whatsapp"})
+	assert.Error(t, err, "only active listings are shared")
+}
+
+func TestShareLinkService_CountsClicksPerChannel(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFrozen(time.Date(2026, time.March, 10, 18, 0, 0, 0, time.UTC))
+	listing := newActiveListing(t, "seller-a", "Used phone", domain.ConditionGood)
+	service := app.NewShareLinkService(newFakeShareLinkRepository(), newFakeListingRepository(listing), "https://dongo.me", "https://dongome.com", clk)
+
+	whatsapp, err := service.CreateShareLink(ctx, app.CreateShareLinkCommand{ListingID: listing.ID, Channel: "whatsapp"})
+	require.NoError(t, err)
+	facebook, err := service.CreateShareLink(ctx, app.CreateShareLinkCommand{ListingID: listing.ID, Channel: "facebook"})
+	require.NoError(t, err)
+
+	url, err := service.FollowShareLink(ctx, whatsapp.Code, "Mozilla/5.0 (Linux; Android 13)")
+	require.NoError(t, err)
+	assert.Equal(t, "https://dongome.com/listings/"+listing.ID.String(), url)
+	clk.Advance(-24 * time.Hour)
+	_, err = service.FollowShareLink(ctx, whatsapp.Code, "Mozilla/5.0")
+	require.NoError(t, err)
+	clk.Advance(24 * time.Hour)
+	_, err = service.FollowShareLink(ctx, facebook.Code, "Mozilla/5.0")
+	require.NoError(t, err)
+	// Link previews are not clicks
+	_, err = service.FollowShareLink(ctx, whatsapp.Code, "WhatsApp/2.23.20.0 A")
+	require.NoError(t, err)
+	_, err = service.FollowShareLink(ctx, "unknown", "Mozilla/5.0")
+	assert.Error(t, err)
+
+	_, err = service.GetShareStats(ctx, listing.ID, "seller-b", app.SellerAnalyticsQuery{})
+	assert.Error(t, err, "only the seller sees the stats")
+
+	stats, err := service.GetShareStats(ctx, listing.ID, "seller-a", app.SellerAnalyticsQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03-10", stats.To)
+	assert.Len(t, stats.Days, 30)
+	assert.Equal(t, int64(3), stats.Clicks)
+	require.Len(t, stats.Channels, 2)
+	assert.Equal(t, domain.ShareChannelWhatsApp, stats.Channels[0].Channel, "most clicked first")
+	assert.Equal(t, int64(2), stats.Channels[0].Clicks)
+	assert.Equal(t, whatsapp.URL, stats.Channels[0].URL)
+	assert.Equal(t, int64(1), stats.Channels[1].Clicks)
+	assert.Equal(t, int64(2), stats.Days[29].Clicks)
+	assert.Equal(t, int64(1), stats.Days[28].Clicks)
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// similarListingsTTL is how long a listing's similar listings stay cached.
+// Only their IDs are cached, so listings sold or edited since are dropped or
+// shown as they are now.
+const similarListingsTTL = 30 * time.Minute
+
+// Limits on similar listings
+const (
+	// similarCandidateLimit is how many listings of the category in the
+	// price band are scored
+	similarCandidateLimit = 200
+	maxSimilarListings    = 20
+	// defaultSimilarListings is how many are shown when the query does not say
+	defaultSimilarListings = 8
+)
+
+// SimilarListingsQuery represents how many similar listings to show
+type SimilarListingsQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=20"`
+}
+
+// SimilarListings represents the listings most similar to a listing, most
+// similar first
+type SimilarListings struct {
+	ListingID ids.ListingID           `json:"listing_id"`
+	Listings  []domain.SimilarListing `json:"listings"`
+}
+
+// similarEntry is a similar listing as cached
+type similarEntry struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	Score     float64       `json:"score"`
+}
+
+// SimilarListingService recommends listings similar to the one a buyer is
+// looking at: of the same category, at a comparable price and close by,
+// scored by the attributes they share
+type SimilarListingService struct {
+	listingRepo domain.ListingRepository
+	cache       cache.Cache
+}
+
+// NewSimilarListingService creates a new similar listing service
+func NewSimilarListingService(listingRepo domain.ListingRepository, cache cache.Cache) *SimilarListingService {
+	return &SimilarListingService{
+		listingRepo: listingRepo,
+		cache:       cache,
+	}
+}
+
+// SimilarListingsKey returns the cache key of a listing's similar listings
+func SimilarListingsKey(listingID ids.ListingID) string {
+	return "listing:similar:" + listingID.String()
+}
+
+// GetSimilarListings returns the active listings most similar to an active
+// listing, from the cache when possible. Cache failures fall back to the
+// database.
+func (s *SimilarListingService) GetSimilarListings(ctx context.Context, listingID ids.ListingID, query SimilarListingsQuery) (*SimilarListings, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSimilarListings
+	}
+
+	listing, err := s.listingRepo.FindByID(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if !listing.IsActive() {
+		return nil, errors.NotFoundError("listing not found")
+	}
+
+	var entries []similarEntry
+	if err := s.cache.Get(ctx, SimilarListingsKey(listingID), &entries); err != nil {
+		entries, err = s.rankSimilar(listing)
+		if err != nil {
+			return nil, err
+		}
+		_ = s.cache.Set(ctx, SimilarListingsKey(listingID), entries, similarListingsTTL)
+	}
+
+	listingIDs := make([]ids.ListingID, 0, len(entries))
+	for _, entry := range entries {
+		listingIDs = append(listingIDs, entry.ListingID)
+	}
+	found, err := s.listingRepo.FindByIDs(listingIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[ids.ListingID]*domain.Listing, len(found))
+	for _, candidate := range found {
+		byID[candidate.ID] = candidate
+	}
+
+	similar := &SimilarListings{ListingID: listingID, Listings: []domain.SimilarListing{}}
+	for _, entry := range entries {
+		candidate, ok := byID[entry.ListingID]
+		if !ok || !candidate.IsActive() {
+			continue
+		}
+		similar.Listings = append(similar.Listings, domain.SimilarListing{Listing: candidate, Score: entry.Score})
+		if len(similar.Listings) == limit {
+			break
+		}
+	}
+	return similar, nil
+}
+
+// InvalidateSimilarListings removes a listing's similar listings from the
+// cache, so they are found again for its new details
+func (s *SimilarListingService) InvalidateSimilarListings(ctx context.Context, listingID ids.ListingID) error {
+	return s.cache.Delete(ctx, SimilarListingsKey(listingID))
+}
+
+// rankSimilar scores the active listings of the listing's category in its
+// price band and returns the most similar
+func (s *SimilarListingService) rankSimilar(listing *domain.Listing) ([]similarEntry, error) {
+	minPrice, maxPrice := listing.SimilarPriceRange()
+	candidates, err := s.listingRepo.Search(domain.ListingSearchCriteria{
+		CategoryID: listing.CategoryID,
+		MinPrice:   &minPrice,
+		MaxPrice:   &maxPrice,
+	}, similarCandidateLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	candidateIDs := make([]ids.ListingID, 0, len(candidates))
+	for _, candidate := range candidates {
+		candidateIDs = append(candidateIDs, candidate.ID)
+	}
+	attributes, err := s.listingRepo.FindAttributes(candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+	byListing := make(map[ids.ListingID][]domain.ListingAttribute)
+	for _, attribute := range attributes {
+		byListing[attribute.ListingID] = append(byListing[attribute.ListingID], attribute)
+	}
+	scored := make([]*domain.Listing, 0, len(candidates))
+	for _, candidate := range candidates {
+		// Scored on a copy, as the repository may hand out shared listings
+		withAttributes := *candidate
+		withAttributes.Attributes = byListing[candidate.ID]
+		scored = append(scored, &withAttributes)
+	}
+
+	ranked := listing.RankSimilar(scored, maxSimilarListings)
+	entries := make([]similarEntry, 0, len(ranked))
+	for _, similar := range ranked {
+		entries = append(entries, similarEntry{ListingID: similar.Listing.ID, Score: similar.Score})
+	}
+	return entries, nil
+}
@@ -0,0 +1,64 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarListingService_GetSimilarListings(t *testing.T) {
+	ctx := context.Background()
+	withBrand := func(listing *domain.Listing, brand string) *domain.Listing {
+		listing.Attributes = []domain.ListingAttribute{{ListingID: listing.ID, Key: "brand", Value: brand}}
+		return listing
+	}
+	phone := withBrand(newActiveListing(t, "seller-a", "Samsung Galaxy", domain.ConditionGood), "Samsung")
+	sameBrand := withBrand(newActiveListing(t, "seller-b", "Samsung Note", domain.ConditionGood), "Samsung")
+	otherBrand := withBrand(newActiveListing(t, "seller-c", "Tecno Spark", domain.ConditionGood), "Tecno")
+	tooDear := withBrand(newActiveListing(t, "seller-d", "Samsung Fold", domain.ConditionNew), "Samsung")
+	tooDear.Price = money.Cedis(500)
+	otherCategory := withBrand(newActiveListing(t, "seller-e", "Samsung TV", domain.ConditionGood), "Samsung")
+	otherCategory.CategoryID = "category-2"
+
+	repo := newFakeListingRepository(phone, sameBrand, otherBrand, tooDear, otherCategory)
+	cache := newFakeCache()
+	service := app.NewSimilarListingService(repo, cache)
+
+	similar, err := service.GetSimilarListings(ctx, phone.ID, app.SimilarListingsQuery{})
+	require.NoError(t, err)
+	require.Len(t, similar.Listings, 2, "only listings of the category in the price band are similar")
+	assert.Equal(t, sameBrand.ID, similar.Listings[0].Listing.ID)
+	assert.Equal(t, otherBrand.ID, similar.Listings[1].Listing.ID)
+	assert.Greater(t, similar.Listings[0].Score, similar.Listings[1].Score)
+
+	// Cached listings are shown as they are now, and dropped once sold
+	require.NoError(t, sameBrand.MarkAsSold())
+	otherBrand.Title = "Tecno Spark 10"
+	similar, err = service.GetSimilarListings(ctx, phone.ID, app.SimilarListingsQuery{})
+	require.NoError(t, err)
+	require.Len(t, similar.Listings, 1)
+	assert.Equal(t, "Tecno Spark 10", similar.Listings[0].Listing.Title)
+
+	// A new listing is only found once the cached ones are invalidated
+	newer := withBrand(newActiveListing(t, "seller-f", "Samsung A54", domain.ConditionGood), "Samsung")
+	require.NoError(t, repo.Save(newer))
+	similar, err = service.GetSimilarListings(ctx, phone.ID, app.SimilarListingsQuery{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, similar.Listings, 1)
+	assert.Equal(t, otherBrand.ID, similar.Listings[0].Listing.ID)
+
+	require.NoError(t, service.InvalidateSimilarListings(ctx, phone.ID))
+	similar, err = service.GetSimilarListings(ctx, phone.ID, app.SimilarListingsQuery{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, similar.Listings, 1)
+	assert.Equal(t, newer.ID, similar.Listings[0].Listing.ID)
+
+	_, err = service.GetSimilarListings(ctx, sameBrand.ID, app.SimilarListingsQuery{})
+	assert.Error(t, err, "sold listings have no similar listings")
+}
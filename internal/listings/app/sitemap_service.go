@@ -0,0 +1,158 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"io"
+	"strings"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/clock"
+	"dongome/pkg/errors"
+	"dongome/pkg/storage"
+)
+
+// LatestSitemapKey is the cache key of the latest sitemap version
+const LatestSitemapKey = "seo:sitemap:latest"
+
+// sitemapBatchSize is how many listings are read at a time for the sitemap
+const sitemapBatchSize = 500
+
+// sitemapFeedTitle is the title of the listing feeds
+const sitemapFeedTitle = "Dongome: new listings"
+
+// SitemapStore stores the generated sitemap and feeds. The photo stores
+// satisfy it, so they are kept next to listing photos.
+type SitemapStore interface {
+	Put(ctx context.Context, key, contentType string, content io.Reader) (string, error)
+	// Open reads the file stored under key, or returns storage.ErrNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// SitemapService generates the sitemap of active listings and the feeds of
+// new listings for search engines and feed readers, and serves the latest
+// version of them
+type SitemapService struct {
+	listingRepo domain.ListingRepository
+	store       SitemapStore
+	cache       cache.Cache
+	siteURL     string
+	feedSize    int
+	clock       clock.Clock
+}
+
+// NewSitemapService creates a new sitemap service. Listing pages are linked
+// on siteURL, and the feeds show the feedSize newest listings.
+func NewSitemapService(listingRepo domain.ListingRepository, store SitemapStore, cache cache.Cache, siteURL string, feedSize int, clk clock.Clock) *SitemapService {
+	return &SitemapService{
+		listingRepo: listingRepo,
+		store:       store,
+		cache:       cache,
+		siteURL:     strings.TrimSuffix(siteURL, "/"),
+		feedSize:    feedSize,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// Generate writes a new version of the sitemap and feeds from the active
+// listings, newest first, and makes it the one served. The files of the
+// version it replaces are deleted.
+func (s *SitemapService) Generate(ctx context.Context) (*domain.SitemapVersion, error) {
+	now := s.clock.Now()
+	var urls []domain.SitemapURL
+	var newest []*domain.Listing
+	criteria := domain.ListingSearchCriteria{Sort: domain.ListingSortNewest}
+	for len(urls) < domain.MaxSitemapURLs {
+		listings, err := s.listingRepo.Search(criteria, sitemapBatchSize, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, listing := range listings {
+			if len(urls) == domain.MaxSitemapURLs {
+				break
+			}
+			urls = append(urls, domain.SitemapURL{Loc: domain.ListingPageURL(s.siteURL, listing.ID), LastMod: listing.UpdatedAt})
+			if len(newest) < s.feedSize {
+				newest = append(newest, listing)
+			}
+		}
+
+		if len(listings) < sitemapBatchSize {
+			break
+		}
+		criteria.After = domain.NewListingCursor(listings[len(listings)-1], domain.ListingSortNewest)
+	}
+
+	feed := domain.ListingFeed{
+		Title:    sitemapFeedTitle,
+		SiteURL:  s.siteURL,
+		FeedURL:  s.siteURL + "/feeds/listings",
+		Updated:  now,
+		Listings: newest,
+	}
+	writers := map[string]func(io.Writer) error{
+		domain.SitemapFile:  func(w io.Writer) error { return domain.WriteSitemap(w, urls) },
+		domain.AtomFeedFile: feed.WriteAtom,
+		domain.JSONFeedFile: feed.WriteJSON,
+	}
+
+	version := &domain.SitemapVersion{
+		Version:     now.UTC().Format("20060102T150405Z"),
+		GeneratedAt: now,
+		Listings:    len(urls),
+		Files:       make(map[string]string, len(writers)),
+	}
+	for name, write := range writers {
+		var content bytes.Buffer
+		if err := write(&content); err != nil {
+			return nil, err
+		}
+		key := "seo/" + version.Version + "/" + name
+		if _, err := s.store.Put(ctx, key, domain.SitemapContentTypes[name], &content); err != nil {
+			return nil, err
+		}
+		version.Files[name] = key
+	}
+
+	var previous domain.SitemapVersion
+	hasPrevious := s.cache.Get(ctx, LatestSitemapKey, &previous) == nil
+	if err := s.cache.Set(ctx, LatestSitemapKey, version, 0); err != nil {
+		return nil, err
+	}
+	// Old files left behind when deleting fails are harmless
+	if hasPrevious && previous.Version != version.Version {
+		for _, key := range previous.Files {
+			_ = s.store.Delete(ctx, key)
+		}
+	}
+	return version, nil
+}
+
+// OpenFile reads a file of the latest sitemap version by name, returning it
+// with its content type
+func (s *SitemapService) OpenFile(ctx context.Context, name string) (io.ReadCloser, string, error) {
+	contentType, ok := domain.SitemapContentTypes[name]
+	if !ok {
+		return nil, "", errors.NotFoundError("sitemap not found")
+	}
+	var version domain.SitemapVersion
+	if err := s.cache.Get(ctx, LatestSitemapKey, &version); err != nil {
+		return nil, "", errors.NotFoundError("sitemap not found")
+	}
+	key, ok := version.Files[name]
+	if !ok {
+		return nil, "", errors.NotFoundError("sitemap not found")
+	}
+
+	file, err := s.store.Open(ctx, key)
+	if stderrors.Is(err, storage.ErrNotFound) {
+		return nil, "", errors.NotFoundError("sitemap not found")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return file, contentType, nil
+}
@@ -0,0 +1,68 @@
+package app_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSitemapService_GeneratesAndServesTheLatestVersion(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFrozen(time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC))
+	older := newActiveListing(t, "seller-a", "Laptop", domain.ConditionGood)
+	older.CreatedAt = clk.Now().Add(-2 * time.Hour)
+	newer := newActiveListing(t, "seller-b", "Phone", domain.ConditionGood)
+	newer.CreatedAt = clk.Now().Add(-time.Hour)
+	sold := newActiveListing(t, "seller-c", "Bag", domain.ConditionGood)
+	require.NoError(t, sold.MarkAsSold())
+
+	store := newFakeImageStore()
+	service := app.NewSitemapService(newFakeListingRepository(older, newer, sold), store, newFakeCache(), "https://dongome.com/", 1, clk)
+
+	_, _, err := service.OpenFile(ctx, domain.SitemapFile)
+	assert.Equal(t, errors.ErrCodeNotFound, err.(*errors.DomainError).Code, "nothing is served before the first run")
+
+	first, err := service.Generate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, first.Listings, "only active listings are listed")
+	assert.Len(t, first.Files, 3)
+
+	file, contentType, err := service.OpenFile(ctx, domain.SitemapFile)
+	require.NoError(t, err)
+	sitemap, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, "application/xml; charset=utf-8", contentType)
+	assert.Contains(t, string(sitemap), "https://dongome.com/listings/"+older.ID.String())
+	assert.Contains(t, string(sitemap), "https://dongome.com/listings/"+newer.ID.String())
+	assert.NotContains(t, string(sitemap), sold.ID.String())
+
+	// The feeds show the newest listings only
+	file, _, err = service.OpenFile(ctx, domain.JSONFeedFile)
+	require.NoError(t, err)
+	feed, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(feed), newer.ID.String())
+	assert.NotContains(t, string(feed), older.ID.String())
+
+	// A new version replaces the old one, whose files are deleted
+	clk.Advance(time.Hour)
+	second, err := service.Generate(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Version, second.Version)
+	assert.Len(t, store.files, 3)
+	for _, key := range first.Files {
+		assert.NotContains(t, store.files, key)
+	}
+
+	_, _, err = service.OpenFile(ctx, "robots.txt")
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/errors"
+)
+
+// maxCategoryDepth bounds the walk up the category tree, guarding against
+// cycles in the data
+const maxCategoryDepth = 10
+
+// ListingSuggester drafts a title and description for a new listing. The
+// built-in suggester uses templates per category; a language model provider
+// can be plugged in behind the same interface.
+type ListingSuggester interface {
+	Suggest(ctx context.Context, req domain.SuggestionRequest) (*domain.ListingSuggestion, error)
+}
+
+// SuggestListingCommand represents the command to suggest a title and description
+type SuggestListingCommand struct {
+	CategoryID string            `json:"category_id" binding:"required"`
+	Condition  domain.Condition  `json:"condition" binding:"omitempty,oneof=new like_new good fair poor for_parts"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// SuggestionService handles listing title and description suggestions
+type SuggestionService struct {
+	categoryRepo domain.CategoryRepository
+	suggester    ListingSuggester
+}
+
+// NewSuggestionService creates a new suggestion service
+func NewSuggestionService(categoryRepo domain.CategoryRepository, suggester ListingSuggester) *SuggestionService {
+	return &SuggestionService{
+		categoryRepo: categoryRepo,
+		suggester:    suggester,
+	}
+}
+
+// SuggestListing suggests a title and description skeleton for a listing in
+// a category from the attributes the seller has filled in so far
+func (s *SuggestionService) SuggestListing(ctx context.Context, cmd SuggestListingCommand) (*domain.ListingSuggestion, error) {
+	attributes, err := normalizeAttributes(cmd.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := categoryPath(s.categoryRepo, cmd.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.suggester.Suggest(ctx, domain.SuggestionRequest{
+		CategoryPath: path,
+		Condition:    cmd.Condition,
+		Attributes:   attributes,
+	})
+}
+
+// categoryPath returns the names of an active category and its ancestors,
+// most specific first
+func categoryPath(categoryRepo domain.CategoryRepository, categoryID string) ([]string, error) {
+	category, err := categoryRepo.FindByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if !category.IsActive {
+		return nil, errors.NotFoundError("category not found")
+	}
+
+	path := []string{category.Name}
+	for depth := 1; category.ParentID != nil && depth < maxCategoryDepth; depth++ {
+		category, err = categoryRepo.FindByID(*category.ParentID)
+		if err != nil {
+			if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+				break
+			}
+			return nil, err
+		}
+		path = append(path, category.Name)
+	}
+	return path, nil
+}
+
+// normalizeAttributes lowercases attribute names and trims values, dropping
+// attributes left empty
+func normalizeAttributes(attributes map[string]string) (map[string]string, error) {
+	if len(attributes) > domain.MaxSuggestionAttributes {
+		return nil, errors.ValidationError(fmt.Sprintf("at most %d attributes can be given", domain.MaxSuggestionAttributes))
+	}
+
+	normalized := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.Join(strings.Fields(value), " ")
+		if name == "" || value == "" {
+			continue
+		}
+		if len(value) > domain.MaxSuggestionValueLength {
+			return nil, errors.ValidationError(fmt.Sprintf("%s must be at most %d characters", name, domain.MaxSuggestionValueLength))
+		}
+		normalized[name] = value
+	}
+	return normalized, nil
+}
@@ -0,0 +1,54 @@
+package app_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCategory(id, name string, parentID *string) *domain.Category {
+	return &domain.Category{ID: id, Name: name, ParentID: parentID, IsActive: true}
+}
+
+func TestSuggestionService_SuggestListing(t *testing.T) {
+	electronics := newCategory("electronics", "Electronics", nil)
+	phones := newCategory("phones", "Mobile Phones", &electronics.ID)
+	archived := newCategory("archived", "Archived", nil)
+	archived.IsActive = false
+	suggester := &fakeSuggester{}
+	service := app.NewSuggestionService(newFakeCategoryRepository(electronics, phones, archived), suggester)
+	ctx := context.Background()
+
+	suggestion, err := service.SuggestListing(ctx, app.SuggestListingCommand{
+		CategoryID: phones.ID,
+		Condition:  domain.ConditionLikeNew,
+		Attributes: map[string]string{" Brand ": "  Samsung  Galaxy ", "model": "S21", "color": "  "},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Samsung Galaxy", suggestion.Title)
+
+	// The suggester gets the category path, most specific first, and the
+	// attributes with names lowercased and blank values dropped
+	assert.Equal(t, []string{"Mobile Phones", "Electronics"}, suggester.last.CategoryPath)
+	assert.Equal(t, domain.ConditionLikeNew, suggester.last.Condition)
+	assert.Equal(t, map[string]string{"brand": "Samsung Galaxy", "model": "S21"}, suggester.last.Attributes)
+
+	// Unknown and inactive categories are not found
+	_, err = service.SuggestListing(ctx, app.SuggestListingCommand{CategoryID: "missing"})
+	assert.Error(t, err)
+	_, err = service.SuggestListing(ctx, app.SuggestListingCommand{CategoryID: archived.ID})
+	assert.Error(t, err)
+
+	// Overlong values are rejected
+	_, err = service.SuggestListing(ctx, app.SuggestListingCommand{
+		CategoryID: phones.ID,
+		Attributes: map[string]string{"model": strings.Repeat("x", domain.MaxSuggestionValueLength+1)},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,433 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// trendingTagsKey is the cache key of the trending tags
+const trendingTagsKey = "tags:trending"
+
+// Trending tags are the tags most used on listings created within
+// TrendingTagsWindow. The top maxTrendingTags are cached for trendingTagsTTL.
+const (
+	TrendingTagsWindow = 7 * 24 * time.Hour
+	trendingTagsTTL    = 15 * time.Minute
+	maxTrendingTags    = 50
+)
+
+// defaultTagSuggestions is how many tags are suggested when no limit is given
+const defaultTagSuggestions = 10
+
+// ListTagsQuery represents the query to list tags, optionally those starting
+// with Query
+type ListTagsQuery struct {
+	Query  string `form:"q"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// TrendingTagsQuery represents the query for the trending tags
+type TrendingTagsQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// TagSuggestionsQuery represents the query for tags to suggest to a seller
+// filling in a listing: the tags named in its title, then the tags most used
+// in its category starting with what the seller has typed
+type TagSuggestionsQuery struct {
+	Query      string `form:"q"`
+	CategoryID string `form:"category_id" binding:"omitempty,uuid"`
+	Title      string `form:"title"`
+	Limit      int    `form:"limit" binding:"omitempty,min=1,max=20"`
+}
+
+// TagList represents a page of tags
+type TagList struct {
+	Tags   []*domain.TagCount `json:"tags"`
+	Total  int64              `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+}
+
+// CreateTagCommand represents the command to add a tag
+type CreateTagCommand struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color"`
+}
+
+// UpdateTagCommand represents the command to rename or recolour a tag.
+// Fields left out are not changed.
+type UpdateTagCommand struct {
+	TagID string  `json:"-"`
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+// MergeTagsCommand represents the command to fold duplicate tags into one.
+// The listings of the source tags get the target tag instead.
+type MergeTagsCommand struct {
+	TargetID  string   `json:"-"`
+	SourceIDs []string `json:"source_ids" binding:"required,min=1,dive,uuid"`
+}
+
+// SetListingTagsCommand represents the command to replace the tags of one of
+// the seller's listings. Tags that do not exist yet are created.
+type SetListingTagsCommand struct {
+	ListingID ids.ListingID `json:"-"`
+	SellerID  ids.UserID    `json:"-"`
+	Tags      []string      `json:"tags" binding:"max=50"`
+}
+
+// TagService handles tagging listings and browsing by tag
+type TagService struct {
+	tagRepo     domain.TagRepository
+	listingRepo domain.ListingRepository
+	eventBus    events.EventBus
+	cache       cache.Cache
+	clock       clock.Clock
+}
+
+// NewTagService creates a new tag service. clk may be nil to use the system
+// clock.
+func NewTagService(tagRepo domain.TagRepository, listingRepo domain.ListingRepository, eventBus events.EventBus, cache cache.Cache, clk clock.Clock) *TagService {
+	return &TagService{
+		tagRepo:     tagRepo,
+		listingRepo: listingRepo,
+		eventBus:    eventBus,
+		cache:       cache,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// ListTags lists tags with their active listing counts, most used first
+func (s *TagService) ListTags(ctx context.Context, query ListTagsQuery) (*TagList, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	tags, total, err := s.tagRepo.List(domain.NormalizeTagName(query.Query), limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &TagList{Tags: tags, Total: total, Limit: limit, Offset: query.Offset}, nil
+}
+
+// TrendingTags returns the tags most used on listings created within
+// TrendingTagsWindow, from the cache when possible
+func (s *TagService) TrendingTags(ctx context.Context, query TrendingTagsQuery) ([]*domain.TagCount, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	var tags []*domain.TagCount
+	if err := s.cache.Get(ctx, trendingTagsKey, &tags); err != nil {
+		tags, err = s.tagRepo.FindTrending(s.clock.Now().Add(-TrendingTagsWindow), maxTrendingTags)
+		if err != nil {
+			return nil, err
+		}
+		_ = s.cache.Set(ctx, trendingTagsKey, tags, trendingTagsTTL)
+	}
+
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
+
+// SuggestTags suggests tags for a listing being filled in: existing tags
+// named in its title first, then the category's most used tags starting
+// with what the seller has typed
+func (s *TagService) SuggestTags(ctx context.Context, query TagSuggestionsQuery) ([]*domain.ListingTag, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultTagSuggestions
+	}
+
+	var suggestions []*domain.ListingTag
+	seen := make(map[string]bool)
+	suggest := func(tag *domain.ListingTag) {
+		if !seen[tag.ID] && len(suggestions) < limit {
+			seen[tag.ID] = true
+			suggestions = append(suggestions, tag)
+		}
+	}
+
+	prefix := domain.NormalizeTagName(query.Query)
+	if phrases := titlePhrases(query.Title); len(phrases) > 0 {
+		named, err := s.tagRepo.FindByNames(phrases)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range named {
+			if strings.HasPrefix(tag.Name, prefix) {
+				suggest(tag)
+			}
+		}
+	}
+
+	popular, err := s.tagRepo.FindPopular(query.CategoryID, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range popular {
+		suggest(&tag.ListingTag)
+	}
+	return suggestions, nil
+}
+
+// titlePhrases returns the words of a title and the pairs of words next to
+// each other, as tag names
+func titlePhrases(title string) []string {
+	var words []string
+	for _, word := range strings.Fields(domain.NormalizeTagName(title)) {
+		if word = strings.Trim(word, ".,;:!?()\"'"); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	phrases := make([]string, 0, 2*len(words))
+	for i, word := range words {
+		phrases = append(phrases, word)
+		if i > 0 {
+			phrases = append(phrases, words[i-1]+" "+word)
+		}
+	}
+	return phrases
+}
+
+// CreateTag adds a tag
+func (s *TagService) CreateTag(ctx context.Context, cmd CreateTagCommand) (*domain.ListingTag, error) {
+	tag, err := domain.NewListingTag(cmd.Name, cmd.Color)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameFree(tag.Name, ""); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.tagRepo.Save(tag) }); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// UpdateTag renames or recolours a tag. A tag cannot take the name of
+// another; the two are merged instead.
+func (s *TagService) UpdateTag(ctx context.Context, cmd UpdateTagCommand) (*domain.ListingTag, error) {
+	tag, err := s.tagRepo.FindByID(cmd.TagID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, color := tag.Name, tag.Color
+	if cmd.Name != nil {
+		name = *cmd.Name
+	}
+	if cmd.Color != nil {
+		color = *cmd.Color
+	}
+	oldName := tag.Name
+	if err := tag.Update(name, color); err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameFree(tag.Name, tag.ID); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.tagRepo.Update(tag) }); err != nil {
+		return nil, err
+	}
+	if tag.Name != oldName {
+		if err := s.refreshTagged(ctx, tag.ID); err != nil {
+			return nil, err
+		}
+	}
+	_ = s.cache.Delete(ctx, trendingTagsKey)
+	return tag, nil
+}
+
+// MergeTags folds duplicate tags, such as spelling or case variants, into
+// the target tag, which the listings of the duplicates get instead
+func (s *TagService) MergeTags(ctx context.Context, cmd MergeTagsCommand) (*domain.ListingTag, error) {
+	target, err := s.tagRepo.FindByID(cmd.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var listingIDs []ids.ListingID
+	for _, sourceID := range cmd.SourceIDs {
+		if sourceID == target.ID {
+			return nil, errors.ValidationError("a tag cannot be merged into itself")
+		}
+		if _, err := s.tagRepo.FindByID(sourceID); err != nil {
+			return nil, err
+		}
+		tagged, err := s.tagRepo.FindListingIDs(sourceID)
+		if err != nil {
+			return nil, err
+		}
+		listingIDs = append(listingIDs, tagged...)
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.tagRepo.Merge(target.ID, cmd.SourceIDs) }); err != nil {
+		return nil, err
+	}
+	if err := s.publishChanged(ctx, listingIDs); err != nil {
+		return nil, err
+	}
+	_ = s.cache.Delete(ctx, trendingTagsKey)
+	return target, nil
+}
+
+// DeleteTag deletes a tag, taking it off every listing
+func (s *TagService) DeleteTag(ctx context.Context, tagID string) error {
+	if _, err := s.tagRepo.FindByID(tagID); err != nil {
+		return err
+	}
+	listingIDs, err := s.tagRepo.FindListingIDs(tagID)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.tagRepo.Delete(tagID) }); err != nil {
+		return err
+	}
+	if err := s.publishChanged(ctx, listingIDs); err != nil {
+		return err
+	}
+	_ = s.cache.Delete(ctx, trendingTagsKey)
+	return nil
+}
+
+// SetListingTags replaces the tags of one of the seller's listings, creating
+// the tags that do not exist yet
+func (s *TagService) SetListingTags(ctx context.Context, cmd SetListingTagsCommand) (*domain.Listing, error) {
+	listing, err := s.listingRepo.FindByID(cmd.ListingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.SellerID != cmd.SellerID {
+		return nil, errors.ForbiddenError("listing does not belong to the seller")
+	}
+
+	names, err := domain.NormalizeTagNames(cmd.Tags)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.findOrCreateTags(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs := make([]string, 0, len(tags))
+	listing.Tags = make([]domain.ListingTag, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+		listing.Tags = append(listing.Tags, *tag)
+	}
+	if err := db.WithRetry(ctx, func() error { return s.tagRepo.SetListingTags(listing.ID, tagIDs) }); err != nil {
+		return nil, err
+	}
+	if err := publishListingChanged(ctx, s.eventBus, listing); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+// findOrCreateTags returns the tags with the given normalized names, in
+// order, creating those that do not exist yet
+func (s *TagService) findOrCreateTags(ctx context.Context, names []string) ([]*domain.ListingTag, error) {
+	existing, err := s.tagRepo.FindByNames(names)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*domain.ListingTag, len(existing))
+	for _, tag := range existing {
+		byName[tag.Name] = tag
+	}
+
+	tags := make([]*domain.ListingTag, 0, len(names))
+	for _, name := range names {
+		tag, ok := byName[name]
+		if !ok {
+			if tag, err = s.createTag(ctx, name); err != nil {
+				return nil, err
+			}
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// createTag adds a tag a seller named. A seller tagging a listing with the
+// same new name at the same time may create it first, in which case theirs
+// is used.
+func (s *TagService) createTag(ctx context.Context, name string) (*domain.ListingTag, error) {
+	tag, err := domain.NewListingTag(name, "")
+	if err != nil {
+		return nil, err
+	}
+	saveErr := db.WithRetry(ctx, func() error { return s.tagRepo.Save(tag) })
+	if saveErr == nil {
+		return tag, nil
+	}
+
+	created, err := s.tagRepo.FindByNames([]string{tag.Name})
+	if err != nil || len(created) == 0 {
+		return nil, saveErr
+	}
+	return created[0], nil
+}
+
+// ensureNameFree refuses a tag name another tag than tagID already has
+func (s *TagService) ensureNameFree(name, tagID string) error {
+	existing, err := s.tagRepo.FindByNames([]string{name})
+	if err != nil {
+		return err
+	}
+	for _, tag := range existing {
+		if tag.ID != tagID {
+			return errors.ConflictError("a tag with this name already exists")
+		}
+	}
+	return nil
+}
+
+// refreshTagged publishes ListingChanged for the listings carrying a tag
+func (s *TagService) refreshTagged(ctx context.Context, tagID string) error {
+	listingIDs, err := s.tagRepo.FindListingIDs(tagID)
+	if err != nil {
+		return err
+	}
+	return s.publishChanged(ctx, listingIDs)
+}
+
+// publishChanged publishes ListingChanged for the listings whose tags
+// changed, so the search index and cached copies are refreshed
+func (s *TagService) publishChanged(ctx context.Context, listingIDs []ids.ListingID) error {
+	if len(listingIDs) == 0 {
+		return nil
+	}
+	listings, err := s.listingRepo.FindByIDs(listingIDs)
+	if err != nil {
+		return err
+	}
+	for _, listing := range listings {
+		if err := publishListingChanged(ctx, s.eventBus, listing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTag(t *testing.T, name string) *domain.ListingTag {
+	t.Helper()
+	tag, err := domain.NewListingTag(name, "")
+	require.NoError(t, err)
+	return tag
+}
+
+func TestTagService_SetListingTags(t *testing.T) {
+	ctx := context.Background()
+	iphone := newTag(t, "iphone")
+	listing := newActiveListing(t, "seller-a", "iPhone 13", domain.ConditionGood)
+	tags := newFakeTagRepository(iphone)
+	bus := &fakeEventBus{}
+	service := app.NewTagService(tags, newFakeListingRepository(listing), bus, newFakeCache(), nil)
+
+	tagged, err := service.SetListingTags(ctx, app.SetListingTagsCommand{
+		ListingID: listing.ID,
+		SellerID:  "seller-a",
+		Tags:      []string{"#iPhone", " Free  Delivery ", "iphone", ""},
+	})
+	require.NoError(t, err)
+	require.Len(t, tagged.Tags, 2, "repeats and blanks are dropped")
+	assert.Equal(t, iphone.ID, tagged.Tags[0].ID, "existing tags are reused whatever their case")
+	assert.Equal(t, "free delivery", tagged.Tags[1].Name)
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 1)
+
+	created, err := tags.FindByNames([]string{"free delivery"})
+	require.NoError(t, err)
+	assert.Len(t, created, 1, "new tags are created")
+
+	_, err = service.SetListingTags(ctx, app.SetListingTagsCommand{ListingID: listing.ID, SellerID: "seller-b", Tags: []string{"stolen"}})
+	assert.Error(t, err, "only the seller can tag their listing")
+
+	tooMany := []string{"a1", "a2", "a3", "a4", "a5", "a6", "a7", "a8", "a9", "a10", "a11"}
+	_, err = service.SetListingTags(ctx, app.SetListingTagsCommand{ListingID: listing.ID, SellerID: "seller-a", Tags: tooMany})
+	assert.Error(t, err)
+}
+
+func TestTagService_MergeTags(t *testing.T) {
+	ctx := context.Background()
+	iphone, iPhones, other := newTag(t, "iphone"), newTag(t, "iphones"), newTag(t, "samsung")
+	first := newActiveListing(t, "seller-a", "iPhone 13", domain.ConditionGood)
+	second := newActiveListing(t, "seller-b", "iPhone 12", domain.ConditionGood)
+	tags := newFakeTagRepository(iphone, iPhones, other)
+	require.NoError(t, tags.SetListingTags(first.ID, []string{iphone.ID, iPhones.ID}))
+	require.NoError(t, tags.SetListingTags(second.ID, []string{iPhones.ID}))
+	bus := &fakeEventBus{}
+	service := app.NewTagService(tags, newFakeListingRepository(first, second), bus, newFakeCache(), nil)
+
+	_, err := service.MergeTags(ctx, app.MergeTagsCommand{TargetID: iphone.ID, SourceIDs: []string{iphone.ID}})
+	assert.Error(t, err, "a tag cannot be merged into itself")
+
+	merged, err := service.MergeTags(ctx, app.MergeTagsCommand{TargetID: iphone.ID, SourceIDs: []string{iPhones.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, iphone.ID, merged.ID)
+
+	_, err = tags.FindByID(iPhones.ID)
+	assert.Error(t, err, "merged tags are deleted")
+	listingIDs, err := tags.FindListingIDs(iphone.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []ids.ListingID{first.ID, second.ID}, listingIDs)
+	assert.Len(t, bus.eventsOfType(domain.ListingChangedEvent), 2)
+
+	// Renaming into an existing name is refused; merging is the way
+	name := "iPhone"
+	_, err = service.UpdateTag(ctx, app.UpdateTagCommand{TagID: other.ID, Name: &name})
+	assert.Error(t, err)
+}
+
+func TestTagService_SuggestTags(t *testing.T) {
+	ctx := context.Background()
+	iphone, unlocked, used := newTag(t, "iphone"), newTag(t, "unlocked"), newTag(t, "used phone")
+	tags := newFakeTagRepository(iphone, unlocked, used)
+	listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	require.NoError(t, tags.SetListingTags(listing.ID, []string{unlocked.ID}))
+	service := app.NewTagService(tags, newFakeListingRepository(listing), &fakeEventBus{}, newFakeCache(), nil)
+
+	suggestions, err := service.SuggestTags(ctx, app.TagSuggestionsQuery{Title: "Used phone, iPhone 12!"})
+	require.NoError(t, err)
+	require.Len(t, suggestions, 3)
+	assert.ElementsMatch(t, []string{"iphone", "used phone"}, []string{suggestions[0].Name, suggestions[1].Name}, "tags named in the title come first")
+	assert.Equal(t, "unlocked", suggestions[2].Name)
+
+	suggestions, err = service.SuggestTags(ctx, app.TagSuggestionsQuery{Query: "Un"})
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, unlocked.ID, suggestions[0].ID)
+}
+
+func TestTagService_TrendingTagsAreCached(t *testing.T) {
+	ctx := context.Background()
+	iphone := newTag(t, "iphone")
+	tags := newFakeTagRepository(iphone)
+	listing := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	require.NoError(t, tags.SetListingTags(listing.ID, []string{iphone.ID}))
+	service := app.NewTagService(tags, newFakeListingRepository(listing), &fakeEventBus{}, newFakeCache(), nil)
+
+	trending, err := service.TrendingTags(ctx, app.TrendingTagsQuery{})
+	require.NoError(t, err)
+	require.Len(t, trending, 1)
+	assert.Equal(t, int64(1), trending[0].Listings)
+
+	_, err = service.TrendingTags(ctx, app.TrendingTagsQuery{Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 1, tags.trending)
+
+	// Deleting a tag refreshes them
+	require.NoError(t, service.DeleteTag(ctx, iphone.ID))
+	trending, err = service.TrendingTags(ctx, app.TrendingTagsQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, trending)
+	assert.Equal(t, 2, tags.trending)
+}
+
+func TestListingService_SearchListingsByTag(t *testing.T) {
+	iphone := newTag(t, "iphone")
+	tagged := newActiveListing(t, "seller-a", "iPhone 13", domain.ConditionGood)
+	tagged.Tags = []domain.ListingTag{*iphone}
+	untagged := newActiveListing(t, "seller-b", "Galaxy S22", domain.ConditionGood)
+	service := app.NewListingService(newFakeListingRepository(tagged, untagged), nil, &fakeEventBus{}, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	results, err := service.SearchListings(context.Background(), app.SearchListingsQuery{Tag: "#iPhone"})
+	require.NoError(t, err)
+	require.Len(t, results.Listings, 1)
+	assert.Equal(t, tagged.ID, results.Listings[0].ID)
+}
@@ -0,0 +1,365 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// SellerDirectory answers questions about seller accounts owned by the users context
+type SellerDirectory interface {
+	IsVerifiedSeller(ctx context.Context, userID ids.UserID) (bool, error)
+}
+
+// RequestTransferCommand represents the command to transfer a listing or a whole storefront.
+// Leaving ListingID empty transfers every listing of the current owner.
+type RequestTransferCommand struct {
+	ListingID    ids.ListingID `json:"listing_id"`
+	FromSellerID ids.UserID    `json:"from_seller_id"`
+	ToSellerID   ids.UserID    `json:"to_seller_id" binding:"required"`
+	Note         string        `json:"note"`
+	RequestedBy  ids.UserID    `json:"-"`
+	IsAdmin      bool          `json:"-"`
+}
+
+// OwnershipTransferService handles listing ownership transfer use cases
+type OwnershipTransferService struct {
+	listingRepo  domain.ListingRepository
+	transferRepo domain.OwnershipTransferRepository
+	sellers      SellerDirectory
+	eventBus     events.EventBus
+}
+
+// NewOwnershipTransferService creates a new ownership transfer service
+func NewOwnershipTransferService(
+	listingRepo domain.ListingRepository,
+	transferRepo domain.OwnershipTransferRepository,
+	sellers SellerDirectory,
+	eventBus events.EventBus,
+) *OwnershipTransferService {
+	return &OwnershipTransferService{
+		listingRepo:  listingRepo,
+		transferRepo: transferRepo,
+		sellers:      sellers,
+		eventBus:     eventBus,
+	}
+}
+
+// RequestTransfer proposes a transfer that completes once both sellers confirm
+func (s *OwnershipTransferService) RequestTransfer(ctx context.Context, cmd RequestTransferCommand) (*domain.OwnershipTransfer, error) {
+	// Sellers can only give away what they own; admins act on behalf of a seller
+	fromSellerID := cmd.RequestedBy
+	if cmd.IsAdmin {
+		if cmd.FromSellerID == "" {
+			return nil, errors.ValidationError("from_seller_id is required")
+		}
+		fromSellerID = cmd.FromSellerID
+	} else if cmd.FromSellerID != "" && cmd.FromSellerID != cmd.RequestedBy {
+		return nil, errors.ForbiddenError("cannot transfer another seller's listings")
+	}
+
+	if err := s.ensureVerifiedSeller(ctx, cmd.ToSellerID); err != nil {
+		return nil, err
+	}
+
+	var listingID *ids.ListingID
+	if cmd.ListingID != "" {
+		listing, err := s.listingRepo.FindByID(cmd.ListingID)
+		if err != nil {
+			return nil, err
+		}
+		if listing.SellerID != fromSellerID {
+			return nil, errors.ForbiddenError("listing does not belong to the seller")
+		}
+		if listing.Status == domain.ListingStatusSold {
+			return nil, errors.ValidationError("cannot transfer a sold listing")
+		}
+		listingID = &listing.ID
+	}
+
+	// Only one pending transfer may cover a listing at a time
+	pending, err := s.transferRepo.FindPendingBySeller(fromSellerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range pending {
+		if existing.FromSellerID != fromSellerID || existing.IsExpired() {
+			continue
+		}
+		if existing.Scope == domain.TransferScopeStorefront || listingID == nil || *existing.ListingID == *listingID {
+			return nil, errors.ConflictError("a transfer is already pending for these listings")
+		}
+	}
+
+	transfer, err := domain.NewOwnershipTransfer(listingID, fromSellerID, cmd.ToSellerID, cmd.RequestedBy, cmd.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.transferRepo.Save(transfer) }); err != nil {
+		return nil, err
+	}
+
+	// Publish ListingTransferRequested event so both parties are notified
+	event, err := events.NewEvent(
+		domain.ListingTransferRequestedEvent,
+		transfer.ID,
+		domain.ListingTransferRequested{
+			TransferID:   transfer.ID,
+			Scope:        transfer.Scope,
+			ListingID:    transfer.ListingID,
+			FromSellerID: transfer.FromSellerID,
+			ToSellerID:   transfer.ToSellerID,
+			InitiatedBy:  transfer.InitiatedBy,
+			ExpiresAt:    transfer.ExpiresAt,
+			Timestamp:    time.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// ConfirmTransfer records a seller's confirmation and carries out the transfer
+// once both sellers have confirmed
+func (s *OwnershipTransferService) ConfirmTransfer(ctx context.Context, transferID string, sellerID ids.UserID) (*domain.OwnershipTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transfer.Confirm(sellerID); err != nil {
+		return nil, err
+	}
+
+	if !transfer.IsConfirmed() {
+		if err := db.WithRetry(ctx, func() error { return s.transferRepo.Update(transfer) }); err != nil {
+			return nil, err
+		}
+		return transfer, nil
+	}
+
+	// The recipient may have lost their verification since the transfer was requested
+	if err := s.ensureVerifiedSeller(ctx, transfer.ToSellerID); err != nil {
+		return nil, err
+	}
+
+	listingIDs, err := s.moveListings(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transfer.Complete(len(listingIDs)); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.transferRepo.Update(transfer) }); err != nil {
+		return nil, err
+	}
+
+	// Publish ListingTransferCompleted event
+	event, err := events.NewEvent(
+		domain.ListingTransferCompletedEvent,
+		transfer.ID,
+		domain.ListingTransferCompleted{
+			TransferID:   transfer.ID,
+			Scope:        transfer.Scope,
+			FromSellerID: transfer.FromSellerID,
+			ToSellerID:   transfer.ToSellerID,
+			ListingIDs:   listingIDs,
+			Timestamp:    time.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// RejectTransfer declines a transfer on behalf of the recipient
+func (s *OwnershipTransferService) RejectTransfer(ctx context.Context, transferID string, sellerID ids.UserID) (*domain.OwnershipTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transfer.Reject(sellerID); err != nil {
+		return nil, err
+	}
+
+	return transfer, s.closeTransfer(ctx, transfer)
+}
+
+// CancelTransfer withdraws a transfer on behalf of the current owner or the initiator
+func (s *OwnershipTransferService) CancelTransfer(ctx context.Context, transferID string, userID ids.UserID) (*domain.OwnershipTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transfer.Cancel(userID); err != nil {
+		return nil, err
+	}
+
+	return transfer, s.closeTransfer(ctx, transfer)
+}
+
+// GetTransfer retrieves a transfer visible to the given user
+func (s *OwnershipTransferService) GetTransfer(ctx context.Context, transferID string, userID ids.UserID, isAdmin bool) (*domain.OwnershipTransfer, error) {
+	transfer, err := s.transferRepo.FindByID(transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAdmin && !transfer.Involves(userID) {
+		return nil, errors.NotFoundError("transfer not found")
+	}
+
+	return transfer, nil
+}
+
+// ListPendingTransfers lists the pending transfers a seller is involved in
+func (s *OwnershipTransferService) ListPendingTransfers(ctx context.Context, sellerID ids.UserID) ([]*domain.OwnershipTransfer, error) {
+	transfers, err := s.transferRepo.FindPendingBySeller(sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]*domain.OwnershipTransfer, 0, len(transfers))
+	for _, transfer := range transfers {
+		if !transfer.IsExpired() {
+			pending = append(pending, transfer)
+		}
+	}
+	return pending, nil
+}
+
+// GetOwnershipHistory retrieves the previous owners of a listing
+func (s *OwnershipTransferService) GetOwnershipHistory(ctx context.Context, listingID ids.ListingID) ([]*domain.OwnershipRecord, error) {
+	if _, err := s.listingRepo.FindByID(listingID); err != nil {
+		return nil, err
+	}
+	return s.transferRepo.FindHistory(listingID)
+}
+
+// moveListings reassigns the listings covered by the transfer and records their history
+func (s *OwnershipTransferService) moveListings(ctx context.Context, transfer *domain.OwnershipTransfer) ([]ids.ListingID, error) {
+	if transfer.Scope == domain.TransferScopeListing {
+		listing, err := s.listingRepo.FindByID(*transfer.ListingID)
+		if err != nil {
+			return nil, err
+		}
+		if listing.SellerID != transfer.FromSellerID {
+			return nil, errors.ConflictError("listing no longer belongs to the seller")
+		}
+		if err := s.moveListing(ctx, transfer, listing); err != nil {
+			return nil, err
+		}
+		return []ids.ListingID{listing.ID}, nil
+	}
+
+	var listingIDs []ids.ListingID
+	for {
+		// Moved listings drop out of the result set, so always read the first page
+		listings, err := s.listingRepo.FindBySeller(transfer.FromSellerID, sellerBatchSize, 0)
+		if err != nil {
+			return listingIDs, err
+		}
+
+		for _, listing := range listings {
+			if err := s.moveListing(ctx, transfer, listing); err != nil {
+				return listingIDs, err
+			}
+			listingIDs = append(listingIDs, listing.ID)
+		}
+
+		if len(listings) < sellerBatchSize {
+			return listingIDs, nil
+		}
+	}
+}
+
+// moveListing reassigns one listing, records its history and publishes ListingOwnerChanged
+func (s *OwnershipTransferService) moveListing(ctx context.Context, transfer *domain.OwnershipTransfer, listing *domain.Listing) error {
+	if err := listing.ReassignSeller(transfer.ToSellerID); err != nil {
+		return err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.listingRepo.Update(listing) }); err != nil {
+		return err
+	}
+
+	record := domain.NewOwnershipRecord(listing.ID, transfer.ID, transfer.FromSellerID, transfer.ToSellerID)
+	if err := db.WithRetry(ctx, func() error { return s.transferRepo.SaveRecord(record) }); err != nil {
+		return err
+	}
+
+	event, err := events.NewEvent(
+		domain.ListingOwnerChangedEvent,
+		listing.ID.String(),
+		domain.ListingOwnerChanged{
+			ListingID:    listing.ID,
+			TransferID:   transfer.ID,
+			FromSellerID: transfer.FromSellerID,
+			ToSellerID:   transfer.ToSellerID,
+			Timestamp:    time.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// closeTransfer persists a rejected or cancelled transfer and publishes ListingTransferCancelled
+func (s *OwnershipTransferService) closeTransfer(ctx context.Context, transfer *domain.OwnershipTransfer) error {
+	if err := db.WithRetry(ctx, func() error { return s.transferRepo.Update(transfer) }); err != nil {
+		return err
+	}
+
+	event, err := events.NewEvent(
+		domain.ListingTransferCancelledEvent,
+		transfer.ID,
+		domain.ListingTransferCancelled{
+			TransferID:   transfer.ID,
+			FromSellerID: transfer.FromSellerID,
+			ToSellerID:   transfer.ToSellerID,
+			Status:       transfer.Status,
+			Timestamp:    time.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// ensureVerifiedSeller rejects transfers to accounts that are not verified sellers
+func (s *OwnershipTransferService) ensureVerifiedSeller(ctx context.Context, sellerID ids.UserID) error {
+	verified, err := s.sellers.IsVerifiedSeller(ctx, sellerID)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return errors.ValidationError("recipient must be a verified seller")
+	}
+	return nil
+}
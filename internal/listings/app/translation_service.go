@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+)
+
+// translationsTTL is how long the translations of a locale stay cached.
+// Changes made through TranslationService and CategoryService evict them
+// straight away.
+const translationsTTL = time.Hour
+
+// translationsKey is the cache key of the translations of a locale
+func translationsKey(locale i18n.Locale) string {
+	return "translations:" + string(locale)
+}
+
+// SetCategoryTranslationCommand represents the command to translate a category
+type SetCategoryTranslationCommand struct {
+	CategoryID  string `json:"-"`
+	Locale      string `json:"-"`
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// SetAttributeLabelCommand represents the command to label an attribute in a locale
+type SetAttributeLabelCommand struct {
+	Key    string `json:"-"`
+	Locale string `json:"-"`
+	Label  string `json:"label" binding:"required"`
+}
+
+// TranslationService handles translating category names and attribute
+// labels, and resolving them in the locale responses are written in
+type TranslationService struct {
+	translationRepo domain.TranslationRepository
+	categoryRepo    domain.CategoryRepository
+	cache           cache.Cache
+}
+
+// NewTranslationService creates a new translation service
+func NewTranslationService(translationRepo domain.TranslationRepository, categoryRepo domain.CategoryRepository, cache cache.Cache) *TranslationService {
+	return &TranslationService{
+		translationRepo: translationRepo,
+		categoryRepo:    categoryRepo,
+		cache:           cache,
+	}
+}
+
+// Translations resolves every category name and attribute label in a
+// locale, from the cache when possible. Cache failures fall back to the
+// database.
+func (s *TranslationService) Translations(ctx context.Context, locale i18n.Locale) (*domain.Translations, error) {
+	var cached domain.Translations
+	if err := s.cache.Get(ctx, translationsKey(locale), &cached); err == nil {
+		return &cached, nil
+	}
+
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	var translations []*domain.CategoryTranslation
+	if locale != i18n.DefaultLocale {
+		if translations, err = s.translationRepo.FindCategoryTranslations(string(locale)); err != nil {
+			return nil, err
+		}
+	}
+	labels, err := s.translationRepo.FindAttributeLabels(string(i18n.DefaultLocale))
+	if err != nil {
+		return nil, err
+	}
+	if locale != i18n.DefaultLocale {
+		localized, err := s.translationRepo.FindAttributeLabels(string(locale))
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, localized...)
+	}
+
+	resolved := domain.BuildTranslations(locale, categories, translations, labels)
+	_ = s.cache.Set(ctx, translationsKey(locale), resolved, translationsTTL)
+	return resolved, nil
+}
+
+// LocalizeListings fills in the category name and attribute labels of
+// listings in a locale. Listings are left untranslated if the translations
+// cannot be loaded, since they are still usable.
+func (s *TranslationService) LocalizeListings(ctx context.Context, locale i18n.Locale, listings ...*domain.Listing) {
+	translations, err := s.Translations(ctx, locale)
+	if err != nil {
+		return
+	}
+	translations.LocalizeListings(listings)
+}
+
+// ListCategoryTranslations lists the translations of a category
+func (s *TranslationService) ListCategoryTranslations(ctx context.Context, categoryID string) ([]*domain.CategoryTranslation, error) {
+	if _, err := s.categoryRepo.FindByID(categoryID); err != nil {
+		return nil, err
+	}
+	return s.translationRepo.FindCategoryTranslationsByCategory(categoryID)
+}
+
+// SetCategoryTranslation creates or replaces the translation of a category
+func (s *TranslationService) SetCategoryTranslation(ctx context.Context, cmd SetCategoryTranslationCommand) (*domain.CategoryTranslation, error) {
+	if _, err := s.categoryRepo.FindByID(cmd.CategoryID); err != nil {
+		return nil, err
+	}
+
+	translation, err := domain.NewCategoryTranslation(cmd.CategoryID, cmd.Locale, cmd.Name, cmd.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.translationRepo.SaveCategoryTranslation(translation) }); err != nil {
+		return nil, err
+	}
+	invalidateLocalized(ctx, s.cache)
+	return translation, nil
+}
+
+// DeleteCategoryTranslation removes the translation of a category, so its
+// name in the default locale is shown instead
+func (s *TranslationService) DeleteCategoryTranslation(ctx context.Context, categoryID, locale string) error {
+	parsed, ok := i18n.ParseLocale(locale)
+	if !ok {
+		return errors.ValidationError("unsupported locale")
+	}
+	if err := db.WithRetry(ctx, func() error { return s.translationRepo.DeleteCategoryTranslation(categoryID, string(parsed)) }); err != nil {
+		return err
+	}
+	invalidateLocalized(ctx, s.cache)
+	return nil
+}
+
+// ListAttributeLabels lists attribute labels, in every locale when locale is empty
+func (s *TranslationService) ListAttributeLabels(ctx context.Context, locale string) ([]*domain.AttributeLabel, error) {
+	if locale == "" {
+		return s.translationRepo.FindAttributeLabels("")
+	}
+	parsed, ok := i18n.ParseLocale(locale)
+	if !ok {
+		return nil, errors.ValidationError("unsupported locale")
+	}
+	return s.translationRepo.FindAttributeLabels(string(parsed))
+}
+
+// SetAttributeLabel creates or replaces the label of an attribute in a locale
+func (s *TranslationService) SetAttributeLabel(ctx context.Context, cmd SetAttributeLabelCommand) (*domain.AttributeLabel, error) {
+	label, err := domain.NewAttributeLabel(cmd.Key, cmd.Locale, cmd.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.translationRepo.SaveAttributeLabel(label) }); err != nil {
+		return nil, err
+	}
+	invalidateLocalized(ctx, s.cache)
+	return label, nil
+}
+
+// DeleteAttributeLabel removes the label of an attribute in a locale
+func (s *TranslationService) DeleteAttributeLabel(ctx context.Context, key, locale string) error {
+	parsed, ok := i18n.ParseLocale(locale)
+	if !ok {
+		return errors.ValidationError("unsupported locale")
+	}
+	key = strings.ToLower(strings.TrimSpace(key))
+	if err := db.WithRetry(ctx, func() error { return s.translationRepo.DeleteAttributeLabel(key, string(parsed)) }); err != nil {
+		return err
+	}
+	invalidateLocalized(ctx, s.cache)
+	return nil
+}
+
+// invalidateLocalized evicts the cached category trees and translations of
+// every locale. A failed eviction is corrected when the cached values expire.
+func invalidateLocalized(ctx context.Context, c cache.Cache) {
+	for _, locale := range i18n.SupportedLocales {
+		_ = c.Delete(ctx, categoryTreeKey(locale))
+		_ = c.Delete(ctx, translationsKey(locale))
+	}
+}
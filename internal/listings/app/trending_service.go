@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/clock"
+	"dongome/pkg/ids"
+)
+
+// TrendingListingsKey is the cache key of the ranked trending listings
+const TrendingListingsKey = "listings:trending"
+
+// trendingListingsTTL is how long trending listings stay cached. The worker
+// ranks them again well before, so the cache only runs dry when it stops.
+const trendingListingsTTL = time.Hour
+
+// Limits on trending listings
+const (
+	// trendingRankLimit is how many of the hottest listings are ranked and
+	// cached, leaving room for those sold since
+	trendingRankLimit = 200
+	// defaultTrendingLimit is how many are shown when the query does not say
+	defaultTrendingLimit = 20
+)
+
+// TrendingListingsQuery represents how many trending listings to show
+type TrendingListingsQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// TrendingListingService ranks the listings buyers are showing the most
+// interest in right now, by their recent views, favorites and messages
+type TrendingListingService struct {
+	statsRepo   domain.ListingStatsRepository
+	listingRepo domain.ListingRepository
+	cache       cache.Cache
+	clock       clock.Clock
+}
+
+// NewTrendingListingService creates a new trending listing service
+func NewTrendingListingService(statsRepo domain.ListingStatsRepository, listingRepo domain.ListingRepository, cache cache.Cache, clk clock.Clock) *TrendingListingService {
+	return &TrendingListingService{
+		statsRepo:   statsRepo,
+		listingRepo: listingRepo,
+		cache:       cache,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// RefreshTrending ranks the active listings by their activity in the
+// trending window and caches the hottest, returning how many were ranked
+func (s *TrendingListingService) RefreshTrending(ctx context.Context) (int, error) {
+	ranked, err := s.rankTrending()
+	if err != nil {
+		return 0, err
+	}
+	if err := s.cache.Set(ctx, TrendingListingsKey, ranked, trendingListingsTTL); err != nil {
+		return 0, err
+	}
+	return len(ranked), nil
+}
+
+// TrendingListings returns the hottest active listings, hottest first, as
+// last ranked by the worker. They are ranked on the spot when the cache is
+// empty or cannot be reached.
+func (s *TrendingListingService) TrendingListings(ctx context.Context, query TrendingListingsQuery) ([]domain.TrendingListing, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultTrendingLimit
+	}
+
+	var ranked []domain.TrendingScore
+	if err := s.cache.Get(ctx, TrendingListingsKey, &ranked); err != nil {
+		ranked, err = s.rankTrending()
+		if err != nil {
+			return nil, err
+		}
+		_ = s.cache.Set(ctx, TrendingListingsKey, ranked, trendingListingsTTL)
+	}
+
+	listingIDs := make([]ids.ListingID, 0, len(ranked))
+	for _, score := range ranked {
+		listingIDs = append(listingIDs, score.ListingID)
+	}
+	found, err := s.listingRepo.FindByIDs(listingIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[ids.ListingID]*domain.Listing, len(found))
+	for _, listing := range found {
+		byID[listing.ID] = listing
+	}
+
+	trending := make([]domain.TrendingListing, 0, limit)
+	for _, score := range ranked {
+		// Listings sold or withdrawn since they were ranked are left out
+		listing, ok := byID[score.ListingID]
+		if !ok || !listing.IsActive() {
+			continue
+		}
+		trending = append(trending, domain.TrendingListing{Listing: listing, Score: score.Score})
+		if len(trending) == limit {
+			break
+		}
+	}
+	return trending, nil
+}
+
+// rankTrending scores the active listings by their daily stats
+func (s *TrendingListingService) rankTrending() ([]domain.TrendingScore, error) {
+	now := s.clock.Now()
+	stats, err := s.statsRepo.FindActiveSince(domain.AnalyticsDay(now.Add(-domain.TrendingWindow)))
+	if err != nil {
+		return nil, err
+	}
+	return domain.RankTrending(stats, now, trendingRankLimit), nil
+}
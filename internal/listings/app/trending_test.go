@@ -0,0 +1,71 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrendingListingService_TrendingListings(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFrozen(time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC))
+	phone := newActiveListing(t, "seller-a", "Phone", domain.ConditionGood)
+	laptop := newActiveListing(t, "seller-b", "Laptop", domain.ConditionGood)
+	bag := newActiveListing(t, "seller-c", "Bag", domain.ConditionGood)
+	quiet := newActiveListing(t, "seller-d", "Shoes", domain.ConditionGood)
+
+	statsRepo := newFakeListingStatsRepository()
+	record := func(listing *domain.Listing, activity domain.ListingActivity, daysAgo int) {
+		at := clk.Now().AddDate(0, 0, -daysAgo)
+		_, err := statsRepo.Record("", listing.ID, listing.SellerID, domain.AnalyticsDay(at), activity, at)
+		require.NoError(t, err)
+	}
+	record(phone, domain.ListingActivity{Views: 20, Favorites: 2}, 0)
+	record(laptop, domain.ListingActivity{Views: 5}, 0)
+	record(bag, domain.ListingActivity{Messages: 1}, 1)
+	record(quiet, domain.ListingActivity{Views: 500}, 10)
+
+	cache := newFakeCache()
+	service := app.NewTrendingListingService(statsRepo, newFakeListingRepository(phone, laptop, bag, quiet), cache, clk)
+
+	ranked, err := service.RefreshTrending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, ranked, "activity from before the window does not count")
+
+	trending, err := service.TrendingListings(ctx, app.TrendingListingsQuery{})
+	require.NoError(t, err)
+	require.Len(t, trending, 3)
+	assert.Equal(t, phone.ID, trending[0].Listing.ID)
+	assert.Equal(t, 30.0, trending[0].Score)
+	assert.Equal(t, bag.ID, trending[1].Listing.ID)
+	assert.Equal(t, laptop.ID, trending[2].Listing.ID)
+
+	// The ranking is served from the cache until the worker refreshes it,
+	// and listings sold since are dropped
+	record(laptop, domain.ListingActivity{Messages: 10}, 0)
+	require.NoError(t, bag.MarkAsSold())
+	trending, err = service.TrendingListings(ctx, app.TrendingListingsQuery{})
+	require.NoError(t, err)
+	require.Len(t, trending, 2)
+	assert.Equal(t, phone.ID, trending[0].Listing.ID)
+
+	_, err = service.RefreshTrending(ctx)
+	require.NoError(t, err)
+	trending, err = service.TrendingListings(ctx, app.TrendingListingsQuery{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, trending, 1)
+	assert.Equal(t, laptop.ID, trending[0].Listing.ID)
+
+	// Without a cached ranking the listings are ranked on the spot
+	require.NoError(t, cache.Delete(ctx, app.TrendingListingsKey))
+	trending, err = service.TrendingListings(ctx, app.TrendingListingsQuery{})
+	require.NoError(t, err)
+	assert.Len(t, trending, 2)
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"dongome/pkg/ids"
+)
+
+// MaxAnalyticsDays is the longest period seller analytics cover at once
+const MaxAnalyticsDays = 90
+
+// AnalyticsDayFormat is how days are written in seller analytics
+const AnalyticsDayFormat = "2006-01-02"
+
+// ListingActivity counts what buyers did with a listing. Favorites are net
+// of the favorites taken back.
+type ListingActivity struct {
+	Views     int64 `gorm:"not null;default:0" json:"views"`
+	Favorites int64 `gorm:"not null;default:0" json:"favorites"`
+	Messages  int64 `gorm:"not null;default:0" json:"messages"`
+	Orders    int64 `gorm:"not null;default:0" json:"orders"`
+}
+
+// Conversion is the share of views that became paid orders
+func (a ListingActivity) Conversion() float64 {
+	if a.Views <= 0 {
+		return 0
+	}
+	return math.Round(float64(a.Orders)/float64(a.Views)*10000) / 10000
+}
+
+// IsZero checks if nothing was counted
+func (a ListingActivity) IsZero() bool {
+	return a == ListingActivity{}
+}
+
+func (a *ListingActivity) add(other ListingActivity) {
+	a.Views += other.Views
+	a.Favorites += other.Favorites
+	a.Messages += other.Messages
+	a.Orders += other.Orders
+}
+
+// ListingDailyStats is the analytics read model of a listing: what buyers did
+// with it on one day. It is projected from events by the worker, so seller
+// analytics never query the transactional tables.
+type ListingDailyStats struct {
+	ListingID       ids.ListingID `gorm:"type:uuid;primary_key" json:"listing_id"`
+	Day             time.Time     `gorm:"type:date;primary_key" json:"day"`
+	SellerID        ids.UserID    `gorm:"type:uuid;not null" json:"seller_id"`
+	ListingActivity `gorm:"embedded"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName returns the table of listing daily stats
+func (ListingDailyStats) TableName() string {
+	return "listing_daily_stats"
+}
+
+// AnalyticsDay returns the day, in UTC, that activity at the given time
+// counts toward
+func AnalyticsDay(at time.Time) time.Time {
+	year, month, day := at.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// ActivitySummary is activity with its conversion
+type ActivitySummary struct {
+	ListingActivity
+	Conversion float64 `json:"conversion"`
+}
+
+// DailyActivity is the activity of one day
+type DailyActivity struct {
+	Day string `json:"day"`
+	ActivitySummary
+}
+
+// ListingAnalytics is the activity of one of a seller's listings over a
+// period, in total and on each day something happened
+type ListingAnalytics struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	ActivitySummary
+	Days []DailyActivity `json:"days"`
+}
+
+// SellerAnalytics is the activity of a seller's listings over a period: in
+// total and on every day of it, and for each listing with any activity, most
+// viewed first
+type SellerAnalytics struct {
+	SellerID ids.UserID         `json:"seller_id"`
+	From     string             `json:"from"`
+	To       string             `json:"to"`
+	Totals   ActivitySummary    `json:"totals"`
+	Days     []DailyActivity    `json:"days"`
+	Listings []ListingAnalytics `json:"listings"`
+}
+
+// NewSellerAnalytics sums up the daily stats of a seller's listings from one
+// day to another, both included
+func NewSellerAnalytics(sellerID ids.UserID, from, to time.Time, stats []*ListingDailyStats) *SellerAnalytics {
+	from, to = AnalyticsDay(from), AnalyticsDay(to)
+	analytics := &SellerAnalytics{
+		SellerID: sellerID,
+		From:     from.Format(AnalyticsDayFormat),
+		To:       to.Format(AnalyticsDayFormat),
+		Days:     []DailyActivity{},
+		Listings: []ListingAnalytics{},
+	}
+
+	var totals ListingActivity
+	byDay := make(map[string]ListingActivity)
+	byListing := make(map[ids.ListingID]*ListingActivity)
+	listingDays := make(map[ids.ListingID][]DailyActivity)
+	for _, stat := range stats {
+		day := AnalyticsDay(stat.Day)
+		if day.Before(from) || day.After(to) || stat.IsZero() {
+			continue
+		}
+		key := day.Format(AnalyticsDayFormat)
+
+		totals.add(stat.ListingActivity)
+		dayTotal := byDay[key]
+		dayTotal.add(stat.ListingActivity)
+		byDay[key] = dayTotal
+		if byListing[stat.ListingID] == nil {
+			byListing[stat.ListingID] = &ListingActivity{}
+		}
+		byListing[stat.ListingID].add(stat.ListingActivity)
+		listingDays[stat.ListingID] = append(listingDays[stat.ListingID], DailyActivity{Day: key, ActivitySummary: summarize(stat.ListingActivity)})
+	}
+
+	analytics.Totals = summarize(totals)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(AnalyticsDayFormat)
+		analytics.Days = append(analytics.Days, DailyActivity{Day: key, ActivitySummary: summarize(byDay[key])})
+	}
+	for listingID, activity := range byListing {
+		days := listingDays[listingID]
+		sort.Slice(days, func(i, j int) bool { return days[i].Day < days[j].Day })
+		analytics.Listings = append(analytics.Listings, ListingAnalytics{
+			ListingID:       listingID,
+			ActivitySummary: summarize(*activity),
+			Days:            days,
+		})
+	}
+	sort.Slice(analytics.Listings, func(i, j int) bool {
+		a, b := analytics.Listings[i], analytics.Listings[j]
+		if a.Views != b.Views {
+			return a.Views > b.Views
+		}
+		return a.ListingID < b.ListingID
+	})
+	return analytics
+}
+
+func summarize(activity ListingActivity) ActivitySummary {
+	return ActivitySummary{ListingActivity: activity, Conversion: activity.Conversion()}
+}
+
+// ListingStatsRepository defines the interface for the persistence of the
+// listing analytics read model
+type ListingStatsRepository interface {
+	// Record adds an event's activity to a listing's stats for the day,
+	// creating them on the day's first activity, and reports whether it did.
+	// An event already recorded is not counted again; activity without an
+	// event ID always is.
+	Record(eventID string, listingID ids.ListingID, sellerID ids.UserID, day time.Time, activity ListingActivity, at time.Time) (bool, error)
+	// FindBySeller finds the daily stats of a seller's listings from one day
+	// to another, both included
+	FindBySeller(sellerID ids.UserID, from, to time.Time) ([]*ListingDailyStats, error)
+	// FindActiveSince finds the daily stats of active listings from a day on
+	FindActiveSince(from time.Time) ([]*ListingDailyStats, error)
+}
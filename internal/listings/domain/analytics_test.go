@@ -0,0 +1,45 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSellerAnalytics(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC) }
+	stats := []*domain.ListingDailyStats{
+		{ListingID: "phone", Day: day(1), ListingActivity: domain.ListingActivity{Views: 40, Favorites: 3, Messages: 2, Orders: 1}},
+		{ListingID: "phone", Day: day(3), ListingActivity: domain.ListingActivity{Views: 60, Favorites: -1, Messages: 1, Orders: 1}},
+		{ListingID: "laptop", Day: day(3), ListingActivity: domain.ListingActivity{Views: 150}},
+		{ListingID: "laptop", Day: day(9), ListingActivity: domain.ListingActivity{Views: 500}},
+		{ListingID: "empty", Day: day(2)},
+	}
+
+	analytics := domain.NewSellerAnalytics("seller-a", day(1).Add(15*time.Hour), day(3), stats)
+	assert.Equal(t, "2026-03-01", analytics.From)
+	assert.Equal(t, "2026-03-03", analytics.To)
+	assert.Equal(t, domain.ListingActivity{Views: 250, Favorites: 2, Messages: 3, Orders: 2}, analytics.Totals.ListingActivity)
+	assert.Equal(t, 0.008, analytics.Totals.Conversion)
+
+	// Every day of the period is there, quiet ones included
+	require.Len(t, analytics.Days, 3)
+	assert.Equal(t, "2026-03-02", analytics.Days[1].Day)
+	assert.Zero(t, analytics.Days[1].Views)
+	assert.Equal(t, int64(210), analytics.Days[2].Views)
+
+	// Listings without activity in the period are left out, most viewed first
+	require.Len(t, analytics.Listings, 2)
+	assert.Equal(t, "laptop", analytics.Listings[0].ListingID.String())
+	assert.Zero(t, analytics.Listings[0].Conversion)
+	phone := analytics.Listings[1]
+	assert.Equal(t, int64(100), phone.Views)
+	assert.Equal(t, 0.02, phone.Conversion)
+	require.Len(t, phone.Days, 2)
+	assert.Equal(t, "2026-03-01", phone.Days[0].Day)
+	assert.Equal(t, 0.025, phone.Days[0].Conversion)
+}
@@ -0,0 +1,323 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// BulkOperationKind is what a bulk operation does to each listing it covers
+type BulkOperationKind string
+
+const (
+	// BulkSuspendSellerListings takes every live listing of a seller down
+	BulkSuspendSellerListings BulkOperationKind = "suspend_seller_listings"
+	// BulkRestoreSellerListings puts a seller's suspended listings back live
+	BulkRestoreSellerListings BulkOperationKind = "restore_seller_listings"
+	// BulkReindexCategory refreshes the search index's copies of a category's listings
+	BulkReindexCategory BulkOperationKind = "reindex_category"
+	// BulkDeactivateListings takes the selected listings off the marketplace
+	BulkDeactivateListings BulkOperationKind = "deactivate_listings"
+	// BulkDeleteListings deletes the selected listings for good
+	BulkDeleteListings BulkOperationKind = "delete_listings"
+	// BulkMoveCategory moves the selected listings to another category
+	BulkMoveCategory BulkOperationKind = "move_category"
+)
+
+// BulkOperationKinds lists the kinds of bulk operation administrators can submit
+var BulkOperationKinds = []BulkOperationKind{
+	BulkSuspendSellerListings, BulkRestoreSellerListings, BulkReindexCategory,
+	BulkDeactivateListings, BulkDeleteListings, BulkMoveCategory,
+}
+
+// MaxBulkListingIDs is the most listings a bulk operation can pick by ID
+const MaxBulkListingIDs = 1000
+
+// IsValid checks if the kind is one administrators can submit
+func (k BulkOperationKind) IsValid() bool {
+	for _, kind := range BulkOperationKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectsListings checks if operations of the kind cover listings picked by
+// ID or by filter rather than those of one seller or category
+func (k BulkOperationKind) SelectsListings() bool {
+	return k == BulkDeactivateListings || k == BulkDeleteListings || k == BulkMoveCategory
+}
+
+// BulkListingFilter picks the listings a bulk operation covers by what they
+// are. Listings must match every criterion set.
+type BulkListingFilter struct {
+	SellerID   ids.UserID    `json:"seller_id,omitempty"`
+	CategoryID string        `json:"category_id,omitempty"`
+	Status     ListingStatus `json:"status,omitempty"`
+	Region     string        `json:"region,omitempty"`
+}
+
+// IsEmpty checks if the filter sets no criterion, which would match every listing
+func (f BulkListingFilter) IsEmpty() bool {
+	return f.SellerID == "" && f.CategoryID == "" && f.Status == "" && f.Region == ""
+}
+
+// BulkListingSelection picks the listings a bulk operation covers, either by
+// ID or by filter
+type BulkListingSelection struct {
+	ListingIDs []ids.ListingID
+	Filter     *BulkListingFilter
+}
+
+// BulkOperationStatus represents how far a bulk operation has got
+type BulkOperationStatus string
+
+const (
+	BulkOperationPending   BulkOperationStatus = "pending"
+	BulkOperationRunning   BulkOperationStatus = "running"
+	BulkOperationCompleted BulkOperationStatus = "completed"
+	BulkOperationCancelled BulkOperationStatus = "cancelled"
+)
+
+// BulkItemStatus represents the result of a bulk operation on one listing
+type BulkItemStatus string
+
+const (
+	BulkItemPending   BulkItemStatus = "pending"
+	BulkItemSucceeded BulkItemStatus = "succeeded"
+	// BulkItemSkipped means the listing needed no change, such as a listing
+	// already down when its seller's listings are suspended
+	BulkItemSkipped BulkItemStatus = "skipped"
+	BulkItemFailed  BulkItemStatus = "failed"
+)
+
+// BulkOperation is an administrator action applied to many listings by the
+// worker, in batches, so the request submitting it returns at once. The
+// listings it covers are fixed when it starts; each gets a BulkOperationItem
+// with its result.
+type BulkOperation struct {
+	ID   string            `gorm:"type:uuid;primary_key" json:"id"`
+	Kind BulkOperationKind `gorm:"size:50;not null" json:"kind"`
+	// TargetID is the seller or category the operation covers, depending on
+	// its kind. It is empty for kinds that select listings.
+	TargetID string `gorm:"size:64;not null" json:"target_id,omitempty"`
+	// ListingIDs or Filter are the listings selected by kinds that select them
+	ListingIDs []ids.ListingID    `gorm:"type:jsonb;serializer:json" json:"listing_ids,omitempty"`
+	Filter     *BulkListingFilter `gorm:"type:jsonb;serializer:json" json:"filter,omitempty"`
+	// CategoryID is the category listings are moved to by move_category
+	CategoryID  string              `gorm:"size:64" json:"category_id,omitempty"`
+	Reason      string              `gorm:"size:500" json:"reason,omitempty"`
+	RequestedBy ids.UserID          `gorm:"type:uuid;not null" json:"requested_by"`
+	Status      BulkOperationStatus `gorm:"size:20;not null;index" json:"status"`
+	// Total is the number of listings covered, known once the operation starts
+	Total       int         `gorm:"not null;default:0" json:"total"`
+	Processed   int         `gorm:"not null;default:0" json:"processed"`
+	Succeeded   int         `gorm:"not null;default:0" json:"succeeded"`
+	Skipped     int         `gorm:"not null;default:0" json:"skipped"`
+	Failed      int         `gorm:"not null;default:0" json:"failed"`
+	StartedAt   *time.Time  `json:"started_at,omitempty"`
+	FinishedAt  *time.Time  `json:"finished_at,omitempty"`
+	CancelledBy *ids.UserID `gorm:"type:uuid" json:"cancelled_by,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// BulkOperationItem is the result of a bulk operation on one listing
+type BulkOperationItem struct {
+	OperationID string         `gorm:"type:uuid;primary_key" json:"operation_id"`
+	ListingID   ids.ListingID  `gorm:"type:uuid;primary_key" json:"listing_id"`
+	Status      BulkItemStatus `gorm:"size:20;not null" json:"status"`
+	Error       string         `json:"error,omitempty"`
+	ProcessedAt *time.Time     `json:"processed_at,omitempty"`
+}
+
+// NewBulkOperation submits a bulk operation on a seller's or a category's
+// listings, to be started by the worker
+func NewBulkOperation(kind BulkOperationKind, targetID string, requestedBy ids.UserID, reason string, now time.Time) (*BulkOperation, error) {
+	if !kind.IsValid() {
+		return nil, errors.ValidationError("unknown bulk operation kind")
+	}
+	if kind.SelectsListings() {
+		return nil, errors.ValidationError("listing IDs or a filter are required")
+	}
+	if targetID == "" {
+		return nil, errors.ValidationError("target ID is required")
+	}
+
+	operation, err := newBulkOperation(kind, requestedBy, reason, now)
+	if err != nil {
+		return nil, err
+	}
+	operation.TargetID = targetID
+	return operation, nil
+}
+
+// NewListingsBulkOperation submits a bulk operation on listings picked by ID
+// or by filter, to be started by the worker. categoryID is the category
+// move_category moves them to.
+func NewListingsBulkOperation(kind BulkOperationKind, selection BulkListingSelection, categoryID string, requestedBy ids.UserID, reason string, now time.Time) (*BulkOperation, error) {
+	if !kind.IsValid() {
+		return nil, errors.ValidationError("unknown bulk operation kind")
+	}
+	if !kind.SelectsListings() {
+		return nil, errors.ValidationError("target ID is required")
+	}
+	if err := selection.validate(); err != nil {
+		return nil, err
+	}
+	if kind == BulkMoveCategory && categoryID == "" {
+		return nil, errors.ValidationError("category ID is required")
+	}
+
+	operation, err := newBulkOperation(kind, requestedBy, reason, now)
+	if err != nil {
+		return nil, err
+	}
+	operation.ListingIDs = selection.ListingIDs
+	operation.Filter = selection.Filter
+	if kind == BulkMoveCategory {
+		operation.CategoryID = categoryID
+	}
+	return operation, nil
+}
+
+// newBulkOperation creates a pending bulk operation
+func newBulkOperation(kind BulkOperationKind, requestedBy ids.UserID, reason string, now time.Time) (*BulkOperation, error) {
+	if requestedBy == "" {
+		return nil, errors.ValidationError("requester ID is required")
+	}
+
+	return &BulkOperation{
+		ID:          uuid.New().String(),
+		Kind:        kind,
+		Reason:      reason,
+		RequestedBy: requestedBy,
+		Status:      BulkOperationPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// validate checks that the selection picks listings one way, by at most
+// MaxBulkListingIDs IDs or by a filter that sets a criterion
+func (s BulkListingSelection) validate() error {
+	switch {
+	case len(s.ListingIDs) > 0 && s.Filter != nil:
+		return errors.ValidationError("give either listing IDs or a filter, not both")
+	case len(s.ListingIDs) > MaxBulkListingIDs:
+		return errors.ValidationError("a bulk operation can pick at most 1000 listings by ID")
+	case len(s.ListingIDs) > 0:
+		return nil
+	case s.Filter == nil:
+		return errors.ValidationError("listing IDs or a filter are required")
+	case s.Filter.IsEmpty():
+		return errors.ValidationError("the filter must set at least one of seller_id, category_id, status, region")
+	}
+	if _, ok := listingTransitions[s.Filter.Status]; s.Filter.Status != "" && !ok {
+		return errors.ValidationError("status must be one of draft, active, inactive, sold, expired")
+	}
+	return nil
+}
+
+// Start records that the operation covers the given number of listings and
+// is being worked through
+func (o *BulkOperation) Start(total int, now time.Time) error {
+	if o.Status != BulkOperationPending {
+		return errors.ConflictError("only pending bulk operations can be started")
+	}
+
+	o.Status = BulkOperationRunning
+	o.Total = total
+	o.StartedAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// RecordProgress updates the operation's counts from the number of its items
+// in each status. The operation completes once no item is pending.
+func (o *BulkOperation) RecordProgress(counts map[BulkItemStatus]int, now time.Time) {
+	o.Succeeded = counts[BulkItemSucceeded]
+	o.Skipped = counts[BulkItemSkipped]
+	o.Failed = counts[BulkItemFailed]
+	o.Processed = o.Succeeded + o.Skipped + o.Failed
+	o.UpdatedAt = now
+	if o.Status == BulkOperationRunning && counts[BulkItemPending] == 0 {
+		o.Status = BulkOperationCompleted
+		o.FinishedAt = &now
+	}
+}
+
+// Cancel stops the operation before its next batch. Listings already
+// processed keep their changes.
+func (o *BulkOperation) Cancel(adminID ids.UserID, now time.Time) error {
+	if o.IsFinished() {
+		return errors.ConflictError("bulk operation has already finished")
+	}
+
+	o.Status = BulkOperationCancelled
+	o.CancelledBy = &adminID
+	o.FinishedAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// IsFinished checks if the operation has completed or was cancelled
+func (o *BulkOperation) IsFinished() bool {
+	return o.Status == BulkOperationCompleted || o.Status == BulkOperationCancelled
+}
+
+// NewBulkOperationItems creates the pending items of an operation, one per listing
+func NewBulkOperationItems(operationID string, listingIDs []ids.ListingID) []*BulkOperationItem {
+	items := make([]*BulkOperationItem, 0, len(listingIDs))
+	for _, listingID := range listingIDs {
+		items = append(items, &BulkOperationItem{
+			OperationID: operationID,
+			ListingID:   listingID,
+			Status:      BulkItemPending,
+		})
+	}
+	return items
+}
+
+// Finish records the result of the operation on the item's listing. err is
+// the failure, if any.
+func (i *BulkOperationItem) Finish(status BulkItemStatus, err error, now time.Time) {
+	i.Status = status
+	i.Error = ""
+	if err != nil {
+		i.Status = BulkItemFailed
+		i.Error = err.Error()
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			i.Error = domainErr.Message
+		}
+	}
+	i.ProcessedAt = &now
+}
+
+// BulkOperationRepository defines the interface for bulk operation persistence
+type BulkOperationRepository interface {
+	Save(operation *BulkOperation) error
+	FindByID(id string) (*BulkOperation, error)
+	// FindAll finds operations, optionally in one status, newest first
+	FindAll(status BulkOperationStatus, limit, offset int) ([]*BulkOperation, int64, error)
+	// FindUnfinished finds pending and running operations, oldest first
+	FindUnfinished(limit int) ([]*BulkOperation, error)
+	// SaveProgress stores an operation's status and counts unless it was
+	// cancelled meanwhile. It reports whether they were stored.
+	SaveProgress(operation *BulkOperation) (bool, error)
+	// SaveCancellation stores that an operation was cancelled unless it
+	// finished meanwhile. It reports whether it was stored.
+	SaveCancellation(operation *BulkOperation) (bool, error)
+	SaveItems(items []*BulkOperationItem) error
+	// FindPendingItems finds items of an operation still to be processed
+	FindPendingItems(operationID string, limit int) ([]*BulkOperationItem, error)
+	// FindItems finds the items of an operation, optionally in one status
+	FindItems(operationID string, status BulkItemStatus, limit, offset int) ([]*BulkOperationItem, int64, error)
+	// CountItems counts the items of an operation by status
+	CountItems(operationID string) (map[BulkItemStatus]int, error)
+	UpdateItem(item *BulkOperationItem) error
+}
@@ -0,0 +1,122 @@
+package domain_test
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkOperation_Lifecycle(t *testing.T) {
+	now := time.Now()
+
+	_, err := domain.NewBulkOperation("delete_everything", "seller-a", "admin-1", "", now)
+	assert.Error(t, err)
+	_, err = domain.NewBulkOperation(domain.BulkSuspendSellerListings, "", "admin-1", "", now)
+	assert.Error(t, err)
+
+	operation, err := domain.NewBulkOperation(domain.BulkSuspendSellerListings, "seller-a", "admin-1", "fraud report", now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkOperationPending, operation.Status)
+
+	require.NoError(t, operation.Start(3, now))
+	assert.Equal(t, domain.BulkOperationRunning, operation.Status)
+	assert.Equal(t, 3, operation.Total)
+	assert.Error(t, operation.Start(3, now), "operations start once")
+
+	operation.RecordProgress(map[domain.BulkItemStatus]int{
+		domain.BulkItemSucceeded: 1,
+		domain.BulkItemPending:   2,
+	}, now)
+	assert.Equal(t, 1, operation.Processed)
+	assert.Equal(t, domain.BulkOperationRunning, operation.Status)
+
+	operation.RecordProgress(map[domain.BulkItemStatus]int{
+		domain.BulkItemSucceeded: 1,
+		domain.BulkItemSkipped:   1,
+		domain.BulkItemFailed:    1,
+	}, now)
+	assert.Equal(t, 3, operation.Processed)
+	assert.Equal(t, domain.BulkOperationCompleted, operation.Status)
+	assert.NotNil(t, operation.FinishedAt)
+
+	assert.Error(t, operation.Cancel("admin-1", now), "finished operations cannot be cancelled")
+}
+
+func TestBulkOperation_Cancel(t *testing.T) {
+	now := time.Now()
+	operation, err := domain.NewBulkOperation(domain.BulkReindexCategory, "category-1", "admin-1", "", now)
+	require.NoError(t, err)
+
+	require.NoError(t, operation.Cancel("admin-2", now))
+	assert.Equal(t, domain.BulkOperationCancelled, operation.Status)
+	assert.Equal(t, ids.UserID("admin-2"), *operation.CancelledBy)
+	assert.True(t, operation.IsFinished())
+	assert.Error(t, operation.Start(1, now))
+}
+
+func TestBulkOperationItem_Finish(t *testing.T) {
+	items := domain.NewBulkOperationItems("operation-1", []ids.ListingID{"listing-1", "listing-2"})
+	require.Len(t, items, 2)
+	assert.Equal(t, domain.BulkItemPending, items[0].Status)
+
+	now := time.Now()
+	items[0].Finish(domain.BulkItemSkipped, nil, now)
+	assert.Equal(t, domain.BulkItemSkipped, items[0].Status)
+	assert.Empty(t, items[0].Error)
+
+	// Failures record the error's message
+	items[1].Finish(domain.BulkItemSucceeded, errors.NotFoundError("listing not found"), now)
+	assert.Equal(t, domain.BulkItemFailed, items[1].Status)
+	assert.Equal(t, "listing not found", items[1].Error)
+
+	items[1].Finish(domain.BulkItemFailed, stderrors.New("connection reset"), now)
+	assert.Equal(t, "connection reset", items[1].Error)
+}
+
+func TestNewListingsBulkOperation(t *testing.T) {
+	now := time.Now()
+	picked := domain.BulkListingSelection{ListingIDs: []ids.ListingID{"listing-1", "listing-2"}}
+
+	_, err := domain.NewBulkOperation(domain.BulkDeleteListings, "seller-a", "admin-1", "", now)
+	assert.Error(t, err, "kinds that select listings need listing IDs or a filter")
+	_, err = domain.NewListingsBulkOperation(domain.BulkSuspendSellerListings, picked, "", "admin-1", "", now)
+	assert.Error(t, err, "kinds acting on a seller need its ID")
+
+	_, err = domain.NewListingsBulkOperation(domain.BulkDeleteListings, domain.BulkListingSelection{}, "", "admin-1", "", now)
+	assert.Error(t, err)
+	_, err = domain.NewListingsBulkOperation(domain.BulkDeleteListings, domain.BulkListingSelection{
+		ListingIDs: picked.ListingIDs,
+		Filter:     &domain.BulkListingFilter{SellerID: "seller-a"},
+	}, "", "admin-1", "", now)
+	assert.Error(t, err, "listings are picked one way")
+	_, err = domain.NewListingsBulkOperation(domain.BulkDeleteListings, domain.BulkListingSelection{
+		ListingIDs: make([]ids.ListingID, domain.MaxBulkListingIDs+1),
+	}, "", "admin-1", "", now)
+	assert.Error(t, err)
+	_, err = domain.NewListingsBulkOperation(domain.BulkDeactivateListings, domain.BulkListingSelection{
+		Filter: &domain.BulkListingFilter{Status: "archived"},
+	}, "", "admin-1", "", now)
+	assert.Error(t, err)
+	_, err = domain.NewListingsBulkOperation(domain.BulkMoveCategory, picked, "", "admin-1", "", now)
+	assert.Error(t, err, "moving needs a category")
+
+	operation, err := domain.NewListingsBulkOperation(domain.BulkMoveCategory, picked, "category-2", "admin-1", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkOperationPending, operation.Status)
+	assert.Equal(t, picked.ListingIDs, operation.ListingIDs)
+	assert.Equal(t, "category-2", operation.CategoryID)
+	assert.Empty(t, operation.TargetID)
+
+	operation, err = domain.NewListingsBulkOperation(domain.BulkDeactivateListings, domain.BulkListingSelection{
+		Filter: &domain.BulkListingFilter{Region: "Ashanti", Status: domain.ListingStatusExpired},
+	}, "category-2", "admin-1", "", now)
+	require.NoError(t, err)
+	assert.Empty(t, operation.CategoryID, "only moves keep a category")
+}
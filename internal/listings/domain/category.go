@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// MaxCategoryNameLength is the longest category name
+const MaxCategoryNameLength = 100
+
+// CategoryNode is a category in the category tree with its subcategories
+type CategoryNode struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	ImageURL    string          `json:"image_url"`
+	ParentID    *string         `json:"parent_id,omitempty"`
+	Position    int             `json:"position"`
+	IsActive    bool            `json:"is_active"`
+	ChildCount  int             `json:"child_count"`
+	Children    []*CategoryNode `json:"children"`
+}
+
+// NewCategory creates a new active category, at the root when parentID is nil
+func NewCategory(name, description, imageURL string, parentID *string, position int) (*Category, error) {
+	name = strings.TrimSpace(name)
+	if err := validateCategoryName(name); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Category{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		ParentID:    parentID,
+		ImageURL:    strings.TrimSpace(imageURL),
+		Position:    position,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// Rename changes the category's name, description and image
+func (c *Category) Rename(name, description, imageURL string) error {
+	name = strings.TrimSpace(name)
+	if err := validateCategoryName(name); err != nil {
+		return err
+	}
+
+	c.Name = name
+	c.Description = strings.TrimSpace(description)
+	c.ImageURL = strings.TrimSpace(imageURL)
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// MoveTo puts the category under another parent, or at the root when
+// parentID is nil. categories must hold every category, so moves that would
+// make the category its own ancestor are refused.
+func (c *Category) MoveTo(parentID *string, categories []*Category) error {
+	if parentID != nil && WouldCreateCycle(categories, c.ID, *parentID) {
+		return errors.ValidationError("a category cannot be moved under itself or its subcategories")
+	}
+
+	c.ParentID = parentID
+	c.Parent = nil
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetActive shows or hides the category and its subcategories
+func (c *Category) SetActive(active bool) {
+	c.IsActive = active
+	c.UpdatedAt = time.Now()
+}
+
+// SetPosition orders the category among its siblings
+func (c *Category) SetPosition(position int) {
+	c.Position = position
+	c.UpdatedAt = time.Now()
+}
+
+// HasParent reports whether the category sits under the given parent, or at
+// the root when parentID is nil
+func (c *Category) HasParent(parentID *string) bool {
+	if c.ParentID == nil || parentID == nil {
+		return c.ParentID == nil && parentID == nil
+	}
+	return *c.ParentID == *parentID
+}
+
+// WouldCreateCycle reports whether making parentID the parent of categoryID
+// would make the category its own ancestor
+func WouldCreateCycle(categories []*Category, categoryID, parentID string) bool {
+	byID := make(map[string]*Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	// Walk up from the new parent; existing cycles are cut off by the visited set
+	visited := make(map[string]bool)
+	for id := parentID; id != ""; {
+		if id == categoryID {
+			return true
+		}
+		if visited[id] {
+			return false
+		}
+		visited[id] = true
+
+		category, ok := byID[id]
+		if !ok || category.ParentID == nil {
+			return false
+		}
+		id = *category.ParentID
+	}
+	return false
+}
+
+// BuildCategoryTree nests categories under their parents, ordering siblings
+// by position and then name. Inactive categories and everything under them
+// are left out unless includeInactive is set. Categories whose parent is
+// missing are treated as roots.
+func BuildCategoryTree(categories []*Category, includeInactive bool) []*CategoryNode {
+	nodes := make(map[string]*CategoryNode, len(categories))
+	for _, category := range categories {
+		if !category.IsActive && !includeInactive {
+			continue
+		}
+		nodes[category.ID] = &CategoryNode{
+			ID:          category.ID,
+			Name:        category.Name,
+			Description: category.Description,
+			ImageURL:    category.ImageURL,
+			ParentID:    category.ParentID,
+			Position:    category.Position,
+			IsActive:    category.IsActive,
+			Children:    []*CategoryNode{},
+		}
+	}
+
+	byID := make(map[string]*Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	roots := []*CategoryNode{}
+	for _, category := range categories {
+		node, ok := nodes[category.ID]
+		if !ok {
+			continue
+		}
+		if category.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		if parent, ok := nodes[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		// A hidden parent hides its subcategories; a missing one does not
+		if _, exists := byID[*category.ParentID]; !exists {
+			roots = append(roots, node)
+		}
+	}
+
+	sortCategoryNodes(roots)
+	return roots
+}
+
+// sortCategoryNodes orders sibling nodes and counts their children, recursively
+func sortCategoryNodes(nodes []*CategoryNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Position != nodes[j].Position {
+			return nodes[i].Position < nodes[j].Position
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+	for _, node := range nodes {
+		node.ChildCount = len(node.Children)
+		sortCategoryNodes(node.Children)
+	}
+}
+
+// validateCategoryName checks a trimmed category name
+func validateCategoryName(name string) error {
+	if name == "" {
+		return errors.ValidationError("category name is required")
+	}
+	if len([]rune(name)) > MaxCategoryNameLength {
+		return errors.ValidationError("category name must be at most 100 characters")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/listings/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCategoryTree(t *testing.T) {
+	electronics := "electronics"
+	vehicles := "vehicles"
+	categories := []*domain.Category{
+		{ID: "phones", Name: "Phones", ParentID: &electronics, Position: 1, IsActive: true},
+		{ID: "electronics", Name: "Electronics", Position: 0, IsActive: true},
+		{ID: "laptops", Name: "Laptops", ParentID: &electronics, Position: 0, IsActive: true},
+		{ID: "vehicles", Name: "Vehicles", Position: 1, IsActive: false},
+		{ID: "cars", Name: "Cars", ParentID: &vehicles, IsActive: true},
+	}
+
+	tree := domain.BuildCategoryTree(categories, false)
+	require.Len(t, tree, 1, "inactive categories hide their subcategories")
+	assert.Equal(t, "electronics", tree[0].ID)
+	assert.Equal(t, 2, tree[0].ChildCount)
+	assert.Equal(t, "laptops", tree[0].Children[0].ID, "siblings are ordered by position")
+
+	full := domain.BuildCategoryTree(categories, true)
+	require.Len(t, full, 2)
+	assert.Equal(t, "cars", full[1].Children[0].ID)
+}
+
+func TestCategory_MoveToRejectsCycles(t *testing.T) {
+	root, err := domain.NewCategory("Electronics", "", "", nil, 0)
+	require.NoError(t, err)
+	child, err := domain.NewCategory("Phones", "", "", &root.ID, 0)
+	require.NoError(t, err)
+	grandchild, err := domain.NewCategory("Smartphones", "", "", &child.ID, 0)
+	require.NoError(t, err)
+	categories := []*domain.Category{root, child, grandchild}
+
+	assert.Error(t, root.MoveTo(&root.ID, categories))
+	assert.Error(t, root.MoveTo(&grandchild.ID, categories))
+	assert.Nil(t, root.ParentID)
+
+	require.NoError(t, grandchild.MoveTo(&root.ID, categories))
+	assert.True(t, grandchild.HasParent(&root.ID))
+	require.NoError(t, grandchild.MoveTo(nil, categories))
+	assert.True(t, grandchild.HasParent(nil))
+}
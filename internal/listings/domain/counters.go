@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/ids"
+)
+
+// ListingCounters holds the view and favorite counts of a listing. They are
+// kept apart from the listing row so hot listings do not contend on it, and
+// are updated from events rather than by the requests that count them.
+type ListingCounters struct {
+	ListingID ids.ListingID `gorm:"type:uuid;primary_key" json:"listing_id"`
+	Views     int64         `gorm:"not null;default:0" json:"views"`
+	Favorites int64         `gorm:"not null;default:0" json:"favorites"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// ListingCounterRepository defines the interface for listing counter
+// persistence. Listings without counters have counted nothing yet.
+type ListingCounterRepository interface {
+	FindByListingIDs(listingIDs []ids.ListingID) ([]*ListingCounters, error)
+	// Add adds the given views and favorites to a listing's counters,
+	// creating them on the listing's first count. Favorites never drop below zero.
+	Add(listingID ids.ListingID, views, favorites int64, at time.Time) error
+}
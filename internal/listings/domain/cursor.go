@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// ListingCursor marks the last listing of a page of search results, so the
+// next page starts after it even if listings were published meanwhile
+type ListingCursor struct {
+	Sort ListingSort `json:"s"`
+	// Value is the price, in minor units, or the view count of the last
+	// listing, depending on the sort
+	Value     float64       `json:"v,omitempty"`
+	CreatedAt time.Time     `json:"t,omitempty"`
+	ID        ids.ListingID `json:"i"`
+}
+
+// SupportsCursor reports whether results in this order can be paged with a
+// cursor. Relevance depends on the query and on seller penalties that change
+// between pages, and distance on the point searched near, so they only
+// support offsets.
+func (s ListingSort) SupportsCursor() bool {
+	switch s {
+	case ListingSortNewest, ListingSortPriceAsc, ListingSortPriceDesc, ListingSortMostViewed:
+		return true
+	}
+	return false
+}
+
+// NewListingCursor marks the position of a listing in the given sort order
+func NewListingCursor(listing *Listing, sort ListingSort) *ListingCursor {
+	cursor := &ListingCursor{Sort: sort, ID: listing.ID}
+	switch sort {
+	case ListingSortNewest:
+		cursor.CreatedAt = listing.CreatedAt
+	case ListingSortPriceAsc, ListingSortPriceDesc:
+		cursor.Value = float64(listing.Price.Amount)
+	case ListingSortMostViewed:
+		cursor.Value = float64(listing.ViewsCount)
+	}
+	return cursor
+}
+
+// DecodeListingCursor parses a cursor returned with a previous page
+func DecodeListingCursor(token string) (*ListingCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.ValidationError("cursor is invalid")
+	}
+	var cursor ListingCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" || !cursor.Sort.SupportsCursor() {
+		return nil, errors.ValidationError("cursor is invalid")
+	}
+	return &cursor, nil
+}
+
+// Encode returns the opaque token clients send back for the next page
+func (c *ListingCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Precedes reports whether the listing comes after the cursor in its sort
+// order. Ties are broken by listing ID, in the direction of the sort.
+func (c *ListingCursor) Precedes(listing *Listing) bool {
+	switch c.Sort {
+	case ListingSortNewest:
+		if !listing.CreatedAt.Equal(c.CreatedAt) {
+			return listing.CreatedAt.Before(c.CreatedAt)
+		}
+		return listing.ID < c.ID
+	case ListingSortPriceAsc:
+		if price := float64(listing.Price.Amount); price != c.Value {
+			return price > c.Value
+		}
+		return listing.ID > c.ID
+	case ListingSortPriceDesc:
+		if price := float64(listing.Price.Amount); price != c.Value {
+			return price < c.Value
+		}
+		return listing.ID < c.ID
+	case ListingSortMostViewed:
+		if views := float64(listing.ViewsCount); views != c.Value {
+			return views < c.Value
+		}
+		return listing.ID < c.ID
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// Event types
+const (
+	ListingTransferRequestedEvent = "listing.transfer_requested"
+	ListingTransferCompletedEvent = "listing.transfer_completed"
+	ListingTransferCancelledEvent = "listing.transfer_cancelled"
+	ListingOwnerChangedEvent      = "listing.owner_changed"
+	ListingPromotedEvent          = "listing.promoted"
+	ListingPromotionPaidEvent     = "listing.promotion_paid"
+	ListingTrendingUpdatedEvent   = "listing.trending_updated"
+	ListingCreatedEvent           = "listing.created"
+	ListingUpdatedEvent           = "listing.updated"
+	ListingPriceChangedEvent      = "listing.price_changed"
+	ListingActivatedEvent         = "listing.activated"
+	ListingDeactivatedEvent       = "listing.deactivated"
+	ListingSoldEvent              = "listing.sold"
+	ListingExpiredEvent           = "listing.expired"
+	ListingStatusChangedEvent     = "listing.status_changed"
+	ListingDeletedEvent           = "listing.deleted"
+	ListingChangedEvent           = "listing.changed"
+	ListingViewedEvent            = "listing.viewed"
+	ListingFavoritedEvent         = "listing.favorited"
+	ListingUnfavoritedEvent       = "listing.unfavorited"
+	ListingImageUploadedEvent     = "listing.image_uploaded"
+	ListingQuestionAskedEvent     = "listing.question_asked"
+	ListingQuestionAnsweredEvent  = "listing.question_answered"
+	ListingQuestionFlaggedEvent   = "listing.question_flagged"
+	ListingOfferMadeEvent         = "listing.offer_made"
+	ListingOfferCounteredEvent    = "listing.offer_countered"
+	ListingOfferAcceptedEvent     = "listing.offer_accepted"
+	ListingOfferDeclinedEvent     = "listing.offer_declined"
+	ListingOfferExpiredEvent      = "listing.offer_expired"
+	SavedSearchMatchedEvent       = "listing.saved_search_matched"
+)
+
+// ListingTransferRequested represents the event when an ownership transfer is proposed
+type ListingTransferRequested struct {
+	TransferID   string         `json:"transfer_id"`
+	Scope        TransferScope  `json:"scope"`
+	ListingID    *ids.ListingID `json:"listing_id,omitempty"`
+	FromSellerID ids.UserID     `json:"from_seller_id"`
+	ToSellerID   ids.UserID     `json:"to_seller_id"`
+	InitiatedBy  ids.UserID     `json:"initiated_by"`
+	ExpiresAt    time.Time      `json:"expires_at"`
+	Timestamp    time.Time      `json:"timestamp"`
+}
+
+// ListingTransferCompleted represents the event when an ownership transfer is carried out
+type ListingTransferCompleted struct {
+	TransferID   string          `json:"transfer_id"`
+	Scope        TransferScope   `json:"scope"`
+	FromSellerID ids.UserID      `json:"from_seller_id"`
+	ToSellerID   ids.UserID      `json:"to_seller_id"`
+	ListingIDs   []ids.ListingID `json:"listing_ids"`
+	Timestamp    time.Time       `json:"timestamp"`
+}
+
+// ListingTransferCancelled represents the event when a transfer is rejected or cancelled
+type ListingTransferCancelled struct {
+	TransferID   string         `json:"transfer_id"`
+	FromSellerID ids.UserID     `json:"from_seller_id"`
+	ToSellerID   ids.UserID     `json:"to_seller_id"`
+	Status       TransferStatus `json:"status"`
+	Timestamp    time.Time      `json:"timestamp"`
+}
+
+// ListingOwnerChanged represents the event when a listing moves to another seller.
+// Search, analytics and messaging use it to update their references.
+type ListingOwnerChanged struct {
+	ListingID    ids.ListingID `json:"listing_id"`
+	TransferID   string        `json:"transfer_id"`
+	FromSellerID ids.UserID    `json:"from_seller_id"`
+	ToSellerID   ids.UserID    `json:"to_seller_id"`
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// ListingPromoted represents the event when a listing is promoted.
+// The worker uses it to warm caches ahead of the promotion traffic.
+type ListingPromoted struct {
+	ListingID     ids.ListingID `json:"listing_id"`
+	SellerID      ids.UserID    `json:"seller_id"`
+	PromotedUntil time.Time     `json:"promoted_until"`
+	Timestamp     time.Time     `json:"timestamp"`
+}
+
+// ListingPromotionPaid represents the event when the payment for a listing
+// promotion is confirmed. The worker promotes the listing for the paid time.
+type ListingPromotionPaid struct {
+	PromotionID string        `json:"promotion_id"`
+	ListingID   ids.ListingID `json:"listing_id"`
+	SellerID    ids.UserID    `json:"seller_id"`
+	Days        int           `json:"days"`
+	Amount      money.Money   `json:"amount"`
+	Timestamp   time.Time     `json:"timestamp"`
+}
+
+// ListingTrendingUpdated represents the event when the trending listings are recalculated
+type ListingTrendingUpdated struct {
+	ListingIDs []ids.ListingID `json:"listing_ids"`
+	Timestamp  time.Time       `json:"timestamp"`
+}
+
+// ListingActivated represents the event when a listing goes live.
+// Followers of the seller are notified of it.
+type ListingActivated struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	Title     string        `json:"title"`
+	Price     money.Money   `json:"price"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ListingCreated represents the event when a seller creates a draft listing
+type ListingCreated struct {
+	ListingID  ids.ListingID `json:"listing_id"`
+	SellerID   ids.UserID    `json:"seller_id"`
+	CategoryID string        `json:"category_id"`
+	Title      string        `json:"title"`
+	Price      money.Money   `json:"price"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+// ListingUpdated represents the event when a seller changes a listing's details
+type ListingUpdated struct {
+	ListingID  ids.ListingID `json:"listing_id"`
+	SellerID   ids.UserID    `json:"seller_id"`
+	CategoryID string        `json:"category_id"`
+	Title      string        `json:"title"`
+	Price      money.Money   `json:"price"`
+	Status     ListingStatus `json:"status"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+// ListingPriceChanged represents the event when a seller changes the price
+// of a published listing, so buyers can be told of price drops
+type ListingPriceChanged struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	Title     string        `json:"title"`
+	OldPrice  money.Money   `json:"old_price"`
+	NewPrice  money.Money   `json:"new_price"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ListingDeactivated represents the event when a seller takes a listing off the marketplace
+type ListingDeactivated struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ListingSold represents the event when a seller marks a listing as sold
+// outside the marketplace checkout
+type ListingSold struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	Price     money.Money   `json:"price"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ListingExpired represents the event when a live listing reaches the end of
+// its lifetime without being renewed
+type ListingExpired struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	ExpiredAt time.Time     `json:"expired_at"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ListingDeleted represents the event when an administrator deletes a
+// listing for good
+type ListingDeleted struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	Reason    string        `json:"reason,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ListingStatusChanged represents the event when a listing moves from one
+// status to another, whatever moved it
+type ListingStatusChanged struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	From      ListingStatus `json:"from"`
+	To        ListingStatus `json:"to"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ListingImageUploaded represents the event when a photo is added to a
+// listing. The worker makes the photo's resized, metadata-free copies from it.
+type ListingImageUploaded struct {
+	ListingID  ids.ListingID `json:"listing_id"`
+	ImageID    string        `json:"image_id"`
+	StorageKey string        `json:"storage_key"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+// ListingChanged represents the event when a listing is edited, taken down,
+// sold or moved to another seller. The search indexer refreshes its copy of
+// the listing from it.
+type ListingChanged struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	Status    ListingStatus `json:"status"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ListingViewed represents the event when a buyer opens a listing. The
+// worker counts the view in the listing's counters, once in a while per
+// viewer. ViewerKey identifies the viewer without saying who they are.
+type ListingViewed struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	ViewerKey string        `json:"viewer_key,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ListingFavorited represents the event when a buyer adds a listing to their
+// favorites, or takes it off them for ListingUnfavoritedEvent. The worker
+// counts it in the listing's counters and analytics.
+type ListingFavorited struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	UserID    ids.UserID    `json:"user_id"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// ListingQuestionAsked represents the event when a buyer asks about a listing.
+// Channels lists how the seller wants to be told; empty means not at all.
+type ListingQuestionAsked struct {
+	QuestionID string        `json:"question_id"`
+	ListingID  ids.ListingID `json:"listing_id"`
+	SellerID   ids.UserID    `json:"seller_id"`
+	AskerID    ids.UserID    `json:"asker_id"`
+	Question   string        `json:"question"`
+	Channels   []string      `json:"channels"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+// ListingQuestionAnswered represents the event when a seller answers a question.
+// Channels lists how the asker wants to be told; empty means not at all.
+type ListingQuestionAnswered struct {
+	QuestionID string        `json:"question_id"`
+	ListingID  ids.ListingID `json:"listing_id"`
+	SellerID   ids.UserID    `json:"seller_id"`
+	AskerID    ids.UserID    `json:"asker_id"`
+	Answer     string        `json:"answer"`
+	Channels   []string      `json:"channels"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+// ListingQuestionFlagged represents the event when a question or answer is
+// held for review by moderators
+type ListingQuestionFlagged struct {
+	QuestionID string        `json:"question_id"`
+	ListingID  ids.ListingID `json:"listing_id"`
+	Reason     string        `json:"reason"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+// ListingOffer represents the events of an offer's negotiation: made,
+// countered, accepted, declined or expired. RecipientID is the party to tell,
+// so chat can post it in their conversation, and Channels lists how they want
+// to be told; empty means not at all.
+type ListingOffer struct {
+	OfferID     string        `json:"offer_id"`
+	ListingID   ids.ListingID `json:"listing_id"`
+	SellerID    ids.UserID    `json:"seller_id"`
+	BuyerID     ids.UserID    `json:"buyer_id"`
+	Amount      money.Money   `json:"amount"`
+	Status      OfferStatus   `json:"status"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	RecipientID ids.UserID    `json:"recipient_id"`
+	Channels    []string      `json:"channels"`
+	Timestamp   time.Time     `json:"timestamp"`
+}
+
+// SavedSearchMatched represents the event when a newly activated listing
+// matches buyers' saved searches. It carries a batch of the buyers to alert.
+type SavedSearchMatched struct {
+	ListingID ids.ListingID      `json:"listing_id"`
+	SellerID  ids.UserID         `json:"seller_id"`
+	Title     string             `json:"title"`
+	Price     money.Money        `json:"price"`
+	Alerts    []SavedSearchAlert `json:"alerts"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+// SavedSearchAlert is one buyer to tell about a listing matching their saved
+// search, with the channels they want saved search alerts on
+type SavedSearchAlert struct {
+	UserID        ids.UserID `json:"user_id"`
+	SavedSearchID string     `json:"saved_search_id"`
+	SearchName    string     `json:"search_name"`
+	Channels      []string   `json:"channels"`
+}
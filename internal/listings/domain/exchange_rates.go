@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/money"
+)
+
+// ExchangeRates are the rates of the supported currencies against a base
+// currency, as fetched from a rates provider. They convert prices for
+// display only; buyers pay in the currency a listing is priced in.
+type ExchangeRates struct {
+	Base string `json:"base"`
+	// Rates maps each currency to how many units of it one unit of Base buys
+	Rates     map[string]float64 `json:"rates"`
+	FetchedAt time.Time          `json:"fetched_at"`
+}
+
+// Rate returns how many units of to one unit of from buys, crossing through
+// the base currency when neither is the base
+func (r *ExchangeRates) Rate(from, to string) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	fromRate, ok := r.baseRate(from)
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := r.baseRate(to)
+	if !ok {
+		return 0, false
+	}
+	return toRate / fromRate, true
+}
+
+// Convert returns the amount in another currency
+func (r *ExchangeRates) Convert(amount money.Money, currency string) (money.Money, error) {
+	rate, ok := r.Rate(amount.Currency, currency)
+	if !ok {
+		return money.Money{}, errors.ValidationError("no exchange rate for the currency")
+	}
+	return amount.Convert(currency, rate), nil
+}
+
+// ConvertPrices sets the display price of the listings priced in another
+// currency. Listings in the currency itself, or in currencies without a
+// rate, keep only their own price.
+func (r *ExchangeRates) ConvertPrices(currency string, listings []*Listing) {
+	for _, listing := range listings {
+		listing.DisplayPrice = nil
+		if listing.Price.Currency == currency {
+			continue
+		}
+		if converted, err := r.Convert(listing.Price, currency); err == nil {
+			listing.DisplayPrice = &converted
+		}
+	}
+}
+
+func (r *ExchangeRates) baseRate(currency string) (float64, bool) {
+	if currency == r.Base {
+		return 1, true
+	}
+	rate, ok := r.Rates[currency]
+	return rate, ok && rate > 0
+}
@@ -0,0 +1,30 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeRates_Convert(t *testing.T) {
+	rates := &domain.ExchangeRates{Base: "GHS", Rates: map[string]float64{"USD": 0.08, "NGN": 100}}
+
+	converted, err := rates.Convert(money.Cedis(25), "USD")
+	require.NoError(t, err)
+	assert.Equal(t, money.New(200, "USD"), converted)
+
+	converted, err = rates.Convert(money.New(100, "USD"), "NGN")
+	require.NoError(t, err)
+	assert.Equal(t, money.New(125000, "NGN"), converted, "rates cross through the base")
+
+	converted, err = rates.Convert(money.New(200, "USD"), "GHS")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(25), converted)
+
+	_, err = rates.Convert(money.Cedis(25), "EUR")
+	assert.Error(t, err)
+}
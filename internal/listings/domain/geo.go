@@ -0,0 +1,46 @@
+package domain
+
+import "math"
+
+// earthRadiusKm is the radius of the sphere distances are measured on, the
+// same as Postgres' earthdistance module uses
+const earthRadiusKm = 6378.168
+
+// GeoRadius restricts a search to the listings within a distance of a point
+type GeoRadius struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+}
+
+// GeoPoint is a position as search engines index it
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// HasCoordinates reports whether the location was pinned on a map. Listings
+// created without coordinates store zero for both.
+func (l Location) HasCoordinates() bool {
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
+// DistanceKm is the great-circle distance in kilometres from the location to a point
+func (l Location) DistanceKm(latitude, longitude float64) float64 {
+	lat1, lat2 := radians(l.Latitude), radians(latitude)
+	dLat := lat2 - lat1
+	dLng := radians(longitude - l.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Contains reports whether a location lies within the radius. Locations
+// without coordinates never do.
+func (r GeoRadius) Contains(location Location) bool {
+	return location.HasCoordinates() && location.DistanceKm(r.Latitude, r.Longitude) <= r.RadiusKm
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,25 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/listings/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocation_DistanceKm(t *testing.T) {
+	accra := domain.Location{Latitude: 5.6037, Longitude: -0.1870}
+	kumasi := domain.Location{Latitude: 6.6885, Longitude: -1.6244}
+
+	assert.InDelta(t, 200, accra.DistanceKm(kumasi.Latitude, kumasi.Longitude), 5)
+	assert.InDelta(t, 0, accra.DistanceKm(accra.Latitude, accra.Longitude), 1e-9)
+}
+
+func TestGeoRadius_Contains(t *testing.T) {
+	near := domain.GeoRadius{Latitude: 5.6037, Longitude: -0.1870, RadiusKm: 10}
+
+	assert.True(t, near.Contains(domain.Location{Latitude: 5.6500, Longitude: -0.1870}))
+	assert.False(t, near.Contains(domain.Location{Latitude: 6.6885, Longitude: -1.6244}))
+	// Listings never pinned on a map are not near anything
+	assert.False(t, near.Contains(domain.Location{}))
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+)
+
+// Names of the copies made of every listing photo
+const (
+	ImageVariantThumbnail = "thumbnail"
+	ImageVariantMedium    = "medium"
+	ImageVariantLarge     = "large"
+	ImageVariantFull      = "full"
+)
+
+// ImageVariantSpec is a copy made of every listing photo, scaled down so its
+// longest edge is at most MaxEdge pixels
+type ImageVariantSpec struct {
+	Name    string
+	MaxEdge int
+}
+
+// ImageVariantSpecs lists the copies made of every listing photo. The full
+// copy replaces the uploaded original as the photo's URL.
+var ImageVariantSpecs = []ImageVariantSpec{
+	{Name: ImageVariantThumbnail, MaxEdge: 200},
+	{Name: ImageVariantMedium, MaxEdge: 640},
+	{Name: ImageVariantLarge, MaxEdge: 1280},
+	{Name: ImageVariantFull, MaxEdge: 2048},
+}
+
+// ImageVariant is a resized WebP copy of a listing photo
+type ImageVariant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageVariantKey returns where a copy of the photo stored under storageKey
+// is stored, next to the original
+func ImageVariantKey(storageKey, name string) string {
+	return strings.TrimSuffix(storageKey, path.Ext(storageKey)) + "-" + name + ".webp"
+}
+
+// IsProcessed checks if the photo's copies have been made
+func (i *ListingImage) IsProcessed() bool {
+	return i.ProcessedAt != nil
+}
+
+// SetVariants records the copies made of the photo and serves the full copy
+// in place of the original, which still carries the camera's metadata
+func (i *ListingImage) SetVariants(variants []ImageVariant, now time.Time) error {
+	var full *ImageVariant
+	for n := range variants {
+		if variants[n].Name == ImageVariantFull {
+			full = &variants[n]
+		}
+	}
+	if full == nil {
+		return errors.ValidationError("a full size copy of the photo is required")
+	}
+
+	i.Variants = variants
+	i.URL = full.URL
+	i.ProcessedAt = &now
+	return nil
+}
+
+// Image finds one of the listing's photos by ID
+func (l *Listing) Image(imageID string) *ListingImage {
+	for n := range l.Images {
+		if l.Images[n].ID == imageID {
+			return &l.Images[n]
+		}
+	}
+	return nil
+}
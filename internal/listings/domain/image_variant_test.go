@@ -0,0 +1,32 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageVariantKey(t *testing.T) {
+	assert.Equal(t, "seller-a/photo-thumbnail.webp", domain.ImageVariantKey("seller-a/photo.jpg", domain.ImageVariantThumbnail))
+	assert.Equal(t, "seller-a/photo-full.webp", domain.ImageVariantKey("seller-a/photo", domain.ImageVariantFull))
+}
+
+func TestListingImage_SetVariants(t *testing.T) {
+	image := domain.ListingImage{URL: "https://media.example.com/seller-a/photo.jpg"}
+
+	// The full copy replaces the original
+	err := image.SetVariants([]domain.ImageVariant{{Name: domain.ImageVariantThumbnail, URL: "https://media.example.com/thumb.webp"}}, time.Now())
+	assert.Error(t, err)
+	assert.False(t, image.IsProcessed())
+
+	require.NoError(t, image.SetVariants([]domain.ImageVariant{
+		{Name: domain.ImageVariantThumbnail, URL: "https://media.example.com/thumb.webp"},
+		{Name: domain.ImageVariantFull, URL: "https://media.example.com/full.webp"},
+	}, time.Now()))
+	assert.True(t, image.IsProcessed())
+	assert.Equal(t, "https://media.example.com/full.webp", image.URL)
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
 
 	"github.com/google/uuid"
 )
@@ -19,6 +21,9 @@ const (
 	ListingStatusExpired  ListingStatus = "expired"
 )
 
+// ListingLifetimeDays is how long a listing stays live before it expires
+const ListingLifetimeDays = 30
+
 // Condition represents the condition of an item
 type Condition string
 
@@ -31,84 +36,127 @@ const (
 	ConditionForParts Condition = "for_parts"
 )
 
+// IsValid checks if the condition is one listings can be in
+func (c Condition) IsValid() bool {
+	switch c {
+	case ConditionNew, ConditionLikeNew, ConditionGood, ConditionFair, ConditionPoor, ConditionForParts:
+		return true
+	}
+	return false
+}
+
 // Category represents a listing category
 type Category struct {
 	ID          string     `gorm:"type:uuid;primary_key" json:"id"`
-	Name        string     `gorm:"not null" json:"name"`
-	Description string     `json:"description"`
-	ParentID    *string    `gorm:"type:uuid" json:"parent_id,omitempty"`
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	ParentID    *string    `gorm:"type:uuid;index" json:"parent_id,omitempty"`
 	Parent      *Category  `gorm:"foreignKey:ParentID" json:"parent,omitempty"`
 	Children    []Category `gorm:"foreignKey:ParentID" json:"children,omitempty"`
-	ImageURL    string     `json:"image_url"`
-	IsActive    bool       `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ImageURL    string     `gorm:"size:255" json:"image_url"`
+	// Position orders a category among its siblings, lowest first
+	Position  int       `gorm:"not null;default:0" json:"position"`
+	IsActive  bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // Listing represents a marketplace listing aggregate root
 type Listing struct {
-	ID             string             `gorm:"type:uuid;primary_key" json:"id"`
-	SellerID       string             `gorm:"type:uuid;not null;index" json:"seller_id"`
-	CategoryID     string             `gorm:"type:uuid;not null" json:"category_id"`
-	Category       Category           `gorm:"foreignKey:CategoryID" json:"category"`
-	Title          string             `gorm:"not null" json:"title"`
-	Description    string             `gorm:"type:text" json:"description"`
-	Price          float64            `gorm:"not null" json:"price"`
-	Currency       string             `gorm:"default:'GHS'" json:"currency"`
-	Condition      Condition          `gorm:"not null" json:"condition"`
-	Status         ListingStatus      `gorm:"default:'draft'" json:"status"`
-	Location       Location           `gorm:"embedded" json:"location"`
-	Images         []ListingImage     `gorm:"foreignKey:ListingID" json:"images"`
-	Attributes     []ListingAttribute `gorm:"foreignKey:ListingID" json:"attributes"`
-	Tags           []ListingTag       `gorm:"many2many:listing_tags;" json:"tags"`
-	ViewsCount     int                `gorm:"default:0" json:"views_count"`
-	FavoritesCount int                `gorm:"default:0" json:"favorites_count"`
-	IsNegotiable   bool               `gorm:"default:true" json:"is_negotiable"`
-	IsPromoted     bool               `gorm:"default:false" json:"is_promoted"`
-	PromotedUntil  *time.Time         `json:"promoted_until,omitempty"`
-	ExpiresAt      time.Time          `json:"expires_at"`
-	CreatedAt      time.Time          `json:"created_at"`
-	UpdatedAt      time.Time          `json:"updated_at"`
+	ID            ids.ListingID      `gorm:"type:uuid;primary_key" json:"id"`
+	SellerID      ids.UserID         `gorm:"type:uuid;not null;index" json:"seller_id"`
+	CategoryID    string             `gorm:"type:uuid" json:"category_id"`
+	Category      Category           `gorm:"foreignKey:CategoryID" json:"category"`
+	Title         string             `gorm:"size:255;not null" json:"title"`
+	Description   string             `gorm:"type:text" json:"description"`
+	Price         money.Money        `gorm:"embedded;embeddedPrefix:price_" json:"price"`
+	Condition     Condition          `gorm:"size:20;not null" json:"condition"`
+	Status        ListingStatus      `gorm:"size:20;default:'draft';index" json:"status"`
+	Location      Location           `gorm:"embedded" json:"location"`
+	Images        []ListingImage     `gorm:"foreignKey:ListingID" json:"images"`
+	Attributes    []ListingAttribute `gorm:"foreignKey:ListingID" json:"attributes"`
+	Tags          []ListingTag       `gorm:"many2many:listing_tag_relations;" json:"tags"`
+	IsNegotiable  bool               `gorm:"default:true" json:"is_negotiable"`
+	IsPromoted    bool               `gorm:"default:false" json:"is_promoted"`
+	PromotedUntil *time.Time         `json:"promoted_until,omitempty"`
+	ReservedFor   *ids.UserID        `gorm:"type:uuid" json:"-"`
+	ReservedUntil *time.Time         `json:"reserved_until,omitempty"`
+	PublishAt     *time.Time         `gorm:"index" json:"publish_at,omitempty"`
+	Holds         []ListingHold      `gorm:"type:jsonb;serializer:json" json:"holds,omitempty"`
+	ExpiresAt     time.Time          `json:"expires_at"`
+	RenewalCount  int                `gorm:"not null;default:0" json:"renewal_count"`
+	RenewedAt     *time.Time         `json:"renewed_at,omitempty"`
+	// RelistedFrom is the sold or expired listing this one was copied from
+	RelistedFrom *ids.ListingID `gorm:"type:uuid" json:"relisted_from,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	// SellerTrust is filled in from the users context when listings are served
+	SellerTrust *SellerTrust `gorm:"-" json:"seller_trust,omitempty"`
+	// Seller is filled in from the seller card read model on search results
+	Seller *SellerCard `gorm:"-" json:"seller,omitempty"`
+	// CategoryName is filled in, in the reader's locale, when listings are served
+	CategoryName string `gorm:"-" json:"category_name,omitempty"`
+	// DistanceKm is filled in on the results of searches near a point
+	DistanceKm *float64 `gorm:"-" json:"distance_km,omitempty"`
+	// Sponsored marks a promoted listing shown in a sponsored slot of search
+	// results, so apps can label it as such
+	Sponsored bool `gorm:"-" json:"sponsored,omitempty"`
+	// DisplayPrice is the price converted into the reader's currency, filled
+	// in when listings are served to readers who prefer another currency
+	DisplayPrice *money.Money `gorm:"-" json:"display_price,omitempty"`
+	// ViewsCount and FavoritesCount are filled in from the listing's counters
+	ViewsCount     int64 `gorm:"-" json:"views_count"`
+	FavoritesCount int64 `gorm:"-" json:"favorites_count"`
+
+	// statusChanges are the status changes not yet taken to be announced
+	statusChanges []ListingStatusChanged
+}
+
+// SellerTrust is the seller's trust level and badges shown with their
+// listings, and whether their store is open for sellers with opening hours
+type SellerTrust struct {
+	TrustLevel string   `json:"trust_level"`
+	Badges     []string `json:"badges"`
+	OpenNow    *bool    `json:"open_now,omitempty"`
 }
 
 // Location represents geographical location
 type Location struct {
-	Region    string  `gorm:"not null" json:"region"`
-	City      string  `gorm:"not null" json:"city"`
-	Area      string  `json:"area"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
+	Region    string  `gorm:"size:100;not null" json:"region"`
+	City      string  `gorm:"size:100;not null" json:"city"`
+	Area      string  `gorm:"size:100" json:"area"`
+	Latitude  float64 `gorm:"type:decimal(10,8)" json:"latitude"`
+	Longitude float64 `gorm:"type:decimal(11,8)" json:"longitude"`
 }
 
 // ListingImage represents a listing image
 type ListingImage struct {
-	ID        string    `gorm:"type:uuid;primary_key" json:"id"`
-	ListingID string    `gorm:"type:uuid;not null" json:"listing_id"`
-	URL       string    `gorm:"not null" json:"url"`
-	Caption   string    `json:"caption"`
-	Order     int       `gorm:"default:0" json:"order"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string        `gorm:"type:uuid;primary_key" json:"id"`
+	ListingID ids.ListingID `gorm:"type:uuid;not null" json:"listing_id"`
+	URL       string        `gorm:"size:500;not null" json:"url"`
+	Caption   string        `gorm:"size:255" json:"caption"`
+	Order     int           `gorm:"default:0" json:"order"`
+	// StorageKey locates the uploaded original in the image store
+	StorageKey string `gorm:"size:255" json:"-"`
+	// Variants are the resized copies made once the photo is processed
+	Variants    []ImageVariant `gorm:"type:jsonb;serializer:json" json:"variants,omitempty"`
+	ProcessedAt *time.Time     `json:"processed_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
 }
 
 // ListingAttribute represents dynamic attributes for listings
 type ListingAttribute struct {
-	ID        string    `gorm:"type:uuid;primary_key" json:"id"`
-	ListingID string    `gorm:"type:uuid;not null" json:"listing_id"`
-	Key       string    `gorm:"not null" json:"key"`
-	Value     string    `gorm:"not null" json:"value"`
-	CreatedAt time.Time `json:"created_at"`
-}
-
-// ListingTag represents tags for listings
-type ListingTag struct {
-	ID        string    `gorm:"type:uuid;primary_key" json:"id"`
-	Name      string    `gorm:"uniqueIndex;not null" json:"name"`
-	Color     string    `json:"color"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string        `gorm:"type:uuid;primary_key" json:"id"`
+	ListingID ids.ListingID `gorm:"type:uuid;not null" json:"listing_id"`
+	Key       string        `gorm:"size:100;not null" json:"key"`
+	Value     string        `gorm:"size:255;not null" json:"value"`
+	CreatedAt time.Time     `json:"created_at"`
+	// Label is filled in, in the reader's locale, when listings are served
+	Label string `gorm:"-" json:"label,omitempty"`
 }
 
 // NewListing creates a new listing
-func NewListing(sellerID, categoryID, title, description string, price float64, condition Condition, location Location) (*Listing, error) {
+func NewListing(sellerID ids.UserID, categoryID, title, description string, price money.Money, condition Condition, location Location) (*Listing, error) {
 	if sellerID == "" {
 		return nil, errors.ValidationError("seller ID is required")
 	}
@@ -118,78 +166,172 @@ func NewListing(sellerID, categoryID, title, description string, price float64,
 	if title == "" {
 		return nil, errors.ValidationError("title is required")
 	}
-	if price <= 0 {
+	if !price.IsPositive() {
 		return nil, errors.ValidationError("price must be greater than 0")
 	}
+	if !money.IsSupported(price.Currency) {
+		return nil, errors.ValidationError("currency is not supported")
+	}
+
+	listing, err := NewDraft(sellerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	listing.CategoryID = categoryID
+	listing.Title = title
+	listing.Description = description
+	listing.Price = price
+	listing.Condition = condition
+	listing.Location = location
+	return listing, nil
+}
 
-	// Set expiration to 30 days from now
-	expiresAt := time.Now().AddDate(0, 0, 30)
+// NewDraft creates an empty draft for the seller to fill in bit by bit. The
+// draft is only checked in full when it is published.
+func NewDraft(sellerID ids.UserID, now time.Time) (*Listing, error) {
+	if sellerID == "" {
+		return nil, errors.ValidationError("seller ID is required")
+	}
 
 	return &Listing{
-		ID:             uuid.New().String(),
-		SellerID:       sellerID,
-		CategoryID:     categoryID,
-		Title:          title,
-		Description:    description,
-		Price:          price,
-		Currency:       "GHS",
-		Condition:      condition,
-		Status:         ListingStatusDraft,
-		Location:       location,
-		Images:         []ListingImage{},
-		Attributes:     []ListingAttribute{},
-		Tags:           []ListingTag{},
-		ViewsCount:     0,
-		FavoritesCount: 0,
-		IsNegotiable:   true,
-		IsPromoted:     false,
-		ExpiresAt:      expiresAt,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		ID:           ids.NewListingID(),
+		SellerID:     sellerID,
+		Price:        money.New(0, money.DefaultCurrency),
+		Status:       ListingStatusDraft,
+		Images:       []ListingImage{},
+		Attributes:   []ListingAttribute{},
+		Tags:         []ListingTag{},
+		IsNegotiable: true,
+		IsPromoted:   false,
+		// Set expiration to 30 days from now
+		ExpiresAt: now.AddDate(0, 0, ListingLifetimeDays),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}, nil
 }
 
-// Activate activates the listing
-func (l *Listing) Activate() error {
+// ListingDetails are the parts of a listing its seller describes
+type ListingDetails struct {
+	CategoryID   string
+	Title        string
+	Description  string
+	Price        money.Money
+	Condition    Condition
+	Location     Location
+	IsNegotiable bool
+}
+
+// Details returns what the seller described about the listing
+func (l *Listing) Details() ListingDetails {
+	return ListingDetails{
+		CategoryID:   l.CategoryID,
+		Title:        l.Title,
+		Description:  l.Description,
+		Price:        l.Price,
+		Condition:    l.Condition,
+		Location:     l.Location,
+		IsNegotiable: l.IsNegotiable,
+	}
+}
+
+// UpdateDetails replaces what the seller described about the listing. Sold
+// listings cannot be changed. Drafts may be left incomplete; they are checked
+// in full when they are published.
+func (l *Listing) UpdateDetails(details ListingDetails) error {
 	if l.Status == ListingStatusSold {
-		return errors.ValidationError("cannot activate sold listing")
+		return errors.ValidationError("sold listings cannot be changed")
+	}
+	if details.Price.IsNegative() {
+		return errors.ValidationError("price must not be negative")
+	}
+	if !money.IsSupported(details.Price.Currency) {
+		return errors.ValidationError("currency is not supported")
+	}
+	if l.Status != ListingStatusDraft {
+		if err := details.ensureComplete(); err != nil {
+			return err
+		}
 	}
 
-	l.Status = ListingStatusActive
+	if details.CategoryID != l.CategoryID {
+		l.Category = Category{}
+	}
+	l.CategoryID = details.CategoryID
+	l.Title = details.Title
+	l.Description = details.Description
+	l.Price = details.Price
+	l.Condition = details.Condition
+	l.Location = details.Location
+	l.IsNegotiable = details.IsNegotiable
 	l.UpdatedAt = time.Now()
 	return nil
 }
 
-// Deactivate deactivates the listing
-func (l *Listing) Deactivate() {
-	l.Status = ListingStatusInactive
-	l.UpdatedAt = time.Now()
+// ensureComplete rejects details a live listing cannot do without
+func (d ListingDetails) ensureComplete() error {
+	if d.CategoryID == "" {
+		return errors.ValidationError("category ID is required")
+	}
+	if d.Title == "" {
+		return errors.ValidationError("title is required")
+	}
+	if !d.Price.IsPositive() {
+		return errors.ValidationError("price must be greater than 0")
+	}
+	if !d.Condition.IsValid() {
+		return errors.ValidationError("condition is required")
+	}
+	return nil
 }
 
-// MarkAsSold marks the listing as sold
-func (l *Listing) MarkAsSold() {
-	l.Status = ListingStatusSold
-	l.UpdatedAt = time.Now()
+// Activate activates the listing. Drafts must have been filled in.
+func (l *Listing) Activate() error {
+	if !l.Status.CanTransitionTo(ListingStatusActive) {
+		return transitionError(l.Status, ListingStatusActive)
+	}
+	if err := l.ensureNotHeld(); err != nil {
+		return err
+	}
+	if err := l.Details().ensureComplete(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := l.transitionTo(ListingStatusActive, now); err != nil {
+		return err
+	}
+	l.UpdatedAt = now
+	return nil
 }
 
-// IncrementViews increments the view count
-func (l *Listing) IncrementViews() {
-	l.ViewsCount++
-	l.UpdatedAt = time.Now()
+// Deactivate takes a published listing off the marketplace
+func (l *Listing) Deactivate() error {
+	return l.transitionTo(ListingStatusInactive, time.Now())
 }
 
-// IncrementFavorites increments the favorites count
-func (l *Listing) IncrementFavorites() {
-	l.FavoritesCount++
-	l.UpdatedAt = time.Now()
+// MarkAsSold marks a published listing as sold
+func (l *Listing) MarkAsSold() error {
+	return l.transitionTo(ListingStatusSold, time.Now())
 }
 
-// DecrementFavorites decrements the favorites count
-func (l *Listing) DecrementFavorites() {
-	if l.FavoritesCount > 0 {
-		l.FavoritesCount--
-		l.UpdatedAt = time.Now()
+// Expire records that a live listing reached the end of its lifetime,
+// reporting false if it has not or is no longer live
+func (l *Listing) Expire(now time.Time) bool {
+	if l.Status != ListingStatusActive || now.Before(l.ExpiresAt) {
+		return false
 	}
+	return l.transitionTo(ListingStatusExpired, now) == nil
+}
+
+// ReassignSeller moves the listing to another seller account
+func (l *Listing) ReassignSeller(sellerID ids.UserID) error {
+	if sellerID == "" {
+		return errors.ValidationError("seller ID is required")
+	}
+
+	l.SellerID = sellerID
+	l.UpdatedAt = time.Now()
+	return nil
 }
 
 // AddImage adds an image to the listing
@@ -219,33 +361,205 @@ func (l *Listing) AddAttribute(key, value string) {
 	l.UpdatedAt = time.Now()
 }
 
-// Promote promotes the listing
-func (l *Listing) Promote(duration time.Duration) {
+// Promote promotes the listing. A listing that is already promoted stays
+// promoted for duration past its current end.
+func (l *Listing) Promote(duration time.Duration, now time.Time) {
+	from := now
+	if l.IsPromoted && l.PromotedUntil != nil && l.PromotedUntil.After(from) {
+		from = *l.PromotedUntil
+	}
 	l.IsPromoted = true
-	promotedUntil := time.Now().Add(duration)
+	promotedUntil := from.Add(duration)
 	l.PromotedUntil = &promotedUntil
+	l.UpdatedAt = now
+}
+
+// Unpromote ends a promotion whose time is up. It reports whether the
+// listing changed.
+func (l *Listing) Unpromote(now time.Time) bool {
+	if !l.IsPromoted || (l.PromotedUntil != nil && now.Before(*l.PromotedUntil)) {
+		return false
+	}
+
+	l.IsPromoted = false
+	l.PromotedUntil = nil
+	l.UpdatedAt = now
+	return true
+}
+
+// Reserve holds the listing for a buyer while they pay. A buyer may extend
+// their own reservation; a listing reserved for someone else cannot be reserved.
+func (l *Listing) Reserve(buyerID ids.UserID, until, now time.Time) error {
+	if !l.IsActiveAt(now) {
+		return errors.ValidationError("listing is not available")
+	}
+	if buyerID == l.SellerID {
+		return errors.ValidationError("cannot buy your own listing")
+	}
+	if l.IsReservedAt(now) && *l.ReservedFor != buyerID {
+		return errors.ConflictError("listing is reserved by another buyer")
+	}
+
+	l.ReservedFor = &buyerID
+	l.ReservedUntil = &until
+	l.UpdatedAt = now
+	return nil
+}
+
+// ReleaseReservation lifts the buyer's reservation. It reports false if the
+// listing was not reserved for the buyer.
+func (l *Listing) ReleaseReservation(buyerID ids.UserID) bool {
+	if l.ReservedFor == nil || *l.ReservedFor != buyerID {
+		return false
+	}
+
+	l.ReservedFor = nil
+	l.ReservedUntil = nil
 	l.UpdatedAt = time.Now()
+	return true
+}
+
+// IsReserved checks if the listing is currently held for a buyer
+func (l *Listing) IsReserved() bool {
+	return l.IsReservedAt(time.Now())
+}
+
+// IsReservedAt checks if the listing is held for a buyer at now
+func (l *Listing) IsReservedAt(now time.Time) bool {
+	return l.ReservedFor != nil && l.ReservedUntil != nil && now.Before(*l.ReservedUntil)
 }
 
 // IsExpired checks if the listing has expired
 func (l *Listing) IsExpired() bool {
-	return time.Now().After(l.ExpiresAt)
+	return l.IsExpiredAt(time.Now())
+}
+
+// IsExpiredAt checks if the listing has expired by now
+func (l *Listing) IsExpiredAt(now time.Time) bool {
+	return now.After(l.ExpiresAt)
 }
 
 // IsActive checks if the listing is active
 func (l *Listing) IsActive() bool {
-	return l.Status == ListingStatusActive && !l.IsExpired()
+	return l.IsActiveAt(time.Now())
+}
+
+// IsActiveAt checks if the listing is live at now
+func (l *Listing) IsActiveAt(now time.Time) bool {
+	return l.Status == ListingStatusActive && !l.IsExpiredAt(now)
+}
+
+// IsPromotedAt checks if the listing's promotion runs at now. A promotion
+// whose time is up no longer counts, even before the worker ends it.
+func (l *Listing) IsPromotedAt(now time.Time) bool {
+	return l.IsPromoted && l.PromotedUntil != nil && now.Before(*l.PromotedUntil)
+}
+
+// ListingSort represents the ordering of search results
+type ListingSort string
+
+const (
+	ListingSortRelevance  ListingSort = "relevance"
+	ListingSortNewest     ListingSort = "newest"
+	ListingSortPriceAsc   ListingSort = "price_asc"
+	ListingSortPriceDesc  ListingSort = "price_desc"
+	ListingSortMostViewed ListingSort = "most_viewed"
+	ListingSortDistance   ListingSort = "distance"
+)
+
+// ListingSearchCriteria represents search filters over active listings.
+// Leaving SellerID and SellerIDs empty searches the whole marketplace.
+type ListingSearchCriteria struct {
+	Query      string
+	SellerID   ids.UserID
+	SellerIDs  []ids.UserID
+	CategoryID string
+	Condition  Condition
+	MinPrice   *float64
+	MaxPrice   *float64
+	Region     string
+	City       string
+	Negotiable *bool
+	// Tag, when set, only matches listings carrying the tag of that
+	// normalized name
+	Tag string
+	// Near, when set, only matches listings pinned within its radius
+	Near *GeoRadius
+	// PromotedAt, when set, only matches listings whose promotion runs at
+	// that time
+	PromotedAt *time.Time
+	Sort       ListingSort
+	// After, when set, starts the results after the listing it marks. It
+	// does not affect counts or facets.
+	After *ListingCursor
+}
+
+// SortOrder is the order results come in: the requested sort, or else
+// nearest first for searches near a point, relevance for text queries and
+// newest first otherwise
+func (c ListingSearchCriteria) SortOrder() ListingSort {
+	if c.Sort != "" {
+		return c.Sort
+	}
+	if c.Near != nil {
+		return ListingSortDistance
+	}
+	if c.Query != "" {
+		return ListingSortRelevance
+	}
+	return ListingSortNewest
+}
+
+// FacetCount represents the number of matching listings sharing a value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SearchFacets summarises the listings matching a search
+type SearchFacets struct {
+	Categories []FacetCount `json:"categories"`
+	Conditions []FacetCount `json:"conditions"`
+	Regions    []FacetCount `json:"regions"`
+	MinPrice   float64      `json:"min_price"`
+	MaxPrice   float64      `json:"max_price"`
 }
 
 // ListingRepository defines the interface for listing persistence
 type ListingRepository interface {
 	Save(listing *Listing) error
-	FindByID(id string) (*Listing, error)
-	FindBySeller(sellerID string, limit, offset int) ([]*Listing, error)
+	FindByID(id ids.ListingID) (*Listing, error)
+	// FindByIDs finds listings by ID in no particular order; missing IDs are left out
+	FindByIDs(listingIDs []ids.ListingID) ([]*Listing, error)
+	// FindAttributes finds the attributes of the listings
+	FindAttributes(listingIDs []ids.ListingID) ([]ListingAttribute, error)
+	FindBySeller(sellerID ids.UserID, limit, offset int) ([]*Listing, error)
 	FindByCategory(categoryID string, limit, offset int) ([]*Listing, error)
-	Search(query string, filters map[string]interface{}, limit, offset int) ([]*Listing, error)
+	// FindIDsByFilter finds a page of the IDs of listings in any status
+	// matching an administrator's filter, oldest first
+	FindIDsByFilter(filter BulkListingFilter, limit, offset int) ([]ids.ListingID, error)
+	// Search finds a page of active listings matching the criteria, in their sort order
+	Search(criteria ListingSearchCriteria, limit, offset int) ([]*Listing, error)
+	// FindByCriteria finds a page of matching listings with the total match count
+	FindByCriteria(criteria ListingSearchCriteria, limit, offset int) ([]*Listing, int64, error)
+	Facets(criteria ListingSearchCriteria) (*SearchFacets, error)
+	CountByLocation(region string) ([]FacetCount, error)
+	// FindDueScheduled finds scheduled drafts whose publish time has come, earliest first
+	FindDueScheduled(now time.Time, limit int) ([]*Listing, error)
+	// FindLapsedPromotions finds promoted listings whose promotion has ended, earliest first
+	FindLapsedPromotions(now time.Time, limit int) ([]*Listing, error)
+	// FindLapsedListings finds live listings past their expiry that no buyer
+	// holds a reservation on, earliest expiry first
+	FindLapsedListings(now time.Time, limit int) ([]*Listing, error)
+	// FindStaleDrafts finds unscheduled drafts last saved before the given
+	// time, least recently saved first
+	FindStaleDrafts(before time.Time, limit int) ([]*Listing, error)
+	CountScheduledBySeller(sellerID ids.UserID) (int64, error)
+	CountActiveBySeller(sellerID ids.UserID) (int64, error)
 	Update(listing *Listing) error
-	Delete(id string) error
+	// UpdateImage stores changes to one of a listing's photos
+	UpdateImage(image *ListingImage) error
+	Delete(id ids.ListingID) error
 }
 
 // CategoryRepository defines the interface for category persistence
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+)
+
+// ListingHold is why a live listing was taken down because of its seller's
+// account or reports from users rather than by the seller
+type ListingHold string
+
+const (
+	ListingHoldSellerSuspended ListingHold = "seller_suspended"
+	ListingHoldSellerRejected  ListingHold = "seller_verification_rejected"
+	ListingHoldSellerDeleted   ListingHold = "seller_deleted"
+	// ListingHoldAdminSuspended is set by an administrator's bulk suspension
+	ListingHoldAdminSuspended ListingHold = "admin_suspended"
+	// ListingHoldReported is set when enough users report the listing, until
+	// a moderator reviews the reports
+	ListingHoldReported ListingHold = "reported"
+	// ListingHoldRemoved is set when a moderator upholds a report
+	ListingHoldRemoved ListingHold = "removed_after_report"
+)
+
+// IsHeld checks if the listing is down until its seller's account is back in
+// good standing
+func (l *Listing) IsHeld() bool {
+	return len(l.Holds) > 0
+}
+
+// Hold takes a live listing down for a reason tied to its seller's account.
+// A listing already held for another reason records this one too, so it only
+// goes back live once every reason is lifted. It reports whether the listing
+// changed.
+func (l *Listing) Hold(reason ListingHold) bool {
+	if l.heldFor(reason) || (l.Status != ListingStatusActive && !l.IsHeld()) {
+		return false
+	}
+
+	if err := l.transitionTo(ListingStatusInactive, time.Now()); err != nil {
+		return false
+	}
+	l.Holds = append(l.Holds, reason)
+	l.UpdatedAt = time.Now()
+	return true
+}
+
+// Release lifts one reason the listing was held for. Once none remain the
+// listing goes back live until it was due to expire anyway, unless the
+// seller sold it meanwhile. It reports whether the listing changed.
+func (l *Listing) Release(reason ListingHold) bool {
+	if !l.heldFor(reason) {
+		return false
+	}
+
+	var holds []ListingHold
+	for _, hold := range l.Holds {
+		if hold != reason {
+			holds = append(holds, hold)
+		}
+	}
+	l.Holds = holds
+	if !l.IsHeld() && l.Status.CanTransitionTo(ListingStatusActive) {
+		_ = l.transitionTo(ListingStatusActive, time.Now())
+	}
+	l.UpdatedAt = time.Now()
+	return true
+}
+
+// ensureNotHeld rejects seller changes that would put a held listing back live
+func (l *Listing) ensureNotHeld() error {
+	if l.IsHeld() {
+		return errors.ValidationError("listing is on hold until the seller's account is back in good standing")
+	}
+	return nil
+}
+
+func (l *Listing) heldFor(reason ListingHold) bool {
+	for _, hold := range l.Holds {
+		if hold == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListing_HoldAndRelease(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+
+	// Only live listings are held
+	assert.False(t, listing.Hold(domain.ListingHoldSellerSuspended))
+	require.NoError(t, listing.Activate())
+
+	assert.True(t, listing.Hold(domain.ListingHoldSellerSuspended))
+	assert.Equal(t, domain.ListingStatusInactive, listing.Status)
+	assert.False(t, listing.Hold(domain.ListingHoldSellerSuspended), "holding twice changes nothing")
+
+	// The seller cannot put a held listing back live
+	assert.Error(t, listing.Activate())
+
+	// A second reason keeps the listing down until both are lifted
+	assert.True(t, listing.Hold(domain.ListingHoldSellerRejected))
+	assert.True(t, listing.Release(domain.ListingHoldSellerSuspended))
+	assert.Equal(t, domain.ListingStatusInactive, listing.Status)
+	assert.False(t, listing.Release(domain.ListingHoldSellerSuspended))
+
+	assert.True(t, listing.Release(domain.ListingHoldSellerRejected))
+	assert.False(t, listing.IsHeld())
+	assert.True(t, listing.IsActive())
+}
+
+func TestListing_HoldLeavesSellerChoices(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	require.NoError(t, listing.Activate())
+	require.NoError(t, listing.Deactivate())
+
+	// Listings the seller took down themselves stay down
+	assert.False(t, listing.Hold(domain.ListingHoldSellerSuspended))
+	assert.False(t, listing.Release(domain.ListingHoldSellerSuspended))
+	assert.Equal(t, domain.ListingStatusInactive, listing.Status)
+}
+
+func TestListing_ReleaseKeepsSoldListingsSold(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	require.NoError(t, listing.Activate())
+	require.True(t, listing.Hold(domain.ListingHoldAdminSuspended))
+	require.NoError(t, listing.MarkAsSold())
+
+	assert.True(t, listing.Release(domain.ListingHoldAdminSuspended))
+	assert.Equal(t, domain.ListingStatusSold, listing.Status)
+}
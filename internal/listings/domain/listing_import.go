@@ -0,0 +1,252 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// Limits on a listing import file
+const (
+	MaxImportFileSize = 5 << 20
+	MaxImportRows     = 1000
+)
+
+// Columns of a listing import file with a listing field. Any other column is
+// an attribute named by its header, such as brand or storage.
+const (
+	ImportColumnCategoryID   = "category_id"
+	ImportColumnTitle        = "title"
+	ImportColumnDescription  = "description"
+	ImportColumnPrice        = "price"
+	ImportColumnCondition    = "condition"
+	ImportColumnRegion       = "region"
+	ImportColumnCity         = "city"
+	ImportColumnArea         = "area"
+	ImportColumnIsNegotiable = "is_negotiable"
+)
+
+// ImportRequiredColumns must be in the header of every import file
+var ImportRequiredColumns = []string{
+	ImportColumnCategoryID,
+	ImportColumnTitle,
+	ImportColumnPrice,
+	ImportColumnCondition,
+	ImportColumnRegion,
+	ImportColumnCity,
+}
+
+// importFieldColumns are the columns that are not attributes
+var importFieldColumns = map[string]bool{
+	ImportColumnCategoryID:   true,
+	ImportColumnTitle:        true,
+	ImportColumnDescription:  true,
+	ImportColumnPrice:        true,
+	ImportColumnCondition:    true,
+	ImportColumnRegion:       true,
+	ImportColumnCity:         true,
+	ImportColumnArea:         true,
+	ImportColumnIsNegotiable: true,
+}
+
+// ListingImportStatus represents how far an import has got
+type ListingImportStatus string
+
+const (
+	ListingImportPending   ListingImportStatus = "pending"
+	ListingImportCompleted ListingImportStatus = "completed"
+)
+
+// ImportRowStatus represents the result of importing one row
+type ImportRowStatus string
+
+const (
+	ImportRowPending ImportRowStatus = "pending"
+	// ImportRowCreated means the row became a draft listing
+	ImportRowCreated ImportRowStatus = "created"
+	ImportRowFailed  ImportRowStatus = "failed"
+)
+
+// ListingImport is a file of listings a seller uploaded at once. The rows are
+// turned into draft listings by the worker, in batches, so the upload
+// returns at once; each row gets a ListingImportRow with its result.
+type ListingImport struct {
+	ID        string              `gorm:"type:uuid;primary_key" json:"id"`
+	SellerID  ids.UserID          `gorm:"type:uuid;not null;index" json:"seller_id"`
+	FileName  string              `gorm:"size:255" json:"file_name"`
+	Status    ListingImportStatus `gorm:"size:20;not null;index" json:"status"`
+	Total     int                 `gorm:"not null;default:0" json:"total"`
+	Processed int                 `gorm:"not null;default:0" json:"processed"`
+	Created   int                 `gorm:"not null;default:0" json:"created"`
+	Failed    int                 `gorm:"not null;default:0" json:"failed"`
+	// FinishedAt is when the last row was processed
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ListingImportRow is one row of an import file and the result of importing it
+type ListingImportRow struct {
+	ImportID string `gorm:"type:uuid;primary_key" json:"import_id"`
+	// Line is the row's line in the file, the header being line 1
+	Line int `gorm:"primary_key;autoIncrement:false" json:"line"`
+	// Values are the row's cells by column
+	Values      map[string]string `gorm:"type:jsonb;serializer:json" json:"values"`
+	Status      ImportRowStatus   `gorm:"size:20;not null" json:"status"`
+	Error       string            `json:"error,omitempty"`
+	ListingID   *ids.ListingID    `gorm:"type:uuid" json:"listing_id,omitempty"`
+	ProcessedAt *time.Time        `json:"processed_at,omitempty"`
+}
+
+// ImportRecord is a row read from an import file
+type ImportRecord struct {
+	// Line is the row's line in the file, the header being line 1
+	Line int
+	// Values are the row's cells by column
+	Values map[string]string
+}
+
+// NewListingImport creates a pending import of the rows of a file
+func NewListingImport(sellerID ids.UserID, fileName string, rows []ImportRecord, now time.Time) (*ListingImport, []*ListingImportRow, error) {
+	if sellerID == "" {
+		return nil, nil, errors.ValidationError("seller ID is required")
+	}
+	if len(rows) == 0 {
+		return nil, nil, errors.ValidationError("import file has no listings")
+	}
+	if len(rows) > MaxImportRows {
+		return nil, nil, errors.ValidationError(fmt.Sprintf("an import file can hold at most %d listings", MaxImportRows))
+	}
+
+	listingImport := &ListingImport{
+		ID:        uuid.New().String(),
+		SellerID:  sellerID,
+		FileName:  fileName,
+		Status:    ListingImportPending,
+		Total:     len(rows),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	importRows := make([]*ListingImportRow, 0, len(rows))
+	for _, row := range rows {
+		importRows = append(importRows, &ListingImportRow{
+			ImportID: listingImport.ID,
+			Line:     row.Line,
+			Values:   row.Values,
+			Status:   ImportRowPending,
+		})
+	}
+	return listingImport, importRows, nil
+}
+
+// RecordProgress updates the import's counts from the number of its rows in
+// each status. The import completes once no row is pending.
+func (i *ListingImport) RecordProgress(counts map[ImportRowStatus]int, now time.Time) {
+	i.Created = counts[ImportRowCreated]
+	i.Failed = counts[ImportRowFailed]
+	i.Processed = i.Created + i.Failed
+	i.UpdatedAt = now
+	if i.Status == ListingImportPending && counts[ImportRowPending] == 0 {
+		i.Status = ListingImportCompleted
+		i.FinishedAt = &now
+	}
+}
+
+// IsFinished checks if every row of the import has been processed
+func (i *ListingImport) IsFinished() bool {
+	return i.Status == ListingImportCompleted
+}
+
+// Listing reads the listing described by the row: its details and its
+// attributes, keyed by column
+func (r *ListingImportRow) Listing() (ListingDetails, map[string]string, error) {
+	details := ListingDetails{
+		CategoryID:   r.Values[ImportColumnCategoryID],
+		Title:        r.Values[ImportColumnTitle],
+		Description:  r.Values[ImportColumnDescription],
+		Condition:    Condition(r.Values[ImportColumnCondition]),
+		IsNegotiable: true,
+		Location: Location{
+			Region: r.Values[ImportColumnRegion],
+			City:   r.Values[ImportColumnCity],
+			Area:   r.Values[ImportColumnArea],
+		},
+	}
+
+	if price := r.Values[ImportColumnPrice]; price != "" {
+		parsed, err := money.ParseMajor(price, money.DefaultCurrency)
+		if err != nil {
+			return ListingDetails{}, nil, errors.ValidationError("price must be a number")
+		}
+		details.Price = parsed
+	}
+	if negotiable := r.Values[ImportColumnIsNegotiable]; negotiable != "" {
+		parsed, err := strconv.ParseBool(negotiable)
+		if err != nil {
+			return ListingDetails{}, nil, errors.ValidationError("is_negotiable must be true or false")
+		}
+		details.IsNegotiable = parsed
+	}
+	if details.Condition != "" && !details.Condition.IsValid() {
+		return ListingDetails{}, nil, errors.ValidationError("condition must be one of new, like_new, good, fair, poor, for_parts")
+	}
+	if err := details.ensureComplete(); err != nil {
+		return ListingDetails{}, nil, err
+	}
+
+	attributes := make(map[string]string)
+	for column, value := range r.Values {
+		if !importFieldColumns[column] && value != "" {
+			attributes[column] = value
+		}
+	}
+	return details, attributes, nil
+}
+
+// Finish records the result of importing the row: the listing created from
+// it, or the failure
+func (r *ListingImportRow) Finish(listingID ids.ListingID, err error, now time.Time) {
+	r.Status = ImportRowCreated
+	r.Error = ""
+	r.ListingID = &listingID
+	if err != nil {
+		r.Status = ImportRowFailed
+		r.ListingID = nil
+		r.Error = err.Error()
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			r.Error = domainErr.Message
+		}
+	}
+	r.ProcessedAt = &now
+}
+
+// ImportColumn normalizes a header of an import file to its column name
+func ImportColumn(header string) string {
+	return strings.ToLower(strings.TrimSpace(header))
+}
+
+// ListingImportRepository defines the interface for listing import persistence
+type ListingImportRepository interface {
+	// Save stores a new import together with its rows
+	Save(listingImport *ListingImport, rows []*ListingImportRow) error
+	Update(listingImport *ListingImport) error
+	FindByID(id string) (*ListingImport, error)
+	// FindBySeller finds a seller's imports, newest first
+	FindBySeller(sellerID ids.UserID, limit, offset int) ([]*ListingImport, int64, error)
+	// FindUnfinished finds imports with rows still to be processed, oldest first
+	FindUnfinished(limit int) ([]*ListingImport, error)
+	// FindPendingRows finds rows of an import still to be processed, in file order
+	FindPendingRows(importID string, limit int) ([]*ListingImportRow, error)
+	// FindRows finds the rows of an import in file order, optionally in one status
+	FindRows(importID string, status ImportRowStatus, limit, offset int) ([]*ListingImportRow, int64, error)
+	// CountRows counts the rows of an import by status
+	CountRows(importID string) (map[ImportRowStatus]int, error)
+	UpdateRow(row *ListingImportRow) error
+}
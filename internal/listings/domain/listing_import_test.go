@@ -0,0 +1,68 @@
+package domain_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListingImportRow_Listing(t *testing.T) {
+	row := &domain.ListingImportRow{Values: map[string]string{
+		"category_id": "phones",
+		"title":       "Used phone",
+		"price":       "150.50",
+		"condition":   "fair",
+		"region":      "Ashanti",
+		"city":        "Kumasi",
+		"storage":     "64GB",
+	}}
+	details, attributes, err := row.Listing()
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(150.50), details.Price)
+	assert.True(t, details.IsNegotiable, "listings are negotiable unless the row says otherwise")
+	assert.Equal(t, "Kumasi", details.Location.City)
+	assert.Equal(t, map[string]string{"storage": "64GB"}, attributes)
+
+	for column, value := range map[string]string{"price": "free", "condition": "broken", "is_negotiable": "maybe", "title": ""} {
+		broken := &domain.ListingImportRow{Values: make(map[string]string)}
+		for k, v := range row.Values {
+			broken.Values[k] = v
+		}
+		broken.Values[column] = value
+		_, _, err := broken.Listing()
+		assert.Error(t, err, column)
+	}
+}
+
+func TestListingImport_RecordProgress(t *testing.T) {
+	now := time.Now()
+	_, _, err := domain.NewListingImport("seller-a", "empty.csv", nil, now)
+	assert.Error(t, err)
+
+	rows := make([]domain.ImportRecord, domain.MaxImportRows+1)
+	_, _, err = domain.NewListingImport("seller-a", "huge.csv", rows, now)
+	assert.Error(t, err)
+
+	listingImport, importRows, err := domain.NewListingImport("seller-a", "phones.csv", rows[:3], now)
+	require.NoError(t, err)
+	require.Len(t, importRows, 3)
+
+	importRows[0].Finish("listing-1", nil, now)
+	importRows[1].Finish("", fmt.Errorf("boom"), now)
+	assert.Equal(t, domain.ImportRowFailed, importRows[1].Status)
+	assert.Nil(t, importRows[1].ListingID)
+
+	listingImport.RecordProgress(map[domain.ImportRowStatus]int{domain.ImportRowCreated: 1, domain.ImportRowFailed: 1, domain.ImportRowPending: 1}, now)
+	assert.False(t, listingImport.IsFinished())
+	assert.Equal(t, 2, listingImport.Processed)
+
+	listingImport.RecordProgress(map[domain.ImportRowStatus]int{domain.ImportRowCreated: 2, domain.ImportRowFailed: 1}, now)
+	assert.True(t, listingImport.IsFinished())
+	assert.NotNil(t, listingImport.FinishedAt)
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+)
+
+// listingTransitions are the statuses a listing may move to from each
+// status. Drafts go live once; live listings are taken down, sold or expire;
+// taken down and expired listings may go live again or be sold; sold
+// listings are final and are relisted as new drafts instead.
+var listingTransitions = map[ListingStatus][]ListingStatus{
+	ListingStatusDraft:    {ListingStatusActive},
+	ListingStatusActive:   {ListingStatusInactive, ListingStatusSold, ListingStatusExpired},
+	ListingStatusInactive: {ListingStatusActive, ListingStatusSold},
+	ListingStatusExpired:  {ListingStatusActive, ListingStatusInactive, ListingStatusSold},
+	ListingStatusSold:     {},
+}
+
+// CanTransitionTo checks if a listing in the status may move to another.
+// Staying in the same status is not a transition and is always allowed.
+func (s ListingStatus) CanTransitionTo(next ListingStatus) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range listingTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TakeStatusChanges returns the status changes of the listing since they
+// were last taken, oldest first, so they can be announced once it is saved
+func (l *Listing) TakeStatusChanges() []ListingStatusChanged {
+	changes := l.statusChanges
+	l.statusChanges = nil
+	return changes
+}
+
+// transitionTo moves the listing to a status, recording the change. Moves
+// the state machine does not allow are refused; staying in the same status
+// records nothing.
+func (l *Listing) transitionTo(next ListingStatus, now time.Time) error {
+	if !l.Status.CanTransitionTo(next) {
+		return transitionError(l.Status, next)
+	}
+	if l.Status == next {
+		return nil
+	}
+
+	l.statusChanges = append(l.statusChanges, ListingStatusChanged{
+		ListingID: l.ID,
+		SellerID:  l.SellerID,
+		From:      l.Status,
+		To:        next,
+		Timestamp: now,
+	})
+	l.Status = next
+	l.UpdatedAt = now
+	return nil
+}
+
+// transitionError explains why a listing cannot move from one status to
+// another, with both statuses in its details
+func transitionError(from, to ListingStatus) error {
+	var message string
+	switch {
+	case to == ListingStatusDraft:
+		message = "listings cannot go back to being drafts"
+	case from == ListingStatusSold && to == ListingStatusActive:
+		message = "cannot activate sold listing"
+	case from == ListingStatusSold && to == ListingStatusInactive:
+		message = "sold listings cannot be deactivated"
+	case from == ListingStatusDraft && to == ListingStatusSold:
+		message = "only published listings can be marked as sold"
+	case from == ListingStatusDraft && to == ListingStatusInactive:
+		message = "drafts cannot be deactivated"
+	default:
+		message = "only live listings can expire"
+	}
+	return errors.ValidationError(message).WithDetails("from", from).WithDetails("to", to)
+}
@@ -0,0 +1,69 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListingStatus_CanTransitionTo(t *testing.T) {
+	cases := []struct {
+		from, to domain.ListingStatus
+		allowed  bool
+	}{
+		{domain.ListingStatusDraft, domain.ListingStatusActive, true},
+		{domain.ListingStatusDraft, domain.ListingStatusInactive, false},
+		{domain.ListingStatusDraft, domain.ListingStatusSold, false},
+		{domain.ListingStatusActive, domain.ListingStatusInactive, true},
+		{domain.ListingStatusActive, domain.ListingStatusSold, true},
+		{domain.ListingStatusActive, domain.ListingStatusExpired, true},
+		{domain.ListingStatusActive, domain.ListingStatusDraft, false},
+		{domain.ListingStatusInactive, domain.ListingStatusActive, true},
+		{domain.ListingStatusInactive, domain.ListingStatusExpired, false},
+		{domain.ListingStatusExpired, domain.ListingStatusActive, true},
+		{domain.ListingStatusExpired, domain.ListingStatusSold, true},
+		{domain.ListingStatusSold, domain.ListingStatusActive, false},
+		{domain.ListingStatusSold, domain.ListingStatusInactive, false},
+		{domain.ListingStatusSold, domain.ListingStatusSold, true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.allowed, c.from.CanTransitionTo(c.to), "%s to %s", c.from, c.to)
+	}
+}
+
+func TestListing_StatusChanges(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+
+	// Drafts were never published, so they cannot be taken down or sold
+	assert.Error(t, listing.Deactivate())
+	assert.Error(t, listing.MarkAsSold())
+	assert.Empty(t, listing.TakeStatusChanges())
+
+	require.NoError(t, listing.Activate())
+	require.NoError(t, listing.Deactivate())
+	require.NoError(t, listing.Deactivate(), "taking down a listing twice changes nothing")
+	require.NoError(t, listing.MarkAsSold())
+
+	changes := listing.TakeStatusChanges()
+	require.Len(t, changes, 3)
+	assert.Equal(t, domain.ListingStatusDraft, changes[0].From)
+	assert.Equal(t, domain.ListingStatusActive, changes[0].To)
+	assert.Equal(t, domain.ListingStatusInactive, changes[1].To)
+	assert.Equal(t, domain.ListingStatusSold, changes[2].To)
+	assert.Equal(t, listing.ID, changes[2].ListingID)
+	assert.Empty(t, listing.TakeStatusChanges(), "changes are taken once")
+
+	// Sold listings are final
+	assert.Error(t, listing.Activate())
+	assert.Error(t, listing.Deactivate())
+	assert.False(t, listing.Expire(time.Now().AddDate(1, 0, 0)))
+	assert.False(t, listing.Hold(domain.ListingHoldAdminSuspended))
+	assert.Empty(t, listing.TakeStatusChanges())
+	assert.Equal(t, domain.ListingStatusSold, listing.Status)
+}
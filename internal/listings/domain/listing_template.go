@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// MaxListingTemplatesPerSeller is how many listing templates a seller can keep
+const MaxListingTemplatesPerSeller = 20
+
+// MaxTemplateAttributes is the most attributes a listing template can preset
+const MaxTemplateAttributes = 30
+
+// ListingTemplate is a preset a seller saved to post similar listings
+// quickly: the category, condition, description boilerplate and attributes
+// new listings made from it start with
+type ListingTemplate struct {
+	ID          string     `gorm:"type:uuid;primary_key" json:"id"`
+	SellerID    ids.UserID `gorm:"type:uuid;not null;index" json:"seller_id"`
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	CategoryID  string     `gorm:"type:uuid;not null" json:"category_id"`
+	Description string     `gorm:"type:text" json:"description"`
+	// Condition is left empty when listings made from the template vary
+	Condition  Condition         `gorm:"size:20" json:"condition,omitempty"`
+	Attributes map[string]string `gorm:"type:jsonb;serializer:json" json:"attributes"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// ListingTemplateDetails are what a seller presets in a listing template
+type ListingTemplateDetails struct {
+	Name        string
+	CategoryID  string
+	Description string
+	Condition   Condition
+	Attributes  map[string]string
+}
+
+// NewListingTemplate saves a listing template for a seller
+func NewListingTemplate(sellerID ids.UserID, details ListingTemplateDetails, now time.Time) (*ListingTemplate, error) {
+	if sellerID == "" {
+		return nil, errors.ValidationError("seller ID is required")
+	}
+	template := &ListingTemplate{
+		ID:        uuid.New().String(),
+		SellerID:  sellerID,
+		CreatedAt: now,
+	}
+	if err := template.Update(details, now); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// Update replaces what the template presets
+func (t *ListingTemplate) Update(details ListingTemplateDetails, now time.Time) error {
+	name := strings.TrimSpace(details.Name)
+	if name == "" {
+		return errors.ValidationError("listing template name is required")
+	}
+	if details.CategoryID == "" {
+		return errors.ValidationError("category ID is required")
+	}
+	if details.Condition != "" && !details.Condition.IsValid() {
+		return errors.ValidationError("condition must be one of new, like_new, good, fair, poor, for_parts")
+	}
+	if len(details.Attributes) > MaxTemplateAttributes {
+		return errors.ValidationError("a listing template can have at most 30 attributes")
+	}
+	attributes := make(map[string]string, len(details.Attributes))
+	for key, value := range details.Attributes {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return errors.ValidationError("attribute names cannot be empty")
+		}
+		value = strings.TrimSpace(value)
+		if len(value) > MaxAttributeValueLength {
+			return errors.ValidationError("attribute values must be at most 200 characters")
+		}
+		attributes[key] = value
+	}
+
+	t.Name = name
+	t.CategoryID = details.CategoryID
+	t.Description = strings.TrimSpace(details.Description)
+	t.Condition = details.Condition
+	t.Attributes = attributes
+	t.UpdatedAt = now
+	return nil
+}
+
+// AttributesWith returns the template's attributes with the given ones
+// added, a given attribute replacing the template's of the same name
+func (t *ListingTemplate) AttributesWith(attributes map[string]string) map[string]string {
+	merged := make(map[string]string, len(t.Attributes)+len(attributes))
+	for key, value := range t.Attributes {
+		merged[key] = value
+	}
+	for key, value := range attributes {
+		merged[strings.TrimSpace(key)] = value
+	}
+	return merged
+}
+
+// ListingTemplateRepository defines the interface for listing template persistence
+type ListingTemplateRepository interface {
+	Save(template *ListingTemplate) error
+	Update(template *ListingTemplate) error
+	Delete(id string) error
+	FindByID(id string) (*ListingTemplate, error)
+	// FindBySeller finds a seller's listing templates, newest first
+	FindBySeller(sellerID ids.UserID) ([]*ListingTemplate, error)
+	CountBySeller(sellerID ids.UserID) (int64, error)
+}
@@ -0,0 +1,58 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewListingTemplate_Validates(t *testing.T) {
+	now := time.Now()
+	valid := domain.ListingTemplateDetails{Name: "Phones", CategoryID: "category-1"}
+
+	_, err := domain.NewListingTemplate("", valid, now)
+	assert.Error(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(*domain.ListingTemplateDetails)
+	}{
+		{"blank name", func(d *domain.ListingTemplateDetails) { d.Name = "  " }},
+		{"no category", func(d *domain.ListingTemplateDetails) { d.CategoryID = "" }},
+		{"unknown condition", func(d *domain.ListingTemplateDetails) { d.Condition = "mint" }},
+		{"empty attribute name", func(d *domain.ListingTemplateDetails) { d.Attributes = map[string]string{" ": "x"} }},
+		{"long attribute value", func(d *domain.ListingTemplateDetails) {
+			d.Attributes = map[string]string{"notes": strings.Repeat("x", domain.MaxAttributeValueLength+1)}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := valid
+			tt.modify(&details)
+			_, err := domain.NewListingTemplate("seller-a", details, now)
+			assert.Error(t, err)
+		})
+	}
+
+	template, err := domain.NewListingTemplate("seller-a", valid, now)
+	require.NoError(t, err)
+	assert.Empty(t, template.Condition, "the condition is optional")
+}
+
+func TestListingTemplate_AttributesWith(t *testing.T) {
+	template, err := domain.NewListingTemplate("seller-a", domain.ListingTemplateDetails{
+		Name:       "Phones",
+		CategoryID: "category-1",
+		Attributes: map[string]string{"brand": "Samsung", "storage": "64GB"},
+	}, time.Now())
+	require.NoError(t, err)
+
+	merged := template.AttributesWith(map[string]string{" storage ": "128GB", "colour": "Black"})
+	assert.Equal(t, map[string]string{"brand": "Samsung", "storage": "128GB", "colour": "Black"}, merged)
+	assert.Equal(t, "64GB", template.Attributes["storage"], "the template is not changed")
+}
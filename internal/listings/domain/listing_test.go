@@ -0,0 +1,104 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListing_Reserve(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	now := time.Now()
+
+	// Drafts cannot be bought
+	assert.Error(t, listing.Reserve("buyer-a", now.Add(time.Hour), now))
+	require.NoError(t, listing.Activate())
+
+	assert.Error(t, listing.Reserve("seller-a", now.Add(time.Hour), now))
+
+	require.NoError(t, listing.Reserve("buyer-a", now.Add(time.Hour), now))
+	assert.True(t, listing.IsReservedAt(now))
+	assert.Error(t, listing.Reserve("buyer-b", now.Add(time.Hour), now))
+	// The same buyer may extend their reservation
+	assert.NoError(t, listing.Reserve("buyer-a", now.Add(2*time.Hour), now))
+
+	assert.False(t, listing.ReleaseReservation("buyer-b"))
+	assert.True(t, listing.ReleaseReservation("buyer-a"))
+	assert.False(t, listing.IsReservedAt(now))
+	assert.NoError(t, listing.Reserve("buyer-b", now.Add(time.Hour), now))
+}
+
+func TestListing_ReserveAfterExpiry(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	require.NoError(t, listing.Activate())
+	now := time.Now()
+
+	require.NoError(t, listing.Reserve("buyer-a", now.Add(time.Hour), now))
+	later := now.Add(2 * time.Hour)
+	assert.False(t, listing.IsReservedAt(later))
+	assert.NoError(t, listing.Reserve("buyer-b", later.Add(time.Hour), later))
+
+	// Listings cannot be bought once their lifetime is over
+	assert.Error(t, listing.Reserve("buyer-b", listing.ExpiresAt.Add(-time.Hour), listing.ExpiresAt.Add(time.Second)))
+}
+
+func TestListing_DraftIsCheckedWhenPublished(t *testing.T) {
+	now := time.Now()
+	draft, err := domain.NewDraft("seller-a", now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ListingStatusDraft, draft.Status)
+	assert.Equal(t, now.AddDate(0, 0, domain.ListingLifetimeDays), draft.ExpiresAt)
+
+	details := draft.Details()
+	details.Title = "Used phone"
+	require.NoError(t, draft.UpdateDetails(details), "drafts can be saved incomplete")
+	assert.Error(t, draft.Activate())
+
+	details.CategoryID = "category-1"
+	details.Price = money.Cedis(100)
+	require.NoError(t, draft.UpdateDetails(details))
+	assert.Error(t, draft.Activate(), "the condition is still missing")
+
+	details.Condition = domain.ConditionGood
+	require.NoError(t, draft.UpdateDetails(details))
+	require.NoError(t, draft.Activate())
+
+	// Live listings must stay complete
+	details.Price = money.Cedis(0)
+	assert.Error(t, draft.UpdateDetails(details))
+}
+
+func TestListingCursor(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Phone", "", money.Cedis(250), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+
+	cursor := domain.NewListingCursor(listing, domain.ListingSortPriceDesc)
+	decoded, err := domain.DecodeListingCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	cheaper, err := domain.NewListing("seller-a", "category-1", "Phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	assert.True(t, decoded.Precedes(cheaper))
+	assert.False(t, decoded.Precedes(listing), "the cursor's own listing was on the previous page")
+
+	for _, token := range []string{"", "not-base64!", "e30"} {
+		_, err := domain.DecodeListingCursor(token)
+		assert.Error(t, err, token)
+	}
+}
+
+func TestNewListing_Currency(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used laptop", "", money.New(45000, "USD"), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	assert.Equal(t, "USD", listing.Price.Currency)
+
+	_, err = domain.NewListing("seller-a", "category-1", "Used laptop", "", money.New(45000, "XYZ"), domain.ConditionGood, domain.Location{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// nonSlugChars matches runs of characters that are not allowed in slugs
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Region represents a canonical administrative region
+type Region struct {
+	ID        string    `gorm:"type:uuid;primary_key" json:"id"`
+	Name      string    `gorm:"uniqueIndex;not null" json:"name"`
+	Slug      string    `gorm:"uniqueIndex;not null" json:"slug"`
+	Cities    []City    `gorm:"foreignKey:RegionID" json:"cities,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// City represents a canonical city or town within a region
+type City struct {
+	ID        string    `gorm:"type:uuid;primary_key" json:"id"`
+	RegionID  string    `gorm:"type:uuid;not null;uniqueIndex:idx_cities_region_slug" json:"region_id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Slug      string    `gorm:"not null;uniqueIndex:idx_cities_region_slug" json:"slug"`
+	Areas     []Area    `gorm:"foreignKey:CityID" json:"areas,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Area represents a canonical neighbourhood within a city
+type Area struct {
+	ID        string    `gorm:"type:uuid;primary_key" json:"id"`
+	CityID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_areas_city_slug" json:"city_id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Slug      string    `gorm:"not null;uniqueIndex:idx_areas_city_slug" json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewRegion creates a new region
+func NewRegion(name string) (*Region, error) {
+	slug := Slugify(name)
+	if slug == "" {
+		return nil, errors.ValidationError("region name is required")
+	}
+
+	return &Region{
+		ID:        uuid.New().String(),
+		Name:      strings.TrimSpace(name),
+		Slug:      slug,
+		Cities:    []City{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// AddCity adds a city to the region, returning the existing one when already present
+func (r *Region) AddCity(name string) (*City, error) {
+	slug := Slugify(name)
+	if slug == "" {
+		return nil, errors.ValidationError("city name is required")
+	}
+
+	for i := range r.Cities {
+		if r.Cities[i].Slug == slug {
+			return &r.Cities[i], nil
+		}
+	}
+
+	r.Cities = append(r.Cities, City{
+		ID:        uuid.New().String(),
+		RegionID:  r.ID,
+		Name:      strings.TrimSpace(name),
+		Slug:      slug,
+		Areas:     []Area{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.UpdatedAt = time.Now()
+	return &r.Cities[len(r.Cities)-1], nil
+}
+
+// FindCity finds a city of the region by name or slug
+func (r *Region) FindCity(name string) *City {
+	slug := Slugify(name)
+	for i := range r.Cities {
+		if r.Cities[i].Slug == slug {
+			return &r.Cities[i]
+		}
+	}
+	return nil
+}
+
+// AddArea adds an area to the city, returning the existing one when already present
+func (c *City) AddArea(name string) (*Area, error) {
+	slug := Slugify(name)
+	if slug == "" {
+		return nil, errors.ValidationError("area name is required")
+	}
+
+	if area := c.FindArea(name); area != nil {
+		return area, nil
+	}
+
+	c.Areas = append(c.Areas, Area{
+		ID:        uuid.New().String(),
+		CityID:    c.ID,
+		Name:      strings.TrimSpace(name),
+		Slug:      slug,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	c.UpdatedAt = time.Now()
+	return &c.Areas[len(c.Areas)-1], nil
+}
+
+// FindArea finds an area of the city by name or slug
+func (c *City) FindArea(name string) *Area {
+	slug := Slugify(name)
+	for i := range c.Areas {
+		if c.Areas[i].Slug == slug {
+			return &c.Areas[i]
+		}
+	}
+	return nil
+}
+
+// Canonicalize checks a listing location against the region and returns it
+// with canonical names. Areas are only checked when the city has reference areas.
+func (r *Region) Canonicalize(location Location) (Location, error) {
+	city := r.FindCity(location.City)
+	if city == nil {
+		return location, errors.ValidationError("unknown city for region " + r.Name)
+	}
+
+	location.Region = r.Name
+	location.City = city.Name
+
+	if location.Area != "" && len(city.Areas) > 0 {
+		area := city.FindArea(location.Area)
+		if area == nil {
+			return location, errors.ValidationError("unknown area for city " + city.Name)
+		}
+		location.Area = area.Name
+	}
+
+	return location, nil
+}
+
+// Slugify converts a place name into its URL-safe slug
+func Slugify(name string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// LocationRepository defines the interface for location reference data persistence
+type LocationRepository interface {
+	FindRegions() ([]*Region, error)
+	FindRegion(slug string) (*Region, error)
+	SaveTree(regions []*Region) error
+}
@@ -0,0 +1,70 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/listings/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "greater-accra", domain.Slugify("Greater Accra"))
+	assert.Equal(t, "greater-accra", domain.Slugify("  greater   ACCRA "))
+	assert.Equal(t, "sekondi-takoradi", domain.Slugify("Sekondi-Takoradi"))
+	assert.Equal(t, "", domain.Slugify("  "))
+}
+
+func TestRegion_AddCityAndArea(t *testing.T) {
+	region, err := domain.NewRegion("Greater Accra")
+	require.NoError(t, err)
+	assert.Equal(t, "greater-accra", region.Slug)
+
+	accra, err := region.AddCity("Accra")
+	require.NoError(t, err)
+	assert.Equal(t, region.ID, accra.RegionID)
+
+	// Adding the same city again returns the existing one
+	again, err := region.AddCity("accra")
+	require.NoError(t, err)
+	assert.Equal(t, accra.ID, again.ID)
+	assert.Len(t, region.Cities, 1)
+
+	_, err = accra.AddArea("East Legon")
+	require.NoError(t, err)
+	_, err = accra.AddArea("east legon")
+	require.NoError(t, err)
+	assert.Len(t, accra.Areas, 1)
+
+	_, err = region.AddCity("")
+	assert.Error(t, err)
+	_, err = domain.NewRegion("")
+	assert.Error(t, err)
+}
+
+func TestRegion_Canonicalize(t *testing.T) {
+	region, err := domain.NewRegion("Greater Accra")
+	require.NoError(t, err)
+	accra, err := region.AddCity("Accra")
+	require.NoError(t, err)
+	_, err = accra.AddArea("East Legon")
+	require.NoError(t, err)
+	_, err = region.AddCity("Tema")
+	require.NoError(t, err)
+
+	location, err := region.Canonicalize(domain.Location{Region: "greater accra", City: "ACCRA", Area: "east-legon"})
+	require.NoError(t, err)
+	assert.Equal(t, "Greater Accra", location.Region)
+	assert.Equal(t, "Accra", location.City)
+	assert.Equal(t, "East Legon", location.Area)
+
+	// Unknown areas are rejected only where reference areas exist
+	_, err = region.Canonicalize(domain.Location{City: "Accra", Area: "Nowhere"})
+	assert.Error(t, err)
+	location, err = region.Canonicalize(domain.Location{City: "Tema", Area: "Community 5"})
+	require.NoError(t, err)
+	assert.Equal(t, "Community 5", location.Area)
+
+	_, err = region.Canonicalize(domain.Location{City: "Kumasi"})
+	assert.Error(t, err)
+}
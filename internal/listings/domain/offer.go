@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// OfferExpiry is how long an offer or counter-offer waits for a reply before
+// it lapses
+const OfferExpiry = 48 * time.Hour
+
+// OfferStatus represents where the negotiation of an offer stands
+type OfferStatus string
+
+const (
+	// OfferStatusPending is an offer waiting for the seller's reply
+	OfferStatusPending OfferStatus = "pending"
+	// OfferStatusCountered is a counter-offer waiting for the buyer's reply
+	OfferStatusCountered OfferStatus = "countered"
+	OfferStatusAccepted  OfferStatus = "accepted"
+	OfferStatusDeclined  OfferStatus = "declined"
+	OfferStatusExpired   OfferStatus = "expired"
+)
+
+// Offer is a buyer's negotiation of the price of a negotiable listing. The
+// buyer and the seller take turns: each can accept or decline the other's
+// last amount or counter it with their own, until one of them accepts or
+// declines or the offer lapses.
+type Offer struct {
+	ID        string        `gorm:"type:uuid;primary_key" json:"id"`
+	ListingID ids.ListingID `gorm:"type:uuid;not null;index:idx_offers_listing" json:"listing_id"`
+	SellerID  ids.UserID    `gorm:"type:uuid;not null;index" json:"seller_id"`
+	BuyerID   ids.UserID    `gorm:"type:uuid;not null;index:idx_offers_listing" json:"buyer_id"`
+	// Amount is the last amount proposed, by the buyer or in the seller's counter-offer
+	Amount money.Money `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	// AskingPrice is the listing's price when the offer was made
+	AskingPrice money.Money `gorm:"embedded;embeddedPrefix:asking_price_" json:"asking_price"`
+	Status      OfferStatus `gorm:"not null;default:'pending';index:idx_offers_expiry" json:"status"`
+	// Counters counts the counter-offers made by either side
+	Counters    int        `gorm:"not null;default:0" json:"counters"`
+	ExpiresAt   time.Time  `gorm:"not null;index:idx_offers_expiry" json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// NewOffer creates a buyer's offer on an active, negotiable listing. Offers
+// must be below the asking price; at the asking price the buyer can just buy.
+func NewOffer(listing *Listing, buyerID ids.UserID, amount money.Money, now time.Time) (*Offer, error) {
+	if !listing.IsActive() {
+		return nil, errors.ValidationError("listing is not available")
+	}
+	if !listing.IsNegotiable {
+		return nil, errors.ValidationError("listing does not accept offers")
+	}
+	if buyerID == listing.SellerID {
+		return nil, errors.ValidationError("cannot make an offer on your own listing")
+	}
+	if !amount.SameCurrency(listing.Price) {
+		return nil, errors.ValidationError("offer must be in the listing's currency")
+	}
+	if !amount.IsPositive() || amount.Cmp(listing.Price) >= 0 {
+		return nil, errors.ValidationError("offer must be below the asking price")
+	}
+
+	return &Offer{
+		ID:          uuid.New().String(),
+		ListingID:   listing.ID,
+		SellerID:    listing.SellerID,
+		BuyerID:     buyerID,
+		Amount:      amount,
+		AskingPrice: listing.Price,
+		Status:      OfferStatusPending,
+		ExpiresAt:   now.Add(OfferExpiry),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// IsOpen checks if the offer is still being negotiated
+func (o *Offer) IsOpen() bool {
+	return o.Status == OfferStatusPending || o.Status == OfferStatusCountered
+}
+
+// IsExpired checks if the offer lapsed without a reply
+func (o *Offer) IsExpired(now time.Time) bool {
+	return o.IsOpen() && now.After(o.ExpiresAt)
+}
+
+// AwaitingReplyFrom returns who must reply to an open offer: the seller to
+// the buyer's offer, the buyer to the seller's counter-offer
+func (o *Offer) AwaitingReplyFrom() ids.UserID {
+	switch o.Status {
+	case OfferStatusPending:
+		return o.SellerID
+	case OfferStatusCountered:
+		return o.BuyerID
+	default:
+		return ""
+	}
+}
+
+// Involves checks if the user is the buyer or the seller of the offer
+func (o *Offer) Involves(userID ids.UserID) bool {
+	return userID == o.BuyerID || userID == o.SellerID
+}
+
+// OtherParty returns the buyer for the seller and the seller for the buyer
+func (o *Offer) OtherParty(userID ids.UserID) ids.UserID {
+	if userID == o.SellerID {
+		return o.BuyerID
+	}
+	return o.SellerID
+}
+
+// Accept agrees to the last amount proposed by the other party
+func (o *Offer) Accept(userID ids.UserID, now time.Time) error {
+	if err := o.ensureAwaiting(userID, now); err != nil {
+		return err
+	}
+	o.respond(OfferStatusAccepted, now)
+	return nil
+}
+
+// Decline turns down the last amount proposed by the other party, ending the
+// negotiation
+func (o *Offer) Decline(userID ids.UserID, now time.Time) error {
+	if err := o.ensureAwaiting(userID, now); err != nil {
+		return err
+	}
+	o.respond(OfferStatusDeclined, now)
+	return nil
+}
+
+// Counter replies to the last amount proposed with another, which the other
+// party then has OfferExpiry to reply to. The seller counters with more than
+// the buyer offered, up to the asking price, and the buyer with less than
+// the seller asked.
+func (o *Offer) Counter(userID ids.UserID, amount money.Money, now time.Time) error {
+	if err := o.ensureAwaiting(userID, now); err != nil {
+		return err
+	}
+	if !amount.SameCurrency(o.Amount) {
+		return errors.ValidationError("offer must be in the listing's currency")
+	}
+
+	if userID == o.SellerID {
+		if amount.Cmp(o.Amount) <= 0 || amount.Cmp(o.AskingPrice) > 0 {
+			return errors.ValidationError("counter-offer must be above the offer and at most the asking price")
+		}
+		o.Status = OfferStatusCountered
+	} else {
+		if !amount.IsPositive() || amount.Cmp(o.Amount) >= 0 {
+			return errors.ValidationError("counter-offer must be below the seller's price")
+		}
+		o.Status = OfferStatusPending
+	}
+
+	o.Amount = amount
+	o.Counters++
+	o.ExpiresAt = now.Add(OfferExpiry)
+	o.UpdatedAt = now
+	return nil
+}
+
+// Expire closes an offer that lapsed without a reply
+func (o *Offer) Expire(now time.Time) error {
+	if !o.IsExpired(now) {
+		return errors.ConflictError("offer has not expired")
+	}
+	o.Status = OfferStatusExpired
+	o.UpdatedAt = now
+	return nil
+}
+
+// ensureAwaiting rejects replies to closed or lapsed offers and replies by
+// anyone but the party the offer is waiting for
+func (o *Offer) ensureAwaiting(userID ids.UserID, now time.Time) error {
+	if !o.Involves(userID) {
+		return errors.ForbiddenError("only the buyer and the seller can reply to an offer")
+	}
+	if !o.IsOpen() {
+		return errors.ConflictError("offer is no longer open")
+	}
+	if o.IsExpired(now) {
+		return errors.ConflictError("offer has expired")
+	}
+	if userID != o.AwaitingReplyFrom() {
+		return errors.ConflictError("offer is waiting for a reply from the other party")
+	}
+	return nil
+}
+
+func (o *Offer) respond(status OfferStatus, now time.Time) {
+	o.Status = status
+	o.RespondedAt = &now
+	o.UpdatedAt = now
+}
+
+// OfferRepository defines the interface for offer persistence
+type OfferRepository interface {
+	Save(offer *Offer) error
+	FindByID(id string) (*Offer, error)
+	// FindOpen finds the buyer's open offers on a listing that have not lapsed by now
+	FindOpen(listingID ids.ListingID, buyerID ids.UserID, now time.Time) ([]*Offer, error)
+	// FindByListing finds the offers on a listing, only the buyer's when
+	// buyerID is set, newest first
+	FindByListing(listingID ids.ListingID, buyerID ids.UserID, limit, offset int) ([]*Offer, int64, error)
+	// FindExpiredOpen finds open offers that lapsed by now, oldest first
+	FindExpiredOpen(now time.Time, limit int) ([]*Offer, error)
+	Update(offer *Offer) error
+}
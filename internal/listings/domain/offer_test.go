@@ -0,0 +1,93 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOffer(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+
+	// Drafts take no offers
+	_, err = domain.NewOffer(listing, "buyer-a", money.Cedis(80), now)
+	assert.Error(t, err)
+	require.NoError(t, listing.Activate())
+
+	offer, err := domain.NewOffer(listing, "buyer-a", money.Cedis(80), now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OfferStatusPending, offer.Status)
+	assert.Equal(t, money.Cedis(100), offer.AskingPrice)
+	assert.Equal(t, now.Add(domain.OfferExpiry), offer.ExpiresAt)
+	assert.Equal(t, listing.SellerID, offer.AwaitingReplyFrom())
+
+	_, err = domain.NewOffer(listing, "seller-a", money.Cedis(80), now)
+	assert.Error(t, err)
+	_, err = domain.NewOffer(listing, "buyer-a", money.Cedis(100), now)
+	assert.Error(t, err)
+	_, err = domain.NewOffer(listing, "buyer-a", money.New(8000, "USD"), now)
+	assert.Error(t, err)
+
+	listing.IsNegotiable = false
+	_, err = domain.NewOffer(listing, "buyer-a", money.Cedis(80), now)
+	assert.Error(t, err)
+}
+
+func TestOffer_Negotiation(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	require.NoError(t, listing.Activate())
+	offer, err := domain.NewOffer(listing, "buyer-a", money.Cedis(70), now)
+	require.NoError(t, err)
+
+	// Only the party the offer waits for can reply
+	assert.Error(t, offer.Accept("buyer-a", now))
+	assert.Error(t, offer.Accept("buyer-b", now))
+
+	// The seller counters above the offer, up to the asking price
+	assert.Error(t, offer.Counter("seller-a", money.Cedis(60), now))
+	assert.Error(t, offer.Counter("seller-a", money.Cedis(120), now))
+	later := now.Add(time.Hour)
+	require.NoError(t, offer.Counter("seller-a", money.Cedis(90), later))
+	assert.Equal(t, domain.OfferStatusCountered, offer.Status)
+	assert.Equal(t, money.Cedis(90), offer.Amount)
+	assert.Equal(t, later.Add(domain.OfferExpiry), offer.ExpiresAt)
+	assert.Equal(t, offer.BuyerID, offer.AwaitingReplyFrom())
+
+	// The buyer counters below the seller's price
+	assert.Error(t, offer.Counter("buyer-a", money.Cedis(95), later))
+	require.NoError(t, offer.Counter("buyer-a", money.Cedis(80), later))
+	assert.Equal(t, domain.OfferStatusPending, offer.Status)
+	assert.Equal(t, 2, offer.Counters)
+
+	require.NoError(t, offer.Accept("seller-a", later))
+	assert.Equal(t, domain.OfferStatusAccepted, offer.Status)
+	require.NotNil(t, offer.RespondedAt)
+	assert.False(t, offer.IsOpen())
+	assert.Error(t, offer.Decline("buyer-a", later))
+}
+
+func TestOffer_Expire(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	require.NoError(t, listing.Activate())
+	offer, err := domain.NewOffer(listing, "buyer-a", money.Cedis(70), now)
+	require.NoError(t, err)
+
+	assert.Error(t, offer.Expire(now))
+
+	lapsed := now.Add(domain.OfferExpiry + time.Minute)
+	assert.True(t, offer.IsExpired(lapsed))
+	assert.Error(t, offer.Decline("seller-a", lapsed))
+	require.NoError(t, offer.Expire(lapsed))
+	assert.Equal(t, domain.OfferStatusExpired, offer.Status)
+	assert.False(t, offer.IsExpired(lapsed))
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// MaxPriceHistory is the most price changes shown for a listing
+const MaxPriceHistory = 100
+
+// PriceChange records a seller changing the price of a published listing.
+// Drafts are left out, as buyers never saw their prices.
+type PriceChange struct {
+	ID        string        `gorm:"type:uuid;primary_key" json:"id"`
+	ListingID ids.ListingID `gorm:"type:uuid;not null;index" json:"listing_id"`
+	OldPrice  money.Money   `gorm:"embedded;embeddedPrefix:old_price_" json:"old_price"`
+	NewPrice  money.Money   `gorm:"embedded;embeddedPrefix:new_price_" json:"new_price"`
+	ChangedAt time.Time     `gorm:"not null" json:"changed_at"`
+}
+
+// TableName keeps the table named after what it holds
+func (PriceChange) TableName() string {
+	return "listing_price_history"
+}
+
+// NewPriceChange records a listing's price moving from oldPrice to its
+// current price
+func NewPriceChange(listing *Listing, oldPrice money.Money, now time.Time) *PriceChange {
+	return &PriceChange{
+		ID:        uuid.New().String(),
+		ListingID: listing.ID,
+		OldPrice:  oldPrice,
+		NewPrice:  listing.Price,
+		ChangedAt: now,
+	}
+}
+
+// IsDrop checks if the price went down
+func (c *PriceChange) IsDrop() bool {
+	return c.NewPrice.Cmp(c.OldPrice) < 0
+}
+
+// PriceHistoryRepository defines the interface for listing price history persistence
+type PriceHistoryRepository interface {
+	Save(change *PriceChange) error
+	// FindByListing finds a listing's price changes, most recent first
+	FindByListing(listingID ids.ListingID, limit int) ([]*PriceChange, error)
+}
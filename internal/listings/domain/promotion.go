@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// PromotionPackage is a length of time a seller can pay to promote a listing for
+type PromotionPackage struct {
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Days  int         `json:"days"`
+	Price money.Money `json:"price"`
+}
+
+// PromotionStatus represents how far the payment of a promotion has got
+type PromotionStatus string
+
+const (
+	PromotionStatusPending PromotionStatus = "pending"
+	PromotionStatusPaid    PromotionStatus = "paid"
+	PromotionStatusFailed  PromotionStatus = "failed"
+)
+
+// ListingPromotion is a seller's purchase of a promotion package for one of
+// their listings. The listing is promoted once the payment is confirmed.
+type ListingPromotion struct {
+	ID        string        `gorm:"type:uuid;primary_key" json:"id"`
+	ListingID ids.ListingID `gorm:"type:uuid;not null;index" json:"listing_id"`
+	SellerID  ids.UserID    `gorm:"type:uuid;not null;index" json:"seller_id"`
+	PackageID string        `gorm:"size:50;not null" json:"package_id"`
+	Days      int           `gorm:"not null" json:"days"`
+	Amount    money.Money   `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	// Payer is the phone number the payment is requested from
+	Payer  string          `gorm:"size:20;not null" json:"payer"`
+	Status PromotionStatus `gorm:"size:20;not null" json:"status"`
+	// PaymentReference is what the payment provider tracks the payment under
+	PaymentReference       string     `gorm:"size:64" json:"payment_reference,omitempty"`
+	FinancialTransactionID string     `gorm:"size:64" json:"-"`
+	PaidAt                 *time.Time `json:"paid_at,omitempty"`
+	// AppliedAt is when the listing was promoted for the paid time
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// NewListingPromotion starts the purchase of a promotion package for a live
+// listing, to be paid from the payer's phone
+func NewListingPromotion(listing *Listing, pkg PromotionPackage, payer string, now time.Time) (*ListingPromotion, error) {
+	if !listing.IsActiveAt(now) {
+		return nil, errors.ValidationError("only live listings can be promoted")
+	}
+	if payer == "" {
+		return nil, errors.ValidationError("payer phone number is required")
+	}
+
+	return &ListingPromotion{
+		ID:        uuid.New().String(),
+		ListingID: listing.ID,
+		SellerID:  listing.SellerID,
+		PackageID: pkg.ID,
+		Days:      pkg.Days,
+		Amount:    pkg.Price,
+		Payer:     payer,
+		Status:    PromotionStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// MarkPaid records the confirmed payment of a pending promotion. It reports
+// false for promotions already paid.
+func (p *ListingPromotion) MarkPaid(financialTransactionID string, now time.Time) (bool, error) {
+	switch p.Status {
+	case PromotionStatusPaid:
+		return false, nil
+	case PromotionStatusFailed:
+		return false, errors.ConflictError("promotion payment already failed")
+	}
+
+	p.Status = PromotionStatusPaid
+	p.FinancialTransactionID = financialTransactionID
+	p.PaidAt = &now
+	p.UpdatedAt = now
+	return true, nil
+}
+
+// MarkFailed records that a pending promotion was not paid
+func (p *ListingPromotion) MarkFailed(now time.Time) bool {
+	if p.Status != PromotionStatusPending {
+		return false
+	}
+
+	p.Status = PromotionStatusFailed
+	p.UpdatedAt = now
+	return true
+}
+
+// MarkApplied records that the listing was promoted for the paid time. It
+// reports false if the promotion is not paid or was already applied.
+func (p *ListingPromotion) MarkApplied(now time.Time) bool {
+	if p.Status != PromotionStatusPaid || p.AppliedAt != nil {
+		return false
+	}
+
+	p.AppliedAt = &now
+	p.UpdatedAt = now
+	return true
+}
+
+// Duration is how long the promotion keeps the listing promoted
+func (p *ListingPromotion) Duration() time.Duration {
+	return time.Duration(p.Days) * 24 * time.Hour
+}
+
+// FindPromotionPackage finds a package by ID
+func FindPromotionPackage(packages []PromotionPackage, packageID string) (PromotionPackage, error) {
+	for _, pkg := range packages {
+		if pkg.ID == packageID {
+			return pkg, nil
+		}
+	}
+	return PromotionPackage{}, errors.NotFoundError("promotion package not found")
+}
+
+// PlaceSponsored spreads promoted listings evenly over a page of organic
+// results, the first at the top, and marks them sponsored. Promoted listings
+// also found organically on the page are shown once, in their sponsored slot.
+// The listings placed are copies, so the ones given are left unmarked.
+func PlaceSponsored(organic, sponsored []*Listing) []*Listing {
+	if len(sponsored) == 0 {
+		return organic
+	}
+
+	placed := make(map[ids.ListingID]bool, len(sponsored))
+	for _, listing := range sponsored {
+		placed[listing.ID] = true
+	}
+	rest := make([]*Listing, 0, len(organic))
+	for _, listing := range organic {
+		if !placed[listing.ID] {
+			rest = append(rest, listing)
+		}
+	}
+
+	page := make([]*Listing, 0, len(rest)+len(sponsored))
+	spacing := (len(rest) + len(sponsored)) / len(sponsored)
+	next := 0
+	for _, listing := range sponsored {
+		for len(page) < next && len(rest) > 0 {
+			page = append(page, rest[0])
+			rest = rest[1:]
+		}
+		marked := *listing
+		marked.Sponsored = true
+		page = append(page, &marked)
+		next += spacing
+	}
+	return append(page, rest...)
+}
+
+// ListingPromotionRepository defines the interface for promotion persistence
+type ListingPromotionRepository interface {
+	Save(promotion *ListingPromotion) error
+	FindByID(id string) (*ListingPromotion, error)
+	Update(promotion *ListingPromotion) error
+}
@@ -0,0 +1,119 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListingPromotion_Lifecycle(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	pkg := domain.PromotionPackage{ID: "week", Name: "7 days", Days: 7, Price: money.Cedis(10)}
+	now := time.Now()
+
+	// Drafts cannot be promoted
+	_, err = domain.NewListingPromotion(listing, pkg, "233241234567", now)
+	assert.Error(t, err)
+
+	require.NoError(t, listing.Activate())
+	promotion, err := domain.NewListingPromotion(listing, pkg, "233241234567", now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PromotionStatusPending, promotion.Status)
+	assert.Equal(t, 7*24*time.Hour, promotion.Duration())
+
+	// Unpaid promotions are not applied
+	assert.False(t, promotion.MarkApplied(now))
+
+	paid, err := promotion.MarkPaid("ftx-1", now)
+	require.NoError(t, err)
+	assert.True(t, paid)
+	paid, err = promotion.MarkPaid("ftx-1", now)
+	require.NoError(t, err)
+	assert.False(t, paid)
+	assert.False(t, promotion.MarkFailed(now))
+
+	assert.True(t, promotion.MarkApplied(now))
+	assert.False(t, promotion.MarkApplied(now))
+}
+
+func TestListingPromotion_FailedPaymentIsFinal(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	require.NoError(t, listing.Activate())
+	promotion, err := domain.NewListingPromotion(listing, domain.PromotionPackage{ID: "week", Days: 7, Price: money.Cedis(10)}, "233241234567", time.Now())
+	require.NoError(t, err)
+
+	assert.True(t, promotion.MarkFailed(time.Now()))
+	_, err = promotion.MarkPaid("ftx-1", time.Now())
+	assert.Error(t, err)
+}
+
+func TestListing_PromoteExtendsRunningPromotion(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	listing.Promote(24*time.Hour, now)
+	listing.Promote(24*time.Hour, now.Add(time.Hour))
+	assert.Equal(t, now.Add(48*time.Hour), *listing.PromotedUntil)
+
+	// Promotions run until their time is up
+	assert.False(t, listing.Unpromote(now.Add(47*time.Hour)))
+	assert.True(t, listing.Unpromote(listing.PromotedUntil.Add(time.Second)))
+	assert.False(t, listing.IsPromoted)
+	assert.Nil(t, listing.PromotedUntil)
+}
+
+func TestFindPromotionPackage(t *testing.T) {
+	packages := []domain.PromotionPackage{{ID: "week", Days: 7}, {ID: "month", Days: 30}}
+
+	pkg, err := domain.FindPromotionPackage(packages, "month")
+	require.NoError(t, err)
+	assert.Equal(t, 30, pkg.Days)
+
+	_, err = domain.FindPromotionPackage(packages, "year")
+	assert.Error(t, err)
+}
+
+func TestListing_IsPromotedAt(t *testing.T) {
+	now := time.Now()
+	listing := &domain.Listing{}
+	assert.False(t, listing.IsPromotedAt(now))
+
+	listing.Promote(time.Hour, now)
+	assert.True(t, listing.IsPromotedAt(now))
+	// Lapsed promotions no longer count before the worker ends them
+	assert.True(t, listing.IsPromoted)
+	assert.False(t, listing.IsPromotedAt(now.Add(time.Hour)))
+}
+
+func TestPlaceSponsored(t *testing.T) {
+	var organic []*domain.Listing
+	for _, id := range []ids.ListingID{"o1", "o2", "o3", "o4", "o5", "o6", "o7", "promoted-2"} {
+		organic = append(organic, &domain.Listing{ID: id})
+	}
+	sponsored := []*domain.Listing{{ID: "promoted-1"}, organic[7]}
+
+	page := domain.PlaceSponsored(organic, sponsored)
+	var placed []ids.ListingID
+	for _, listing := range page {
+		placed = append(placed, listing.ID)
+	}
+	// Spread evenly, and promoted listings found organically are shown once
+	assert.Equal(t, []ids.ListingID{"promoted-1", "o1", "o2", "o3", "promoted-2", "o4", "o5", "o6", "o7"}, placed)
+	assert.True(t, page[0].Sponsored)
+	assert.True(t, page[4].Sponsored)
+	assert.False(t, page[1].Sponsored)
+	assert.False(t, organic[7].Sponsored, "the listings given are left unmarked")
+
+	assert.Equal(t, organic, domain.PlaceSponsored(organic, nil))
+	page = domain.PlaceSponsored(nil, sponsored)
+	require.Len(t, page, 2)
+	assert.True(t, page[1].Sponsored)
+}
@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"dongome/pkg/ids"
+)
+
+// Listing quality thresholds checked before a listing goes live
+const (
+	MinListingImages         = 1
+	RecommendedListingImages = 3
+	MinTitleLength           = 10
+	MinDescriptionLength     = 30
+	MaxAttributeValueLength  = 200
+)
+
+// Publication issue codes returned to clients, so they can point sellers at
+// the field to fix
+const (
+	IssueInvalidStatus    = "invalid_status"
+	IssueImagesRequired   = "images_required"
+	IssueFewImages        = "few_images"
+	IssueTooManyImages    = "too_many_images"
+	IssueTitleTooShort    = "title_too_short"
+	IssueTitleTooLong     = "title_too_long"
+	IssueDescriptionShort = "description_too_short"
+	IssueInvalidPrice     = "invalid_price"
+	IssueProhibitedItem   = "prohibited_item"
+	IssueInvalidAttribute = "invalid_attribute"
+	IssueMissingAttribute = "missing_attribute"
+	IssueQuotaExceeded    = "quota_exceeded"
+	IssueInvalidLocation  = "invalid_location"
+	IssueInvalidCategory  = "invalid_category"
+	IssueContactDetails   = "contact_details"
+	IssueAlreadyActive    = "already_active"
+	IssueScheduled        = "scheduled"
+	// Drafts can be saved without these, but not published
+	IssueCategoryRequired  = "category_required"
+	IssueTitleRequired     = "title_required"
+	IssueConditionRequired = "condition_required"
+)
+
+// prohibitedTerms are items that cannot be sold on the marketplace, matched
+// as whole words in a listing's title, description and attributes
+var prohibitedTerms = []string{
+	"ammunition", "cocaine", "counterfeit", "firearm", "firearms", "handgun",
+	"heroin", "ivory", "marijuana", "rifle", "tramadol",
+}
+
+// prohibitedPattern matches any prohibited term as a whole word
+var prohibitedPattern = regexp.MustCompile(`(?i)\b(` + strings.Join(prohibitedTerms, "|") + `)\b`)
+
+// PublicationLimits caps how many listings a seller can have live. Each
+// seller's limits come from the listings.max_active_per_seller business rule.
+type PublicationLimits struct {
+	// MaxActiveListings is how many active listings a seller can have; zero means no cap
+	MaxActiveListings int
+}
+
+// PublicationIssue is a problem found with a listing before it goes live
+type PublicationIssue struct {
+	Code    string `json:"code"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// PublicationReport lists what stops a listing from going live and what
+// would make it better. A listing with no errors can be published.
+type PublicationReport struct {
+	ListingID   ids.ListingID      `json:"listing_id"`
+	Publishable bool               `json:"publishable"`
+	Errors      []PublicationIssue `json:"errors"`
+	Warnings    []PublicationIssue `json:"warnings"`
+}
+
+// NewPublicationReport creates an empty report for a listing
+func NewPublicationReport(listingID ids.ListingID) *PublicationReport {
+	return &PublicationReport{
+		ListingID:   listingID,
+		Publishable: true,
+		Errors:      []PublicationIssue{},
+		Warnings:    []PublicationIssue{},
+	}
+}
+
+// AddError records a problem that blocks publication
+func (r *PublicationReport) AddError(code, field, message string) {
+	r.Errors = append(r.Errors, PublicationIssue{Code: code, Field: field, Message: message})
+	r.Publishable = false
+}
+
+// AddWarning records a problem that does not block publication
+func (r *PublicationReport) AddWarning(code, field, message string) {
+	r.Warnings = append(r.Warnings, PublicationIssue{Code: code, Field: field, Message: message})
+}
+
+// CheckPublication runs the publication rules that need nothing beyond the
+// listing itself and the seller's count of other active listings
+func (l *Listing) CheckPublication(limits PublicationLimits, activeListings int64) *PublicationReport {
+	report := NewPublicationReport(l.ID)
+
+	switch l.Status {
+	case ListingStatusSold:
+		report.AddError(IssueInvalidStatus, "status", "sold listings cannot be published again")
+	case ListingStatusActive:
+		report.AddWarning(IssueAlreadyActive, "status", "listing is already live")
+	}
+	if l.IsScheduled() {
+		report.AddWarning(IssueScheduled, "publish_at", fmt.Sprintf("listing is scheduled to go live at %s", l.PublishAt.Format("2006-01-02 15:04 MST")))
+	}
+
+	switch {
+	case len(l.Images) < MinListingImages:
+		report.AddError(IssueImagesRequired, "images", "add at least one photo")
+	case len(l.Images) < RecommendedListingImages:
+		report.AddWarning(IssueFewImages, "images", fmt.Sprintf("listings with %d or more photos get more replies", RecommendedListingImages))
+	case len(l.Images) > MaxListingImages:
+		report.AddError(IssueTooManyImages, "images", fmt.Sprintf("a listing can have at most %d photos", MaxListingImages))
+	}
+
+	if l.CategoryID == "" {
+		report.AddError(IssueCategoryRequired, "category_id", "choose a category")
+	}
+	title := strings.TrimSpace(l.Title)
+	switch {
+	case title == "":
+		report.AddError(IssueTitleRequired, "title", "add a title")
+	case len([]rune(title)) > MaxListingTitleLength:
+		report.AddError(IssueTitleTooLong, "title", fmt.Sprintf("title must be at most %d characters", MaxListingTitleLength))
+	case len([]rune(title)) < MinTitleLength:
+		report.AddWarning(IssueTitleTooShort, "title", "a longer title with the brand and model helps buyers find the listing")
+	}
+	if len([]rune(strings.TrimSpace(l.Description))) < MinDescriptionLength {
+		report.AddWarning(IssueDescriptionShort, "description", "describe the item's condition and what is included")
+	}
+	if !l.Price.IsPositive() {
+		report.AddError(IssueInvalidPrice, "price", "price must be greater than 0")
+	}
+	if !l.Condition.IsValid() {
+		report.AddError(IssueConditionRequired, "condition", "choose the item's condition")
+	}
+
+	seen := make(map[string]bool, len(l.Attributes))
+	for _, attribute := range l.Attributes {
+		key := strings.ToLower(strings.TrimSpace(attribute.Key))
+		field := "attributes." + key
+		switch {
+		case key == "":
+			report.AddError(IssueInvalidAttribute, "attributes", "attribute names cannot be empty")
+		case seen[key]:
+			report.AddError(IssueInvalidAttribute, field, fmt.Sprintf("%s is given more than once", key))
+		case strings.TrimSpace(attribute.Value) == "":
+			report.AddError(IssueInvalidAttribute, field, fmt.Sprintf("%s has no value", key))
+		case len(attribute.Value) > MaxAttributeValueLength:
+			report.AddError(IssueInvalidAttribute, field, fmt.Sprintf("%s must be at most %d characters", key, MaxAttributeValueLength))
+		}
+		seen[key] = true
+	}
+
+	if term := l.prohibitedTerm(); term != "" {
+		report.AddError(IssueProhibitedItem, "", fmt.Sprintf("%q items cannot be sold on the marketplace", term))
+	}
+
+	if l.Status != ListingStatusActive && limits.MaxActiveListings > 0 && activeListings >= int64(limits.MaxActiveListings) {
+		report.AddError(IssueQuotaExceeded, "", fmt.Sprintf("you can have at most %d active listings, deactivate one first", limits.MaxActiveListings))
+	}
+
+	return report
+}
+
+// prohibitedTerm returns the first prohibited term found in the listing's
+// text, or an empty string
+func (l *Listing) prohibitedTerm() string {
+	texts := []string{l.Title, l.Description}
+	for _, attribute := range l.Attributes {
+		texts = append(texts, attribute.Value)
+	}
+	for _, text := range texts {
+		if match := prohibitedPattern.FindString(text); match != "" {
+			return strings.ToLower(match)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func issueCodes(issues []domain.PublicationIssue) []string {
+	codes := []string{}
+	for _, issue := range issues {
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+func TestListing_CheckPublication(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Samsung Galaxy S21 128GB", "Barely used, comes with the original box and charger.", money.Cedis(2500), domain.ConditionLikeNew,
+		domain.Location{Region: "Greater Accra", City: "Accra"})
+	require.NoError(t, err)
+
+	// Without photos the listing cannot go live
+	report := listing.CheckPublication(domain.PublicationLimits{MaxActiveListings: 5}, 0)
+	assert.False(t, report.Publishable)
+	assert.Equal(t, []string{domain.IssueImagesRequired}, issueCodes(report.Errors))
+
+	// One photo is enough, but more are recommended
+	listing.AddImage("https://cdn.example.com/1.jpg", "")
+	report = listing.CheckPublication(domain.PublicationLimits{MaxActiveListings: 5}, 0)
+	assert.True(t, report.Publishable)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, []string{domain.IssueFewImages}, issueCodes(report.Warnings))
+
+	// The seller is at their active listing quota
+	report = listing.CheckPublication(domain.PublicationLimits{MaxActiveListings: 5}, 5)
+	assert.Equal(t, []string{domain.IssueQuotaExceeded}, issueCodes(report.Errors))
+	assert.True(t, listing.CheckPublication(domain.PublicationLimits{}, 1000).Publishable, "zero limits do not cap anything")
+
+	// Prohibited items and broken attributes are blocking
+	listing.Description = "Selling a counterfeit designer bag"
+	listing.Attributes = []domain.ListingAttribute{{Key: "Color", Value: "Black"}, {Key: "color", Value: "Red"}, {Key: "size", Value: " "}}
+	report = listing.CheckPublication(domain.PublicationLimits{}, 0)
+	assert.ElementsMatch(t, []string{domain.IssueInvalidAttribute, domain.IssueInvalidAttribute, domain.IssueProhibitedItem}, issueCodes(report.Errors))
+
+	// Whole words only: "rifleman" is not a rifle
+	listing.Description = "Vintage Rifleman series poster, framed and in great shape"
+	listing.Attributes = nil
+	assert.True(t, listing.CheckPublication(domain.PublicationLimits{}, 0).Publishable)
+
+	listing.Title = strings.Repeat("x", domain.MaxListingTitleLength+1)
+	assert.Contains(t, issueCodes(listing.CheckPublication(domain.PublicationLimits{}, 0).Errors), domain.IssueTitleTooLong)
+}
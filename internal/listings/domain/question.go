@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// Question and answer length limits
+const (
+	MinQuestionLength = 5
+	MaxQuestionLength = 500
+	MaxAnswerLength   = 1000
+)
+
+// QuestionStatus represents the moderation state of a listing question
+type QuestionStatus string
+
+const (
+	QuestionStatusPublished     QuestionStatus = "published"
+	QuestionStatusPendingReview QuestionStatus = "pending_review"
+	QuestionStatusHidden        QuestionStatus = "hidden"
+)
+
+// ListingQuestion is a buyer's public question about a listing and the
+// seller's answer. Published questions are visible to everyone so the same
+// question does not have to be asked again in chat.
+type ListingQuestion struct {
+	ID         string         `gorm:"type:uuid;primary_key" json:"id"`
+	ListingID  ids.ListingID  `gorm:"type:uuid;not null;index:idx_listing_questions_listing" json:"listing_id"`
+	AskerID    ids.UserID     `gorm:"type:uuid;not null;index" json:"asker_id"`
+	Question   string         `gorm:"type:text;not null" json:"question"`
+	Answer     *string        `gorm:"type:text" json:"answer,omitempty"`
+	AnsweredBy *ids.UserID    `gorm:"type:uuid" json:"answered_by,omitempty"`
+	AnsweredAt *time.Time     `json:"answered_at,omitempty"`
+	Status     QuestionStatus `gorm:"not null;default:'published';index:idx_listing_questions_listing" json:"status"`
+	// ModerationReason explains why the question is held for review or hidden
+	ModerationReason string    `json:"moderation_reason,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// NewListingQuestion creates a question about an active listing. Sellers
+// cannot ask about their own listings.
+func NewListingQuestion(listing *Listing, askerID ids.UserID, question string) (*ListingQuestion, error) {
+	if !listing.IsActive() {
+		return nil, errors.ValidationError("listing is not available")
+	}
+	if askerID == listing.SellerID {
+		return nil, errors.ValidationError("cannot ask about your own listing")
+	}
+
+	question = strings.TrimSpace(question)
+	if len(question) < MinQuestionLength || len(question) > MaxQuestionLength {
+		return nil, errors.ValidationError("question must be between 5 and 500 characters")
+	}
+
+	now := time.Now()
+	return &ListingQuestion{
+		ID:        uuid.New().String(),
+		ListingID: listing.ID,
+		AskerID:   askerID,
+		Question:  question,
+		Status:    QuestionStatusPublished,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// SetAnswer records the seller's answer. Sellers may rewrite their answer.
+func (q *ListingQuestion) SetAnswer(listing *Listing, sellerID ids.UserID, answer string) error {
+	if listing.ID != q.ListingID || listing.SellerID != sellerID {
+		return errors.ForbiddenError("only the seller can answer this question")
+	}
+	if q.Status == QuestionStatusHidden {
+		return errors.ConflictError("question has been removed")
+	}
+
+	answer = strings.TrimSpace(answer)
+	if answer == "" || len(answer) > MaxAnswerLength {
+		return errors.ValidationError("answer must be between 1 and 1000 characters")
+	}
+
+	now := time.Now()
+	q.Answer = &answer
+	q.AnsweredBy = &sellerID
+	q.AnsweredAt = &now
+	q.UpdatedAt = now
+	return nil
+}
+
+// IsAnswered checks if the seller has answered the question
+func (q *ListingQuestion) IsAnswered() bool {
+	return q.Answer != nil
+}
+
+// IsVisible checks if the question is shown publicly
+func (q *ListingQuestion) IsVisible() bool {
+	return q.Status == QuestionStatusPublished
+}
+
+// HoldForReview takes the question out of public view until a moderator decides
+func (q *ListingQuestion) HoldForReview(reason string) {
+	q.Status = QuestionStatusPendingReview
+	q.ModerationReason = reason
+	q.UpdatedAt = time.Now()
+}
+
+// Publish makes the question visible again after moderation
+func (q *ListingQuestion) Publish() {
+	q.Status = QuestionStatusPublished
+	q.ModerationReason = ""
+	q.UpdatedAt = time.Now()
+}
+
+// Hide removes the question from public view
+func (q *ListingQuestion) Hide(reason string) {
+	q.Status = QuestionStatusHidden
+	q.ModerationReason = reason
+	q.UpdatedAt = time.Now()
+}
+
+// ListingQuestionRepository defines the interface for listing question persistence
+type ListingQuestionRepository interface {
+	Save(question *ListingQuestion) error
+	FindByID(id string) (*ListingQuestion, error)
+	// FindByListing finds a listing's questions with the given status, newest first
+	FindByListing(listingID ids.ListingID, status QuestionStatus, limit, offset int) ([]*ListingQuestion, int64, error)
+	// FindTopAnswered finds a listing's published, answered questions, most recently answered first
+	FindTopAnswered(listingID ids.ListingID, limit int) ([]*ListingQuestion, error)
+	// FindByStatus finds questions across listings with the given status, oldest first
+	FindByStatus(status QuestionStatus, limit, offset int) ([]*ListingQuestion, int64, error)
+	Update(question *ListingQuestion) error
+}
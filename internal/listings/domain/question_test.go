@@ -0,0 +1,54 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewListingQuestion(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+
+	// Drafts cannot be asked about
+	_, err = domain.NewListingQuestion(listing, "buyer-a", "Is it unlocked?")
+	assert.Error(t, err)
+	require.NoError(t, listing.Activate())
+
+	question, err := domain.NewListingQuestion(listing, "buyer-a", "  Is it unlocked?  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Is it unlocked?", question.Question)
+	assert.True(t, question.IsVisible())
+	assert.False(t, question.IsAnswered())
+
+	_, err = domain.NewListingQuestion(listing, "seller-a", "Is it unlocked?")
+	assert.Error(t, err)
+	_, err = domain.NewListingQuestion(listing, "buyer-a", "Hi")
+	assert.Error(t, err)
+}
+
+func TestListingQuestion_SetAnswer(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	require.NoError(t, listing.Activate())
+	question, err := domain.NewListingQuestion(listing, "buyer-a", "Is it unlocked?")
+	require.NoError(t, err)
+
+	assert.Error(t, question.SetAnswer(listing, "buyer-b", "Yes"))
+	assert.Error(t, question.SetAnswer(listing, "seller-a", "   "))
+
+	require.NoError(t, question.SetAnswer(listing, "seller-a", "Yes, on all networks"))
+	assert.True(t, question.IsAnswered())
+	assert.Equal(t, "Yes, on all networks", *question.Answer)
+
+	question.Hide("spam")
+	assert.False(t, question.IsVisible())
+	assert.Error(t, question.SetAnswer(listing, "seller-a", "Updated"))
+
+	question.Publish()
+	assert.True(t, question.IsVisible())
+	assert.Empty(t, question.ModerationReason)
+}
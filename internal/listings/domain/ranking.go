@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// DefaultRankingPolicyID identifies the ranking policy applied to search. There
+// is a single policy for the whole marketplace.
+const DefaultRankingPolicyID = "default"
+
+// Reasons a seller's listings are demoted in search
+const (
+	RankingReasonStale        = "stale"
+	RankingReasonUnresponsive = "unresponsive"
+)
+
+// SellerEngagement is how recently a seller logged in and how they answered
+// buyers over the response window, as reported by the users context
+type SellerEngagement struct {
+	SellerID    ids.UserID
+	LastLoginAt *time.Time
+	// Inquiries is how many conversations buyers started with the seller
+	Inquiries int
+	// Responses is how many of those conversations the seller answered
+	Responses int
+}
+
+// ResponseRate returns the share of inquiries the seller answered
+func (e SellerEngagement) ResponseRate() float64 {
+	if e.Inquiries == 0 {
+		return 1
+	}
+	rate := float64(e.Responses) / float64(e.Inquiries)
+	if rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+// RankingPolicy sets how much listings of stale or unresponsive sellers are
+// demoted in search. Penalties are factors between 0 and 1 multiplied into a
+// listing's relevance; a seller who is both stale and unresponsive gets both.
+type RankingPolicy struct {
+	ID string `gorm:"primary_key" json:"-"`
+	// StaleAfterDays is how many days without logging in make a seller stale; zero disables the penalty
+	StaleAfterDays int     `gorm:"not null" json:"stale_after_days"`
+	StalePenalty   float64 `gorm:"not null" json:"stale_penalty"`
+	// MinResponseRate is the share of inquiries a seller must answer; zero disables the penalty
+	MinResponseRate float64 `gorm:"not null" json:"min_response_rate"`
+	// MinInquiries is how many inquiries a seller needs before their response rate counts
+	MinInquiries        int        `gorm:"not null" json:"min_inquiries"`
+	UnresponsivePenalty float64    `gorm:"not null" json:"unresponsive_penalty"`
+	ResponseWindowDays  int        `gorm:"not null" json:"response_window_days"`
+	UpdatedBy           ids.UserID `json:"updated_by,omitempty"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// DefaultRankingPolicy is applied until an administrator changes it
+func DefaultRankingPolicy() *RankingPolicy {
+	return &RankingPolicy{
+		ID:                  DefaultRankingPolicyID,
+		StaleAfterDays:      30,
+		StalePenalty:        0.5,
+		MinResponseRate:     0.5,
+		MinInquiries:        5,
+		UnresponsivePenalty: 0.7,
+		ResponseWindowDays:  30,
+	}
+}
+
+// Validate checks that thresholds and penalty factors are in range
+func (p *RankingPolicy) Validate() error {
+	if p.StaleAfterDays < 0 || p.MinInquiries < 0 {
+		return errors.ValidationError("stale_after_days and min_inquiries must not be negative")
+	}
+	if p.ResponseWindowDays < 1 || p.ResponseWindowDays > 365 {
+		return errors.ValidationError("response_window_days must be between 1 and 365")
+	}
+	if p.MinResponseRate < 0 || p.MinResponseRate > 1 {
+		return errors.ValidationError("min_response_rate must be between 0 and 1")
+	}
+	penalties := []struct {
+		name  string
+		value float64
+	}{
+		{"stale_penalty", p.StalePenalty},
+		{"unresponsive_penalty", p.UnresponsivePenalty},
+	}
+	for _, penalty := range penalties {
+		if penalty.value <= 0 || penalty.value > 1 {
+			return errors.ValidationError(fmt.Sprintf("%s must be greater than 0 and at most 1", penalty.name))
+		}
+	}
+	return nil
+}
+
+// ResponseWindowStart is the start of the window response rates are computed over
+func (p *RankingPolicy) ResponseWindowStart(now time.Time) time.Time {
+	return now.AddDate(0, 0, -p.ResponseWindowDays)
+}
+
+// Penalty returns the factor a seller's listings are ranked with and why
+// they are demoted. Sellers with nothing against them get a factor of 1.
+func (p *RankingPolicy) Penalty(engagement SellerEngagement, now time.Time) (float64, []string) {
+	factor := 1.0
+	reasons := []string{}
+
+	if p.StaleAfterDays > 0 {
+		cutoff := now.AddDate(0, 0, -p.StaleAfterDays)
+		if engagement.LastLoginAt == nil || engagement.LastLoginAt.Before(cutoff) {
+			factor *= p.StalePenalty
+			reasons = append(reasons, RankingReasonStale)
+		}
+	}
+
+	if p.MinResponseRate > 0 && engagement.Inquiries >= p.MinInquiries && engagement.Inquiries > 0 &&
+		engagement.ResponseRate() < p.MinResponseRate {
+		factor *= p.UnresponsivePenalty
+		reasons = append(reasons, RankingReasonUnresponsive)
+	}
+
+	return factor, reasons
+}
+
+// RankingPolicyRepository defines the interface for ranking policy persistence
+type RankingPolicyRepository interface {
+	// Find returns the ranking policy, or a not found error if it was never saved
+	Find() (*RankingPolicy, error)
+	Save(policy *RankingPolicy) error
+}
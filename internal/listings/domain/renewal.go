@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"dongome/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// RenewalLimits caps how sellers renew listings. Sellers have no paid plans
+// yet, so every seller gets the configured limits.
+type RenewalLimits struct {
+	// Window is how long before it expires a listing can be renewed, and how
+	// long after; zero allows renewing at any time
+	Window time.Duration
+	// MaxRenewals is how many times a listing can be renewed; zero means no cap
+	MaxRenewals int
+}
+
+// Renew keeps a live or expired listing up for another full lifetime,
+// counted from when it was due to expire, or from now if it already has.
+// Listings past the window or out of renewals have to be relisted instead.
+func (l *Listing) Renew(limits RenewalLimits, now time.Time) error {
+	switch {
+	case l.Status == ListingStatusSold:
+		return errors.ValidationError("sold listings cannot be renewed, relist them instead")
+	case l.Status != ListingStatusActive && l.Status != ListingStatusExpired:
+		return errors.ValidationError("only live or expired listings can be renewed")
+	}
+	if limits.Window > 0 && now.Before(l.ExpiresAt.Add(-limits.Window)) {
+		return errors.ValidationError(fmt.Sprintf("listings can be renewed from %s before they expire", limits.Window))
+	}
+	if limits.Window > 0 && now.After(l.ExpiresAt.Add(limits.Window)) {
+		return errors.ValidationError("listing expired too long ago to be renewed, relist it instead")
+	}
+	if limits.MaxRenewals > 0 && l.RenewalCount >= limits.MaxRenewals {
+		return errors.ValidationError("listing cannot be renewed again, relist it instead")
+	}
+
+	from := l.ExpiresAt
+	if now.After(from) {
+		from = now
+	}
+	if err := l.transitionTo(ListingStatusActive, now); err != nil {
+		return err
+	}
+	l.ExpiresAt = from.AddDate(0, 0, ListingLifetimeDays)
+	l.RenewalCount++
+	l.RenewedAt = &now
+	l.UpdatedAt = now
+	return nil
+}
+
+// IsRelistable checks if the listing is sold or has expired, so a new draft
+// can be made from it
+func (l *Listing) IsRelistable() bool {
+	return l.IsRelistableAt(time.Now())
+}
+
+// IsRelistableAt checks if the listing is sold or has expired by now
+func (l *Listing) IsRelistableAt(now time.Time) bool {
+	switch l.Status {
+	case ListingStatusSold, ListingStatusExpired:
+		return true
+	case ListingStatusActive, ListingStatusInactive:
+		return l.IsExpiredAt(now)
+	}
+	return false
+}
+
+// Relist copies a sold or expired listing into a new draft with the same
+// details, attributes and photos. The photos' files are shared with the
+// original listing.
+func (l *Listing) Relist(now time.Time) (*Listing, error) {
+	if !l.IsRelistableAt(now) {
+		return nil, errors.ValidationError("only sold or expired listings can be relisted")
+	}
+	if err := l.ensureNotHeld(); err != nil {
+		return nil, err
+	}
+
+	draft, err := NewListing(l.SellerID, l.CategoryID, l.Title, l.Description, l.Price, l.Condition, l.Location)
+	if err != nil {
+		return nil, err
+	}
+	draft.IsNegotiable = l.IsNegotiable
+	draft.Tags = append(draft.Tags, l.Tags...)
+	relistedFrom := l.ID
+	draft.RelistedFrom = &relistedFrom
+
+	for _, attribute := range l.Attributes {
+		draft.AddAttribute(attribute.Key, attribute.Value)
+	}
+	for _, image := range l.Images {
+		draft.Images = append(draft.Images, ListingImage{
+			ID:          uuid.New().String(),
+			ListingID:   draft.ID,
+			URL:         image.URL,
+			Caption:     image.Caption,
+			Order:       image.Order,
+			Variants:    image.Variants,
+			ProcessedAt: image.ProcessedAt,
+			CreatedAt:   now,
+		})
+	}
+	return draft, nil
+}
@@ -0,0 +1,86 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListing_Renew(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	limits := domain.RenewalLimits{Window: 3 * 24 * time.Hour, MaxRenewals: 2}
+	now := time.Now()
+
+	// Drafts are activated, not renewed
+	assert.Error(t, listing.Renew(limits, now))
+
+	// Too early: the listing still has most of its lifetime left
+	require.NoError(t, listing.Activate())
+	assert.Error(t, listing.Renew(limits, now))
+
+	// Inside the window the lifetime runs on from the old expiry
+	expiresAt := listing.ExpiresAt
+	require.NoError(t, listing.Renew(limits, expiresAt.Add(-time.Hour)))
+	assert.Equal(t, expiresAt.AddDate(0, 0, domain.ListingLifetimeDays), listing.ExpiresAt)
+	assert.Equal(t, 1, listing.RenewalCount)
+
+	// An expired listing runs a full lifetime from now and goes back live
+	listing.Status = domain.ListingStatusExpired
+	later := listing.ExpiresAt.Add(24 * time.Hour)
+	require.NoError(t, listing.Renew(limits, later))
+	assert.Equal(t, later.AddDate(0, 0, domain.ListingLifetimeDays), listing.ExpiresAt)
+	assert.Equal(t, domain.ListingStatusActive, listing.Status)
+
+	// Out of renewals
+	assert.Error(t, listing.Renew(limits, listing.ExpiresAt))
+
+	// Expired too long ago
+	listing.RenewalCount = 0
+	assert.Error(t, listing.Renew(limits, listing.ExpiresAt.Add(4*24*time.Hour)))
+
+	require.NoError(t, listing.MarkAsSold())
+	assert.Error(t, listing.Renew(domain.RenewalLimits{}, listing.ExpiresAt))
+}
+
+func TestListing_Relist(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "Works well", money.Cedis(100), domain.ConditionGood, domain.Location{City: "Accra"})
+	require.NoError(t, err)
+	listing.IsNegotiable = false
+	listing.AddAttribute("brand", "Tecno")
+	listing.AddImage("https://cdn.example.com/phone.jpg", "front")
+	require.NoError(t, listing.Activate())
+
+	// Live listings are renewed, not relisted
+	_, err = listing.Relist(time.Now())
+	assert.Error(t, err)
+
+	require.NoError(t, listing.MarkAsSold())
+	draft, err := listing.Relist(time.Now())
+	require.NoError(t, err)
+	assert.NotEqual(t, listing.ID, draft.ID)
+	assert.Equal(t, domain.ListingStatusDraft, draft.Status)
+	assert.Equal(t, listing.ID, *draft.RelistedFrom)
+	assert.Equal(t, "Used phone", draft.Title)
+	assert.False(t, draft.IsNegotiable)
+	require.Len(t, draft.Attributes, 1)
+	assert.Equal(t, draft.ID, draft.Attributes[0].ListingID)
+	require.Len(t, draft.Images, 1)
+	assert.Equal(t, draft.ID, draft.Images[0].ListingID)
+	assert.NotEqual(t, listing.Images[0].ID, draft.Images[0].ID)
+	assert.Equal(t, listing.Images[0].URL, draft.Images[0].URL)
+
+	// Expired listings can be relisted too, unless they are held
+	expired, err := domain.NewListing("seller-a", "category-1", "Laptop", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	require.NoError(t, expired.Activate())
+	expired.ExpiresAt = time.Now().Add(-time.Hour)
+	assert.True(t, expired.IsRelistable())
+	expired.Hold(domain.ListingHoldSellerSuspended)
+	_, err = expired.Relist(time.Now())
+	assert.Error(t, err)
+}
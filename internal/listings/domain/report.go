@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// MaxReportDetailsLength is the longest explanation a reporter can give
+const MaxReportDetailsLength = 1000
+
+// ReportReason is why a user reported a listing
+type ReportReason string
+
+const (
+	ReportReasonScam          ReportReason = "scam"
+	ReportReasonProhibited    ReportReason = "prohibited_item"
+	ReportReasonWrongCategory ReportReason = "wrong_category"
+)
+
+// IsValid checks if the reason is one users can report a listing for
+func (r ReportReason) IsValid() bool {
+	switch r {
+	case ReportReasonScam, ReportReasonProhibited, ReportReasonWrongCategory:
+		return true
+	}
+	return false
+}
+
+// ReportStatus is where a report is in review
+type ReportStatus string
+
+const (
+	ReportStatusOpen ReportStatus = "open"
+	// ReportStatusDismissed means a moderator found nothing wrong
+	ReportStatusDismissed ReportStatus = "dismissed"
+	// ReportStatusUpheld means a moderator took the listing down
+	ReportStatusUpheld ReportStatus = "upheld"
+)
+
+// ReportResolution is a moderator's decision on a report
+type ReportResolution string
+
+const (
+	ReportResolutionDismiss ReportResolution = "dismiss"
+	ReportResolutionUphold  ReportResolution = "uphold"
+)
+
+// ListingReport is a user's report that a listing breaks the marketplace's
+// rules. Each user can report a listing once.
+type ListingReport struct {
+	ID         string        `gorm:"type:uuid;primary_key" json:"id"`
+	ListingID  ids.ListingID `gorm:"type:uuid;not null;index" json:"listing_id"`
+	ReporterID ids.UserID    `gorm:"type:uuid;not null" json:"reporter_id"`
+	Reason     ReportReason  `gorm:"not null" json:"reason"`
+	Details    string        `gorm:"type:text" json:"details,omitempty"`
+	Status     ReportStatus  `gorm:"not null;default:'open';index" json:"status"`
+	ResolvedBy *ids.UserID   `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time    `json:"resolved_at,omitempty"`
+	// Note is the moderator's explanation of their decision
+	Note      string    `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewListingReport creates a user's report of a live listing. Sellers cannot
+// report their own listings.
+func NewListingReport(listing *Listing, reporterID ids.UserID, reason ReportReason, details string, now time.Time) (*ListingReport, error) {
+	if !listing.IsActiveAt(now) {
+		return nil, errors.ValidationError("listing is not available")
+	}
+	if reporterID == listing.SellerID {
+		return nil, errors.ValidationError("cannot report your own listing")
+	}
+	if !reason.IsValid() {
+		return nil, errors.ValidationError("invalid report reason")
+	}
+
+	details = strings.TrimSpace(details)
+	if len(details) > MaxReportDetailsLength {
+		return nil, errors.ValidationError("report details must be at most 1000 characters")
+	}
+
+	return &ListingReport{
+		ID:         uuid.New().String(),
+		ListingID:  listing.ID,
+		ReporterID: reporterID,
+		Reason:     reason,
+		Details:    details,
+		Status:     ReportStatusOpen,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// IsOpen checks if the report is waiting for a moderator
+func (r *ListingReport) IsOpen() bool {
+	return r.Status == ReportStatusOpen
+}
+
+// Resolve records a moderator's decision on an open report
+func (r *ListingReport) Resolve(resolution ReportResolution, adminID ids.UserID, note string, now time.Time) error {
+	if !r.IsOpen() {
+		return errors.ConflictError("report is already resolved")
+	}
+
+	switch resolution {
+	case ReportResolutionDismiss:
+		r.Status = ReportStatusDismissed
+	case ReportResolutionUphold:
+		r.Status = ReportStatusUpheld
+	default:
+		return errors.ValidationError("invalid report resolution")
+	}
+	r.ResolvedBy = &adminID
+	r.ResolvedAt = &now
+	r.Note = strings.TrimSpace(note)
+	r.UpdatedAt = now
+	return nil
+}
+
+// ListingReportRepository defines the interface for listing report persistence
+type ListingReportRepository interface {
+	Save(report *ListingReport) error
+	Update(report *ListingReport) error
+	FindByID(id string) (*ListingReport, error)
+	// HasReported checks if the user has already reported the listing
+	HasReported(listingID ids.ListingID, reporterID ids.UserID) (bool, error)
+	// FindOpenByListing finds a listing's open reports, oldest first
+	FindOpenByListing(listingID ids.ListingID) ([]*ListingReport, error)
+	// FindByStatus finds reports across listings with the given status, oldest first
+	FindByStatus(status ReportStatus, limit, offset int) ([]*ListingReport, int64, error)
+}
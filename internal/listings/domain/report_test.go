@@ -0,0 +1,49 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewListingReport(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	now := time.Now()
+
+	// Drafts are not public, so they cannot be reported
+	_, err = domain.NewListingReport(listing, "buyer-a", domain.ReportReasonScam, "", now)
+	assert.Error(t, err)
+	require.NoError(t, listing.Activate())
+
+	report, err := domain.NewListingReport(listing, "buyer-a", domain.ReportReasonScam, "", now)
+	require.NoError(t, err)
+	assert.True(t, report.IsOpen())
+
+	_, err = domain.NewListingReport(listing, "seller-a", domain.ReportReasonScam, "", now)
+	assert.Error(t, err)
+	_, err = domain.NewListingReport(listing, "buyer-a", "spam", "", now)
+	assert.Error(t, err)
+	_, err = domain.NewListingReport(listing, "buyer-a", domain.ReportReasonScam, strings.Repeat("a", 1001), now)
+	assert.Error(t, err)
+}
+
+func TestListingReport_Resolve(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+	require.NoError(t, listing.Activate())
+	now := time.Now()
+	report, err := domain.NewListingReport(listing, "buyer-a", domain.ReportReasonProhibited, "", now)
+	require.NoError(t, err)
+
+	assert.Error(t, report.Resolve("ignore", "admin-1", "", now))
+	require.NoError(t, report.Resolve(domain.ReportResolutionUphold, "admin-1", " Weapons are not allowed ", now))
+	assert.Equal(t, domain.ReportStatusUpheld, report.Status)
+	assert.Equal(t, "Weapons are not allowed", report.Note)
+	assert.Error(t, report.Resolve(domain.ReportResolutionDismiss, "admin-1", "", now))
+}
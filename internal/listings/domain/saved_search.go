@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// MaxSavedSearchesPerUser is how many searches a user can save
+const MaxSavedSearchesPerUser = 25
+
+// SavedSearchFilters are the query and filters of a saved search, the same
+// as a listing search takes. Empty filters match every listing.
+type SavedSearchFilters struct {
+	Query      string    `gorm:"size:255" json:"q,omitempty"`
+	CategoryID string    `gorm:"size:64" json:"category_id,omitempty"`
+	Condition  Condition `gorm:"size:20" json:"condition,omitempty"`
+	MinPrice   *float64  `gorm:"type:decimal(12,2)" json:"min_price,omitempty"`
+	MaxPrice   *float64  `gorm:"type:decimal(12,2)" json:"max_price,omitempty"`
+	Region     string    `gorm:"size:100" json:"region,omitempty"`
+	City       string    `gorm:"size:100" json:"city,omitempty"`
+	Negotiable *bool     `json:"negotiable,omitempty"`
+	// Latitude, Longitude and RadiusKm, when set, only match listings pinned
+	// within the radius of the point
+	Latitude  *float64 `gorm:"type:decimal(10,8)" json:"lat,omitempty"`
+	Longitude *float64 `gorm:"type:decimal(11,8)" json:"lng,omitempty"`
+	RadiusKm  float64  `gorm:"not null;default:0" json:"radius_km,omitempty"`
+}
+
+// SavedSearch is a search a buyer saved to run again, and to be alerted
+// about when new listings matching it are activated
+type SavedSearch struct {
+	ID            string             `gorm:"type:uuid;primary_key" json:"id"`
+	UserID        ids.UserID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name          string             `gorm:"size:100;not null" json:"name"`
+	Filters       SavedSearchFilters `gorm:"embedded" json:"filters"`
+	AlertsEnabled bool               `gorm:"not null;default:true" json:"alerts_enabled"`
+	// LastAlertedAt is when the user was last told about a new match
+	LastAlertedAt *time.Time `json:"last_alerted_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// NewSavedSearch saves a search for a user
+func NewSavedSearch(userID ids.UserID, name string, filters SavedSearchFilters, alertsEnabled bool, now time.Time) (*SavedSearch, error) {
+	if userID == "" {
+		return nil, errors.ValidationError("user ID is required")
+	}
+	search := &SavedSearch{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: now,
+	}
+	if err := search.Update(name, filters, alertsEnabled, now); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// Update replaces the name, filters and alert setting of the search
+func (s *SavedSearch) Update(name string, filters SavedSearchFilters, alertsEnabled bool, now time.Time) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.ValidationError("saved search name is required")
+	}
+	filters.Query = strings.TrimSpace(filters.Query)
+	if err := filters.Validate(); err != nil {
+		return err
+	}
+
+	s.Name = name
+	s.Filters = filters
+	s.AlertsEnabled = alertsEnabled
+	s.UpdatedAt = now
+	return nil
+}
+
+// Matches reports whether a listing matches the search. Words of the query
+// must each start a word of the listing's title or description, so a saved
+// "phone" matches a "Phones" listing.
+func (s *SavedSearch) Matches(listing *Listing) bool {
+	f := s.Filters
+	switch {
+	case f.CategoryID != "" && f.CategoryID != listing.CategoryID:
+		return false
+	case f.Condition != "" && f.Condition != listing.Condition:
+		return false
+	case f.MinPrice != nil && listing.Price.Major() < *f.MinPrice:
+		return false
+	case f.MaxPrice != nil && listing.Price.Major() > *f.MaxPrice:
+		return false
+	case f.Region != "" && f.Region != listing.Location.Region:
+		return false
+	case f.City != "" && f.City != listing.Location.City:
+		return false
+	case f.Negotiable != nil && *f.Negotiable != listing.IsNegotiable:
+		return false
+	}
+	if near := f.Near(); near != nil && !near.Contains(listing.Location) {
+		return false
+	}
+	return matchesQuery(f.Query, listing.Title+" "+listing.Description)
+}
+
+// Validate checks the filters can be searched on
+func (f SavedSearchFilters) Validate() error {
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return errors.ValidationError("min_price cannot be greater than max_price")
+	}
+	if (f.Latitude == nil) != (f.Longitude == nil) {
+		return errors.ValidationError("lat and lng must be given together")
+	}
+	if f.RadiusKm != 0 && f.Latitude == nil {
+		return errors.ValidationError("radius_km requires lat and lng")
+	}
+	return nil
+}
+
+// Near is the radius the search is restricted to, or nil if it is not
+func (f SavedSearchFilters) Near() *GeoRadius {
+	if f.Latitude == nil || f.Longitude == nil {
+		return nil
+	}
+	return &GeoRadius{Latitude: *f.Latitude, Longitude: *f.Longitude, RadiusKm: f.RadiusKm}
+}
+
+// matchesQuery reports whether each word of the query starts a word of the text
+func matchesQuery(query, text string) bool {
+	terms := searchWords(query)
+	if len(terms) == 0 {
+		return true
+	}
+	words := searchWords(text)
+	for _, term := range terms {
+		found := false
+		for _, word := range words {
+			if strings.HasPrefix(word, term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// searchWords splits text into lower-case words of letters and digits
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SavedSearchRepository defines the interface for saved search persistence
+type SavedSearchRepository interface {
+	Save(search *SavedSearch) error
+	Update(search *SavedSearch) error
+	Delete(id string) error
+	FindByID(id string) (*SavedSearch, error)
+	// FindByUser finds a user's saved searches, newest first
+	FindByUser(userID ids.UserID) ([]*SavedSearch, error)
+	CountByUser(userID ids.UserID) (int64, error)
+	// FindAlertCandidates finds saved searches with alerts enabled whose
+	// category, condition, price, place and negotiability filters admit the
+	// listing, ordered by ID after afterID. The query and radius are left
+	// to Matches.
+	FindAlertCandidates(listing *Listing, afterID string, limit int) ([]*SavedSearch, error)
+	// MarkAlerted records that the users of the searches were told about a new match
+	MarkAlerted(ids []string, at time.Time) error
+}
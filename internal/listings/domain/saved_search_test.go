@@ -0,0 +1,72 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSavedSearch_ValidatesFilters(t *testing.T) {
+	now := time.Now()
+	low, high := 50.0, 10.0
+	lat := 5.6
+
+	_, err := domain.NewSavedSearch("buyer-a", "  ", domain.SavedSearchFilters{}, true, now)
+	assert.Error(t, err)
+	_, err = domain.NewSavedSearch("buyer-a", "Cheap", domain.SavedSearchFilters{MinPrice: &low, MaxPrice: &high}, true, now)
+	assert.Error(t, err)
+	_, err = domain.NewSavedSearch("buyer-a", "Nearby", domain.SavedSearchFilters{Latitude: &lat}, true, now)
+	assert.Error(t, err)
+	_, err = domain.NewSavedSearch("buyer-a", "Anywhere", domain.SavedSearchFilters{RadiusKm: 5}, true, now)
+	assert.Error(t, err)
+
+	search, err := domain.NewSavedSearch("buyer-a", " Phones ", domain.SavedSearchFilters{Query: " phone "}, true, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Phones", search.Name)
+	assert.Equal(t, "phone", search.Filters.Query)
+	assert.True(t, search.AlertsEnabled)
+}
+
+func TestSavedSearch_Matches(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Samsung Galaxy phones", "Two used handsets, boxed", money.Cedis(900), domain.ConditionGood,
+		domain.Location{Region: "Greater Accra", City: "Accra", Latitude: 5.6037, Longitude: -0.1870})
+	require.NoError(t, err)
+
+	price := func(v float64) *float64 { return &v }
+	negotiable := false
+	kumasiLat, kumasiLng := 6.6885, -1.6244
+	accraLat, accraLng := 5.61, -0.19
+
+	tests := []struct {
+		name    string
+		filters domain.SavedSearchFilters
+		want    bool
+	}{
+		{"no filters", domain.SavedSearchFilters{}, true},
+		{"query words start listing words", domain.SavedSearchFilters{Query: "galaxy PHONE"}, true},
+		{"query word in description", domain.SavedSearchFilters{Query: "handset"}, true},
+		{"query word missing", domain.SavedSearchFilters{Query: "galaxy tablet"}, false},
+		{"query word inside a word", domain.SavedSearchFilters{Query: "axy"}, false},
+		{"other category", domain.SavedSearchFilters{CategoryID: "category-2"}, false},
+		{"other condition", domain.SavedSearchFilters{Condition: domain.ConditionNew}, false},
+		{"within price range", domain.SavedSearchFilters{MinPrice: price(500), MaxPrice: price(900)}, true},
+		{"below minimum price", domain.SavedSearchFilters{MinPrice: price(1000)}, false},
+		{"above maximum price", domain.SavedSearchFilters{MaxPrice: price(800)}, false},
+		{"same region and city", domain.SavedSearchFilters{Region: "Greater Accra", City: "Accra"}, true},
+		{"other city", domain.SavedSearchFilters{City: "Tema"}, false},
+		{"not negotiable", domain.SavedSearchFilters{Negotiable: &negotiable}, false},
+		{"within radius", domain.SavedSearchFilters{Latitude: &accraLat, Longitude: &accraLng, RadiusKm: 5}, true},
+		{"outside radius", domain.SavedSearchFilters{Latitude: &kumasiLat, Longitude: &kumasiLng, RadiusKm: 50}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search := &domain.SavedSearch{Filters: tt.filters}
+			assert.Equal(t, tt.want, search.Matches(listing))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"dongome/pkg/errors"
+)
+
+// MinScheduleLeadTime is how far ahead a listing must be scheduled, so the
+// worker has a run in between
+const MinScheduleLeadTime = 5 * time.Minute
+
+// SchedulingLimits caps how sellers schedule listings. Sellers have no paid
+// plans yet, so every seller gets the configured limits.
+type SchedulingLimits struct {
+	// MaxScheduled is how many listings a seller can have waiting to go live
+	MaxScheduled int
+	// MaxLeadTime is how far ahead a listing can be scheduled
+	MaxLeadTime time.Duration
+}
+
+// Check validates a publish time against the limits. scheduled is how many
+// other listings the seller already has waiting.
+func (l SchedulingLimits) Check(publishAt, now time.Time, scheduled int64) error {
+	if publishAt.Before(now.Add(MinScheduleLeadTime)) {
+		return errors.ValidationError(fmt.Sprintf("publish time must be at least %s from now", MinScheduleLeadTime))
+	}
+	if l.MaxLeadTime > 0 && publishAt.After(now.Add(l.MaxLeadTime)) {
+		return errors.ValidationError(fmt.Sprintf("listings can be scheduled at most %s ahead", l.MaxLeadTime))
+	}
+	if l.MaxScheduled > 0 && scheduled >= int64(l.MaxScheduled) {
+		return errors.ValidationError(fmt.Sprintf("you can have at most %d scheduled listings", l.MaxScheduled))
+	}
+	return nil
+}
+
+// Schedule sets a draft to go live at publishAt. Scheduling a listing again
+// moves its publish time.
+func (l *Listing) Schedule(publishAt, now time.Time) error {
+	if l.Status != ListingStatusDraft {
+		return errors.ValidationError("only draft listings can be scheduled")
+	}
+	if err := l.Details().ensureComplete(); err != nil {
+		return err
+	}
+
+	l.PublishAt = &publishAt
+	l.UpdatedAt = now
+	return nil
+}
+
+// CancelSchedule keeps a scheduled listing as a plain draft
+func (l *Listing) CancelSchedule(now time.Time) error {
+	if !l.IsScheduled() {
+		return errors.ValidationError("listing is not scheduled")
+	}
+
+	l.PublishAt = nil
+	l.UpdatedAt = now
+	return nil
+}
+
+// PublishScheduled activates a scheduled listing whose publish time has come.
+// The listing runs its full lifetime from going live.
+func (l *Listing) PublishScheduled(now time.Time) error {
+	if !l.IsScheduled() || now.Before(*l.PublishAt) {
+		return errors.ValidationError("listing is not due to be published")
+	}
+	if err := l.Activate(); err != nil {
+		return err
+	}
+
+	l.PublishAt = nil
+	l.ExpiresAt = now.AddDate(0, 0, ListingLifetimeDays)
+	return nil
+}
+
+// IsScheduled checks if the listing is a draft waiting to go live
+func (l *Listing) IsScheduled() bool {
+	return l.Status == ListingStatusDraft && l.PublishAt != nil
+}
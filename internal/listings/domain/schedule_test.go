@@ -0,0 +1,60 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulingLimits_Check(t *testing.T) {
+	now := time.Now()
+	limits := domain.SchedulingLimits{MaxScheduled: 2, MaxLeadTime: 7 * 24 * time.Hour}
+
+	assert.NoError(t, limits.Check(now.Add(time.Hour), now, 1))
+	assert.Error(t, limits.Check(now.Add(time.Minute), now, 0), "too soon")
+	assert.Error(t, limits.Check(now.Add(8*24*time.Hour), now, 0), "too far ahead")
+	assert.Error(t, limits.Check(now.Add(time.Hour), now, 2), "too many scheduled")
+
+	// Zero limits do not cap anything
+	assert.NoError(t, domain.SchedulingLimits{}.Check(now.Add(365*24*time.Hour), now, 1000))
+}
+
+func TestListing_PublishScheduled(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	publishAt := now.Add(time.Hour)
+	require.NoError(t, listing.Schedule(publishAt, now))
+	assert.True(t, listing.IsScheduled())
+	assert.Equal(t, now, listing.UpdatedAt)
+
+	// Not due yet
+	assert.Error(t, listing.PublishScheduled(now))
+
+	later := publishAt.Add(time.Minute)
+	require.NoError(t, listing.PublishScheduled(later))
+	assert.Equal(t, domain.ListingStatusActive, listing.Status)
+	assert.Nil(t, listing.PublishAt)
+	assert.Equal(t, later.AddDate(0, 0, domain.ListingLifetimeDays), listing.ExpiresAt)
+
+	// Live listings cannot be scheduled
+	assert.Error(t, listing.Schedule(later.Add(time.Hour), later))
+}
+
+func TestListing_CancelSchedule(t *testing.T) {
+	listing, err := domain.NewListing("seller-a", "category-1", "Used phone", "", money.Cedis(100), domain.ConditionGood, domain.Location{})
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	assert.Error(t, listing.CancelSchedule(now))
+	require.NoError(t, listing.Schedule(now.Add(time.Hour), now))
+	require.NoError(t, listing.CancelSchedule(now.Add(time.Minute)))
+	assert.Equal(t, now.Add(time.Minute), listing.UpdatedAt)
+	assert.False(t, listing.IsScheduled())
+	assert.Equal(t, domain.ListingStatusDraft, listing.Status)
+}
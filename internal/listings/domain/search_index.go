@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"dongome/pkg/ids"
+)
+
+// ListingDocument is the copy of a listing kept in the search index. It holds
+// only what searches filter, sort and count by; results are loaded from the
+// database by ID.
+type ListingDocument struct {
+	ID          ids.ListingID `json:"id"`
+	SellerID    ids.UserID    `json:"seller_id"`
+	CategoryID  string        `json:"category_id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	// Price is in major units, like the price filters of searches
+	Price        float64   `json:"price"`
+	Condition    Condition `json:"condition"`
+	Region       string    `json:"region"`
+	City         string    `json:"city"`
+	IsNegotiable bool      `json:"is_negotiable"`
+	// Tags are the normalized names of the listing's tags
+	Tags       []string      `json:"tags"`
+	Status     ListingStatus `json:"status"`
+	ViewsCount int64         `json:"views_count"`
+	// Location is left out for listings without coordinates
+	Location *GeoPoint `json:"location,omitempty"`
+	// RankingFactor is the seller's search ranking penalty, 1 for none
+	RankingFactor float64 `json:"ranking_factor"`
+	// PromotedUntil is left out for listings that are not promoted
+	PromotedUntil *time.Time `json:"promoted_until,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// NewListingDocument builds the index document of a listing, whose counters
+// must be filled in. card is the seller's card, or nil if the seller has none yet.
+func NewListingDocument(listing *Listing, card *SellerCard) *ListingDocument {
+	doc := &ListingDocument{
+		ID:            listing.ID,
+		SellerID:      listing.SellerID,
+		CategoryID:    listing.CategoryID,
+		Title:         listing.Title,
+		Description:   listing.Description,
+		Price:         listing.Price.Major(),
+		Condition:     listing.Condition,
+		Region:        listing.Location.Region,
+		City:          listing.Location.City,
+		IsNegotiable:  listing.IsNegotiable,
+		Status:        listing.Status,
+		ViewsCount:    listing.ViewsCount,
+		RankingFactor: 1,
+		ExpiresAt:     listing.ExpiresAt,
+		CreatedAt:     listing.CreatedAt,
+	}
+	for _, tag := range listing.Tags {
+		doc.Tags = append(doc.Tags, tag.Name)
+	}
+	if listing.Location.HasCoordinates() {
+		doc.Location = &GeoPoint{Lat: listing.Location.Latitude, Lon: listing.Location.Longitude}
+	}
+	if listing.IsPromoted {
+		doc.PromotedUntil = listing.PromotedUntil
+	}
+	if card != nil {
+		doc.RankingFactor = card.RankingFactor
+	}
+	return doc
+}
+
+// ListingHits is a page of listings found in the search index, in their sort
+// order, with the total match count and facets
+type ListingHits struct {
+	IDs    []ids.ListingID
+	Total  int64
+	Facets *SearchFacets
+}
+
+// ListingIndex is a search engine holding a copy of the marketplace's
+// listings. It may lag behind the database, so searches only use it to find
+// listing IDs.
+type ListingIndex interface {
+	// Index adds a listing to the index or replaces it
+	Index(ctx context.Context, doc *ListingDocument) error
+	// Remove drops a listing from the index; listings not in it are ignored
+	Remove(ctx context.Context, listingID ids.ListingID) error
+	// Search finds a page of active listings matching the criteria
+	Search(ctx context.Context, criteria ListingSearchCriteria, limit, offset int) (*ListingHits, error)
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/ids"
+)
+
+// SellerCard is the listings context's copy of the seller details shown on
+// listing cards. It is kept up to date from seller events, so search results
+// can show every seller's standing without calling the users context.
+type SellerCard struct {
+	SellerID            ids.UserID `gorm:"type:uuid;primary_key" json:"seller_id"`
+	Verified            bool       `gorm:"not null;default:false" json:"verified"`
+	Rating              float64    `gorm:"not null;default:0" json:"rating"`
+	TotalReviews        int        `gorm:"not null;default:0" json:"total_reviews"`
+	ResponseTimeMinutes float64    `gorm:"not null;default:0" json:"response_time_minutes"`
+	TrustLevel          string     `gorm:"not null;default:'new'" json:"trust_level"`
+	Badges              []string   `gorm:"type:jsonb;serializer:json" json:"badges"`
+	// StatsAsOf is when the stats were computed, so stats arriving out of
+	// order never overwrite newer ones
+	StatsAsOf time.Time `json:"-"`
+	// RankingFactor demotes the seller's listings in search; 1 means no penalty
+	RankingFactor  float64    `gorm:"not null;default:1" json:"-"`
+	RankingReasons []string   `gorm:"type:jsonb;serializer:json" json:"-"`
+	RankedAt       *time.Time `json:"-"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewSellerCard creates the card of a seller with no reviews or badges yet
+func NewSellerCard(sellerID ids.UserID, now time.Time) *SellerCard {
+	return &SellerCard{
+		SellerID:       sellerID,
+		TrustLevel:     "new",
+		Badges:         []string{},
+		RankingFactor:  1,
+		RankingReasons: []string{},
+		UpdatedAt:      now,
+	}
+}
+
+// Trust returns the seller trust shown with listings built from the card
+func (c *SellerCard) Trust() *SellerTrust {
+	return &SellerTrust{
+		TrustLevel: c.TrustLevel,
+		Badges:     c.Badges,
+	}
+}
+
+// SellerCardRepository defines the interface for seller card persistence.
+// Updates are upserts, since a seller's first event creates their card.
+type SellerCardRepository interface {
+	FindBySellerIDs(sellerIDs []ids.UserID) ([]*SellerCard, error)
+	// SaveStats stores the card's stats unless stats as of a later time are already stored
+	SaveStats(card *SellerCard) error
+	// MarkVerified records that a seller was verified
+	MarkVerified(sellerID ids.UserID, at time.Time) error
+	// SaveRanking stores the search ranking penalty of a seller
+	SaveRanking(sellerID ids.UserID, factor float64, reasons []string, at time.Time) error
+	// FindPenalized finds the cards of sellers whose listings are demoted, most demoted first
+	FindPenalized(limit, offset int) ([]*SellerCard, int64, error)
+}
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// ShareLinkCodeLength is the length of share link codes
+const ShareLinkCodeLength = 7
+
+// shareLinkCodeAlphabet holds the characters of share link codes: letters of
+// both cases to keep codes short, without those easily mistaken for others
+const shareLinkCodeAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// ShareChannel is where a listing's short link is shared, so clicks can be
+// counted per channel
+type ShareChannel string
+
+const (
+	ShareChannelWhatsApp ShareChannel = "whatsapp"
+	ShareChannelFacebook ShareChannel = "facebook"
+	ShareChannelTwitter  ShareChannel = "twitter"
+	ShareChannelTelegram ShareChannel = "telegram"
+	ShareChannelSMS      ShareChannel = "sms"
+	ShareChannelEmail    ShareChannel = "email"
+	// ShareChannelCopy is a link copied to be pasted anywhere
+	ShareChannelCopy ShareChannel = "copy"
+)
+
+// IsValid checks if the channel is one links can be shared on
+func (c ShareChannel) IsValid() bool {
+	switch c {
+	case ShareChannelWhatsApp, ShareChannelFacebook, ShareChannelTwitter, ShareChannelTelegram, ShareChannelSMS, ShareChannelEmail, ShareChannelCopy:
+		return true
+	default:
+		return false
+	}
+}
+
+// ShareLink is the short link of a listing on one channel. Everyone sharing
+// a listing on a channel gets the same link, so its clicks add up.
+type ShareLink struct {
+	Code      string        `gorm:"size:16;primary_key" json:"code"`
+	ListingID ids.ListingID `gorm:"type:uuid;not null" json:"listing_id"`
+	Channel   ShareChannel  `gorm:"size:20;not null" json:"channel"`
+	CreatedAt time.Time     `json:"created_at"`
+	// URL is filled in with the short link when it is served
+	URL string `gorm:"-" json:"url"`
+}
+
+// NewShareLink creates the short link of a listing on a channel, with a new
+// random code
+func NewShareLink(listingID ids.ListingID, channel ShareChannel, now time.Time) (*ShareLink, error) {
+	if listingID == "" {
+		return nil, errors.ValidationError("listing ID is required")
+	}
+	if !channel.IsValid() {
+		return nil, errors.ValidationError("channel must be one of whatsapp, facebook, twitter, telegram, sms, email, copy")
+	}
+
+	code := make([]byte, ShareLinkCodeLength)
+	size := big.NewInt(int64(len(shareLinkCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return nil, err
+		}
+		code[i] = shareLinkCodeAlphabet[n.Int64()]
+	}
+
+	return &ShareLink{
+		Code:      string(code),
+		ListingID: listingID,
+		Channel:   channel,
+		CreatedAt: now,
+	}, nil
+}
+
+// ShortLinkURL returns the URL a share link code is followed at
+func ShortLinkURL(baseURL, code string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/l/" + code
+}
+
+// linkPreviewers are parts of the user agents of the bots that fetch a link
+// to preview it when it is posted, which are not clicks
+var linkPreviewers = []string{"facebookexternalhit", "facebot", "whatsapp", "twitterbot", "telegrambot", "slackbot", "discordbot", "linkedinbot", "skypeuripreview"}
+
+// IsLinkPreviewer checks if a user agent is a bot fetching a link to
+// preview it rather than someone following it
+func IsLinkPreviewer(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, previewer := range linkPreviewers {
+		if strings.Contains(userAgent, previewer) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShareLinkDailyClicks counts the clicks on a share link on one day
+type ShareLinkDailyClicks struct {
+	Code      string    `gorm:"size:16;primary_key" json:"code"`
+	Day       time.Time `gorm:"type:date;primary_key" json:"day"`
+	Clicks    int64     `gorm:"not null;default:0" json:"clicks"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table of share link daily clicks
+func (ShareLinkDailyClicks) TableName() string {
+	return "share_link_daily_clicks"
+}
+
+// ChannelClicks are the clicks on a listing's link on one channel
+type ChannelClicks struct {
+	Channel ShareChannel `json:"channel"`
+	Code    string       `json:"code"`
+	URL     string       `json:"url"`
+	Clicks  int64        `json:"clicks"`
+}
+
+// DailyClicks are the clicks on a listing's links on one day
+type DailyClicks struct {
+	Day    string `json:"day"`
+	Clicks int64  `json:"clicks"`
+}
+
+// ShareStats are the clicks on the share links of a listing over a period:
+// in total, per channel, most clicked first, and on every day of it
+type ShareStats struct {
+	ListingID ids.ListingID   `json:"listing_id"`
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Clicks    int64           `json:"clicks"`
+	Channels  []ChannelClicks `json:"channels"`
+	Days      []DailyClicks   `json:"days"`
+}
+
+// NewShareStats sums up the daily clicks on a listing's share links from one
+// day to another, both included. Links are served with their URLs.
+func NewShareStats(listingID ids.ListingID, links []*ShareLink, clicks []*ShareLinkDailyClicks, from, to time.Time) *ShareStats {
+	from, to = AnalyticsDay(from), AnalyticsDay(to)
+	stats := &ShareStats{
+		ListingID: listingID,
+		From:      from.Format(AnalyticsDayFormat),
+		To:        to.Format(AnalyticsDayFormat),
+		Channels:  []ChannelClicks{},
+		Days:      []DailyClicks{},
+	}
+
+	byCode := make(map[string]int64)
+	byDay := make(map[string]int64)
+	for _, click := range clicks {
+		day := AnalyticsDay(click.Day)
+		if day.Before(from) || day.After(to) {
+			continue
+		}
+		byCode[click.Code] += click.Clicks
+		byDay[day.Format(AnalyticsDayFormat)] += click.Clicks
+		stats.Clicks += click.Clicks
+	}
+
+	for _, link := range links {
+		stats.Channels = append(stats.Channels, ChannelClicks{
+			Channel: link.Channel,
+			Code:    link.Code,
+			URL:     link.URL,
+			Clicks:  byCode[link.Code],
+		})
+	}
+	sort.Slice(stats.Channels, func(i, j int) bool {
+		a, b := stats.Channels[i], stats.Channels[j]
+		if a.Clicks != b.Clicks {
+			return a.Clicks > b.Clicks
+		}
+		return a.Channel < b.Channel
+	})
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(AnalyticsDayFormat)
+		stats.Days = append(stats.Days, DailyClicks{Day: key, Clicks: byDay[key]})
+	}
+	return stats
+}
+
+// ShareLinkRepository defines the interface for share link persistence
+type ShareLinkRepository interface {
+	Save(link *ShareLink) error
+	FindByCode(code string) (*ShareLink, error)
+	// FindByListingAndChannel finds the link of a listing on a channel, or
+	// returns a not found error
+	FindByListingAndChannel(listingID ids.ListingID, channel ShareChannel) (*ShareLink, error)
+	FindByListing(listingID ids.ListingID) ([]*ShareLink, error)
+	// RecordClick counts a click on a link on a day, creating the day's
+	// count on its first click
+	RecordClick(code string, day time.Time, at time.Time) error
+	// FindClicks finds the daily clicks on links from one day to another,
+	// both included
+	FindClicks(codes []string, from, to time.Time) ([]*ShareLinkDailyClicks, error)
+}
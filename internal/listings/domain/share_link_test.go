@@ -0,0 +1,62 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewShareLink(t *testing.T) {
+	now := time.Now()
+
+	_, err := domain.NewShareLink("listing-1", "myspace", now)
+	assert.Error(t, err)
+	_, err = domain.NewShareLink("", domain.ShareChannelWhatsApp, now)
+	assert.Error(t, err)
+
+	first, err := domain.NewShareLink("listing-1", domain.ShareChannelWhatsApp, now)
+	require.NoError(t, err)
+	second, err := domain.NewShareLink("listing-1", domain.ShareChannelWhatsApp, now)
+	require.NoError(t, err)
+	assert.Len(t, first.Code, domain.ShareLinkCodeLength)
+	assert.NotEqual(t, first.Code, second.Code)
+	assert.Equal(t, "https://dongo.me/l/"+first.Code, domain.ShortLinkURL("https://dongo.me/", first.Code))
+}
+
+func TestIsLinkPreviewer(t *testing.T) {
+	assert.True(t, domain.IsLinkPreviewer("facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)"))
+	assert.True(t, domain.IsLinkPreviewer("WhatsApp/2.23.20.0 A"))
+	assert.True(t, domain.IsLinkPreviewer("TelegramBot (like TwitterBot)"))
+	assert.False(t, domain.IsLinkPreviewer("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148 [FBAN/FBIOS]"))
+	assert.False(t, domain.IsLinkPreviewer(""))
+}
+
+func TestNewShareStats(t *testing.T) {
+	from := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+	links := []*domain.ShareLink{
+		{Code: "aaaaaaa", Channel: domain.ShareChannelFacebook},
+		{Code: "bbbbbbb", Channel: domain.ShareChannelWhatsApp},
+		{Code: "ccccccc", Channel: domain.ShareChannelSMS},
+	}
+	clicks := []*domain.ShareLinkDailyClicks{
+		{Code: "aaaaaaa", Day: from, Clicks: 1},
+		{Code: "bbbbbbb", Day: from, Clicks: 2},
+		{Code: "bbbbbbb", Day: to, Clicks: 3},
+		{Code: "bbbbbbb", Day: to.AddDate(0, 0, 1), Clicks: 50},
+	}
+
+	stats := domain.NewShareStats("listing-1", links, clicks, from, to)
+	assert.Equal(t, "2026-03-01", stats.From)
+	assert.Equal(t, "2026-03-03", stats.To)
+	assert.Equal(t, int64(6), stats.Clicks, "clicks outside the period are left out")
+	require.Len(t, stats.Channels, 3)
+	assert.Equal(t, domain.ShareChannelWhatsApp, stats.Channels[0].Channel)
+	assert.Equal(t, domain.ShareChannelFacebook, stats.Channels[1].Channel)
+	assert.Equal(t, domain.ShareChannelSMS, stats.Channels[2].Channel, "links never clicked are listed too")
+	assert.Equal(t, []domain.DailyClicks{{Day: "2026-03-01", Clicks: 3}, {Day: "2026-03-02", Clicks: 0}, {Day: "2026-03-03", Clicks: 3}}, stats.Days)
+}
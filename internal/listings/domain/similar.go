@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// SimilarPriceBand is how far, as a share of a listing's price, the price of
+// a similar listing may be from it
+const SimilarPriceBand = 0.5
+
+// How much each kind of likeness counts toward a similarity score. They add
+// up to 1, so scores run from 0 to 1.
+const (
+	similarAttributeWeight = 0.5
+	similarLocationWeight  = 0.3
+	similarPriceWeight     = 0.2
+)
+
+// SimilarListing is a listing found similar to another, with how similar it is
+type SimilarListing struct {
+	Listing *Listing `json:"listing"`
+	Score   float64  `json:"score"`
+}
+
+// SimilarPriceRange returns the prices, in major units, of listings
+// comparable to the listing's
+func (l *Listing) SimilarPriceRange() (min, max float64) {
+	return l.Price.Scale(1 - SimilarPriceBand).Major(), l.Price.Scale(1 + SimilarPriceBand).Major()
+}
+
+// SimilarityTo scores how similar another listing of the same category is to
+// this one: mostly by the attributes they share, then by how close they are
+// and how close their prices are. Listings of another category, or the
+// listing itself, score 0.
+func (l *Listing) SimilarityTo(other *Listing) float64 {
+	if other.ID == l.ID || other.CategoryID != l.CategoryID {
+		return 0
+	}
+	return similarAttributeWeight*attributeOverlap(l.Attributes, other.Attributes) +
+		similarLocationWeight*locationCloseness(l.Location, other.Location) +
+		similarPriceWeight*priceCloseness(l.Price.Major(), other.Price.Major())
+}
+
+// RankSimilar scores the candidates against the listing and returns the
+// most similar, best first, up to limit. Ties go to the most recent listing.
+func (l *Listing) RankSimilar(candidates []*Listing, limit int) []SimilarListing {
+	ranked := make([]SimilarListing, 0, len(candidates))
+	for _, candidate := range candidates {
+		if score := l.SimilarityTo(candidate); score > 0 {
+			ranked = append(ranked, SimilarListing{Listing: candidate, Score: math.Round(score*1000) / 1000})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Listing.CreatedAt.After(ranked[j].Listing.CreatedAt)
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// attributeOverlap is the share of the two listings' attributes that they
+// both have with the same value. Listings with no attributes overlap fully,
+// as nothing tells them apart.
+func attributeOverlap(a, b []ListingAttribute) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	values := make(map[string]string, len(a))
+	for _, attribute := range a {
+		values[attribute.Key] = strings.ToLower(strings.TrimSpace(attribute.Value))
+	}
+	shared := 0
+	for _, attribute := range b {
+		if value, ok := values[attribute.Key]; ok && value == strings.ToLower(strings.TrimSpace(attribute.Value)) {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// locationCloseness is 1 for listings in the same city, half that in the same
+// region and 0 otherwise
+func locationCloseness(a, b Location) float64 {
+	if !strings.EqualFold(a.Region, b.Region) {
+		return 0
+	}
+	if strings.EqualFold(a.City, b.City) {
+		return 1
+	}
+	return 0.5
+}
+
+// priceCloseness is 1 for the same price, falling to 0 at the edge of the
+// similar price band
+func priceCloseness(price, other float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	closeness := 1 - math.Abs(other-price)/(price*SimilarPriceBand)
+	return math.Max(closeness, 0)
+}
@@ -0,0 +1,56 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSimilarListing(t *testing.T, price float64, city string, attributes map[string]string) *domain.Listing {
+	t.Helper()
+	listing, err := domain.NewListing("seller-a", "phones", "Phone", "", money.Cedis(price), domain.ConditionGood,
+		domain.Location{Region: "Greater Accra", City: city})
+	require.NoError(t, err)
+	for key, value := range attributes {
+		listing.Attributes = append(listing.Attributes, domain.ListingAttribute{ListingID: listing.ID, Key: key, Value: value})
+	}
+	return listing
+}
+
+func TestListing_SimilarityTo(t *testing.T) {
+	source := newSimilarListing(t, 1000, "Accra", map[string]string{"brand": "Samsung", "storage": "128GB"})
+
+	twin := newSimilarListing(t, 1000, "Accra", map[string]string{"brand": "samsung ", "storage": "128GB"})
+	assert.InDelta(t, 1.0, source.SimilarityTo(twin), 0.001, "attribute values are compared loosely")
+
+	sameBrand := newSimilarListing(t, 1250, "Tema", map[string]string{"brand": "Samsung", "storage": "64GB"})
+	// A third of the attributes shared, in the region but not the city, half way across the price band
+	assert.InDelta(t, 0.5/3+0.3*0.5+0.2*0.5, source.SimilarityTo(sameBrand), 0.001)
+
+	otherCategory := newSimilarListing(t, 1000, "Accra", map[string]string{"brand": "Samsung", "storage": "128GB"})
+	otherCategory.CategoryID = "laptops"
+	assert.Zero(t, source.SimilarityTo(otherCategory))
+	assert.Zero(t, source.SimilarityTo(source))
+
+	min, max := source.SimilarPriceRange()
+	assert.Equal(t, 500.0, min)
+	assert.Equal(t, 1500.0, max)
+}
+
+func TestListing_RankSimilar(t *testing.T) {
+	source := newSimilarListing(t, 1000, "Accra", map[string]string{"brand": "Samsung"})
+	nearby := newSimilarListing(t, 1000, "Accra", map[string]string{"brand": "Samsung"})
+	far := newSimilarListing(t, 1400, "Kumasi", map[string]string{"brand": "Tecno"})
+	far.Location.Region = "Ashanti"
+	middle := newSimilarListing(t, 1100, "Tema", map[string]string{"brand": "Samsung"})
+
+	ranked := source.RankSimilar([]*domain.Listing{far, source, middle, nearby}, 2)
+	require.Len(t, ranked, 2)
+	assert.Equal(t, nearby.ID, ranked[0].Listing.ID)
+	assert.Equal(t, middle.ID, ranked[1].Listing.ID)
+	assert.Greater(t, ranked[0].Score, ranked[1].Score)
+}
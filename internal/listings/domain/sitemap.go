@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"dongome/pkg/ids"
+)
+
+// MaxSitemapURLs is the most URLs a sitemap file may list
+const MaxSitemapURLs = 50000
+
+// Names of the files generated for search engines and feed readers
+const (
+	SitemapFile  = "sitemap.xml"
+	AtomFeedFile = "listings.atom"
+	JSONFeedFile = "listings.json"
+)
+
+// SitemapContentTypes are the content types the generated files are served with
+var SitemapContentTypes = map[string]string{
+	SitemapFile:  "application/xml; charset=utf-8",
+	AtomFeedFile: "application/atom+xml; charset=utf-8",
+	JSONFeedFile: "application/feed+json; charset=utf-8",
+}
+
+// SitemapVersion is one generation of the sitemap and listing feeds, with
+// the storage keys of its files by name
+type SitemapVersion struct {
+	Version     string            `json:"version"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Listings    int               `json:"listings"`
+	Files       map[string]string `json:"files"`
+}
+
+// ListingPageURL returns the URL of a listing's page on the site
+func ListingPageURL(siteURL string, listingID ids.ListingID) string {
+	return strings.TrimSuffix(siteURL, "/") + "/listings/" + listingID.String()
+}
+
+// SitemapURL is a page listed in a sitemap
+type SitemapURL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// WriteSitemap writes a sitemap of the given pages in the sitemaps.org format
+func WriteSitemap(w io.Writer, urls []SitemapURL) error {
+	type sitemapURL struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	}
+	urlset := struct {
+		XMLName xml.Name     `xml:"urlset"`
+		XMLNS   string       `xml:"xmlns,attr"`
+		URLs    []sitemapURL `xml:"url"`
+	}{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, url := range urls {
+		urlset.URLs = append(urlset.URLs, sitemapURL{Loc: url.Loc, LastMod: url.LastMod.UTC().Format(time.RFC3339)})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(urlset)
+}
+
+// ListingFeed is a feed of the newest listings for feed readers and
+// aggregators, newest first
+type ListingFeed struct {
+	Title   string
+	SiteURL string
+	// FeedURL is where the feed itself is served, without its extension
+	FeedURL  string
+	Updated  time.Time
+	Listings []*Listing
+}
+
+// WriteAtom writes the feed in the Atom format
+func (f ListingFeed) WriteAtom(w io.Writer) error {
+	type atomLink struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr,omitempty"`
+		Type string `xml:"type,attr,omitempty"`
+	}
+	type atomCategory struct {
+		Term string `xml:"term,attr"`
+	}
+	type atomEntry struct {
+		ID         string         `xml:"id"`
+		Title      string         `xml:"title"`
+		Link       atomLink       `xml:"link"`
+		Published  string         `xml:"published"`
+		Updated    string         `xml:"updated"`
+		Summary    string         `xml:"summary"`
+		Categories []atomCategory `xml:"category"`
+	}
+	feed := struct {
+		XMLName xml.Name    `xml:"feed"`
+		XMLNS   string      `xml:"xmlns,attr"`
+		ID      string      `xml:"id"`
+		Title   string      `xml:"title"`
+		Updated string      `xml:"updated"`
+		Links   []atomLink  `xml:"link"`
+		Entries []atomEntry `xml:"entry"`
+	}{
+		XMLNS:   "http://www.w3.org/2005/Atom",
+		ID:      f.FeedURL + ".atom",
+		Title:   f.Title,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.FeedURL + ".atom", Rel: "self", Type: "application/atom+xml"},
+			{Href: f.SiteURL},
+		},
+	}
+	for _, listing := range f.Listings {
+		url := ListingPageURL(f.SiteURL, listing.ID)
+		entry := atomEntry{
+			ID:        url,
+			Title:     listing.Title,
+			Link:      atomLink{Href: url},
+			Published: listing.CreatedAt.UTC().Format(time.RFC3339),
+			Updated:   listing.UpdatedAt.UTC().Format(time.RFC3339),
+			Summary:   listing.Price.String() + " · " + listing.Description,
+		}
+		for _, tag := range listing.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag.Name})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(feed)
+}
+
+// WriteJSON writes the feed in the JSON Feed 1.1 format
+func (f ListingFeed) WriteJSON(w io.Writer) error {
+	type jsonFeedItem struct {
+		ID            string    `json:"id"`
+		URL           string    `json:"url"`
+		Title         string    `json:"title"`
+		ContentText   string    `json:"content_text"`
+		Summary       string    `json:"summary"`
+		Image         string    `json:"image,omitempty"`
+		DatePublished time.Time `json:"date_published"`
+		DateModified  time.Time `json:"date_modified"`
+		Tags          []string  `json:"tags,omitempty"`
+	}
+	feed := struct {
+		Version     string         `json:"version"`
+		Title       string         `json:"title"`
+		HomePageURL string         `json:"home_page_url"`
+		FeedURL     string         `json:"feed_url"`
+		Items       []jsonFeedItem `json:"items"`
+	}{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.SiteURL,
+		FeedURL:     f.FeedURL + ".json",
+		Items:       []jsonFeedItem{},
+	}
+	for _, listing := range f.Listings {
+		url := ListingPageURL(f.SiteURL, listing.ID)
+		item := jsonFeedItem{
+			ID:            url,
+			URL:           url,
+			Title:         listing.Title,
+			ContentText:   listing.Description,
+			Summary:       listing.Price.String(),
+			DatePublished: listing.CreatedAt.UTC(),
+			DateModified:  listing.UpdatedAt.UTC(),
+		}
+		if len(listing.Images) > 0 {
+			item.Image = listing.Images[0].URL
+		}
+		for _, tag := range listing.Tags {
+			item.Tags = append(item.Tags, tag.Name)
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return json.NewEncoder(w).Encode(feed)
+}
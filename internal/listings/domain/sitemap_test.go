@@ -0,0 +1,77 @@
+package domain_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSitemap(t *testing.T) {
+	updated := time.Date(2026, time.March, 10, 9, 30, 0, 0, time.UTC)
+	var out bytes.Buffer
+	require.NoError(t, domain.WriteSitemap(&out, []domain.SitemapURL{
+		{Loc: domain.ListingPageURL("https://dongome.com/", "listing-1"), LastMod: updated},
+	}))
+
+	assert.Contains(t, out.String(), `<?xml version="1.0" encoding="UTF-8"?>`)
+	assert.Contains(t, out.String(), `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	assert.Contains(t, out.String(), "<url><loc>https://dongome.com/listings/listing-1</loc><lastmod>2026-03-10T09:30:00Z</lastmod></url>")
+}
+
+func TestListingFeed(t *testing.T) {
+	created := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+	feed := domain.ListingFeed{
+		Title:   "New listings",
+		SiteURL: "https://dongome.com",
+		FeedURL: "https://dongome.com/feeds/listings",
+		Updated: created.Add(time.Hour),
+		Listings: []*domain.Listing{{
+			ID:          "listing-1",
+			Title:       "iPhone 13 & case",
+			Description: "Barely used",
+			Price:       money.Cedis(4500),
+			Images:      []domain.ListingImage{{URL: "https://media.example.com/1.jpg"}},
+			Tags:        []domain.ListingTag{{Name: "iphone"}},
+			CreatedAt:   created,
+			UpdatedAt:   created,
+		}},
+	}
+
+	var atom bytes.Buffer
+	require.NoError(t, feed.WriteAtom(&atom))
+	var parsed struct {
+		Title   string `xml:"title"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Title   string `xml:"title"`
+			Summary string `xml:"summary"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(atom.Bytes(), &parsed))
+	assert.Equal(t, "New listings", parsed.Title)
+	require.Len(t, parsed.Entries, 1)
+	assert.Equal(t, "https://dongome.com/listings/listing-1", parsed.Entries[0].ID)
+	assert.Equal(t, "iPhone 13 & case", parsed.Entries[0].Title, "titles are escaped")
+	assert.Equal(t, "GHS 4500.00 · Barely used", parsed.Entries[0].Summary)
+	assert.Contains(t, atom.String(), `<link href="https://dongome.com/feeds/listings.atom" rel="self" type="application/atom+xml"></link>`)
+
+	var jsonFeed bytes.Buffer
+	require.NoError(t, feed.WriteJSON(&jsonFeed))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonFeed.Bytes(), &decoded))
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", decoded["version"])
+	assert.Equal(t, "https://dongome.com/feeds/listings.json", decoded["feed_url"])
+	items := decoded["items"].([]interface{})
+	require.Len(t, items, 1)
+	item := items[0].(map[string]interface{})
+	assert.Equal(t, "https://media.example.com/1.jpg", item["image"])
+	assert.Equal(t, []interface{}{"iphone"}, item["tags"])
+}
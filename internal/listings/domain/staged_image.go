@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// Limits on photo uploads
+const (
+	MaxListingImages        = 20
+	MaxImagesPerUpload      = 20
+	MaxStagedImagesPerOwner = 100
+	MaxImageSize            = 10 << 20
+)
+
+// StagedImageTTL is how long an uploaded photo waits to be attached to a
+// listing before it is cleaned up
+const StagedImageTTL = 24 * time.Hour
+
+// ImageContentTypes maps the accepted photo content types to file extensions
+var ImageContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// StagedImage is a photo uploaded ahead of the listing it belongs to.
+// Sellers on mobile can take and upload many photos first and attach them
+// to a listing in one go later.
+type StagedImage struct {
+	ID          string     `gorm:"type:uuid;primary_key" json:"id"`
+	OwnerID     ids.UserID `gorm:"type:uuid;not null;index" json:"owner_id"`
+	StorageKey  string     `gorm:"not null" json:"-"`
+	URL         string     `gorm:"not null" json:"url"`
+	ContentType string     `gorm:"not null" json:"content_type"`
+	Size        int64      `gorm:"not null" json:"size"`
+	// AwaitingUpload is set while the owner's app uploads the photo straight
+	// to storage, until the upload is confirmed
+	AwaitingUpload bool           `gorm:"not null;default:false" json:"awaiting_upload,omitempty"`
+	ListingID      *ids.ListingID `gorm:"type:uuid;index" json:"listing_id,omitempty"`
+	AttachedAt     *time.Time     `json:"attached_at,omitempty"`
+	ExpiresAt      time.Time      `gorm:"index" json:"expires_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// NewStagedImageID returns the ID for a new staged image, used to name it in storage
+func NewStagedImageID() string {
+	return uuid.New().String()
+}
+
+// NewStagedImage records a photo stored under storageKey for an owner
+func NewStagedImage(id string, ownerID ids.UserID, storageKey, url, contentType string, size int64) (*StagedImage, error) {
+	if ownerID == "" {
+		return nil, errors.ValidationError("owner is required")
+	}
+	if err := ValidateImage(contentType, size); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &StagedImage{
+		ID:          id,
+		OwnerID:     ownerID,
+		StorageKey:  storageKey,
+		URL:         url,
+		ContentType: contentType,
+		Size:        size,
+		ExpiresAt:   now.Add(StagedImageTTL),
+		CreatedAt:   now,
+	}, nil
+}
+
+// NewAwaitingImage records a photo the owner's app is about to upload
+// straight to storage under storageKey
+func NewAwaitingImage(id string, ownerID ids.UserID, storageKey, url, contentType string, size int64) (*StagedImage, error) {
+	image, err := NewStagedImage(id, ownerID, storageKey, url, contentType, size)
+	if err != nil {
+		return nil, err
+	}
+	image.AwaitingUpload = true
+	return image, nil
+}
+
+// ConfirmUpload records that the photo reached storage, with the content type
+// and size storage reports
+func (i *StagedImage) ConfirmUpload(contentType string, size int64) error {
+	if !i.AwaitingUpload {
+		return nil
+	}
+	if err := ValidateImage(contentType, size); err != nil {
+		return err
+	}
+	i.ContentType = contentType
+	i.Size = size
+	i.AwaitingUpload = false
+	return nil
+}
+
+// ValidateImage checks a photo's content type and size
+func ValidateImage(contentType string, size int64) error {
+	if _, ok := ImageContentTypes[contentType]; !ok {
+		return errors.ValidationError("photos must be JPEG, PNG or WebP images")
+	}
+	if size <= 0 {
+		return errors.ValidationError("photo is empty")
+	}
+	if size > MaxImageSize {
+		return errors.ValidationError(fmt.Sprintf("photos must be at most %d MB", MaxImageSize>>20))
+	}
+	return nil
+}
+
+// AttachTo marks the photo as used by a listing. Attaching a photo to the
+// listing it is already attached to is allowed, so a failed attach can be retried.
+func (i *StagedImage) AttachTo(listingID ids.ListingID, now time.Time) error {
+	if i.ListingID != nil {
+		if *i.ListingID == listingID {
+			return nil
+		}
+		return errors.ConflictError("photo is already attached to another listing")
+	}
+	if i.Expired(now) {
+		return errors.ValidationError("photo upload has expired, please upload it again")
+	}
+	if i.AwaitingUpload {
+		return errors.ValidationError("photo upload has not been confirmed")
+	}
+
+	i.ListingID = &listingID
+	i.AttachedAt = &now
+	return nil
+}
+
+// IsAttached checks if the photo has been attached to a listing
+func (i *StagedImage) IsAttached() bool {
+	return i.ListingID != nil
+}
+
+// Expired checks if an unattached photo has waited too long to be attached
+func (i *StagedImage) Expired(now time.Time) bool {
+	return !i.IsAttached() && !now.Before(i.ExpiresAt)
+}
+
+// HasImage checks if the listing already shows a staged photo
+func (l *Listing) HasImage(staged *StagedImage) bool {
+	for _, image := range l.Images {
+		if image.StorageKey == staged.StorageKey || image.URL == staged.URL {
+			return true
+		}
+	}
+	return false
+}
+
+// AddStagedImage adds a staged photo to the listing and returns the added image
+func (l *Listing) AddStagedImage(staged *StagedImage) ListingImage {
+	l.AddImage(staged.URL, "")
+	image := &l.Images[len(l.Images)-1]
+	image.StorageKey = staged.StorageKey
+	return *image
+}
+
+// StagedImageRepository defines the interface for staged image persistence
+type StagedImageRepository interface {
+	Save(image *StagedImage) error
+	FindByIDs(ownerID ids.UserID, imageIDs []string) ([]*StagedImage, error)
+	CountPendingByOwner(ownerID ids.UserID, now time.Time) (int64, error)
+	FindExpired(now time.Time, limit int) ([]*StagedImage, error)
+	UpdateAll(images []*StagedImage) error
+	Delete(id string) error
+}
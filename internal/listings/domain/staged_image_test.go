@@ -0,0 +1,37 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateImage(t *testing.T) {
+	assert.NoError(t, domain.ValidateImage("image/webp", 1024))
+	assert.Error(t, domain.ValidateImage("image/gif", 1024))
+	assert.Error(t, domain.ValidateImage("image/png", 0))
+	assert.Error(t, domain.ValidateImage("image/png", domain.MaxImageSize+1))
+}
+
+func TestStagedImage_AttachTo(t *testing.T) {
+	image, err := domain.NewStagedImage(domain.NewStagedImageID(), "seller-a", "seller-a/photo.jpg", "https://media.example.com/seller-a/photo.jpg", "image/jpeg", 2048)
+	require.NoError(t, err)
+	now := time.Now()
+	assert.False(t, image.Expired(now))
+	assert.True(t, image.Expired(now.Add(domain.StagedImageTTL)))
+
+	require.NoError(t, image.AttachTo("listing-1", now))
+	assert.True(t, image.IsAttached())
+	assert.False(t, image.Expired(now.Add(domain.StagedImageTTL)), "attached photos never expire")
+
+	assert.NoError(t, image.AttachTo("listing-1", now), "attaching again to the same listing is allowed")
+	assert.Error(t, image.AttachTo("listing-2", now))
+
+	expired, err := domain.NewStagedImage(domain.NewStagedImageID(), "seller-a", "seller-a/late.jpg", "https://media.example.com/seller-a/late.jpg", "image/jpeg", 2048)
+	require.NoError(t, err)
+	assert.Error(t, expired.AttachTo("listing-1", now.Add(domain.StagedImageTTL+time.Second)))
+}
@@ -0,0 +1,49 @@
+package domain
+
+// Limits on the attributes a seller can give when asking for a suggestion
+const (
+	MaxSuggestionAttributes  = 20
+	MaxSuggestionValueLength = 100
+)
+
+// MaxListingTitleLength is the longest title a listing can go live with
+const MaxListingTitleLength = 100
+
+// SuggestionRequest describes the listing a seller is drafting
+type SuggestionRequest struct {
+	// CategoryPath lists the names of the listing's category and its
+	// ancestors, most specific first
+	CategoryPath []string
+	Condition    Condition
+	// Attributes are lowercase attribute names such as brand and model
+	Attributes map[string]string
+}
+
+// ListingSuggestion is a suggested title and description skeleton for a new listing
+type ListingSuggestion struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// MissingAttributes lists attributes the suggestion would use that were not given
+	MissingAttributes []string `json:"missing_attributes"`
+	// Source names what produced the suggestion, such as "template"
+	Source string `json:"source"`
+}
+
+// ConditionLabel returns the wording used for a condition in listing text
+func ConditionLabel(condition Condition) string {
+	switch condition {
+	case ConditionNew:
+		return "Brand new"
+	case ConditionLikeNew:
+		return "Like new"
+	case ConditionGood:
+		return "Good"
+	case ConditionFair:
+		return "Fair"
+	case ConditionPoor:
+		return "Poor"
+	case ConditionForParts:
+		return "For parts"
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// Tag limits
+const (
+	MinTagNameLength = 2
+	MaxTagNameLength = 50
+	// MaxListingTags is the most tags a listing can have
+	MaxListingTags = 10
+)
+
+// tagColor matches hex colour codes such as #1a2b3c
+var tagColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ListingTag is a free-form label sellers put on listings, such as "iphone"
+// or "free delivery", that buyers browse by. Names are kept normalized, so
+// "iPhone" and "#iphone " are the same tag.
+type ListingTag struct {
+	ID        string    `gorm:"type:uuid;primary_key" json:"id"`
+	Name      string    `gorm:"size:50;uniqueIndex;not null" json:"name"`
+	Color     string    `gorm:"size:7" json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagCount is a tag with the number of active listings carrying it
+type TagCount struct {
+	ListingTag
+	Listings int64 `json:"listings"`
+}
+
+// NormalizeTagName writes a tag name the way it is stored: lower case,
+// without a leading # and with single spaces between words
+func NormalizeTagName(name string) string {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "#")
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// NewListingTag creates a tag with a normalized name. color is a hex code
+// such as #1a2b3c, or empty for none.
+func NewListingTag(name, color string) (*ListingTag, error) {
+	tag := &ListingTag{
+		ID:        uuid.New().String(),
+		CreatedAt: time.Now(),
+	}
+	if err := tag.Update(name, color); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// Update renames the tag and changes its colour
+func (t *ListingTag) Update(name, color string) error {
+	name = NormalizeTagName(name)
+	if length := utf8.RuneCountInString(name); length < MinTagNameLength || length > MaxTagNameLength {
+		return errors.ValidationError("tag name must be between 2 and 50 characters")
+	}
+	color = strings.TrimSpace(color)
+	if color != "" && !tagColor.MatchString(color) {
+		return errors.ValidationError("tag color must be a hex code such as #1a2b3c")
+	}
+
+	t.Name = name
+	t.Color = strings.ToLower(color)
+	return nil
+}
+
+// NormalizeTagNames normalizes the names of the tags a seller puts on a
+// listing, dropping blanks and repeats, and refuses more than MaxListingTags
+func NormalizeTagNames(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	if len(normalized) > MaxListingTags {
+		return nil, errors.ValidationError("a listing can have at most 10 tags")
+	}
+	return normalized, nil
+}
+
+// TagRepository defines the interface for tag persistence
+type TagRepository interface {
+	Save(tag *ListingTag) error
+	FindByID(id string) (*ListingTag, error)
+	// FindByNames finds the tags with the given normalized names; missing
+	// names are left out
+	FindByNames(names []string) ([]*ListingTag, error)
+	// List finds tags whose name starts with prefix, or every tag when it is
+	// empty, most used first
+	List(prefix string, limit, offset int) ([]*TagCount, int64, error)
+	// FindTrending finds the tags most used on active listings created since,
+	// most used first
+	FindTrending(since time.Time, limit int) ([]*TagCount, error)
+	// FindPopular finds the tags most used on active listings of a category,
+	// or of every category when categoryID is empty, whose name starts with
+	// prefix, most used first
+	FindPopular(categoryID, prefix string, limit int) ([]*TagCount, error)
+	// FindListingIDs finds the listings carrying a tag
+	FindListingIDs(tagID string) ([]ids.ListingID, error)
+	// SetListingTags replaces the tags of a listing
+	SetListingTags(listingID ids.ListingID, tagIDs []string) error
+	Update(tag *ListingTag) error
+	// Merge moves the listings of the source tags to the target tag and
+	// deletes the sources
+	Merge(targetID string, sourceIDs []string) error
+	Delete(id string) error
+}
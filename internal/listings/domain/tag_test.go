@@ -0,0 +1,37 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/listings/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTagName(t *testing.T) {
+	assert.Equal(t, "iphone", domain.NormalizeTagName(" #iPhone "))
+	assert.Equal(t, "free delivery", domain.NormalizeTagName("Free \t Delivery"))
+}
+
+func TestNewListingTag(t *testing.T) {
+	tag, err := domain.NewListingTag("Kente Cloth", "#A1B2C3")
+	require.NoError(t, err)
+	assert.Equal(t, "kente cloth", tag.Name)
+	assert.Equal(t, "#a1b2c3", tag.Color)
+
+	_, err = domain.NewListingTag("#x", "")
+	assert.Error(t, err, "names must be at least 2 characters")
+	_, err = domain.NewListingTag("kente", "red")
+	assert.Error(t, err)
+}
+
+func TestNormalizeTagNames(t *testing.T) {
+	names, err := domain.NormalizeTagNames([]string{"Phone", "phone", " ", "#Unlocked"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"phone", "unlocked"}, names)
+
+	tooMany := []string{"a1", "a2", "a3", "a4", "a5", "a6", "a7", "a8", "a9", "a10", "a11"}
+	_, err = domain.NormalizeTagNames(tooMany)
+	assert.Error(t, err)
+}
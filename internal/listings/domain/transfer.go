@@ -0,0 +1,221 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// TransferExpiry is how long a transfer waits for confirmation before it lapses
+const TransferExpiry = 7 * 24 * time.Hour
+
+// TransferScope represents what an ownership transfer covers
+type TransferScope string
+
+const (
+	TransferScopeListing    TransferScope = "listing"
+	TransferScopeStorefront TransferScope = "storefront"
+)
+
+// TransferStatus represents the status of an ownership transfer
+type TransferStatus string
+
+const (
+	TransferStatusPending   TransferStatus = "pending"
+	TransferStatusCompleted TransferStatus = "completed"
+	TransferStatusRejected  TransferStatus = "rejected"
+	TransferStatusCancelled TransferStatus = "cancelled"
+)
+
+// OwnershipTransfer moves a single listing or a seller's entire storefront to
+// another seller once both the current and the new owner have confirmed
+type OwnershipTransfer struct {
+	ID                   string         `gorm:"type:uuid;primary_key" json:"id"`
+	Scope                TransferScope  `gorm:"not null" json:"scope"`
+	ListingID            *ids.ListingID `gorm:"type:uuid;index" json:"listing_id,omitempty"`
+	FromSellerID         ids.UserID     `gorm:"type:uuid;not null;index" json:"from_seller_id"`
+	ToSellerID           ids.UserID     `gorm:"type:uuid;not null;index" json:"to_seller_id"`
+	InitiatedBy          ids.UserID     `gorm:"type:uuid;not null" json:"initiated_by"`
+	Status               TransferStatus `gorm:"default:'pending'" json:"status"`
+	Note                 string         `json:"note"`
+	SenderConfirmedAt    *time.Time     `json:"sender_confirmed_at,omitempty"`
+	RecipientConfirmedAt *time.Time     `json:"recipient_confirmed_at,omitempty"`
+	ListingsTransferred  int            `gorm:"default:0" json:"listings_transferred"`
+	CompletedAt          *time.Time     `json:"completed_at,omitempty"`
+	ExpiresAt            time.Time      `json:"expires_at"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+}
+
+// OwnershipRecord is one entry in a listing's ownership history
+type OwnershipRecord struct {
+	ID            string        `gorm:"type:uuid;primary_key" json:"id"`
+	ListingID     ids.ListingID `gorm:"type:uuid;not null;index" json:"listing_id"`
+	TransferID    string        `gorm:"type:uuid;not null;index" json:"transfer_id"`
+	FromSellerID  ids.UserID    `gorm:"type:uuid;not null" json:"from_seller_id"`
+	ToSellerID    ids.UserID    `gorm:"type:uuid;not null" json:"to_seller_id"`
+	TransferredAt time.Time     `json:"transferred_at"`
+}
+
+// NewOwnershipTransfer creates a pending transfer. A transfer started by the
+// current owner counts as their confirmation; one started by an admin needs
+// both sellers to confirm.
+func NewOwnershipTransfer(listingID *ids.ListingID, fromSellerID, toSellerID, initiatedBy ids.UserID, note string) (*OwnershipTransfer, error) {
+	if fromSellerID == "" || toSellerID == "" {
+		return nil, errors.ValidationError("both seller IDs are required")
+	}
+	if fromSellerID == toSellerID {
+		return nil, errors.ValidationError("cannot transfer to the current owner")
+	}
+	if initiatedBy == "" {
+		return nil, errors.ValidationError("initiator is required")
+	}
+
+	scope := TransferScopeStorefront
+	if listingID != nil {
+		if *listingID == "" {
+			return nil, errors.ValidationError("listing ID is required")
+		}
+		scope = TransferScopeListing
+	}
+
+	now := time.Now()
+	transfer := &OwnershipTransfer{
+		ID:           uuid.New().String(),
+		Scope:        scope,
+		ListingID:    listingID,
+		FromSellerID: fromSellerID,
+		ToSellerID:   toSellerID,
+		InitiatedBy:  initiatedBy,
+		Status:       TransferStatusPending,
+		Note:         note,
+		ExpiresAt:    now.Add(TransferExpiry),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if initiatedBy == fromSellerID {
+		transfer.SenderConfirmedAt = &now
+	}
+
+	return transfer, nil
+}
+
+// Confirm records the confirmation of one of the two sellers
+func (t *OwnershipTransfer) Confirm(sellerID ids.UserID) error {
+	if err := t.ensurePending(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	switch sellerID {
+	case t.FromSellerID:
+		if t.SenderConfirmedAt == nil {
+			t.SenderConfirmedAt = &now
+		}
+	case t.ToSellerID:
+		if t.RecipientConfirmedAt == nil {
+			t.RecipientConfirmedAt = &now
+		}
+	default:
+		return errors.ForbiddenError("only the sellers involved can confirm a transfer")
+	}
+
+	t.UpdatedAt = now
+	return nil
+}
+
+// IsConfirmed checks if both sellers have confirmed the transfer
+func (t *OwnershipTransfer) IsConfirmed() bool {
+	return t.SenderConfirmedAt != nil && t.RecipientConfirmedAt != nil
+}
+
+// Complete marks a confirmed transfer as carried out
+func (t *OwnershipTransfer) Complete(listingsTransferred int) error {
+	if err := t.ensurePending(); err != nil {
+		return err
+	}
+	if !t.IsConfirmed() {
+		return errors.ValidationError("transfer has not been confirmed by both sellers")
+	}
+
+	now := time.Now()
+	t.Status = TransferStatusCompleted
+	t.ListingsTransferred = listingsTransferred
+	t.CompletedAt = &now
+	t.UpdatedAt = now
+	return nil
+}
+
+// Reject declines the transfer on behalf of the recipient
+func (t *OwnershipTransfer) Reject(sellerID ids.UserID) error {
+	if err := t.ensurePending(); err != nil {
+		return err
+	}
+	if sellerID != t.ToSellerID {
+		return errors.ForbiddenError("only the recipient can reject a transfer")
+	}
+
+	t.Status = TransferStatusRejected
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// Cancel withdraws the transfer on behalf of the current owner or the initiator
+func (t *OwnershipTransfer) Cancel(userID ids.UserID) error {
+	if err := t.ensurePending(); err != nil {
+		return err
+	}
+	if userID != t.FromSellerID && userID != t.InitiatedBy {
+		return errors.ForbiddenError("only the current owner can cancel a transfer")
+	}
+
+	t.Status = TransferStatusCancelled
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsExpired checks if the transfer lapsed without being confirmed
+func (t *OwnershipTransfer) IsExpired() bool {
+	return t.Status == TransferStatusPending && time.Now().After(t.ExpiresAt)
+}
+
+// Involves checks if the user is a party to the transfer
+func (t *OwnershipTransfer) Involves(userID ids.UserID) bool {
+	return userID == t.FromSellerID || userID == t.ToSellerID || userID == t.InitiatedBy
+}
+
+// ensurePending rejects changes to finished or expired transfers
+func (t *OwnershipTransfer) ensurePending() error {
+	if t.Status != TransferStatusPending {
+		return errors.ValidationError("transfer is no longer pending")
+	}
+	if t.IsExpired() {
+		return errors.ValidationError("transfer has expired")
+	}
+	return nil
+}
+
+// NewOwnershipRecord records a listing changing hands as part of a transfer
+func NewOwnershipRecord(listingID ids.ListingID, transferID string, fromSellerID, toSellerID ids.UserID) *OwnershipRecord {
+	return &OwnershipRecord{
+		ID:            uuid.New().String(),
+		ListingID:     listingID,
+		TransferID:    transferID,
+		FromSellerID:  fromSellerID,
+		ToSellerID:    toSellerID,
+		TransferredAt: time.Now(),
+	}
+}
+
+// OwnershipTransferRepository defines the interface for ownership transfer persistence
+type OwnershipTransferRepository interface {
+	Save(transfer *OwnershipTransfer) error
+	FindByID(id string) (*OwnershipTransfer, error)
+	FindPendingBySeller(sellerID ids.UserID) ([]*OwnershipTransfer, error)
+	Update(transfer *OwnershipTransfer) error
+	SaveRecord(record *OwnershipRecord) error
+	FindHistory(listingID ids.ListingID) ([]*OwnershipRecord, error)
+}
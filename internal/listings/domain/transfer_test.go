@@ -0,0 +1,86 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/ids"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOwnershipTransfer(t *testing.T) {
+	listingID := ids.ListingID("listing-1")
+
+	transfer, err := domain.NewOwnershipTransfer(&listingID, "seller-a", "seller-b", "seller-a", "sold the shop")
+	require.NoError(t, err)
+	assert.Equal(t, domain.TransferScopeListing, transfer.Scope)
+	assert.Equal(t, domain.TransferStatusPending, transfer.Status)
+	// The owner starting the transfer counts as their confirmation
+	assert.NotNil(t, transfer.SenderConfirmedAt)
+	assert.Nil(t, transfer.RecipientConfirmedAt)
+	assert.False(t, transfer.IsConfirmed())
+
+	storefront, err := domain.NewOwnershipTransfer(nil, "seller-a", "seller-b", "admin-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.TransferScopeStorefront, storefront.Scope)
+	assert.Nil(t, storefront.SenderConfirmedAt)
+
+	_, err = domain.NewOwnershipTransfer(nil, "seller-a", "seller-a", "seller-a", "")
+	assert.Error(t, err)
+	_, err = domain.NewOwnershipTransfer(nil, "", "seller-b", "seller-a", "")
+	assert.Error(t, err)
+}
+
+func TestOwnershipTransfer_ConfirmAndComplete(t *testing.T) {
+	transfer, err := domain.NewOwnershipTransfer(nil, "seller-a", "seller-b", "admin-1", "")
+	require.NoError(t, err)
+
+	// Cannot complete before both sellers confirm
+	assert.Error(t, transfer.Complete(0))
+
+	// Strangers cannot confirm
+	assert.Error(t, transfer.Confirm("seller-c"))
+
+	require.NoError(t, transfer.Confirm("seller-b"))
+	assert.False(t, transfer.IsConfirmed())
+	require.NoError(t, transfer.Confirm("seller-a"))
+	assert.True(t, transfer.IsConfirmed())
+
+	require.NoError(t, transfer.Complete(3))
+	assert.Equal(t, domain.TransferStatusCompleted, transfer.Status)
+	assert.Equal(t, 3, transfer.ListingsTransferred)
+	assert.NotNil(t, transfer.CompletedAt)
+
+	// Completed transfers are final
+	assert.Error(t, transfer.Confirm("seller-b"))
+	assert.Error(t, transfer.Cancel("seller-a"))
+}
+
+func TestOwnershipTransfer_RejectAndCancel(t *testing.T) {
+	transfer, err := domain.NewOwnershipTransfer(nil, "seller-a", "seller-b", "seller-a", "")
+	require.NoError(t, err)
+
+	// Only the recipient can reject
+	assert.Error(t, transfer.Reject("seller-a"))
+	require.NoError(t, transfer.Reject("seller-b"))
+	assert.Equal(t, domain.TransferStatusRejected, transfer.Status)
+
+	transfer, err = domain.NewOwnershipTransfer(nil, "seller-a", "seller-b", "seller-a", "")
+	require.NoError(t, err)
+
+	// Only the current owner can cancel
+	assert.Error(t, transfer.Cancel("seller-b"))
+	require.NoError(t, transfer.Cancel("seller-a"))
+	assert.Equal(t, domain.TransferStatusCancelled, transfer.Status)
+}
+
+func TestOwnershipTransfer_Expired(t *testing.T) {
+	transfer, err := domain.NewOwnershipTransfer(nil, "seller-a", "seller-b", "seller-a", "")
+	require.NoError(t, err)
+
+	transfer.ExpiresAt = time.Now().Add(-time.Minute)
+	assert.True(t, transfer.IsExpired())
+	assert.Error(t, transfer.Confirm("seller-b"))
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+)
+
+// MaxAttributeLabelLength is the longest attribute label
+const MaxAttributeLabelLength = 100
+
+// CategoryTranslation is a category's name and description in a locale other
+// than the default one, which is kept on the category itself
+type CategoryTranslation struct {
+	CategoryID  string    `gorm:"type:uuid;primaryKey" json:"category_id"`
+	Locale      string    `gorm:"size:10;primaryKey" json:"locale"`
+	Name        string    `gorm:"size:100;not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewCategoryTranslation creates the translation of a category into a locale
+func NewCategoryTranslation(categoryID, locale, name, description string) (*CategoryTranslation, error) {
+	parsed, ok := i18n.ParseLocale(locale)
+	if !ok {
+		return nil, errors.ValidationError("unsupported locale")
+	}
+	if parsed == i18n.DefaultLocale {
+		return nil, errors.ValidationError("names in the default locale are set on the category itself")
+	}
+	name = strings.TrimSpace(name)
+	if err := validateCategoryName(name); err != nil {
+		return nil, err
+	}
+
+	return &CategoryTranslation{
+		CategoryID:  categoryID,
+		Locale:      string(parsed),
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+// AttributeLabel is the label shown for a listing attribute, such as "RAM"
+// for ram, in one locale
+type AttributeLabel struct {
+	Key       string    `gorm:"size:100;primaryKey" json:"key"`
+	Locale    string    `gorm:"size:10;primaryKey" json:"locale"`
+	Label     string    `gorm:"size:100;not null" json:"label"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewAttributeLabel creates the label of an attribute in a locale. Attribute
+// names are matched case-insensitively, like on listings.
+func NewAttributeLabel(key, locale, label string) (*AttributeLabel, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		return nil, errors.ValidationError("attribute names cannot be empty")
+	}
+	parsed, ok := i18n.ParseLocale(locale)
+	if !ok {
+		return nil, errors.ValidationError("unsupported locale")
+	}
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return nil, errors.ValidationError("label is required")
+	}
+	if len([]rune(label)) > MaxAttributeLabelLength {
+		return nil, errors.ValidationError("label must be at most 100 characters")
+	}
+
+	return &AttributeLabel{
+		Key:       key,
+		Locale:    string(parsed),
+		Label:     label,
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// LocalizedCategory is a category's name and description in one locale
+type LocalizedCategory struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Translations resolves category names and attribute labels in one locale.
+// Anything not translated into it is in the default locale instead.
+type Translations struct {
+	Locale     i18n.Locale                  `json:"locale"`
+	Categories map[string]LocalizedCategory `json:"categories"`
+	Attributes map[string]string            `json:"attributes"`
+}
+
+// BuildTranslations resolves the names of every category and the labels of
+// every attribute in a locale. translations and labels may hold other
+// locales; only the requested one and the default locale are used.
+func BuildTranslations(locale i18n.Locale, categories []*Category, translations []*CategoryTranslation, labels []*AttributeLabel) *Translations {
+	t := &Translations{
+		Locale:     locale,
+		Categories: make(map[string]LocalizedCategory, len(categories)),
+		Attributes: make(map[string]string),
+	}
+	for _, category := range categories {
+		t.Categories[category.ID] = LocalizedCategory{Name: category.Name, Description: category.Description}
+	}
+	for _, translation := range translations {
+		if _, ok := t.Categories[translation.CategoryID]; ok && translation.Locale == string(locale) {
+			t.Categories[translation.CategoryID] = LocalizedCategory{Name: translation.Name, Description: translation.Description}
+		}
+	}
+
+	for _, label := range labels {
+		if label.Locale == string(i18n.DefaultLocale) {
+			if _, ok := t.Attributes[label.Key]; !ok {
+				t.Attributes[label.Key] = label.Label
+			}
+		}
+	}
+	for _, label := range labels {
+		if label.Locale == string(locale) {
+			t.Attributes[label.Key] = label.Label
+		}
+	}
+	return t
+}
+
+// AttributeLabel returns the label of an attribute, or its name if it has none
+func (t *Translations) AttributeLabel(key string) string {
+	if label, ok := t.Attributes[strings.ToLower(strings.TrimSpace(key))]; ok {
+		return label
+	}
+	return key
+}
+
+// LocalizeTree replaces the names and descriptions in a category tree
+func (t *Translations) LocalizeTree(nodes []*CategoryNode) {
+	for _, node := range nodes {
+		if localized, ok := t.Categories[node.ID]; ok {
+			node.Name = localized.Name
+			node.Description = localized.Description
+		}
+		t.LocalizeTree(node.Children)
+	}
+}
+
+// LocalizeListings fills in the category name and attribute labels shown
+// with each listing
+func (t *Translations) LocalizeListings(listings []*Listing) {
+	for _, listing := range listings {
+		if localized, ok := t.Categories[listing.CategoryID]; ok {
+			listing.CategoryName = localized.Name
+		}
+		for i := range listing.Attributes {
+			listing.Attributes[i].Label = t.AttributeLabel(listing.Attributes[i].Key)
+		}
+	}
+}
+
+// TranslationRepository defines the interface for translation persistence
+type TranslationRepository interface {
+	// FindCategoryTranslations finds the translations of every category into a locale
+	FindCategoryTranslations(locale string) ([]*CategoryTranslation, error)
+	FindCategoryTranslationsByCategory(categoryID string) ([]*CategoryTranslation, error)
+	// SaveCategoryTranslation creates or replaces a category's translation
+	SaveCategoryTranslation(translation *CategoryTranslation) error
+	DeleteCategoryTranslation(categoryID, locale string) error
+	// FindAttributeLabels finds attribute labels, in every locale when locale is empty
+	FindAttributeLabels(locale string) ([]*AttributeLabel, error)
+	// SaveAttributeLabel creates or replaces an attribute's label
+	SaveAttributeLabel(label *AttributeLabel) error
+	DeleteAttributeLabel(key, locale string) error
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"dongome/pkg/ids"
+)
+
+// TrendingWindow is how far back buyer activity counts toward trending scores
+const TrendingWindow = 7 * 24 * time.Hour
+
+// TrendingHalfLife is how long it takes activity to count half as much
+const TrendingHalfLife = 24 * time.Hour
+
+// How much each kind of buyer activity counts toward a trending score.
+// Favorites and messages show more interest than a look.
+const (
+	trendingViewWeight     = 1
+	trendingFavoriteWeight = 5
+	trendingMessageWeight  = 10
+)
+
+// TrendingScore is how hot a listing is: its recent views, favorites and
+// messages, each counting less the older it is
+type TrendingScore struct {
+	ListingID ids.ListingID `json:"listing_id"`
+	Score     float64       `json:"score"`
+}
+
+// TrendingListing is a trending listing with its score
+type TrendingListing struct {
+	Listing *Listing `json:"listing"`
+	Score   float64  `json:"score"`
+}
+
+// RankTrending scores listings by their daily stats within TrendingWindow
+// of now and returns the hottest, best first, up to limit. A day's activity
+// is taken to have happened at its midday, or now for today's before then.
+func RankTrending(stats []*ListingDailyStats, now time.Time, limit int) []TrendingScore {
+	since := now.Add(-TrendingWindow)
+	scores := make(map[ids.ListingID]float64)
+	for _, stat := range stats {
+		at := AnalyticsDay(stat.Day).Add(12 * time.Hour)
+		if at.After(now) {
+			at = now
+		}
+		if at.Before(since) {
+			continue
+		}
+		decay := math.Pow(0.5, now.Sub(at).Hours()/TrendingHalfLife.Hours())
+		scores[stat.ListingID] += stat.ListingActivity.trendingWeight() * decay
+	}
+
+	ranked := make([]TrendingScore, 0, len(scores))
+	for listingID, score := range scores {
+		if score > 0 {
+			ranked = append(ranked, TrendingScore{ListingID: listingID, Score: math.Round(score*1000) / 1000})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].ListingID < ranked[j].ListingID
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// trendingWeight is the activity weighted by how much interest each kind shows
+func (a ListingActivity) trendingWeight() float64 {
+	return float64(a.Views*trendingViewWeight + a.Favorites*trendingFavoriteWeight + a.Messages*trendingMessageWeight)
+}
@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/listings/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankTrending(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC) }
+	now := day(10).Add(9 * time.Hour)
+	stats := []*domain.ListingDailyStats{
+		{ListingID: "phone", Day: day(10), ListingActivity: domain.ListingActivity{Views: 10}},
+		{ListingID: "bag", Day: day(9), ListingActivity: domain.ListingActivity{Messages: 1}},
+		{ListingID: "laptop", Day: day(7), ListingActivity: domain.ListingActivity{Views: 30, Orders: 4}},
+		{ListingID: "laptop", Day: day(2), ListingActivity: domain.ListingActivity{Views: 1000}},
+		{ListingID: "shoes", Day: day(10), ListingActivity: domain.ListingActivity{Favorites: -1}},
+	}
+
+	ranked := domain.RankTrending(stats, now, 10)
+	require.Len(t, ranked, 3, "listings without recent interest are left out")
+	// Today's activity counts in full until midday
+	assert.Equal(t, domain.TrendingScore{ListingID: "phone", Score: 10}, ranked[0])
+	// A message counts for ten views, halving every day
+	assert.Equal(t, domain.TrendingScore{ListingID: "bag", Score: 5.453}, ranked[1])
+	// Orders do not count, nor do views from before the window
+	assert.Equal(t, domain.TrendingScore{ListingID: "laptop", Score: 4.089}, ranked[2])
+
+	assert.Len(t, domain.RankTrending(stats, now, 1), 1)
+}
@@ -0,0 +1,77 @@
+package infra
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ghanaLocations is the canonical Ghana region, city and area reference data
+//
+//go:embed seed/ghana_locations.json
+var ghanaLocations []byte
+
+// AdminLocationHandler handles HTTP requests for location reference data administration
+type AdminLocationHandler struct {
+	locationService *app.LocationService
+}
+
+// NewAdminLocationHandler creates a new admin location handler
+func NewAdminLocationHandler(locationService *app.LocationService) *AdminLocationHandler {
+	return &AdminLocationHandler{
+		locationService: locationService,
+	}
+}
+
+// RegisterRoutes registers admin location routes. The group must be protected
+// by the admin role.
+func (h *AdminLocationHandler) RegisterRoutes(r *gin.RouterGroup) {
+	locations := r.Group("/locations")
+	{
+		locations.POST("/seed", h.SeedLocations)
+		locations.POST("/import", h.ImportLocations)
+	}
+}
+
+// SeedLocations handles loading the bundled Ghana reference data
+func (h *AdminLocationHandler) SeedLocations(c *gin.Context) {
+	var cmd app.ImportLocationsCommand
+	if err := json.Unmarshal(ghanaLocations, &cmd); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "invalid seed data")})
+		return
+	}
+
+	h.importLocations(c, cmd)
+}
+
+// ImportLocations handles importing custom reference data
+func (h *AdminLocationHandler) ImportLocations(c *gin.Context) {
+	var cmd app.ImportLocationsCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	h.importLocations(c, cmd)
+}
+
+// importLocations runs an import and writes its summary
+func (h *AdminLocationHandler) importLocations(c *gin.Context, cmd app.ImportLocationsCommand) {
+	summary, err := h.locationService.ImportLocations(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,59 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SellerAnalyticsHandler handles HTTP requests for sellers' analytics
+type SellerAnalyticsHandler struct {
+	analyticsService *app.AnalyticsService
+}
+
+// NewSellerAnalyticsHandler creates a new seller analytics handler
+func NewSellerAnalyticsHandler(analyticsService *app.AnalyticsService) *SellerAnalyticsHandler {
+	return &SellerAnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// RegisterRoutes registers seller analytics routes. The group must be
+// protected by RequireAuth.
+func (h *SellerAnalyticsHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/sellers/:id/analytics", h.GetSellerAnalytics)
+}
+
+// GetSellerAnalytics handles retrieving the analytics of the caller's
+// listings. The :id path parameter must be "me" or the caller's own ID.
+func (h *SellerAnalyticsHandler) GetSellerAnalytics(c *gin.Context) {
+	sellerID := auth.UserID(c)
+	if id := c.Param("id"); id != "me" && id != sellerID.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": i18n.Localize(c, "you can only see the analytics of your own listings"), "code": errors.ErrCodeForbidden})
+		return
+	}
+
+	var query app.SellerAnalyticsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	analytics, err := h.analyticsService.GetSellerAnalytics(c.Request.Context(), sellerID, query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
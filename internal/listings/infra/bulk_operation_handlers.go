@@ -0,0 +1,134 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminBulkOperationHandler handles HTTP requests for submitting and following
+// bulk operations on listings
+type AdminBulkOperationHandler struct {
+	bulkOperationService *app.BulkOperationService
+}
+
+// NewAdminBulkOperationHandler creates a new admin bulk operation handler
+func NewAdminBulkOperationHandler(bulkOperationService *app.BulkOperationService) *AdminBulkOperationHandler {
+	return &AdminBulkOperationHandler{
+		bulkOperationService: bulkOperationService,
+	}
+}
+
+// RegisterRoutes registers admin bulk operation routes. The group must be
+// protected by the admin role.
+func (h *AdminBulkOperationHandler) RegisterRoutes(r *gin.RouterGroup) {
+	operations := r.Group("/bulk-operations")
+	{
+		operations.POST("", h.SubmitOperation)
+		operations.GET("", h.ListOperations)
+		operations.GET("/:id", h.GetOperation)
+		operations.GET("/:id/items", h.ListItems)
+		operations.POST("/:id/cancel", h.CancelOperation)
+	}
+}
+
+// SubmitOperation handles queueing a bulk operation. It is carried out by the
+// worker, so the response only holds the operation's ID and status.
+func (h *AdminBulkOperationHandler) SubmitOperation(c *gin.Context) {
+	var cmd app.SubmitBulkOperationCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.AdminID = auth.UserID(c)
+
+	operation, err := h.bulkOperationService.Submit(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, operation)
+}
+
+// ListOperations handles listing bulk operations, newest first
+func (h *AdminBulkOperationHandler) ListOperations(c *gin.Context) {
+	var query app.ListBulkOperationsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	operations, err := h.bulkOperationService.ListOperations(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, operations)
+}
+
+// GetOperation handles retrieving a bulk operation with its progress
+func (h *AdminBulkOperationHandler) GetOperation(c *gin.Context) {
+	operation, err := h.bulkOperationService.GetOperation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, operation)
+}
+
+// ListItems handles listing the per-listing results of a bulk operation
+func (h *AdminBulkOperationHandler) ListItems(c *gin.Context) {
+	var query app.ListBulkOperationItemsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	items, err := h.bulkOperationService.ListItems(c.Request.Context(), c.Param("id"), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// CancelOperation handles stopping a pending or running bulk operation
+func (h *AdminBulkOperationHandler) CancelOperation(c *gin.Context) {
+	operation, err := h.bulkOperationService.Cancel(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, operation)
+}
@@ -0,0 +1,177 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// bulkItemInsertBatch is how many bulk operation items are inserted per statement
+const bulkItemInsertBatch = 500
+
+// BulkOperationGORMRepository implements BulkOperationRepository using GORM
+type BulkOperationGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewBulkOperationGORMRepository creates a new bulk operation repository
+func NewBulkOperationGORMRepository(db *gorm.DB) *BulkOperationGORMRepository {
+	return &BulkOperationGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a bulk operation to the database
+func (r *BulkOperationGORMRepository) Save(operation *domain.BulkOperation) error {
+	return db.ClassifyError(r.db.Create(operation).Error)
+}
+
+// FindByID finds a bulk operation by ID
+func (r *BulkOperationGORMRepository) FindByID(id string) (*domain.BulkOperation, error) {
+	var operation domain.BulkOperation
+	if err := r.db.Where("id = ?", id).First(&operation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("bulk operation not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &operation, nil
+}
+
+// FindAll finds operations, optionally in one status, newest first, with the total count
+func (r *BulkOperationGORMRepository) FindAll(status domain.BulkOperationStatus, limit, offset int) ([]*domain.BulkOperation, int64, error) {
+	query := r.db.Model(&domain.BulkOperation{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var operations []*domain.BulkOperation
+	err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&operations).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return operations, total, nil
+}
+
+// FindUnfinished finds pending and running operations, oldest first
+func (r *BulkOperationGORMRepository) FindUnfinished(limit int) ([]*domain.BulkOperation, error) {
+	var operations []*domain.BulkOperation
+	err := r.db.Where("status IN ?", []domain.BulkOperationStatus{domain.BulkOperationPending, domain.BulkOperationRunning}).
+		Order("created_at").
+		Limit(limit).
+		Find(&operations).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return operations, nil
+}
+
+// SaveProgress stores an operation's status and counts unless it was
+// cancelled meanwhile, reporting whether they were stored
+func (r *BulkOperationGORMRepository) SaveProgress(operation *domain.BulkOperation) (bool, error) {
+	result := r.db.Model(&domain.BulkOperation{}).
+		Where("id = ? AND status <> ?", operation.ID, domain.BulkOperationCancelled).
+		Select("status", "total", "processed", "succeeded", "skipped", "failed", "started_at", "finished_at", "updated_at").
+		Updates(operation)
+	if result.Error != nil {
+		return false, db.ClassifyError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SaveCancellation stores that an operation was cancelled unless it finished
+// meanwhile, reporting whether it was stored
+func (r *BulkOperationGORMRepository) SaveCancellation(operation *domain.BulkOperation) (bool, error) {
+	result := r.db.Model(&domain.BulkOperation{}).
+		Where("id = ? AND status IN ?", operation.ID, []domain.BulkOperationStatus{domain.BulkOperationPending, domain.BulkOperationRunning}).
+		Select("status", "cancelled_by", "finished_at", "updated_at").
+		Updates(operation)
+	if result.Error != nil {
+		return false, db.ClassifyError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SaveItems saves the items of an operation. Items already saved are left
+// alone, so an operation whose start was interrupted can start again.
+func (r *BulkOperationGORMRepository) SaveItems(items []*domain.BulkOperationItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return db.ClassifyError(r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(items, bulkItemInsertBatch).Error)
+}
+
+// FindPendingItems finds items of an operation still to be processed
+func (r *BulkOperationGORMRepository) FindPendingItems(operationID string, limit int) ([]*domain.BulkOperationItem, error) {
+	var items []*domain.BulkOperationItem
+	err := r.db.Where("operation_id = ? AND status = ?", operationID, domain.BulkItemPending).
+		Order("listing_id").
+		Limit(limit).
+		Find(&items).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return items, nil
+}
+
+// FindItems finds the items of an operation, optionally in one status, with the total count
+func (r *BulkOperationGORMRepository) FindItems(operationID string, status domain.BulkItemStatus, limit, offset int) ([]*domain.BulkOperationItem, int64, error) {
+	query := r.db.Model(&domain.BulkOperationItem{}).Where("operation_id = ?", operationID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var items []*domain.BulkOperationItem
+	err := query.Order("listing_id").
+		Limit(limit).
+		Offset(offset).
+		Find(&items).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return items, total, nil
+}
+
+// CountItems counts the items of an operation by status
+func (r *BulkOperationGORMRepository) CountItems(operationID string) (map[domain.BulkItemStatus]int, error) {
+	var rows []struct {
+		Status domain.BulkItemStatus
+		Count  int
+	}
+	err := r.db.Model(&domain.BulkOperationItem{}).
+		Select("status, COUNT(*) AS count").
+		Where("operation_id = ?", operationID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+
+	counts := make(map[domain.BulkItemStatus]int, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// UpdateItem stores the result of an operation on one listing
+func (r *BulkOperationGORMRepository) UpdateItem(item *domain.BulkOperationItem) error {
+	return db.ClassifyError(r.db.Save(item).Error)
+}
@@ -0,0 +1,164 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CategoryHandler handles HTTP requests for browsing categories
+type CategoryHandler struct {
+	categoryService *app.CategoryService
+}
+
+// NewCategoryHandler creates a new category handler
+func NewCategoryHandler(categoryService *app.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// RegisterRoutes registers the public category routes
+func (h *CategoryHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/categories", h.ListCategories)
+}
+
+// ListCategories handles listing the active categories as a tree, named in
+// the request locale
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	tree, err := h.categoryService.CategoryTree(c.Request.Context(), i18n.FromContext(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": tree})
+}
+
+// AdminCategoryHandler handles HTTP requests for category administration
+type AdminCategoryHandler struct {
+	categoryService *app.CategoryService
+}
+
+// NewAdminCategoryHandler creates a new admin category handler
+func NewAdminCategoryHandler(categoryService *app.CategoryService) *AdminCategoryHandler {
+	return &AdminCategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// RegisterRoutes registers category administration routes. The group must be
+// protected by the admin role.
+func (h *AdminCategoryHandler) RegisterRoutes(r *gin.RouterGroup) {
+	categories := r.Group("/categories")
+	{
+		categories.GET("", h.ListCategories)
+		categories.POST("", h.CreateCategory)
+		categories.PUT("/order", h.ReorderCategories)
+		categories.PUT("/:id", h.UpdateCategory)
+		categories.POST("/:id/deactivate", h.DeactivateCategory)
+	}
+}
+
+// ListCategories handles listing every category, including inactive ones, as a tree
+func (h *AdminCategoryHandler) ListCategories(c *gin.Context) {
+	tree, err := h.categoryService.FullCategoryTree(c.Request.Context())
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": tree})
+}
+
+// CreateCategory handles adding a category
+func (h *AdminCategoryHandler) CreateCategory(c *gin.Context) {
+	var cmd app.CreateCategoryCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory handles renaming, moving or showing a category
+func (h *AdminCategoryHandler) UpdateCategory(c *gin.Context) {
+	var cmd app.UpdateCategoryCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.CategoryID = c.Param("id")
+
+	category, err := h.categoryService.UpdateCategory(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// ReorderCategories handles ordering the subcategories of a parent
+func (h *AdminCategoryHandler) ReorderCategories(c *gin.Context) {
+	var cmd app.ReorderCategoriesCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	categories, err := h.categoryService.ReorderCategories(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+// DeactivateCategory handles hiding a category and its subcategories
+func (h *AdminCategoryHandler) DeactivateCategory(c *gin.Context) {
+	category, err := h.categoryService.DeactivateCategory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
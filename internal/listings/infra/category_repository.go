@@ -0,0 +1,67 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// CategoryGORMRepository implements CategoryRepository using GORM
+type CategoryGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryGORMRepository creates a new category repository
+func NewCategoryGORMRepository(db *gorm.DB) *CategoryGORMRepository {
+	return &CategoryGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a category to the database
+func (r *CategoryGORMRepository) Save(category *domain.Category) error {
+	return db.ClassifyError(r.db.Omit("Parent", "Children").Create(category).Error)
+}
+
+// FindByID finds a category by ID
+func (r *CategoryGORMRepository) FindByID(id string) (*domain.Category, error) {
+	var category domain.Category
+	err := r.db.First(&category, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("category not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &category, nil
+}
+
+// FindAll finds all categories ordered by position and then name
+func (r *CategoryGORMRepository) FindAll() ([]*domain.Category, error) {
+	var categories []*domain.Category
+	if err := r.db.Order("position ASC, name ASC").Find(&categories).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return categories, nil
+}
+
+// FindByParent finds the subcategories of a category ordered by position and then name
+func (r *CategoryGORMRepository) FindByParent(parentID string) ([]*domain.Category, error) {
+	var categories []*domain.Category
+	if err := r.db.Where("parent_id = ?", parentID).Order("position ASC, name ASC").Find(&categories).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return categories, nil
+}
+
+// Update updates a category in the database
+func (r *CategoryGORMRepository) Update(category *domain.Category) error {
+	return db.ClassifyError(r.db.Omit("Parent", "Children").Save(category).Error)
+}
+
+// Delete deletes a category from the database
+func (r *CategoryGORMRepository) Delete(id string) error {
+	return db.ClassifyError(r.db.Delete(&domain.Category{}, "id = ?", id).Error)
+}
@@ -0,0 +1,56 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListingCounterGORMRepository implements ListingCounterRepository using GORM
+type ListingCounterGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewListingCounterGORMRepository creates a new listing counter repository
+func NewListingCounterGORMRepository(db *gorm.DB) *ListingCounterGORMRepository {
+	return &ListingCounterGORMRepository{
+		db: db,
+	}
+}
+
+// FindByListingIDs finds the counters of the given listings in one query.
+// Listings without counters are left out.
+func (r *ListingCounterGORMRepository) FindByListingIDs(listingIDs []ids.ListingID) ([]*domain.ListingCounters, error) {
+	var counters []*domain.ListingCounters
+	if len(listingIDs) == 0 {
+		return counters, nil
+	}
+	if err := r.db.Where("listing_id IN ?", listingIDs).Find(&counters).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return counters, nil
+}
+
+// Add adds to a listing's counters in a single upsert, so concurrent counts
+// are never lost
+func (r *ListingCounterGORMRepository) Add(listingID ids.ListingID, views, favorites int64, at time.Time) error {
+	counters := &domain.ListingCounters{
+		ListingID: listingID,
+		Views:     max(views, 0),
+		Favorites: max(favorites, 0),
+		UpdatedAt: at,
+	}
+	return db.ClassifyError(r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "listing_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "views"}, Value: gorm.Expr("listing_counters.views + ?", views)},
+			{Column: clause.Column{Name: "favorites"}, Value: gorm.Expr("GREATEST(listing_counters.favorites + ?, 0)", favorites)},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+		},
+	}).Create(counters).Error)
+}
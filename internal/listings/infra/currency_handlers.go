@@ -0,0 +1,44 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrencyHandler handles HTTP requests for the currencies prices are shown in
+type CurrencyHandler struct {
+	currencyService *app.CurrencyService
+}
+
+// NewCurrencyHandler creates a new currency handler
+func NewCurrencyHandler(currencyService *app.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{
+		currencyService: currencyService,
+	}
+}
+
+// RegisterRoutes registers currency routes
+func (h *CurrencyHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/currencies", h.ListCurrencies)
+}
+
+// ListCurrencies handles listing the supported currencies and their exchange rates
+func (h *CurrencyHandler) ListCurrencies(c *gin.Context) {
+	c.JSON(http.StatusOK, h.currencyService.ListCurrencies(c.Request.Context()))
+}
+
+// showDisplayPrices converts the listings' prices into the currency asked for
+// with ?currency=, or else the caller's preferred currency
+func showDisplayPrices(c *gin.Context, currencyService *app.CurrencyService, listings ...*domain.Listing) {
+	if currencyService == nil {
+		return
+	}
+	ctx := c.Request.Context()
+	currency := currencyService.DisplayCurrency(ctx, auth.UserID(c), c.Query("currency"))
+	currencyService.ConvertListings(ctx, currency, listings...)
+}
@@ -0,0 +1,82 @@
+package infra
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// edgeWarmConcurrency limits how many asset requests are in flight at once
+const edgeWarmConcurrency = 4
+
+// HTTPEdgeWarmer primes CDN edge caches by requesting each asset through the CDN
+type HTTPEdgeWarmer struct {
+	client *http.Client
+}
+
+// NewHTTPEdgeWarmer creates a new edge warmer
+func NewHTTPEdgeWarmer(timeout time.Duration) *HTTPEdgeWarmer {
+	return &HTTPEdgeWarmer{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Warm requests every URL so the serving edge caches it. It returns the number
+// of URLs that were fetched successfully and the failures, if any.
+func (w *HTTPEdgeWarmer) Warm(ctx context.Context, urls []string) (int, error) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		warmed int
+		errs   []error
+	)
+
+	slots := make(chan struct{}, edgeWarmConcurrency)
+	for _, url := range urls {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(url string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			err := w.fetch(ctx, url)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			warmed++
+		}(url)
+	}
+	wg.Wait()
+
+	return warmed, stderrors.Join(errs...)
+}
+
+// fetch downloads a single asset and discards the body
+func (w *HTTPEdgeWarmer) fetch(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("warming %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/money"
+)
+
+// HTTPExchangeRateSource fetches exchange rates from an API answering
+// GET <url>/<base> in the shape of open.er-api.com:
+//
+//	{"result": "success", "base_code": "GHS", "rates": {"USD": 0.066}, "time_last_update_unix": 1700000000}
+type HTTPExchangeRateSource struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewHTTPExchangeRateSource creates a new exchange rate source
+func NewHTTPExchangeRateSource(baseURL string, timeout time.Duration) *HTTPExchangeRateSource {
+	return &HTTPExchangeRateSource{
+		client:  &http.Client{Timeout: timeout},
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+type exchangeRatesResponse struct {
+	Result     string             `json:"result"`
+	BaseCode   string             `json:"base_code"`
+	Rates      map[string]float64 `json:"rates"`
+	LastUpdate int64              `json:"time_last_update_unix"`
+}
+
+// LatestRates fetches the rates against base, keeping only the supported
+// currencies
+func (s *HTTPExchangeRateSource) LatestRates(ctx context.Context, base string) (*domain.ExchangeRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/"+base, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("fetching exchange rates: unexpected status %d", resp.StatusCode)
+	}
+
+	var body exchangeRatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding exchange rates: %w", err)
+	}
+	if body.Result != "success" || body.BaseCode != base {
+		return nil, fmt.Errorf("fetching exchange rates: unexpected response for %s", base)
+	}
+
+	rates := &domain.ExchangeRates{Base: base, Rates: make(map[string]float64)}
+	for _, currency := range money.SupportedCurrencies {
+		if rate, ok := body.Rates[currency]; ok && currency != base {
+			rates.Rates[currency] = rate
+		}
+	}
+	if body.LastUpdate > 0 {
+		rates.FetchedAt = time.Unix(body.LastUpdate, 0).UTC()
+	}
+	return rates, nil
+}
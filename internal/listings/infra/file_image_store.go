@@ -0,0 +1,69 @@
+package infra
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dongome/pkg/storage"
+)
+
+// FileImageStore stores uploaded photos in a local directory that the API
+// serves under baseURL
+type FileImageStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewFileImageStore creates a new file-based image store
+func NewFileImageStore(dir, baseURL string) *FileImageStore {
+	return &FileImageStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Put writes a photo under key and returns the URL it is served from
+func (s *FileImageStore) Put(ctx context.Context, key, contentType string, content io.Reader) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	return s.baseURL + "/" + key, nil
+}
+
+// Open reads the photo stored under key
+func (s *FileImageStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return nil, storage.ErrNotFound
+	}
+	return file, err
+}
+
+// Delete removes the photo stored under key. Photos that are already gone
+// are not an error.
+func (s *FileImageStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListingHandler handles HTTP requests for sellers managing their listings
+type ListingHandler struct {
+	listingService *app.ListingService
+}
+
+// NewListingHandler creates a new listing handler
+func NewListingHandler(listingService *app.ListingService) *ListingHandler {
+	return &ListingHandler{
+		listingService: listingService,
+	}
+}
+
+// RegisterRoutes registers listing management routes. The group must be
+// protected by RequireAuth.
+func (h *ListingHandler) RegisterRoutes(r *gin.RouterGroup) {
+	listings := r.Group("/listings")
+	{
+		listings.POST("", h.CreateListing)
+		listings.POST("/drafts", h.CreateDraft)
+		listings.PUT("/:id", h.UpdateListing)
+		// Drafts autosave with PATCH; fields left out are kept either way
+		listings.PATCH("/:id", h.UpdateListing)
+		listings.POST("/:id/activate", h.ActivateListing)
+		listings.POST("/:id/publish", h.ActivateListing)
+		listings.POST("/:id/deactivate", h.DeactivateListing)
+		listings.POST("/:id/sold", h.MarkListingSold)
+	}
+
+	r.GET("/users/me/listings", h.ListMyListings)
+	r.GET("/users/me/listings/:id", h.GetMyListing)
+}
+
+// CreateListing handles creating a draft listing
+func (h *ListingHandler) CreateListing(c *gin.Context) {
+	var cmd app.CreateListingCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.SellerID = auth.UserID(c)
+
+	listing, err := h.listingService.CreateListing(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, listing)
+}
+
+// CreateDraft handles starting a draft listing from whatever the seller has
+// filled in so far
+func (h *ListingHandler) CreateDraft(c *gin.Context) {
+	var cmd app.CreateDraftCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.SellerID = auth.UserID(c)
+
+	listing, err := h.listingService.CreateDraft(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, listing)
+}
+
+// UpdateListing handles changing one of the caller's listings
+func (h *ListingHandler) UpdateListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.UpdateListingCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.ListingID = listingID
+	cmd.SellerID = auth.UserID(c)
+
+	listing, err := h.listingService.UpdateListing(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// ActivateListing handles putting one of the caller's listings live
+func (h *ListingHandler) ActivateListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	listing, err := h.listingService.ActivateListing(c.Request.Context(), listingID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// DeactivateListing handles taking one of the caller's listings down
+func (h *ListingHandler) DeactivateListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	listing, err := h.listingService.DeactivateListing(c.Request.Context(), listingID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// MarkListingSold handles recording that one of the caller's listings was sold
+func (h *ListingHandler) MarkListingSold(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	listing, err := h.listingService.MarkListingSold(c.Request.Context(), listingID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// ListMyListings handles listing the caller's own listings in every status
+func (h *ListingHandler) ListMyListings(c *gin.Context) {
+	var query app.ListSellerListingsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	query.SellerID = auth.UserID(c)
+
+	listings, err := h.listingService.ListSellerListings(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listings)
+}
+
+// GetMyListing handles retrieving one of the caller's listings, including
+// drafts and listings that are no longer live
+func (h *ListingHandler) GetMyListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	listing, err := h.listingService.GetSellerListing(c.Request.Context(), listingID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
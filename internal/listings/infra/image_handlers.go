@@ -0,0 +1,202 @@
+package infra
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxUploadBodySize bounds a staging upload request: a full batch of photos
+// at the size limit, plus room for the multipart framing
+const maxUploadBodySize = domain.MaxImagesPerUpload*domain.MaxImageSize + 1<<20
+
+// sniffLength is how many bytes are read to detect a photo's content type
+const sniffLength = 512
+
+// ImageHandler handles HTTP requests for photo uploads
+type ImageHandler struct {
+	imageService *app.ImageService
+}
+
+// NewImageHandler creates a new image handler
+func NewImageHandler(imageService *app.ImageService) *ImageHandler {
+	return &ImageHandler{
+		imageService: imageService,
+	}
+}
+
+// RegisterRoutes registers photo upload routes. The group must be protected
+// by RequireAuth.
+func (h *ImageHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/uploads/images", h.StageImages)
+	r.POST("/listings/:id/images/attach", h.AttachImages)
+	r.POST("/listings/:id/images/uploads", h.RequestImageUploads)
+	r.POST("/listings/:id/images/uploads/confirm", h.ConfirmImageUploads)
+}
+
+// StageImages handles uploading photos ahead of the listing they belong to.
+// Photos are sent as multipart form files named "images".
+func (h *ImageHandler) StageImages(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBodySize)
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.Localize(c, "photos must be sent as multipart form files named images")})
+		return
+	}
+
+	var uploads []app.ImageUpload
+	for _, header := range form.File["images"] {
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": i18n.Localize(c, "could not read photo "+header.Filename)})
+			return
+		}
+		defer file.Close()
+
+		contentType, err := detectContentType(file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": i18n.Localize(c, "could not read photo "+header.Filename)})
+			return
+		}
+		uploads = append(uploads, app.ImageUpload{ContentType: contentType, Size: header.Size, Content: file})
+	}
+
+	images, err := h.imageService.StageImages(c.Request.Context(), auth.UserID(c), uploads)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"images": images})
+}
+
+// AttachImages handles attaching staged photos to one of the caller's listings
+func (h *ImageHandler) AttachImages(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.AttachImagesCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.ListingID = listingID
+	cmd.SellerID = auth.UserID(c)
+
+	listing, err := h.imageService.AttachImages(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// RequestImageUploads handles getting presigned requests that upload photos
+// of one of the caller's listings straight to storage
+func (h *ImageHandler) RequestImageUploads(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.RequestImageUploadsCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.ListingID = listingID
+	cmd.SellerID = auth.UserID(c)
+
+	uploads, err := h.imageService.RequestImageUploads(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"uploads": uploads})
+}
+
+// ConfirmImageUploads handles adding photos uploaded straight to storage to
+// one of the caller's listings
+func (h *ImageHandler) ConfirmImageUploads(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.AttachImagesCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.ListingID = listingID
+	cmd.SellerID = auth.UserID(c)
+
+	listing, err := h.imageService.ConfirmImageUploads(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// detectContentType sniffs a photo's content type from its first bytes,
+// rather than trusting the type the client declared
+func detectContentType(file multipart.File) (string, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
@@ -0,0 +1,56 @@
+package infra
+
+import (
+	"context"
+	"image"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/imaging"
+)
+
+// WebPImageProcessor makes the copies of listing photos with libwebp's
+// command line tools, which must be installed on the worker
+type WebPImageProcessor struct {
+	codec *imaging.WebPCodec
+}
+
+// NewWebPImageProcessor creates a processor encoding copies at quality, from 1 to 100
+func NewWebPImageProcessor(quality int) *WebPImageProcessor {
+	return &WebPImageProcessor{codec: imaging.NewWebPCodec(quality)}
+}
+
+// CheckTools reports whether the tools the processor runs are installed
+func (p *WebPImageProcessor) CheckTools() error {
+	return p.codec.CheckTools()
+}
+
+// Process decodes the photo upright and encodes a copy of it scaled to each spec
+func (p *WebPImageProcessor) Process(ctx context.Context, original []byte, specs []domain.ImageVariantSpec) ([]app.ProcessedImage, error) {
+	var img image.Image
+	var err error
+	if imaging.IsWebP(original) {
+		img, err = p.codec.Decode(ctx, original)
+	} else {
+		img, err = imaging.Decode(original)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	processed := make([]app.ProcessedImage, 0, len(specs))
+	for _, spec := range specs {
+		fitted := imaging.Fit(img, spec.MaxEdge)
+		content, err := p.codec.Encode(ctx, fitted)
+		if err != nil {
+			return nil, err
+		}
+		processed = append(processed, app.ProcessedImage{
+			Name:    spec.Name,
+			Width:   fitted.Bounds().Dx(),
+			Height:  fitted.Bounds().Dy(),
+			Content: content,
+		})
+	}
+	return processed, nil
+}
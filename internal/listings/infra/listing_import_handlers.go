@@ -0,0 +1,139 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBodySize bounds an import upload: the file at the size limit,
+// plus room for the multipart framing
+const maxImportBodySize = domain.MaxImportFileSize + 1<<20
+
+// ListingImportHandler handles HTTP requests for sellers importing listings
+// from a file
+type ListingImportHandler struct {
+	importService *app.ListingImportService
+}
+
+// NewListingImportHandler creates a new listing import handler
+func NewListingImportHandler(importService *app.ListingImportService) *ListingImportHandler {
+	return &ListingImportHandler{
+		importService: importService,
+	}
+}
+
+// RegisterRoutes registers listing import routes. The group must be protected
+// by RequireAuth.
+func (h *ListingImportHandler) RegisterRoutes(r *gin.RouterGroup) {
+	imports := r.Group("/listings/imports")
+	{
+		imports.POST("", h.SubmitImport)
+		imports.GET("", h.ListImports)
+		imports.GET("/:id", h.GetImport)
+		imports.GET("/:id/rows", h.ListRows)
+	}
+}
+
+// SubmitImport handles uploading a CSV file of listings, sent as a multipart
+// form file named "file". The rows are imported by the worker, so the
+// response only holds the import's ID and status.
+func (h *ListingImportHandler) SubmitImport(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodySize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.Localize(c, "listings must be sent as a multipart form file named file")})
+		return
+	}
+	if header.Size > domain.MaxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": i18n.Localize(c, "import file must be at most 5 MB")})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.Localize(c, "could not read the import file")})
+		return
+	}
+	defer file.Close()
+
+	listingImport, err := h.importService.SubmitImport(c.Request.Context(), app.SubmitListingImportCommand{
+		SellerID: auth.UserID(c),
+		FileName: header.Filename,
+		Content:  file,
+	})
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, listingImport)
+}
+
+// ListImports handles listing the caller's imports, newest first
+func (h *ListingImportHandler) ListImports(c *gin.Context) {
+	var query app.ListListingImportsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	query.SellerID = auth.UserID(c)
+
+	imports, err := h.importService.ListImports(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, imports)
+}
+
+// GetImport handles retrieving one of the caller's imports with its progress
+func (h *ListingImportHandler) GetImport(c *gin.Context) {
+	listingImport, err := h.importService.GetImport(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listingImport)
+}
+
+// ListRows handles listing the per-row results of one of the caller's imports
+func (h *ListingImportHandler) ListRows(c *gin.Context) {
+	var query app.ListImportRowsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	rows, err := h.importService.ListRows(c.Request.Context(), c.Param("id"), auth.UserID(c), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, rows)
+}
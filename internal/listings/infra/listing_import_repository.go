@@ -0,0 +1,150 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// importRowInsertBatch is how many import rows are inserted per statement
+const importRowInsertBatch = 500
+
+// ListingImportGORMRepository implements ListingImportRepository using GORM
+type ListingImportGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewListingImportGORMRepository creates a new listing import repository
+func NewListingImportGORMRepository(db *gorm.DB) *ListingImportGORMRepository {
+	return &ListingImportGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a new import and its rows in one transaction, so the worker
+// never sees an import without its rows
+func (r *ListingImportGORMRepository) Save(listingImport *domain.ListingImport, rows []*domain.ListingImportRow) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(listingImport).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(rows, importRowInsertBatch).Error
+	}))
+}
+
+// Update stores an import's status and counts
+func (r *ListingImportGORMRepository) Update(listingImport *domain.ListingImport) error {
+	return db.ClassifyError(r.db.Save(listingImport).Error)
+}
+
+// FindByID finds an import by ID
+func (r *ListingImportGORMRepository) FindByID(id string) (*domain.ListingImport, error) {
+	var listingImport domain.ListingImport
+	if err := r.db.Where("id = ?", id).First(&listingImport).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("import not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &listingImport, nil
+}
+
+// FindBySeller finds a seller's imports, newest first, with the total count
+func (r *ListingImportGORMRepository) FindBySeller(sellerID ids.UserID, limit, offset int) ([]*domain.ListingImport, int64, error) {
+	query := r.db.Model(&domain.ListingImport{}).Where("seller_id = ?", sellerID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var imports []*domain.ListingImport
+	err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&imports).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return imports, total, nil
+}
+
+// FindUnfinished finds imports with rows still to be processed, oldest first
+func (r *ListingImportGORMRepository) FindUnfinished(limit int) ([]*domain.ListingImport, error) {
+	var imports []*domain.ListingImport
+	err := r.db.Where("status = ?", domain.ListingImportPending).
+		Order("created_at").
+		Limit(limit).
+		Find(&imports).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return imports, nil
+}
+
+// FindPendingRows finds rows of an import still to be processed, in file order
+func (r *ListingImportGORMRepository) FindPendingRows(importID string, limit int) ([]*domain.ListingImportRow, error) {
+	var rows []*domain.ListingImportRow
+	err := r.db.Where("import_id = ? AND status = ?", importID, domain.ImportRowPending).
+		Order("line").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return rows, nil
+}
+
+// FindRows finds the rows of an import in file order, optionally in one
+// status, with the total count
+func (r *ListingImportGORMRepository) FindRows(importID string, status domain.ImportRowStatus, limit, offset int) ([]*domain.ListingImportRow, int64, error) {
+	query := r.db.Model(&domain.ListingImportRow{}).Where("import_id = ?", importID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var rows []*domain.ListingImportRow
+	err := query.Order("line").
+		Limit(limit).
+		Offset(offset).
+		Find(&rows).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return rows, total, nil
+}
+
+// CountRows counts the rows of an import by status
+func (r *ListingImportGORMRepository) CountRows(importID string) (map[domain.ImportRowStatus]int, error) {
+	var counts []struct {
+		Status domain.ImportRowStatus
+		Count  int
+	}
+	err := r.db.Model(&domain.ListingImportRow{}).
+		Select("status, COUNT(*) AS count").
+		Where("import_id = ?", importID).
+		Group("status").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+
+	byStatus := make(map[domain.ImportRowStatus]int, len(counts))
+	for _, count := range counts {
+		byStatus[count.Status] = count.Count
+	}
+	return byStatus, nil
+}
+
+// UpdateRow stores the result of importing one row
+func (r *ListingImportGORMRepository) UpdateRow(row *domain.ListingImportRow) error {
+	return db.ClassifyError(r.db.Save(row).Error)
+}
@@ -0,0 +1,103 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recordedStatsEvent marks an event as counted in the listing stats
+type recordedStatsEvent struct {
+	EventID    string `gorm:"primary_key"`
+	RecordedAt time.Time
+}
+
+// TableName returns the table of events counted in the listing stats
+func (recordedStatsEvent) TableName() string {
+	return "listing_stats_events"
+}
+
+// ListingStatsGORMRepository implements ListingStatsRepository using GORM
+type ListingStatsGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewListingStatsGORMRepository creates a new listing stats repository
+func NewListingStatsGORMRepository(db *gorm.DB) *ListingStatsGORMRepository {
+	return &ListingStatsGORMRepository{
+		db: db,
+	}
+}
+
+// Record adds activity to a listing's stats for the day in a single upsert,
+// in the same transaction as marking the event counted
+func (r *ListingStatsGORMRepository) Record(eventID string, listingID ids.ListingID, sellerID ids.UserID, day time.Time, activity domain.ListingActivity, at time.Time) (bool, error) {
+	recorded := true
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if eventID != "" {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&recordedStatsEvent{EventID: eventID, RecordedAt: at})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				recorded = false
+				return nil
+			}
+		}
+
+		stats := &domain.ListingDailyStats{
+			ListingID:       listingID,
+			Day:             day,
+			SellerID:        sellerID,
+			ListingActivity: activity,
+			UpdatedAt:       at,
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "listing_id"}, {Name: "day"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "views"}, Value: gorm.Expr("listing_daily_stats.views + ?", activity.Views)},
+				{Column: clause.Column{Name: "favorites"}, Value: gorm.Expr("listing_daily_stats.favorites + ?", activity.Favorites)},
+				{Column: clause.Column{Name: "messages"}, Value: gorm.Expr("listing_daily_stats.messages + ?", activity.Messages)},
+				{Column: clause.Column{Name: "orders"}, Value: gorm.Expr("listing_daily_stats.orders + ?", activity.Orders)},
+				{Column: clause.Column{Name: "seller_id"}, Value: gorm.Expr("excluded.seller_id")},
+				{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+			},
+		}).Create(stats).Error
+	})
+	if err != nil {
+		return false, db.ClassifyError(err)
+	}
+	return recorded, nil
+}
+
+// FindBySeller finds the daily stats of a seller's listings from one day to
+// another, both included, by listing and day
+func (r *ListingStatsGORMRepository) FindBySeller(sellerID ids.UserID, from, to time.Time) ([]*domain.ListingDailyStats, error) {
+	var stats []*domain.ListingDailyStats
+	err := r.db.Where("seller_id = ? AND day BETWEEN ? AND ?", sellerID, from, to).
+		Order("listing_id, day").
+		Find(&stats).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return stats, nil
+}
+
+// FindActiveSince finds the daily stats of active listings from a day on
+func (r *ListingStatsGORMRepository) FindActiveSince(from time.Time) ([]*domain.ListingDailyStats, error) {
+	var stats []*domain.ListingDailyStats
+	err := r.db.Select("listing_daily_stats.*").
+		Joins("JOIN listings ON listings.id = listing_daily_stats.listing_id").
+		Where("listing_daily_stats.day >= ?", from).
+		Where("listings.status = ? AND listings.expires_at > ?", domain.ListingStatusActive, time.Now()).
+		Find(&stats).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return stats, nil
+}
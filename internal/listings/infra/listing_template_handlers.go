@@ -0,0 +1,157 @@
+package infra
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListingTemplateHandler handles HTTP requests for a seller's listing
+// templates and the listings started from them
+type ListingTemplateHandler struct {
+	templateService *app.ListingTemplateService
+}
+
+// NewListingTemplateHandler creates a new listing template handler
+func NewListingTemplateHandler(templateService *app.ListingTemplateService) *ListingTemplateHandler {
+	return &ListingTemplateHandler{
+		templateService: templateService,
+	}
+}
+
+// RegisterRoutes registers listing template routes. The group must be
+// protected by RequireAuth.
+func (h *ListingTemplateHandler) RegisterRoutes(r *gin.RouterGroup) {
+	templates := r.Group("/listing-templates")
+	{
+		templates.POST("", h.CreateTemplate)
+		templates.GET("", h.ListTemplates)
+		templates.GET("/:id", h.GetTemplate)
+		templates.PUT("/:id", h.UpdateTemplate)
+		templates.DELETE("/:id", h.DeleteTemplate)
+	}
+
+	r.POST("/listings/from-template/:templateId", h.CreateListingFromTemplate)
+}
+
+// CreateTemplate handles saving a listing template for the caller
+func (h *ListingTemplateHandler) CreateTemplate(c *gin.Context) {
+	var cmd app.SaveListingTemplateCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.SellerID = auth.UserID(c)
+
+	template, err := h.templateService.CreateTemplate(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// ListTemplates handles listing the caller's listing templates, newest first
+func (h *ListingTemplateHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.templateService.ListTemplates(c.Request.Context(), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"listing_templates": templates})
+}
+
+// GetTemplate handles retrieving one of the caller's listing templates
+func (h *ListingTemplateHandler) GetTemplate(c *gin.Context) {
+	template, err := h.templateService.GetTemplate(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// UpdateTemplate handles replacing what one of the caller's listing
+// templates presets
+func (h *ListingTemplateHandler) UpdateTemplate(c *gin.Context) {
+	var cmd app.SaveListingTemplateCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.SellerID = auth.UserID(c)
+
+	template, err := h.templateService.UpdateTemplate(c.Request.Context(), c.Param("id"), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate handles deleting one of the caller's listing templates
+func (h *ListingTemplateHandler) DeleteTemplate(c *gin.Context) {
+	err := h.templateService.DeleteTemplate(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "listing template deleted"})
+}
+
+// CreateListingFromTemplate handles starting a draft listing from one of the
+// caller's templates. The body is optional: without one the draft has just
+// what the template presets.
+func (h *ListingTemplateHandler) CreateListingFromTemplate(c *gin.Context) {
+	var cmd app.CreateFromTemplateCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil && !stderrors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.TemplateID = c.Param("templateId")
+	cmd.SellerID = auth.UserID(c)
+
+	listing, err := h.templateService.CreateListingFromTemplate(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, listing)
+}
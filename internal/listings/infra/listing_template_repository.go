@@ -0,0 +1,67 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// ListingTemplateGORMRepository implements ListingTemplateRepository using GORM
+type ListingTemplateGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewListingTemplateGORMRepository creates a new listing template repository
+func NewListingTemplateGORMRepository(db *gorm.DB) *ListingTemplateGORMRepository {
+	return &ListingTemplateGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a listing template to the database
+func (r *ListingTemplateGORMRepository) Save(template *domain.ListingTemplate) error {
+	return db.ClassifyError(r.db.Create(template).Error)
+}
+
+// Update updates a listing template in the database
+func (r *ListingTemplateGORMRepository) Update(template *domain.ListingTemplate) error {
+	return db.ClassifyError(r.db.Save(template).Error)
+}
+
+// Delete deletes a listing template from the database
+func (r *ListingTemplateGORMRepository) Delete(id string) error {
+	return db.ClassifyError(r.db.Where("id = ?", id).Delete(&domain.ListingTemplate{}).Error)
+}
+
+// FindByID finds a listing template by ID
+func (r *ListingTemplateGORMRepository) FindByID(id string) (*domain.ListingTemplate, error) {
+	var template domain.ListingTemplate
+	if err := r.db.Where("id = ?", id).First(&template).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("listing template not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &template, nil
+}
+
+// FindBySeller finds a seller's listing templates, newest first
+func (r *ListingTemplateGORMRepository) FindBySeller(sellerID ids.UserID) ([]*domain.ListingTemplate, error) {
+	var templates []*domain.ListingTemplate
+	if err := r.db.Where("seller_id = ?", sellerID).Order("created_at DESC").Find(&templates).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return templates, nil
+}
+
+// CountBySeller counts a seller's listing templates
+func (r *ListingTemplateGORMRepository) CountBySeller(sellerID ids.UserID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.ListingTemplate{}).Where("seller_id = ?", sellerID).Count(&count).Error; err != nil {
+		return 0, db.ClassifyError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,62 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocationHandler handles HTTP requests for browsing locations
+type LocationHandler struct {
+	locationService *app.LocationService
+}
+
+// NewLocationHandler creates a new location handler
+func NewLocationHandler(locationService *app.LocationService) *LocationHandler {
+	return &LocationHandler{
+		locationService: locationService,
+	}
+}
+
+// RegisterRoutes registers location routes
+func (h *LocationHandler) RegisterRoutes(r *gin.RouterGroup) {
+	locations := r.Group("/locations")
+	{
+		locations.GET("/regions", h.ListRegions)
+		locations.GET("/:region/cities", h.ListCities)
+	}
+}
+
+// ListRegions handles listing regions with listing counts
+func (h *LocationHandler) ListRegions(c *gin.Context) {
+	regions, err := h.locationService.ListRegions(c.Request.Context())
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"regions": regions})
+}
+
+// ListCities handles listing the cities of a region with listing counts
+func (h *LocationHandler) ListCities(c *gin.Context) {
+	cities, err := h.locationService.ListCities(c.Request.Context(), c.Param("region"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"region": c.Param("region"), "cities": cities})
+}
@@ -0,0 +1,58 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// LocationGORMRepository implements LocationRepository using GORM
+type LocationGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewLocationGORMRepository creates a new location repository
+func NewLocationGORMRepository(db *gorm.DB) *LocationGORMRepository {
+	return &LocationGORMRepository{
+		db: db,
+	}
+}
+
+// FindRegions finds all regions ordered by name, without their cities
+func (r *LocationGORMRepository) FindRegions() ([]*domain.Region, error) {
+	var regions []*domain.Region
+	if err := r.db.Order("name ASC").Find(&regions).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return regions, nil
+}
+
+// FindRegion finds a region by slug with its cities and areas
+func (r *LocationGORMRepository) FindRegion(slug string) (*domain.Region, error) {
+	var region domain.Region
+	err := r.db.
+		Preload("Cities", func(q *gorm.DB) *gorm.DB { return q.Order("cities.name ASC") }).
+		Preload("Cities.Areas", func(q *gorm.DB) *gorm.DB { return q.Order("areas.name ASC") }).
+		First(&region, "slug = ?", slug).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("region not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &region, nil
+}
+
+// SaveTree saves regions with their cities and areas in a single transaction
+func (r *LocationGORMRepository) SaveTree(regions []*domain.Region) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		for _, region := range regions {
+			if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(region).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
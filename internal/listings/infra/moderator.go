@@ -0,0 +1,38 @@
+package infra
+
+import (
+	"context"
+	"regexp"
+
+	"dongome/internal/listings/app"
+)
+
+// Patterns of contact details that would take a sale off the platform
+var (
+	phonePattern = regexp.MustCompile(`(\+?233|\b0)[\s-]?\d{2}[\s-]?\d{3}[\s-]?\d{4}\b`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	linkPattern  = regexp.MustCompile(`(?i)\b(https?://|www\.|wa\.me/)`)
+)
+
+// ContactDetailsModerator flags public questions and answers that share
+// phone numbers, email addresses or links, so buyers and sellers keep their
+// conversations in the marketplace's chat
+type ContactDetailsModerator struct{}
+
+// NewContactDetailsModerator creates a new contact details moderator
+func NewContactDetailsModerator() *ContactDetailsModerator {
+	return &ContactDetailsModerator{}
+}
+
+// Review flags text that contains contact details
+func (m *ContactDetailsModerator) Review(ctx context.Context, text string) (app.ModerationResult, error) {
+	switch {
+	case phonePattern.MatchString(text):
+		return app.ModerationResult{Flagged: true, Reason: "contains a phone number"}, nil
+	case emailPattern.MatchString(text):
+		return app.ModerationResult{Flagged: true, Reason: "contains an email address"}, nil
+	case linkPattern.MatchString(text):
+		return app.ModerationResult{Flagged: true, Reason: "contains a link"}, nil
+	}
+	return app.ModerationResult{}, nil
+}
@@ -0,0 +1,45 @@
+package infra
+
+import (
+	"context"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/logger"
+	"dongome/pkg/momo"
+
+	"go.uber.org/zap"
+)
+
+// MoMoPaymentGateway implements PaymentGateway with MTN MoMo, whose results
+// are sent to the callback URL
+type MoMoPaymentGateway struct {
+	client      *momo.Client
+	callbackURL string
+}
+
+// NewMoMoPaymentGateway creates a payment gateway reporting results to callbackURL
+func NewMoMoPaymentGateway(client *momo.Client, callbackURL string) *MoMoPaymentGateway {
+	return &MoMoPaymentGateway{
+		client:      client,
+		callbackURL: callbackURL,
+	}
+}
+
+// RequestPayment asks the payer to approve the payment on their phone.
+// Failures are reported as payments being unavailable.
+func (g *MoMoPaymentGateway) RequestPayment(ctx context.Context, payment app.PaymentRequest) (string, error) {
+	reference, err := g.client.RequestToPay(ctx, momo.PaymentRequest{
+		ExternalID:   payment.Reference,
+		Amount:       payment.Amount,
+		Payer:        payment.Payer,
+		PayerMessage: payment.Description,
+		PayeeNote:    payment.Description,
+		CallbackURL:  g.callbackURL,
+	})
+	if err != nil {
+		logger.Warn("Failed to request MoMo payment", zap.Error(err), logger.TraceID(ctx))
+		return "", errors.UnavailableError("mobile money payments are temporarily unavailable")
+	}
+	return reference, nil
+}
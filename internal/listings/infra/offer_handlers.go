@@ -0,0 +1,176 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OfferHandler handles HTTP requests for offers on negotiable listings
+type OfferHandler struct {
+	offerService *app.OfferService
+}
+
+// NewOfferHandler creates a new offer handler
+func NewOfferHandler(offerService *app.OfferService) *OfferHandler {
+	return &OfferHandler{
+		offerService: offerService,
+	}
+}
+
+// RegisterRoutes registers offer routes. The group must be protected by
+// RequireAuth.
+func (h *OfferHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/listings/:id/offers", h.MakeOffer)
+	r.GET("/listings/:id/offers", h.ListOffers)
+
+	offers := r.Group("/offers")
+	{
+		offers.GET("/:id", h.GetOffer)
+		offers.POST("/:id/accept", h.AcceptOffer)
+		offers.POST("/:id/counter", h.CounterOffer)
+		offers.POST("/:id/decline", h.DeclineOffer)
+	}
+}
+
+// MakeOffer handles a buyer making an offer on a listing
+func (h *OfferHandler) MakeOffer(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.MakeOfferCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.ListingID = listingID
+	cmd.BuyerID = auth.UserID(c)
+
+	offer, err := h.offerService.MakeOffer(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, offer)
+}
+
+// ListOffers handles listing the offers on a listing: all of them for its
+// seller, the user's own for anyone else
+func (h *OfferHandler) ListOffers(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var query app.ListOffersQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	offers, err := h.offerService.ListOffers(c.Request.Context(), listingID, auth.UserID(c), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+// GetOffer handles retrieving an offer the user is the buyer or seller of
+func (h *OfferHandler) GetOffer(c *gin.Context) {
+	offer, err := h.offerService.GetOffer(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// AcceptOffer handles accepting the other party's last amount
+func (h *OfferHandler) AcceptOffer(c *gin.Context) {
+	offer, err := h.offerService.AcceptOffer(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// CounterOffer handles countering the other party's last amount
+func (h *OfferHandler) CounterOffer(c *gin.Context) {
+	var cmd app.CounterOfferCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.OfferID = c.Param("id")
+	cmd.UserID = auth.UserID(c)
+
+	offer, err := h.offerService.CounterOffer(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// DeclineOffer handles turning down the other party's last amount
+func (h *OfferHandler) DeclineOffer(c *gin.Context) {
+	offer, err := h.offerService.DeclineOffer(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
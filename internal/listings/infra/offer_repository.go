@@ -0,0 +1,94 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// openOfferStatuses are the statuses of offers still being negotiated
+var openOfferStatuses = []domain.OfferStatus{domain.OfferStatusPending, domain.OfferStatusCountered}
+
+// OfferGORMRepository implements OfferRepository using GORM
+type OfferGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewOfferGORMRepository creates a new offer repository
+func NewOfferGORMRepository(db *gorm.DB) *OfferGORMRepository {
+	return &OfferGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves an offer to the database
+func (r *OfferGORMRepository) Save(offer *domain.Offer) error {
+	return db.ClassifyError(r.db.Create(offer).Error)
+}
+
+// FindByID finds an offer by ID
+func (r *OfferGORMRepository) FindByID(id string) (*domain.Offer, error) {
+	var offer domain.Offer
+	err := r.db.First(&offer, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("offer not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &offer, nil
+}
+
+// FindOpen finds the buyer's open offers on a listing that have not lapsed by now
+func (r *OfferGORMRepository) FindOpen(listingID ids.ListingID, buyerID ids.UserID, now time.Time) ([]*domain.Offer, error) {
+	var offers []*domain.Offer
+	err := r.db.Where("listing_id = ? AND buyer_id = ? AND status IN ? AND expires_at > ?", listingID, buyerID, openOfferStatuses, now).
+		Find(&offers).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return offers, nil
+}
+
+// FindByListing finds the offers on a listing, only the buyer's when buyerID
+// is set, newest first
+func (r *OfferGORMRepository) FindByListing(listingID ids.ListingID, buyerID ids.UserID, limit, offset int) ([]*domain.Offer, int64, error) {
+	q := r.db.Model(&domain.Offer{}).Where("listing_id = ?", listingID)
+	if !buyerID.IsZero() {
+		q = q.Where("buyer_id = ?", buyerID)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var offers []*domain.Offer
+	if err := q.Order("created_at DESC").Limit(limit).Offset(offset).Find(&offers).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return offers, total, nil
+}
+
+// FindExpiredOpen finds open offers that lapsed by now, oldest first
+func (r *OfferGORMRepository) FindExpiredOpen(now time.Time, limit int) ([]*domain.Offer, error) {
+	var offers []*domain.Offer
+	err := r.db.Where("status IN ? AND expires_at <= ?", openOfferStatuses, now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&offers).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return offers, nil
+}
+
+// Update updates an offer in the database
+func (r *OfferGORMRepository) Update(offer *domain.Offer) error {
+	return db.ClassifyError(r.db.Save(offer).Error)
+}
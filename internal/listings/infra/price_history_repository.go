@@ -0,0 +1,39 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// PriceHistoryGORMRepository implements PriceHistoryRepository using GORM
+type PriceHistoryGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceHistoryGORMRepository creates a new price history repository
+func NewPriceHistoryGORMRepository(db *gorm.DB) *PriceHistoryGORMRepository {
+	return &PriceHistoryGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a price change to the database
+func (r *PriceHistoryGORMRepository) Save(change *domain.PriceChange) error {
+	return db.ClassifyError(r.db.Create(change).Error)
+}
+
+// FindByListing finds a listing's price changes, most recent first
+func (r *PriceHistoryGORMRepository) FindByListing(listingID ids.ListingID, limit int) ([]*domain.PriceChange, error) {
+	var changes []*domain.PriceChange
+	err := r.db.Where("listing_id = ?", listingID).
+		Order("changed_at DESC").
+		Limit(limit).
+		Find(&changes).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,100 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+	"dongome/pkg/webhookauth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionHandler handles HTTP requests for paid listing promotions
+type PromotionHandler struct {
+	promotionService *app.PromotionService
+}
+
+// NewPromotionHandler creates a new promotion handler
+func NewPromotionHandler(promotionService *app.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+// RegisterRoutes registers promotion routes. The group must be protected by
+// RequireAuth.
+func (h *PromotionHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/listings/promotion-packages", h.ListPackages)
+	r.POST("/listings/:id/promote", h.PromoteListing)
+}
+
+// RegisterCallbackRoutes registers the promotion payment callback. It is not
+// behind RequireAuth; each request is verified by its signature instead.
+func (h *PromotionHandler) RegisterCallbackRoutes(r *gin.RouterGroup, verifier *webhookauth.Verifier, momo webhookauth.Provider) {
+	r.POST("/payments/momo/promotions/callback", verifier.Middleware(momo), h.MoMoCallback)
+}
+
+// ListPackages handles listing the promotion packages on sale
+func (h *PromotionHandler) ListPackages(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"packages": h.promotionService.ListPackages(c.Request.Context())})
+}
+
+// PromoteListing handles buying a promotion package for one of the caller's
+// listings. The payment is approved on the caller's phone, so the listing is
+// promoted later.
+func (h *PromotionHandler) PromoteListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.PromoteListingCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.ListingID = listingID
+	cmd.SellerID = auth.UserID(c)
+
+	promotion, err := h.promotionService.PromoteListing(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, promotion)
+}
+
+// MoMoCallback handles the Mobile Money result of a promotion payment
+func (h *PromotionHandler) MoMoCallback(c *gin.Context) {
+	var cmd app.PromotionPaymentCallbackCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	promotion, err := h.promotionService.HandlePaymentCallback(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promotion_id": promotion.ID, "status": promotion.Status})
+}
@@ -0,0 +1,44 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// ListingPromotionGORMRepository implements ListingPromotionRepository using GORM
+type ListingPromotionGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewListingPromotionGORMRepository creates a new listing promotion repository
+func NewListingPromotionGORMRepository(db *gorm.DB) *ListingPromotionGORMRepository {
+	return &ListingPromotionGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a listing promotion to the database
+func (r *ListingPromotionGORMRepository) Save(promotion *domain.ListingPromotion) error {
+	return db.ClassifyError(r.db.Create(promotion).Error)
+}
+
+// FindByID finds a listing promotion by ID
+func (r *ListingPromotionGORMRepository) FindByID(id string) (*domain.ListingPromotion, error) {
+	var promotion domain.ListingPromotion
+	err := r.db.First(&promotion, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("promotion not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &promotion, nil
+}
+
+// Update updates a listing promotion in the database
+func (r *ListingPromotionGORMRepository) Update(promotion *domain.ListingPromotion) error {
+	return db.ClassifyError(r.db.Save(promotion).Error)
+}
@@ -0,0 +1,57 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PublicationHandler handles HTTP requests for checking listings before they go live
+type PublicationHandler struct {
+	publicationService *app.PublicationService
+}
+
+// NewPublicationHandler creates a new publication handler
+func NewPublicationHandler(publicationService *app.PublicationService) *PublicationHandler {
+	return &PublicationHandler{
+		publicationService: publicationService,
+	}
+}
+
+// RegisterRoutes registers listing publication routes. The group must be
+// protected by RequireAuth.
+func (h *PublicationHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/listings/:id/validate", h.ValidateListing)
+}
+
+// ValidateListing handles a dry run of the publication rules against one of
+// the caller's listings
+func (h *PublicationHandler) ValidateListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	report, err := h.publicationService.ValidateListing(c.Request.Context(), listingID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
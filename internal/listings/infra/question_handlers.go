@@ -0,0 +1,209 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuestionHandler handles HTTP requests for public listing questions and answers
+type QuestionHandler struct {
+	questionService *app.QuestionService
+}
+
+// NewQuestionHandler creates a new question handler
+func NewQuestionHandler(questionService *app.QuestionService) *QuestionHandler {
+	return &QuestionHandler{
+		questionService: questionService,
+	}
+}
+
+// RegisterRoutes registers the public question routes
+func (h *QuestionHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/listings/:id/questions", h.ListQuestions)
+}
+
+// RegisterAuthenticatedRoutes registers the routes to ask and answer
+// questions. The group must be protected by RequireAuth.
+func (h *QuestionHandler) RegisterAuthenticatedRoutes(r *gin.RouterGroup) {
+	r.POST("/listings/:id/questions", h.AskQuestion)
+	r.PUT("/listing-questions/:id/answer", h.AnswerQuestion)
+}
+
+// ListQuestions handles listing the published questions of a listing
+func (h *QuestionHandler) ListQuestions(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var query app.ListQuestionsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	questions, err := h.questionService.ListQuestions(c.Request.Context(), listingID, query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+// AskQuestion handles asking a public question about a listing
+func (h *QuestionHandler) AskQuestion(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.AskQuestionCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.ListingID = listingID
+	cmd.AskerID = auth.UserID(c)
+
+	question, err := h.questionService.AskQuestion(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, question)
+}
+
+// AnswerQuestion handles a seller answering a question about their listing
+func (h *QuestionHandler) AnswerQuestion(c *gin.Context) {
+	var cmd app.AnswerQuestionCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.QuestionID = c.Param("id")
+	cmd.SellerID = auth.UserID(c)
+
+	question, err := h.questionService.AnswerQuestion(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// AdminQuestionHandler handles HTTP requests for moderating listing questions
+type AdminQuestionHandler struct {
+	questionService *app.QuestionService
+}
+
+// NewAdminQuestionHandler creates a new admin question handler
+func NewAdminQuestionHandler(questionService *app.QuestionService) *AdminQuestionHandler {
+	return &AdminQuestionHandler{
+		questionService: questionService,
+	}
+}
+
+// RegisterRoutes registers question moderation routes. The group must be
+// protected by the admin role.
+func (h *AdminQuestionHandler) RegisterRoutes(r *gin.RouterGroup) {
+	questions := r.Group("/listing-questions")
+	{
+		questions.GET("", h.ModerationQueue)
+		questions.POST("/:id/publish", h.PublishQuestion)
+		questions.POST("/:id/hide", h.HideQuestion)
+	}
+}
+
+// ModerationQueue handles listing questions by moderation status
+func (h *AdminQuestionHandler) ModerationQueue(c *gin.Context) {
+	var query app.ModerationQueueQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	questions, err := h.questionService.ModerationQueue(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+// PublishQuestion handles approving a held or hidden question
+func (h *AdminQuestionHandler) PublishQuestion(c *gin.Context) {
+	question, err := h.questionService.PublishQuestion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// HideQuestion handles removing a question from public view
+func (h *AdminQuestionHandler) HideQuestion(c *gin.Context) {
+	var cmd app.ModerateQuestionCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.QuestionID = c.Param("id")
+
+	question, err := h.questionService.HideQuestion(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
@@ -0,0 +1,82 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// ListingQuestionGORMRepository implements ListingQuestionRepository using GORM
+type ListingQuestionGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewListingQuestionGORMRepository creates a new listing question repository
+func NewListingQuestionGORMRepository(db *gorm.DB) *ListingQuestionGORMRepository {
+	return &ListingQuestionGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a question to the database
+func (r *ListingQuestionGORMRepository) Save(question *domain.ListingQuestion) error {
+	return db.ClassifyError(r.db.Create(question).Error)
+}
+
+// FindByID finds a question by ID
+func (r *ListingQuestionGORMRepository) FindByID(id string) (*domain.ListingQuestion, error) {
+	var question domain.ListingQuestion
+	err := r.db.First(&question, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("question not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &question, nil
+}
+
+// FindByListing finds a listing's questions with the given status, newest first
+func (r *ListingQuestionGORMRepository) FindByListing(listingID ids.ListingID, status domain.QuestionStatus, limit, offset int) ([]*domain.ListingQuestion, int64, error) {
+	return r.page(r.db.Where("listing_id = ? AND status = ?", listingID, status), "created_at DESC", limit, offset)
+}
+
+// FindTopAnswered finds a listing's published, answered questions, most recently answered first
+func (r *ListingQuestionGORMRepository) FindTopAnswered(listingID ids.ListingID, limit int) ([]*domain.ListingQuestion, error) {
+	var questions []*domain.ListingQuestion
+	err := r.db.Where("listing_id = ? AND status = ? AND answer IS NOT NULL", listingID, domain.QuestionStatusPublished).
+		Order("answered_at DESC").
+		Limit(limit).
+		Find(&questions).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return questions, nil
+}
+
+// FindByStatus finds questions across listings with the given status, oldest first
+func (r *ListingQuestionGORMRepository) FindByStatus(status domain.QuestionStatus, limit, offset int) ([]*domain.ListingQuestion, int64, error) {
+	return r.page(r.db.Where("status = ?", status), "updated_at", limit, offset)
+}
+
+// Update updates a question in the database
+func (r *ListingQuestionGORMRepository) Update(question *domain.ListingQuestion) error {
+	return db.ClassifyError(r.db.Save(question).Error)
+}
+
+// page counts the questions matched by q and loads one page of them
+func (r *ListingQuestionGORMRepository) page(q *gorm.DB, order string, limit, offset int) ([]*domain.ListingQuestion, int64, error) {
+	var total int64
+	if err := q.Model(&domain.ListingQuestion{}).Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var questions []*domain.ListingQuestion
+	if err := q.Order(order).Limit(limit).Offset(offset).Find(&questions).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return questions, total, nil
+}
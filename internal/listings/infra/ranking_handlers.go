@@ -0,0 +1,116 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rankingBatchSize is how many sellers a recalculation requested by an
+// administrator processes at a time
+const rankingBatchSize = 200
+
+// AdminRankingHandler handles HTTP requests for search ranking administration
+type AdminRankingHandler struct {
+	rankingService *app.RankingService
+}
+
+// NewAdminRankingHandler creates a new admin ranking handler
+func NewAdminRankingHandler(rankingService *app.RankingService) *AdminRankingHandler {
+	return &AdminRankingHandler{
+		rankingService: rankingService,
+	}
+}
+
+// RegisterRoutes registers admin ranking routes. The group must be protected
+// by the admin role.
+func (h *AdminRankingHandler) RegisterRoutes(r *gin.RouterGroup) {
+	ranking := r.Group("/ranking")
+	{
+		ranking.GET("/policy", h.GetPolicy)
+		ranking.PUT("/policy", h.UpdatePolicy)
+		ranking.GET("/penalties", h.ListPenalizedSellers)
+		ranking.POST("/recalculate", h.Recalculate)
+	}
+}
+
+// GetPolicy handles getting the ranking penalties
+func (h *AdminRankingHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.rankingService.GetPolicy(c.Request.Context())
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdatePolicy handles changing the ranking penalties
+func (h *AdminRankingHandler) UpdatePolicy(c *gin.Context) {
+	var cmd app.UpdateRankingPolicyCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UpdatedBy = auth.UserID(c)
+
+	policy, err := h.rankingService.UpdatePolicy(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// ListPenalizedSellers handles listing the sellers whose listings are demoted
+func (h *AdminRankingHandler) ListPenalizedSellers(c *gin.Context) {
+	var query app.ListPenalizedSellersQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	sellers, err := h.rankingService.ListPenalizedSellers(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, sellers)
+}
+
+// Recalculate handles applying the ranking policy without waiting for the
+// scheduled job
+func (h *AdminRankingHandler) Recalculate(c *gin.Context) {
+	summary, err := h.rankingService.RecalculatePenalties(c.Request.Context(), rankingBatchSize)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,38 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// RankingPolicyGORMRepository implements RankingPolicyRepository using GORM
+type RankingPolicyGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewRankingPolicyGORMRepository creates a new ranking policy repository
+func NewRankingPolicyGORMRepository(db *gorm.DB) *RankingPolicyGORMRepository {
+	return &RankingPolicyGORMRepository{
+		db: db,
+	}
+}
+
+// Find finds the ranking policy
+func (r *RankingPolicyGORMRepository) Find() (*domain.RankingPolicy, error) {
+	var policy domain.RankingPolicy
+	if err := r.db.Where("id = ?", domain.DefaultRankingPolicyID).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("ranking policy not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &policy, nil
+}
+
+// Save creates or replaces the ranking policy
+func (r *RankingPolicyGORMRepository) Save(policy *domain.RankingPolicy) error {
+	return db.ClassifyError(r.db.Save(policy).Error)
+}
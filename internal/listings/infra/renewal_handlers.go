@@ -0,0 +1,83 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListingRenewalHandler handles HTTP requests for renewing and relisting listings
+type ListingRenewalHandler struct {
+	renewalService *app.ListingRenewalService
+}
+
+// NewListingRenewalHandler creates a new listing renewal handler
+func NewListingRenewalHandler(renewalService *app.ListingRenewalService) *ListingRenewalHandler {
+	return &ListingRenewalHandler{
+		renewalService: renewalService,
+	}
+}
+
+// RegisterRoutes registers listing renewal routes. The group must be
+// protected by RequireAuth.
+func (h *ListingRenewalHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/listings/:id/renew", h.RenewListing)
+	r.POST("/listings/:id/relist", h.RelistListing)
+}
+
+// RenewListing handles pushing back when one of the caller's listings expires
+func (h *ListingRenewalHandler) RenewListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	listing, err := h.renewalService.RenewListing(c.Request.Context(), listingID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// RelistListing handles copying one of the caller's sold or expired listings
+// into a new draft
+func (h *ListingRenewalHandler) RelistListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	draft, err := h.renewalService.RelistListing(c.Request.Context(), listingID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, draft)
+}
@@ -0,0 +1,133 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler handles HTTP requests for users reporting listings
+type ReportHandler struct {
+	reportService *app.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(reportService *app.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// RegisterRoutes registers the route to report a listing. The group must be
+// protected by RequireAuth.
+func (h *ReportHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/listings/:id/report", h.ReportListing)
+}
+
+// ReportListing handles a user reporting a listing that breaks the rules
+func (h *ReportHandler) ReportListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.ReportListingCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.ListingID = listingID
+	cmd.ReporterID = auth.UserID(c)
+
+	report, err := h.reportService.ReportListing(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// AdminReportHandler handles HTTP requests for reviewing listing reports
+type AdminReportHandler struct {
+	reportService *app.ReportService
+}
+
+// NewAdminReportHandler creates a new admin report handler
+func NewAdminReportHandler(reportService *app.ReportService) *AdminReportHandler {
+	return &AdminReportHandler{
+		reportService: reportService,
+	}
+}
+
+// RegisterRoutes registers report review routes. The group must be protected
+// by the admin role.
+func (h *AdminReportHandler) RegisterRoutes(r *gin.RouterGroup) {
+	reports := r.Group("/listing-reports")
+	{
+		reports.GET("", h.ReportQueue)
+		reports.POST("/:id/resolve", h.ResolveReport)
+	}
+}
+
+// ReportQueue handles listing reports by status
+func (h *AdminReportHandler) ReportQueue(c *gin.Context) {
+	var query app.ReportQueueQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	reports, err := h.reportService.ReportQueue(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// ResolveReport handles a moderator dismissing or upholding a report
+func (h *AdminReportHandler) ResolveReport(c *gin.Context) {
+	var cmd app.ResolveReportCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.ReportID = c.Param("id")
+	cmd.AdminID = auth.UserID(c)
+
+	report, err := h.reportService.ResolveReport(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,85 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// ListingReportGORMRepository implements ListingReportRepository using GORM
+type ListingReportGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewListingReportGORMRepository creates a new listing report repository
+func NewListingReportGORMRepository(db *gorm.DB) *ListingReportGORMRepository {
+	return &ListingReportGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a report to the database
+func (r *ListingReportGORMRepository) Save(report *domain.ListingReport) error {
+	return db.ClassifyError(r.db.Create(report).Error)
+}
+
+// Update updates a report in the database
+func (r *ListingReportGORMRepository) Update(report *domain.ListingReport) error {
+	return db.ClassifyError(r.db.Save(report).Error)
+}
+
+// FindByID finds a report by ID
+func (r *ListingReportGORMRepository) FindByID(id string) (*domain.ListingReport, error) {
+	var report domain.ListingReport
+	err := r.db.First(&report, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("listing report not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &report, nil
+}
+
+// HasReported checks if the user has already reported the listing
+func (r *ListingReportGORMRepository) HasReported(listingID ids.ListingID, reporterID ids.UserID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.ListingReport{}).
+		Where("listing_id = ? AND reporter_id = ?", listingID, reporterID).
+		Count(&count).Error
+	if err != nil {
+		return false, db.ClassifyError(err)
+	}
+	return count > 0, nil
+}
+
+// FindOpenByListing finds a listing's open reports, oldest first
+func (r *ListingReportGORMRepository) FindOpenByListing(listingID ids.ListingID) ([]*domain.ListingReport, error) {
+	var reports []*domain.ListingReport
+	err := r.db.Where("listing_id = ? AND status = ?", listingID, domain.ReportStatusOpen).
+		Order("created_at").
+		Find(&reports).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return reports, nil
+}
+
+// FindByStatus finds reports across listings with the given status, oldest first
+func (r *ListingReportGORMRepository) FindByStatus(status domain.ReportStatus, limit, offset int) ([]*domain.ListingReport, int64, error) {
+	q := r.db.Where("status = ?", status)
+
+	var total int64
+	if err := q.Model(&domain.ListingReport{}).Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var reports []*domain.ListingReport
+	if err := q.Order("created_at").Limit(limit).Offset(offset).Find(&reports).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return reports, total, nil
+}
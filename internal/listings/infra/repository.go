@@ -0,0 +1,482 @@
+package infra
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// listingDocument is the weighted text of a listing, kept up to date by the
+// update_listings_search_vector trigger and indexed by idx_listings_search_vector
+const listingDocument = "listings.search_vector"
+
+// listingQuery parses a search built by searchTerms
+const listingQuery = "to_tsquery('english', ?)"
+
+// searchTerms turns what a buyer typed into a tsquery matching listings that
+// contain every word, the last one as a prefix so results follow the buyer
+// as they type. Punctuation is dropped, so input cannot break the query syntax.
+func searchTerms(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	words[len(words)-1] += ":*"
+	return strings.Join(words, " & ")
+}
+
+// listingPoint is a listing's position on the earthdistance sphere, indexed
+// by idx_listings_location for listings with coordinates
+const listingPoint = "ll_to_earth(listings.latitude, listings.longitude)"
+
+// hasCoordinates matches the listings pinned on a map
+const hasCoordinates = "(listings.latitude <> 0 OR listings.longitude <> 0)"
+
+// distanceFrom is a listing's distance in metres from the point given as its
+// latitude and longitude parameters
+const distanceFrom = "earth_distance(ll_to_earth(?, ?), " + listingPoint + ")"
+
+// ListingGORMRepository implements ListingRepository using GORM
+type ListingGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewListingGORMRepository creates a new listing repository
+func NewListingGORMRepository(db *gorm.DB) *ListingGORMRepository {
+	return &ListingGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a listing to the database
+func (r *ListingGORMRepository) Save(listing *domain.Listing) error {
+	return db.ClassifyError(r.writable(listing).Create(listing).Error)
+}
+
+// writable scopes a write to a listing's own columns. A draft without a
+// category stores NULL, since an empty string is not a UUID.
+func (r *ListingGORMRepository) writable(listing *domain.Listing) *gorm.DB {
+	if listing.CategoryID == "" {
+		return r.db.Omit("Category", "Tags", "CategoryID")
+	}
+	return r.db.Omit("Category", "Tags")
+}
+
+// orderedImages loads a listing's photos in the order the seller arranged them
+func orderedImages(q *gorm.DB) *gorm.DB {
+	return q.Order(`listing_images."order" ASC`)
+}
+
+// FindByID finds a listing by ID
+func (r *ListingGORMRepository) FindByID(id ids.ListingID) (*domain.Listing, error) {
+	var listing domain.Listing
+	err := r.db.Preload("Images", orderedImages).Preload("Attributes").Preload("Tags").First(&listing, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("listing not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &listing, nil
+}
+
+// FindByIDs finds listings by ID
+func (r *ListingGORMRepository) FindByIDs(listingIDs []ids.ListingID) ([]*domain.Listing, error) {
+	var listings []*domain.Listing
+	if len(listingIDs) == 0 {
+		return listings, nil
+	}
+	err := r.db.Preload("Images", orderedImages).Preload("Tags").
+		Where("id IN ?", listingIDs).
+		Find(&listings).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return listings, nil
+}
+
+// FindAttributes finds the attributes of the listings
+func (r *ListingGORMRepository) FindAttributes(listingIDs []ids.ListingID) ([]domain.ListingAttribute, error) {
+	var attributes []domain.ListingAttribute
+	if len(listingIDs) == 0 {
+		return attributes, nil
+	}
+	if err := r.db.Where("listing_id IN ?", listingIDs).Find(&attributes).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return attributes, nil
+}
+
+// FindBySeller finds listings by seller
+func (r *ListingGORMRepository) FindBySeller(sellerID ids.UserID, limit, offset int) ([]*domain.Listing, error) {
+	var listings []*domain.Listing
+	err := r.db.Preload("Images", orderedImages).Preload("Tags").
+		Where("seller_id = ?", sellerID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&listings).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return listings, nil
+}
+
+// FindByCategory finds listings by category
+func (r *ListingGORMRepository) FindByCategory(categoryID string, limit, offset int) ([]*domain.Listing, error) {
+	var listings []*domain.Listing
+	err := r.db.Preload("Images", orderedImages).Preload("Tags").
+		Where("category_id = ? AND status = ?", categoryID, domain.ListingStatusActive).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&listings).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return listings, nil
+}
+
+// FindIDsByFilter finds a page of the IDs of listings in any status matching
+// an administrator's filter, oldest first
+func (r *ListingGORMRepository) FindIDsByFilter(filter domain.BulkListingFilter, limit, offset int) ([]ids.ListingID, error) {
+	query := r.db.Model(&domain.Listing{})
+	if filter.SellerID != "" {
+		query = query.Where("seller_id = ?", filter.SellerID)
+	}
+	if filter.CategoryID != "" {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Region != "" {
+		query = query.Where("region = ?", filter.Region)
+	}
+
+	var listingIDs []ids.ListingID
+	err := query.Order("created_at, id").
+		Limit(limit).
+		Offset(offset).
+		Pluck("id", &listingIDs).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return listingIDs, nil
+}
+
+// Search finds a page of active listings matching the criteria, in their
+// sort order and starting after the criteria's cursor if it has one
+func (r *ListingGORMRepository) Search(criteria domain.ListingSearchCriteria, limit, offset int) ([]*domain.Listing, error) {
+	var listings []*domain.Listing
+	err := r.db.Preload("Images", orderedImages).Preload("Tags").
+		Scopes(matchCriteria(criteria), afterCursor(criteria.After), orderByCriteria(criteria)).
+		Limit(limit).
+		Offset(offset).
+		Find(&listings).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return listings, nil
+}
+
+// FindByCriteria finds active listings matching the criteria with the total match count
+func (r *ListingGORMRepository) FindByCriteria(criteria domain.ListingSearchCriteria, limit, offset int) ([]*domain.Listing, int64, error) {
+	var total int64
+	if err := r.db.Model(&domain.Listing{}).Scopes(matchCriteria(criteria)).Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	listings, err := r.Search(criteria, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return listings, total, nil
+}
+
+// Facets counts the listings matching the criteria by category, condition and region
+func (r *ListingGORMRepository) Facets(criteria domain.ListingSearchCriteria) (*domain.SearchFacets, error) {
+	facets := &domain.SearchFacets{}
+
+	groups := []struct {
+		column string
+		counts *[]domain.FacetCount
+	}{
+		{"listings.category_id", &facets.Categories},
+		{"listings.condition", &facets.Conditions},
+		{"listings.region", &facets.Regions},
+	}
+	for _, group := range groups {
+		err := r.db.Model(&domain.Listing{}).
+			Scopes(matchCriteria(criteria)).
+			Select(group.column + " AS value, COUNT(*) AS count").
+			Group(group.column).
+			Order("count DESC").
+			Scan(group.counts).Error
+		if err != nil {
+			return nil, db.ClassifyError(err)
+		}
+	}
+
+	var prices struct {
+		MinPrice *int64
+		MaxPrice *int64
+	}
+	err := r.db.Model(&domain.Listing{}).
+		Scopes(matchCriteria(criteria)).
+		Select("MIN(listings.price_minor) AS min_price, MAX(listings.price_minor) AS max_price").
+		Scan(&prices).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	if prices.MinPrice != nil {
+		facets.MinPrice = money.New(*prices.MinPrice, money.DefaultCurrency).Major()
+	}
+	if prices.MaxPrice != nil {
+		facets.MaxPrice = money.New(*prices.MaxPrice, money.DefaultCurrency).Major()
+	}
+
+	return facets, nil
+}
+
+// CountByLocation counts active listings per region, or per city when a region is given
+func (r *ListingGORMRepository) CountByLocation(region string) ([]domain.FacetCount, error) {
+	column := "listings.region"
+	criteria := domain.ListingSearchCriteria{}
+	if region != "" {
+		column = "listings.city"
+		criteria.Region = region
+	}
+
+	var counts []domain.FacetCount
+	err := r.db.Model(&domain.Listing{}).
+		Scopes(matchCriteria(criteria)).
+		Select(column + " AS value, COUNT(*) AS count").
+		Group(column).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return counts, nil
+}
+
+// matchCriteria restricts a query to the active, unexpired listings matching the criteria
+func matchCriteria(criteria domain.ListingSearchCriteria) func(*gorm.DB) *gorm.DB {
+	return func(q *gorm.DB) *gorm.DB {
+		q = q.Where("listings.status = ? AND listings.expires_at > ?", domain.ListingStatusActive, time.Now())
+		if criteria.Query != "" {
+			q = q.Where(listingDocument+" @@ "+listingQuery, searchTerms(criteria.Query))
+		}
+		if criteria.SellerID != "" {
+			q = q.Where("listings.seller_id = ?", criteria.SellerID)
+		}
+		if len(criteria.SellerIDs) > 0 {
+			q = q.Where("listings.seller_id IN ?", criteria.SellerIDs)
+		}
+		if criteria.CategoryID != "" {
+			q = q.Where("listings.category_id = ?", criteria.CategoryID)
+		}
+		if criteria.Condition != "" {
+			q = q.Where("listings.condition = ?", criteria.Condition)
+		}
+		if criteria.MinPrice != nil {
+			q = q.Where("listings.price_minor >= ?", money.Cedis(*criteria.MinPrice).Amount)
+		}
+		if criteria.MaxPrice != nil {
+			q = q.Where("listings.price_minor <= ?", money.Cedis(*criteria.MaxPrice).Amount)
+		}
+		if criteria.Region != "" {
+			q = q.Where("listings.region = ?", criteria.Region)
+		}
+		if criteria.City != "" {
+			q = q.Where("listings.city = ?", criteria.City)
+		}
+		if criteria.Negotiable != nil {
+			q = q.Where("listings.is_negotiable = ?", *criteria.Negotiable)
+		}
+		if criteria.Tag != "" {
+			q = q.Where(`EXISTS (SELECT 1 FROM listing_tag_relations
+				JOIN listing_tags ON listing_tags.id = listing_tag_relations.listing_tag_id
+				WHERE listing_tag_relations.listing_id = listings.id AND listing_tags.name = ?)`, criteria.Tag)
+		}
+		if criteria.PromotedAt != nil {
+			q = q.Where("listings.is_promoted AND listings.promoted_until > ?", *criteria.PromotedAt)
+		}
+		if near := criteria.Near; near != nil {
+			// The bounding box narrows the search through the index before
+			// exact distances are compared
+			radius := near.RadiusKm * 1000
+			q = q.Where(hasCoordinates).
+				Where("earth_box(ll_to_earth(?, ?), ?) @> "+listingPoint, near.Latitude, near.Longitude, radius).
+				Where(distanceFrom+" <= ?", near.Latitude, near.Longitude, radius)
+		}
+		return q
+	}
+}
+
+// afterCursor restricts a query to the listings after the cursor in its sort
+// order. The comparisons mirror the orderings in orderByCriteria.
+func afterCursor(cursor *domain.ListingCursor) func(*gorm.DB) *gorm.DB {
+	return func(q *gorm.DB) *gorm.DB {
+		if cursor == nil {
+			return q
+		}
+		switch cursor.Sort {
+		case domain.ListingSortNewest:
+			return q.Where("(listings.created_at, listings.id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+		case domain.ListingSortPriceAsc:
+			return q.Where("(listings.price_minor, listings.id) > (?, ?)", cursor.Value, cursor.ID)
+		case domain.ListingSortPriceDesc:
+			return q.Where("(listings.price_minor, listings.id) < (?, ?)", cursor.Value, cursor.ID)
+		case domain.ListingSortMostViewed:
+			return q.Where("("+listingViews+", listings.id) < (?, ?)", cursor.Value, cursor.ID)
+		}
+		return q
+	}
+}
+
+// listingViews is the view count of a listing, 0 for listings not viewed yet
+const listingViews = "COALESCE((SELECT listing_counters.views FROM listing_counters WHERE listing_counters.listing_id = listings.id), 0)"
+
+// sellerRankingFactor is the ranking penalty of a listing's seller, 1 for
+// sellers the ranking job has not penalized
+const sellerRankingFactor = "COALESCE((SELECT seller_cards.ranking_factor FROM seller_cards WHERE seller_cards.seller_id = listings.seller_id), 1)"
+
+// orderByCriteria applies the criteria's sort order. Listing IDs break ties,
+// so pages never overlap. Relevance is demoted by the seller's ranking penalty.
+func orderByCriteria(criteria domain.ListingSearchCriteria) func(*gorm.DB) *gorm.DB {
+	return func(q *gorm.DB) *gorm.DB {
+		switch criteria.SortOrder() {
+		case domain.ListingSortPriceAsc:
+			return q.Order("listings.price_minor ASC, listings.id ASC")
+		case domain.ListingSortPriceDesc:
+			return q.Order("listings.price_minor DESC, listings.id DESC")
+		case domain.ListingSortMostViewed:
+			return q.Order(listingViews + " DESC, listings.id DESC")
+		case domain.ListingSortNewest:
+			return q.Order("listings.created_at DESC, listings.id DESC")
+		case domain.ListingSortDistance:
+			if near := criteria.Near; near != nil {
+				return q.Order(clause.OrderBy{Expression: clause.Expr{
+					SQL:                distanceFrom + " ASC, listings.id ASC",
+					Vars:               []interface{}{near.Latitude, near.Longitude},
+					WithoutParentheses: true,
+				}})
+			}
+		}
+
+		if criteria.Query == "" {
+			return q.Order(sellerRankingFactor + " DESC, listings.created_at DESC")
+		}
+		return q.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ts_rank(" + listingDocument + ", " + listingQuery + ") * " + sellerRankingFactor + " DESC, listings.created_at DESC",
+			Vars:               []interface{}{searchTerms(criteria.Query)},
+			WithoutParentheses: true,
+		}})
+	}
+}
+
+// FindDueScheduled finds scheduled drafts whose publish time has come, earliest first
+func (r *ListingGORMRepository) FindDueScheduled(now time.Time, limit int) ([]*domain.Listing, error) {
+	var listings []*domain.Listing
+	err := r.db.Where("status = ? AND publish_at <= ?", domain.ListingStatusDraft, now).
+		Order("publish_at").
+		Limit(limit).
+		Find(&listings).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return listings, nil
+}
+
+// FindLapsedPromotions finds promoted listings whose promotion has ended, earliest first
+func (r *ListingGORMRepository) FindLapsedPromotions(now time.Time, limit int) ([]*domain.Listing, error) {
+	var listings []*domain.Listing
+	err := r.db.Where("is_promoted AND (promoted_until IS NULL OR promoted_until <= ?)", now).
+		Order("promoted_until").
+		Limit(limit).
+		Find(&listings).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return listings, nil
+}
+
+// FindStaleDrafts finds unscheduled drafts last saved before the given time,
+// least recently saved first
+func (r *ListingGORMRepository) FindStaleDrafts(before time.Time, limit int) ([]*domain.Listing, error) {
+	var listings []*domain.Listing
+	err := r.db.Where("status = ? AND publish_at IS NULL AND updated_at < ?", domain.ListingStatusDraft, before).
+		Order("updated_at").
+		Limit(limit).
+		Find(&listings).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return listings, nil
+}
+
+// FindLapsedListings finds live listings past their expiry that no buyer
+// holds a reservation on, earliest expiry first
+func (r *ListingGORMRepository) FindLapsedListings(now time.Time, limit int) ([]*domain.Listing, error) {
+	var listings []*domain.Listing
+	err := r.db.Where("status = ? AND expires_at <= ?", domain.ListingStatusActive, now).
+		Where("reserved_until IS NULL OR reserved_until <= ?", now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&listings).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return listings, nil
+}
+
+// CountScheduledBySeller counts a seller's drafts waiting to go live
+func (r *ListingGORMRepository) CountScheduledBySeller(sellerID ids.UserID) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Listing{}).
+		Where("seller_id = ? AND status = ? AND publish_at IS NOT NULL", sellerID, domain.ListingStatusDraft).
+		Count(&count).Error
+	if err != nil {
+		return 0, db.ClassifyError(err)
+	}
+	return count, nil
+}
+
+// CountActiveBySeller counts a seller's live listings
+func (r *ListingGORMRepository) CountActiveBySeller(sellerID ids.UserID) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Listing{}).
+		Where("seller_id = ? AND status = ?", sellerID, domain.ListingStatusActive).
+		Count(&count).Error
+	if err != nil {
+		return 0, db.ClassifyError(err)
+	}
+	return count, nil
+}
+
+// Update updates a listing in the database
+func (r *ListingGORMRepository) Update(listing *domain.Listing) error {
+	return db.ClassifyError(r.writable(listing).Save(listing).Error)
+}
+
+// UpdateImage updates one of a listing's photos
+func (r *ListingGORMRepository) UpdateImage(image *domain.ListingImage) error {
+	return db.ClassifyError(r.db.Save(image).Error)
+}
+
+// Delete deletes a listing from the database
+func (r *ListingGORMRepository) Delete(id ids.ListingID) error {
+	return db.ClassifyError(r.db.Delete(&domain.Listing{}, "id = ?", id).Error)
+}
@@ -0,0 +1,50 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/rules"
+
+	"gorm.io/gorm"
+)
+
+// SellerQuotaHistory replays listing quota rules against the sellers whose
+// cards changed recently
+type SellerQuotaHistory struct {
+	db *gorm.DB
+}
+
+// NewSellerQuotaHistory creates a new seller quota history
+func NewSellerQuotaHistory(db *gorm.DB) *SellerQuotaHistory {
+	return &SellerQuotaHistory{
+		db: db,
+	}
+}
+
+// Cases returns one case per seller card updated since the given time, most
+// recent first
+func (h *SellerQuotaHistory) Cases(ctx context.Context, since time.Time, limit int) ([]rules.Case, error) {
+	var cards []*domain.SellerCard
+	err := h.db.
+		Where("updated_at >= ?", since).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&cards).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+
+	cases := make([]rules.Case, 0, len(cards))
+	for _, card := range cards {
+		cases = append(cases, rules.Case{
+			Subject:    card.SellerID.String(),
+			OccurredAt: card.UpdatedAt,
+			Facts:      app.SellerCardFacts(card),
+		})
+	}
+	return cases, nil
+}
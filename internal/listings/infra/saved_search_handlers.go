@@ -0,0 +1,127 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SavedSearchHandler handles HTTP requests for a buyer's saved searches
+type SavedSearchHandler struct {
+	savedSearchService *app.SavedSearchService
+}
+
+// NewSavedSearchHandler creates a new saved search handler
+func NewSavedSearchHandler(savedSearchService *app.SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		savedSearchService: savedSearchService,
+	}
+}
+
+// RegisterRoutes registers saved search routes. The group must be protected
+// by RequireAuth.
+func (h *SavedSearchHandler) RegisterRoutes(r *gin.RouterGroup) {
+	searches := r.Group("/saved-searches")
+	{
+		searches.POST("", h.CreateSavedSearch)
+		searches.GET("", h.ListSavedSearches)
+		searches.GET("/:id", h.GetSavedSearch)
+		searches.PUT("/:id", h.UpdateSavedSearch)
+		searches.DELETE("/:id", h.DeleteSavedSearch)
+	}
+}
+
+// CreateSavedSearch handles saving a search for the caller
+func (h *SavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+	var cmd app.SaveSearchCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.UserID = auth.UserID(c)
+
+	search, err := h.savedSearchService.CreateSavedSearch(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+// ListSavedSearches handles listing the caller's saved searches, newest first
+func (h *SavedSearchHandler) ListSavedSearches(c *gin.Context) {
+	searches, err := h.savedSearchService.ListSavedSearches(c.Request.Context(), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"saved_searches": searches})
+}
+
+// GetSavedSearch handles retrieving one of the caller's saved searches
+func (h *SavedSearchHandler) GetSavedSearch(c *gin.Context) {
+	search, err := h.savedSearchService.GetSavedSearch(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// UpdateSavedSearch handles replacing the query, filters and alert setting of
+// one of the caller's saved searches
+func (h *SavedSearchHandler) UpdateSavedSearch(c *gin.Context) {
+	var cmd app.SaveSearchCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.UserID = auth.UserID(c)
+
+	search, err := h.savedSearchService.UpdateSavedSearch(c.Request.Context(), c.Param("id"), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// DeleteSavedSearch handles deleting one of the caller's saved searches
+func (h *SavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	err := h.savedSearchService.DeleteSavedSearch(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "saved search deleted"})
+}
@@ -0,0 +1,102 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// SavedSearchGORMRepository implements SavedSearchRepository using GORM
+type SavedSearchGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewSavedSearchGORMRepository creates a new saved search repository
+func NewSavedSearchGORMRepository(db *gorm.DB) *SavedSearchGORMRepository {
+	return &SavedSearchGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a saved search to the database
+func (r *SavedSearchGORMRepository) Save(search *domain.SavedSearch) error {
+	return db.ClassifyError(r.db.Create(search).Error)
+}
+
+// Update updates a saved search in the database
+func (r *SavedSearchGORMRepository) Update(search *domain.SavedSearch) error {
+	return db.ClassifyError(r.db.Save(search).Error)
+}
+
+// Delete deletes a saved search from the database
+func (r *SavedSearchGORMRepository) Delete(id string) error {
+	return db.ClassifyError(r.db.Where("id = ?", id).Delete(&domain.SavedSearch{}).Error)
+}
+
+// FindByID finds a saved search by ID
+func (r *SavedSearchGORMRepository) FindByID(id string) (*domain.SavedSearch, error) {
+	var search domain.SavedSearch
+	if err := r.db.Where("id = ?", id).First(&search).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("saved search not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &search, nil
+}
+
+// FindByUser finds a user's saved searches, newest first
+func (r *SavedSearchGORMRepository) FindByUser(userID ids.UserID) ([]*domain.SavedSearch, error) {
+	var searches []*domain.SavedSearch
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&searches).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return searches, nil
+}
+
+// CountByUser counts a user's saved searches
+func (r *SavedSearchGORMRepository) CountByUser(userID ids.UserID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.SavedSearch{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, db.ClassifyError(err)
+	}
+	return count, nil
+}
+
+// FindAlertCandidates finds saved searches with alerts enabled whose
+// structured filters admit the listing, ordered by ID after afterID. Empty
+// filters admit every listing; the query and radius are checked by Matches.
+func (r *SavedSearchGORMRepository) FindAlertCandidates(listing *domain.Listing, afterID string, limit int) ([]*domain.SavedSearch, error) {
+	query := r.db.Where("alerts_enabled").
+		Where("category_id = '' OR category_id = ?", listing.CategoryID).
+		Where("condition = '' OR condition = ?", listing.Condition).
+		Where("min_price IS NULL OR min_price <= ?", listing.Price.Major()).
+		Where("max_price IS NULL OR max_price >= ?", listing.Price.Major()).
+		Where("region = '' OR region = ?", listing.Location.Region).
+		Where("city = '' OR city = ?", listing.Location.City).
+		Where("negotiable IS NULL OR negotiable = ?", listing.IsNegotiable)
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+
+	var searches []*domain.SavedSearch
+	if err := query.Order("id").Limit(limit).Find(&searches).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return searches, nil
+}
+
+// MarkAlerted records that the users of the searches were told about a new match
+func (r *SavedSearchGORMRepository) MarkAlerted(searchIDs []string, at time.Time) error {
+	if len(searchIDs) == 0 {
+		return nil
+	}
+	return db.ClassifyError(r.db.Model(&domain.SavedSearch{}).
+		Where("id IN ?", searchIDs).
+		Update("last_alerted_at", at).Error)
+}
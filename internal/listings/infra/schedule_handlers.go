@@ -0,0 +1,91 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListingScheduleHandler handles HTTP requests for scheduling listings
+type ListingScheduleHandler struct {
+	scheduleService *app.ListingScheduleService
+}
+
+// NewListingScheduleHandler creates a new listing schedule handler
+func NewListingScheduleHandler(scheduleService *app.ListingScheduleService) *ListingScheduleHandler {
+	return &ListingScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// RegisterRoutes registers listing schedule routes. The group must be
+// protected by RequireAuth.
+func (h *ListingScheduleHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.PUT("/listings/:id/schedule", h.ScheduleListing)
+	r.DELETE("/listings/:id/schedule", h.CancelSchedule)
+}
+
+// ScheduleListing handles scheduling or rescheduling one of the caller's drafts
+func (h *ListingScheduleHandler) ScheduleListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.ScheduleListingCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.ListingID = listingID
+	cmd.SellerID = auth.UserID(c)
+
+	listing, err := h.scheduleService.ScheduleListing(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// CancelSchedule handles keeping one of the caller's scheduled listings as a draft
+func (h *ListingScheduleHandler) CancelSchedule(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	listing, err := h.scheduleService.CancelSchedule(c.Request.Context(), listingID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
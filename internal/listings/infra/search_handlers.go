@@ -0,0 +1,206 @@
+package infra
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListingSearchHandler handles HTTP requests for listing search
+type ListingSearchHandler struct {
+	listingService     *app.ListingService
+	translationService *app.TranslationService
+	searchService      *app.SearchService
+	currencyService    *app.CurrencyService
+}
+
+// NewListingSearchHandler creates a new listing search handler. Listings are
+// shown with their category name and attribute labels in the request locale.
+// Searches go to the search index when searchService is set, and to the
+// database otherwise. Prices are also shown in the currency the caller asks
+// for or prefers.
+func NewListingSearchHandler(listingService *app.ListingService, translationService *app.TranslationService, searchService *app.SearchService, currencyService *app.CurrencyService) *ListingSearchHandler {
+	return &ListingSearchHandler{
+		listingService:     listingService,
+		translationService: translationService,
+		searchService:      searchService,
+		currencyService:    currencyService,
+	}
+}
+
+// RegisterRoutes registers listing search routes
+func (h *ListingSearchHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/listings", h.SearchListings)
+	r.GET("/listings/:id", h.GetListing)
+	r.GET("/listings/:id/price-history", h.GetPriceHistory)
+
+	sellers := r.Group("/sellers")
+	{
+		sellers.GET("/:id/listings/search", h.SearchSellerListings)
+	}
+}
+
+// RegisterFeedRoutes registers the followed-seller feed route. The group must
+// be protected by RequireAuth.
+func (h *ListingSearchHandler) RegisterFeedRoutes(r *gin.RouterGroup) {
+	r.GET("/follows/feed", h.FollowedSellerFeed)
+}
+
+// GetListing handles retrieving an active listing with its top answered questions
+func (h *ListingSearchHandler) GetListing(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	listing, err := h.listingService.GetListing(c.Request.Context(), listingID, viewerKey(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	h.translationService.LocalizeListings(c.Request.Context(), i18n.FromContext(c), listing.Listing)
+	showDisplayPrices(c, h.currencyService, listing.Listing)
+	c.JSON(http.StatusOK, listing)
+}
+
+// viewerKey identifies who is viewing a listing, by their address and
+// browser, so their repeated views count once. Only a hash is kept, so the
+// key does not say who they are.
+func viewerKey(c *gin.Context) string {
+	sum := sha256.Sum256([]byte(c.ClientIP() + "|" + c.Request.UserAgent()))
+	return hex.EncodeToString(sum[:16])
+}
+
+// GetPriceHistory handles retrieving how an active listing's price has changed
+func (h *ListingSearchHandler) GetPriceHistory(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	history, err := h.listingService.GetPriceHistory(c.Request.Context(), listingID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// SearchListings handles searching the marketplace
+func (h *ListingSearchHandler) SearchListings(c *gin.Context) {
+	var query app.SearchListingsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	var results *app.ListingSearchResults
+	var err error
+	if h.searchService != nil {
+		results, err = h.searchService.SearchListings(c.Request.Context(), query)
+	} else {
+		results, err = h.listingService.SearchListings(c.Request.Context(), query)
+	}
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	h.translationService.LocalizeListings(c.Request.Context(), i18n.FromContext(c), results.Listings...)
+	showDisplayPrices(c, h.currencyService, results.Listings...)
+	c.JSON(http.StatusOK, results)
+}
+
+// SearchSellerListings handles searching within a seller's storefront
+func (h *ListingSearchHandler) SearchSellerListings(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var query app.SearchListingsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	var results *app.ListingSearchResults
+	if h.searchService != nil {
+		results, err = h.searchService.SearchSellerListings(c.Request.Context(), userID, query)
+	} else {
+		results, err = h.listingService.SearchSellerListings(c.Request.Context(), userID, query)
+	}
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	h.translationService.LocalizeListings(c.Request.Context(), i18n.FromContext(c), results.Listings...)
+	showDisplayPrices(c, h.currencyService, results.Listings...)
+	c.JSON(http.StatusOK, results)
+}
+
+// FollowedSellerFeed handles listing the new listings of the sellers the caller follows
+func (h *ListingSearchHandler) FollowedSellerFeed(c *gin.Context) {
+	var query app.SearchListingsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	results, err := h.listingService.FollowedSellerFeed(c.Request.Context(), auth.UserID(c), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	h.translationService.LocalizeListings(c.Request.Context(), i18n.FromContext(c), results.Listings...)
+	showDisplayPrices(c, h.currencyService, results.Listings...)
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,396 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// facetSize caps how many values are counted per facet
+const facetSize = 50
+
+// listingIndexMapping is the mapping of the listings index. Titles and
+// descriptions are analyzed like the Postgres search vector, in English.
+const listingIndexMapping = `{
+  "mappings": ` + listingIndexProperties + `
+}`
+
+// listingIndexProperties are the fields of listing documents
+const listingIndexProperties = `{
+    "properties": {
+      "id": {"type": "keyword"},
+      "seller_id": {"type": "keyword"},
+      "category_id": {"type": "keyword"},
+      "title": {"type": "text", "analyzer": "english"},
+      "description": {"type": "text", "analyzer": "english"},
+      "price": {"type": "double"},
+      "condition": {"type": "keyword"},
+      "region": {"type": "keyword"},
+      "city": {"type": "keyword"},
+      "location": {"type": "geo_point"},
+      "is_negotiable": {"type": "boolean"},
+      "tags": {"type": "keyword"},
+      "status": {"type": "keyword"},
+      "views_count": {"type": "long"},
+      "ranking_factor": {"type": "double"},
+      "promoted_until": {"type": "date"},
+      "expires_at": {"type": "date"},
+      "created_at": {"type": "date"}
+    }
+  }`
+
+// ElasticsearchListingIndex implements ListingIndex on Elasticsearch or
+// OpenSearch through their common REST API
+type ElasticsearchListingIndex struct {
+	client   *http.Client
+	baseURL  string
+	index    string
+	username string
+	password string
+}
+
+// NewElasticsearchListingIndex creates a listing index stored in the named
+// index of the cluster at baseURL. username may be empty for clusters
+// without authentication.
+func NewElasticsearchListingIndex(baseURL, index, username, password string, timeout time.Duration) *ElasticsearchListingIndex {
+	return &ElasticsearchListingIndex{
+		client:   &http.Client{Timeout: timeout},
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		index:    index,
+		username: username,
+		password: password,
+	}
+}
+
+// EnsureIndex creates the index with its mapping, or adds the fields an
+// existing index created by an earlier version lacks
+func (x *ElasticsearchListingIndex) EnsureIndex(ctx context.Context) error {
+	status, _, err := x.do(ctx, http.MethodHead, "/"+x.index, nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusOK {
+		status, body, err := x.do(ctx, http.MethodPut, "/"+x.index+"/_mapping", []byte(listingIndexProperties))
+		if err != nil {
+			return err
+		}
+		if status >= http.StatusBadRequest {
+			return fmt.Errorf("updating search index %s mapping: unexpected status %d: %s", x.index, status, body)
+		}
+		return nil
+	}
+
+	status, body, err := x.do(ctx, http.MethodPut, "/"+x.index, []byte(listingIndexMapping))
+	if err != nil {
+		return err
+	}
+	// Another instance may have created it meanwhile
+	if status >= http.StatusBadRequest && !bytes.Contains(body, []byte("resource_already_exists_exception")) {
+		return fmt.Errorf("creating search index %s: unexpected status %d: %s", x.index, status, body)
+	}
+	return nil
+}
+
+// Index adds a listing to the index or replaces it
+func (x *ElasticsearchListingIndex) Index(ctx context.Context, doc *domain.ListingDocument) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	status, body, err := x.do(ctx, http.MethodPut, x.docPath(doc.ID), data)
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusBadRequest {
+		return fmt.Errorf("indexing listing %s: unexpected status %d: %s", doc.ID, status, body)
+	}
+	return nil
+}
+
+// Remove drops a listing from the index; listings not in it are ignored
+func (x *ElasticsearchListingIndex) Remove(ctx context.Context, listingID ids.ListingID) error {
+	status, body, err := x.do(ctx, http.MethodDelete, x.docPath(listingID), nil)
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusBadRequest && status != http.StatusNotFound {
+		return fmt.Errorf("removing listing %s: unexpected status %d: %s", listingID, status, body)
+	}
+	return nil
+}
+
+// Search finds a page of active listings matching the criteria, with facets
+// over every match. Failures are reported as the search being unavailable.
+func (x *ElasticsearchListingIndex) Search(ctx context.Context, criteria domain.ListingSearchCriteria, limit, offset int) (*domain.ListingHits, error) {
+	data, err := json.Marshal(searchRequest(criteria, limit, offset))
+	if err != nil {
+		return nil, err
+	}
+	status, body, err := x.do(ctx, http.MethodPost, "/"+x.index+"/_search", data)
+	if err != nil || status >= http.StatusBadRequest {
+		return nil, errors.UnavailableError("search is temporarily unavailable")
+	}
+
+	var resp searchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	hits := &domain.ListingHits{
+		IDs:   make([]ids.ListingID, 0, len(resp.Hits.Hits)),
+		Total: resp.Hits.Total.Value,
+		Facets: &domain.SearchFacets{
+			Categories: resp.Aggregations.Categories.counts(),
+			Conditions: resp.Aggregations.Conditions.counts(),
+			Regions:    resp.Aggregations.Regions.counts(),
+		},
+	}
+	if resp.Aggregations.MinPrice.Value != nil {
+		hits.Facets.MinPrice = *resp.Aggregations.MinPrice.Value
+	}
+	if resp.Aggregations.MaxPrice.Value != nil {
+		hits.Facets.MaxPrice = *resp.Aggregations.MaxPrice.Value
+	}
+	for _, hit := range resp.Hits.Hits {
+		hits.IDs = append(hits.IDs, ids.ListingID(hit.ID))
+	}
+	return hits, nil
+}
+
+// docPath is the path of a listing's document
+func (x *ElasticsearchListingIndex) docPath(listingID ids.ListingID) string {
+	return "/" + x.index + "/_doc/" + url.PathEscape(listingID.String())
+}
+
+// do sends a request to the cluster and returns the response status and body
+func (x *ElasticsearchListingIndex) do(ctx context.Context, method, path string, body []byte) (int, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, x.baseURL+path, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if x.username != "" {
+		req.SetBasicAuth(x.username, x.password)
+	}
+
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, data, nil
+}
+
+// searchRequest builds the search body for the criteria. The filters mirror
+// matchCriteria and the sort orders mirror orderByCriteria, so results come
+// back as they would from Postgres.
+func searchRequest(criteria domain.ListingSearchCriteria, limit, offset int) map[string]interface{} {
+	filters := []interface{}{
+		term("status", domain.ListingStatusActive),
+		map[string]interface{}{"range": map[string]interface{}{"expires_at": map[string]interface{}{"gt": "now"}}},
+	}
+	if criteria.SellerID != "" {
+		filters = append(filters, term("seller_id", criteria.SellerID))
+	}
+	if len(criteria.SellerIDs) > 0 {
+		filters = append(filters, map[string]interface{}{"terms": map[string]interface{}{"seller_id": criteria.SellerIDs}})
+	}
+	if criteria.CategoryID != "" {
+		filters = append(filters, term("category_id", criteria.CategoryID))
+	}
+	if criteria.Condition != "" {
+		filters = append(filters, term("condition", criteria.Condition))
+	}
+	if criteria.MinPrice != nil || criteria.MaxPrice != nil {
+		price := map[string]interface{}{}
+		if criteria.MinPrice != nil {
+			price["gte"] = *criteria.MinPrice
+		}
+		if criteria.MaxPrice != nil {
+			price["lte"] = *criteria.MaxPrice
+		}
+		filters = append(filters, map[string]interface{}{"range": map[string]interface{}{"price": price}})
+	}
+	if criteria.Region != "" {
+		filters = append(filters, term("region", criteria.Region))
+	}
+	if criteria.City != "" {
+		filters = append(filters, term("city", criteria.City))
+	}
+	if criteria.Negotiable != nil {
+		filters = append(filters, term("is_negotiable", *criteria.Negotiable))
+	}
+	if criteria.Tag != "" {
+		filters = append(filters, term("tags", criteria.Tag))
+	}
+	if criteria.PromotedAt != nil {
+		filters = append(filters, map[string]interface{}{"range": map[string]interface{}{"promoted_until": map[string]interface{}{"gt": *criteria.PromotedAt}}})
+	}
+	if near := criteria.Near; near != nil {
+		filters = append(filters, map[string]interface{}{"geo_distance": map[string]interface{}{
+			"distance": fmt.Sprintf("%gkm", near.RadiusKm),
+			"location": domain.GeoPoint{Lat: near.Latitude, Lon: near.Longitude},
+		}})
+	}
+
+	match := map[string]interface{}{"filter": filters}
+	if criteria.Query != "" {
+		// The last word matches as a prefix, like searchTerms
+		match["must"] = map[string]interface{}{"multi_match": map[string]interface{}{
+			"query":    criteria.Query,
+			"type":     "bool_prefix",
+			"fields":   []string{"title^2", "description"},
+			"operator": "and",
+		}}
+	}
+	query := map[string]interface{}{"bool": match}
+
+	var sort []interface{}
+	switch criteria.SortOrder() {
+	case domain.ListingSortPriceAsc:
+		sort = []interface{}{field("price", "asc"), field("id", "asc")}
+	case domain.ListingSortPriceDesc:
+		sort = []interface{}{field("price", "desc"), field("id", "desc")}
+	case domain.ListingSortMostViewed:
+		sort = []interface{}{field("views_count", "desc"), field("id", "desc")}
+	case domain.ListingSortNewest:
+		sort = []interface{}{field("created_at", "desc"), field("id", "desc")}
+	case domain.ListingSortDistance:
+		if near := criteria.Near; near != nil {
+			sort = []interface{}{map[string]interface{}{"_geo_distance": map[string]interface{}{
+				"location": domain.GeoPoint{Lat: near.Latitude, Lon: near.Longitude},
+				"order":    "asc",
+				"unit":     "km",
+			}}, field("id", "asc")}
+		}
+	default:
+		if criteria.Query == "" {
+			sort = []interface{}{field("ranking_factor", "desc"), field("created_at", "desc"), field("id", "desc")}
+		} else {
+			// Relevance is demoted by the seller's ranking penalty
+			query = map[string]interface{}{"function_score": map[string]interface{}{
+				"query":              query,
+				"field_value_factor": map[string]interface{}{"field": "ranking_factor", "missing": 1},
+				"boost_mode":         "multiply",
+			}}
+			sort = []interface{}{"_score", field("created_at", "desc"), field("id", "desc")}
+		}
+	}
+
+	request := map[string]interface{}{
+		"query":            query,
+		"sort":             sort,
+		"size":             limit,
+		"from":             offset,
+		"track_total_hits": true,
+		"_source":          false,
+		"aggs": map[string]interface{}{
+			"categories": terms("category_id"),
+			"conditions": terms("condition"),
+			"regions":    terms("region"),
+			"min_price":  map[string]interface{}{"min": map[string]interface{}{"field": "price"}},
+			"max_price":  map[string]interface{}{"max": map[string]interface{}{"field": "price"}},
+		},
+	}
+	if after := searchAfter(criteria.After); after != nil {
+		request["search_after"] = after
+	}
+	return request
+}
+
+// searchAfter returns the sort values of the listing a cursor marks, in the
+// order of the sort fields
+func searchAfter(cursor *domain.ListingCursor) []interface{} {
+	if cursor == nil {
+		return nil
+	}
+	switch cursor.Sort {
+	case domain.ListingSortNewest:
+		return []interface{}{cursor.CreatedAt.UnixMilli(), cursor.ID}
+	case domain.ListingSortPriceAsc, domain.ListingSortPriceDesc:
+		// Cursors keep prices in minor units, documents in major units
+		return []interface{}{money.New(int64(cursor.Value), money.DefaultCurrency).Major(), cursor.ID}
+	case domain.ListingSortMostViewed:
+		return []interface{}{cursor.Value, cursor.ID}
+	}
+	return nil
+}
+
+// term matches documents whose field has exactly the value
+func term(name string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"term": map[string]interface{}{name: value}}
+}
+
+// field sorts by a field in the given direction
+func field(name, order string) map[string]interface{} {
+	return map[string]interface{}{name: map[string]interface{}{"order": order}}
+}
+
+// terms counts the matches per value of a field, most common first
+func terms(name string) map[string]interface{} {
+	return map[string]interface{}{"terms": map[string]interface{}{"field": name, "size": facetSize}}
+}
+
+// searchResponse is the part of a search response the index reads
+type searchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			ID string `json:"_id"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations struct {
+		Categories termsAggregation  `json:"categories"`
+		Conditions termsAggregation  `json:"conditions"`
+		Regions    termsAggregation  `json:"regions"`
+		MinPrice   metricAggregation `json:"min_price"`
+		MaxPrice   metricAggregation `json:"max_price"`
+	} `json:"aggregations"`
+}
+
+// termsAggregation is the result of a terms aggregation
+type termsAggregation struct {
+	Buckets []struct {
+		Key      string `json:"key"`
+		DocCount int64  `json:"doc_count"`
+	} `json:"buckets"`
+}
+
+// counts converts the buckets into facet counts
+func (a termsAggregation) counts() []domain.FacetCount {
+	counts := make([]domain.FacetCount, 0, len(a.Buckets))
+	for _, bucket := range a.Buckets {
+		counts = append(counts, domain.FacetCount{Value: bucket.Key, Count: bucket.DocCount})
+	}
+	return counts
+}
+
+// metricAggregation is the result of a min or max aggregation, null when
+// nothing matched
+type metricAggregation struct {
+	Value *float64 `json:"value"`
+}
@@ -0,0 +1,112 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SellerCardGORMRepository implements SellerCardRepository using GORM
+type SellerCardGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewSellerCardGORMRepository creates a new seller card repository
+func NewSellerCardGORMRepository(db *gorm.DB) *SellerCardGORMRepository {
+	return &SellerCardGORMRepository{
+		db: db,
+	}
+}
+
+// FindBySellerIDs finds the cards of the given sellers in one query. Sellers
+// without a card are left out.
+func (r *SellerCardGORMRepository) FindBySellerIDs(sellerIDs []ids.UserID) ([]*domain.SellerCard, error) {
+	var cards []*domain.SellerCard
+	if len(sellerIDs) == 0 {
+		return cards, nil
+	}
+	if err := r.db.Where("seller_id IN ?", sellerIDs).Find(&cards).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return cards, nil
+}
+
+// SaveStats creates or updates a card's stats, leaving the card alone when
+// it already holds stats as of a later time. Verification is kept, since
+// sellers are not unverified.
+func (r *SellerCardGORMRepository) SaveStats(card *domain.SellerCard) error {
+	return db.ClassifyError(r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "seller_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "verified"}, Value: gorm.Expr("seller_cards.verified OR excluded.verified")},
+			{Column: clause.Column{Name: "rating"}, Value: gorm.Expr("excluded.rating")},
+			{Column: clause.Column{Name: "total_reviews"}, Value: gorm.Expr("excluded.total_reviews")},
+			{Column: clause.Column{Name: "response_time_minutes"}, Value: gorm.Expr("excluded.response_time_minutes")},
+			{Column: clause.Column{Name: "trust_level"}, Value: gorm.Expr("excluded.trust_level")},
+			{Column: clause.Column{Name: "badges"}, Value: gorm.Expr("excluded.badges")},
+			{Column: clause.Column{Name: "stats_as_of"}, Value: gorm.Expr("excluded.stats_as_of")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+		},
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "seller_cards.stats_as_of IS NULL OR seller_cards.stats_as_of < excluded.stats_as_of"},
+		}},
+	}).Create(card).Error)
+}
+
+// MarkVerified sets a seller's card as verified, creating the card if the
+// seller has none yet
+func (r *SellerCardGORMRepository) MarkVerified(sellerID ids.UserID, at time.Time) error {
+	card := domain.NewSellerCard(sellerID, at)
+	card.Verified = true
+	return db.ClassifyError(r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "seller_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "verified"}, Value: true},
+			{Column: clause.Column{Name: "updated_at"}, Value: at},
+		},
+	}).Create(card).Error)
+}
+
+// SaveRanking sets a seller's ranking penalty, creating the card if the
+// seller has none yet
+func (r *SellerCardGORMRepository) SaveRanking(sellerID ids.UserID, factor float64, reasons []string, at time.Time) error {
+	card := domain.NewSellerCard(sellerID, at)
+	card.RankingFactor = factor
+	card.RankingReasons = reasons
+	card.RankedAt = &at
+	return db.ClassifyError(r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "seller_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "ranking_factor"}, Value: gorm.Expr("excluded.ranking_factor")},
+			{Column: clause.Column{Name: "ranking_reasons"}, Value: gorm.Expr("excluded.ranking_reasons")},
+			{Column: clause.Column{Name: "ranked_at"}, Value: gorm.Expr("excluded.ranked_at")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+		},
+	}).Create(card).Error)
+}
+
+// FindPenalized finds the cards of sellers ranked with a penalty, most
+// demoted first, with the total count
+func (r *SellerCardGORMRepository) FindPenalized(limit, offset int) ([]*domain.SellerCard, int64, error) {
+	query := r.db.Model(&domain.SellerCard{}).Where("ranking_factor < 1")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var cards []*domain.SellerCard
+	err := query.Order("ranking_factor ASC, seller_id").
+		Limit(limit).
+		Offset(offset).
+		Find(&cards).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return cards, total, nil
+}
@@ -0,0 +1,126 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareLinkHandler handles HTTP requests for the short links listings are
+// shared with
+type ShareLinkHandler struct {
+	shareLinkService *app.ShareLinkService
+}
+
+// NewShareLinkHandler creates a new share link handler
+func NewShareLinkHandler(shareLinkService *app.ShareLinkService) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		shareLinkService: shareLinkService,
+	}
+}
+
+// RegisterRoutes registers the public share link routes. Anyone may share a
+// listing, signed in or not.
+func (h *ShareLinkHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/listings/:id/share-links", h.CreateShareLink)
+}
+
+// RegisterAuthenticatedRoutes registers share link routes for sellers. The
+// group must be protected by RequireAuth.
+func (h *ShareLinkHandler) RegisterAuthenticatedRoutes(r *gin.RouterGroup) {
+	r.GET("/listings/:id/share-stats", h.GetShareStats)
+}
+
+// RegisterRedirectRoutes registers the short links themselves. They sit at
+// the root to be as short as they can.
+func (h *ShareLinkHandler) RegisterRedirectRoutes(r gin.IRoutes) {
+	r.GET("/l/:code", h.FollowShareLink)
+}
+
+// CreateShareLink handles getting a listing's short link for a channel
+func (h *ShareLinkHandler) CreateShareLink(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.CreateShareLinkCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.ListingID = listingID
+
+	link, err := h.shareLinkService.CreateShareLink(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// FollowShareLink handles following a short link, redirecting to the
+// listing's page
+func (h *ShareLinkHandler) FollowShareLink(c *gin.Context) {
+	url, err := h.shareLinkService.FollowShareLink(c.Request.Context(), c.Param("code"), c.Request.UserAgent())
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, url)
+}
+
+// GetShareStats handles retrieving the clicks on the share links of one of
+// the caller's listings
+func (h *ShareLinkHandler) GetShareStats(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var query app.SellerAnalyticsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	stats, err := h.shareLinkService.GetShareStats(c.Request.Context(), listingID, auth.UserID(c), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,90 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ShareLinkGORMRepository implements ShareLinkRepository using GORM
+type ShareLinkGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewShareLinkGORMRepository creates a new share link repository
+func NewShareLinkGORMRepository(db *gorm.DB) *ShareLinkGORMRepository {
+	return &ShareLinkGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a share link to the database
+func (r *ShareLinkGORMRepository) Save(link *domain.ShareLink) error {
+	return db.ClassifyError(r.db.Create(link).Error)
+}
+
+// FindByCode finds a share link by code
+func (r *ShareLinkGORMRepository) FindByCode(code string) (*domain.ShareLink, error) {
+	var link domain.ShareLink
+	if err := r.db.Where("code = ?", code).First(&link).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("share link not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &link, nil
+}
+
+// FindByListingAndChannel finds the link of a listing on a channel
+func (r *ShareLinkGORMRepository) FindByListingAndChannel(listingID ids.ListingID, channel domain.ShareChannel) (*domain.ShareLink, error) {
+	var link domain.ShareLink
+	if err := r.db.Where("listing_id = ? AND channel = ?", listingID, channel).First(&link).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("share link not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &link, nil
+}
+
+// FindByListing finds the share links of a listing
+func (r *ShareLinkGORMRepository) FindByListing(listingID ids.ListingID) ([]*domain.ShareLink, error) {
+	var links []*domain.ShareLink
+	if err := r.db.Where("listing_id = ?", listingID).Order("channel").Find(&links).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return links, nil
+}
+
+// RecordClick counts a click on a link for the day in a single upsert
+func (r *ShareLinkGORMRepository) RecordClick(code string, day time.Time, at time.Time) error {
+	clicks := &domain.ShareLinkDailyClicks{Code: code, Day: day, Clicks: 1, UpdatedAt: at}
+	return db.ClassifyError(r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "code"}, {Name: "day"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "clicks"}, Value: gorm.Expr("share_link_daily_clicks.clicks + 1")},
+			{Column: clause.Column{Name: "updated_at"}, Value: at},
+		},
+	}).Create(clicks).Error)
+}
+
+// FindClicks finds the daily clicks on links from one day to another, both included
+func (r *ShareLinkGORMRepository) FindClicks(codes []string, from, to time.Time) ([]*domain.ShareLinkDailyClicks, error) {
+	var clicks []*domain.ShareLinkDailyClicks
+	if len(codes) == 0 {
+		return clicks, nil
+	}
+	err := r.db.Where("code IN ? AND day BETWEEN ? AND ?", codes, from, to).
+		Order("day").
+		Find(&clicks).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return clicks, nil
+}
@@ -0,0 +1,75 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SimilarListingHandler handles HTTP requests for listings similar to another
+type SimilarListingHandler struct {
+	similarService     *app.SimilarListingService
+	translationService *app.TranslationService
+	currencyService    *app.CurrencyService
+}
+
+// NewSimilarListingHandler creates a new similar listing handler. Listings are
+// shown with their category name and attribute labels in the request locale,
+// and with prices in the currency the caller asks for or prefers.
+func NewSimilarListingHandler(similarService *app.SimilarListingService, translationService *app.TranslationService, currencyService *app.CurrencyService) *SimilarListingHandler {
+	return &SimilarListingHandler{
+		similarService:     similarService,
+		translationService: translationService,
+		currencyService:    currencyService,
+	}
+}
+
+// RegisterRoutes registers similar listing routes
+func (h *SimilarListingHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/listings/:id/similar", h.GetSimilarListings)
+}
+
+// GetSimilarListings handles retrieving the listings most similar to an
+// active listing
+func (h *SimilarListingHandler) GetSimilarListings(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var query app.SimilarListingsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	similar, err := h.similarService.GetSimilarListings(c.Request.Context(), listingID, query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	listings := make([]*domain.Listing, 0, len(similar.Listings))
+	for _, listing := range similar.Listings {
+		listings = append(listings, listing.Listing)
+	}
+	h.translationService.LocalizeListings(c.Request.Context(), i18n.FromContext(c), listings...)
+	showDisplayPrices(c, h.currencyService, listings...)
+	c.JSON(http.StatusOK, similar)
+}
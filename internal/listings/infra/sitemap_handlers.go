@@ -0,0 +1,52 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SitemapHandler serves the latest sitemap and listing feeds generated by
+// the worker
+type SitemapHandler struct {
+	sitemapService *app.SitemapService
+}
+
+// NewSitemapHandler creates a new sitemap handler
+func NewSitemapHandler(sitemapService *app.SitemapService) *SitemapHandler {
+	return &SitemapHandler{
+		sitemapService: sitemapService,
+	}
+}
+
+// RegisterRoutes registers the sitemap and feed routes. They are public and
+// sit at the root, where search engines and feed readers look for them.
+func (h *SitemapHandler) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/sitemap.xml", h.serveFile(domain.SitemapFile))
+	r.GET("/feeds/listings.atom", h.serveFile(domain.AtomFeedFile))
+	r.GET("/feeds/listings.json", h.serveFile(domain.JSONFeedFile))
+}
+
+// serveFile handles streaming a file of the latest sitemap version
+func (h *SitemapHandler) serveFile(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		file, contentType, err := h.sitemapService.OpenFile(c.Request.Context(), name)
+		if err != nil {
+			if domainErr, ok := err.(*errors.DomainError); ok {
+				c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+			return
+		}
+		defer file.Close()
+
+		c.Header("Cache-Control", "public, max-age=300")
+		c.DataFromReader(http.StatusOK, -1, contentType, file, nil)
+	}
+}
@@ -0,0 +1,81 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// StagedImageGORMRepository implements StagedImageRepository using GORM
+type StagedImageGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewStagedImageGORMRepository creates a new staged image repository
+func NewStagedImageGORMRepository(db *gorm.DB) *StagedImageGORMRepository {
+	return &StagedImageGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a staged image to the database
+func (r *StagedImageGORMRepository) Save(image *domain.StagedImage) error {
+	return db.ClassifyError(r.db.Create(image).Error)
+}
+
+// FindByIDs finds an owner's staged images by ID. IDs of missing images, or
+// of images belonging to someone else, are left out.
+func (r *StagedImageGORMRepository) FindByIDs(ownerID ids.UserID, imageIDs []string) ([]*domain.StagedImage, error) {
+	var images []*domain.StagedImage
+	err := r.db.Where("owner_id = ? AND id IN ?", ownerID, imageIDs).Find(&images).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return images, nil
+}
+
+// CountPendingByOwner counts an owner's unattached images that have not expired
+func (r *StagedImageGORMRepository) CountPendingByOwner(ownerID ids.UserID, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.StagedImage{}).
+		Where("owner_id = ? AND listing_id IS NULL AND expires_at > ?", ownerID, now).
+		Count(&count).Error
+	if err != nil {
+		return 0, db.ClassifyError(err)
+	}
+	return count, nil
+}
+
+// FindExpired finds unattached images that expired, oldest first
+func (r *StagedImageGORMRepository) FindExpired(now time.Time, limit int) ([]*domain.StagedImage, error) {
+	var images []*domain.StagedImage
+	err := r.db.Where("listing_id IS NULL AND expires_at <= ?", now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&images).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return images, nil
+}
+
+// UpdateAll updates several staged images in a single transaction
+func (r *StagedImageGORMRepository) UpdateAll(images []*domain.StagedImage) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		for _, image := range images {
+			if err := tx.Save(image).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// Delete deletes a staged image from the database
+func (r *StagedImageGORMRepository) Delete(id string) error {
+	return db.ClassifyError(r.db.Delete(&domain.StagedImage{}, "id = ?", id).Error)
+}
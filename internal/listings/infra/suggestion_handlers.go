@@ -0,0 +1,50 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SuggestionHandler handles HTTP requests for listing suggestions
+type SuggestionHandler struct {
+	suggestionService *app.SuggestionService
+}
+
+// NewSuggestionHandler creates a new suggestion handler
+func NewSuggestionHandler(suggestionService *app.SuggestionService) *SuggestionHandler {
+	return &SuggestionHandler{
+		suggestionService: suggestionService,
+	}
+}
+
+// RegisterRoutes registers listing suggestion routes. The group must be
+// protected by RequireAuth.
+func (h *SuggestionHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/listings/suggestions", h.SuggestListing)
+}
+
+// SuggestListing handles suggesting a title and description for a new listing
+func (h *SuggestionHandler) SuggestListing(c *gin.Context) {
+	var cmd app.SuggestListingCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	suggestion, err := h.suggestionService.SuggestListing(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestion)
+}
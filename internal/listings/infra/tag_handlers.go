@@ -0,0 +1,244 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagHandler handles HTTP requests for browsing tags and tagging listings
+type TagHandler struct {
+	tagService *app.TagService
+}
+
+// NewTagHandler creates a new tag handler
+func NewTagHandler(tagService *app.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+// RegisterRoutes registers the public tag routes
+func (h *TagHandler) RegisterRoutes(r *gin.RouterGroup) {
+	tags := r.Group("/tags")
+	{
+		tags.GET("", h.ListTags)
+		tags.GET("/trending", h.TrendingTags)
+	}
+}
+
+// RegisterAuthenticatedRoutes registers the routes sellers tag their
+// listings with. The group must be protected by RequireAuth.
+func (h *TagHandler) RegisterAuthenticatedRoutes(r *gin.RouterGroup) {
+	r.GET("/tags/suggestions", h.SuggestTags)
+	r.PUT("/listings/:id/tags", h.SetListingTags)
+}
+
+// ListTags handles listing tags, most used first
+func (h *TagHandler) ListTags(c *gin.Context) {
+	var query app.ListTagsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	tags, err := h.tagService.ListTags(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// TrendingTags handles listing the tags most used on recent listings
+func (h *TagHandler) TrendingTags(c *gin.Context) {
+	var query app.TrendingTagsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	tags, err := h.tagService.TrendingTags(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// SuggestTags handles suggesting tags for a listing being filled in
+func (h *TagHandler) SuggestTags(c *gin.Context) {
+	var query app.TagSuggestionsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	tags, err := h.tagService.SuggestTags(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// SetListingTags handles a seller replacing the tags of their listing
+func (h *TagHandler) SetListingTags(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.SetListingTagsCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.ListingID = listingID
+	cmd.SellerID = auth.UserID(c)
+
+	listing, err := h.tagService.SetListingTags(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// AdminTagHandler handles HTTP requests for tag administration
+type AdminTagHandler struct {
+	tagService *app.TagService
+}
+
+// NewAdminTagHandler creates a new admin tag handler
+func NewAdminTagHandler(tagService *app.TagService) *AdminTagHandler {
+	return &AdminTagHandler{
+		tagService: tagService,
+	}
+}
+
+// RegisterRoutes registers tag administration routes. The group must be
+// protected by the admin role.
+func (h *AdminTagHandler) RegisterRoutes(r *gin.RouterGroup) {
+	tags := r.Group("/tags")
+	{
+		tags.POST("", h.CreateTag)
+		tags.PUT("/:id", h.UpdateTag)
+		tags.POST("/:id/merge", h.MergeTags)
+		tags.DELETE("/:id", h.DeleteTag)
+	}
+}
+
+// CreateTag handles adding a tag
+func (h *AdminTagHandler) CreateTag(c *gin.Context) {
+	var cmd app.CreateTagCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// UpdateTag handles renaming or recolouring a tag
+func (h *AdminTagHandler) UpdateTag(c *gin.Context) {
+	var cmd app.UpdateTagCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.TagID = c.Param("id")
+
+	tag, err := h.tagService.UpdateTag(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// MergeTags handles folding duplicate tags into the tag of the path
+func (h *AdminTagHandler) MergeTags(c *gin.Context) {
+	var cmd app.MergeTagsCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.TargetID = c.Param("id")
+
+	tag, err := h.tagService.MergeTags(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag handles deleting a tag
+func (h *AdminTagHandler) DeleteTag(c *gin.Context) {
+	if err := h.tagService.DeleteTag(c.Request.Context(), c.Param("id")); err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tag deleted"})
+}
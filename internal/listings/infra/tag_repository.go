@@ -0,0 +1,193 @@
+package infra
+
+import (
+	"strings"
+	"time"
+
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tagCounts selects tags with the number of active listings carrying them
+const tagCounts = "listing_tags.*, COUNT(listings.id) AS listings"
+
+// TagGORMRepository implements TagRepository using GORM
+type TagGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewTagGORMRepository creates a new tag repository
+func NewTagGORMRepository(db *gorm.DB) *TagGORMRepository {
+	return &TagGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a tag to the database
+func (r *TagGORMRepository) Save(tag *domain.ListingTag) error {
+	return db.ClassifyError(r.db.Create(tag).Error)
+}
+
+// FindByID finds a tag by ID
+func (r *TagGORMRepository) FindByID(id string) (*domain.ListingTag, error) {
+	var tag domain.ListingTag
+	err := r.db.First(&tag, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("tag not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &tag, nil
+}
+
+// FindByNames finds the tags with the given normalized names
+func (r *TagGORMRepository) FindByNames(names []string) ([]*domain.ListingTag, error) {
+	var tags []*domain.ListingTag
+	if len(names) == 0 {
+		return tags, nil
+	}
+	if err := r.db.Where("name IN ?", names).Find(&tags).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return tags, nil
+}
+
+// List finds tags whose name starts with prefix, most used first
+func (r *TagGORMRepository) List(prefix string, limit, offset int) ([]*domain.TagCount, int64, error) {
+	var total int64
+	if err := r.db.Model(&domain.ListingTag{}).Scopes(namePrefix(prefix)).Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var tags []*domain.TagCount
+	err := r.db.Table("listing_tags").
+		Select(tagCounts).
+		Joins("LEFT JOIN listing_tag_relations ON listing_tag_relations.listing_tag_id = listing_tags.id").
+		Joins("LEFT JOIN listings ON listings.id = listing_tag_relations.listing_id AND listings.status = ? AND listings.expires_at > ?",
+			domain.ListingStatusActive, time.Now()).
+		Scopes(namePrefix(prefix)).
+		Group("listing_tags.id").
+		Order("listings DESC, listing_tags.name ASC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&tags).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return tags, total, nil
+}
+
+// FindTrending finds the tags most used on active listings created since
+func (r *TagGORMRepository) FindTrending(since time.Time, limit int) ([]*domain.TagCount, error) {
+	var tags []*domain.TagCount
+	err := r.usedOnActiveListings().
+		Where("listings.created_at >= ?", since).
+		Group("listing_tags.id").
+		Order("listings DESC, listing_tags.name ASC").
+		Limit(limit).
+		Scan(&tags).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return tags, nil
+}
+
+// FindPopular finds the tags most used on active listings of a category
+// whose name starts with prefix
+func (r *TagGORMRepository) FindPopular(categoryID, prefix string, limit int) ([]*domain.TagCount, error) {
+	q := r.usedOnActiveListings().Scopes(namePrefix(prefix))
+	if categoryID != "" {
+		q = q.Where("listings.category_id = ?", categoryID)
+	}
+
+	var tags []*domain.TagCount
+	err := q.Group("listing_tags.id").
+		Order("listings DESC, listing_tags.name ASC").
+		Limit(limit).
+		Scan(&tags).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return tags, nil
+}
+
+// FindListingIDs finds the listings carrying a tag
+func (r *TagGORMRepository) FindListingIDs(tagID string) ([]ids.ListingID, error) {
+	var listingIDs []ids.ListingID
+	err := r.db.Table("listing_tag_relations").
+		Where("listing_tag_id = ?", tagID).
+		Pluck("listing_id", &listingIDs).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return listingIDs, nil
+}
+
+// SetListingTags replaces the tags of a listing in a single transaction
+func (r *TagGORMRepository) SetListingTags(listingID ids.ListingID, tagIDs []string) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM listing_tag_relations WHERE listing_id = ?", listingID).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+
+		relations := make([]map[string]interface{}, 0, len(tagIDs))
+		for _, tagID := range tagIDs {
+			relations = append(relations, map[string]interface{}{"listing_id": listingID, "listing_tag_id": tagID})
+		}
+		return tx.Table("listing_tag_relations").Clauses(clause.OnConflict{DoNothing: true}).Create(relations).Error
+	}))
+}
+
+// Update updates a tag in the database
+func (r *TagGORMRepository) Update(tag *domain.ListingTag) error {
+	return db.ClassifyError(r.db.Save(tag).Error)
+}
+
+// Merge moves the listings of the source tags to the target tag and deletes
+// the sources in a single transaction. Listings already carrying the target
+// keep it once.
+func (r *TagGORMRepository) Merge(targetID string, sourceIDs []string) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO listing_tag_relations (listing_id, listing_tag_id)
+			SELECT listing_id, ? FROM listing_tag_relations WHERE listing_tag_id IN ?
+			ON CONFLICT DO NOTHING`, targetID, sourceIDs).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&domain.ListingTag{}, "id IN ?", sourceIDs).Error
+	}))
+}
+
+// Delete deletes a tag from the database, taking it off every listing
+func (r *TagGORMRepository) Delete(id string) error {
+	return db.ClassifyError(r.db.Delete(&domain.ListingTag{}, "id = ?", id).Error)
+}
+
+// usedOnActiveListings selects the tags of active listings with their counts
+func (r *TagGORMRepository) usedOnActiveListings() *gorm.DB {
+	return r.db.Table("listing_tags").
+		Select(tagCounts).
+		Joins("JOIN listing_tag_relations ON listing_tag_relations.listing_tag_id = listing_tags.id").
+		Joins("JOIN listings ON listings.id = listing_tag_relations.listing_id").
+		Where("listings.status = ? AND listings.expires_at > ?", domain.ListingStatusActive, time.Now())
+}
+
+// namePrefix restricts a query to the tags whose name starts with prefix
+func namePrefix(prefix string) func(*gorm.DB) *gorm.DB {
+	return func(q *gorm.DB) *gorm.DB {
+		if prefix == "" {
+			return q
+		}
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix)
+		return q.Where("listing_tags.name LIKE ?", escaped+"%")
+	}
+}
@@ -0,0 +1,217 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"dongome/internal/listings/domain"
+)
+
+// suggestionTemplate drafts listings for the categories named by its keywords
+type suggestionTemplate struct {
+	// keywords match words of a category name, singular and lowercase
+	keywords []string
+	// titleAttributes make up the title, in order
+	titleAttributes []string
+	// attributes are all the attributes the template uses, in order
+	attributes  []string
+	description *template.Template
+}
+
+// suggestionTemplates are checked in order against each category on the
+// path, most specific category first
+var suggestionTemplates = []suggestionTemplate{
+	{
+		keywords:        []string{"phone", "mobile", "smartphone", "tablet"},
+		titleAttributes: []string{"brand", "model", "storage", "color"},
+		attributes:      []string{"brand", "model", "storage", "color", "battery"},
+		description: parseSuggestionTemplate(`{{or .brand "[Brand]"}} {{or .model "[Model]"}} for sale.
+
+Condition: {{or .condition "[Condition]"}}
+Storage: {{or .storage "[Storage]"}}
+Colour: {{or .color "[Colour]"}}
+Battery health: {{or .battery "[Battery health]"}}
+
+Comes with: [Charger, box, case]
+Reason for selling: [Reason]`),
+	},
+	{
+		keywords:        []string{"laptop", "computer", "notebook", "desktop"},
+		titleAttributes: []string{"brand", "model", "processor", "ram", "storage"},
+		attributes:      []string{"brand", "model", "processor", "ram", "storage", "screen"},
+		description: parseSuggestionTemplate(`{{or .brand "[Brand]"}} {{or .model "[Model]"}} for sale.
+
+Condition: {{or .condition "[Condition]"}}
+Processor: {{or .processor "[Processor]"}}
+Memory: {{or .ram "[RAM]"}}
+Storage: {{or .storage "[Storage]"}}
+Screen: {{or .screen "[Screen size]"}}
+
+Comes with: [Charger, bag]
+Reason for selling: [Reason]`),
+	},
+	{
+		keywords:        []string{"car", "vehicle", "motor", "truck"},
+		titleAttributes: []string{"year", "brand", "model", "transmission"},
+		attributes:      []string{"brand", "model", "year", "transmission", "mileage", "fuel"},
+		description: parseSuggestionTemplate(`{{or .year "[Year]"}} {{or .brand "[Make]"}} {{or .model "[Model]"}} for sale.
+
+Condition: {{or .condition "[Condition]"}}
+Transmission: {{or .transmission "[Automatic or manual]"}}
+Mileage: {{or .mileage "[Mileage]"}}
+Fuel: {{or .fuel "[Petrol, diesel or hybrid]"}}
+
+Registered: [Yes or no]
+Service history: [Details]`),
+	},
+	{
+		keywords:        []string{"fashion", "clothing", "clothe", "shoe", "apparel", "bag"},
+		titleAttributes: []string{"brand", "item", "size", "color"},
+		attributes:      []string{"brand", "item", "size", "color", "material"},
+		description: parseSuggestionTemplate(`{{or .brand "[Brand]"}} {{or .item "[Item]"}} for sale.
+
+Condition: {{or .condition "[Condition]"}}
+Size: {{or .size "[Size]"}}
+Colour: {{or .color "[Colour]"}}
+Material: {{or .material "[Material]"}}
+
+Measurements: [Measurements]`),
+	},
+	{
+		keywords:        []string{"furniture"},
+		titleAttributes: []string{"material", "item", "color"},
+		attributes:      []string{"item", "material", "color", "dimensions"},
+		description: parseSuggestionTemplate(`{{or .item "[Item]"}} for sale.
+
+Condition: {{or .condition "[Condition]"}}
+Material: {{or .material "[Material]"}}
+Colour: {{or .color "[Colour]"}}
+Dimensions: {{or .dimensions "[Width x depth x height]"}}
+
+Collection: [Pickup or delivery details]`),
+	},
+	{
+		keywords:        []string{"electronic", "tv", "television", "appliance", "audio"},
+		titleAttributes: []string{"brand", "model", "size"},
+		attributes:      []string{"brand", "model", "size"},
+		description: parseSuggestionTemplate(`{{or .brand "[Brand]"}} {{or .model "[Model]"}} for sale.
+
+Condition: {{or .condition "[Condition]"}}
+Size: {{or .size "[Size]"}}
+
+Comes with: [Remote, cables, box]
+Reason for selling: [Reason]`),
+	},
+}
+
+// genericSuggestionTemplate drafts listings for categories no other template covers
+var genericSuggestionTemplate = suggestionTemplate{
+	titleAttributes: []string{"brand", "model"},
+	attributes:      []string{"brand", "model"},
+	description: parseSuggestionTemplate(`{{or .brand "[Brand]"}} {{or .model "[Item]"}} for sale.
+
+Condition: {{or .condition "[Condition]"}}
+
+Details: [Size, colour, age and anything a buyer should know]
+Reason for selling: [Reason]`),
+}
+
+// parseSuggestionTemplate parses a built-in description template
+func parseSuggestionTemplate(text string) *template.Template {
+	return template.Must(template.New("description").Option("missingkey=zero").Parse(text))
+}
+
+// TemplateSuggester suggests listing titles and descriptions from built-in
+// templates for common categories
+type TemplateSuggester struct{}
+
+// NewTemplateSuggester creates a new template suggester
+func NewTemplateSuggester() *TemplateSuggester {
+	return &TemplateSuggester{}
+}
+
+// Suggest drafts a title and description from the template for the most
+// specific category on the path that has one
+func (s *TemplateSuggester) Suggest(ctx context.Context, req domain.SuggestionRequest) (*domain.ListingSuggestion, error) {
+	tmpl := templateFor(req.CategoryPath)
+
+	data := make(map[string]string, len(req.Attributes)+1)
+	for name, value := range req.Attributes {
+		data[name] = value
+	}
+	if label := domain.ConditionLabel(req.Condition); label != "" {
+		data["condition"] = label
+	}
+
+	var description bytes.Buffer
+	if err := tmpl.description.Execute(&description, data); err != nil {
+		return nil, err
+	}
+
+	missing := []string{}
+	for _, name := range tmpl.attributes {
+		if data[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+
+	return &domain.ListingSuggestion{
+		Title:             suggestedTitle(tmpl, data),
+		Description:       description.String(),
+		MissingAttributes: missing,
+		Source:            "template",
+	}, nil
+}
+
+// templateFor finds the template for the most specific category on the path
+func templateFor(path []string) *suggestionTemplate {
+	for _, name := range path {
+		for _, word := range categoryWords(name) {
+			for i := range suggestionTemplates {
+				for _, keyword := range suggestionTemplates[i].keywords {
+					if word == keyword {
+						return &suggestionTemplates[i]
+					}
+				}
+			}
+		}
+	}
+	return &genericSuggestionTemplate
+}
+
+// categoryWords splits a category name into lowercase singular words, so
+// "Mobile Phones & Tablets" gives mobile, phone and tablet
+func categoryWords(name string) []string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for i, word := range words {
+		if len(word) > 2 && strings.HasSuffix(word, "s") {
+			words[i] = strings.TrimSuffix(word, "s")
+		}
+	}
+	return words
+}
+
+// suggestedTitle joins the title attributes that were given, followed by the
+// condition, keeping within the title length limit
+func suggestedTitle(tmpl *suggestionTemplate, data map[string]string) string {
+	var parts []string
+	for _, name := range tmpl.titleAttributes {
+		if value := data[name]; value != "" {
+			parts = append(parts, value)
+		}
+	}
+	title := strings.Join(parts, " ")
+	if condition := data["condition"]; condition != "" && title != "" {
+		title += " - " + condition
+	}
+
+	if runes := []rune(title); len(runes) > domain.MaxListingTitleLength {
+		title = strings.TrimSpace(string(runes[:domain.MaxListingTitleLength]))
+	}
+	return title
+}
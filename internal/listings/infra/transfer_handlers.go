@@ -0,0 +1,165 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OwnershipTransferHandler handles HTTP requests for listing ownership transfers
+type OwnershipTransferHandler struct {
+	transferService *app.OwnershipTransferService
+}
+
+// NewOwnershipTransferHandler creates a new ownership transfer handler
+func NewOwnershipTransferHandler(transferService *app.OwnershipTransferService) *OwnershipTransferHandler {
+	return &OwnershipTransferHandler{
+		transferService: transferService,
+	}
+}
+
+// RegisterRoutes registers ownership transfer routes. The group must be
+// protected by RequireAuth.
+func (h *OwnershipTransferHandler) RegisterRoutes(r *gin.RouterGroup) {
+	transfers := r.Group("/listing-transfers")
+	{
+		transfers.POST("", h.RequestTransfer)
+		transfers.GET("", h.ListPendingTransfers)
+		transfers.GET("/:id", h.GetTransfer)
+		transfers.POST("/:id/confirm", h.ConfirmTransfer)
+		transfers.POST("/:id/reject", h.RejectTransfer)
+		transfers.POST("/:id/cancel", h.CancelTransfer)
+	}
+
+	r.GET("/listings/:id/ownership-history", h.GetOwnershipHistory)
+}
+
+// RequestTransfer handles proposing a listing or storefront transfer
+func (h *OwnershipTransferHandler) RequestTransfer(c *gin.Context) {
+	var cmd app.RequestTransferCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.RequestedBy = auth.UserID(c)
+	cmd.IsAdmin = auth.Role(c) == auth.RoleAdmin
+
+	transfer, err := h.transferService.RequestTransfer(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// ListPendingTransfers handles listing the caller's pending transfers
+func (h *OwnershipTransferHandler) ListPendingTransfers(c *gin.Context) {
+	transfers, err := h.transferService.ListPendingTransfers(c.Request.Context(), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transfers": transfers})
+}
+
+// GetTransfer handles retrieving a transfer
+func (h *OwnershipTransferHandler) GetTransfer(c *gin.Context) {
+	transfer, err := h.transferService.GetTransfer(c.Request.Context(), c.Param("id"), auth.UserID(c), auth.Role(c) == auth.RoleAdmin)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// ConfirmTransfer handles a seller confirming a transfer
+func (h *OwnershipTransferHandler) ConfirmTransfer(c *gin.Context) {
+	transfer, err := h.transferService.ConfirmTransfer(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// RejectTransfer handles the recipient declining a transfer
+func (h *OwnershipTransferHandler) RejectTransfer(c *gin.Context) {
+	transfer, err := h.transferService.RejectTransfer(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// CancelTransfer handles the current owner withdrawing a transfer
+func (h *OwnershipTransferHandler) CancelTransfer(c *gin.Context) {
+	transfer, err := h.transferService.CancelTransfer(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// GetOwnershipHistory handles retrieving the previous owners of a listing
+func (h *OwnershipTransferHandler) GetOwnershipHistory(c *gin.Context) {
+	listingID, err := ids.ParseListingID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	history, err := h.transferService.GetOwnershipHistory(c.Request.Context(), listingID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"listing_id": listingID, "history": history})
+}
@@ -0,0 +1,74 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// OwnershipTransferGORMRepository implements OwnershipTransferRepository using GORM
+type OwnershipTransferGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewOwnershipTransferGORMRepository creates a new ownership transfer repository
+func NewOwnershipTransferGORMRepository(db *gorm.DB) *OwnershipTransferGORMRepository {
+	return &OwnershipTransferGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves an ownership transfer to the database
+func (r *OwnershipTransferGORMRepository) Save(transfer *domain.OwnershipTransfer) error {
+	return db.ClassifyError(r.db.Create(transfer).Error)
+}
+
+// FindByID finds an ownership transfer by ID
+func (r *OwnershipTransferGORMRepository) FindByID(id string) (*domain.OwnershipTransfer, error) {
+	var transfer domain.OwnershipTransfer
+	err := r.db.First(&transfer, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("transfer not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &transfer, nil
+}
+
+// FindPendingBySeller finds pending transfers sent or received by a seller
+func (r *OwnershipTransferGORMRepository) FindPendingBySeller(sellerID ids.UserID) ([]*domain.OwnershipTransfer, error) {
+	var transfers []*domain.OwnershipTransfer
+	err := r.db.Where("(from_seller_id = ? OR to_seller_id = ?) AND status = ?", sellerID, sellerID, domain.TransferStatusPending).
+		Order("created_at DESC").
+		Find(&transfers).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return transfers, nil
+}
+
+// Update updates an ownership transfer in the database
+func (r *OwnershipTransferGORMRepository) Update(transfer *domain.OwnershipTransfer) error {
+	return db.ClassifyError(r.db.Save(transfer).Error)
+}
+
+// SaveRecord saves an ownership history record to the database
+func (r *OwnershipTransferGORMRepository) SaveRecord(record *domain.OwnershipRecord) error {
+	return db.ClassifyError(r.db.Create(record).Error)
+}
+
+// FindHistory finds the ownership history of a listing, oldest first
+func (r *OwnershipTransferGORMRepository) FindHistory(listingID ids.ListingID) ([]*domain.OwnershipRecord, error) {
+	var records []*domain.OwnershipRecord
+	err := r.db.Where("listing_id = ?", listingID).
+		Order("transferred_at ASC").
+		Find(&records).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return records, nil
+}
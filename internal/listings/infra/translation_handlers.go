@@ -0,0 +1,150 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTranslationHandler handles HTTP requests for translating category
+// names and attribute labels
+type AdminTranslationHandler struct {
+	translationService *app.TranslationService
+}
+
+// NewAdminTranslationHandler creates a new admin translation handler
+func NewAdminTranslationHandler(translationService *app.TranslationService) *AdminTranslationHandler {
+	return &AdminTranslationHandler{
+		translationService: translationService,
+	}
+}
+
+// RegisterRoutes registers translation administration routes. The group must
+// be protected by the admin role.
+func (h *AdminTranslationHandler) RegisterRoutes(r *gin.RouterGroup) {
+	categories := r.Group("/categories/:id/translations")
+	{
+		categories.GET("", h.ListCategoryTranslations)
+		categories.PUT("/:locale", h.SetCategoryTranslation)
+		categories.DELETE("/:locale", h.DeleteCategoryTranslation)
+	}
+
+	labels := r.Group("/attribute-labels")
+	{
+		labels.GET("", h.ListAttributeLabels)
+		labels.PUT("/:key/:locale", h.SetAttributeLabel)
+		labels.DELETE("/:key/:locale", h.DeleteAttributeLabel)
+	}
+}
+
+// ListCategoryTranslations handles listing the translations of a category
+func (h *AdminTranslationHandler) ListCategoryTranslations(c *gin.Context) {
+	translations, err := h.translationService.ListCategoryTranslations(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"translations": translations})
+}
+
+// SetCategoryTranslation handles translating a category into a locale
+func (h *AdminTranslationHandler) SetCategoryTranslation(c *gin.Context) {
+	var cmd app.SetCategoryTranslationCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.CategoryID = c.Param("id")
+	cmd.Locale = c.Param("locale")
+
+	translation, err := h.translationService.SetCategoryTranslation(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+// DeleteCategoryTranslation handles removing a category's translation
+func (h *AdminTranslationHandler) DeleteCategoryTranslation(c *gin.Context) {
+	err := h.translationService.DeleteCategoryTranslation(c.Request.Context(), c.Param("id"), c.Param("locale"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "translation deleted"})
+}
+
+// ListAttributeLabels handles listing attribute labels, optionally in one locale
+func (h *AdminTranslationHandler) ListAttributeLabels(c *gin.Context) {
+	labels, err := h.translationService.ListAttributeLabels(c.Request.Context(), c.Query("locale"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"labels": labels})
+}
+
+// SetAttributeLabel handles labelling an attribute in a locale
+func (h *AdminTranslationHandler) SetAttributeLabel(c *gin.Context) {
+	var cmd app.SetAttributeLabelCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.Key = c.Param("key")
+	cmd.Locale = c.Param("locale")
+
+	label, err := h.translationService.SetAttributeLabel(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, label)
+}
+
+// DeleteAttributeLabel handles removing an attribute's label in a locale
+func (h *AdminTranslationHandler) DeleteAttributeLabel(c *gin.Context) {
+	err := h.translationService.DeleteAttributeLabel(c.Request.Context(), c.Param("key"), c.Param("locale"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "label deleted"})
+}
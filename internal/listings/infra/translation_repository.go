@@ -0,0 +1,79 @@
+package infra
+
+import (
+	"dongome/internal/listings/domain"
+	"dongome/pkg/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TranslationGORMRepository implements TranslationRepository using GORM
+type TranslationGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewTranslationGORMRepository creates a new translation repository
+func NewTranslationGORMRepository(db *gorm.DB) *TranslationGORMRepository {
+	return &TranslationGORMRepository{
+		db: db,
+	}
+}
+
+// FindCategoryTranslations finds the translations of every category into a locale
+func (r *TranslationGORMRepository) FindCategoryTranslations(locale string) ([]*domain.CategoryTranslation, error) {
+	var translations []*domain.CategoryTranslation
+	if err := r.db.Where("locale = ?", locale).Find(&translations).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return translations, nil
+}
+
+// FindCategoryTranslationsByCategory finds the translations of a category ordered by locale
+func (r *TranslationGORMRepository) FindCategoryTranslationsByCategory(categoryID string) ([]*domain.CategoryTranslation, error) {
+	var translations []*domain.CategoryTranslation
+	if err := r.db.Where("category_id = ?", categoryID).Order("locale ASC").Find(&translations).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return translations, nil
+}
+
+// SaveCategoryTranslation creates or replaces a category's translation
+func (r *TranslationGORMRepository) SaveCategoryTranslation(translation *domain.CategoryTranslation) error {
+	return db.ClassifyError(r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "updated_at"}),
+	}).Create(translation).Error)
+}
+
+// DeleteCategoryTranslation deletes a category's translation into a locale
+func (r *TranslationGORMRepository) DeleteCategoryTranslation(categoryID, locale string) error {
+	return db.ClassifyError(r.db.Delete(&domain.CategoryTranslation{}, "category_id = ? AND locale = ?", categoryID, locale).Error)
+}
+
+// FindAttributeLabels finds attribute labels ordered by key, in every locale
+// when locale is empty
+func (r *TranslationGORMRepository) FindAttributeLabels(locale string) ([]*domain.AttributeLabel, error) {
+	query := r.db.Order("key ASC, locale ASC")
+	if locale != "" {
+		query = query.Where("locale = ?", locale)
+	}
+	var labels []*domain.AttributeLabel
+	if err := query.Find(&labels).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return labels, nil
+}
+
+// SaveAttributeLabel creates or replaces an attribute's label
+func (r *TranslationGORMRepository) SaveAttributeLabel(label *domain.AttributeLabel) error {
+	return db.ClassifyError(r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"label", "updated_at"}),
+	}).Create(label).Error)
+}
+
+// DeleteAttributeLabel deletes an attribute's label in a locale
+func (r *TranslationGORMRepository) DeleteAttributeLabel(key, locale string) error {
+	return db.ClassifyError(r.db.Delete(&domain.AttributeLabel{}, "key = ? AND locale = ?", key, locale).Error)
+}
@@ -0,0 +1,63 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/listings/app"
+	"dongome/internal/listings/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrendingListingHandler handles HTTP requests for trending listings
+type TrendingListingHandler struct {
+	trendingService    *app.TrendingListingService
+	translationService *app.TranslationService
+	currencyService    *app.CurrencyService
+}
+
+// NewTrendingListingHandler creates a new trending listing handler. Listings
+// are shown with their category name and attribute labels in the request
+// locale, and with prices in the currency the caller asks for or prefers.
+func NewTrendingListingHandler(trendingService *app.TrendingListingService, translationService *app.TranslationService, currencyService *app.CurrencyService) *TrendingListingHandler {
+	return &TrendingListingHandler{
+		trendingService:    trendingService,
+		translationService: translationService,
+		currencyService:    currencyService,
+	}
+}
+
+// RegisterRoutes registers trending listing routes
+func (h *TrendingListingHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/listings/trending", h.GetTrendingListings)
+}
+
+// GetTrendingListings handles retrieving the hottest active listings
+func (h *TrendingListingHandler) GetTrendingListings(c *gin.Context) {
+	var query app.TrendingListingsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	trending, err := h.trendingService.TrendingListings(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	listings := make([]*domain.Listing, 0, len(trending))
+	for _, listing := range trending {
+		listings = append(listings, listing.Listing)
+	}
+	h.translationService.LocalizeListings(c.Request.Context(), i18n.FromContext(c), listings...)
+	showDisplayPrices(c, h.currencyService, listings...)
+	c.JSON(http.StatusOK, gin.H{"listings": trending})
+}
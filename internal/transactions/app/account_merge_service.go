@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// AccountMergeResult reports what a merge moved to the primary account
+type AccountMergeResult struct {
+	OrdersMoved int64       `json:"orders_moved"`
+	WalletMoved money.Money `json:"wallet_moved"`
+}
+
+// AccountMergeService consolidates the transactions of a duplicate account
+// into the account it was merged into
+type AccountMergeService struct {
+	mergeRepo  domain.AccountMergeRepository
+	walletRepo domain.WalletRepository
+	clock      clock.Clock
+}
+
+// NewAccountMergeService creates a new account merge service. clk may be nil
+// to use the system clock.
+func NewAccountMergeService(mergeRepo domain.AccountMergeRepository, walletRepo domain.WalletRepository, clk clock.Clock) *AccountMergeService {
+	return &AccountMergeService{
+		mergeRepo:  mergeRepo,
+		walletRepo: walletRepo,
+		clock:      clock.OrSystem(clk),
+	}
+}
+
+// MergeAccounts moves the duplicate account's orders and everything hanging
+// off them to the primary account, and its wallet balance into the primary
+// account's wallet. mergeID identifies the merge, so running it again, as
+// when its event is redelivered, moves only what is left.
+func (s *AccountMergeService) MergeAccounts(ctx context.Context, mergeID string, duplicateID, primaryID ids.UserID) (*AccountMergeResult, error) {
+	if duplicateID == primaryID {
+		return nil, errors.ValidationError("cannot merge a user into itself")
+	}
+
+	var orders int64
+	err := db.WithRetry(ctx, func() error {
+		var err error
+		orders, err = s.mergeRepo.ReassignUser(duplicateID, primaryID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	moved, err := s.moveWallet(ctx, mergeID, duplicateID, primaryID)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountMergeResult{OrdersMoved: orders, WalletMoved: moved}, nil
+}
+
+// moveWallet moves the duplicate account's balance to the primary account,
+// opening a wallet for it if it has none
+func (s *AccountMergeService) moveWallet(ctx context.Context, mergeID string, duplicateID, primaryID ids.UserID) (money.Money, error) {
+	moved := money.New(0, money.DefaultCurrency)
+	if _, err := s.walletRepo.FindByUser(duplicateID); err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return moved, nil
+		}
+		return moved, err
+	}
+
+	now := s.clock.Now()
+	err := db.WithRetry(ctx, func() error {
+		if _, err := s.walletRepo.Open(domain.NewWallet(primaryID, now)); err != nil {
+			return err
+		}
+		return s.walletRepo.Transfer(duplicateID, primaryID, func(from, to *domain.Wallet) ([]*domain.WalletEntry, error) {
+			entries, err := from.MoveBalanceTo(to, domain.WalletEntryAccountMerge, mergeID, now)
+			if err != nil {
+				return nil, err
+			}
+			if len(entries) > 0 {
+				moved = entries[1].Amount
+			}
+			return entries, nil
+		})
+	})
+	if err != nil {
+		// The balance was moved when this merge ran before
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeConflict {
+			return money.New(0, money.DefaultCurrency), nil
+		}
+		return moved, err
+	}
+	return moved, nil
+}
@@ -0,0 +1,98 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountMergeService_MovesOrdersAndWalletBalance(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	orders := newFakeOrderRepository()
+	escrows := newFakeEscrowRepository()
+	wallets := newFakeWalletRepository()
+	service := app.NewAccountMergeService(&fakeAccountMergeRepository{orders: orders, escrows: escrows}, wallets, clock.NewFrozen(now))
+
+	bought := placedOrder(t, orders, "duplicate", "seller-a", now)
+	sold := placedOrder(t, orders, "buyer-a", "duplicate", now)
+	require.NoError(t, sold.MarkPaid(now))
+	escrow, err := domain.NewEscrow(sold, 14*24*time.Hour, now)
+	require.NoError(t, err)
+	require.NoError(t, escrows.Save(escrow))
+	other := placedOrder(t, orders, "buyer-a", "seller-a", now)
+
+	_, err = wallets.Open(domain.NewWallet("duplicate", now))
+	require.NoError(t, err)
+	_, err = wallets.Post("duplicate", func(wallet *domain.Wallet) (*domain.WalletEntry, error) {
+		return wallet.Credit(domain.WalletEntryTopUp, money.Cedis(40), "transfer-1", now)
+	})
+	require.NoError(t, err)
+
+	result, err := service.MergeAccounts(ctx, "merge-1", "duplicate", "primary")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.OrdersMoved)
+	assert.Equal(t, money.Cedis(40), result.WalletMoved)
+
+	// The duplicate's orders, as buyer and as seller, belong to the primary account
+	assert.Equal(t, "primary", bought.BuyerID.String())
+	assert.Equal(t, "primary", sold.SellerID.String())
+	assert.Equal(t, "primary", escrow.SellerID.String())
+	assert.Equal(t, "seller-a", other.SellerID.String())
+
+	// The balance moved to a wallet opened for the primary account
+	primary, err := wallets.FindByUser("primary")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(40), primary.Balance)
+	duplicate, err := wallets.FindByUser("duplicate")
+	require.NoError(t, err)
+	assert.True(t, duplicate.Balance.IsZero())
+
+	// Redelivering the merge moves nothing twice
+	result, err = service.MergeAccounts(ctx, "merge-1", "duplicate", "primary")
+	require.NoError(t, err)
+	assert.Zero(t, result.OrdersMoved)
+	assert.True(t, result.WalletMoved.IsZero())
+	primary, err = wallets.FindByUser("primary")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(40), primary.Balance)
+}
+
+func TestAccountMergeService_AddsToThePrimaryWallet(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	orders := newFakeOrderRepository()
+	wallets := newFakeWalletRepository()
+	service := app.NewAccountMergeService(&fakeAccountMergeRepository{orders: orders, escrows: newFakeEscrowRepository()}, wallets, clock.NewFrozen(now))
+
+	for userID, amount := range map[ids.UserID]float64{"duplicate": 25, "primary": 10} {
+		_, err := wallets.Open(domain.NewWallet(userID, now))
+		require.NoError(t, err)
+		_, err = wallets.Post(userID, func(wallet *domain.Wallet) (*domain.WalletEntry, error) {
+			return wallet.Credit(domain.WalletEntryTopUp, money.Cedis(amount), "transfer-"+userID.String(), now)
+		})
+		require.NoError(t, err)
+	}
+
+	_, err := service.MergeAccounts(context.Background(), "merge-1", "duplicate", "primary")
+	require.NoError(t, err)
+	primary, err := wallets.FindByUser("primary")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(35), primary.Balance)
+
+	// Accounts without a wallet have nothing to move
+	result, err := service.MergeAccounts(context.Background(), "merge-2", "no-wallet", "primary")
+	require.NoError(t, err)
+	assert.True(t, result.WalletMoved.IsZero())
+
+	_, err = service.MergeAccounts(context.Background(), "merge-3", "primary", "primary")
+	assert.Error(t, err)
+}
@@ -0,0 +1,380 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+	"dongome/pkg/payments"
+)
+
+// defaultClaimPageSize is how many claims are listed when no limit is given
+const defaultClaimPageSize = 20
+
+// OpenClaimCommand represents an administrator opening a claim for a
+// reversal the payment provider reported outside its webhooks, such as a
+// MoMo reversal. No amount claims the whole payment.
+type OpenClaimCommand struct {
+	AdminID       ids.UserID  `json:"-"`
+	OrderID       ids.OrderID `json:"order_id" binding:"required"`
+	Amount        float64     `json:"amount" binding:"omitempty,gt=0"`
+	Reason        string      `json:"reason" binding:"required,max=500"`
+	EvidenceDueAt *time.Time  `json:"evidence_due_at"`
+}
+
+// SubmitEvidenceCommand represents a seller contesting a claim against one
+// of their orders
+type SubmitEvidenceCommand struct {
+	ClaimID     string     `json:"-"`
+	SellerID    ids.UserID `json:"-"`
+	Description string     `json:"description" binding:"required,max=2000"`
+	Attachments []string   `json:"attachments" binding:"omitempty,max=5,dive,url,max=500"`
+}
+
+// ResolveClaimCommand represents an administrator deciding a claim
+type ResolveClaimCommand struct {
+	ClaimID    string     `json:"-"`
+	AdminID    ids.UserID `json:"-"`
+	Status     string     `json:"status" binding:"required,oneof=won lost"`
+	Resolution string     `json:"resolution" binding:"max=500"`
+}
+
+// ClaimsQuery represents the query to list claims
+type ClaimsQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=open won lost"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// Claims represents a page of claims
+type Claims struct {
+	Claims []*domain.Claim `json:"claims"`
+	Total  int64           `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+// ClaimService tracks buyers' claims against orders, from the chargeback the
+// payment provider reports to its resolution. The order's funds are frozen
+// in escrow, and so kept out of the seller's payouts, while any of its
+// claims is open.
+type ClaimService struct {
+	claimRepo   domain.ClaimRepository
+	orderRepo   domain.OrderRepository
+	escrowRepo  domain.EscrowRepository
+	refundRepo  domain.RefundRepository
+	preferences NotificationPreferences
+	eventBus    events.EventBus
+	clock       clock.Clock
+}
+
+// NewClaimService creates a new claim service. clk may be nil to use the
+// system clock.
+func NewClaimService(claimRepo domain.ClaimRepository, orderRepo domain.OrderRepository, escrowRepo domain.EscrowRepository, refundRepo domain.RefundRepository, preferences NotificationPreferences, eventBus events.EventBus, clk clock.Clock) *ClaimService {
+	return &ClaimService{
+		claimRepo:   claimRepo,
+		orderRepo:   orderRepo,
+		escrowRepo:  escrowRepo,
+		refundRepo:  refundRepo,
+		preferences: preferences,
+		eventBus:    eventBus,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// HandleChargeback applies a chargeback from a provider's webhook whose
+// authenticity has been checked; the event must carry one. The first event
+// about a dispute opens its claim, and later ones move the evidence deadline
+// or resolve it. Events are applied once: repeated events for a resolved
+// claim change nothing.
+func (s *ClaimService) HandleChargeback(ctx context.Context, event payments.Event) (*domain.Claim, error) {
+	chargeback := event.Chargeback
+	claim, err := s.claimRepo.FindByReference(event.Provider, chargeback.ID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeNotFound {
+			return nil, err
+		}
+		orderID, err := ids.ParseOrderID(event.Reference)
+		if err != nil {
+			return nil, err
+		}
+		order, err := s.orderRepo.FindByID(orderID)
+		if err != nil {
+			return nil, err
+		}
+		claim, err = s.open(ctx, order, event.Provider, chargeback.ID, chargeback.Amount, chargeback.Reason, domain.ClaimActorProvider, chargeback.EvidenceDueAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !claim.IsOpen() {
+		return claim, nil
+	}
+
+	switch chargeback.Status {
+	case payments.ChargebackWon:
+		return s.resolve(ctx, claim, domain.ClaimStatusWon, domain.ClaimActorProvider, "")
+	case payments.ChargebackLost:
+		return s.resolve(ctx, claim, domain.ClaimStatusLost, domain.ClaimActorProvider, "")
+	}
+	if due := chargeback.EvidenceDueAt; due != nil && (claim.EvidenceDueAt == nil || !due.Equal(*claim.EvidenceDueAt)) {
+		claim.ExtendEvidenceDue(*due, s.clock.Now())
+		if err := db.WithRetry(ctx, func() error { return s.claimRepo.Update(claim) }); err != nil {
+			return nil, err
+		}
+	}
+	return claim, nil
+}
+
+// OpenClaim opens a claim an administrator was told of by the payment
+// provider
+func (s *ClaimService) OpenClaim(ctx context.Context, cmd OpenClaimCommand) (*domain.Claim, error) {
+	orderID, err := ids.ParseOrderID(cmd.OrderID.String())
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var amount money.Money
+	if cmd.Amount != 0 {
+		// Amounts are read exactly, so that one too small to claim does not
+		// freeze the whole payment
+		if amount, err = money.ParseMajor(strconv.FormatFloat(cmd.Amount, 'f', -1, 64), order.Amount.Currency); err != nil {
+			return nil, err
+		}
+		if !amount.IsPositive() {
+			return nil, errors.ValidationError("claim amount must be positive")
+		}
+	}
+	return s.open(ctx, order, order.PaymentProvider, "", amount, cmd.Reason, cmd.AdminID.String(), cmd.EvidenceDueAt)
+}
+
+// SubmitEvidence records the seller's evidence against an open claim
+func (s *ClaimService) SubmitEvidence(ctx context.Context, cmd SubmitEvidenceCommand) (*domain.Claim, error) {
+	claim, err := s.GetPartyClaim(ctx, cmd.ClaimID, cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := claim.SubmitEvidence(cmd.SellerID, cmd.Description, cmd.Attachments, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.claimRepo.Update(claim) }); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// ResolveClaim records an administrator's decision on an open claim
+func (s *ClaimService) ResolveClaim(ctx context.Context, cmd ResolveClaimCommand) (*domain.Claim, error) {
+	claim, err := s.claimRepo.FindByID(cmd.ClaimID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolve(ctx, claim, domain.ClaimStatus(cmd.Status), cmd.AdminID.String(), cmd.Resolution)
+}
+
+// GetClaim returns a claim to an administrator
+func (s *ClaimService) GetClaim(ctx context.Context, claimID string) (*domain.Claim, error) {
+	return s.claimRepo.FindByID(claimID)
+}
+
+// GetPartyClaim returns a claim to the buyer or seller of its order
+func (s *ClaimService) GetPartyClaim(ctx context.Context, claimID string, userID ids.UserID) (*domain.Claim, error) {
+	claim, err := s.claimRepo.FindByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+	// Other users are told the claim does not exist rather than that it is not theirs
+	if !claim.IsParty(userID) {
+		return nil, errors.NotFoundError("claim not found")
+	}
+	return claim, nil
+}
+
+// ListClaims returns a page of claims, newest first
+func (s *ClaimService) ListClaims(ctx context.Context, query ClaimsQuery) (*Claims, error) {
+	return s.list(domain.ClaimFilter{Status: domain.ClaimStatus(query.Status)}, query)
+}
+
+// ListSellerClaims returns a page of the claims against a seller's orders,
+// newest first
+func (s *ClaimService) ListSellerClaims(ctx context.Context, sellerID ids.UserID, query ClaimsQuery) (*Claims, error) {
+	return s.list(domain.ClaimFilter{Status: domain.ClaimStatus(query.Status), SellerID: sellerID}, query)
+}
+
+func (s *ClaimService) list(filter domain.ClaimFilter, query ClaimsQuery) (*Claims, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultClaimPageSize
+	}
+	claims, total, err := s.claimRepo.FindAll(filter, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &Claims{Claims: claims, Total: total, Limit: limit, Offset: query.Offset}, nil
+}
+
+// open saves a new claim against a paid order, freezes the order's funds
+// and tells the seller
+func (s *ClaimService) open(ctx context.Context, order *domain.Order, provider, reference string, amount money.Money, reason, openedBy string, evidenceDueAt *time.Time) (*domain.Claim, error) {
+	claim, err := domain.NewClaim(order, provider, reference, amount, reason, openedBy, evidenceDueAt, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.claimRepo.Save(claim) }); err != nil {
+		return nil, err
+	}
+	if err := s.freeze(ctx, order.ID); err != nil {
+		return nil, err
+	}
+	if err := s.publishOpened(ctx, claim); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// freeze freezes the funds of an order held or released in escrow. Orders
+// whose funds were refunded already have nothing left to freeze.
+func (s *ClaimService) freeze(ctx context.Context, orderID ids.OrderID) error {
+	escrow, err := s.escrowRepo.FindByOrder(orderID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return nil
+		}
+		return err
+	}
+	if escrow.Frozen() || !escrow.Freeze(s.clock.Now()) {
+		return nil
+	}
+	return db.WithRetry(ctx, func() error { return s.escrowRepo.Update(escrow) })
+}
+
+// resolve records the decision on an open claim. The amount of a lost claim
+// is recorded as refunded, since the provider already sent it back to the
+// buyer; the order's funds are unfrozen once none of its claims is open.
+func (s *ClaimService) resolve(ctx context.Context, claim *domain.Claim, status domain.ClaimStatus, resolvedBy, resolution string) (*domain.Claim, error) {
+	if err := claim.Resolve(status, resolvedBy, resolution, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if status == domain.ClaimStatusLost {
+		order, err := s.orderRepo.FindByID(claim.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		refunds, err := s.refundRepo.FindByOrder(order.ID)
+		if err != nil {
+			return nil, err
+		}
+		refund, err := domain.NewChargebackRefund(order, refunds, claim, s.clock.Now())
+		if err != nil {
+			return nil, err
+		}
+		if refund != nil {
+			if err := db.WithRetry(ctx, func() error { return s.refundRepo.Save(refund) }); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := db.WithRetry(ctx, func() error { return s.claimRepo.Update(claim) }); err != nil {
+		return nil, err
+	}
+	if err := s.unfreeze(ctx, claim.OrderID); err != nil {
+		return nil, err
+	}
+	if err := s.publishResolved(ctx, claim); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// unfreeze unfreezes the funds of an order none of whose claims is open
+func (s *ClaimService) unfreeze(ctx context.Context, orderID ids.OrderID) error {
+	claims, err := s.claimRepo.FindByOrder(orderID)
+	if err != nil {
+		return err
+	}
+	for _, claim := range claims {
+		if claim.IsOpen() {
+			return nil
+		}
+	}
+
+	escrow, err := s.escrowRepo.FindByOrder(orderID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return nil
+		}
+		return err
+	}
+	if !escrow.Frozen() {
+		return nil
+	}
+	escrow.Unfreeze(s.clock.Now())
+	return db.WithRetry(ctx, func() error { return s.escrowRepo.Update(escrow) })
+}
+
+// publishOpened tells the seller of a claim against their order
+func (s *ClaimService) publishOpened(ctx context.Context, claim *domain.Claim) error {
+	channels, err := s.preferences.NotificationChannels(ctx, claim.SellerID, orderUpdatesCategory)
+	if err != nil {
+		return err
+	}
+
+	event, err := events.NewEvent(
+		domain.ClaimOpenedEvent,
+		claim.OrderID.String(),
+		domain.ClaimOpened{
+			ClaimID:       claim.ID,
+			OrderID:       claim.OrderID,
+			BuyerID:       claim.BuyerID,
+			SellerID:      claim.SellerID,
+			Amount:        claim.Amount,
+			Reason:        claim.Reason,
+			EvidenceDueAt: claim.EvidenceDueAt,
+			Channels:      channels,
+			Timestamp:     s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return s.eventBus.Publish(ctx, event)
+}
+
+// publishResolved tells the seller of the decision on a claim
+func (s *ClaimService) publishResolved(ctx context.Context, claim *domain.Claim) error {
+	channels, err := s.preferences.NotificationChannels(ctx, claim.SellerID, orderUpdatesCategory)
+	if err != nil {
+		return err
+	}
+
+	event, err := events.NewEvent(
+		domain.ClaimResolvedEvent,
+		claim.OrderID.String(),
+		domain.ClaimResolved{
+			ClaimID:    claim.ID,
+			OrderID:    claim.OrderID,
+			SellerID:   claim.SellerID,
+			Status:     claim.Status,
+			Amount:     claim.Amount,
+			Resolution: claim.Resolution,
+			Channels:   channels,
+			Timestamp:  s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return s.eventBus.Publish(ctx, event)
+}
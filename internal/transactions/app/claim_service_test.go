@@ -0,0 +1,122 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/money"
+	"dongome/pkg/payments"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimService_ChargebackFreezesPayout(t *testing.T) {
+	f := newPayoutFixture()
+	ctx := context.Background()
+	_, err := f.service.RegisterAccount(ctx, app.RegisterPayoutAccountCommand{SellerID: "seller-a", Phone: "+233241234567"})
+	require.NoError(t, err)
+	order := f.releasedOrder(t)
+
+	dueAt := f.clock.Now().Add(7 * 24 * time.Hour)
+	chargeback := payments.Event{
+		Provider:  payments.ProviderMoMo,
+		Reference: order.ID.String(),
+		Chargeback: &payments.Chargeback{
+			ID:            "dispute-1",
+			Status:        payments.ChargebackOpen,
+			Amount:        money.Cedis(50),
+			Reason:        "item not received",
+			EvidenceDueAt: &dueAt,
+		},
+	}
+	claim, err := f.claims.HandleChargeback(ctx, chargeback)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ClaimStatusOpen, claim.Status)
+	assert.Equal(t, "seller-a", claim.SellerID.String())
+	require.Len(t, f.eventBus.eventsOfType(domain.ClaimOpenedEvent), 1, "the seller is told of the claim")
+	escrow, err := f.escrows.GetEscrow(ctx, order.ID, "seller-a")
+	require.NoError(t, err)
+	assert.True(t, escrow.Frozen())
+
+	// The provider moves the deadline; the dispute is claimed once
+	later := dueAt.Add(48 * time.Hour)
+	chargeback.Chargeback.EvidenceDueAt = &later
+	again, err := f.claims.HandleChargeback(ctx, chargeback)
+	require.NoError(t, err)
+	assert.Equal(t, claim.ID, again.ID)
+	assert.Equal(t, later, *again.EvidenceDueAt)
+	assert.Len(t, f.eventBus.eventsOfType(domain.ClaimOpenedEvent), 1)
+
+	f.clock.Advance(15 * time.Hour)
+	count, err := f.service.RunPayouts(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count, "frozen funds are not paid out")
+
+	_, err = f.claims.SubmitEvidence(ctx, app.SubmitEvidenceCommand{ClaimID: claim.ID, SellerID: "buyer-a", Description: "I never got it"})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeForbidden, err.(*errors.DomainError).Code)
+	claim, err = f.claims.SubmitEvidence(ctx, app.SubmitEvidenceCommand{ClaimID: claim.ID, SellerID: "seller-a", Description: "Delivered on Monday", Attachments: []string{"https://example.com/receipt.jpg"}})
+	require.NoError(t, err)
+	require.Len(t, claim.Evidence, 1)
+
+	// A lost chargeback is taken off the seller's funds, which are paid out
+	chargeback.Chargeback.Status = payments.ChargebackLost
+	claim, err = f.claims.HandleChargeback(ctx, chargeback)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ClaimStatusLost, claim.Status)
+	assert.Equal(t, domain.ClaimActorProvider, claim.ResolvedBy)
+	_, err = f.claims.HandleChargeback(ctx, chargeback)
+	require.NoError(t, err)
+	assert.Len(t, f.eventBus.eventsOfType(domain.ClaimResolvedEvent), 1, "repeated events change nothing")
+
+	count, err = f.service.RunPayouts(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.Len(t, f.gateway.payouts, 1)
+	assert.Equal(t, money.Cedis(50), f.gateway.payouts[0].Amount, "the chargeback was sent back to the buyer")
+}
+
+func TestClaimService_AdminResolvesClaim(t *testing.T) {
+	f := newPayoutFixture()
+	ctx := context.Background()
+	order := f.releasedOrder(t)
+
+	_, err := f.claims.OpenClaim(ctx, app.OpenClaimCommand{AdminID: "admin-a", OrderID: order.ID, Amount: 500, Reason: "MoMo reversal"})
+	require.Error(t, err, "claims are at most the order amount")
+	assert.Equal(t, errors.ErrCodeValidation, err.(*errors.DomainError).Code)
+	_, err = f.claims.OpenClaim(ctx, app.OpenClaimCommand{AdminID: "admin-a", OrderID: order.ID, Amount: 0.001, Reason: "MoMo reversal"})
+	require.Error(t, err, "amounts too small to claim are not taken for the whole payment")
+	assert.Equal(t, errors.ErrCodeValidation, err.(*errors.DomainError).Code)
+	claim, err := f.claims.OpenClaim(ctx, app.OpenClaimCommand{AdminID: "admin-a", OrderID: order.ID, Reason: "MoMo reversal"})
+	require.NoError(t, err)
+	assert.Equal(t, order.Amount, claim.Amount, "claims are for the whole payment by default")
+	assert.Equal(t, "admin-a", claim.OpenedBy)
+
+	claims, err := f.claims.ListSellerClaims(ctx, "seller-a", app.ClaimsQuery{Status: "open"})
+	require.NoError(t, err)
+	require.Len(t, claims.Claims, 1)
+	_, err = f.claims.GetPartyClaim(ctx, claim.ID, "buyer-a")
+	require.NoError(t, err)
+	_, err = f.claims.GetPartyClaim(ctx, claim.ID, "seller-b")
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeNotFound, err.(*errors.DomainError).Code)
+
+	claim, err = f.claims.ResolveClaim(ctx, app.ResolveClaimCommand{ClaimID: claim.ID, AdminID: "admin-a", Status: "won", Resolution: "delivery confirmed"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ClaimStatusWon, claim.Status)
+	escrow, err := f.escrows.GetEscrow(ctx, order.ID, "seller-a")
+	require.NoError(t, err)
+	assert.False(t, escrow.Frozen(), "the funds go on to the seller")
+	refunds, err := f.refunds.ListRefunds(ctx, order.ID, "seller-a")
+	require.NoError(t, err)
+	assert.Empty(t, refunds)
+
+	_, err = f.claims.ResolveClaim(ctx, app.ResolveClaimCommand{ClaimID: claim.ID, AdminID: "admin-a", Status: "lost"})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeConflict, err.(*errors.DomainError).Code)
+}
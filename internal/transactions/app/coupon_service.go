@@ -0,0 +1,259 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// defaultCouponPageSize is how many coupons or redemptions are listed when
+// no limit is given
+const defaultCouponPageSize = 20
+
+// CheckoutCoupons redeems the coupons buyers enter at checkout. It is
+// implemented by CouponService.
+type CheckoutCoupons interface {
+	RedeemCoupon(ctx context.Context, code string, order *domain.Order) (*domain.CouponRedemption, error)
+	ReleaseCoupon(ctx context.Context, orderID ids.OrderID) error
+}
+
+// CouponTermsCommand represents the terms an administrator gives a coupon.
+// Percentage coupons take Percent percent off; fixed ones take AmountOff of
+// Currency, cedis when none is given.
+type CouponTermsCommand struct {
+	Description            string     `json:"description" binding:"max=500"`
+	Kind                   string     `json:"kind" binding:"required,oneof=percentage fixed"`
+	Percent                int        `json:"percent" binding:"required_if=Kind percentage,omitempty,min=1,max=99"`
+	AmountOff              float64    `json:"amount_off" binding:"required_if=Kind fixed,omitempty,gt=0"`
+	Currency               string     `json:"currency" binding:"omitempty,oneof=GHS USD EUR GBP NGN"`
+	CategoryID             string     `json:"category_id" binding:"omitempty,uuid"`
+	SellerID               string     `json:"seller_id" binding:"omitempty,uuid"`
+	MaxRedemptions         int        `json:"max_redemptions" binding:"omitempty,min=0"`
+	MaxRedemptionsPerBuyer int        `json:"max_redemptions_per_buyer" binding:"omitempty,min=0"`
+	StartsAt               *time.Time `json:"starts_at"`
+	ExpiresAt              *time.Time `json:"expires_at"`
+}
+
+// CreateCouponCommand represents an administrator creating a coupon
+type CreateCouponCommand struct {
+	AdminID ids.UserID `json:"-"`
+	Code    string     `json:"code" binding:"required,min=3,max=32"`
+	CouponTermsCommand
+}
+
+// UpdateCouponCommand represents an administrator replacing a coupon's
+// terms, and starting or stopping it being accepted when Active is given
+type UpdateCouponCommand struct {
+	CouponID string `json:"-"`
+	Active   *bool  `json:"active"`
+	CouponTermsCommand
+}
+
+// CouponsQuery represents the query to list coupons or a coupon's redemptions
+type CouponsQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// Coupons represents a page of coupons
+type Coupons struct {
+	Coupons []*domain.Coupon `json:"coupons"`
+	Total   int64            `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+}
+
+// CouponRedemptions represents a page of a coupon's redemptions
+type CouponRedemptions struct {
+	Redemptions []*domain.CouponRedemption `json:"redemptions"`
+	Total       int64                      `json:"total"`
+	Limit       int                        `json:"limit"`
+	Offset      int                        `json:"offset"`
+}
+
+// CouponService handles promo codes: administrators managing them, and
+// redeeming them on orders at checkout
+type CouponService struct {
+	couponRepo domain.CouponRepository
+	clock      clock.Clock
+}
+
+// NewCouponService creates a new coupon service. clk may be nil to use the
+// system clock.
+func NewCouponService(couponRepo domain.CouponRepository, clk clock.Clock) *CouponService {
+	return &CouponService{
+		couponRepo: couponRepo,
+		clock:      clock.OrSystem(clk),
+	}
+}
+
+// CreateCoupon creates a coupon, accepted at checkout from when it starts
+func (s *CouponService) CreateCoupon(ctx context.Context, cmd CreateCouponCommand) (*domain.Coupon, error) {
+	if _, err := s.couponRepo.FindByCode(domain.NormalizeCouponCode(cmd.Code)); err == nil {
+		return nil, errors.ConflictError("a coupon with this code already exists")
+	} else if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeNotFound {
+		return nil, err
+	}
+
+	terms, err := cmd.terms()
+	if err != nil {
+		return nil, err
+	}
+	coupon, err := domain.NewCoupon(cmd.Code, terms, cmd.AdminID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.couponRepo.Save(coupon) }); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// ListCoupons lists coupons, newest first
+func (s *CouponService) ListCoupons(ctx context.Context, query CouponsQuery) (*Coupons, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultCouponPageSize
+	}
+
+	coupons, total, err := s.couponRepo.FindAll(limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &Coupons{
+		Coupons: coupons,
+		Total:   total,
+		Limit:   limit,
+		Offset:  query.Offset,
+	}, nil
+}
+
+// GetCoupon retrieves a coupon
+func (s *CouponService) GetCoupon(ctx context.Context, couponID string) (*domain.Coupon, error) {
+	return s.couponRepo.FindByID(couponID)
+}
+
+// UpdateCoupon replaces a coupon's terms. Orders already using it keep the
+// discount they got.
+func (s *CouponService) UpdateCoupon(ctx context.Context, cmd UpdateCouponCommand) (*domain.Coupon, error) {
+	coupon, err := s.couponRepo.FindByID(cmd.CouponID)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := cmd.terms()
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	if err := coupon.Revise(terms, now); err != nil {
+		return nil, err
+	}
+	if cmd.Active != nil {
+		coupon.SetActive(*cmd.Active, now)
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.couponRepo.Update(coupon) }); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// DeleteCoupon deletes a coupon no order ever used. Used coupons are kept
+// for their redemptions' sake and can be deactivated instead.
+func (s *CouponService) DeleteCoupon(ctx context.Context, couponID string) error {
+	return db.WithRetry(ctx, func() error { return s.couponRepo.Delete(couponID) })
+}
+
+// ListRedemptions lists the orders that used a coupon, newest first,
+// including released redemptions
+func (s *CouponService) ListRedemptions(ctx context.Context, couponID string, query CouponsQuery) (*CouponRedemptions, error) {
+	if _, err := s.couponRepo.FindByID(couponID); err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultCouponPageSize
+	}
+
+	redemptions, total, err := s.couponRepo.FindRedemptions(couponID, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &CouponRedemptions{
+		Redemptions: redemptions,
+		Total:       total,
+		Limit:       limit,
+		Offset:      query.Offset,
+	}, nil
+}
+
+// RedeemCoupon counts an order as using the coupon with the code, and
+// returns the redemption with the discount the order gets. Unknown codes are
+// reported like inactive coupons, so codes cannot be guessed from the error.
+func (s *CouponService) RedeemCoupon(ctx context.Context, code string, order *domain.Order) (*domain.CouponRedemption, error) {
+	var redemption *domain.CouponRedemption
+	err := db.WithRetry(ctx, func() error {
+		var err error
+		redemption, err = s.couponRepo.Redeem(domain.NormalizeCouponCode(code), order.ID, order.BuyerID, func(coupon *domain.Coupon, buyerRedemptions int) (*domain.CouponRedemption, error) {
+			return coupon.Redeem(order, buyerRedemptions, s.clock.Now())
+		})
+		return err
+	})
+	if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+		return nil, errors.ValidationError("the coupon code is not valid")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
+
+// ReleaseCoupon stops counting an order abandoned or cancelled before it was
+// paid as using its coupon, if it had one
+func (s *CouponService) ReleaseCoupon(ctx context.Context, orderID ids.OrderID) error {
+	return db.WithRetry(ctx, func() error {
+		return s.couponRepo.Release(orderID, func(coupon *domain.Coupon, redemption *domain.CouponRedemption) bool {
+			return coupon.Release(redemption, s.clock.Now())
+		})
+	})
+}
+
+// terms converts the command into a coupon's terms. The amount off is read
+// exactly, so buyers get the discount the coupon advertises.
+func (cmd CouponTermsCommand) terms() (domain.CouponTerms, error) {
+	terms := domain.CouponTerms{
+		Description:            cmd.Description,
+		Kind:                   domain.CouponKind(cmd.Kind),
+		Percent:                cmd.Percent,
+		MaxRedemptions:         cmd.MaxRedemptions,
+		MaxRedemptionsPerBuyer: cmd.MaxRedemptionsPerBuyer,
+		StartsAt:               cmd.StartsAt,
+		ExpiresAt:              cmd.ExpiresAt,
+	}
+	if cmd.AmountOff > 0 {
+		currency := cmd.Currency
+		if currency == "" {
+			currency = money.DefaultCurrency
+		}
+		amountOff, err := money.ParseMajor(strconv.FormatFloat(cmd.AmountOff, 'f', -1, 64), currency)
+		if err != nil {
+			return domain.CouponTerms{}, err
+		}
+		terms.AmountOff = amountOff
+	}
+	if cmd.CategoryID != "" {
+		terms.CategoryID = &cmd.CategoryID
+	}
+	if cmd.SellerID != "" {
+		sellerID := ids.UserID(cmd.SellerID)
+		terms.SellerID = &sellerID
+	}
+	return terms, nil
+}
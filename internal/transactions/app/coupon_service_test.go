@@ -0,0 +1,165 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_CreateAndManage(t *testing.T) {
+	couponRepo := newFakeCouponRepository()
+	service := app.NewCouponService(couponRepo, nil)
+
+	coupon, err := service.CreateCoupon(context.Background(), app.CreateCouponCommand{
+		AdminID:            "admin-a",
+		Code:               "akwaaba",
+		CouponTermsCommand: app.CouponTermsCommand{Kind: "fixed", AmountOff: 12.5},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "AKWAABA", coupon.Code)
+	assert.Equal(t, money.New(1250, "GHS"), coupon.AmountOff, "fixed coupons are in cedis by default")
+
+	// Amounts off are not rounded, so fractions of a pesewa are refused
+	_, err = service.CreateCoupon(context.Background(), app.CreateCouponCommand{
+		AdminID:            "admin-a",
+		Code:               "PESEWA",
+		CouponTermsCommand: app.CouponTermsCommand{Kind: "fixed", AmountOff: 0.004},
+	})
+	assert.Error(t, err)
+	_, err = service.UpdateCoupon(context.Background(), app.UpdateCouponCommand{
+		CouponID:           coupon.ID,
+		CouponTermsCommand: app.CouponTermsCommand{Kind: "fixed", AmountOff: 12.505},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, money.New(1250, "GHS"), coupon.AmountOff)
+
+	_, err = service.CreateCoupon(context.Background(), app.CreateCouponCommand{
+		AdminID:            "admin-a",
+		Code:               "AKWAABA",
+		CouponTermsCommand: app.CouponTermsCommand{Kind: "percentage", Percent: 10},
+	})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeConflict, err.(*errors.DomainError).Code)
+
+	inactive := false
+	updated, err := service.UpdateCoupon(context.Background(), app.UpdateCouponCommand{
+		CouponID:           coupon.ID,
+		Active:             &inactive,
+		CouponTermsCommand: app.CouponTermsCommand{Kind: "percentage", Percent: 20},
+	})
+	require.NoError(t, err)
+	assert.False(t, updated.Active)
+	assert.Equal(t, 20, updated.Percent)
+	assert.True(t, updated.AmountOff.IsZero())
+
+	page, err := service.ListCoupons(context.Background(), app.CouponsQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, 20, page.Limit)
+
+	require.NoError(t, service.DeleteCoupon(context.Background(), coupon.ID))
+	_, err = service.GetCoupon(context.Background(), coupon.ID)
+	assert.Error(t, err)
+}
+
+func TestCouponService_RedeemAndRelease(t *testing.T) {
+	couponRepo := newFakeCouponRepository()
+	service := app.NewCouponService(couponRepo, nil)
+	coupon, err := service.CreateCoupon(context.Background(), app.CreateCouponCommand{
+		AdminID:            "admin-a",
+		Code:               "ONCE",
+		CouponTermsCommand: app.CouponTermsCommand{Kind: "percentage", Percent: 10, MaxRedemptions: 1},
+	})
+	require.NoError(t, err)
+
+	_, err = service.RedeemCoupon(context.Background(), "NOPE", newCouponTestOrder(t))
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeValidation, err.(*errors.DomainError).Code, "unknown codes read like invalid ones")
+
+	order := newCouponTestOrder(t)
+	redemption, err := service.RedeemCoupon(context.Background(), "once", order)
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(10), redemption.Discount)
+
+	again, err := service.RedeemCoupon(context.Background(), "ONCE", order)
+	require.NoError(t, err)
+	assert.Equal(t, redemption.ID, again.ID, "an order redeems a coupon once")
+
+	_, err = service.RedeemCoupon(context.Background(), "ONCE", newCouponTestOrder(t))
+	assert.Error(t, err, "the coupon is used up")
+
+	require.NoError(t, service.ReleaseCoupon(context.Background(), order.ID))
+	require.NoError(t, service.ReleaseCoupon(context.Background(), order.ID))
+	stored, err := service.GetCoupon(context.Background(), coupon.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.Redemptions)
+
+	_, err = service.RedeemCoupon(context.Background(), "ONCE", newCouponTestOrder(t))
+	require.NoError(t, err, "released redemptions no longer count")
+
+	page, err := service.ListRedemptions(context.Background(), coupon.ID, app.CouponsQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Error(t, service.DeleteCoupon(context.Background(), coupon.ID), "used coupons are kept")
+}
+
+func TestOrderService_CreateOrderWithCoupon(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	couponRepo := newFakeCouponRepository()
+	coupons := app.NewCouponService(couponRepo, nil)
+	coupon, err := coupons.CreateCoupon(context.Background(), app.CreateCouponCommand{
+		AdminID:            "admin-a",
+		Code:               "SALE15",
+		CouponTermsCommand: app.CouponTermsCommand{Kind: "percentage", Percent: 15},
+	})
+	require.NoError(t, err)
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing), &fakeNotificationPreferences{}, nil, &fakeEventBus{}, 30*time.Minute, "", nil, coupons, nil, nil)
+
+	// A coupon that does not apply leaves the listing free
+	_, err = service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID, CouponCode: "NOPE"})
+	require.Error(t, err)
+	assert.False(t, listing.IsReserved())
+
+	order, err := service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID, CouponCode: "sale15"})
+	require.NoError(t, err)
+	assert.Equal(t, "SALE15", order.CouponCode)
+	assert.Equal(t, money.Cedis(15), order.Discount)
+	assert.Equal(t, money.Cedis(85), order.Amount)
+
+	// Abandoning the checkout frees the coupon, and resuming it takes it back
+	// at the discount the order got
+	expireOrder(order)
+	_, err = service.AbandonExpiredOrders(context.Background(), 10)
+	require.NoError(t, err)
+	stored, err := coupons.GetCoupon(context.Background(), coupon.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.Redemptions)
+
+	_, err = coupons.UpdateCoupon(context.Background(), app.UpdateCouponCommand{
+		CouponID:           coupon.ID,
+		CouponTermsCommand: app.CouponTermsCommand{Kind: "percentage", Percent: 50},
+	})
+	require.NoError(t, err)
+	resumed, err := service.ResumeCheckout(context.Background(), order.ID, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(85), resumed.Amount)
+	stored, err = coupons.GetCoupon(context.Background(), coupon.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.Redemptions)
+}
+
+func newCouponTestOrder(t *testing.T) *domain.Order {
+	t.Helper()
+	now := time.Now()
+	order, err := domain.NewOrder("buyer-a", "seller-a", "listing-a", "category-1", "Used phone", money.Cedis(100), now.Add(time.Hour), now)
+	require.NoError(t, err)
+	return order
+}
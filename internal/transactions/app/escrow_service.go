@@ -0,0 +1,245 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// CancelOrderCommand represents the buyer or the seller cancelling a paid
+// order, refunding the buyer
+type CancelOrderCommand struct {
+	OrderID ids.OrderID `json:"-"`
+	UserID  ids.UserID  `json:"-"`
+	Reason  string      `json:"reason" binding:"required,max=500"`
+}
+
+// EscrowService protects buyers by holding the payment of each order in
+// escrow until delivery is confirmed, releasing it to the seller then or
+// when the hold times out, and refunding it when the order is cancelled
+type EscrowService struct {
+	escrowRepo    domain.EscrowRepository
+	orderRepo     domain.OrderRepository
+	listings      ListingReservations
+	eventBus      events.EventBus
+	holdPeriod    time.Duration
+	confirmWindow time.Duration
+	clock         clock.Clock
+}
+
+// NewEscrowService creates a new escrow service. Payments are held for up to
+// holdPeriod, or confirmWindow after the seller requests their release if
+// sooner. clk may be nil to use the system clock.
+func NewEscrowService(escrowRepo domain.EscrowRepository, orderRepo domain.OrderRepository, listings ListingReservations, eventBus events.EventBus, holdPeriod, confirmWindow time.Duration, clk clock.Clock) *EscrowService {
+	return &EscrowService{
+		escrowRepo:    escrowRepo,
+		orderRepo:     orderRepo,
+		listings:      listings,
+		eventBus:      eventBus,
+		holdPeriod:    holdPeriod,
+		confirmWindow: confirmWindow,
+		clock:         clock.OrSystem(clk),
+	}
+}
+
+// HoldPayment holds the payment of a paid order in escrow. Holding an
+// order's payment again returns its escrow, so redelivered events are safe.
+func (s *EscrowService) HoldPayment(ctx context.Context, orderID ids.OrderID) (*domain.Escrow, error) {
+	if escrow, err := s.escrowRepo.FindByOrder(orderID); err == nil {
+		return escrow, nil
+	} else if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeNotFound {
+		return nil, err
+	}
+
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	escrow, err := domain.NewEscrow(order, s.holdPeriod, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.escrowRepo.Save(escrow) }); err != nil {
+		return nil, err
+	}
+	if err := s.publish(ctx, domain.EscrowHeldEvent, escrow); err != nil {
+		return nil, err
+	}
+	return escrow, nil
+}
+
+// GetEscrow returns the escrow of an order to its buyer or seller
+func (s *EscrowService) GetEscrow(ctx context.Context, orderID ids.OrderID, userID ids.UserID) (*domain.Escrow, error) {
+	escrow, err := s.escrowRepo.FindByOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	// Other users are told the escrow does not exist rather than that it is not theirs
+	if !escrow.IsParty(userID) {
+		return nil, errors.NotFoundError("escrow not found")
+	}
+	return escrow, nil
+}
+
+// ConfirmDelivery releases an order's funds to the seller once the buyer
+// confirms they received it
+func (s *EscrowService) ConfirmDelivery(ctx context.Context, orderID ids.OrderID, buyerID ids.UserID) (*domain.Escrow, error) {
+	escrow, err := s.GetEscrow(ctx, orderID, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := escrow.ConfirmDelivery(buyerID, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.escrowRepo.Update(escrow) }); err != nil {
+		return nil, err
+	}
+	if err := s.publish(ctx, domain.EscrowReleasedEvent, escrow); err != nil {
+		return nil, err
+	}
+	if err := s.publishCompleted(ctx, escrow); err != nil {
+		return nil, err
+	}
+	return escrow, nil
+}
+
+// RequestRelease records the seller saying an order was delivered, leaving
+// the buyer the confirmation window to confirm or cancel it before the
+// funds are released
+func (s *EscrowService) RequestRelease(ctx context.Context, orderID ids.OrderID, sellerID ids.UserID) (*domain.Escrow, error) {
+	escrow, err := s.GetEscrow(ctx, orderID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := escrow.RequestRelease(sellerID, s.confirmWindow, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.escrowRepo.Update(escrow) }); err != nil {
+		return nil, err
+	}
+	if err := s.publish(ctx, domain.EscrowReleaseRequestedEvent, escrow); err != nil {
+		return nil, err
+	}
+	return escrow, nil
+}
+
+// CancelOrder cancels a paid order whose funds are still held, refunding the
+// buyer and releasing the listing if it is still reserved for them
+func (s *EscrowService) CancelOrder(ctx context.Context, cmd CancelOrderCommand) (*domain.Escrow, error) {
+	escrow, err := s.GetEscrow(ctx, cmd.OrderID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orderRepo.FindByID(cmd.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if err := escrow.Refund(cmd.UserID, cmd.Reason, now); err != nil {
+		return nil, err
+	}
+	if err := order.CancelPaid(now); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.escrowRepo.Update(escrow) }); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.orderRepo.Update(order) }); err != nil {
+		return nil, err
+	}
+	if err := s.listings.ReleaseListing(ctx, order.ListingID, order.BuyerID); err != nil {
+		return nil, err
+	}
+	if err := s.publish(ctx, domain.EscrowRefundedEvent, escrow); err != nil {
+		return nil, err
+	}
+	if err := publishOrderCancelled(ctx, s.eventBus, order, cmd.Reason); err != nil {
+		return nil, err
+	}
+	return escrow, nil
+}
+
+// ReleaseDueEscrows releases to their sellers the funds of up to limit
+// escrows whose hold timed out without the buyer confirming delivery or
+// cancelling. It returns how many were released.
+func (s *EscrowService) ReleaseDueEscrows(ctx context.Context, limit int) (int, error) {
+	now := s.clock.Now()
+	escrows, err := s.escrowRepo.FindDueForRelease(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, escrow := range escrows {
+		if err := escrow.Release(domain.EscrowReleaseTimeout, now); err != nil {
+			continue
+		}
+		if err := db.WithRetry(ctx, func() error { return s.escrowRepo.Update(escrow) }); err != nil {
+			return released, err
+		}
+		if err := s.publish(ctx, domain.EscrowReleasedEvent, escrow); err != nil {
+			return released, err
+		}
+		if err := s.publishCompleted(ctx, escrow); err != nil {
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
+
+// publish announces a transition of an escrow
+func (s *EscrowService) publish(ctx context.Context, eventType string, escrow *domain.Escrow) error {
+	event, err := events.NewEvent(
+		eventType,
+		escrow.OrderID.String(),
+		domain.EscrowChanged{
+			EscrowID:     escrow.ID,
+			OrderID:      escrow.OrderID,
+			BuyerID:      escrow.BuyerID,
+			SellerID:     escrow.SellerID,
+			Amount:       escrow.Amount,
+			Status:       escrow.Status,
+			ReleaseDueAt: escrow.ReleaseDueAt,
+			Reason:       escrow.Reason,
+			Timestamp:    s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return s.eventBus.Publish(ctx, event)
+}
+
+// publishCompleted announces that the order whose funds were released to the
+// seller is complete
+func (s *EscrowService) publishCompleted(ctx context.Context, escrow *domain.Escrow) error {
+	order, err := s.orderRepo.FindByID(escrow.OrderID)
+	if err != nil {
+		return err
+	}
+
+	event, err := events.NewEvent(
+		domain.OrderCompletedEvent,
+		order.ID.String(),
+		domain.OrderCompleted{
+			OrderID:   order.ID,
+			BuyerID:   order.BuyerID,
+			SellerID:  order.SellerID,
+			ListingID: order.ListingID,
+			Amount:    order.Amount,
+			Timestamp: s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return s.eventBus.Publish(ctx, event)
+}
@@ -0,0 +1,158 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// escrowFixture holds the payment of a new paid order in escrow
+type escrowFixture struct {
+	orders       *fakeOrderRepository
+	reservations *fakeListingReservations
+	eventBus     *fakeEventBus
+	clock        *clock.Frozen
+	service      *app.EscrowService
+}
+
+func newEscrowFixture() *escrowFixture {
+	f := &escrowFixture{
+		orders:       newFakeOrderRepository(),
+		reservations: newFakeListingReservations(),
+		eventBus:     &fakeEventBus{},
+		clock:        clock.NewFrozen(time.Now()),
+	}
+	f.service = app.NewEscrowService(newFakeEscrowRepository(), f.orders, f.reservations, f.eventBus, 14*24*time.Hour, 72*time.Hour, f.clock)
+	return f
+}
+
+// paidOrder places an order and completes its payment
+func (f *escrowFixture) paidOrder(t *testing.T) *domain.Order {
+	t.Helper()
+	listing := newActiveListing(t, "seller-a")
+	f.reservations.listings[listing.ID] = listing
+	orderService := app.NewOrderService(f.orders, f.reservations, &fakeNotificationPreferences{}, nil, &fakeEventBus{}, 30*time.Minute, "", nil, nil, nil, nil)
+
+	order, err := orderService.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	order, err = orderService.CompletePayment(context.Background(), order.ID)
+	require.NoError(t, err)
+	return order
+}
+
+func TestEscrowService_HoldAndConfirmDelivery(t *testing.T) {
+	f := newEscrowFixture()
+	ctx := context.Background()
+	order := f.paidOrder(t)
+
+	escrow, err := f.service.HoldPayment(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EscrowStatusHeld, escrow.Status)
+	assert.Equal(t, order.Amount, escrow.Amount)
+
+	// Redelivered OrderPaid events reuse the escrow
+	again, err := f.service.HoldPayment(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, escrow.ID, again.ID)
+	require.Len(t, f.eventBus.eventsOfType(domain.EscrowHeldEvent), 1)
+
+	_, err = f.service.GetEscrow(ctx, order.ID, "stranger")
+	assert.Error(t, err)
+	_, err = f.service.ConfirmDelivery(ctx, order.ID, "seller-a")
+	assert.Error(t, err, "only the buyer confirms delivery")
+
+	released, err := f.service.ConfirmDelivery(ctx, order.ID, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.EscrowStatusReleased, released.Status)
+	assert.NotNil(t, released.DeliveryConfirmedAt)
+
+	published := f.eventBus.eventsOfType(domain.EscrowReleasedEvent)
+	require.Len(t, published, 1)
+	assertEscrowEvent(t, published[0], domain.EscrowReleaseDeliveryConfirmed)
+	completed := f.eventBus.eventsOfType(domain.OrderCompletedEvent)
+	require.Len(t, completed, 1, "the sale is final once the funds are released")
+	var data domain.OrderCompleted
+	require.NoError(t, events.ParseEventData(completed[0], &data))
+	assert.Equal(t, order.ListingID, data.ListingID)
+
+	_, err = f.service.CancelOrder(ctx, app.CancelOrderCommand{OrderID: order.ID, UserID: "seller-a", Reason: "out of stock"})
+	assert.Error(t, err, "released funds cannot be refunded")
+}
+
+func TestEscrowService_ReleaseRequestShortensTheHold(t *testing.T) {
+	f := newEscrowFixture()
+	ctx := context.Background()
+	order := f.paidOrder(t)
+	_, err := f.service.HoldPayment(ctx, order.ID)
+	require.NoError(t, err)
+
+	_, err = f.service.RequestRelease(ctx, order.ID, "buyer-a")
+	assert.Error(t, err, "only the seller requests the release")
+	requested, err := f.service.RequestRelease(ctx, order.ID, "seller-a")
+	require.NoError(t, err)
+	assert.Equal(t, f.clock.Now().Add(72*time.Hour), requested.ReleaseDueAt)
+	assert.Len(t, f.eventBus.eventsOfType(domain.EscrowReleaseRequestedEvent), 1)
+
+	// The buyer can no longer cancel once the seller reported the delivery
+	_, err = f.service.CancelOrder(ctx, app.CancelOrderCommand{OrderID: order.ID, UserID: "buyer-a", Reason: "changed my mind"})
+	assert.Error(t, err)
+
+	count, err := f.service.ReleaseDueEscrows(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	f.clock.Advance(72 * time.Hour)
+	count, err = f.service.ReleaseDueEscrows(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	published := f.eventBus.eventsOfType(domain.EscrowReleasedEvent)
+	require.Len(t, published, 1)
+	assertEscrowEvent(t, published[0], domain.EscrowReleaseTimeout)
+	assert.Len(t, f.eventBus.eventsOfType(domain.OrderCompletedEvent), 1)
+}
+
+func TestEscrowService_CancelOrderRefundsTheBuyer(t *testing.T) {
+	f := newEscrowFixture()
+	ctx := context.Background()
+	order := f.paidOrder(t)
+	_, err := f.service.HoldPayment(ctx, order.ID)
+	require.NoError(t, err)
+
+	refunded, err := f.service.CancelOrder(ctx, app.CancelOrderCommand{OrderID: order.ID, UserID: "buyer-a", Reason: "changed my mind"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.EscrowStatusRefunded, refunded.Status)
+	assert.Equal(t, domain.OrderStatusCancelled, order.Status)
+	assert.False(t, f.reservations.listings[order.ListingID].IsReserved(), "the listing is released for other buyers")
+
+	published := f.eventBus.eventsOfType(domain.EscrowRefundedEvent)
+	require.Len(t, published, 1)
+	assertEscrowEvent(t, published[0], "changed my mind")
+	cancelled := f.eventBus.eventsOfType(domain.OrderCancelledEvent)
+	require.Len(t, cancelled, 1)
+	var data domain.OrderCancelled
+	require.NoError(t, events.ParseEventData(cancelled[0], &data))
+	assert.True(t, data.Paid, "the payment is refunded")
+	assert.Equal(t, "changed my mind", data.Reason)
+
+	// Refunded funds are never released
+	f.clock.Advance(15 * 24 * time.Hour)
+	count, err := f.service.ReleaseDueEscrows(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func assertEscrowEvent(t *testing.T, event *events.Event, reason string) {
+	t.Helper()
+	var data domain.EscrowChanged
+	require.NoError(t, events.ParseEventData(event, &data))
+	assert.Equal(t, reason, data.Reason)
+}
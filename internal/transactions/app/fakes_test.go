@@ -0,0 +1,1314 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	users "dongome/internal/users/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/payments"
+	"dongome/pkg/rules"
+)
+
+// fakeOrderRepository is an in-memory OrderRepository
+type fakeOrderRepository struct {
+	mu     sync.Mutex
+	orders map[ids.OrderID]*domain.Order
+	// versions are the stored versions, which copies of orders may be behind
+	versions map[ids.OrderID]int
+}
+
+func newFakeOrderRepository() *fakeOrderRepository {
+	return &fakeOrderRepository{orders: make(map[ids.OrderID]*domain.Order), versions: make(map[ids.OrderID]int)}
+}
+
+func (r *fakeOrderRepository) Save(order *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orders[order.ID] = order
+	r.versions[order.ID] = order.Version
+	return nil
+}
+
+func (r *fakeOrderRepository) FindByID(id ids.OrderID) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if order, ok := r.orders[id]; ok {
+		return order, nil
+	}
+	return nil, errors.NotFoundError("order not found")
+}
+
+func (r *fakeOrderRepository) FindExpiredPending(now time.Time, limit int) ([]*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var orders []*domain.Order
+	for _, order := range r.orders {
+		if order.PaymentExpired(now) && !order.AwaitsPaymentApproval() {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].PaymentDueAt.Before(orders[j].PaymentDueAt) })
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+func (r *fakeOrderRepository) FindAwaitingPaymentApproval(requestedBefore time.Time, limit int) ([]*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var orders []*domain.Order
+	for _, order := range r.orders {
+		if order.AwaitsPaymentApproval() && order.PaymentRequestedAt.Before(requestedBefore) {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].PaymentRequestedAt.Before(*orders[j].PaymentRequestedAt) })
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+func (r *fakeOrderRepository) Update(order *domain.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if order.Version != r.versions[order.ID] {
+		return errors.ConflictError("the order was changed meanwhile")
+	}
+	order.Version++
+	r.versions[order.ID] = order.Version
+	r.orders[order.ID] = order
+	return nil
+}
+
+func (r *fakeOrderRepository) CountByCategory(from, to time.Time) ([]domain.CategoryOrderCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byCategory := make(map[string]*domain.CategoryOrderCount)
+	var counts []domain.CategoryOrderCount
+	var categories []string
+	for _, order := range r.orders {
+		if order.CreatedAt.Before(from) || !order.CreatedAt.Before(to) {
+			continue
+		}
+		count, ok := byCategory[order.CategoryID]
+		if !ok {
+			count = &domain.CategoryOrderCount{CategoryID: order.CategoryID}
+			byCategory[order.CategoryID] = count
+			categories = append(categories, order.CategoryID)
+		}
+		count.Orders++
+		if order.AbandonedAt != nil {
+			count.Abandoned++
+		}
+		if order.Recovered {
+			count.Recovered++
+		}
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		counts = append(counts, *byCategory[category])
+	}
+	return counts, nil
+}
+
+func (r *fakeOrderRepository) FindHistory(filter domain.OrderHistoryFilter, limit int) ([]*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var orders []*domain.Order
+	for _, order := range r.orders {
+		switch {
+		case filter.BuyerID != "" && order.BuyerID != filter.BuyerID,
+			filter.SellerID != "" && order.SellerID != filter.SellerID,
+			filter.Status != "" && order.Status != filter.Status,
+			filter.CreatedFrom != nil && order.CreatedAt.Before(*filter.CreatedFrom),
+			filter.CreatedTo != nil && !order.CreatedAt.Before(*filter.CreatedTo),
+			filter.After != nil && !filter.After.Precedes(order):
+			continue
+		}
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return domain.NewOrderCursor(orders[i]).Precedes(orders[j]) })
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+func (r *fakeOrderRepository) CountByBuyerSince(buyerID ids.UserID, since time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, order := range r.orders {
+		if order.BuyerID == buyerID && !order.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeEscrowRepository is an in-memory EscrowRepository
+type fakeEscrowRepository struct {
+	mu      sync.Mutex
+	escrows map[ids.OrderID]*domain.Escrow
+	// payouts maps escrows claimed by a payout to the payout
+	payouts map[string]string
+}
+
+func newFakeEscrowRepository() *fakeEscrowRepository {
+	return &fakeEscrowRepository{escrows: make(map[ids.OrderID]*domain.Escrow), payouts: make(map[string]string)}
+}
+
+func (r *fakeEscrowRepository) Save(escrow *domain.Escrow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.escrows[escrow.OrderID] = escrow
+	return nil
+}
+
+func (r *fakeEscrowRepository) FindByOrder(orderID ids.OrderID) (*domain.Escrow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	escrow, ok := r.escrows[orderID]
+	if !ok {
+		return nil, errors.NotFoundError("escrow not found")
+	}
+	return escrow, nil
+}
+
+func (r *fakeEscrowRepository) FindDueForRelease(now time.Time, limit int) ([]*domain.Escrow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var escrows []*domain.Escrow
+	for _, escrow := range r.escrows {
+		if escrow.ReleaseDue(now) {
+			escrows = append(escrows, escrow)
+		}
+	}
+	sort.Slice(escrows, func(i, j int) bool { return escrows[i].ReleaseDueAt.Before(escrows[j].ReleaseDueAt) })
+	if len(escrows) > limit {
+		escrows = escrows[:limit]
+	}
+	return escrows, nil
+}
+
+func (r *fakeEscrowRepository) FindUnpaid(sellerID ids.UserID, limit int) ([]*domain.Escrow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var escrows []*domain.Escrow
+	for _, escrow := range r.escrows {
+		if escrow.SellerID == sellerID && escrow.Status == domain.EscrowStatusReleased && !escrow.Frozen() && r.payouts[escrow.ID] == "" {
+			escrows = append(escrows, escrow)
+		}
+	}
+	sort.Slice(escrows, func(i, j int) bool { return escrows[i].ReleasedAt.Before(*escrows[j].ReleasedAt) })
+	if len(escrows) > limit {
+		escrows = escrows[:limit]
+	}
+	return escrows, nil
+}
+
+func (r *fakeEscrowRepository) IsPaidOut(orderID ids.OrderID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	escrow, ok := r.escrows[orderID]
+	return ok && r.payouts[escrow.ID] != "", nil
+}
+
+func (r *fakeEscrowRepository) Update(escrow *domain.Escrow) error {
+	return r.Save(escrow)
+}
+
+// fakeListingReservations reserves in-memory listings
+type fakeListingReservations struct {
+	mu       sync.Mutex
+	listings map[ids.ListingID]*listings.Listing
+}
+
+func newFakeListingReservations(items ...*listings.Listing) *fakeListingReservations {
+	reservations := &fakeListingReservations{listings: make(map[ids.ListingID]*listings.Listing)}
+	for _, listing := range items {
+		reservations.listings[listing.ID] = listing
+	}
+	return reservations
+}
+
+func (r *fakeListingReservations) ReserveListing(ctx context.Context, listingID ids.ListingID, buyerID ids.UserID, until time.Time) (*listings.Listing, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	listing, ok := r.listings[listingID]
+	if !ok {
+		return nil, errors.NotFoundError("listing not found")
+	}
+	if err := listing.Reserve(buyerID, until, time.Now()); err != nil {
+		return nil, err
+	}
+	return listing, nil
+}
+
+func (r *fakeListingReservations) ReleaseListing(ctx context.Context, listingID ids.ListingID, buyerID ids.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if listing, ok := r.listings[listingID]; ok {
+		listing.ReleaseReservation(buyerID)
+	}
+	return nil
+}
+
+// fakeNotificationPreferences reports notification channels; users not
+// listed get email and push
+type fakeNotificationPreferences struct {
+	channels map[ids.UserID][]string
+}
+
+func (p *fakeNotificationPreferences) NotificationChannels(ctx context.Context, userID ids.UserID, category string) ([]string, error) {
+	if channels, ok := p.channels[userID]; ok {
+		return channels, nil
+	}
+	return []string{"email", "push"}, nil
+}
+
+// fakeEventBus records published events
+type fakeEventBus struct {
+	mu        sync.Mutex
+	published []*events.Event
+}
+
+func (b *fakeEventBus) Publish(ctx context.Context, event *events.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, event)
+	return nil
+}
+
+func (b *fakeEventBus) Subscribe(eventType string, handler events.EventHandler) error {
+	return nil
+}
+
+func (b *fakeEventBus) Close() error {
+	return nil
+}
+
+// eventsOfType returns the published events of the given type
+func (b *fakeEventBus) eventsOfType(eventType string) []*events.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var matching []*events.Event
+	for _, event := range b.published {
+		if event.Type == eventType {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+// fakePaymentGateway is an in-memory PaymentGateway reporting the results
+// of payments set in results; unknown ones are pending
+type fakePaymentGateway struct {
+	requests []app.PaymentRequest
+	results  map[string]*app.PaymentResult
+}
+
+func (g *fakePaymentGateway) RequestPayment(ctx context.Context, payment app.PaymentRequest) (string, error) {
+	g.requests = append(g.requests, payment)
+	return fmt.Sprintf("payment-%d", len(g.requests)), nil
+}
+
+func (g *fakePaymentGateway) PaymentResult(ctx context.Context, reference string) (*app.PaymentResult, error) {
+	if result, ok := g.results[reference]; ok {
+		return result, nil
+	}
+	return &app.PaymentResult{Status: "PENDING"}, nil
+}
+
+// fakePaymentProvider is an in-memory payment provider reporting the results
+// of payments and refunds set in results; unknown ones are pending. Payments
+// get checkoutURL as their checkout page, and refunds fail to start while
+// refundErr is set.
+type fakePaymentProvider struct {
+	name        string
+	checkoutURL string
+	requests    []payments.Request
+	refunds     []payments.RefundRequest
+	refundErr   error
+	results     map[string]*payments.Result
+}
+
+// newFakePaymentProvider creates a fake provider with the given name
+func newFakePaymentProvider(name string) *fakePaymentProvider {
+	return &fakePaymentProvider{name: name, results: make(map[string]*payments.Result)}
+}
+
+// registry returns a registry where the provider takes the given methods
+func (p *fakePaymentProvider) registry(methods ...payments.Method) *payments.Registry {
+	registry := payments.NewRegistry()
+	registry.Use(p, methods...)
+	return registry
+}
+
+func (p *fakePaymentProvider) Name() string {
+	return p.name
+}
+
+func (p *fakePaymentProvider) RequestPayment(ctx context.Context, payment payments.Request) (*payments.Payment, error) {
+	p.requests = append(p.requests, payment)
+	return &payments.Payment{Reference: fmt.Sprintf("payment-%d", len(p.requests)), CheckoutURL: p.checkoutURL}, nil
+}
+
+func (p *fakePaymentProvider) PaymentResult(ctx context.Context, reference string) (*payments.Result, error) {
+	if result, ok := p.results[reference]; ok {
+		return result, nil
+	}
+	return &payments.Result{Status: payments.StatusPending}, nil
+}
+
+func (p *fakePaymentProvider) Refund(ctx context.Context, refund payments.RefundRequest) (string, error) {
+	if p.refundErr != nil {
+		return "", p.refundErr
+	}
+	p.refunds = append(p.refunds, refund)
+	return fmt.Sprintf("transfer-%d", len(p.refunds)), nil
+}
+
+func (p *fakePaymentProvider) RefundResult(ctx context.Context, reference string) (*payments.Result, error) {
+	return p.PaymentResult(ctx, reference)
+}
+
+func (p *fakePaymentProvider) ParseWebhook(header http.Header, body []byte) (*payments.Event, error) {
+	return nil, payments.ErrIgnoredEvent
+}
+
+// fakeCodeProvider is a fakePaymentProvider whose payments ask the payer for
+// a code, accepting only code
+type fakeCodeProvider struct {
+	*fakePaymentProvider
+	code  string
+	codes []string
+}
+
+func (p *fakeCodeProvider) RequestPayment(ctx context.Context, payment payments.Request) (*payments.Payment, error) {
+	requested, err := p.fakePaymentProvider.RequestPayment(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	requested.Action = payments.ActionEnterCode
+	requested.Instructions = "Dial *110# to generate a voucher"
+	return requested, nil
+}
+
+func (p *fakeCodeProvider) ConfirmPayment(ctx context.Context, reference, code string) (*payments.Payment, error) {
+	p.codes = append(p.codes, code)
+	if code != p.code {
+		return nil, errors.ValidationError("the code was not accepted")
+	}
+	return &payments.Payment{Reference: reference, Action: payments.ActionApprove}, nil
+}
+
+// fakeRefundRepository is an in-memory RefundRepository
+type fakeRefundRepository struct {
+	mu      sync.Mutex
+	refunds []*domain.Refund
+}
+
+func (r *fakeRefundRepository) Save(refund *domain.Refund) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.refunds {
+		if existing.OrderID == refund.OrderID && existing.IdempotencyKey == refund.IdempotencyKey {
+			return errors.ConflictError("refund already exists")
+		}
+	}
+	r.refunds = append(r.refunds, refund)
+	return nil
+}
+
+func (r *fakeRefundRepository) FindByIdempotencyKey(orderID ids.OrderID, key string) (*domain.Refund, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, refund := range r.refunds {
+		if refund.OrderID == orderID && refund.IdempotencyKey == key {
+			return refund, nil
+		}
+	}
+	return nil, errors.NotFoundError("refund not found")
+}
+
+func (r *fakeRefundRepository) FindByOrder(orderID ids.OrderID) ([]*domain.Refund, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var refunds []*domain.Refund
+	for _, refund := range r.refunds {
+		if refund.OrderID == orderID {
+			refunds = append(refunds, refund)
+		}
+	}
+	return refunds, nil
+}
+
+func (r *fakeRefundRepository) FindPending(createdBefore time.Time, limit int) ([]*domain.Refund, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var refunds []*domain.Refund
+	for _, refund := range r.refunds {
+		if refund.Status == domain.RefundStatusPending && refund.Reference != "" && refund.CreatedAt.Before(createdBefore) && len(refunds) < limit {
+			refunds = append(refunds, refund)
+		}
+	}
+	return refunds, nil
+}
+
+func (r *fakeRefundRepository) Update(refund *domain.Refund) error {
+	return nil
+}
+
+// fakePayoutGateway is an in-memory PayoutGateway. Numbers in inactive are
+// not wallets; payouts report the results set in results and are otherwise
+// pending, and fail to start while sendErr is set.
+type fakePayoutGateway struct {
+	inactive map[string]bool
+	payouts  []app.PaymentRequest
+	sendErr  error
+	results  map[string]*app.PaymentResult
+}
+
+func (g *fakePayoutGateway) VerifyAccount(ctx context.Context, phone string) (bool, error) {
+	return !g.inactive[phone], nil
+}
+
+func (g *fakePayoutGateway) SendPayout(ctx context.Context, payout app.PaymentRequest) (string, error) {
+	if g.sendErr != nil {
+		return "", g.sendErr
+	}
+	g.payouts = append(g.payouts, payout)
+	return fmt.Sprintf("payout-%d", len(g.payouts)), nil
+}
+
+func (g *fakePayoutGateway) PayoutResult(ctx context.Context, reference string) (*app.PaymentResult, error) {
+	if result, ok := g.results[reference]; ok {
+		return result, nil
+	}
+	return &app.PaymentResult{Status: "PENDING"}, nil
+}
+
+// fakePayoutRepository is an in-memory PayoutRepository claiming the
+// escrows of its payouts in escrows
+type fakePayoutRepository struct {
+	mu       sync.Mutex
+	escrows  *fakeEscrowRepository
+	accounts map[ids.UserID]*domain.PayoutAccount
+	payouts  []*domain.Payout
+}
+
+func newFakePayoutRepository(escrows *fakeEscrowRepository) *fakePayoutRepository {
+	return &fakePayoutRepository{escrows: escrows, accounts: make(map[ids.UserID]*domain.PayoutAccount)}
+}
+
+func (r *fakePayoutRepository) SaveAccount(account *domain.PayoutAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accounts[account.SellerID] = account
+	return nil
+}
+
+func (r *fakePayoutRepository) FindAccount(sellerID ids.UserID) (*domain.PayoutAccount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	account, ok := r.accounts[sellerID]
+	if !ok {
+		return nil, errors.NotFoundError("payout account not found")
+	}
+	return account, nil
+}
+
+func (r *fakePayoutRepository) FindAccountsDue(now time.Time, limit int) ([]*domain.PayoutAccount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var accounts []*domain.PayoutAccount
+	for _, account := range r.accounts {
+		if !account.IsVerified() || account.NextPayoutAt.After(now) {
+			continue
+		}
+		if unpaid, _ := r.escrows.FindUnpaid(account.SellerID, 1); len(unpaid) > 0 {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].NextPayoutAt.Before(accounts[j].NextPayoutAt) })
+	if len(accounts) > limit {
+		accounts = accounts[:limit]
+	}
+	return accounts, nil
+}
+
+func (r *fakePayoutRepository) UpdateAccount(account *domain.PayoutAccount) error {
+	return r.SaveAccount(account)
+}
+
+func (r *fakePayoutRepository) Save(payout *domain.Payout) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.escrows.mu.Lock()
+	defer r.escrows.mu.Unlock()
+	for _, escrowID := range payout.EscrowIDs() {
+		if r.escrows.payouts[escrowID] != "" {
+			return errors.ConflictError("the funds are already being paid out")
+		}
+	}
+	for _, escrowID := range payout.EscrowIDs() {
+		r.escrows.payouts[escrowID] = payout.ID
+	}
+	r.payouts = append(r.payouts, payout)
+	return nil
+}
+
+func (r *fakePayoutRepository) FindByID(id string) (*domain.Payout, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, payout := range r.payouts {
+		if payout.ID == id {
+			return payout, nil
+		}
+	}
+	return nil, errors.NotFoundError("payout not found")
+}
+
+func (r *fakePayoutRepository) FindBySeller(sellerID ids.UserID, limit, offset int) ([]*domain.Payout, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var payouts []*domain.Payout
+	for i := len(r.payouts) - 1; i >= 0; i-- {
+		if r.payouts[i].SellerID == sellerID {
+			payouts = append(payouts, r.payouts[i])
+		}
+	}
+	total := int64(len(payouts))
+	if offset > len(payouts) {
+		offset = len(payouts)
+	}
+	payouts = payouts[offset:]
+	if len(payouts) > limit {
+		payouts = payouts[:limit]
+	}
+	return payouts, total, nil
+}
+
+func (r *fakePayoutRepository) FindPending(createdBefore time.Time, limit int) ([]*domain.Payout, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var payouts []*domain.Payout
+	for _, payout := range r.payouts {
+		if payout.Status == domain.PayoutStatusPending && payout.Reference != "" && payout.CreatedAt.Before(createdBefore) && len(payouts) < limit {
+			payouts = append(payouts, payout)
+		}
+	}
+	return payouts, nil
+}
+
+func (r *fakePayoutRepository) Update(payout *domain.Payout) error {
+	if payout.Status != domain.PayoutStatusFailed {
+		return nil
+	}
+	r.escrows.mu.Lock()
+	defer r.escrows.mu.Unlock()
+	for escrowID, payoutID := range r.escrows.payouts {
+		if payoutID == payout.ID {
+			delete(r.escrows.payouts, escrowID)
+		}
+	}
+	return nil
+}
+
+// fakeWalletRepository is an in-memory WalletRepository. Posts hold its
+// lock while they change a wallet, as the row lock does in the database.
+type fakeWalletRepository struct {
+	mu        sync.Mutex
+	wallets   map[ids.UserID]*domain.Wallet
+	entries   []*domain.WalletEntry
+	transfers []*domain.WalletTransfer
+}
+
+func newFakeWalletRepository() *fakeWalletRepository {
+	return &fakeWalletRepository{wallets: make(map[ids.UserID]*domain.Wallet)}
+}
+
+func (r *fakeWalletRepository) Open(wallet *domain.Wallet) (*domain.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.wallets[wallet.UserID]; !ok {
+		r.wallets[wallet.UserID] = wallet
+	}
+	opened := *r.wallets[wallet.UserID]
+	return &opened, nil
+}
+
+func (r *fakeWalletRepository) FindByUser(userID ids.UserID) (*domain.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wallet, ok := r.wallets[userID]
+	if !ok {
+		return nil, errors.NotFoundError("wallet not found")
+	}
+	found := *wallet
+	return &found, nil
+}
+
+func (r *fakeWalletRepository) Post(userID ids.UserID, change func(wallet *domain.Wallet) (*domain.WalletEntry, error)) (*domain.WalletEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.wallets[userID]
+	if !ok {
+		return nil, errors.NotFoundError("wallet not found")
+	}
+
+	wallet := *stored
+	entry, err := change(&wallet)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range r.entries {
+		if existing.WalletID == entry.WalletID && existing.Kind == entry.Kind && existing.Reference == entry.Reference {
+			return nil, errors.ConflictError("the wallet entry was already posted")
+		}
+	}
+	r.entries = append(r.entries, entry)
+	*stored = wallet
+	return entry, nil
+}
+
+func (r *fakeWalletRepository) Transfer(fromUserID, toUserID ids.UserID, move func(from, to *domain.Wallet) ([]*domain.WalletEntry, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	storedFrom, ok := r.wallets[fromUserID]
+	if !ok {
+		return errors.NotFoundError("wallet not found")
+	}
+	storedTo, ok := r.wallets[toUserID]
+	if !ok {
+		return errors.NotFoundError("wallet not found")
+	}
+
+	from, to := *storedFrom, *storedTo
+	entries, err := move(&from, &to)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		for _, existing := range r.entries {
+			if existing.WalletID == entry.WalletID && existing.Kind == entry.Kind && existing.Reference == entry.Reference {
+				return errors.ConflictError("the wallet entry was already posted")
+			}
+		}
+	}
+	r.entries = append(r.entries, entries...)
+	*storedFrom, *storedTo = from, to
+	return nil
+}
+
+func (r *fakeWalletRepository) FindEntries(walletID string, limit, offset int) ([]*domain.WalletEntry, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []*domain.WalletEntry
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if r.entries[i].WalletID == walletID {
+			entries = append(entries, r.entries[i])
+		}
+	}
+	total := int64(len(entries))
+	if offset >= len(entries) {
+		return nil, total, nil
+	}
+	entries = entries[offset:]
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, total, nil
+}
+
+func (r *fakeWalletRepository) SaveTransfer(transfer *domain.WalletTransfer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transfers = append(r.transfers, transfer)
+	return nil
+}
+
+func (r *fakeWalletRepository) FindTransfer(id string) (*domain.WalletTransfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, transfer := range r.transfers {
+		if transfer.ID == id {
+			return transfer, nil
+		}
+	}
+	return nil, errors.NotFoundError("wallet transfer not found")
+}
+
+func (r *fakeWalletRepository) FindPendingTransfers(createdBefore time.Time, limit int) ([]*domain.WalletTransfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pending []*domain.WalletTransfer
+	for _, transfer := range r.transfers {
+		if transfer.Status == domain.WalletTransferStatusPending && transfer.Reference != "" && transfer.CreatedAt.Before(createdBefore) {
+			pending = append(pending, transfer)
+		}
+	}
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending, nil
+}
+
+func (r *fakeWalletRepository) UpdateTransfer(transfer *domain.WalletTransfer) error {
+	return nil
+}
+
+// fakeCouponRepository is an in-memory CouponRepository. Redemptions and
+// releases hold its lock while they change a coupon, as the row lock does in
+// the database.
+type fakeCouponRepository struct {
+	mu          sync.Mutex
+	coupons     map[string]*domain.Coupon
+	redemptions []*domain.CouponRedemption
+}
+
+func newFakeCouponRepository() *fakeCouponRepository {
+	return &fakeCouponRepository{coupons: make(map[string]*domain.Coupon)}
+}
+
+func (r *fakeCouponRepository) Save(coupon *domain.Coupon) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.coupons {
+		if existing.Code == coupon.Code {
+			return errors.ConflictError("duplicate coupon code")
+		}
+	}
+	stored := *coupon
+	r.coupons[coupon.ID] = &stored
+	return nil
+}
+
+func (r *fakeCouponRepository) FindByID(id string) (*domain.Coupon, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.coupons[id]
+	if !ok {
+		return nil, errors.NotFoundError("coupon not found")
+	}
+	coupon := *stored
+	return &coupon, nil
+}
+
+func (r *fakeCouponRepository) FindByCode(code string) (*domain.Coupon, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.coupons {
+		if stored.Code == code {
+			coupon := *stored
+			return &coupon, nil
+		}
+	}
+	return nil, errors.NotFoundError("coupon not found")
+}
+
+func (r *fakeCouponRepository) FindAll(limit, offset int) ([]*domain.Coupon, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var coupons []*domain.Coupon
+	for _, stored := range r.coupons {
+		coupon := *stored
+		coupons = append(coupons, &coupon)
+	}
+	sort.Slice(coupons, func(i, j int) bool { return coupons[i].CreatedAt.After(coupons[j].CreatedAt) })
+	total := int64(len(coupons))
+	if offset >= len(coupons) {
+		return nil, total, nil
+	}
+	coupons = coupons[offset:]
+	if len(coupons) > limit {
+		coupons = coupons[:limit]
+	}
+	return coupons, total, nil
+}
+
+func (r *fakeCouponRepository) Update(coupon *domain.Coupon) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.coupons[coupon.ID]
+	if !ok {
+		return errors.NotFoundError("coupon not found")
+	}
+	updated := *coupon
+	updated.Redemptions = stored.Redemptions
+	*stored = updated
+	return nil
+}
+
+func (r *fakeCouponRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.coupons[id]; !ok {
+		return errors.NotFoundError("coupon not found")
+	}
+	for _, redemption := range r.redemptions {
+		if redemption.CouponID == id {
+			return errors.ConflictError("a coupon orders used cannot be deleted; deactivate it instead")
+		}
+	}
+	delete(r.coupons, id)
+	return nil
+}
+
+func (r *fakeCouponRepository) Redeem(code string, orderID ids.OrderID, buyerID ids.UserID, redeem func(coupon *domain.Coupon, buyerRedemptions int) (*domain.CouponRedemption, error)) (*domain.CouponRedemption, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var stored *domain.Coupon
+	for _, coupon := range r.coupons {
+		if coupon.Code == code {
+			stored = coupon
+		}
+	}
+	if stored == nil {
+		return nil, errors.NotFoundError("coupon not found")
+	}
+
+	buyerRedemptions := 0
+	for _, redemption := range r.redemptions {
+		if redemption.CouponID != stored.ID || redemption.ReleasedAt != nil {
+			continue
+		}
+		if redemption.OrderID == orderID {
+			return redemption, nil
+		}
+		if redemption.BuyerID == buyerID {
+			buyerRedemptions++
+		}
+	}
+
+	coupon := *stored
+	redemption, err := redeem(&coupon, buyerRedemptions)
+	if err != nil {
+		return nil, err
+	}
+	r.redemptions = append(r.redemptions, redemption)
+	*stored = coupon
+	return redemption, nil
+}
+
+func (r *fakeCouponRepository) Release(orderID ids.OrderID, release func(coupon *domain.Coupon, redemption *domain.CouponRedemption) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.redemptions {
+		if stored.OrderID != orderID || stored.ReleasedAt != nil {
+			continue
+		}
+		coupon := *r.coupons[stored.CouponID]
+		redemption := *stored
+		if release(&coupon, &redemption) {
+			*r.coupons[stored.CouponID] = coupon
+			*stored = redemption
+		}
+		return nil
+	}
+	return nil
+}
+
+func (r *fakeCouponRepository) FindRedemptions(couponID string, limit, offset int) ([]*domain.CouponRedemption, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var redemptions []*domain.CouponRedemption
+	for i := len(r.redemptions) - 1; i >= 0; i-- {
+		if r.redemptions[i].CouponID == couponID {
+			redemptions = append(redemptions, r.redemptions[i])
+		}
+	}
+	total := int64(len(redemptions))
+	if offset >= len(redemptions) {
+		return nil, total, nil
+	}
+	redemptions = redemptions[offset:]
+	if len(redemptions) > limit {
+		redemptions = redemptions[:limit]
+	}
+	return redemptions, total, nil
+}
+
+// fakePolicyRules evaluates every number rule with a fixed function
+type fakePolicyRules struct {
+	number func(key string, facts rules.Facts) float64
+}
+
+func (r *fakePolicyRules) Number(key string, facts rules.Facts) float64 {
+	return r.number(key, facts)
+}
+
+// fakeSagaRepository is an in-memory SagaRepository
+type fakeSagaRepository struct {
+	mu    sync.Mutex
+	sagas map[string]*domain.CheckoutSaga
+}
+
+func newFakeSagaRepository() *fakeSagaRepository {
+	return &fakeSagaRepository{sagas: make(map[string]*domain.CheckoutSaga)}
+}
+
+func (r *fakeSagaRepository) Save(saga *domain.CheckoutSaga) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sagas[saga.ID] = saga
+	return nil
+}
+
+func (r *fakeSagaRepository) FindByID(id string) (*domain.CheckoutSaga, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if saga, ok := r.sagas[id]; ok {
+		return saga, nil
+	}
+	return nil, errors.NotFoundError("saga not found")
+}
+
+func (r *fakeSagaRepository) FindByOrder(orderID ids.OrderID) (*domain.CheckoutSaga, error) {
+	sagas := r.filter(func(s *domain.CheckoutSaga) bool { return s.OrderID == orderID })
+	if len(sagas) == 0 {
+		return nil, errors.NotFoundError("saga not found")
+	}
+	return sagas[0], nil
+}
+
+func (r *fakeSagaRepository) FindTimedOut(now time.Time, limit int) ([]*domain.CheckoutSaga, error) {
+	return r.page(r.filter(func(s *domain.CheckoutSaga) bool { return s.TimedOut(now) }), limit, 0), nil
+}
+
+func (r *fakeSagaRepository) FindCompensating(limit int) ([]*domain.CheckoutSaga, error) {
+	return r.page(r.filter(func(s *domain.CheckoutSaga) bool { return s.Status == domain.SagaStatusCompensating }), limit, 0), nil
+}
+
+func (r *fakeSagaRepository) FindStuck(now time.Time, limit, offset int) ([]*domain.CheckoutSaga, int64, error) {
+	stuck := r.filter(func(s *domain.CheckoutSaga) bool { return s.IsStuck(now) })
+	return r.page(stuck, limit, offset), int64(len(stuck)), nil
+}
+
+func (r *fakeSagaRepository) Update(saga *domain.CheckoutSaga) error {
+	return r.Save(saga)
+}
+
+// filter returns the matching sagas, oldest first
+func (r *fakeSagaRepository) filter(match func(*domain.CheckoutSaga) bool) []*domain.CheckoutSaga {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sagas []*domain.CheckoutSaga
+	for _, saga := range r.sagas {
+		if match(saga) {
+			sagas = append(sagas, saga)
+		}
+	}
+	sort.Slice(sagas, func(i, j int) bool { return sagas[i].CreatedAt.Before(sagas[j].CreatedAt) })
+	return sagas
+}
+
+func (r *fakeSagaRepository) page(sagas []*domain.CheckoutSaga, limit, offset int) []*domain.CheckoutSaga {
+	if offset >= len(sagas) {
+		return []*domain.CheckoutSaga{}
+	}
+	sagas = sagas[offset:]
+	if len(sagas) > limit {
+		sagas = sagas[:limit]
+	}
+	return sagas
+}
+
+// fakeFraudRepository is an in-memory FraudRepository
+type fakeFraudRepository struct {
+	mu     sync.Mutex
+	checks map[ids.OrderID]*domain.FraudCheck
+	phones map[string]*domain.BlockedPhone
+}
+
+func newFakeFraudRepository() *fakeFraudRepository {
+	return &fakeFraudRepository{
+		checks: make(map[ids.OrderID]*domain.FraudCheck),
+		phones: make(map[string]*domain.BlockedPhone),
+	}
+}
+
+func (r *fakeFraudRepository) SaveCheck(check *domain.FraudCheck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.checks[check.OrderID]; ok {
+		return errors.ConflictError("duplicate fraud check")
+	}
+	stored := *check
+	r.checks[check.OrderID] = &stored
+	return nil
+}
+
+func (r *fakeFraudRepository) FindCheckByOrder(orderID ids.OrderID) (*domain.FraudCheck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.checks[orderID]
+	if !ok {
+		return nil, errors.NotFoundError("fraud check not found")
+	}
+	check := *stored
+	return &check, nil
+}
+
+func (r *fakeFraudRepository) UpdateCheck(check *domain.FraudCheck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.checks[check.OrderID]; !ok {
+		return errors.NotFoundError("fraud check not found")
+	}
+	stored := *check
+	r.checks[check.OrderID] = &stored
+	return nil
+}
+
+func (r *fakeFraudRepository) FindChecks(decision domain.FraudDecision, now time.Time, limit, offset int) ([]*domain.FraudCheck, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var checks []*domain.FraudCheck
+	for _, stored := range r.checks {
+		if stored.Decision != decision || (stored.Held() && !stored.ReviewDueAt.After(now)) {
+			continue
+		}
+		check := *stored
+		checks = append(checks, &check)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].CreatedAt.Before(checks[j].CreatedAt) })
+	total := int64(len(checks))
+	if offset >= len(checks) {
+		return nil, total, nil
+	}
+	checks = checks[offset:]
+	if len(checks) > limit {
+		checks = checks[:limit]
+	}
+	return checks, total, nil
+}
+
+func (r *fakeFraudRepository) FindChecksSince(since time.Time, limit int) ([]*domain.FraudCheck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var checks []*domain.FraudCheck
+	for _, stored := range r.checks {
+		if !stored.CreatedAt.Before(since) {
+			check := *stored
+			checks = append(checks, &check)
+		}
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].CreatedAt.After(checks[j].CreatedAt) })
+	if len(checks) > limit {
+		checks = checks[:limit]
+	}
+	return checks, nil
+}
+
+func (r *fakeFraudRepository) BlockPhone(phone *domain.BlockedPhone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.phones[phone.PhoneNumber]; ok {
+		return errors.ConflictError("the phone number is blocked already")
+	}
+	stored := *phone
+	r.phones[phone.PhoneNumber] = &stored
+	return nil
+}
+
+func (r *fakeFraudRepository) UnblockPhone(phoneNumber string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.phones[phoneNumber]; !ok {
+		return errors.NotFoundError("the phone number is not blocked")
+	}
+	delete(r.phones, phoneNumber)
+	return nil
+}
+
+func (r *fakeFraudRepository) FindBlockedPhones(limit, offset int) ([]*domain.BlockedPhone, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var phones []*domain.BlockedPhone
+	for _, stored := range r.phones {
+		phone := *stored
+		phones = append(phones, &phone)
+	}
+	sort.Slice(phones, func(i, j int) bool { return phones[i].CreatedAt.After(phones[j].CreatedAt) })
+	total := int64(len(phones))
+	if offset >= len(phones) {
+		return nil, total, nil
+	}
+	phones = phones[offset:]
+	if len(phones) > limit {
+		phones = phones[:limit]
+	}
+	return phones, total, nil
+}
+
+func (r *fakeFraudRepository) AnyPhoneBlocked(phoneNumbers []string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, phoneNumber := range phoneNumbers {
+		if _, ok := r.phones[phoneNumber]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeBuyers serves buyers' accounts and addresses from memory
+type fakeBuyers struct {
+	users     map[ids.UserID]*users.User
+	addresses map[ids.UserID][]*users.Address
+}
+
+func (b *fakeBuyers) GetUser(ctx context.Context, userID ids.UserID) (*users.User, error) {
+	if user, ok := b.users[userID]; ok {
+		return user, nil
+	}
+	return nil, errors.NotFoundError("user not found")
+}
+
+func (b *fakeBuyers) ListAddresses(ctx context.Context, userID ids.UserID) ([]*users.Address, error) {
+	return b.addresses[userID], nil
+}
+
+// fakeClaimRepository is an in-memory ClaimRepository
+type fakeClaimRepository struct {
+	mu     sync.Mutex
+	claims map[string]*domain.Claim
+}
+
+func newFakeClaimRepository() *fakeClaimRepository {
+	return &fakeClaimRepository{claims: make(map[string]*domain.Claim)}
+}
+
+func (r *fakeClaimRepository) Save(claim *domain.Claim) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.claims {
+		if claim.Reference != "" && existing.Provider == claim.Provider && existing.Reference == claim.Reference {
+			return errors.ConflictError("the dispute was reported already")
+		}
+	}
+	stored := *claim
+	r.claims[claim.ID] = &stored
+	return nil
+}
+
+func (r *fakeClaimRepository) FindByID(id string) (*domain.Claim, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.claims[id]
+	if !ok {
+		return nil, errors.NotFoundError("claim not found")
+	}
+	claim := *stored
+	return &claim, nil
+}
+
+func (r *fakeClaimRepository) FindByReference(provider, reference string) (*domain.Claim, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.claims {
+		if stored.Provider == provider && stored.Reference == reference {
+			claim := *stored
+			return &claim, nil
+		}
+	}
+	return nil, errors.NotFoundError("claim not found")
+}
+
+func (r *fakeClaimRepository) FindByOrder(orderID ids.OrderID) ([]*domain.Claim, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claims []*domain.Claim
+	for _, stored := range r.claims {
+		if stored.OrderID == orderID {
+			claim := *stored
+			claims = append(claims, &claim)
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].CreatedAt.Before(claims[j].CreatedAt) })
+	return claims, nil
+}
+
+func (r *fakeClaimRepository) FindAll(filter domain.ClaimFilter, limit, offset int) ([]*domain.Claim, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claims []*domain.Claim
+	for _, stored := range r.claims {
+		if (filter.Status != "" && stored.Status != filter.Status) || (filter.SellerID != "" && stored.SellerID != filter.SellerID) {
+			continue
+		}
+		claim := *stored
+		claims = append(claims, &claim)
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].CreatedAt.After(claims[j].CreatedAt) })
+	total := int64(len(claims))
+	if offset >= len(claims) {
+		return nil, total, nil
+	}
+	claims = claims[offset:]
+	if len(claims) > limit {
+		claims = claims[:limit]
+	}
+	return claims, total, nil
+}
+
+func (r *fakeClaimRepository) Update(claim *domain.Claim) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.claims[claim.ID]; !ok {
+		return errors.NotFoundError("claim not found")
+	}
+	stored := *claim
+	r.claims[claim.ID] = &stored
+	return nil
+}
+
+// fakeAccountMergeRepository reassigns the orders and escrows of in-memory
+// repositories
+type fakeAccountMergeRepository struct {
+	orders  *fakeOrderRepository
+	escrows *fakeEscrowRepository
+}
+
+func (r *fakeAccountMergeRepository) ReassignUser(fromUserID, toUserID ids.UserID) (int64, error) {
+	r.orders.mu.Lock()
+	var moved int64
+	for _, order := range r.orders.orders {
+		if order.BuyerID == fromUserID || order.SellerID == fromUserID {
+			moved++
+		}
+		if order.BuyerID == fromUserID {
+			order.BuyerID = toUserID
+		}
+		if order.SellerID == fromUserID {
+			order.SellerID = toUserID
+		}
+	}
+	r.orders.mu.Unlock()
+
+	r.escrows.mu.Lock()
+	defer r.escrows.mu.Unlock()
+	for _, escrow := range r.escrows.escrows {
+		if escrow.BuyerID == fromUserID {
+			escrow.BuyerID = toUserID
+		}
+		if escrow.SellerID == fromUserID {
+			escrow.SellerID = toUserID
+		}
+	}
+	return moved, nil
+}
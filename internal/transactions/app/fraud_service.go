@@ -0,0 +1,273 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/transactions/domain"
+	users "dongome/internal/users/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/ids"
+)
+
+// defaultFraudPageSize is how many fraud checks or blocked phone numbers are
+// listed when no limit is given
+const defaultFraudPageSize = 20
+
+// BuyerAccounts looks up buyers' accounts. It is implemented by the users
+// context.
+type BuyerAccounts interface {
+	GetUser(ctx context.Context, userID ids.UserID) (*users.User, error)
+}
+
+// BuyerAddresses lists buyers' delivery addresses. It is implemented by the
+// users context.
+type BuyerAddresses interface {
+	ListAddresses(ctx context.Context, userID ids.UserID) ([]*users.Address, error)
+}
+
+// OrderScreening checks orders for fraud as they are placed, and records the
+// review of the orders it held. It is implemented by FraudService.
+type OrderScreening interface {
+	ScreenOrder(ctx context.Context, order *domain.Order, listing *listings.Listing) (*domain.FraudCheck, error)
+	RecordReview(ctx context.Context, orderID ids.OrderID, decision domain.FraudDecision, reviewerID ids.UserID, note string) error
+}
+
+// FraudChecksQuery represents the query to list fraud checks, the orders
+// held for review when no decision is given
+type FraudChecksQuery struct {
+	Decision string `form:"decision" binding:"omitempty,oneof=passed held released declined"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset   int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// FraudChecks represents a page of fraud checks
+type FraudChecks struct {
+	Checks []*domain.FraudCheck `json:"checks"`
+	Total  int64                `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+// BlockPhoneCommand represents an administrator blocking a phone number used
+// for fraud
+type BlockPhoneCommand struct {
+	AdminID     ids.UserID `json:"-"`
+	PhoneNumber string     `json:"phone_number" binding:"required,max=20"`
+	Reason      string     `json:"reason" binding:"required,max=500"`
+}
+
+// BlockedPhonesQuery represents the query to list blocked phone numbers
+type BlockedPhonesQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// BlockedPhones represents a page of blocked phone numbers
+type BlockedPhones struct {
+	Phones []*domain.BlockedPhone `json:"phones"`
+	Total  int64                  `json:"total"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+}
+
+// FraudService scores orders as they are placed and holds likely fraud for
+// an administrator's review
+type FraudService struct {
+	fraudRepo    domain.FraudRepository
+	orderRepo    domain.OrderRepository
+	accounts     BuyerAccounts
+	addresses    BuyerAddresses
+	rules        PolicyRules
+	holdScore    float64
+	reviewWindow time.Duration
+	clock        clock.Clock
+}
+
+// NewFraudService creates a new fraud service. FraudScoreRule scores each
+// order; orders scoring holdScore or more are held for review, and
+// cancelled unless an administrator released them within reviewWindow. clk
+// may be nil to use the system clock.
+func NewFraudService(fraudRepo domain.FraudRepository, orderRepo domain.OrderRepository, accounts BuyerAccounts, addresses BuyerAddresses, rules PolicyRules, holdScore float64, reviewWindow time.Duration, clk clock.Clock) *FraudService {
+	return &FraudService{
+		fraudRepo:    fraudRepo,
+		orderRepo:    orderRepo,
+		accounts:     accounts,
+		addresses:    addresses,
+		rules:        rules,
+		holdScore:    holdScore,
+		reviewWindow: reviewWindow,
+		clock:        clock.OrSystem(clk),
+	}
+}
+
+// ScreenOrder scores an order about to be placed for the listing, and
+// records the check. The order is to be held if the check is.
+func (s *FraudService) ScreenOrder(ctx context.Context, order *domain.Order, listing *listings.Listing) (*domain.FraudCheck, error) {
+	signals, err := s.signals(ctx, order, listing)
+	if err != nil {
+		return nil, err
+	}
+
+	score := s.rules.Number(FraudScoreRule, FraudScoreFacts(signals))
+	check := domain.NewFraudCheck(order, signals, score, s.holdScore, s.reviewWindow, s.clock.Now())
+	if err := db.WithRetry(ctx, func() error { return s.fraudRepo.SaveCheck(check) }); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// signals gathers what is known about the buyer of an order about to be
+// placed. The buyer's delivery address is their default one, or the first
+// they saved.
+func (s *FraudService) signals(ctx context.Context, order *domain.Order, listing *listings.Listing) (domain.FraudSignals, error) {
+	now := s.clock.Now()
+	buyer, err := s.accounts.GetUser(ctx, order.BuyerID)
+	if err != nil {
+		return domain.FraudSignals{}, err
+	}
+	addresses, err := s.addresses.ListAddresses(ctx, order.BuyerID)
+	if err != nil {
+		return domain.FraudSignals{}, err
+	}
+	lastHour, err := s.orderRepo.CountByBuyerSince(order.BuyerID, now.Add(-time.Hour))
+	if err != nil {
+		return domain.FraudSignals{}, err
+	}
+	lastDay, err := s.orderRepo.CountByBuyerSince(order.BuyerID, now.Add(-24*time.Hour))
+	if err != nil {
+		return domain.FraudSignals{}, err
+	}
+
+	signals := domain.FraudSignals{
+		AccountAgeDays: int(now.Sub(buyer.CreatedAt).Hours() / 24),
+		// The order is not saved yet
+		OrdersLastHour: int(lastHour) + 1,
+		OrdersLastDay:  int(lastDay) + 1,
+		Amount:         order.Amount.Major(),
+		Currency:       order.Amount.Currency,
+	}
+
+	phones := []string{}
+	if buyer.PhoneNumber != "" {
+		phones = append(phones, buyer.PhoneNumber)
+	}
+	if address := deliveryAddress(addresses); address != nil {
+		phones = append(phones, address.PhoneNumber)
+		signals.LocationMismatch = address.Location.Region != "" && listing.Location.Region != "" &&
+			!strings.EqualFold(address.Location.Region, listing.Location.Region)
+	}
+	if len(phones) > 0 {
+		if signals.PhoneBlocked, err = s.fraudRepo.AnyPhoneBlocked(phones); err != nil {
+			return domain.FraudSignals{}, err
+		}
+	}
+	return signals, nil
+}
+
+// deliveryAddress picks the address a buyer's orders are delivered to
+func deliveryAddress(addresses []*users.Address) *users.Address {
+	for _, address := range addresses {
+		if address.IsDefault {
+			return address
+		}
+	}
+	if len(addresses) > 0 {
+		return addresses[0]
+	}
+	return nil
+}
+
+// RecordReview records an administrator releasing or declining an order
+// held for review. Only the first review of an order is recorded; others
+// fail with a conflict error.
+func (s *FraudService) RecordReview(ctx context.Context, orderID ids.OrderID, decision domain.FraudDecision, reviewerID ids.UserID, note string) error {
+	check, err := s.fraudRepo.FindCheckByOrder(orderID)
+	if err != nil {
+		return err
+	}
+	if err := check.Review(decision, reviewerID, note, s.clock.Now()); err != nil {
+		return err
+	}
+	return db.WithRetry(ctx, func() error { return s.fraudRepo.UpdateCheck(check) })
+}
+
+// ListChecks lists fraud checks with a decision, oldest first. Without a
+// decision it lists the orders awaiting review, leaving out those whose
+// review is overdue, as they are being cancelled.
+func (s *FraudService) ListChecks(ctx context.Context, query FraudChecksQuery) (*FraudChecks, error) {
+	decision := domain.FraudDecision(query.Decision)
+	if decision == "" {
+		decision = domain.FraudDecisionHeld
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultFraudPageSize
+	}
+
+	checks, total, err := s.fraudRepo.FindChecks(decision, s.clock.Now(), limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &FraudChecks{
+		Checks: checks,
+		Total:  total,
+		Limit:  limit,
+		Offset: query.Offset,
+	}, nil
+}
+
+// GetCheck retrieves the fraud check of an order
+func (s *FraudService) GetCheck(ctx context.Context, orderID ids.OrderID) (*domain.FraudCheck, error) {
+	return s.fraudRepo.FindCheckByOrder(orderID)
+}
+
+// BlockPhone blocks a phone number, so orders of buyers using it score higher
+func (s *FraudService) BlockPhone(ctx context.Context, cmd BlockPhoneCommand) (*domain.BlockedPhone, error) {
+	phoneNumber, err := users.NormalizePhoneNumber(cmd.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	phone := &domain.BlockedPhone{
+		PhoneNumber: phoneNumber,
+		Reason:      strings.TrimSpace(cmd.Reason),
+		BlockedBy:   cmd.AdminID,
+		CreatedAt:   s.clock.Now(),
+	}
+	if err := db.WithRetry(ctx, func() error { return s.fraudRepo.BlockPhone(phone) }); err != nil {
+		return nil, err
+	}
+	return phone, nil
+}
+
+// UnblockPhone unblocks a phone number. Orders already held stay held.
+func (s *FraudService) UnblockPhone(ctx context.Context, raw string) error {
+	phoneNumber, err := users.NormalizePhoneNumber(raw)
+	if err != nil {
+		return err
+	}
+	return db.WithRetry(ctx, func() error { return s.fraudRepo.UnblockPhone(phoneNumber) })
+}
+
+// ListBlockedPhones lists blocked phone numbers, most recently blocked first
+func (s *FraudService) ListBlockedPhones(ctx context.Context, query BlockedPhonesQuery) (*BlockedPhones, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultFraudPageSize
+	}
+
+	phones, total, err := s.fraudRepo.FindBlockedPhones(limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockedPhones{
+		Phones: phones,
+		Total:  total,
+		Limit:  limit,
+		Offset: query.Offset,
+	}, nil
+}
@@ -0,0 +1,174 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	users "dongome/internal/users/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/rules"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fraudFixture places orders screened by the default fraud score rule
+type fraudFixture struct {
+	orders       *fakeOrderRepository
+	reservations *fakeListingReservations
+	fraudRepo    *fakeFraudRepository
+	eventBus     *fakeEventBus
+	fraud        *app.FraudService
+	service      *app.OrderService
+}
+
+func newFraudFixture(t *testing.T, buyers *fakeBuyers) *fraudFixture {
+	t.Helper()
+	definition := app.FraudScoreDefinition()
+	expression, err := rules.Compile(definition.Default, definition.Variables)
+	require.NoError(t, err)
+	scoring := &fakePolicyRules{number: func(key string, facts rules.Facts) float64 {
+		score, err := expression.Eval(facts)
+		require.NoError(t, err)
+		return score.(float64)
+	}}
+
+	f := &fraudFixture{
+		orders:       newFakeOrderRepository(),
+		reservations: newFakeListingReservations(),
+		fraudRepo:    newFakeFraudRepository(),
+		eventBus:     &fakeEventBus{},
+	}
+	f.fraud = app.NewFraudService(f.fraudRepo, f.orders, buyers, buyers, scoring, 60, 24*time.Hour, nil)
+	f.service = app.NewOrderService(f.orders, f.reservations, &fakeNotificationPreferences{}, nil, f.eventBus, 30*time.Minute, "", nil, nil, f.fraud, nil)
+	return f
+}
+
+// placeOrder places buyer-a's order for a new listing
+func (f *fraudFixture) placeOrder(t *testing.T) (*domain.Order, *listings.Listing) {
+	t.Helper()
+	listing := newActiveListing(t, "seller-a")
+	f.reservations.listings[listing.ID] = listing
+	order, err := f.service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	return order, listing
+}
+
+func newFraudBuyers(age time.Duration, region string) *fakeBuyers {
+	return &fakeBuyers{
+		users: map[ids.UserID]*users.User{
+			"buyer-a": {ID: "buyer-a", PhoneNumber: "+233201234567", CreatedAt: time.Now().Add(-age)},
+		},
+		addresses: map[ids.UserID][]*users.Address{
+			"buyer-a": {
+				{ID: "address-a", UserID: "buyer-a", PhoneNumber: "+233241234567", Location: listings.Location{Region: region}},
+			},
+		},
+	}
+}
+
+func TestFraudService_ScreenOrderSignals(t *testing.T) {
+	f := newFraudFixture(t, newFraudBuyers(2*time.Hour, "ashanti"))
+
+	order, _ := f.placeOrder(t)
+	assert.Equal(t, domain.OrderStatusPendingPayment, order.Status, "a new account ordering from another region scores below the hold score")
+	check, err := f.fraud.GetCheck(context.Background(), order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FraudDecisionPassed, check.Decision)
+	assert.Equal(t, float64(50), check.Score)
+	assert.Zero(t, check.Signals.AccountAgeDays)
+	assert.True(t, check.Signals.LocationMismatch)
+	assert.Equal(t, 1, check.Signals.OrdersLastHour)
+	assert.False(t, check.Signals.PhoneBlocked)
+
+	// The address's phone number is blocked, however it is written
+	_, err = f.fraud.BlockPhone(context.Background(), app.BlockPhoneCommand{AdminID: "admin-a", PhoneNumber: "024 123 4567", Reason: "chargebacks"})
+	require.NoError(t, err)
+	_, err = f.fraud.BlockPhone(context.Background(), app.BlockPhoneCommand{AdminID: "admin-a", PhoneNumber: "+233241234567", Reason: "again"})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeConflict, err.(*errors.DomainError).Code)
+
+	order, listing := f.placeOrder(t)
+	assert.Equal(t, domain.OrderStatusHeldForReview, order.Status)
+	assert.True(t, listing.IsReserved(), "the listing waits for the review")
+	check, err = f.fraud.GetCheck(context.Background(), order.ID)
+	require.NoError(t, err)
+	assert.True(t, check.Signals.PhoneBlocked)
+	assert.Equal(t, 2, check.Signals.OrdersLastHour)
+	assert.Equal(t, *check.ReviewDueAt, order.PaymentDueAt)
+
+	held, err := f.fraud.ListChecks(context.Background(), app.FraudChecksQuery{})
+	require.NoError(t, err)
+	require.Len(t, held.Checks, 1)
+	assert.Equal(t, order.ID, held.Checks[0].OrderID)
+
+	require.NoError(t, f.fraud.UnblockPhone(context.Background(), "0241234567"))
+	assert.Error(t, f.fraud.UnblockPhone(context.Background(), "0241234567"))
+}
+
+func TestOrderService_ReleaseHeldOrder(t *testing.T) {
+	f := newFraudFixture(t, newFraudBuyers(time.Hour, ""))
+	_, err := f.fraud.BlockPhone(context.Background(), app.BlockPhoneCommand{AdminID: "admin-a", PhoneNumber: "0201234567", Reason: "stolen phone"})
+	require.NoError(t, err)
+	order, listing := f.placeOrder(t)
+	require.Equal(t, domain.OrderStatusHeldForReview, order.Status)
+
+	released, err := f.service.ReleaseHeldOrder(context.Background(), app.ReleaseOrderCommand{OrderID: order.ID, AdminID: "admin-a", Note: "buyer called in"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPendingPayment, released.Status)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), released.PaymentDueAt, time.Minute)
+	assert.NotNil(t, released.ReviewedAt)
+	assert.True(t, listing.IsReserved())
+	assert.Len(t, f.eventBus.eventsOfType(domain.OrderReleasedEvent), 1)
+
+	check, err := f.fraud.GetCheck(context.Background(), order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FraudDecisionReleased, check.Decision)
+	assert.Equal(t, "buyer called in", check.ReviewNote)
+
+	_, err = f.service.DeclineHeldOrder(context.Background(), app.DeclineOrderCommand{OrderID: order.ID, AdminID: "admin-b", Reason: "late"})
+	require.Error(t, err, "orders are reviewed once")
+	assert.Equal(t, errors.ErrCodeConflict, err.(*errors.DomainError).Code)
+}
+
+func TestSagaOrchestrator_HeldOrderReview(t *testing.T) {
+	ctx := context.Background()
+	f := newFraudFixture(t, newFraudBuyers(0, ""))
+	orchestrator := app.NewSagaOrchestrator(newFakeSagaRepository(), f.orders, f.reservations, nil, f.eventBus, 15*time.Minute, nil)
+	_, err := f.fraud.BlockPhone(ctx, app.BlockPhoneCommand{AdminID: "admin-a", PhoneNumber: "0201234567", Reason: "stolen phone"})
+	require.NoError(t, err)
+
+	// A released order's saga waits for the new payment deadline
+	order, _ := f.placeOrder(t)
+	saga, err := orchestrator.StartCheckout(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, order.PaymentDueAt.Add(15*time.Minute), *saga.DeadlineAt, "the saga waits for the review")
+	order, err = f.service.ReleaseHeldOrder(ctx, app.ReleaseOrderCommand{OrderID: order.ID, AdminID: "admin-a"})
+	require.NoError(t, err)
+	saga, err = orchestrator.ReleaseCheckout(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SagaStatusRunning, saga.Status)
+	assert.Equal(t, order.PaymentDueAt.Add(15*time.Minute), *saga.DeadlineAt)
+
+	// A declined order's saga is undone, releasing the listing
+	order, listing := f.placeOrder(t)
+	_, err = orchestrator.StartCheckout(ctx, order.ID)
+	require.NoError(t, err)
+	order, err = f.service.DeclineHeldOrder(ctx, app.DeclineOrderCommand{OrderID: order.ID, AdminID: "admin-a", Reason: "stolen phone"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusCancelled, order.Status)
+	assert.Len(t, f.eventBus.eventsOfType(domain.OrderDeclinedEvent), 1)
+	saga, err = orchestrator.DeclineCheckout(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SagaStatusCompensated, saga.Status)
+	assert.False(t, listing.IsReserved())
+
+	check, err := f.fraud.GetCheck(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FraudDecisionDeclined, check.Decision)
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// defaultOrderHistoryPageSize is how many orders a page of history holds
+// when no limit is given
+const defaultOrderHistoryPageSize = 20
+
+// OrderHistoryQuery represents the query to list a buyer's or a seller's
+// orders. The date range is also accepted as created_at[gte] and
+// created_at[lte]; both ends are inclusive.
+type OrderHistoryQuery struct {
+	Status string     `form:"status" binding:"omitempty,oneof=pending_payment held_for_review paid abandoned cancelled"`
+	From   *time.Time `form:"from" filter:"created_at,gte" time_format:"2006-01-02"`
+	To     *time.Time `form:"to" filter:"created_at,lte" time_format:"2006-01-02"`
+	Limit  int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	// Cursor is the next_cursor of the previous page
+	Cursor string `form:"cursor"`
+}
+
+// OrderHistory represents a page of a buyer's or a seller's orders, newest first
+type OrderHistory struct {
+	Orders []*domain.Order `json:"orders"`
+	Limit  int             `json:"limit"`
+	// NextCursor fetches the next page; it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// OrderDetail represents an order with every step it went through
+type OrderDetail struct {
+	*domain.Order
+	Timeline []domain.OrderTimelineEntry `json:"timeline"`
+}
+
+// OrderHistoryService lists buyers' and sellers' orders and tells how each
+// order went, from the order, its escrow and its refunds
+type OrderHistoryService struct {
+	orderRepo  domain.OrderRepository
+	escrowRepo domain.EscrowRepository
+	refundRepo domain.RefundRepository
+}
+
+// NewOrderHistoryService creates a new order history service
+func NewOrderHistoryService(orderRepo domain.OrderRepository, escrowRepo domain.EscrowRepository, refundRepo domain.RefundRepository) *OrderHistoryService {
+	return &OrderHistoryService{
+		orderRepo:  orderRepo,
+		escrowRepo: escrowRepo,
+		refundRepo: refundRepo,
+	}
+}
+
+// ListBuyerOrders lists the orders a user placed, newest first
+func (s *OrderHistoryService) ListBuyerOrders(ctx context.Context, buyerID ids.UserID, query OrderHistoryQuery) (*OrderHistory, error) {
+	return s.list(domain.OrderHistoryFilter{BuyerID: buyerID}, query)
+}
+
+// ListSellerOrders lists the orders placed for a seller's listings, newest first
+func (s *OrderHistoryService) ListSellerOrders(ctx context.Context, sellerID ids.UserID, query OrderHistoryQuery) (*OrderHistory, error) {
+	return s.list(domain.OrderHistoryFilter{SellerID: sellerID}, query)
+}
+
+// list completes the filter from the query and fetches one more order than
+// the page holds to tell whether there is a next page
+func (s *OrderHistoryService) list(filter domain.OrderHistoryFilter, query OrderHistoryQuery) (*OrderHistory, error) {
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return nil, errors.ValidationError("from must be before to")
+	}
+	filter.Status = domain.OrderStatus(query.Status)
+	filter.CreatedFrom = query.From
+	// The end date is inclusive
+	if query.To != nil {
+		createdTo := query.To.AddDate(0, 0, 1)
+		filter.CreatedTo = &createdTo
+	}
+	if query.Cursor != "" {
+		cursor, err := domain.DecodeOrderCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = cursor
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultOrderHistoryPageSize
+	}
+
+	orders, err := s.orderRepo.FindHistory(filter, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	var nextCursor string
+	if len(orders) > limit {
+		orders = orders[:limit]
+		nextCursor = domain.NewOrderCursor(orders[limit-1]).Encode()
+	}
+
+	return &OrderHistory{
+		Orders:     orders,
+		Limit:      limit,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// GetOrder returns an order with its timeline to its buyer or seller
+func (s *OrderHistoryService) GetOrder(ctx context.Context, orderID ids.OrderID, userID ids.UserID) (*OrderDetail, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	// Other users are told the order does not exist rather than that it is not theirs
+	if order.BuyerID != userID && order.SellerID != userID {
+		return nil, errors.NotFoundError("order not found")
+	}
+
+	// Orders never paid have no escrow
+	escrow, err := s.escrowRepo.FindByOrder(orderID)
+	if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+		escrow, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	refunds, err := s.refundRepo.FindByOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderDetail{
+		Order:    order,
+		Timeline: domain.OrderTimeline(order, escrow, refunds),
+	}, nil
+}
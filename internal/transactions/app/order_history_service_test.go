@@ -0,0 +1,152 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// placedOrder saves an order the buyer placed with the seller at the given time
+func placedOrder(t *testing.T, orders *fakeOrderRepository, buyerID, sellerID ids.UserID, at time.Time) *domain.Order {
+	t.Helper()
+	order, err := domain.NewOrder(buyerID, sellerID, ids.NewListingID(), "electronics", "Phone", money.Cedis(100), at.Add(30*time.Minute), at)
+	require.NoError(t, err)
+	require.NoError(t, orders.Save(order))
+	return order
+}
+
+func orderIDs(orders []*domain.Order) []ids.OrderID {
+	var result []ids.OrderID
+	for _, order := range orders {
+		result = append(result, order.ID)
+	}
+	return result
+}
+
+func TestOrderHistoryService_PagesThroughOrdersNewestFirst(t *testing.T) {
+	orders := newFakeOrderRepository()
+	service := app.NewOrderHistoryService(orders, newFakeEscrowRepository(), &fakeRefundRepository{})
+	ctx := context.Background()
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var placed []*domain.Order
+	for i := 0; i < 4; i++ {
+		placed = append(placed, placedOrder(t, orders, "buyer-a", "seller-a", start.Add(time.Duration(i)*time.Hour)))
+	}
+	// Orders placed at the same time are still listed once each
+	placed = append(placed, placedOrder(t, orders, "buyer-a", "seller-b", start.Add(3*time.Hour)))
+	placedOrder(t, orders, "buyer-b", "seller-a", start)
+
+	var listed []*domain.Order
+	query := app.OrderHistoryQuery{Limit: 2}
+	for page := 0; ; page++ {
+		require.Less(t, page, 3)
+		history, err := service.ListBuyerOrders(ctx, "buyer-a", query)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(history.Orders), 2)
+		listed = append(listed, history.Orders...)
+		if history.NextCursor == "" {
+			break
+		}
+		query.Cursor = history.NextCursor
+	}
+
+	require.Len(t, listed, len(placed))
+	assert.ElementsMatch(t, orderIDs(placed), orderIDs(listed))
+	for i := 1; i < len(listed); i++ {
+		assert.False(t, listed[i].CreatedAt.After(listed[i-1].CreatedAt), "orders are listed newest first")
+	}
+
+	sold, err := service.ListSellerOrders(ctx, "seller-a", app.OrderHistoryQuery{})
+	require.NoError(t, err)
+	assert.Len(t, sold.Orders, 5)
+	assert.Empty(t, sold.NextCursor)
+
+	_, err = service.ListBuyerOrders(ctx, "buyer-a", app.OrderHistoryQuery{Cursor: "not-a-cursor"})
+	assert.Error(t, err)
+}
+
+func TestOrderHistoryService_FiltersByStatusAndDates(t *testing.T) {
+	orders := newFakeOrderRepository()
+	service := app.NewOrderHistoryService(orders, newFakeEscrowRepository(), &fakeRefundRepository{})
+	ctx := context.Background()
+
+	march1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	march2 := march1.AddDate(0, 0, 1)
+	early := placedOrder(t, orders, "buyer-a", "seller-a", march1.Add(-time.Hour))
+	onTheFirst := placedOrder(t, orders, "buyer-a", "seller-a", march1.Add(10*time.Hour))
+	lateOnTheSecond := placedOrder(t, orders, "buyer-a", "seller-a", march2.Add(23*time.Hour))
+	require.NoError(t, lateOnTheSecond.Cancel(march2.Add(23*time.Hour)))
+
+	history, err := service.ListSellerOrders(ctx, "seller-a", app.OrderHistoryQuery{From: &march1, To: &march2})
+	require.NoError(t, err)
+	assert.Equal(t, []ids.OrderID{lateOnTheSecond.ID, onTheFirst.ID}, orderIDs(history.Orders), "both ends of the range are inclusive")
+
+	history, err = service.ListSellerOrders(ctx, "seller-a", app.OrderHistoryQuery{Status: string(domain.OrderStatusPendingPayment)})
+	require.NoError(t, err)
+	assert.Equal(t, []ids.OrderID{onTheFirst.ID, early.ID}, orderIDs(history.Orders))
+
+	_, err = service.ListSellerOrders(ctx, "seller-a", app.OrderHistoryQuery{From: &march2, To: &march1})
+	require.Error(t, err)
+	domainErr, ok := err.(*errors.DomainError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ErrCodeValidation, domainErr.Code)
+}
+
+func TestOrderHistoryService_GetOrderWithItsTimeline(t *testing.T) {
+	orders := newFakeOrderRepository()
+	escrows := newFakeEscrowRepository()
+	refunds := &fakeRefundRepository{}
+	service := app.NewOrderHistoryService(orders, escrows, refunds)
+	ctx := context.Background()
+
+	placedAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	order := placedOrder(t, orders, "buyer-a", "seller-a", placedAt)
+
+	detail, err := service.GetOrder(ctx, order.ID, "buyer-a")
+	require.NoError(t, err)
+	require.Len(t, detail.Timeline, 1, "unpaid orders have no escrow")
+	assert.Equal(t, domain.OrderStepPlaced, detail.Timeline[0].Step)
+
+	paidAt := placedAt.Add(5 * time.Minute)
+	require.NoError(t, order.RequestPayment(domain.PaymentMethodMoMo, "momo", "payment-1", "233241234567", placedAt.Add(time.Minute)))
+	require.NoError(t, order.MarkPaid(paidAt))
+	escrow, err := domain.NewEscrow(order, 72*time.Hour, paidAt)
+	require.NoError(t, err)
+	require.NoError(t, escrows.Save(escrow))
+	refund, err := domain.NewRefund(order, nil, money.Cedis(40), "seller-a", "damaged", "key-1", paidAt.Add(time.Hour))
+	require.NoError(t, err)
+	require.True(t, refund.Succeed("tx-1", paidAt.Add(2*time.Hour)))
+	require.NoError(t, refunds.Save(refund))
+
+	detail, err = service.GetOrder(ctx, order.ID, "seller-a")
+	require.NoError(t, err)
+	var steps []domain.OrderTimelineStep
+	for _, entry := range detail.Timeline {
+		steps = append(steps, entry.Step)
+	}
+	assert.Equal(t, []domain.OrderTimelineStep{
+		domain.OrderStepPlaced,
+		domain.OrderStepPaymentRequested,
+		domain.OrderStepPaid,
+		domain.OrderStepFundsHeld,
+		domain.OrderStepRefundRequested,
+		domain.OrderStepRefunded,
+	}, steps)
+	assert.Equal(t, money.Cedis(40), *detail.Timeline[5].Amount)
+
+	_, err = service.GetOrder(ctx, order.ID, "someone-else")
+	require.Error(t, err)
+	domainErr, ok := err.(*errors.DomainError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ErrCodeNotFound, domainErr.Code, "other users are told the order does not exist")
+}
@@ -0,0 +1,1016 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+	"dongome/pkg/payments"
+)
+
+// defaultStatsWindow is the period abandonment stats cover when no range is given
+const defaultStatsWindow = 30 * 24 * time.Hour
+
+// ListingReservations holds listings for buyers while they pay. It is
+// implemented by the listings context.
+type ListingReservations interface {
+	ReserveListing(ctx context.Context, listingID ids.ListingID, buyerID ids.UserID, until time.Time) (*listings.Listing, error)
+	ReleaseListing(ctx context.Context, listingID ids.ListingID, buyerID ids.UserID) error
+}
+
+// NotificationPreferences tells which channels a user wants a category of
+// notifications on. It is implemented by the users context.
+type NotificationPreferences interface {
+	NotificationChannels(ctx context.Context, userID ids.UserID, category string) ([]string, error)
+}
+
+// orderUpdatesCategory is the notification category buyers' order updates belong to
+const orderUpdatesCategory = "order_updates"
+
+// CreateOrderCommand represents the command to place an order for a
+// listing, with the code of a coupon for a discount if the buyer has one
+type CreateOrderCommand struct {
+	BuyerID    ids.UserID    `json:"-"`
+	ListingID  ids.ListingID `json:"listing_id" binding:"required,uuid"`
+	CouponCode string        `json:"coupon_code" binding:"omitempty,max=32"`
+}
+
+// Statuses of payments, as payment providers report them
+const (
+	paymentSuccessful = "SUCCESSFUL"
+	paymentFailed     = "FAILED"
+	paymentPending    = "PENDING"
+)
+
+// paymentCallbackGrace is how long the callback of a payment is waited for
+// before the payment is looked up
+const paymentCallbackGrace = 2 * time.Minute
+
+// PaymentRequest asks a user to pay from their Mobile Money wallet
+type PaymentRequest struct {
+	// Reference is returned with the payment's result to tell what it was for
+	Reference   string
+	Amount      money.Money
+	Payer       string
+	Description string
+}
+
+// PaymentResult is how a requested payment went
+type PaymentResult struct {
+	Status string
+	Amount money.Money
+	// TransactionID is the provider's ID of a successful payment
+	TransactionID string
+	// Reason is why a failed payment failed, such as NOT_ENOUGH_FUNDS
+	Reason string
+}
+
+// PaymentGateway requests Mobile Money payments into wallets, whose results
+// are looked up
+type PaymentGateway interface {
+	RequestPayment(ctx context.Context, payment PaymentRequest) (string, error)
+	PaymentResult(ctx context.Context, reference string) (*PaymentResult, error)
+}
+
+// PaymentProviders finds the payment provider taking each payment method,
+// and the provider a payment was taken through. It is implemented by
+// payments.Registry.
+type PaymentProviders interface {
+	ForMethod(method payments.Method) (payments.PaymentProvider, error)
+	Provider(name string) (payments.PaymentProvider, error)
+}
+
+// PayOrderCommand represents a buyer paying for an order by a payment
+// method, momo when none is given. Mobile Money payments, whichever the
+// network, are made from the given phone number; providers other than MoMo
+// also require an email address.
+type PayOrderCommand struct {
+	OrderID ids.OrderID `json:"-"`
+	BuyerID ids.UserID  `json:"-"`
+	Method  string      `json:"method" binding:"omitempty,oneof=momo card vodafone_cash airteltigo_money"`
+	Phone   string      `json:"phone" binding:"required_unless=Method card,omitempty,e164"`
+	Email   string      `json:"email" binding:"omitempty,email,max=254"`
+}
+
+// ConfirmPaymentCommand represents a buyer entering the code their payment
+// asked for, such as a Vodafone Cash voucher or a one-time password
+type ConfirmPaymentCommand struct {
+	OrderID ids.OrderID `json:"-"`
+	BuyerID ids.UserID  `json:"-"`
+	Code    string      `json:"code" binding:"required,max=20"`
+}
+
+// OrderPayment is an order whose payment was started, with what the buyer
+// does next to complete it: approve it on their phone, complete it on
+// CheckoutURL, or enter a code with ConfirmPayment. Instructions are the
+// provider's, such as how to generate a Vodafone Cash voucher.
+type OrderPayment struct {
+	*domain.Order
+	Action       string `json:"action,omitempty"`
+	CheckoutURL  string `json:"checkout_url,omitempty"`
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// ReleaseOrderCommand represents an administrator letting the buyer pay for
+// an order held for review
+type ReleaseOrderCommand struct {
+	OrderID ids.OrderID `json:"-"`
+	AdminID ids.UserID  `json:"-"`
+	Note    string      `json:"note" binding:"max=500"`
+}
+
+// DeclineOrderCommand represents an administrator cancelling an order held
+// for review
+type DeclineOrderCommand struct {
+	OrderID ids.OrderID `json:"-"`
+	AdminID ids.UserID  `json:"-"`
+	Reason  string      `json:"reason" binding:"required,max=500"`
+}
+
+// AdvanceFulfillmentCommand represents the buyer or seller of a paid order
+// moving it to the next fulfillment status. Sellers shipping an order may
+// give the carrier and its tracking number.
+type AdvanceFulfillmentCommand struct {
+	OrderID        ids.OrderID `json:"-"`
+	UserID         ids.UserID  `json:"-"`
+	Status         string      `json:"status" binding:"required,oneof=pickup_arranged shipped in_transit delivered"`
+	Carrier        string      `json:"carrier" binding:"max=50"`
+	TrackingNumber string      `json:"tracking_number" binding:"max=64"`
+}
+
+// AbandonmentStatsQuery represents the date range of an abandonment report,
+// also accepted as created_at[gte] and created_at[lte]
+type AbandonmentStatsQuery struct {
+	From time.Time `form:"from" filter:"created_at,gte" time_format:"2006-01-02"`
+	To   time.Time `form:"to" filter:"created_at,lte" time_format:"2006-01-02"`
+}
+
+// OrderService handles order checkout use cases
+type OrderService struct {
+	orderRepo      domain.OrderRepository
+	listings       ListingReservations
+	preferences    NotificationPreferences
+	providers      PaymentProviders
+	eventBus       events.EventBus
+	paymentTimeout time.Duration
+	resumeURL      string
+	rules          PolicyRules
+	coupons        CheckoutCoupons
+	fraud          OrderScreening
+	clock          clock.Clock
+}
+
+// NewOrderService creates a new order service. Buyers have paymentTimeout to
+// pay for an order; resumeURL is the deep link template sent to buyers who
+// abandon a checkout, with {order_id} replaced by the order. providers may be
+// nil where orders cannot be paid online. If rules is given,
+// PaymentWindowRule decides the payment window instead of paymentTimeout.
+// coupons may be nil where no coupons are accepted, and fraud where orders
+// are not screened for fraud. clk may be nil to use the system clock.
+func NewOrderService(orderRepo domain.OrderRepository, listings ListingReservations, preferences NotificationPreferences, providers PaymentProviders, eventBus events.EventBus, paymentTimeout time.Duration, resumeURL string, rules PolicyRules, coupons CheckoutCoupons, fraud OrderScreening, clk clock.Clock) *OrderService {
+	return &OrderService{
+		orderRepo:      orderRepo,
+		listings:       listings,
+		preferences:    preferences,
+		providers:      providers,
+		eventBus:       eventBus,
+		paymentTimeout: paymentTimeout,
+		resumeURL:      resumeURL,
+		rules:          rules,
+		coupons:        coupons,
+		fraud:          fraud,
+		clock:          clock.OrSystem(clk),
+	}
+}
+
+// CreateOrder places an order and reserves the listing for the buyer until
+// the payment is due. A coupon the buyer entered is redeemed on the order,
+// which then costs the listing's price less the coupon's discount. Orders
+// screened as likely fraud are held for review instead, with the listing
+// reserved until the review is due.
+func (s *OrderService) CreateOrder(ctx context.Context, cmd CreateOrderCommand) (*domain.Order, error) {
+	now := s.clock.Now()
+	dueAt := now.Add(s.paymentTimeout)
+	listing, err := s.listings.ReserveListing(ctx, cmd.ListingID, cmd.BuyerID, dueAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// The payment window may depend on the price, which is only known once
+	// the listing is reserved
+	if window := s.paymentWindow(listing.Price); window != s.paymentTimeout {
+		dueAt = now.Add(window)
+		if listing, err = s.listings.ReserveListing(ctx, cmd.ListingID, cmd.BuyerID, dueAt); err != nil {
+			s.listings.ReleaseListing(ctx, cmd.ListingID, cmd.BuyerID)
+			return nil, err
+		}
+	}
+
+	order, err := domain.NewOrder(cmd.BuyerID, listing.SellerID, listing.ID, listing.CategoryID, listing.Title, listing.Price, dueAt, now)
+	if err != nil {
+		s.listings.ReleaseListing(ctx, listing.ID, cmd.BuyerID)
+		return nil, err
+	}
+	if cmd.CouponCode != "" {
+		if err := s.applyCoupon(ctx, order, cmd.CouponCode); err != nil {
+			s.listings.ReleaseListing(ctx, listing.ID, cmd.BuyerID)
+			return nil, err
+		}
+	}
+	release := func() {
+		if order.CouponCode != "" {
+			s.coupons.ReleaseCoupon(ctx, order.ID)
+		}
+		s.listings.ReleaseListing(ctx, listing.ID, cmd.BuyerID)
+	}
+
+	if s.fraud != nil {
+		if err := s.screen(ctx, order, listing); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.orderRepo.Save(order) }); err != nil {
+		release()
+		return nil, err
+	}
+
+	// Publish OrderCreated event
+	event, err := events.NewEvent(
+		domain.OrderCreatedEvent,
+		order.ID.String(),
+		domain.OrderCreated{
+			OrderID:      order.ID,
+			BuyerID:      order.BuyerID,
+			SellerID:     order.SellerID,
+			ListingID:    order.ListingID,
+			Amount:       order.Amount,
+			PaymentDueAt: order.PaymentDueAt,
+			Timestamp:    s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// applyCoupon redeems the coupon with the code on a new order and takes its
+// discount off
+func (s *OrderService) applyCoupon(ctx context.Context, order *domain.Order, code string) error {
+	if s.coupons == nil {
+		return errors.ValidationError("the coupon code is not valid")
+	}
+	redemption, err := s.coupons.RedeemCoupon(ctx, code, order)
+	if err != nil {
+		return err
+	}
+	if err := order.ApplyCoupon(domain.NormalizeCouponCode(code), redemption.Discount); err != nil {
+		s.coupons.ReleaseCoupon(ctx, order.ID)
+		return err
+	}
+	return nil
+}
+
+// screen checks a new order for fraud, and holds it for review if it is
+// likely fraud, keeping the listing reserved until the review is due
+func (s *OrderService) screen(ctx context.Context, order *domain.Order, listing *listings.Listing) error {
+	check, err := s.fraud.ScreenOrder(ctx, order, listing)
+	if err != nil {
+		return err
+	}
+	if !check.Held() {
+		return nil
+	}
+	if _, err := s.listings.ReserveListing(ctx, listing.ID, order.BuyerID, *check.ReviewDueAt); err != nil {
+		return err
+	}
+	return order.HoldForReview(*check.ReviewDueAt, s.clock.Now())
+}
+
+// GetOrder retrieves one of the buyer's orders
+func (s *OrderService) GetOrder(ctx context.Context, orderID ids.OrderID, buyerID ids.UserID) (*domain.Order, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.BuyerID != buyerID {
+		return nil, errors.NotFoundError("order not found")
+	}
+	return order, nil
+}
+
+// PayOrder starts the payment of one of the buyer's orders through the
+// provider taking the chosen payment method. MoMo asks the buyer to approve
+// the payment on their phone; other providers return a checkout page. The
+// order is paid once the provider confirms the payment.
+func (s *OrderService) PayOrder(ctx context.Context, cmd PayOrderCommand) (*OrderPayment, error) {
+	method := payments.Method(cmd.Method)
+	if method == "" {
+		method = payments.MethodMoMo
+	}
+	if s.providers == nil {
+		return nil, errors.UnavailableError("the payment method is not available")
+	}
+	provider, err := s.providers.ForMethod(method)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.GetOrder(ctx, cmd.OrderID, cmd.BuyerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := order.CanRequestPayment(s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	// Providers take phone numbers without the leading +
+	payer := strings.TrimPrefix(cmd.Phone, "+")
+	payment, err := provider.RequestPayment(ctx, payments.Request{
+		Reference:   order.ID.String(),
+		Method:      method,
+		Amount:      order.Amount,
+		Payer:       payer,
+		Email:       cmd.Email,
+		Description: order.Title,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := order.RequestPayment(domain.PaymentMethod(method), provider.Name(), payment.Reference, payer, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.orderRepo.Update(order) }); err != nil {
+		return nil, err
+	}
+	return newOrderPayment(order, payment), nil
+}
+
+// ConfirmPayment submits the code the payment of one of the buyer's orders
+// asked for to its provider, and returns what the buyer does next. A code
+// the provider refuses leaves the payment awaiting another.
+func (s *OrderService) ConfirmPayment(ctx context.Context, cmd ConfirmPaymentCommand) (*OrderPayment, error) {
+	order, err := s.GetOrder(ctx, cmd.OrderID, cmd.BuyerID)
+	if err != nil {
+		return nil, err
+	}
+	if !order.AwaitsPaymentApproval() {
+		return nil, errors.ConflictError("no payment of the order awaits a code")
+	}
+	if s.providers == nil {
+		return nil, errors.UnavailableError("the payment provider is not available")
+	}
+	provider, err := s.providers.Provider(order.PaymentProvider)
+	if err != nil {
+		return nil, err
+	}
+	confirmer, ok := provider.(payments.CodeConfirmer)
+	if !ok {
+		return nil, errors.ConflictError("no payment of the order awaits a code")
+	}
+
+	payment, err := confirmer.ConfirmPayment(ctx, order.PaymentReference, strings.TrimSpace(cmd.Code))
+	if err != nil {
+		return nil, err
+	}
+	return newOrderPayment(order, payment), nil
+}
+
+// newOrderPayment tells the buyer what to do next about an order's payment
+func newOrderPayment(order *domain.Order, payment *payments.Payment) *OrderPayment {
+	return &OrderPayment{
+		Order:        order,
+		Action:       payment.Action,
+		CheckoutURL:  payment.CheckoutURL,
+		Instructions: payment.Instructions,
+	}
+}
+
+// CheckPendingPayments looks up the payments of up to limit orders whose
+// webhook is late, in case it was lost. Successful payments of the full
+// amount complete their order, and failed ones are cleared so the buyer can
+// try again or the order can be abandoned. It returns how many orders were
+// settled either way.
+func (s *OrderService) CheckPendingPayments(ctx context.Context, limit int) (int, error) {
+	if s.providers == nil {
+		return 0, nil
+	}
+	orders, err := s.orderRepo.FindAwaitingPaymentApproval(s.clock.Now().Add(-paymentCallbackGrace), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, order := range orders {
+		provider, err := s.providers.Provider(order.PaymentProvider)
+		if err != nil {
+			// The provider is no longer configured, so the payment can
+			// never be approved; clearing it lets the order be abandoned
+			failed, err := s.failPayment(ctx, order, payments.StatusFailed, "the payment provider is no longer available")
+			if err != nil {
+				return settled, err
+			}
+			if failed {
+				settled++
+			}
+			continue
+		}
+		result, err := provider.PaymentResult(ctx, order.PaymentReference)
+		if err != nil {
+			return settled, err
+		}
+
+		switch paymentOutcome(result.Status) {
+		case paymentSuccessful:
+			if !result.Amount.Equal(order.Amount) {
+				continue
+			}
+			if _, err := s.completePayment(ctx, order.ID, result.TransactionID); err != nil {
+				return settled, err
+			}
+		case paymentFailed:
+			failed, err := s.failPayment(ctx, order, result.Status, result.Reason)
+			if err != nil {
+				return settled, err
+			}
+			if !failed {
+				continue
+			}
+		default:
+			continue
+		}
+		settled++
+	}
+	return settled, nil
+}
+
+// CompletePayment marks an order as paid once the payment provider confirms it
+func (s *OrderService) CompletePayment(ctx context.Context, orderID ids.OrderID) (*domain.Order, error) {
+	return s.completePayment(ctx, orderID, "")
+}
+
+// HandlePaymentEvent applies a payment event from a provider's webhook whose
+// authenticity has been checked. Only successful payments of the full order
+// amount complete the order; failed payments leave it pending until it is
+// abandoned, so the buyer can try again. Events are applied once: repeated
+// events for a paid order or a payment already failed change nothing and
+// publish nothing. Events about another payment than the one the order
+// awaits, such as an earlier attempt, are ignored.
+func (s *OrderService) HandlePaymentEvent(ctx context.Context, event payments.Event) (*domain.Order, error) {
+	orderID, err := ids.ParseOrderID(event.Reference)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	// MoMo callbacks do not carry the payment's reference
+	if order.PaymentProvider != event.Provider || (event.PaymentReference != "" && event.PaymentReference != order.PaymentReference) {
+		return order, nil
+	}
+
+	switch paymentOutcome(event.Status) {
+	case paymentSuccessful:
+		if order.Status == domain.OrderStatusPaid {
+			return order, nil
+		}
+		if !event.Amount.Equal(order.Amount) {
+			return nil, errors.ValidationError("payment amount does not match the order")
+		}
+		return s.completePayment(ctx, order.ID, event.TransactionID)
+	case paymentFailed:
+		if _, err := s.failPayment(ctx, order, event.Status, event.Reason); err != nil {
+			return nil, err
+		}
+		return order, nil
+	default:
+		return order, nil
+	}
+}
+
+// paymentOutcome maps a payment status to whether the payment succeeded,
+// failed, or is still pending. Rejected and expired MoMo payments are
+// failures; statuses MoMo may add later are treated as pending.
+func paymentOutcome(status string) string {
+	switch strings.ToUpper(status) {
+	case paymentSuccessful:
+		return paymentSuccessful
+	case paymentFailed, "REJECTED", "TIMEOUT", "EXPIRED":
+		return paymentFailed
+	default:
+		return paymentPending
+	}
+}
+
+// completePayment marks an order as paid and publishes PaymentSucceeded and
+// OrderPaid. transactionID is the provider's ID of the payment, if known.
+func (s *OrderService) completePayment(ctx context.Context, orderID ids.OrderID, transactionID string) (*domain.Order, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	reference := order.PaymentReference
+
+	if err := order.MarkPaid(s.clock.Now()); err != nil {
+		return nil, err
+	}
+	return s.paid(ctx, order, reference, transactionID)
+}
+
+// CompleteWalletPayment marks an order as paid from the buyer's wallet by
+// the given wallet entry
+func (s *OrderService) CompleteWalletPayment(ctx context.Context, orderID ids.OrderID, entryID string) (*domain.Order, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := order.PayFromWallet(entryID, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	return s.paid(ctx, order, entryID, entryID)
+}
+
+// paid saves an order just paid and publishes PaymentSucceeded and OrderPaid
+func (s *OrderService) paid(ctx context.Context, order *domain.Order, reference, transactionID string) (*domain.Order, error) {
+	if err := db.WithRetry(ctx, func() error { return s.orderRepo.Update(order) }); err != nil {
+		return nil, err
+	}
+
+	// Publish PaymentSucceeded event
+	succeeded, err := events.NewEvent(
+		domain.PaymentSucceededEvent,
+		order.ID.String(),
+		domain.PaymentSucceeded{
+			OrderID:       order.ID,
+			BuyerID:       order.BuyerID,
+			SellerID:      order.SellerID,
+			Reference:     reference,
+			TransactionID: transactionID,
+			Amount:        order.Amount,
+			Timestamp:     s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.eventBus.Publish(ctx, succeeded); err != nil {
+		return nil, err
+	}
+
+	// Publish OrderPaid event
+	event, err := events.NewEvent(
+		domain.OrderPaidEvent,
+		order.ID.String(),
+		domain.OrderPaid{
+			OrderID:   order.ID,
+			BuyerID:   order.BuyerID,
+			SellerID:  order.SellerID,
+			ListingID: order.ListingID,
+			Amount:    order.Amount,
+			Timestamp: s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// publishOrderCancelled announces an order just cancelled, so the services
+// reacting to orders need not know each way an order can be cancelled
+func publishOrderCancelled(ctx context.Context, eventBus events.EventBus, order *domain.Order, reason string) error {
+	event, err := events.NewEvent(
+		domain.OrderCancelledEvent,
+		order.ID.String(),
+		domain.OrderCancelled{
+			OrderID:   order.ID,
+			BuyerID:   order.BuyerID,
+			SellerID:  order.SellerID,
+			ListingID: order.ListingID,
+			Amount:    order.Amount,
+			Paid:      order.PaidAt != nil,
+			Reason:    reason,
+			Timestamp: *order.CancelledAt,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return eventBus.Publish(ctx, event)
+}
+
+// failPayment clears the failed payment an order awaits and publishes
+// PaymentFailed. It reports false, publishing nothing, when the order awaits
+// no payment, as when the failure was already applied.
+func (s *OrderService) failPayment(ctx context.Context, order *domain.Order, status, reason string) (bool, error) {
+	reference := order.PaymentReference
+	if !order.PaymentFailed(reference, s.clock.Now()) {
+		return false, nil
+	}
+	if err := db.WithRetry(ctx, func() error { return s.orderRepo.Update(order) }); err != nil {
+		return false, err
+	}
+
+	event, err := events.NewEvent(
+		domain.PaymentFailedEvent,
+		order.ID.String(),
+		domain.PaymentFailed{
+			OrderID:   order.ID,
+			BuyerID:   order.BuyerID,
+			Reference: reference,
+			Status:    status,
+			Reason:    reason,
+			Timestamp: s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ResumeCheckout reopens an abandoned checkout, reserving the listing for the
+// buyer again if nobody else has claimed it meanwhile. The order's coupon is
+// redeemed again, keeping its discount, if it is still valid and not used up.
+func (s *OrderService) ResumeCheckout(ctx context.Context, orderID ids.OrderID, buyerID ids.UserID) (*domain.Order, error) {
+	order, err := s.GetOrder(ctx, orderID, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != domain.OrderStatusAbandoned {
+		return nil, errors.ConflictError("only abandoned orders can be resumed")
+	}
+
+	redeemed := false
+	if order.CouponCode != "" && s.coupons != nil {
+		if _, err := s.coupons.RedeemCoupon(ctx, order.CouponCode, order); err != nil {
+			return nil, err
+		}
+		redeemed = true
+	}
+	release := func() {
+		if redeemed {
+			s.coupons.ReleaseCoupon(ctx, order.ID)
+		}
+	}
+
+	now := s.clock.Now()
+	dueAt := now.Add(s.paymentWindow(order.Amount))
+	if _, err := s.listings.ReserveListing(ctx, order.ListingID, order.BuyerID, dueAt); err != nil {
+		release()
+		return nil, err
+	}
+
+	if err := order.Resume(dueAt, now); err != nil {
+		release()
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.orderRepo.Update(order) }); err != nil {
+		release()
+		return nil, err
+	}
+
+	// Publish OrderResumed event
+	event, err := events.NewEvent(
+		domain.OrderResumedEvent,
+		order.ID.String(),
+		domain.OrderResumed{
+			OrderID:      order.ID,
+			BuyerID:      order.BuyerID,
+			ListingID:    order.ListingID,
+			PaymentDueAt: order.PaymentDueAt,
+			Timestamp:    s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// AdvanceFulfillment moves one of the caller's paid orders or sales to the
+// next fulfillment status and tells the other party
+func (s *OrderService) AdvanceFulfillment(ctx context.Context, cmd AdvanceFulfillmentCommand) (*domain.Order, error) {
+	order, err := s.orderRepo.FindByID(cmd.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	// Other users are told the order does not exist rather than that it is not theirs
+	if !order.IsParty(cmd.UserID) {
+		return nil, errors.NotFoundError("order not found")
+	}
+
+	now := s.clock.Now()
+	if err := order.AdvanceFulfillment(cmd.UserID, domain.FulfillmentStatus(cmd.Status), cmd.Carrier, cmd.TrackingNumber, now); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.orderRepo.Update(order) }); err != nil {
+		return nil, err
+	}
+
+	recipient := order.BuyerID
+	if cmd.UserID == order.BuyerID {
+		recipient = order.SellerID
+	}
+	channels, err := s.preferences.NotificationChannels(ctx, recipient, orderUpdatesCategory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish OrderFulfillmentUpdated event
+	event, err := events.NewEvent(
+		domain.OrderFulfillmentEvent,
+		order.ID.String(),
+		domain.OrderFulfillmentUpdated{
+			OrderID:        order.ID,
+			BuyerID:        order.BuyerID,
+			SellerID:       order.SellerID,
+			Status:         order.FulfillmentStatus,
+			Carrier:        order.Carrier,
+			TrackingNumber: order.TrackingNumber,
+			UpdatedBy:      cmd.UserID,
+			Channels:       channels,
+			Timestamp:      now,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ReleaseHeldOrder lets the buyer pay for an order held for review, within a
+// new payment window. The listing is reserved for the buyer again, which
+// fails if somebody else claimed it after the review was due.
+func (s *OrderService) ReleaseHeldOrder(ctx context.Context, cmd ReleaseOrderCommand) (*domain.Order, error) {
+	order, err := s.orderRepo.FindByID(cmd.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != domain.OrderStatusHeldForReview {
+		return nil, errors.ConflictError("the order is not held for review")
+	}
+
+	now := s.clock.Now()
+	dueAt := now.Add(s.paymentWindow(order.Amount))
+	if _, err := s.listings.ReserveListing(ctx, order.ListingID, order.BuyerID, dueAt); err != nil {
+		return nil, err
+	}
+	if err := order.ReleaseReview(dueAt, now); err != nil {
+		return nil, err
+	}
+	if err := s.recordReview(ctx, order, domain.FraudDecisionReleased, cmd.AdminID, cmd.Note); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.orderRepo.Update(order) }); err != nil {
+		return nil, err
+	}
+
+	// Publish OrderReleased event so the checkout saga waits for the new deadline
+	event, err := events.NewEvent(
+		domain.OrderReleasedEvent,
+		order.ID.String(),
+		domain.OrderReleased{
+			OrderID:      order.ID,
+			BuyerID:      order.BuyerID,
+			ReviewerID:   cmd.AdminID,
+			PaymentDueAt: order.PaymentDueAt,
+			Timestamp:    now,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// DeclineHeldOrder cancels an order held for review and releases its coupon.
+// The listing is released when the checkout saga is undone.
+func (s *OrderService) DeclineHeldOrder(ctx context.Context, cmd DeclineOrderCommand) (*domain.Order, error) {
+	order, err := s.orderRepo.FindByID(cmd.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if err := order.DeclineReview(now); err != nil {
+		return nil, err
+	}
+	if err := s.recordReview(ctx, order, domain.FraudDecisionDeclined, cmd.AdminID, cmd.Reason); err != nil {
+		return nil, err
+	}
+	if order.CouponCode != "" && s.coupons != nil {
+		if err := s.coupons.ReleaseCoupon(ctx, order.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := db.WithRetry(ctx, func() error { return s.orderRepo.Update(order) }); err != nil {
+		return nil, err
+	}
+
+	// Publish OrderDeclined event so the checkout saga is undone
+	event, err := events.NewEvent(
+		domain.OrderDeclinedEvent,
+		order.ID.String(),
+		domain.OrderDeclined{
+			OrderID:    order.ID,
+			BuyerID:    order.BuyerID,
+			ListingID:  order.ListingID,
+			ReviewerID: cmd.AdminID,
+			Reason:     cmd.Reason,
+			Timestamp:  now,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+	if err := publishOrderCancelled(ctx, s.eventBus, order, cmd.Reason); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// recordReview records the review of a held order on its fraud check, which
+// fails if another administrator reviewed it first
+func (s *OrderService) recordReview(ctx context.Context, order *domain.Order, decision domain.FraudDecision, reviewerID ids.UserID, note string) error {
+	if s.fraud == nil {
+		return nil
+	}
+	return s.fraud.RecordReview(ctx, order.ID, decision, reviewerID, note)
+}
+
+// AbandonExpiredOrders abandons up to limit orders whose payment deadline has
+// passed and returns how many were abandoned. Their coupons are released so
+// they no longer count towards the coupons' limits. Orders whose payment
+// awaits approval are left to CheckPendingPayments, so a payment approved
+// late completes its order instead of finding it abandoned.
+func (s *OrderService) AbandonExpiredOrders(ctx context.Context, limit int) (int, error) {
+	now := s.clock.Now()
+	orders, err := s.orderRepo.FindExpiredPending(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	abandoned := 0
+	for _, order := range orders {
+		if err := order.Abandon(now); err != nil {
+			continue
+		}
+		if order.CouponCode != "" && s.coupons != nil {
+			if err := s.coupons.ReleaseCoupon(ctx, order.ID); err != nil {
+				return abandoned, err
+			}
+		}
+
+		if err := db.WithRetry(ctx, func() error { return s.orderRepo.Update(order) }); err != nil {
+			return abandoned, err
+		}
+
+		// Publish OrderAbandoned event
+		event, err := events.NewEvent(
+			domain.OrderAbandonedEvent,
+			order.ID.String(),
+			domain.OrderAbandoned{
+				OrderID:    order.ID,
+				BuyerID:    order.BuyerID,
+				SellerID:   order.SellerID,
+				ListingID:  order.ListingID,
+				CategoryID: order.CategoryID,
+				Timestamp:  now,
+			},
+		)
+		if err != nil {
+			return abandoned, err
+		}
+
+		if err := s.eventBus.Publish(ctx, event); err != nil {
+			return abandoned, err
+		}
+		abandoned++
+	}
+	return abandoned, nil
+}
+
+// RecoverAbandonedCheckout releases the listing of an abandoned order so
+// others can buy it, and sends the buyer a link back to the payment unless
+// they turned off order updates. The link goes out on the channels the buyer
+// chose for order updates. Orders resumed in the meantime are left alone.
+func (s *OrderService) RecoverAbandonedCheckout(ctx context.Context, orderID ids.OrderID) error {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != domain.OrderStatusAbandoned {
+		return nil
+	}
+
+	if err := s.listings.ReleaseListing(ctx, order.ListingID, order.BuyerID); err != nil {
+		return err
+	}
+
+	channels, err := s.preferences.NotificationChannels(ctx, order.BuyerID, orderUpdatesCategory)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+
+	// Publish CheckoutRecovery event so the notifications context reminds the buyer
+	event, err := events.NewEvent(
+		domain.CheckoutRecoveryEvent,
+		order.ID.String(),
+		domain.CheckoutRecovery{
+			OrderID:   order.ID,
+			BuyerID:   order.BuyerID,
+			Title:     order.Title,
+			Amount:    order.Amount,
+			ResumeURL: strings.ReplaceAll(s.resumeURL, "{order_id}", order.ID.String()),
+			Channels:  channels,
+			Timestamp: s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// AbandonmentStats reports checkout abandonment per category for orders
+// created in the queried range, which defaults to the last 30 days
+func (s *OrderService) AbandonmentStats(ctx context.Context, query AbandonmentStatsQuery) ([]domain.CategoryAbandonment, error) {
+	from, to := query.From, query.To
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultStatsWindow)
+	}
+	if from.After(to) {
+		return nil, errors.ValidationError("from must be before to")
+	}
+
+	counts, err := s.orderRepo.CountByCategory(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return domain.AbandonmentRates(counts), nil
+}
+
+// paymentWindow decides how long a buyer has to pay for an order
+func (s *OrderService) paymentWindow(amount money.Money) time.Duration {
+	if s.rules == nil {
+		return s.paymentTimeout
+	}
+	minutes := s.rules.Number(PaymentWindowRule, PaymentWindowFacts(amount))
+	if minutes <= 0 {
+		return s.paymentTimeout
+	}
+	return time.Duration(minutes * float64(time.Minute))
+}
@@ -0,0 +1,502 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+	"dongome/pkg/payments"
+	"dongome/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newActiveListing(t *testing.T, sellerID ids.UserID) *listings.Listing {
+	t.Helper()
+	listing, err := listings.NewListing(sellerID, "category-1", "Used phone", "", money.Cedis(100), listings.ConditionGood,
+		listings.Location{Region: "Greater Accra", City: "Accra"})
+	require.NoError(t, err)
+	require.NoError(t, listing.Activate())
+	return listing
+}
+
+// expireOrder moves an order's payment deadline into the past
+func expireOrder(order *domain.Order) {
+	order.PaymentDueAt = time.Now().Add(-time.Minute)
+}
+
+func TestOrderService_CreateOrderReservesListing(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	eventBus := &fakeEventBus{}
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing), &fakeNotificationPreferences{}, nil, eventBus, 30*time.Minute, "", nil, nil, nil, nil)
+
+	order, err := service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	assert.Equal(t, ids.UserID("seller-a"), order.SellerID)
+	assert.Equal(t, "category-1", order.CategoryID)
+	assert.Equal(t, money.Cedis(100), order.Amount)
+	assert.True(t, listing.IsReserved())
+	assert.Len(t, eventBus.eventsOfType(domain.OrderCreatedEvent), 1)
+
+	// Another buyer cannot order the reserved listing
+	_, err = service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-b", ListingID: listing.ID})
+	assert.Error(t, err)
+}
+
+func TestOrderService_CreateOrderUsesPaymentWindowRule(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	policy := &fakePolicyRules{number: func(key string, facts rules.Facts) float64 {
+		if key == app.PaymentWindowRule && facts["amount"].(float64) >= 100 {
+			return 120
+		}
+		return 0
+	}}
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing), &fakeNotificationPreferences{}, nil, &fakeEventBus{}, 30*time.Minute, "", policy, nil, nil, nil)
+
+	order, err := service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), order.PaymentDueAt, time.Minute)
+	require.NotNil(t, listing.ReservedUntil)
+	assert.Equal(t, order.PaymentDueAt, *listing.ReservedUntil, "the listing is held for the whole window")
+}
+
+func TestOrderService_AbandonAndRecover(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	orderRepo := newFakeOrderRepository()
+	eventBus := &fakeEventBus{}
+	service := app.NewOrderService(orderRepo, newFakeListingReservations(listing), &fakeNotificationPreferences{}, nil, eventBus, 30*time.Minute, "dongome://orders/{order_id}/pay", nil, nil, nil, nil)
+
+	order, err := service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+
+	// Nothing to abandon while the buyer can still pay
+	count, err := service.AbandonExpiredOrders(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	expireOrder(order)
+	count, err = service.AbandonExpiredOrders(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, domain.OrderStatusAbandoned, order.Status)
+	require.Len(t, eventBus.eventsOfType(domain.OrderAbandonedEvent), 1)
+
+	require.NoError(t, service.RecoverAbandonedCheckout(context.Background(), order.ID))
+	assert.False(t, listing.IsReserved())
+
+	recoveries := eventBus.eventsOfType(domain.CheckoutRecoveryEvent)
+	require.Len(t, recoveries, 1)
+	var recovery domain.CheckoutRecovery
+	require.NoError(t, events.ParseEventData(recoveries[0], &recovery))
+	assert.Equal(t, ids.UserID("buyer-a"), recovery.BuyerID)
+	assert.Equal(t, "dongome://orders/"+order.ID.String()+"/pay", recovery.ResumeURL)
+	assert.Equal(t, []string{"email", "push"}, recovery.Channels)
+
+	// The buyer follows the link and resumes the checkout
+	resumed, err := service.ResumeCheckout(context.Background(), order.ID, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPendingPayment, resumed.Status)
+	assert.True(t, listing.IsReserved())
+	assert.Len(t, eventBus.eventsOfType(domain.OrderResumedEvent), 1)
+
+	paid, err := service.CompletePayment(context.Background(), order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPaid, paid.Status)
+}
+
+func TestOrderService_HandlePaymentEvent(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	provider := newFakePaymentProvider(payments.ProviderPaystack)
+	eventBus := &fakeEventBus{}
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing), &fakeNotificationPreferences{}, provider.registry(payments.MethodCard), eventBus, 30*time.Minute, "", nil, nil, nil, nil)
+	ctx := context.Background()
+
+	order, err := service.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	event := payments.Event{Provider: payments.ProviderPaystack, Reference: order.ID.String(), PaymentReference: "payment-1", Status: payments.StatusSuccessful, Amount: money.Cedis(100)}
+
+	// Events about payments the order never asked for are ignored
+	ignored, err := service.HandlePaymentEvent(ctx, event)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPendingPayment, ignored.Status)
+
+	_, err = service.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Method: "card", Email: "buyer@example.com"})
+	require.NoError(t, err)
+
+	// Events from another provider, or about another payment, are ignored too
+	other := event
+	other.Provider = payments.ProviderFlutterwave
+	ignored, err = service.HandlePaymentEvent(ctx, other)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPendingPayment, ignored.Status)
+	other = event
+	other.PaymentReference = "payment-0"
+	ignored, err = service.HandlePaymentEvent(ctx, other)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPendingPayment, ignored.Status)
+
+	partial := event
+	partial.Amount = money.Cedis(1)
+	_, err = service.HandlePaymentEvent(ctx, partial)
+	assert.Error(t, err, "partial payments do not complete the order")
+
+	event.TransactionID = "tx-1"
+	paid, err := service.HandlePaymentEvent(ctx, event)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPaid, paid.Status)
+
+	// The provider retrying the webhook changes nothing
+	_, err = service.HandlePaymentEvent(ctx, event)
+	require.NoError(t, err)
+	assert.Len(t, eventBus.eventsOfType(domain.OrderPaidEvent), 1)
+
+	succeeded := eventBus.eventsOfType(domain.PaymentSucceededEvent)
+	require.Len(t, succeeded, 1)
+	var payment domain.PaymentSucceeded
+	require.NoError(t, events.ParseEventData(succeeded[0], &payment))
+	assert.Equal(t, order.ID, payment.OrderID)
+	assert.Equal(t, "payment-1", payment.Reference)
+	assert.Equal(t, "tx-1", payment.TransactionID)
+	assert.Equal(t, money.Cedis(100), payment.Amount)
+}
+
+func TestOrderService_PayOrder(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	provider := newFakePaymentProvider(payments.ProviderMoMo)
+	eventBus := &fakeEventBus{}
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing), &fakeNotificationPreferences{}, provider.registry(payments.MethodMoMo), eventBus, 30*time.Minute, "", nil, nil, nil, nil)
+	ctx := context.Background()
+
+	order, err := service.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+
+	_, err = service.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-b", Phone: "+233241234567"})
+	assert.Error(t, err, "only the buyer pays for an order")
+	_, err = service.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Method: "card", Email: "buyer@example.com"})
+	assert.Error(t, err, "no provider takes cards")
+
+	requested, err := service.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Phone: "+233241234567"})
+	require.NoError(t, err)
+	assert.Equal(t, "payment-1", requested.PaymentReference)
+	assert.Equal(t, domain.PaymentMethodMoMo, requested.PaymentMethod)
+	assert.Equal(t, payments.ProviderMoMo, requested.PaymentProvider)
+	assert.Empty(t, requested.CheckoutURL, "MoMo payments are approved on the buyer's phone")
+	require.Len(t, provider.requests, 1)
+	assert.Equal(t, payments.MethodMoMo, provider.requests[0].Method)
+	assert.Equal(t, "233241234567", provider.requests[0].Payer)
+	assert.Equal(t, order.ID.String(), provider.requests[0].Reference)
+	assert.Equal(t, money.Cedis(100), provider.requests[0].Amount)
+
+	// The buyer cannot be asked twice while the first payment awaits approval
+	_, err = service.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Phone: "+233241234567"})
+	assert.Error(t, err)
+
+	// Once it is rejected, the buyer can try again
+	rejected := payments.Event{Provider: payments.ProviderMoMo, Reference: order.ID.String(), Status: payments.StatusFailed, Amount: money.Cedis(100), Reason: "APPROVAL_REJECTED"}
+	_, err = service.HandlePaymentEvent(ctx, rejected)
+	require.NoError(t, err)
+	_, err = service.HandlePaymentEvent(ctx, rejected)
+	require.NoError(t, err)
+	failures := eventBus.eventsOfType(domain.PaymentFailedEvent)
+	require.Len(t, failures, 1, "a repeated failure is applied once")
+	var failure domain.PaymentFailed
+	require.NoError(t, events.ParseEventData(failures[0], &failure))
+	assert.Equal(t, "payment-1", failure.Reference)
+	assert.Equal(t, payments.StatusFailed, failure.Status)
+	assert.Equal(t, "APPROVAL_REJECTED", failure.Reason)
+
+	// Pending events change nothing
+	pending, err := service.HandlePaymentEvent(ctx, payments.Event{Provider: payments.ProviderMoMo, Reference: order.ID.String(), Status: payments.StatusPending})
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPendingPayment, pending.Status)
+
+	retried, err := service.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Phone: "+233241234567"})
+	require.NoError(t, err)
+	assert.Equal(t, "payment-2", retried.PaymentReference)
+}
+
+func TestOrderService_PayOrderByCard(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	momo := newFakePaymentProvider(payments.ProviderMoMo)
+	paystack := newFakePaymentProvider(payments.ProviderPaystack)
+	paystack.checkoutURL = "https://checkout.paystack.com/abc"
+	providers := momo.registry(payments.MethodMoMo)
+	providers.Use(paystack, payments.MethodCard)
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing), &fakeNotificationPreferences{}, providers, &fakeEventBus{}, 30*time.Minute, "", nil, nil, nil, nil)
+	ctx := context.Background()
+
+	order, err := service.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+
+	// Card payments go to the provider taking cards, which returns a checkout page
+	requested, err := service.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Method: "card", Email: "buyer@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://checkout.paystack.com/abc", requested.CheckoutURL)
+	assert.Equal(t, domain.PaymentMethodCard, requested.PaymentMethod)
+	assert.Equal(t, payments.ProviderPaystack, requested.PaymentProvider)
+	assert.Empty(t, momo.requests)
+	require.Len(t, paystack.requests, 1)
+	assert.Equal(t, payments.MethodCard, paystack.requests[0].Method)
+	assert.Equal(t, "buyer@example.com", paystack.requests[0].Email)
+}
+
+func TestOrderService_ConfirmPayment(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	momoListing := newActiveListing(t, "seller-a")
+	momo := newFakePaymentProvider(payments.ProviderMoMo)
+	paystack := &fakeCodeProvider{fakePaymentProvider: newFakePaymentProvider(payments.ProviderPaystack), code: "123456"}
+	providers := momo.registry(payments.MethodMoMo)
+	providers.Use(paystack, payments.MethodVodafoneCash)
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing, momoListing), &fakeNotificationPreferences{}, providers, &fakeEventBus{}, 30*time.Minute, "", nil, nil, nil, nil)
+	ctx := context.Background()
+
+	order, err := service.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	_, err = service.ConfirmPayment(ctx, app.ConfirmPaymentCommand{OrderID: order.ID, BuyerID: "buyer-a", Code: "123456"})
+	assert.Error(t, err, "an unpaid order awaits no code")
+
+	// Vodafone Cash payers are asked for the voucher they generate
+	requested, err := service.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Method: "vodafone_cash", Phone: "+233201234567", Email: "buyer@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, payments.ActionEnterCode, requested.Action)
+	assert.Equal(t, "Dial *110# to generate a voucher", requested.Instructions)
+	assert.Equal(t, domain.PaymentMethodVodafoneCash, requested.PaymentMethod)
+	assert.Equal(t, payments.ProviderPaystack, requested.PaymentProvider)
+
+	_, err = service.ConfirmPayment(ctx, app.ConfirmPaymentCommand{OrderID: order.ID, BuyerID: "buyer-b", Code: "123456"})
+	assert.Error(t, err, "only the buyer confirms their payment")
+
+	// A wrong code leaves the payment awaiting another
+	_, err = service.ConfirmPayment(ctx, app.ConfirmPaymentCommand{OrderID: order.ID, BuyerID: "buyer-a", Code: "000000"})
+	assert.Error(t, err)
+	confirmed, err := service.ConfirmPayment(ctx, app.ConfirmPaymentCommand{OrderID: order.ID, BuyerID: "buyer-a", Code: " 123456 "})
+	require.NoError(t, err)
+	assert.Equal(t, payments.ActionApprove, confirmed.Action)
+	assert.Equal(t, []string{"000000", "123456"}, paystack.codes)
+	assert.Equal(t, domain.OrderStatusPendingPayment, confirmed.Status)
+
+	// MoMo payments are approved on the phone and take no code
+	momoOrder, err := service.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: momoListing.ID})
+	require.NoError(t, err)
+	_, err = service.PayOrder(ctx, app.PayOrderCommand{OrderID: momoOrder.ID, BuyerID: "buyer-a", Phone: "+233241234567"})
+	require.NoError(t, err)
+	_, err = service.ConfirmPayment(ctx, app.ConfirmPaymentCommand{OrderID: momoOrder.ID, BuyerID: "buyer-a", Code: "123456"})
+	assert.Error(t, err)
+}
+
+func TestOrderService_PayOrderWithoutGateway(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing), &fakeNotificationPreferences{}, nil, &fakeEventBus{}, 30*time.Minute, "", nil, nil, nil, nil)
+
+	order, err := service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	_, err = service.PayOrder(context.Background(), app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Phone: "+233241234567"})
+	assert.Error(t, err)
+}
+
+func TestOrderService_AbandonLeavesPaymentsAwaitingApproval(t *testing.T) {
+	lateListing := newActiveListing(t, "seller-a")
+	failedListing := newActiveListing(t, "seller-a")
+	provider := newFakePaymentProvider(payments.ProviderMoMo)
+	eventBus := &fakeEventBus{}
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(lateListing, failedListing), &fakeNotificationPreferences{}, provider.registry(payments.MethodMoMo), eventBus, 30*time.Minute, "", nil, nil, nil, nil)
+	ctx := context.Background()
+
+	requestPayment := func(listing *listings.Listing) *domain.Order {
+		order, err := service.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+		require.NoError(t, err)
+		payment, err := service.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Phone: "+233241234567"})
+		require.NoError(t, err)
+		expireOrder(payment.Order)
+		return payment.Order
+	}
+	late := requestPayment(lateListing)
+	failed := requestPayment(failedListing)
+
+	// Buyers may still approve payments past the deadline, so neither order is abandoned
+	count, err := service.AbandonExpiredOrders(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Empty(t, eventBus.eventsOfType(domain.OrderAbandonedEvent))
+
+	// A payment approved late completes its order
+	paid, err := service.HandlePaymentEvent(ctx, payments.Event{Provider: payments.ProviderMoMo, Reference: late.ID.String(), PaymentReference: late.PaymentReference, Status: payments.StatusSuccessful, Amount: money.Cedis(100)})
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPaid, paid.Status)
+
+	// Once the payment-approval sweep finds a payment failed, its order is abandoned
+	provider.results[failed.PaymentReference] = &payments.Result{Status: payments.StatusFailed, Reason: "EXPIRED"}
+	requestedAt := failed.PaymentRequestedAt.Add(-time.Hour)
+	failed.PaymentRequestedAt = &requestedAt
+	count, err = service.CheckPendingPayments(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = service.AbandonExpiredOrders(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, domain.OrderStatusAbandoned, failed.Status)
+	assert.Equal(t, domain.OrderStatusPaid, late.Status)
+}
+
+func TestOrderService_CheckPendingPayments(t *testing.T) {
+	paidListing := newActiveListing(t, "seller-a")
+	failedListing := newActiveListing(t, "seller-a")
+	pendingListing := newActiveListing(t, "seller-a")
+	provider := newFakePaymentProvider(payments.ProviderMoMo)
+	eventBus := &fakeEventBus{}
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(paidListing, failedListing, pendingListing), &fakeNotificationPreferences{}, provider.registry(payments.MethodMoMo), eventBus, 30*time.Minute, "", nil, nil, nil, nil)
+	ctx := context.Background()
+
+	requestPayment := func(listing *listings.Listing) *domain.Order {
+		order, err := service.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+		require.NoError(t, err)
+		payment, err := service.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Phone: "+233241234567"})
+		require.NoError(t, err)
+		return payment.Order
+	}
+	paid := requestPayment(paidListing)
+	failed := requestPayment(failedListing)
+	pending := requestPayment(pendingListing)
+	provider.results[paid.PaymentReference] = &payments.Result{Status: payments.StatusSuccessful, Amount: money.Cedis(100)}
+	provider.results[failed.PaymentReference] = &payments.Result{Status: payments.StatusFailed, Reason: "NOT_ENOUGH_FUNDS"}
+
+	// Webhooks are given time to arrive before payments are looked up
+	count, err := service.CheckPendingPayments(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	for _, order := range []*domain.Order{paid, failed, pending} {
+		requestedAt := order.PaymentRequestedAt.Add(-time.Hour)
+		order.PaymentRequestedAt = &requestedAt
+	}
+	count, err = service.CheckPendingPayments(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, domain.OrderStatusPaid, paid.Status)
+	assert.Len(t, eventBus.eventsOfType(domain.OrderPaidEvent), 1)
+	assert.Equal(t, domain.OrderStatusPendingPayment, failed.Status)
+	assert.Empty(t, failed.PaymentReference, "the buyer can try again")
+	require.Len(t, eventBus.eventsOfType(domain.PaymentSucceededEvent), 1)
+	failures := eventBus.eventsOfType(domain.PaymentFailedEvent)
+	require.Len(t, failures, 1)
+	var failure domain.PaymentFailed
+	require.NoError(t, events.ParseEventData(failures[0], &failure))
+	assert.Equal(t, "NOT_ENOUGH_FUNDS", failure.Reason)
+	assert.True(t, pending.AwaitsPaymentApproval())
+}
+
+func TestOrderService_RecoverRespectsPreferences(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	eventBus := &fakeEventBus{}
+	preferences := &fakeNotificationPreferences{channels: map[ids.UserID][]string{"buyer-a": nil}}
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing), preferences, nil, eventBus, 30*time.Minute, "", nil, nil, nil, nil)
+
+	order, err := service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	expireOrder(order)
+	_, err = service.AbandonExpiredOrders(context.Background(), 10)
+	require.NoError(t, err)
+
+	require.NoError(t, service.RecoverAbandonedCheckout(context.Background(), order.ID))
+	// The listing is released but the buyer is not contacted
+	assert.False(t, listing.IsReserved())
+	assert.Empty(t, eventBus.eventsOfType(domain.CheckoutRecoveryEvent))
+}
+
+func TestOrderService_ResumeCheckoutAfterListingTaken(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing), &fakeNotificationPreferences{}, nil, &fakeEventBus{}, 30*time.Minute, "", nil, nil, nil, nil)
+
+	order, err := service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	expireOrder(order)
+	_, err = service.AbandonExpiredOrders(context.Background(), 10)
+	require.NoError(t, err)
+	require.NoError(t, service.RecoverAbandonedCheckout(context.Background(), order.ID))
+
+	// Someone else ordered the listing after it was released
+	_, err = service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-b", ListingID: listing.ID})
+	require.NoError(t, err)
+
+	_, err = service.ResumeCheckout(context.Background(), order.ID, "buyer-a")
+	assert.Error(t, err)
+	assert.Equal(t, domain.OrderStatusAbandoned, order.Status)
+
+	// Other users cannot see or resume the order
+	_, err = service.ResumeCheckout(context.Background(), order.ID, "buyer-b")
+	assert.Error(t, err)
+}
+
+func TestOrderService_AbandonmentStats(t *testing.T) {
+	phone := newActiveListing(t, "seller-a")
+	laptop := newActiveListing(t, "seller-a")
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(phone, laptop), &fakeNotificationPreferences{}, nil, &fakeEventBus{}, 30*time.Minute, "", nil, nil, nil, nil)
+
+	abandoned, err := service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: phone.ID})
+	require.NoError(t, err)
+	_, err = service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-b", ListingID: laptop.ID})
+	require.NoError(t, err)
+	expireOrder(abandoned)
+	_, err = service.AbandonExpiredOrders(context.Background(), 10)
+	require.NoError(t, err)
+
+	stats, err := service.AbandonmentStats(context.Background(), app.AbandonmentStatsQuery{})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "category-1", stats[0].CategoryID)
+	assert.Equal(t, int64(2), stats[0].Orders)
+	assert.Equal(t, int64(1), stats[0].Abandoned)
+	assert.InDelta(t, 0.5, stats[0].AbandonmentRate, 0.0001)
+
+	_, err = service.AbandonmentStats(context.Background(), app.AbandonmentStatsQuery{From: time.Now(), To: time.Now().Add(-time.Hour)})
+	assert.Error(t, err)
+}
+
+func TestOrderService_AdvanceFulfillment(t *testing.T) {
+	listing := newActiveListing(t, "seller-a")
+	eventBus := &fakeEventBus{}
+	preferences := &fakeNotificationPreferences{channels: map[ids.UserID][]string{"buyer-a": {"sms"}}}
+	service := app.NewOrderService(newFakeOrderRepository(), newFakeListingReservations(listing), preferences, nil, eventBus, 30*time.Minute, "", nil, nil, nil, nil)
+	ctx := context.Background()
+
+	order, err := service.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	_, err = service.CompletePayment(ctx, order.ID)
+	require.NoError(t, err)
+
+	_, err = service.AdvanceFulfillment(ctx, app.AdvanceFulfillmentCommand{OrderID: order.ID, UserID: "stranger", Status: "shipped"})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeNotFound, err.(*errors.DomainError).Code, "other users cannot tell the order exists")
+
+	shipped, err := service.AdvanceFulfillment(ctx, app.AdvanceFulfillmentCommand{OrderID: order.ID, UserID: "seller-a", Status: "shipped", Carrier: "Ghana Post", TrackingNumber: "EMS123GH"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.FulfillmentShipped, shipped.FulfillmentStatus)
+
+	updates := eventBus.eventsOfType(domain.OrderFulfillmentEvent)
+	require.Len(t, updates, 1)
+	var update domain.OrderFulfillmentUpdated
+	require.NoError(t, events.ParseEventData(updates[0], &update))
+	assert.Equal(t, domain.FulfillmentShipped, update.Status)
+	assert.Equal(t, "EMS123GH", update.TrackingNumber)
+	assert.Equal(t, []string{"sms"}, update.Channels, "the buyer is told on their channels")
+
+	_, err = service.AdvanceFulfillment(ctx, app.AdvanceFulfillmentCommand{OrderID: order.ID, UserID: "buyer-a", Status: "delivered"})
+	require.NoError(t, err)
+	updates = eventBus.eventsOfType(domain.OrderFulfillmentEvent)
+	require.Len(t, updates, 2)
+	require.NoError(t, events.ParseEventData(updates[1], &update))
+	assert.Equal(t, ids.UserID("buyer-a"), update.UpdatedBy)
+	assert.Equal(t, []string{"email", "push"}, update.Channels, "the seller is told on theirs")
+
+	_, err = service.AdvanceFulfillment(ctx, app.AdvanceFulfillmentCommand{OrderID: order.ID, UserID: "seller-a", Status: "in_transit"})
+	assert.Error(t, err, "delivered orders are final")
+	assert.Len(t, eventBus.eventsOfType(domain.OrderFulfillmentEvent), 2)
+}
@@ -0,0 +1,419 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// payoutCheckDelay is how long after it was sent a payout's transfer is
+// first looked up
+const payoutCheckDelay = 30 * time.Second
+
+// maxPayoutItems limits how many orders' funds are sent in one payout; the
+// rest are paid out on the next run
+const maxPayoutItems = 500
+
+// defaultPayoutPageSize is how many payouts are listed when no limit is given
+const defaultPayoutPageSize = 20
+
+// payeeNotFound is the reason Mobile Money gives for transfers to a wallet
+// that does not exist
+const payeeNotFound = "PAYEE_NOT_FOUND"
+
+// PayoutGateway sends sellers the funds released to them
+type PayoutGateway interface {
+	// VerifyAccount checks the phone number, without the +, is an active wallet
+	VerifyAccount(ctx context.Context, phone string) (bool, error)
+	// SendPayout sends the amount to the payee's wallet and returns the
+	// reference of the transfer
+	SendPayout(ctx context.Context, payout PaymentRequest) (string, error)
+	PayoutResult(ctx context.Context, reference string) (*PaymentResult, error)
+}
+
+// RegisterPayoutAccountCommand represents a seller registering the wallet
+// they are paid to, or moving payouts to another. Schedule is kept, or
+// defaults to the marketplace's, when empty.
+type RegisterPayoutAccountCommand struct {
+	SellerID ids.UserID            `json:"-"`
+	Phone    string                `json:"phone" binding:"required,e164"`
+	Schedule domain.PayoutSchedule `json:"schedule"`
+}
+
+// ChangePayoutScheduleCommand represents a seller changing how often they are paid
+type ChangePayoutScheduleCommand struct {
+	SellerID ids.UserID            `json:"-"`
+	Schedule domain.PayoutSchedule `json:"schedule" binding:"required"`
+}
+
+// PayoutsQuery represents the query to list a seller's payouts
+type PayoutsQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// Payouts represents a page of a seller's payouts
+type Payouts struct {
+	Payouts []*domain.Payout `json:"payouts"`
+	Total   int64            `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+}
+
+// PayoutService pays sellers the funds released to them from escrow, batched
+// into one transfer per seller on the schedule each seller chooses
+type PayoutService struct {
+	payoutRepo  domain.PayoutRepository
+	escrowRepo  domain.EscrowRepository
+	orderRepo   domain.OrderRepository
+	refundRepo  domain.RefundRepository
+	payments    PayoutGateway
+	preferences NotificationPreferences
+	eventBus    events.EventBus
+	policy      domain.PayoutPolicy
+	clock       clock.Clock
+}
+
+// NewPayoutService creates a new payout service. payments may be nil where
+// payouts cannot be sent through Mobile Money. clk may be nil to use the
+// system clock.
+func NewPayoutService(payoutRepo domain.PayoutRepository, escrowRepo domain.EscrowRepository, orderRepo domain.OrderRepository, refundRepo domain.RefundRepository, payments PayoutGateway, preferences NotificationPreferences, eventBus events.EventBus, policy domain.PayoutPolicy, clk clock.Clock) *PayoutService {
+	return &PayoutService{
+		payoutRepo:  payoutRepo,
+		escrowRepo:  escrowRepo,
+		orderRepo:   orderRepo,
+		refundRepo:  refundRepo,
+		payments:    payments,
+		preferences: preferences,
+		eventBus:    eventBus,
+		policy:      policy,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// RegisterAccount registers the wallet a seller is paid to once Mobile Money
+// confirms it is active. Registering again moves payouts to the new number
+// and resumes them if they were paused.
+func (s *PayoutService) RegisterAccount(ctx context.Context, cmd RegisterPayoutAccountCommand) (*domain.PayoutAccount, error) {
+	if s.payments == nil {
+		return nil, errors.UnavailableError("mobile money payouts are temporarily unavailable")
+	}
+	account, err := s.findAccount(cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Schedule != "" && !cmd.Schedule.IsValid() {
+		return nil, errors.ValidationError("payout schedule must be instant, daily or weekly")
+	}
+
+	// MoMo takes phone numbers without the leading +
+	active, err := s.payments.VerifyAccount(ctx, strings.TrimPrefix(cmd.Phone, "+"))
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, errors.ValidationError("the number is not an active mobile money wallet")
+	}
+
+	now := s.clock.Now()
+	if account == nil {
+		schedule := cmd.Schedule
+		if schedule == "" {
+			schedule = s.policy.DefaultSchedule
+		}
+		account, err = domain.NewPayoutAccount(cmd.SellerID, cmd.Phone, schedule, s.policy.Weekday, now)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.WithRetry(ctx, func() error { return s.payoutRepo.SaveAccount(account) }); err != nil {
+			return nil, err
+		}
+		return account, nil
+	}
+
+	account.ChangeNumber(cmd.Phone, now)
+	if cmd.Schedule != "" && cmd.Schedule != account.Schedule {
+		if err := account.ChangeSchedule(cmd.Schedule, s.policy.Weekday, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := db.WithRetry(ctx, func() error { return s.payoutRepo.UpdateAccount(account) }); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// GetAccount returns the payout account of a seller
+func (s *PayoutService) GetAccount(ctx context.Context, sellerID ids.UserID) (*domain.PayoutAccount, error) {
+	return s.payoutRepo.FindAccount(sellerID)
+}
+
+// ChangeSchedule changes how often a seller is paid
+func (s *PayoutService) ChangeSchedule(ctx context.Context, cmd ChangePayoutScheduleCommand) (*domain.PayoutAccount, error) {
+	account, err := s.payoutRepo.FindAccount(cmd.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := account.ChangeSchedule(cmd.Schedule, s.policy.Weekday, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.payoutRepo.UpdateAccount(account) }); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// ListPayouts lists a seller's payouts, newest first
+func (s *PayoutService) ListPayouts(ctx context.Context, sellerID ids.UserID, query PayoutsQuery) (*Payouts, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPayoutPageSize
+	}
+
+	payouts, total, err := s.payoutRepo.FindBySeller(sellerID, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &Payouts{
+		Payouts: payouts,
+		Total:   total,
+		Limit:   limit,
+		Offset:  query.Offset,
+	}, nil
+}
+
+// GetPayout returns one of a seller's payouts with the orders it paid out
+func (s *PayoutService) GetPayout(ctx context.Context, payoutID string, sellerID ids.UserID) (*domain.Payout, error) {
+	payout, err := s.payoutRepo.FindByID(payoutID)
+	if err != nil {
+		return nil, err
+	}
+	// Other users are told the payout does not exist rather than that it is not theirs
+	if payout.SellerID != sellerID {
+		return nil, errors.NotFoundError("payout not found")
+	}
+	return payout, nil
+}
+
+// RunPayouts pays up to limit sellers whose payout is due the funds released
+// to them since their last payout, less what was refunded to buyers. It
+// returns how many payouts were made.
+func (s *PayoutService) RunPayouts(ctx context.Context, limit int) (int, error) {
+	if s.payments == nil {
+		return 0, nil
+	}
+	accounts, err := s.payoutRepo.FindAccountsDue(s.clock.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	paid := 0
+	for _, account := range accounts {
+		items, err := s.payoutItems(account.SellerID)
+		if err != nil {
+			return paid, err
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		payout, err := domain.NewPayout(account, items, s.clock.Now())
+		if err != nil {
+			return paid, err
+		}
+		err = db.WithRetry(ctx, func() error { return s.payoutRepo.Save(payout) })
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeConflict {
+			// Another run claimed the funds first
+			continue
+		}
+		if err != nil {
+			return paid, err
+		}
+
+		if payout.Status == domain.PayoutStatusPending {
+			reference, err := s.payments.SendPayout(ctx, PaymentRequest{
+				Reference:   payout.ID,
+				Amount:      payout.Amount,
+				Payer:       payout.Payee,
+				Description: "Dongome payout",
+			})
+			if err != nil {
+				// The transfer never started, so the funds are paid out next run
+				payout.Fail("the transfer could not be started", s.clock.Now())
+				if updateErr := db.WithRetry(ctx, func() error { return s.payoutRepo.Update(payout) }); updateErr != nil {
+					return paid, updateErr
+				}
+				return paid, err
+			}
+			payout.Started(reference, s.clock.Now())
+			if err := db.WithRetry(ctx, func() error { return s.payoutRepo.Update(payout) }); err != nil {
+				return paid, err
+			}
+		}
+
+		account.PaidOut(s.policy.Weekday, s.clock.Now())
+		if err := db.WithRetry(ctx, func() error { return s.payoutRepo.UpdateAccount(account) }); err != nil {
+			return paid, err
+		}
+		paid++
+	}
+	return paid, nil
+}
+
+// CheckPendingPayouts looks up the transfers of up to limit pending payouts.
+// Payouts that reached the seller are announced with PayoutSucceeded; failed
+// ones free their funds to be paid out again and are announced with
+// PayoutFailed. It returns how many payouts were settled either way.
+func (s *PayoutService) CheckPendingPayouts(ctx context.Context, limit int) (int, error) {
+	if s.payments == nil {
+		return 0, nil
+	}
+	payouts, err := s.payoutRepo.FindPending(s.clock.Now().Add(-payoutCheckDelay), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, payout := range payouts {
+		result, err := s.payments.PayoutResult(ctx, payout.Reference)
+		if err != nil {
+			return settled, err
+		}
+
+		var eventType string
+		switch paymentOutcome(result.Status) {
+		case paymentSuccessful:
+			if !payout.Succeed(result.TransactionID, s.clock.Now()) {
+				continue
+			}
+			eventType = domain.PayoutSucceededEvent
+		case paymentFailed:
+			if !payout.Fail(result.Reason, s.clock.Now()) {
+				continue
+			}
+			eventType = domain.PayoutFailedEvent
+		default:
+			continue
+		}
+		if err := db.WithRetry(ctx, func() error { return s.payoutRepo.Update(payout) }); err != nil {
+			return settled, err
+		}
+		if result.Reason == payeeNotFound {
+			if err := s.pauseAccount(ctx, payout.SellerID); err != nil {
+				return settled, err
+			}
+		}
+		if err := s.publish(ctx, eventType, payout); err != nil {
+			return settled, err
+		}
+		settled++
+	}
+	return settled, nil
+}
+
+// payoutItems returns the funds released to a seller and not yet paid out.
+// Orders with a refund still pending are left for a later payout, once it
+// is known how much of them is the seller's.
+func (s *PayoutService) payoutItems(sellerID ids.UserID) ([]domain.PayoutItem, error) {
+	escrows, err := s.escrowRepo.FindUnpaid(sellerID, maxPayoutItems)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.PayoutItem, 0, len(escrows))
+	for _, escrow := range escrows {
+		order, err := s.orderRepo.FindByID(escrow.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		refunds, err := s.refundRepo.FindByOrder(escrow.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		if hasPendingRefund(refunds) {
+			continue
+		}
+		amount, err := domain.RefundableAmount(order, refunds)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, domain.PayoutItem{
+			EscrowID: escrow.ID,
+			OrderID:  escrow.OrderID,
+			Amount:   amount,
+		})
+	}
+	return items, nil
+}
+
+// hasPendingRefund checks if any of the refunds is still being sent
+func hasPendingRefund(refunds []*domain.Refund) bool {
+	for _, refund := range refunds {
+		if refund.Status == domain.RefundStatusPending {
+			return true
+		}
+	}
+	return false
+}
+
+// pauseAccount stops payouts to a seller's wallet once Mobile Money no
+// longer knows it, until the seller registers a number again
+func (s *PayoutService) pauseAccount(ctx context.Context, sellerID ids.UserID) error {
+	account, err := s.payoutRepo.FindAccount(sellerID)
+	if err != nil {
+		return err
+	}
+	account.Unverify(s.clock.Now())
+	return db.WithRetry(ctx, func() error { return s.payoutRepo.UpdateAccount(account) })
+}
+
+// findAccount finds the payout account of a seller, or nil if they have none
+func (s *PayoutService) findAccount(sellerID ids.UserID) (*domain.PayoutAccount, error) {
+	account, err := s.payoutRepo.FindAccount(sellerID)
+	if err == nil {
+		return account, nil
+	}
+	if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+		return nil, nil
+	}
+	return nil, err
+}
+
+// publish announces a settled payout to the seller
+func (s *PayoutService) publish(ctx context.Context, eventType string, payout *domain.Payout) error {
+	channels, err := s.preferences.NotificationChannels(ctx, payout.SellerID, orderUpdatesCategory)
+	if err != nil {
+		return err
+	}
+	orderIDs := make([]ids.OrderID, len(payout.Items))
+	for i, item := range payout.Items {
+		orderIDs[i] = item.OrderID
+	}
+
+	event, err := events.NewEvent(
+		eventType,
+		payout.ID,
+		domain.PayoutSettled{
+			PayoutID:      payout.ID,
+			SellerID:      payout.SellerID,
+			Amount:        payout.Amount,
+			OrderIDs:      orderIDs,
+			Status:        payout.Status,
+			TransactionID: payout.TransactionID,
+			Reason:        payout.FailureReason,
+			Channels:      channels,
+			Timestamp:     s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return s.eventBus.Publish(ctx, event)
+}
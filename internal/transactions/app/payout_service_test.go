@@ -0,0 +1,246 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/money"
+	"dongome/pkg/payments"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// payoutFixture pays sellers the funds of orders released from escrow,
+// paying weekly on Fridays and daily by default
+type payoutFixture struct {
+	orders   *fakeOrderRepository
+	provider *fakePaymentProvider
+	gateway  *fakePayoutGateway
+	eventBus *fakeEventBus
+	clock    *clock.Frozen
+	escrows  *app.EscrowService
+	refunds  *app.RefundService
+	claims   *app.ClaimService
+	service  *app.PayoutService
+}
+
+func newPayoutFixture() *payoutFixture {
+	f := &payoutFixture{
+		orders:   newFakeOrderRepository(),
+		provider: newFakePaymentProvider(payments.ProviderMoMo),
+		gateway:  &fakePayoutGateway{inactive: make(map[string]bool), results: make(map[string]*app.PaymentResult)},
+		eventBus: &fakeEventBus{},
+		// A Wednesday morning
+		clock: clock.NewFrozen(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)),
+	}
+	escrowRepo := newFakeEscrowRepository()
+	refundRepo := &fakeRefundRepository{}
+	f.escrows = app.NewEscrowService(escrowRepo, f.orders, newFakeListingReservations(), &fakeEventBus{}, 14*24*time.Hour, 72*time.Hour, f.clock)
+	f.refunds = app.NewRefundService(refundRepo, f.orders, escrowRepo, f.provider.registry(), nil, &fakeNotificationPreferences{}, &fakeEventBus{}, f.clock)
+	f.claims = app.NewClaimService(newFakeClaimRepository(), f.orders, escrowRepo, refundRepo, &fakeNotificationPreferences{}, f.eventBus, f.clock)
+	f.service = app.NewPayoutService(newFakePayoutRepository(escrowRepo), escrowRepo, f.orders, refundRepo, f.gateway, &fakeNotificationPreferences{}, f.eventBus, domain.PayoutPolicy{
+		DefaultSchedule: domain.PayoutScheduleDaily,
+		Weekday:         time.Friday,
+	}, f.clock)
+	return f
+}
+
+// releasedOrder places an order of seller-a paid by Mobile Money, whose
+// buyer confirmed delivery
+func (f *payoutFixture) releasedOrder(t *testing.T) *domain.Order {
+	t.Helper()
+	listing := newActiveListing(t, "seller-a")
+	orderService := app.NewOrderService(f.orders, newFakeListingReservations(listing), &fakeNotificationPreferences{}, f.provider.registry(payments.MethodMoMo), &fakeEventBus{}, 30*time.Minute, "", nil, nil, nil, nil)
+	ctx := context.Background()
+
+	order, err := orderService.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	_, err = orderService.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Phone: "+233241234567"})
+	require.NoError(t, err)
+	order, err = orderService.CompletePayment(ctx, order.ID)
+	require.NoError(t, err)
+	_, err = f.escrows.HoldPayment(ctx, order.ID)
+	require.NoError(t, err)
+	_, err = f.escrows.ConfirmDelivery(ctx, order.ID, "buyer-a")
+	require.NoError(t, err)
+	// Each order is released a moment after the last
+	f.clock.Advance(time.Second)
+	return order
+}
+
+func TestPayoutService_RegisterAccount(t *testing.T) {
+	f := newPayoutFixture()
+	ctx := context.Background()
+
+	f.gateway.inactive["233501234567"] = true
+	_, err := f.service.RegisterAccount(ctx, app.RegisterPayoutAccountCommand{SellerID: "seller-a", Phone: "+233501234567"})
+	assert.Error(t, err, "numbers that are not wallets are refused")
+	_, err = f.service.RegisterAccount(ctx, app.RegisterPayoutAccountCommand{SellerID: "seller-a", Phone: "+233241234567", Schedule: "monthly"})
+	assert.Error(t, err)
+
+	account, err := f.service.RegisterAccount(ctx, app.RegisterPayoutAccountCommand{SellerID: "seller-a", Phone: "+233241234567"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.PayoutScheduleDaily, account.Schedule)
+	assert.True(t, account.IsVerified())
+	assert.Equal(t, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), account.NextPayoutAt)
+
+	// Registering again moves payouts to the new number and keeps the schedule
+	moved, err := f.service.RegisterAccount(ctx, app.RegisterPayoutAccountCommand{SellerID: "seller-a", Phone: "+233201234567"})
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, moved.ID)
+	assert.Equal(t, "+233201234567", moved.Phone)
+	assert.Equal(t, domain.PayoutScheduleDaily, moved.Schedule)
+
+	weekly, err := f.service.ChangeSchedule(ctx, app.ChangePayoutScheduleCommand{SellerID: "seller-a", Schedule: domain.PayoutScheduleWeekly})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), weekly.NextPayoutAt, "weekly payouts are made on Fridays")
+
+	_, err = f.service.ChangeSchedule(ctx, app.ChangePayoutScheduleCommand{SellerID: "seller-b", Schedule: domain.PayoutScheduleWeekly})
+	assert.Error(t, err, "sellers without an account have no schedule")
+}
+
+func TestPayoutService_BatchesReleasedFundsOnSchedule(t *testing.T) {
+	f := newPayoutFixture()
+	ctx := context.Background()
+	_, err := f.service.RegisterAccount(ctx, app.RegisterPayoutAccountCommand{SellerID: "seller-a", Phone: "+233241234567"})
+	require.NoError(t, err)
+
+	first := f.releasedOrder(t)
+	refunded := f.releasedOrder(t)
+	pending := f.releasedOrder(t)
+	refund, err := f.refunds.RefundOrder(ctx, app.RefundOrderCommand{OrderID: refunded.ID, SellerID: "seller-a", IdempotencyKey: "key-1", Amount: 40, Reason: "scratched"})
+	require.NoError(t, err)
+	f.provider.results[refund.Reference] = &payments.Result{Status: payments.StatusSuccessful}
+	f.clock.Advance(time.Minute)
+	_, err = f.refunds.CheckPendingRefunds(ctx, 10)
+	require.NoError(t, err)
+	_, err = f.refunds.RefundOrder(ctx, app.RefundOrderCommand{OrderID: pending.ID, SellerID: "seller-a", IdempotencyKey: "key-2", Amount: 10, Reason: "late"})
+	require.NoError(t, err)
+
+	// Daily payouts wait for midnight
+	count, err := f.service.RunPayouts(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	f.clock.Advance(15 * time.Hour)
+	count, err = f.service.RunPayouts(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.Len(t, f.gateway.payouts, 1)
+	assert.Equal(t, "233241234567", f.gateway.payouts[0].Payer)
+	assert.Equal(t, money.Cedis(160), f.gateway.payouts[0].Amount, "refunds are taken off the seller's funds")
+
+	payouts, err := f.service.ListPayouts(ctx, "seller-a", app.PayoutsQuery{})
+	require.NoError(t, err)
+	require.Len(t, payouts.Payouts, 1)
+	payout := payouts.Payouts[0]
+	assert.Equal(t, domain.PayoutStatusPending, payout.Status)
+	require.Len(t, payout.Items, 2, "orders with a refund pending wait for a later payout")
+	assert.Equal(t, first.ID, payout.Items[0].OrderID)
+	assert.Equal(t, money.Cedis(60), payout.Items[1].Amount)
+
+	// Funds are paid out once, and the next payout is the next day
+	f.clock.Advance(time.Hour)
+	count, err = f.service.RunPayouts(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	account, err := f.service.GetAccount(ctx, "seller-a")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), account.NextPayoutAt)
+
+	_, err = f.service.GetPayout(ctx, payout.ID, "seller-b")
+	assert.Error(t, err)
+}
+
+func TestPayoutService_PaidOutOrdersCannotBeRefunded(t *testing.T) {
+	f := newPayoutFixture()
+	ctx := context.Background()
+	_, err := f.service.RegisterAccount(ctx, app.RegisterPayoutAccountCommand{SellerID: "seller-a", Phone: "+233241234567", Schedule: domain.PayoutScheduleInstant})
+	require.NoError(t, err)
+
+	order := f.releasedOrder(t)
+	count, err := f.service.RunPayouts(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	assert.Equal(t, order.Amount, f.gateway.payouts[0].Amount)
+
+	// The seller was paid in full, so nothing could be taken back off a payout
+	_, err = f.refunds.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-1", Amount: 40, Reason: "scratched"})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeConflict, err.(*errors.DomainError).Code)
+	assert.Empty(t, f.provider.refunds)
+}
+
+func TestPayoutService_CheckPendingPayouts(t *testing.T) {
+	f := newPayoutFixture()
+	ctx := context.Background()
+	_, err := f.service.RegisterAccount(ctx, app.RegisterPayoutAccountCommand{SellerID: "seller-a", Phone: "+233241234567", Schedule: domain.PayoutScheduleInstant})
+	require.NoError(t, err)
+
+	order := f.releasedOrder(t)
+	count, err := f.service.RunPayouts(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	f.gateway.results["payout-1"] = &app.PaymentResult{Status: "FAILED", Reason: "PAYEE_NOT_FOUND"}
+
+	f.clock.Advance(time.Minute)
+	count, err = f.service.CheckPendingPayouts(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	failed := f.eventBus.eventsOfType(domain.PayoutFailedEvent)
+	require.Len(t, failed, 1)
+	var data domain.PayoutSettled
+	require.NoError(t, events.ParseEventData(failed[0], &data))
+	assert.Equal(t, "PAYEE_NOT_FOUND", data.Reason)
+	assert.Equal(t, []string{"email", "push"}, data.Channels)
+
+	// Payouts to a wallet that is gone pause until the seller registers a number again
+	count, err = f.service.RunPayouts(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	_, err = f.service.RegisterAccount(ctx, app.RegisterPayoutAccountCommand{SellerID: "seller-a", Phone: "+233201234567"})
+	require.NoError(t, err)
+
+	count, err = f.service.RunPayouts(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Len(t, f.gateway.payouts, 2)
+	assert.Equal(t, "233201234567", f.gateway.payouts[1].Payer)
+	assert.Equal(t, order.Amount, f.gateway.payouts[1].Amount)
+	f.gateway.results["payout-2"] = &app.PaymentResult{Status: "SUCCESSFUL", TransactionID: "tx-1"}
+
+	f.clock.Advance(time.Minute)
+	count, err = f.service.CheckPendingPayouts(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	succeeded := f.eventBus.eventsOfType(domain.PayoutSucceededEvent)
+	require.Len(t, succeeded, 1)
+	require.NoError(t, events.ParseEventData(succeeded[0], &data))
+	assert.Equal(t, "tx-1", data.TransactionID)
+	assert.Equal(t, order.ID, data.OrderIDs[0])
+}
+
+func TestPayoutService_TransferThatNeverStartedIsPaidNextRun(t *testing.T) {
+	f := newPayoutFixture()
+	ctx := context.Background()
+	_, err := f.service.RegisterAccount(ctx, app.RegisterPayoutAccountCommand{SellerID: "seller-a", Phone: "+233241234567", Schedule: domain.PayoutScheduleInstant})
+	require.NoError(t, err)
+	f.releasedOrder(t)
+
+	f.gateway.sendErr = errors.UnavailableError("mobile money payouts are temporarily unavailable")
+	_, err = f.service.RunPayouts(ctx, 10)
+	assert.Error(t, err)
+
+	f.gateway.sendErr = nil
+	count, err := f.service.RunPayouts(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, money.Cedis(100), f.gateway.payouts[0].Amount)
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"strconv"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/money"
+	"dongome/pkg/rules"
+)
+
+// PaymentWindowRule is the business rule deciding how many minutes a buyer
+// has to pay before their order is abandoned and the listing released
+const PaymentWindowRule = "checkout.payment_window_minutes"
+
+// PaymentWindowDefinition declares PaymentWindowRule. Until an administrator
+// changes it, every order gets defaultMinutes.
+func PaymentWindowDefinition(defaultMinutes int) rules.Definition {
+	return rules.Definition{
+		Key:         PaymentWindowRule,
+		Description: "How many minutes a buyer has to pay before the listing is released",
+		Kind:        rules.KindNumber,
+		Variables: []rules.Variable{
+			{Name: "amount", Kind: rules.KindNumber, Description: "the order amount in major units, such as cedis"},
+			{Name: "currency", Kind: rules.KindString, Description: "the order currency, such as RWF"},
+		},
+		Default: strconv.Itoa(defaultMinutes),
+	}
+}
+
+// PaymentWindowFacts are the facts about an order that checkout rules use
+func PaymentWindowFacts(amount money.Money) rules.Facts {
+	return rules.Facts{
+		"amount":   amount.Major(),
+		"currency": amount.Currency,
+	}
+}
+
+// PolicyRules evaluates business rules administrators can change. It is
+// implemented by rules.Engine.
+type PolicyRules interface {
+	Number(key string, facts rules.Facts) float64
+}
+
+// FraudScoreRule is the business rule scoring how likely an order is
+// fraudulent when it is placed. Orders scoring the hold score or more are
+// held for review.
+const FraudScoreRule = "orders.fraud_score"
+
+// defaultFraudScore holds orders from blocked phone numbers, and orders of
+// accounts a day old that order in bursts or from another region
+const defaultFraudScore = "(phone_blocked ? 100 : 0) + (account_age_days < 1 ? 30 : 0) + (location_mismatch ? 20 : 0) + (orders_last_hour > 3 ? 40 : 0) + (orders_last_day > 10 ? 30 : 0)"
+
+// FraudScoreDefinition declares FraudScoreRule
+func FraudScoreDefinition() rules.Definition {
+	return rules.Definition{
+		Key:         FraudScoreRule,
+		Description: "How likely an order is fraudulent; orders scoring fraud.hold_score or more are held for review",
+		Kind:        rules.KindNumber,
+		Variables: []rules.Variable{
+			{Name: "account_age_days", Kind: rules.KindNumber, Description: "how many days ago the buyer signed up"},
+			{Name: "location_mismatch", Kind: rules.KindBool, Description: "the buyer's delivery address is in another region than the listing"},
+			{Name: "orders_last_hour", Kind: rules.KindNumber, Description: "how many orders the buyer placed in the last hour, this one included"},
+			{Name: "orders_last_day", Kind: rules.KindNumber, Description: "how many orders the buyer placed in the last 24 hours, this one included"},
+			{Name: "phone_blocked", Kind: rules.KindBool, Description: "the buyer's phone number, or that of their delivery address, is blocked"},
+			{Name: "amount", Kind: rules.KindNumber, Description: "the order amount in major units, such as cedis"},
+			{Name: "currency", Kind: rules.KindString, Description: "the order currency, such as GHS"},
+		},
+		Default: defaultFraudScore,
+	}
+}
+
+// FraudScoreFacts are the facts about an order that FraudScoreRule uses
+func FraudScoreFacts(signals domain.FraudSignals) rules.Facts {
+	return rules.Facts{
+		"account_age_days":  signals.AccountAgeDays,
+		"location_mismatch": signals.LocationMismatch,
+		"orders_last_hour":  signals.OrdersLastHour,
+		"orders_last_day":   signals.OrdersLastDay,
+		"phone_blocked":     signals.PhoneBlocked,
+		"amount":            signals.Amount,
+		"currency":          signals.Currency,
+	}
+}
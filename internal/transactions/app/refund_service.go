@@ -0,0 +1,320 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+	"dongome/pkg/payments"
+)
+
+// refundCheckDelay is how long after it was sent a refund is first looked up
+const refundCheckDelay = 30 * time.Second
+
+// RefundOrderCommand represents a seller refunding part or all of an order's
+// payment. No amount refunds all that is left; IdempotencyKey comes from the
+// Idempotency-Key header.
+type RefundOrderCommand struct {
+	OrderID        ids.OrderID `json:"-"`
+	SellerID       ids.UserID  `json:"-"`
+	IdempotencyKey string      `json:"-"`
+	Amount         float64     `json:"amount" binding:"omitempty,gt=0"`
+	Reason         string      `json:"reason" binding:"required,max=500"`
+}
+
+// WalletCredits credits refunds of orders paid from a wallet back to it
+type WalletCredits interface {
+	// CreditRefund credits a refund to the buyer's wallet, once however
+	// often it is asked
+	CreditRefund(ctx context.Context, buyerID ids.UserID, refundID string, amount money.Money) error
+}
+
+// RefundService sends the payments of orders back to the buyers' wallets or
+// cards, in full or in part, through the provider each order was paid
+// through, and tracks each refund until the provider reports it done
+type RefundService struct {
+	refundRepo  domain.RefundRepository
+	orderRepo   domain.OrderRepository
+	escrowRepo  domain.EscrowRepository
+	providers   PaymentProviders
+	wallets     WalletCredits
+	preferences NotificationPreferences
+	eventBus    events.EventBus
+	clock       clock.Clock
+}
+
+// NewRefundService creates a new refund service. providers may be nil where
+// refunds cannot be sent through payment providers; refunds of orders paid
+// from a wallet go back to it through wallets. clk may be nil to use the
+// system clock.
+func NewRefundService(refundRepo domain.RefundRepository, orderRepo domain.OrderRepository, escrowRepo domain.EscrowRepository, providers PaymentProviders, wallets WalletCredits, preferences NotificationPreferences, eventBus events.EventBus, clk clock.Clock) *RefundService {
+	return &RefundService{
+		refundRepo:  refundRepo,
+		orderRepo:   orderRepo,
+		escrowRepo:  escrowRepo,
+		providers:   providers,
+		wallets:     wallets,
+		preferences: preferences,
+		eventBus:    eventBus,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// RefundOrder refunds the buyer of one of the seller's orders. Repeating a
+// request with the same idempotency key returns the refund it made.
+func (s *RefundService) RefundOrder(ctx context.Context, cmd RefundOrderCommand) (*domain.Refund, error) {
+	order, err := s.orderRepo.FindByID(cmd.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.SellerID != cmd.SellerID {
+		return nil, errors.ForbiddenError("only the seller can refund the order")
+	}
+
+	var amount money.Money
+	if cmd.Amount != 0 {
+		// Amounts are read exactly, so that one too small to refund is not
+		// taken for a full refund
+		if amount, err = money.ParseMajor(strconv.FormatFloat(cmd.Amount, 'f', -1, 64), order.Amount.Currency); err != nil {
+			return nil, err
+		}
+		if !amount.IsPositive() {
+			return nil, errors.ValidationError("refund amount must be positive")
+		}
+	}
+	return s.refund(ctx, order, amount, cmd.SellerID.String(), cmd.Reason, cmd.IdempotencyKey)
+}
+
+// RefundCancelledOrder refunds what is left of the payment of an order
+// cancelled while its funds were in escrow. Each escrow is refunded once,
+// however often its refund is asked for.
+func (s *RefundService) RefundCancelledOrder(ctx context.Context, orderID ids.OrderID, escrowID, reason string) (*domain.Refund, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.refund(ctx, order, money.Money{}, domain.SagaActorSystem, reason, "escrow:"+escrowID)
+}
+
+// ListRefunds returns the refunds of an order to its buyer or seller, oldest first
+func (s *RefundService) ListRefunds(ctx context.Context, orderID ids.OrderID, userID ids.UserID) ([]*domain.Refund, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	// Other users are told the order does not exist rather than that it is not theirs
+	if order.BuyerID != userID && order.SellerID != userID {
+		return nil, errors.NotFoundError("order not found")
+	}
+	return s.refundRepo.FindByOrder(order.ID)
+}
+
+// CheckPendingRefunds looks up up to limit pending refunds with their providers.
+// Refunds that reached the buyer are announced with PaymentRefunded; failed
+// ones leave their amount to be refunded again. It returns how many refunds
+// were settled either way.
+func (s *RefundService) CheckPendingRefunds(ctx context.Context, limit int) (int, error) {
+	if s.providers == nil {
+		return 0, nil
+	}
+	refunds, err := s.refundRepo.FindPending(s.clock.Now().Add(-refundCheckDelay), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, refund := range refunds {
+		provider, err := s.providers.Provider(refund.Provider)
+		if err != nil {
+			// The provider is no longer configured; the refund stays pending
+			continue
+		}
+		result, err := provider.RefundResult(ctx, refund.Reference)
+		if err != nil {
+			return settled, err
+		}
+
+		switch paymentOutcome(result.Status) {
+		case paymentSuccessful:
+			if !refund.Succeed(result.TransactionID, s.clock.Now()) {
+				continue
+			}
+			if err := db.WithRetry(ctx, func() error { return s.refundRepo.Update(refund) }); err != nil {
+				return settled, err
+			}
+			if err := s.publishRefunded(ctx, refund); err != nil {
+				return settled, err
+			}
+		case paymentFailed:
+			if !refund.Fail(result.Reason, s.clock.Now()) {
+				continue
+			}
+			if err := db.WithRetry(ctx, func() error { return s.refundRepo.Update(refund) }); err != nil {
+				return settled, err
+			}
+		default:
+			continue
+		}
+		settled++
+	}
+	return settled, nil
+}
+
+// refund records a refund of an order and sends it. A refund already made
+// for the idempotency key is returned as it is. Orders are refunded until
+// their funds are paid out to the seller, as refunds are taken off payouts.
+func (s *RefundService) refund(ctx context.Context, order *domain.Order, amount money.Money, requestedBy, reason, idempotencyKey string) (*domain.Refund, error) {
+	var provider payments.PaymentProvider
+	if order.PaymentMethod != domain.PaymentMethodWallet {
+		if s.providers == nil {
+			return nil, errors.UnavailableError("the payment provider is not available")
+		}
+		var err error
+		if provider, err = s.providers.Provider(order.PaymentProvider); err != nil {
+			return nil, err
+		}
+	}
+	if existing, err := s.findByIdempotencyKey(order.ID, idempotencyKey); existing != nil || err != nil {
+		return existing, err
+	}
+	// Funds paid out to the seller cannot be taken back off later payouts
+	paidOut, err := s.escrowRepo.IsPaidOut(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if paidOut {
+		return nil, errors.ConflictError("the order's funds were paid out to the seller and it can no longer be refunded")
+	}
+
+	refunds, err := s.refundRepo.FindByOrder(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	refund, err := domain.NewRefund(order, refunds, amount, requestedBy, reason, idempotencyKey, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	err = db.WithRetry(ctx, func() error { return s.refundRepo.Save(refund) })
+	if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeConflict {
+		// A concurrent request with the same key saved its refund first
+		return s.refundRepo.FindByIdempotencyKey(order.ID, idempotencyKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if order.PaymentMethod == domain.PaymentMethodWallet {
+		return s.refundToWallet(ctx, refund)
+	}
+
+	reference, err := provider.Refund(ctx, payments.RefundRequest{
+		Reference:        refund.ID,
+		PaymentReference: order.PaymentReference,
+		Amount:           refund.Amount,
+		Payee:            refund.Payee,
+		Description:      "Refund: " + order.Title,
+	})
+	if err != nil {
+		// The refund never started, so the amount can be refunded again
+		refund.Fail("the refund could not be started", s.clock.Now())
+		if updateErr := db.WithRetry(ctx, func() error { return s.refundRepo.Update(refund) }); updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, err
+	}
+
+	refund.Started(reference, s.clock.Now())
+	if err := db.WithRetry(ctx, func() error { return s.refundRepo.Update(refund) }); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// refundToWallet credits a refund of an order paid from the buyer's wallet
+// back to it, so the refund succeeds at once
+func (s *RefundService) refundToWallet(ctx context.Context, refund *domain.Refund) (*domain.Refund, error) {
+	if err := s.wallets.CreditRefund(ctx, refund.BuyerID, refund.ID, refund.Amount); err != nil {
+		// The wallet was not credited, so the amount can be refunded again
+		refund.Fail("the wallet could not be credited", s.clock.Now())
+		if updateErr := db.WithRetry(ctx, func() error { return s.refundRepo.Update(refund) }); updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, err
+	}
+
+	refund.Succeed("", s.clock.Now())
+	if err := db.WithRetry(ctx, func() error { return s.refundRepo.Update(refund) }); err != nil {
+		return nil, err
+	}
+	if err := s.publishRefunded(ctx, refund); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// findByIdempotencyKey finds the refund of an order made for a request, or
+// nil if there is none
+func (s *RefundService) findByIdempotencyKey(orderID ids.OrderID, key string) (*domain.Refund, error) {
+	refund, err := s.refundRepo.FindByIdempotencyKey(orderID, key)
+	if err == nil {
+		return refund, nil
+	}
+	if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+		return nil, nil
+	}
+	return nil, err
+}
+
+// publishRefunded announces a refund that reached the buyer, with all that
+// was refunded of the order so far
+func (s *RefundService) publishRefunded(ctx context.Context, refund *domain.Refund) error {
+	order, err := s.orderRepo.FindByID(refund.OrderID)
+	if err != nil {
+		return err
+	}
+	refunds, err := s.refundRepo.FindByOrder(order.ID)
+	if err != nil {
+		return err
+	}
+	refunded := money.New(0, order.Amount.Currency)
+	for _, other := range refunds {
+		if other.Status != domain.RefundStatusSucceeded {
+			continue
+		}
+		if refunded, err = refunded.Add(other.Amount); err != nil {
+			return err
+		}
+	}
+	channels, err := s.preferences.NotificationChannels(ctx, order.BuyerID, orderUpdatesCategory)
+	if err != nil {
+		return err
+	}
+
+	event, err := events.NewEvent(
+		domain.PaymentRefundedEvent,
+		order.ID.String(),
+		domain.PaymentRefunded{
+			RefundID:      refund.ID,
+			OrderID:       order.ID,
+			BuyerID:       order.BuyerID,
+			SellerID:      order.SellerID,
+			Amount:        refund.Amount,
+			Refunded:      refunded,
+			Full:          refunded.Equal(order.Amount),
+			Reason:        refund.Reason,
+			TransactionID: refund.TransactionID,
+			Channels:      channels,
+			Timestamp:     s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return s.eventBus.Publish(ctx, event)
+}
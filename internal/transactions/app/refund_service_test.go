@@ -0,0 +1,171 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/money"
+	"dongome/pkg/payments"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refundFixture refunds orders paid through a fake Mobile Money gateway
+type refundFixture struct {
+	orders   *fakeOrderRepository
+	provider *fakePaymentProvider
+	eventBus *fakeEventBus
+	clock    *clock.Frozen
+	service  *app.RefundService
+}
+
+func newRefundFixture() *refundFixture {
+	f := &refundFixture{
+		orders:   newFakeOrderRepository(),
+		provider: newFakePaymentProvider(payments.ProviderMoMo),
+		eventBus: &fakeEventBus{},
+		clock:    clock.NewFrozen(time.Now()),
+	}
+	f.service = app.NewRefundService(&fakeRefundRepository{}, f.orders, newFakeEscrowRepository(), f.provider.registry(), nil, &fakeNotificationPreferences{}, f.eventBus, f.clock)
+	return f
+}
+
+// paidOrder places an order and pays for it from the buyer's wallet
+func (f *refundFixture) paidOrder(t *testing.T) *domain.Order {
+	t.Helper()
+	listing := newActiveListing(t, "seller-a")
+	orderService := app.NewOrderService(f.orders, newFakeListingReservations(listing), &fakeNotificationPreferences{}, f.provider.registry(payments.MethodMoMo), &fakeEventBus{}, 30*time.Minute, "", nil, nil, nil, nil)
+	ctx := context.Background()
+
+	order, err := orderService.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	_, err = orderService.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Phone: "+233241234567"})
+	require.NoError(t, err)
+	order, err = orderService.CompletePayment(ctx, order.ID)
+	require.NoError(t, err)
+	return order
+}
+
+func TestRefundService_PartialRefundsUpToThePayment(t *testing.T) {
+	f := newRefundFixture()
+	ctx := context.Background()
+	order := f.paidOrder(t)
+
+	_, err := f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "buyer-a", IdempotencyKey: "key-1", Amount: 40, Reason: "damaged"})
+	assert.Error(t, err, "only the seller refunds the order")
+
+	refund, err := f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-1", Amount: 40, Reason: "damaged"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RefundStatusPending, refund.Status)
+	assert.Equal(t, money.Cedis(40), refund.Amount)
+	assert.Equal(t, "transfer-1", refund.Reference)
+	require.Len(t, f.provider.refunds, 1)
+	assert.Equal(t, "233241234567", f.provider.refunds[0].Payee)
+	assert.Equal(t, order.PaymentReference, f.provider.refunds[0].PaymentReference)
+
+	// Retrying the request returns the same refund
+	again, err := f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-1", Amount: 40, Reason: "damaged"})
+	require.NoError(t, err)
+	assert.Equal(t, refund.ID, again.ID)
+	assert.Len(t, f.provider.refunds, 1)
+
+	_, err = f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-2", Amount: 70, Reason: "damaged"})
+	assert.Error(t, err, "refunds cannot exceed the payment")
+
+	// Amounts too small to refund are refused rather than taken for a full refund
+	_, err = f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-2", Amount: 0.001, Reason: "damaged"})
+	assert.Error(t, err)
+	_, err = f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-2", Amount: 1e300, Reason: "damaged"})
+	assert.Error(t, err)
+	assert.Len(t, f.provider.refunds, 1)
+
+	// No amount refunds the rest
+	rest, err := f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-3", Reason: "damaged"})
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(60), rest.Amount)
+
+	refunds, err := f.service.ListRefunds(ctx, order.ID, "buyer-a")
+	require.NoError(t, err)
+	assert.Len(t, refunds, 2)
+	_, err = f.service.ListRefunds(ctx, order.ID, "stranger")
+	assert.Error(t, err)
+}
+
+func TestRefundService_CheckPendingRefunds(t *testing.T) {
+	f := newRefundFixture()
+	ctx := context.Background()
+	order := f.paidOrder(t)
+
+	first, err := f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-1", Amount: 40, Reason: "damaged"})
+	require.NoError(t, err)
+	second, err := f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-2", Reason: "returned"})
+	require.NoError(t, err)
+	f.provider.results[first.Reference] = &payments.Result{Status: payments.StatusSuccessful, TransactionID: "tx-1"}
+	f.provider.results[second.Reference] = &payments.Result{Status: payments.StatusFailed, Reason: "PAYEE_NOT_FOUND"}
+
+	// Transfers are given time before they are looked up
+	count, err := f.service.CheckPendingRefunds(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	f.clock.Advance(time.Minute)
+	count, err = f.service.CheckPendingRefunds(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, domain.RefundStatusSucceeded, first.Status)
+	assert.Equal(t, domain.RefundStatusFailed, second.Status)
+	assert.Equal(t, "PAYEE_NOT_FOUND", second.FailureReason)
+
+	refunded := f.eventBus.eventsOfType(domain.PaymentRefundedEvent)
+	require.Len(t, refunded, 1)
+	var data domain.PaymentRefunded
+	require.NoError(t, events.ParseEventData(refunded[0], &data))
+	assert.Equal(t, money.Cedis(40), data.Refunded)
+	assert.False(t, data.Full)
+	assert.Equal(t, "tx-1", data.TransactionID)
+	assert.Equal(t, []string{"email", "push"}, data.Channels)
+
+	// The failed refund's amount can be refunded again
+	retried, err := f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-3", Reason: "returned"})
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(60), retried.Amount)
+}
+
+func TestRefundService_RefundCancelledOrder(t *testing.T) {
+	f := newRefundFixture()
+	ctx := context.Background()
+	order := f.paidOrder(t)
+
+	refund, err := f.service.RefundCancelledOrder(ctx, order.ID, "escrow-1", "out of stock")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(100), refund.Amount)
+	assert.Equal(t, domain.SagaActorSystem, refund.RequestedBy)
+
+	// Redelivered EscrowRefunded events refund once
+	again, err := f.service.RefundCancelledOrder(ctx, order.ID, "escrow-1", "out of stock")
+	require.NoError(t, err)
+	assert.Equal(t, refund.ID, again.ID)
+	assert.Len(t, f.provider.refunds, 1)
+}
+
+func TestRefundService_TransferThatNeverStartedCanBeRetried(t *testing.T) {
+	f := newRefundFixture()
+	ctx := context.Background()
+	order := f.paidOrder(t)
+
+	f.provider.refundErr = errors.UnavailableError("mobile money refunds are temporarily unavailable")
+	_, err := f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-1", Reason: "damaged"})
+	assert.Error(t, err)
+
+	f.provider.refundErr = nil
+	refund, err := f.service.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-2", Reason: "damaged"})
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(100), refund.Amount)
+}
@@ -0,0 +1,407 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// SagaCompensation undoes a step of a checkout. It must be safe to run more
+// than once, as failed compensations are retried.
+type SagaCompensation func(ctx context.Context, saga *domain.CheckoutSaga) error
+
+// StuckSagasQuery represents the query to list sagas that need attention
+type StuckSagasQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// AdvanceSagaCommand represents an administrator marking the running step of
+// a saga as done, for steps completed outside the marketplace. Step must be
+// the running step, so two administrators cannot advance a saga twice.
+type AdvanceSagaCommand struct {
+	SagaID  string          `json:"-"`
+	AdminID ids.UserID      `json:"-"`
+	Step    domain.SagaStep `json:"step" binding:"required"`
+	Reason  string          `json:"reason" binding:"required,max=500"`
+}
+
+// CompensateSagaCommand represents an administrator undoing a saga, or
+// retrying the compensation of a saga that failed to undo. Skip marks the
+// step that failed to undo as undone without running its compensation, for
+// steps undone by hand such as refunding a payment.
+type CompensateSagaCommand struct {
+	SagaID  string     `json:"-"`
+	AdminID ids.UserID `json:"-"`
+	Reason  string     `json:"reason" binding:"required,max=500"`
+	Skip    bool       `json:"skip"`
+}
+
+// CheckoutSagas represents a page of checkout sagas
+type CheckoutSagas struct {
+	Sagas  []*domain.CheckoutSaga `json:"sagas"`
+	Total  int64                  `json:"total"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+}
+
+// defaultSagaPageSize is how many sagas are listed when no limit is given
+const defaultSagaPageSize = 20
+
+// SagaOrchestrator drives the checkout saga of each order from the order
+// events, and compensates the steps done when a checkout fails or times out
+type SagaOrchestrator struct {
+	sagaRepo      domain.SagaRepository
+	orderRepo     domain.OrderRepository
+	listings      ListingReservations
+	coupons       CheckoutCoupons
+	eventBus      events.EventBus
+	paymentGrace  time.Duration
+	compensations map[domain.SagaStep]SagaCompensation
+	clock         clock.Clock
+}
+
+// NewSagaOrchestrator creates a new saga orchestrator. A checkout waiting for
+// payment times out paymentGrace after the order's payment deadline, leaving
+// the abandonment job time to abandon the order first. coupons releases the
+// coupons of orders cancelled before they were paid, and may be nil where no
+// coupons are accepted. Orders the saga cancels are announced on eventBus.
+// clk may be nil to use the system clock.
+func NewSagaOrchestrator(sagaRepo domain.SagaRepository, orderRepo domain.OrderRepository, listings ListingReservations, coupons CheckoutCoupons, eventBus events.EventBus, paymentGrace time.Duration, clk clock.Clock) *SagaOrchestrator {
+	o := &SagaOrchestrator{
+		sagaRepo:     sagaRepo,
+		orderRepo:    orderRepo,
+		listings:     listings,
+		coupons:      coupons,
+		eventBus:     eventBus,
+		paymentGrace: paymentGrace,
+		clock:        clock.OrSystem(clk),
+	}
+	o.compensations = map[domain.SagaStep]SagaCompensation{
+		domain.SagaStepReserveListing: o.releaseListing,
+		domain.SagaStepAwaitPayment:   o.cancelOrder,
+	}
+	return o
+}
+
+// StartCheckout starts the saga of a new order. The listing is reserved when
+// the order is placed, so the saga starts out waiting for the payment.
+func (o *SagaOrchestrator) StartCheckout(ctx context.Context, orderID ids.OrderID) (*domain.CheckoutSaga, error) {
+	order, err := o.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if saga, err := o.sagaRepo.FindByOrder(orderID); err == nil {
+		return saga, nil
+	}
+
+	now := o.clock.Now()
+	saga := domain.NewCheckoutSaga(order.ID, order.BuyerID, order.ListingID, now)
+	if _, err := saga.CompleteStep(domain.SagaStepReserveListing, o.deadline(domain.SagaStepAwaitPayment, order), domain.SagaActorSystem, "order placed", now); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return o.sagaRepo.Save(saga) }); err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
+
+// CompletePayment completes the saga of a paid order. Payments arriving
+// after the checkout was compensated leave the saga for an administrator.
+func (o *SagaOrchestrator) CompletePayment(ctx context.Context, orderID ids.OrderID) (*domain.CheckoutSaga, error) {
+	saga, err := o.sagaRepo.FindByOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := saga.CompleteStep(domain.SagaStepAwaitPayment, nil, domain.SagaActorSystem, "order paid", o.clock.Now())
+	if err != nil || !changed {
+		return saga, err
+	}
+	if err := db.WithRetry(ctx, func() error { return o.sagaRepo.Update(saga) }); err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
+
+// AbandonCheckout compensates the saga of an abandoned order
+func (o *SagaOrchestrator) AbandonCheckout(ctx context.Context, orderID ids.OrderID) (*domain.CheckoutSaga, error) {
+	return o.stopCheckout(ctx, orderID, "order abandoned")
+}
+
+// DeclineCheckout compensates the saga of an order declined on review
+func (o *SagaOrchestrator) DeclineCheckout(ctx context.Context, orderID ids.OrderID) (*domain.CheckoutSaga, error) {
+	return o.stopCheckout(ctx, orderID, "order declined on review")
+}
+
+// stopCheckout compensates the saga of an order that will not be paid
+func (o *SagaOrchestrator) stopCheckout(ctx context.Context, orderID ids.OrderID, reason string) (*domain.CheckoutSaga, error) {
+	saga, err := o.sagaRepo.FindByOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if saga.Status != domain.SagaStatusRunning {
+		return saga, nil
+	}
+
+	if err := saga.StartCompensation(domain.SagaActorSystem, reason, o.clock.Now()); err != nil {
+		return nil, err
+	}
+	return saga, o.compensate(ctx, saga, domain.SagaActorSystem)
+}
+
+// ReleaseCheckout moves the payment deadline of the saga of an order
+// released on review to the order's new one. Sagas no longer waiting for
+// the payment are left alone.
+func (o *SagaOrchestrator) ReleaseCheckout(ctx context.Context, orderID ids.OrderID) (*domain.CheckoutSaga, error) {
+	saga, err := o.sagaRepo.FindByOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := o.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if saga.Status != domain.SagaStatusRunning || saga.Step != domain.SagaStepAwaitPayment || order.Status != domain.OrderStatusPendingPayment {
+		return saga, nil
+	}
+
+	if err := saga.Reschedule(domain.SagaStepAwaitPayment, o.deadline(domain.SagaStepAwaitPayment, order), domain.SagaActorSystem, "order released on review", o.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return o.sagaRepo.Update(saga) }); err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
+
+// ResumeCheckout runs the saga of an order the buyer came back to again. The
+// listing is reserved again when the checkout is resumed.
+func (o *SagaOrchestrator) ResumeCheckout(ctx context.Context, orderID ids.OrderID) (*domain.CheckoutSaga, error) {
+	saga, err := o.sagaRepo.FindByOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := o.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if saga.Status != domain.SagaStatusCompensated || order.Status != domain.OrderStatusPendingPayment {
+		return saga, nil
+	}
+
+	now := o.clock.Now()
+	if err := saga.Restart(domain.SagaActorSystem, "checkout resumed", now); err != nil {
+		return nil, err
+	}
+	if _, err := saga.CompleteStep(domain.SagaStepReserveListing, o.deadline(domain.SagaStepAwaitPayment, order), domain.SagaActorSystem, "checkout resumed", now); err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return o.sagaRepo.Update(saga) }); err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
+
+// TimeOutSagas compensates up to limit running sagas whose step is past its
+// deadline and returns how many were compensated
+func (o *SagaOrchestrator) TimeOutSagas(ctx context.Context, limit int) (int, error) {
+	now := o.clock.Now()
+	sagas, err := o.sagaRepo.FindTimedOut(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	compensated := 0
+	for _, saga := range sagas {
+		if err := saga.StartCompensation(domain.SagaActorSystem, "step "+string(saga.Step)+" timed out", now); err != nil {
+			continue
+		}
+		if err := o.compensate(ctx, saga, domain.SagaActorSystem); err != nil {
+			return compensated, err
+		}
+		if saga.Status == domain.SagaStatusCompensated {
+			compensated++
+		}
+	}
+	return compensated, nil
+}
+
+// RetryCompensations retries up to limit compensations that failed and
+// returns how many sagas are now compensated
+func (o *SagaOrchestrator) RetryCompensations(ctx context.Context, limit int) (int, error) {
+	sagas, err := o.sagaRepo.FindCompensating(limit)
+	if err != nil {
+		return 0, err
+	}
+
+	compensated := 0
+	for _, saga := range sagas {
+		if err := o.compensate(ctx, saga, domain.SagaActorSystem); err != nil {
+			return compensated, err
+		}
+		if saga.Status == domain.SagaStatusCompensated {
+			compensated++
+		}
+	}
+	return compensated, nil
+}
+
+// ListStuckSagas lists the sagas past their step's deadline or whose
+// compensation failed, oldest first
+func (o *SagaOrchestrator) ListStuckSagas(ctx context.Context, query StuckSagasQuery) (*CheckoutSagas, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultSagaPageSize
+	}
+
+	sagas, total, err := o.sagaRepo.FindStuck(o.clock.Now(), limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckoutSagas{
+		Sagas:  sagas,
+		Total:  total,
+		Limit:  limit,
+		Offset: query.Offset,
+	}, nil
+}
+
+// GetSaga retrieves a saga with its history
+func (o *SagaOrchestrator) GetSaga(ctx context.Context, sagaID string) (*domain.CheckoutSaga, error) {
+	return o.sagaRepo.FindByID(sagaID)
+}
+
+// AdvanceSaga marks the running step of a saga as done on an
+// administrator's word. Only the saga moves on; the order is not changed.
+func (o *SagaOrchestrator) AdvanceSaga(ctx context.Context, cmd AdvanceSagaCommand) (*domain.CheckoutSaga, error) {
+	saga, err := o.sagaRepo.FindByID(cmd.SagaID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := o.orderRepo.FindByID(saga.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := saga.CompleteStep(cmd.Step, o.deadline(o.stepAfter(cmd.Step), order), cmd.AdminID.String(), cmd.Reason, o.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, errors.ConflictError("saga is at a different step")
+	}
+	if err := db.WithRetry(ctx, func() error { return o.sagaRepo.Update(saga) }); err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
+
+// CompensateSaga undoes a running saga on an administrator's word, or
+// retries the compensation of a saga that failed to undo. A compensation
+// that fails again is reported in the saga's last_error.
+func (o *SagaOrchestrator) CompensateSaga(ctx context.Context, cmd CompensateSagaCommand) (*domain.CheckoutSaga, error) {
+	saga, err := o.sagaRepo.FindByID(cmd.SagaID)
+	if err != nil {
+		return nil, err
+	}
+	if saga.Status == domain.SagaStatusRunning {
+		if err := saga.StartCompensation(cmd.AdminID.String(), cmd.Reason, o.clock.Now()); err != nil {
+			return nil, err
+		}
+	} else if saga.Status != domain.SagaStatusCompensating {
+		return nil, errors.ConflictError("only running sagas can be compensated")
+	} else if cmd.Skip {
+		step, _ := saga.NextCompensation()
+		if err := saga.CompensateStep(step, cmd.AdminID.String(), o.clock.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := o.compensate(ctx, saga, cmd.AdminID.String()); err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
+
+// compensate undoes the saga's queued steps in order. A step that fails to
+// undo is recorded on the saga and retried later; only failing to store the
+// saga is returned.
+func (o *SagaOrchestrator) compensate(ctx context.Context, saga *domain.CheckoutSaga, actor string) error {
+	for {
+		step, ok := saga.NextCompensation()
+		if !ok {
+			break
+		}
+
+		if compensation := o.compensations[step]; compensation != nil {
+			if err := compensation(ctx, saga); err != nil {
+				saga.RecordFailure(err, o.clock.Now())
+				break
+			}
+		}
+		if err := saga.CompensateStep(step, actor, o.clock.Now()); err != nil {
+			return err
+		}
+	}
+	return db.WithRetry(ctx, func() error { return o.sagaRepo.Update(saga) })
+}
+
+// releaseListing undoes the reservation of the order's listing
+func (o *SagaOrchestrator) releaseListing(ctx context.Context, saga *domain.CheckoutSaga) error {
+	return o.listings.ReleaseListing(ctx, saga.ListingID, saga.BuyerID)
+}
+
+// cancelOrder cancels an order still awaiting payment or review and releases
+// its coupon. Orders already abandoned or cancelled are left alone; paid
+// orders must be refunded by hand.
+func (o *SagaOrchestrator) cancelOrder(ctx context.Context, saga *domain.CheckoutSaga) error {
+	order, err := o.orderRepo.FindByID(saga.OrderID)
+	if err != nil {
+		return err
+	}
+	switch order.Status {
+	case domain.OrderStatusPaid:
+		return errors.ConflictError("order was paid and must be refunded")
+	case domain.OrderStatusPendingPayment, domain.OrderStatusHeldForReview:
+		if err := order.Cancel(o.clock.Now()); err != nil {
+			return err
+		}
+		if order.CouponCode != "" && o.coupons != nil {
+			if err := o.coupons.ReleaseCoupon(ctx, order.ID); err != nil {
+				return err
+			}
+		}
+		if err := db.WithRetry(ctx, func() error { return o.orderRepo.Update(order) }); err != nil {
+			return err
+		}
+		return publishOrderCancelled(ctx, o.eventBus, order, "checkout undone")
+	}
+	return nil
+}
+
+// deadline is when a step of the order's checkout times out, or nil if it
+// does not time out
+func (o *SagaOrchestrator) deadline(step domain.SagaStep, order *domain.Order) *time.Time {
+	if step != domain.SagaStepAwaitPayment {
+		return nil
+	}
+	deadline := order.PaymentDueAt.Add(o.paymentGrace)
+	return &deadline
+}
+
+// stepAfter returns the checkout step following step
+func (o *SagaOrchestrator) stepAfter(step domain.SagaStep) domain.SagaStep {
+	for i, s := range domain.CheckoutSteps {
+		if s == step && i+1 < len(domain.CheckoutSteps) {
+			return domain.CheckoutSteps[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// placeOrder places an order for a new listing at the time on clk and starts
+// its checkout saga
+func placeOrder(t *testing.T, orders *fakeOrderRepository, reservations *fakeListingReservations, orchestrator *app.SagaOrchestrator, clk clock.Clock) (*domain.Order, *domain.CheckoutSaga) {
+	t.Helper()
+	listing := newActiveListing(t, "seller-a")
+	reservations.listings[listing.ID] = listing
+	service := app.NewOrderService(orders, reservations, &fakeNotificationPreferences{}, nil, &fakeEventBus{}, 30*time.Minute, "", nil, nil, nil, clk)
+
+	order, err := service.CreateOrder(context.Background(), app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: listing.ID})
+	require.NoError(t, err)
+	saga, err := orchestrator.StartCheckout(context.Background(), order.ID)
+	require.NoError(t, err)
+	return order, saga
+}
+
+func TestSagaOrchestrator_PaymentCompletesCheckout(t *testing.T) {
+	orders := newFakeOrderRepository()
+	reservations := newFakeListingReservations()
+	orchestrator := app.NewSagaOrchestrator(newFakeSagaRepository(), orders, reservations, nil, &fakeEventBus{}, 15*time.Minute, nil)
+	ctx := context.Background()
+
+	order, saga := placeOrder(t, orders, reservations, orchestrator, nil)
+	assert.Equal(t, domain.SagaStepAwaitPayment, saga.Step)
+	assert.Equal(t, order.PaymentDueAt.Add(15*time.Minute), *saga.DeadlineAt)
+
+	// Redelivered OrderCreated events reuse the saga
+	again, err := orchestrator.StartCheckout(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, saga.ID, again.ID)
+
+	saga, err = orchestrator.CompletePayment(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SagaStatusCompleted, saga.Status)
+}
+
+func TestSagaOrchestrator_AbandonAndResume(t *testing.T) {
+	orders := newFakeOrderRepository()
+	reservations := newFakeListingReservations()
+	orchestrator := app.NewSagaOrchestrator(newFakeSagaRepository(), orders, reservations, nil, &fakeEventBus{}, 15*time.Minute, nil)
+	ctx := context.Background()
+
+	order, _ := placeOrder(t, orders, reservations, orchestrator, nil)
+	listing := reservations.listings[order.ListingID]
+	expireOrder(order)
+	require.NoError(t, order.Abandon(time.Now()))
+
+	saga, err := orchestrator.AbandonCheckout(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SagaStatusCompensated, saga.Status)
+	assert.False(t, listing.IsReserved())
+	assert.Equal(t, domain.OrderStatusAbandoned, order.Status)
+
+	// The buyer comes back and the saga runs again
+	require.NoError(t, order.Resume(time.Now().Add(30*time.Minute), time.Now()))
+	saga, err = orchestrator.ResumeCheckout(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SagaStatusRunning, saga.Status)
+	assert.Equal(t, domain.SagaStepAwaitPayment, saga.Step)
+}
+
+func TestSagaOrchestrator_TimeOutCancelsOrder(t *testing.T) {
+	orders := newFakeOrderRepository()
+	reservations := newFakeListingReservations()
+	eventBus := &fakeEventBus{}
+	clk := clock.NewFrozen(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	orchestrator := app.NewSagaOrchestrator(newFakeSagaRepository(), orders, reservations, nil, eventBus, 15*time.Minute, clk)
+	ctx := context.Background()
+
+	order, saga := placeOrder(t, orders, reservations, orchestrator, clk)
+	listing := reservations.listings[order.ListingID]
+
+	// The checkout waits for the payment until the grace after its deadline
+	clk.Advance(45 * time.Minute)
+	count, err := orchestrator.TimeOutSagas(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Equal(t, domain.SagaStatusRunning, saga.Status)
+
+	clk.Advance(time.Second)
+	stuck, err := orchestrator.ListStuckSagas(ctx, app.StuckSagasQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stuck.Total)
+
+	count, err = orchestrator.TimeOutSagas(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, domain.SagaStatusCompensated, saga.Status)
+	assert.Equal(t, domain.OrderStatusCancelled, order.Status)
+	assert.False(t, listing.IsReserved())
+	require.Len(t, eventBus.eventsOfType(domain.OrderCancelledEvent), 1)
+
+	// Undoing the checkout again cancels nothing more
+	_, err = orchestrator.AbandonCheckout(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Len(t, eventBus.eventsOfType(domain.OrderCancelledEvent), 1)
+}
+
+func TestSagaOrchestrator_AdminRepairs(t *testing.T) {
+	orders := newFakeOrderRepository()
+	reservations := newFakeListingReservations()
+	orchestrator := app.NewSagaOrchestrator(newFakeSagaRepository(), orders, reservations, nil, &fakeEventBus{}, 15*time.Minute, nil)
+	ctx := context.Background()
+
+	// Advancing needs the running step
+	order, saga := placeOrder(t, orders, reservations, orchestrator, nil)
+	_, err := orchestrator.AdvanceSaga(ctx, app.AdvanceSagaCommand{SagaID: saga.ID, AdminID: "admin-1", Step: domain.SagaStepReserveListing, Reason: "retry"})
+	assert.Error(t, err)
+	advanced, err := orchestrator.AdvanceSaga(ctx, app.AdvanceSagaCommand{SagaID: saga.ID, AdminID: "admin-1", Step: domain.SagaStepAwaitPayment, Reason: "paid in cash"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.SagaStatusCompleted, advanced.Status)
+	assert.Equal(t, "admin-1", advanced.History[len(advanced.History)-1].Actor)
+	assert.Equal(t, domain.OrderStatusPendingPayment, order.Status)
+
+	// A paid order cannot be cancelled, so its compensation fails until an
+	// administrator refunds it by hand and skips the step
+	order, saga = placeOrder(t, orders, reservations, orchestrator, nil)
+	require.NoError(t, order.MarkPaid(time.Now()))
+	compensating, err := orchestrator.CompensateSaga(ctx, app.CompensateSagaCommand{SagaID: saga.ID, AdminID: "admin-1", Reason: "fraud"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.SagaStatusCompensating, compensating.Status)
+	assert.NotEmpty(t, compensating.LastError)
+	assert.True(t, compensating.IsStuck(time.Now()))
+
+	compensated, err := orchestrator.CompensateSaga(ctx, app.CompensateSagaCommand{SagaID: saga.ID, AdminID: "admin-1", Reason: "refunded by hand", Skip: true})
+	require.NoError(t, err)
+	assert.Equal(t, domain.SagaStatusCompensated, compensated.Status)
+	assert.False(t, reservations.listings[order.ListingID].IsReserved())
+}
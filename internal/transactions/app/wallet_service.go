@@ -0,0 +1,364 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// walletTransferCheckDelay is how long after it was started a wallet's
+// transfer is first looked up
+const walletTransferCheckDelay = 30 * time.Second
+
+// defaultWalletEntryPageSize is how many wallet entries are listed when no
+// limit is given
+const defaultWalletEntryPageSize = 20
+
+// WalletCheckout completes orders paid from a wallet. It is implemented by
+// OrderService.
+type WalletCheckout interface {
+	CompleteWalletPayment(ctx context.Context, orderID ids.OrderID, entryID string) (*domain.Order, error)
+}
+
+// WalletTransferCommand represents a user topping up their wallet from, or
+// withdrawing from it to, the Mobile Money wallet of the given phone number
+type WalletTransferCommand struct {
+	UserID ids.UserID `json:"-"`
+	Amount float64    `json:"amount" binding:"required,gt=0"`
+	Phone  string     `json:"phone" binding:"required,e164"`
+}
+
+// PayOrderFromWalletCommand represents a buyer paying for an order from their wallet
+type PayOrderFromWalletCommand struct {
+	OrderID ids.OrderID
+	BuyerID ids.UserID
+}
+
+// WalletEntriesQuery represents the query to list a wallet's entries
+type WalletEntriesQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// WalletEntries represents a page of a wallet's entries
+type WalletEntries struct {
+	Entries []*domain.WalletEntry `json:"entries"`
+	Total   int64                 `json:"total"`
+	Limit   int                   `json:"limit"`
+	Offset  int                   `json:"offset"`
+}
+
+// WalletService keeps users' wallets: topping them up and withdrawing from
+// them by Mobile Money, paying for orders from them and crediting refunds
+// back to them
+type WalletService struct {
+	walletRepo  domain.WalletRepository
+	orderRepo   domain.OrderRepository
+	checkout    WalletCheckout
+	payments    PaymentGateway
+	withdrawals PayoutGateway
+	clock       clock.Clock
+}
+
+// NewWalletService creates a new wallet service. payments collects top-ups
+// and withdrawals sends money out; either may be nil where Mobile Money is
+// not set up for it. clk may be nil to use the system clock.
+func NewWalletService(walletRepo domain.WalletRepository, orderRepo domain.OrderRepository, checkout WalletCheckout, payments PaymentGateway, withdrawals PayoutGateway, clk clock.Clock) *WalletService {
+	return &WalletService{
+		walletRepo:  walletRepo,
+		orderRepo:   orderRepo,
+		checkout:    checkout,
+		payments:    payments,
+		withdrawals: withdrawals,
+		clock:       clock.OrSystem(clk),
+	}
+}
+
+// GetWallet returns a user's wallet, opening an empty one if they have none
+func (s *WalletService) GetWallet(ctx context.Context, userID ids.UserID) (*domain.Wallet, error) {
+	var wallet *domain.Wallet
+	err := db.WithRetry(ctx, func() error {
+		var err error
+		wallet, err = s.walletRepo.Open(domain.NewWallet(userID, s.clock.Now()))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// ListEntries lists the entries of a user's wallet, newest first
+func (s *WalletService) ListEntries(ctx context.Context, userID ids.UserID, query WalletEntriesQuery) (*WalletEntries, error) {
+	wallet, err := s.GetWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultWalletEntryPageSize
+	}
+
+	entries, total, err := s.walletRepo.FindEntries(wallet.ID, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &WalletEntries{
+		Entries: entries,
+		Total:   total,
+		Limit:   limit,
+		Offset:  query.Offset,
+	}, nil
+}
+
+// TopUp asks the user to approve paying the amount into their wallet on
+// their phone. The wallet is credited once Mobile Money reports the payment
+// done.
+func (s *WalletService) TopUp(ctx context.Context, cmd WalletTransferCommand) (*domain.WalletTransfer, error) {
+	if s.payments == nil {
+		return nil, errors.UnavailableError("mobile money payments are temporarily unavailable")
+	}
+	// Amounts are read exactly, so the amount moved is the amount asked for
+	amount, err := money.ParseMajor(strconv.FormatFloat(cmd.Amount, 'f', -1, 64), money.DefaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := domain.NewWalletTransfer(cmd.UserID, domain.WalletTransferTopUp, cmd.Phone, amount, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.walletRepo.SaveTransfer(transfer) }); err != nil {
+		return nil, err
+	}
+
+	reference, err := s.payments.RequestPayment(ctx, PaymentRequest{
+		Reference:   transfer.ID,
+		Amount:      transfer.Amount,
+		Payer:       transfer.Phone,
+		Description: "Dongome wallet top-up",
+	})
+	if err != nil {
+		transfer.Fail("the payment could not be requested", s.clock.Now())
+		if updateErr := db.WithRetry(ctx, func() error { return s.walletRepo.UpdateTransfer(transfer) }); updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, err
+	}
+	transfer.Started(reference, s.clock.Now())
+	if err := db.WithRetry(ctx, func() error { return s.walletRepo.UpdateTransfer(transfer) }); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// Withdraw takes the amount from the user's wallet and sends it to their
+// Mobile Money wallet. The amount is credited back if the transfer fails.
+func (s *WalletService) Withdraw(ctx context.Context, cmd WalletTransferCommand) (*domain.WalletTransfer, error) {
+	if s.withdrawals == nil {
+		return nil, errors.UnavailableError("mobile money payouts are temporarily unavailable")
+	}
+	amount, err := money.ParseMajor(strconv.FormatFloat(cmd.Amount, 'f', -1, 64), money.DefaultCurrency)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := domain.NewWalletTransfer(cmd.UserID, domain.WalletTransferWithdrawal, cmd.Phone, amount, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.walletRepo.SaveTransfer(transfer) }); err != nil {
+		return nil, err
+	}
+
+	// The amount is taken before it is sent, so it cannot be spent twice
+	if _, err := s.post(ctx, cmd.UserID, func(wallet *domain.Wallet) (*domain.WalletEntry, error) {
+		return wallet.Debit(domain.WalletEntryWithdrawal, transfer.Amount, transfer.ID, s.clock.Now())
+	}); err != nil {
+		transfer.Fail("the wallet could not be debited", s.clock.Now())
+		if updateErr := db.WithRetry(ctx, func() error { return s.walletRepo.UpdateTransfer(transfer) }); updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, err
+	}
+
+	reference, err := s.withdrawals.SendPayout(ctx, PaymentRequest{
+		Reference:   transfer.ID,
+		Amount:      transfer.Amount,
+		Payer:       transfer.Phone,
+		Description: "Dongome wallet withdrawal",
+	})
+	if err != nil {
+		// The transfer never started, so the amount goes back to the wallet
+		if reverseErr := s.failWithdrawal(ctx, transfer, "the transfer could not be started"); reverseErr != nil {
+			return nil, reverseErr
+		}
+		return nil, err
+	}
+	transfer.Started(reference, s.clock.Now())
+	if err := db.WithRetry(ctx, func() error { return s.walletRepo.UpdateTransfer(transfer) }); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// GetTransfer returns one of a user's top-ups or withdrawals
+func (s *WalletService) GetTransfer(ctx context.Context, transferID string, userID ids.UserID) (*domain.WalletTransfer, error) {
+	transfer, err := s.walletRepo.FindTransfer(transferID)
+	if err != nil {
+		return nil, err
+	}
+	// Other users are told the transfer does not exist rather than that it is not theirs
+	if transfer.UserID != userID {
+		return nil, errors.NotFoundError("wallet transfer not found")
+	}
+	return transfer, nil
+}
+
+// PayOrder pays for one of the buyer's orders from their wallet. An order
+// is paid from a wallet at most once: if the order cannot be completed once
+// the wallet is debited, the amount goes back to the wallet and the order
+// is left to be paid by Mobile Money.
+func (s *WalletService) PayOrder(ctx context.Context, cmd PayOrderFromWalletCommand) (*domain.Order, error) {
+	order, err := s.orderRepo.FindByID(cmd.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.BuyerID != cmd.BuyerID {
+		return nil, errors.NotFoundError("order not found")
+	}
+	if err := order.CanRequestPayment(s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	purchase, err := s.post(ctx, cmd.BuyerID, func(wallet *domain.Wallet) (*domain.WalletEntry, error) {
+		return wallet.Debit(domain.WalletEntryPurchase, order.Amount, order.ID.String(), s.clock.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paid, err := s.checkout.CompleteWalletPayment(ctx, order.ID, purchase.ID)
+	if err != nil {
+		if _, reverseErr := s.credit(ctx, cmd.BuyerID, domain.WalletEntryPurchaseReversal, order.Amount, order.ID.String()); reverseErr != nil {
+			return nil, reverseErr
+		}
+		return nil, err
+	}
+	return paid, nil
+}
+
+// CreditRefund credits a refund of an order paid from the buyer's wallet
+// back to it. A refund is credited once, however often it is asked for.
+func (s *WalletService) CreditRefund(ctx context.Context, buyerID ids.UserID, refundID string, amount money.Money) error {
+	_, err := s.credit(ctx, buyerID, domain.WalletEntryRefund, amount, refundID)
+	return err
+}
+
+// CheckPendingTransfers looks up the Mobile Money transfers of up to limit
+// pending top-ups and withdrawals. Top-ups that were paid are credited to
+// the wallet, and withdrawals that failed are credited back. It returns how
+// many transfers were settled either way.
+func (s *WalletService) CheckPendingTransfers(ctx context.Context, limit int) (int, error) {
+	transfers, err := s.walletRepo.FindPendingTransfers(s.clock.Now().Add(-walletTransferCheckDelay), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, transfer := range transfers {
+		var result *PaymentResult
+		switch {
+		case transfer.Kind == domain.WalletTransferTopUp && s.payments != nil:
+			result, err = s.payments.PaymentResult(ctx, transfer.Reference)
+		case transfer.Kind == domain.WalletTransferWithdrawal && s.withdrawals != nil:
+			result, err = s.withdrawals.PayoutResult(ctx, transfer.Reference)
+		default:
+			continue
+		}
+		if err != nil {
+			return settled, err
+		}
+
+		switch paymentOutcome(result.Status) {
+		case paymentSuccessful:
+			if transfer.Kind == domain.WalletTransferTopUp {
+				if !result.Amount.Equal(transfer.Amount) {
+					continue
+				}
+				if _, err := s.credit(ctx, transfer.UserID, domain.WalletEntryTopUp, transfer.Amount, transfer.ID); err != nil {
+					return settled, err
+				}
+			}
+			if !transfer.Succeed(result.TransactionID, s.clock.Now()) {
+				continue
+			}
+			if err := db.WithRetry(ctx, func() error { return s.walletRepo.UpdateTransfer(transfer) }); err != nil {
+				return settled, err
+			}
+		case paymentFailed:
+			if transfer.Kind == domain.WalletTransferWithdrawal {
+				if err := s.failWithdrawal(ctx, transfer, result.Reason); err != nil {
+					return settled, err
+				}
+				break
+			}
+			if !transfer.Fail(result.Reason, s.clock.Now()) {
+				continue
+			}
+			if err := db.WithRetry(ctx, func() error { return s.walletRepo.UpdateTransfer(transfer) }); err != nil {
+				return settled, err
+			}
+		default:
+			continue
+		}
+		settled++
+	}
+	return settled, nil
+}
+
+// failWithdrawal credits a failed withdrawal back to the wallet and records
+// why it failed
+func (s *WalletService) failWithdrawal(ctx context.Context, transfer *domain.WalletTransfer, reason string) error {
+	if _, err := s.credit(ctx, transfer.UserID, domain.WalletEntryWithdrawalReversal, transfer.Amount, transfer.ID); err != nil {
+		return err
+	}
+	transfer.Fail(reason, s.clock.Now())
+	return db.WithRetry(ctx, func() error { return s.walletRepo.UpdateTransfer(transfer) })
+}
+
+// credit adds the amount to a user's wallet for the reference. A credit
+// already posted for the reference is not posted again, and nil is returned
+// for it.
+func (s *WalletService) credit(ctx context.Context, userID ids.UserID, kind domain.WalletEntryKind, amount money.Money, reference string) (*domain.WalletEntry, error) {
+	entry, err := s.post(ctx, userID, func(wallet *domain.Wallet) (*domain.WalletEntry, error) {
+		return wallet.Credit(kind, amount, reference, s.clock.Now())
+	})
+	if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeConflict {
+		return nil, nil
+	}
+	return entry, err
+}
+
+// post changes the balance of a user's wallet, opening the wallet if the
+// user has none yet
+func (s *WalletService) post(ctx context.Context, userID ids.UserID, change func(wallet *domain.Wallet) (*domain.WalletEntry, error)) (*domain.WalletEntry, error) {
+	var entry *domain.WalletEntry
+	err := db.WithRetry(ctx, func() error {
+		if _, err := s.walletRepo.Open(domain.NewWallet(userID, s.clock.Now())); err != nil {
+			return err
+		}
+		var err error
+		entry, err = s.walletRepo.Post(userID, change)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,319 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+	"dongome/pkg/payments"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// walletFixture keeps wallets topped up and withdrawn by Mobile Money, and
+// pays orders of listings priced at 100 cedis from them
+type walletFixture struct {
+	listing  *listings.Listing
+	wallets  *fakeWalletRepository
+	orders   *fakeOrderRepository
+	payments *fakePaymentGateway
+	provider *fakePaymentProvider
+	gateway  *fakePayoutGateway
+	eventBus *fakeEventBus
+	clock    *clock.Frozen
+	checkout *app.OrderService
+	refunds  *app.RefundService
+	service  *app.WalletService
+}
+
+func newWalletFixture(t *testing.T) *walletFixture {
+	f := &walletFixture{
+		listing:  newActiveListing(t, "seller-a"),
+		wallets:  newFakeWalletRepository(),
+		orders:   newFakeOrderRepository(),
+		payments: &fakePaymentGateway{results: make(map[string]*app.PaymentResult)},
+		provider: newFakePaymentProvider(payments.ProviderMoMo),
+		gateway:  &fakePayoutGateway{results: make(map[string]*app.PaymentResult)},
+		eventBus: &fakeEventBus{},
+		clock:    clock.NewFrozen(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)),
+	}
+	f.checkout = app.NewOrderService(f.orders, newFakeListingReservations(f.listing), &fakeNotificationPreferences{}, f.provider.registry(payments.MethodMoMo), f.eventBus, 30*time.Minute, "", nil, nil, nil, f.clock)
+	f.service = app.NewWalletService(f.wallets, f.orders, f.checkout, f.payments, f.gateway, f.clock)
+	f.refunds = app.NewRefundService(&fakeRefundRepository{}, f.orders, newFakeEscrowRepository(), f.provider.registry(), f.service, &fakeNotificationPreferences{}, f.eventBus, f.clock)
+	return f
+}
+
+// topUp credits the amount to the user's wallet through a top-up Mobile
+// Money reports paid
+func (f *walletFixture) topUp(t *testing.T, userID ids.UserID, amount float64) {
+	t.Helper()
+	ctx := context.Background()
+	transfer, err := f.service.TopUp(ctx, app.WalletTransferCommand{UserID: userID, Amount: amount, Phone: "+233241234567"})
+	require.NoError(t, err)
+	f.payments.results[transfer.Reference] = &app.PaymentResult{Status: "SUCCESSFUL", Amount: money.Cedis(amount), TransactionID: "tx-" + transfer.ID}
+	f.clock.Advance(time.Minute)
+	_, err = f.service.CheckPendingTransfers(ctx, 10)
+	require.NoError(t, err)
+}
+
+func TestWalletService_TopUpIsCreditedOnce(t *testing.T) {
+	f := newWalletFixture(t)
+	ctx := context.Background()
+
+	transfer, err := f.service.TopUp(ctx, app.WalletTransferCommand{UserID: "buyer-a", Amount: 50, Phone: "+233241234567"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.WalletTransferStatusPending, transfer.Status)
+	require.Len(t, f.payments.requests, 1)
+	assert.Equal(t, "233241234567", f.payments.requests[0].Payer)
+	assert.Equal(t, transfer.ID, f.payments.requests[0].Reference)
+
+	// Nothing is credited until Mobile Money reports the payment done
+	f.clock.Advance(time.Minute)
+	count, err := f.service.CheckPendingTransfers(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	wallet, err := f.service.GetWallet(ctx, "buyer-a")
+	require.NoError(t, err)
+	assert.True(t, wallet.Balance.IsZero())
+
+	f.payments.results[transfer.Reference] = &app.PaymentResult{Status: "SUCCESSFUL", Amount: money.Cedis(50), TransactionID: "tx-1"}
+	count, err = f.service.CheckPendingTransfers(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = f.service.CheckPendingTransfers(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	wallet, err = f.service.GetWallet(ctx, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(50), wallet.Balance)
+	transfer, err = f.service.GetTransfer(ctx, transfer.ID, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.WalletTransferStatusSucceeded, transfer.Status)
+	assert.Equal(t, "tx-1", transfer.TransactionID)
+
+	_, err = f.service.GetTransfer(ctx, transfer.ID, "buyer-b")
+	assert.Error(t, err)
+
+	// Failed top-ups credit nothing
+	failed, err := f.service.TopUp(ctx, app.WalletTransferCommand{UserID: "buyer-a", Amount: 20, Phone: "+233241234567"})
+	require.NoError(t, err)
+	f.payments.results[failed.Reference] = &app.PaymentResult{Status: "REJECTED"}
+	f.clock.Advance(time.Minute)
+	count, err = f.service.CheckPendingTransfers(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	entries, err := f.service.ListEntries(ctx, "buyer-a", app.WalletEntriesQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 1)
+	assert.Equal(t, domain.WalletEntryTopUp, entries.Entries[0].Kind)
+	assert.Equal(t, money.Cedis(50), entries.Entries[0].BalanceAfter)
+}
+
+func TestWalletService_PayOrder(t *testing.T) {
+	f := newWalletFixture(t)
+	ctx := context.Background()
+	order, err := f.checkout.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: f.listing.ID})
+	require.NoError(t, err)
+
+	_, err = f.service.PayOrder(ctx, app.PayOrderFromWalletCommand{OrderID: order.ID, BuyerID: "buyer-a"})
+	var domainErr *errors.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "insufficient wallet balance", domainErr.Message)
+
+	f.topUp(t, "buyer-a", 150)
+	_, err = f.service.PayOrder(ctx, app.PayOrderFromWalletCommand{OrderID: order.ID, BuyerID: "buyer-b"})
+	assert.Error(t, err, "only the buyer can pay for the order")
+
+	paid, err := f.service.PayOrder(ctx, app.PayOrderFromWalletCommand{OrderID: order.ID, BuyerID: "buyer-a"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPaid, paid.Status)
+	assert.Equal(t, domain.PaymentMethodWallet, paid.PaymentMethod)
+	assert.Len(t, f.eventBus.eventsOfType(domain.PaymentSucceededEvent), 1)
+	assert.Len(t, f.eventBus.eventsOfType(domain.OrderPaidEvent), 1)
+
+	_, err = f.service.PayOrder(ctx, app.PayOrderFromWalletCommand{OrderID: order.ID, BuyerID: "buyer-a"})
+	assert.Error(t, err, "orders are paid once")
+	wallet, err := f.service.GetWallet(ctx, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(50), wallet.Balance)
+
+	// Refunds of orders paid from the wallet go straight back to it
+	refund, err := f.refunds.RefundOrder(ctx, app.RefundOrderCommand{OrderID: order.ID, SellerID: "seller-a", IdempotencyKey: "key-1", Amount: 30, Reason: "scratched"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RefundStatusSucceeded, refund.Status)
+	assert.Empty(t, f.provider.refunds)
+	assert.Len(t, f.eventBus.eventsOfType(domain.PaymentRefundedEvent), 1)
+	wallet, err = f.service.GetWallet(ctx, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(80), wallet.Balance)
+}
+
+func TestWalletService_PurchaseIsReversedWhenOrderCannotBePaid(t *testing.T) {
+	f := newWalletFixture(t)
+	ctx := context.Background()
+	order, err := f.checkout.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: f.listing.ID})
+	require.NoError(t, err)
+	f.topUp(t, "buyer-a", 100)
+
+	failing := app.NewWalletService(f.wallets, f.orders, failingCheckout{}, f.payments, f.gateway, f.clock)
+	_, err = failing.PayOrder(ctx, app.PayOrderFromWalletCommand{OrderID: order.ID, BuyerID: "buyer-a"})
+	assert.Error(t, err)
+	wallet, err := failing.GetWallet(ctx, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(100), wallet.Balance)
+	entries, err := failing.ListEntries(ctx, "buyer-a", app.WalletEntriesQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 3)
+	assert.Equal(t, domain.WalletEntryPurchaseReversal, entries.Entries[0].Kind)
+	assert.Equal(t, domain.WalletEntryPurchase, entries.Entries[1].Kind)
+	assert.Equal(t, money.Cedis(-100), entries.Entries[1].Amount)
+}
+
+func TestWalletService_Withdraw(t *testing.T) {
+	f := newWalletFixture(t)
+	ctx := context.Background()
+	f.topUp(t, "buyer-a", 100)
+
+	_, err := f.service.Withdraw(ctx, app.WalletTransferCommand{UserID: "buyer-a", Amount: 120, Phone: "+233241234567"})
+	assert.Error(t, err, "wallets cannot be overdrawn")
+	assert.Empty(t, f.gateway.payouts)
+
+	// Amounts are not rounded, so fractions of a pesewa are refused
+	for _, amount := range []float64{0.004, 10.005} {
+		_, err = f.service.Withdraw(ctx, app.WalletTransferCommand{UserID: "buyer-a", Amount: amount, Phone: "+233241234567"})
+		assert.Error(t, err)
+		_, err = f.service.TopUp(ctx, app.WalletTransferCommand{UserID: "buyer-a", Amount: amount, Phone: "+233241234567"})
+		assert.Error(t, err)
+	}
+	assert.Empty(t, f.gateway.payouts)
+	assert.Len(t, f.payments.requests, 1)
+
+	// A transfer that never started goes back to the wallet
+	f.gateway.sendErr = errors.UnavailableError("mobile money payouts are temporarily unavailable")
+	_, err = f.service.Withdraw(ctx, app.WalletTransferCommand{UserID: "buyer-a", Amount: 40, Phone: "+233241234567"})
+	assert.Error(t, err)
+	f.gateway.sendErr = nil
+
+	transfer, err := f.service.Withdraw(ctx, app.WalletTransferCommand{UserID: "buyer-a", Amount: 40, Phone: "+233241234567"})
+	require.NoError(t, err)
+	require.Len(t, f.gateway.payouts, 1)
+	assert.Equal(t, money.Cedis(40), f.gateway.payouts[0].Amount)
+	wallet, err := f.service.GetWallet(ctx, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(60), wallet.Balance, "withdrawals are taken when they are made")
+
+	// So is a transfer Mobile Money reports failed
+	f.gateway.results[transfer.Reference] = &app.PaymentResult{Status: "FAILED", Reason: "PAYEE_NOT_FOUND"}
+	f.clock.Advance(time.Minute)
+	count, err := f.service.CheckPendingTransfers(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	wallet, err = f.service.GetWallet(ctx, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(100), wallet.Balance)
+	transfer, err = f.service.GetTransfer(ctx, transfer.ID, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.WalletTransferStatusFailed, transfer.Status)
+	assert.Equal(t, "PAYEE_NOT_FOUND", transfer.FailureReason)
+}
+
+func TestWalletService_ConcurrentCredits(t *testing.T) {
+	f := newWalletFixture(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		refundID := fmt.Sprintf("refund-%d", i)
+		// Each refund is asked for twice, and credited once
+		for j := 0; j < 2; j++ {
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, f.service.CreditRefund(ctx, "buyer-a", refundID, money.Cedis(1)))
+			}()
+		}
+	}
+	wg.Wait()
+
+	wallet, err := f.service.GetWallet(ctx, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(50), wallet.Balance)
+	entries, err := f.service.ListEntries(ctx, "buyer-a", app.WalletEntriesQuery{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 50)
+	seen := make(map[int64]bool)
+	for _, entry := range entries.Entries {
+		assert.False(t, seen[entry.BalanceAfter.Amount], "each credit builds on the one before")
+		seen[entry.BalanceAfter.Amount] = true
+	}
+}
+
+func TestWalletService_PurchaseIsReversedWhenAMoMoPaymentWinsTheRace(t *testing.T) {
+	f := newWalletFixture(t)
+	ctx := context.Background()
+	order, err := f.checkout.CreateOrder(ctx, app.CreateOrderCommand{BuyerID: "buyer-a", ListingID: f.listing.ID})
+	require.NoError(t, err)
+	f.topUp(t, "buyer-a", 100)
+
+	// The buyer asks for a MoMo payment while the wallet payment is under way
+	orders := &racingOrderRepository{fakeOrderRepository: f.orders}
+	checkout := app.NewOrderService(orders, newFakeListingReservations(f.listing), &fakeNotificationPreferences{}, f.provider.registry(payments.MethodMoMo), f.eventBus, 30*time.Minute, "", nil, nil, nil, f.clock)
+	service := app.NewWalletService(f.wallets, orders, checkout, f.payments, f.gateway, f.clock)
+	orders.race = func() {
+		_, err := checkout.PayOrder(ctx, app.PayOrderCommand{OrderID: order.ID, BuyerID: "buyer-a", Phone: "+233241234567"})
+		require.NoError(t, err)
+	}
+
+	_, err = service.PayOrder(ctx, app.PayOrderFromWalletCommand{OrderID: order.ID, BuyerID: "buyer-a"})
+	assert.Error(t, err)
+	wallet, err := service.GetWallet(ctx, "buyer-a")
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(100), wallet.Balance)
+	stored, err := f.orders.FindByID(order.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.AwaitsPaymentApproval())
+	assert.Equal(t, domain.PaymentMethodMoMo, stored.PaymentMethod)
+}
+
+// racingOrderRepository hands out copies of orders, as a database does, and
+// runs race before the first update, as a concurrent request would
+type racingOrderRepository struct {
+	*fakeOrderRepository
+	race func()
+}
+
+func (r *racingOrderRepository) FindByID(id ids.OrderID) (*domain.Order, error) {
+	order, err := r.fakeOrderRepository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (r *racingOrderRepository) Update(order *domain.Order) error {
+	if race := r.race; race != nil {
+		r.race = nil
+		race()
+	}
+	return r.fakeOrderRepository.Update(order)
+}
+
+// failingCheckout never completes orders
+type failingCheckout struct{}
+
+func (failingCheckout) CompleteWalletPayment(ctx context.Context, orderID ids.OrderID, entryID string) (*domain.Order, error) {
+	return nil, errors.ConflictError("order payment has expired")
+}
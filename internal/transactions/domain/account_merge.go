@@ -0,0 +1,14 @@
+package domain
+
+import "dongome/pkg/ids"
+
+// AccountMergeRepository moves the transactions of a duplicate account to the
+// account it was merged into
+type AccountMergeRepository interface {
+	// ReassignUser moves a user's orders, checkout sagas, escrows, refunds,
+	// claims, fraud checks, coupon redemptions, coupons, payouts and wallet
+	// transfers to another user in one transaction, with the payout account
+	// unless the other user has one. It returns how many orders moved;
+	// running it again moves only what is left.
+	ReassignUser(fromUserID, toUserID ids.UserID) (int64, error)
+}
@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// ClaimStatus represents how far a claim against an order has got
+type ClaimStatus string
+
+const (
+	// ClaimStatusOpen claims await the seller's evidence and a decision
+	ClaimStatusOpen ClaimStatus = "open"
+	// ClaimStatusWon claims were decided for the seller, who is paid as usual
+	ClaimStatusWon ClaimStatus = "won"
+	// ClaimStatusLost claims were decided for the buyer, who got the amount
+	// back from the payment provider
+	ClaimStatusLost ClaimStatus = "lost"
+)
+
+// ClaimActorProvider is the actor of claims the payment provider reported
+// or decided
+const ClaimActorProvider = "provider"
+
+// MaxClaimEvidence is how many times a seller can submit evidence for a claim
+const MaxClaimEvidence = 10
+
+// ClaimEvidence is what a seller submitted to contest a claim
+type ClaimEvidence struct {
+	Description string `json:"description"`
+	// Attachments link to photos or documents, such as a delivery receipt
+	Attachments []string  `json:"attachments,omitempty"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// Claim is a buyer disputing an order's payment, as a chargeback or reversal
+// the payment provider reported or one an administrator opened. The funds of
+// the order are frozen in escrow while any of its claims is open.
+type Claim struct {
+	ID       string      `gorm:"type:uuid;primary_key" json:"id"`
+	OrderID  ids.OrderID `gorm:"type:uuid;not null;index" json:"order_id"`
+	BuyerID  ids.UserID  `gorm:"type:uuid;not null" json:"buyer_id"`
+	SellerID ids.UserID  `gorm:"type:uuid;not null;index" json:"seller_id"`
+	// Provider is the payment provider that reported the claim, empty for
+	// claims an administrator opened
+	Provider string `gorm:"size:20" json:"provider,omitempty"`
+	// Reference is the provider's ID of the dispute
+	Reference string      `gorm:"size:64" json:"reference,omitempty"`
+	Amount    money.Money `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	Reason    string      `gorm:"type:text;not null" json:"reason"`
+	Status    ClaimStatus `gorm:"size:20;not null;index" json:"status"`
+	// OpenedBy is the administrator who opened the claim, or ClaimActorProvider
+	OpenedBy string `gorm:"size:50;not null" json:"opened_by"`
+	// EvidenceDueAt is when the seller's evidence is due, if there is a deadline
+	EvidenceDueAt *time.Time      `json:"evidence_due_at,omitempty"`
+	Evidence      []ClaimEvidence `gorm:"type:jsonb;serializer:json" json:"evidence"`
+	// ResolvedBy is the administrator who decided the claim, or ClaimActorProvider
+	ResolvedBy string     `gorm:"size:50" json:"resolved_by,omitempty"`
+	Resolution string     `gorm:"size:500" json:"resolution,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// NewClaim opens a claim for amount of a paid order's payment. A zero amount
+// claims the whole payment.
+func NewClaim(order *Order, provider, reference string, amount money.Money, reason, openedBy string, evidenceDueAt *time.Time, now time.Time) (*Claim, error) {
+	if order.PaidAt == nil {
+		return nil, errors.ConflictError("only paid orders can be claimed against")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.ValidationError("claim reason is required")
+	}
+	if amount.IsZero() {
+		amount = order.Amount
+	}
+	if !amount.SameCurrency(order.Amount) {
+		return nil, errors.ValidationError("claims must be in the currency of the order")
+	}
+	if !amount.IsPositive() || amount.Cmp(order.Amount) > 0 {
+		return nil, errors.ValidationError("the claim amount must be positive and at most the order amount")
+	}
+
+	return &Claim{
+		ID:            uuid.New().String(),
+		OrderID:       order.ID,
+		BuyerID:       order.BuyerID,
+		SellerID:      order.SellerID,
+		Provider:      provider,
+		Reference:     reference,
+		Amount:        amount,
+		Reason:        reason,
+		Status:        ClaimStatusOpen,
+		OpenedBy:      openedBy,
+		EvidenceDueAt: evidenceDueAt,
+		Evidence:      []ClaimEvidence{},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// IsOpen checks if the claim awaits a decision
+func (c *Claim) IsOpen() bool {
+	return c.Status == ClaimStatusOpen
+}
+
+// IsParty checks if the user is the buyer or the seller of the order
+func (c *Claim) IsParty(userID ids.UserID) bool {
+	return userID == c.BuyerID || userID == c.SellerID
+}
+
+// ExtendEvidenceDue moves when the seller's evidence is due, as when the
+// provider reminds of its deadline
+func (c *Claim) ExtendEvidenceDue(dueAt time.Time, now time.Time) {
+	c.EvidenceDueAt = &dueAt
+	c.UpdatedAt = now
+}
+
+// SubmitEvidence records the seller contesting an open claim
+func (c *Claim) SubmitEvidence(sellerID ids.UserID, description string, attachments []string, now time.Time) error {
+	if sellerID != c.SellerID {
+		return errors.ForbiddenError("only the seller can submit evidence")
+	}
+	if !c.IsOpen() {
+		return errors.ConflictError("the claim is resolved already")
+	}
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return errors.ValidationError("evidence description is required")
+	}
+	if len(c.Evidence) >= MaxClaimEvidence {
+		return errors.ConflictError("no more evidence can be submitted for the claim")
+	}
+
+	c.Evidence = append(c.Evidence, ClaimEvidence{
+		Description: description,
+		Attachments: attachments,
+		SubmittedAt: now,
+	})
+	c.UpdatedAt = now
+	return nil
+}
+
+// Resolve records the decision on an open claim
+func (c *Claim) Resolve(status ClaimStatus, resolvedBy, resolution string, now time.Time) error {
+	if status != ClaimStatusWon && status != ClaimStatusLost {
+		return errors.ValidationError("claims are either won or lost")
+	}
+	if !c.IsOpen() {
+		return errors.ConflictError("the claim is resolved already")
+	}
+	c.Status = status
+	c.ResolvedBy = resolvedBy
+	c.Resolution = strings.TrimSpace(resolution)
+	c.ResolvedAt = &now
+	c.UpdatedAt = now
+	return nil
+}
+
+// ClaimFilter selects claims to list. Zero fields match every claim.
+type ClaimFilter struct {
+	Status   ClaimStatus
+	SellerID ids.UserID
+}
+
+// ClaimRepository defines the interface for claim persistence
+type ClaimRepository interface {
+	// Save saves a claim, or returns a conflict error if the provider
+	// reported its dispute already
+	Save(claim *Claim) error
+	FindByID(id string) (*Claim, error)
+	// FindByReference finds the claim of a provider's dispute, or returns a
+	// not found error
+	FindByReference(provider, reference string) (*Claim, error)
+	// FindByOrder finds the claims against an order, oldest first
+	FindByOrder(orderID ids.OrderID) ([]*Claim, error)
+	// FindAll finds a page of claims, newest first
+	FindAll(filter ClaimFilter, limit, offset int) ([]*Claim, int64, error)
+	Update(claim *Claim) error
+}
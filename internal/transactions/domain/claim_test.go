@@ -0,0 +1,66 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClaim(t *testing.T) {
+	now := time.Now()
+	order := newOrder(t, now.Add(30*time.Minute), now)
+	_, err := domain.NewClaim(order, "paystack", "dispute-1", money.Money{}, "fraud", domain.ClaimActorProvider, nil, now)
+	assert.Error(t, err, "unpaid orders cannot be claimed against")
+
+	require.NoError(t, order.MarkPaid(now))
+	_, err = domain.NewClaim(order, "paystack", "dispute-1", money.Money{}, " ", domain.ClaimActorProvider, nil, now)
+	assert.Error(t, err, "a reason is required")
+	_, err = domain.NewClaim(order, "paystack", "dispute-1", money.New(1000, "USD"), "fraud", domain.ClaimActorProvider, nil, now)
+	assert.Error(t, err)
+	_, err = domain.NewClaim(order, "paystack", "dispute-1", money.Cedis(101), "fraud", domain.ClaimActorProvider, nil, now)
+	assert.Error(t, err)
+
+	claim, err := domain.NewClaim(order, "paystack", "dispute-1", money.Money{}, "fraud", domain.ClaimActorProvider, nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ClaimStatusOpen, claim.Status)
+	assert.Equal(t, order.Amount, claim.Amount, "claims are for the whole payment by default")
+	assert.True(t, claim.IsParty("buyer-a"))
+	assert.False(t, claim.IsParty("stranger"))
+}
+
+func TestClaim_EvidenceAndResolution(t *testing.T) {
+	now := time.Now()
+	order := newOrder(t, now.Add(30*time.Minute), now)
+	require.NoError(t, order.RequestPayment(domain.PaymentMethodMoMo, "momo", "payment-1", "233241234567", now))
+	require.NoError(t, order.MarkPaid(now))
+	claim, err := domain.NewClaim(order, "paystack", "dispute-1", money.Cedis(40), "not received", domain.ClaimActorProvider, nil, now)
+	require.NoError(t, err)
+
+	assert.Error(t, claim.SubmitEvidence("buyer-a", "tracking number", nil, now), "only the seller submits evidence")
+	assert.Error(t, claim.SubmitEvidence("seller-a", " ", nil, now))
+	for i := 0; i < domain.MaxClaimEvidence; i++ {
+		require.NoError(t, claim.SubmitEvidence("seller-a", "tracking number", []string{"https://example.com/receipt.jpg"}, now))
+	}
+	assert.Error(t, claim.SubmitEvidence("seller-a", "one more", nil, now))
+
+	assert.Error(t, claim.Resolve(domain.ClaimStatusOpen, "admin-a", "", now))
+	require.NoError(t, claim.Resolve(domain.ClaimStatusLost, "admin-a", " refunded by the bank ", now))
+	assert.Equal(t, "refunded by the bank", claim.Resolution)
+	assert.Error(t, claim.Resolve(domain.ClaimStatusWon, "admin-a", "", now), "claims are resolved once")
+	assert.Error(t, claim.SubmitEvidence("seller-a", "late", nil, now))
+
+	// A lost claim is recorded as refunded, up to what is left of the payment
+	partial, err := domain.NewRefund(order, nil, money.Cedis(80), "seller-a", "damaged", "key-1", now)
+	require.NoError(t, err)
+	require.True(t, partial.Succeed("tx-1", now))
+	refund, err := domain.NewChargebackRefund(order, []*domain.Refund{partial}, claim, now)
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(20), refund.Amount)
+	assert.Equal(t, domain.RefundStatusSucceeded, refund.Status)
+	assert.Equal(t, "claim:"+claim.ID, refund.IdempotencyKey)
+}
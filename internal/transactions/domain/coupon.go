@@ -0,0 +1,283 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// CouponKind represents how a coupon discounts an order
+type CouponKind string
+
+const (
+	// CouponPercentage takes a percentage off the order
+	CouponPercentage CouponKind = "percentage"
+	// CouponFixed takes a fixed amount off the order
+	CouponFixed CouponKind = "fixed"
+)
+
+// CouponTerms are what an administrator decides about a coupon: its
+// discount, what it applies to, how often it may be used and when
+type CouponTerms struct {
+	Description string
+	Kind        CouponKind
+	// Percent is how many percent percentage coupons take off, 1 to 99
+	Percent int
+	// AmountOff is the amount fixed coupons take off, which only applies
+	// to orders in its currency
+	AmountOff money.Money
+	// CategoryID and SellerID limit the coupon to listings of a category or
+	// of a seller, when set
+	CategoryID *string
+	SellerID   *ids.UserID
+	// MaxRedemptions caps how many orders may use the coupon, and
+	// MaxRedemptionsPerBuyer how many of each buyer's; zero is no limit
+	MaxRedemptions         int
+	MaxRedemptionsPerBuyer int
+	StartsAt               *time.Time
+	ExpiresAt              *time.Time
+}
+
+// Coupon is a promo code buyers enter at checkout for a discount aggregate
+// root. Redemptions counts the orders using it, so usage limits hold however
+// many buyers check out at once.
+type Coupon struct {
+	ID          string      `gorm:"type:uuid;primary_key" json:"id"`
+	Code        string      `gorm:"size:32;not null;uniqueIndex" json:"code"`
+	Description string      `gorm:"size:500" json:"description,omitempty"`
+	Kind        CouponKind  `gorm:"size:20;not null" json:"kind"`
+	Percent     int         `gorm:"not null;default:0" json:"percent,omitempty"`
+	AmountOff   money.Money `gorm:"embedded;embeddedPrefix:amount_off_" json:"amount_off"`
+	CategoryID  *string     `gorm:"type:uuid" json:"category_id,omitempty"`
+	SellerID    *ids.UserID `gorm:"type:uuid" json:"seller_id,omitempty"`
+	// MaxRedemptions and MaxRedemptionsPerBuyer are zero when unlimited
+	MaxRedemptions         int        `gorm:"not null;default:0" json:"max_redemptions"`
+	MaxRedemptionsPerBuyer int        `gorm:"not null;default:0" json:"max_redemptions_per_buyer"`
+	Redemptions            int        `gorm:"not null;default:0" json:"redemptions"`
+	StartsAt               *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt              *time.Time `json:"expires_at,omitempty"`
+	// Active is cleared to stop the coupon being used without deleting it
+	Active    bool       `gorm:"not null;default:true" json:"active"`
+	CreatedBy ids.UserID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// NormalizeCouponCode returns a code as coupons are stored, so buyers may
+// type it in any case
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// NewCoupon creates an active coupon with the given code and terms
+func NewCoupon(code string, terms CouponTerms, createdBy ids.UserID, now time.Time) (*Coupon, error) {
+	code = NormalizeCouponCode(code)
+	if !validCouponCode(code) {
+		return nil, errors.ValidationError("the coupon code must be 3 to 32 letters, digits or dashes")
+	}
+	coupon := &Coupon{
+		ID:        uuid.New().String(),
+		Code:      code,
+		Active:    true,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+	}
+	if err := coupon.Revise(terms, now); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// validCouponCode checks a normalized code is 3 to 32 letters, digits or dashes
+func validCouponCode(code string) bool {
+	if len(code) < 3 || len(code) > 32 {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// Revise replaces the coupon's terms. Orders already using the coupon keep
+// the discount they got.
+func (c *Coupon) Revise(terms CouponTerms, now time.Time) error {
+	switch terms.Kind {
+	case CouponPercentage:
+		if terms.Percent < 1 || terms.Percent > 99 {
+			return errors.ValidationError("percentage coupons take 1 to 99 percent off")
+		}
+		terms.AmountOff = money.Money{}
+	case CouponFixed:
+		if !terms.AmountOff.IsPositive() || !money.IsSupported(terms.AmountOff.Currency) {
+			return errors.ValidationError("fixed coupons need a positive amount off")
+		}
+		terms.Percent = 0
+	default:
+		return errors.ValidationError("coupon kind must be percentage or fixed")
+	}
+	if terms.MaxRedemptions < 0 || terms.MaxRedemptionsPerBuyer < 0 {
+		return errors.ValidationError("redemption limits cannot be negative")
+	}
+	if terms.StartsAt != nil && terms.ExpiresAt != nil && !terms.ExpiresAt.After(*terms.StartsAt) {
+		return errors.ValidationError("the coupon must expire after it starts")
+	}
+
+	c.Description = terms.Description
+	c.Kind = terms.Kind
+	c.Percent = terms.Percent
+	c.AmountOff = terms.AmountOff
+	c.CategoryID = terms.CategoryID
+	c.SellerID = terms.SellerID
+	c.MaxRedemptions = terms.MaxRedemptions
+	c.MaxRedemptionsPerBuyer = terms.MaxRedemptionsPerBuyer
+	c.StartsAt = terms.StartsAt
+	c.ExpiresAt = terms.ExpiresAt
+	c.UpdatedAt = now
+	return nil
+}
+
+// SetActive starts or stops the coupon being accepted at checkout
+func (c *Coupon) SetActive(active bool, now time.Time) {
+	c.Active = active
+	c.UpdatedAt = now
+}
+
+// Discount is how much the coupon takes off an order placed now. Percentage
+// discounts are rounded down to the pesewa. A coupon never pays for a whole
+// order.
+func (c *Coupon) Discount(order *Order, now time.Time) (money.Money, error) {
+	if err := c.appliesTo(order, now); err != nil {
+		return money.Money{}, err
+	}
+
+	discount := c.AmountOff
+	if c.Kind == CouponPercentage {
+		discount = money.New(order.Amount.Amount*int64(c.Percent)/100, order.Amount.Currency)
+	}
+	if !discount.IsPositive() {
+		return money.Money{}, errors.ValidationError("the coupon does not apply to this listing")
+	}
+	if discount.Cmp(order.Amount) >= 0 {
+		return money.Money{}, errors.ValidationError("the coupon cannot pay for the whole order")
+	}
+	return discount, nil
+}
+
+// appliesTo checks the coupon may be used on an order now
+func (c *Coupon) appliesTo(order *Order, now time.Time) error {
+	if !c.Active {
+		return errors.ValidationError("the coupon code is not valid")
+	}
+	if c.StartsAt != nil && now.Before(*c.StartsAt) {
+		return errors.ValidationError("the coupon is not valid yet")
+	}
+	if c.ExpiresAt != nil && !now.Before(*c.ExpiresAt) {
+		return errors.ValidationError("the coupon has expired")
+	}
+	if c.CategoryID != nil && *c.CategoryID != order.CategoryID {
+		return errors.ValidationError("the coupon does not apply to this listing")
+	}
+	if c.SellerID != nil && *c.SellerID != order.SellerID {
+		return errors.ValidationError("the coupon does not apply to this listing")
+	}
+	if c.Kind == CouponFixed && !c.AmountOff.SameCurrency(order.Amount) {
+		return errors.ValidationError("the coupon does not apply to this listing")
+	}
+	return nil
+}
+
+// Redeem counts an order as using the coupon, and returns the redemption
+// recording it. buyerRedemptions is how many of the buyer's orders use the
+// coupon already. An order already carrying the coupon, as when an abandoned
+// checkout is resumed, keeps the discount it got; others get the coupon's
+// current discount.
+func (c *Coupon) Redeem(order *Order, buyerRedemptions int, now time.Time) (*CouponRedemption, error) {
+	discount := order.Discount
+	if order.CouponCode != c.Code {
+		var err error
+		if discount, err = c.Discount(order, now); err != nil {
+			return nil, err
+		}
+	} else if err := c.appliesTo(order, now); err != nil {
+		return nil, err
+	}
+	if c.MaxRedemptions > 0 && c.Redemptions >= c.MaxRedemptions {
+		return nil, errors.ConflictError("the coupon has been used up")
+	}
+	if c.MaxRedemptionsPerBuyer > 0 && buyerRedemptions >= c.MaxRedemptionsPerBuyer {
+		return nil, errors.ConflictError("you have used this coupon as often as allowed")
+	}
+
+	c.Redemptions++
+	c.UpdatedAt = now
+	return &CouponRedemption{
+		ID:         uuid.New().String(),
+		CouponID:   c.ID,
+		OrderID:    order.ID,
+		BuyerID:    order.BuyerID,
+		Discount:   discount,
+		RedeemedAt: now,
+	}, nil
+}
+
+// Release stops counting an order abandoned or cancelled before it was paid
+// as using the coupon. It reports false if the redemption was already
+// released.
+func (c *Coupon) Release(redemption *CouponRedemption, now time.Time) bool {
+	if redemption.ReleasedAt != nil {
+		return false
+	}
+	redemption.ReleasedAt = &now
+	if c.Redemptions > 0 {
+		c.Redemptions--
+	}
+	c.UpdatedAt = now
+	return true
+}
+
+// CouponRedemption records an order using a coupon. Redemptions of orders
+// abandoned or cancelled before they were paid are released, and no longer
+// count towards the coupon's limits.
+type CouponRedemption struct {
+	ID         string      `gorm:"type:uuid;primary_key" json:"id"`
+	CouponID   string      `gorm:"type:uuid;not null;index" json:"coupon_id"`
+	OrderID    ids.OrderID `gorm:"type:uuid;not null;index" json:"order_id"`
+	BuyerID    ids.UserID  `gorm:"type:uuid;not null" json:"buyer_id"`
+	Discount   money.Money `gorm:"embedded;embeddedPrefix:discount_" json:"discount"`
+	RedeemedAt time.Time   `gorm:"not null" json:"redeemed_at"`
+	ReleasedAt *time.Time  `json:"released_at,omitempty"`
+}
+
+// CouponRepository defines the interface for coupon persistence
+type CouponRepository interface {
+	Save(coupon *Coupon) error
+	FindByID(id string) (*Coupon, error)
+	// FindByCode finds a coupon by its normalized code, or returns a not
+	// found error
+	FindByCode(code string) (*Coupon, error)
+	// FindAll finds a page of coupons, newest first
+	FindAll(limit, offset int) ([]*Coupon, int64, error)
+	Update(coupon *Coupon) error
+	// Delete deletes a coupon no order ever used. It returns a conflict
+	// error if one did.
+	Delete(id string) error
+	// Redeem locks the coupon with the code while redeem runs, so
+	// concurrent redemptions apply one after the other, and saves the
+	// redemption it returns. redeem is given how many of the buyer's
+	// orders use the coupon. An order already using the coupon keeps its
+	// redemption, which is returned without calling redeem.
+	Redeem(code string, orderID ids.OrderID, buyerID ids.UserID, redeem func(coupon *Coupon, buyerRedemptions int) (*CouponRedemption, error)) (*CouponRedemption, error)
+	// Release locks the coupon of the order's redemption not yet released,
+	// if it has one, while release runs, and saves both if it reports true
+	Release(orderID ids.OrderID, release func(coupon *Coupon, redemption *CouponRedemption) bool) error
+	// FindRedemptions finds a page of a coupon's redemptions, newest first
+	FindRedemptions(couponID string, limit, offset int) ([]*CouponRedemption, int64, error)
+}
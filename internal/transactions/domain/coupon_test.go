@@ -0,0 +1,113 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCouponOrder(t *testing.T, price money.Money) *domain.Order {
+	t.Helper()
+	now := time.Now()
+	order, err := domain.NewOrder("buyer-a", "seller-a", "listing-a", "category-a", "Phone", price, now.Add(time.Hour), now)
+	require.NoError(t, err)
+	return order
+}
+
+func TestNewCoupon_Validates(t *testing.T) {
+	now := time.Now()
+	_, err := domain.NewCoupon("no", domain.CouponTerms{Kind: domain.CouponPercentage, Percent: 10}, "admin-a", now)
+	assert.Error(t, err, "codes are at least 3 characters")
+	_, err = domain.NewCoupon("SALE 10", domain.CouponTerms{Kind: domain.CouponPercentage, Percent: 10}, "admin-a", now)
+	assert.Error(t, err)
+	_, err = domain.NewCoupon("SALE10", domain.CouponTerms{Kind: domain.CouponPercentage, Percent: 100}, "admin-a", now)
+	assert.Error(t, err, "coupons never make orders free")
+	_, err = domain.NewCoupon("SALE10", domain.CouponTerms{Kind: domain.CouponFixed}, "admin-a", now)
+	assert.Error(t, err)
+	later := now.Add(time.Hour)
+	_, err = domain.NewCoupon("SALE10", domain.CouponTerms{Kind: domain.CouponPercentage, Percent: 10, StartsAt: &later, ExpiresAt: &now}, "admin-a", now)
+	assert.Error(t, err)
+
+	coupon, err := domain.NewCoupon(" sale-10 ", domain.CouponTerms{Kind: domain.CouponPercentage, Percent: 10, AmountOff: money.Cedis(5)}, "admin-a", now)
+	require.NoError(t, err)
+	assert.Equal(t, "SALE-10", coupon.Code)
+	assert.True(t, coupon.Active)
+	assert.True(t, coupon.AmountOff.IsZero(), "percentage coupons take no fixed amount off")
+}
+
+func TestCoupon_Discount(t *testing.T) {
+	now := time.Now()
+	order := newCouponOrder(t, money.New(9999, "GHS"))
+
+	percentage, err := domain.NewCoupon("SALE15", domain.CouponTerms{Kind: domain.CouponPercentage, Percent: 15}, "admin-a", now)
+	require.NoError(t, err)
+	discount, err := percentage.Discount(order, now)
+	require.NoError(t, err)
+	assert.Equal(t, money.New(1499, "GHS"), discount, "percentage discounts are rounded down")
+
+	fixed, err := domain.NewCoupon("TENOFF", domain.CouponTerms{Kind: domain.CouponFixed, AmountOff: money.Cedis(10)}, "admin-a", now)
+	require.NoError(t, err)
+	discount, err = fixed.Discount(order, now)
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(10), discount)
+	_, err = fixed.Discount(newCouponOrder(t, money.Cedis(10)), now)
+	assert.Error(t, err, "a coupon never pays for a whole order")
+	_, err = fixed.Discount(newCouponOrder(t, money.New(5000, "USD")), now)
+	assert.Error(t, err, "fixed coupons only apply in their currency")
+
+	category, seller := "category-b", ids.UserID("seller-a")
+	scoped, err := domain.NewCoupon("PHONES", domain.CouponTerms{Kind: domain.CouponPercentage, Percent: 10, CategoryID: &category, SellerID: &seller}, "admin-a", now)
+	require.NoError(t, err)
+	_, err = scoped.Discount(order, now)
+	assert.Error(t, err, "scoped coupons only apply to their category")
+	category = "category-a"
+	_, err = scoped.Discount(order, now)
+	assert.NoError(t, err)
+
+	expired, err := domain.NewCoupon("OLD", domain.CouponTerms{Kind: domain.CouponPercentage, Percent: 10, ExpiresAt: &now}, "admin-a", now)
+	require.NoError(t, err)
+	_, err = expired.Discount(order, now)
+	assert.Error(t, err)
+	percentage.SetActive(false, now)
+	_, err = percentage.Discount(order, now)
+	assert.Error(t, err)
+}
+
+func TestCoupon_RedeemAndRelease(t *testing.T) {
+	now := time.Now()
+	coupon, err := domain.NewCoupon("SALE10", domain.CouponTerms{Kind: domain.CouponPercentage, Percent: 10, MaxRedemptions: 2, MaxRedemptionsPerBuyer: 1}, "admin-a", now)
+	require.NoError(t, err)
+	order := newCouponOrder(t, money.Cedis(100))
+
+	redemption, err := coupon.Redeem(order, 0, now)
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(10), redemption.Discount)
+	assert.Equal(t, order.ID, redemption.OrderID)
+	assert.Equal(t, 1, coupon.Redemptions)
+	require.NoError(t, order.ApplyCoupon(coupon.Code, redemption.Discount))
+	assert.Equal(t, money.Cedis(90), order.Amount)
+
+	_, err = coupon.Redeem(newCouponOrder(t, money.Cedis(100)), 1, now)
+	assert.Error(t, err, "each buyer may use the coupon once")
+	_, err = coupon.Redeem(newCouponOrder(t, money.Cedis(50)), 0, now)
+	require.NoError(t, err)
+	_, err = coupon.Redeem(newCouponOrder(t, money.Cedis(50)), 0, now)
+	assert.Error(t, err, "the coupon is used up")
+
+	assert.True(t, coupon.Release(redemption, now))
+	assert.False(t, coupon.Release(redemption, now), "redemptions are released once")
+	assert.Equal(t, 1, coupon.Redemptions)
+
+	// A resumed order keeps the discount it got, whatever the coupon's terms now
+	coupon.Percent = 50
+	redemption, err = coupon.Redeem(order, 0, now)
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(10), redemption.Discount)
+	assert.Error(t, order.ApplyCoupon(coupon.Code, redemption.Discount), "an order takes one coupon")
+}
@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// EscrowStatus represents where the funds of a paid order are
+type EscrowStatus string
+
+const (
+	EscrowStatusHeld     EscrowStatus = "held"
+	EscrowStatusReleased EscrowStatus = "released"
+	EscrowStatusRefunded EscrowStatus = "refunded"
+)
+
+// Reasons funds are released to the seller
+const (
+	EscrowReleaseDeliveryConfirmed = "delivery_confirmed"
+	EscrowReleaseTimeout           = "timeout"
+)
+
+// Escrow holds a buyer's payment for an order until the buyer confirms
+// delivery, when it is released to the seller. Funds the buyer does not claim
+// back are released once ReleaseDueAt passes; cancelled orders are refunded.
+type Escrow struct {
+	ID       string       `gorm:"type:uuid;primary_key" json:"id"`
+	OrderID  ids.OrderID  `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	BuyerID  ids.UserID   `gorm:"type:uuid;not null" json:"buyer_id"`
+	SellerID ids.UserID   `gorm:"type:uuid;not null" json:"seller_id"`
+	Amount   money.Money  `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	Status   EscrowStatus `gorm:"size:20;not null" json:"status"`
+	// ReleaseDueAt is when held funds are released to the seller without
+	// the buyer confirming delivery
+	ReleaseDueAt time.Time `gorm:"not null" json:"release_due_at"`
+	// ReleaseRequestedAt is when the seller said the order was delivered
+	ReleaseRequestedAt  *time.Time `json:"release_requested_at,omitempty"`
+	DeliveryConfirmedAt *time.Time `json:"delivery_confirmed_at,omitempty"`
+	ReleasedAt          *time.Time `json:"released_at,omitempty"`
+	RefundedAt          *time.Time `json:"refunded_at,omitempty"`
+	// FrozenAt is when a claim against the order froze the funds, which are
+	// neither released nor paid out until its claims are resolved
+	FrozenAt *time.Time `json:"frozen_at,omitempty"`
+	// Reason is why the funds were released or refunded
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewEscrow holds the payment of a paid order for up to holdPeriod
+func NewEscrow(order *Order, holdPeriod time.Duration, now time.Time) (*Escrow, error) {
+	if order.Status != OrderStatusPaid {
+		return nil, errors.ConflictError("only paid orders are held in escrow")
+	}
+	return &Escrow{
+		ID:           uuid.New().String(),
+		OrderID:      order.ID,
+		BuyerID:      order.BuyerID,
+		SellerID:     order.SellerID,
+		Amount:       order.Amount,
+		Status:       EscrowStatusHeld,
+		ReleaseDueAt: now.Add(holdPeriod),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// IsParty checks if the user is the buyer or the seller of the order
+func (e *Escrow) IsParty(userID ids.UserID) bool {
+	return userID == e.BuyerID || userID == e.SellerID
+}
+
+// ConfirmDelivery releases the funds to the seller once the buyer confirms
+// they received the order
+func (e *Escrow) ConfirmDelivery(buyerID ids.UserID, now time.Time) error {
+	if buyerID != e.BuyerID {
+		return errors.ForbiddenError("only the buyer can confirm delivery")
+	}
+	if err := e.Release(EscrowReleaseDeliveryConfirmed, now); err != nil {
+		return err
+	}
+	e.DeliveryConfirmedAt = &now
+	return nil
+}
+
+// RequestRelease records the seller saying the order was delivered. The
+// buyer then has confirmWindow to confirm delivery or cancel before the funds
+// are released, if that is sooner than the end of the hold.
+func (e *Escrow) RequestRelease(sellerID ids.UserID, confirmWindow time.Duration, now time.Time) error {
+	if sellerID != e.SellerID {
+		return errors.ForbiddenError("only the seller can request the release of funds")
+	}
+	if e.Status != EscrowStatusHeld {
+		return errors.ConflictError("funds are no longer held in escrow")
+	}
+	if e.ReleaseRequestedAt != nil {
+		return errors.ConflictError("release of the funds was already requested")
+	}
+	e.ReleaseRequestedAt = &now
+	if due := now.Add(confirmWindow); due.Before(e.ReleaseDueAt) {
+		e.ReleaseDueAt = due
+	}
+	e.UpdatedAt = now
+	return nil
+}
+
+// ReleaseDue checks if held funds are past the time they are released
+func (e *Escrow) ReleaseDue(now time.Time) bool {
+	return e.Status == EscrowStatusHeld && !e.Frozen() && !now.Before(e.ReleaseDueAt)
+}
+
+// Release releases the held funds to the seller
+func (e *Escrow) Release(reason string, now time.Time) error {
+	if e.Status != EscrowStatusHeld {
+		return errors.ConflictError("funds are no longer held in escrow")
+	}
+	if e.Frozen() {
+		return errors.ConflictError("the funds are frozen by a claim against the order")
+	}
+	e.Status = EscrowStatusReleased
+	e.ReleasedAt = &now
+	e.Reason = reason
+	e.UpdatedAt = now
+	return nil
+}
+
+// Refund returns the held funds to the buyer when the order is cancelled.
+// The seller may cancel while the funds are held; the buyer only until the
+// seller requests their release.
+func (e *Escrow) Refund(userID ids.UserID, reason string, now time.Time) error {
+	if !e.IsParty(userID) {
+		return errors.ForbiddenError("only the buyer or the seller can cancel the order")
+	}
+	if e.Status != EscrowStatusHeld {
+		return errors.ConflictError("funds are no longer held in escrow")
+	}
+	if e.Frozen() {
+		return errors.ConflictError("the funds are frozen by a claim against the order")
+	}
+	if userID == e.BuyerID && e.ReleaseRequestedAt != nil {
+		return errors.ConflictError("the seller reported the order delivered; ask the seller to cancel it")
+	}
+	e.Status = EscrowStatusRefunded
+	e.RefundedAt = &now
+	e.Reason = reason
+	e.UpdatedAt = now
+	return nil
+}
+
+// Frozen checks if a claim against the order froze the funds
+func (e *Escrow) Frozen() bool {
+	return e.FrozenAt != nil
+}
+
+// Freeze stops the funds being released or paid out to the seller while a
+// claim against the order is open. It reports false if they were already
+// refunded to the buyer.
+func (e *Escrow) Freeze(now time.Time) bool {
+	if e.Status == EscrowStatusRefunded {
+		return false
+	}
+	if e.FrozenAt == nil {
+		e.FrozenAt = &now
+		e.UpdatedAt = now
+	}
+	return true
+}
+
+// Unfreeze lets the funds go on to the seller once the claims against the
+// order are resolved
+func (e *Escrow) Unfreeze(now time.Time) {
+	e.FrozenAt = nil
+	e.UpdatedAt = now
+}
+
+// EscrowRepository defines the interface for escrow persistence
+type EscrowRepository interface {
+	Save(escrow *Escrow) error
+	// FindByOrder finds the escrow of an order, or returns a not found error
+	FindByOrder(orderID ids.OrderID) (*Escrow, error)
+	// FindDueForRelease finds held escrows past their release time and not
+	// frozen, earliest first
+	FindDueForRelease(now time.Time, limit int) ([]*Escrow, error)
+	// FindUnpaid finds a seller's released escrows not yet in a payout nor
+	// frozen, earliest released first
+	FindUnpaid(sellerID ids.UserID, limit int) ([]*Escrow, error)
+	// IsPaidOut checks if the released funds of an order are in a payout to
+	// the seller. Orders without an escrow are not.
+	IsPaidOut(orderID ids.OrderID) (bool, error)
+	Update(escrow *Escrow) error
+}
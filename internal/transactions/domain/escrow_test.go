@@ -0,0 +1,66 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEscrow(t *testing.T, now time.Time) *domain.Escrow {
+	t.Helper()
+	order := newOrder(t, now.Add(30*time.Minute), now)
+	_, err := domain.NewEscrow(order, 14*24*time.Hour, now)
+	assert.Error(t, err, "unpaid orders are not held in escrow")
+
+	require.NoError(t, order.MarkPaid(now))
+	escrow, err := domain.NewEscrow(order, 14*24*time.Hour, now)
+	require.NoError(t, err)
+	return escrow
+}
+
+func TestEscrow_ReleaseRequest(t *testing.T) {
+	now := time.Now()
+	escrow := newEscrow(t, now)
+	assert.False(t, escrow.ReleaseDue(now.Add(13*24*time.Hour)))
+	assert.True(t, escrow.ReleaseDue(now.Add(14*24*time.Hour)))
+
+	require.NoError(t, escrow.RequestRelease("seller-a", 72*time.Hour, now))
+	assert.Equal(t, now.Add(72*time.Hour), escrow.ReleaseDueAt)
+	assert.Error(t, escrow.RequestRelease("seller-a", 72*time.Hour, now), "release is requested once")
+
+	// A window longer than what is left of the hold does not extend it
+	late := newEscrow(t, now)
+	require.NoError(t, late.RequestRelease("seller-a", 72*time.Hour, now.Add(13*24*time.Hour)))
+	assert.Equal(t, now.Add(14*24*time.Hour), late.ReleaseDueAt)
+}
+
+func TestEscrow_Refund(t *testing.T) {
+	now := time.Now()
+	escrow := newEscrow(t, now)
+	assert.Error(t, escrow.Refund("stranger", "fraud", now))
+
+	require.NoError(t, escrow.RequestRelease("seller-a", 72*time.Hour, now))
+	assert.Error(t, escrow.Refund("buyer-a", "changed my mind", now), "the buyer cannot cancel once the seller reported the delivery")
+	require.NoError(t, escrow.Refund("seller-a", "out of stock", now))
+	assert.Equal(t, domain.EscrowStatusRefunded, escrow.Status)
+	assert.False(t, escrow.ReleaseDue(now.Add(30*24*time.Hour)))
+	assert.Error(t, escrow.Release(domain.EscrowReleaseTimeout, now))
+}
+
+func TestEscrow_Freeze(t *testing.T) {
+	now := time.Now()
+	escrow := newEscrow(t, now)
+	require.True(t, escrow.Freeze(now))
+	assert.False(t, escrow.ReleaseDue(now.Add(30*24*time.Hour)), "frozen funds are not released")
+	assert.Error(t, escrow.Release("delivered", now))
+	assert.Error(t, escrow.Refund("seller-a", "out of stock", now))
+
+	escrow.Unfreeze(now)
+	assert.True(t, escrow.ReleaseDue(now.Add(30*24*time.Hour)))
+	require.NoError(t, escrow.Refund("seller-a", "out of stock", now))
+	assert.False(t, escrow.Freeze(now), "refunded funds have nothing left to freeze")
+}
@@ -0,0 +1,256 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// Event types
+const (
+	OrderCreatedEvent     = "order.created"
+	OrderPaidEvent        = "order.paid"
+	OrderAbandonedEvent   = "order.abandoned"
+	OrderResumedEvent     = "order.resumed"
+	OrderReleasedEvent    = "order.review_released"
+	OrderDeclinedEvent    = "order.review_declined"
+	OrderCompletedEvent   = "order.completed"
+	OrderCancelledEvent   = "order.cancelled"
+	OrderFulfillmentEvent = "order.fulfillment_updated"
+	CheckoutRecoveryEvent = "order.checkout_recovery"
+	PaymentSucceededEvent = "payment.succeeded"
+	PaymentFailedEvent    = "payment.failed"
+	PaymentRefundedEvent  = "payment.refunded"
+
+	EscrowHeldEvent             = "escrow.held"
+	EscrowReleaseRequestedEvent = "escrow.release_requested"
+	EscrowReleasedEvent         = "escrow.released"
+	EscrowRefundedEvent         = "escrow.refunded"
+
+	PayoutSucceededEvent = "payout.succeeded"
+	PayoutFailedEvent    = "payout.failed"
+
+	ClaimOpenedEvent   = "claim.opened"
+	ClaimResolvedEvent = "claim.resolved"
+)
+
+// OrderCreated represents the event when a buyer places an order
+type OrderCreated struct {
+	OrderID      ids.OrderID   `json:"order_id"`
+	BuyerID      ids.UserID    `json:"buyer_id"`
+	SellerID     ids.UserID    `json:"seller_id"`
+	ListingID    ids.ListingID `json:"listing_id"`
+	Amount       money.Money   `json:"amount"`
+	PaymentDueAt time.Time     `json:"payment_due_at"`
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// OrderPaid represents the event when an order's payment completes
+type OrderPaid struct {
+	OrderID   ids.OrderID   `json:"order_id"`
+	BuyerID   ids.UserID    `json:"buyer_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	ListingID ids.ListingID `json:"listing_id"`
+	Amount    money.Money   `json:"amount"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// OrderCompleted represents the event when an order's funds are released to
+// the seller, after the buyer confirmed delivery or the escrow hold timed out.
+// The sale is final: the listings context marks the listing sold.
+type OrderCompleted struct {
+	OrderID   ids.OrderID   `json:"order_id"`
+	BuyerID   ids.UserID    `json:"buyer_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	ListingID ids.ListingID `json:"listing_id"`
+	Amount    money.Money   `json:"amount"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// OrderCancelled represents the event when an order is cancelled, whether
+// before it was paid or, with Paid set, after its payment was refunded
+type OrderCancelled struct {
+	OrderID   ids.OrderID   `json:"order_id"`
+	BuyerID   ids.UserID    `json:"buyer_id"`
+	SellerID  ids.UserID    `json:"seller_id"`
+	ListingID ids.ListingID `json:"listing_id"`
+	Amount    money.Money   `json:"amount"`
+	Paid      bool          `json:"paid"`
+	Reason    string        `json:"reason,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// OrderFulfillmentUpdated represents the event when a paid order moves to
+// another fulfillment status. UpdatedBy is the buyer or seller who moved it;
+// the notifications context tells the other party on Channels, the channels
+// they want order updates on.
+type OrderFulfillmentUpdated struct {
+	OrderID        ids.OrderID       `json:"order_id"`
+	BuyerID        ids.UserID        `json:"buyer_id"`
+	SellerID       ids.UserID        `json:"seller_id"`
+	Status         FulfillmentStatus `json:"status"`
+	Carrier        string            `json:"carrier,omitempty"`
+	TrackingNumber string            `json:"tracking_number,omitempty"`
+	UpdatedBy      ids.UserID        `json:"updated_by"`
+	Channels       []string          `json:"channels"`
+	Timestamp      time.Time         `json:"timestamp"`
+}
+
+// PaymentSucceeded represents the event when the provider confirms an
+// order's payment. It is published once per order, before OrderPaid.
+type PaymentSucceeded struct {
+	OrderID  ids.OrderID `json:"order_id"`
+	BuyerID  ids.UserID  `json:"buyer_id"`
+	SellerID ids.UserID  `json:"seller_id"`
+	// Reference is the payment's Mobile Money reference, empty when the
+	// payment was not requested through the order
+	Reference     string      `json:"reference,omitempty"`
+	TransactionID string      `json:"transaction_id,omitempty"`
+	Amount        money.Money `json:"amount"`
+	Timestamp     time.Time   `json:"timestamp"`
+}
+
+// PaymentFailed represents the event when the payment an order awaited
+// failed, leaving the buyer free to try again
+type PaymentFailed struct {
+	OrderID   ids.OrderID `json:"order_id"`
+	BuyerID   ids.UserID  `json:"buyer_id"`
+	Reference string      `json:"reference"`
+	// Status is the provider's status, such as FAILED or REJECTED
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PaymentRefunded represents the event when a refund reached the buyer's
+// wallet. Refunded is all that was refunded of the order so far, Full set
+// once that is the whole payment. The notifications context tells the buyer
+// on Channels, the channels they want order updates on.
+type PaymentRefunded struct {
+	RefundID      string      `json:"refund_id"`
+	OrderID       ids.OrderID `json:"order_id"`
+	BuyerID       ids.UserID  `json:"buyer_id"`
+	SellerID      ids.UserID  `json:"seller_id"`
+	Amount        money.Money `json:"amount"`
+	Refunded      money.Money `json:"refunded"`
+	Full          bool        `json:"full"`
+	Reason        string      `json:"reason"`
+	TransactionID string      `json:"transaction_id,omitempty"`
+	Channels      []string    `json:"channels"`
+	Timestamp     time.Time   `json:"timestamp"`
+}
+
+// OrderAbandoned represents the event when an order's payment never completed.
+// The worker releases the listing and reminds the buyer to finish paying.
+type OrderAbandoned struct {
+	OrderID    ids.OrderID   `json:"order_id"`
+	BuyerID    ids.UserID    `json:"buyer_id"`
+	SellerID   ids.UserID    `json:"seller_id"`
+	ListingID  ids.ListingID `json:"listing_id"`
+	CategoryID string        `json:"category_id"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+// OrderResumed represents the event when a buyer returns to an abandoned checkout
+type OrderResumed struct {
+	OrderID      ids.OrderID   `json:"order_id"`
+	BuyerID      ids.UserID    `json:"buyer_id"`
+	ListingID    ids.ListingID `json:"listing_id"`
+	PaymentDueAt time.Time     `json:"payment_due_at"`
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// OrderReleased represents the event when an administrator releases an order
+// held for review, so the buyer can pay until PaymentDueAt
+type OrderReleased struct {
+	OrderID      ids.OrderID `json:"order_id"`
+	BuyerID      ids.UserID  `json:"buyer_id"`
+	ReviewerID   ids.UserID  `json:"reviewer_id"`
+	PaymentDueAt time.Time   `json:"payment_due_at"`
+	Timestamp    time.Time   `json:"timestamp"`
+}
+
+// OrderDeclined represents the event when an administrator declines an order
+// held for review, cancelling it
+type OrderDeclined struct {
+	OrderID    ids.OrderID   `json:"order_id"`
+	BuyerID    ids.UserID    `json:"buyer_id"`
+	ListingID  ids.ListingID `json:"listing_id"`
+	ReviewerID ids.UserID    `json:"reviewer_id"`
+	Reason     string        `json:"reason"`
+	Timestamp  time.Time     `json:"timestamp"`
+}
+
+// CheckoutRecovery represents the event asking the notifications context to
+// send the buyer a deep link back to the payment of an abandoned order
+type CheckoutRecovery struct {
+	OrderID   ids.OrderID `json:"order_id"`
+	BuyerID   ids.UserID  `json:"buyer_id"`
+	Title     string      `json:"title"`
+	Amount    money.Money `json:"amount"`
+	ResumeURL string      `json:"resume_url"`
+	// Channels lists the channels the buyer wants order updates on
+	Channels  []string  `json:"channels"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EscrowChanged represents the event when an order's funds are held in
+// escrow, the seller requests their release, or they are released to the
+// seller or refunded to the buyer. The event type tells which; Reason is
+// set on release and refund.
+type EscrowChanged struct {
+	EscrowID     string       `json:"escrow_id"`
+	OrderID      ids.OrderID  `json:"order_id"`
+	BuyerID      ids.UserID   `json:"buyer_id"`
+	SellerID     ids.UserID   `json:"seller_id"`
+	Amount       money.Money  `json:"amount"`
+	Status       EscrowStatus `json:"status"`
+	ReleaseDueAt time.Time    `json:"release_due_at"`
+	Reason       string       `json:"reason,omitempty"`
+	Timestamp    time.Time    `json:"timestamp"`
+}
+
+// PayoutSettled represents the event when a payout reached the seller's
+// wallet or failed, leaving its funds to be paid out again. The event type
+// tells which; Reason is set on failure. The notifications context tells the
+// seller on Channels, the channels they want order updates on.
+type PayoutSettled struct {
+	PayoutID      string        `json:"payout_id"`
+	SellerID      ids.UserID    `json:"seller_id"`
+	Amount        money.Money   `json:"amount"`
+	OrderIDs      []ids.OrderID `json:"order_ids"`
+	Status        PayoutStatus  `json:"status"`
+	TransactionID string        `json:"transaction_id,omitempty"`
+	Reason        string        `json:"reason,omitempty"`
+	Channels      []string      `json:"channels"`
+	Timestamp     time.Time     `json:"timestamp"`
+}
+
+// ClaimOpened represents the event when a buyer's claim against an order is
+// opened and the order's funds frozen. The notifications context tells the
+// seller on Channels, the channels they want order updates on.
+type ClaimOpened struct {
+	ClaimID       string      `json:"claim_id"`
+	OrderID       ids.OrderID `json:"order_id"`
+	BuyerID       ids.UserID  `json:"buyer_id"`
+	SellerID      ids.UserID  `json:"seller_id"`
+	Amount        money.Money `json:"amount"`
+	Reason        string      `json:"reason"`
+	EvidenceDueAt *time.Time  `json:"evidence_due_at,omitempty"`
+	Channels      []string    `json:"channels"`
+	Timestamp     time.Time   `json:"timestamp"`
+}
+
+// ClaimResolved represents the event when a claim against an order is won
+// or lost. The seller is told on Channels.
+type ClaimResolved struct {
+	ClaimID    string      `json:"claim_id"`
+	OrderID    ids.OrderID `json:"order_id"`
+	SellerID   ids.UserID  `json:"seller_id"`
+	Status     ClaimStatus `json:"status"`
+	Amount     money.Money `json:"amount"`
+	Resolution string      `json:"resolution,omitempty"`
+	Channels   []string    `json:"channels"`
+	Timestamp  time.Time   `json:"timestamp"`
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// FraudDecision represents what became of an order after its fraud check
+type FraudDecision string
+
+const (
+	// FraudDecisionPassed orders scored below the hold score
+	FraudDecisionPassed FraudDecision = "passed"
+	// FraudDecisionHeld orders await an administrator's review
+	FraudDecisionHeld FraudDecision = "held"
+	// FraudDecisionReleased orders were held and let through on review
+	FraudDecisionReleased FraudDecision = "released"
+	// FraudDecisionDeclined orders were held and cancelled on review
+	FraudDecisionDeclined FraudDecision = "declined"
+)
+
+// FraudSignals are what is known about a buyer and their order when it is
+// placed, which the fraud score is computed from
+type FraudSignals struct {
+	// AccountAgeDays is how many days ago the buyer signed up
+	AccountAgeDays int `json:"account_age_days"`
+	// LocationMismatch is set when the region of the buyer's delivery
+	// address is not the listing's
+	LocationMismatch bool `json:"location_mismatch"`
+	// OrdersLastHour and OrdersLastDay count the buyer's orders placed in
+	// the last hour and day, this one included
+	OrdersLastHour int `json:"orders_last_hour"`
+	OrdersLastDay  int `json:"orders_last_day"`
+	// PhoneBlocked is set when the buyer's phone number, or that of their
+	// delivery address, is blocked
+	PhoneBlocked bool `json:"phone_blocked"`
+	// Amount is the order amount in major units, such as cedis
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// FraudCheck records the fraud score of an order when it was placed, and the
+// review of orders held for scoring too high
+type FraudCheck struct {
+	ID       string        `gorm:"type:uuid;primary_key" json:"id"`
+	OrderID  ids.OrderID   `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	BuyerID  ids.UserID    `gorm:"type:uuid;not null;index" json:"buyer_id"`
+	Score    float64       `gorm:"not null" json:"score"`
+	Signals  FraudSignals  `gorm:"type:jsonb;serializer:json" json:"signals"`
+	Decision FraudDecision `gorm:"size:20;not null;index" json:"decision"`
+	// ReviewDueAt is when a held order is cancelled unless it was reviewed
+	ReviewDueAt *time.Time  `json:"review_due_at,omitempty"`
+	ReviewedBy  *ids.UserID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewNote  string      `gorm:"size:500" json:"review_note,omitempty"`
+	ReviewedAt  *time.Time  `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// NewFraudCheck records an order's fraud score. Orders scoring holdScore or
+// more are held for review until now plus reviewWindow.
+func NewFraudCheck(order *Order, signals FraudSignals, score, holdScore float64, reviewWindow time.Duration, now time.Time) *FraudCheck {
+	check := &FraudCheck{
+		ID:        uuid.New().String(),
+		OrderID:   order.ID,
+		BuyerID:   order.BuyerID,
+		Score:     score,
+		Signals:   signals,
+		Decision:  FraudDecisionPassed,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if score >= holdScore {
+		reviewDueAt := now.Add(reviewWindow)
+		check.Decision = FraudDecisionHeld
+		check.ReviewDueAt = &reviewDueAt
+	}
+	return check
+}
+
+// Held checks if the order awaits review
+func (c *FraudCheck) Held() bool {
+	return c.Decision == FraudDecisionHeld
+}
+
+// Review records an administrator releasing or declining the held order
+func (c *FraudCheck) Review(decision FraudDecision, reviewerID ids.UserID, note string, now time.Time) error {
+	if decision != FraudDecisionReleased && decision != FraudDecisionDeclined {
+		return errors.ValidationError("held orders are either released or declined")
+	}
+	if !c.Held() {
+		return errors.ConflictError("the order is not held for review")
+	}
+	c.Decision = decision
+	c.ReviewedBy = &reviewerID
+	c.ReviewNote = note
+	c.ReviewedAt = &now
+	c.UpdatedAt = now
+	return nil
+}
+
+// BlockedPhone is a phone number administrators found used for fraud.
+// Orders of buyers using it score higher.
+type BlockedPhone struct {
+	// PhoneNumber is in E.164 form, such as +233241234567, as users'
+	// numbers are stored
+	PhoneNumber string     `gorm:"primaryKey;size:20" json:"phone_number"`
+	Reason      string     `gorm:"size:500;not null" json:"reason"`
+	BlockedBy   ids.UserID `gorm:"type:uuid;not null" json:"blocked_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// FraudRepository defines the interface for fraud check and blocked phone
+// number persistence
+type FraudRepository interface {
+	SaveCheck(check *FraudCheck) error
+	FindCheckByOrder(orderID ids.OrderID) (*FraudCheck, error)
+	UpdateCheck(check *FraudCheck) error
+	// FindChecks finds a page of checks with the given decision, oldest
+	// first so held orders are reviewed in turn. Held checks whose review
+	// was due before now are left out.
+	FindChecks(decision FraudDecision, now time.Time, limit, offset int) ([]*FraudCheck, int64, error)
+	// FindChecksSince finds the checks made since the given time, most
+	// recent first
+	FindChecksSince(since time.Time, limit int) ([]*FraudCheck, error)
+
+	// BlockPhone saves a blocked phone number, or returns a conflict error
+	// if it is blocked already
+	BlockPhone(phone *BlockedPhone) error
+	UnblockPhone(phoneNumber string) error
+	// FindBlockedPhones finds a page of blocked phone numbers, most
+	// recently blocked first
+	FindBlockedPhones(limit, offset int) ([]*BlockedPhone, int64, error)
+	// AnyPhoneBlocked checks if any of the phone numbers is blocked
+	AnyPhoneBlocked(phoneNumbers []string) (bool, error)
+}
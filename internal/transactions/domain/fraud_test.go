@@ -0,0 +1,39 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFraudCheck_HoldsHighScores(t *testing.T) {
+	now := time.Now()
+	order := newOrder(t, now.Add(30*time.Minute), now)
+
+	passed := domain.NewFraudCheck(order, domain.FraudSignals{AccountAgeDays: 400}, 10, 60, 24*time.Hour, now)
+	assert.Equal(t, domain.FraudDecisionPassed, passed.Decision)
+	assert.Nil(t, passed.ReviewDueAt)
+	assert.Error(t, passed.Review(domain.FraudDecisionReleased, "admin-a", "", now), "only held orders are reviewed")
+
+	held := domain.NewFraudCheck(order, domain.FraudSignals{PhoneBlocked: true}, 60, 60, 24*time.Hour, now)
+	assert.True(t, held.Held())
+	require.NotNil(t, held.ReviewDueAt)
+	assert.Equal(t, now.Add(24*time.Hour), *held.ReviewDueAt)
+}
+
+func TestFraudCheck_Review(t *testing.T) {
+	now := time.Now()
+	check := domain.NewFraudCheck(newOrder(t, now.Add(30*time.Minute), now), domain.FraudSignals{}, 80, 60, time.Hour, now)
+
+	assert.Error(t, check.Review(domain.FraudDecisionHeld, "admin-a", "", now))
+	require.NoError(t, check.Review(domain.FraudDecisionDeclined, "admin-a", "stolen phone", now))
+	assert.Equal(t, domain.FraudDecisionDeclined, check.Decision)
+	assert.Equal(t, ids.UserID("admin-a"), *check.ReviewedBy)
+	assert.Equal(t, "stolen phone", check.ReviewNote)
+	assert.Error(t, check.Review(domain.FraudDecisionReleased, "admin-b", "", now), "orders are reviewed once")
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// FulfillmentStatus represents how far a paid order has got on its way to
+// the buyer
+type FulfillmentStatus string
+
+const (
+	// FulfillmentAwaitingShipment orders are paid and wait for the seller
+	FulfillmentAwaitingShipment FulfillmentStatus = "awaiting_shipment"
+	// FulfillmentPickupArranged orders are collected by the buyer in person
+	FulfillmentPickupArranged FulfillmentStatus = "pickup_arranged"
+	// FulfillmentShipped orders were handed to a carrier or delivery rider
+	FulfillmentShipped FulfillmentStatus = "shipped"
+	// FulfillmentInTransit orders are on their way, as the carrier reported
+	FulfillmentInTransit FulfillmentStatus = "in_transit"
+	// FulfillmentDelivered orders reached the buyer
+	FulfillmentDelivered FulfillmentStatus = "delivered"
+)
+
+// fulfillmentTransitions are the statuses a paid order may move to from each
+// fulfillment status. Orders are either shipped or collected; shipped orders
+// may skip being in transit; delivered orders are final.
+var fulfillmentTransitions = map[FulfillmentStatus][]FulfillmentStatus{
+	FulfillmentAwaitingShipment: {FulfillmentPickupArranged, FulfillmentShipped},
+	FulfillmentPickupArranged:   {FulfillmentDelivered},
+	FulfillmentShipped:          {FulfillmentInTransit, FulfillmentDelivered},
+	FulfillmentInTransit:        {FulfillmentDelivered},
+	FulfillmentDelivered:        {},
+}
+
+// CanTransitionTo checks if an order in the fulfillment status may move to
+// another. Staying in the same status is not a transition.
+func (s FulfillmentStatus) CanTransitionTo(next FulfillmentStatus) bool {
+	for _, allowed := range fulfillmentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsParty checks if the user is the buyer or the seller of the order
+func (o *Order) IsParty(userID ids.UserID) bool {
+	return userID == o.BuyerID || userID == o.SellerID
+}
+
+// AdvanceFulfillment moves a paid order to the next fulfillment status,
+// recording when it got there. The seller arranges pickup, ships the order
+// and reports it in transit, giving the carrier and tracking number if there
+// is one; either party may mark it delivered.
+func (o *Order) AdvanceFulfillment(userID ids.UserID, next FulfillmentStatus, carrier, trackingNumber string, now time.Time) error {
+	if _, ok := fulfillmentTransitions[next]; !ok || next == FulfillmentAwaitingShipment {
+		return errors.ValidationError("unknown fulfillment status").WithDetails("status", next)
+	}
+	if !o.IsParty(userID) {
+		return errors.ForbiddenError("only the buyer or seller can update the order's fulfillment")
+	}
+	if userID == o.BuyerID && next != FulfillmentDelivered {
+		return errors.ForbiddenError("buyers can only mark orders delivered")
+	}
+	if o.Status != OrderStatusPaid {
+		return errors.ConflictError("only paid orders can be fulfilled")
+	}
+	if !o.FulfillmentStatus.CanTransitionTo(next) {
+		return fulfillmentTransitionError(o.FulfillmentStatus, next)
+	}
+
+	carrier = strings.TrimSpace(carrier)
+	trackingNumber = strings.TrimSpace(trackingNumber)
+	if next != FulfillmentShipped && next != FulfillmentInTransit && (carrier != "" || trackingNumber != "") {
+		return errors.ValidationError("tracking details are only given for shipped orders")
+	}
+	if carrier != "" {
+		o.Carrier = carrier
+	}
+	if trackingNumber != "" {
+		o.TrackingNumber = trackingNumber
+	}
+
+	switch next {
+	case FulfillmentPickupArranged:
+		o.PickupArrangedAt = &now
+	case FulfillmentShipped:
+		o.ShippedAt = &now
+	case FulfillmentInTransit:
+		o.InTransitAt = &now
+	case FulfillmentDelivered:
+		o.DeliveredAt = &now
+	}
+	o.FulfillmentStatus = next
+	o.UpdatedAt = now
+	return nil
+}
+
+// fulfillmentTransitionError explains why an order cannot move from one
+// fulfillment status to another, with both statuses in its details
+func fulfillmentTransitionError(from, to FulfillmentStatus) error {
+	var message string
+	switch {
+	case from == FulfillmentDelivered:
+		message = "the order was delivered already"
+	case from == to:
+		message = "the order is in that fulfillment status already"
+	case to == FulfillmentInTransit:
+		message = "only shipped orders can be in transit"
+	case to == FulfillmentDelivered:
+		message = "the order must be shipped or arranged for pickup before it is delivered"
+	default:
+		message = "the order was shipped or arranged for pickup already"
+	}
+	return errors.ValidationError(message).WithDetails("from", from).WithDetails("to", to)
+}
@@ -0,0 +1,65 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFulfillmentStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, domain.FulfillmentAwaitingShipment.CanTransitionTo(domain.FulfillmentShipped))
+	assert.True(t, domain.FulfillmentAwaitingShipment.CanTransitionTo(domain.FulfillmentPickupArranged))
+	assert.True(t, domain.FulfillmentShipped.CanTransitionTo(domain.FulfillmentDelivered), "shipped orders may skip being in transit")
+	assert.False(t, domain.FulfillmentAwaitingShipment.CanTransitionTo(domain.FulfillmentDelivered))
+	assert.False(t, domain.FulfillmentPickupArranged.CanTransitionTo(domain.FulfillmentInTransit))
+	assert.False(t, domain.FulfillmentShipped.CanTransitionTo(domain.FulfillmentShipped))
+	assert.False(t, domain.FulfillmentDelivered.CanTransitionTo(domain.FulfillmentShipped))
+}
+
+func TestOrder_AdvanceFulfillment(t *testing.T) {
+	now := time.Now()
+	order := newOrder(t, now.Add(30*time.Minute), now)
+	err := order.AdvanceFulfillment("seller-a", domain.FulfillmentShipped, "", "", now)
+	require.Error(t, err, "unpaid orders are not shipped")
+	assert.Equal(t, errors.ErrCodeConflict, err.(*errors.DomainError).Code)
+
+	require.NoError(t, order.MarkPaid(now))
+	assert.Equal(t, domain.FulfillmentAwaitingShipment, order.FulfillmentStatus)
+
+	err = order.AdvanceFulfillment("buyer-a", domain.FulfillmentShipped, "", "", now)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeForbidden, err.(*errors.DomainError).Code, "only the seller ships")
+	err = order.AdvanceFulfillment("seller-a", domain.FulfillmentDelivered, "", "", now)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeValidation, err.(*errors.DomainError).Code, "orders are shipped before they are delivered")
+
+	shippedAt := now.Add(time.Hour)
+	require.NoError(t, order.AdvanceFulfillment("seller-a", domain.FulfillmentShipped, "Ghana Post", " EMS123GH ", shippedAt))
+	assert.Equal(t, domain.FulfillmentShipped, order.FulfillmentStatus)
+	assert.Equal(t, "EMS123GH", order.TrackingNumber)
+	require.NotNil(t, order.ShippedAt)
+	assert.Equal(t, shippedAt, *order.ShippedAt)
+	assert.Error(t, order.AdvanceFulfillment("seller-a", domain.FulfillmentShipped, "", "", shippedAt), "orders are shipped once")
+
+	require.NoError(t, order.AdvanceFulfillment("seller-a", domain.FulfillmentInTransit, "", "", now.Add(2*time.Hour)))
+	assert.Equal(t, "Ghana Post", order.Carrier, "the carrier is kept")
+	assert.Error(t, order.AdvanceFulfillment("buyer-a", domain.FulfillmentDelivered, "", "EMS999GH", now), "tracking is given when shipping")
+	require.NoError(t, order.AdvanceFulfillment("buyer-a", domain.FulfillmentDelivered, "", "", now.Add(3*time.Hour)))
+	assert.NotNil(t, order.DeliveredAt)
+	assert.Error(t, order.AdvanceFulfillment("seller-a", domain.FulfillmentDelivered, "", "", now), "delivered orders are final")
+
+	pickup := newOrder(t, now.Add(30*time.Minute), now)
+	require.NoError(t, pickup.MarkPaid(now))
+	assert.Error(t, pickup.AdvanceFulfillment("seller-a", domain.FulfillmentPickupArranged, "", "EMS123GH", now))
+	require.NoError(t, pickup.AdvanceFulfillment("seller-a", domain.FulfillmentPickupArranged, "", "", now))
+	assert.Error(t, pickup.AdvanceFulfillment("seller-a", domain.FulfillmentInTransit, "", "", now), "collected orders are not in transit")
+	assert.Error(t, pickup.AdvanceFulfillment("seller-a", domain.FulfillmentAwaitingShipment, "", "", now))
+	require.NoError(t, pickup.AdvanceFulfillment("seller-a", domain.FulfillmentDelivered, "", "", now))
+	assert.Nil(t, pickup.ShippedAt)
+	assert.NotNil(t, pickup.PickupArrangedAt)
+}
@@ -0,0 +1,376 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// OrderStatus represents the status of an order
+type OrderStatus string
+
+const (
+	OrderStatusPendingPayment OrderStatus = "pending_payment"
+	OrderStatusPaid           OrderStatus = "paid"
+	OrderStatusAbandoned      OrderStatus = "abandoned"
+	OrderStatusCancelled      OrderStatus = "cancelled"
+	// OrderStatusHeldForReview orders scored as likely fraud and cannot be
+	// paid until an administrator releases them
+	OrderStatusHeldForReview OrderStatus = "held_for_review"
+)
+
+// PaymentMethod represents how the buyer paid for an order
+type PaymentMethod string
+
+const (
+	// PaymentMethodMoMo pays from the buyer's MTN Mobile Money wallet
+	PaymentMethodMoMo PaymentMethod = "momo"
+	// PaymentMethodVodafoneCash pays from the buyer's Vodafone Cash wallet
+	PaymentMethodVodafoneCash PaymentMethod = "vodafone_cash"
+	// PaymentMethodAirtelTigoMoney pays from the buyer's AirtelTigo Money wallet
+	PaymentMethodAirtelTigoMoney PaymentMethod = "airteltigo_money"
+	// PaymentMethodWallet pays from the buyer's marketplace wallet
+	PaymentMethodWallet PaymentMethod = "wallet"
+	// PaymentMethodCard pays by debit or credit card
+	PaymentMethodCard PaymentMethod = "card"
+)
+
+// Order represents a buyer's purchase of a listing aggregate root. The
+// listing's title, price and category are copied when the order is placed.
+// Amount is what the buyer pays, the price less the discount of the coupon
+// they entered, if any.
+type Order struct {
+	ID           ids.OrderID   `gorm:"type:uuid;primary_key" json:"id"`
+	BuyerID      ids.UserID    `gorm:"type:uuid;not null;index" json:"buyer_id"`
+	SellerID     ids.UserID    `gorm:"type:uuid;not null;index" json:"seller_id"`
+	ListingID    ids.ListingID `gorm:"type:uuid;not null;index" json:"listing_id"`
+	CategoryID   string        `gorm:"type:uuid;not null" json:"category_id"`
+	Title        string        `gorm:"not null" json:"title"`
+	Amount       money.Money   `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	CouponCode   string        `gorm:"size:32" json:"coupon_code,omitempty"`
+	Discount     money.Money   `gorm:"embedded;embeddedPrefix:discount_" json:"discount"`
+	Status       OrderStatus   `gorm:"not null;default:'pending_payment';index:idx_orders_payment_due" json:"status"`
+	PaymentDueAt time.Time     `gorm:"not null;index:idx_orders_payment_due" json:"payment_due_at"`
+	PaidAt       *time.Time    `json:"paid_at,omitempty"`
+	AbandonedAt  *time.Time    `json:"abandoned_at,omitempty"`
+	// ResumedAt is when an abandoned checkout was last resumed
+	ResumedAt *time.Time `json:"resumed_at,omitempty"`
+	// HeldAt is when the order was held for review, and ReviewedAt when an
+	// administrator released or declined it
+	HeldAt      *time.Time `json:"held_at,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	// PaymentReference is the provider's reference of the payment the buyer
+	// was last asked to make, cleared when that payment fails. For orders
+	// paid from the wallet it is the wallet entry of the payment.
+	PaymentReference string        `gorm:"size:64" json:"payment_reference,omitempty"`
+	PaymentMethod    PaymentMethod `gorm:"size:20" json:"payment_method,omitempty"`
+	// PaymentProvider is the payment provider the payment was taken through,
+	// which refunds it
+	PaymentProvider    string     `gorm:"size:20" json:"payment_provider,omitempty"`
+	Payer              string     `gorm:"size:20" json:"-"`
+	PaymentRequestedAt *time.Time `json:"payment_requested_at,omitempty"`
+	// FulfillmentStatus is how far a paid order has got on its way to the
+	// buyer, with when it reached each step. Carrier and TrackingNumber are
+	// set by the seller when shipping.
+	FulfillmentStatus FulfillmentStatus `gorm:"size:20;index" json:"fulfillment_status,omitempty"`
+	Carrier           string            `gorm:"size:50" json:"carrier,omitempty"`
+	TrackingNumber    string            `gorm:"size:64" json:"tracking_number,omitempty"`
+	PickupArrangedAt  *time.Time        `json:"pickup_arranged_at,omitempty"`
+	ShippedAt         *time.Time        `json:"shipped_at,omitempty"`
+	InTransitAt       *time.Time        `json:"in_transit_at,omitempty"`
+	DeliveredAt       *time.Time        `json:"delivered_at,omitempty"`
+	// Recovered is set when an abandoned checkout is resumed
+	Recovered bool `gorm:"not null;default:false" json:"recovered"`
+	// Version counts the updates of the order, so an update made from a copy
+	// read before another update is refused rather than overwriting it
+	Version   int       `gorm:"not null;default:0" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewOrder creates an order placed at now, awaiting payment until paymentDueAt
+func NewOrder(buyerID, sellerID ids.UserID, listingID ids.ListingID, categoryID, title string, amount money.Money, paymentDueAt, now time.Time) (*Order, error) {
+	if buyerID == "" || listingID == "" {
+		return nil, errors.ValidationError("buyer and listing are required")
+	}
+	if buyerID == sellerID {
+		return nil, errors.ValidationError("cannot buy your own listing")
+	}
+	if !amount.IsPositive() {
+		return nil, errors.ValidationError("amount must be positive")
+	}
+
+	return &Order{
+		ID:           ids.NewOrderID(),
+		BuyerID:      buyerID,
+		SellerID:     sellerID,
+		ListingID:    listingID,
+		CategoryID:   categoryID,
+		Title:        title,
+		Amount:       amount,
+		Discount:     money.New(0, amount.Currency),
+		Status:       OrderStatusPendingPayment,
+		PaymentDueAt: paymentDueAt,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// ApplyCoupon takes a coupon's discount off an order before it is saved
+func (o *Order) ApplyCoupon(code string, discount money.Money) error {
+	if o.CouponCode != "" {
+		return errors.ConflictError("the order already has a coupon")
+	}
+	amount, err := o.Amount.Sub(discount)
+	if err != nil {
+		return err
+	}
+	if !amount.IsPositive() {
+		return errors.ValidationError("the coupon cannot pay for the whole order")
+	}
+	o.Amount = amount
+	o.CouponCode = code
+	o.Discount = discount
+	return nil
+}
+
+// MarkPaid records that the buyer paid for the order, which then awaits
+// shipment
+func (o *Order) MarkPaid(now time.Time) error {
+	if o.Status != OrderStatusPendingPayment {
+		return errors.ConflictError("order is not awaiting payment")
+	}
+	o.Status = OrderStatusPaid
+	o.PaidAt = &now
+	o.FulfillmentStatus = FulfillmentAwaitingShipment
+	o.UpdatedAt = now
+	return nil
+}
+
+// RequestPayment records that the buyer was asked to pay for the order by a
+// method, through a payment provider. payer is the phone number paid from,
+// if any. Only one payment may await approval at a time, so the buyer cannot
+// pay twice.
+func (o *Order) RequestPayment(method PaymentMethod, provider, reference, payer string, now time.Time) error {
+	if err := o.CanRequestPayment(now); err != nil {
+		return err
+	}
+	o.PaymentReference = reference
+	o.PaymentMethod = method
+	o.PaymentProvider = provider
+	o.Payer = payer
+	o.PaymentRequestedAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// PayFromWallet records that the buyer paid for the order from their wallet,
+// by the given wallet entry
+func (o *Order) PayFromWallet(entryID string, now time.Time) error {
+	if err := o.CanRequestPayment(now); err != nil {
+		return err
+	}
+	o.PaymentReference = entryID
+	o.PaymentMethod = PaymentMethodWallet
+	o.PaymentRequestedAt = &now
+	return o.MarkPaid(now)
+}
+
+// CanRequestPayment checks that the buyer may be asked to pay for the order:
+// it is unpaid, within its payment deadline and has no payment awaiting
+// approval
+func (o *Order) CanRequestPayment(now time.Time) error {
+	if o.Status == OrderStatusHeldForReview {
+		return errors.ConflictError("the order is being reviewed and cannot be paid yet")
+	}
+	if o.Status != OrderStatusPendingPayment {
+		return errors.ConflictError("order is not awaiting payment")
+	}
+	if o.PaymentExpired(now) {
+		return errors.ConflictError("order payment has expired")
+	}
+	if o.PaymentReference != "" {
+		return errors.ConflictError("a payment for the order is already awaiting approval")
+	}
+	return nil
+}
+
+// AwaitsPaymentApproval checks if the order's payment is still to be
+// approved by the buyer
+func (o *Order) AwaitsPaymentApproval() bool {
+	return o.Status == OrderStatusPendingPayment && o.PaymentReference != ""
+}
+
+// PaymentFailed clears the order's payment request once the payment failed,
+// so the buyer can try again. It reports false if the reference is not that
+// of the payment awaiting approval.
+func (o *Order) PaymentFailed(reference string, now time.Time) bool {
+	if !o.AwaitsPaymentApproval() || o.PaymentReference != reference {
+		return false
+	}
+	o.PaymentReference = ""
+	o.PaymentRequestedAt = nil
+	o.UpdatedAt = now
+	return true
+}
+
+// PaymentExpired checks if the order is still unpaid past its payment deadline
+func (o *Order) PaymentExpired(now time.Time) bool {
+	return o.Status == OrderStatusPendingPayment && now.After(o.PaymentDueAt)
+}
+
+// Abandon marks an order whose payment never completed as abandoned. Orders
+// whose payment awaits approval are not, since the buyer may still approve it.
+func (o *Order) Abandon(now time.Time) error {
+	if !o.PaymentExpired(now) {
+		return errors.ConflictError("order payment has not expired")
+	}
+	if o.AwaitsPaymentApproval() {
+		return errors.ConflictError("the order's payment still awaits approval")
+	}
+	o.Status = OrderStatusAbandoned
+	o.AbandonedAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// Resume reopens an abandoned checkout so the buyer can pay until paymentDueAt
+func (o *Order) Resume(paymentDueAt, now time.Time) error {
+	if o.Status != OrderStatusAbandoned {
+		return errors.ConflictError("only abandoned orders can be resumed")
+	}
+	o.Status = OrderStatusPendingPayment
+	o.PaymentDueAt = paymentDueAt
+	o.PaymentReference = ""
+	o.PaymentRequestedAt = nil
+	o.Recovered = true
+	o.ResumedAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// HoldForReview holds a new order scored as likely fraud until
+// reviewDueAt, when it is cancelled unless an administrator released it
+func (o *Order) HoldForReview(reviewDueAt, now time.Time) error {
+	if o.Status != OrderStatusPendingPayment || o.PaymentReference != "" {
+		return errors.ConflictError("only orders awaiting payment can be held for review")
+	}
+	o.Status = OrderStatusHeldForReview
+	o.PaymentDueAt = reviewDueAt
+	o.HeldAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// ReleaseReview lets the buyer pay for an order held for review until
+// paymentDueAt
+func (o *Order) ReleaseReview(paymentDueAt, now time.Time) error {
+	if o.Status != OrderStatusHeldForReview {
+		return errors.ConflictError("the order is not held for review")
+	}
+	o.Status = OrderStatusPendingPayment
+	o.PaymentDueAt = paymentDueAt
+	o.ReviewedAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// DeclineReview cancels an order held for review
+func (o *Order) DeclineReview(now time.Time) error {
+	if o.Status != OrderStatusHeldForReview {
+		return errors.ConflictError("the order is not held for review")
+	}
+	o.Status = OrderStatusCancelled
+	o.ReviewedAt = &now
+	o.CancelledAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// Cancel cancels an order that is still awaiting payment, or held for a
+// review that never came
+func (o *Order) Cancel(now time.Time) error {
+	if o.Status != OrderStatusPendingPayment && o.Status != OrderStatusHeldForReview {
+		return errors.ConflictError("only orders awaiting payment can be cancelled")
+	}
+	o.Status = OrderStatusCancelled
+	o.CancelledAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// CancelPaid cancels a paid order whose funds are refunded to the buyer
+func (o *Order) CancelPaid(now time.Time) error {
+	if o.Status != OrderStatusPaid {
+		return errors.ConflictError("only paid orders can be cancelled for a refund")
+	}
+	o.Status = OrderStatusCancelled
+	o.CancelledAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// CategoryOrderCount counts a category's orders between two dates
+type CategoryOrderCount struct {
+	CategoryID string
+	Orders     int64
+	// Abandoned counts orders that were ever abandoned, including recovered ones
+	Abandoned int64
+	Recovered int64
+}
+
+// CategoryAbandonment reports how often checkouts in a category are abandoned
+// and how many of those the buyer came back to
+type CategoryAbandonment struct {
+	CategoryID      string  `json:"category_id"`
+	Orders          int64   `json:"orders"`
+	Abandoned       int64   `json:"abandoned"`
+	Recovered       int64   `json:"recovered"`
+	AbandonmentRate float64 `json:"abandonment_rate"`
+	RecoveryRate    float64 `json:"recovery_rate"`
+}
+
+// AbandonmentRates computes the abandonment and recovery rates of each category
+func AbandonmentRates(counts []CategoryOrderCount) []CategoryAbandonment {
+	stats := make([]CategoryAbandonment, 0, len(counts))
+	for _, count := range counts {
+		stat := CategoryAbandonment{
+			CategoryID: count.CategoryID,
+			Orders:     count.Orders,
+			Abandoned:  count.Abandoned,
+			Recovered:  count.Recovered,
+		}
+		if count.Orders > 0 {
+			stat.AbandonmentRate = float64(count.Abandoned) / float64(count.Orders)
+		}
+		if count.Abandoned > 0 {
+			stat.RecoveryRate = float64(count.Recovered) / float64(count.Abandoned)
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// OrderRepository defines the interface for order persistence
+type OrderRepository interface {
+	Save(order *Order) error
+	FindByID(id ids.OrderID) (*Order, error)
+	// FindExpiredPending finds unpaid orders past their payment deadline
+	// with no payment awaiting approval, oldest first
+	FindExpiredPending(now time.Time, limit int) ([]*Order, error)
+	// FindAwaitingPaymentApproval finds unpaid orders whose payment was
+	// requested before the given time, earliest request first
+	FindAwaitingPaymentApproval(requestedBefore time.Time, limit int) ([]*Order, error)
+	// Update saves a changed order, returning a conflict error if the order
+	// was updated since it was read
+	Update(order *Order) error
+	// CountByCategory counts orders created between from and to by category
+	CountByCategory(from, to time.Time) ([]CategoryOrderCount, error)
+	// FindHistory finds up to limit orders matching the filter, newest first
+	FindHistory(filter OrderHistoryFilter, limit int) ([]*Order, error)
+	// CountByBuyerSince counts the orders a buyer placed since the given time
+	CountByBuyerSince(buyerID ids.UserID, since time.Time) (int64, error)
+}
@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// OrderHistoryFilter defines criteria for listing a buyer's or a seller's
+// orders. Exactly one of BuyerID and SellerID is set.
+type OrderHistoryFilter struct {
+	BuyerID     ids.UserID
+	SellerID    ids.UserID
+	Status      OrderStatus
+	CreatedFrom *time.Time
+	// CreatedTo is exclusive
+	CreatedTo *time.Time
+	// After resumes the history after the last order of the previous page
+	After *OrderCursor
+}
+
+// OrderCursor marks the last order of a page of order history, so the next
+// page starts after it even if orders were placed meanwhile
+type OrderCursor struct {
+	CreatedAt time.Time   `json:"t"`
+	ID        ids.OrderID `json:"i"`
+}
+
+// NewOrderCursor marks the position of an order in the history, newest first
+func NewOrderCursor(order *Order) *OrderCursor {
+	return &OrderCursor{CreatedAt: order.CreatedAt, ID: order.ID}
+}
+
+// DecodeOrderCursor parses a cursor returned with a previous page
+func DecodeOrderCursor(token string) (*OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.ValidationError("cursor is invalid")
+	}
+	var cursor OrderCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" || cursor.CreatedAt.IsZero() {
+		return nil, errors.ValidationError("cursor is invalid")
+	}
+	return &cursor, nil
+}
+
+// Encode returns the opaque token clients send back for the next page
+func (c *OrderCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Precedes reports whether the order comes after the cursor, newest first.
+// Orders placed at the same time are ordered by ID, descending.
+func (c *OrderCursor) Precedes(order *Order) bool {
+	if !order.CreatedAt.Equal(c.CreatedAt) {
+		return order.CreatedAt.Before(c.CreatedAt)
+	}
+	return order.ID < c.ID
+}
+
+// OrderTimelineStep names a step an order went through
+type OrderTimelineStep string
+
+const (
+	OrderStepPlaced            OrderTimelineStep = "placed"
+	OrderStepHeldForReview     OrderTimelineStep = "held_for_review"
+	OrderStepReviewed          OrderTimelineStep = "reviewed"
+	OrderStepPaymentRequested  OrderTimelineStep = "payment_requested"
+	OrderStepPaid              OrderTimelineStep = "paid"
+	OrderStepAbandoned         OrderTimelineStep = "abandoned"
+	OrderStepResumed           OrderTimelineStep = "resumed"
+	OrderStepCancelled         OrderTimelineStep = "cancelled"
+	OrderStepPickupArranged    OrderTimelineStep = "pickup_arranged"
+	OrderStepShipped           OrderTimelineStep = "shipped"
+	OrderStepInTransit         OrderTimelineStep = "in_transit"
+	OrderStepDelivered         OrderTimelineStep = "delivered"
+	OrderStepFundsHeld         OrderTimelineStep = "funds_held"
+	OrderStepReleaseRequested  OrderTimelineStep = "release_requested"
+	OrderStepDeliveryConfirmed OrderTimelineStep = "delivery_confirmed"
+	OrderStepFundsReleased     OrderTimelineStep = "funds_released"
+	OrderStepFundsRefunded     OrderTimelineStep = "funds_refunded"
+	OrderStepRefundRequested   OrderTimelineStep = "refund_requested"
+	OrderStepRefunded          OrderTimelineStep = "refunded"
+	OrderStepRefundFailed      OrderTimelineStep = "refund_failed"
+)
+
+// OrderTimelineEntry is one step of an order's timeline
+type OrderTimelineEntry struct {
+	Step OrderTimelineStep `json:"step"`
+	At   time.Time         `json:"at"`
+	// Amount is the amount of a refund
+	Amount *money.Money `json:"amount,omitempty"`
+	// Reason is why the funds were released or refunded, why a refund was
+	// made or why it failed
+	Reason string `json:"reason,omitempty"`
+}
+
+// OrderTimeline lists the steps an order went through, oldest first, from
+// the order, its escrow and its refunds. escrow is nil for orders never
+// paid. A payment that failed clears its request, so only the payment
+// request last made is listed.
+func OrderTimeline(order *Order, escrow *Escrow, refunds []*Refund) []OrderTimelineEntry {
+	timeline := []OrderTimelineEntry{{Step: OrderStepPlaced, At: order.CreatedAt}}
+	add := func(step OrderTimelineStep, at *time.Time) {
+		if at != nil {
+			timeline = append(timeline, OrderTimelineEntry{Step: step, At: *at})
+		}
+	}
+
+	add(OrderStepHeldForReview, order.HeldAt)
+	add(OrderStepReviewed, order.ReviewedAt)
+	add(OrderStepPaymentRequested, order.PaymentRequestedAt)
+	add(OrderStepAbandoned, order.AbandonedAt)
+	add(OrderStepResumed, order.ResumedAt)
+	add(OrderStepPaid, order.PaidAt)
+	add(OrderStepCancelled, order.CancelledAt)
+	add(OrderStepPickupArranged, order.PickupArrangedAt)
+	add(OrderStepShipped, order.ShippedAt)
+	add(OrderStepInTransit, order.InTransitAt)
+	add(OrderStepDelivered, order.DeliveredAt)
+
+	if escrow != nil {
+		add(OrderStepFundsHeld, &escrow.CreatedAt)
+		add(OrderStepReleaseRequested, escrow.ReleaseRequestedAt)
+		add(OrderStepDeliveryConfirmed, escrow.DeliveryConfirmedAt)
+		if escrow.ReleasedAt != nil {
+			timeline = append(timeline, OrderTimelineEntry{Step: OrderStepFundsReleased, At: *escrow.ReleasedAt, Reason: escrow.Reason})
+		}
+		if escrow.RefundedAt != nil {
+			timeline = append(timeline, OrderTimelineEntry{Step: OrderStepFundsRefunded, At: *escrow.RefundedAt, Reason: escrow.Reason})
+		}
+	}
+
+	for _, refund := range refunds {
+		amount := refund.Amount
+		timeline = append(timeline, OrderTimelineEntry{Step: OrderStepRefundRequested, At: refund.CreatedAt, Amount: &amount, Reason: refund.Reason})
+		if refund.CompletedAt == nil {
+			continue
+		}
+		switch refund.Status {
+		case RefundStatusSucceeded:
+			timeline = append(timeline, OrderTimelineEntry{Step: OrderStepRefunded, At: *refund.CompletedAt, Amount: &amount})
+		case RefundStatusFailed:
+			timeline = append(timeline, OrderTimelineEntry{Step: OrderStepRefundFailed, At: *refund.CompletedAt, Amount: &amount, Reason: refund.FailureReason})
+		}
+	}
+
+	// Steps recorded at the same time keep the order they happen in
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].At.Before(timeline[j].At)
+	})
+	return timeline
+}
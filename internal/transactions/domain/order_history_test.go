@@ -0,0 +1,52 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderCursor_RoundTrips(t *testing.T) {
+	now := time.Now()
+	order, err := domain.NewOrder("buyer-a", "seller-a", "listing-a", "electronics", "Phone", money.Cedis(100), now.Add(time.Hour), now)
+	require.NoError(t, err)
+
+	cursor, err := domain.DecodeOrderCursor(domain.NewOrderCursor(order).Encode())
+	require.NoError(t, err)
+	assert.Equal(t, order.ID, cursor.ID)
+	assert.True(t, order.CreatedAt.Equal(cursor.CreatedAt))
+	assert.False(t, cursor.Precedes(order), "the cursor's own order is on the previous page")
+
+	older := *order
+	older.CreatedAt = order.CreatedAt.Add(-time.Second)
+	assert.True(t, cursor.Precedes(&older))
+
+	_, err = domain.DecodeOrderCursor("not-a-cursor")
+	assert.Error(t, err)
+}
+
+func TestOrderTimeline_ListsAbandonedCheckoutsResumedAndCancelled(t *testing.T) {
+	now := time.Now()
+	order, err := domain.NewOrder("buyer-a", "seller-a", "listing-a", "electronics", "Phone", money.Cedis(100), now.Add(-time.Hour), now.Add(-2*time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, order.Abandon(now.Add(-30*time.Minute)))
+	require.NoError(t, order.Resume(now.Add(time.Hour), now))
+	require.NoError(t, order.Cancel(now.Add(time.Minute)))
+
+	var steps []domain.OrderTimelineStep
+	for _, entry := range domain.OrderTimeline(order, nil, nil) {
+		steps = append(steps, entry.Step)
+	}
+	assert.Equal(t, []domain.OrderTimelineStep{
+		domain.OrderStepPlaced,
+		domain.OrderStepAbandoned,
+		domain.OrderStepResumed,
+		domain.OrderStepCancelled,
+	}, steps)
+}
@@ -0,0 +1,119 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOrder(t *testing.T, dueAt, now time.Time) *domain.Order {
+	t.Helper()
+	order, err := domain.NewOrder("buyer-a", "seller-a", "listing-1", "category-1", "Used phone", money.Cedis(100), dueAt, now)
+	require.NoError(t, err)
+	return order
+}
+
+func TestNewOrder(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	order := newOrder(t, now.Add(30*time.Minute), now)
+	assert.NotEmpty(t, order.ID)
+	assert.Equal(t, domain.OrderStatusPendingPayment, order.Status)
+	assert.Equal(t, now, order.CreatedAt)
+
+	_, err := domain.NewOrder("seller-a", "seller-a", "listing-1", "category-1", "Used phone", money.Cedis(100), now, now)
+	assert.Error(t, err)
+	_, err = domain.NewOrder("buyer-a", "seller-a", "listing-1", "category-1", "Used phone", money.Cedis(0), now, now)
+	assert.Error(t, err)
+}
+
+func TestOrder_AbandonAndResume(t *testing.T) {
+	now := time.Now()
+	order := newOrder(t, now.Add(30*time.Minute), now)
+
+	// Still within the payment window
+	assert.False(t, order.PaymentExpired(now))
+	assert.Error(t, order.Abandon(now))
+
+	later := now.Add(time.Hour)
+	assert.True(t, order.PaymentExpired(later))
+
+	// Nor while a payment awaits the buyer's approval
+	require.NoError(t, order.RequestPayment(domain.PaymentMethodMoMo, "momo", "payment-1", "233241234567", now))
+	assert.Error(t, order.Abandon(later))
+	require.True(t, order.PaymentFailed("payment-1", later))
+
+	require.NoError(t, order.Abandon(later))
+	assert.Equal(t, domain.OrderStatusAbandoned, order.Status)
+	assert.Error(t, order.MarkPaid(later))
+
+	require.NoError(t, order.Resume(later.Add(30*time.Minute), later))
+	assert.Equal(t, domain.OrderStatusPendingPayment, order.Status)
+	assert.True(t, order.Recovered)
+	assert.Equal(t, later, *order.ResumedAt)
+	assert.Error(t, order.Resume(later.Add(time.Hour), later))
+
+	require.NoError(t, order.MarkPaid(later))
+	assert.Equal(t, domain.OrderStatusPaid, order.Status)
+	assert.False(t, order.PaymentExpired(later.Add(time.Hour)))
+}
+
+func TestOrder_RequestPayment(t *testing.T) {
+	now := time.Now()
+	order := newOrder(t, now.Add(30*time.Minute), now)
+
+	require.NoError(t, order.RequestPayment(domain.PaymentMethodMoMo, "momo", "payment-1", "233241234567", now))
+	assert.True(t, order.AwaitsPaymentApproval())
+	assert.Error(t, order.RequestPayment(domain.PaymentMethodMoMo, "momo", "payment-2", "233241234567", now), "one payment awaits approval at a time")
+
+	// Failures of other payments change nothing
+	assert.False(t, order.PaymentFailed("payment-0", now))
+	assert.True(t, order.PaymentFailed("payment-1", now))
+	assert.False(t, order.AwaitsPaymentApproval())
+	require.NoError(t, order.RequestPayment(domain.PaymentMethodMoMo, "momo", "payment-2", "233241234567", now))
+
+	assert.Error(t, newOrder(t, now.Add(-time.Minute), now).RequestPayment(domain.PaymentMethodMoMo, "momo", "payment-3", "233241234567", now), "expired orders cannot be paid")
+	require.NoError(t, order.MarkPaid(now))
+	assert.False(t, order.AwaitsPaymentApproval())
+}
+
+func TestOrder_HoldForReview(t *testing.T) {
+	now := time.Now()
+	order := newOrder(t, now.Add(30*time.Minute), now)
+	require.NoError(t, order.HoldForReview(now.Add(24*time.Hour), now))
+	assert.Equal(t, domain.OrderStatusHeldForReview, order.Status)
+	assert.Error(t, order.CanRequestPayment(now), "held orders cannot be paid")
+	assert.False(t, order.PaymentExpired(now.Add(48*time.Hour)), "held orders are not abandoned")
+
+	require.NoError(t, order.ReleaseReview(now.Add(time.Hour), now))
+	assert.Equal(t, domain.OrderStatusPendingPayment, order.Status)
+	assert.NoError(t, order.CanRequestPayment(now))
+	assert.Error(t, order.ReleaseReview(now.Add(time.Hour), now))
+
+	declined := newOrder(t, now.Add(30*time.Minute), now)
+	assert.Error(t, declined.DeclineReview(now), "only held orders are declined")
+	require.NoError(t, declined.HoldForReview(now.Add(24*time.Hour), now))
+	require.NoError(t, declined.DeclineReview(now))
+	assert.Equal(t, domain.OrderStatusCancelled, declined.Status)
+	assert.NotNil(t, declined.ReviewedAt)
+
+	// Held orders nobody reviewed are cancelled
+	unreviewed := newOrder(t, now.Add(30*time.Minute), now)
+	require.NoError(t, unreviewed.HoldForReview(now.Add(24*time.Hour), now))
+	require.NoError(t, unreviewed.Cancel(now))
+}
+
+func TestAbandonmentRates(t *testing.T) {
+	stats := domain.AbandonmentRates([]domain.CategoryOrderCount{
+		{CategoryID: "phones", Orders: 10, Abandoned: 4, Recovered: 1},
+		{CategoryID: "books", Orders: 5},
+	})
+	require.Len(t, stats, 2)
+	assert.InDelta(t, 0.4, stats[0].AbandonmentRate, 0.0001)
+	assert.InDelta(t, 0.25, stats[0].RecoveryRate, 0.0001)
+	assert.Zero(t, stats[1].AbandonmentRate)
+	assert.Zero(t, stats[1].RecoveryRate)
+}
@@ -0,0 +1,286 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// PayoutSchedule represents how often a seller is paid the funds released to them
+type PayoutSchedule string
+
+const (
+	// PayoutScheduleInstant pays sellers as soon as funds are released
+	PayoutScheduleInstant PayoutSchedule = "instant"
+	// PayoutScheduleDaily pays sellers once a day, from midnight
+	PayoutScheduleDaily PayoutSchedule = "daily"
+	// PayoutScheduleWeekly pays sellers once a week, on the payout weekday
+	PayoutScheduleWeekly PayoutSchedule = "weekly"
+)
+
+// IsValid checks if the payout schedule is one sellers can choose
+func (s PayoutSchedule) IsValid() bool {
+	switch s {
+	case PayoutScheduleInstant, PayoutScheduleDaily, PayoutScheduleWeekly:
+		return true
+	}
+	return false
+}
+
+// NextPayout returns when a seller on the schedule is next paid after the
+// given time. Days start at midnight UTC, which is Ghana's time all year.
+func (s PayoutSchedule) NextPayout(after time.Time, weekday time.Weekday) time.Time {
+	if s == PayoutScheduleInstant {
+		return after
+	}
+	year, month, day := after.UTC().Date()
+	next := time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+	if s == PayoutScheduleWeekly {
+		for next.Weekday() != weekday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
+// PayoutPolicy is how payouts are scheduled across the marketplace
+type PayoutPolicy struct {
+	// DefaultSchedule is the schedule of accounts registered without one
+	DefaultSchedule PayoutSchedule
+	// Weekday is the day weekly payouts are made
+	Weekday time.Weekday
+}
+
+// weekdays maps day names to weekdays
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// ParseWeekday reads a day name, such as friday
+func ParseWeekday(name string) (time.Weekday, error) {
+	weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, errors.ValidationError("invalid weekday")
+	}
+	return weekday, nil
+}
+
+// PayoutAccount is the Mobile Money wallet a seller is paid to, and how often
+type PayoutAccount struct {
+	ID       string     `gorm:"type:uuid;primary_key" json:"id"`
+	SellerID ids.UserID `gorm:"type:uuid;not null;uniqueIndex" json:"seller_id"`
+	// Phone is the wallet's number in E.164 form, such as +233241234567
+	Phone    string         `gorm:"size:20;not null" json:"phone"`
+	Schedule PayoutSchedule `gorm:"size:20;not null" json:"schedule"`
+	// VerifiedAt is when the number was found to be an active wallet. It is
+	// cleared when a payout finds the wallet gone, pausing payouts until the
+	// seller registers a number again.
+	VerifiedAt   *time.Time `json:"verified_at"`
+	NextPayoutAt time.Time  `gorm:"not null" json:"next_payout_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// NewPayoutAccount registers the wallet a seller is paid to. The number must
+// have been verified to be an active wallet.
+func NewPayoutAccount(sellerID ids.UserID, phone string, schedule PayoutSchedule, weekday time.Weekday, now time.Time) (*PayoutAccount, error) {
+	if !schedule.IsValid() {
+		return nil, errors.ValidationError("payout schedule must be instant, daily or weekly")
+	}
+	return &PayoutAccount{
+		ID:           uuid.New().String(),
+		SellerID:     sellerID,
+		Phone:        phone,
+		Schedule:     schedule,
+		VerifiedAt:   &now,
+		NextPayoutAt: schedule.NextPayout(now, weekday),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// IsVerified checks if payouts can be sent to the account
+func (a *PayoutAccount) IsVerified() bool {
+	return a.VerifiedAt != nil
+}
+
+// Payee is the account's number as Mobile Money takes it, without the +
+func (a *PayoutAccount) Payee() string {
+	return strings.TrimPrefix(a.Phone, "+")
+}
+
+// ChangeNumber moves payouts to another wallet, verified to be active
+func (a *PayoutAccount) ChangeNumber(phone string, now time.Time) {
+	a.Phone = phone
+	a.VerifiedAt = &now
+	a.UpdatedAt = now
+}
+
+// ChangeSchedule changes how often the seller is paid, starting from now
+func (a *PayoutAccount) ChangeSchedule(schedule PayoutSchedule, weekday time.Weekday, now time.Time) error {
+	if !schedule.IsValid() {
+		return errors.ValidationError("payout schedule must be instant, daily or weekly")
+	}
+	a.Schedule = schedule
+	a.NextPayoutAt = schedule.NextPayout(now, weekday)
+	a.UpdatedAt = now
+	return nil
+}
+
+// PaidOut moves the account's next payout on to its next slot
+func (a *PayoutAccount) PaidOut(weekday time.Weekday, now time.Time) {
+	a.NextPayoutAt = a.Schedule.NextPayout(now, weekday)
+	a.UpdatedAt = now
+}
+
+// Unverify pauses payouts to a wallet Mobile Money no longer knows
+func (a *PayoutAccount) Unverify(now time.Time) {
+	a.VerifiedAt = nil
+	a.UpdatedAt = now
+}
+
+// PayoutStatus represents how far a payout to the seller's wallet has got
+type PayoutStatus string
+
+const (
+	PayoutStatusPending   PayoutStatus = "pending"
+	PayoutStatusSucceeded PayoutStatus = "succeeded"
+	PayoutStatusFailed    PayoutStatus = "failed"
+)
+
+// PayoutItem is the funds of one order released to the seller, less what
+// was refunded of it to the buyer
+type PayoutItem struct {
+	EscrowID string      `json:"escrow_id"`
+	OrderID  ids.OrderID `json:"order_id"`
+	Amount   money.Money `json:"amount"`
+}
+
+// Payout sends a seller the funds released to them in one transfer. It is
+// pending until Mobile Money reports the transfer done; the funds of a
+// failed payout are paid out again.
+type Payout struct {
+	ID       string       `gorm:"type:uuid;primary_key" json:"id"`
+	SellerID ids.UserID   `gorm:"type:uuid;not null;index" json:"seller_id"`
+	Payee    string       `gorm:"size:20;not null" json:"-"`
+	Amount   money.Money  `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	Items    []PayoutItem `gorm:"type:jsonb;serializer:json" json:"items"`
+	Status   PayoutStatus `gorm:"size:20;not null" json:"status"`
+	// Reference is the Mobile Money reference of the transfer
+	Reference     string     `gorm:"size:64" json:"reference,omitempty"`
+	TransactionID string     `gorm:"size:64" json:"transaction_id,omitempty"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// NewPayout pays the items out to a seller's wallet. Items refunded in full
+// leave nothing to send, so a payout of nothing succeeds straight away.
+func NewPayout(account *PayoutAccount, items []PayoutItem, now time.Time) (*Payout, error) {
+	if !account.IsVerified() {
+		return nil, errors.ConflictError("the payout number must be verified again")
+	}
+	if len(items) == 0 {
+		return nil, errors.ValidationError("a payout needs released funds to pay out")
+	}
+
+	amount := money.New(0, items[0].Amount.Currency)
+	for _, item := range items {
+		var err error
+		if amount, err = amount.Add(item.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	payout := &Payout{
+		ID:        uuid.New().String(),
+		SellerID:  account.SellerID,
+		Payee:     account.Payee(),
+		Amount:    amount,
+		Items:     items,
+		Status:    PayoutStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if !amount.IsPositive() {
+		payout.Succeed("", now)
+	}
+	return payout, nil
+}
+
+// EscrowIDs returns the escrows whose funds the payout sends
+func (p *Payout) EscrowIDs() []string {
+	escrowIDs := make([]string, len(p.Items))
+	for i, item := range p.Items {
+		escrowIDs[i] = item.EscrowID
+	}
+	return escrowIDs
+}
+
+// Started records the reference of the transfer sending the payout
+func (p *Payout) Started(reference string, now time.Time) {
+	p.Reference = reference
+	p.UpdatedAt = now
+}
+
+// Succeed records that the payout reached the seller's wallet. It reports
+// false if the payout was no longer pending.
+func (p *Payout) Succeed(transactionID string, now time.Time) bool {
+	if p.Status != PayoutStatusPending {
+		return false
+	}
+	p.Status = PayoutStatusSucceeded
+	p.TransactionID = transactionID
+	p.CompletedAt = &now
+	p.UpdatedAt = now
+	return true
+}
+
+// Fail records that the payout could not be sent, so its funds are paid out
+// again. It reports false if the payout was no longer pending.
+func (p *Payout) Fail(reason string, now time.Time) bool {
+	if p.Status != PayoutStatusPending {
+		return false
+	}
+	p.Status = PayoutStatusFailed
+	p.FailureReason = reason
+	p.CompletedAt = &now
+	p.UpdatedAt = now
+	return true
+}
+
+// PayoutRepository defines the interface for payout and payout account persistence
+type PayoutRepository interface {
+	SaveAccount(account *PayoutAccount) error
+	// FindAccount finds the payout account of a seller, or returns a not
+	// found error
+	FindAccount(sellerID ids.UserID) (*PayoutAccount, error)
+	// FindAccountsDue finds verified accounts whose next payout is due and
+	// whose sellers have released funds not yet paid out nor frozen,
+	// earliest due first
+	FindAccountsDue(now time.Time, limit int) ([]*PayoutAccount, error)
+	UpdateAccount(account *PayoutAccount) error
+	// Save saves a payout, claiming the escrows it pays out. It returns a
+	// conflict error if one of them is already in another payout or frozen.
+	Save(payout *Payout) error
+	FindByID(id string) (*Payout, error)
+	// FindBySeller finds a page of a seller's payouts, newest first
+	FindBySeller(sellerID ids.UserID, limit, offset int) ([]*Payout, int64, error)
+	// FindPending finds pending payouts created before the given time, oldest first
+	FindPending(createdBefore time.Time, limit int) ([]*Payout, error)
+	// Update updates a payout, freeing the escrows of a failed payout to be
+	// paid out again
+	Update(payout *Payout) error
+}
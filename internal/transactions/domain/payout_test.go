@@ -0,0 +1,58 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayoutSchedule_NextPayout(t *testing.T) {
+	// A Friday afternoon
+	now := time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, now, domain.PayoutScheduleInstant.NextPayout(now, time.Friday))
+	assert.Equal(t, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), domain.PayoutScheduleDaily.NextPayout(now, time.Friday))
+	assert.Equal(t, time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC), domain.PayoutScheduleWeekly.NextPayout(now, time.Friday), "sellers paid today are paid again next week")
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), domain.PayoutScheduleWeekly.NextPayout(now, time.Monday))
+
+	weekday, err := domain.ParseWeekday("Friday")
+	require.NoError(t, err)
+	assert.Equal(t, time.Friday, weekday)
+	_, err = domain.ParseWeekday("someday")
+	assert.Error(t, err)
+}
+
+func TestNewPayout(t *testing.T) {
+	now := time.Now()
+	_, err := domain.NewPayoutAccount("seller-a", "+233241234567", "monthly", time.Friday, now)
+	assert.Error(t, err)
+	account, err := domain.NewPayoutAccount("seller-a", "+233241234567", domain.PayoutScheduleDaily, time.Friday, now)
+	require.NoError(t, err)
+
+	_, err = domain.NewPayout(account, nil, now)
+	assert.Error(t, err, "payouts need funds to pay out")
+
+	payout, err := domain.NewPayout(account, []domain.PayoutItem{
+		{EscrowID: "escrow-1", OrderID: "order-1", Amount: money.Cedis(100)},
+		{EscrowID: "escrow-2", OrderID: "order-2", Amount: money.Cedis(60)},
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(160), payout.Amount)
+	assert.Equal(t, "233241234567", payout.Payee)
+	assert.Equal(t, []string{"escrow-1", "escrow-2"}, payout.EscrowIDs())
+	assert.Equal(t, domain.PayoutStatusPending, payout.Status)
+
+	// Orders refunded in full leave nothing to send
+	nothing, err := domain.NewPayout(account, []domain.PayoutItem{{EscrowID: "escrow-3", OrderID: "order-3", Amount: money.Cedis(0)}}, now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PayoutStatusSucceeded, nothing.Status)
+
+	account.Unverify(now)
+	_, err = domain.NewPayout(account, []domain.PayoutItem{{EscrowID: "escrow-4", OrderID: "order-4", Amount: money.Cedis(10)}}, now)
+	assert.Error(t, err, "paused accounts are not paid")
+}
@@ -0,0 +1,202 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// RefundStatus represents how far a refund to the buyer's wallet has got
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusSucceeded RefundStatus = "succeeded"
+	RefundStatusFailed    RefundStatus = "failed"
+)
+
+// MaxIdempotencyKeyLength is the longest idempotency key a refund request may carry
+const MaxIdempotencyKeyLength = 100
+
+// Refund sends part or all of an order's payment back to the wallet or card
+// it was paid from. It is pending until the payment provider reports it done.
+type Refund struct {
+	ID      string      `gorm:"type:uuid;primary_key" json:"id"`
+	OrderID ids.OrderID `gorm:"type:uuid;not null;uniqueIndex:idx_refunds_idempotency" json:"order_id"`
+	// IdempotencyKey identifies the request that asked for the refund, so a
+	// retried request gets the same refund instead of a second one
+	IdempotencyKey string     `gorm:"size:100;not null;uniqueIndex:idx_refunds_idempotency" json:"-"`
+	BuyerID        ids.UserID `gorm:"type:uuid;not null" json:"buyer_id"`
+	SellerID       ids.UserID `gorm:"type:uuid;not null" json:"seller_id"`
+	// RequestedBy is the seller who refunded the buyer, or SagaActorSystem
+	// for refunds of cancelled orders
+	RequestedBy string       `gorm:"size:50;not null" json:"requested_by"`
+	Amount      money.Money  `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	Reason      string       `gorm:"type:text;not null" json:"reason"`
+	Status      RefundStatus `gorm:"size:20;not null" json:"status"`
+	// Provider is the payment provider sending the refund, that of the order's
+	// payment. Refunds to the marketplace wallet have none.
+	Provider string `gorm:"size:20" json:"provider,omitempty"`
+	// Reference is the provider's reference of the refund
+	Reference     string     `gorm:"size:64" json:"reference,omitempty"`
+	Payee         string     `gorm:"size:20;not null" json:"-"`
+	TransactionID string     `gorm:"size:64" json:"transaction_id,omitempty"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// NewRefund refunds amount of an order's payment to the wallet or card it
+// was paid from, up to what is left of the payment after the order's other
+// refunds. A zero amount refunds all that is left.
+func NewRefund(order *Order, refunds []*Refund, amount money.Money, requestedBy, reason, idempotencyKey string, now time.Time) (*Refund, error) {
+	if order.PaidAt == nil {
+		return nil, errors.ConflictError("only paid orders can be refunded")
+	}
+	if order.Payer == "" && order.PaymentMethod != PaymentMethodWallet && order.PaymentMethod != PaymentMethodCard {
+		return nil, errors.ConflictError("the order was not paid by mobile money and cannot be refunded")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.ValidationError("refund reason is required")
+	}
+	if idempotencyKey == "" || len(idempotencyKey) > MaxIdempotencyKeyLength {
+		return nil, errors.ValidationError("idempotency key must be 1 to 100 characters")
+	}
+
+	refundable, err := RefundableAmount(order, refunds)
+	if err != nil {
+		return nil, err
+	}
+	if amount.IsZero() {
+		amount = refundable
+	}
+	if !amount.SameCurrency(order.Amount) {
+		return nil, errors.ValidationError("refunds must be in the currency of the order")
+	}
+	if !amount.IsPositive() {
+		return nil, errors.ConflictError("the order was already refunded in full")
+	}
+	if amount.Cmp(refundable) > 0 {
+		return nil, errors.ValidationError("refund exceeds what is left of the payment").WithDetails("refundable", refundable)
+	}
+
+	return &Refund{
+		ID:             uuid.New().String(),
+		OrderID:        order.ID,
+		IdempotencyKey: idempotencyKey,
+		BuyerID:        order.BuyerID,
+		SellerID:       order.SellerID,
+		RequestedBy:    requestedBy,
+		Amount:         amount,
+		Reason:         reason,
+		Status:         RefundStatusPending,
+		Payee:          order.Payer,
+		Provider:       order.PaymentProvider,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// RefundableAmount is what is left of an order's payment after its refunds,
+// not counting those that failed
+func RefundableAmount(order *Order, refunds []*Refund) (money.Money, error) {
+	refundable := order.Amount
+	for _, refund := range refunds {
+		if refund.Status == RefundStatusFailed {
+			continue
+		}
+		var err error
+		if refundable, err = refundable.Sub(refund.Amount); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return refundable, nil
+}
+
+// Started records the reference of the transfer sending the refund
+func (r *Refund) Started(reference string, now time.Time) {
+	r.Reference = reference
+	r.UpdatedAt = now
+}
+
+// Succeed records that the refund reached the buyer's wallet. It reports
+// false if the refund was no longer pending.
+func (r *Refund) Succeed(transactionID string, now time.Time) bool {
+	if r.Status != RefundStatusPending {
+		return false
+	}
+	r.Status = RefundStatusSucceeded
+	r.TransactionID = transactionID
+	r.CompletedAt = &now
+	r.UpdatedAt = now
+	return true
+}
+
+// Fail records that the refund could not be sent, so its amount can be
+// refunded again. It reports false if the refund was no longer pending.
+func (r *Refund) Fail(reason string, now time.Time) bool {
+	if r.Status != RefundStatusPending {
+		return false
+	}
+	r.Status = RefundStatusFailed
+	r.FailureReason = reason
+	r.CompletedAt = &now
+	r.UpdatedAt = now
+	return true
+}
+
+// NewChargebackRefund records the amount of a lost claim as refunded, as the
+// payment provider already sent it back to the payer, so it is not paid out
+// to the seller. It is capped at what is left of the payment, and is nil when
+// nothing is left.
+func NewChargebackRefund(order *Order, refunds []*Refund, claim *Claim, now time.Time) (*Refund, error) {
+	refundable, err := RefundableAmount(order, refunds)
+	if err != nil {
+		return nil, err
+	}
+	if !refundable.IsPositive() {
+		return nil, nil
+	}
+	amount := claim.Amount
+	if amount.Cmp(refundable) > 0 {
+		amount = refundable
+	}
+
+	return &Refund{
+		ID:             uuid.New().String(),
+		OrderID:        order.ID,
+		IdempotencyKey: "claim:" + claim.ID,
+		BuyerID:        order.BuyerID,
+		SellerID:       order.SellerID,
+		RequestedBy:    claim.ResolvedBy,
+		Amount:         amount,
+		Reason:         "chargeback: " + claim.Reason,
+		Status:         RefundStatusSucceeded,
+		Provider:       claim.Provider,
+		Reference:      claim.Reference,
+		Payee:          order.Payer,
+		CompletedAt:    &now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// RefundRepository defines the interface for refund persistence
+type RefundRepository interface {
+	Save(refund *Refund) error
+	// FindByIdempotencyKey finds the refund of an order made for a request,
+	// or returns a not found error
+	FindByIdempotencyKey(orderID ids.OrderID, key string) (*Refund, error)
+	// FindByOrder finds the refunds of an order, oldest first
+	FindByOrder(orderID ids.OrderID) ([]*Refund, error)
+	// FindPending finds pending refunds created before the given time, oldest first
+	FindPending(createdBefore time.Time, limit int) ([]*Refund, error)
+	Update(refund *Refund) error
+}
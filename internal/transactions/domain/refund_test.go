@@ -0,0 +1,49 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRefund(t *testing.T) {
+	now := time.Now()
+	order := newOrder(t, now.Add(30*time.Minute), now)
+	require.NoError(t, order.RequestPayment(domain.PaymentMethodMoMo, "momo", "payment-1", "233241234567", now))
+
+	_, err := domain.NewRefund(order, nil, money.Cedis(10), "seller-a", "damaged", "key-1", now)
+	assert.Error(t, err, "unpaid orders cannot be refunded")
+
+	require.NoError(t, order.MarkPaid(now))
+	_, err = domain.NewRefund(order, nil, money.Cedis(10), "seller-a", " ", "key-1", now)
+	assert.Error(t, err, "a reason is required")
+	_, err = domain.NewRefund(order, nil, money.Cedis(10), "seller-a", "damaged", "", now)
+	assert.Error(t, err, "an idempotency key is required")
+	_, err = domain.NewRefund(order, nil, money.New(1000, "USD"), "seller-a", "damaged", "key-1", now)
+	assert.Error(t, err)
+
+	partial, err := domain.NewRefund(order, nil, money.Cedis(30), "seller-a", "damaged", "key-1", now)
+	require.NoError(t, err)
+	assert.Equal(t, "233241234567", partial.Payee)
+	assert.Equal(t, "momo", partial.Provider, "refunds go back through the provider the order was paid through")
+
+	failed, err := domain.NewRefund(order, []*domain.Refund{partial}, money.Cedis(50), "seller-a", "damaged", "key-2", now)
+	require.NoError(t, err)
+	assert.True(t, failed.Fail("PAYEE_NOT_FOUND", now))
+	assert.False(t, failed.Succeed("tx-1", now), "settled refunds stay settled")
+
+	refundable, err := domain.RefundableAmount(order, []*domain.Refund{partial, failed})
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(70), refundable, "failed refunds do not count")
+
+	rest, err := domain.NewRefund(order, []*domain.Refund{partial, failed}, money.Money{}, "seller-a", "damaged", "key-3", now)
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(70), rest.Amount)
+	_, err = domain.NewRefund(order, []*domain.Refund{partial, failed, rest}, money.Money{}, "seller-a", "damaged", "key-4", now)
+	assert.Error(t, err, "the order is refunded in full")
+}
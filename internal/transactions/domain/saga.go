@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// SagaStatus represents how far a checkout saga has got
+type SagaStatus string
+
+const (
+	SagaStatusRunning      SagaStatus = "running"
+	SagaStatusCompleted    SagaStatus = "completed"
+	SagaStatusCompensating SagaStatus = "compensating"
+	SagaStatusCompensated  SagaStatus = "compensated"
+)
+
+// SagaStep is one step of a checkout
+type SagaStep string
+
+const (
+	SagaStepReserveListing SagaStep = "reserve_listing"
+	SagaStepAwaitPayment   SagaStep = "await_payment"
+)
+
+// CheckoutSteps are the steps of a checkout, in order
+var CheckoutSteps = []SagaStep{SagaStepReserveListing, SagaStepAwaitPayment}
+
+// SagaActorSystem is the actor of transitions made by events and timeouts
+const SagaActorSystem = "system"
+
+// SagaTransition records a step of a saga completing or being compensated
+type SagaTransition struct {
+	Step   SagaStep   `json:"step"`
+	Status SagaStatus `json:"status"`
+	Reason string     `json:"reason,omitempty"`
+	// Actor is SagaActorSystem, or the administrator who made the transition
+	Actor string    `json:"actor"`
+	At    time.Time `json:"at"`
+}
+
+// CheckoutSaga coordinates the steps of an order's checkout. Each step
+// completes when the event it waits for arrives; if a step fails or times
+// out, the steps done so far are compensated in reverse order.
+type CheckoutSaga struct {
+	ID        string        `gorm:"type:uuid;primary_key" json:"id"`
+	OrderID   ids.OrderID   `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	BuyerID   ids.UserID    `gorm:"type:uuid;not null" json:"buyer_id"`
+	ListingID ids.ListingID `gorm:"type:uuid;not null" json:"listing_id"`
+	Status    SagaStatus    `gorm:"size:20;not null" json:"status"`
+	// Step is the step running, or the step that failed
+	Step SagaStep `gorm:"size:50;not null" json:"step"`
+	// Completed lists the steps done, in order
+	Completed []SagaStep `gorm:"type:jsonb;serializer:json" json:"completed"`
+	// Compensations lists the steps still to undo, in the order they are undone
+	Compensations []SagaStep `gorm:"type:jsonb;serializer:json" json:"compensations,omitempty"`
+	// DeadlineAt is when the running step times out
+	DeadlineAt *time.Time `json:"deadline_at,omitempty"`
+	// Attempts counts failed attempts at the next compensation
+	Attempts  int              `gorm:"not null;default:0" json:"attempts"`
+	LastError string           `json:"last_error,omitempty"`
+	History   []SagaTransition `gorm:"type:jsonb;serializer:json" json:"history"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// NewCheckoutSaga starts the checkout saga of an order at its first step
+func NewCheckoutSaga(orderID ids.OrderID, buyerID ids.UserID, listingID ids.ListingID, now time.Time) *CheckoutSaga {
+	return &CheckoutSaga{
+		ID:        uuid.New().String(),
+		OrderID:   orderID,
+		BuyerID:   buyerID,
+		ListingID: listingID,
+		Status:    SagaStatusRunning,
+		Step:      CheckoutSteps[0],
+		Completed: []SagaStep{},
+		History:   []SagaTransition{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// CompleteStep records that the running step is done and moves on to the
+// next, which times out at deadline unless it is nil. It reports false if the
+// step was already done, so redelivered events change nothing.
+func (s *CheckoutSaga) CompleteStep(step SagaStep, deadline *time.Time, actor, reason string, now time.Time) (bool, error) {
+	if s.hasCompleted(step) {
+		return false, nil
+	}
+	if s.Status != SagaStatusRunning {
+		return false, errors.ConflictError("saga is not running")
+	}
+	if s.Step != step {
+		return false, errors.ConflictError("saga is at a different step")
+	}
+
+	s.Completed = append(s.Completed, step)
+	s.record(step, SagaStatusCompleted, actor, reason, now)
+	next, ok := s.nextStep()
+	if !ok {
+		s.Status = SagaStatusCompleted
+		s.DeadlineAt = nil
+		return true, nil
+	}
+	s.Step = next
+	s.DeadlineAt = deadline
+	return true, nil
+}
+
+// StartCompensation stops a running saga and queues its steps to be undone:
+// the step that failed first, then the completed steps in reverse order
+func (s *CheckoutSaga) StartCompensation(actor, reason string, now time.Time) error {
+	if s.Status != SagaStatusRunning {
+		return errors.ConflictError("only running sagas can be compensated")
+	}
+
+	compensations := []SagaStep{s.Step}
+	for i := len(s.Completed) - 1; i >= 0; i-- {
+		compensations = append(compensations, s.Completed[i])
+	}
+	s.Status = SagaStatusCompensating
+	s.Compensations = compensations
+	s.DeadlineAt = nil
+	s.Attempts = 0
+	s.LastError = ""
+	s.record(s.Step, SagaStatusCompensating, actor, reason, now)
+	return nil
+}
+
+// NextCompensation returns the next step to undo
+func (s *CheckoutSaga) NextCompensation() (SagaStep, bool) {
+	if s.Status != SagaStatusCompensating || len(s.Compensations) == 0 {
+		return "", false
+	}
+	return s.Compensations[0], true
+}
+
+// CompensateStep records that the next step to undo was undone. The saga is
+// compensated once every step is undone.
+func (s *CheckoutSaga) CompensateStep(step SagaStep, actor string, now time.Time) error {
+	next, ok := s.NextCompensation()
+	if !ok || next != step {
+		return errors.ConflictError("saga is not compensating this step")
+	}
+
+	s.Compensations = s.Compensations[1:]
+	s.Attempts = 0
+	s.LastError = ""
+	s.record(step, SagaStatusCompensated, actor, "", now)
+	if len(s.Compensations) == 0 {
+		s.Status = SagaStatusCompensated
+	}
+	return nil
+}
+
+// RecordFailure records a failed attempt at the next compensation, to be retried
+func (s *CheckoutSaga) RecordFailure(err error, now time.Time) {
+	s.Attempts++
+	s.LastError = err.Error()
+	s.UpdatedAt = now
+}
+
+// Restart runs a compensated saga again from its first step, for an order
+// the buyer came back to
+func (s *CheckoutSaga) Restart(actor, reason string, now time.Time) error {
+	if s.Status != SagaStatusCompensated {
+		return errors.ConflictError("only compensated sagas can be restarted")
+	}
+
+	s.Status = SagaStatusRunning
+	s.Step = CheckoutSteps[0]
+	s.Completed = []SagaStep{}
+	s.Compensations = nil
+	s.DeadlineAt = nil
+	s.record(s.Step, SagaStatusRunning, actor, reason, now)
+	return nil
+}
+
+// Reschedule moves the deadline of the running step, as when an order held
+// for review is released with a new payment deadline
+func (s *CheckoutSaga) Reschedule(step SagaStep, deadline *time.Time, actor, reason string, now time.Time) error {
+	if s.Status != SagaStatusRunning || s.Step != step {
+		return errors.ConflictError("saga is at a different step")
+	}
+	s.DeadlineAt = deadline
+	s.record(step, SagaStatusRunning, actor, reason, now)
+	return nil
+}
+
+// TimedOut checks if the running step is past its deadline
+func (s *CheckoutSaga) TimedOut(now time.Time) bool {
+	return s.Status == SagaStatusRunning && s.DeadlineAt != nil && now.After(*s.DeadlineAt)
+}
+
+// IsStuck checks if the saga needs attention: its running step is past its
+// deadline, or a compensation has failed
+func (s *CheckoutSaga) IsStuck(now time.Time) bool {
+	return s.TimedOut(now) || (s.Status == SagaStatusCompensating && s.Attempts > 0)
+}
+
+// hasCompleted checks if a step is done
+func (s *CheckoutSaga) hasCompleted(step SagaStep) bool {
+	for _, completed := range s.Completed {
+		if completed == step {
+			return true
+		}
+	}
+	return false
+}
+
+// nextStep returns the step after the running one
+func (s *CheckoutSaga) nextStep() (SagaStep, bool) {
+	for i, step := range CheckoutSteps {
+		if step == s.Step && i+1 < len(CheckoutSteps) {
+			return CheckoutSteps[i+1], true
+		}
+	}
+	return "", false
+}
+
+// record appends a transition to the saga's history
+func (s *CheckoutSaga) record(step SagaStep, status SagaStatus, actor, reason string, now time.Time) {
+	s.History = append(s.History, SagaTransition{
+		Step:   step,
+		Status: status,
+		Reason: reason,
+		Actor:  actor,
+		At:     now,
+	})
+	s.UpdatedAt = now
+}
+
+// SagaRepository defines the interface for checkout saga persistence
+type SagaRepository interface {
+	Save(saga *CheckoutSaga) error
+	FindByID(id string) (*CheckoutSaga, error)
+	FindByOrder(orderID ids.OrderID) (*CheckoutSaga, error)
+	// FindTimedOut finds running sagas past their step's deadline, oldest first
+	FindTimedOut(now time.Time, limit int) ([]*CheckoutSaga, error)
+	// FindCompensating finds sagas with steps still to undo, least recently tried first
+	FindCompensating(limit int) ([]*CheckoutSaga, error)
+	// FindStuck finds sagas past their step's deadline or whose compensation failed, oldest first
+	FindStuck(now time.Time, limit, offset int) ([]*CheckoutSaga, int64, error)
+	Update(saga *CheckoutSaga) error
+}
@@ -0,0 +1,70 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckoutSaga_CompletesStepsInOrder(t *testing.T) {
+	now := time.Now()
+	saga := domain.NewCheckoutSaga("order-1", "buyer-a", "listing-1", now)
+	deadline := now.Add(time.Hour)
+
+	// Steps cannot be skipped
+	_, err := saga.CompleteStep(domain.SagaStepAwaitPayment, nil, domain.SagaActorSystem, "", now)
+	assert.Error(t, err)
+
+	changed, err := saga.CompleteStep(domain.SagaStepReserveListing, &deadline, domain.SagaActorSystem, "order placed", now)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, domain.SagaStepAwaitPayment, saga.Step)
+	assert.False(t, saga.TimedOut(now))
+	assert.True(t, saga.TimedOut(deadline.Add(time.Second)))
+	assert.True(t, saga.IsStuck(deadline.Add(time.Second)))
+
+	// Redelivered events change nothing
+	changed, err = saga.CompleteStep(domain.SagaStepReserveListing, &deadline, domain.SagaActorSystem, "order placed", now)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, err = saga.CompleteStep(domain.SagaStepAwaitPayment, nil, domain.SagaActorSystem, "order paid", now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SagaStatusCompleted, saga.Status)
+	assert.Nil(t, saga.DeadlineAt)
+	assert.Len(t, saga.History, 2)
+
+	// Completed sagas cannot be undone
+	assert.Error(t, saga.StartCompensation(domain.SagaActorSystem, "", now))
+}
+
+func TestCheckoutSaga_CompensatesInReverse(t *testing.T) {
+	now := time.Now()
+	saga := domain.NewCheckoutSaga("order-1", "buyer-a", "listing-1", now)
+	_, err := saga.CompleteStep(domain.SagaStepReserveListing, nil, domain.SagaActorSystem, "", now)
+	require.NoError(t, err)
+
+	require.NoError(t, saga.StartCompensation("admin-1", "buyer asked to cancel", now))
+	assert.Equal(t, []domain.SagaStep{domain.SagaStepAwaitPayment, domain.SagaStepReserveListing}, saga.Compensations)
+
+	// Steps are undone one at a time, in order
+	assert.Error(t, saga.CompensateStep(domain.SagaStepReserveListing, "admin-1", now))
+	saga.RecordFailure(assert.AnError, now)
+	assert.True(t, saga.IsStuck(now))
+
+	require.NoError(t, saga.CompensateStep(domain.SagaStepAwaitPayment, "admin-1", now))
+	assert.Zero(t, saga.Attempts)
+	require.NoError(t, saga.CompensateStep(domain.SagaStepReserveListing, "admin-1", now))
+	assert.Equal(t, domain.SagaStatusCompensated, saga.Status)
+	_, ok := saga.NextCompensation()
+	assert.False(t, ok)
+
+	// A compensated saga can run again from the start
+	require.NoError(t, saga.Restart(domain.SagaActorSystem, "checkout resumed", now))
+	assert.Equal(t, domain.SagaStatusRunning, saga.Status)
+	assert.Equal(t, domain.SagaStepReserveListing, saga.Step)
+	assert.Empty(t, saga.Completed)
+}
@@ -0,0 +1,254 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// Wallet holds a user's balance on the marketplace. The balance only changes
+// through entries appended to the wallet's log, each recording the balance
+// it left.
+type Wallet struct {
+	ID        string      `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    ids.UserID  `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Balance   money.Money `gorm:"embedded;embeddedPrefix:balance_" json:"balance"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// NewWallet opens an empty wallet for a user
+func NewWallet(userID ids.UserID, now time.Time) *Wallet {
+	return &Wallet{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Balance:   money.New(0, money.DefaultCurrency),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// WalletEntryKind represents why a wallet's balance changed
+type WalletEntryKind string
+
+const (
+	// WalletEntryTopUp credits money paid into the wallet by Mobile Money
+	WalletEntryTopUp WalletEntryKind = "top_up"
+	// WalletEntryPurchase debits the payment of an order
+	WalletEntryPurchase WalletEntryKind = "purchase"
+	// WalletEntryPurchaseReversal credits back a purchase whose order could
+	// not be paid
+	WalletEntryPurchaseReversal WalletEntryKind = "purchase_reversal"
+	// WalletEntryRefund credits a refund of an order paid from the wallet
+	WalletEntryRefund WalletEntryKind = "refund"
+	// WalletEntryWithdrawal debits money sent out to a Mobile Money wallet
+	WalletEntryWithdrawal WalletEntryKind = "withdrawal"
+	// WalletEntryWithdrawalReversal credits back a withdrawal whose transfer failed
+	WalletEntryWithdrawalReversal WalletEntryKind = "withdrawal_reversal"
+	// WalletEntryAccountMerge moves the balance of a duplicate account's
+	// wallet to the account it was merged into
+	WalletEntryAccountMerge WalletEntryKind = "account_merge"
+)
+
+// WalletEntry is one change to a wallet's balance. Entries are never changed
+// once written. Amount is negative for debits; Reference is what the change
+// was for, such as the order or transfer, and each kind of entry is posted
+// once per reference.
+type WalletEntry struct {
+	ID           string          `gorm:"type:uuid;primary_key" json:"id"`
+	WalletID     string          `gorm:"type:uuid;not null;index" json:"wallet_id"`
+	Kind         WalletEntryKind `gorm:"size:30;not null" json:"kind"`
+	Amount       money.Money     `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	BalanceAfter money.Money     `gorm:"embedded;embeddedPrefix:balance_after_" json:"balance_after"`
+	Reference    string          `gorm:"size:64;not null" json:"reference"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// Credit adds the amount to the wallet and returns the entry recording it
+func (w *Wallet) Credit(kind WalletEntryKind, amount money.Money, reference string, now time.Time) (*WalletEntry, error) {
+	if !amount.IsPositive() {
+		return nil, errors.ValidationError("amount must be positive")
+	}
+	return w.post(kind, amount, reference, now)
+}
+
+// Debit takes the amount from the wallet and returns the entry recording it.
+// The balance never goes below zero.
+func (w *Wallet) Debit(kind WalletEntryKind, amount money.Money, reference string, now time.Time) (*WalletEntry, error) {
+	if !amount.IsPositive() {
+		return nil, errors.ValidationError("amount must be positive")
+	}
+	if amount.SameCurrency(w.Balance) && amount.Cmp(w.Balance) > 0 {
+		return nil, errors.ConflictError("insufficient wallet balance").WithDetails("balance", w.Balance)
+	}
+	return w.post(kind, amount.Multiply(-1), reference, now)
+}
+
+// MoveBalanceTo moves the whole balance to another wallet and returns the
+// entries recording it on each, debit first, or none if there is nothing to move
+func (w *Wallet) MoveBalanceTo(to *Wallet, kind WalletEntryKind, reference string, now time.Time) ([]*WalletEntry, error) {
+	if !w.Balance.IsPositive() {
+		return nil, nil
+	}
+	amount := w.Balance
+	debit, err := w.Debit(kind, amount, reference, now)
+	if err != nil {
+		return nil, err
+	}
+	credit, err := to.Credit(kind, amount, reference, now)
+	if err != nil {
+		return nil, err
+	}
+	return []*WalletEntry{debit, credit}, nil
+}
+
+// post applies a signed amount to the balance
+func (w *Wallet) post(kind WalletEntryKind, amount money.Money, reference string, now time.Time) (*WalletEntry, error) {
+	if reference == "" {
+		return nil, errors.ValidationError("wallet entries need a reference")
+	}
+	balance, err := w.Balance.Add(amount)
+	if err != nil {
+		return nil, errors.ValidationError("wallets only hold cedis")
+	}
+
+	w.Balance = balance
+	w.UpdatedAt = now
+	return &WalletEntry{
+		ID:           uuid.New().String(),
+		WalletID:     w.ID,
+		Kind:         kind,
+		Amount:       amount,
+		BalanceAfter: balance,
+		Reference:    reference,
+		CreatedAt:    now,
+	}, nil
+}
+
+// WalletTransferKind represents which way a transfer moves money between a
+// wallet and Mobile Money
+type WalletTransferKind string
+
+const (
+	// WalletTransferTopUp collects money from a Mobile Money wallet into the wallet
+	WalletTransferTopUp WalletTransferKind = "top_up"
+	// WalletTransferWithdrawal sends money from the wallet to a Mobile Money wallet
+	WalletTransferWithdrawal WalletTransferKind = "withdrawal"
+)
+
+// WalletTransferStatus represents how far a wallet's transfer has got
+type WalletTransferStatus string
+
+const (
+	WalletTransferStatusPending   WalletTransferStatus = "pending"
+	WalletTransferStatusSucceeded WalletTransferStatus = "succeeded"
+	WalletTransferStatusFailed    WalletTransferStatus = "failed"
+)
+
+// WalletTransfer moves money between a user's wallet and their Mobile Money
+// wallet. It is pending until Mobile Money reports it done. A top-up is
+// credited once it succeeds; a withdrawal is debited when it is made and
+// credited back if it fails.
+type WalletTransfer struct {
+	ID     string             `gorm:"type:uuid;primary_key" json:"id"`
+	UserID ids.UserID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	Kind   WalletTransferKind `gorm:"size:20;not null" json:"kind"`
+	// Phone is the Mobile Money number as Mobile Money takes it, without the +
+	Phone  string               `gorm:"size:20;not null" json:"-"`
+	Amount money.Money          `gorm:"embedded;embeddedPrefix:amount_" json:"amount"`
+	Status WalletTransferStatus `gorm:"size:20;not null" json:"status"`
+	// Reference is the Mobile Money reference of the transfer
+	Reference     string     `gorm:"size:64" json:"reference,omitempty"`
+	TransactionID string     `gorm:"size:64" json:"transaction_id,omitempty"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// NewWalletTransfer starts moving the amount between a user's wallet and the
+// Mobile Money wallet of the phone number, given in E.164 form
+func NewWalletTransfer(userID ids.UserID, kind WalletTransferKind, phone string, amount money.Money, now time.Time) (*WalletTransfer, error) {
+	if !amount.IsPositive() {
+		return nil, errors.ValidationError("amount must be positive")
+	}
+	if amount.Currency != money.DefaultCurrency {
+		return nil, errors.ValidationError("wallets only hold cedis")
+	}
+	return &WalletTransfer{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Kind:      kind,
+		Phone:     strings.TrimPrefix(phone, "+"),
+		Amount:    amount,
+		Status:    WalletTransferStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Started records the reference of the Mobile Money transfer
+func (t *WalletTransfer) Started(reference string, now time.Time) {
+	t.Reference = reference
+	t.UpdatedAt = now
+}
+
+// Succeed records that Mobile Money completed the transfer. It reports
+// false if the transfer was no longer pending.
+func (t *WalletTransfer) Succeed(transactionID string, now time.Time) bool {
+	if t.Status != WalletTransferStatusPending {
+		return false
+	}
+	t.Status = WalletTransferStatusSucceeded
+	t.TransactionID = transactionID
+	t.CompletedAt = &now
+	t.UpdatedAt = now
+	return true
+}
+
+// Fail records that the transfer did not go through. It reports false if
+// the transfer was no longer pending.
+func (t *WalletTransfer) Fail(reason string, now time.Time) bool {
+	if t.Status != WalletTransferStatusPending {
+		return false
+	}
+	t.Status = WalletTransferStatusFailed
+	t.FailureReason = reason
+	t.CompletedAt = &now
+	t.UpdatedAt = now
+	return true
+}
+
+// WalletRepository defines the interface for wallet persistence
+type WalletRepository interface {
+	// Open saves the wallet unless its user already has one, and returns
+	// the user's wallet
+	Open(wallet *Wallet) (*Wallet, error)
+	// Post changes the balance of a user's wallet. The wallet is locked
+	// while change runs, so concurrent changes apply one after the other,
+	// and the entry change returns is appended to the wallet's log. Posting
+	// an entry already posted for its reference returns a conflict error.
+	Post(userID ids.UserID, change func(wallet *Wallet) (*WalletEntry, error)) (*WalletEntry, error)
+	// Transfer changes the balances of two users' wallets in one
+	// transaction, holding both wallets' locks. move is given the sending
+	// and the receiving wallet and returns the entries to append to them.
+	// Posting an entry already posted for its reference returns a conflict
+	// error; a missing wallet returns a not found error.
+	Transfer(fromUserID, toUserID ids.UserID, move func(from, to *Wallet) ([]*WalletEntry, error)) error
+	// FindByUser finds a user's wallet, or returns a not found error if
+	// they never opened one
+	FindByUser(userID ids.UserID) (*Wallet, error)
+	// FindEntries finds a page of a wallet's entries, newest first
+	FindEntries(walletID string, limit, offset int) ([]*WalletEntry, int64, error)
+	SaveTransfer(transfer *WalletTransfer) error
+	FindTransfer(id string) (*WalletTransfer, error)
+	// FindPendingTransfers finds pending transfers started before the given
+	// time, oldest first
+	FindPendingTransfers(createdBefore time.Time, limit int) ([]*WalletTransfer, error)
+	UpdateTransfer(transfer *WalletTransfer) error
+}
@@ -0,0 +1,81 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWallet_CreditAndDebit(t *testing.T) {
+	now := time.Now()
+	wallet := domain.NewWallet("buyer-a", now)
+	assert.True(t, wallet.Balance.IsZero())
+
+	credit, err := wallet.Credit(domain.WalletEntryTopUp, money.Cedis(80), "transfer-1", now)
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(80), credit.Amount)
+	assert.Equal(t, money.Cedis(80), credit.BalanceAfter)
+	assert.Equal(t, wallet.ID, credit.WalletID)
+
+	_, err = wallet.Debit(domain.WalletEntryPurchase, money.Cedis(100), "order-1", now)
+	assert.Error(t, err, "wallets cannot be overdrawn")
+	assert.Equal(t, money.Cedis(80), wallet.Balance)
+
+	debit, err := wallet.Debit(domain.WalletEntryPurchase, money.Cedis(80), "order-1", now)
+	require.NoError(t, err)
+	assert.Equal(t, money.Cedis(-80), debit.Amount, "debits are negative")
+	assert.True(t, debit.BalanceAfter.IsZero())
+
+	_, err = wallet.Credit(domain.WalletEntryRefund, money.New(100, "USD"), "refund-1", now)
+	assert.Error(t, err)
+	_, err = wallet.Credit(domain.WalletEntryRefund, money.Cedis(-5), "refund-1", now)
+	assert.Error(t, err)
+	_, err = wallet.Credit(domain.WalletEntryRefund, money.Cedis(5), "", now)
+	assert.Error(t, err, "entries need a reference")
+}
+
+func TestWallet_MoveBalanceTo(t *testing.T) {
+	now := time.Now()
+	duplicate := domain.NewWallet("buyer-a", now)
+	primary := domain.NewWallet("buyer-b", now)
+
+	// Empty wallets have nothing to move
+	entries, err := duplicate.MoveBalanceTo(primary, domain.WalletEntryAccountMerge, "merge-1", now)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = duplicate.Credit(domain.WalletEntryTopUp, money.Cedis(30), "transfer-1", now)
+	require.NoError(t, err)
+	_, err = primary.Credit(domain.WalletEntryTopUp, money.Cedis(20), "transfer-2", now)
+	require.NoError(t, err)
+
+	entries, err = duplicate.MoveBalanceTo(primary, domain.WalletEntryAccountMerge, "merge-1", now)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, duplicate.ID, entries[0].WalletID)
+	assert.Equal(t, money.Cedis(-30), entries[0].Amount)
+	assert.Equal(t, primary.ID, entries[1].WalletID)
+	assert.Equal(t, money.Cedis(30), entries[1].Amount)
+	assert.True(t, duplicate.Balance.IsZero())
+	assert.Equal(t, money.Cedis(50), primary.Balance)
+}
+
+func TestWalletTransfer_Settles(t *testing.T) {
+	now := time.Now()
+	_, err := domain.NewWalletTransfer("buyer-a", domain.WalletTransferTopUp, "+233241234567", money.New(1000, "USD"), now)
+	assert.Error(t, err)
+
+	transfer, err := domain.NewWalletTransfer("buyer-a", domain.WalletTransferTopUp, "+233241234567", money.Cedis(50), now)
+	require.NoError(t, err)
+	assert.Equal(t, "233241234567", transfer.Phone)
+	assert.Equal(t, domain.WalletTransferStatusPending, transfer.Status)
+
+	assert.True(t, transfer.Succeed("tx-1", now))
+	assert.False(t, transfer.Fail("late failure", now), "settled transfers stay settled")
+	assert.Equal(t, domain.WalletTransferStatusSucceeded, transfer.Status)
+}
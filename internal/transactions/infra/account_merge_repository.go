@@ -0,0 +1,74 @@
+package infra
+
+import (
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// AccountMergeGORMRepository implements AccountMergeRepository using GORM
+type AccountMergeGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountMergeGORMRepository creates a new account merge repository
+func NewAccountMergeGORMRepository(db *gorm.DB) *AccountMergeGORMRepository {
+	return &AccountMergeGORMRepository{
+		db: db,
+	}
+}
+
+// reassignedColumns are the columns naming the user a transaction belongs to
+var reassignedColumns = []struct {
+	model  interface{}
+	column string
+}{
+	{&domain.Order{}, "buyer_id"},
+	{&domain.Order{}, "seller_id"},
+	{&domain.CheckoutSaga{}, "buyer_id"},
+	{&domain.Escrow{}, "buyer_id"},
+	{&domain.Escrow{}, "seller_id"},
+	{&domain.Refund{}, "buyer_id"},
+	{&domain.Refund{}, "seller_id"},
+	{&domain.Claim{}, "buyer_id"},
+	{&domain.Claim{}, "seller_id"},
+	{&domain.FraudCheck{}, "buyer_id"},
+	{&domain.CouponRedemption{}, "buyer_id"},
+	{&domain.Coupon{}, "seller_id"},
+	{&domain.Payout{}, "seller_id"},
+	{&domain.WalletTransfer{}, "user_id"},
+}
+
+// ReassignUser moves a user's transactions to another user in one transaction
+func (r *AccountMergeGORMRepository) ReassignUser(fromUserID, toUserID ids.UserID) (int64, error) {
+	var orders int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, target := range reassignedColumns {
+			changes := map[string]interface{}{target.column: toUserID}
+			if _, ok := target.model.(*domain.Order); ok {
+				// Copies of the orders read before the merge must not undo it
+				changes["version"] = gorm.Expr("version + 1")
+			}
+			result := tx.Model(target.model).
+				Where(target.column+" = ?", fromUserID).
+				Updates(changes)
+			if result.Error != nil {
+				return result.Error
+			}
+			if _, ok := target.model.(*domain.Order); ok {
+				orders += result.RowsAffected
+			}
+		}
+
+		// Sellers have one payout account, so the primary account keeps its own
+		return tx.Model(&domain.PayoutAccount{}).
+			Where("seller_id = ? AND NOT EXISTS (SELECT 1 FROM payout_accounts WHERE seller_id = ?)", fromUserID, toUserID).
+			Update("seller_id", toUserID).Error
+	})
+	if err != nil {
+		return 0, db.ClassifyError(err)
+	}
+	return orders, nil
+}
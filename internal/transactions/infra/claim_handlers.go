@@ -0,0 +1,202 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClaimHandler handles HTTP requests for the claims against sellers' orders
+type ClaimHandler struct {
+	claimService *app.ClaimService
+}
+
+// NewClaimHandler creates a new claim handler
+func NewClaimHandler(claimService *app.ClaimService) *ClaimHandler {
+	return &ClaimHandler{
+		claimService: claimService,
+	}
+}
+
+// RegisterRoutes registers claim routes. The group must be protected by
+// RequireAuth.
+func (h *ClaimHandler) RegisterRoutes(r *gin.RouterGroup) {
+	claims := r.Group("/claims")
+	{
+		claims.GET("", h.ListClaims)
+		claims.GET("/:id", h.GetClaim)
+		claims.POST("/:id/evidence", h.SubmitEvidence)
+	}
+}
+
+// ListClaims handles listing the claims against the caller's orders as a seller
+func (h *ClaimHandler) ListClaims(c *gin.Context) {
+	var query app.ClaimsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	claims, err := h.claimService.ListSellerClaims(c.Request.Context(), auth.UserID(c), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, claims)
+}
+
+// GetClaim handles retrieving a claim against an order the caller bought or sold
+func (h *ClaimHandler) GetClaim(c *gin.Context) {
+	claim, err := h.claimService.GetPartyClaim(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, claim)
+}
+
+// SubmitEvidence handles a seller contesting a claim against their order
+func (h *ClaimHandler) SubmitEvidence(c *gin.Context) {
+	var cmd app.SubmitEvidenceCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.ClaimID = c.Param("id")
+	cmd.SellerID = auth.UserID(c)
+
+	claim, err := h.claimService.SubmitEvidence(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, claim)
+}
+
+// AdminClaimHandler handles HTTP requests for administrators to open and
+// decide claims against orders
+type AdminClaimHandler struct {
+	claimService *app.ClaimService
+}
+
+// NewAdminClaimHandler creates a new admin claim handler
+func NewAdminClaimHandler(claimService *app.ClaimService) *AdminClaimHandler {
+	return &AdminClaimHandler{
+		claimService: claimService,
+	}
+}
+
+// RegisterRoutes registers admin claim routes. The group must be protected
+// by the admin role.
+func (h *AdminClaimHandler) RegisterRoutes(r *gin.RouterGroup) {
+	claims := r.Group("/claims")
+	{
+		claims.GET("", h.ListClaims)
+		claims.POST("", h.OpenClaim)
+		claims.GET("/:id", h.GetClaim)
+		claims.POST("/:id/resolve", h.ResolveClaim)
+	}
+}
+
+// ListClaims handles listing claims against any order
+func (h *AdminClaimHandler) ListClaims(c *gin.Context) {
+	var query app.ClaimsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	claims, err := h.claimService.ListClaims(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, claims)
+}
+
+// OpenClaim handles opening a claim for a reversal the payment provider
+// reported outside its webhooks
+func (h *AdminClaimHandler) OpenClaim(c *gin.Context) {
+	var cmd app.OpenClaimCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.AdminID = auth.UserID(c)
+
+	claim, err := h.claimService.OpenClaim(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, claim)
+}
+
+// GetClaim handles retrieving a claim with the seller's evidence
+func (h *AdminClaimHandler) GetClaim(c *gin.Context) {
+	claim, err := h.claimService.GetClaim(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, claim)
+}
+
+// ResolveClaim handles deciding an open claim
+func (h *AdminClaimHandler) ResolveClaim(c *gin.Context) {
+	var cmd app.ResolveClaimCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.ClaimID = c.Param("id")
+	cmd.AdminID = auth.UserID(c)
+
+	claim, err := h.claimService.ResolveClaim(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, claim)
+}
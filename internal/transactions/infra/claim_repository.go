@@ -0,0 +1,100 @@
+package infra
+
+import (
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// ClaimGORMRepository implements ClaimRepository using GORM
+type ClaimGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewClaimGORMRepository creates a new claim repository
+func NewClaimGORMRepository(db *gorm.DB) *ClaimGORMRepository {
+	return &ClaimGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a claim to the database. Each dispute of a provider is claimed
+// once.
+func (r *ClaimGORMRepository) Save(claim *domain.Claim) error {
+	err := db.ClassifyError(r.db.Create(claim).Error)
+	if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeConflict {
+		return errors.ConflictError("the dispute was reported already")
+	}
+	return err
+}
+
+// FindByID finds a claim by ID
+func (r *ClaimGORMRepository) FindByID(id string) (*domain.Claim, error) {
+	var claim domain.Claim
+	if err := r.db.First(&claim, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("claim not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &claim, nil
+}
+
+// FindByReference finds the claim of a provider's dispute
+func (r *ClaimGORMRepository) FindByReference(provider, reference string) (*domain.Claim, error) {
+	var claim domain.Claim
+	err := r.db.First(&claim, "provider = ? AND reference = ?", provider, reference).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("claim not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &claim, nil
+}
+
+// FindByOrder finds the claims against an order, oldest first
+func (r *ClaimGORMRepository) FindByOrder(orderID ids.OrderID) ([]*domain.Claim, error) {
+	var claims []*domain.Claim
+	err := r.db.Where("order_id = ?", orderID).
+		Order("created_at").
+		Find(&claims).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return claims, nil
+}
+
+// FindAll finds a page of claims, newest first
+func (r *ClaimGORMRepository) FindAll(filter domain.ClaimFilter, limit, offset int) ([]*domain.Claim, int64, error) {
+	query := r.db.Model(&domain.Claim{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.SellerID != "" {
+		query = query.Where("seller_id = ?", filter.SellerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var claims []*domain.Claim
+	err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&claims).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return claims, total, nil
+}
+
+// Update updates a claim in the database
+func (r *ClaimGORMRepository) Update(claim *domain.Claim) error {
+	return db.ClassifyError(r.db.Save(claim).Error)
+}
@@ -0,0 +1,157 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminCouponHandler handles HTTP requests for managing coupons
+type AdminCouponHandler struct {
+	couponService *app.CouponService
+}
+
+// NewAdminCouponHandler creates a new admin coupon handler
+func NewAdminCouponHandler(couponService *app.CouponService) *AdminCouponHandler {
+	return &AdminCouponHandler{
+		couponService: couponService,
+	}
+}
+
+// RegisterRoutes registers coupon administration routes. The group must be
+// protected by the admin role.
+func (h *AdminCouponHandler) RegisterRoutes(r *gin.RouterGroup) {
+	coupons := r.Group("/coupons")
+	{
+		coupons.GET("", h.ListCoupons)
+		coupons.POST("", h.CreateCoupon)
+		coupons.GET("/:id", h.GetCoupon)
+		coupons.PUT("/:id", h.UpdateCoupon)
+		coupons.DELETE("/:id", h.DeleteCoupon)
+		coupons.GET("/:id/redemptions", h.ListRedemptions)
+	}
+}
+
+// ListCoupons handles listing coupons, newest first
+func (h *AdminCouponHandler) ListCoupons(c *gin.Context) {
+	var query app.CouponsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	coupons, err := h.couponService.ListCoupons(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, coupons)
+}
+
+// CreateCoupon handles creating a coupon
+func (h *AdminCouponHandler) CreateCoupon(c *gin.Context) {
+	var cmd app.CreateCouponCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.AdminID = auth.UserID(c)
+
+	coupon, err := h.couponService.CreateCoupon(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, coupon)
+}
+
+// GetCoupon handles retrieving a coupon
+func (h *AdminCouponHandler) GetCoupon(c *gin.Context) {
+	coupon, err := h.couponService.GetCoupon(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+// UpdateCoupon handles replacing a coupon's terms, and activating or
+// deactivating it
+func (h *AdminCouponHandler) UpdateCoupon(c *gin.Context) {
+	var cmd app.UpdateCouponCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.CouponID = c.Param("id")
+
+	coupon, err := h.couponService.UpdateCoupon(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+// DeleteCoupon handles deleting a coupon no order used
+func (h *AdminCouponHandler) DeleteCoupon(c *gin.Context) {
+	if err := h.couponService.DeleteCoupon(c.Request.Context(), c.Param("id")); err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "coupon deleted"})
+}
+
+// ListRedemptions handles listing the orders that used a coupon, newest first
+func (h *AdminCouponHandler) ListRedemptions(c *gin.Context) {
+	var query app.CouponsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	redemptions, err := h.couponService.ListRedemptions(c.Request.Context(), c.Param("id"), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, redemptions)
+}
@@ -0,0 +1,205 @@
+package infra
+
+import (
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CouponGORMRepository implements CouponRepository using GORM. Coupons are
+// redeemed and released with their row locked (SELECT ... FOR UPDATE), so
+// concurrent checkouts cannot use a coupon more often than its limits allow.
+type CouponGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewCouponGORMRepository creates a new coupon repository
+func NewCouponGORMRepository(db *gorm.DB) *CouponGORMRepository {
+	return &CouponGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a coupon to the database
+func (r *CouponGORMRepository) Save(coupon *domain.Coupon) error {
+	return db.ClassifyError(r.db.Create(coupon).Error)
+}
+
+// FindByID finds a coupon by ID
+func (r *CouponGORMRepository) FindByID(id string) (*domain.Coupon, error) {
+	var coupon domain.Coupon
+	err := r.db.First(&coupon, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("coupon not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &coupon, nil
+}
+
+// FindByCode finds a coupon by its normalized code
+func (r *CouponGORMRepository) FindByCode(code string) (*domain.Coupon, error) {
+	var coupon domain.Coupon
+	err := r.db.First(&coupon, "code = ?", code).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("coupon not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &coupon, nil
+}
+
+// FindAll finds a page of coupons, newest first
+func (r *CouponGORMRepository) FindAll(limit, offset int) ([]*domain.Coupon, int64, error) {
+	var total int64
+	if err := r.db.Model(&domain.Coupon{}).Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var coupons []*domain.Coupon
+	err := r.db.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&coupons).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return coupons, total, nil
+}
+
+// Update updates a coupon's terms in the database. Redemptions is left alone,
+// as only Redeem and Release change it.
+func (r *CouponGORMRepository) Update(coupon *domain.Coupon) error {
+	return db.ClassifyError(r.db.Model(coupon).Select("*").Omit("redemptions", "created_at", "created_by").Updates(coupon).Error)
+}
+
+// Delete deletes a coupon no order ever used
+func (r *CouponGORMRepository) Delete(id string) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var coupon domain.Coupon
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&coupon, "id = ?", id).Error
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFoundError("coupon not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		var redeemed int64
+		if err := tx.Model(&domain.CouponRedemption{}).Where("coupon_id = ?", id).Count(&redeemed).Error; err != nil {
+			return err
+		}
+		if redeemed > 0 {
+			return errors.ConflictError("a coupon orders used cannot be deleted; deactivate it instead")
+		}
+		return tx.Delete(&coupon).Error
+	})
+	return db.ClassifyError(err)
+}
+
+// Redeem runs redeem with the coupon's row locked, and saves the redemption
+// it returns alongside the coupon in one transaction. An order already using
+// the coupon keeps its redemption.
+func (r *CouponGORMRepository) Redeem(code string, orderID ids.OrderID, buyerID ids.UserID, redeem func(coupon *domain.Coupon, buyerRedemptions int) (*domain.CouponRedemption, error)) (*domain.CouponRedemption, error) {
+	var redemption *domain.CouponRedemption
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var coupon domain.Coupon
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&coupon, "code = ?", code).Error
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFoundError("coupon not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		var existing domain.CouponRedemption
+		err = tx.Where("coupon_id = ? AND order_id = ? AND released_at IS NULL", coupon.ID, orderID).First(&existing).Error
+		if err == nil {
+			redemption = &existing
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		var buyerRedemptions int64
+		err = tx.Model(&domain.CouponRedemption{}).
+			Where("coupon_id = ? AND buyer_id = ? AND released_at IS NULL", coupon.ID, buyerID).
+			Count(&buyerRedemptions).Error
+		if err != nil {
+			return err
+		}
+
+		if redemption, err = redeem(&coupon, int(buyerRedemptions)); err != nil {
+			return err
+		}
+		if err := tx.Create(redemption).Error; err != nil {
+			return err
+		}
+		return tx.Model(&coupon).Select("redemptions", "updated_at").Updates(&coupon).Error
+	})
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return redemption, nil
+}
+
+// Release runs release on the order's redemption not yet released, if it has
+// one, with its coupon's row locked, and saves both if it reports true
+func (r *CouponGORMRepository) Release(orderID ids.OrderID, release func(coupon *domain.Coupon, redemption *domain.CouponRedemption) bool) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var redemption domain.CouponRedemption
+		err := tx.Where("order_id = ? AND released_at IS NULL", orderID).First(&redemption).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var coupon domain.Coupon
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&coupon, "id = ?", redemption.CouponID).Error; err != nil {
+			return err
+		}
+		// Reread the redemption under the lock, in case it was released
+		// meanwhile
+		if err := tx.First(&redemption, "id = ?", redemption.ID).Error; err != nil {
+			return err
+		}
+
+		if !release(&coupon, &redemption) {
+			return nil
+		}
+		if err := tx.Save(&redemption).Error; err != nil {
+			return err
+		}
+		return tx.Model(&coupon).Select("redemptions", "updated_at").Updates(&coupon).Error
+	})
+	return db.ClassifyError(err)
+}
+
+// FindRedemptions finds a page of a coupon's redemptions, newest first
+func (r *CouponGORMRepository) FindRedemptions(couponID string, limit, offset int) ([]*domain.CouponRedemption, int64, error) {
+	query := r.db.Model(&domain.CouponRedemption{}).Where("coupon_id = ?", couponID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var redemptions []*domain.CouponRedemption
+	err := query.Order("redeemed_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&redemptions).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return redemptions, total, nil
+}
@@ -0,0 +1,148 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EscrowHandler handles HTTP requests for the escrow of paid orders
+type EscrowHandler struct {
+	escrowService *app.EscrowService
+}
+
+// NewEscrowHandler creates a new escrow handler
+func NewEscrowHandler(escrowService *app.EscrowService) *EscrowHandler {
+	return &EscrowHandler{
+		escrowService: escrowService,
+	}
+}
+
+// RegisterRoutes registers escrow routes for buyers and sellers. The group
+// must be protected by RequireAuth.
+func (h *EscrowHandler) RegisterRoutes(r *gin.RouterGroup) {
+	orders := r.Group("/orders")
+	{
+		orders.GET("/:id/escrow", h.GetEscrow)
+		orders.POST("/:id/confirm-delivery", h.ConfirmDelivery)
+		orders.POST("/:id/request-release", h.RequestRelease)
+		orders.POST("/:id/cancel", h.CancelOrder)
+	}
+}
+
+// GetEscrow handles retrieving the escrow of one of the caller's orders or sales
+func (h *EscrowHandler) GetEscrow(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	escrow, err := h.escrowService.GetEscrow(c.Request.Context(), orderID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, escrow)
+}
+
+// ConfirmDelivery handles a buyer confirming they received an order,
+// releasing its funds to the seller
+func (h *EscrowHandler) ConfirmDelivery(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	escrow, err := h.escrowService.ConfirmDelivery(c.Request.Context(), orderID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, escrow)
+}
+
+// RequestRelease handles a seller reporting an order delivered and asking
+// for its funds
+func (h *EscrowHandler) RequestRelease(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	escrow, err := h.escrowService.RequestRelease(c.Request.Context(), orderID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, escrow)
+}
+
+// CancelOrder handles the buyer or the seller cancelling a paid order,
+// refunding the buyer
+func (h *EscrowHandler) CancelOrder(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.CancelOrderCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.OrderID = orderID
+	cmd.UserID = auth.UserID(c)
+
+	escrow, err := h.escrowService.CancelOrder(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, escrow)
+}
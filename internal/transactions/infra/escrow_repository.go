@@ -0,0 +1,85 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// EscrowGORMRepository implements EscrowRepository using GORM
+type EscrowGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewEscrowGORMRepository creates a new escrow repository
+func NewEscrowGORMRepository(db *gorm.DB) *EscrowGORMRepository {
+	return &EscrowGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves an escrow to the database
+func (r *EscrowGORMRepository) Save(escrow *domain.Escrow) error {
+	return db.ClassifyError(r.db.Create(escrow).Error)
+}
+
+// FindByOrder finds the escrow of an order
+func (r *EscrowGORMRepository) FindByOrder(orderID ids.OrderID) (*domain.Escrow, error) {
+	var escrow domain.Escrow
+	err := r.db.First(&escrow, "order_id = ?", orderID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("escrow not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &escrow, nil
+}
+
+// FindDueForRelease finds held escrows past their release time and not
+// frozen, earliest first
+func (r *EscrowGORMRepository) FindDueForRelease(now time.Time, limit int) ([]*domain.Escrow, error) {
+	var escrows []*domain.Escrow
+	err := r.db.Where("status = ? AND release_due_at <= ? AND frozen_at IS NULL", domain.EscrowStatusHeld, now).
+		Order("release_due_at").
+		Limit(limit).
+		Find(&escrows).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return escrows, nil
+}
+
+// FindUnpaid finds a seller's released escrows not yet in a payout nor
+// frozen, earliest released first
+func (r *EscrowGORMRepository) FindUnpaid(sellerID ids.UserID, limit int) ([]*domain.Escrow, error) {
+	var escrows []*domain.Escrow
+	err := r.db.Where("seller_id = ? AND status = ? AND payout_id IS NULL AND frozen_at IS NULL", sellerID, domain.EscrowStatusReleased).
+		Order("released_at").
+		Limit(limit).
+		Find(&escrows).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return escrows, nil
+}
+
+// IsPaidOut checks if the released funds of an order are claimed by a payout
+func (r *EscrowGORMRepository) IsPaidOut(orderID ids.OrderID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Escrow{}).Where("order_id = ? AND payout_id IS NOT NULL", orderID).Count(&count).Error
+	if err != nil {
+		return false, db.ClassifyError(err)
+	}
+	return count > 0, nil
+}
+
+// Update updates an escrow in the database
+func (r *EscrowGORMRepository) Update(escrow *domain.Escrow) error {
+	return db.ClassifyError(r.db.Save(escrow).Error)
+}
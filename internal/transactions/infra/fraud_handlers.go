@@ -0,0 +1,215 @@
+package infra
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminFraudHandler handles HTTP requests for reviewing orders held as likely
+// fraud and managing blocked phone numbers
+type AdminFraudHandler struct {
+	fraudService *app.FraudService
+	orderService *app.OrderService
+}
+
+// NewAdminFraudHandler creates a new admin fraud handler
+func NewAdminFraudHandler(fraudService *app.FraudService, orderService *app.OrderService) *AdminFraudHandler {
+	return &AdminFraudHandler{
+		fraudService: fraudService,
+		orderService: orderService,
+	}
+}
+
+// RegisterRoutes registers admin fraud routes. The group must be protected
+// by the admin role.
+func (h *AdminFraudHandler) RegisterRoutes(r *gin.RouterGroup) {
+	fraud := r.Group("/fraud")
+	{
+		fraud.GET("/orders", h.ListChecks)
+		fraud.GET("/orders/:id", h.GetCheck)
+		fraud.POST("/orders/:id/release", h.ReleaseOrder)
+		fraud.POST("/orders/:id/decline", h.DeclineOrder)
+		fraud.GET("/blocked-phones", h.ListBlockedPhones)
+		fraud.POST("/blocked-phones", h.BlockPhone)
+		fraud.DELETE("/blocked-phones/:phone", h.UnblockPhone)
+	}
+}
+
+// ListChecks handles listing fraud checks, the orders awaiting review by default
+func (h *AdminFraudHandler) ListChecks(c *gin.Context) {
+	var query app.FraudChecksQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	checks, err := h.fraudService.ListChecks(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, checks)
+}
+
+// GetCheck handles retrieving the fraud check of an order, with the signals
+// it was scored on
+func (h *AdminFraudHandler) GetCheck(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	check, err := h.fraudService.GetCheck(c.Request.Context(), orderID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+// ReleaseOrder handles letting an order held for review through to payment.
+// The body is optional: without one the review has no note.
+func (h *AdminFraudHandler) ReleaseOrder(c *gin.Context) {
+	var cmd app.ReleaseOrderCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil && !stderrors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+	cmd.OrderID = orderID
+	cmd.AdminID = auth.UserID(c)
+
+	order, err := h.orderService.ReleaseHeldOrder(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// DeclineOrder handles cancelling an order held for review
+func (h *AdminFraudHandler) DeclineOrder(c *gin.Context) {
+	var cmd app.DeclineOrderCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+	cmd.OrderID = orderID
+	cmd.AdminID = auth.UserID(c)
+
+	order, err := h.orderService.DeclineHeldOrder(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ListBlockedPhones handles listing blocked phone numbers
+func (h *AdminFraudHandler) ListBlockedPhones(c *gin.Context) {
+	var query app.BlockedPhonesQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	phones, err := h.fraudService.ListBlockedPhones(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, phones)
+}
+
+// BlockPhone handles blocking a phone number used for fraud
+func (h *AdminFraudHandler) BlockPhone(c *gin.Context) {
+	var cmd app.BlockPhoneCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.AdminID = auth.UserID(c)
+
+	phone, err := h.fraudService.BlockPhone(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, phone)
+}
+
+// UnblockPhone handles unblocking a phone number
+func (h *AdminFraudHandler) UnblockPhone(c *gin.Context) {
+	if err := h.fraudService.UnblockPhone(c.Request.Context(), c.Param("phone")); err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "phone number unblocked"})
+}
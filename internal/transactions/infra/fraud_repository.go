@@ -0,0 +1,137 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// FraudGORMRepository implements FraudRepository using GORM
+type FraudGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewFraudGORMRepository creates a new fraud repository
+func NewFraudGORMRepository(db *gorm.DB) *FraudGORMRepository {
+	return &FraudGORMRepository{
+		db: db,
+	}
+}
+
+// SaveCheck saves a fraud check to the database
+func (r *FraudGORMRepository) SaveCheck(check *domain.FraudCheck) error {
+	return db.ClassifyError(r.db.Create(check).Error)
+}
+
+// FindCheckByOrder finds the fraud check of an order
+func (r *FraudGORMRepository) FindCheckByOrder(orderID ids.OrderID) (*domain.FraudCheck, error) {
+	var check domain.FraudCheck
+	err := r.db.First(&check, "order_id = ?", orderID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("fraud check not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &check, nil
+}
+
+// UpdateCheck updates a fraud check in the database
+func (r *FraudGORMRepository) UpdateCheck(check *domain.FraudCheck) error {
+	return db.ClassifyError(r.db.Save(check).Error)
+}
+
+// FindChecks finds a page of checks with a decision, oldest first. Held
+// checks whose review was due before now are left out.
+func (r *FraudGORMRepository) FindChecks(decision domain.FraudDecision, now time.Time, limit, offset int) ([]*domain.FraudCheck, int64, error) {
+	query := r.db.Model(&domain.FraudCheck{}).Where("decision = ?", decision)
+	if decision == domain.FraudDecisionHeld {
+		query = query.Where("review_due_at > ?", now)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var checks []*domain.FraudCheck
+	err := query.Order("created_at").
+		Limit(limit).
+		Offset(offset).
+		Find(&checks).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return checks, total, nil
+}
+
+// FindChecksSince finds the checks made since the given time, most recent first
+func (r *FraudGORMRepository) FindChecksSince(since time.Time, limit int) ([]*domain.FraudCheck, error) {
+	var checks []*domain.FraudCheck
+	err := r.db.Where("created_at >= ?", since).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&checks).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return checks, nil
+}
+
+// BlockPhone saves a blocked phone number. Blocking a number twice is a
+// conflict.
+func (r *FraudGORMRepository) BlockPhone(phone *domain.BlockedPhone) error {
+	err := db.ClassifyError(r.db.Create(phone).Error)
+	if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeConflict {
+		return errors.ConflictError("the phone number is blocked already")
+	}
+	return err
+}
+
+// UnblockPhone deletes a blocked phone number
+func (r *FraudGORMRepository) UnblockPhone(phoneNumber string) error {
+	result := r.db.Delete(&domain.BlockedPhone{}, "phone_number = ?", phoneNumber)
+	if result.Error != nil {
+		return db.ClassifyError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NotFoundError("the phone number is not blocked")
+	}
+	return nil
+}
+
+// FindBlockedPhones finds a page of blocked phone numbers, most recently
+// blocked first
+func (r *FraudGORMRepository) FindBlockedPhones(limit, offset int) ([]*domain.BlockedPhone, int64, error) {
+	var total int64
+	if err := r.db.Model(&domain.BlockedPhone{}).Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var phones []*domain.BlockedPhone
+	err := r.db.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&phones).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return phones, total, nil
+}
+
+// AnyPhoneBlocked checks if any of the phone numbers is blocked
+func (r *FraudGORMRepository) AnyPhoneBlocked(phoneNumbers []string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.BlockedPhone{}).
+		Where("phone_number IN ?", phoneNumbers).
+		Count(&count).Error
+	if err != nil {
+		return false, db.ClassifyError(err)
+	}
+	return count > 0, nil
+}
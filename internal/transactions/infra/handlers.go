@@ -0,0 +1,228 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderHandler handles HTTP requests for buyers' orders
+type OrderHandler struct {
+	orderService *app.OrderService
+}
+
+// NewOrderHandler creates a new order handler
+func NewOrderHandler(orderService *app.OrderService) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+	}
+}
+
+// RegisterRoutes registers order routes. The group must be protected by
+// RequireAuth.
+func (h *OrderHandler) RegisterRoutes(r *gin.RouterGroup) {
+	orders := r.Group("/orders")
+	{
+		orders.POST("", h.CreateOrder)
+		orders.POST("/:id/pay", h.PayOrder)
+		orders.POST("/:id/pay/confirm", h.ConfirmPayment)
+		orders.POST("/:id/resume", h.ResumeCheckout)
+		orders.POST("/:id/fulfillment", h.AdvanceFulfillment)
+	}
+}
+
+// CreateOrder handles placing an order for a listing
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var cmd app.CreateOrderCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.BuyerID = auth.UserID(c)
+
+	order, err := h.orderService.CreateOrder(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// PayOrder handles starting the payment of an order. The buyer approves it
+// on their phone, completes it on the returned checkout page or enters a
+// code, as the response's action says, so the response is 202 Accepted.
+func (h *OrderHandler) PayOrder(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.PayOrderCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.OrderID = orderID
+	cmd.BuyerID = auth.UserID(c)
+
+	payment, err := h.orderService.PayOrder(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, payment)
+}
+
+// ConfirmPayment handles a buyer entering the code their order's payment
+// asked for, such as a Vodafone Cash voucher
+func (h *OrderHandler) ConfirmPayment(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.ConfirmPaymentCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.OrderID = orderID
+	cmd.BuyerID = auth.UserID(c)
+
+	payment, err := h.orderService.ConfirmPayment(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, payment)
+}
+
+// ResumeCheckout handles a buyer returning to pay for an abandoned order
+func (h *OrderHandler) ResumeCheckout(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	order, err := h.orderService.ResumeCheckout(c.Request.Context(), orderID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// AdvanceFulfillment handles the seller shipping an order, or either party
+// marking it delivered
+func (h *OrderHandler) AdvanceFulfillment(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.AdvanceFulfillmentCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.OrderID = orderID
+	cmd.UserID = auth.UserID(c)
+
+	order, err := h.orderService.AdvanceFulfillment(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// AdminOrderHandler handles HTTP requests for order reporting
+type AdminOrderHandler struct {
+	orderService *app.OrderService
+}
+
+// NewAdminOrderHandler creates a new admin order handler
+func NewAdminOrderHandler(orderService *app.OrderService) *AdminOrderHandler {
+	return &AdminOrderHandler{
+		orderService: orderService,
+	}
+}
+
+// RegisterRoutes registers admin order routes. The group must be protected
+// by the admin role.
+func (h *AdminOrderHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/orders/abandonment", h.GetAbandonmentStats)
+}
+
+// GetAbandonmentStats handles reporting checkout abandonment per category
+func (h *AdminOrderHandler) GetAbandonmentStats(c *gin.Context) {
+	var query app.AbandonmentStatsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	stats, err := h.orderService.AbandonmentStats(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": stats})
+}
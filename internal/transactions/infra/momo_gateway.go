@@ -0,0 +1,117 @@
+package infra
+
+import (
+	"context"
+	stderrors "errors"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/logger"
+	"dongome/pkg/momo"
+
+	"go.uber.org/zap"
+)
+
+// MoMoPaymentGateway implements PaymentGateway and PayoutGateway with MTN
+// MoMo: wallet top-ups go through the Collection API, and withdrawals and
+// payouts through the Disbursement API
+type MoMoPaymentGateway struct {
+	client      *momo.Client
+	callbackURL string
+}
+
+// NewMoMoPaymentGateway creates a payment gateway reporting results to callbackURL
+func NewMoMoPaymentGateway(client *momo.Client, callbackURL string) *MoMoPaymentGateway {
+	return &MoMoPaymentGateway{
+		client:      client,
+		callbackURL: callbackURL,
+	}
+}
+
+// RequestPayment asks the payer to approve the payment on their phone.
+// Failures are reported as payments being unavailable.
+func (g *MoMoPaymentGateway) RequestPayment(ctx context.Context, payment app.PaymentRequest) (string, error) {
+	reference, err := g.client.RequestToPay(ctx, momo.PaymentRequest{
+		ExternalID:   payment.Reference,
+		Amount:       payment.Amount,
+		Payer:        payment.Payer,
+		PayerMessage: payment.Description,
+		PayeeNote:    payment.Description,
+		CallbackURL:  g.callbackURL,
+	})
+	if err != nil {
+		logger.Warn("Failed to request MoMo payment", zap.Error(err), logger.TraceID(ctx))
+		return "", errors.UnavailableError("mobile money payments are temporarily unavailable")
+	}
+	return reference, nil
+}
+
+// PaymentResult looks up how a payment went. Payments MoMo does not know of
+// never reached the payer, so they are reported as failed.
+func (g *MoMoPaymentGateway) PaymentResult(ctx context.Context, reference string) (*app.PaymentResult, error) {
+	payment, err := g.client.GetPayment(ctx, reference)
+	if stderrors.Is(err, momo.ErrNotFound) {
+		return &app.PaymentResult{Status: momo.StatusFailed, Reason: "NOT_FOUND"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &app.PaymentResult{
+		Status:        payment.Status,
+		Amount:        payment.Amount,
+		TransactionID: payment.FinancialTransactionID,
+		Reason:        payment.Reason,
+	}, nil
+}
+
+// VerifyAccount checks with the Disbursement API that a seller's payout
+// number is an active wallet. Failures are reported as payouts being
+// unavailable.
+func (g *MoMoPaymentGateway) VerifyAccount(ctx context.Context, phone string) (bool, error) {
+	active, err := g.client.AccountHolderActive(ctx, phone)
+	if err != nil {
+		logger.Warn("Failed to verify MoMo payout account", zap.Error(err), logger.TraceID(ctx))
+		return false, errors.UnavailableError("mobile money payouts are temporarily unavailable")
+	}
+	return active, nil
+}
+
+// SendPayout sends a seller's payout to their wallet through the
+// Disbursement API. Failures are reported as payouts being unavailable.
+func (g *MoMoPaymentGateway) SendPayout(ctx context.Context, payout app.PaymentRequest) (string, error) {
+	reference, err := g.client.Transfer(ctx, momo.TransferRequest{
+		ExternalID:   payout.Reference,
+		Amount:       payout.Amount,
+		Payee:        payout.Payer,
+		PayerMessage: payout.Description,
+		PayeeNote:    payout.Description,
+	})
+	if err != nil {
+		logger.Warn("Failed to send MoMo payout", zap.Error(err), logger.TraceID(ctx))
+		return "", errors.UnavailableError("mobile money payouts are temporarily unavailable")
+	}
+	return reference, nil
+}
+
+// PayoutResult looks up how a payout's transfer went
+func (g *MoMoPaymentGateway) PayoutResult(ctx context.Context, reference string) (*app.PaymentResult, error) {
+	return g.transferResult(ctx, reference)
+}
+
+// transferResult looks up how a transfer went. Transfers MoMo does not know
+// of never left, so they are reported as failed.
+func (g *MoMoPaymentGateway) transferResult(ctx context.Context, reference string) (*app.PaymentResult, error) {
+	transfer, err := g.client.GetTransfer(ctx, reference)
+	if stderrors.Is(err, momo.ErrNotFound) {
+		return &app.PaymentResult{Status: momo.StatusFailed, Reason: "NOT_FOUND"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &app.PaymentResult{
+		Status:        transfer.Status,
+		Amount:        transfer.Amount,
+		TransactionID: transfer.FinancialTransactionID,
+		Reason:        transfer.Reason,
+	}, nil
+}
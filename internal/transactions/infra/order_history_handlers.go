@@ -0,0 +1,117 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderHistoryHandler handles HTTP requests for buyers' and sellers' order
+// history and for an order's timeline
+type OrderHistoryHandler struct {
+	historyService *app.OrderHistoryService
+}
+
+// NewOrderHistoryHandler creates a new order history handler
+func NewOrderHistoryHandler(historyService *app.OrderHistoryService) *OrderHistoryHandler {
+	return &OrderHistoryHandler{
+		historyService: historyService,
+	}
+}
+
+// RegisterRoutes registers order history routes. The group must be
+// protected by RequireAuth.
+func (h *OrderHistoryHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/users/:id/orders", h.ListBuyerOrders)
+	r.GET("/sellers/:id/orders", h.ListSellerOrders)
+	r.GET("/orders/:id", h.GetOrder)
+}
+
+// ListBuyerOrders handles listing the orders the caller placed. The :id
+// path parameter must be "me" or the caller's own ID.
+func (h *OrderHistoryHandler) ListBuyerOrders(c *gin.Context) {
+	buyerID := auth.UserID(c)
+	if id := c.Param("id"); id != "me" && id != buyerID.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": i18n.Localize(c, "you can only see your own orders"), "code": errors.ErrCodeForbidden})
+		return
+	}
+
+	var query app.OrderHistoryQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	history, err := h.historyService.ListBuyerOrders(c.Request.Context(), buyerID, query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// ListSellerOrders handles listing the orders placed for the caller's
+// listings. The :id path parameter must be "me" or the caller's own ID.
+func (h *OrderHistoryHandler) ListSellerOrders(c *gin.Context) {
+	sellerID := auth.UserID(c)
+	if id := c.Param("id"); id != "me" && id != sellerID.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": i18n.Localize(c, "you can only see your own orders"), "code": errors.ErrCodeForbidden})
+		return
+	}
+
+	var query app.OrderHistoryQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	history, err := h.historyService.ListSellerOrders(c.Request.Context(), sellerID, query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetOrder handles retrieving one of the caller's orders, as its buyer or
+// its seller, with every step it went through
+func (h *OrderHistoryHandler) GetOrder(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	order, err := h.historyService.GetOrder(c.Request.Context(), orderID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
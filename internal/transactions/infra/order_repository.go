@@ -0,0 +1,150 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// OrderGORMRepository implements OrderRepository using GORM
+type OrderGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderGORMRepository creates a new order repository
+func NewOrderGORMRepository(db *gorm.DB) *OrderGORMRepository {
+	return &OrderGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves an order to the database
+func (r *OrderGORMRepository) Save(order *domain.Order) error {
+	return db.ClassifyError(r.db.Create(order).Error)
+}
+
+// FindByID finds an order by ID
+func (r *OrderGORMRepository) FindByID(id ids.OrderID) (*domain.Order, error) {
+	var order domain.Order
+	err := r.db.First(&order, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("order not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &order, nil
+}
+
+// FindExpiredPending finds unpaid orders past their payment deadline with no
+// payment awaiting approval, oldest first
+func (r *OrderGORMRepository) FindExpiredPending(now time.Time, limit int) ([]*domain.Order, error) {
+	var orders []*domain.Order
+	err := r.db.Where("status = ? AND payment_due_at < ? AND payment_reference = ''", domain.OrderStatusPendingPayment, now).
+		Order("payment_due_at").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return orders, nil
+}
+
+// FindAwaitingPaymentApproval finds unpaid orders whose payment was requested
+// before the given time, earliest request first
+func (r *OrderGORMRepository) FindAwaitingPaymentApproval(requestedBefore time.Time, limit int) ([]*domain.Order, error) {
+	var orders []*domain.Order
+	err := r.db.Where("status = ? AND payment_reference <> '' AND payment_requested_at < ?", domain.OrderStatusPendingPayment, requestedBefore).
+		Order("payment_requested_at").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return orders, nil
+}
+
+// Update updates an order in the database if it is still at the version it
+// was read at, so two concurrent changes, such as a wallet payment and a
+// MoMo callback, cannot both apply
+func (r *OrderGORMRepository) Update(order *domain.Order) error {
+	read := order.Version
+	order.Version++
+	result := r.db.Model(order).Where("version = ?", read).Select("*").Updates(order)
+	if result.Error != nil {
+		order.Version = read
+		return db.ClassifyError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		order.Version = read
+		return errors.ConflictError("the order was changed meanwhile")
+	}
+	return nil
+}
+
+// CountByCategory counts orders created between from and to by category
+func (r *OrderGORMRepository) CountByCategory(from, to time.Time) ([]domain.CategoryOrderCount, error) {
+	var counts []domain.CategoryOrderCount
+	err := r.db.Model(&domain.Order{}).
+		Select("category_id, COUNT(*) AS orders, "+
+			"COUNT(*) FILTER (WHERE abandoned_at IS NOT NULL) AS abandoned, "+
+			"COUNT(*) FILTER (WHERE recovered) AS recovered").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("category_id").
+		Order("category_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return counts, nil
+}
+
+// FindHistory finds up to limit orders matching the filter, newest first.
+// Orders placed at the same time are ordered by ID, so pages never overlap.
+func (r *OrderGORMRepository) FindHistory(filter domain.OrderHistoryFilter, limit int) ([]*domain.Order, error) {
+	query := r.db.Model(&domain.Order{})
+	if filter.BuyerID != "" {
+		query = query.Where("buyer_id = ?", filter.BuyerID)
+	}
+	if filter.SellerID != "" {
+		query = query.Where("seller_id = ?", filter.SellerID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	if filter.After != nil {
+		query = query.Where("(created_at, id) < (?, ?)", filter.After.CreatedAt, filter.After.ID)
+	}
+
+	var orders []*domain.Order
+	err := query.Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return orders, nil
+}
+
+// CountByBuyerSince counts the orders a buyer placed since the given time
+func (r *OrderGORMRepository) CountByBuyerSince(buyerID ids.UserID, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Order{}).
+		Where("buyer_id = ? AND created_at >= ?", buyerID, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, db.ClassifyError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,162 @@
+package infra
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/logger"
+	"dongome/pkg/payments"
+	"dongome/pkg/webhookauth"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PaymentCallbackHandler handles payment providers' webhooks
+type PaymentCallbackHandler struct {
+	orderService *app.OrderService
+	claimService *app.ClaimService
+	providers    app.PaymentProviders
+	verifier     *webhookauth.Verifier
+	momo         webhookauth.Provider
+}
+
+// NewPaymentCallbackHandler creates a new payment callback handler. MoMo
+// callbacks are authenticated with momo's signature scheme; other providers
+// sign their webhooks themselves, and verifier records their bodies so they
+// cannot be replayed. Chargebacks the providers report are handled by
+// claimService.
+func NewPaymentCallbackHandler(orderService *app.OrderService, claimService *app.ClaimService, providers app.PaymentProviders, verifier *webhookauth.Verifier, momo webhookauth.Provider) *PaymentCallbackHandler {
+	return &PaymentCallbackHandler{
+		orderService: orderService,
+		claimService: claimService,
+		providers:    providers,
+		verifier:     verifier,
+		momo:         momo,
+	}
+}
+
+// Payment webhook routes are not behind RequireAuth; each request is verified
+// by its signature instead. Each provider's route is registered once the
+// provider can verify its webhooks.
+
+// RegisterMoMoRoutes registers the Mobile Money callback route
+func (h *PaymentCallbackHandler) RegisterMoMoRoutes(r *gin.RouterGroup) {
+	r.POST("/payments/momo/callback", h.verifier.Middleware(h.momo), h.MoMoCallback)
+}
+
+// RegisterPaystackRoutes registers the Paystack webhook route
+func (h *PaymentCallbackHandler) RegisterPaystackRoutes(r *gin.RouterGroup) {
+	r.POST("/payments/paystack/webhook", h.PaystackWebhook)
+}
+
+// RegisterFlutterwaveRoutes registers the Flutterwave webhook route
+func (h *PaymentCallbackHandler) RegisterFlutterwaveRoutes(r *gin.RouterGroup) {
+	r.POST("/payments/flutterwave/webhook", h.FlutterwaveWebhook)
+}
+
+// MoMoCallback handles a Mobile Money payment result. Its route's middleware
+// has already refused replays.
+func (h *PaymentCallbackHandler) MoMoCallback(c *gin.Context) {
+	h.handleWebhook(c, payments.ProviderMoMo, false)
+}
+
+// PaystackWebhook handles a Paystack event
+func (h *PaymentCallbackHandler) PaystackWebhook(c *gin.Context) {
+	h.handleWebhook(c, payments.ProviderPaystack, true)
+}
+
+// FlutterwaveWebhook handles a Flutterwave event
+func (h *PaymentCallbackHandler) FlutterwaveWebhook(c *gin.Context) {
+	h.handleWebhook(c, payments.ProviderFlutterwave, true)
+}
+
+// handleWebhook has the named provider authenticate and normalize a webhook,
+// and applies the payment or chargeback event it reports. Other events are
+// acknowledged so the provider does not send them again. With recordBody the
+// body is recorded once authenticated, refusing replays of it; it is
+// forgotten if handling fails with a server error, so the provider's retry
+// is accepted.
+func (h *PaymentCallbackHandler) handleWebhook(c *gin.Context, name string, recordBody bool) {
+	provider, err := h.providers.Provider(name)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, webhookauth.MaxBodyBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.Localize(c, "the payment webhook is invalid")})
+		return
+	}
+	event, err := provider.ParseWebhook(c.Request.Header, body)
+	if stderrors.Is(err, payments.ErrIgnoredEvent) {
+		c.JSON(http.StatusOK, gin.H{"ignored": true})
+		return
+	}
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	if recordBody {
+		ctx := c.Request.Context()
+		if err := h.verifier.RecordBody(ctx, name, body); err != nil {
+			if stderrors.Is(err, webhookauth.ErrReplayed) {
+				logger.Warn("Webhook replay rejected", zap.String("provider", name), logger.HTTPRoute(c.FullPath()), logger.TraceID(ctx))
+				c.JSON(http.StatusConflict, gin.H{"error": i18n.Localize(c, err.Error()), "code": "REPLAYED"})
+				return
+			}
+			// Fail closed: without the replay cache a replay cannot be told apart
+			logger.Error("Webhook verification failed", zap.String("provider", name), logger.TraceID(ctx), zap.Error(err))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": i18n.Localize(c, "service temporarily unavailable")})
+			return
+		}
+		defer func() {
+			if c.Writer.Status() < http.StatusInternalServerError {
+				return
+			}
+			if err := h.verifier.ForgetBody(ctx, name, body); err != nil {
+				logger.Error("Failed to forget webhook body", zap.String("provider", name), logger.TraceID(ctx), zap.Error(err))
+			}
+		}()
+	}
+
+	if event.Chargeback != nil {
+		claim, err := h.claimService.HandleChargeback(c.Request.Context(), *event)
+		if err != nil {
+			if domainErr, ok := err.(*errors.DomainError); ok {
+				c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"claim_id": claim.ID, "status": claim.Status})
+		return
+	}
+
+	order, err := h.orderService.HandlePaymentEvent(c.Request.Context(), *event)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"order_id": order.ID, "status": order.Status})
+}
@@ -0,0 +1,134 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PayoutHandler handles HTTP requests for sellers' payout accounts and payouts
+type PayoutHandler struct {
+	payoutService *app.PayoutService
+}
+
+// NewPayoutHandler creates a new payout handler
+func NewPayoutHandler(payoutService *app.PayoutService) *PayoutHandler {
+	return &PayoutHandler{
+		payoutService: payoutService,
+	}
+}
+
+// RegisterRoutes registers payout routes. The group must be protected by
+// RequireAuth.
+func (h *PayoutHandler) RegisterRoutes(r *gin.RouterGroup) {
+	payouts := r.Group("/payouts")
+	{
+		payouts.GET("/account", h.GetAccount)
+		payouts.PUT("/account", h.RegisterAccount)
+		payouts.PUT("/account/schedule", h.ChangeSchedule)
+		payouts.GET("", h.ListPayouts)
+		payouts.GET("/:id", h.GetPayout)
+	}
+}
+
+// GetAccount handles retrieving the caller's payout account
+func (h *PayoutHandler) GetAccount(c *gin.Context) {
+	account, err := h.payoutService.GetAccount(c.Request.Context(), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// RegisterAccount handles a seller registering the Mobile Money number they
+// are paid to, or moving payouts to another number
+func (h *PayoutHandler) RegisterAccount(c *gin.Context) {
+	var cmd app.RegisterPayoutAccountCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.SellerID = auth.UserID(c)
+
+	account, err := h.payoutService.RegisterAccount(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// ChangeSchedule handles a seller changing how often they are paid
+func (h *PayoutHandler) ChangeSchedule(c *gin.Context) {
+	var cmd app.ChangePayoutScheduleCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.SellerID = auth.UserID(c)
+
+	account, err := h.payoutService.ChangeSchedule(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// ListPayouts handles listing the caller's payouts, newest first
+func (h *PayoutHandler) ListPayouts(c *gin.Context) {
+	var query app.PayoutsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	payouts, err := h.payoutService.ListPayouts(c.Request.Context(), auth.UserID(c), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, payouts)
+}
+
+// GetPayout handles retrieving one of the caller's payouts with the orders it paid out
+func (h *PayoutHandler) GetPayout(c *gin.Context) {
+	payout, err := h.payoutService.GetPayout(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, payout)
+}
@@ -0,0 +1,148 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// PayoutGORMRepository implements PayoutRepository using GORM. Escrows are
+// claimed by a payout through their payout_id column.
+type PayoutGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewPayoutGORMRepository creates a new payout repository
+func NewPayoutGORMRepository(db *gorm.DB) *PayoutGORMRepository {
+	return &PayoutGORMRepository{
+		db: db,
+	}
+}
+
+// SaveAccount saves a payout account to the database
+func (r *PayoutGORMRepository) SaveAccount(account *domain.PayoutAccount) error {
+	return db.ClassifyError(r.db.Create(account).Error)
+}
+
+// FindAccount finds the payout account of a seller
+func (r *PayoutGORMRepository) FindAccount(sellerID ids.UserID) (*domain.PayoutAccount, error) {
+	var account domain.PayoutAccount
+	err := r.db.First(&account, "seller_id = ?", sellerID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("payout account not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &account, nil
+}
+
+// FindAccountsDue finds verified accounts whose next payout is due and whose
+// sellers have released funds not yet paid out nor frozen, earliest due first
+func (r *PayoutGORMRepository) FindAccountsDue(now time.Time, limit int) ([]*domain.PayoutAccount, error) {
+	var accounts []*domain.PayoutAccount
+	err := r.db.Where("verified_at IS NOT NULL AND next_payout_at <= ?", now).
+		Where("EXISTS (SELECT 1 FROM escrows WHERE escrows.seller_id = payout_accounts.seller_id AND escrows.status = ? AND escrows.payout_id IS NULL AND escrows.frozen_at IS NULL)", domain.EscrowStatusReleased).
+		Order("next_payout_at").
+		Limit(limit).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return accounts, nil
+}
+
+// UpdateAccount updates a payout account in the database
+func (r *PayoutGORMRepository) UpdateAccount(account *domain.PayoutAccount) error {
+	return db.ClassifyError(r.db.Save(account).Error)
+}
+
+// Save saves a payout and claims its escrows in one transaction, so funds
+// are never in two payouts at once nor paid out while frozen
+func (r *PayoutGORMRepository) Save(payout *domain.Payout) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(payout).Error; err != nil {
+			return err
+		}
+
+		escrowIDs := payout.EscrowIDs()
+		result := tx.Table("escrows").
+			Where("id IN ? AND status = ? AND payout_id IS NULL AND frozen_at IS NULL", escrowIDs, domain.EscrowStatusReleased).
+			Update("payout_id", payout.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(escrowIDs)) {
+			return errors.ConflictError("the funds are already being paid out")
+		}
+		return nil
+	}))
+}
+
+// FindByID finds a payout by ID
+func (r *PayoutGORMRepository) FindByID(id string) (*domain.Payout, error) {
+	var payout domain.Payout
+	err := r.db.First(&payout, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("payout not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &payout, nil
+}
+
+// FindBySeller finds a page of a seller's payouts, newest first
+func (r *PayoutGORMRepository) FindBySeller(sellerID ids.UserID, limit, offset int) ([]*domain.Payout, int64, error) {
+	query := r.db.Model(&domain.Payout{}).Where("seller_id = ?", sellerID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var payouts []*domain.Payout
+	err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&payouts).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return payouts, total, nil
+}
+
+// FindPending finds pending payouts whose transfer was sent before the given
+// time, oldest first
+func (r *PayoutGORMRepository) FindPending(createdBefore time.Time, limit int) ([]*domain.Payout, error) {
+	var payouts []*domain.Payout
+	err := r.db.Where("status = ? AND reference <> '' AND created_at < ?", domain.PayoutStatusPending, createdBefore).
+		Order("created_at").
+		Limit(limit).
+		Find(&payouts).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return payouts, nil
+}
+
+// Update updates a payout in the database. The escrows of a failed payout
+// are freed in the same transaction.
+func (r *PayoutGORMRepository) Update(payout *domain.Payout) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(payout).Error; err != nil {
+			return err
+		}
+		if payout.Status != domain.PayoutStatusFailed {
+			return nil
+		}
+		return tx.Table("escrows").
+			Where("payout_id = ?", payout.ID).
+			Update("payout_id", nil).Error
+	}))
+}
@@ -0,0 +1,95 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefundHandler handles HTTP requests for refunds of orders
+type RefundHandler struct {
+	refundService *app.RefundService
+}
+
+// NewRefundHandler creates a new refund handler
+func NewRefundHandler(refundService *app.RefundService) *RefundHandler {
+	return &RefundHandler{
+		refundService: refundService,
+	}
+}
+
+// RegisterRoutes registers refund routes. The group must be protected by
+// RequireAuth.
+func (h *RefundHandler) RegisterRoutes(r *gin.RouterGroup) {
+	orders := r.Group("/orders")
+	{
+		orders.POST("/:id/refund", h.RefundOrder)
+		orders.GET("/:id/refunds", h.ListRefunds)
+	}
+}
+
+// RefundOrder handles a seller refunding part or all of an order. Requests
+// carry an Idempotency-Key header so retrying one never refunds twice.
+func (h *RefundHandler) RefundOrder(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.RefundOrderCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.OrderID = orderID
+	cmd.SellerID = auth.UserID(c)
+	cmd.IdempotencyKey = c.GetHeader("Idempotency-Key")
+
+	refund, err := h.refundService.RefundOrder(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, refund)
+}
+
+// ListRefunds handles listing the refunds of one of the caller's orders or sales
+func (h *RefundHandler) ListRefunds(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	refunds, err := h.refundService.ListRefunds(c.Request.Context(), orderID, auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refunds": refunds})
+}
@@ -0,0 +1,73 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// RefundGORMRepository implements RefundRepository using GORM
+type RefundGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewRefundGORMRepository creates a new refund repository
+func NewRefundGORMRepository(db *gorm.DB) *RefundGORMRepository {
+	return &RefundGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a refund to the database
+func (r *RefundGORMRepository) Save(refund *domain.Refund) error {
+	return db.ClassifyError(r.db.Create(refund).Error)
+}
+
+// FindByIdempotencyKey finds the refund of an order made for a request
+func (r *RefundGORMRepository) FindByIdempotencyKey(orderID ids.OrderID, key string) (*domain.Refund, error) {
+	var refund domain.Refund
+	err := r.db.First(&refund, "order_id = ? AND idempotency_key = ?", orderID, key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("refund not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &refund, nil
+}
+
+// FindByOrder finds the refunds of an order, oldest first
+func (r *RefundGORMRepository) FindByOrder(orderID ids.OrderID) ([]*domain.Refund, error) {
+	var refunds []*domain.Refund
+	err := r.db.Where("order_id = ?", orderID).
+		Order("created_at").
+		Find(&refunds).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return refunds, nil
+}
+
+// FindPending finds pending refunds whose transfer was sent before the given
+// time, oldest first
+func (r *RefundGORMRepository) FindPending(createdBefore time.Time, limit int) ([]*domain.Refund, error) {
+	var refunds []*domain.Refund
+	err := r.db.Where("status = ? AND reference <> '' AND created_at < ?", domain.RefundStatusPending, createdBefore).
+		Order("created_at").
+		Limit(limit).
+		Find(&refunds).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return refunds, nil
+}
+
+// Update updates a refund in the database
+func (r *RefundGORMRepository) Update(refund *domain.Refund) error {
+	return db.ClassifyError(r.db.Save(refund).Error)
+}
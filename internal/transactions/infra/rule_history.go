@@ -0,0 +1,78 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/transactions/app"
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/rules"
+
+	"gorm.io/gorm"
+)
+
+// PaymentWindowHistory replays checkout rules against past orders
+type PaymentWindowHistory struct {
+	db *gorm.DB
+}
+
+// NewPaymentWindowHistory creates a new payment window history
+func NewPaymentWindowHistory(db *gorm.DB) *PaymentWindowHistory {
+	return &PaymentWindowHistory{
+		db: db,
+	}
+}
+
+// Cases returns one case per order placed since the given time, most recent first
+func (h *PaymentWindowHistory) Cases(ctx context.Context, since time.Time, limit int) ([]rules.Case, error) {
+	var orders []*domain.Order
+	err := h.db.Where("created_at >= ?", since).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&orders).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+
+	cases := make([]rules.Case, 0, len(orders))
+	for _, order := range orders {
+		cases = append(cases, rules.Case{
+			Subject:    order.ID.String(),
+			OccurredAt: order.CreatedAt,
+			Facts:      app.PaymentWindowFacts(order.Amount),
+		})
+	}
+	return cases, nil
+}
+
+// FraudScoreHistory replays the fraud score rule against the signals of
+// past orders' fraud checks
+type FraudScoreHistory struct {
+	fraudRepo domain.FraudRepository
+}
+
+// NewFraudScoreHistory creates a new fraud score history
+func NewFraudScoreHistory(fraudRepo domain.FraudRepository) *FraudScoreHistory {
+	return &FraudScoreHistory{
+		fraudRepo: fraudRepo,
+	}
+}
+
+// Cases returns one case per order checked since the given time, most recent first
+func (h *FraudScoreHistory) Cases(ctx context.Context, since time.Time, limit int) ([]rules.Case, error) {
+	checks, err := h.fraudRepo.FindChecksSince(since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := make([]rules.Case, 0, len(checks))
+	for _, check := range checks {
+		cases = append(cases, rules.Case{
+			Subject:    check.OrderID.String(),
+			OccurredAt: check.CreatedAt,
+			Facts:      app.FraudScoreFacts(check.Signals),
+		})
+	}
+	return cases, nil
+}
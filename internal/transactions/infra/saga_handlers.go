@@ -0,0 +1,119 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminSagaHandler handles HTTP requests for inspecting and repairing checkout sagas
+type AdminSagaHandler struct {
+	orchestrator *app.SagaOrchestrator
+}
+
+// NewAdminSagaHandler creates a new admin saga handler
+func NewAdminSagaHandler(orchestrator *app.SagaOrchestrator) *AdminSagaHandler {
+	return &AdminSagaHandler{
+		orchestrator: orchestrator,
+	}
+}
+
+// RegisterRoutes registers admin saga routes. The group must be protected
+// by the admin role.
+func (h *AdminSagaHandler) RegisterRoutes(r *gin.RouterGroup) {
+	sagas := r.Group("/sagas")
+	{
+		sagas.GET("/stuck", h.ListStuckSagas)
+		sagas.GET("/:id", h.GetSaga)
+		sagas.POST("/:id/advance", h.AdvanceSaga)
+		sagas.POST("/:id/compensate", h.CompensateSaga)
+	}
+}
+
+// ListStuckSagas handles listing sagas past their step's deadline or whose compensation failed
+func (h *AdminSagaHandler) ListStuckSagas(c *gin.Context) {
+	var query app.StuckSagasQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	sagas, err := h.orchestrator.ListStuckSagas(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, sagas)
+}
+
+// GetSaga handles retrieving a saga with its history
+func (h *AdminSagaHandler) GetSaga(c *gin.Context) {
+	saga, err := h.orchestrator.GetSaga(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, saga)
+}
+
+// AdvanceSaga handles marking the running step of a saga as done
+func (h *AdminSagaHandler) AdvanceSaga(c *gin.Context) {
+	var cmd app.AdvanceSagaCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.SagaID = c.Param("id")
+	cmd.AdminID = auth.UserID(c)
+
+	saga, err := h.orchestrator.AdvanceSaga(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, saga)
+}
+
+// CompensateSaga handles undoing a saga, or retrying or skipping a failed compensation
+func (h *AdminSagaHandler) CompensateSaga(c *gin.Context) {
+	var cmd app.CompensateSagaCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.SagaID = c.Param("id")
+	cmd.AdminID = auth.UserID(c)
+
+	saga, err := h.orchestrator.CompensateSaga(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, saga)
+}
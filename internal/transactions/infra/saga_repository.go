@@ -0,0 +1,105 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// SagaGORMRepository implements SagaRepository using GORM
+type SagaGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewSagaGORMRepository creates a new checkout saga repository
+func NewSagaGORMRepository(db *gorm.DB) *SagaGORMRepository {
+	return &SagaGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a saga to the database
+func (r *SagaGORMRepository) Save(saga *domain.CheckoutSaga) error {
+	return db.ClassifyError(r.db.Create(saga).Error)
+}
+
+// FindByID finds a saga by ID
+func (r *SagaGORMRepository) FindByID(id string) (*domain.CheckoutSaga, error) {
+	return r.findOne("id = ?", id)
+}
+
+// FindByOrder finds the saga of an order
+func (r *SagaGORMRepository) FindByOrder(orderID ids.OrderID) (*domain.CheckoutSaga, error) {
+	return r.findOne("order_id = ?", orderID)
+}
+
+// FindTimedOut finds running sagas past their step's deadline, oldest first
+func (r *SagaGORMRepository) FindTimedOut(now time.Time, limit int) ([]*domain.CheckoutSaga, error) {
+	var sagas []*domain.CheckoutSaga
+	err := r.db.Where("status = ? AND deadline_at < ?", domain.SagaStatusRunning, now).
+		Order("deadline_at").
+		Limit(limit).
+		Find(&sagas).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return sagas, nil
+}
+
+// FindCompensating finds sagas with steps still to undo, least recently tried first
+func (r *SagaGORMRepository) FindCompensating(limit int) ([]*domain.CheckoutSaga, error) {
+	var sagas []*domain.CheckoutSaga
+	err := r.db.Where("status = ?", domain.SagaStatusCompensating).
+		Order("updated_at").
+		Limit(limit).
+		Find(&sagas).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return sagas, nil
+}
+
+// FindStuck finds sagas past their step's deadline or whose compensation failed, oldest first
+func (r *SagaGORMRepository) FindStuck(now time.Time, limit, offset int) ([]*domain.CheckoutSaga, int64, error) {
+	query := r.db.Model(&domain.CheckoutSaga{}).
+		Where("(status = ? AND deadline_at < ?) OR (status = ? AND attempts > 0)",
+			domain.SagaStatusRunning, now, domain.SagaStatusCompensating)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var sagas []*domain.CheckoutSaga
+	err := query.Order("created_at").
+		Limit(limit).
+		Offset(offset).
+		Find(&sagas).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return sagas, total, nil
+}
+
+// Update updates a saga in the database
+func (r *SagaGORMRepository) Update(saga *domain.CheckoutSaga) error {
+	return db.ClassifyError(r.db.Save(saga).Error)
+}
+
+// findOne finds the saga matching a condition
+func (r *SagaGORMRepository) findOne(query string, arg interface{}) (*domain.CheckoutSaga, error) {
+	var saga domain.CheckoutSaga
+	err := r.db.Where(query, arg).First(&saga).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("saga not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &saga, nil
+}
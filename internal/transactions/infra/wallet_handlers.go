@@ -0,0 +1,164 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/transactions/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WalletHandler handles HTTP requests for users' wallets
+type WalletHandler struct {
+	walletService *app.WalletService
+}
+
+// NewWalletHandler creates a new wallet handler
+func NewWalletHandler(walletService *app.WalletService) *WalletHandler {
+	return &WalletHandler{
+		walletService: walletService,
+	}
+}
+
+// RegisterRoutes registers wallet routes, and paying for orders from the
+// wallet. The group must be protected by RequireAuth.
+func (h *WalletHandler) RegisterRoutes(r *gin.RouterGroup) {
+	wallet := r.Group("/wallet")
+	{
+		wallet.GET("", h.GetWallet)
+		wallet.GET("/entries", h.ListEntries)
+		wallet.POST("/top-ups", h.TopUp)
+		wallet.POST("/withdrawals", h.Withdraw)
+		wallet.GET("/transfers/:id", h.GetTransfer)
+	}
+	r.POST("/orders/:id/pay/wallet", h.PayOrder)
+}
+
+// GetWallet handles retrieving the caller's wallet and its balance
+func (h *WalletHandler) GetWallet(c *gin.Context) {
+	wallet, err := h.walletService.GetWallet(c.Request.Context(), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// ListEntries handles listing the entries of the caller's wallet, newest first
+func (h *WalletHandler) ListEntries(c *gin.Context) {
+	var query app.WalletEntriesQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	entries, err := h.walletService.ListEntries(c.Request.Context(), auth.UserID(c), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// TopUp handles the caller paying money into their wallet by Mobile Money.
+// The wallet is credited once the payment is confirmed.
+func (h *WalletHandler) TopUp(c *gin.Context) {
+	var cmd app.WalletTransferCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.UserID = auth.UserID(c)
+
+	transfer, err := h.walletService.TopUp(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, transfer)
+}
+
+// Withdraw handles the caller sending money from their wallet to Mobile Money
+func (h *WalletHandler) Withdraw(c *gin.Context) {
+	var cmd app.WalletTransferCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	cmd.UserID = auth.UserID(c)
+
+	transfer, err := h.walletService.Withdraw(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, transfer)
+}
+
+// GetTransfer handles retrieving one of the caller's top-ups or withdrawals
+func (h *WalletHandler) GetTransfer(c *gin.Context) {
+	transfer, err := h.walletService.GetTransfer(c.Request.Context(), c.Param("id"), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// PayOrder handles a buyer paying for one of their orders from their wallet
+func (h *WalletHandler) PayOrder(c *gin.Context) {
+	orderID, err := ids.ParseOrderID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	order, err := h.walletService.PayOrder(c.Request.Context(), app.PayOrderFromWalletCommand{
+		OrderID: orderID,
+		BuyerID: auth.UserID(c),
+	})
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,198 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/transactions/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WalletGORMRepository implements WalletRepository using GORM. Balances are
+// changed with the wallet's row locked (SELECT ... FOR UPDATE), so concurrent
+// changes to a wallet are applied one after the other.
+type WalletGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewWalletGORMRepository creates a new wallet repository
+func NewWalletGORMRepository(db *gorm.DB) *WalletGORMRepository {
+	return &WalletGORMRepository{
+		db: db,
+	}
+}
+
+// Open saves the wallet unless its user already has one, and returns the
+// user's wallet
+func (r *WalletGORMRepository) Open(wallet *domain.Wallet) (*domain.Wallet, error) {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(wallet).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+
+	var existing domain.Wallet
+	if err := r.db.First(&existing, "user_id = ?", wallet.UserID).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return &existing, nil
+}
+
+// FindByUser finds a user's wallet
+func (r *WalletGORMRepository) FindByUser(userID ids.UserID) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	err := r.db.First(&wallet, "user_id = ?", userID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("wallet not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &wallet, nil
+}
+
+// Post changes the balance of a user's wallet and appends the entry in one
+// transaction, holding the wallet's row lock until it commits
+func (r *WalletGORMRepository) Post(userID ids.UserID, change func(wallet *domain.Wallet) (*domain.WalletEntry, error)) (*domain.WalletEntry, error) {
+	var entry *domain.WalletEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var wallet domain.Wallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&wallet, "user_id = ?", userID).Error
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFoundError("wallet not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		if entry, err = change(&wallet); err != nil {
+			return err
+		}
+		if err := appendEntry(tx, entry); err != nil {
+			return err
+		}
+		return tx.Save(&wallet).Error
+	})
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return entry, nil
+}
+
+// Transfer changes the balances of two wallets in one transaction. Both rows
+// are locked in the order of their users, so transfers the other way cannot
+// deadlock with it.
+func (r *WalletGORMRepository) Transfer(fromUserID, toUserID ids.UserID, move func(from, to *domain.Wallet) ([]*domain.WalletEntry, error)) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var wallets []*domain.Wallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id IN ?", []ids.UserID{fromUserID, toUserID}).
+			Order("user_id").
+			Find(&wallets).Error
+		if err != nil {
+			return err
+		}
+		var from, to *domain.Wallet
+		for _, wallet := range wallets {
+			switch wallet.UserID {
+			case fromUserID:
+				from = wallet
+			case toUserID:
+				to = wallet
+			}
+		}
+		if from == nil || to == nil {
+			return errors.NotFoundError("wallet not found")
+		}
+
+		entries, err := move(from, to)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := appendEntry(tx, entry); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(from).Error; err != nil {
+			return err
+		}
+		return tx.Save(to).Error
+	})
+	return db.ClassifyError(err)
+}
+
+// appendEntry appends an entry to its wallet's log unless an entry of its
+// kind was already posted for its reference
+func appendEntry(tx *gorm.DB, entry *domain.WalletEntry) error {
+	var posted int64
+	err := tx.Model(&domain.WalletEntry{}).
+		Where("wallet_id = ? AND kind = ? AND reference = ?", entry.WalletID, entry.Kind, entry.Reference).
+		Count(&posted).Error
+	if err != nil {
+		return err
+	}
+	if posted > 0 {
+		return errors.ConflictError("the wallet entry was already posted")
+	}
+	return tx.Create(entry).Error
+}
+
+// FindEntries finds a page of a wallet's entries, newest first
+func (r *WalletGORMRepository) FindEntries(walletID string, limit, offset int) ([]*domain.WalletEntry, int64, error) {
+	query := r.db.Model(&domain.WalletEntry{}).Where("wallet_id = ?", walletID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var entries []*domain.WalletEntry
+	err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return entries, total, nil
+}
+
+// SaveTransfer saves a wallet transfer to the database
+func (r *WalletGORMRepository) SaveTransfer(transfer *domain.WalletTransfer) error {
+	return db.ClassifyError(r.db.Create(transfer).Error)
+}
+
+// FindTransfer finds a wallet transfer by ID
+func (r *WalletGORMRepository) FindTransfer(id string) (*domain.WalletTransfer, error) {
+	var transfer domain.WalletTransfer
+	err := r.db.First(&transfer, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("wallet transfer not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &transfer, nil
+}
+
+// FindPendingTransfers finds pending transfers whose Mobile Money transfer
+// was started before the given time, oldest first
+func (r *WalletGORMRepository) FindPendingTransfers(createdBefore time.Time, limit int) ([]*domain.WalletTransfer, error) {
+	var transfers []*domain.WalletTransfer
+	err := r.db.Where("status = ? AND reference <> '' AND created_at < ?", domain.WalletTransferStatusPending, createdBefore).
+		Order("created_at").
+		Limit(limit).
+		Find(&transfers).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return transfers, nil
+}
+
+// UpdateTransfer updates a wallet transfer in the database
+func (r *WalletGORMRepository) UpdateTransfer(transfer *domain.WalletTransfer) error {
+	return db.ClassifyError(r.db.Save(transfer).Error)
+}
@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// LocationValidator checks locations against the reference data owned by the listings context
+type LocationValidator interface {
+	ValidateLocation(ctx context.Context, location listings.Location) (listings.Location, error)
+}
+
+// AddressCommand represents the command to create or update an address
+type AddressCommand struct {
+	UserID         ids.UserID `json:"-"`
+	AddressID      string     `json:"-"`
+	Label          string     `json:"label"`
+	RecipientName  string     `json:"recipient_name" binding:"required"`
+	PhoneNumber    string     `json:"phone_number" binding:"required"`
+	Street         string     `json:"street"`
+	Landmark       string     `json:"landmark"`
+	DigitalAddress string     `json:"digital_address"`
+	Region         string     `json:"region" binding:"required"`
+	City           string     `json:"city" binding:"required"`
+	Area           string     `json:"area"`
+	IsDefault      bool       `json:"is_default"`
+}
+
+// AddressService handles address book use cases
+type AddressService struct {
+	addressRepo domain.AddressRepository
+	locations   LocationValidator
+}
+
+// NewAddressService creates a new address service
+func NewAddressService(addressRepo domain.AddressRepository, locations LocationValidator) *AddressService {
+	return &AddressService{
+		addressRepo: addressRepo,
+		locations:   locations,
+	}
+}
+
+// ListAddresses lists a user's addresses, default first
+func (s *AddressService) ListAddresses(ctx context.Context, userID ids.UserID) ([]*domain.Address, error) {
+	return s.addressRepo.FindByUser(userID)
+}
+
+// GetAddress retrieves one of a user's addresses
+func (s *AddressService) GetAddress(ctx context.Context, userID ids.UserID, addressID string) (*domain.Address, error) {
+	address, err := s.addressRepo.FindByID(addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	if address.UserID != userID {
+		return nil, errors.NotFoundError("address not found")
+	}
+
+	return address, nil
+}
+
+// CreateAddress adds an address to a user's address book. The first address
+// becomes the default.
+func (s *AddressService) CreateAddress(ctx context.Context, cmd AddressCommand) (*domain.Address, error) {
+	existing, err := s.addressRepo.FindByUser(cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= domain.MaxAddressesPerUser {
+		return nil, errors.ValidationError("address book is full")
+	}
+
+	details, err := s.details(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := domain.NewAddress(cmd.UserID, details)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.addressRepo.Save(address) }); err != nil {
+		return nil, err
+	}
+
+	if cmd.IsDefault || len(existing) == 0 {
+		if err := s.setDefault(ctx, address); err != nil {
+			return nil, err
+		}
+	}
+
+	return address, nil
+}
+
+// UpdateAddress changes one of a user's addresses
+func (s *AddressService) UpdateAddress(ctx context.Context, cmd AddressCommand) (*domain.Address, error) {
+	address, err := s.GetAddress(ctx, cmd.UserID, cmd.AddressID)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := s.details(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := address.Update(details); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.addressRepo.Update(address) }); err != nil {
+		return nil, err
+	}
+
+	if cmd.IsDefault && !address.IsDefault {
+		if err := s.setDefault(ctx, address); err != nil {
+			return nil, err
+		}
+	}
+
+	return address, nil
+}
+
+// SetDefaultAddress makes one of a user's addresses the default
+func (s *AddressService) SetDefaultAddress(ctx context.Context, userID ids.UserID, addressID string) (*domain.Address, error) {
+	address, err := s.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.setDefault(ctx, address); err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// DeleteAddress removes one of a user's addresses. When the default address is
+// removed, the most recently added remaining address becomes the default.
+func (s *AddressService) DeleteAddress(ctx context.Context, userID ids.UserID, addressID string) error {
+	address, err := s.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.addressRepo.Delete(address.ID) }); err != nil {
+		return err
+	}
+
+	if !address.IsDefault {
+		return nil
+	}
+
+	remaining, err := s.addressRepo.FindByUser(userID)
+	if err != nil || len(remaining) == 0 {
+		return err
+	}
+
+	next := remaining[0]
+	for _, candidate := range remaining[1:] {
+		if candidate.CreatedAt.After(next.CreatedAt) {
+			next = candidate
+		}
+	}
+	return s.setDefault(ctx, next)
+}
+
+// details validates the command's location and converts it into address details
+func (s *AddressService) details(ctx context.Context, cmd AddressCommand) (domain.AddressDetails, error) {
+	location, err := s.locations.ValidateLocation(ctx, listings.Location{
+		Region: cmd.Region,
+		City:   cmd.City,
+		Area:   cmd.Area,
+	})
+	if err != nil {
+		return domain.AddressDetails{}, err
+	}
+
+	return domain.AddressDetails{
+		Label:          cmd.Label,
+		RecipientName:  cmd.RecipientName,
+		PhoneNumber:    cmd.PhoneNumber,
+		Street:         cmd.Street,
+		Landmark:       cmd.Landmark,
+		DigitalAddress: cmd.DigitalAddress,
+		Location:       location,
+	}, nil
+}
+
+// setDefault makes the address the user's only default address
+func (s *AddressService) setDefault(ctx context.Context, address *domain.Address) error {
+	if err := db.WithRetry(ctx, func() error { return s.addressRepo.SetDefault(address.UserID, address.ID) }); err != nil {
+		return err
+	}
+	address.IsDefault = true
+	return nil
+}
@@ -0,0 +1,103 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAddressService() (*app.AddressService, *fakeAddressRepository) {
+	repo := &fakeAddressRepository{}
+	locations := &fakeLocationValidator{cities: map[string][]string{
+		"greater-accra": {"accra", "tema"},
+		"ashanti":       {"kumasi"},
+	}}
+	return app.NewAddressService(repo, locations), repo
+}
+
+func addressCommand(userID ids.UserID, region, city string) app.AddressCommand {
+	return app.AddressCommand{
+		UserID:        userID,
+		RecipientName: "Ama Mensah",
+		PhoneNumber:   "+233201234567",
+		Region:        region,
+		City:          city,
+	}
+}
+
+func TestAddressService_CreateAddress_FirstBecomesDefault(t *testing.T) {
+	service, _ := newAddressService()
+	ctx := context.Background()
+
+	first, err := service.CreateAddress(ctx, addressCommand("user-1", "greater-accra", "accra"))
+	require.NoError(t, err)
+	assert.True(t, first.IsDefault)
+
+	second, err := service.CreateAddress(ctx, addressCommand("user-1", "ashanti", "kumasi"))
+	require.NoError(t, err)
+	assert.False(t, second.IsDefault)
+	assert.True(t, first.IsDefault)
+}
+
+func TestAddressService_CreateAddress_RejectsUnknownLocation(t *testing.T) {
+	service, repo := newAddressService()
+
+	_, err := service.CreateAddress(context.Background(), addressCommand("user-1", "ashanti", "accra"))
+	assert.Error(t, err)
+	assert.Empty(t, repo.addresses)
+}
+
+func TestAddressService_CreateAddress_LimitsAddressBook(t *testing.T) {
+	service, _ := newAddressService()
+	ctx := context.Background()
+
+	for i := 0; i < domain.MaxAddressesPerUser; i++ {
+		_, err := service.CreateAddress(ctx, addressCommand("user-1", "greater-accra", "tema"))
+		require.NoError(t, err)
+	}
+
+	_, err := service.CreateAddress(ctx, addressCommand("user-1", "greater-accra", "tema"))
+	assert.Error(t, err)
+}
+
+func TestAddressService_SetDefaultAddress(t *testing.T) {
+	service, _ := newAddressService()
+	ctx := context.Background()
+
+	first, err := service.CreateAddress(ctx, addressCommand("user-1", "greater-accra", "accra"))
+	require.NoError(t, err)
+	second, err := service.CreateAddress(ctx, addressCommand("user-1", "ashanti", "kumasi"))
+	require.NoError(t, err)
+
+	_, err = service.SetDefaultAddress(ctx, "user-1", second.ID)
+	require.NoError(t, err)
+	assert.True(t, second.IsDefault)
+	assert.False(t, first.IsDefault)
+
+	// Other users cannot see or change the address
+	_, err = service.SetDefaultAddress(ctx, "user-2", first.ID)
+	assert.Error(t, err)
+}
+
+func TestAddressService_DeleteDefaultPromotesAnother(t *testing.T) {
+	service, _ := newAddressService()
+	ctx := context.Background()
+
+	first, err := service.CreateAddress(ctx, addressCommand("user-1", "greater-accra", "accra"))
+	require.NoError(t, err)
+	second, err := service.CreateAddress(ctx, addressCommand("user-1", "ashanti", "kumasi"))
+	require.NoError(t, err)
+
+	require.NoError(t, service.DeleteAddress(ctx, "user-1", first.ID))
+	assert.True(t, second.IsDefault)
+
+	addresses, err := service.ListAddresses(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Len(t, addresses, 1)
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// RecordSellerActivityCommand represents an event counting towards a seller's badges
+type RecordSellerActivityCommand struct {
+	EventID    string
+	SellerID   ids.UserID
+	Kind       domain.ActivityKind
+	Value      float64
+	OccurredAt time.Time
+}
+
+// BadgeService computes seller badges and trust levels from seller activity
+type BadgeService struct {
+	activityRepo domain.SellerActivityRepository
+	eventBus     events.EventBus
+}
+
+// NewBadgeService creates a new badge service
+func NewBadgeService(activityRepo domain.SellerActivityRepository, eventBus events.EventBus) *BadgeService {
+	return &BadgeService{
+		activityRepo: activityRepo,
+		eventBus:     eventBus,
+	}
+}
+
+// RecordActivity records a seller activity and re-evaluates the seller's
+// badges. Activity that was already recorded is ignored, and inquiries are
+// only kept for ranking.
+func (s *BadgeService) RecordActivity(ctx context.Context, cmd RecordSellerActivityCommand) error {
+	if cmd.EventID == "" || cmd.SellerID == "" {
+		return errors.ValidationError("event id and seller id are required")
+	}
+
+	activity := &domain.SellerActivity{
+		EventID:    cmd.EventID,
+		SellerID:   cmd.SellerID,
+		Kind:       cmd.Kind,
+		Value:      cmd.Value,
+		OccurredAt: cmd.OccurredAt,
+	}
+	if activity.OccurredAt.IsZero() {
+		activity.OccurredAt = time.Now()
+	}
+
+	var recorded bool
+	err := db.WithRetry(ctx, func() error {
+		var err error
+		recorded, err = s.activityRepo.Record(activity)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !recorded || cmd.Kind == domain.ActivityInquiry {
+		return nil
+	}
+
+	return s.RefreshSeller(ctx, cmd.SellerID)
+}
+
+// RefreshSeller re-evaluates a seller's badges and trust level. Users without
+// a seller profile are ignored.
+func (s *BadgeService) RefreshSeller(ctx context.Context, sellerID ids.UserID) error {
+	profiles, err := s.activityRepo.FindProfiles([]ids.UserID{sellerID})
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		return nil
+	}
+	return s.refresh(ctx, profiles[0], time.Now(), false)
+}
+
+// RefreshAll re-evaluates every seller in batches, so badges based on recent
+// activity expire when the activity ages out. Every seller's standing is
+// published, so copies kept by other contexts converge. It returns the
+// number of sellers processed.
+func (s *BadgeService) RefreshAll(ctx context.Context, batchSize int) (int, error) {
+	now := time.Now()
+	processed := 0
+	var afterID ids.UserID
+	for {
+		sellerIDs, err := s.activityRepo.FindSellerIDs(afterID, batchSize)
+		if err != nil {
+			return processed, err
+		}
+		if len(sellerIDs) == 0 {
+			return processed, nil
+		}
+
+		profiles, err := s.activityRepo.FindProfiles(sellerIDs)
+		if err != nil {
+			return processed, err
+		}
+		for _, profile := range profiles {
+			if err := s.refresh(ctx, profile, now, true); err != nil {
+				return processed, err
+			}
+			processed++
+		}
+
+		afterID = sellerIDs[len(sellerIDs)-1]
+	}
+}
+
+// SellerTrust returns the trust level, badges and store opening status of
+// the given sellers, keyed by user ID. Users without a seller profile are left out.
+func (s *BadgeService) SellerTrust(ctx context.Context, sellerIDs []ids.UserID) (map[ids.UserID]*listings.SellerTrust, error) {
+	profiles, err := s.activityRepo.FindProfiles(sellerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	trust := make(map[ids.UserID]*listings.SellerTrust, len(profiles))
+	for _, profile := range profiles {
+		badges := make([]string, len(profile.Badges))
+		for i, badge := range profile.Badges {
+			badges[i] = string(badge.Type)
+		}
+		trust[profile.UserID] = &listings.SellerTrust{
+			TrustLevel: string(profile.TrustLevel),
+			Badges:     badges,
+			OpenNow:    profile.OpenNow(now),
+		}
+	}
+	return trust, nil
+}
+
+// refresh recomputes a seller's statistics. It publishes a SellerRatingChanged
+// event when the seller's standing changes, or always when publishStanding
+// is set, and a SellerBadgesChanged event when the badges or trust level change.
+func (s *BadgeService) refresh(ctx context.Context, profile *domain.SellerProfile, now time.Time, publishStanding bool) error {
+	stats, err := s.activityRepo.Stats(profile.UserID, now.Add(-domain.FastResponderResponseWindow))
+	if err != nil {
+		return err
+	}
+
+	previousLevel := profile.TrustLevel
+	ratingChanged := profile.Rating != stats.Rating ||
+		profile.TotalReviews != stats.TotalReviews ||
+		profile.ResponseTimeMinutes != stats.ResponseTimeMinutes
+	awarded, revoked := profile.ApplyStats(stats, now)
+
+	if err := db.WithRetry(ctx, func() error { return s.activityRepo.UpdateProfile(profile) }); err != nil {
+		return err
+	}
+
+	badgesChanged := len(awarded) > 0 || len(revoked) > 0 || profile.TrustLevel != previousLevel
+	if ratingChanged || badgesChanged || publishStanding {
+		if err := s.publishStanding(ctx, profile, now); err != nil {
+			return err
+		}
+	}
+	if !badgesChanged {
+		return nil
+	}
+
+	// Publish SellerBadgesChanged event so other contexts can refresh the seller's standing
+	event, err := events.NewEvent(
+		domain.SellerBadgesChangedEvent,
+		profile.UserID.String(),
+		domain.SellerBadgesChanged{
+			SellerID:   profile.UserID,
+			Awarded:    awarded,
+			Revoked:    revoked,
+			TrustLevel: profile.TrustLevel,
+			Timestamp:  now,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// publishStanding publishes a SellerRatingChanged event with the seller's
+// current rating, response time, badges and trust level
+func (s *BadgeService) publishStanding(ctx context.Context, profile *domain.SellerProfile, now time.Time) error {
+	badges := make([]domain.BadgeType, len(profile.Badges))
+	for i, badge := range profile.Badges {
+		badges[i] = badge.Type
+	}
+
+	event, err := events.NewEvent(
+		domain.SellerRatingChangedEvent,
+		profile.UserID.String(),
+		domain.SellerRatingChanged{
+			SellerID:            profile.UserID,
+			Verified:            profile.VerificationStatus == domain.VerificationStatusApproved,
+			Rating:              profile.Rating,
+			TotalReviews:        profile.TotalReviews,
+			ResponseTimeMinutes: profile.ResponseTimeMinutes,
+			TrustLevel:          profile.TrustLevel,
+			Badges:              badges,
+			Timestamp:           now,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
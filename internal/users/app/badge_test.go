@@ -0,0 +1,142 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSeller(t *testing.T, email string) *domain.User {
+	t.Helper()
+	user := newActiveUser(t, email)
+	require.NoError(t, user.UpgradeToSeller("Ama Fabrics", "Kumasi", time.Now()))
+	return user
+}
+
+func TestBadgeService_RecordActivity(t *testing.T) {
+	seller := newSeller(t, "ama@example.com")
+	repo := newFakeSellerActivityRepository(seller.SellerProfile)
+	bus := &fakeEventBus{}
+	service := app.NewBadgeService(repo, bus)
+	ctx := context.Background()
+
+	for i := 0; i < domain.FastResponderMinResponses; i++ {
+		err := service.RecordActivity(ctx, app.RecordSellerActivityCommand{
+			EventID:  fmt.Sprintf("response-%d", i),
+			SellerID: seller.ID,
+			Kind:     domain.ActivityResponse,
+			Value:    15,
+		})
+		require.NoError(t, err)
+	}
+	assert.True(t, seller.SellerProfile.HasBadge(domain.BadgeFastResponder))
+
+	// The badge is announced once, and redelivered events are ignored
+	changed := bus.eventsOfType(domain.SellerBadgesChangedEvent)
+	require.Len(t, changed, 1)
+	require.NoError(t, service.RecordActivity(ctx, app.RecordSellerActivityCommand{
+		EventID:  "response-0",
+		SellerID: seller.ID,
+		Kind:     domain.ActivityResponse,
+		Value:    600,
+	}))
+	assert.True(t, seller.SellerProfile.HasBadge(domain.BadgeFastResponder))
+	assert.Len(t, bus.eventsOfType(domain.SellerBadgesChangedEvent), 1)
+
+	// Old responses no longer count once the window has passed
+	require.NoError(t, service.RecordActivity(ctx, app.RecordSellerActivityCommand{
+		EventID:    "response-old",
+		SellerID:   seller.ID,
+		Kind:       domain.ActivityResponse,
+		Value:      1,
+		OccurredAt: time.Now().Add(-2 * domain.FastResponderResponseWindow),
+	}))
+	assert.Equal(t, 15.0, seller.SellerProfile.ResponseTimeMinutes)
+
+	// Every change to the response time was published with the seller's standing
+	standing := bus.eventsOfType(domain.SellerRatingChangedEvent)
+	require.NotEmpty(t, standing)
+	var latest domain.SellerRatingChanged
+	require.NoError(t, events.ParseEventData(standing[len(standing)-1], &latest))
+	assert.Equal(t, 15.0, latest.ResponseTimeMinutes)
+	assert.Equal(t, []domain.BadgeType{domain.BadgeFastResponder}, latest.Badges)
+}
+
+func TestBadgeService_RefreshAllAndSellerTrust(t *testing.T) {
+	verified := newSeller(t, "ama@example.com")
+	verified.SellerProfile.VerificationStatus = domain.VerificationStatusApproved
+	pending := newSeller(t, "kofi@example.com")
+
+	repo := newFakeSellerActivityRepository(verified.SellerProfile, pending.SellerProfile)
+	bus := &fakeEventBus{}
+	service := app.NewBadgeService(repo, bus)
+	ctx := context.Background()
+
+	count, err := service.RefreshAll(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Len(t, bus.eventsOfType(domain.SellerRatingChangedEvent), 2, "every seller's standing is republished")
+
+	trust, err := service.SellerTrust(ctx, []ids.UserID{verified.ID, pending.ID, "unknown"})
+	require.NoError(t, err)
+	require.Len(t, trust, 2)
+	assert.Equal(t, []string{string(domain.BadgeVerified)}, trust[verified.ID].Badges)
+	assert.Equal(t, string(domain.TrustLevelNew), trust[verified.ID].TrustLevel)
+	assert.Empty(t, trust[pending.ID].Badges)
+}
+
+func TestBadgeService_RecordActivity_RequiresIDs(t *testing.T) {
+	service := app.NewBadgeService(newFakeSellerActivityRepository(), &fakeEventBus{})
+	err := service.RecordActivity(context.Background(), app.RecordSellerActivityCommand{Kind: domain.ActivitySale})
+	assert.Error(t, err)
+}
+
+func TestSellerEngagementSource_CountsInquiriesAndResponses(t *testing.T) {
+	seller := newSeller(t, "ama@example.com")
+	repo := newFakeSellerActivityRepository(seller.SellerProfile)
+	lastLogin := time.Now().Add(-time.Hour)
+	repo.lastLogins[seller.ID] = lastLogin
+	bus := &fakeEventBus{}
+	badges := app.NewBadgeService(repo, bus)
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, badges.RecordActivity(ctx, app.RecordSellerActivityCommand{
+			EventID:  fmt.Sprintf("inquiry-%d", i),
+			SellerID: seller.ID,
+			Kind:     domain.ActivityInquiry,
+		}))
+	}
+	require.NoError(t, badges.RecordActivity(ctx, app.RecordSellerActivityCommand{
+		EventID:  "response-0",
+		SellerID: seller.ID,
+		Kind:     domain.ActivityResponse,
+		Value:    30,
+	}))
+	require.NoError(t, badges.RecordActivity(ctx, app.RecordSellerActivityCommand{
+		EventID:    "inquiry-old",
+		SellerID:   seller.ID,
+		Kind:       domain.ActivityInquiry,
+		OccurredAt: time.Now().AddDate(0, 0, -60),
+	}))
+
+	source := app.NewSellerEngagementSource(repo)
+	engagement, err := source.SellerEngagement(ctx, "", 10, time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	require.Len(t, engagement, 1)
+	assert.Equal(t, seller.ID, engagement[0].SellerID)
+	assert.Equal(t, 4, engagement[0].Inquiries)
+	assert.Equal(t, 1, engagement[0].Responses)
+	require.NotNil(t, engagement[0].LastLoginAt)
+	assert.True(t, lastLogin.Equal(*engagement[0].LastLoginAt))
+	assert.InDelta(t, 0.25, engagement[0].ResponseRate(), 1e-9)
+}
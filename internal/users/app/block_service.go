@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// BlockUserCommand represents the command to block another user
+type BlockUserCommand struct {
+	UserID    ids.UserID `json:"user_id" binding:"required"`
+	Reason    string     `json:"reason"`
+	BlockerID ids.UserID `json:"-"`
+}
+
+// BlockService handles user-to-user blocking use cases
+type BlockService struct {
+	userRepo  domain.UserRepository
+	blockRepo domain.UserBlockRepository
+	eventBus  events.EventBus
+	rules     PolicyRules
+	clock     clock.Clock
+}
+
+// NewBlockService creates a new block service. If rules is given, users are
+// suspended automatically when AutoSuspendRule holds after they are blocked.
+// clk may be nil to use the system clock.
+func NewBlockService(userRepo domain.UserRepository, blockRepo domain.UserBlockRepository, eventBus events.EventBus, rules PolicyRules, clk clock.Clock) *BlockService {
+	return &BlockService{
+		userRepo:  userRepo,
+		blockRepo: blockRepo,
+		eventBus:  eventBus,
+		rules:     rules,
+		clock:     clock.OrSystem(clk),
+	}
+}
+
+// BlockUser blocks another user. Blocking an already blocked user is a no-op.
+func (s *BlockService) BlockUser(ctx context.Context, cmd BlockUserCommand) (*domain.UserBlock, error) {
+	blocked, err := s.userRepo.FindByID(cmd.UserID)
+	if err != nil {
+		return nil, errors.NotFoundError("user not found")
+	}
+
+	// Admins stay reachable so users can always contact support
+	if blocked.Role == domain.UserRoleAdmin {
+		return nil, errors.ValidationError("cannot block an administrator")
+	}
+
+	if existing, err := s.blockRepo.Find(cmd.BlockerID, blocked.ID); err == nil && existing != nil {
+		return existing, nil
+	}
+
+	block, err := domain.NewUserBlock(cmd.BlockerID, blocked.ID, cmd.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.blockRepo.Save(block) }); err != nil {
+		return nil, err
+	}
+
+	// Publish UserBlocked event so other contexts can hide conversations
+	event, err := events.NewEvent(
+		domain.UserBlockedEvent,
+		block.BlockerID.String(),
+		domain.UserBlocked{
+			BlockerID: block.BlockerID,
+			BlockedID: block.BlockedID,
+			Timestamp: s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	if err := s.autoSuspend(ctx, blocked); err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// autoSuspend suspends a user who was just blocked if AutoSuspendRule holds
+// for them. Users who are already suspended are left alone.
+func (s *BlockService) autoSuspend(ctx context.Context, user *domain.User) error {
+	if s.rules == nil || user.Status == domain.UserStatusSuspended {
+		return nil
+	}
+
+	now := s.clock.Now()
+	blocksReceived, err := s.blockRepo.CountBlockedSince(user.ID, now.Add(-AutoSuspendWindow))
+	if err != nil {
+		return err
+	}
+	if !s.rules.Bool(AutoSuspendRule, AutoSuspendFacts(blocksReceived, now.Sub(user.CreatedAt), user.EmailVerified)) {
+		return nil
+	}
+
+	if err := user.Suspend(autoSuspendReason, now); err != nil {
+		return err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return err
+	}
+
+	// Publish UserSuspended event; SuspendedBy is empty since no administrator
+	// took the decision
+	event, err := events.NewEvent(
+		domain.UserSuspendedEvent,
+		user.ID.String(),
+		domain.UserSuspended{
+			UserID:    user.ID,
+			Email:     user.Email,
+			Reason:    user.SuspensionReason,
+			Timestamp: now,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// UnblockUser lifts a block placed by the blocker
+func (s *BlockService) UnblockUser(ctx context.Context, blockerID, blockedID ids.UserID) error {
+	if _, err := s.blockRepo.Find(blockerID, blockedID); err != nil {
+		return err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.blockRepo.Delete(blockerID, blockedID) }); err != nil {
+		return err
+	}
+
+	// Publish UserUnblocked event
+	event, err := events.NewEvent(
+		domain.UserUnblockedEvent,
+		blockerID.String(),
+		domain.UserUnblocked{
+			BlockerID: blockerID,
+			BlockedID: blockedID,
+			Timestamp: s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// ListBlockedUsers lists the blocks placed by a user
+func (s *BlockService) ListBlockedUsers(ctx context.Context, blockerID ids.UserID) ([]*domain.UserBlock, error) {
+	return s.blockRepo.FindByBlocker(blockerID)
+}
+
+// IsBlocked checks if either user has blocked the other
+func (s *BlockService) IsBlocked(ctx context.Context, userID, otherUserID ids.UserID) (bool, error) {
+	return s.blockRepo.ExistsBetween(userID, otherUserID)
+}
+
+// EnsureNotBlocked is the enforcement hook for interactions between two users,
+// such as messages and offers. It fails when either user has blocked the other.
+func (s *BlockService) EnsureNotBlocked(ctx context.Context, senderID, recipientID ids.UserID) error {
+	blocked, err := s.IsBlocked(ctx, senderID, recipientID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return errors.ForbiddenError("you cannot interact with this user")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+	"dongome/pkg/events"
+	"dongome/pkg/rules"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockService_BlockAndUnblock(t *testing.T) {
+	buyer := newActiveUser(t, "buyer@example.com")
+	seller := newActiveUser(t, "seller@example.com")
+
+	bus := &fakeEventBus{}
+	service := app.NewBlockService(newFakeUserRepository(buyer, seller), &fakeUserBlockRepository{}, bus, nil, nil)
+	ctx := context.Background()
+
+	block, err := service.BlockUser(ctx, app.BlockUserCommand{BlockerID: buyer.ID, UserID: seller.ID, Reason: "spam"})
+	require.NoError(t, err)
+	assert.Equal(t, seller.ID, block.BlockedID)
+	assert.Len(t, bus.eventsOfType(domain.UserBlockedEvent), 1)
+
+	// Blocking twice keeps the original block
+	again, err := service.BlockUser(ctx, app.BlockUserCommand{BlockerID: buyer.ID, UserID: seller.ID})
+	require.NoError(t, err)
+	assert.Equal(t, block.ID, again.ID)
+	assert.Len(t, bus.eventsOfType(domain.UserBlockedEvent), 1)
+
+	// The block applies in both directions
+	assert.Error(t, service.EnsureNotBlocked(ctx, buyer.ID, seller.ID))
+	assert.Error(t, service.EnsureNotBlocked(ctx, seller.ID, buyer.ID))
+
+	blocks, err := service.ListBlockedUsers(ctx, buyer.ID)
+	require.NoError(t, err)
+	assert.Len(t, blocks, 1)
+
+	// Only the blocker can lift the block
+	assert.Error(t, service.UnblockUser(ctx, seller.ID, buyer.ID))
+	require.NoError(t, service.UnblockUser(ctx, buyer.ID, seller.ID))
+	assert.NoError(t, service.EnsureNotBlocked(ctx, seller.ID, buyer.ID))
+	assert.Len(t, bus.eventsOfType(domain.UserUnblockedEvent), 1)
+}
+
+func TestBlockService_BlockUser_Invalid(t *testing.T) {
+	user := newActiveUser(t, "user@example.com")
+	admin := newActiveUser(t, "admin@example.com")
+	admin.Role = domain.UserRoleAdmin
+
+	service := app.NewBlockService(newFakeUserRepository(user, admin), &fakeUserBlockRepository{}, &fakeEventBus{}, nil, nil)
+	ctx := context.Background()
+
+	_, err := service.BlockUser(ctx, app.BlockUserCommand{BlockerID: user.ID, UserID: user.ID})
+	assert.Error(t, err)
+
+	_, err = service.BlockUser(ctx, app.BlockUserCommand{BlockerID: user.ID, UserID: admin.ID})
+	assert.Error(t, err)
+
+	_, err = service.BlockUser(ctx, app.BlockUserCommand{BlockerID: user.ID, UserID: "missing"})
+	assert.Error(t, err)
+}
+
+func TestBlockService_AutoSuspend(t *testing.T) {
+	first := newActiveUser(t, "first@example.com")
+	second := newActiveUser(t, "second@example.com")
+	spammer := newActiveUser(t, "spammer@example.com")
+
+	bus := &fakeEventBus{}
+	policy := &fakePolicyRules{check: func(key string, facts rules.Facts) bool {
+		return key == app.AutoSuspendRule && facts["blocks_received"].(int) >= 2
+	}}
+	service := app.NewBlockService(newFakeUserRepository(first, second, spammer), &fakeUserBlockRepository{}, bus, policy, nil)
+	ctx := context.Background()
+
+	_, err := service.BlockUser(ctx, app.BlockUserCommand{BlockerID: first.ID, UserID: spammer.ID})
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserStatusActive, spammer.Status)
+
+	_, err = service.BlockUser(ctx, app.BlockUserCommand{BlockerID: second.ID, UserID: spammer.ID})
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserStatusSuspended, spammer.Status)
+
+	suspensions := bus.eventsOfType(domain.UserSuspendedEvent)
+	require.Len(t, suspensions, 1)
+	var suspended domain.UserSuspended
+	require.NoError(t, events.ParseEventData(suspensions[0], &suspended))
+	assert.Empty(t, suspended.SuspendedBy, "automatic suspensions have no administrator")
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/users/domain"
+	"dongome/pkg/ids"
+)
+
+// SellerEngagementSource reports seller logins and response rates to the
+// listings ranking
+type SellerEngagementSource struct {
+	activityRepo domain.SellerActivityRepository
+}
+
+// NewSellerEngagementSource creates a new seller engagement source
+func NewSellerEngagementSource(activityRepo domain.SellerActivityRepository) *SellerEngagementSource {
+	return &SellerEngagementSource{
+		activityRepo: activityRepo,
+	}
+}
+
+// SellerEngagement returns the next batch of sellers after afterID with the
+// inquiries and responses they received since since
+func (s *SellerEngagementSource) SellerEngagement(ctx context.Context, afterID ids.UserID, limit int, since time.Time) ([]listings.SellerEngagement, error) {
+	batch, err := s.activityRepo.Engagement(afterID, limit, since)
+	if err != nil {
+		return nil, err
+	}
+
+	engagement := make([]listings.SellerEngagement, 0, len(batch))
+	for _, seller := range batch {
+		engagement = append(engagement, listings.SellerEngagement{
+			SellerID:    seller.SellerID,
+			LastLoginAt: seller.LastLoginAt,
+			Inquiries:   seller.Inquiries,
+			Responses:   seller.Responses,
+		})
+	}
+	return engagement, nil
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// DataExportSource provides one section of a user's data export. Other
+// bounded contexts implement it to contribute the data they own.
+type DataExportSource interface {
+	Name() string
+	ExportUserData(ctx context.Context, userID ids.UserID) (interface{}, error)
+}
+
+// DataExportArchive stores assembled export archives
+type DataExportArchive interface {
+	Write(exportID string, sections map[string]interface{}) (path string, size int64, err error)
+}
+
+// DataExportService handles GDPR-style data export use cases
+type DataExportService struct {
+	userRepo   domain.UserRepository
+	exportRepo domain.DataExportRepository
+	archive    DataExportArchive
+	sources    []DataExportSource
+	eventBus   events.EventBus
+}
+
+// NewDataExportService creates a new data export service
+func NewDataExportService(
+	userRepo domain.UserRepository,
+	exportRepo domain.DataExportRepository,
+	archive DataExportArchive,
+	eventBus events.EventBus,
+	sources ...DataExportSource,
+) *DataExportService {
+	return &DataExportService{
+		userRepo:   userRepo,
+		exportRepo: exportRepo,
+		archive:    archive,
+		sources:    sources,
+		eventBus:   eventBus,
+	}
+}
+
+// RequestExport creates a data export and hands it to the worker for processing
+func (s *DataExportService) RequestExport(ctx context.Context, userID ids.UserID) (*domain.DataExport, error) {
+	// Find user
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.NotFoundError("user not found")
+	}
+
+	// Only one export may run at a time per user
+	if existing, err := s.exportRepo.FindInProgressByUser(user.ID); err == nil && existing != nil {
+		return existing, nil
+	}
+
+	export, err := domain.NewDataExport(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.exportRepo.Save(export) }); err != nil {
+		return nil, err
+	}
+
+	// Publish DataExportRequested event for the worker
+	event, err := events.NewEvent(
+		domain.DataExportRequestedEvent,
+		user.ID.String(),
+		domain.DataExportRequested{
+			ExportID:  export.ID,
+			UserID:    user.ID,
+			Timestamp: time.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// GetExport retrieves a user's data export
+func (s *DataExportService) GetExport(ctx context.Context, userID ids.UserID, exportID string) (*domain.DataExport, error) {
+	export, err := s.exportRepo.FindByID(exportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.UserID != userID {
+		return nil, errors.NotFoundError("export not found")
+	}
+
+	return export, nil
+}
+
+// ProcessExport assembles the export archive from all registered sources
+func (s *DataExportService) ProcessExport(ctx context.Context, exportID string) error {
+	export, err := s.exportRepo.FindByID(exportID)
+	if err != nil {
+		return err
+	}
+
+	// Redelivered events for finished exports are ignored
+	if err := export.Start(); err != nil {
+		return nil
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.exportRepo.Update(export) }); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(export.UserID)
+	if err != nil {
+		export.Fail("user not found")
+		return s.finishExport(ctx, export, "")
+	}
+
+	sections := map[string]interface{}{
+		"profile": user,
+	}
+	for _, source := range s.sources {
+		data, err := source.ExportUserData(ctx, user.ID)
+		if err != nil {
+			export.Fail("failed to export " + source.Name())
+			return s.finishExport(ctx, export, user.Email)
+		}
+		sections[source.Name()] = data
+	}
+
+	path, size, err := s.archive.Write(export.ID, sections)
+	if err != nil {
+		export.Fail("failed to write archive")
+		return s.finishExport(ctx, export, user.Email)
+	}
+
+	export.Complete(path, size)
+	return s.finishExport(ctx, export, user.Email)
+}
+
+// finishExport persists the final export state and publishes DataExportCompleted
+func (s *DataExportService) finishExport(ctx context.Context, export *domain.DataExport, email string) error {
+	if err := db.WithRetry(ctx, func() error { return s.exportRepo.Update(export) }); err != nil {
+		return err
+	}
+
+	event, err := events.NewEvent(
+		domain.DataExportCompletedEvent,
+		export.UserID.String(),
+		domain.DataExportCompleted{
+			ExportID:  export.ID,
+			UserID:    export.UserID,
+			Email:     email,
+			Status:    export.Status,
+			ExpiresAt: export.ExpiresAt,
+			Timestamp: time.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
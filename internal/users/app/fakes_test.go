@@ -0,0 +1,886 @@
+package app_test
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/users/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/rules"
+)
+
+// fakeUserRepository is an in-memory UserRepository
+type fakeUserRepository struct {
+	mu    sync.Mutex
+	users map[ids.UserID]*domain.User
+}
+
+func newFakeUserRepository(users ...*domain.User) *fakeUserRepository {
+	repo := &fakeUserRepository{users: make(map[ids.UserID]*domain.User)}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (r *fakeUserRepository) Save(user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepository) FindByID(id ids.UserID) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok && !user.DeletedAt.Valid {
+		return user, nil
+	}
+	return nil, errors.NotFoundError("user not found")
+}
+
+func (r *fakeUserRepository) FindByIDIncludingDeleted(id ids.UserID) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, errors.NotFoundError("user not found")
+}
+
+func (r *fakeUserRepository) FindByEmail(email string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == email && !user.DeletedAt.Valid {
+			return user, nil
+		}
+	}
+	return nil, errors.NotFoundError("user not found")
+}
+
+func (r *fakeUserRepository) FindByPhoneNumber(phoneNumber string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.PhoneNumber == phoneNumber {
+			return user, nil
+		}
+	}
+	return nil, errors.NotFoundError("user not found")
+}
+
+func (r *fakeUserRepository) FindByHandle(handle string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.HandleValue() == handle {
+			return user, nil
+		}
+	}
+	return nil, errors.NotFoundError("user not found")
+}
+
+func (r *fakeUserRepository) FindWithPhoneNumber(afterID ids.UserID, limit int) ([]*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*domain.User
+	for _, user := range r.users {
+		if user.PhoneNumber != "" && user.ID > afterID {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepository) FindByVerificationToken(token string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.VerificationToken == token && !user.DeletedAt.Valid {
+			return user, nil
+		}
+	}
+	return nil, errors.NotFoundError("user not found")
+}
+
+func (r *fakeUserRepository) FindPendingErasure(deletedBefore time.Time, limit int) ([]*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*domain.User
+	for _, user := range r.users {
+		if user.IsDeleted() && user.AnonymizedAt == nil && user.DeletedAt.Time.Before(deletedBefore) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepository) FindAll(filter domain.UserFilter, limit, offset int) ([]*domain.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*domain.User
+	for _, user := range r.users {
+		if user.DeletedAt.Valid && !filter.IncludeDeleted {
+			continue
+		}
+		if filter.Status != "" && user.Status != filter.Status {
+			continue
+		}
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		users = append(users, user)
+	}
+	return users, int64(len(users)), nil
+}
+
+func (r *fakeUserRepository) Search(search domain.UserSearch, limit, offset int) ([]*domain.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*domain.User
+	for _, user := range r.users {
+		if user.DeletedAt.Valid && !search.IncludeDeleted {
+			continue
+		}
+		if _, ok := search.Match(user); ok {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	total := int64(len(users))
+	if offset >= len(users) {
+		return nil, total, nil
+	}
+	users = users[offset:]
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, total, nil
+}
+
+func (r *fakeUserRepository) Update(user *domain.User) error {
+	return r.Save(user)
+}
+
+func (r *fakeUserRepository) UpdateAll(users ...*domain.User) error {
+	for _, user := range users {
+		if err := r.Save(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeUserRepository) Delete(id ids.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, id)
+	return nil
+}
+
+// fakeEventBus records published events
+type fakeEventBus struct {
+	mu        sync.Mutex
+	published []*events.Event
+}
+
+func (b *fakeEventBus) Publish(ctx context.Context, event *events.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, event)
+	return nil
+}
+
+func (b *fakeEventBus) Subscribe(eventType string, handler events.EventHandler) error {
+	return nil
+}
+
+func (b *fakeEventBus) Close() error {
+	return nil
+}
+
+// eventsOfType returns the published events of the given type
+func (b *fakeEventBus) eventsOfType(eventType string) []*events.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var matching []*events.Event
+	for _, event := range b.published {
+		if event.Type == eventType {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+// fakeUserBlockRepository is an in-memory UserBlockRepository
+type fakeUserBlockRepository struct {
+	mu     sync.Mutex
+	blocks []*domain.UserBlock
+}
+
+func (r *fakeUserBlockRepository) Save(block *domain.UserBlock) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.blocks = append(r.blocks, block)
+	return nil
+}
+
+func (r *fakeUserBlockRepository) Find(blockerID, blockedID ids.UserID) (*domain.UserBlock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, block := range r.blocks {
+		if block.BlockerID == blockerID && block.BlockedID == blockedID {
+			return block, nil
+		}
+	}
+	return nil, errors.NotFoundError("block not found")
+}
+
+func (r *fakeUserBlockRepository) FindByBlocker(blockerID ids.UserID) ([]*domain.UserBlock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var blocks []*domain.UserBlock
+	for _, block := range r.blocks {
+		if block.BlockerID == blockerID {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
+
+func (r *fakeUserBlockRepository) ExistsBetween(userID, otherUserID ids.UserID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, block := range r.blocks {
+		if (block.BlockerID == userID && block.BlockedID == otherUserID) ||
+			(block.BlockerID == otherUserID && block.BlockedID == userID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeUserBlockRepository) CountBlockedSince(blockedID ids.UserID, since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, block := range r.blocks {
+		if block.BlockedID == blockedID && !block.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeUserBlockRepository) Delete(blockerID, blockedID ids.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, block := range r.blocks {
+		if block.BlockerID == blockerID && block.BlockedID == blockedID {
+			r.blocks = append(r.blocks[:i], r.blocks[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// fakePolicyRules evaluates every true/false rule with a fixed function
+type fakePolicyRules struct {
+	check func(key string, facts rules.Facts) bool
+}
+
+func (r *fakePolicyRules) Bool(key string, facts rules.Facts) bool {
+	return r.check(key, facts)
+}
+
+// fakeAddressRepository is an in-memory AddressRepository
+type fakeAddressRepository struct {
+	mu        sync.Mutex
+	addresses []*domain.Address
+}
+
+func (r *fakeAddressRepository) Save(address *domain.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addresses = append(r.addresses, address)
+	return nil
+}
+
+func (r *fakeAddressRepository) FindByID(id string) (*domain.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, address := range r.addresses {
+		if address.ID == id {
+			return address, nil
+		}
+	}
+	return nil, errors.NotFoundError("address not found")
+}
+
+func (r *fakeAddressRepository) FindByUser(userID ids.UserID) ([]*domain.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var addresses []*domain.Address
+	for _, address := range r.addresses {
+		if address.UserID == userID {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+func (r *fakeAddressRepository) Update(address *domain.Address) error {
+	return nil
+}
+
+func (r *fakeAddressRepository) SetDefault(userID ids.UserID, addressID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	found := false
+	for _, address := range r.addresses {
+		if address.UserID != userID {
+			continue
+		}
+		address.IsDefault = address.ID == addressID
+		found = found || address.IsDefault
+	}
+	if !found {
+		return errors.NotFoundError("address not found")
+	}
+	return nil
+}
+
+func (r *fakeAddressRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, address := range r.addresses {
+		if address.ID == id {
+			r.addresses = append(r.addresses[:i], r.addresses[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// fakeLocationValidator accepts a fixed set of region/city pairs
+type fakeLocationValidator struct {
+	cities map[string][]string
+}
+
+func (v *fakeLocationValidator) ValidateLocation(ctx context.Context, location listings.Location) (listings.Location, error) {
+	for _, city := range v.cities[location.Region] {
+		if city == location.City {
+			return location, nil
+		}
+	}
+	return listings.Location{}, errors.ValidationError("unknown location")
+}
+
+// fakeSellerActivityRepository is an in-memory SellerActivityRepository
+type fakeSellerActivityRepository struct {
+	mu         sync.Mutex
+	activities map[string]*domain.SellerActivity
+	profiles   map[ids.UserID]*domain.SellerProfile
+	lastLogins map[ids.UserID]time.Time
+}
+
+func newFakeSellerActivityRepository(profiles ...*domain.SellerProfile) *fakeSellerActivityRepository {
+	repo := &fakeSellerActivityRepository{
+		activities: make(map[string]*domain.SellerActivity),
+		profiles:   make(map[ids.UserID]*domain.SellerProfile),
+		lastLogins: make(map[ids.UserID]time.Time),
+	}
+	for _, profile := range profiles {
+		repo.profiles[profile.UserID] = profile
+	}
+	return repo
+}
+
+func (r *fakeSellerActivityRepository) Record(activity *domain.SellerActivity) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.activities[activity.EventID]; ok {
+		return false, nil
+	}
+	r.activities[activity.EventID] = activity
+	return true, nil
+}
+
+func (r *fakeSellerActivityRepository) Stats(sellerID ids.UserID, responsesSince time.Time) (domain.SellerStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var stats domain.SellerStats
+	var ratings, responses float64
+	for _, activity := range r.activities {
+		if activity.SellerID != sellerID {
+			continue
+		}
+		switch activity.Kind {
+		case domain.ActivitySale:
+			stats.CompletedSales++
+		case domain.ActivityReview:
+			stats.TotalReviews++
+			ratings += activity.Value
+		case domain.ActivityResponse:
+			if !activity.OccurredAt.Before(responsesSince) {
+				stats.ResponseSamples++
+				responses += activity.Value
+			}
+		}
+	}
+	if stats.TotalReviews > 0 {
+		stats.Rating = ratings / float64(stats.TotalReviews)
+	}
+	if stats.ResponseSamples > 0 {
+		stats.ResponseTimeMinutes = responses / float64(stats.ResponseSamples)
+	}
+	return stats, nil
+}
+
+func (r *fakeSellerActivityRepository) FindProfiles(userIDs []ids.UserID) ([]*domain.SellerProfile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var profiles []*domain.SellerProfile
+	for _, id := range userIDs {
+		if profile, ok := r.profiles[id]; ok {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
+
+func (r *fakeSellerActivityRepository) FindSellerIDs(afterID ids.UserID, limit int) ([]ids.UserID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sellerIDs []ids.UserID
+	for id := range r.profiles {
+		if id > afterID {
+			sellerIDs = append(sellerIDs, id)
+		}
+	}
+	sort.Slice(sellerIDs, func(i, j int) bool { return sellerIDs[i] < sellerIDs[j] })
+	if len(sellerIDs) > limit {
+		sellerIDs = sellerIDs[:limit]
+	}
+	return sellerIDs, nil
+}
+
+func (r *fakeSellerActivityRepository) Engagement(afterID ids.UserID, limit int, since time.Time) ([]domain.SellerEngagement, error) {
+	sellerIDs, _ := r.FindSellerIDs(afterID, limit)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	engagement := make([]domain.SellerEngagement, 0, len(sellerIDs))
+	for _, id := range sellerIDs {
+		seller := domain.SellerEngagement{SellerID: id}
+		if lastLogin, ok := r.lastLogins[id]; ok {
+			seller.LastLoginAt = &lastLogin
+		}
+		for _, activity := range r.activities {
+			if activity.SellerID != id || activity.OccurredAt.Before(since) {
+				continue
+			}
+			switch activity.Kind {
+			case domain.ActivityInquiry:
+				seller.Inquiries++
+			case domain.ActivityResponse:
+				seller.Responses++
+			}
+		}
+		engagement = append(engagement, seller)
+	}
+	return engagement, nil
+}
+
+func (r *fakeSellerActivityRepository) UpdateProfile(profile *domain.SellerProfile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.profiles[profile.UserID] = profile
+	return nil
+}
+
+// fakeVerificationReminderRepository is an in-memory VerificationReminderRepository
+type fakeVerificationReminderRepository struct {
+	mu        sync.Mutex
+	reminders map[ids.UserID]*domain.VerificationReminder
+}
+
+func newFakeVerificationReminderRepository() *fakeVerificationReminderRepository {
+	return &fakeVerificationReminderRepository{reminders: make(map[ids.UserID]*domain.VerificationReminder)}
+}
+
+func (r *fakeVerificationReminderRepository) Create(reminder *domain.VerificationReminder) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.reminders[reminder.UserID]; ok {
+		return false, nil
+	}
+	r.reminders[reminder.UserID] = reminder
+	return true, nil
+}
+
+func (r *fakeVerificationReminderRepository) FindByUserID(userID ids.UserID) (*domain.VerificationReminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if reminder, ok := r.reminders[userID]; ok {
+		return reminder, nil
+	}
+	return nil, errors.NotFoundError("verification reminder not found")
+}
+
+func (r *fakeVerificationReminderRepository) FindDue(now time.Time, limit int) ([]*domain.VerificationReminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []*domain.VerificationReminder
+	for _, reminder := range r.reminders {
+		if reminder.Due(now) {
+			due = append(due, reminder)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextReminderAt.Before(*due[j].NextReminderAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (r *fakeVerificationReminderRepository) Update(reminder *domain.VerificationReminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reminders[reminder.UserID] = reminder
+	return nil
+}
+
+func (r *fakeVerificationReminderRepository) CountBySteps() ([]domain.ReminderStepCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	totals := make(map[domain.ReminderStepCount]int64)
+	for _, reminder := range r.reminders {
+		totals[domain.ReminderStepCount{Step: reminder.Step, Status: reminder.Status}]++
+	}
+	var counts []domain.ReminderStepCount
+	for key, count := range totals {
+		key.Count = count
+		counts = append(counts, key)
+	}
+	return counts, nil
+}
+
+// fakeSellerFollowRepository is an in-memory SellerFollowRepository that
+// keeps the followers counts of the user repository's seller profiles
+type fakeSellerFollowRepository struct {
+	mu      sync.Mutex
+	users   *fakeUserRepository
+	follows []*domain.SellerFollow
+}
+
+func (r *fakeSellerFollowRepository) Save(follow *domain.SellerFollow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.follows = append(r.follows, follow)
+	r.adjustFollowers(follow.SellerID, 1)
+	return nil
+}
+
+func (r *fakeSellerFollowRepository) Find(followerID, sellerID ids.UserID) (*domain.SellerFollow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, follow := range r.follows {
+		if follow.FollowerID == followerID && follow.SellerID == sellerID {
+			return follow, nil
+		}
+	}
+	return nil, errors.NotFoundError("follow not found")
+}
+
+func (r *fakeSellerFollowRepository) FindByFollower(followerID ids.UserID, limit, offset int) ([]*domain.SellerFollow, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []*domain.SellerFollow
+	for _, follow := range r.follows {
+		if follow.FollowerID == followerID {
+			result = append(result, follow)
+		}
+	}
+	total := int64(len(result))
+	if offset >= len(result) {
+		return []*domain.SellerFollow{}, total, nil
+	}
+	result = result[offset:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, total, nil
+}
+
+func (r *fakeSellerFollowRepository) FindFollowedSellerIDs(followerID ids.UserID) ([]ids.UserID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sellerIDs []ids.UserID
+	for _, follow := range r.follows {
+		if follow.FollowerID == followerID {
+			sellerIDs = append(sellerIDs, follow.SellerID)
+		}
+	}
+	return sellerIDs, nil
+}
+
+func (r *fakeSellerFollowRepository) FindFollowerIDs(sellerID, afterID ids.UserID, limit int) ([]ids.UserID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var followerIDs []ids.UserID
+	for _, follow := range r.follows {
+		if follow.SellerID == sellerID && follow.FollowerID > afterID {
+			followerIDs = append(followerIDs, follow.FollowerID)
+		}
+	}
+	sort.Slice(followerIDs, func(i, j int) bool { return followerIDs[i] < followerIDs[j] })
+	if len(followerIDs) > limit {
+		followerIDs = followerIDs[:limit]
+	}
+	return followerIDs, nil
+}
+
+func (r *fakeSellerFollowRepository) Delete(followerID, sellerID ids.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, follow := range r.follows {
+		if follow.FollowerID == followerID && follow.SellerID == sellerID {
+			r.follows = append(r.follows[:i], r.follows[i+1:]...)
+			r.adjustFollowers(sellerID, -1)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeSellerFollowRepository) adjustFollowers(sellerID ids.UserID, delta int) {
+	if r.users == nil {
+		return
+	}
+	if seller, err := r.users.FindByID(sellerID); err == nil && seller.SellerProfile != nil {
+		seller.SellerProfile.FollowersCount += delta
+	}
+}
+
+// fakeUserPreferencesRepository is an in-memory UserPreferencesRepository
+type fakeUserPreferencesRepository struct {
+	mu          sync.Mutex
+	preferences map[ids.UserID]*domain.UserPreferences
+}
+
+func newFakeUserPreferencesRepository(preferences ...*domain.UserPreferences) *fakeUserPreferencesRepository {
+	repo := &fakeUserPreferencesRepository{preferences: make(map[ids.UserID]*domain.UserPreferences)}
+	for _, p := range preferences {
+		repo.preferences[p.UserID] = p
+	}
+	return repo
+}
+
+func (r *fakeUserPreferencesRepository) FindByUser(userID ids.UserID) (*domain.UserPreferences, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if preferences, ok := r.preferences[userID]; ok {
+		return preferences, nil
+	}
+	return nil, errors.NotFoundError("preferences not found")
+}
+
+func (r *fakeUserPreferencesRepository) FindByUsers(userIDs []ids.UserID) ([]*domain.UserPreferences, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []*domain.UserPreferences
+	for _, userID := range userIDs {
+		if preferences, ok := r.preferences[userID]; ok {
+			found = append(found, preferences)
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeUserPreferencesRepository) Save(preferences *domain.UserPreferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.preferences[preferences.UserID] = preferences
+	return nil
+}
+
+// fakeReferralRepository is an in-memory ReferralRepository
+type fakeReferralRepository struct {
+	mu        sync.Mutex
+	codes     map[ids.UserID]*domain.ReferralCode
+	referrals map[ids.UserID]*domain.Referral
+}
+
+func newFakeReferralRepository() *fakeReferralRepository {
+	return &fakeReferralRepository{
+		codes:     make(map[ids.UserID]*domain.ReferralCode),
+		referrals: make(map[ids.UserID]*domain.Referral),
+	}
+}
+
+func (r *fakeReferralRepository) SaveCode(code *domain.ReferralCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.codes {
+		if existing.UserID == code.UserID || existing.Code == code.Code {
+			return errors.ConflictError("resource already exists")
+		}
+	}
+	r.codes[code.UserID] = code
+	return nil
+}
+
+func (r *fakeReferralRepository) FindCodeByUser(userID ids.UserID) (*domain.ReferralCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if code, ok := r.codes[userID]; ok {
+		return code, nil
+	}
+	return nil, errors.NotFoundError("referral code not found")
+}
+
+func (r *fakeReferralRepository) FindCode(value string) (*domain.ReferralCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, code := range r.codes {
+		if code.Code == value {
+			return code, nil
+		}
+	}
+	return nil, errors.NotFoundError("referral code not found")
+}
+
+func (r *fakeReferralRepository) Create(referral *domain.Referral) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.referrals[referral.ReferredID]; ok {
+		return false, nil
+	}
+	r.referrals[referral.ReferredID] = referral
+	return true, nil
+}
+
+func (r *fakeReferralRepository) FindByReferred(referredID ids.UserID) (*domain.Referral, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if referral, ok := r.referrals[referredID]; ok {
+		return referral, nil
+	}
+	return nil, errors.NotFoundError("referral not found")
+}
+
+func (r *fakeReferralRepository) FindByReferrer(referrerID ids.UserID, limit, offset int) ([]*domain.Referral, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matches []*domain.Referral
+	for _, referral := range r.referrals {
+		if referral.ReferrerID == referrerID {
+			matches = append(matches, referral)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].CreatedAt.After(matches[j].CreatedAt) })
+	total := int64(len(matches))
+	if offset >= len(matches) {
+		return nil, total, nil
+	}
+	matches = matches[offset:]
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, total, nil
+}
+
+func (r *fakeReferralRepository) CountByReferrer(referrerID ids.UserID) (domain.ReferralCounts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var counts domain.ReferralCounts
+	for _, referral := range r.referrals {
+		if referral.ReferrerID != referrerID {
+			continue
+		}
+		switch referral.Status {
+		case domain.ReferralStatusPending:
+			counts.Pending++
+		case domain.ReferralStatusCompleted:
+			counts.Completed++
+		}
+	}
+	return counts, nil
+}
+
+func (r *fakeReferralRepository) Update(referral *domain.Referral) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.referrals[referral.ReferredID] = referral
+	return nil
+}
+
+// fakeCache is an in-memory Cache that stores values as JSON
+type fakeCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{entries: make(map[string][]byte)}
+}
+
+func (c *fakeCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.entries[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *fakeCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = data
+	return nil
+}
+
+func (c *fakeCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return false, nil
+	}
+	c.entries[key] = data
+	return true, nil
+}
+
+func (c *fakeCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+func (c *fakeCache) Close() error {
+	return nil
+}
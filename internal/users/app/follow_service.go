@@ -0,0 +1,248 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// followerBatchSize is how many followers are notified per event
+const followerBatchSize = 500
+
+// ListFollowedSellersQuery represents the query to list the sellers a user follows
+type ListFollowedSellersQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// FollowedSellers represents a page of the sellers a user follows
+type FollowedSellers struct {
+	Follows []*domain.SellerFollow `json:"follows"`
+	Total   int64                  `json:"total"`
+	Limit   int                    `json:"limit"`
+	Offset  int                    `json:"offset"`
+}
+
+// NotifyFollowersCommand represents a new listing to announce to the seller's followers
+type NotifyFollowersCommand struct {
+	ListingID ids.ListingID
+	SellerID  ids.UserID
+	Title     string
+	Price     money.Money
+}
+
+// FollowService handles following sellers
+type FollowService struct {
+	userRepo        domain.UserRepository
+	followRepo      domain.SellerFollowRepository
+	blockRepo       domain.UserBlockRepository
+	preferencesRepo domain.UserPreferencesRepository
+	eventBus        events.EventBus
+}
+
+// NewFollowService creates a new follow service
+func NewFollowService(userRepo domain.UserRepository, followRepo domain.SellerFollowRepository, blockRepo domain.UserBlockRepository, preferencesRepo domain.UserPreferencesRepository, eventBus events.EventBus) *FollowService {
+	return &FollowService{
+		userRepo:        userRepo,
+		followRepo:      followRepo,
+		blockRepo:       blockRepo,
+		preferencesRepo: preferencesRepo,
+		eventBus:        eventBus,
+	}
+}
+
+// FollowSeller follows a seller. Following an already followed seller is a no-op.
+func (s *FollowService) FollowSeller(ctx context.Context, followerID, sellerID ids.UserID) (*domain.SellerFollow, error) {
+	seller, err := s.userRepo.FindByID(sellerID)
+	if err != nil {
+		return nil, errors.NotFoundError("seller not found")
+	}
+	if seller.SellerProfile == nil {
+		return nil, errors.NotFoundError("seller not found")
+	}
+
+	if existing, err := s.followRepo.Find(followerID, sellerID); err == nil && existing != nil {
+		return existing, nil
+	}
+
+	blocked, err := s.blockRepo.ExistsBetween(followerID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, errors.ForbiddenError("you cannot follow this seller")
+	}
+
+	follow, err := domain.NewSellerFollow(followerID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.followRepo.Save(follow) }); err != nil {
+		return nil, err
+	}
+
+	// Publish SellerFollowed event
+	event, err := events.NewEvent(
+		domain.SellerFollowedEvent,
+		follow.FollowerID.String(),
+		domain.SellerFollowed{
+			FollowerID: follow.FollowerID,
+			SellerID:   follow.SellerID,
+			Timestamp:  time.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return follow, nil
+}
+
+// UnfollowSeller stops following a seller
+func (s *FollowService) UnfollowSeller(ctx context.Context, followerID, sellerID ids.UserID) error {
+	if _, err := s.followRepo.Find(followerID, sellerID); err != nil {
+		return err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.followRepo.Delete(followerID, sellerID) }); err != nil {
+		return err
+	}
+
+	// Publish SellerUnfollowed event
+	event, err := events.NewEvent(
+		domain.SellerUnfollowedEvent,
+		followerID.String(),
+		domain.SellerUnfollowed{
+			FollowerID: followerID,
+			SellerID:   sellerID,
+			Timestamp:  time.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// ListFollowedSellers lists the sellers a user follows, newest first
+func (s *FollowService) ListFollowedSellers(ctx context.Context, followerID ids.UserID, query ListFollowedSellersQuery) (*FollowedSellers, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	follows, total, err := s.followRepo.FindByFollower(followerID, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FollowedSellers{
+		Follows: follows,
+		Total:   total,
+		Limit:   limit,
+		Offset:  query.Offset,
+	}, nil
+}
+
+// FollowedSellerIDs returns the IDs of every seller a user follows. The
+// listings context uses it to build the followed-seller feed.
+func (s *FollowService) FollowedSellerIDs(ctx context.Context, followerID ids.UserID) ([]ids.UserID, error) {
+	return s.followRepo.FindFollowedSellerIDs(followerID)
+}
+
+// NotifyFollowers announces a seller's new listing to their followers,
+// publishing one FollowedSellerListing event per batch of followers. Followers
+// who turned off every channel for followed sellers are left out. It returns
+// the number of followers notified.
+func (s *FollowService) NotifyFollowers(ctx context.Context, cmd NotifyFollowersCommand) (int, error) {
+	notified := 0
+	var afterID ids.UserID
+	for {
+		followerIDs, err := s.followRepo.FindFollowerIDs(cmd.SellerID, afterID, followerBatchSize)
+		if err != nil {
+			return notified, err
+		}
+		if len(followerIDs) == 0 {
+			return notified, nil
+		}
+
+		recipients, channels, err := s.followerChannels(followerIDs)
+		if err != nil {
+			return notified, err
+		}
+		if len(recipients) > 0 {
+			if err := s.publishFollowedSellerListing(ctx, cmd, recipients, channels); err != nil {
+				return notified, err
+			}
+			notified += len(recipients)
+		}
+
+		if len(followerIDs) < followerBatchSize {
+			return notified, nil
+		}
+		afterID = followerIDs[len(followerIDs)-1]
+	}
+}
+
+// followerChannels looks up the channels each follower wants followed seller
+// news on, returning the followers with at least one channel
+func (s *FollowService) followerChannels(followerIDs []ids.UserID) ([]ids.UserID, map[ids.UserID][]string, error) {
+	stored, err := s.preferencesRepo.FindByUsers(followerIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	byUser := make(map[ids.UserID]*domain.UserPreferences, len(stored))
+	for _, preferences := range stored {
+		byUser[preferences.UserID] = preferences
+	}
+
+	var recipients []ids.UserID
+	channels := make(map[ids.UserID][]string)
+	for _, followerID := range followerIDs {
+		preferences, ok := byUser[followerID]
+		if !ok {
+			preferences = domain.DefaultUserPreferences(followerID)
+		}
+		enabled := preferences.EnabledChannels(domain.NotificationFollowedSellers)
+		if len(enabled) == 0 {
+			continue
+		}
+		recipients = append(recipients, followerID)
+		channels[followerID] = channelNames(enabled)
+	}
+	return recipients, channels, nil
+}
+
+// publishFollowedSellerListing publishes a FollowedSellerListing event for a batch of followers
+func (s *FollowService) publishFollowedSellerListing(ctx context.Context, cmd NotifyFollowersCommand, followerIDs []ids.UserID, channels map[ids.UserID][]string) error {
+	event, err := events.NewEvent(
+		domain.FollowedSellerListingEvent,
+		cmd.SellerID.String(),
+		domain.FollowedSellerListing{
+			ListingID:   cmd.ListingID,
+			SellerID:    cmd.SellerID,
+			Title:       cmd.Title,
+			Price:       cmd.Price,
+			FollowerIDs: followerIDs,
+			Channels:    channels,
+			Timestamp:   time.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
@@ -0,0 +1,136 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+	"dongome/pkg/events"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowService_FollowAndUnfollow(t *testing.T) {
+	buyer := newActiveUser(t, "buyer@example.com")
+	seller := newSeller(t, "seller@example.com")
+
+	users := newFakeUserRepository(buyer, seller)
+	follows := &fakeSellerFollowRepository{users: users}
+	bus := &fakeEventBus{}
+	service := app.NewFollowService(users, follows, &fakeUserBlockRepository{}, newFakeUserPreferencesRepository(), bus)
+	ctx := context.Background()
+
+	follow, err := service.FollowSeller(ctx, buyer.ID, seller.ID)
+	require.NoError(t, err)
+	assert.Equal(t, seller.ID, follow.SellerID)
+	assert.Equal(t, 1, seller.SellerProfile.FollowersCount)
+
+	// Following again is a no-op
+	again, err := service.FollowSeller(ctx, buyer.ID, seller.ID)
+	require.NoError(t, err)
+	assert.Equal(t, follow.ID, again.ID)
+	assert.Equal(t, 1, seller.SellerProfile.FollowersCount)
+	assert.Len(t, bus.eventsOfType(domain.SellerFollowedEvent), 1)
+
+	followed, err := service.ListFollowedSellers(ctx, buyer.ID, app.ListFollowedSellersQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), followed.Total)
+
+	require.NoError(t, service.UnfollowSeller(ctx, buyer.ID, seller.ID))
+	assert.Zero(t, seller.SellerProfile.FollowersCount)
+	assert.Len(t, bus.eventsOfType(domain.SellerUnfollowedEvent), 1)
+	assert.Error(t, service.UnfollowSeller(ctx, buyer.ID, seller.ID))
+}
+
+func TestFollowService_FollowSeller_Rejected(t *testing.T) {
+	buyer := newActiveUser(t, "buyer@example.com")
+	notSeller := newActiveUser(t, "other@example.com")
+	seller := newSeller(t, "seller@example.com")
+
+	users := newFakeUserRepository(buyer, notSeller, seller)
+	blocks := &fakeUserBlockRepository{}
+	service := app.NewFollowService(users, &fakeSellerFollowRepository{users: users}, blocks, newFakeUserPreferencesRepository(), &fakeEventBus{})
+	ctx := context.Background()
+
+	_, err := service.FollowSeller(ctx, buyer.ID, notSeller.ID)
+	assert.Error(t, err)
+
+	_, err = service.FollowSeller(ctx, seller.ID, seller.ID)
+	assert.Error(t, err)
+
+	block, err := domain.NewUserBlock(seller.ID, buyer.ID, "")
+	require.NoError(t, err)
+	require.NoError(t, blocks.Save(block))
+	_, err = service.FollowSeller(ctx, buyer.ID, seller.ID)
+	assert.Error(t, err)
+	assert.Zero(t, seller.SellerProfile.FollowersCount)
+}
+
+func TestFollowService_NotifyFollowers(t *testing.T) {
+	seller := newSeller(t, "seller@example.com")
+	users := newFakeUserRepository(seller)
+	follows := &fakeSellerFollowRepository{users: users}
+	for i := 0; i < 3; i++ {
+		follow, err := domain.NewSellerFollow(newActiveUser(t, "buyer@example.com").ID, seller.ID)
+		require.NoError(t, err)
+		require.NoError(t, follows.Save(follow))
+	}
+
+	bus := &fakeEventBus{}
+	service := app.NewFollowService(users, follows, &fakeUserBlockRepository{}, newFakeUserPreferencesRepository(), bus)
+
+	count, err := service.NotifyFollowers(context.Background(), app.NotifyFollowersCommand{
+		ListingID: "listing-1",
+		SellerID:  seller.ID,
+		Title:     "Kente cloth",
+		Price:     money.Cedis(350),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	published := bus.eventsOfType(domain.FollowedSellerListingEvent)
+	require.Len(t, published, 1)
+	assert.Equal(t, seller.ID.String(), published[0].AggregateID)
+}
+
+func TestFollowService_NotifyFollowersRespectsChannels(t *testing.T) {
+	seller := newSeller(t, "seller@example.com")
+	users := newFakeUserRepository(seller)
+	follows := &fakeSellerFollowRepository{users: users}
+	muted := newActiveUser(t, "muted@example.com")
+	pushOnly := newActiveUser(t, "push@example.com")
+	for _, follower := range []*domain.User{muted, pushOnly, newActiveUser(t, "default@example.com")} {
+		follow, err := domain.NewSellerFollow(follower.ID, seller.ID)
+		require.NoError(t, err)
+		require.NoError(t, follows.Save(follow))
+	}
+
+	off := false
+	mutedPreferences := domain.DefaultUserPreferences(muted.ID)
+	_, err := mutedPreferences.ApplyChannels(map[domain.NotificationCategory]domain.ChannelSettingsUpdate{
+		domain.NotificationFollowedSellers: {Email: &off, Push: &off},
+	})
+	require.NoError(t, err)
+	pushPreferences := domain.DefaultUserPreferences(pushOnly.ID)
+	_, err = pushPreferences.ApplyChannels(map[domain.NotificationCategory]domain.ChannelSettingsUpdate{
+		domain.NotificationFollowedSellers: {Email: &off},
+	})
+	require.NoError(t, err)
+
+	bus := &fakeEventBus{}
+	service := app.NewFollowService(users, follows, &fakeUserBlockRepository{}, newFakeUserPreferencesRepository(mutedPreferences, pushPreferences), bus)
+
+	count, err := service.NotifyFollowers(context.Background(), app.NotifyFollowersCommand{ListingID: "listing-1", SellerID: seller.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	published := bus.eventsOfType(domain.FollowedSellerListingEvent)
+	require.Len(t, published, 1)
+	var data domain.FollowedSellerListing
+	require.NoError(t, events.ParseEventData(published[0], &data))
+	assert.NotContains(t, data.FollowerIDs, muted.ID)
+	assert.Equal(t, []string{"push"}, data.Channels[pushOnly.ID])
+}
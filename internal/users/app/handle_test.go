@@ -0,0 +1,87 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_SetHandle(t *testing.T) {
+	kofi := newActiveUser(t, "kofi@example.com")
+	ama := newActiveUser(t, "ama@example.com")
+	repo := newFakeUserRepository(kofi, ama)
+	bus := &fakeEventBus{}
+	service := app.NewUserService(repo, bus, nil)
+	ctx := context.Background()
+
+	user, err := service.SetHandle(ctx, app.SetHandleCommand{UserID: kofi.ID, Handle: "@Kofi"})
+	require.NoError(t, err)
+	assert.Equal(t, "kofi", user.HandleValue())
+	require.Len(t, bus.eventsOfType(domain.UserHandleChangedEvent), 1)
+
+	// Setting the same handle again changes nothing
+	_, err = service.SetHandle(ctx, app.SetHandleCommand{UserID: kofi.ID, Handle: "KOFI"})
+	require.NoError(t, err)
+	assert.Len(t, bus.eventsOfType(domain.UserHandleChangedEvent), 1)
+
+	// Handles are unique regardless of case
+	_, err = service.SetHandle(ctx, app.SetHandleCommand{UserID: ama.ID, Handle: "Kofi"})
+	assert.Error(t, err)
+
+	found, err := service.GetUserByHandle(ctx, "@KOFI")
+	require.NoError(t, err)
+	assert.Equal(t, kofi.ID, found.ID)
+
+	// Removing the handle frees it
+	_, err = service.SetHandle(ctx, app.SetHandleCommand{UserID: kofi.ID, Handle: ""})
+	require.NoError(t, err)
+	_, err = service.GetUserByHandle(ctx, "kofi")
+	assert.Error(t, err)
+	_, err = service.SetHandle(ctx, app.SetHandleCommand{UserID: ama.ID, Handle: "kofi"})
+	assert.NoError(t, err)
+}
+
+func TestUserService_CheckHandleAvailability(t *testing.T) {
+	kofi := newActiveUser(t, "kofi@example.com")
+	require.NoError(t, kofi.SetHandle("kofi"))
+	service := app.NewUserService(newFakeUserRepository(kofi), &fakeEventBus{}, nil)
+	ctx := context.Background()
+
+	availability, err := service.CheckHandleAvailability(ctx, "@Ama_K")
+	require.NoError(t, err)
+	assert.True(t, availability.Available)
+	assert.Equal(t, "ama_k", availability.Handle)
+
+	availability, err = service.CheckHandleAvailability(ctx, "Kofi")
+	require.NoError(t, err)
+	assert.False(t, availability.Available)
+	assert.Equal(t, "handle is already taken", availability.Reason)
+
+	availability, err = service.CheckHandleAvailability(ctx, "admin")
+	require.NoError(t, err)
+	assert.False(t, availability.Available)
+	assert.Equal(t, "handle is reserved", availability.Reason)
+}
+
+func TestUserService_RegisterUserWithHandle(t *testing.T) {
+	kofi := newActiveUser(t, "kofi@example.com")
+	require.NoError(t, kofi.SetHandle("kofi"))
+	service := app.NewUserService(newFakeUserRepository(kofi), &fakeEventBus{}, nil)
+	ctx := context.Background()
+
+	_, err := service.RegisterUser(ctx, app.RegisterUserCommand{
+		Email: "other@example.com", Password: "password123", FirstName: "Kofi", LastName: "Boateng", Handle: "kofi",
+	})
+	assert.Error(t, err)
+
+	user, err := service.RegisterUser(ctx, app.RegisterUserCommand{
+		Email: "other@example.com", Password: "password123", FirstName: "Kofi", LastName: "Boateng", Handle: "@kofi_b",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "kofi_b", user.HandleValue())
+}
@@ -0,0 +1,72 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+	"dongome/pkg/events"
+	"dongome/pkg/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_SetLocale(t *testing.T) {
+	kofi := newActiveUser(t, "kofi@example.com")
+	service := app.NewUserService(newFakeUserRepository(kofi), &fakeEventBus{}, nil)
+	ctx := context.Background()
+
+	stored, err := service.StoredLocale(ctx, kofi.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored, "users follow Accept-Language until they choose")
+
+	user, err := service.SetLocale(ctx, app.SetLocaleCommand{UserID: kofi.ID, Locale: "fr-CI"})
+	require.NoError(t, err)
+	assert.Equal(t, "fr", user.Locale)
+	assert.Equal(t, i18n.French, user.PreferredLocale())
+
+	_, err = service.SetLocale(ctx, app.SetLocaleCommand{UserID: kofi.ID, Locale: "de"})
+	assert.Error(t, err)
+	stored, err = service.StoredLocale(ctx, kofi.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "fr", stored, "an unsupported locale leaves the choice unchanged")
+
+	user, err = service.SetLocale(ctx, app.SetLocaleCommand{UserID: kofi.ID, Locale: ""})
+	require.NoError(t, err)
+	assert.Empty(t, user.Locale)
+	assert.Equal(t, i18n.DefaultLocale, user.PreferredLocale())
+}
+
+func TestUserService_RegisterUserWithLocale(t *testing.T) {
+	bus := &fakeEventBus{}
+	service := app.NewUserService(newFakeUserRepository(), bus, nil)
+	ctx := context.Background()
+
+	user, err := service.RegisterUser(ctx, app.RegisterUserCommand{
+		Email:     "ama@example.com",
+		Password:  "password123",
+		FirstName: "Ama",
+		LastName:  "Mensah",
+		Locale:    "tw",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "tw", user.Locale)
+
+	// The verification email is written in the user's locale
+	registered := bus.eventsOfType(domain.UserRegisteredEvent)
+	require.Len(t, registered, 1)
+	var payload domain.UserRegistered
+	require.NoError(t, events.ParseEventData(registered[0], &payload))
+	assert.Equal(t, "tw", payload.Locale)
+
+	_, err = service.RegisterUser(ctx, app.RegisterUserCommand{
+		Email:     "yaw@example.com",
+		Password:  "password123",
+		FirstName: "Yaw",
+		LastName:  "Boateng",
+		Locale:    "xx",
+	})
+	assert.Error(t, err)
+}
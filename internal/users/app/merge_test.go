@@ -0,0 +1,129 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newActiveUser(t *testing.T, email string) *domain.User {
+	t.Helper()
+	user, err := domain.NewUser(email, "password123", "Ama", "Mensah", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, user.VerifyEmail(time.Now()))
+	return user
+}
+
+func TestUserService_MergeUsers(t *testing.T) {
+	primary := newActiveUser(t, "ama@example.com")
+	duplicate := newActiveUser(t, "ama.phone@example.com")
+	duplicate.PhoneNumber = "+233241234567"
+	duplicate.PhoneVerified = true
+
+	repo := newFakeUserRepository(primary, duplicate)
+	bus := &fakeEventBus{}
+	service := app.NewUserService(repo, bus, nil)
+
+	merged, err := service.MergeUsers(context.Background(), app.MergeUsersCommand{
+		PrimaryUserID:   primary.ID,
+		DuplicateUserID: duplicate.ID,
+		Reason:          "same person signed up with email and phone",
+		MergedBy:        "admin-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, primary.ID, merged.ID)
+	assert.Equal(t, "+233241234567", merged.PhoneNumber)
+
+	// Both accounts are persisted
+	storedDuplicate, err := repo.FindByID(duplicate.ID)
+	require.NoError(t, err)
+	assert.True(t, storedDuplicate.IsMerged())
+	require.NotNil(t, storedDuplicate.MergedIntoID)
+	assert.Equal(t, primary.ID, *storedDuplicate.MergedIntoID)
+
+	// The duplicate's phone number now resolves to the primary account only
+	storedPrimary, err := repo.FindByID(primary.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "+233241234567", storedPrimary.PhoneNumber)
+
+	// UserMerged is published for other contexts
+	published := bus.eventsOfType(domain.UserMergedEvent)
+	require.Len(t, published, 1)
+	assert.Equal(t, primary.ID.String(), published[0].AggregateID)
+
+	var payload domain.UserMerged
+	require.NoError(t, events.ParseEventData(published[0], &payload))
+	assert.Equal(t, primary.ID, payload.PrimaryUserID)
+	assert.Equal(t, duplicate.ID, payload.DuplicateUserID)
+	assert.Equal(t, ids.UserID("admin-1"), payload.MergedBy)
+	assert.Equal(t, "same person signed up with email and phone", payload.Reason)
+}
+
+func TestUserService_MergeUsers_NotFound(t *testing.T) {
+	primary := newActiveUser(t, "ama@example.com")
+	repo := newFakeUserRepository(primary)
+	bus := &fakeEventBus{}
+	service := app.NewUserService(repo, bus, nil)
+
+	_, err := service.MergeUsers(context.Background(), app.MergeUsersCommand{
+		PrimaryUserID:   primary.ID,
+		DuplicateUserID: "missing",
+		Reason:          "duplicate",
+	})
+	require.Error(t, err)
+	domainErr, ok := err.(*errors.DomainError)
+	require.True(t, ok)
+	assert.Equal(t, errors.ErrCodeNotFound, domainErr.Code)
+
+	_, err = service.MergeUsers(context.Background(), app.MergeUsersCommand{
+		PrimaryUserID:   "missing",
+		DuplicateUserID: primary.ID,
+		Reason:          "duplicate",
+	})
+	assert.Error(t, err)
+	assert.Empty(t, bus.published)
+}
+
+func TestUserService_MergeUsers_RejectsInvalidMerge(t *testing.T) {
+	primary := newActiveUser(t, "ama@example.com")
+	duplicate := newActiveUser(t, "ama2@example.com")
+	require.NoError(t, duplicate.Delete(time.Now()))
+
+	repo := newFakeUserRepository(primary, duplicate)
+	bus := &fakeEventBus{}
+	service := app.NewUserService(repo, bus, nil)
+
+	_, err := service.MergeUsers(context.Background(), app.MergeUsersCommand{
+		PrimaryUserID:   primary.ID,
+		DuplicateUserID: duplicate.ID,
+		Reason:          "duplicate",
+	})
+	assert.Error(t, err)
+	assert.Empty(t, bus.published)
+
+	// Merging the same account twice is rejected
+	other := newActiveUser(t, "ama3@example.com")
+	require.NoError(t, repo.Save(other))
+	_, err = service.MergeUsers(context.Background(), app.MergeUsersCommand{
+		PrimaryUserID:   primary.ID,
+		DuplicateUserID: other.ID,
+		Reason:          "duplicate",
+	})
+	require.NoError(t, err)
+	_, err = service.MergeUsers(context.Background(), app.MergeUsersCommand{
+		PrimaryUserID:   primary.ID,
+		DuplicateUserID: other.ID,
+		Reason:          "duplicate",
+	})
+	assert.Error(t, err)
+	assert.Len(t, bus.eventsOfType(domain.UserMergedEvent), 1)
+}
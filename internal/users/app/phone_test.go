@@ -0,0 +1,65 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_RegisterUser_PhoneNumber(t *testing.T) {
+	service := app.NewUserService(newFakeUserRepository(), &fakeEventBus{}, nil)
+	ctx := context.Background()
+
+	user, err := service.RegisterUser(ctx, app.RegisterUserCommand{
+		Email: "ama@example.com", Password: "password123", FirstName: "Ama", LastName: "Mensah",
+		PhoneNumber: "024 123 4567",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "+233241234567", user.PhoneNumber)
+
+	// The same number in another format is a duplicate
+	_, err = service.RegisterUser(ctx, app.RegisterUserCommand{
+		Email: "kofi@example.com", Password: "password123", FirstName: "Kofi", LastName: "Boateng",
+		PhoneNumber: "+233241234567",
+	})
+	assert.Error(t, err)
+}
+
+func TestUserService_NormalizePhoneNumbers(t *testing.T) {
+	local := newActiveUser(t, "local@example.com")
+	local.PhoneNumber = "0241234567"
+	local.PhoneVerified = true
+	normalized := newActiveUser(t, "normalized@example.com")
+	normalized.PhoneNumber = "+233201234567"
+	duplicate := newActiveUser(t, "duplicate@example.com")
+	duplicate.PhoneNumber = "020 123 4567"
+	invalid := newActiveUser(t, "invalid@example.com")
+	invalid.PhoneNumber = "n/a"
+	noPhone := newActiveUser(t, "nophone@example.com")
+
+	repo := newFakeUserRepository(local, normalized, duplicate, invalid, noPhone)
+	service := app.NewUserService(repo, &fakeEventBus{}, nil)
+	ctx := context.Background()
+
+	// A dry run reports without writing
+	summary, err := service.NormalizePhoneNumbers(ctx, 2, true)
+	require.NoError(t, err)
+	assert.Equal(t, 4, summary.Scanned)
+	assert.Equal(t, 1, summary.Normalized)
+	assert.Equal(t, "0241234567", local.PhoneNumber)
+
+	summary, err = service.NormalizePhoneNumbers(ctx, 2, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Normalized)
+	assert.Equal(t, []ids.UserID{invalid.ID}, summary.Invalid)
+	assert.Equal(t, []ids.UserID{duplicate.ID}, summary.Conflicts)
+
+	assert.Equal(t, "+233241234567", local.PhoneNumber)
+	assert.True(t, local.PhoneVerified)
+	assert.Equal(t, "020 123 4567", duplicate.PhoneNumber)
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"time"
+
+	"dongome/pkg/rules"
+)
+
+const (
+	// AutoSuspendRule is the business rule deciding whether a user is
+	// suspended automatically after being blocked
+	AutoSuspendRule = "users.auto_suspend"
+
+	// AutoSuspendWindow is the period blocks are counted over for AutoSuspendRule
+	AutoSuspendWindow = 30 * 24 * time.Hour
+
+	// autoSuspendReason is the suspension reason recorded for automatic suspensions
+	autoSuspendReason = "Automatically suspended after being blocked by other users"
+)
+
+// AutoSuspendDefinition declares AutoSuspendRule. Until an administrator
+// changes it, nobody is suspended automatically.
+func AutoSuspendDefinition() rules.Definition {
+	return rules.Definition{
+		Key:         AutoSuspendRule,
+		Description: "Whether a user is suspended automatically when someone blocks them",
+		Kind:        rules.KindBool,
+		Variables: []rules.Variable{
+			{Name: "blocks_received", Kind: rules.KindNumber, Description: "how many users blocked them in the last 30 days"},
+			{Name: "account_age_days", Kind: rules.KindNumber, Description: "how many days ago they signed up"},
+			{Name: "email_verified", Kind: rules.KindBool, Description: "they verified their email"},
+		},
+		Default: "false",
+	}
+}
+
+// AutoSuspendFacts are the facts about a blocked user that AutoSuspendRule uses
+func AutoSuspendFacts(blocksReceived int, accountAge time.Duration, emailVerified bool) rules.Facts {
+	return rules.Facts{
+		"blocks_received":  blocksReceived,
+		"account_age_days": int(accountAge.Hours() / 24),
+		"email_verified":   emailVerified,
+	}
+}
+
+// PolicyRules evaluates business rules administrators can change. It is
+// implemented by rules.Engine.
+type PolicyRules interface {
+	Bool(key string, facts rules.Facts) bool
+}
@@ -0,0 +1,230 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// UpdatePreferencesCommand represents the command to change a user's preferences.
+// Omitted fields are left unchanged.
+type UpdatePreferencesCommand struct {
+	UserID              ids.UserID `json:"-"`
+	Language            *string    `json:"language" binding:"omitempty,oneof=en tw ee ha fr"`
+	Currency            *string    `json:"currency" binding:"omitempty,oneof=GHS USD EUR GBP NGN"`
+	DefaultRegion       *string    `json:"default_region"`
+	MarketingOptIn      *bool      `json:"marketing_opt_in"`
+	NotifyMessages      *bool      `json:"notify_messages"`
+	NotifyOffers        *bool      `json:"notify_offers"`
+	NotifyOrderUpdates  *bool      `json:"notify_order_updates"`
+	NotifySavedSearches *bool      `json:"notify_saved_searches"`
+	HideOnlineStatus    *bool      `json:"hide_online_status"`
+}
+
+// UpdateNotificationChannelsCommand represents the command to change which
+// channels each notification category is sent on. Omitted categories and
+// channels are left unchanged.
+type UpdateNotificationChannelsCommand struct {
+	UserID   ids.UserID                                                   `json:"-"`
+	Channels map[domain.NotificationCategory]domain.ChannelSettingsUpdate `json:"channels" binding:"required"`
+}
+
+// UpdateBrowsePreferencesCommand represents the command to replace a user's
+// browse settings. Categories left out of CategoryFilters are forgotten.
+type UpdateBrowsePreferencesCommand struct {
+	UserID          ids.UserID                      `json:"-"`
+	DefaultSort     string                          `json:"default_sort" binding:"required,oneof=relevance newest price_asc price_desc"`
+	ViewDensity     string                          `json:"view_density" binding:"required,oneof=comfortable compact list"`
+	CategoryFilters map[string]domain.BrowseFilters `json:"category_filters"`
+}
+
+// PreferencesService handles user preference use cases
+type PreferencesService struct {
+	userRepo        domain.UserRepository
+	preferencesRepo domain.UserPreferencesRepository
+	eventBus        events.EventBus
+}
+
+// NewPreferencesService creates a new preferences service
+func NewPreferencesService(userRepo domain.UserRepository, preferencesRepo domain.UserPreferencesRepository, eventBus events.EventBus) *PreferencesService {
+	return &PreferencesService{
+		userRepo:        userRepo,
+		preferencesRepo: preferencesRepo,
+		eventBus:        eventBus,
+	}
+}
+
+// GetPreferences retrieves a user's preferences, falling back to the defaults
+func (s *PreferencesService) GetPreferences(ctx context.Context, userID ids.UserID) (*domain.UserPreferences, error) {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return nil, errors.NotFoundError("user not found")
+	}
+
+	preferences, err := s.preferencesRepo.FindByUser(userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return domain.DefaultUserPreferences(userID), nil
+		}
+		return nil, err
+	}
+	preferences.FillChannelDefaults()
+	preferences.FillBrowseDefaults()
+	return preferences, nil
+}
+
+// GetBrowsePreferences retrieves a user's browse settings, falling back to the defaults
+func (s *PreferencesService) GetBrowsePreferences(ctx context.Context, userID ids.UserID) (*domain.BrowsePreferences, error) {
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &preferences.Browse, nil
+}
+
+// UpdateBrowsePreferences replaces a user's browse settings
+func (s *PreferencesService) UpdateBrowsePreferences(ctx context.Context, cmd UpdateBrowsePreferencesCommand) (*domain.BrowsePreferences, error) {
+	preferences, err := s.GetPreferences(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = preferences.ReplaceBrowse(domain.BrowsePreferences{
+		DefaultSort:     cmd.DefaultSort,
+		ViewDensity:     domain.ViewDensity(cmd.ViewDensity),
+		CategoryFilters: cmd.CategoryFilters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.preferencesRepo.Save(preferences) }); err != nil {
+		return nil, err
+	}
+
+	return &preferences.Browse, nil
+}
+
+// NotificationChannels returns the channels a user wants a category of
+// notifications on, or none if they switched the category off. Other contexts
+// check it before contacting users.
+func (s *PreferencesService) NotificationChannels(ctx context.Context, userID ids.UserID, category string) ([]string, error) {
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return channelNames(preferences.EnabledChannels(domain.NotificationCategory(category))), nil
+}
+
+// PreferredCurrency returns the currency a user wants prices shown in. Other
+// contexts use it to convert prices for display.
+func (s *PreferencesService) PreferredCurrency(ctx context.Context, userID ids.UserID) (string, error) {
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return preferences.Currency, nil
+}
+
+// UpdatePreferences applies a partial update to a user's preferences
+func (s *PreferencesService) UpdatePreferences(ctx context.Context, cmd UpdatePreferencesCommand) (*domain.UserPreferences, error) {
+	preferences, err := s.GetPreferences(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := preferences.Apply(domain.PreferencesUpdate{
+		Language:            cmd.Language,
+		Currency:            cmd.Currency,
+		DefaultRegion:       cmd.DefaultRegion,
+		MarketingOptIn:      cmd.MarketingOptIn,
+		NotifyMessages:      cmd.NotifyMessages,
+		NotifyOffers:        cmd.NotifyOffers,
+		NotifyOrderUpdates:  cmd.NotifyOrderUpdates,
+		NotifySavedSearches: cmd.NotifySavedSearches,
+		HideOnlineStatus:    cmd.HideOnlineStatus,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return preferences, nil
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.preferencesRepo.Save(preferences) }); err != nil {
+		return nil, err
+	}
+
+	if err := s.publishPreferencesUpdated(ctx, preferences, changed); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// UpdateNotificationChannels applies a partial update to the channels each
+// notification category is sent on
+func (s *PreferencesService) UpdateNotificationChannels(ctx context.Context, cmd UpdateNotificationChannelsCommand) (*domain.UserPreferences, error) {
+	preferences, err := s.GetPreferences(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := preferences.ApplyChannels(cmd.Channels)
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return preferences, nil
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.preferencesRepo.Save(preferences) }); err != nil {
+		return nil, err
+	}
+
+	if err := s.publishPreferencesUpdated(ctx, preferences, changed); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// publishPreferencesUpdated publishes a UserPreferencesUpdated event so notifications respect opt-outs
+func (s *PreferencesService) publishPreferencesUpdated(ctx context.Context, preferences *domain.UserPreferences, changed []string) error {
+	event, err := events.NewEvent(
+		domain.UserPreferencesUpdatedEvent,
+		preferences.UserID.String(),
+		domain.UserPreferencesUpdated{
+			UserID:               preferences.UserID,
+			Language:             preferences.Language,
+			Currency:             preferences.Currency,
+			DefaultRegion:        preferences.DefaultRegion,
+			MarketingOptIn:       preferences.MarketingOptIn,
+			NotifyMessages:       preferences.NotifyMessages,
+			NotifyOffers:         preferences.NotifyOffers,
+			NotifyOrderUpdates:   preferences.NotifyOrderUpdates,
+			NotifySavedSearches:  preferences.NotifySavedSearches,
+			NotificationChannels: preferences.NotificationChannels,
+			ChangedFields:        changed,
+			Timestamp:            time.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// channelNames converts channels to the plain strings shared with other contexts
+func channelNames(channels []domain.NotificationChannel) []string {
+	names := make([]string, len(channels))
+	for i, channel := range channels {
+		names[i] = string(channel)
+	}
+	return names
+}
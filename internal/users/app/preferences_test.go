@@ -0,0 +1,108 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferencesService_UpdateNotificationChannels(t *testing.T) {
+	user := newActiveUser(t, "buyer@example.com")
+	preferencesRepo := newFakeUserPreferencesRepository()
+	bus := &fakeEventBus{}
+	service := app.NewPreferencesService(newFakeUserRepository(user), preferencesRepo, bus)
+
+	on, off := true, false
+	preferences, err := service.UpdateNotificationChannels(context.Background(), app.UpdateNotificationChannelsCommand{
+		UserID: user.ID,
+		Channels: map[domain.NotificationCategory]domain.ChannelSettingsUpdate{
+			domain.NotificationOffers: {SMS: &on, Email: &off},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, preferences.ChannelSettingsFor(domain.NotificationOffers).SMS)
+	require.Len(t, bus.eventsOfType(domain.UserPreferencesUpdatedEvent), 1)
+
+	stored, err := preferencesRepo.FindByUser(user.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.ChannelSettingsFor(domain.NotificationOffers).Email)
+
+	channels, err := service.NotificationChannels(context.Background(), user.ID, string(domain.NotificationOffers))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sms", "push"}, channels)
+
+	// Repeating the update changes nothing and publishes nothing
+	_, err = service.UpdateNotificationChannels(context.Background(), app.UpdateNotificationChannelsCommand{
+		UserID: user.ID,
+		Channels: map[domain.NotificationCategory]domain.ChannelSettingsUpdate{
+			domain.NotificationOffers: {SMS: &on},
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, bus.eventsOfType(domain.UserPreferencesUpdatedEvent), 1)
+}
+
+func TestPreferencesService_NotificationChannelsOfCategorySwitchedOff(t *testing.T) {
+	user := newActiveUser(t, "buyer@example.com")
+	service := app.NewPreferencesService(newFakeUserRepository(user), newFakeUserPreferencesRepository(), &fakeEventBus{})
+
+	off := false
+	_, err := service.UpdatePreferences(context.Background(), app.UpdatePreferencesCommand{UserID: user.ID, NotifyOrderUpdates: &off})
+	require.NoError(t, err)
+
+	channels, err := service.NotificationChannels(context.Background(), user.ID, string(domain.NotificationOrderUpdates))
+	require.NoError(t, err)
+	assert.Empty(t, channels)
+}
+
+func TestPreferencesService_BrowsePreferences(t *testing.T) {
+	user := newActiveUser(t, "buyer@example.com")
+	preferencesRepo := newFakeUserPreferencesRepository()
+	service := app.NewPreferencesService(newFakeUserRepository(user), preferencesRepo, &fakeEventBus{})
+	ctx := context.Background()
+
+	browse, err := service.GetBrowsePreferences(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultBrowseSort, browse.DefaultSort)
+	assert.Equal(t, domain.DefaultViewDensity, browse.ViewDensity)
+	assert.Empty(t, browse.CategoryFilters)
+
+	maxPrice := 500.0
+	browse, err = service.UpdateBrowsePreferences(ctx, app.UpdateBrowsePreferencesCommand{
+		UserID:      user.ID,
+		DefaultSort: "price_asc",
+		ViewDensity: "compact",
+		CategoryFilters: map[string]domain.BrowseFilters{
+			"phones": {Condition: "like_new", MaxPrice: &maxPrice, Region: "Greater Accra"},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, browse.UpdatedAt)
+
+	// Another device reads the same settings
+	stored, err := service.GetBrowsePreferences(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "price_asc", stored.DefaultSort)
+	assert.Equal(t, domain.ViewDensityCompact, stored.ViewDensity)
+	assert.Equal(t, "like_new", stored.CategoryFilters["phones"].Condition)
+
+	// Invalid filters are rejected and the stored settings are kept
+	minPrice := 900.0
+	_, err = service.UpdateBrowsePreferences(ctx, app.UpdateBrowsePreferencesCommand{
+		UserID:      user.ID,
+		DefaultSort: "newest",
+		ViewDensity: "list",
+		CategoryFilters: map[string]domain.BrowseFilters{
+			"phones": {MinPrice: &minPrice, MaxPrice: &maxPrice},
+		},
+	})
+	assert.Error(t, err)
+
+	stored, err = service.GetBrowsePreferences(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "price_asc", stored.DefaultSort)
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/cache"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+)
+
+// presenceKeyPrefix prefixes the cache keys holding when users were last seen
+const presenceKeyPrefix = "presence:"
+
+// PresenceService tracks when users were last active
+type PresenceService struct {
+	cache           cache.Cache
+	preferencesRepo domain.UserPreferencesRepository
+}
+
+// NewPresenceService creates a new presence service
+func NewPresenceService(cache cache.Cache, preferencesRepo domain.UserPreferencesRepository) *PresenceService {
+	return &PresenceService{
+		cache:           cache,
+		preferencesRepo: preferencesRepo,
+	}
+}
+
+// RecordActivity remembers that a user was just active. It is called for
+// every authenticated request.
+func (s *PresenceService) RecordActivity(ctx context.Context, userID ids.UserID) error {
+	return s.cache.Set(ctx, presenceKeyPrefix+userID.String(), time.Now().UTC(), domain.LastSeenRetention)
+}
+
+// GetPresence returns whether a user is online and when they were last
+// seen. It returns nil for users who chose to hide their online status.
+func (s *PresenceService) GetPresence(ctx context.Context, userID ids.UserID) (*domain.Presence, error) {
+	preferences, err := s.preferencesRepo.FindByUser(userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeNotFound {
+			return nil, err
+		}
+	} else if preferences.HideOnlineStatus {
+		return nil, nil
+	}
+
+	var lastSeen time.Time
+	if err := s.cache.Get(ctx, presenceKeyPrefix+userID.String(), &lastSeen); err != nil && err != cache.ErrCacheMiss {
+		return nil, err
+	}
+	return domain.NewPresence(lastSeen, time.Now()), nil
+}
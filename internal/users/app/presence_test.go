@@ -0,0 +1,38 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenceService(t *testing.T) {
+	hidden := domain.DefaultUserPreferences("seller-b")
+	hidden.HideOnlineStatus = true
+	service := app.NewPresenceService(newFakeCache(), newFakeUserPreferencesRepository(hidden))
+	ctx := context.Background()
+
+	// Users who have not been seen are offline, with no last seen time
+	presence, err := service.GetPresence(ctx, "seller-a")
+	require.NoError(t, err)
+	require.NotNil(t, presence)
+	assert.False(t, presence.Online)
+	assert.Nil(t, presence.LastSeenAt)
+
+	require.NoError(t, service.RecordActivity(ctx, "seller-a"))
+	presence, err = service.GetPresence(ctx, "seller-a")
+	require.NoError(t, err)
+	assert.True(t, presence.Online)
+	assert.NotNil(t, presence.LastSeenAt)
+
+	// Users who opted out are not shown at all
+	require.NoError(t, service.RecordActivity(ctx, "seller-b"))
+	presence, err = service.GetPresence(ctx, "seller-b")
+	require.NoError(t, err)
+	assert.Nil(t, presence)
+}
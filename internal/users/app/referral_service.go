@@ -0,0 +1,215 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// referralCodeAttempts is how many random codes are tried before giving up on
+// collisions with codes already in use
+const referralCodeAttempts = 5
+
+// ListReferralsQuery represents the query to list the users someone referred
+type ListReferralsQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ReferralSummary represents a user's referral code and how their referrals are going
+type ReferralSummary struct {
+	Code string `json:"code"`
+	domain.ReferralCounts
+}
+
+// ReferralList represents a page of a user's referral ledger
+type ReferralList struct {
+	Referrals []*domain.Referral `json:"referrals"`
+	Total     int64              `json:"total"`
+	Limit     int                `json:"limit"`
+	Offset    int                `json:"offset"`
+}
+
+// CompleteReferralCommand represents a paid order that may complete a referral
+type CompleteReferralCommand struct {
+	BuyerID ids.UserID
+	OrderID ids.OrderID
+	Amount  money.Money
+}
+
+// ReferralService handles the referral program
+type ReferralService struct {
+	referralRepo domain.ReferralRepository
+	eventBus     events.EventBus
+}
+
+// NewReferralService creates a new referral service
+func NewReferralService(referralRepo domain.ReferralRepository, eventBus events.EventBus) *ReferralService {
+	return &ReferralService{
+		referralRepo: referralRepo,
+		eventBus:     eventBus,
+	}
+}
+
+// GetReferralSummary returns a user's referral code, creating it on first use,
+// with the number of pending and completed referrals
+func (s *ReferralService) GetReferralSummary(ctx context.Context, userID ids.UserID) (*ReferralSummary, error) {
+	code, err := s.referralCode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.referralRepo.CountByReferrer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReferralSummary{Code: code.Code, ReferralCounts: counts}, nil
+}
+
+// ListReferrals lists the users someone referred, newest first
+func (s *ReferralService) ListReferrals(ctx context.Context, referrerID ids.UserID, query ListReferralsQuery) (*ReferralList, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	referrals, total, err := s.referralRepo.FindByReferrer(referrerID, limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReferralList{
+		Referrals: referrals,
+		Total:     total,
+		Limit:     limit,
+		Offset:    query.Offset,
+	}, nil
+}
+
+// AttributeReferral credits a newly registered user to the owner of the
+// referral code they signed up with. Unknown codes and self-referrals are
+// ignored so that a bad link never gets in the way of registering.
+func (s *ReferralService) AttributeReferral(ctx context.Context, referredID ids.UserID, code string) error {
+	code = domain.NormalizeReferralCode(code)
+	if code == "" {
+		return nil
+	}
+
+	referralCode, err := s.referralRepo.FindCode(code)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return nil
+		}
+		return err
+	}
+
+	referral, err := domain.NewReferral(referralCode, referredID)
+	if err != nil {
+		return nil
+	}
+
+	var created bool
+	err = db.WithRetry(ctx, func() error {
+		var err error
+		created, err = s.referralRepo.Create(referral)
+		return err
+	})
+	if err != nil || !created {
+		return err
+	}
+
+	// Publish ReferralAttributed event
+	event, err := events.NewEvent(
+		domain.ReferralAttributedEvent,
+		referral.ReferredID.String(),
+		domain.ReferralAttributed{
+			ReferralID: referral.ID,
+			ReferrerID: referral.ReferrerID,
+			ReferredID: referral.ReferredID,
+			Code:       referral.Code,
+			Timestamp:  time.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// CompleteReferral completes the referral of a buyer on their first paid
+// order. Buyers who were not referred, or whose referral is already complete,
+// are left alone.
+func (s *ReferralService) CompleteReferral(ctx context.Context, cmd CompleteReferralCommand) error {
+	referral, err := s.referralRepo.FindByReferred(cmd.BuyerID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return nil
+		}
+		return err
+	}
+
+	now := time.Now()
+	if !referral.Complete(cmd.OrderID, cmd.Amount, now) {
+		return nil
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.referralRepo.Update(referral) }); err != nil {
+		return err
+	}
+
+	// Publish ReferralCompleted event so the referrer can be credited
+	event, err := events.NewEvent(
+		domain.ReferralCompletedEvent,
+		referral.ReferredID.String(),
+		domain.ReferralCompleted{
+			ReferralID:  referral.ID,
+			ReferrerID:  referral.ReferrerID,
+			ReferredID:  referral.ReferredID,
+			OrderID:     cmd.OrderID,
+			OrderAmount: cmd.Amount,
+			Timestamp:   now,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// referralCode finds a user's referral code or creates one
+func (s *ReferralService) referralCode(ctx context.Context, userID ids.UserID) (*domain.ReferralCode, error) {
+	for attempt := 0; attempt < referralCodeAttempts; attempt++ {
+		existing, err := s.referralRepo.FindCodeByUser(userID)
+		if err == nil {
+			return existing, nil
+		}
+		if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeNotFound {
+			return nil, err
+		}
+
+		code, err := domain.NewReferralCode(userID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = db.WithRetry(ctx, func() error { return s.referralRepo.SaveCode(code) })
+		if err == nil {
+			return code, nil
+		}
+		// A conflict means the code is taken or another request created the
+		// user's code first; the next attempt finds out which
+		if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeConflict {
+			return nil, err
+		}
+	}
+	return nil, errors.UnavailableError("could not create a referral code, please try again")
+}
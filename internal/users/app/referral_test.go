@@ -0,0 +1,69 @@
+package app_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferralService_ReferralLifecycle(t *testing.T) {
+	repo := newFakeReferralRepository()
+	bus := &fakeEventBus{}
+	service := app.NewReferralService(repo, bus)
+	ctx := context.Background()
+
+	summary, err := service.GetReferralSummary(ctx, "referrer-1")
+	require.NoError(t, err)
+	assert.Len(t, summary.Code, domain.ReferralCodeLength)
+
+	// The code is created once and reused
+	again, err := service.GetReferralSummary(ctx, "referrer-1")
+	require.NoError(t, err)
+	assert.Equal(t, summary.Code, again.Code)
+
+	// Codes are matched regardless of case and surrounding spaces
+	require.NoError(t, service.AttributeReferral(ctx, "buyer-1", " "+strings.ToLower(summary.Code)+" "))
+	require.Len(t, bus.eventsOfType(domain.ReferralAttributedEvent), 1)
+
+	// A user is only ever referred once
+	require.NoError(t, service.AttributeReferral(ctx, "buyer-1", summary.Code))
+	assert.Len(t, bus.eventsOfType(domain.ReferralAttributedEvent), 1)
+
+	// Unknown codes and self-referrals are ignored
+	require.NoError(t, service.AttributeReferral(ctx, "buyer-2", "NOPE2345"))
+	require.NoError(t, service.AttributeReferral(ctx, "referrer-1", summary.Code))
+	assert.Len(t, bus.eventsOfType(domain.ReferralAttributedEvent), 1)
+
+	// The first paid order completes the referral; later orders do not
+	require.NoError(t, service.CompleteReferral(ctx, app.CompleteReferralCommand{BuyerID: "buyer-1", OrderID: "order-1", Amount: money.Cedis(250)}))
+	require.NoError(t, service.CompleteReferral(ctx, app.CompleteReferralCommand{BuyerID: "buyer-1", OrderID: "order-2", Amount: money.Cedis(90)}))
+	completed := bus.eventsOfType(domain.ReferralCompletedEvent)
+	require.Len(t, completed, 1)
+
+	var data domain.ReferralCompleted
+	require.NoError(t, events.ParseEventData(completed[0], &data))
+	assert.Equal(t, ids.UserID("referrer-1"), data.ReferrerID)
+	assert.Equal(t, ids.OrderID("order-1"), data.OrderID)
+
+	// Buyers who were not referred are left alone
+	require.NoError(t, service.CompleteReferral(ctx, app.CompleteReferralCommand{BuyerID: "buyer-3", OrderID: "order-3"}))
+
+	summary, err = service.GetReferralSummary(ctx, "referrer-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), summary.Pending)
+	assert.Equal(t, int64(1), summary.Completed)
+
+	list, err := service.ListReferrals(ctx, "referrer-1", app.ListReferralsQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), list.Total)
+	assert.Equal(t, domain.ReferralStatusCompleted, list.Referrals[0].Status)
+}
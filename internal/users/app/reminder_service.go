@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// VerificationReminderService handles the email verification reminder sequence
+type VerificationReminderService struct {
+	userRepo     domain.UserRepository
+	reminderRepo domain.VerificationReminderRepository
+	eventBus     events.EventBus
+	maxPerUser   int
+	clock        clock.Clock
+}
+
+// NewVerificationReminderService creates a new verification reminder service
+// sending at most maxPerUser reminders to each user. clk may be nil to use
+// the system clock.
+func NewVerificationReminderService(userRepo domain.UserRepository, reminderRepo domain.VerificationReminderRepository, eventBus events.EventBus, maxPerUser int, clk clock.Clock) *VerificationReminderService {
+	return &VerificationReminderService{
+		userRepo:     userRepo,
+		reminderRepo: reminderRepo,
+		eventBus:     eventBus,
+		maxPerUser:   maxPerUser,
+		clock:        clock.OrSystem(clk),
+	}
+}
+
+// ScheduleReminders starts the reminder sequence of a newly registered user.
+// A user only ever gets one sequence, so redelivered events are ignored.
+func (s *VerificationReminderService) ScheduleReminders(ctx context.Context, userID ids.UserID, registeredAt time.Time) error {
+	if s.maxPerUser == 0 {
+		return nil
+	}
+	reminder := domain.NewVerificationReminder(userID, registeredAt)
+	return db.WithRetry(ctx, func() error {
+		_, err := s.reminderRepo.Create(reminder)
+		return err
+	})
+}
+
+// StopReminders ends a user's reminder sequence once they have verified their email
+func (s *VerificationReminderService) StopReminders(ctx context.Context, userID ids.UserID) error {
+	reminder, err := s.reminderRepo.FindByUserID(userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return nil
+		}
+		return err
+	}
+
+	reminder.MarkVerified(s.clock.Now())
+	return db.WithRetry(ctx, func() error { return s.reminderRepo.Update(reminder) })
+}
+
+// SendDueReminders sends up to limit due reminders and returns how many were
+// sent. Sequences of users who verified or can no longer verify are ended
+// instead.
+func (s *VerificationReminderService) SendDueReminders(ctx context.Context, limit int) (int, error) {
+	now := s.clock.Now()
+	reminders, err := s.reminderRepo.FindDue(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, reminder := range reminders {
+		ok, err := s.sendReminder(ctx, reminder, now)
+		if err != nil {
+			return sent, err
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendReminder sends one reminder and reports whether it was sent
+func (s *VerificationReminderService) sendReminder(ctx context.Context, reminder *domain.VerificationReminder, now time.Time) (bool, error) {
+	user, err := s.userRepo.FindByID(reminder.UserID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			reminder.Cancel(now)
+			return false, db.WithRetry(ctx, func() error { return s.reminderRepo.Update(reminder) })
+		}
+		return false, err
+	}
+
+	switch {
+	case user.EmailVerified:
+		reminder.MarkVerified(now)
+		return false, db.WithRetry(ctx, func() error { return s.reminderRepo.Update(reminder) })
+	case user.Status != domain.UserStatusPending:
+		reminder.Cancel(now)
+		return false, db.WithRetry(ctx, func() error { return s.reminderRepo.Update(reminder) })
+	}
+
+	// Refresh the token if it expired; a user who just asked for a new email
+	// is within the resend cooldown and gets the reminder on a later run
+	if err := user.ResendVerification(now); err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeRateLimited {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return false, err
+	}
+
+	reminder.MarkSent(now, s.maxPerUser)
+	if err := db.WithRetry(ctx, func() error { return s.reminderRepo.Update(reminder) }); err != nil {
+		return false, err
+	}
+
+	// Publish VerificationReminderSent event so the notifications context sends the email
+	event, err := events.NewEvent(
+		domain.VerificationReminderSentEvent,
+		user.ID.String(),
+		domain.VerificationReminderSent{
+			UserID:            user.ID,
+			Email:             user.Email,
+			FirstName:         user.FirstName,
+			VerificationToken: user.VerificationToken,
+			Locale:            string(user.PreferredLocale()),
+			ExpiresAt:         *user.VerificationExpiresAt,
+			Step:              reminder.Step,
+			Timestamp:         now,
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReminderStats reports the verification conversion of each reminder step
+func (s *VerificationReminderService) ReminderStats(ctx context.Context) ([]domain.ReminderStepStats, error) {
+	counts, err := s.reminderRepo.CountBySteps()
+	if err != nil {
+		return nil, err
+	}
+	return domain.ReminderConversion(counts), nil
+}
@@ -0,0 +1,98 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPendingUser creates an unverified user who registered a day ago, so
+// their verification token has expired
+func newPendingUser(t *testing.T, email string) *domain.User {
+	t.Helper()
+	user, err := domain.NewUser(email, "password123", "Ama", "Mensah", time.Now())
+	require.NoError(t, err)
+	dayAgo := time.Now().Add(-25 * time.Hour)
+	expiredAt := dayAgo.Add(domain.VerificationTokenTTL)
+	user.VerificationSentAt = &dayAgo
+	user.VerificationExpiresAt = &expiredAt
+	return user
+}
+
+func TestVerificationReminderService_SendDueReminders(t *testing.T) {
+	pending := newPendingUser(t, "pending@example.com")
+	verified := newActiveUser(t, "verified@example.com")
+	recent := newPendingUser(t, "recent@example.com")
+	token := pending.VerificationToken
+
+	userRepo := newFakeUserRepository(pending, verified, recent)
+	reminderRepo := newFakeVerificationReminderRepository()
+	bus := &fakeEventBus{}
+	service := app.NewVerificationReminderService(userRepo, reminderRepo, bus, 3, nil)
+	ctx := context.Background()
+
+	dayAgo := time.Now().Add(-25 * time.Hour)
+	require.NoError(t, service.ScheduleReminders(ctx, pending.ID, dayAgo))
+	require.NoError(t, service.ScheduleReminders(ctx, verified.ID, dayAgo))
+	require.NoError(t, service.ScheduleReminders(ctx, recent.ID, time.Now()))
+	// Redelivered registrations do not restart the sequence
+	require.NoError(t, service.ScheduleReminders(ctx, pending.ID, time.Now()))
+
+	sent, err := service.SendDueReminders(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	reminders := bus.eventsOfType(domain.VerificationReminderSentEvent)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, pending.ID.String(), reminders[0].AggregateID)
+
+	// The token is refreshed since the registration token expired
+	assert.NotEqual(t, token, pending.VerificationToken)
+	assert.False(t, pending.VerificationTokenExpired(time.Now()))
+
+	reminder, err := reminderRepo.FindByUserID(verified.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReminderStatusVerified, reminder.Status)
+	assert.Zero(t, reminder.Step)
+
+	// Nothing else is due until the next step
+	sent, err = service.SendDueReminders(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+}
+
+func TestVerificationReminderService_StopRemindersAndStats(t *testing.T) {
+	user := newPendingUser(t, "ama@example.com")
+	reminderRepo := newFakeVerificationReminderRepository()
+	service := app.NewVerificationReminderService(newFakeUserRepository(user), reminderRepo, &fakeEventBus{}, 3, nil)
+	ctx := context.Background()
+
+	require.NoError(t, service.ScheduleReminders(ctx, user.ID, time.Now().Add(-25*time.Hour)))
+	_, err := service.SendDueReminders(ctx, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, service.StopReminders(ctx, user.ID))
+	require.NoError(t, service.StopReminders(ctx, "unknown-user"))
+
+	stats, err := service.ReminderStats(ctx)
+	require.NoError(t, err)
+	require.Len(t, stats, len(domain.VerificationReminderSchedule)+1)
+	assert.Equal(t, int64(1), stats[1].Reached)
+	assert.Equal(t, int64(1), stats[1].Verified)
+	assert.Equal(t, 1.0, stats[1].ConversionRate)
+}
+
+func TestVerificationReminderService_Disabled(t *testing.T) {
+	reminderRepo := newFakeVerificationReminderRepository()
+	service := app.NewVerificationReminderService(newFakeUserRepository(), reminderRepo, &fakeEventBus{}, 0, nil)
+
+	require.NoError(t, service.ScheduleReminders(context.Background(), "user-1", time.Now()))
+	_, err := reminderRepo.FindByUserID("user-1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_SearchUsers(t *testing.T) {
+	ama := newActiveUser(t, "ama@example.com")
+	require.NoError(t, ama.SetPhoneNumber("0241234567", time.Now()))
+	kofi := newActiveUser(t, "kofi@example.com")
+	kofi.FirstName, kofi.LastName = "Kofi", "Boateng"
+	deleted := newActiveUser(t, "kofi.old@example.com")
+	deleted.FirstName, deleted.LastName = "Kofi", "Boateng"
+	require.NoError(t, deleted.Delete(time.Now()))
+
+	service := app.NewUserService(newFakeUserRepository(ama, kofi, deleted), &fakeEventBus{}, nil)
+	ctx := context.Background()
+
+	results, err := service.SearchUsers(ctx, app.SearchUsersQuery{Q: "+233 24 123 4567"})
+	require.NoError(t, err)
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, ama.ID, results.Results[0].User.ID)
+	assert.Equal(t, domain.UserSearchFieldPhoneNumber, results.Results[0].Match.Field)
+	assert.Equal(t, 20, results.Limit)
+
+	results, err = service.SearchUsers(ctx, app.SearchUsersQuery{Q: "boateng"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, results.Total)
+	assert.Equal(t, domain.UserSearchFieldName, results.Results[0].Match.Field)
+
+	// Deleted accounts are found only when asked for
+	results, err = service.SearchUsers(ctx, app.SearchUsersQuery{Q: "boateng", IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, results.Total)
+
+	_, err = service.SearchUsers(ctx, app.SearchUsersQuery{Q: "k"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"context"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/users/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/events"
+	"dongome/pkg/ids"
+)
+
+// StoreLocationInput is a store location picked on a map. The coordinates
+// come from the pin, the names are checked against the location reference data.
+type StoreLocationInput struct {
+	Region    string  `json:"region" binding:"required"`
+	City      string  `json:"city" binding:"required"`
+	Area      string  `json:"area"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// UpgradeToSellerCommand represents the command to upgrade user to seller
+type UpgradeToSellerCommand struct {
+	UserID          ids.UserID             `json:"user_id" binding:"required"`
+	BusinessName    string                 `json:"business_name" binding:"required"`
+	BusinessAddress string                 `json:"business_address" binding:"required"`
+	BusinessHours   []domain.OpeningPeriod `json:"business_hours" binding:"omitempty,max=14"`
+	StoreLocation   *StoreLocationInput    `json:"store_location"`
+}
+
+// UpdateSellerProfileCommand represents the command to edit a seller's
+// business details. Omitted hours or store location are removed.
+type UpdateSellerProfileCommand struct {
+	UserID          ids.UserID             `json:"-"`
+	BusinessName    string                 `json:"business_name" binding:"required"`
+	BusinessAddress string                 `json:"business_address"`
+	BusinessPhone   string                 `json:"business_phone"`
+	BusinessEmail   string                 `json:"business_email" binding:"omitempty,email"`
+	BusinessHours   []domain.OpeningPeriod `json:"business_hours" binding:"omitempty,max=14"`
+	StoreLocation   *StoreLocationInput    `json:"store_location"`
+}
+
+// SellerProfileService handles becoming a seller and editing seller profiles
+type SellerProfileService struct {
+	userRepo  domain.UserRepository
+	locations LocationValidator
+	eventBus  events.EventBus
+	clock     clock.Clock
+}
+
+// NewSellerProfileService creates a new seller profile service. clk may be
+// nil to use the system clock.
+func NewSellerProfileService(userRepo domain.UserRepository, locations LocationValidator, eventBus events.EventBus, clk clock.Clock) *SellerProfileService {
+	return &SellerProfileService{
+		userRepo:  userRepo,
+		locations: locations,
+		eventBus:  eventBus,
+		clock:     clock.OrSystem(clk),
+	}
+}
+
+// UpgradeToSeller upgrades a user to seller
+func (s *SellerProfileService) UpgradeToSeller(ctx context.Context, cmd UpgradeToSellerCommand) error {
+	// Find user
+	user, err := s.userRepo.FindByID(cmd.UserID)
+	if err != nil {
+		return errors.NotFoundError("user not found")
+	}
+
+	// Check the store details before changing the user
+	location, err := s.storeLocation(ctx, cmd.StoreLocation)
+	if err != nil {
+		return err
+	}
+	if _, err := domain.NormalizeBusinessHours(cmd.BusinessHours); err != nil {
+		return err
+	}
+
+	// Upgrade to seller
+	if err := user.UpgradeToSeller(cmd.BusinessName, cmd.BusinessAddress, s.clock.Now()); err != nil {
+		return err
+	}
+	if err := user.SellerProfile.UpdateDetails(domain.SellerProfileDetails{
+		BusinessName:    cmd.BusinessName,
+		BusinessAddress: cmd.BusinessAddress,
+		BusinessHours:   cmd.BusinessHours,
+		StoreLocation:   location,
+	}); err != nil {
+		return err
+	}
+
+	// Update user
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return err
+	}
+
+	// Publish UserUpgradedToSeller event
+	event, err := events.NewEvent(
+		domain.UserUpgradedToSellerEvent,
+		user.ID.String(),
+		domain.UserUpgradedToSeller{
+			UserID:       user.ID,
+			Email:        user.Email,
+			BusinessName: cmd.BusinessName,
+			Timestamp:    s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.eventBus.Publish(ctx, event)
+}
+
+// UpdateSellerProfile replaces a seller's business details, opening hours
+// and store location
+func (s *SellerProfileService) UpdateSellerProfile(ctx context.Context, cmd UpdateSellerProfileCommand) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.SellerProfile == nil {
+		return nil, errors.ValidationError("user is not a seller")
+	}
+
+	location, err := s.storeLocation(ctx, cmd.StoreLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.SellerProfile.UpdateDetails(domain.SellerProfileDetails{
+		BusinessName:    cmd.BusinessName,
+		BusinessAddress: cmd.BusinessAddress,
+		BusinessPhone:   cmd.BusinessPhone,
+		BusinessEmail:   cmd.BusinessEmail,
+		BusinessHours:   cmd.BusinessHours,
+		StoreLocation:   location,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return nil, err
+	}
+
+	// Publish SellerProfileUpdated event
+	event, err := events.NewEvent(
+		domain.SellerProfileUpdatedEvent,
+		user.ID.String(),
+		domain.SellerProfileUpdated{
+			UserID:           user.ID,
+			BusinessName:     user.SellerProfile.BusinessName,
+			HasBusinessHours: user.SellerProfile.HasBusinessHours(),
+			HasStoreLocation: user.SellerProfile.HasStoreLocation(),
+			Timestamp:        s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// storeLocation checks a store location against the reference data and
+// returns it with canonical names, or nil if none was given
+func (s *SellerProfileService) storeLocation(ctx context.Context, input *StoreLocationInput) (*listings.Location, error) {
+	if input == nil {
+		return nil, nil
+	}
+
+	location, err := s.locations.ValidateLocation(ctx, listings.Location{
+		Region:    input.Region,
+		City:      input.City,
+		Area:      input.Area,
+		Latitude:  input.Latitude,
+		Longitude: input.Longitude,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.ValidateStoreLocation(location); err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
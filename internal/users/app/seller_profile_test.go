@@ -0,0 +1,61 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSellerProfileService_UpgradeAndUpdate(t *testing.T) {
+	user := newActiveUser(t, "kofi@example.com")
+	repo := newFakeUserRepository(user)
+	bus := &fakeEventBus{}
+	locations := &fakeLocationValidator{cities: map[string][]string{"Greater Accra": {"Accra"}}}
+	service := app.NewSellerProfileService(repo, locations, bus, nil)
+	ctx := context.Background()
+	hours := []domain.OpeningPeriod{{Day: "monday", Opens: "08:00", Closes: "17:00"}}
+
+	// An unknown store location is rejected before the user changes
+	err := service.UpgradeToSeller(ctx, app.UpgradeToSellerCommand{
+		UserID:        user.ID,
+		BusinessName:  "Kofi Electronics",
+		StoreLocation: &app.StoreLocationInput{Region: "Atlantis", City: "Accra", Latitude: 5.6, Longitude: -0.18},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, domain.UserRoleBuyer, user.Role)
+
+	err = service.UpgradeToSeller(ctx, app.UpgradeToSellerCommand{
+		UserID:          user.ID,
+		BusinessName:    "Kofi Electronics",
+		BusinessAddress: "Oxford Street",
+		BusinessHours:   hours,
+		StoreLocation:   &app.StoreLocationInput{Region: "Greater Accra", City: "Accra", Latitude: 5.6, Longitude: -0.18},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserRoleSeller, user.Role)
+	assert.Equal(t, hours, user.SellerProfile.BusinessHours)
+	assert.Equal(t, "Accra", user.SellerProfile.StoreLocation.City)
+	assert.Len(t, bus.eventsOfType(domain.UserUpgradedToSellerEvent), 1)
+
+	updated, err := service.UpdateSellerProfile(ctx, app.UpdateSellerProfileCommand{
+		UserID:        user.ID,
+		BusinessName:  "Kofi Electronics Ltd",
+		BusinessPhone: "0241234567",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Kofi Electronics Ltd", updated.SellerProfile.BusinessName)
+	assert.Empty(t, updated.SellerProfile.BusinessHours)
+	assert.False(t, updated.SellerProfile.HasStoreLocation())
+	assert.Len(t, bus.eventsOfType(domain.SellerProfileUpdatedEvent), 1)
+
+	// Only sellers have a profile to edit
+	buyer := newActiveUser(t, "ama@example.com")
+	require.NoError(t, repo.Save(buyer))
+	_, err = service.UpdateSellerProfile(ctx, app.UpdateSellerProfileCommand{UserID: buyer.ID, BusinessName: "Ama"})
+	assert.Error(t, err)
+}
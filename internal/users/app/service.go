@@ -2,19 +2,29 @@ package app
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"dongome/internal/users/domain"
+	"dongome/pkg/clock"
+	"dongome/pkg/db"
 	"dongome/pkg/errors"
 	"dongome/pkg/events"
+	"dongome/pkg/ids"
 )
 
-// RegisterUserCommand represents the command to register a user
+// RegisterUserCommand represents the command to register a user.
+// Handle is optional. ReferralCode is the code of the user who referred
+// them, if any.
 type RegisterUserCommand struct {
-	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=8"`
-	FirstName string `json:"first_name" binding:"required"`
-	LastName  string `json:"last_name" binding:"required"`
+	Email        string `json:"email" binding:"required,email"`
+	Password     string `json:"password" binding:"required,min=8"`
+	FirstName    string `json:"first_name" binding:"required"`
+	LastName     string `json:"last_name" binding:"required"`
+	PhoneNumber  string `json:"phone_number"`
+	Handle       string `json:"handle"`
+	Locale       string `json:"locale"`
+	ReferralCode string `json:"referral_code"`
 }
 
 // LoginCommand represents the command to login a user
@@ -23,24 +33,144 @@ type LoginCommand struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// UpgradeToSellerCommand represents the command to upgrade user to seller
-type UpgradeToSellerCommand struct {
-	UserID          string `json:"user_id" binding:"required"`
-	BusinessName    string `json:"business_name" binding:"required"`
-	BusinessAddress string `json:"business_address" binding:"required"`
+// ResendVerificationCommand represents the command to resend a verification email
+type ResendVerificationCommand struct {
+	Email string `json:"email" binding:"required,email"`
 }
 
+// DeleteUserCommand represents the command to delete a user account
+type DeleteUserCommand struct {
+	UserID ids.UserID `json:"user_id" binding:"required"`
+	Reason string     `json:"reason"`
+}
+
+// MergeUsersCommand represents the command to merge a duplicate account into a primary account
+type MergeUsersCommand struct {
+	PrimaryUserID   ids.UserID `json:"primary_user_id" binding:"required"`
+	DuplicateUserID ids.UserID `json:"duplicate_user_id" binding:"required"`
+	Reason          string     `json:"reason" binding:"required"`
+	MergedBy        ids.UserID `json:"-"`
+}
+
+// RestoreUserCommand represents the command to restore a deleted user account
+type RestoreUserCommand struct {
+	UserID     ids.UserID `json:"-"`
+	RestoredBy ids.UserID `json:"-"`
+}
+
+// SuspendUserCommand represents the command to suspend a user account
+type SuspendUserCommand struct {
+	UserID      ids.UserID `json:"-"`
+	Reason      string     `json:"reason" binding:"required"`
+	SuspendedBy ids.UserID `json:"-"`
+}
+
+// ActivateUserCommand represents the command to lift a user's suspension
+type ActivateUserCommand struct {
+	UserID      ids.UserID `json:"-"`
+	ActivatedBy ids.UserID `json:"-"`
+}
+
+// ReviewSellerVerificationCommand represents an admin's decision on a
+// seller's verification
+type ReviewSellerVerificationCommand struct {
+	UserID     ids.UserID `json:"-"`
+	Status     string     `json:"status" binding:"required,oneof=approved rejected"`
+	Notes      string     `json:"notes"`
+	ReviewedBy ids.UserID `json:"-"`
+}
+
+// SetHandleCommand represents the command to claim, change or remove a
+// user's handle. An empty handle removes it.
+type SetHandleCommand struct {
+	UserID ids.UserID `json:"-"`
+	Handle string     `json:"handle"`
+}
+
+// SetLocaleCommand represents the command to choose or clear the locale a
+// user's responses and emails are written in
+type SetLocaleCommand struct {
+	UserID ids.UserID `json:"-"`
+	Locale string     `json:"locale"`
+}
+
+// HandleAvailability reports whether a handle can be claimed, and why not
+type HandleAvailability struct {
+	Handle    string `json:"handle"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ListUsersQuery represents the query to list users for administration. The
+// signup range may also be given as created_at[gte] and created_at[lte].
+type ListUsersQuery struct {
+	Status             string     `form:"status" binding:"omitempty,oneof=pending active suspended deactive deleted"`
+	Role               string     `form:"role" binding:"omitempty,oneof=buyer seller admin"`
+	EmailVerified      *bool      `form:"email_verified"`
+	VerificationStatus string     `form:"verification_status" binding:"omitempty,oneof=pending approved rejected"`
+	CreatedFrom        *time.Time `form:"created_from" filter:"created_at,gte" time_format:"2006-01-02"`
+	CreatedTo          *time.Time `form:"created_to" filter:"created_at,lte" time_format:"2006-01-02"`
+	IncludeDeleted     bool       `form:"include_deleted"`
+	Limit              int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset             int        `form:"offset" binding:"omitempty,min=0"`
+}
+
+// SearchUsersQuery represents the query to search users by name, email or phone number
+type SearchUsersQuery struct {
+	Q              string `form:"q" binding:"required"`
+	IncludeDeleted bool   `form:"include_deleted"`
+	Limit          int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset         int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// PhoneNormalizationSummary reports the outcome of normalizing stored phone numbers
+type PhoneNormalizationSummary struct {
+	Scanned    int `json:"scanned"`
+	Normalized int `json:"normalized"`
+	// Invalid and Conflicts list the IDs of users whose numbers were left unchanged
+	Invalid   []ids.UserID `json:"invalid"`
+	Conflicts []ids.UserID `json:"conflicts"`
+}
+
+// UserList represents a page of users
+type UserList struct {
+	Users  []*domain.User `json:"users"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// UserSearchResult is a user found by a search, with the field that matched
+type UserSearchResult struct {
+	User  *domain.User            `json:"user"`
+	Match *domain.UserSearchMatch `json:"match,omitempty"`
+}
+
+// UserSearchResults represents a page of search results
+type UserSearchResults struct {
+	Results []*UserSearchResult `json:"results"`
+	Total   int64               `json:"total"`
+	Limit   int                 `json:"limit"`
+	Offset  int                 `json:"offset"`
+}
+
+// defaultPageSize is used when a list query does not specify a limit
+const defaultPageSize = 20
+
 // UserService handles user-related use cases
 type UserService struct {
 	userRepo domain.UserRepository
 	eventBus events.EventBus
+	clock    clock.Clock
 }
 
-// NewUserService creates a new user service
-func NewUserService(userRepo domain.UserRepository, eventBus events.EventBus) *UserService {
+// NewUserService creates a new user service. clk may be nil to use the
+// system clock.
+func NewUserService(userRepo domain.UserRepository, eventBus events.EventBus, clk clock.Clock) *UserService {
 	return &UserService{
 		userRepo: userRepo,
 		eventBus: eventBus,
+		clock:    clock.OrSystem(clk),
 	}
 }
 
@@ -53,20 +183,45 @@ func (s *UserService) RegisterUser(ctx context.Context, cmd RegisterUserCommand)
 	}
 
 	// Create new user
-	user, err := domain.NewUser(cmd.Email, cmd.Password, cmd.FirstName, cmd.LastName)
+	now := s.clock.Now()
+	user, err := domain.NewUser(cmd.Email, cmd.Password, cmd.FirstName, cmd.LastName, now)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := user.SetPhoneNumber(cmd.PhoneNumber, now); err != nil {
+		return nil, err
+	}
+	if user.PhoneNumber != "" {
+		existing, _ := s.userRepo.FindByPhoneNumber(user.PhoneNumber)
+		if existing != nil {
+			return nil, errors.ConflictError("user with this phone number already exists")
+		}
+	}
+
+	if err := user.SetHandle(cmd.Handle); err != nil {
+		return nil, err
+	}
+	if user.Handle != nil {
+		existing, _ := s.userRepo.FindByHandle(*user.Handle)
+		if existing != nil {
+			return nil, errors.ConflictError("handle is already taken")
+		}
+	}
+
+	if err := user.SetLocale(cmd.Locale); err != nil {
+		return nil, err
+	}
+
 	// Save user
-	if err := s.userRepo.Save(user); err != nil {
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Save(user) }); err != nil {
 		return nil, err
 	}
 
 	// Publish UserRegistered event
 	event, err := events.NewEvent(
 		domain.UserRegisteredEvent,
-		user.ID,
+		user.ID.String(),
 		domain.UserRegistered{
 			UserID:            user.ID,
 			Email:             user.Email,
@@ -74,7 +229,9 @@ func (s *UserService) RegisterUser(ctx context.Context, cmd RegisterUserCommand)
 			LastName:          user.LastName,
 			Role:              user.Role,
 			VerificationToken: user.VerificationToken,
-			Timestamp:         time.Now(),
+			Locale:            string(user.PreferredLocale()),
+			ReferralCode:      domain.NormalizeReferralCode(cmd.ReferralCode),
+			Timestamp:         s.clock.Now(),
 		},
 	)
 	if err != nil {
@@ -108,19 +265,19 @@ func (s *UserService) LoginUser(ctx context.Context, cmd LoginCommand) (*domain.
 	}
 
 	// Update last login
-	user.UpdateLastLogin()
-	if err := s.userRepo.Update(user); err != nil {
+	user.UpdateLastLogin(s.clock.Now())
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
 		return nil, err
 	}
 
 	// Publish UserLoggedIn event
 	event, err := events.NewEvent(
 		domain.UserLoggedInEvent,
-		user.ID,
+		user.ID.String(),
 		domain.UserLoggedIn{
 			UserID:    user.ID,
 			Email:     user.Email,
-			Timestamp: time.Now(),
+			Timestamp: s.clock.Now(),
 		},
 	)
 	if err != nil {
@@ -143,19 +300,21 @@ func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
 	}
 
 	// Verify email
-	user.VerifyEmail()
-	if err := s.userRepo.Update(user); err != nil {
+	if err := user.VerifyEmail(s.clock.Now()); err != nil {
+		return err
+	}
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
 		return err
 	}
 
 	// Publish UserEmailVerified event
 	event, err := events.NewEvent(
 		domain.UserEmailVerifiedEvent,
-		user.ID,
+		user.ID.String(),
 		domain.UserEmailVerified{
 			UserID:    user.ID,
 			Email:     user.Email,
-			Timestamp: time.Now(),
+			Timestamp: s.clock.Now(),
 		},
 	)
 	if err != nil {
@@ -165,33 +324,42 @@ func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
 	return s.eventBus.Publish(ctx, event)
 }
 
-// UpgradeToSeller upgrades a user to seller
-func (s *UserService) UpgradeToSeller(ctx context.Context, cmd UpgradeToSellerCommand) error {
-	// Find user
-	user, err := s.userRepo.FindByID(cmd.UserID)
+// ResendVerification sends a new verification email, regenerating the token
+// when it has expired. Unknown and already verified addresses are ignored so
+// the endpoint does not reveal which emails are registered.
+func (s *UserService) ResendVerification(ctx context.Context, cmd ResendVerificationCommand) error {
+	user, err := s.userRepo.FindByEmail(cmd.Email)
 	if err != nil {
-		return errors.NotFoundError("user not found")
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if user.EmailVerified {
+		return nil
 	}
 
-	// Upgrade to seller
-	if err := user.UpgradeToSeller(cmd.BusinessName, cmd.BusinessAddress); err != nil {
+	if err := user.ResendVerification(s.clock.Now()); err != nil {
 		return err
 	}
 
-	// Update user
-	if err := s.userRepo.Update(user); err != nil {
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
 		return err
 	}
 
-	// Publish UserUpgradedToSeller event
+	// Publish VerificationResent event
 	event, err := events.NewEvent(
-		domain.UserUpgradedToSellerEvent,
-		user.ID,
-		domain.UserUpgradedToSeller{
-			UserID:       user.ID,
-			Email:        user.Email,
-			BusinessName: cmd.BusinessName,
-			Timestamp:    time.Now(),
+		domain.VerificationResentEvent,
+		user.ID.String(),
+		domain.VerificationResent{
+			UserID:            user.ID,
+			Email:             user.Email,
+			FirstName:         user.FirstName,
+			VerificationToken: user.VerificationToken,
+			Locale:            string(user.PreferredLocale()),
+			ExpiresAt:         *user.VerificationExpiresAt,
+			Timestamp:         s.clock.Now(),
 		},
 	)
 	if err != nil {
@@ -201,8 +369,579 @@ func (s *UserService) UpgradeToSeller(ctx context.Context, cmd UpgradeToSellerCo
 	return s.eventBus.Publish(ctx, event)
 }
 
+// NormalizePhoneNumbers rewrites stored phone numbers in E.164 form. Numbers
+// that cannot be parsed, or that would duplicate another user's number once
+// normalized, are left unchanged and reported for manual review. With dryRun
+// set, nothing is written.
+func (s *UserService) NormalizePhoneNumbers(ctx context.Context, batchSize int, dryRun bool) (*PhoneNormalizationSummary, error) {
+	summary := &PhoneNormalizationSummary{Invalid: []ids.UserID{}, Conflicts: []ids.UserID{}}
+	claimed := make(map[string]ids.UserID)
+
+	var afterID ids.UserID
+	for {
+		users, err := s.userRepo.FindWithPhoneNumber(afterID, batchSize)
+		if err != nil {
+			return summary, err
+		}
+
+		for _, user := range users {
+			summary.Scanned++
+			afterID = user.ID
+
+			normalized, err := domain.NormalizePhoneNumber(user.PhoneNumber)
+			if err != nil {
+				summary.Invalid = append(summary.Invalid, user.ID)
+				continue
+			}
+			if _, ok := claimed[normalized]; ok {
+				summary.Conflicts = append(summary.Conflicts, user.ID)
+				continue
+			}
+			if normalized != user.PhoneNumber {
+				existing, _ := s.userRepo.FindByPhoneNumber(normalized)
+				if existing != nil && existing.ID != user.ID {
+					summary.Conflicts = append(summary.Conflicts, user.ID)
+					continue
+				}
+			}
+			claimed[normalized] = user.ID
+			if normalized == user.PhoneNumber {
+				continue
+			}
+
+			summary.Normalized++
+			if dryRun {
+				continue
+			}
+
+			// The number is unchanged apart from its format, so it stays verified
+			user.PhoneNumber = normalized
+			user.UpdatedAt = s.clock.Now()
+			if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+				return summary, err
+			}
+		}
+
+		if len(users) < batchSize {
+			return summary, nil
+		}
+	}
+}
+
+// DeleteUser soft-deletes a user account and schedules erasure of its personal data
+func (s *UserService) DeleteUser(ctx context.Context, cmd DeleteUserCommand) (*domain.User, error) {
+	// Find user
+	user, err := s.userRepo.FindByID(cmd.UserID)
+	if err != nil {
+		return nil, errors.NotFoundError("user not found")
+	}
+
+	// Mark account as deleted
+	if err := user.Delete(s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return nil, err
+	}
+
+	// Publish UserDeleted event so other contexts can deactivate the user's data
+	event, err := events.NewEvent(
+		domain.UserDeletedEvent,
+		user.ID.String(),
+		domain.UserDeleted{
+			UserID:             user.ID,
+			Email:              user.Email,
+			Role:               user.Role,
+			Reason:             cmd.Reason,
+			ErasureScheduledAt: user.ErasureScheduledAt(),
+			Timestamp:          s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// AnonymizeDeletedUsers erases personal data of deleted users whose grace period has passed
+func (s *UserService) AnonymizeDeletedUsers(ctx context.Context, batchSize int) (int, error) {
+	now := s.clock.Now()
+	users, err := s.userRepo.FindPendingErasure(now.Add(-domain.ErasureGracePeriod), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	anonymized := 0
+	for _, user := range users {
+		if !user.ErasureDue(now) {
+			continue
+		}
+
+		if err := user.Anonymize(now); err != nil {
+			return anonymized, err
+		}
+
+		if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+			return anonymized, err
+		}
+		anonymized++
+
+		// Publish UserAnonymized event
+		event, err := events.NewEvent(
+			domain.UserAnonymizedEvent,
+			user.ID.String(),
+			domain.UserAnonymized{
+				UserID:    user.ID,
+				Timestamp: s.clock.Now(),
+			},
+		)
+		if err != nil {
+			return anonymized, err
+		}
+
+		if err := s.eventBus.Publish(ctx, event); err != nil {
+			// Log error but don't fail the operation
+		}
+	}
+
+	return anonymized, nil
+}
+
+// ListUsers lists users matching the query for administration
+func (s *UserService) ListUsers(ctx context.Context, query ListUsersQuery) (*UserList, error) {
+	if query.Limit == 0 {
+		query.Limit = defaultPageSize
+	}
+
+	filter := domain.UserFilter{
+		Status:             domain.UserStatus(query.Status),
+		Role:               domain.UserRole(query.Role),
+		EmailVerified:      query.EmailVerified,
+		VerificationStatus: domain.VerificationStatus(query.VerificationStatus),
+		CreatedFrom:        query.CreatedFrom,
+		// Deleted users are hidden unless asked for explicitly
+		IncludeDeleted: query.IncludeDeleted || query.Status == string(domain.UserStatusDeleted),
+	}
+
+	// The end date is inclusive
+	if query.CreatedTo != nil {
+		createdTo := query.CreatedTo.AddDate(0, 0, 1)
+		filter.CreatedTo = &createdTo
+	}
+
+	users, total, err := s.userRepo.FindAll(filter, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserList{
+		Users:  users,
+		Total:  total,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}, nil
+}
+
+// SearchUsers finds users whose name, email or phone number contains the
+// query, reporting which field matched so support can see why a user was found
+func (s *UserService) SearchUsers(ctx context.Context, query SearchUsersQuery) (*UserSearchResults, error) {
+	if query.Limit == 0 {
+		query.Limit = defaultPageSize
+	}
+
+	search, err := domain.NewUserSearch(query.Q, query.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+
+	users, total, err := s.userRepo.Search(search, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*UserSearchResult, 0, len(users))
+	for _, user := range users {
+		result := &UserSearchResult{User: user}
+		if match, ok := search.Match(user); ok {
+			result.Match = &match
+		}
+		results = append(results, result)
+	}
+
+	return &UserSearchResults{
+		Results: results,
+		Total:   total,
+		Limit:   query.Limit,
+		Offset:  query.Offset,
+	}, nil
+}
+
+// MergeUsers consolidates a duplicate account into a primary account
+func (s *UserService) MergeUsers(ctx context.Context, cmd MergeUsersCommand) (*domain.User, error) {
+	primary, err := s.userRepo.FindByID(cmd.PrimaryUserID)
+	if err != nil {
+		return nil, errors.NotFoundError("primary user not found")
+	}
+
+	duplicate, err := s.userRepo.FindByID(cmd.DuplicateUserID)
+	if err != nil {
+		return nil, errors.NotFoundError("duplicate user not found")
+	}
+
+	if err := duplicate.MergeInto(primary, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	// The duplicate is saved first so the identifiers it gave up are free for the primary
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.UpdateAll(duplicate, primary) }); err != nil {
+		return nil, err
+	}
+
+	// Publish UserMerged event so other contexts move the duplicate's data
+	event, err := events.NewEvent(
+		domain.UserMergedEvent,
+		primary.ID.String(),
+		domain.UserMerged{
+			PrimaryUserID:   primary.ID,
+			DuplicateUserID: duplicate.ID,
+			MergedBy:        cmd.MergedBy,
+			Reason:          cmd.Reason,
+			Timestamp:       s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return primary, nil
+}
+
+// RestoreUser reactivates a soft-deleted account before its personal data is erased
+func (s *UserService) RestoreUser(ctx context.Context, cmd RestoreUserCommand) (*domain.User, error) {
+	user, err := s.userRepo.FindByIDIncludingDeleted(cmd.UserID)
+	if err != nil {
+		return nil, errors.NotFoundError("user not found")
+	}
+
+	if err := user.Restore(s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return nil, err
+	}
+
+	// Publish UserRestored event
+	event, err := events.NewEvent(
+		domain.UserRestoredEvent,
+		user.ID.String(),
+		domain.UserRestored{
+			UserID:     user.ID,
+			Email:      user.Email,
+			RestoredBy: cmd.RestoredBy,
+			Timestamp:  s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SuspendUser suspends an account and force-expires its sessions
+func (s *UserService) SuspendUser(ctx context.Context, cmd SuspendUserCommand) (*domain.User, error) {
+	if cmd.UserID == cmd.SuspendedBy {
+		return nil, errors.ValidationError("you cannot suspend your own account")
+	}
+
+	user, err := s.userRepo.FindByID(cmd.UserID)
+	if err != nil {
+		return nil, errors.NotFoundError("user not found")
+	}
+
+	if err := user.Suspend(cmd.Reason, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return nil, err
+	}
+
+	// Publish UserSuspended event
+	event, err := events.NewEvent(
+		domain.UserSuspendedEvent,
+		user.ID.String(),
+		domain.UserSuspended{
+			UserID:      user.ID,
+			Email:       user.Email,
+			Reason:      user.SuspensionReason,
+			SuspendedBy: cmd.SuspendedBy,
+			Timestamp:   s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ActivateUser lifts a user's suspension. Sessions revoked by the suspension
+// stay expired, so the user has to log in again.
+func (s *UserService) ActivateUser(ctx context.Context, cmd ActivateUserCommand) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(cmd.UserID)
+	if err != nil {
+		return nil, errors.NotFoundError("user not found")
+	}
+
+	if err := user.Activate(s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return nil, err
+	}
+
+	// Publish UserActivated event
+	event, err := events.NewEvent(
+		domain.UserActivatedEvent,
+		user.ID.String(),
+		domain.UserActivated{
+			UserID:      user.ID,
+			Email:       user.Email,
+			ActivatedBy: cmd.ActivatedBy,
+			Timestamp:   s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ReviewSellerVerification approves or rejects a seller's verification.
+// Rejected sellers' live listings are taken down until they are approved.
+func (s *UserService) ReviewSellerVerification(ctx context.Context, cmd ReviewSellerVerificationCommand) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(cmd.UserID)
+	if err != nil {
+		return nil, errors.NotFoundError("user not found")
+	}
+
+	if err := user.ReviewVerification(domain.VerificationStatus(cmd.Status), cmd.Notes, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return nil, err
+	}
+
+	var event *events.Event
+	if user.IsVerifiedSeller() {
+		event, err = events.NewEvent(
+			domain.SellerVerifiedEvent,
+			user.ID.String(),
+			domain.SellerVerified{
+				UserID:       user.ID,
+				SellerID:     user.ID,
+				Email:        user.Email,
+				BusinessName: user.SellerProfile.BusinessName,
+				Timestamp:    s.clock.Now(),
+			},
+		)
+	} else {
+		event, err = events.NewEvent(
+			domain.SellerVerificationRejectedEvent,
+			user.ID.String(),
+			domain.SellerVerificationRejected{
+				UserID:     user.ID,
+				Email:      user.Email,
+				Notes:      user.SellerProfile.VerificationNotes,
+				ReviewedBy: cmd.ReviewedBy,
+				Timestamp:  s.clock.Now(),
+			},
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SessionActive checks if an access token issued to a user at issuedAt may
+// still be used. Tokens of missing, inactive or suspended users, and tokens
+// issued before the user's sessions were revoked, are rejected.
+func (s *UserService) SessionActive(ctx context.Context, userID ids.UserID, issuedAt time.Time) (bool, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return user.SessionValid(issuedAt), nil
+}
+
+// SetHandle claims, changes or removes a user's handle
+func (s *UserService) SetHandle(ctx context.Context, cmd SetHandleCommand) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the handle is free before changing the user
+	if strings.TrimSpace(cmd.Handle) != "" {
+		handle, err := domain.NormalizeHandle(cmd.Handle)
+		if err != nil {
+			return nil, err
+		}
+		existing, _ := s.userRepo.FindByHandle(handle)
+		if existing != nil && existing.ID != user.ID {
+			return nil, errors.ConflictError("handle is already taken")
+		}
+	}
+
+	previous := user.HandleValue()
+	if err := user.SetHandle(cmd.Handle); err != nil {
+		return nil, err
+	}
+	if user.HandleValue() == previous {
+		return user, nil
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return nil, err
+	}
+
+	// Publish UserHandleChanged event
+	event, err := events.NewEvent(
+		domain.UserHandleChangedEvent,
+		user.ID.String(),
+		domain.UserHandleChanged{
+			UserID:         user.ID,
+			PreviousHandle: previous,
+			Handle:         user.HandleValue(),
+			Timestamp:      s.clock.Now(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SetLocale chooses or clears the locale a user's responses and emails are
+// written in
+func (s *UserService) SetLocale(ctx context.Context, cmd SetLocaleCommand) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := user.Locale
+	if err := user.SetLocale(cmd.Locale); err != nil {
+		return nil, err
+	}
+	if user.Locale == previous {
+		return user, nil
+	}
+
+	if err := db.WithRetry(ctx, func() error { return s.userRepo.Update(user) }); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// StoredLocale returns the locale a user chose, or an empty string if they
+// have not chosen one
+func (s *UserService) StoredLocale(ctx context.Context, userID ids.UserID) (string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", err
+	}
+	return user.Locale, nil
+}
+
+// CheckHandleAvailability reports whether a handle is well formed, not
+// reserved and not taken. Handles of deleted users stay taken until their
+// data is anonymized.
+func (s *UserService) CheckHandleAvailability(ctx context.Context, raw string) (*HandleAvailability, error) {
+	handle, err := domain.NormalizeHandle(raw)
+	if err != nil {
+		domainErr, ok := err.(*errors.DomainError)
+		if !ok {
+			return nil, err
+		}
+		return &HandleAvailability{Handle: raw, Available: false, Reason: domainErr.Message}, nil
+	}
+
+	_, err = s.userRepo.FindByHandle(handle)
+	if err == nil {
+		return &HandleAvailability{Handle: handle, Available: false, Reason: "handle is already taken"}, nil
+	}
+	if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.ErrCodeNotFound {
+		return nil, err
+	}
+	return &HandleAvailability{Handle: handle, Available: true}, nil
+}
+
+// GetUserByHandle retrieves a user by handle, with or without the leading @
+func (s *UserService) GetUserByHandle(ctx context.Context, raw string) (*domain.User, error) {
+	handle, err := domain.NormalizeHandle(raw)
+	if err != nil {
+		return nil, errors.NotFoundError("user not found")
+	}
+
+	user, err := s.userRepo.FindByHandle(handle)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDeleted() {
+		return nil, errors.NotFoundError("user not found")
+	}
+	return user, nil
+}
+
+// GetUserIncludingDeleted retrieves a user by ID, including soft-deleted users, for administration
+func (s *UserService) GetUserIncludingDeleted(ctx context.Context, userID ids.UserID) (*domain.User, error) {
+	return s.userRepo.FindByIDIncludingDeleted(userID)
+}
+
 // GetUser retrieves a user by ID
-func (s *UserService) GetUser(ctx context.Context, userID string) (*domain.User, error) {
+func (s *UserService) GetUser(ctx context.Context, userID ids.UserID) (*domain.User, error) {
 	return s.userRepo.FindByID(userID)
 }
 
@@ -210,3 +949,15 @@ func (s *UserService) GetUser(ctx context.Context, userID string) (*domain.User,
 func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	return s.userRepo.FindByEmail(email)
 }
+
+// IsVerifiedSeller checks if the user is an active seller with an approved profile
+func (s *UserService) IsVerifiedSeller(ctx context.Context, userID ids.UserID) (bool, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return user.IsActive() && user.IsVerifiedSeller(), nil
+}
@@ -0,0 +1,57 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_DeleteAndRestore(t *testing.T) {
+	user := newActiveUser(t, "ama@example.com")
+	other := newActiveUser(t, "kofi@example.com")
+	repo := newFakeUserRepository(user, other)
+	bus := &fakeEventBus{}
+	service := app.NewUserService(repo, bus, nil)
+	ctx := context.Background()
+
+	_, err := service.DeleteUser(ctx, app.DeleteUserCommand{UserID: user.ID})
+	require.NoError(t, err)
+
+	// Deleted users are hidden from regular lookups and listings
+	_, err = service.GetUser(ctx, user.ID)
+	assert.Error(t, err)
+
+	list, err := service.ListUsers(ctx, app.ListUsersQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), list.Total)
+
+	// ...but remain visible to admins
+	list, err = service.ListUsers(ctx, app.ListUsersQuery{IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), list.Total)
+
+	list, err = service.ListUsers(ctx, app.ListUsersQuery{Status: string(domain.UserStatusDeleted)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), list.Total)
+
+	found, err := service.GetUserIncludingDeleted(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, found.IsDeleted())
+
+	restored, err := service.RestoreUser(ctx, app.RestoreUserCommand{UserID: user.ID, RestoredBy: "admin-1"})
+	require.NoError(t, err)
+	assert.True(t, restored.IsActive())
+	assert.Len(t, bus.eventsOfType(domain.UserRestoredEvent), 1)
+
+	_, err = service.GetUser(ctx, user.ID)
+	assert.NoError(t, err)
+
+	// Restoring an active user fails
+	_, err = service.RestoreUser(ctx, app.RestoreUserCommand{UserID: other.ID})
+	assert.Error(t, err)
+}
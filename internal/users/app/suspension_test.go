@@ -0,0 +1,113 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_SuspendAndActivate(t *testing.T) {
+	user := newActiveUser(t, "ama@example.com")
+	repo := newFakeUserRepository(user)
+	bus := &fakeEventBus{}
+	service := app.NewUserService(repo, bus, nil)
+	ctx := context.Background()
+
+	issuedAt := time.Now().Add(-time.Minute)
+	active, err := service.SessionActive(ctx, user.ID, issuedAt)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	// A reason is required
+	_, err = service.SuspendUser(ctx, app.SuspendUserCommand{UserID: user.ID, Reason: "  ", SuspendedBy: "admin-1"})
+	assert.Error(t, err)
+
+	// Admins cannot suspend themselves
+	_, err = service.SuspendUser(ctx, app.SuspendUserCommand{UserID: user.ID, Reason: "Fraud", SuspendedBy: user.ID})
+	assert.Error(t, err)
+
+	suspended, err := service.SuspendUser(ctx, app.SuspendUserCommand{UserID: user.ID, Reason: "Selling counterfeit goods", SuspendedBy: "admin-1"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserStatusSuspended, suspended.Status)
+	assert.Equal(t, "Selling counterfeit goods", suspended.SuspensionReason)
+	require.NotNil(t, suspended.SuspendedAt)
+
+	published := bus.eventsOfType(domain.UserSuspendedEvent)
+	require.Len(t, published, 1)
+	assert.Equal(t, user.ID.String(), published[0].AggregateID)
+
+	// Existing sessions are expired
+	active, err = service.SessionActive(ctx, user.ID, issuedAt)
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	// Suspending twice conflicts
+	_, err = service.SuspendUser(ctx, app.SuspendUserCommand{UserID: user.ID, Reason: "Again", SuspendedBy: "admin-1"})
+	assert.Error(t, err)
+
+	activated, err := service.ActivateUser(ctx, app.ActivateUserCommand{UserID: user.ID, ActivatedBy: "admin-1"})
+	require.NoError(t, err)
+	assert.True(t, activated.IsActive())
+	assert.Empty(t, activated.SuspensionReason)
+	assert.Nil(t, activated.SuspendedAt)
+	assert.Len(t, bus.eventsOfType(domain.UserActivatedEvent), 1)
+
+	// Sessions from before the suspension stay expired; new logins work
+	active, err = service.SessionActive(ctx, user.ID, issuedAt)
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	active, err = service.SessionActive(ctx, user.ID, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	// Activating an active user fails
+	_, err = service.ActivateUser(ctx, app.ActivateUserCommand{UserID: user.ID})
+	assert.Error(t, err)
+}
+
+func TestUserService_SessionActiveUnknownUser(t *testing.T) {
+	service := app.NewUserService(newFakeUserRepository(), &fakeEventBus{}, nil)
+
+	active, err := service.SessionActive(context.Background(), "missing", time.Now())
+	require.NoError(t, err)
+	assert.False(t, active)
+}
+
+func TestUserService_ReviewSellerVerification(t *testing.T) {
+	user := newActiveUser(t, "ama@example.com")
+	require.NoError(t, user.UpgradeToSeller("Ama's Phones", "Osu, Accra", time.Now()))
+	buyer := newActiveUser(t, "kofi@example.com")
+	bus := &fakeEventBus{}
+	service := app.NewUserService(newFakeUserRepository(user, buyer), bus, nil)
+	ctx := context.Background()
+
+	// Only sellers are verified
+	_, err := service.ReviewSellerVerification(ctx, app.ReviewSellerVerificationCommand{UserID: buyer.ID, Status: "approved", ReviewedBy: "admin-1"})
+	assert.Error(t, err)
+
+	// Rejections must say why
+	_, err = service.ReviewSellerVerification(ctx, app.ReviewSellerVerificationCommand{UserID: user.ID, Status: "rejected", ReviewedBy: "admin-1"})
+	assert.Error(t, err)
+
+	rejected, err := service.ReviewSellerVerification(ctx, app.ReviewSellerVerificationCommand{UserID: user.ID, Status: "rejected", Notes: "Business registration does not match", ReviewedBy: "admin-1"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.VerificationStatusRejected, rejected.SellerProfile.VerificationStatus)
+	assert.Equal(t, "Business registration does not match", rejected.SellerProfile.VerificationNotes)
+	require.Len(t, bus.eventsOfType(domain.SellerVerificationRejectedEvent), 1)
+
+	// Rejecting twice conflicts
+	_, err = service.ReviewSellerVerification(ctx, app.ReviewSellerVerificationCommand{UserID: user.ID, Status: "rejected", Notes: "Again", ReviewedBy: "admin-1"})
+	assert.Error(t, err)
+
+	approved, err := service.ReviewSellerVerification(ctx, app.ReviewSellerVerificationCommand{UserID: user.ID, Status: "approved", ReviewedBy: "admin-1"})
+	require.NoError(t, err)
+	assert.True(t, approved.IsVerifiedSeller())
+	assert.Len(t, bus.eventsOfType(domain.SellerVerifiedEvent), 1)
+}
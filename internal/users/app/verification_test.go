@@ -0,0 +1,44 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_ResendVerification(t *testing.T) {
+	user, err := domain.NewUser("ama@example.com", "password123", "Ama", "Mensah", time.Now())
+	require.NoError(t, err)
+	sentAt := time.Now().Add(-time.Hour)
+	expired := time.Now().Add(-time.Minute)
+	user.VerificationSentAt = &sentAt
+	user.VerificationExpiresAt = &expired
+	oldToken := user.VerificationToken
+
+	bus := &fakeEventBus{}
+	service := app.NewUserService(newFakeUserRepository(user), bus, nil)
+	ctx := context.Background()
+
+	// The expired token cannot be used
+	assert.Error(t, service.VerifyEmail(ctx, oldToken))
+
+	require.NoError(t, service.ResendVerification(ctx, app.ResendVerificationCommand{Email: user.Email}))
+	assert.NotEqual(t, oldToken, user.VerificationToken)
+	assert.Len(t, bus.eventsOfType(domain.VerificationResentEvent), 1)
+
+	// A second request within the cooldown is rejected
+	assert.Error(t, service.ResendVerification(ctx, app.ResendVerificationCommand{Email: user.Email}))
+
+	// Unknown emails are accepted without sending anything
+	require.NoError(t, service.ResendVerification(ctx, app.ResendVerificationCommand{Email: "nobody@example.com"}))
+	assert.Len(t, bus.eventsOfType(domain.VerificationResentEvent), 1)
+
+	require.NoError(t, service.VerifyEmail(ctx, user.VerificationToken))
+	assert.True(t, user.EmailVerified)
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// MaxAddressesPerUser limits the size of a user's address book
+const MaxAddressesPerUser = 10
+
+// digitalAddressPattern matches GhanaPostGPS digital addresses such as GA-123-4567
+var digitalAddressPattern = regexp.MustCompile(`^[A-Z]{2}-\d{3,4}-\d{4}$`)
+
+// Address represents a saved delivery address in a user's address book
+type Address struct {
+	ID             string            `gorm:"type:uuid;primary_key" json:"id"`
+	UserID         ids.UserID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	Label          string            `json:"label"`
+	RecipientName  string            `gorm:"not null" json:"recipient_name"`
+	PhoneNumber    string            `gorm:"not null" json:"phone_number"`
+	Street         string            `json:"street"`
+	Landmark       string            `json:"landmark"`
+	DigitalAddress string            `json:"digital_address"`
+	Location       listings.Location `gorm:"embedded" json:"location"`
+	IsDefault      bool              `json:"is_default"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// AddressDetails holds the editable fields of an address
+type AddressDetails struct {
+	Label          string
+	RecipientName  string
+	PhoneNumber    string
+	Street         string
+	Landmark       string
+	DigitalAddress string
+	Location       listings.Location
+}
+
+// NewAddress creates a new address for a user
+func NewAddress(userID ids.UserID, details AddressDetails) (*Address, error) {
+	if userID == "" {
+		return nil, errors.ValidationError("user ID is required")
+	}
+
+	address := &Address{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	if err := address.Update(details); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// Update replaces the editable fields of the address
+func (a *Address) Update(details AddressDetails) error {
+	if strings.TrimSpace(details.RecipientName) == "" {
+		return errors.ValidationError("recipient name is required")
+	}
+	phoneNumber, err := NormalizePhoneNumber(details.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	if details.Location.Region == "" || details.Location.City == "" {
+		return errors.ValidationError("region and city are required")
+	}
+
+	digitalAddress := strings.ToUpper(strings.TrimSpace(details.DigitalAddress))
+	if digitalAddress != "" && !digitalAddressPattern.MatchString(digitalAddress) {
+		return errors.ValidationError("invalid GhanaPostGPS digital address")
+	}
+
+	a.Label = strings.TrimSpace(details.Label)
+	a.RecipientName = strings.TrimSpace(details.RecipientName)
+	a.PhoneNumber = phoneNumber
+	a.Street = strings.TrimSpace(details.Street)
+	a.Landmark = strings.TrimSpace(details.Landmark)
+	a.DigitalAddress = digitalAddress
+	a.Location = details.Location
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// AddressRepository defines the interface for address persistence
+type AddressRepository interface {
+	Save(address *Address) error
+	FindByID(id string) (*Address, error)
+	FindByUser(userID ids.UserID) ([]*Address, error)
+	Update(address *Address) error
+	SetDefault(userID ids.UserID, addressID string) error
+	Delete(id string) error
+}
@@ -0,0 +1,55 @@
+package domain_test
+
+import (
+	"testing"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validAddressDetails() domain.AddressDetails {
+	return domain.AddressDetails{
+		Label:         " Home ",
+		RecipientName: "Kofi Boateng",
+		PhoneNumber:   "+233241234567",
+		Location:      listings.Location{Region: "greater-accra", City: "accra"},
+	}
+}
+
+func TestNewAddress(t *testing.T) {
+	details := validAddressDetails()
+	details.DigitalAddress = "ga-183-8164"
+
+	address, err := domain.NewAddress("user-1", details)
+	require.NoError(t, err)
+	assert.Equal(t, "Home", address.Label)
+	assert.Equal(t, "GA-183-8164", address.DigitalAddress)
+	assert.False(t, address.IsDefault)
+}
+
+func TestNewAddress_Validation(t *testing.T) {
+	missingRecipient := validAddressDetails()
+	missingRecipient.RecipientName = " "
+
+	missingCity := validAddressDetails()
+	missingCity.Location.City = ""
+
+	badDigitalAddress := validAddressDetails()
+	badDigitalAddress.DigitalAddress = "accra-123"
+
+	for name, details := range map[string]domain.AddressDetails{
+		"missing recipient":       missingRecipient,
+		"missing city":            missingCity,
+		"invalid digital address": badDigitalAddress,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := domain.NewAddress("user-1", details)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := domain.NewAddress("", validAddressDetails())
+	assert.Error(t, err)
+}
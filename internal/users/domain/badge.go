@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/ids"
+)
+
+// BadgeType represents a badge awarded to a seller
+type BadgeType string
+
+const (
+	BadgeVerified      BadgeType = "verified"
+	BadgeTopRated      BadgeType = "top_rated"
+	BadgeFastResponder BadgeType = "fast_responder"
+)
+
+// TrustLevel summarizes how much buyers can rely on a seller
+type TrustLevel string
+
+const (
+	TrustLevelNew         TrustLevel = "new"
+	TrustLevelEstablished TrustLevel = "established"
+	TrustLevelTrusted     TrustLevel = "trusted"
+)
+
+// Badge and trust level thresholds
+const (
+	TopRatedMinRating  = 4.5
+	TopRatedMinReviews = 10
+	TopRatedMinSales   = 10
+
+	// Fast responders answer within an hour on average over the last 30 days
+	FastResponderMaxMinutes     = 60
+	FastResponderMinResponses   = 10
+	FastResponderResponseWindow = 30 * 24 * time.Hour
+
+	EstablishedMinSales  = 5
+	EstablishedMinRating = 3.5
+
+	TrustedMinSales   = 25
+	TrustedMinRating  = 4.0
+	TrustedMinReviews = 10
+)
+
+// SellerBadge is a badge held by a seller
+type SellerBadge struct {
+	Type      BadgeType `json:"type"`
+	AwardedAt time.Time `json:"awarded_at"`
+}
+
+// ActivityKind represents the kind of seller activity that badges are computed from
+type ActivityKind string
+
+const (
+	ActivitySale     ActivityKind = "sale"
+	ActivityReview   ActivityKind = "review"
+	ActivityResponse ActivityKind = "response"
+	// ActivityInquiry is a buyer starting a conversation. Inquiries do not
+	// affect badges; with responses they give the seller's response rate.
+	ActivityInquiry ActivityKind = "inquiry"
+)
+
+// SellerActivity records one event counting towards a seller's badges or
+// search ranking. It is keyed by the ID of the event it came from, so
+// redelivered events count once.
+type SellerActivity struct {
+	EventID  string       `gorm:"primary_key" json:"event_id"`
+	SellerID ids.UserID   `gorm:"type:uuid;not null;index:idx_seller_activities_seller" json:"seller_id"`
+	Kind     ActivityKind `gorm:"not null;index:idx_seller_activities_seller" json:"kind"`
+	// Value is the rating of a review or the response time in minutes
+	Value      float64   `json:"value"`
+	OccurredAt time.Time `gorm:"not null" json:"occurred_at"`
+}
+
+// SellerStats aggregates a seller's activity
+type SellerStats struct {
+	CompletedSales      int
+	Rating              float64
+	TotalReviews        int
+	ResponseSamples     int
+	ResponseTimeMinutes float64
+}
+
+// ApplyStats updates the seller's statistics and re-evaluates badges and trust
+// level. Badges the seller keeps retain their award time. It returns the
+// badges gained and lost.
+func (p *SellerProfile) ApplyStats(stats SellerStats, now time.Time) (awarded, revoked []BadgeType) {
+	p.CompletedSales = stats.CompletedSales
+	p.Rating = stats.Rating
+	p.TotalReviews = stats.TotalReviews
+	p.ResponseTimeMinutes = stats.ResponseTimeMinutes
+
+	held := make(map[BadgeType]SellerBadge, len(p.Badges))
+	for _, badge := range p.Badges {
+		held[badge.Type] = badge
+	}
+
+	badges := []SellerBadge{}
+	for _, badgeType := range earnedBadges(p, stats) {
+		badge, ok := held[badgeType]
+		if !ok {
+			badge = SellerBadge{Type: badgeType, AwardedAt: now}
+			awarded = append(awarded, badgeType)
+		}
+		delete(held, badgeType)
+		badges = append(badges, badge)
+	}
+	for _, badge := range p.Badges {
+		if _, lost := held[badge.Type]; lost {
+			revoked = append(revoked, badge.Type)
+		}
+	}
+
+	p.Badges = badges
+	p.TrustLevel = p.trustLevel()
+	p.UpdatedAt = now
+	return awarded, revoked
+}
+
+// HasBadge reports whether the seller holds the badge
+func (p *SellerProfile) HasBadge(badgeType BadgeType) bool {
+	for _, badge := range p.Badges {
+		if badge.Type == badgeType {
+			return true
+		}
+	}
+	return false
+}
+
+// earnedBadges lists the badges the seller qualifies for, in display order
+func earnedBadges(p *SellerProfile, stats SellerStats) []BadgeType {
+	var earned []BadgeType
+	if p.VerificationStatus == VerificationStatusApproved {
+		earned = append(earned, BadgeVerified)
+	}
+	if stats.Rating >= TopRatedMinRating && stats.TotalReviews >= TopRatedMinReviews && stats.CompletedSales >= TopRatedMinSales {
+		earned = append(earned, BadgeTopRated)
+	}
+	if stats.ResponseSamples >= FastResponderMinResponses && stats.ResponseTimeMinutes <= FastResponderMaxMinutes {
+		earned = append(earned, BadgeFastResponder)
+	}
+	return earned
+}
+
+func (p *SellerProfile) trustLevel() TrustLevel {
+	switch {
+	case p.HasBadge(BadgeVerified) && p.CompletedSales >= TrustedMinSales &&
+		p.TotalReviews >= TrustedMinReviews && p.Rating >= TrustedMinRating:
+		return TrustLevelTrusted
+	case p.CompletedSales >= EstablishedMinSales && (p.TotalReviews == 0 || p.Rating >= EstablishedMinRating):
+		return TrustLevelEstablished
+	default:
+		return TrustLevelNew
+	}
+}
+
+// SellerActivityRepository defines persistence for seller activity and the
+// badge fields of seller profiles
+type SellerActivityRepository interface {
+	// Record stores the activity and reports false if it was already recorded
+	Record(activity *SellerActivity) (bool, error)
+	// Stats aggregates a seller's activity, counting responses since responsesSince
+	Stats(sellerID ids.UserID, responsesSince time.Time) (SellerStats, error)
+	FindProfiles(userIDs []ids.UserID) ([]*SellerProfile, error)
+	// FindSellerIDs returns the user IDs of sellers ordered by ID, for batch processing
+	FindSellerIDs(afterID ids.UserID, limit int) ([]ids.UserID, error)
+	// Engagement returns the next batch of sellers after afterID ordered by
+	// ID, with their last login and the inquiries and responses since since
+	Engagement(afterID ids.UserID, limit int, since time.Time) ([]SellerEngagement, error)
+	UpdateProfile(profile *SellerProfile) error
+}
+
+// SellerEngagement is how recently a seller logged in and how many buyer
+// inquiries they answered
+type SellerEngagement struct {
+	SellerID    ids.UserID
+	LastLoginAt *time.Time
+	Inquiries   int
+	Responses   int
+}
@@ -0,0 +1,70 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSellerProfile(t *testing.T) *domain.SellerProfile {
+	t.Helper()
+	user, err := domain.NewUser("kofi@example.com", "password123", "Kofi", "Boateng", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, user.VerifyEmail(time.Now()))
+	require.NoError(t, user.UpgradeToSeller("Kofi Electronics", "Accra", time.Now()))
+	return user.SellerProfile
+}
+
+func TestSellerProfile_ApplyStats(t *testing.T) {
+	profile := newSellerProfile(t)
+	now := time.Now()
+
+	awarded, revoked := profile.ApplyStats(domain.SellerStats{CompletedSales: 2}, now)
+	assert.Empty(t, awarded)
+	assert.Empty(t, revoked)
+	assert.Equal(t, domain.TrustLevelNew, profile.TrustLevel)
+
+	stats := domain.SellerStats{
+		CompletedSales:      12,
+		Rating:              4.8,
+		TotalReviews:        11,
+		ResponseSamples:     15,
+		ResponseTimeMinutes: 20,
+	}
+	awarded, revoked = profile.ApplyStats(stats, now)
+	assert.Equal(t, []domain.BadgeType{domain.BadgeTopRated, domain.BadgeFastResponder}, awarded)
+	assert.Empty(t, revoked)
+	assert.Equal(t, 12, profile.CompletedSales)
+	assert.Equal(t, domain.TrustLevelEstablished, profile.TrustLevel)
+
+	// Kept badges retain their award time; slow responses lose the badge
+	later := now.Add(time.Hour)
+	stats.ResponseTimeMinutes = 180
+	awarded, revoked = profile.ApplyStats(stats, later)
+	assert.Empty(t, awarded)
+	assert.Equal(t, []domain.BadgeType{domain.BadgeFastResponder}, revoked)
+	require.Len(t, profile.Badges, 1)
+	assert.Equal(t, now, profile.Badges[0].AwardedAt)
+}
+
+func TestSellerProfile_ApplyStats_TrustLevel(t *testing.T) {
+	profile := newSellerProfile(t)
+	stats := domain.SellerStats{CompletedSales: 30, Rating: 4.2, TotalReviews: 20}
+
+	// Trusted sellers must also be verified
+	profile.ApplyStats(stats, time.Now())
+	assert.Equal(t, domain.TrustLevelEstablished, profile.TrustLevel)
+
+	profile.VerificationStatus = domain.VerificationStatusApproved
+	awarded, _ := profile.ApplyStats(stats, time.Now())
+	assert.Equal(t, []domain.BadgeType{domain.BadgeVerified}, awarded)
+	assert.Equal(t, domain.TrustLevelTrusted, profile.TrustLevel)
+
+	// Poorly rated sellers stay new however much they sell
+	stats.Rating = 2.5
+	profile.ApplyStats(stats, time.Now())
+	assert.Equal(t, domain.TrustLevelNew, profile.TrustLevel)
+}
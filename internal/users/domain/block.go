@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// UserBlock records that one user has blocked another. Blocks apply in both
+// directions: neither user can contact the other while the block exists.
+type UserBlock struct {
+	ID        string     `gorm:"type:uuid;primary_key" json:"id"`
+	BlockerID ids.UserID `gorm:"type:uuid;not null;uniqueIndex:idx_user_blocks_pair" json:"blocker_id"`
+	BlockedID ids.UserID `gorm:"type:uuid;not null;uniqueIndex:idx_user_blocks_pair;index" json:"blocked_id"`
+	Reason    string     `json:"reason,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewUserBlock creates a new block
+func NewUserBlock(blockerID, blockedID ids.UserID, reason string) (*UserBlock, error) {
+	if blockerID == "" || blockedID == "" {
+		return nil, errors.ValidationError("both user IDs are required")
+	}
+	if blockerID == blockedID {
+		return nil, errors.ValidationError("cannot block yourself")
+	}
+
+	return &UserBlock{
+		ID:        uuid.New().String(),
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// UserBlockRepository defines the interface for user block persistence
+type UserBlockRepository interface {
+	Save(block *UserBlock) error
+	Find(blockerID, blockedID ids.UserID) (*UserBlock, error)
+	FindByBlocker(blockerID ids.UserID) ([]*UserBlock, error)
+	ExistsBetween(userID, otherUserID ids.UserID) (bool, error)
+	// CountBlockedSince counts the users who blocked blockedID since the given time
+	CountBlockedSince(blockedID ids.UserID, since time.Time) (int, error)
+	Delete(blockerID, blockedID ids.UserID) error
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"dongome/pkg/errors"
+)
+
+// MaxBrowseCategories is how many categories' last-used filters are remembered
+const MaxBrowseCategories = 50
+
+// BrowseSorts lists the listing search sorts a user can pick as their default.
+// They mirror the sorts of the listings search.
+var BrowseSorts = []string{"relevance", "newest", "price_asc", "price_desc"}
+
+// BrowseConditions lists the listing conditions a filter can select
+var BrowseConditions = []string{"new", "like_new", "good", "fair", "poor", "for_parts"}
+
+// ViewDensity is how tightly listings are laid out in results
+type ViewDensity string
+
+const (
+	ViewDensityComfortable ViewDensity = "comfortable"
+	ViewDensityCompact     ViewDensity = "compact"
+	ViewDensityList        ViewDensity = "list"
+)
+
+// Default browse settings for users who have not changed them
+const (
+	DefaultBrowseSort  = "relevance"
+	DefaultViewDensity = ViewDensityComfortable
+)
+
+// BrowseFilters are the listing search filters a user last used in a category
+type BrowseFilters struct {
+	Condition  string   `json:"condition,omitempty"`
+	MinPrice   *float64 `json:"min_price,omitempty"`
+	MaxPrice   *float64 `json:"max_price,omitempty"`
+	Region     string   `json:"region,omitempty"`
+	City       string   `json:"city,omitempty"`
+	Negotiable *bool    `json:"negotiable,omitempty"`
+}
+
+// BrowsePreferences holds how a user likes to browse listings. Clients on
+// every device read and replace it as a whole, so the last write wins.
+// CategoryFilters is keyed by category ID.
+type BrowsePreferences struct {
+	DefaultSort     string                   `json:"default_sort"`
+	ViewDensity     ViewDensity              `json:"view_density"`
+	CategoryFilters map[string]BrowseFilters `json:"category_filters"`
+	UpdatedAt       *time.Time               `json:"updated_at,omitempty"`
+}
+
+// DefaultBrowsePreferences returns the browse settings of a user who has not changed them
+func DefaultBrowsePreferences() BrowsePreferences {
+	return BrowsePreferences{
+		DefaultSort:     DefaultBrowseSort,
+		ViewDensity:     DefaultViewDensity,
+		CategoryFilters: map[string]BrowseFilters{},
+	}
+}
+
+// Validate checks the browse settings can be stored
+func (b BrowsePreferences) Validate() error {
+	if !contains(BrowseSorts, b.DefaultSort) {
+		return errors.ValidationError("unsupported default sort")
+	}
+	switch b.ViewDensity {
+	case ViewDensityComfortable, ViewDensityCompact, ViewDensityList:
+	default:
+		return errors.ValidationError("unsupported view density")
+	}
+	if len(b.CategoryFilters) > MaxBrowseCategories {
+		return errors.ValidationError(fmt.Sprintf("filters can be saved for at most %d categories", MaxBrowseCategories))
+	}
+	for categoryID, filters := range b.CategoryFilters {
+		if categoryID == "" {
+			return errors.ValidationError("filters must be saved against a category")
+		}
+		if err := filters.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the filters are ones the listings search accepts
+func (f BrowseFilters) Validate() error {
+	if f.Condition != "" && !contains(BrowseConditions, f.Condition) {
+		return errors.ValidationError("unsupported condition filter")
+	}
+	if (f.MinPrice != nil && *f.MinPrice < 0) || (f.MaxPrice != nil && *f.MaxPrice < 0) {
+		return errors.ValidationError("price filters cannot be negative")
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return errors.ValidationError("minimum price cannot exceed maximum price")
+	}
+	return nil
+}
+
+// FillBrowseDefaults sets the defaults of browse settings the user never stored
+func (p *UserPreferences) FillBrowseDefaults() {
+	defaults := DefaultBrowsePreferences()
+	if p.Browse.DefaultSort == "" {
+		p.Browse.DefaultSort = defaults.DefaultSort
+	}
+	if p.Browse.ViewDensity == "" {
+		p.Browse.ViewDensity = defaults.ViewDensity
+	}
+	if p.Browse.CategoryFilters == nil {
+		p.Browse.CategoryFilters = defaults.CategoryFilters
+	}
+}
+
+// ReplaceBrowse validates and stores a user's browse settings
+func (p *UserPreferences) ReplaceBrowse(browse BrowsePreferences) error {
+	if browse.CategoryFilters == nil {
+		browse.CategoryFilters = map[string]BrowseFilters{}
+	}
+	if err := browse.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	browse.UpdatedAt = &now
+	p.Browse = browse
+	p.UpdatedAt = now
+	return nil
+}
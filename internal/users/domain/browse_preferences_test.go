@@ -0,0 +1,51 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserPreferences_ReplaceBrowse(t *testing.T) {
+	preferences := domain.DefaultUserPreferences("user-1")
+
+	negotiable := true
+	require.NoError(t, preferences.ReplaceBrowse(domain.BrowsePreferences{
+		DefaultSort: "newest",
+		ViewDensity: domain.ViewDensityList,
+		CategoryFilters: map[string]domain.BrowseFilters{
+			"cars": {Negotiable: &negotiable, City: "Kumasi"},
+		},
+	}))
+	assert.Equal(t, "newest", preferences.Browse.DefaultSort)
+	assert.NotNil(t, preferences.Browse.UpdatedAt)
+
+	tests := []struct {
+		name   string
+		browse domain.BrowsePreferences
+	}{
+		{"unknown sort", domain.BrowsePreferences{DefaultSort: "cheapest", ViewDensity: domain.ViewDensityCompact}},
+		{"unknown density", domain.BrowsePreferences{DefaultSort: "newest", ViewDensity: "dense"}},
+		{"unknown condition", domain.BrowsePreferences{DefaultSort: "newest", ViewDensity: domain.ViewDensityCompact,
+			CategoryFilters: map[string]domain.BrowseFilters{"cars": {Condition: "mint"}}}},
+		{"blank category", domain.BrowsePreferences{DefaultSort: "newest", ViewDensity: domain.ViewDensityCompact,
+			CategoryFilters: map[string]domain.BrowseFilters{"": {}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, preferences.ReplaceBrowse(tt.browse))
+			assert.Equal(t, "newest", preferences.Browse.DefaultSort)
+		})
+	}
+}
+
+func TestUserPreferences_FillBrowseDefaults(t *testing.T) {
+	preferences := &domain.UserPreferences{UserID: "user-1"}
+	preferences.FillBrowseDefaults()
+
+	assert.Equal(t, domain.DefaultBrowseSort, preferences.Browse.DefaultSort)
+	assert.Equal(t, domain.DefaultViewDensity, preferences.Browse.ViewDensity)
+	assert.NotNil(t, preferences.Browse.CategoryFilters)
+}
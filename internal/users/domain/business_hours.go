@@ -0,0 +1,207 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/pkg/errors"
+)
+
+// MaxOpeningPeriods limits how many opening periods a seller can list, enough
+// for a lunch break every day of the week
+const MaxOpeningPeriods = 14
+
+// BusinessTimeZone is the zone opening hours are given in. Ghana keeps GMT
+// all year, so a fixed zone avoids depending on the host's tzdata.
+var BusinessTimeZone = time.FixedZone("GMT", 0)
+
+// weekdays maps the day names used in opening hours to weekdays
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// OpeningPeriod is a span of a day when a seller's store is open. Times are
+// HH:MM in BusinessTimeZone; a period closing at 24:00 runs to midnight.
+type OpeningPeriod struct {
+	Day    string `json:"day"`
+	Opens  string `json:"opens"`
+	Closes string `json:"closes"`
+}
+
+// SellerProfileDetails holds the editable fields of a seller profile
+type SellerProfileDetails struct {
+	BusinessName    string
+	BusinessAddress string
+	BusinessPhone   string
+	BusinessEmail   string
+	BusinessHours   []OpeningPeriod
+	StoreLocation   *listings.Location
+}
+
+// UpdateDetails replaces the editable fields of the seller profile
+func (p *SellerProfile) UpdateDetails(details SellerProfileDetails) error {
+	businessName := strings.TrimSpace(details.BusinessName)
+	if businessName == "" {
+		return errors.ValidationError("business name is required")
+	}
+	businessPhone := ""
+	if strings.TrimSpace(details.BusinessPhone) != "" {
+		normalized, err := NormalizePhoneNumber(details.BusinessPhone)
+		if err != nil {
+			return err
+		}
+		businessPhone = normalized
+	}
+	hours, err := NormalizeBusinessHours(details.BusinessHours)
+	if err != nil {
+		return err
+	}
+	location := listings.Location{}
+	if details.StoreLocation != nil {
+		if err := ValidateStoreLocation(*details.StoreLocation); err != nil {
+			return err
+		}
+		location = *details.StoreLocation
+	}
+
+	p.BusinessName = businessName
+	p.BusinessAddress = strings.TrimSpace(details.BusinessAddress)
+	p.BusinessPhone = businessPhone
+	p.BusinessEmail = strings.TrimSpace(details.BusinessEmail)
+	p.BusinessHours = hours
+	p.StoreLocation = location
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// ValidateStoreLocation checks that a store location names its region and
+// city and carries the coordinates it was geocoded to
+func ValidateStoreLocation(location listings.Location) error {
+	if location.Region == "" || location.City == "" {
+		return errors.ValidationError("store region and city are required")
+	}
+	if location.Latitude == 0 && location.Longitude == 0 {
+		return errors.ValidationError("store location must include its coordinates")
+	}
+	if location.Latitude < -90 || location.Latitude > 90 || location.Longitude < -180 || location.Longitude > 180 {
+		return errors.ValidationError("store coordinates are out of range")
+	}
+	return nil
+}
+
+// HasStoreLocation checks if the seller has set where their store is
+func (p *SellerProfile) HasStoreLocation() bool {
+	return p.StoreLocation.Region != ""
+}
+
+// NormalizeBusinessHours lowercases day names, checks every period and sorts
+// them from Monday to Sunday
+func NormalizeBusinessHours(periods []OpeningPeriod) ([]OpeningPeriod, error) {
+	if len(periods) > MaxOpeningPeriods {
+		return nil, errors.ValidationError(fmt.Sprintf("at most %d opening periods can be given", MaxOpeningPeriods))
+	}
+
+	normalized := make([]OpeningPeriod, 0, len(periods))
+	for _, period := range periods {
+		period.Day = strings.ToLower(strings.TrimSpace(period.Day))
+		if _, ok := weekdays[period.Day]; !ok {
+			return nil, errors.ValidationError(fmt.Sprintf("unknown day %q", period.Day))
+		}
+		opens, err := parseClock(period.Opens)
+		if err != nil {
+			return nil, err
+		}
+		closes, err := parseClock(period.Closes)
+		if err != nil {
+			return nil, err
+		}
+		if closes <= opens {
+			return nil, errors.ValidationError(fmt.Sprintf("%s opening hours must close after they open", period.Day))
+		}
+		normalized = append(normalized, period)
+	}
+
+	sort.SliceStable(normalized, func(i, j int) bool {
+		di, dj := weekdayOrder(normalized[i].Day), weekdayOrder(normalized[j].Day)
+		if di != dj {
+			return di < dj
+		}
+		return normalized[i].Opens < normalized[j].Opens
+	})
+	for i := 1; i < len(normalized); i++ {
+		previous, current := normalized[i-1], normalized[i]
+		if previous.Day == current.Day && current.Opens < previous.Closes {
+			return nil, errors.ValidationError(fmt.Sprintf("%s opening hours overlap", current.Day))
+		}
+	}
+	return normalized, nil
+}
+
+// HasBusinessHours checks if the seller has published opening hours
+func (p *SellerProfile) HasBusinessHours() bool {
+	return len(p.BusinessHours) > 0
+}
+
+// IsOpenAt checks if the seller's store is open at the given time. Sellers
+// without opening hours are never reported open.
+func (p *SellerProfile) IsOpenAt(t time.Time) bool {
+	local := t.In(BusinessTimeZone)
+	minute := local.Hour()*60 + local.Minute()
+	for _, period := range p.BusinessHours {
+		if weekdays[period.Day] != local.Weekday() {
+			continue
+		}
+		opens, err := parseClock(period.Opens)
+		if err != nil {
+			continue
+		}
+		closes, err := parseClock(period.Closes)
+		if err != nil {
+			continue
+		}
+		if minute >= opens && minute < closes {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenNow reports whether the store is open at the given time, or nil if the
+// seller has not published opening hours
+func (p *SellerProfile) OpenNow(now time.Time) *bool {
+	if !p.HasBusinessHours() {
+		return nil
+	}
+	open := p.IsOpenAt(now)
+	return &open
+}
+
+// parseClock parses an HH:MM time of day into minutes after midnight,
+// allowing 24:00 for closing at midnight
+func parseClock(value string) (int, error) {
+	var hour, minute int
+	if len(value) != 5 || value[2] != ':' {
+		return 0, errors.ValidationError(fmt.Sprintf("invalid time %q, use HH:MM", value))
+	}
+	if _, err := fmt.Sscanf(value, "%02d:%02d", &hour, &minute); err != nil {
+		return 0, errors.ValidationError(fmt.Sprintf("invalid time %q, use HH:MM", value))
+	}
+	if minute < 0 || minute > 59 || hour < 0 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, errors.ValidationError(fmt.Sprintf("invalid time %q, use HH:MM", value))
+	}
+	return hour*60 + minute, nil
+}
+
+// weekdayOrder orders days from Monday to Sunday
+func weekdayOrder(day string) int {
+	return (int(weekdays[day]) + 6) % 7
+}
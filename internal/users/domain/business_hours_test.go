@@ -0,0 +1,90 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	listings "dongome/internal/listings/domain"
+	"dongome/internal/users/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeBusinessHours(t *testing.T) {
+	hours, err := domain.NormalizeBusinessHours([]domain.OpeningPeriod{
+		{Day: "Sunday", Opens: "12:00", Closes: "16:00"},
+		{Day: "monday", Opens: "14:00", Closes: "18:00"},
+		{Day: " MONDAY ", Opens: "08:00", Closes: "12:00"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.OpeningPeriod{
+		{Day: "monday", Opens: "08:00", Closes: "12:00"},
+		{Day: "monday", Opens: "14:00", Closes: "18:00"},
+		{Day: "sunday", Opens: "12:00", Closes: "16:00"},
+	}, hours)
+
+	invalid := [][]domain.OpeningPeriod{
+		{{Day: "someday", Opens: "08:00", Closes: "17:00"}},
+		{{Day: "monday", Opens: "8am", Closes: "17:00"}},
+		{{Day: "monday", Opens: "08:00", Closes: "24:30"}},
+		{{Day: "monday", Opens: "17:00", Closes: "08:00"}},
+		{{Day: "monday", Opens: "08:00", Closes: "13:00"}, {Day: "monday", Opens: "12:00", Closes: "17:00"}},
+	}
+	for _, periods := range invalid {
+		_, err := domain.NormalizeBusinessHours(periods)
+		assert.Error(t, err, "%v", periods)
+	}
+}
+
+func TestSellerProfile_IsOpenAt(t *testing.T) {
+	profile := &domain.SellerProfile{}
+	assert.Nil(t, profile.OpenNow(time.Now()), "no hours, no status")
+
+	require.NoError(t, profile.UpdateDetails(domain.SellerProfileDetails{
+		BusinessName: "Kofi Electronics",
+		BusinessHours: []domain.OpeningPeriod{
+			{Day: "monday", Opens: "08:00", Closes: "17:00"},
+			{Day: "saturday", Opens: "20:00", Closes: "24:00"},
+		},
+	}))
+
+	// 2024-01-01 was a Monday; opening hours are in GMT
+	monday := time.Date(2024, 1, 1, 0, 0, 0, 0, domain.BusinessTimeZone)
+	assert.False(t, profile.IsOpenAt(monday.Add(7*time.Hour+59*time.Minute)))
+	assert.True(t, profile.IsOpenAt(monday.Add(8*time.Hour)))
+	assert.False(t, profile.IsOpenAt(monday.Add(17*time.Hour)), "closing time is exclusive")
+	assert.True(t, profile.IsOpenAt(monday.Add(5*24*time.Hour+23*time.Hour+30*time.Minute)), "open until midnight")
+
+	// Times in other zones are converted
+	assert.True(t, profile.IsOpenAt(monday.Add(9*time.Hour).In(time.FixedZone("WAT", 3600))))
+
+	openNow := profile.OpenNow(monday.Add(12 * time.Hour))
+	require.NotNil(t, openNow)
+	assert.True(t, *openNow)
+}
+
+func TestSellerProfile_UpdateDetails(t *testing.T) {
+	profile := &domain.SellerProfile{BusinessName: "Old Name"}
+
+	err := profile.UpdateDetails(domain.SellerProfileDetails{
+		BusinessName:  "Kofi Electronics",
+		BusinessPhone: "024 123 4567",
+		StoreLocation: &listings.Location{Region: "Greater Accra", City: "Accra", Latitude: 5.6037, Longitude: -0.187},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "+233241234567", profile.BusinessPhone)
+	assert.True(t, profile.HasStoreLocation())
+
+	// A store location must be geocoded
+	err = profile.UpdateDetails(domain.SellerProfileDetails{
+		BusinessName:  "Kofi Electronics",
+		StoreLocation: &listings.Location{Region: "Greater Accra", City: "Accra"},
+	})
+	assert.Error(t, err)
+	assert.True(t, profile.HasStoreLocation(), "invalid details leave the profile unchanged")
+
+	// Leaving the store location out removes it
+	require.NoError(t, profile.UpdateDetails(domain.SellerProfileDetails{BusinessName: "Kofi Electronics"}))
+	assert.False(t, profile.HasStoreLocation())
+}
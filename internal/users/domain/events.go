@@ -2,72 +2,353 @@ package domain
 
 import (
 	"time"
+
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
 )
 
 // Event types
 const (
-	UserRegisteredEvent       = "user.registered"
-	UserEmailVerifiedEvent    = "user.email_verified"
-	UserUpgradedToSellerEvent = "user.upgraded_to_seller"
-	SellerVerifiedEvent       = "seller.verified"
-	UserSuspendedEvent        = "user.suspended"
-	UserActivatedEvent        = "user.activated"
-	UserLoggedInEvent         = "user.logged_in"
+	UserRegisteredEvent             = "user.registered"
+	UserEmailVerifiedEvent          = "user.email_verified"
+	VerificationResentEvent         = "user.verification_resent"
+	VerificationReminderSentEvent   = "user.verification_reminder_sent"
+	UserUpgradedToSellerEvent       = "user.upgraded_to_seller"
+	SellerVerifiedEvent             = "seller.verified"
+	SellerVerificationRejectedEvent = "seller.verification_rejected"
+	UserSuspendedEvent              = "user.suspended"
+	UserActivatedEvent              = "user.activated"
+	UserLoggedInEvent               = "user.logged_in"
+	UserDeletedEvent                = "user.deleted"
+	UserAnonymizedEvent             = "user.anonymized"
+	UserRestoredEvent               = "user.restored"
+	DataExportRequestedEvent        = "user.data_export_requested"
+	DataExportCompletedEvent        = "user.data_export_completed"
+	UserMergedEvent                 = "user.merged"
+	UserBlockedEvent                = "user.blocked"
+	UserPreferencesUpdatedEvent     = "user.preferences_updated"
+	UserUnblockedEvent              = "user.unblocked"
+	SellerBadgesChangedEvent        = "seller.badges_changed"
+	SellerRatingChangedEvent        = "seller.rating_changed"
+	SellerFollowedEvent             = "user.seller_followed"
+	SellerUnfollowedEvent           = "user.seller_unfollowed"
+	FollowedSellerListingEvent      = "user.followed_seller_listing"
+	ReferralAttributedEvent         = "user.referral_attributed"
+	ReferralCompletedEvent          = "user.referral_completed"
+	UserHandleChangedEvent          = "user.handle_changed"
+	SellerProfileUpdatedEvent       = "seller.profile_updated"
+)
+
+// Events consumed from other contexts to compute seller badges and ranking
+const (
+	SaleCompletedEvent       = "transaction.sale_completed"
+	ReviewSubmittedEvent     = "review.submitted"
+	SellerRespondedEvent     = "messaging.seller_responded"
+	ConversationStartedEvent = "messaging.conversation_started"
 )
 
 // UserRegistered represents the event when a user registers
 type UserRegistered struct {
-	UserID            string    `json:"user_id"`
-	Email             string    `json:"email"`
-	FirstName         string    `json:"first_name"`
-	LastName          string    `json:"last_name"`
-	Role              UserRole  `json:"role"`
-	VerificationToken string    `json:"verification_token"`
-	Timestamp         time.Time `json:"timestamp"`
+	UserID            ids.UserID `json:"user_id"`
+	Email             string     `json:"email"`
+	FirstName         string     `json:"first_name"`
+	LastName          string     `json:"last_name"`
+	Role              UserRole   `json:"role"`
+	VerificationToken string     `json:"verification_token"`
+	Locale            string     `json:"locale"`
+	ReferralCode      string     `json:"referral_code,omitempty"`
+	Timestamp         time.Time  `json:"timestamp"`
 }
 
 // UserEmailVerified represents the event when a user verifies their email
 type UserEmailVerified struct {
-	UserID    string    `json:"user_id"`
-	Email     string    `json:"email"`
-	Timestamp time.Time `json:"timestamp"`
+	UserID    ids.UserID `json:"user_id"`
+	Email     string     `json:"email"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// VerificationResent represents the event when a user asks for a new verification email
+type VerificationResent struct {
+	UserID            ids.UserID `json:"user_id"`
+	Email             string     `json:"email"`
+	FirstName         string     `json:"first_name"`
+	VerificationToken string     `json:"verification_token"`
+	Locale            string     `json:"locale"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	Timestamp         time.Time  `json:"timestamp"`
+}
+
+// VerificationReminderSent represents the event when a user who has not
+// verified their email is sent a reminder. Step counts reminders from 1.
+type VerificationReminderSent struct {
+	UserID            ids.UserID `json:"user_id"`
+	Email             string     `json:"email"`
+	FirstName         string     `json:"first_name"`
+	VerificationToken string     `json:"verification_token"`
+	Locale            string     `json:"locale"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	Step              int        `json:"step"`
+	Timestamp         time.Time  `json:"timestamp"`
 }
 
 // UserUpgradedToSeller represents the event when a user becomes a seller
 type UserUpgradedToSeller struct {
-	UserID       string    `json:"user_id"`
-	Email        string    `json:"email"`
-	BusinessName string    `json:"business_name"`
-	Timestamp    time.Time `json:"timestamp"`
+	UserID       ids.UserID `json:"user_id"`
+	Email        string     `json:"email"`
+	BusinessName string     `json:"business_name"`
+	Timestamp    time.Time  `json:"timestamp"`
 }
 
 // SellerVerified represents the event when a seller gets verified
 type SellerVerified struct {
-	UserID       string    `json:"user_id"`
-	SellerID     string    `json:"seller_id"`
-	Email        string    `json:"email"`
-	BusinessName string    `json:"business_name"`
-	Timestamp    time.Time `json:"timestamp"`
+	UserID       ids.UserID `json:"user_id"`
+	SellerID     ids.UserID `json:"seller_id"`
+	Email        string     `json:"email"`
+	BusinessName string     `json:"business_name"`
+	Timestamp    time.Time  `json:"timestamp"`
+}
+
+// SellerVerificationRejected represents the event when a seller's
+// verification is rejected
+type SellerVerificationRejected struct {
+	UserID     ids.UserID `json:"user_id"`
+	Email      string     `json:"email"`
+	Notes      string     `json:"notes"`
+	ReviewedBy ids.UserID `json:"reviewed_by"`
+	Timestamp  time.Time  `json:"timestamp"`
 }
 
 // UserSuspended represents the event when a user is suspended
 type UserSuspended struct {
-	UserID    string    `json:"user_id"`
-	Email     string    `json:"email"`
-	Reason    string    `json:"reason"`
-	Timestamp time.Time `json:"timestamp"`
+	UserID      ids.UserID `json:"user_id"`
+	Email       string     `json:"email"`
+	Reason      string     `json:"reason"`
+	SuspendedBy ids.UserID `json:"suspended_by"`
+	Timestamp   time.Time  `json:"timestamp"`
 }
 
 // UserActivated represents the event when a user is activated
 type UserActivated struct {
-	UserID    string    `json:"user_id"`
-	Email     string    `json:"email"`
-	Timestamp time.Time `json:"timestamp"`
+	UserID      ids.UserID `json:"user_id"`
+	Email       string     `json:"email"`
+	ActivatedBy ids.UserID `json:"activated_by"`
+	Timestamp   time.Time  `json:"timestamp"`
 }
 
 // UserLoggedIn represents the event when a user logs in
 type UserLoggedIn struct {
-	UserID    string    `json:"user_id"`
-	Email     string    `json:"email"`
-	Timestamp time.Time `json:"timestamp"`
+	UserID    ids.UserID `json:"user_id"`
+	Email     string     `json:"email"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// UserDeleted represents the event when a user deletes their account
+type UserDeleted struct {
+	UserID             ids.UserID `json:"user_id"`
+	Email              string     `json:"email"`
+	Role               UserRole   `json:"role"`
+	Reason             string     `json:"reason"`
+	ErasureScheduledAt time.Time  `json:"erasure_scheduled_at"`
+	Timestamp          time.Time  `json:"timestamp"`
+}
+
+// UserAnonymized represents the event when a deleted user's personal data is erased
+type UserAnonymized struct {
+	UserID    ids.UserID `json:"user_id"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// UserRestored represents the event when a deleted account is restored
+type UserRestored struct {
+	UserID     ids.UserID `json:"user_id"`
+	Email      string     `json:"email"`
+	RestoredBy ids.UserID `json:"restored_by"`
+	Timestamp  time.Time  `json:"timestamp"`
+}
+
+// DataExportRequested represents the event when a user requests a copy of their data
+type DataExportRequested struct {
+	ExportID  string     `json:"export_id"`
+	UserID    ids.UserID `json:"user_id"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// DataExportCompleted represents the event when a data export has finished processing
+type DataExportCompleted struct {
+	ExportID  string           `json:"export_id"`
+	UserID    ids.UserID       `json:"user_id"`
+	Email     string           `json:"email"`
+	Status    DataExportStatus `json:"status"`
+	ExpiresAt *time.Time       `json:"expires_at,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// UserMerged represents the event when a duplicate account is merged into a primary account.
+// Contexts owning user data should move it from DuplicateUserID to PrimaryUserID: the worker
+// moves listings, orders and their escrows, refunds, claims and payouts, and the wallet
+// balance. Favorites and messages are kept by the services that publish their events.
+type UserMerged struct {
+	PrimaryUserID   ids.UserID `json:"primary_user_id"`
+	DuplicateUserID ids.UserID `json:"duplicate_user_id"`
+	MergedBy        ids.UserID `json:"merged_by"`
+	Reason          string     `json:"reason"`
+	Timestamp       time.Time  `json:"timestamp"`
+}
+
+// UserBlocked represents the event when a user blocks another user
+type UserBlocked struct {
+	BlockerID ids.UserID `json:"blocker_id"`
+	BlockedID ids.UserID `json:"blocked_id"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// UserUnblocked represents the event when a user lifts a block
+type UserUnblocked struct {
+	BlockerID ids.UserID `json:"blocker_id"`
+	BlockedID ids.UserID `json:"blocked_id"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// UserPreferencesUpdated represents the event when a user changes their settings.
+// The notifications context uses it to respect opt-outs.
+type UserPreferencesUpdated struct {
+	UserID               ids.UserID           `json:"user_id"`
+	Language             string               `json:"language"`
+	Currency             string               `json:"currency"`
+	DefaultRegion        string               `json:"default_region"`
+	MarketingOptIn       bool                 `json:"marketing_opt_in"`
+	NotifyMessages       bool                 `json:"notify_messages"`
+	NotifyOffers         bool                 `json:"notify_offers"`
+	NotifyOrderUpdates   bool                 `json:"notify_order_updates"`
+	NotifySavedSearches  bool                 `json:"notify_saved_searches"`
+	NotificationChannels NotificationChannels `json:"notification_channels"`
+	ChangedFields        []string             `json:"changed_fields"`
+	Timestamp            time.Time            `json:"timestamp"`
+}
+
+// SellerBadgesChanged represents the event when a seller gains or loses badges
+// or changes trust level, so other contexts can refresh what they show about the seller.
+type SellerBadgesChanged struct {
+	SellerID   ids.UserID  `json:"seller_id"`
+	Awarded    []BadgeType `json:"awarded"`
+	Revoked    []BadgeType `json:"revoked"`
+	TrustLevel TrustLevel  `json:"trust_level"`
+	Timestamp  time.Time   `json:"timestamp"`
+}
+
+// SellerRatingChanged carries a seller's current standing whenever their
+// rating, reviews, response time, badges or trust level change, so other
+// contexts can keep a copy
+type SellerRatingChanged struct {
+	SellerID            ids.UserID  `json:"seller_id"`
+	Verified            bool        `json:"verified"`
+	Rating              float64     `json:"rating"`
+	TotalReviews        int         `json:"total_reviews"`
+	ResponseTimeMinutes float64     `json:"response_time_minutes"`
+	TrustLevel          TrustLevel  `json:"trust_level"`
+	Badges              []BadgeType `json:"badges"`
+	Timestamp           time.Time   `json:"timestamp"`
+}
+
+// SaleCompleted is published by the transactions context when an order is fulfilled
+type SaleCompleted struct {
+	TransactionID string        `json:"transaction_id"`
+	SellerID      ids.UserID    `json:"seller_id"`
+	BuyerID       ids.UserID    `json:"buyer_id"`
+	ListingID     ids.ListingID `json:"listing_id"`
+	Timestamp     time.Time     `json:"timestamp"`
+}
+
+// ReviewSubmitted is published by the reviews context when a buyer rates a seller
+type ReviewSubmitted struct {
+	ReviewID   string     `json:"review_id"`
+	SellerID   ids.UserID `json:"seller_id"`
+	ReviewerID ids.UserID `json:"reviewer_id"`
+	Rating     int        `json:"rating"`
+	Timestamp  time.Time  `json:"timestamp"`
+}
+
+// SellerResponded is published by the messaging context when a seller first
+// replies to a buyer's message
+type SellerResponded struct {
+	ConversationID  string     `json:"conversation_id"`
+	SellerID        ids.UserID `json:"seller_id"`
+	ResponseSeconds int64      `json:"response_seconds"`
+	Timestamp       time.Time  `json:"timestamp"`
+}
+
+// ConversationStarted is published by the messaging context when a buyer
+// starts a conversation with a seller, about one of their listings unless
+// ListingID is empty
+type ConversationStarted struct {
+	ConversationID string        `json:"conversation_id"`
+	SellerID       ids.UserID    `json:"seller_id"`
+	BuyerID        ids.UserID    `json:"buyer_id"`
+	ListingID      ids.ListingID `json:"listing_id,omitempty"`
+	Timestamp      time.Time     `json:"timestamp"`
+}
+
+// SellerFollowed represents the event when a user follows a seller
+type SellerFollowed struct {
+	FollowerID ids.UserID `json:"follower_id"`
+	SellerID   ids.UserID `json:"seller_id"`
+	Timestamp  time.Time  `json:"timestamp"`
+}
+
+// SellerUnfollowed represents the event when a user stops following a seller
+type SellerUnfollowed struct {
+	FollowerID ids.UserID `json:"follower_id"`
+	SellerID   ids.UserID `json:"seller_id"`
+	Timestamp  time.Time  `json:"timestamp"`
+}
+
+// FollowedSellerListing represents the event when a followed seller posts a
+// listing. Large follower lists are split over several events; the
+// notifications context notifies each follower listed.
+type FollowedSellerListing struct {
+	ListingID   ids.ListingID `json:"listing_id"`
+	SellerID    ids.UserID    `json:"seller_id"`
+	Title       string        `json:"title"`
+	Price       money.Money   `json:"price"`
+	FollowerIDs []ids.UserID  `json:"follower_ids"`
+	// Channels maps each follower ID to the channels they want followed seller news on
+	Channels  map[ids.UserID][]string `json:"channels"`
+	Timestamp time.Time               `json:"timestamp"`
+}
+
+// ReferralAttributed represents the event when a new user registers with another user's referral code
+type ReferralAttributed struct {
+	ReferralID string     `json:"referral_id"`
+	ReferrerID ids.UserID `json:"referrer_id"`
+	ReferredID ids.UserID `json:"referred_id"`
+	Code       string     `json:"code"`
+	Timestamp  time.Time  `json:"timestamp"`
+}
+
+// ReferralCompleted represents the event when a referred user completes their
+// first purchase. Marketing and the wallet use it to credit the referrer.
+type ReferralCompleted struct {
+	ReferralID  string      `json:"referral_id"`
+	ReferrerID  ids.UserID  `json:"referrer_id"`
+	ReferredID  ids.UserID  `json:"referred_id"`
+	OrderID     ids.OrderID `json:"order_id"`
+	OrderAmount money.Money `json:"order_amount"`
+	Timestamp   time.Time   `json:"timestamp"`
+}
+
+// UserHandleChanged represents the event when a user claims, changes or removes their handle
+type UserHandleChanged struct {
+	UserID         ids.UserID `json:"user_id"`
+	PreviousHandle string     `json:"previous_handle,omitempty"`
+	Handle         string     `json:"handle,omitempty"`
+	Timestamp      time.Time  `json:"timestamp"`
+}
+
+// SellerProfileUpdated represents the event when a seller edits their profile
+type SellerProfileUpdated struct {
+	UserID           ids.UserID `json:"user_id"`
+	BusinessName     string     `json:"business_name"`
+	HasBusinessHours bool       `json:"has_business_hours"`
+	HasStoreLocation bool       `json:"has_store_location"`
+	Timestamp        time.Time  `json:"timestamp"`
 }
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// DataExportRetention is how long a completed export archive can be downloaded
+const DataExportRetention = 7 * 24 * time.Hour
+
+// DataExportStatus represents the status of a data export
+type DataExportStatus string
+
+const (
+	DataExportStatusPending    DataExportStatus = "pending"
+	DataExportStatusProcessing DataExportStatus = "processing"
+	DataExportStatusCompleted  DataExportStatus = "completed"
+	DataExportStatusFailed     DataExportStatus = "failed"
+)
+
+// DataExport represents a request for an archive of all data held about a user
+type DataExport struct {
+	ID          string           `gorm:"type:uuid;primary_key" json:"id"`
+	UserID      ids.UserID       `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status      DataExportStatus `gorm:"default:'pending'" json:"status"`
+	FilePath    string           `json:"-"`
+	FileSize    int64            `json:"file_size"`
+	Error       string           `json:"error,omitempty"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// NewDataExport creates a new pending data export
+func NewDataExport(userID ids.UserID) (*DataExport, error) {
+	if userID == "" {
+		return nil, errors.ValidationError("user ID is required")
+	}
+
+	return &DataExport{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Status:    DataExportStatusPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// Start marks the export as being processed
+func (e *DataExport) Start() error {
+	if e.Status != DataExportStatusPending {
+		return errors.ValidationError("only pending exports can be started")
+	}
+
+	e.Status = DataExportStatusProcessing
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+// Complete marks the export as ready for download
+func (e *DataExport) Complete(filePath string, fileSize int64) {
+	now := time.Now()
+	expiresAt := now.Add(DataExportRetention)
+	e.Status = DataExportStatusCompleted
+	e.FilePath = filePath
+	e.FileSize = fileSize
+	e.Error = ""
+	e.CompletedAt = &now
+	e.ExpiresAt = &expiresAt
+	e.UpdatedAt = now
+}
+
+// Fail marks the export as failed
+func (e *DataExport) Fail(reason string) {
+	e.Status = DataExportStatusFailed
+	e.Error = reason
+	e.UpdatedAt = time.Now()
+}
+
+// InProgress checks if the export has not finished yet
+func (e *DataExport) InProgress() bool {
+	return e.Status == DataExportStatusPending || e.Status == DataExportStatusProcessing
+}
+
+// IsDownloadable checks if the export archive is ready and not expired
+func (e *DataExport) IsDownloadable() bool {
+	return e.Status == DataExportStatusCompleted && e.ExpiresAt != nil && time.Now().Before(*e.ExpiresAt)
+}
+
+// DataExportRepository defines the interface for data export persistence
+type DataExportRepository interface {
+	Save(export *DataExport) error
+	FindByID(id string) (*DataExport, error)
+	FindInProgressByUser(userID ids.UserID) (*DataExport, error)
+	Update(export *DataExport) error
+}
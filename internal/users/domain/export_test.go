@@ -0,0 +1,48 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataExport_Lifecycle(t *testing.T) {
+	_, err := domain.NewDataExport("")
+	assert.Error(t, err)
+
+	export, err := domain.NewDataExport("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DataExportStatusPending, export.Status)
+	assert.True(t, export.InProgress())
+	assert.False(t, export.IsDownloadable())
+
+	// Start processing
+	require.NoError(t, export.Start())
+	assert.Equal(t, domain.DataExportStatusProcessing, export.Status)
+	assert.True(t, export.InProgress())
+
+	// Cannot start twice
+	assert.Error(t, export.Start())
+
+	// Complete
+	export.Complete("/tmp/export.zip", 1024)
+	assert.Equal(t, domain.DataExportStatusCompleted, export.Status)
+	assert.False(t, export.InProgress())
+	assert.True(t, export.IsDownloadable())
+	require.NotNil(t, export.ExpiresAt)
+	assert.Equal(t, export.CompletedAt.Add(domain.DataExportRetention), *export.ExpiresAt)
+}
+
+func TestDataExport_Fail(t *testing.T) {
+	export, err := domain.NewDataExport("user-1")
+	require.NoError(t, err)
+	require.NoError(t, export.Start())
+
+	export.Fail("failed to write archive")
+	assert.Equal(t, domain.DataExportStatusFailed, export.Status)
+	assert.Equal(t, "failed to write archive", export.Error)
+	assert.False(t, export.InProgress())
+	assert.False(t, export.IsDownloadable())
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// SellerFollow records that a user follows a seller to hear about their new listings
+type SellerFollow struct {
+	ID         string     `gorm:"type:uuid;primary_key" json:"id"`
+	FollowerID ids.UserID `gorm:"type:uuid;not null;uniqueIndex:idx_seller_follows_pair" json:"follower_id"`
+	SellerID   ids.UserID `gorm:"type:uuid;not null;uniqueIndex:idx_seller_follows_pair;index" json:"seller_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewSellerFollow creates a new follow of a seller
+func NewSellerFollow(followerID, sellerID ids.UserID) (*SellerFollow, error) {
+	if followerID == "" || sellerID == "" {
+		return nil, errors.ValidationError("both user IDs are required")
+	}
+	if followerID == sellerID {
+		return nil, errors.ValidationError("cannot follow yourself")
+	}
+
+	return &SellerFollow{
+		ID:         uuid.New().String(),
+		FollowerID: followerID,
+		SellerID:   sellerID,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// SellerFollowRepository defines the interface for seller follow persistence.
+// Save and Delete keep the seller profile's followers count in step.
+type SellerFollowRepository interface {
+	Save(follow *SellerFollow) error
+	Find(followerID, sellerID ids.UserID) (*SellerFollow, error)
+	FindByFollower(followerID ids.UserID, limit, offset int) ([]*SellerFollow, int64, error)
+	FindFollowedSellerIDs(followerID ids.UserID) ([]ids.UserID, error)
+	// FindFollowerIDs returns the IDs of a seller's followers ordered by ID, for batch processing
+	FindFollowerIDs(sellerID ids.UserID, afterID ids.UserID, limit int) ([]ids.UserID, error)
+	Delete(followerID, sellerID ids.UserID) error
+}
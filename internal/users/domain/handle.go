@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+)
+
+// Handle length limits
+const (
+	MinHandleLength = 3
+	MaxHandleLength = 30
+)
+
+// handlePattern matches a normalized handle: lowercase letters, digits and
+// underscores, starting with a letter
+var handlePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// reservedHandles cannot be claimed because they would impersonate the
+// marketplace or clash with routes and system accounts
+var reservedHandles = map[string]bool{
+	"admin":         true,
+	"administrator": true,
+	"api":           true,
+	"dongome":       true,
+	"help":          true,
+	"info":          true,
+	"login":         true,
+	"logout":        true,
+	"me":            true,
+	"mod":           true,
+	"moderator":     true,
+	"null":          true,
+	"official":      true,
+	"register":      true,
+	"root":          true,
+	"security":      true,
+	"settings":      true,
+	"signup":        true,
+	"staff":         true,
+	"support":       true,
+	"system":        true,
+	"undefined":     true,
+}
+
+// NormalizeHandle lowercases a handle, drops a leading @ and checks that it
+// is well formed and not reserved
+func NormalizeHandle(raw string) (string, error) {
+	handle := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(raw), "@"))
+	if handle == "" {
+		return "", errors.ValidationError("handle is required")
+	}
+	if len(handle) < MinHandleLength || len(handle) > MaxHandleLength {
+		return "", errors.ValidationError(fmt.Sprintf("handle must be between %d and %d characters", MinHandleLength, MaxHandleLength))
+	}
+	if !handlePattern.MatchString(handle) {
+		return "", errors.ValidationError("handle must start with a letter and contain only letters, digits and underscores")
+	}
+	if IsReservedHandle(handle) {
+		return "", errors.ValidationError("handle is reserved")
+	}
+	return handle, nil
+}
+
+// IsReservedHandle reports whether a normalized handle is reserved
+func IsReservedHandle(handle string) bool {
+	return reservedHandles[handle] || strings.HasPrefix(handle, "dongome")
+}
+
+// SetHandle normalizes and sets the user's handle. An empty value removes it.
+func (u *User) SetHandle(raw string) error {
+	handle := ""
+	if strings.TrimSpace(raw) != "" {
+		normalized, err := NormalizeHandle(raw)
+		if err != nil {
+			return err
+		}
+		handle = normalized
+	}
+
+	if handle == u.HandleValue() {
+		return nil
+	}
+	if handle == "" {
+		u.Handle = nil
+	} else {
+		u.Handle = &handle
+	}
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// HandleValue returns the user's handle, or an empty string if they have none
+func (u *User) HandleValue() string {
+	if u.Handle == nil {
+		return ""
+	}
+	return *u.Handle
+}
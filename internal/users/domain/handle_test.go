@@ -0,0 +1,55 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeHandle(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "@Kofi_Mensah", want: "kofi_mensah"},
+		{raw: "  ama99 ", want: "ama99"},
+		{raw: "ab", wantErr: true},
+		{raw: strings.Repeat("a", domain.MaxHandleLength+1), wantErr: true},
+		{raw: "9lives", wantErr: true},
+		{raw: "kofi.mensah", wantErr: true},
+		{raw: "Admin", wantErr: true},
+		{raw: "dongome_deals", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := domain.NormalizeHandle(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUser_SetHandle(t *testing.T) {
+	user, err := domain.NewUser("kofi@example.com", "password123", "Kofi", "Mensah", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "", user.HandleValue())
+
+	require.NoError(t, user.SetHandle("@KofiM"))
+	assert.Equal(t, "kofim", user.HandleValue())
+
+	assert.Error(t, user.SetHandle("support"))
+	assert.Equal(t, "kofim", user.HandleValue(), "an invalid handle leaves the current one")
+
+	require.NoError(t, user.SetHandle(""))
+	assert.Nil(t, user.Handle)
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+)
+
+// SetLocale sets the locale the user's API responses and emails are written
+// in. An empty value removes it, so responses follow Accept-Language again.
+func (u *User) SetLocale(raw string) error {
+	locale := ""
+	if strings.TrimSpace(raw) != "" {
+		parsed, ok := i18n.ParseLocale(raw)
+		if !ok {
+			return errors.ValidationError("unsupported locale")
+		}
+		locale = string(parsed)
+	}
+
+	if locale == u.Locale {
+		return nil
+	}
+	u.Locale = locale
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// PreferredLocale returns the locale to write to the user in, such as in
+// emails sent outside of a request
+func (u *User) PreferredLocale() i18n.Locale {
+	if locale, ok := i18n.ParseLocale(u.Locale); ok {
+		return locale
+	}
+	return i18n.DefaultLocale
+}
@@ -0,0 +1,178 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVerifiedUser(t *testing.T, email string) *domain.User {
+	t.Helper()
+	user, err := domain.NewUser(email, "password123", "John", "Doe", time.Now())
+	require.NoError(t, err)
+	user.VerifyEmail(time.Now())
+	return user
+}
+
+func TestUser_MergeInto(t *testing.T) {
+	primary := newVerifiedUser(t, "john@example.com")
+	duplicate := newVerifiedUser(t, "john.phone@example.com")
+	duplicate.PhoneNumber = "+233241234567"
+	duplicate.PhoneVerified = true
+	duplicate.Avatar = "https://cdn.example.com/avatar.png"
+
+	err := duplicate.MergeInto(primary, time.Now())
+	require.NoError(t, err)
+
+	// Duplicate is tombstoned
+	assert.True(t, duplicate.IsMerged())
+	assert.False(t, duplicate.IsActive())
+	require.NotNil(t, duplicate.MergedIntoID)
+	assert.Equal(t, primary.ID, *duplicate.MergedIntoID)
+	assert.Contains(t, duplicate.Email, duplicate.ID)
+	assert.Empty(t, duplicate.PhoneNumber)
+
+	// Primary takes over missing contact details
+	assert.Equal(t, "john@example.com", primary.Email)
+	assert.Equal(t, "+233241234567", primary.PhoneNumber)
+	assert.True(t, primary.PhoneVerified)
+	assert.Equal(t, "https://cdn.example.com/avatar.png", primary.Avatar)
+	assert.True(t, primary.IsActive())
+}
+
+func TestUser_MergeInto_KeepsPrimaryContactDetails(t *testing.T) {
+	primary := newVerifiedUser(t, "john@example.com")
+	primary.PhoneNumber = "+233200000000"
+	primary.Avatar = "primary.png"
+	duplicate := newVerifiedUser(t, "other@example.com")
+	duplicate.PhoneNumber = "+233241234567"
+	duplicate.PhoneVerified = true
+	duplicate.Avatar = "duplicate.png"
+
+	require.NoError(t, duplicate.MergeInto(primary, time.Now()))
+
+	assert.Equal(t, "+233200000000", primary.PhoneNumber)
+	assert.False(t, primary.PhoneVerified)
+	assert.Equal(t, "primary.png", primary.Avatar)
+}
+
+func TestUser_MergeInto_MovesSellerProfile(t *testing.T) {
+	primary := newVerifiedUser(t, "buyer@example.com")
+	duplicate := newVerifiedUser(t, "seller@example.com")
+	require.NoError(t, duplicate.UpgradeToSeller("Kofi Electronics", "Accra", time.Now()))
+	profileID := duplicate.SellerProfile.ID
+
+	require.NoError(t, duplicate.MergeInto(primary, time.Now()))
+
+	assert.True(t, primary.IsSeller())
+	require.NotNil(t, primary.SellerProfile)
+	assert.Equal(t, profileID, primary.SellerProfile.ID)
+	assert.Equal(t, primary.ID, primary.SellerProfile.UserID)
+	assert.Equal(t, "Kofi Electronics", primary.SellerProfile.BusinessName)
+
+	assert.Nil(t, duplicate.SellerProfile)
+	assert.False(t, duplicate.IsSeller())
+}
+
+func TestUser_MergeInto_CombinesSellerRatings(t *testing.T) {
+	primary := newVerifiedUser(t, "seller1@example.com")
+	require.NoError(t, primary.UpgradeToSeller("Shop One", "Accra", time.Now()))
+	primary.SellerProfile.Rating = 4.0
+	primary.SellerProfile.TotalReviews = 30
+
+	duplicate := newVerifiedUser(t, "seller2@example.com")
+	require.NoError(t, duplicate.UpgradeToSeller("Shop Two", "Kumasi", time.Now()))
+	duplicate.SellerProfile.Rating = 5.0
+	duplicate.SellerProfile.TotalReviews = 10
+
+	require.NoError(t, duplicate.MergeInto(primary, time.Now()))
+
+	assert.Equal(t, "Shop One", primary.SellerProfile.BusinessName)
+	assert.Equal(t, 40, primary.SellerProfile.TotalReviews)
+	assert.InDelta(t, 4.25, primary.SellerProfile.Rating, 0.0001)
+}
+
+func TestUser_MergeInto_KeepsEarliestHistory(t *testing.T) {
+	primary := newVerifiedUser(t, "john@example.com")
+	duplicate := newVerifiedUser(t, "old@example.com")
+
+	duplicate.CreatedAt = primary.CreatedAt.Add(-365 * 24 * time.Hour)
+	lastLogin := time.Now().Add(-time.Hour)
+	duplicate.LastLoginAt = &lastLogin
+
+	require.NoError(t, duplicate.MergeInto(primary, time.Now()))
+
+	assert.Equal(t, duplicate.CreatedAt, primary.CreatedAt)
+	require.NotNil(t, primary.LastLoginAt)
+	assert.Equal(t, lastLogin, *primary.LastLoginAt)
+}
+
+func TestUser_MergeInto_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(primary, duplicate *domain.User) *domain.User
+	}{
+		{
+			name: "nil primary",
+			setup: func(primary, duplicate *domain.User) *domain.User {
+				return nil
+			},
+		},
+		{
+			name: "merge into itself",
+			setup: func(primary, duplicate *domain.User) *domain.User {
+				return duplicate
+			},
+		},
+		{
+			name: "duplicate already merged",
+			setup: func(primary, duplicate *domain.User) *domain.User {
+				duplicate.Status = domain.UserStatusMerged
+				return primary
+			},
+		},
+		{
+			name: "primary already merged",
+			setup: func(primary, duplicate *domain.User) *domain.User {
+				primary.Status = domain.UserStatusMerged
+				return primary
+			},
+		},
+		{
+			name: "duplicate deleted",
+			setup: func(primary, duplicate *domain.User) *domain.User {
+				require.NoError(t, duplicate.Delete(time.Now()))
+				return primary
+			},
+		},
+		{
+			name: "primary deleted",
+			setup: func(primary, duplicate *domain.User) *domain.User {
+				require.NoError(t, primary.Delete(time.Now()))
+				return primary
+			},
+		},
+		{
+			name: "admin account",
+			setup: func(primary, duplicate *domain.User) *domain.User {
+				duplicate.Role = domain.UserRoleAdmin
+				return primary
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := newVerifiedUser(t, "john@example.com")
+			duplicate := newVerifiedUser(t, "john2@example.com")
+			target := tt.setup(primary, duplicate)
+
+			err := duplicate.MergeInto(target, time.Now())
+			assert.Error(t, err)
+			assert.Nil(t, duplicate.MergedIntoID)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"dongome/pkg/errors"
+)
+
+// NotificationChannel is a way of reaching a user
+type NotificationChannel string
+
+const (
+	ChannelEmail NotificationChannel = "email"
+	ChannelSMS   NotificationChannel = "sms"
+	ChannelPush  NotificationChannel = "push"
+)
+
+// NotificationCategory groups the notifications a user can configure together.
+// Account notifications such as email verification are always sent and have no category.
+type NotificationCategory string
+
+const (
+	NotificationMessages         NotificationCategory = "messages"
+	NotificationOffers           NotificationCategory = "offers"
+	NotificationOrderUpdates     NotificationCategory = "order_updates"
+	NotificationSavedSearches    NotificationCategory = "saved_searches"
+	NotificationFollowedSellers  NotificationCategory = "followed_sellers"
+	NotificationListingQuestions NotificationCategory = "listing_questions"
+	NotificationMarketing        NotificationCategory = "marketing"
+)
+
+// NotificationCategories lists the categories users can configure
+var NotificationCategories = []NotificationCategory{
+	NotificationMessages,
+	NotificationOffers,
+	NotificationOrderUpdates,
+	NotificationSavedSearches,
+	NotificationFollowedSellers,
+	NotificationListingQuestions,
+	NotificationMarketing,
+}
+
+// ChannelSettings holds which channels a category of notifications is sent on
+type ChannelSettings struct {
+	Email bool `json:"email"`
+	SMS   bool `json:"sms"`
+	Push  bool `json:"push"`
+}
+
+// NotificationChannels holds the channel settings of each category. Categories
+// without stored settings use the defaults.
+type NotificationChannels map[NotificationCategory]ChannelSettings
+
+// DefaultChannelSettings returns the channels a category is sent on until the
+// user changes them. SMS costs money, so only order updates use it by default.
+func DefaultChannelSettings(category NotificationCategory) ChannelSettings {
+	return ChannelSettings{
+		Email: true,
+		SMS:   category == NotificationOrderUpdates,
+		Push:  true,
+	}
+}
+
+// DefaultNotificationChannels returns the default channel settings of every category
+func DefaultNotificationChannels() NotificationChannels {
+	channels := make(NotificationChannels, len(NotificationCategories))
+	for _, category := range NotificationCategories {
+		channels[category] = DefaultChannelSettings(category)
+	}
+	return channels
+}
+
+// ChannelSettingsUpdate describes a partial change to a category's channels; nil fields are left unchanged
+type ChannelSettingsUpdate struct {
+	Email *bool `json:"email"`
+	SMS   *bool `json:"sms"`
+	Push  *bool `json:"push"`
+}
+
+// ChannelSettingsFor returns the channel settings of a category, falling back to the defaults
+func (p *UserPreferences) ChannelSettingsFor(category NotificationCategory) ChannelSettings {
+	if settings, ok := p.NotificationChannels[category]; ok {
+		return settings
+	}
+	return DefaultChannelSettings(category)
+}
+
+// FillChannelDefaults adds the default settings of categories the user has
+// not configured, so every category is listed
+func (p *UserPreferences) FillChannelDefaults() {
+	if p.NotificationChannels == nil {
+		p.NotificationChannels = make(NotificationChannels, len(NotificationCategories))
+	}
+	for _, category := range NotificationCategories {
+		if _, ok := p.NotificationChannels[category]; !ok {
+			p.NotificationChannels[category] = DefaultChannelSettings(category)
+		}
+	}
+}
+
+// EnabledChannels returns the channels a category of notifications may be sent
+// on. A category switched off as a whole is sent on none.
+func (p *UserPreferences) EnabledChannels(category NotificationCategory) []NotificationChannel {
+	if !p.categoryEnabled(category) {
+		return nil
+	}
+
+	settings := p.ChannelSettingsFor(category)
+	var channels []NotificationChannel
+	if settings.Email {
+		channels = append(channels, ChannelEmail)
+	}
+	if settings.SMS {
+		channels = append(channels, ChannelSMS)
+	}
+	if settings.Push {
+		channels = append(channels, ChannelPush)
+	}
+	return channels
+}
+
+// categoryEnabled applies the per-category switches, which take precedence over channel settings
+func (p *UserPreferences) categoryEnabled(category NotificationCategory) bool {
+	switch category {
+	case NotificationMessages:
+		return p.NotifyMessages
+	case NotificationOffers:
+		return p.NotifyOffers
+	case NotificationOrderUpdates:
+		return p.NotifyOrderUpdates
+	case NotificationSavedSearches:
+		return p.NotifySavedSearches
+	case NotificationMarketing:
+		return p.MarketingOptIn
+	}
+	return true
+}
+
+// ApplyChannels validates and applies a partial update of channel settings,
+// returning the names of the changed settings such as "offers.sms"
+func (p *UserPreferences) ApplyChannels(update map[NotificationCategory]ChannelSettingsUpdate) ([]string, error) {
+	for category := range update {
+		if !isNotificationCategory(category) {
+			return nil, errors.ValidationError("unsupported notification category: " + string(category))
+		}
+	}
+
+	categories := make([]string, 0, len(update))
+	for category := range update {
+		categories = append(categories, string(category))
+	}
+	sort.Strings(categories)
+
+	var changed []string
+	for _, name := range categories {
+		category := NotificationCategory(name)
+		change := update[category]
+		settings := p.ChannelSettingsFor(category)
+
+		setBool := func(channel NotificationChannel, target *bool, value *bool) {
+			if value != nil && *target != *value {
+				*target = *value
+				changed = append(changed, name+"."+string(channel))
+			}
+		}
+		setBool(ChannelEmail, &settings.Email, change.Email)
+		setBool(ChannelSMS, &settings.SMS, change.SMS)
+		setBool(ChannelPush, &settings.Push, change.Push)
+
+		if p.NotificationChannels == nil {
+			p.NotificationChannels = make(NotificationChannels)
+		}
+		p.NotificationChannels[category] = settings
+	}
+
+	if len(changed) > 0 {
+		p.UpdatedAt = time.Now()
+	}
+	return changed, nil
+}
+
+// isNotificationCategory checks if category is one users can configure
+func isNotificationCategory(category NotificationCategory) bool {
+	for _, c := range NotificationCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserPreferences_EnabledChannels(t *testing.T) {
+	preferences := domain.DefaultUserPreferences("user-1")
+
+	assert.Equal(t, []domain.NotificationChannel{domain.ChannelEmail, domain.ChannelSMS, domain.ChannelPush},
+		preferences.EnabledChannels(domain.NotificationOrderUpdates))
+	assert.Equal(t, []domain.NotificationChannel{domain.ChannelEmail, domain.ChannelPush},
+		preferences.EnabledChannels(domain.NotificationOffers))
+	// Marketing needs the opt-in whatever the channel settings say
+	assert.Empty(t, preferences.EnabledChannels(domain.NotificationMarketing))
+
+	// Switching a category off silences every channel
+	preferences.NotifyOffers = false
+	assert.Empty(t, preferences.EnabledChannels(domain.NotificationOffers))
+}
+
+func TestUserPreferences_ApplyChannels(t *testing.T) {
+	preferences := &domain.UserPreferences{UserID: "user-1", NotifyOffers: true}
+
+	on, off := true, false
+	changed, err := preferences.ApplyChannels(map[domain.NotificationCategory]domain.ChannelSettingsUpdate{
+		domain.NotificationOffers:   {SMS: &on, Push: &off, Email: &on},
+		domain.NotificationMessages: {Email: &on},
+	})
+	require.NoError(t, err)
+	// Unchanged values are not reported
+	assert.ElementsMatch(t, []string{"offers.push", "offers.sms"}, changed)
+	assert.Equal(t, domain.ChannelSettings{Email: true, SMS: true, Push: false}, preferences.ChannelSettingsFor(domain.NotificationOffers))
+	assert.Equal(t, domain.DefaultChannelSettings(domain.NotificationSavedSearches), preferences.ChannelSettingsFor(domain.NotificationSavedSearches))
+
+	_, err = preferences.ApplyChannels(map[domain.NotificationCategory]domain.ChannelSettingsUpdate{
+		domain.NotificationOffers: {Email: &off},
+		"account":                 {Email: &off},
+	})
+	assert.Error(t, err)
+	assert.True(t, preferences.ChannelSettingsFor(domain.NotificationOffers).Email)
+}
+
+func TestUserPreferences_FillChannelDefaults(t *testing.T) {
+	preferences := &domain.UserPreferences{UserID: "user-1"}
+	preferences.FillChannelDefaults()
+	assert.Len(t, preferences.NotificationChannels, len(domain.NotificationCategories))
+}
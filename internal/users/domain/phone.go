@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+
+	"dongome/pkg/errors"
+)
+
+// GhanaCountryCode is the E.164 country calling code of Ghana
+const GhanaCountryCode = "233"
+
+var (
+	// phoneSeparators are the characters people use to group digits
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+	// ghanaNationalNumber matches a Ghana national significant number: 9 digits,
+	// starting with 2 or 5 for mobile networks and 3 for fixed lines
+	ghanaNationalNumber = regexp.MustCompile(`^[235]\d{8}$`)
+
+	// internationalNumber matches an E.164 number without the leading +
+	internationalNumber = regexp.MustCompile(`^[1-9]\d{7,14}$`)
+)
+
+// NormalizePhoneNumber converts a phone number to E.164 form, such as +233241234567.
+// Ghana numbers are accepted in local (0241234567) or international
+// (+233, 00233 or 233 prefixed) form and validated against the Ghana
+// numbering plan. Numbers of other countries must be given with their country code.
+func NormalizePhoneNumber(raw string) (string, error) {
+	number := phoneSeparators.Replace(strings.TrimSpace(raw))
+	if number == "" {
+		return "", errors.ValidationError("phone number is required")
+	}
+
+	switch {
+	case strings.HasPrefix(number, "+"):
+		number = number[1:]
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	case strings.HasPrefix(number, "0"):
+		number = GhanaCountryCode + number[1:]
+	case len(number) == 9 && ghanaNationalNumber.MatchString(number):
+		number = GhanaCountryCode + number
+	}
+
+	if national, ok := strings.CutPrefix(number, GhanaCountryCode); ok {
+		// Some people keep the trunk 0 after the country code: +233 024 123 4567
+		if len(national) == 10 && strings.HasPrefix(national, "0") {
+			national = national[1:]
+		}
+		if !ghanaNationalNumber.MatchString(national) {
+			return "", errors.ValidationError("invalid Ghana phone number")
+		}
+		return "+" + GhanaCountryCode + national, nil
+	}
+
+	if !internationalNumber.MatchString(number) {
+		return "", errors.ValidationError("invalid phone number")
+	}
+	return "+" + number, nil
+}
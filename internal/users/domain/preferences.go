@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+)
+
+// Default preference values for users who have not changed their settings
+const (
+	DefaultLanguage = "en"
+	DefaultCurrency = money.DefaultCurrency
+)
+
+// SupportedLanguages lists the languages the marketplace is offered in
+var SupportedLanguages = []string{"en", "tw", "ee", "ha", "fr"}
+
+// SupportedCurrencies lists the currencies prices can be displayed in
+var SupportedCurrencies = money.SupportedCurrencies
+
+// UserPreferences holds a user's settings. Users without stored preferences
+// get the defaults. Fields carry no GORM defaults so that false values are
+// written on insert. The Notify fields switch whole notification categories
+// on or off; NotificationChannels picks the channels of each category. Browse
+// holds the listing browsing settings synced across the user's devices.
+// HideOnlineStatus opts out of showing when the user was last online.
+type UserPreferences struct {
+	UserID               ids.UserID           `gorm:"type:uuid;primary_key" json:"user_id"`
+	Language             string               `json:"language"`
+	Currency             string               `json:"currency"`
+	DefaultRegion        string               `json:"default_region"`
+	MarketingOptIn       bool                 `json:"marketing_opt_in"`
+	NotifyMessages       bool                 `json:"notify_messages"`
+	NotifyOffers         bool                 `json:"notify_offers"`
+	NotifyOrderUpdates   bool                 `json:"notify_order_updates"`
+	NotifySavedSearches  bool                 `json:"notify_saved_searches"`
+	HideOnlineStatus     bool                 `json:"hide_online_status"`
+	NotificationChannels NotificationChannels `gorm:"type:jsonb;serializer:json" json:"notification_channels"`
+	Browse               BrowsePreferences    `gorm:"type:jsonb;serializer:json" json:"browse"`
+	CreatedAt            time.Time            `json:"created_at"`
+	UpdatedAt            time.Time            `json:"updated_at"`
+}
+
+// PreferencesUpdate describes a partial change to a user's preferences; nil fields are left unchanged
+type PreferencesUpdate struct {
+	Language            *string
+	Currency            *string
+	DefaultRegion       *string
+	MarketingOptIn      *bool
+	NotifyMessages      *bool
+	NotifyOffers        *bool
+	NotifyOrderUpdates  *bool
+	NotifySavedSearches *bool
+	HideOnlineStatus    *bool
+}
+
+// DefaultUserPreferences returns the default preferences for a user
+func DefaultUserPreferences(userID ids.UserID) *UserPreferences {
+	return &UserPreferences{
+		UserID:               userID,
+		Language:             DefaultLanguage,
+		Currency:             DefaultCurrency,
+		MarketingOptIn:       false,
+		NotifyMessages:       true,
+		NotifyOffers:         true,
+		NotifyOrderUpdates:   true,
+		NotifySavedSearches:  true,
+		NotificationChannels: DefaultNotificationChannels(),
+		Browse:               DefaultBrowsePreferences(),
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
+	}
+}
+
+// Apply validates and applies a partial update, returning the names of the changed fields
+func (p *UserPreferences) Apply(update PreferencesUpdate) ([]string, error) {
+	if update.Language != nil && !contains(SupportedLanguages, *update.Language) {
+		return nil, errors.ValidationError("unsupported language")
+	}
+	if update.Currency != nil && !contains(SupportedCurrencies, *update.Currency) {
+		return nil, errors.ValidationError("unsupported currency")
+	}
+
+	var changed []string
+	setString := func(field string, target *string, value *string) {
+		if value != nil && *target != *value {
+			*target = *value
+			changed = append(changed, field)
+		}
+	}
+	setBool := func(field string, target *bool, value *bool) {
+		if value != nil && *target != *value {
+			*target = *value
+			changed = append(changed, field)
+		}
+	}
+
+	setString("language", &p.Language, update.Language)
+	setString("currency", &p.Currency, update.Currency)
+	setString("default_region", &p.DefaultRegion, update.DefaultRegion)
+	setBool("marketing_opt_in", &p.MarketingOptIn, update.MarketingOptIn)
+	setBool("notify_messages", &p.NotifyMessages, update.NotifyMessages)
+	setBool("notify_offers", &p.NotifyOffers, update.NotifyOffers)
+	setBool("notify_order_updates", &p.NotifyOrderUpdates, update.NotifyOrderUpdates)
+	setBool("notify_saved_searches", &p.NotifySavedSearches, update.NotifySavedSearches)
+	setBool("hide_online_status", &p.HideOnlineStatus, update.HideOnlineStatus)
+
+	if len(changed) > 0 {
+		p.UpdatedAt = time.Now()
+	}
+	return changed, nil
+}
+
+// contains checks if values includes value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// UserPreferencesRepository defines the interface for user preferences persistence
+type UserPreferencesRepository interface {
+	FindByUser(userID ids.UserID) (*UserPreferences, error)
+	// FindByUsers finds the stored preferences of several users; users without any are left out
+	FindByUsers(userIDs []ids.UserID) ([]*UserPreferences, error)
+	Save(preferences *UserPreferences) error
+}
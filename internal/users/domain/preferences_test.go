@@ -0,0 +1,49 @@
+package domain_test
+
+import (
+	"testing"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/ids"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultUserPreferences(t *testing.T) {
+	preferences := domain.DefaultUserPreferences("user-1")
+
+	assert.Equal(t, ids.UserID("user-1"), preferences.UserID)
+	assert.Equal(t, domain.DefaultLanguage, preferences.Language)
+	assert.Equal(t, domain.DefaultCurrency, preferences.Currency)
+	assert.False(t, preferences.MarketingOptIn)
+	assert.True(t, preferences.NotifyMessages)
+	assert.True(t, preferences.NotifyOffers)
+}
+
+func TestUserPreferences_Apply(t *testing.T) {
+	preferences := domain.DefaultUserPreferences("user-1")
+
+	language := "tw"
+	optIn := true
+	notifyOffers := false
+	sameCurrency := domain.DefaultCurrency
+
+	changed, err := preferences.Apply(domain.PreferencesUpdate{
+		Language:       &language,
+		Currency:       &sameCurrency,
+		MarketingOptIn: &optIn,
+		NotifyOffers:   &notifyOffers,
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"language", "marketing_opt_in", "notify_offers"}, changed)
+	assert.Equal(t, "tw", preferences.Language)
+	assert.True(t, preferences.MarketingOptIn)
+	assert.False(t, preferences.NotifyOffers)
+	assert.True(t, preferences.NotifyMessages)
+
+	// Unsupported values are rejected without partial changes
+	unsupported := "xx"
+	_, err = preferences.Apply(domain.PreferencesUpdate{Currency: &unsupported, MarketingOptIn: &notifyOffers})
+	assert.Error(t, err)
+	assert.True(t, preferences.MarketingOptIn)
+}
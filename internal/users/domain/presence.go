@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// OnlineWindow is how recently a user must have been active to show as online
+const OnlineWindow = 5 * time.Minute
+
+// LastSeenRetention is how long a user's last activity is remembered
+const LastSeenRetention = 30 * 24 * time.Hour
+
+// Presence tells buyers whether a user is online and when they were last
+// active, so they can judge how quickly a seller is likely to respond
+type Presence struct {
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// NewPresence derives a user's presence from when they were last seen. A
+// zero lastSeen means they have not been seen recently.
+func NewPresence(lastSeen, now time.Time) *Presence {
+	if lastSeen.IsZero() {
+		return &Presence{}
+	}
+	// Last seen is shown to the minute, which is all buyers need
+	lastSeen = lastSeen.Truncate(time.Minute)
+	return &Presence{
+		Online:     now.Sub(lastSeen) < OnlineWindow,
+		LastSeenAt: &lastSeen,
+	}
+}
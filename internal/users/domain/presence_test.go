@@ -0,0 +1,27 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPresence(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 30, 0, time.UTC)
+
+	presence := domain.NewPresence(now.Add(-2*time.Minute), now)
+	assert.True(t, presence.Online)
+	require.NotNil(t, presence.LastSeenAt)
+	assert.Equal(t, time.Date(2024, 3, 1, 11, 58, 0, 0, time.UTC), *presence.LastSeenAt)
+
+	presence = domain.NewPresence(now.Add(-time.Hour), now)
+	assert.False(t, presence.Online)
+	assert.NotNil(t, presence.LastSeenAt)
+
+	presence = domain.NewPresence(time.Time{}, now)
+	assert.False(t, presence.Online)
+	assert.Nil(t, presence.LastSeenAt)
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// ReferralCodeLength is the number of characters in a referral code
+const ReferralCodeLength = 8
+
+// referralCodeAlphabet leaves out characters that are easily confused when
+// codes are read aloud or typed, such as 0 and O or 1 and I
+const referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// ReferralCode is the code a user shares to refer others
+type ReferralCode struct {
+	UserID    ids.UserID `gorm:"type:uuid;primary_key" json:"user_id"`
+	Code      string     `gorm:"uniqueIndex;not null" json:"code"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewReferralCode creates a random referral code for a user
+func NewReferralCode(userID ids.UserID) (*ReferralCode, error) {
+	if userID == "" {
+		return nil, errors.ValidationError("user ID is required")
+	}
+
+	code := make([]byte, ReferralCodeLength)
+	max := big.NewInt(int64(len(referralCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return nil, err
+		}
+		code[i] = referralCodeAlphabet[n.Int64()]
+	}
+
+	return &ReferralCode{
+		UserID:    userID,
+		Code:      string(code),
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// NormalizeReferralCode puts a referral code typed by a user in its stored form
+func NormalizeReferralCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ReferralStatus represents the state of a referral
+type ReferralStatus string
+
+const (
+	// ReferralStatusPending means the referred user registered but has not bought anything yet
+	ReferralStatusPending ReferralStatus = "pending"
+	// ReferralStatusCompleted means the referred user completed their first purchase
+	ReferralStatusCompleted ReferralStatus = "completed"
+)
+
+// Referral is a ledger entry recording that a user registered with another
+// user's referral code. A user can only ever be referred once.
+type Referral struct {
+	ID         string         `gorm:"type:uuid;primary_key" json:"id"`
+	ReferrerID ids.UserID     `gorm:"type:uuid;not null;index" json:"referrer_id"`
+	ReferredID ids.UserID     `gorm:"type:uuid;not null;uniqueIndex" json:"referred_id"`
+	Code       string         `gorm:"not null" json:"code"`
+	Status     ReferralStatus `gorm:"not null;default:'pending'" json:"status"`
+	OrderID    *ids.OrderID   `gorm:"type:uuid" json:"order_id,omitempty"`
+	// OrderAmount is the amount of the first purchase, nothing until then
+	OrderAmount money.Money `gorm:"embedded;embeddedPrefix:order_amount_" json:"order_amount"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// NewReferral attributes a newly registered user to the owner of a referral code
+func NewReferral(code *ReferralCode, referredID ids.UserID) (*Referral, error) {
+	if referredID == "" {
+		return nil, errors.ValidationError("referred user ID is required")
+	}
+	if code.UserID == referredID {
+		return nil, errors.ValidationError("cannot use your own referral code")
+	}
+
+	now := time.Now()
+	return &Referral{
+		ID:         uuid.New().String(),
+		ReferrerID: code.UserID,
+		ReferredID: referredID,
+		Code:       code.Code,
+		Status:     ReferralStatusPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// Complete records the referred user's first purchase. It reports false if
+// the referral was already completed by an earlier order.
+func (r *Referral) Complete(orderID ids.OrderID, amount money.Money, now time.Time) bool {
+	if r.Status != ReferralStatusPending {
+		return false
+	}
+
+	r.Status = ReferralStatusCompleted
+	r.OrderID = &orderID
+	r.OrderAmount = amount
+	r.CompletedAt = &now
+	r.UpdatedAt = now
+	return true
+}
+
+// ReferralCounts counts a referrer's referrals by status
+type ReferralCounts struct {
+	Pending   int64 `json:"pending"`
+	Completed int64 `json:"completed"`
+}
+
+// ReferralRepository defines persistence for referral codes and the referral ledger
+type ReferralRepository interface {
+	// SaveCode stores a new referral code; a code already in use is a conflict
+	SaveCode(code *ReferralCode) error
+	FindCodeByUser(userID ids.UserID) (*ReferralCode, error)
+	FindCode(code string) (*ReferralCode, error)
+	// Create stores a referral and reports false if the user was already referred
+	Create(referral *Referral) (bool, error)
+	FindByReferred(referredID ids.UserID) (*Referral, error)
+	FindByReferrer(referrerID ids.UserID, limit, offset int) ([]*Referral, int64, error)
+	CountByReferrer(referrerID ids.UserID) (ReferralCounts, error)
+	Update(referral *Referral) error
+}
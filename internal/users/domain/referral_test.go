@@ -0,0 +1,44 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/ids"
+	"dongome/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReferralCode(t *testing.T) {
+	code, err := domain.NewReferralCode("user-1")
+	require.NoError(t, err)
+	assert.Len(t, code.Code, domain.ReferralCodeLength)
+	assert.False(t, strings.ContainsAny(code.Code, "01IO"), "ambiguous characters are left out")
+	assert.Equal(t, code.Code, domain.NormalizeReferralCode(strings.ToLower(code.Code)))
+
+	_, err = domain.NewReferralCode("")
+	assert.Error(t, err)
+}
+
+func TestReferral_Complete(t *testing.T) {
+	code, err := domain.NewReferralCode("referrer-1")
+	require.NoError(t, err)
+
+	_, err = domain.NewReferral(code, "referrer-1")
+	assert.Error(t, err, "self-referral")
+
+	referral, err := domain.NewReferral(code, "buyer-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReferralStatusPending, referral.Status)
+
+	assert.True(t, referral.Complete("order-1", money.Cedis(120), time.Now()))
+	assert.Equal(t, domain.ReferralStatusCompleted, referral.Status)
+	assert.Equal(t, ids.OrderID("order-1"), *referral.OrderID)
+
+	// Only the first purchase counts
+	assert.False(t, referral.Complete("order-2", money.Cedis(80), time.Now()))
+	assert.Equal(t, ids.OrderID("order-1"), *referral.OrderID)
+}
@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"time"
+
+	"dongome/pkg/ids"
+)
+
+// VerificationReminderSchedule lists when verification reminders are sent,
+// measured from registration
+var VerificationReminderSchedule = []time.Duration{
+	24 * time.Hour,
+	72 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// ReminderStatus represents the state of a user's reminder sequence
+type ReminderStatus string
+
+const (
+	ReminderStatusScheduled ReminderStatus = "scheduled"
+	ReminderStatusCompleted ReminderStatus = "completed"
+	ReminderStatusVerified  ReminderStatus = "verified"
+	ReminderStatusCancelled ReminderStatus = "cancelled"
+)
+
+// VerificationReminder tracks the email verification reminders sent to a user
+type VerificationReminder struct {
+	UserID       ids.UserID     `gorm:"type:uuid;primary_key" json:"user_id"`
+	Status       ReminderStatus `gorm:"not null;default:'scheduled';index:idx_verification_reminders_due" json:"status"`
+	RegisteredAt time.Time      `gorm:"not null" json:"registered_at"`
+	// Step is the number of reminders sent so far
+	Step           int        `gorm:"not null;default:0" json:"step"`
+	NextReminderAt *time.Time `gorm:"index:idx_verification_reminders_due" json:"next_reminder_at,omitempty"`
+	LastSentAt     *time.Time `json:"last_sent_at,omitempty"`
+	VerifiedAt     *time.Time `json:"verified_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewVerificationReminder schedules the reminder sequence of a newly registered user
+func NewVerificationReminder(userID ids.UserID, registeredAt time.Time) *VerificationReminder {
+	now := time.Now()
+	next := registeredAt.Add(VerificationReminderSchedule[0])
+	return &VerificationReminder{
+		UserID:         userID,
+		Status:         ReminderStatusScheduled,
+		RegisteredAt:   registeredAt,
+		NextReminderAt: &next,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Due checks if the next reminder should be sent
+func (r *VerificationReminder) Due(now time.Time) bool {
+	return r.Status == ReminderStatusScheduled && r.NextReminderAt != nil && !now.Before(*r.NextReminderAt)
+}
+
+// MarkSent records that a reminder was sent and schedules the next one. The
+// sequence completes after the last step, or once maxPerUser reminders were sent.
+func (r *VerificationReminder) MarkSent(now time.Time, maxPerUser int) {
+	r.Step++
+	r.LastSentAt = &now
+	r.UpdatedAt = now
+
+	if r.Step >= len(VerificationReminderSchedule) || r.Step >= maxPerUser {
+		r.Status = ReminderStatusCompleted
+		r.NextReminderAt = nil
+		return
+	}
+
+	next := r.RegisteredAt.Add(VerificationReminderSchedule[r.Step])
+	if next.Before(now) {
+		// Reminders that fell behind are spread out rather than sent back to back
+		next = now.Add(VerificationReminderSchedule[r.Step] - VerificationReminderSchedule[r.Step-1])
+	}
+	r.NextReminderAt = &next
+}
+
+// MarkVerified ends the sequence because the user verified their email. The
+// step at which they verified is kept for conversion metrics.
+func (r *VerificationReminder) MarkVerified(now time.Time) {
+	if r.Status == ReminderStatusVerified {
+		return
+	}
+	r.Status = ReminderStatusVerified
+	r.VerifiedAt = &now
+	r.NextReminderAt = nil
+	r.UpdatedAt = now
+}
+
+// Cancel ends the sequence because the user can no longer be verified
+func (r *VerificationReminder) Cancel(now time.Time) {
+	if r.Status != ReminderStatusScheduled {
+		return
+	}
+	r.Status = ReminderStatusCancelled
+	r.NextReminderAt = nil
+	r.UpdatedAt = now
+}
+
+// ReminderStepCount counts reminder sequences by how many reminders were sent
+// and how they ended
+type ReminderStepCount struct {
+	Step   int
+	Status ReminderStatus
+	Count  int64
+}
+
+// ReminderStepStats reports how many users verified after each reminder step.
+// Step 0 covers users who verified before any reminder was sent.
+type ReminderStepStats struct {
+	Step int `json:"step"`
+	// Reached counts users who were sent this many reminders
+	Reached int64 `json:"reached"`
+	// Verified counts users who verified after this step and before the next reminder
+	Verified       int64   `json:"verified"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+// ReminderConversion computes the verification conversion of each reminder step
+func ReminderConversion(counts []ReminderStepCount) []ReminderStepStats {
+	stats := make([]ReminderStepStats, len(VerificationReminderSchedule)+1)
+	for step := range stats {
+		stats[step].Step = step
+	}
+
+	for _, count := range counts {
+		step := count.Step
+		if step < 0 || step >= len(stats) {
+			continue
+		}
+		// A sequence that got to step n passed through every step before it
+		for reached := 0; reached <= step; reached++ {
+			stats[reached].Reached += count.Count
+		}
+		if count.Status == ReminderStatusVerified {
+			stats[step].Verified += count.Count
+		}
+	}
+
+	for i := range stats {
+		if stats[i].Reached > 0 {
+			stats[i].ConversionRate = float64(stats[i].Verified) / float64(stats[i].Reached)
+		}
+	}
+	return stats
+}
+
+// VerificationReminderRepository defines persistence for verification reminders
+type VerificationReminderRepository interface {
+	// Create stores a new reminder sequence and reports false if the user already has one
+	Create(reminder *VerificationReminder) (bool, error)
+	FindByUserID(userID ids.UserID) (*VerificationReminder, error)
+	FindDue(now time.Time, limit int) ([]*VerificationReminder, error)
+	Update(reminder *VerificationReminder) error
+	CountBySteps() ([]ReminderStepCount, error)
+}
@@ -0,0 +1,74 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationReminder_Sequence(t *testing.T) {
+	registeredAt := time.Now().Add(-25 * time.Hour)
+	reminder := domain.NewVerificationReminder("user-1", registeredAt)
+	now := time.Now()
+
+	require.True(t, reminder.Due(now))
+	reminder.MarkSent(now, 3)
+	assert.Equal(t, 1, reminder.Step)
+	assert.Equal(t, registeredAt.Add(72*time.Hour), *reminder.NextReminderAt)
+	assert.False(t, reminder.Due(now))
+
+	reminder.MarkSent(now.Add(48*time.Hour), 3)
+	assert.Equal(t, registeredAt.Add(7*24*time.Hour), *reminder.NextReminderAt)
+
+	reminder.MarkSent(now.Add(6*24*time.Hour), 3)
+	assert.Equal(t, domain.ReminderStatusCompleted, reminder.Status)
+	assert.Nil(t, reminder.NextReminderAt)
+}
+
+func TestVerificationReminder_CapAndCatchUp(t *testing.T) {
+	// A backlog of reminders is spread out instead of sent back to back
+	reminder := domain.NewVerificationReminder("user-1", time.Now().Add(-10*24*time.Hour))
+	now := time.Now()
+	reminder.MarkSent(now, 3)
+	assert.Equal(t, now.Add(48*time.Hour), *reminder.NextReminderAt)
+
+	capped := domain.NewVerificationReminder("user-2", time.Now().Add(-25*time.Hour))
+	capped.MarkSent(now, 1)
+	assert.Equal(t, domain.ReminderStatusCompleted, capped.Status)
+}
+
+func TestVerificationReminder_VerifiedAndCancelled(t *testing.T) {
+	reminder := domain.NewVerificationReminder("user-1", time.Now().Add(-25*time.Hour))
+	reminder.MarkSent(time.Now(), 3)
+	reminder.MarkVerified(time.Now())
+	assert.Equal(t, domain.ReminderStatusVerified, reminder.Status)
+	assert.Equal(t, 1, reminder.Step)
+	assert.False(t, reminder.Due(time.Now().Add(30*24*time.Hour)))
+
+	// Verified sequences are not cancelled afterwards
+	reminder.Cancel(time.Now())
+	assert.Equal(t, domain.ReminderStatusVerified, reminder.Status)
+}
+
+func TestReminderConversion(t *testing.T) {
+	stats := domain.ReminderConversion([]domain.ReminderStepCount{
+		{Step: 0, Status: domain.ReminderStatusVerified, Count: 40},
+		{Step: 1, Status: domain.ReminderStatusVerified, Count: 15},
+		{Step: 1, Status: domain.ReminderStatusScheduled, Count: 5},
+		{Step: 3, Status: domain.ReminderStatusCompleted, Count: 30},
+		{Step: 3, Status: domain.ReminderStatusVerified, Count: 10},
+	})
+
+	require.Len(t, stats, 4)
+	assert.Equal(t, int64(100), stats[0].Reached)
+	assert.Equal(t, 0.4, stats[0].ConversionRate)
+	assert.Equal(t, int64(60), stats[1].Reached)
+	assert.Equal(t, 0.25, stats[1].ConversionRate)
+	assert.Equal(t, int64(40), stats[2].Reached)
+	assert.Zero(t, stats[2].Verified)
+	assert.Equal(t, int64(40), stats[3].Reached)
+	assert.Equal(t, 0.25, stats[3].ConversionRate)
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"dongome/pkg/errors"
+)
+
+// MinUserSearchLength is the shortest search term accepted, to keep searches selective
+const MinUserSearchLength = 2
+
+// Fields a user search can match on
+const (
+	UserSearchFieldName        = "name"
+	UserSearchFieldEmail       = "email"
+	UserSearchFieldPhoneNumber = "phone_number"
+)
+
+// UserSearch defines a free-text search over user names, emails and phone numbers
+type UserSearch struct {
+	// Term is matched case-insensitively as a substring
+	Term string
+	// PhoneNumber is the term in E.164 form, when it reads as a phone number
+	PhoneNumber    string
+	IncludeDeleted bool
+}
+
+// NewUserSearch validates a search term. A term that looks like a phone
+// number is also normalized, so local and international forms both match.
+func NewUserSearch(term string, includeDeleted bool) (UserSearch, error) {
+	term = strings.Join(strings.Fields(term), " ")
+	if utf8.RuneCountInString(term) < MinUserSearchLength {
+		return UserSearch{}, errors.ValidationError("search term must be at least 2 characters")
+	}
+
+	search := UserSearch{Term: term, IncludeDeleted: includeDeleted}
+	if phoneNumber, err := NormalizePhoneNumber(term); err == nil {
+		search.PhoneNumber = phoneNumber
+	}
+	return search, nil
+}
+
+// UserSearchMatch describes which field of a user matched a search and where
+type UserSearchMatch struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+	// Start and End are byte offsets of the match within Value
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Match reports the first field of the user matching the search, checking
+// email, phone number and name in that order
+func (s UserSearch) Match(user *User) (UserSearchMatch, bool) {
+	if start, end, ok := indexFold(user.Email, s.Term); ok {
+		return UserSearchMatch{Field: UserSearchFieldEmail, Value: user.Email, Start: start, End: end}, true
+	}
+
+	if user.PhoneNumber != "" {
+		if s.PhoneNumber != "" && user.PhoneNumber == s.PhoneNumber {
+			return UserSearchMatch{Field: UserSearchFieldPhoneNumber, Value: user.PhoneNumber, Start: 0, End: len(user.PhoneNumber)}, true
+		}
+		if start, end, ok := indexFold(user.PhoneNumber, s.Term); ok {
+			return UserSearchMatch{Field: UserSearchFieldPhoneNumber, Value: user.PhoneNumber, Start: start, End: end}, true
+		}
+	}
+
+	name := user.FullName()
+	if start, end, ok := indexFold(name, s.Term); ok {
+		return UserSearchMatch{Field: UserSearchFieldName, Value: name, Start: start, End: end}, true
+	}
+	return UserSearchMatch{}, false
+}
+
+// indexFold finds term in s ignoring case and returns the byte range of the match in s
+func indexFold(s, term string) (int, int, bool) {
+	termLen := utf8.RuneCountInString(term)
+	for start := range s {
+		end := start
+		for i := 0; i < termLen && end < len(s); i++ {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+		}
+		if strings.EqualFold(s[start:end], term) {
+			return start, end, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,61 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/internal/users/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUserSearch(t *testing.T) {
+	_, err := domain.NewUserSearch(" a ", false)
+	assert.Error(t, err)
+
+	search, err := domain.NewUserSearch("  Ama   Mensah ", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Ama Mensah", search.Term)
+	assert.Empty(t, search.PhoneNumber)
+
+	search, err = domain.NewUserSearch("024 123 4567", true)
+	require.NoError(t, err)
+	assert.Equal(t, "+233241234567", search.PhoneNumber)
+	assert.True(t, search.IncludeDeleted)
+}
+
+func TestUserSearch_Match(t *testing.T) {
+	user, err := domain.NewUser("ama.mensah@example.com", "password123", "Ama", "Mensah", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, user.SetPhoneNumber("0241234567", time.Now()))
+
+	tests := []struct {
+		term      string
+		wantField string
+		wantValue string
+		wantText  string
+	}{
+		{"MENSAH@", domain.UserSearchFieldEmail, "ama.mensah@example.com", "mensah@"},
+		{"0241234567", domain.UserSearchFieldPhoneNumber, "+233241234567", "+233241234567"},
+		{"1234", domain.UserSearchFieldPhoneNumber, "+233241234567", "1234"},
+		{"ama mens", domain.UserSearchFieldName, "Ama Mensah", "Ama Mens"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			search, err := domain.NewUserSearch(tt.term, false)
+			require.NoError(t, err)
+
+			match, ok := search.Match(user)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantField, match.Field)
+			assert.Equal(t, tt.wantValue, match.Value)
+			assert.Equal(t, tt.wantText, match.Value[match.Start:match.End])
+		})
+	}
+
+	search, err := domain.NewUserSearch("kofi", false)
+	require.NoError(t, err)
+	_, ok := search.Match(user)
+	assert.False(t, ok)
+}
@@ -1,12 +1,17 @@
 package domain
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
+	listings "dongome/internal/listings/domain"
 	"dongome/pkg/errors"
+	"dongome/pkg/ids"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // UserStatus represents the status of a user
@@ -17,8 +22,23 @@ const (
 	UserStatusActive    UserStatus = "active"
 	UserStatusSuspended UserStatus = "suspended"
 	UserStatusDeactive  UserStatus = "deactive"
+	UserStatusDeleted   UserStatus = "deleted"
+	UserStatusMerged    UserStatus = "merged"
 )
 
+// ErasureGracePeriod is how long a deleted account is kept before its
+// personal data is anonymized
+const ErasureGracePeriod = 30 * 24 * time.Hour
+
+// VerificationTokenTTL is how long an email verification token stays valid
+const VerificationTokenTTL = 24 * time.Hour
+
+// VerificationResendCooldown is the minimum time between two verification emails
+const VerificationResendCooldown = 2 * time.Minute
+
+// MaxSuspensionReasonLength is the longest suspension reason that can be recorded
+const MaxSuspensionReasonLength = 500
+
 // UserRole represents the role of a user
 type UserRole string
 
@@ -39,21 +59,31 @@ const (
 
 // User represents a user aggregate root
 type User struct {
-	ID                string     `gorm:"type:uuid;primary_key" json:"id"`
-	Email             string     `gorm:"uniqueIndex;not null" json:"email"`
-	PasswordHash      string     `gorm:"not null" json:"-"`
-	FirstName         string     `gorm:"not null" json:"first_name"`
-	LastName          string     `gorm:"not null" json:"last_name"`
-	PhoneNumber       string     `gorm:"uniqueIndex" json:"phone_number"`
-	Avatar            string     `json:"avatar"`
-	Status            UserStatus `gorm:"default:'pending'" json:"status"`
-	Role              UserRole   `gorm:"default:'buyer'" json:"role"`
-	EmailVerified     bool       `gorm:"default:false" json:"email_verified"`
-	PhoneVerified     bool       `gorm:"default:false" json:"phone_verified"`
-	VerificationToken string     `json:"-"`
-	LastLoginAt       *time.Time `json:"last_login_at"`
-	CreatedAt         time.Time  `json:"created_at"`
-	UpdatedAt         time.Time  `json:"updated_at"`
+	ID                    ids.UserID     `gorm:"type:uuid;primary_key" json:"id"`
+	Email                 string         `gorm:"uniqueIndex;not null" json:"email"`
+	PasswordHash          string         `gorm:"not null" json:"-"`
+	FirstName             string         `gorm:"not null" json:"first_name"`
+	LastName              string         `gorm:"not null" json:"last_name"`
+	PhoneNumber           string         `gorm:"uniqueIndex" json:"phone_number"`
+	Handle                *string        `gorm:"uniqueIndex;size:30" json:"handle,omitempty"`
+	Avatar                string         `json:"avatar"`
+	Locale                string         `gorm:"size:10;not null;default:''" json:"locale,omitempty"`
+	Status                UserStatus     `gorm:"default:'pending'" json:"status"`
+	Role                  UserRole       `gorm:"default:'buyer'" json:"role"`
+	EmailVerified         bool           `gorm:"default:false" json:"email_verified"`
+	PhoneVerified         bool           `gorm:"default:false" json:"phone_verified"`
+	VerificationToken     string         `json:"-"`
+	VerificationExpiresAt *time.Time     `json:"-"`
+	VerificationSentAt    *time.Time     `json:"-"`
+	LastLoginAt           *time.Time     `json:"last_login_at"`
+	DeletedAt             gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	AnonymizedAt          *time.Time     `json:"anonymized_at,omitempty"`
+	MergedIntoID          *ids.UserID    `gorm:"type:uuid;index" json:"merged_into_id,omitempty"`
+	SuspensionReason      string         `json:"suspension_reason,omitempty"`
+	SuspendedAt           *time.Time     `json:"suspended_at,omitempty"`
+	SessionsRevokedAt     *time.Time     `json:"-"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
 
 	// Seller-specific fields
 	SellerProfile *SellerProfile `gorm:"foreignKey:UserID" json:"seller_profile,omitempty"`
@@ -61,23 +91,30 @@ type User struct {
 
 // SellerProfile represents seller-specific information
 type SellerProfile struct {
-	ID                 string             `gorm:"type:uuid;primary_key" json:"id"`
-	UserID             string             `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
-	BusinessName       string             `json:"business_name"`
-	BusinessAddress    string             `json:"business_address"`
-	BusinessPhone      string             `json:"business_phone"`
-	BusinessEmail      string             `json:"business_email"`
-	TaxNumber          string             `json:"tax_number"`
-	VerificationStatus VerificationStatus `gorm:"default:'pending'" json:"verification_status"`
-	VerificationNotes  string             `json:"verification_notes"`
-	Rating             float64            `gorm:"default:0" json:"rating"`
-	TotalReviews       int                `gorm:"default:0" json:"total_reviews"`
-	CreatedAt          time.Time          `json:"created_at"`
-	UpdatedAt          time.Time          `json:"updated_at"`
-}
-
-// NewUser creates a new user
-func NewUser(email, password, firstName, lastName string) (*User, error) {
+	ID                  string             `gorm:"type:uuid;primary_key" json:"id"`
+	UserID              ids.UserID         `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	BusinessName        string             `json:"business_name"`
+	BusinessAddress     string             `json:"business_address"`
+	BusinessPhone       string             `json:"business_phone"`
+	BusinessEmail       string             `json:"business_email"`
+	BusinessHours       []OpeningPeriod    `gorm:"type:jsonb;serializer:json" json:"business_hours"`
+	StoreLocation       listings.Location  `gorm:"embedded;embeddedPrefix:store_" json:"store_location"`
+	TaxNumber           string             `json:"tax_number"`
+	VerificationStatus  VerificationStatus `gorm:"default:'pending'" json:"verification_status"`
+	VerificationNotes   string             `json:"verification_notes"`
+	Rating              float64            `gorm:"default:0" json:"rating"`
+	TotalReviews        int                `gorm:"default:0" json:"total_reviews"`
+	CompletedSales      int                `gorm:"default:0" json:"completed_sales"`
+	ResponseTimeMinutes float64            `json:"response_time_minutes"`
+	Badges              []SellerBadge      `gorm:"type:jsonb;serializer:json" json:"badges"`
+	TrustLevel          TrustLevel         `gorm:"default:'new'" json:"trust_level"`
+	FollowersCount      int                `gorm:"default:0" json:"followers_count"`
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+}
+
+// NewUser creates a new user registered at now
+func NewUser(email, password, firstName, lastName string, now time.Time) (*User, error) {
 	if email == "" {
 		return nil, errors.ValidationError("email is required")
 	}
@@ -97,23 +134,44 @@ func NewUser(email, password, firstName, lastName string) (*User, error) {
 		return nil, err
 	}
 
+	user := &User{
+		ID:            ids.NewUserID(),
+		Email:         email,
+		PasswordHash:  string(hashedPassword),
+		FirstName:     firstName,
+		LastName:      lastName,
+		Status:        UserStatusPending,
+		Role:          UserRoleBuyer,
+		EmailVerified: false,
+		PhoneVerified: false,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
 	// Generate verification token
-	verificationToken := uuid.New().String()
-
-	return &User{
-		ID:                uuid.New().String(),
-		Email:             email,
-		PasswordHash:      string(hashedPassword),
-		FirstName:         firstName,
-		LastName:          lastName,
-		Status:            UserStatusPending,
-		Role:              UserRoleBuyer,
-		EmailVerified:     false,
-		PhoneVerified:     false,
-		VerificationToken: verificationToken,
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
-	}, nil
+	user.regenerateVerificationToken(now)
+
+	return user, nil
+}
+
+// SetPhoneNumber normalizes and sets the user's phone number. An empty value
+// removes it. A changed number has to be verified again.
+func (u *User) SetPhoneNumber(raw string, now time.Time) error {
+	phoneNumber := ""
+	if strings.TrimSpace(raw) != "" {
+		normalized, err := NormalizePhoneNumber(raw)
+		if err != nil {
+			return err
+		}
+		phoneNumber = normalized
+	}
+
+	if phoneNumber != u.PhoneNumber {
+		u.PhoneNumber = phoneNumber
+		u.PhoneVerified = false
+		u.UpdatedAt = now
+	}
+	return nil
 }
 
 // ValidatePassword checks if the provided password matches the user's password
@@ -122,15 +180,61 @@ func (u *User) ValidatePassword(password string) error {
 }
 
 // VerifyEmail marks the user's email as verified
-func (u *User) VerifyEmail() {
+func (u *User) VerifyEmail(now time.Time) error {
+	if u.VerificationTokenExpired(now) {
+		return errors.ValidationError("verification token has expired, request a new one")
+	}
+
 	u.EmailVerified = true
 	u.Status = UserStatusActive
 	u.VerificationToken = ""
-	u.UpdatedAt = time.Now()
+	u.VerificationExpiresAt = nil
+	u.UpdatedAt = now
+	return nil
+}
+
+// VerificationTokenExpired checks if the verification token can no longer be
+// used at now. Tokens without an expiry predate expiring tokens and are
+// treated as expired.
+func (u *User) VerificationTokenExpired(now time.Time) bool {
+	return u.VerificationToken == "" || u.VerificationExpiresAt == nil ||
+		now.After(*u.VerificationExpiresAt)
+}
+
+// ResendVerification prepares a new verification email. An expired token is
+// replaced; a valid one is sent again. Resends are limited by a cooldown.
+func (u *User) ResendVerification(now time.Time) error {
+	if u.EmailVerified {
+		return errors.ValidationError("email is already verified")
+	}
+	if u.Status != UserStatusPending {
+		return errors.ValidationError("user cannot be verified")
+	}
+	if u.VerificationSentAt != nil && now.Sub(*u.VerificationSentAt) < VerificationResendCooldown {
+		return errors.RateLimitedError("a verification email was sent recently, try again later")
+	}
+
+	if u.VerificationTokenExpired(now) {
+		u.regenerateVerificationToken(now)
+		return nil
+	}
+
+	u.VerificationSentAt = &now
+	u.UpdatedAt = now
+	return nil
+}
+
+// regenerateVerificationToken issues a new verification token with a fresh expiry
+func (u *User) regenerateVerificationToken(now time.Time) {
+	expiresAt := now.Add(VerificationTokenTTL)
+	u.VerificationToken = uuid.New().String()
+	u.VerificationExpiresAt = &expiresAt
+	u.VerificationSentAt = &now
+	u.UpdatedAt = now
 }
 
 // UpgradeToSeller upgrades a buyer to seller
-func (u *User) UpgradeToSeller(businessName, businessAddress string) error {
+func (u *User) UpgradeToSeller(businessName, businessAddress string, now time.Time) error {
 	if u.Role == UserRoleSeller {
 		return errors.ValidationError("user is already a seller")
 	}
@@ -145,32 +249,231 @@ func (u *User) UpgradeToSeller(businessName, businessAddress string) error {
 		UserID:             u.ID,
 		BusinessName:       businessName,
 		BusinessAddress:    businessAddress,
+		BusinessHours:      []OpeningPeriod{},
 		VerificationStatus: VerificationStatusPending,
-		CreatedAt:          time.Now(),
-		UpdatedAt:          time.Now(),
+		Badges:             []SellerBadge{},
+		TrustLevel:         TrustLevelNew,
+		CreatedAt:          now,
+		UpdatedAt:          now,
 	}
-	u.UpdatedAt = time.Now()
+	u.UpdatedAt = now
 
 	return nil
 }
 
-// Suspend suspends the user account
-func (u *User) Suspend(reason string) {
+// Suspend suspends the user account and revokes its sessions
+func (u *User) Suspend(reason string, now time.Time) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.ValidationError("a suspension reason is required")
+	}
+	if len(reason) > MaxSuspensionReasonLength {
+		return errors.ValidationError(fmt.Sprintf("suspension reason must be at most %d characters", MaxSuspensionReasonLength))
+	}
+	if u.IsDeleted() || u.IsMerged() {
+		return errors.ValidationError("deleted or merged users cannot be suspended")
+	}
+	if u.Status == UserStatusSuspended {
+		return errors.ConflictError("user is already suspended")
+	}
+
 	u.Status = UserStatusSuspended
-	u.UpdatedAt = time.Now()
+	u.SuspensionReason = reason
+	u.SuspendedAt = &now
+	u.RevokeSessions(now)
+	return nil
 }
 
-// Activate activates the user account
-func (u *User) Activate() {
+// Activate lifts a suspension. Users who never verified their email go back
+// to pending.
+func (u *User) Activate(now time.Time) error {
+	if u.Status != UserStatusSuspended {
+		return errors.ValidationError("user is not suspended")
+	}
+
 	u.Status = UserStatusActive
-	u.UpdatedAt = time.Now()
+	if !u.EmailVerified {
+		u.Status = UserStatusPending
+	}
+	u.SuspensionReason = ""
+	u.SuspendedAt = nil
+	u.UpdatedAt = now
+	return nil
+}
+
+// RevokeSessions expires every access token issued to the user so far
+func (u *User) RevokeSessions(now time.Time) {
+	// Token issue times have second precision, so the revocation is too
+	revokedAt := now.Truncate(time.Second)
+	u.SessionsRevokedAt = &revokedAt
+	u.UpdatedAt = now
+}
+
+// SessionValid checks if an access token issued at issuedAt may still be used
+func (u *User) SessionValid(issuedAt time.Time) bool {
+	if !u.IsActive() {
+		return false
+	}
+	return u.SessionsRevokedAt == nil || !issuedAt.Before(*u.SessionsRevokedAt)
 }
 
 // UpdateLastLogin updates the last login timestamp
-func (u *User) UpdateLastLogin() {
-	now := time.Now()
+func (u *User) UpdateLastLogin(now time.Time) {
 	u.LastLoginAt = &now
-	u.UpdatedAt = time.Now()
+	u.UpdatedAt = now
+}
+
+// Delete marks the account as deleted. Personal data is kept until the
+// erasure grace period has passed and the account is anonymized.
+func (u *User) Delete(now time.Time) error {
+	if u.IsDeleted() {
+		return errors.ValidationError("user is already deleted")
+	}
+
+	u.Status = UserStatusDeleted
+	u.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	u.VerificationToken = ""
+	u.UpdatedAt = now
+	return nil
+}
+
+// ErasureScheduledAt returns when a deleted account's personal data will be erased
+func (u *User) ErasureScheduledAt() time.Time {
+	if !u.DeletedAt.Valid {
+		return time.Time{}
+	}
+	return u.DeletedAt.Time.Add(ErasureGracePeriod)
+}
+
+// ErasureDue checks if the grace period for a deleted account has passed by now
+func (u *User) ErasureDue(now time.Time) bool {
+	return u.IsDeleted() && u.AnonymizedAt == nil &&
+		now.After(u.ErasureScheduledAt())
+}
+
+// Restore reactivates a deleted account whose personal data has not been erased yet
+func (u *User) Restore(now time.Time) error {
+	if !u.IsDeleted() {
+		return errors.ValidationError("user is not deleted")
+	}
+	if u.AnonymizedAt != nil {
+		return errors.ValidationError("user has already been anonymized")
+	}
+
+	u.Status = UserStatusActive
+	if !u.EmailVerified {
+		u.Status = UserStatusPending
+	}
+	u.DeletedAt = gorm.DeletedAt{}
+	u.UpdatedAt = now
+	return nil
+}
+
+// Anonymize irreversibly scrubs personal data from a deleted account
+func (u *User) Anonymize(now time.Time) error {
+	if !u.IsDeleted() {
+		return errors.ValidationError("only deleted users can be anonymized")
+	}
+
+	u.Email = fmt.Sprintf("deleted+%s@anonymized.dongome.invalid", u.ID)
+	u.PasswordHash = ""
+	u.FirstName = "Deleted"
+	u.LastName = "User"
+	u.PhoneNumber = ""
+	u.Handle = nil
+	u.Avatar = ""
+	u.VerificationToken = ""
+	if u.SellerProfile != nil {
+		u.SellerProfile.BusinessName = ""
+		u.SellerProfile.BusinessAddress = ""
+		u.SellerProfile.BusinessPhone = ""
+		u.SellerProfile.BusinessEmail = ""
+		u.SellerProfile.StoreLocation = listings.Location{}
+		u.SellerProfile.TaxNumber = ""
+		u.SellerProfile.VerificationNotes = ""
+		u.SellerProfile.UpdatedAt = now
+	}
+	u.AnonymizedAt = &now
+	u.UpdatedAt = now
+	return nil
+}
+
+// MergeInto consolidates this duplicate account into the primary account and
+// tombstones it. Data owned by other contexts is moved by consumers of the
+// UserMerged event.
+func (u *User) MergeInto(primary *User, now time.Time) error {
+	if primary == nil || u.ID == primary.ID {
+		return errors.ValidationError("cannot merge a user into itself")
+	}
+	if u.IsMerged() || primary.IsMerged() {
+		return errors.ValidationError("user has already been merged")
+	}
+	if u.IsDeleted() || primary.IsDeleted() {
+		return errors.ValidationError("cannot merge deleted users")
+	}
+	if u.Role == UserRoleAdmin || primary.Role == UserRoleAdmin {
+		return errors.ValidationError("cannot merge admin accounts")
+	}
+
+	// Carry over contact details the primary account is missing
+	if primary.PhoneNumber == "" && u.PhoneNumber != "" {
+		primary.PhoneNumber = u.PhoneNumber
+		primary.PhoneVerified = u.PhoneVerified
+	}
+	if primary.Handle == nil {
+		primary.Handle = u.Handle
+	}
+	if primary.Avatar == "" {
+		primary.Avatar = u.Avatar
+	}
+	if !primary.EmailVerified && u.EmailVerified && primary.Email == u.Email {
+		primary.EmailVerified = true
+	}
+
+	// Carry over the seller profile, combining ratings when both are sellers
+	if u.SellerProfile != nil {
+		if primary.SellerProfile == nil {
+			primary.SellerProfile = u.SellerProfile
+			primary.SellerProfile.UserID = primary.ID
+			primary.SellerProfile.UpdatedAt = now
+			primary.Role = UserRoleSeller
+		} else {
+			primary.SellerProfile.mergeReviews(u.SellerProfile)
+			primary.SellerProfile.UpdatedAt = now
+		}
+		u.SellerProfile = nil
+	}
+
+	if primary.LastLoginAt == nil || (u.LastLoginAt != nil && u.LastLoginAt.After(*primary.LastLoginAt)) {
+		primary.LastLoginAt = u.LastLoginAt
+	}
+	if u.CreatedAt.Before(primary.CreatedAt) {
+		primary.CreatedAt = u.CreatedAt
+	}
+	primary.UpdatedAt = now
+
+	// Tombstone the duplicate, freeing its unique identifiers
+	primaryID := primary.ID
+	u.Status = UserStatusMerged
+	u.MergedIntoID = &primaryID
+	u.Email = fmt.Sprintf("merged+%s@merged.dongome.invalid", u.ID)
+	u.PhoneNumber = ""
+	u.Handle = nil
+	u.VerificationToken = ""
+	u.Role = UserRoleBuyer
+	u.UpdatedAt = now
+
+	return nil
+}
+
+// mergeReviews combines another profile's reviews into a weighted average rating
+func (p *SellerProfile) mergeReviews(other *SellerProfile) {
+	total := p.TotalReviews + other.TotalReviews
+	if total == 0 {
+		return
+	}
+	p.Rating = (p.Rating*float64(p.TotalReviews) + other.Rating*float64(other.TotalReviews)) / float64(total)
+	p.TotalReviews = total
 }
 
 // FullName returns the user's full name
@@ -183,6 +486,47 @@ func (u *User) IsActive() bool {
 	return u.Status == UserStatusActive
 }
 
+// IsDeleted checks if the user has deleted their account
+func (u *User) IsDeleted() bool {
+	return u.Status == UserStatusDeleted && u.DeletedAt.Valid
+}
+
+// IsMerged checks if the user was merged into another account
+func (u *User) IsMerged() bool {
+	return u.Status == UserStatusMerged
+}
+
+// ReviewVerification records an admin's decision on a seller's verification.
+// Rejections must give the seller the notes explaining why.
+func (u *User) ReviewVerification(status VerificationStatus, notes string, now time.Time) error {
+	if !u.IsSeller() || u.SellerProfile == nil {
+		return errors.ValidationError("user is not a seller")
+	}
+	notes = strings.TrimSpace(notes)
+	if len(notes) > MaxSuspensionReasonLength {
+		return errors.ValidationError(fmt.Sprintf("verification notes must be at most %d characters", MaxSuspensionReasonLength))
+	}
+
+	switch status {
+	case VerificationStatusApproved:
+	case VerificationStatusRejected:
+		if notes == "" {
+			return errors.ValidationError("verification notes are required when rejecting a seller")
+		}
+	default:
+		return errors.ValidationError("verification status must be approved or rejected")
+	}
+	if u.SellerProfile.VerificationStatus == status {
+		return errors.ConflictError("seller verification already has this status")
+	}
+
+	u.SellerProfile.VerificationStatus = status
+	u.SellerProfile.VerificationNotes = notes
+	u.SellerProfile.UpdatedAt = now
+	u.UpdatedAt = now
+	return nil
+}
+
 // IsSeller checks if the user is a seller
 func (u *User) IsSeller() bool {
 	return u.Role == UserRoleSeller
@@ -194,12 +538,32 @@ func (u *User) IsVerifiedSeller() bool {
 		u.SellerProfile.VerificationStatus == VerificationStatusApproved
 }
 
-// UserRepository defines the interface for user persistence
+// UserFilter defines criteria for listing users
+type UserFilter struct {
+	Status             UserStatus
+	Role               UserRole
+	EmailVerified      *bool
+	VerificationStatus VerificationStatus
+	CreatedFrom        *time.Time
+	CreatedTo          *time.Time
+	IncludeDeleted     bool
+}
+
+// UserRepository defines the interface for user persistence. Finders exclude
+// soft-deleted users unless stated otherwise.
 type UserRepository interface {
 	Save(user *User) error
-	FindByID(id string) (*User, error)
+	FindByID(id ids.UserID) (*User, error)
+	FindByIDIncludingDeleted(id ids.UserID) (*User, error)
 	FindByEmail(email string) (*User, error)
+	FindByPhoneNumber(phoneNumber string) (*User, error)
+	FindByHandle(handle string) (*User, error)
+	FindWithPhoneNumber(afterID ids.UserID, limit int) ([]*User, error)
 	FindByVerificationToken(token string) (*User, error)
+	FindPendingErasure(deletedBefore time.Time, limit int) ([]*User, error)
+	FindAll(filter UserFilter, limit, offset int) ([]*User, int64, error)
+	Search(search UserSearch, limit, offset int) ([]*User, int64, error)
 	Update(user *User) error
-	Delete(id string) error
+	UpdateAll(users ...*User) error
+	Delete(id ids.UserID) error
 }
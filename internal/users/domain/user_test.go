@@ -1,4 +1,3 @@
-package domain
 package domain_test
 
 import (
@@ -55,7 +54,7 @@ func TestNewUser(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user, err := domain.NewUser(tt.email, tt.password, tt.firstName, tt.lastName)
+			user, err := domain.NewUser(tt.email, tt.password, tt.firstName, tt.lastName, time.Now())
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -82,7 +81,7 @@ func TestNewUser(t *testing.T) {
 }
 
 func TestUser_ValidatePassword(t *testing.T) {
-	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe")
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", time.Now())
 	require.NoError(t, err)
 
 	// Test correct password
@@ -95,7 +94,7 @@ func TestUser_ValidatePassword(t *testing.T) {
 }
 
 func TestUser_VerifyEmail(t *testing.T) {
-	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe")
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", time.Now())
 	require.NoError(t, err)
 
 	// Initially not verified
@@ -104,7 +103,7 @@ func TestUser_VerifyEmail(t *testing.T) {
 	assert.NotEmpty(t, user.VerificationToken)
 
 	// Verify email
-	user.VerifyEmail()
+	user.VerifyEmail(time.Now())
 
 	assert.True(t, user.EmailVerified)
 	assert.Equal(t, domain.UserStatusActive, user.Status)
@@ -112,18 +111,18 @@ func TestUser_VerifyEmail(t *testing.T) {
 }
 
 func TestUser_UpgradeToSeller(t *testing.T) {
-	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe")
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", time.Now())
 	require.NoError(t, err)
 
 	// Cannot upgrade unverified user
-	err = user.UpgradeToSeller("Test Business", "123 Business St")
+	err = user.UpgradeToSeller("Test Business", "123 Business St", time.Now())
 	assert.Error(t, err)
 
 	// Verify email first
-	user.VerifyEmail()
+	user.VerifyEmail(time.Now())
 
 	// Now upgrade to seller
-	err = user.UpgradeToSeller("Test Business", "123 Business St")
+	err = user.UpgradeToSeller("Test Business", "123 Business St", time.Now())
 	assert.NoError(t, err)
 
 	assert.Equal(t, domain.UserRoleSeller, user.Role)
@@ -133,29 +132,27 @@ func TestUser_UpgradeToSeller(t *testing.T) {
 	assert.Equal(t, domain.VerificationStatusPending, user.SellerProfile.VerificationStatus)
 
 	// Cannot upgrade again
-	err = user.UpgradeToSeller("Another Business", "456 Another St")
+	err = user.UpgradeToSeller("Another Business", "456 Another St", time.Now())
 	assert.Error(t, err)
 }
 
 func TestUser_UpdateLastLogin(t *testing.T) {
-	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe")
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", time.Now())
 	require.NoError(t, err)
 
 	// Initially no last login
 	assert.Nil(t, user.LastLoginAt)
 
 	// Update last login
-	beforeUpdate := time.Now()
-	user.UpdateLastLogin()
-	afterUpdate := time.Now()
+	loginAt := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	user.UpdateLastLogin(loginAt)
 
 	require.NotNil(t, user.LastLoginAt)
-	assert.True(t, user.LastLoginAt.After(beforeUpdate) || user.LastLoginAt.Equal(beforeUpdate))
-	assert.True(t, user.LastLoginAt.Before(afterUpdate) || user.LastLoginAt.Equal(afterUpdate))
+	assert.Equal(t, loginAt, *user.LastLoginAt)
 }
 
 func TestUser_BusinessLogic(t *testing.T) {
-	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe")
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", time.Now())
 	require.NoError(t, err)
 
 	// Test FullName
@@ -163,12 +160,12 @@ func TestUser_BusinessLogic(t *testing.T) {
 
 	// Test IsActive
 	assert.False(t, user.IsActive()) // Pending users are not active
-	user.VerifyEmail()
+	user.VerifyEmail(time.Now())
 	assert.True(t, user.IsActive())
 
 	// Test IsSeller
 	assert.False(t, user.IsSeller())
-	user.UpgradeToSeller("Test Business", "123 Business St")
+	user.UpgradeToSeller("Test Business", "123 Business St", time.Now())
 	assert.True(t, user.IsSeller())
 
 	// Test IsVerifiedSeller
@@ -177,12 +174,182 @@ func TestUser_BusinessLogic(t *testing.T) {
 	assert.True(t, user.IsVerifiedSeller())
 
 	// Test Suspend
-	user.Suspend("Terms violation")
+	require.NoError(t, user.Suspend("Terms violation", time.Now()))
 	assert.Equal(t, domain.UserStatusSuspended, user.Status)
 	assert.False(t, user.IsActive())
 
 	// Test Activate
-	user.Activate()
+	require.NoError(t, user.Activate(time.Now()))
 	assert.Equal(t, domain.UserStatusActive, user.Status)
 	assert.True(t, user.IsActive())
-}
\ No newline at end of file
+}
+
+func TestUser_Delete(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", now)
+	require.NoError(t, err)
+	user.VerifyEmail(now)
+
+	// Delete account
+	err = user.Delete(now)
+	require.NoError(t, err)
+
+	assert.True(t, user.IsDeleted())
+	assert.False(t, user.IsActive())
+	assert.Equal(t, domain.UserStatusDeleted, user.Status)
+	require.True(t, user.DeletedAt.Valid)
+	assert.Equal(t, user.DeletedAt.Time.Add(domain.ErasureGracePeriod), user.ErasureScheduledAt())
+
+	// Erasure is due once the grace period has passed
+	assert.False(t, user.ErasureDue(now.Add(domain.ErasureGracePeriod)))
+	assert.True(t, user.ErasureDue(now.Add(domain.ErasureGracePeriod+time.Second)))
+
+	// Cannot delete twice
+	err = user.Delete(now)
+	assert.Error(t, err)
+}
+
+func TestUser_Anonymize(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", now)
+	require.NoError(t, err)
+	user.VerifyEmail(now)
+	require.NoError(t, user.UpgradeToSeller("Test Business", "123 Business St", now))
+
+	// Cannot anonymize an account that is not deleted
+	err = user.Anonymize(now)
+	assert.Error(t, err)
+
+	require.NoError(t, user.Delete(now))
+	now = now.Add(domain.ErasureGracePeriod + time.Hour)
+	assert.True(t, user.ErasureDue(now))
+
+	err = user.Anonymize(now)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, "test@example.com", user.Email)
+	assert.Contains(t, user.Email, user.ID)
+	assert.Equal(t, "Deleted User", user.FullName())
+	assert.Empty(t, user.PasswordHash)
+	assert.Empty(t, user.PhoneNumber)
+	assert.Empty(t, user.SellerProfile.BusinessName)
+	assert.Empty(t, user.SellerProfile.BusinessAddress)
+	require.NotNil(t, user.AnonymizedAt)
+	assert.Equal(t, now, *user.AnonymizedAt)
+	assert.False(t, user.ErasureDue(now))
+}
+
+func TestUser_Restore(t *testing.T) {
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", time.Now())
+	require.NoError(t, err)
+	user.VerifyEmail(time.Now())
+
+	// Only deleted users can be restored
+	assert.Error(t, user.Restore(time.Now()))
+
+	require.NoError(t, user.Delete(time.Now()))
+	require.NoError(t, user.Restore(time.Now()))
+	assert.True(t, user.IsActive())
+	assert.False(t, user.IsDeleted())
+	assert.False(t, user.DeletedAt.Valid)
+
+	// Anonymized users cannot be restored
+	require.NoError(t, user.Delete(time.Now()))
+	require.NoError(t, user.Anonymize(time.Now()))
+	assert.Error(t, user.Restore(time.Now()))
+}
+
+func TestUser_VerifyEmail_ExpiredToken(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", now)
+	require.NoError(t, err)
+	require.NotNil(t, user.VerificationExpiresAt)
+	assert.False(t, user.VerificationTokenExpired(now.Add(domain.VerificationTokenTTL-time.Second)))
+
+	now = now.Add(domain.VerificationTokenTTL + time.Minute)
+	assert.True(t, user.VerificationTokenExpired(now))
+	assert.Error(t, user.VerifyEmail(now))
+	assert.False(t, user.EmailVerified)
+}
+
+func TestUser_ResendVerification(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", now)
+	require.NoError(t, err)
+	token := user.VerificationToken
+
+	// Registration just sent an email, so an immediate resend is rate limited
+	assert.Error(t, user.ResendVerification(now.Add(time.Second)))
+
+	// A still valid token is sent again
+	now = now.Add(domain.VerificationResendCooldown + time.Second)
+	require.NoError(t, user.ResendVerification(now))
+	assert.Equal(t, token, user.VerificationToken)
+
+	// An expired token is replaced
+	now = now.Add(domain.VerificationTokenTTL)
+	require.True(t, user.VerificationTokenExpired(now))
+	require.NoError(t, user.ResendVerification(now))
+	assert.NotEqual(t, token, user.VerificationToken)
+	assert.False(t, user.VerificationTokenExpired(now))
+
+	require.NoError(t, user.VerifyEmail(now))
+	assert.Error(t, user.ResendVerification(now.Add(domain.VerificationResendCooldown)))
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "0241234567", want: "+233241234567"},
+		{raw: "024 123 4567", want: "+233241234567"},
+		{raw: "+233241234567", want: "+233241234567"},
+		{raw: "+233 (0) 24-123-4567", want: "+233241234567"},
+		{raw: "00233 24 123 4567", want: "+233241234567"},
+		{raw: "233241234567", want: "+233241234567"},
+		{raw: "241234567", want: "+233241234567"},
+		{raw: "0302123456", want: "+233302123456"},
+		{raw: "+44 20 7946 0958", want: "+442079460958"},
+		{raw: "0141234567", wantErr: true},
+		{raw: "02412345", wantErr: true},
+		{raw: "+233 24 123 45678", wantErr: true},
+		{raw: "call me", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := domain.NormalizePhoneNumber(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUser_SetPhoneNumber(t *testing.T) {
+	user, err := domain.NewUser("test@example.com", "password123", "John", "Doe", time.Now())
+	require.NoError(t, err)
+
+	require.NoError(t, user.SetPhoneNumber("0241234567", time.Now()))
+	assert.Equal(t, "+233241234567", user.PhoneNumber)
+	user.PhoneVerified = true
+
+	// The same number in another format keeps its verification
+	require.NoError(t, user.SetPhoneNumber("+233 24 123 4567", time.Now()))
+	assert.True(t, user.PhoneVerified)
+
+	require.NoError(t, user.SetPhoneNumber("0201234567", time.Now()))
+	assert.False(t, user.PhoneVerified)
+
+	assert.Error(t, user.SetPhoneNumber("12345", time.Now()))
+	assert.Equal(t, "+233201234567", user.PhoneNumber)
+
+	require.NoError(t, user.SetPhoneNumber("", time.Now()))
+	assert.Empty(t, user.PhoneNumber)
+}
@@ -0,0 +1,145 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddressHandler handles HTTP requests for the address book
+type AddressHandler struct {
+	addressService *app.AddressService
+}
+
+// NewAddressHandler creates a new address handler
+func NewAddressHandler(addressService *app.AddressService) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+	}
+}
+
+// RegisterRoutes registers address book routes. The group must be protected by
+// RequireAuth.
+func (h *AddressHandler) RegisterRoutes(r *gin.RouterGroup) {
+	addresses := r.Group("/users/me/addresses")
+	{
+		addresses.GET("", h.ListAddresses)
+		addresses.POST("", h.CreateAddress)
+		addresses.GET("/:addressId", h.GetAddress)
+		addresses.PUT("/:addressId", h.UpdateAddress)
+		addresses.DELETE("/:addressId", h.DeleteAddress)
+		addresses.POST("/:addressId/default", h.SetDefaultAddress)
+	}
+}
+
+// ListAddresses handles listing the caller's addresses
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	addresses, err := h.addressService.ListAddresses(c.Request.Context(), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"addresses": addresses})
+}
+
+// CreateAddress handles adding an address
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	var cmd app.AddressCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = auth.UserID(c)
+
+	address, err := h.addressService.CreateAddress(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+// GetAddress handles retrieving one of the caller's addresses
+func (h *AddressHandler) GetAddress(c *gin.Context) {
+	address, err := h.addressService.GetAddress(c.Request.Context(), auth.UserID(c), c.Param("addressId"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// UpdateAddress handles changing one of the caller's addresses
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	var cmd app.AddressCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = auth.UserID(c)
+	cmd.AddressID = c.Param("addressId")
+
+	address, err := h.addressService.UpdateAddress(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress handles removing one of the caller's addresses
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	err := h.addressService.DeleteAddress(c.Request.Context(), auth.UserID(c), c.Param("addressId"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "address deleted"})
+}
+
+// SetDefaultAddress handles making one of the caller's addresses the default
+func (h *AddressHandler) SetDefaultAddress(c *gin.Context) {
+	address, err := h.addressService.SetDefaultAddress(c.Request.Context(), auth.UserID(c), c.Param("addressId"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
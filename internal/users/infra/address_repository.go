@@ -0,0 +1,85 @@
+package infra
+
+import (
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// AddressGORMRepository implements AddressRepository using GORM
+type AddressGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewAddressGORMRepository creates a new address repository
+func NewAddressGORMRepository(db *gorm.DB) *AddressGORMRepository {
+	return &AddressGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves an address to the database
+func (r *AddressGORMRepository) Save(address *domain.Address) error {
+	return db.ClassifyError(r.db.Create(address).Error)
+}
+
+// FindByID finds an address by ID
+func (r *AddressGORMRepository) FindByID(id string) (*domain.Address, error) {
+	var address domain.Address
+	err := r.db.First(&address, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("address not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &address, nil
+}
+
+// FindByUser finds a user's addresses, default first
+func (r *AddressGORMRepository) FindByUser(userID ids.UserID) ([]*domain.Address, error) {
+	var addresses []*domain.Address
+	err := r.db.Where("user_id = ?", userID).
+		Order("is_default DESC, created_at DESC").
+		Find(&addresses).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return addresses, nil
+}
+
+// Update updates an address in the database
+func (r *AddressGORMRepository) Update(address *domain.Address) error {
+	return db.ClassifyError(r.db.Save(address).Error)
+}
+
+// SetDefault flags one address as the user's default and clears the flag on the others
+func (r *AddressGORMRepository) SetDefault(userID ids.UserID, addressID string) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.Address{}).
+			Where("user_id = ? AND id <> ? AND is_default", userID, addressID).
+			Update("is_default", false).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&domain.Address{}).
+			Where("user_id = ? AND id = ?", userID, addressID).
+			Update("is_default", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.NotFoundError("address not found")
+		}
+		return nil
+	}))
+}
+
+// Delete deletes an address from the database
+func (r *AddressGORMRepository) Delete(id string) error {
+	return db.ClassifyError(r.db.Delete(&domain.Address{}, "id = ?", id).Error)
+}
@@ -0,0 +1,260 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminUserHandler handles HTTP requests for user administration
+type AdminUserHandler struct {
+	userService *app.UserService
+}
+
+// NewAdminUserHandler creates a new admin user handler
+func NewAdminUserHandler(userService *app.UserService) *AdminUserHandler {
+	return &AdminUserHandler{
+		userService: userService,
+	}
+}
+
+// RegisterRoutes registers admin user routes. The group must be protected by
+// the admin role.
+func (h *AdminUserHandler) RegisterRoutes(r *gin.RouterGroup) {
+	users := r.Group("/users")
+	{
+		users.GET("", h.ListUsers)
+		users.GET("/search", h.SearchUsers)
+		users.GET("/:id", h.GetUser)
+		users.POST("/:id/restore", h.RestoreUser)
+		users.POST("/:id/suspend", h.SuspendUser)
+		users.POST("/:id/activate", h.ActivateUser)
+		users.POST("/:id/verification", h.ReviewSellerVerification)
+		users.POST("/merge", h.MergeUsers)
+	}
+}
+
+// ListUsers handles listing users with filters and pagination
+func (h *AdminUserHandler) ListUsers(c *gin.Context) {
+	var query app.ListUsersQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	users, err := h.userService.ListUsers(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// SearchUsers handles searching users by name, email or phone number
+func (h *AdminUserHandler) SearchUsers(c *gin.Context) {
+	var query app.SearchUsersQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	results, err := h.userService.SearchUsers(c.Request.Context(), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// MergeUsers handles merging a duplicate account into a primary account
+func (h *AdminUserHandler) MergeUsers(c *gin.Context) {
+	var cmd app.MergeUsersCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.MergedBy = auth.UserID(c)
+
+	user, err := h.userService.MergeUsers(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetUser handles retrieving any user, including deleted users
+func (h *AdminUserHandler) GetUser(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	user, err := h.userService.GetUserIncludingDeleted(c.Request.Context(), userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// RestoreUser handles restoring a deleted user account
+func (h *AdminUserHandler) RestoreUser(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	cmd := app.RestoreUserCommand{
+		UserID:     userID,
+		RestoredBy: auth.UserID(c),
+	}
+
+	user, err := h.userService.RestoreUser(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// SuspendUser handles suspending a user account
+func (h *AdminUserHandler) SuspendUser(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.SuspendUserCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = userID
+	cmd.SuspendedBy = auth.UserID(c)
+
+	user, err := h.userService.SuspendUser(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ActivateUser handles lifting a user's suspension
+func (h *AdminUserHandler) ActivateUser(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	cmd := app.ActivateUserCommand{
+		UserID:      userID,
+		ActivatedBy: auth.UserID(c),
+	}
+
+	user, err := h.userService.ActivateUser(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ReviewSellerVerification handles approving or rejecting a seller's verification
+func (h *AdminUserHandler) ReviewSellerVerification(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.ReviewSellerVerificationCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = userID
+	cmd.ReviewedBy = auth.UserID(c)
+
+	user, err := h.userService.ReviewSellerVerification(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
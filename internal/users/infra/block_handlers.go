@@ -0,0 +1,99 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlockHandler handles HTTP requests for user blocking
+type BlockHandler struct {
+	blockService *app.BlockService
+}
+
+// NewBlockHandler creates a new block handler
+func NewBlockHandler(blockService *app.BlockService) *BlockHandler {
+	return &BlockHandler{
+		blockService: blockService,
+	}
+}
+
+// RegisterRoutes registers block routes. The group must be protected by
+// RequireAuth.
+func (h *BlockHandler) RegisterRoutes(r *gin.RouterGroup) {
+	blocks := r.Group("/blocks")
+	{
+		blocks.GET("", h.ListBlockedUsers)
+		blocks.POST("", h.BlockUser)
+		blocks.DELETE("/:userId", h.UnblockUser)
+	}
+}
+
+// BlockUser handles blocking another user
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	var cmd app.BlockUserCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.BlockerID = auth.UserID(c)
+
+	block, err := h.blockService.BlockUser(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, block)
+}
+
+// UnblockUser handles lifting a block
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("userId"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	err = h.blockService.UnblockUser(c.Request.Context(), auth.UserID(c), userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user unblocked"})
+}
+
+// ListBlockedUsers handles listing the users blocked by the caller
+func (h *BlockHandler) ListBlockedUsers(c *gin.Context) {
+	blocks, err := h.blockService.ListBlockedUsers(c.Request.Context(), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blocks": blocks})
+}
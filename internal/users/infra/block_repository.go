@@ -0,0 +1,84 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// UserBlockGORMRepository implements UserBlockRepository using GORM
+type UserBlockGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewUserBlockGORMRepository creates a new user block repository
+func NewUserBlockGORMRepository(db *gorm.DB) *UserBlockGORMRepository {
+	return &UserBlockGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a user block to the database
+func (r *UserBlockGORMRepository) Save(block *domain.UserBlock) error {
+	return db.ClassifyError(r.db.Create(block).Error)
+}
+
+// Find finds the block placed by blockerID on blockedID
+func (r *UserBlockGORMRepository) Find(blockerID, blockedID ids.UserID) (*domain.UserBlock, error) {
+	var block domain.UserBlock
+	err := r.db.First(&block, "blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("block not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &block, nil
+}
+
+// FindByBlocker finds the blocks placed by a user, newest first
+func (r *UserBlockGORMRepository) FindByBlocker(blockerID ids.UserID) ([]*domain.UserBlock, error) {
+	var blocks []*domain.UserBlock
+	err := r.db.Where("blocker_id = ?", blockerID).
+		Order("created_at DESC").
+		Find(&blocks).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return blocks, nil
+}
+
+// ExistsBetween checks if either user has blocked the other
+func (r *UserBlockGORMRepository) ExistsBetween(userID, otherUserID ids.UserID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)",
+			userID, otherUserID, otherUserID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, db.ClassifyError(err)
+	}
+	return count > 0, nil
+}
+
+// CountBlockedSince counts the users who blocked blockedID since the given time
+func (r *UserBlockGORMRepository) CountBlockedSince(blockedID ids.UserID, since time.Time) (int, error) {
+	var count int64
+	err := r.db.Model(&domain.UserBlock{}).
+		Where("blocked_id = ? AND created_at >= ?", blockedID, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, db.ClassifyError(err)
+	}
+	return int(count), nil
+}
+
+// Delete deletes the block placed by blockerID on blockedID
+func (r *UserBlockGORMRepository) Delete(blockerID, blockedID ids.UserID) error {
+	return db.ClassifyError(r.db.Delete(&domain.UserBlock{}, "blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Error)
+}
@@ -0,0 +1,76 @@
+package infra
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FileExportArchive writes data export archives as zip files to a local directory
+type FileExportArchive struct {
+	dir string
+}
+
+// NewFileExportArchive creates a new file-based export archive
+func NewFileExportArchive(dir string) *FileExportArchive {
+	return &FileExportArchive{
+		dir: dir,
+	}
+}
+
+// Write stores each section as a JSON document inside a zip archive
+func (a *FileExportArchive) Write(exportID string, sections map[string]interface{}) (string, int64, error) {
+	if err := os.MkdirAll(a.dir, 0o750); err != nil {
+		return "", 0, err
+	}
+
+	path := filepath.Join(a.dir, exportID+".zip")
+	file, err := os.Create(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	archive := zip.NewWriter(file)
+	manifest := map[string]interface{}{
+		"export_id":    exportID,
+		"generated_at": time.Now().UTC(),
+		"sections":     names,
+	}
+	if err := writeJSONEntry(archive, "manifest.json", manifest); err != nil {
+		return "", 0, err
+	}
+	for _, name := range names {
+		if err := writeJSONEntry(archive, name+".json", sections[name]); err != nil {
+			return "", 0, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return "", 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+	return path, info.Size(), nil
+}
+
+func writeJSONEntry(archive *zip.Writer, name string, data interface{}) error {
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
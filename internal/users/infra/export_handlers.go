@@ -0,0 +1,100 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DataExportHandler handles HTTP requests for user data exports
+type DataExportHandler struct {
+	exportService *app.DataExportService
+}
+
+// NewDataExportHandler creates a new data export handler
+func NewDataExportHandler(exportService *app.DataExportService) *DataExportHandler {
+	return &DataExportHandler{
+		exportService: exportService,
+	}
+}
+
+// RegisterRoutes registers data export routes. Users may only export their
+// own data, so the group must be protected by RequireAuth.
+func (h *DataExportHandler) RegisterRoutes(r *gin.RouterGroup) {
+	users := r.Group("/users")
+	{
+		users.POST("/:id/export", h.RequestExport)
+		users.GET("/:id/exports/:exportId", h.GetExport)
+		users.GET("/:id/exports/:exportId/download", h.DownloadExport)
+	}
+}
+
+// RequestExport handles requests for a copy of a user's data
+func (h *DataExportHandler) RequestExport(c *gin.Context) {
+	userID, ok := ownUserID(c, "you can only access your own data exports", false)
+	if !ok {
+		return
+	}
+
+	export, err := h.exportService.RequestExport(c.Request.Context(), userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, export)
+}
+
+// GetExport handles polling the status of a data export. Exports of other
+// users are not found.
+func (h *DataExportHandler) GetExport(c *gin.Context) {
+	userID, ok := ownUserID(c, "you can only access your own data exports", false)
+	if !ok {
+		return
+	}
+
+	export, err := h.exportService.GetExport(c.Request.Context(), userID, c.Param("exportId"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
+// DownloadExport handles downloading a completed data export archive
+func (h *DataExportHandler) DownloadExport(c *gin.Context) {
+	userID, ok := ownUserID(c, "you can only access your own data exports", false)
+	if !ok {
+		return
+	}
+
+	export, err := h.exportService.GetExport(c.Request.Context(), userID, c.Param("exportId"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	if !export.IsDownloadable() {
+		c.JSON(http.StatusConflict, gin.H{"error": i18n.Localize(c, "export is not available for download"), "status": export.Status})
+		return
+	}
+
+	c.FileAttachment(export.FilePath, "dongome-export-"+export.ID+".zip")
+}
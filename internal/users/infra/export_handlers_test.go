@@ -0,0 +1,93 @@
+package infra_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+	"dongome/internal/users/infra"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDataExportRepository keeps data exports in memory
+type fakeDataExportRepository struct {
+	exports map[string]*domain.DataExport
+}
+
+func (r *fakeDataExportRepository) Save(export *domain.DataExport) error {
+	r.exports[export.ID] = export
+	return nil
+}
+
+func (r *fakeDataExportRepository) FindByID(id string) (*domain.DataExport, error) {
+	export, ok := r.exports[id]
+	if !ok {
+		return nil, errors.NotFoundError("export not found")
+	}
+	return export, nil
+}
+
+func (r *fakeDataExportRepository) FindInProgressByUser(userID ids.UserID) (*domain.DataExport, error) {
+	return nil, errors.NotFoundError("export not found")
+}
+
+func (r *fakeDataExportRepository) Update(export *domain.DataExport) error {
+	r.exports[export.ID] = export
+	return nil
+}
+
+func TestDataExportHandler_OnlyServesTheCallersOwnExports(t *testing.T) {
+	tokens := auth.NewTokenManager("test-secret", time.Hour, nil)
+	owner := ids.NewUserID()
+	other := ids.NewUserID()
+
+	export, err := domain.NewDataExport(owner)
+	require.NoError(t, err)
+	otherExport, err := domain.NewDataExport(other)
+	require.NoError(t, err)
+	repo := &fakeDataExportRepository{exports: map[string]*domain.DataExport{export.ID: export, otherExport.ID: otherExport}}
+
+	handler := infra.NewDataExportHandler(app.NewDataExportService(nil, repo, nil, nil))
+	router := newAuthenticatedRouter(tokens, handler.RegisterRoutes)
+	ownerToken := bearerToken(t, tokens, owner, "buyer")
+	otherToken := bearerToken(t, tokens, other, "buyer")
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/v1/users/" + owner.String() + "/export"},
+		{http.MethodGet, "/api/v1/users/" + owner.String() + "/exports/" + export.ID},
+		{http.MethodGet, "/api/v1/users/" + owner.String() + "/exports/" + export.ID + "/download"},
+	}
+	for _, route := range routes {
+		// Anonymous callers are turned away
+		rec := serve(t, router, route.method, route.path, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, route.path)
+
+		// Nor can users reach each other's exports, admins included
+		rec = serve(t, router, route.method, route.path, otherToken)
+		assert.Equal(t, http.StatusForbidden, rec.Code, route.path)
+		rec = serve(t, router, route.method, route.path, bearerToken(t, tokens, ids.NewUserID(), auth.RoleAdmin))
+		assert.Equal(t, http.StatusForbidden, rec.Code, route.path)
+	}
+
+	// The owner sees their own export, as "me" or by ID
+	rec := serve(t, router, http.MethodGet, "/api/v1/users/me/exports/"+export.ID, ownerToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(t, router, http.MethodGet, "/api/v1/users/"+owner.String()+"/exports/"+export.ID, ownerToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Exports of other users are not found under the owner's account
+	rec = serve(t, router, http.MethodGet, "/api/v1/users/me/exports/"+otherExport.ID, ownerToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serve(t, router, http.MethodGet, "/api/v1/users/me/exports/"+otherExport.ID+"/download", ownerToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
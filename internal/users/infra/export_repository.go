@@ -0,0 +1,61 @@
+package infra
+
+import (
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// DataExportGORMRepository implements DataExportRepository using GORM
+type DataExportGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewDataExportGORMRepository creates a new data export repository
+func NewDataExportGORMRepository(db *gorm.DB) *DataExportGORMRepository {
+	return &DataExportGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a data export to the database
+func (r *DataExportGORMRepository) Save(export *domain.DataExport) error {
+	return db.ClassifyError(r.db.Create(export).Error)
+}
+
+// FindByID finds a data export by ID
+func (r *DataExportGORMRepository) FindByID(id string) (*domain.DataExport, error) {
+	var export domain.DataExport
+	err := r.db.First(&export, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("export not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &export, nil
+}
+
+// FindInProgressByUser finds a pending or processing export for a user
+func (r *DataExportGORMRepository) FindInProgressByUser(userID ids.UserID) (*domain.DataExport, error) {
+	var export domain.DataExport
+	err := r.db.Where("user_id = ? AND status IN ?", userID,
+		[]domain.DataExportStatus{domain.DataExportStatusPending, domain.DataExportStatusProcessing}).
+		Order("created_at DESC").
+		First(&export).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("export not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &export, nil
+}
+
+// Update updates a data export in the database
+func (r *DataExportGORMRepository) Update(export *domain.DataExport) error {
+	return db.ClassifyError(r.db.Save(export).Error)
+}
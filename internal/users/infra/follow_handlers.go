@@ -0,0 +1,105 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FollowHandler handles HTTP requests for following sellers
+type FollowHandler struct {
+	followService *app.FollowService
+}
+
+// NewFollowHandler creates a new follow handler
+func NewFollowHandler(followService *app.FollowService) *FollowHandler {
+	return &FollowHandler{
+		followService: followService,
+	}
+}
+
+// RegisterRoutes registers follow routes. The group must be protected by
+// RequireAuth.
+func (h *FollowHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/sellers/:id/follow", h.FollowSeller)
+	r.DELETE("/sellers/:id/follow", h.UnfollowSeller)
+	r.GET("/follows", h.ListFollowedSellers)
+}
+
+// FollowSeller handles following a seller
+func (h *FollowHandler) FollowSeller(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	follow, err := h.followService.FollowSeller(c.Request.Context(), auth.UserID(c), userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, follow)
+}
+
+// UnfollowSeller handles unfollowing a seller
+func (h *FollowHandler) UnfollowSeller(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	err = h.followService.UnfollowSeller(c.Request.Context(), auth.UserID(c), userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "seller unfollowed"})
+}
+
+// ListFollowedSellers handles listing the sellers the caller follows
+func (h *FollowHandler) ListFollowedSellers(c *gin.Context) {
+	var query app.ListFollowedSellersQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	follows, err := h.followService.ListFollowedSellers(c.Request.Context(), auth.UserID(c), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, follows)
+}
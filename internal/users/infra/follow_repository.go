@@ -0,0 +1,102 @@
+package infra
+
+import (
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// SellerFollowGORMRepository implements SellerFollowRepository using GORM
+type SellerFollowGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewSellerFollowGORMRepository creates a new seller follow repository
+func NewSellerFollowGORMRepository(db *gorm.DB) *SellerFollowGORMRepository {
+	return &SellerFollowGORMRepository{
+		db: db,
+	}
+}
+
+// Save saves a follow and increments the seller's followers count
+func (r *SellerFollowGORMRepository) Save(follow *domain.SellerFollow) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(follow).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.SellerProfile{}).
+			Where("user_id = ?", follow.SellerID).
+			Update("followers_count", gorm.Expr("followers_count + 1")).Error
+	}))
+}
+
+// Find finds the follow of sellerID by followerID
+func (r *SellerFollowGORMRepository) Find(followerID, sellerID ids.UserID) (*domain.SellerFollow, error) {
+	var follow domain.SellerFollow
+	err := r.db.First(&follow, "follower_id = ? AND seller_id = ?", followerID, sellerID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("follow not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &follow, nil
+}
+
+// FindByFollower finds the sellers a user follows, newest first
+func (r *SellerFollowGORMRepository) FindByFollower(followerID ids.UserID, limit, offset int) ([]*domain.SellerFollow, int64, error) {
+	var total int64
+	q := r.db.Model(&domain.SellerFollow{}).Where("follower_id = ?", followerID)
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var follows []*domain.SellerFollow
+	err := q.Order("created_at DESC").Limit(limit).Offset(offset).Find(&follows).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return follows, total, nil
+}
+
+// FindFollowedSellerIDs returns the IDs of every seller a user follows
+func (r *SellerFollowGORMRepository) FindFollowedSellerIDs(followerID ids.UserID) ([]ids.UserID, error) {
+	var sellerIDs []ids.UserID
+	err := r.db.Model(&domain.SellerFollow{}).
+		Where("follower_id = ?", followerID).
+		Pluck("seller_id", &sellerIDs).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return sellerIDs, nil
+}
+
+// FindFollowerIDs returns the next batch of a seller's follower IDs after afterID
+func (r *SellerFollowGORMRepository) FindFollowerIDs(sellerID, afterID ids.UserID, limit int) ([]ids.UserID, error) {
+	q := r.db.Model(&domain.SellerFollow{}).Where("seller_id = ?", sellerID)
+	if afterID != "" {
+		q = q.Where("follower_id > ?", afterID)
+	}
+
+	var followerIDs []ids.UserID
+	if err := q.Order("follower_id").Limit(limit).Pluck("follower_id", &followerIDs).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return followerIDs, nil
+}
+
+// Delete deletes a follow and decrements the seller's followers count
+func (r *SellerFollowGORMRepository) Delete(followerID, sellerID ids.UserID) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&domain.SellerFollow{}, "follower_id = ? AND seller_id = ?", followerID, sellerID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&domain.SellerProfile{}).
+			Where("user_id = ? AND followers_count > 0", sellerID).
+			Update("followers_count", gorm.Expr("followers_count - 1")).Error
+	}))
+}
@@ -2,22 +2,40 @@ package infra
 
 import (
 	"net/http"
+	"time"
 
 	"dongome/internal/users/app"
+	"dongome/internal/users/domain"
+	"dongome/pkg/auth"
 	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+	"dongome/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Resend verification requests allowed per client IP and window
+const (
+	resendVerificationLimit  = 5
+	resendVerificationWindow = 15 * time.Minute
+)
+
 // UserHandler handles HTTP requests for users
 type UserHandler struct {
-	userService *app.UserService
+	userService     *app.UserService
+	presenceService *app.PresenceService
+	tokens          *auth.TokenManager
+	resendLimiter   *ratelimit.Limiter
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService *app.UserService) *UserHandler {
+func NewUserHandler(userService *app.UserService, presenceService *app.PresenceService, tokens *auth.TokenManager) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:     userService,
+		presenceService: presenceService,
+		tokens:          tokens,
+		resendLimiter:   ratelimit.NewLimiter(resendVerificationLimit, resendVerificationWindow),
 	}
 }
 
@@ -28,26 +46,41 @@ func (h *UserHandler) RegisterRoutes(r *gin.RouterGroup) {
 		users.POST("/register", h.RegisterUser)
 		users.POST("/login", h.LoginUser)
 		users.POST("/verify-email", h.VerifyEmail)
-		users.POST("/:id/upgrade-to-seller", h.UpgradeToSeller)
+		users.POST("/resend-verification", ratelimit.PerClientIP(h.resendLimiter), h.ResendVerification)
+		users.GET("/by-handle/:handle", h.GetUserByHandle)
+		users.GET("/handles/:handle/availability", h.CheckHandleAvailability)
 		users.GET("/:id", h.GetUser)
 	}
 }
 
+// RegisterAuthenticatedRoutes registers the routes for managing the caller's
+// own account. The group must be protected by RequireAuth.
+func (h *UserHandler) RegisterAuthenticatedRoutes(r *gin.RouterGroup) {
+	r.PUT("/users/me/handle", h.SetHandle)
+	r.PUT("/users/me/locale", h.SetLocale)
+	r.DELETE("/users/:id", h.DeleteUser)
+}
+
 // RegisterUser handles user registration
 func (h *UserHandler) RegisterUser(c *gin.Context) {
 	var cmd app.RegisterUserCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
 		return
 	}
 
+	// Referral links carry the code as ?ref=CODE
+	if cmd.ReferralCode == "" {
+		cmd.ReferralCode = c.Query("ref")
+	}
+
 	user, err := h.userService.RegisterUser(c.Request.Context(), cmd)
 	if err != nil {
 		if domainErr, ok := err.(*errors.DomainError); ok {
-			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": domainErr.Message, "code": domainErr.Code})
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
 		return
 	}
 
@@ -55,6 +88,7 @@ func (h *UserHandler) RegisterUser(c *gin.Context) {
 	response := gin.H{
 		"id":             user.ID,
 		"email":          user.Email,
+		"handle":         user.Handle,
 		"first_name":     user.FirstName,
 		"last_name":      user.LastName,
 		"status":         user.Status,
@@ -71,24 +105,33 @@ func (h *UserHandler) RegisterUser(c *gin.Context) {
 func (h *UserHandler) LoginUser(c *gin.Context) {
 	var cmd app.LoginCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
 		return
 	}
 
 	user, err := h.userService.LoginUser(c.Request.Context(), cmd)
 	if err != nil {
 		if domainErr, ok := err.(*errors.DomainError); ok {
-			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": domainErr.Message, "code": domainErr.Code})
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	token, expiresAt, err := h.tokens.Generate(user.ID, string(user.Role))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
 		return
 	}
 
-	// In a real app, you'd generate and return a JWT token here
 	response := gin.H{
+		"access_token":   token,
+		"token_type":     "Bearer",
+		"expires_at":     expiresAt,
 		"id":             user.ID,
 		"email":          user.Email,
+		"handle":         user.Handle,
 		"first_name":     user.FirstName,
 		"last_name":      user.LastName,
 		"status":         user.Status,
@@ -110,59 +153,63 @@ func (h *UserHandler) VerifyEmail(c *gin.Context) {
 
 	var req VerifyEmailRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
 		return
 	}
 
 	err := h.userService.VerifyEmail(c.Request.Context(), req.Token)
 	if err != nil {
 		if domainErr, ok := err.(*errors.DomainError); ok {
-			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": domainErr.Message, "code": domainErr.Code})
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "email verified successfully"})
 }
 
-// UpgradeToSeller handles user upgrade to seller
-func (h *UserHandler) UpgradeToSeller(c *gin.Context) {
-	userID := c.Param("id")
-
-	var cmd app.UpgradeToSellerCommand
+// ResendVerification handles requests for a new verification email
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var cmd app.ResendVerificationCommand
 	if err := c.ShouldBindJSON(&cmd); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
 		return
 	}
 
-	cmd.UserID = userID
-
-	err := h.userService.UpgradeToSeller(c.Request.Context(), cmd)
+	err := h.userService.ResendVerification(c.Request.Context(), cmd)
 	if err != nil {
 		if domainErr, ok := err.(*errors.DomainError); ok {
-			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": domainErr.Message, "code": domainErr.Code})
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "successfully upgraded to seller"})
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the email is registered and unverified, a verification email has been sent"})
 }
 
 // GetUser handles getting user by ID
 func (h *UserHandler) GetUser(c *gin.Context) {
-	userID := c.Param("id")
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
 
 	user, err := h.userService.GetUser(c.Request.Context(), userID)
 	if err != nil {
 		if domainErr, ok := err.(*errors.DomainError); ok {
-			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": domainErr.Message, "code": domainErr.Code})
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
 		return
 	}
 
@@ -170,6 +217,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	response := gin.H{
 		"id":             user.ID,
 		"email":          user.Email,
+		"handle":         user.Handle,
 		"first_name":     user.FirstName,
 		"last_name":      user.LastName,
 		"phone_number":   user.PhoneNumber,
@@ -182,6 +230,159 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		"created_at":     user.CreatedAt,
 		"seller_profile": user.SellerProfile,
 	}
+	h.addPresence(c, response, user.ID)
+	addStoreStatus(response, user)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserByHandle handles looking up a user's public profile by handle
+func (h *UserHandler) GetUserByHandle(c *gin.Context) {
+	user, err := h.userService.GetUserByHandle(c.Request.Context(), c.Param("handle"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	// Only the public profile, since anyone can look up a handle
+	response := gin.H{
+		"id":         user.ID,
+		"handle":     user.Handle,
+		"first_name": user.FirstName,
+		"last_name":  user.LastName,
+		"avatar":     user.Avatar,
+		"role":       user.Role,
+		"created_at": user.CreatedAt,
+	}
+	if user.SellerProfile != nil {
+		response["business_hours"] = user.SellerProfile.BusinessHours
+		if user.SellerProfile.HasStoreLocation() {
+			response["store_location"] = user.SellerProfile.StoreLocation
+		}
+	}
+	h.addPresence(c, response, user.ID)
+	addStoreStatus(response, user)
 
 	c.JSON(http.StatusOK, response)
 }
+
+// addPresence adds whether the user is online and when they were last seen
+// to a profile response, unless they hide their online status. A profile is
+// still served without it if presence cannot be looked up.
+func (h *UserHandler) addPresence(c *gin.Context, response gin.H, userID ids.UserID) {
+	presence, err := h.presenceService.GetPresence(c.Request.Context(), userID)
+	if err != nil || presence == nil {
+		return
+	}
+	response["online"] = presence.Online
+	response["last_seen_at"] = presence.LastSeenAt
+}
+
+// addStoreStatus adds whether a seller's store is open now to a profile
+// response, for sellers who publish opening hours
+func addStoreStatus(response gin.H, user *domain.User) {
+	if user.SellerProfile == nil {
+		return
+	}
+	if openNow := user.SellerProfile.OpenNow(time.Now()); openNow != nil {
+		response["open_now"] = *openNow
+	}
+}
+
+// CheckHandleAvailability handles checking whether a handle can be claimed
+func (h *UserHandler) CheckHandleAvailability(c *gin.Context) {
+	availability, err := h.userService.CheckHandleAvailability(c.Request.Context(), c.Param("handle"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// SetHandle handles claiming, changing or removing the caller's handle
+func (h *UserHandler) SetHandle(c *gin.Context) {
+	var cmd app.SetHandleCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = auth.UserID(c)
+
+	user, err := h.userService.SetHandle(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": user.ID, "handle": user.Handle})
+}
+
+// SetLocale handles choosing or clearing the caller's locale
+func (h *UserHandler) SetLocale(c *gin.Context) {
+	var cmd app.SetLocaleCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = auth.UserID(c)
+
+	user, err := h.userService.SetLocale(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	// Answer in the new locale straight away
+	locale := user.PreferredLocale()
+	if user.Locale == "" {
+		locale = i18n.Negotiate(c.GetHeader("Accept-Language"))
+	}
+	i18n.SetLocale(c, locale)
+
+	c.JSON(http.StatusOK, gin.H{"id": user.ID, "locale": user.Locale})
+}
+
+// DeleteUser handles account deletion. Users may delete their own account,
+// as "me" or by ID, and admins may delete any account.
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	userID, ok := ownUserID(c, "you can only delete your own account", true)
+	if !ok {
+		return
+	}
+
+	cmd := app.DeleteUserCommand{
+		UserID: userID,
+		Reason: c.Query("reason"),
+	}
+
+	user, err := h.userService.DeleteUser(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":              "account scheduled for deletion",
+		"deleted_at":           user.DeletedAt,
+		"erasure_scheduled_at": user.ErasureScheduledAt(),
+	})
+}
@@ -0,0 +1,71 @@
+package infra_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"dongome/internal/users/app"
+	"dongome/internal/users/infra"
+	"dongome/pkg/auth"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// activeSessions accepts every session
+type activeSessions struct{}
+
+func (activeSessions) SessionActive(ctx context.Context, userID ids.UserID, issuedAt time.Time) (bool, error) {
+	return true, nil
+}
+
+// newAuthenticatedRouter serves the routes registered by register behind the
+// same authentication the API puts in front of them
+func newAuthenticatedRouter(tokens *auth.TokenManager, register func(r *gin.RouterGroup)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	register(router.Group("/api/v1", auth.RequireAuth(tokens), auth.RequireActiveSession(activeSessions{})))
+	return router
+}
+
+// serve sends a request with the token, if any, and returns the response
+func serve(t *testing.T, router *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func bearerToken(t *testing.T, tokens *auth.TokenManager, userID ids.UserID, role string) string {
+	t.Helper()
+	token, _, err := tokens.Generate(userID, role)
+	require.NoError(t, err)
+	return token
+}
+
+func TestUserHandler_DeleteUserRequiresTheAccountOwner(t *testing.T) {
+	tokens := auth.NewTokenManager("test-secret", time.Hour, nil)
+	handler := infra.NewUserHandler(app.NewUserService(nil, nil, nil), nil, tokens)
+	router := newAuthenticatedRouter(tokens, handler.RegisterAuthenticatedRoutes)
+
+	owner := ids.NewUserID()
+	other := ids.NewUserID()
+
+	// Anonymous callers cannot delete anyone
+	rec := serve(t, router, http.MethodDelete, "/api/v1/users/"+owner.String(), "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Nor can users delete each other
+	rec = serve(t, router, http.MethodDelete, "/api/v1/users/"+owner.String(), bearerToken(t, tokens, other, "buyer"))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "FORBIDDEN")
+}
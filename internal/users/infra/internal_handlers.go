@@ -0,0 +1,66 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InternalUserHandler serves user lookups to other Dongome services
+type InternalUserHandler struct {
+	userService *app.UserService
+}
+
+// NewInternalUserHandler creates a new internal user handler
+func NewInternalUserHandler(userService *app.UserService) *InternalUserHandler {
+	return &InternalUserHandler{
+		userService: userService,
+	}
+}
+
+// RegisterRoutes registers internal user routes. The group must be served by
+// the internal listener, which authenticates the calling service.
+func (h *InternalUserHandler) RegisterRoutes(r *gin.RouterGroup) {
+	users := r.Group("/internal/v1/users")
+	{
+		users.GET("/:id", h.GetUser)
+	}
+}
+
+// GetUser handles retrieving the fields other services need about a user
+func (h *InternalUserHandler) GetUser(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	user, err := h.userService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":                 user.ID,
+		"email":              user.Email,
+		"first_name":         user.FirstName,
+		"last_name":          user.LastName,
+		"role":               user.Role,
+		"status":             user.Status,
+		"is_verified_seller": user.IsVerifiedSeller(),
+	})
+}
@@ -0,0 +1,173 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreferencesHandler handles HTTP requests for user preferences
+type PreferencesHandler struct {
+	preferencesService *app.PreferencesService
+}
+
+// NewPreferencesHandler creates a new preferences handler
+func NewPreferencesHandler(preferencesService *app.PreferencesService) *PreferencesHandler {
+	return &PreferencesHandler{
+		preferencesService: preferencesService,
+	}
+}
+
+// RegisterRoutes registers preferences routes. The group must be protected by
+// RequireAuth.
+func (h *PreferencesHandler) RegisterRoutes(r *gin.RouterGroup) {
+	users := r.Group("/users")
+	{
+		users.GET("/me/preferences", h.GetPreferences)
+		users.PATCH("/me/preferences", h.UpdatePreferences)
+		users.PATCH("/me/preferences/notifications", h.UpdateNotificationChannels)
+		users.GET("/:id/preferences/browse", h.GetBrowsePreferences)
+		users.PUT("/:id/preferences/browse", h.UpdateBrowsePreferences)
+	}
+}
+
+// GetPreferences handles retrieving the caller's preferences
+func (h *PreferencesHandler) GetPreferences(c *gin.Context) {
+	preferences, err := h.preferencesService.GetPreferences(c.Request.Context(), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences handles changing the caller's preferences
+func (h *PreferencesHandler) UpdatePreferences(c *gin.Context) {
+	var cmd app.UpdatePreferencesCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = auth.UserID(c)
+
+	preferences, err := h.preferencesService.UpdatePreferences(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdateNotificationChannels handles changing the channels the caller gets each category of notifications on
+func (h *PreferencesHandler) UpdateNotificationChannels(c *gin.Context) {
+	var cmd app.UpdateNotificationChannelsCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = auth.UserID(c)
+
+	preferences, err := h.preferencesService.UpdateNotificationChannels(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// GetBrowsePreferences handles retrieving the caller's browse settings
+func (h *PreferencesHandler) GetBrowsePreferences(c *gin.Context) {
+	userID, ok := ownUserID(c, "you can only access your own preferences", false)
+	if !ok {
+		return
+	}
+
+	browse, err := h.preferencesService.GetBrowsePreferences(c.Request.Context(), userID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, browse)
+}
+
+// UpdateBrowsePreferences handles replacing the caller's browse settings
+func (h *PreferencesHandler) UpdateBrowsePreferences(c *gin.Context) {
+	userID, ok := ownUserID(c, "you can only access your own preferences", false)
+	if !ok {
+		return
+	}
+
+	var cmd app.UpdateBrowsePreferencesCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = userID
+
+	browse, err := h.preferencesService.UpdateBrowsePreferences(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, browse)
+}
+
+// ownUserID resolves the :id path parameter, which must be "me" or the
+// caller's own ID, or any user's ID if allowAdmin and the caller is an admin,
+// and rejects the request with forbidden otherwise
+func ownUserID(c *gin.Context, forbidden string, allowAdmin bool) (ids.UserID, bool) {
+	userID := auth.UserID(c)
+	id := c.Param("id")
+	if id == "me" || id == userID.String() {
+		return userID, true
+	}
+	if !allowAdmin || auth.Role(c) != auth.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": i18n.Localize(c, forbidden), "code": errors.ErrCodeForbidden})
+		return "", false
+	}
+
+	other, err := ids.ParseUserID(id)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return "", false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return "", false
+	}
+	return other, true
+}
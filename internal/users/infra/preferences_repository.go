@@ -0,0 +1,52 @@
+package infra
+
+import (
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+)
+
+// UserPreferencesGORMRepository implements UserPreferencesRepository using GORM
+type UserPreferencesGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewUserPreferencesGORMRepository creates a new user preferences repository
+func NewUserPreferencesGORMRepository(db *gorm.DB) *UserPreferencesGORMRepository {
+	return &UserPreferencesGORMRepository{
+		db: db,
+	}
+}
+
+// FindByUser finds the stored preferences of a user
+func (r *UserPreferencesGORMRepository) FindByUser(userID ids.UserID) (*domain.UserPreferences, error) {
+	var preferences domain.UserPreferences
+	err := r.db.First(&preferences, "user_id = ?", userID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("preferences not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &preferences, nil
+}
+
+// FindByUsers finds the stored preferences of several users
+func (r *UserPreferencesGORMRepository) FindByUsers(userIDs []ids.UserID) ([]*domain.UserPreferences, error) {
+	var preferences []*domain.UserPreferences
+	if len(userIDs) == 0 {
+		return preferences, nil
+	}
+	if err := r.db.Where("user_id IN ?", userIDs).Find(&preferences).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return preferences, nil
+}
+
+// Save inserts or updates a user's preferences
+func (r *UserPreferencesGORMRepository) Save(preferences *domain.UserPreferences) error {
+	return db.ClassifyError(r.db.Save(preferences).Error)
+}
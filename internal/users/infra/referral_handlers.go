@@ -0,0 +1,71 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/httpx"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReferralHandler handles HTTP requests for the referral program
+type ReferralHandler struct {
+	referralService *app.ReferralService
+}
+
+// NewReferralHandler creates a new referral handler
+func NewReferralHandler(referralService *app.ReferralService) *ReferralHandler {
+	return &ReferralHandler{
+		referralService: referralService,
+	}
+}
+
+// RegisterRoutes registers referral routes. The group must be protected by
+// RequireAuth.
+func (h *ReferralHandler) RegisterRoutes(r *gin.RouterGroup) {
+	users := r.Group("/users")
+	{
+		users.GET("/me/referral", h.GetReferralSummary)
+		users.GET("/me/referrals", h.ListReferrals)
+	}
+}
+
+// GetReferralSummary handles retrieving the caller's referral code and referral counts
+func (h *ReferralHandler) GetReferralSummary(c *gin.Context) {
+	summary, err := h.referralService.GetReferralSummary(c.Request.Context(), auth.UserID(c))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// ListReferrals handles listing the users the caller referred
+func (h *ReferralHandler) ListReferrals(c *gin.Context) {
+	var query app.ListReferralsQuery
+	if err := httpx.BindQuery(c, &query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	referrals, err := h.referralService.ListReferrals(c.Request.Context(), auth.UserID(c), query)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, referrals)
+}
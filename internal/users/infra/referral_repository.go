@@ -0,0 +1,114 @@
+package infra
+
+import (
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReferralGORMRepository implements ReferralRepository using GORM
+type ReferralGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewReferralGORMRepository creates a new referral repository
+func NewReferralGORMRepository(db *gorm.DB) *ReferralGORMRepository {
+	return &ReferralGORMRepository{
+		db: db,
+	}
+}
+
+// SaveCode stores a new referral code
+func (r *ReferralGORMRepository) SaveCode(code *domain.ReferralCode) error {
+	return db.ClassifyError(r.db.Create(code).Error)
+}
+
+// FindCodeByUser finds a user's referral code
+func (r *ReferralGORMRepository) FindCodeByUser(userID ids.UserID) (*domain.ReferralCode, error) {
+	var code domain.ReferralCode
+	err := r.db.First(&code, "user_id = ?", userID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("referral code not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &code, nil
+}
+
+// FindCode finds a referral code by its value
+func (r *ReferralGORMRepository) FindCode(value string) (*domain.ReferralCode, error) {
+	var code domain.ReferralCode
+	err := r.db.First(&code, "code = ?", value).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("referral code not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &code, nil
+}
+
+// Create stores a referral unless the user was already referred
+func (r *ReferralGORMRepository) Create(referral *domain.Referral) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "referred_id"}},
+		DoNothing: true,
+	}).Create(referral)
+	if result.Error != nil {
+		return false, db.ClassifyError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindByReferred finds the referral of a referred user
+func (r *ReferralGORMRepository) FindByReferred(referredID ids.UserID) (*domain.Referral, error) {
+	var referral domain.Referral
+	err := r.db.First(&referral, "referred_id = ?", referredID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("referral not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &referral, nil
+}
+
+// FindByReferrer finds the referrals of a referrer, newest first
+func (r *ReferralGORMRepository) FindByReferrer(referrerID ids.UserID, limit, offset int) ([]*domain.Referral, int64, error) {
+	var total int64
+	q := r.db.Model(&domain.Referral{}).Where("referrer_id = ?", referrerID)
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var referrals []*domain.Referral
+	err := q.Order("created_at DESC").Limit(limit).Offset(offset).Find(&referrals).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return referrals, total, nil
+}
+
+// CountByReferrer counts a referrer's referrals by status
+func (r *ReferralGORMRepository) CountByReferrer(referrerID ids.UserID) (domain.ReferralCounts, error) {
+	var counts domain.ReferralCounts
+	err := r.db.Model(&domain.Referral{}).
+		Select("COUNT(*) FILTER (WHERE status = ?) AS pending, COUNT(*) FILTER (WHERE status = ?) AS completed",
+			domain.ReferralStatusPending, domain.ReferralStatusCompleted).
+		Where("referrer_id = ?", referrerID).
+		Scan(&counts).Error
+	if err != nil {
+		return domain.ReferralCounts{}, db.ClassifyError(err)
+	}
+	return counts, nil
+}
+
+// Update updates a referral in the database
+func (r *ReferralGORMRepository) Update(referral *domain.Referral) error {
+	return db.ClassifyError(r.db.Save(referral).Error)
+}
@@ -0,0 +1,44 @@
+package infra
+
+import (
+	"net/http"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerificationReminderHandler handles HTTP requests for verification reminder metrics
+type VerificationReminderHandler struct {
+	reminderService *app.VerificationReminderService
+}
+
+// NewVerificationReminderHandler creates a new verification reminder handler
+func NewVerificationReminderHandler(reminderService *app.VerificationReminderService) *VerificationReminderHandler {
+	return &VerificationReminderHandler{
+		reminderService: reminderService,
+	}
+}
+
+// RegisterRoutes registers verification reminder routes. The group must be
+// protected by the admin role.
+func (h *VerificationReminderHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/verification-reminders/stats", h.GetReminderStats)
+}
+
+// GetReminderStats handles reporting the verification conversion of each reminder step
+func (h *VerificationReminderHandler) GetReminderStats(c *gin.Context) {
+	stats, err := h.reminderService.ReminderStats(c.Request.Context())
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"steps": stats})
+}
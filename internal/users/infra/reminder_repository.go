@@ -0,0 +1,78 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VerificationReminderGORMRepository implements VerificationReminderRepository using GORM
+type VerificationReminderGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewVerificationReminderGORMRepository creates a new verification reminder repository
+func NewVerificationReminderGORMRepository(db *gorm.DB) *VerificationReminderGORMRepository {
+	return &VerificationReminderGORMRepository{
+		db: db,
+	}
+}
+
+// Create stores a reminder sequence unless the user already has one
+func (r *VerificationReminderGORMRepository) Create(reminder *domain.VerificationReminder) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+	if result.Error != nil {
+		return false, db.ClassifyError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindByUserID finds a user's reminder sequence
+func (r *VerificationReminderGORMRepository) FindByUserID(userID ids.UserID) (*domain.VerificationReminder, error) {
+	var reminder domain.VerificationReminder
+	err := r.db.First(&reminder, "user_id = ?", userID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("verification reminder not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &reminder, nil
+}
+
+// FindDue finds scheduled reminders whose next reminder is due, oldest first
+func (r *VerificationReminderGORMRepository) FindDue(now time.Time, limit int) ([]*domain.VerificationReminder, error) {
+	var reminders []*domain.VerificationReminder
+	err := r.db.Where("status = ? AND next_reminder_at <= ?", domain.ReminderStatusScheduled, now).
+		Order("next_reminder_at").
+		Limit(limit).
+		Find(&reminders).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return reminders, nil
+}
+
+// Update updates a reminder sequence in the database
+func (r *VerificationReminderGORMRepository) Update(reminder *domain.VerificationReminder) error {
+	return db.ClassifyError(r.db.Save(reminder).Error)
+}
+
+// CountBySteps counts reminder sequences by step and status
+func (r *VerificationReminderGORMRepository) CountBySteps() ([]domain.ReminderStepCount, error) {
+	var counts []domain.ReminderStepCount
+	err := r.db.Model(&domain.VerificationReminder{}).
+		Select("step, status, COUNT(*) AS count").
+		Group("step, status").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return counts, nil
+}
@@ -1,12 +1,21 @@
 package infra
 
 import (
+	"strings"
+	"time"
+
 	"dongome/internal/users/domain"
+	"dongome/pkg/db"
 	"dongome/pkg/errors"
+	"dongome/pkg/ids"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// likeEscaper escapes LIKE wildcards so search terms match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 // UserGORMRepository implements UserRepository using GORM
 type UserGORMRepository struct {
 	db *gorm.DB
@@ -21,21 +30,31 @@ func NewUserGORMRepository(db *gorm.DB) *UserGORMRepository {
 
 // Save saves a user to the database
 func (r *UserGORMRepository) Save(user *domain.User) error {
-	if err := r.db.Create(user).Error; err != nil {
-		return err
-	}
-	return nil
+	return db.ClassifyError(r.db.Create(user).Error)
 }
 
 // FindByID finds a user by ID
-func (r *UserGORMRepository) FindByID(id string) (*domain.User, error) {
+func (r *UserGORMRepository) FindByID(id ids.UserID) (*domain.User, error) {
 	var user domain.User
 	err := r.db.Preload("SellerProfile").First(&user, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.NotFoundError("user not found")
 		}
-		return nil, err
+		return nil, db.ClassifyError(err)
+	}
+	return &user, nil
+}
+
+// FindByIDIncludingDeleted finds a user by ID, including soft-deleted users
+func (r *UserGORMRepository) FindByIDIncludingDeleted(id ids.UserID) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Unscoped().Preload("SellerProfile").First(&user, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("user not found")
+		}
+		return nil, db.ClassifyError(err)
 	}
 	return &user, nil
 }
@@ -48,11 +67,55 @@ func (r *UserGORMRepository) FindByEmail(email string) (*domain.User, error) {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.NotFoundError("user not found")
 		}
-		return nil, err
+		return nil, db.ClassifyError(err)
+	}
+	return &user, nil
+}
+
+// FindByPhoneNumber finds a user by normalized phone number, including
+// soft-deleted users since they keep their number until anonymized
+func (r *UserGORMRepository) FindByPhoneNumber(phoneNumber string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Unscoped().First(&user, "phone_number = ?", phoneNumber).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("user not found")
+		}
+		return nil, db.ClassifyError(err)
 	}
 	return &user, nil
 }
 
+// FindByHandle finds a user by normalized handle, including soft-deleted
+// users since they keep their handle until anonymized
+func (r *UserGORMRepository) FindByHandle(handle string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Unscoped().Preload("SellerProfile").First(&user, "handle = ?", handle).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFoundError("user not found")
+		}
+		return nil, db.ClassifyError(err)
+	}
+	return &user, nil
+}
+
+// FindWithPhoneNumber finds users that have a phone number, ordered by ID
+// after the given ID, including soft-deleted users
+func (r *UserGORMRepository) FindWithPhoneNumber(afterID ids.UserID, limit int) ([]*domain.User, error) {
+	q := r.db.Unscoped().Where("phone_number IS NOT NULL AND phone_number <> ''")
+	if afterID != "" {
+		q = q.Where("id > ?", afterID)
+	}
+
+	var users []*domain.User
+	err := q.Order("id ASC").Limit(limit).Find(&users).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return users, nil
+}
+
 // FindByVerificationToken finds a user by verification token
 func (r *UserGORMRepository) FindByVerificationToken(token string) (*domain.User, error) {
 	var user domain.User
@@ -61,17 +124,127 @@ func (r *UserGORMRepository) FindByVerificationToken(token string) (*domain.User
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.NotFoundError("user not found")
 		}
-		return nil, err
+		return nil, db.ClassifyError(err)
 	}
 	return &user, nil
 }
 
-// Update updates a user in the database
+// FindPendingErasure finds deleted users that have not been anonymized yet
+func (r *UserGORMRepository) FindPendingErasure(deletedBefore time.Time, limit int) ([]*domain.User, error) {
+	var users []*domain.User
+	err := r.db.Unscoped().Preload("SellerProfile").
+		Where("status = ? AND deleted_at < ? AND anonymized_at IS NULL", domain.UserStatusDeleted, deletedBefore).
+		Order("deleted_at ASC").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return users, nil
+}
+
+// FindAll finds users matching the filter, newest first, with the total match count
+func (r *UserGORMRepository) FindAll(filter domain.UserFilter, limit, offset int) ([]*domain.User, int64, error) {
+	q := r.db.Model(&domain.User{})
+	if filter.IncludeDeleted {
+		q = q.Unscoped()
+	}
+	if filter.Status != "" {
+		q = q.Where("users.status = ?", filter.Status)
+	}
+	if filter.Role != "" {
+		q = q.Where("users.role = ?", filter.Role)
+	}
+	if filter.EmailVerified != nil {
+		q = q.Where("users.email_verified = ?", *filter.EmailVerified)
+	}
+	if filter.VerificationStatus != "" {
+		q = q.Joins("JOIN seller_profiles ON seller_profiles.user_id = users.id").
+			Where("seller_profiles.verification_status = ?", filter.VerificationStatus)
+	}
+	if filter.CreatedFrom != nil {
+		q = q.Where("users.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		q = q.Where("users.created_at < ?", *filter.CreatedTo)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var users []*domain.User
+	err := q.Preload("SellerProfile").
+		Order("users.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return users, total, nil
+}
+
+// Search finds users whose name, email or phone number contains the search
+// term. Exact email and phone matches are listed first, then newest users.
+// The trigram indexes from migration 000013 keep the ILIKE scans fast.
+func (r *UserGORMRepository) Search(search domain.UserSearch, limit, offset int) ([]*domain.User, int64, error) {
+	pattern := "%" + likeEscaper.Replace(search.Term) + "%"
+
+	q := r.db.Model(&domain.User{})
+	if search.IncludeDeleted {
+		q = q.Unscoped()
+	}
+	matches := r.db.Where("users.email ILIKE ?", pattern).
+		Or("users.first_name ILIKE ?", pattern).
+		Or("users.last_name ILIKE ?", pattern).
+		Or("(users.first_name || ' ' || users.last_name) ILIKE ?", pattern).
+		Or("users.phone_number ILIKE ?", pattern)
+	if search.PhoneNumber != "" {
+		matches = matches.Or("users.phone_number = ?", search.PhoneNumber)
+	}
+	q = q.Where(matches)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+
+	var users []*domain.User
+	err := q.Preload("SellerProfile").
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "(LOWER(users.email) = LOWER(?) OR users.phone_number = ?) DESC, users.created_at DESC",
+			Vars: []interface{}{search.Term, search.PhoneNumber},
+		}}).
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, db.ClassifyError(err)
+	}
+	return users, total, nil
+}
+
+// Update updates a user in the database. Soft-deleted users can be updated,
+// so that they can be anonymized and restored.
 func (r *UserGORMRepository) Update(user *domain.User) error {
-	return r.db.Session(&gorm.Session{FullSaveAssociations: true}).Save(user).Error
+	return db.ClassifyError(r.db.Unscoped().Session(&gorm.Session{FullSaveAssociations: true}).Save(user).Error)
+}
+
+// UpdateAll updates several users in a single transaction
+func (r *UserGORMRepository) UpdateAll(users ...*domain.User) error {
+	return db.ClassifyError(r.db.Transaction(func(tx *gorm.DB) error {
+		for _, user := range users {
+			if err := tx.Unscoped().Session(&gorm.Session{FullSaveAssociations: true}).Save(user).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
 }
 
-// Delete deletes a user from the database
-func (r *UserGORMRepository) Delete(id string) error {
-	return r.db.Delete(&domain.User{}, "id = ?", id).Error
+// Delete soft-deletes a user, keeping the row for the history that references it
+func (r *UserGORMRepository) Delete(id ids.UserID) error {
+	return db.ClassifyError(r.db.Delete(&domain.User{}, "id = ?", id).Error)
 }
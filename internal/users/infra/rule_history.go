@@ -0,0 +1,63 @@
+package infra
+
+import (
+	"context"
+	"time"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/db"
+	"dongome/pkg/rules"
+
+	"gorm.io/gorm"
+)
+
+// AutoSuspendHistory replays the auto-suspend rule against past blocks
+type AutoSuspendHistory struct {
+	db *gorm.DB
+}
+
+// NewAutoSuspendHistory creates a new auto-suspend history
+func NewAutoSuspendHistory(db *gorm.DB) *AutoSuspendHistory {
+	return &AutoSuspendHistory{
+		db: db,
+	}
+}
+
+// blockCase is a block with what was known about the blocked user at the time
+type blockCase struct {
+	BlockedID      string
+	CreatedAt      time.Time
+	UserCreatedAt  time.Time
+	EmailVerified  bool
+	BlocksReceived int
+}
+
+// Cases returns one case per block placed since the given time, most recent
+// first. Blocks received are counted as of each block; whether the email was
+// verified is as of now, since its history is not kept.
+func (h *AutoSuspendHistory) Cases(ctx context.Context, since time.Time, limit int) ([]rules.Case, error) {
+	var blocks []blockCase
+	err := h.db.Table("user_blocks AS b").
+		Select(`b.blocked_id, b.created_at, u.created_at AS user_created_at, u.email_verified,
+			(SELECT COUNT(*) FROM user_blocks p WHERE p.blocked_id = b.blocked_id
+				AND p.created_at > b.created_at - (? * INTERVAL '1 second') AND p.created_at <= b.created_at) AS blocks_received`,
+			int(app.AutoSuspendWindow.Seconds())).
+		Joins("JOIN users u ON u.id = b.blocked_id").
+		Where("b.created_at >= ?", since).
+		Order("b.created_at DESC").
+		Limit(limit).
+		Scan(&blocks).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+
+	cases := make([]rules.Case, 0, len(blocks))
+	for _, block := range blocks {
+		cases = append(cases, rules.Case{
+			Subject:    block.BlockedID,
+			OccurredAt: block.CreatedAt,
+			Facts:      app.AutoSuspendFacts(block.BlocksReceived, block.CreatedAt.Sub(block.UserCreatedAt), block.EmailVerified),
+		})
+	}
+	return cases, nil
+}
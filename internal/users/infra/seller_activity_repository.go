@@ -0,0 +1,133 @@
+package infra
+
+import (
+	"time"
+
+	"dongome/internal/users/domain"
+	"dongome/pkg/db"
+	"dongome/pkg/ids"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SellerActivityGORMRepository implements SellerActivityRepository using GORM
+type SellerActivityGORMRepository struct {
+	db *gorm.DB
+}
+
+// NewSellerActivityGORMRepository creates a new seller activity repository
+func NewSellerActivityGORMRepository(db *gorm.DB) *SellerActivityGORMRepository {
+	return &SellerActivityGORMRepository{
+		db: db,
+	}
+}
+
+// Record stores the activity, ignoring events that were already recorded
+func (r *SellerActivityGORMRepository) Record(activity *domain.SellerActivity) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(activity)
+	if result.Error != nil {
+		return false, db.ClassifyError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Stats aggregates a seller's sales, reviews and recent responses
+func (r *SellerActivityGORMRepository) Stats(sellerID ids.UserID, responsesSince time.Time) (domain.SellerStats, error) {
+	var stats domain.SellerStats
+
+	var sales int64
+	err := r.db.Model(&domain.SellerActivity{}).
+		Where("seller_id = ? AND kind = ?", sellerID, domain.ActivitySale).
+		Count(&sales).Error
+	if err != nil {
+		return stats, db.ClassifyError(err)
+	}
+	stats.CompletedSales = int(sales)
+
+	var reviews aggregate
+	err = r.db.Model(&domain.SellerActivity{}).
+		Select("COUNT(*) AS count, COALESCE(AVG(value), 0) AS average").
+		Where("seller_id = ? AND kind = ?", sellerID, domain.ActivityReview).
+		Scan(&reviews).Error
+	if err != nil {
+		return stats, db.ClassifyError(err)
+	}
+	stats.TotalReviews = int(reviews.Count)
+	stats.Rating = reviews.Average
+
+	var responses aggregate
+	err = r.db.Model(&domain.SellerActivity{}).
+		Select("COUNT(*) AS count, COALESCE(AVG(value), 0) AS average").
+		Where("seller_id = ? AND kind = ? AND occurred_at >= ?", sellerID, domain.ActivityResponse, responsesSince).
+		Scan(&responses).Error
+	if err != nil {
+		return stats, db.ClassifyError(err)
+	}
+	stats.ResponseSamples = int(responses.Count)
+	stats.ResponseTimeMinutes = responses.Average
+
+	return stats, nil
+}
+
+// aggregate is the result of a count and average query
+type aggregate struct {
+	Count   int64
+	Average float64
+}
+
+// FindProfiles finds the seller profiles of the given users
+func (r *SellerActivityGORMRepository) FindProfiles(userIDs []ids.UserID) ([]*domain.SellerProfile, error) {
+	var profiles []*domain.SellerProfile
+	if len(userIDs) == 0 {
+		return profiles, nil
+	}
+	if err := r.db.Where("user_id IN ?", userIDs).Find(&profiles).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return profiles, nil
+}
+
+// FindSellerIDs returns the next batch of seller user IDs after afterID
+func (r *SellerActivityGORMRepository) FindSellerIDs(afterID ids.UserID, limit int) ([]ids.UserID, error) {
+	query := r.db.Model(&domain.SellerProfile{}).Order("user_id").Limit(limit)
+	if afterID != "" {
+		query = query.Where("user_id > ?", afterID)
+	}
+	var sellerIDs []ids.UserID
+	if err := query.Pluck("user_id", &sellerIDs).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return sellerIDs, nil
+}
+
+// Engagement returns the next batch of sellers with their last login and the
+// inquiries and responses they received since since
+func (r *SellerActivityGORMRepository) Engagement(afterID ids.UserID, limit int, since time.Time) ([]domain.SellerEngagement, error) {
+	query := r.db.Table("seller_profiles").
+		Select("seller_profiles.user_id AS seller_id, users.last_login_at, "+
+			"COUNT(seller_activities.event_id) FILTER (WHERE seller_activities.kind = ?) AS inquiries, "+
+			"COUNT(seller_activities.event_id) FILTER (WHERE seller_activities.kind = ?) AS responses",
+			domain.ActivityInquiry, domain.ActivityResponse).
+		Joins("JOIN users ON users.id = seller_profiles.user_id AND users.deleted_at IS NULL").
+		Joins("LEFT JOIN seller_activities ON seller_activities.seller_id = seller_profiles.user_id "+
+			"AND seller_activities.kind IN ? AND seller_activities.occurred_at >= ?",
+			[]domain.ActivityKind{domain.ActivityInquiry, domain.ActivityResponse}, since).
+		Group("seller_profiles.user_id, users.last_login_at").
+		Order("seller_profiles.user_id").
+		Limit(limit)
+	if afterID != "" {
+		query = query.Where("seller_profiles.user_id > ?", afterID)
+	}
+
+	var engagement []domain.SellerEngagement
+	if err := query.Scan(&engagement).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return engagement, nil
+}
+
+// UpdateProfile updates a seller profile in the database
+func (r *SellerActivityGORMRepository) UpdateProfile(profile *domain.SellerProfile) error {
+	return db.ClassifyError(r.db.Save(profile).Error)
+}
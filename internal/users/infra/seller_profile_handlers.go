@@ -0,0 +1,96 @@
+package infra
+
+import (
+	"net/http"
+	"time"
+
+	"dongome/internal/users/app"
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SellerProfileHandler handles HTTP requests for seller profiles
+type SellerProfileHandler struct {
+	sellerProfileService *app.SellerProfileService
+}
+
+// NewSellerProfileHandler creates a new seller profile handler
+func NewSellerProfileHandler(sellerProfileService *app.SellerProfileService) *SellerProfileHandler {
+	return &SellerProfileHandler{
+		sellerProfileService: sellerProfileService,
+	}
+}
+
+// RegisterRoutes registers seller profile routes
+func (h *SellerProfileHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/users/:id/upgrade-to-seller", h.UpgradeToSeller)
+}
+
+// RegisterAuthenticatedRoutes registers the routes for managing the caller's
+// own seller profile. The group must be protected by RequireAuth.
+func (h *SellerProfileHandler) RegisterAuthenticatedRoutes(r *gin.RouterGroup) {
+	r.PUT("/users/me/seller-profile", h.UpdateSellerProfile)
+}
+
+// UpgradeToSeller handles user upgrade to seller
+func (h *SellerProfileHandler) UpgradeToSeller(c *gin.Context) {
+	userID, err := ids.ParseUserID(c.Param("id"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	var cmd app.UpgradeToSellerCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = userID
+
+	err = h.sellerProfileService.UpgradeToSeller(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "successfully upgraded to seller"})
+}
+
+// UpdateSellerProfile handles editing the caller's seller profile
+func (h *SellerProfileHandler) UpdateSellerProfile(c *gin.Context) {
+	var cmd app.UpdateSellerProfileCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	cmd.UserID = auth.UserID(c)
+
+	user, err := h.sellerProfileService.UpdateSellerProfile(c.Request.Context(), cmd)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"seller_profile": user.SellerProfile,
+		"open_now":       user.SellerProfile.OpenNow(time.Now()),
+	})
+}
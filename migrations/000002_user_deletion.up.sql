@@ -0,0 +1,10 @@
+-- Allow soft-deleted accounts
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users ADD CONSTRAINT users_status_check
+    CHECK (status IN ('pending', 'active', 'suspended', 'deactive', 'deleted'));
+
+-- Account deletion and erasure tracking
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN anonymized_at TIMESTAMP;
+
+CREATE INDEX idx_users_deleted_at ON users(deleted_at);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS update_data_exports_updated_at ON data_exports;
+DROP INDEX IF EXISTS idx_data_exports_user_id;
+DROP TABLE IF EXISTS data_exports;
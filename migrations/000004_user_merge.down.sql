@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_users_merged_into_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS merged_into_id;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users ADD CONSTRAINT users_status_check
+    CHECK (status IN ('pending', 'active', 'suspended', 'deactive', 'deleted'));
@@ -0,0 +1,9 @@
+-- Allow accounts merged into another account
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users ADD CONSTRAINT users_status_check
+    CHECK (status IN ('pending', 'active', 'suspended', 'deactive', 'deleted', 'merged'));
+
+-- Link a merged duplicate to the account it was merged into
+ALTER TABLE users ADD COLUMN merged_into_id UUID REFERENCES users(id);
+
+CREATE INDEX idx_users_merged_into_id ON users(merged_into_id);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS update_ownership_transfers_updated_at ON ownership_transfers;
+DROP INDEX IF EXISTS idx_ownership_records_transfer_id;
+DROP INDEX IF EXISTS idx_ownership_records_listing_id;
+DROP INDEX IF EXISTS idx_ownership_transfers_to_seller_id;
+DROP INDEX IF EXISTS idx_ownership_transfers_from_seller_id;
+DROP INDEX IF EXISTS idx_ownership_transfers_listing_id;
+DROP TABLE IF EXISTS ownership_records;
+DROP TABLE IF EXISTS ownership_transfers;
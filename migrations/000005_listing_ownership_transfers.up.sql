@@ -0,0 +1,38 @@
+-- Listing and storefront ownership transfers
+CREATE TABLE ownership_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('listing', 'storefront')),
+    listing_id UUID REFERENCES listings(id) ON DELETE CASCADE,
+    from_seller_id UUID NOT NULL REFERENCES users(id),
+    to_seller_id UUID NOT NULL REFERENCES users(id),
+    initiated_by UUID NOT NULL REFERENCES users(id),
+    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'rejected', 'cancelled')),
+    note TEXT,
+    sender_confirmed_at TIMESTAMP,
+    recipient_confirmed_at TIMESTAMP,
+    listings_transferred INTEGER DEFAULT 0,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((scope = 'listing') = (listing_id IS NOT NULL))
+);
+
+-- Ownership history of listings
+CREATE TABLE ownership_records (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    listing_id UUID NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    transfer_id UUID NOT NULL REFERENCES ownership_transfers(id),
+    from_seller_id UUID NOT NULL REFERENCES users(id),
+    to_seller_id UUID NOT NULL REFERENCES users(id),
+    transferred_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_ownership_transfers_listing_id ON ownership_transfers(listing_id);
+CREATE INDEX idx_ownership_transfers_from_seller_id ON ownership_transfers(from_seller_id);
+CREATE INDEX idx_ownership_transfers_to_seller_id ON ownership_transfers(to_seller_id);
+CREATE INDEX idx_ownership_records_listing_id ON ownership_records(listing_id);
+CREATE INDEX idx_ownership_records_transfer_id ON ownership_records(transfer_id);
+
+CREATE TRIGGER update_ownership_transfers_updated_at BEFORE UPDATE ON ownership_transfers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_blocks_blocked_id;
+DROP INDEX IF EXISTS idx_user_blocks_pair;
+DROP TABLE IF EXISTS user_blocks;
@@ -0,0 +1,12 @@
+-- User-to-user blocks
+CREATE TABLE user_blocks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE UNIQUE INDEX idx_user_blocks_pair ON user_blocks(blocker_id, blocked_id);
+CREATE INDEX idx_user_blocks_blocked_id ON user_blocks(blocked_id);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS update_areas_updated_at ON areas;
+DROP TRIGGER IF EXISTS update_cities_updated_at ON cities;
+DROP TRIGGER IF EXISTS update_regions_updated_at ON regions;
+DROP INDEX IF EXISTS idx_areas_city_slug;
+DROP INDEX IF EXISTS idx_cities_region_slug;
+DROP TABLE IF EXISTS areas;
+DROP TABLE IF EXISTS cities;
+DROP TABLE IF EXISTS regions;
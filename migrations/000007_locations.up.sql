@@ -0,0 +1,36 @@
+-- Canonical location reference data (region -> city -> area)
+CREATE TABLE regions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    slug VARCHAR(100) UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE cities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    region_id UUID NOT NULL REFERENCES regions(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE areas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    city_id UUID NOT NULL REFERENCES cities(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_cities_region_slug ON cities(region_id, slug);
+CREATE UNIQUE INDEX idx_areas_city_slug ON areas(city_id, slug);
+
+CREATE TRIGGER update_regions_updated_at BEFORE UPDATE ON regions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_cities_updated_at BEFORE UPDATE ON cities
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_areas_updated_at BEFORE UPDATE ON areas
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,17 @@
+-- User preferences and notification settings
+CREATE TABLE user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    language VARCHAR(5) NOT NULL DEFAULT 'en',
+    currency VARCHAR(3) NOT NULL DEFAULT 'GHS',
+    default_region VARCHAR(100),
+    marketing_opt_in BOOLEAN NOT NULL DEFAULT FALSE,
+    notify_messages BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_offers BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_order_updates BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_saved_searches BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_user_preferences_updated_at BEFORE UPDATE ON user_preferences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
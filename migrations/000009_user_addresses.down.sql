@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_addresses_updated_at ON addresses;
+DROP TABLE IF EXISTS addresses;
@@ -0,0 +1,18 @@
+-- Per-route latency statistics, persisted per deploy version and time window
+CREATE TABLE sla_windows (
+    id UUID PRIMARY KEY,
+    version VARCHAR(100) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    route_class VARCHAR(50) NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    violations BIGINT NOT NULL DEFAULT 0,
+    max_latency_ms BIGINT NOT NULL DEFAULT 0,
+    exemplars TEXT,
+    window_start TIMESTAMP NOT NULL,
+    window_end TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sla_windows_version ON sla_windows(version);
+CREATE INDEX idx_sla_windows_window_end ON sla_windows(window_end);
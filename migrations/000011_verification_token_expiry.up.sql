@@ -0,0 +1,8 @@
+-- Expiring email verification tokens
+ALTER TABLE users ADD COLUMN verification_expires_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN verification_sent_at TIMESTAMP;
+
+-- Give outstanding tokens a full validity period from now
+UPDATE users
+SET verification_expires_at = CURRENT_TIMESTAMP + INTERVAL '24 hours'
+WHERE verification_token IS NOT NULL AND verification_token <> '';
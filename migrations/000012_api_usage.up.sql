@@ -0,0 +1,19 @@
+-- Daily API usage per client and endpoint
+CREATE TABLE api_usage (
+    day DATE NOT NULL,
+    user_id VARCHAR(36) NOT NULL DEFAULT '',
+    api_key VARCHAR(16) NOT NULL DEFAULT '',
+    app_version VARCHAR(50) NOT NULL DEFAULT '',
+    api_version VARCHAR(10) NOT NULL DEFAULT '',
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    error_count BIGINT NOT NULL DEFAULT 0,
+    server_error_count BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, user_id, api_key, app_version, api_version, method, route)
+);
+
+CREATE INDEX idx_api_usage_user_id ON api_usage(user_id, day);
+CREATE INDEX idx_api_usage_api_key ON api_usage(api_key, day);
+CREATE INDEX idx_api_usage_api_version ON api_usage(api_version, day);
@@ -0,0 +1,8 @@
+-- Trigram indexes for admin user search by name, email or phone number
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_users_email_trgm ON users USING GIN (email gin_trgm_ops);
+CREATE INDEX idx_users_first_name_trgm ON users USING GIN (first_name gin_trgm_ops);
+CREATE INDEX idx_users_last_name_trgm ON users USING GIN (last_name gin_trgm_ops);
+CREATE INDEX idx_users_full_name_trgm ON users USING GIN ((first_name || ' ' || last_name) gin_trgm_ops);
+CREATE INDEX idx_users_phone_number_trgm ON users USING GIN (phone_number gin_trgm_ops);
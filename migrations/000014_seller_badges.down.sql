@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS seller_activities;
+
+ALTER TABLE seller_profiles
+    DROP COLUMN IF EXISTS trust_level,
+    DROP COLUMN IF EXISTS badges,
+    DROP COLUMN IF EXISTS response_time_minutes,
+    DROP COLUMN IF EXISTS completed_sales;
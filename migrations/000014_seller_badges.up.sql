@@ -0,0 +1,16 @@
+-- Seller badges and trust levels computed from seller activity
+ALTER TABLE seller_profiles
+    ADD COLUMN completed_sales INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN response_time_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN badges JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN trust_level VARCHAR(20) NOT NULL DEFAULT 'new';
+
+CREATE TABLE seller_activities (
+    event_id VARCHAR(255) PRIMARY KEY,
+    seller_id UUID NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    value DOUBLE PRECISION NOT NULL DEFAULT 0,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_seller_activities_seller ON seller_activities(seller_id, kind, occurred_at);
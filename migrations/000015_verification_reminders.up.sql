@@ -0,0 +1,14 @@
+-- Email verification reminder sequences, one per registered user
+CREATE TABLE verification_reminders (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'completed', 'verified', 'cancelled')),
+    registered_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    step INTEGER NOT NULL DEFAULT 0,
+    next_reminder_at TIMESTAMP WITH TIME ZONE,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_verification_reminders_due ON verification_reminders(status, next_reminder_at);
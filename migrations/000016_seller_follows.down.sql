@@ -0,0 +1,3 @@
+ALTER TABLE seller_profiles DROP COLUMN IF EXISTS followers_count;
+
+DROP TABLE IF EXISTS seller_follows;
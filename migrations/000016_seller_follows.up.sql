@@ -0,0 +1,13 @@
+-- Users following sellers to hear about their new listings
+CREATE TABLE seller_follows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (follower_id <> seller_id)
+);
+
+CREATE UNIQUE INDEX idx_seller_follows_pair ON seller_follows(follower_id, seller_id);
+CREATE INDEX idx_seller_follows_seller_id ON seller_follows(seller_id);
+
+ALTER TABLE seller_profiles ADD COLUMN followers_count INTEGER NOT NULL DEFAULT 0;
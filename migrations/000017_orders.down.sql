@@ -0,0 +1,4 @@
+ALTER TABLE listings DROP COLUMN IF EXISTS reserved_until;
+ALTER TABLE listings DROP COLUMN IF EXISTS reserved_for;
+
+DROP TABLE IF EXISTS orders;
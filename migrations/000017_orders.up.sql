@@ -0,0 +1,28 @@
+-- Orders awaiting or completing payment, and the listing reservations that hold
+-- an item for a buyer while they pay
+CREATE TABLE orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    listing_id UUID NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id),
+    title VARCHAR(255) NOT NULL,
+    amount DECIMAL(12,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending_payment',
+    payment_due_at TIMESTAMP NOT NULL,
+    paid_at TIMESTAMP,
+    abandoned_at TIMESTAMP,
+    recovered BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_orders_buyer_id ON orders(buyer_id);
+CREATE INDEX idx_orders_seller_id ON orders(seller_id);
+CREATE INDEX idx_orders_listing_id ON orders(listing_id);
+CREATE INDEX idx_orders_payment_due ON orders(status, payment_due_at);
+CREATE INDEX idx_orders_category_created ON orders(category_id, created_at);
+
+ALTER TABLE listings ADD COLUMN reserved_for UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE listings ADD COLUMN reserved_until TIMESTAMP;
@@ -0,0 +1,3 @@
+-- Email, SMS and push settings per notification category; categories without
+-- settings use the defaults
+ALTER TABLE user_preferences ADD COLUMN notification_channels JSONB NOT NULL DEFAULT '{}';
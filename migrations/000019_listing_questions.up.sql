@@ -0,0 +1,18 @@
+-- Public questions about listings and the sellers' answers
+CREATE TABLE listing_questions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    listing_id UUID NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    asker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    answer TEXT,
+    answered_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    answered_at TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    moderation_reason VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_listing_questions_listing ON listing_questions(listing_id, status);
+CREATE INDEX idx_listing_questions_asker_id ON listing_questions(asker_id);
+CREATE INDEX idx_listing_questions_status ON listing_questions(status, updated_at);
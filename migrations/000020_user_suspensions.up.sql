@@ -0,0 +1,5 @@
+-- Why and when a user was suspended, and when their sessions were last
+-- force-expired; tokens issued before sessions_revoked_at are rejected
+ALTER TABLE users ADD COLUMN suspension_reason VARCHAR(500);
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN sessions_revoked_at TIMESTAMP;
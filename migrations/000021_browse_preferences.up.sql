@@ -0,0 +1,3 @@
+-- Default sort, view density and last-used filters per category, synced across
+-- a user's devices; an empty object means the defaults
+ALTER TABLE user_preferences ADD COLUMN browse JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,4 @@
+-- When a draft is scheduled to go live; NULL for unscheduled listings
+ALTER TABLE listings ADD COLUMN publish_at TIMESTAMP;
+
+CREATE INDEX idx_listings_publish_at ON listings(publish_at) WHERE status = 'draft' AND publish_at IS NOT NULL;
@@ -0,0 +1,26 @@
+-- The code each user shares to refer others
+CREATE TABLE referral_codes (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_referral_codes_code ON referral_codes(code);
+
+-- Referral ledger: one entry per referred user, completed by their first purchase
+CREATE TABLE referrals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    referrer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    referred_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(16) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    order_id UUID,
+    order_amount DECIMAL(12,2),
+    currency VARCHAR(3),
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_referrals_referred_id ON referrals(referred_id);
+CREATE INDEX idx_referrals_referrer_id ON referrals(referrer_id, created_at);
@@ -0,0 +1,3 @@
+-- Optional public @handle, stored lowercase so uniqueness is case-insensitive
+ALTER TABLE users ADD COLUMN handle VARCHAR(30);
+CREATE UNIQUE INDEX idx_users_handle ON users(handle);
@@ -0,0 +1,18 @@
+-- Photos uploaded ahead of a listing; unattached photos are deleted once
+-- expires_at passes
+CREATE TABLE staged_images (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    storage_key VARCHAR(255) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size BIGINT NOT NULL,
+    listing_id UUID REFERENCES listings(id) ON DELETE SET NULL,
+    attached_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_staged_images_owner_id ON staged_images(owner_id);
+CREATE INDEX idx_staged_images_listing_id ON staged_images(listing_id);
+CREATE INDEX idx_staged_images_expires_at ON staged_images(expires_at) WHERE listing_id IS NULL;
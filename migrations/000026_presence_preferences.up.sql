@@ -0,0 +1,2 @@
+-- Users can hide when they were last online from their public profile
+ALTER TABLE user_preferences ADD COLUMN hide_online_status BOOLEAN NOT NULL DEFAULT FALSE;
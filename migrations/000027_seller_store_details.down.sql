@@ -0,0 +1,6 @@
+ALTER TABLE seller_profiles DROP COLUMN IF EXISTS store_longitude;
+ALTER TABLE seller_profiles DROP COLUMN IF EXISTS store_latitude;
+ALTER TABLE seller_profiles DROP COLUMN IF EXISTS store_area;
+ALTER TABLE seller_profiles DROP COLUMN IF EXISTS store_city;
+ALTER TABLE seller_profiles DROP COLUMN IF EXISTS store_region;
+ALTER TABLE seller_profiles DROP COLUMN IF EXISTS business_hours;
@@ -0,0 +1,7 @@
+-- Sellers can publish opening hours and where their store is
+ALTER TABLE seller_profiles ADD COLUMN business_hours JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE seller_profiles ADD COLUMN store_region VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE seller_profiles ADD COLUMN store_city VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE seller_profiles ADD COLUMN store_area VARCHAR(100);
+ALTER TABLE seller_profiles ADD COLUMN store_latitude DOUBLE PRECISION;
+ALTER TABLE seller_profiles ADD COLUMN store_longitude DOUBLE PRECISION;
@@ -0,0 +1,26 @@
+-- Listings keep a copy of each seller's standing to show on listing cards
+CREATE TABLE seller_cards (
+    seller_id UUID PRIMARY KEY,
+    verified BOOLEAN NOT NULL DEFAULT FALSE,
+    rating DOUBLE PRECISION NOT NULL DEFAULT 0,
+    total_reviews INTEGER NOT NULL DEFAULT 0,
+    response_time_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    trust_level VARCHAR(20) NOT NULL DEFAULT 'new',
+    badges JSONB NOT NULL DEFAULT '[]',
+    stats_as_of TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Seed the cards from existing seller profiles; later standings arrive as events
+INSERT INTO seller_cards (seller_id, verified, rating, total_reviews, response_time_minutes, trust_level, badges, stats_as_of, updated_at)
+SELECT
+    user_id,
+    verification_status = 'approved',
+    COALESCE(rating, 0),
+    COALESCE(total_reviews, 0),
+    COALESCE(response_time_minutes, 0),
+    COALESCE(trust_level, 'new'),
+    COALESCE((SELECT jsonb_agg(badge->>'type') FROM jsonb_array_elements(badges) AS badge), '[]'),
+    updated_at,
+    CURRENT_TIMESTAMP
+FROM seller_profiles;
@@ -0,0 +1,2 @@
+-- Users can choose the language API responses and emails are written in
+ALTER TABLE users ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_seller_activities_occurred;
+DROP TABLE IF EXISTS ranking_policies;
+DROP INDEX IF EXISTS idx_seller_cards_penalized;
+ALTER TABLE seller_cards
+    DROP COLUMN IF EXISTS ranking_factor,
+    DROP COLUMN IF EXISTS ranking_reasons,
+    DROP COLUMN IF EXISTS ranked_at;
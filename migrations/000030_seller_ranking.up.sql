@@ -0,0 +1,23 @@
+-- Listings of stale or unresponsive sellers are demoted in search by a factor
+ALTER TABLE seller_cards
+    ADD COLUMN ranking_factor DOUBLE PRECISION NOT NULL DEFAULT 1,
+    ADD COLUMN ranking_reasons JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN ranked_at TIMESTAMP;
+
+CREATE INDEX idx_seller_cards_penalized ON seller_cards (ranking_factor) WHERE ranking_factor < 1;
+
+-- Administrators tune the penalties; defaults apply until a policy is saved
+CREATE TABLE ranking_policies (
+    id VARCHAR(50) PRIMARY KEY,
+    stale_after_days INTEGER NOT NULL,
+    stale_penalty DOUBLE PRECISION NOT NULL,
+    min_response_rate DOUBLE PRECISION NOT NULL,
+    min_inquiries INTEGER NOT NULL,
+    unresponsive_penalty DOUBLE PRECISION NOT NULL,
+    response_window_days INTEGER NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Engagement is counted per seller and kind over the response window
+CREATE INDEX IF NOT EXISTS idx_seller_activities_occurred ON seller_activities (seller_id, kind, occurred_at);
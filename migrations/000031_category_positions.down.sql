@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_categories_parent_position;
+ALTER TABLE categories DROP COLUMN IF EXISTS position;
@@ -0,0 +1,4 @@
+-- Order of a category among its siblings, lowest first
+ALTER TABLE categories ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_categories_parent_position ON categories(parent_id, position);
@@ -0,0 +1,8 @@
+-- Expressions administrators set for business rules; defaults in code apply to rules missing here
+CREATE TABLE business_rules (
+    key VARCHAR(100) PRIMARY KEY,
+    expression TEXT NOT NULL,
+    updated_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_listings_active_views;
+DROP INDEX IF EXISTS idx_listings_active_price;
+DROP INDEX IF EXISTS idx_listings_active_newest;
@@ -0,0 +1,4 @@
+-- Search sorts break ties by ID so cursors can resume after any listing
+CREATE INDEX idx_listings_active_newest ON listings(created_at DESC, id DESC) WHERE status = 'active';
+CREATE INDEX idx_listings_active_price ON listings(price, id) WHERE status = 'active';
+CREATE INDEX idx_listings_active_views ON listings(views_count DESC, id DESC) WHERE status = 'active';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS attribute_labels;
+DROP TABLE IF EXISTS category_translations;
@@ -0,0 +1,18 @@
+-- Category names in locales other than English, which is kept on the category itself
+CREATE TABLE category_translations (
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    locale VARCHAR(10) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (category_id, locale)
+);
+
+-- Labels shown for listing attribute names, per locale
+CREATE TABLE attribute_labels (
+    key VARCHAR(100) NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (key, locale)
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_listings_search_vector;
+CREATE INDEX idx_listings_search ON listings USING gin(to_tsvector('english', title || ' ' || description));
+DROP TRIGGER IF EXISTS update_listings_search_vector ON listings;
+DROP FUNCTION IF EXISTS update_listing_search_vector();
+ALTER TABLE listings DROP COLUMN IF EXISTS search_vector;
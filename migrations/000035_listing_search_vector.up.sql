@@ -0,0 +1,23 @@
+-- Listing text kept as a tsvector, so searches read it instead of parsing every
+-- title and description. Titles weigh more than descriptions when ranking.
+ALTER TABLE listings ADD COLUMN search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION update_listing_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'B');
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER update_listings_search_vector BEFORE INSERT OR UPDATE OF title, description ON listings
+    FOR EACH ROW EXECUTE FUNCTION update_listing_search_vector();
+
+UPDATE listings SET search_vector =
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B');
+
+DROP INDEX IF EXISTS idx_listings_search;
+CREATE INDEX idx_listings_search_vector ON listings USING gin(search_vector);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_listings_location;
+DROP EXTENSION IF EXISTS earthdistance;
+DROP EXTENSION IF EXISTS cube;
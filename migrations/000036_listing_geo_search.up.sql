@@ -0,0 +1,7 @@
+-- Radius searches measure great-circle distances with the earthdistance module.
+-- Listings without coordinates store zero for both and are left out of the index.
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+CREATE INDEX idx_listings_location ON listings USING gist(ll_to_earth(latitude, longitude))
+    WHERE status = 'active' AND (latitude <> 0 OR longitude <> 0);
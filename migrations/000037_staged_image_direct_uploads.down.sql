@@ -0,0 +1 @@
+ALTER TABLE staged_images DROP COLUMN IF EXISTS awaiting_upload;
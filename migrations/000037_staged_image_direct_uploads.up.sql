@@ -0,0 +1,3 @@
+-- Photos sellers' apps upload straight to object storage are recorded before
+-- the upload and flagged until it is confirmed
+ALTER TABLE staged_images ADD COLUMN awaiting_upload BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- Why a listing was taken down because of its seller's account, so it can go
+-- back live once the seller is back in good standing
+ALTER TABLE listings ADD COLUMN holds JSONB;
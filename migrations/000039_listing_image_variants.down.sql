@@ -0,0 +1,4 @@
+ALTER TABLE listing_images
+    DROP COLUMN IF EXISTS processed_at,
+    DROP COLUMN IF EXISTS variants,
+    DROP COLUMN IF EXISTS storage_key;
@@ -0,0 +1,6 @@
+-- Listing photos keep where their original is stored and the resized WebP
+-- copies the worker makes of them
+ALTER TABLE listing_images
+    ADD COLUMN storage_key VARCHAR(255),
+    ADD COLUMN variants JSONB,
+    ADD COLUMN processed_at TIMESTAMP;
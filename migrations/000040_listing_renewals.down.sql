@@ -0,0 +1,4 @@
+ALTER TABLE listings
+    DROP COLUMN IF EXISTS relisted_from,
+    DROP COLUMN IF EXISTS renewed_at,
+    DROP COLUMN IF EXISTS renewal_count;
@@ -0,0 +1,5 @@
+-- Listings count their renewals and remember the listing they were relisted from
+ALTER TABLE listings
+    ADD COLUMN renewal_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN renewed_at TIMESTAMP,
+    ADD COLUMN relisted_from UUID;
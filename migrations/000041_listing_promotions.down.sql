@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_listings_promoted_until;
+DROP TABLE IF EXISTS listing_promotions;
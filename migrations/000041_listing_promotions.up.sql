@@ -0,0 +1,24 @@
+-- Promotion packages sellers buy for their listings, paid with Mobile Money
+CREATE TABLE listing_promotions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    listing_id UUID NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id),
+    package_id VARCHAR(50) NOT NULL,
+    days INTEGER NOT NULL,
+    amount DECIMAL(12,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    payer VARCHAR(20) NOT NULL,
+    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'failed')),
+    payment_reference VARCHAR(64),
+    financial_transaction_id VARCHAR(64),
+    paid_at TIMESTAMP,
+    applied_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_listing_promotions_listing_id ON listing_promotions(listing_id);
+CREATE INDEX idx_listing_promotions_seller_id ON listing_promotions(seller_id);
+
+-- The worker looks up promotions that have ended
+CREATE INDEX idx_listings_promoted_until ON listings(promoted_until) WHERE is_promoted;
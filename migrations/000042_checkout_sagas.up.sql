@@ -0,0 +1,22 @@
+-- Checkout sagas coordinate the steps of each order's checkout and undo them
+-- when the checkout fails or times out
+CREATE TABLE checkout_sagas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    buyer_id UUID NOT NULL REFERENCES users(id),
+    listing_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'completed', 'compensating', 'compensated')),
+    step VARCHAR(50) NOT NULL,
+    completed JSONB NOT NULL DEFAULT '[]',
+    compensations JSONB,
+    deadline_at TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    history JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The worker looks up running sagas past their deadline and failed compensations
+CREATE INDEX idx_checkout_sagas_deadline ON checkout_sagas(deadline_at) WHERE status = 'running';
+CREATE INDEX idx_checkout_sagas_compensating ON checkout_sagas(updated_at) WHERE status = 'compensating';
@@ -0,0 +1,11 @@
+ALTER TABLE listings ADD COLUMN IF NOT EXISTS views_count INTEGER DEFAULT 0;
+ALTER TABLE listings ADD COLUMN IF NOT EXISTS favorites_count INTEGER DEFAULT 0;
+
+UPDATE listings
+SET views_count = listing_counters.views, favorites_count = listing_counters.favorites
+FROM listing_counters
+WHERE listing_counters.listing_id = listings.id;
+
+CREATE INDEX idx_listings_active_views ON listings(views_count DESC, id DESC) WHERE status = 'active';
+
+DROP TABLE IF EXISTS listing_counters;
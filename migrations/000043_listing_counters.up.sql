@@ -0,0 +1,20 @@
+-- View and favorite counts move off the listings row, so counting views of
+-- hot listings no longer contends on it or bumps updated_at
+CREATE TABLE listing_counters (
+    listing_id UUID PRIMARY KEY REFERENCES listings(id) ON DELETE CASCADE,
+    views BIGINT NOT NULL DEFAULT 0,
+    favorites BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO listing_counters (listing_id, views, favorites, updated_at)
+SELECT id, COALESCE(views_count, 0), COALESCE(favorites_count, 0), CURRENT_TIMESTAMP
+FROM listings
+WHERE COALESCE(views_count, 0) > 0 OR COALESCE(favorites_count, 0) > 0;
+
+-- Search sorts by most viewed, ties broken by listing ID
+CREATE INDEX idx_listing_counters_views ON listing_counters(views DESC, listing_id DESC);
+
+DROP INDEX IF EXISTS idx_listings_active_views;
+ALTER TABLE listings DROP COLUMN IF EXISTS views_count;
+ALTER TABLE listings DROP COLUMN IF EXISTS favorites_count;
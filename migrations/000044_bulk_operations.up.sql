@@ -0,0 +1,33 @@
+-- Administrator actions over many listings, carried out by the worker in batches
+CREATE TABLE bulk_operations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(50) NOT NULL CHECK (kind IN ('suspend_seller_listings', 'restore_seller_listings', 'reindex_category')),
+    target_id VARCHAR(64) NOT NULL,
+    reason VARCHAR(500),
+    requested_by UUID NOT NULL REFERENCES users(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'cancelled')),
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    cancelled_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bulk_operations_status ON bulk_operations(status, created_at);
+
+-- The result of a bulk operation on each listing it covers
+CREATE TABLE bulk_operation_items (
+    operation_id UUID NOT NULL REFERENCES bulk_operations(id) ON DELETE CASCADE,
+    listing_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'skipped', 'failed')),
+    error TEXT,
+    processed_at TIMESTAMP,
+    PRIMARY KEY (operation_id, listing_id)
+);
+
+CREATE INDEX idx_bulk_operation_items_pending ON bulk_operation_items(operation_id, listing_id) WHERE status = 'pending';
@@ -0,0 +1,24 @@
+-- Searches buyers saved, with the filters new listings are matched against
+CREATE TABLE saved_searches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    query VARCHAR(255) NOT NULL DEFAULT '',
+    category_id VARCHAR(64) NOT NULL DEFAULT '',
+    condition VARCHAR(20) NOT NULL DEFAULT '',
+    min_price DECIMAL(12,2),
+    max_price DECIMAL(12,2),
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    negotiable BOOLEAN,
+    latitude DECIMAL(10,8),
+    longitude DECIMAL(11,8),
+    radius_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    alerts_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_alerted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_saved_searches_user_id ON saved_searches(user_id, created_at DESC);
+CREATE INDEX idx_saved_searches_alerts ON saved_searches(category_id, id) WHERE alerts_enabled;
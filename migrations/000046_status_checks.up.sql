@@ -0,0 +1,12 @@
+-- Health check history behind the public status page, written by the worker
+CREATE TABLE status_checks (
+    id BIGSERIAL PRIMARY KEY,
+    component VARCHAR(50) NOT NULL,
+    state VARCHAR(20) NOT NULL CHECK (state IN ('operational', 'degraded', 'down')),
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    message VARCHAR(500),
+    checked_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_status_checks_component ON status_checks(component, checked_at DESC);
+CREATE INDEX idx_status_checks_checked_at ON status_checks(checked_at);
@@ -0,0 +1,18 @@
+-- Users' reports that a listing breaks the marketplace's rules, for moderators to review
+CREATE TABLE listing_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    listing_id UUID NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(32) NOT NULL,
+    details TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Each user can report a listing once
+CREATE UNIQUE INDEX idx_listing_reports_reporter ON listing_reports(listing_id, reporter_id);
+CREATE INDEX idx_listing_reports_status ON listing_reports(status, created_at);
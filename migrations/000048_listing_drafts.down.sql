@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_listings_stale_drafts;
+
+DELETE FROM listings WHERE status = 'draft' AND (category_id IS NULL OR price = 0 OR condition = '');
+
+ALTER TABLE listings DROP CONSTRAINT IF EXISTS listings_condition_check;
+ALTER TABLE listings ADD CONSTRAINT listings_condition_check CHECK (condition IN ('new', 'like_new', 'good', 'fair', 'poor', 'for_parts'));
+
+ALTER TABLE listings DROP CONSTRAINT IF EXISTS listings_price_check;
+ALTER TABLE listings ADD CONSTRAINT listings_price_check CHECK (price > 0);
+
+ALTER TABLE listings DROP CONSTRAINT IF EXISTS listings_category_check;
+ALTER TABLE listings ALTER COLUMN category_id SET NOT NULL;
@@ -0,0 +1,16 @@
+-- Drafts are saved while the seller is still filling them in, so the
+-- category, price and condition are only required once a listing is published
+ALTER TABLE listings ALTER COLUMN category_id DROP NOT NULL;
+ALTER TABLE listings ADD CONSTRAINT listings_category_check CHECK (status = 'draft' OR category_id IS NOT NULL);
+
+ALTER TABLE listings DROP CONSTRAINT IF EXISTS listings_price_check;
+ALTER TABLE listings ADD CONSTRAINT listings_price_check CHECK (price > 0 OR (status = 'draft' AND price = 0));
+
+ALTER TABLE listings DROP CONSTRAINT IF EXISTS listings_condition_check;
+ALTER TABLE listings ADD CONSTRAINT listings_condition_check CHECK (
+    condition IN ('new', 'like_new', 'good', 'fair', 'poor', 'for_parts')
+    OR (status = 'draft' AND condition = '')
+);
+
+-- The worker deletes drafts that have not been touched for a while
+CREATE INDEX idx_listings_stale_drafts ON listings(updated_at) WHERE status = 'draft' AND publish_at IS NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS listing_import_rows;
+DROP TABLE IF EXISTS listing_imports;
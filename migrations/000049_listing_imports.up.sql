@@ -0,0 +1,32 @@
+-- Files of listings uploaded by sellers, turned into drafts by the worker in batches
+CREATE TABLE listing_imports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    file_name VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed')),
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    created INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_listing_imports_seller ON listing_imports(seller_id, created_at DESC);
+CREATE INDEX idx_listing_imports_pending ON listing_imports(created_at) WHERE status = 'pending';
+
+-- Each row of an import file and the result of importing it
+CREATE TABLE listing_import_rows (
+    import_id UUID NOT NULL REFERENCES listing_imports(id) ON DELETE CASCADE,
+    line INTEGER NOT NULL,
+    "values" JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'created', 'failed')),
+    error TEXT,
+    -- Drafts created from a row may be deleted later by their seller or the cleanup job
+    listing_id UUID REFERENCES listings(id) ON DELETE SET NULL,
+    processed_at TIMESTAMP,
+    PRIMARY KEY (import_id, line)
+);
+
+CREATE INDEX idx_listing_import_rows_pending ON listing_import_rows(import_id, line) WHERE status = 'pending';
@@ -0,0 +1,11 @@
+-- Every change to the price of a published listing, so buyers can see drops
+CREATE TABLE listing_price_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    listing_id UUID NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    old_price DECIMAL(12,2) NOT NULL,
+    new_price DECIMAL(12,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    changed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_listing_price_history_listing ON listing_price_history(listing_id, changed_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS listing_stats_events;
+DROP TABLE IF EXISTS listing_daily_stats;
@@ -0,0 +1,22 @@
+-- Seller analytics read model: what buyers did with each listing per day,
+-- projected from events by the worker. There is no foreign key to listings,
+-- so a seller's history outlives the listings they delete.
+CREATE TABLE listing_daily_stats (
+    listing_id UUID NOT NULL,
+    day DATE NOT NULL,
+    seller_id UUID NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    favorites BIGINT NOT NULL DEFAULT 0,
+    messages BIGINT NOT NULL DEFAULT 0,
+    orders BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (listing_id, day)
+);
+
+CREATE INDEX idx_listing_daily_stats_seller ON listing_daily_stats(seller_id, day);
+
+-- Events already counted in listing_daily_stats, so redelivered events count once
+CREATE TABLE listing_stats_events (
+    event_id VARCHAR(255) PRIMARY KEY,
+    recorded_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,48 @@
+-- Referrals
+ALTER TABLE referrals ADD COLUMN order_amount DECIMAL(12,2), ADD COLUMN currency VARCHAR(3);
+UPDATE referrals SET order_amount = order_amount_minor / 100.0, currency = order_amount_currency WHERE order_id IS NOT NULL;
+ALTER TABLE referrals DROP COLUMN order_amount_minor, DROP COLUMN order_amount_currency;
+
+-- Orders
+ALTER TABLE orders ADD COLUMN amount DECIMAL(12,2), ADD COLUMN currency VARCHAR(3);
+UPDATE orders SET amount = amount_minor / 100.0, currency = amount_currency;
+ALTER TABLE orders ALTER COLUMN amount SET NOT NULL, ALTER COLUMN currency SET NOT NULL;
+ALTER TABLE orders DROP COLUMN amount_minor, DROP COLUMN amount_currency;
+
+-- Offers
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_amount_check;
+ALTER TABLE offers ADD COLUMN amount DECIMAL(12,2), ADD COLUMN currency VARCHAR(3), ADD COLUMN asking_price DECIMAL(12,2);
+UPDATE offers SET amount = amount_minor / 100.0, currency = amount_currency, asking_price = asking_price_minor / 100.0;
+ALTER TABLE offers ALTER COLUMN amount SET NOT NULL, ALTER COLUMN currency SET NOT NULL, ALTER COLUMN asking_price SET NOT NULL;
+ALTER TABLE offers ADD CONSTRAINT offers_amount_check CHECK (amount > 0);
+ALTER TABLE offers
+    DROP COLUMN amount_minor, DROP COLUMN amount_currency,
+    DROP COLUMN asking_price_minor, DROP COLUMN asking_price_currency;
+
+-- Promotions
+ALTER TABLE listing_promotions ADD COLUMN amount DECIMAL(12,2), ADD COLUMN currency VARCHAR(3);
+UPDATE listing_promotions SET amount = amount_minor / 100.0, currency = amount_currency;
+ALTER TABLE listing_promotions ALTER COLUMN amount SET NOT NULL, ALTER COLUMN currency SET NOT NULL;
+ALTER TABLE listing_promotions DROP COLUMN amount_minor, DROP COLUMN amount_currency;
+
+-- Price history
+ALTER TABLE listing_price_history ADD COLUMN old_price DECIMAL(12,2), ADD COLUMN new_price DECIMAL(12,2), ADD COLUMN currency VARCHAR(3);
+UPDATE listing_price_history SET old_price = old_price_minor / 100.0, new_price = new_price_minor / 100.0, currency = new_price_currency;
+ALTER TABLE listing_price_history ALTER COLUMN old_price SET NOT NULL, ALTER COLUMN new_price SET NOT NULL, ALTER COLUMN currency SET NOT NULL;
+ALTER TABLE listing_price_history
+    DROP COLUMN old_price_minor, DROP COLUMN old_price_currency,
+    DROP COLUMN new_price_minor, DROP COLUMN new_price_currency;
+
+-- Listings
+DROP INDEX IF EXISTS idx_listings_price;
+DROP INDEX IF EXISTS idx_listings_active_price;
+ALTER TABLE listings DROP CONSTRAINT IF EXISTS listings_price_check;
+
+ALTER TABLE listings ADD COLUMN price DECIMAL(12,2), ADD COLUMN currency VARCHAR(3) DEFAULT 'GHS';
+UPDATE listings SET price = price_minor / 100.0, currency = price_currency;
+ALTER TABLE listings ALTER COLUMN price SET NOT NULL;
+ALTER TABLE listings DROP COLUMN price_minor, DROP COLUMN price_currency;
+
+ALTER TABLE listings ADD CONSTRAINT listings_price_check CHECK (price > 0 OR (status = 'draft' AND price = 0));
+CREATE INDEX idx_listings_price ON listings(price);
+CREATE INDEX idx_listings_active_price ON listings(price, id) WHERE status = 'active';
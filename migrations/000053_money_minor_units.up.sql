@@ -0,0 +1,59 @@
+-- Amounts of money are kept exactly, as a whole number of minor units
+-- (pesewas for cedis), next to their currency: <name>_minor and <name>_currency
+
+-- Listings
+ALTER TABLE listings ADD COLUMN price_minor BIGINT, ADD COLUMN price_currency VARCHAR(3);
+UPDATE listings SET price_minor = ROUND(price * 100), price_currency = COALESCE(currency, 'GHS');
+ALTER TABLE listings ALTER COLUMN price_minor SET NOT NULL, ALTER COLUMN price_minor SET DEFAULT 0;
+ALTER TABLE listings ALTER COLUMN price_currency SET NOT NULL, ALTER COLUMN price_currency SET DEFAULT 'GHS';
+
+DROP INDEX IF EXISTS idx_listings_price;
+DROP INDEX IF EXISTS idx_listings_active_price;
+ALTER TABLE listings DROP CONSTRAINT IF EXISTS listings_price_check;
+ALTER TABLE listings DROP COLUMN price, DROP COLUMN currency;
+
+ALTER TABLE listings ADD CONSTRAINT listings_price_check CHECK (price_minor > 0 OR (status = 'draft' AND price_minor = 0));
+CREATE INDEX idx_listings_price ON listings(price_minor);
+CREATE INDEX idx_listings_active_price ON listings(price_minor, id) WHERE status = 'active';
+
+-- Price history
+ALTER TABLE listing_price_history
+    ADD COLUMN old_price_minor BIGINT, ADD COLUMN old_price_currency VARCHAR(3),
+    ADD COLUMN new_price_minor BIGINT, ADD COLUMN new_price_currency VARCHAR(3);
+UPDATE listing_price_history SET
+    old_price_minor = ROUND(old_price * 100), old_price_currency = currency,
+    new_price_minor = ROUND(new_price * 100), new_price_currency = currency;
+ALTER TABLE listing_price_history
+    ALTER COLUMN old_price_minor SET NOT NULL, ALTER COLUMN old_price_currency SET NOT NULL,
+    ALTER COLUMN new_price_minor SET NOT NULL, ALTER COLUMN new_price_currency SET NOT NULL;
+ALTER TABLE listing_price_history DROP COLUMN old_price, DROP COLUMN new_price, DROP COLUMN currency;
+
+-- Promotions
+ALTER TABLE listing_promotions ADD COLUMN amount_minor BIGINT, ADD COLUMN amount_currency VARCHAR(3);
+UPDATE listing_promotions SET amount_minor = ROUND(amount * 100), amount_currency = currency;
+ALTER TABLE listing_promotions ALTER COLUMN amount_minor SET NOT NULL, ALTER COLUMN amount_currency SET NOT NULL;
+ALTER TABLE listing_promotions DROP COLUMN amount, DROP COLUMN currency;
+
+-- Offers
+ALTER TABLE offers
+    ADD COLUMN amount_minor BIGINT, ADD COLUMN amount_currency VARCHAR(3),
+    ADD COLUMN asking_price_minor BIGINT, ADD COLUMN asking_price_currency VARCHAR(3);
+UPDATE offers SET
+    amount_minor = ROUND(amount * 100), amount_currency = currency,
+    asking_price_minor = ROUND(asking_price * 100), asking_price_currency = currency;
+ALTER TABLE offers
+    ALTER COLUMN amount_minor SET NOT NULL, ALTER COLUMN amount_currency SET NOT NULL,
+    ALTER COLUMN asking_price_minor SET NOT NULL, ALTER COLUMN asking_price_currency SET NOT NULL;
+ALTER TABLE offers DROP COLUMN amount, DROP COLUMN asking_price, DROP COLUMN currency;
+ALTER TABLE offers ADD CONSTRAINT offers_amount_check CHECK (amount_minor > 0);
+
+-- Orders
+ALTER TABLE orders ADD COLUMN amount_minor BIGINT, ADD COLUMN amount_currency VARCHAR(3);
+UPDATE orders SET amount_minor = ROUND(amount * 100), amount_currency = currency;
+ALTER TABLE orders ALTER COLUMN amount_minor SET NOT NULL, ALTER COLUMN amount_currency SET NOT NULL;
+ALTER TABLE orders DROP COLUMN amount, DROP COLUMN currency;
+
+-- Referrals record the amount of the referred user's first purchase, nothing until then
+ALTER TABLE referrals ADD COLUMN order_amount_minor BIGINT NOT NULL DEFAULT 0, ADD COLUMN order_amount_currency VARCHAR(3) NOT NULL DEFAULT 'GHS';
+UPDATE referrals SET order_amount_minor = ROUND(order_amount * 100), order_amount_currency = currency WHERE order_amount IS NOT NULL;
+ALTER TABLE referrals DROP COLUMN order_amount, DROP COLUMN currency;
@@ -0,0 +1,2 @@
+-- Merged tags cannot be split again
+DROP INDEX IF EXISTS idx_listing_tag_relations_tag;
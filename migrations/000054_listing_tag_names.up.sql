@@ -0,0 +1,33 @@
+-- Tag names are stored normalized: lower case, without a leading # and with
+-- single spaces. Tags whose names only differed in case or spacing are
+-- merged into the oldest of them.
+CREATE TEMP TABLE listing_tag_merges AS
+SELECT id,
+       FIRST_VALUE(id) OVER (PARTITION BY normalized ORDER BY created_at, id) AS keep_id,
+       normalized
+FROM (
+    SELECT id, created_at,
+           LOWER(REGEXP_REPLACE(BTRIM(LTRIM(BTRIM(name), '#')), '\s+', ' ', 'g')) AS normalized
+    FROM listing_tags
+) tags;
+
+INSERT INTO listing_tag_relations (listing_id, listing_tag_id)
+SELECT r.listing_id, m.keep_id
+FROM listing_tag_relations r
+JOIN listing_tag_merges m ON m.id = r.listing_tag_id
+WHERE m.id <> m.keep_id
+ON CONFLICT DO NOTHING;
+
+DELETE FROM listing_tags t
+USING listing_tag_merges m
+WHERE t.id = m.id AND m.id <> m.keep_id;
+
+UPDATE listing_tags t
+SET name = m.normalized
+FROM listing_tag_merges m
+WHERE t.id = m.id AND t.name <> m.normalized;
+
+DROP TABLE listing_tag_merges;
+
+-- Browsing by tag and counting tag use look relations up by tag
+CREATE INDEX IF NOT EXISTS idx_listing_tag_relations_tag ON listing_tag_relations(listing_tag_id);
@@ -0,0 +1,14 @@
+-- Presets sellers saved to post similar listings quickly
+CREATE TABLE listing_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    description TEXT NOT NULL DEFAULT '',
+    condition VARCHAR(20) NOT NULL DEFAULT '',
+    attributes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_listing_templates_seller_id ON listing_templates(seller_id, created_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS share_link_daily_clicks;
+DROP TABLE IF EXISTS share_links;
@@ -0,0 +1,17 @@
+-- Short links of listings, one per listing and channel shared on
+CREATE TABLE share_links (
+    code VARCHAR(16) PRIMARY KEY,
+    listing_id UUID NOT NULL REFERENCES listings(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (listing_id, channel)
+);
+
+-- Clicks on share links per day, for the seller's share stats
+CREATE TABLE share_link_daily_clicks (
+    code VARCHAR(16) NOT NULL REFERENCES share_links(code) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (code, day)
+);
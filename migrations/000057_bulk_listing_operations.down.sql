@@ -0,0 +1,9 @@
+DELETE FROM bulk_operations WHERE kind IN ('deactivate_listings', 'delete_listings', 'move_category');
+
+ALTER TABLE bulk_operations DROP COLUMN IF EXISTS category_id;
+ALTER TABLE bulk_operations DROP COLUMN IF EXISTS filter;
+ALTER TABLE bulk_operations DROP COLUMN IF EXISTS listing_ids;
+
+ALTER TABLE bulk_operations DROP CONSTRAINT IF EXISTS bulk_operations_kind_check;
+ALTER TABLE bulk_operations ADD CONSTRAINT bulk_operations_kind_check
+    CHECK (kind IN ('suspend_seller_listings', 'restore_seller_listings', 'reindex_category'));
@@ -0,0 +1,10 @@
+-- Bulk operations on listings picked by ID or by filter
+ALTER TABLE bulk_operations DROP CONSTRAINT IF EXISTS bulk_operations_kind_check;
+ALTER TABLE bulk_operations ADD CONSTRAINT bulk_operations_kind_check
+    CHECK (kind IN ('suspend_seller_listings', 'restore_seller_listings', 'reindex_category',
+                    'deactivate_listings', 'delete_listings', 'move_category'));
+
+ALTER TABLE bulk_operations ADD COLUMN listing_ids JSONB;
+ALTER TABLE bulk_operations ADD COLUMN filter JSONB;
+-- The category move_category moves listings to
+ALTER TABLE bulk_operations ADD COLUMN category_id VARCHAR(64);
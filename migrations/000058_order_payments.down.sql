@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_orders_payment_requested;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_requested_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS payer;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_reference;
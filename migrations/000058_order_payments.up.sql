@@ -0,0 +1,7 @@
+-- The Mobile Money payment a buyer was last asked to approve for an order
+ALTER TABLE orders ADD COLUMN payment_reference VARCHAR(64);
+ALTER TABLE orders ADD COLUMN payer VARCHAR(20);
+ALTER TABLE orders ADD COLUMN payment_requested_at TIMESTAMP;
+
+CREATE INDEX idx_orders_payment_requested ON orders(payment_requested_at)
+    WHERE status = 'pending_payment' AND payment_reference <> '';
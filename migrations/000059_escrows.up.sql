@@ -0,0 +1,22 @@
+-- Escrows hold the payment of each paid order until the buyer confirms
+-- delivery, the hold times out, or the order is cancelled and refunded
+CREATE TABLE escrows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    buyer_id UUID NOT NULL REFERENCES users(id),
+    seller_id UUID NOT NULL REFERENCES users(id),
+    amount_minor BIGINT NOT NULL,
+    amount_currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('held', 'released', 'refunded')),
+    release_due_at TIMESTAMP NOT NULL,
+    release_requested_at TIMESTAMP,
+    delivery_confirmed_at TIMESTAMP,
+    released_at TIMESTAMP,
+    refunded_at TIMESTAMP,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The worker releases held funds past their release time
+CREATE INDEX idx_escrows_release_due ON escrows(release_due_at) WHERE status = 'held';
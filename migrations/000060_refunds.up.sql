@@ -0,0 +1,26 @@
+-- Refunds send part or all of an order's payment back to the buyer's Mobile
+-- Money wallet; each request's idempotency key makes one refund at most
+CREATE TABLE refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(100) NOT NULL,
+    buyer_id UUID NOT NULL REFERENCES users(id),
+    seller_id UUID NOT NULL REFERENCES users(id),
+    requested_by VARCHAR(50) NOT NULL,
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    amount_currency VARCHAR(3) NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed')),
+    reference VARCHAR(64),
+    payee VARCHAR(20) NOT NULL,
+    transaction_id VARCHAR(64),
+    failure_reason TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_refunds_idempotency ON refunds(order_id, idempotency_key);
+
+-- The worker looks up the transfers of pending refunds
+CREATE INDEX idx_refunds_pending ON refunds(created_at) WHERE status = 'pending';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_escrows_unpaid;
+ALTER TABLE escrows DROP COLUMN IF EXISTS payout_id;
+DROP TABLE IF EXISTS payouts;
+DROP TABLE IF EXISTS payout_accounts;
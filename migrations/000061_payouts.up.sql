@@ -0,0 +1,44 @@
+-- Payout accounts are the Mobile Money wallets sellers are paid to, and how
+-- often; next_payout_at is when a seller's released funds are next paid out
+CREATE TABLE payout_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seller_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    phone VARCHAR(20) NOT NULL,
+    schedule VARCHAR(20) NOT NULL CHECK (schedule IN ('instant', 'daily', 'weekly')),
+    verified_at TIMESTAMP,
+    next_payout_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The worker pays verified accounts whose next payout is due
+CREATE INDEX idx_payout_accounts_due ON payout_accounts(next_payout_at) WHERE verified_at IS NOT NULL;
+
+-- Payouts send sellers the funds released to them, one transfer per batch;
+-- items lists the escrows paid out with what was left of each after refunds
+CREATE TABLE payouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seller_id UUID NOT NULL REFERENCES users(id),
+    payee VARCHAR(20) NOT NULL,
+    amount_minor BIGINT NOT NULL CHECK (amount_minor >= 0),
+    amount_currency VARCHAR(3) NOT NULL,
+    items JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed')),
+    reference VARCHAR(64),
+    transaction_id VARCHAR(64),
+    failure_reason TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payouts_seller ON payouts(seller_id, created_at DESC);
+
+-- The worker looks up the transfers of pending payouts
+CREATE INDEX idx_payouts_pending ON payouts(created_at) WHERE status = 'pending';
+
+-- A released escrow is claimed by the payout sending its funds, and freed
+-- again if that payout fails
+ALTER TABLE escrows ADD COLUMN payout_id UUID REFERENCES payouts(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_escrows_unpaid ON escrows(seller_id, released_at) WHERE status = 'released' AND payout_id IS NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_method;
+DROP TABLE IF EXISTS wallet_transfers;
+DROP TABLE IF EXISTS wallet_entries;
+DROP TABLE IF EXISTS wallets;
@@ -0,0 +1,54 @@
+-- Wallets hold users' balances on the marketplace. A balance only changes
+-- with the wallet's row locked, alongside the entry recording the change.
+CREATE TABLE wallets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    balance_minor BIGINT NOT NULL DEFAULT 0 CHECK (balance_minor >= 0),
+    balance_currency VARCHAR(3) NOT NULL DEFAULT 'GHS',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Wallet entries are the append-only log of every change to a balance;
+-- debits are negative. Each kind of entry is posted once per reference, so
+-- a top-up or refund is never credited twice and an order never paid twice.
+CREATE TABLE wallet_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('top_up', 'purchase', 'purchase_reversal', 'refund', 'withdrawal', 'withdrawal_reversal')),
+    amount_minor BIGINT NOT NULL CHECK (amount_minor <> 0),
+    amount_currency VARCHAR(3) NOT NULL,
+    balance_after_minor BIGINT NOT NULL CHECK (balance_after_minor >= 0),
+    balance_after_currency VARCHAR(3) NOT NULL,
+    reference VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_wallet_entries_reference ON wallet_entries(wallet_id, kind, reference);
+CREATE INDEX idx_wallet_entries_wallet ON wallet_entries(wallet_id, created_at DESC);
+
+-- Wallet transfers top wallets up from, and withdraw them to, Mobile Money
+CREATE TABLE wallet_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('top_up', 'withdrawal')),
+    phone VARCHAR(20) NOT NULL,
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    amount_currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed')),
+    reference VARCHAR(64),
+    transaction_id VARCHAR(64),
+    failure_reason TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_wallet_transfers_user ON wallet_transfers(user_id, created_at DESC);
+
+-- The worker looks up the transfers of pending top-ups and withdrawals
+CREATE INDEX idx_wallet_transfers_pending ON wallet_transfers(created_at) WHERE status = 'pending';
+
+-- Orders record whether they were paid by Mobile Money or from the wallet
+ALTER TABLE orders ADD COLUMN payment_method VARCHAR(20);
+UPDATE orders SET payment_method = 'momo' WHERE payer <> '';
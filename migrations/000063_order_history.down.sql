@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_seller_history;
+DROP INDEX IF EXISTS idx_orders_buyer_history;
+ALTER TABLE orders DROP COLUMN IF EXISTS resumed_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancelled_at;
@@ -0,0 +1,10 @@
+-- Orders record when they were cancelled and when an abandoned checkout was
+-- resumed, for their timelines. Orders cancelled before were last updated
+-- when they were cancelled.
+ALTER TABLE orders ADD COLUMN cancelled_at TIMESTAMP;
+ALTER TABLE orders ADD COLUMN resumed_at TIMESTAMP;
+UPDATE orders SET cancelled_at = updated_at WHERE status = 'cancelled';
+
+-- Buyers and sellers page through their orders newest first
+CREATE INDEX idx_orders_buyer_history ON orders(buyer_id, created_at DESC, id DESC);
+CREATE INDEX idx_orders_seller_history ON orders(seller_id, created_at DESC, id DESC);
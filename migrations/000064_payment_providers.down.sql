@@ -0,0 +1,2 @@
+ALTER TABLE refunds DROP COLUMN IF EXISTS provider;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_provider;
@@ -0,0 +1,9 @@
+-- Orders record the payment provider they were paid through, which their
+-- refunds go back through. Payments and refunds before were all MoMo's.
+ALTER TABLE orders ADD COLUMN payment_provider VARCHAR(20);
+UPDATE orders SET payment_provider = 'momo' WHERE payment_method = 'momo';
+
+ALTER TABLE refunds ADD COLUMN provider VARCHAR(20);
+UPDATE refunds SET provider = 'momo'
+FROM orders
+WHERE orders.id = refunds.order_id AND orders.payment_method = 'momo';
@@ -0,0 +1,50 @@
+-- Coupons are promo codes buyers enter at checkout for a percentage or fixed
+-- discount, optionally limited to a category or a seller. redemptions counts
+-- the orders using a coupon and only changes with the coupon's row locked.
+CREATE TABLE coupons (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(32) NOT NULL UNIQUE,
+    description VARCHAR(500),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('percentage', 'fixed')),
+    percent INTEGER NOT NULL DEFAULT 0 CHECK (percent BETWEEN 0 AND 99),
+    amount_off_minor BIGINT NOT NULL DEFAULT 0 CHECK (amount_off_minor >= 0),
+    amount_off_currency VARCHAR(3) NOT NULL DEFAULT 'GHS',
+    category_id UUID REFERENCES categories(id),
+    seller_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    max_redemptions INTEGER NOT NULL DEFAULT 0 CHECK (max_redemptions >= 0),
+    max_redemptions_per_buyer INTEGER NOT NULL DEFAULT 0 CHECK (max_redemptions_per_buyer >= 0),
+    redemptions INTEGER NOT NULL DEFAULT 0 CHECK (redemptions >= 0),
+    starts_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_coupons_created ON coupons(created_at DESC);
+
+-- Coupon redemptions record the orders that used each coupon. Redemptions
+-- of orders abandoned or cancelled before they were paid are released; an
+-- order has at most one redemption not released.
+CREATE TABLE coupon_redemptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    coupon_id UUID NOT NULL REFERENCES coupons(id),
+    order_id UUID NOT NULL,
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    discount_minor BIGINT NOT NULL CHECK (discount_minor > 0),
+    discount_currency VARCHAR(3) NOT NULL,
+    redeemed_at TIMESTAMP NOT NULL,
+    released_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_coupon_redemptions_order ON coupon_redemptions(order_id) WHERE released_at IS NULL;
+CREATE INDEX idx_coupon_redemptions_coupon ON coupon_redemptions(coupon_id, redeemed_at DESC);
+CREATE INDEX idx_coupon_redemptions_buyer ON coupon_redemptions(coupon_id, buyer_id) WHERE released_at IS NULL;
+
+-- Orders record the coupon the buyer entered and its discount, which the
+-- amount is already net of
+ALTER TABLE orders ADD COLUMN coupon_code VARCHAR(32);
+ALTER TABLE orders ADD COLUMN discount_minor BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN discount_currency VARCHAR(3) NOT NULL DEFAULT 'GHS';
+UPDATE orders SET discount_currency = amount_currency;
@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS held_at;
+DROP TABLE IF EXISTS blocked_phones;
+DROP TABLE IF EXISTS fraud_checks;
@@ -0,0 +1,36 @@
+-- Fraud checks record the score of each order when it was placed and the
+-- signals it was computed from. Orders scoring the hold score or more are
+-- held until an administrator releases or declines them, or cancelled once
+-- review_due_at passes. Checks are made before the order is saved.
+CREATE TABLE fraud_checks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL UNIQUE,
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    signals JSONB NOT NULL DEFAULT '{}',
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('passed', 'held', 'released', 'declined')),
+    review_due_at TIMESTAMP,
+    reviewed_by UUID REFERENCES users(id),
+    review_note VARCHAR(500),
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_fraud_checks_buyer_id ON fraud_checks(buyer_id);
+CREATE INDEX idx_fraud_checks_decision ON fraud_checks(decision, created_at);
+CREATE INDEX idx_fraud_checks_created ON fraud_checks(created_at DESC);
+
+-- Blocked phone numbers are numbers administrators found used for fraud,
+-- in E.164 form as users' numbers are stored
+CREATE TABLE blocked_phones (
+    phone_number VARCHAR(20) PRIMARY KEY,
+    reason VARCHAR(500) NOT NULL,
+    blocked_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_blocked_phones_created ON blocked_phones(created_at DESC);
+
+ALTER TABLE orders ADD COLUMN held_at TIMESTAMP;
+ALTER TABLE orders ADD COLUMN reviewed_at TIMESTAMP;
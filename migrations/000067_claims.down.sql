@@ -0,0 +1,2 @@
+ALTER TABLE escrows DROP COLUMN IF EXISTS frozen_at;
+DROP TABLE IF EXISTS claims;
@@ -0,0 +1,32 @@
+-- Claims are buyers disputing an order's payment, as chargebacks the payment
+-- provider reported or reversals an administrator opened a claim for. The
+-- order's funds are frozen in escrow, and left out of payouts, while any of
+-- its claims is open.
+CREATE TABLE claims (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(20),
+    reference VARCHAR(64),
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    amount_currency VARCHAR(3) NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('open', 'won', 'lost')),
+    opened_by VARCHAR(50) NOT NULL,
+    evidence_due_at TIMESTAMP,
+    evidence JSONB NOT NULL DEFAULT '[]',
+    resolved_by VARCHAR(50),
+    resolution VARCHAR(500),
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Each dispute of a provider is claimed once
+CREATE UNIQUE INDEX idx_claims_reference ON claims(provider, reference) WHERE reference <> '';
+CREATE INDEX idx_claims_order_id ON claims(order_id);
+CREATE INDEX idx_claims_seller_id ON claims(seller_id, created_at DESC);
+CREATE INDEX idx_claims_status ON claims(status, created_at DESC);
+
+ALTER TABLE escrows ADD COLUMN frozen_at TIMESTAMP;
@@ -0,0 +1,12 @@
+-- Paid orders move through fulfillment statuses on their way to the buyer,
+-- recording when they reached each. Orders paid before await shipment.
+ALTER TABLE orders ADD COLUMN fulfillment_status VARCHAR(20);
+ALTER TABLE orders ADD COLUMN carrier VARCHAR(50);
+ALTER TABLE orders ADD COLUMN tracking_number VARCHAR(64);
+ALTER TABLE orders ADD COLUMN pickup_arranged_at TIMESTAMP;
+ALTER TABLE orders ADD COLUMN shipped_at TIMESTAMP;
+ALTER TABLE orders ADD COLUMN in_transit_at TIMESTAMP;
+ALTER TABLE orders ADD COLUMN delivered_at TIMESTAMP;
+UPDATE orders SET fulfillment_status = 'awaiting_shipment' WHERE status = 'paid';
+
+CREATE INDEX idx_orders_fulfillment_status ON orders(fulfillment_status);
@@ -0,0 +1,4 @@
+-- Entries already posted for merges are kept, so the ledger still adds up
+ALTER TABLE wallet_entries DROP CONSTRAINT IF EXISTS wallet_entries_kind_check;
+ALTER TABLE wallet_entries ADD CONSTRAINT wallet_entries_kind_check
+    CHECK (kind IN ('top_up', 'purchase', 'purchase_reversal', 'refund', 'withdrawal', 'withdrawal_reversal')) NOT VALID;
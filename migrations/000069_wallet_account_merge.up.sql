@@ -0,0 +1,4 @@
+-- Allow moving a merged duplicate account's balance to the account it was merged into
+ALTER TABLE wallet_entries DROP CONSTRAINT IF EXISTS wallet_entries_kind_check;
+ALTER TABLE wallet_entries ADD CONSTRAINT wallet_entries_kind_check
+    CHECK (kind IN ('top_up', 'purchase', 'purchase_reversal', 'refund', 'withdrawal', 'withdrawal_reversal', 'account_merge'));
@@ -0,0 +1,3 @@
+-- Orders count their updates, so an update made from a stale copy of an
+-- order is refused instead of overwriting a concurrent one
+ALTER TABLE orders ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,220 @@
+package anonymize
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dongome/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// batchSize is how many rows are rewritten per transaction
+const batchSize = 500
+
+// maxAttempts bounds the search for an unused pseudonym in a unique column
+const maxAttempts = 100
+
+// Summary reports what an anonymization run changed
+type Summary struct {
+	// Rows counts the rows rewritten per table
+	Rows map[string]int64 `json:"rows"`
+	// Skipped lists the rules whose table or column does not exist, as table.column
+	Skipped []string `json:"skipped"`
+}
+
+// Anonymizer rewrites personal data in place. It must only be pointed at a
+// copy of production data, such as a staging database restored from a backup.
+type Anonymizer struct {
+	db           *gorm.DB
+	pseudonyms   *Pseudonymizer
+	rules        []Rule
+	passwordHash string
+}
+
+// NewAnonymizer creates an anonymizer applying rules to database. Passwords
+// are replaced by passwordHash; an empty hash disables password logins.
+func NewAnonymizer(database *gorm.DB, pseudonyms *Pseudonymizer, rules []Rule, passwordHash string) *Anonymizer {
+	return &Anonymizer{
+		db:           database,
+		pseudonyms:   pseudonyms,
+		rules:        rules,
+		passwordHash: passwordHash,
+	}
+}
+
+// Run applies every rule whose column exists and returns what changed
+func (a *Anonymizer) Run(ctx context.Context) (*Summary, error) {
+	summary := &Summary{Rows: make(map[string]int64), Skipped: []string{}}
+	tx := a.db.WithContext(ctx)
+
+	// Group the row-by-row rules per table, so each row is read and written once
+	var tables []string
+	rowRules := make(map[string][]Rule)
+	for _, rule := range a.rules {
+		if !tx.Migrator().HasColumn(rule.Table, rule.Column) {
+			summary.Skipped = append(summary.Skipped, rule.Table+"."+rule.Column)
+			continue
+		}
+		if rule.Kind.bulk() {
+			if err := a.applyBulk(tx, rule); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", rule.Table, rule.Column, err)
+			}
+			continue
+		}
+		if _, ok := rowRules[rule.Table]; !ok {
+			tables = append(tables, rule.Table)
+		}
+		rowRules[rule.Table] = append(rowRules[rule.Table], rule)
+	}
+
+	for _, table := range tables {
+		rows, err := a.rewriteTable(tx, table, rowRules[table])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", table, err)
+		}
+		summary.Rows[table] = rows
+		logger.Info("Anonymized table", zap.String("table", table), zap.Int64("rows", rows))
+	}
+	return summary, nil
+}
+
+// applyBulk applies a whole-column rule with a single statement
+func (a *Anonymizer) applyBulk(tx *gorm.DB, rule Rule) error {
+	column := tx.Statement.Quote(rule.Column)
+	switch rule.Kind {
+	case KindClear:
+		return tx.Table(rule.Table).Where("1 = 1").Update(rule.Column, nil).Error
+	case KindPassword:
+		return tx.Table(rule.Table).Where("1 = 1").Update(rule.Column, a.passwordHash).Error
+	case KindCoordinate:
+		return tx.Table(rule.Table).Where(column+" IS NOT NULL").
+			Update(rule.Column, gorm.Expr("ROUND(CAST("+column+" AS numeric), 2)")).Error
+	}
+	return fmt.Errorf("unsupported bulk kind %s", rule.Kind)
+}
+
+// rewriteTable replaces the values of the rule columns row by row, walking the table by id
+func (a *Anonymizer) rewriteTable(tx *gorm.DB, table string, rules []Rule) (int64, error) {
+	columns := make([]string, len(rules))
+	taken := make(map[string]map[string]bool)
+	for i, rule := range rules {
+		columns[i] = rule.Column
+		if rule.Unique {
+			values, err := columnValues(tx, table, rule.Column)
+			if err != nil {
+				return 0, err
+			}
+			taken[rule.Column] = values
+		}
+	}
+
+	var rewritten int64
+	afterID := ""
+	for {
+		ids, originals, err := readBatch(tx, table, columns, afterID)
+		if err != nil {
+			return rewritten, err
+		}
+		if len(ids) == 0 {
+			return rewritten, nil
+		}
+
+		err = tx.Transaction(func(batch *gorm.DB) error {
+			for i, id := range ids {
+				updates := make(map[string]interface{})
+				for j, rule := range rules {
+					original := originals[i][j]
+					if !original.Valid || original.String == "" {
+						continue
+					}
+					value, err := a.pseudonym(rule, original.String, taken[rule.Column])
+					if err != nil {
+						return err
+					}
+					updates[rule.Column] = value
+				}
+				if len(updates) == 0 {
+					continue
+				}
+				if err := batch.Table(table).Where("id = ?", id).Updates(updates).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return rewritten, err
+		}
+
+		rewritten += int64(len(ids))
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// pseudonym returns the replacement for original. For unique columns taken
+// holds the values currently in the column; the original is released and the
+// first pseudonym not in use is claimed.
+func (a *Anonymizer) pseudonym(rule Rule, original string, taken map[string]bool) (string, error) {
+	if taken == nil {
+		return a.pseudonyms.Pseudonym(rule.Kind, original, 0), nil
+	}
+
+	delete(taken, original)
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		value := a.pseudonyms.Pseudonym(rule.Kind, original, attempt)
+		if !taken[value] {
+			taken[value] = true
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("no unused pseudonym for %s.%s after %d attempts", rule.Table, rule.Column, maxAttempts)
+}
+
+// readBatch reads the next rows after afterID with the given columns
+func readBatch(tx *gorm.DB, table string, columns []string, afterID string) ([]string, [][]sql.NullString, error) {
+	query := tx.Table(table).Select(append([]string{"id"}, columns...)).Order("id").Limit(batchSize)
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var (
+		ids       []string
+		originals [][]sql.NullString
+	)
+	for rows.Next() {
+		var id string
+		values := make([]sql.NullString, len(columns))
+		dest := []interface{}{&id}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		originals = append(originals, values)
+	}
+	return ids, originals, rows.Err()
+}
+
+// columnValues returns the distinct non-null values of a column
+func columnValues(tx *gorm.DB, table, column string) (map[string]bool, error) {
+	var values []string
+	err := tx.Table(table).Where(tx.Statement.Quote(column)+" IS NOT NULL").Distinct(column).Pluck(column, &values).Error
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool, len(values))
+	for _, value := range values {
+		taken[value] = true
+	}
+	return taken, nil
+}
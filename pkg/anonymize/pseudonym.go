@@ -0,0 +1,142 @@
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// EmailDomain is the domain of pseudonymized email addresses. It is reserved,
+// so staging can never email a real person.
+const EmailDomain = "staging.dongome.test"
+
+var (
+	firstNames = []string{
+		"Kwame", "Kofi", "Kwabena", "Kwaku", "Yaw", "Kojo", "Kwesi", "Fiifi", "Nana", "Selorm",
+		"Ama", "Akosua", "Abena", "Akua", "Yaa", "Afua", "Adwoa", "Esi", "Efua", "Dzifa",
+	}
+	lastNames = []string{
+		"Mensah", "Owusu", "Boateng", "Asante", "Osei", "Agyeman", "Appiah", "Darko", "Amoah", "Ofori",
+		"Addo", "Quaye", "Tetteh", "Nkrumah", "Acheampong", "Badu", "Sarpong", "Adjei", "Gyamfi", "Kumah",
+	}
+	streets = []string{
+		"Independence Avenue", "Liberation Road", "Oxford Street", "Ring Road", "Spintex Road",
+		"Kojo Thompson Road", "Harper Road", "Lagos Avenue", "Castle Road", "Cantonments Road",
+	}
+)
+
+// loremText replaces free text. It is cut to the length of the original.
+const loremText = "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. "
+
+// Pseudonymizer derives realistic replacement values from originals with a
+// keyed hash. The same original and key always give the same pseudonym, so
+// values repeated across tables stay consistent, but originals cannot be
+// recovered without the key.
+type Pseudonymizer struct {
+	key []byte
+}
+
+// NewPseudonymizer creates a pseudonymizer keyed with key
+func NewPseudonymizer(key string) *Pseudonymizer {
+	return &Pseudonymizer{key: []byte(key)}
+}
+
+// Pseudonym returns the replacement for value. attempt > 0 derives an
+// alternative, used when the first pseudonym collides with an existing value.
+func (p *Pseudonymizer) Pseudonym(kind Kind, value string, attempt int) string {
+	if value == "" {
+		return ""
+	}
+
+	// Case and spacing do not change who a value identifies
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	if attempt > 0 {
+		normalized = fmt.Sprintf("%s#%d", normalized, attempt)
+	}
+	sum := p.sum(string(kind) + ":" + normalized)
+
+	switch kind {
+	case KindEmail:
+		return "user." + hex.EncodeToString(sum[:6]) + "@" + EmailDomain
+	case KindFirstName:
+		return pick(firstNames, sum)
+	case KindLastName:
+		return pick(lastNames, sum)
+	case KindFullName:
+		return pick(firstNames, sum) + " " + pick(lastNames, sum[8:])
+	case KindCompany:
+		return pick(lastNames, sum) + " Enterprises"
+	case KindAddress:
+		return fmt.Sprintf("%d %s", 1+binary.BigEndian.Uint16(sum[:2])%200, pick(streets, sum[2:]))
+	case KindPhone:
+		return pseudonymPhone(value, sum)
+	case KindReference:
+		return pseudonymReference(value, sum)
+	case KindText:
+		return loremOfLength(len([]rune(value)))
+	default:
+		return ""
+	}
+}
+
+func (p *Pseudonymizer) sum(value string) []byte {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func pick(values []string, sum []byte) string {
+	return values[binary.BigEndian.Uint32(sum[:4])%uint32(len(values))]
+}
+
+// pseudonymPhone keeps the country code and, for Ghana, the network prefix,
+// and replaces the subscriber digits, so the result is still a valid number
+func pseudonymPhone(value string, sum []byte) string {
+	keep := 4 // "+" and the first three digits, covering most country codes
+	if strings.HasPrefix(value, "+233") {
+		keep = 6 // "+233" and the two-digit network prefix
+	}
+	return replaceDigits(value, keep, sum)
+}
+
+// pseudonymReference keeps the format of an ID such as a tax number: letters
+// stay letters of the same case, digits stay digits and separators are kept
+func pseudonymReference(value string, sum []byte) string {
+	var b strings.Builder
+	for i, r := range value {
+		n := sum[i%len(sum)] ^ byte(i/len(sum))
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteByte('0' + n%10)
+		case unicode.IsUpper(r):
+			b.WriteByte('A' + n%26)
+		case unicode.IsLower(r):
+			b.WriteByte('a' + n%26)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// replaceDigits replaces every digit after the first keep characters
+func replaceDigits(value string, keep int, sum []byte) string {
+	var b strings.Builder
+	for i, r := range value {
+		if i < keep || !unicode.IsDigit(r) {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteByte('0' + sum[i%len(sum)]%10)
+	}
+	return b.String()
+}
+
+func loremOfLength(length int) string {
+	text := strings.Repeat(loremText, length/len(loremText)+1)
+	return strings.TrimSpace(text[:length])
+}
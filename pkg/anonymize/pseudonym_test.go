@@ -0,0 +1,47 @@
+package anonymize_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"dongome/pkg/anonymize"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymizer_Deterministic(t *testing.T) {
+	p := anonymize.NewPseudonymizer("staging-key")
+
+	email := p.Pseudonym(anonymize.KindEmail, "Ama.Mensah@Example.com", 0)
+	assert.Equal(t, email, p.Pseudonym(anonymize.KindEmail, " ama.mensah@example.com", 0))
+	assert.True(t, strings.HasSuffix(email, "@"+anonymize.EmailDomain))
+	assert.NotContains(t, email, "mensah")
+
+	// Another attempt or another key gives another pseudonym
+	assert.NotEqual(t, email, p.Pseudonym(anonymize.KindEmail, "ama.mensah@example.com", 1))
+	assert.NotEqual(t, email, anonymize.NewPseudonymizer("other-key").Pseudonym(anonymize.KindEmail, "ama.mensah@example.com", 0))
+
+	assert.Empty(t, p.Pseudonym(anonymize.KindFirstName, "", 0))
+}
+
+func TestPseudonymizer_PreservesFormat(t *testing.T) {
+	p := anonymize.NewPseudonymizer("staging-key")
+
+	phone := p.Pseudonym(anonymize.KindPhone, "+233241234567", 0)
+	assert.Regexp(t, regexp.MustCompile(`^\+23324\d{7}$`), phone)
+	assert.NotEqual(t, "+233241234567", phone)
+
+	reference := p.Pseudonym(anonymize.KindReference, "GHA-712345678-9", 0)
+	assert.Regexp(t, regexp.MustCompile(`^[A-Z]{3}-\d{9}-\d$`), reference)
+
+	digital := p.Pseudonym(anonymize.KindReference, "GA-123-4567", 0)
+	assert.Regexp(t, regexp.MustCompile(`^[A-Z]{2}-\d{3}-\d{4}$`), digital)
+
+	name := p.Pseudonym(anonymize.KindFullName, "Ama Mensah", 0)
+	assert.Len(t, strings.Fields(name), 2)
+
+	text := p.Pseudonym(anonymize.KindText, "Call me on 0241234567 after 5pm", 0)
+	assert.LessOrEqual(t, len(text), len("Call me on 0241234567 after 5pm"))
+	assert.True(t, strings.HasPrefix(text, "Lorem ipsum"))
+}
@@ -0,0 +1,78 @@
+package anonymize
+
+// Kind describes what a column holds and how it is anonymized
+type Kind string
+
+// Kinds replaced row by row with a deterministic pseudonym
+const (
+	KindEmail     Kind = "email"
+	KindFirstName Kind = "first_name"
+	KindLastName  Kind = "last_name"
+	KindFullName  Kind = "full_name"
+	KindCompany   Kind = "company"
+	KindAddress   Kind = "address"
+	KindPhone     Kind = "phone"
+	KindReference Kind = "reference"
+	KindText      Kind = "text"
+)
+
+// Kinds applied to the whole column at once
+const (
+	// KindClear sets the column to NULL
+	KindClear Kind = "clear"
+	// KindPassword sets every password to the staging password
+	KindPassword Kind = "password"
+	// KindCoordinate rounds coordinates to about a kilometre
+	KindCoordinate Kind = "coordinate"
+)
+
+// bulk reports whether the kind is applied with a single statement
+func (k Kind) bulk() bool {
+	return k == KindClear || k == KindPassword || k == KindCoordinate
+}
+
+// Rule anonymizes one column. Unique columns get a different pseudonym when
+// the first one is already taken.
+type Rule struct {
+	Table  string
+	Column string
+	Kind   Kind
+	Unique bool
+}
+
+// DefaultRules cover the personal data stored by the marketplace. Rules for
+// tables or columns that do not exist are skipped, so rules can be added
+// before the context that owns the table is deployed. Primary and foreign
+// keys are never changed, which keeps referential integrity.
+var DefaultRules = []Rule{
+	{Table: "users", Column: "email", Kind: KindEmail, Unique: true},
+	{Table: "users", Column: "first_name", Kind: KindFirstName},
+	{Table: "users", Column: "last_name", Kind: KindLastName},
+	{Table: "users", Column: "phone_number", Kind: KindPhone, Unique: true},
+	{Table: "users", Column: "avatar", Kind: KindClear},
+	{Table: "users", Column: "verification_token", Kind: KindClear},
+	{Table: "users", Column: "password_hash", Kind: KindPassword},
+
+	{Table: "seller_profiles", Column: "business_name", Kind: KindCompany},
+	{Table: "seller_profiles", Column: "business_address", Kind: KindAddress},
+	{Table: "seller_profiles", Column: "business_phone", Kind: KindPhone},
+	{Table: "seller_profiles", Column: "business_email", Kind: KindEmail},
+	{Table: "seller_profiles", Column: "store_latitude", Kind: KindCoordinate},
+	{Table: "seller_profiles", Column: "store_longitude", Kind: KindCoordinate},
+	{Table: "seller_profiles", Column: "tax_number", Kind: KindReference},
+	{Table: "seller_profiles", Column: "verification_notes", Kind: KindText},
+
+	{Table: "addresses", Column: "recipient_name", Kind: KindFullName},
+	{Table: "addresses", Column: "phone_number", Kind: KindPhone},
+	{Table: "addresses", Column: "street", Kind: KindAddress},
+	{Table: "addresses", Column: "landmark", Kind: KindText},
+	{Table: "addresses", Column: "digital_address", Kind: KindReference},
+	{Table: "addresses", Column: "latitude", Kind: KindCoordinate},
+	{Table: "addresses", Column: "longitude", Kind: KindCoordinate},
+
+	{Table: "user_blocks", Column: "reason", Kind: KindText},
+	{Table: "ownership_transfers", Column: "note", Kind: KindText},
+	{Table: "data_exports", Column: "file_path", Kind: KindClear},
+
+	{Table: "messages", Column: "body", Kind: KindText},
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"dongome/pkg/i18n"
+	"dongome/pkg/ids"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys for authenticated request data
+const (
+	ContextUserID = "auth.user_id"
+	ContextRole   = "auth.role"
+	ContextClaims = "auth.claims"
+)
+
+// RoleAdmin is the role claim carried by administrator tokens
+const RoleAdmin = "admin"
+
+// RequireAuth rejects requests without a valid bearer token
+func RequireAuth(tokens *TokenManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		tokenString, found := strings.CutPrefix(header, "Bearer ")
+		if !found || tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": i18n.Localize(c, "missing bearer token"), "code": "UNAUTHORIZED"})
+			return
+		}
+
+		claims, err := tokens.Parse(tokenString)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": i18n.Localize(c, "invalid or expired token"), "code": "UNAUTHORIZED"})
+			return
+		}
+
+		c.Set(ContextUserID, claims.UserID())
+		c.Set(ContextRole, claims.Role)
+		c.Set(ContextClaims, claims)
+		c.Next()
+	}
+}
+
+// IdentifyUser identifies the user of requests with a valid bearer token on
+// routes anyone may call, so their responses can follow the user's
+// preferences. Other requests go through anonymously. Sessions are not
+// checked, so it must not guard anything.
+func IdentifyUser(tokens *TokenManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if found && tokenString != "" {
+			if claims, err := tokens.Parse(tokenString); err == nil {
+				c.Set(ContextUserID, claims.UserID())
+				c.Set(ContextRole, claims.Role)
+				c.Set(ContextClaims, claims)
+			}
+		}
+		c.Next()
+	}
+}
+
+// SessionChecker reports whether an access token issued to a user at
+// issuedAt may still be used
+type SessionChecker interface {
+	SessionActive(ctx context.Context, userID ids.UserID, issuedAt time.Time) (bool, error)
+}
+
+// RequireActiveSession rejects tokens whose sessions were revoked, such as
+// those of suspended users. It must be used after RequireAuth.
+func RequireActiveSession(sessions SessionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var issuedAt time.Time
+		if claims, ok := c.Value(ContextClaims).(*Claims); ok && claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+
+		active, err := sessions.SessionActive(c.Request.Context(), UserID(c), issuedAt)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": i18n.Localize(c, "session has expired"), "code": "UNAUTHORIZED"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ActivityRecorder records that a user was just active
+type ActivityRecorder interface {
+	RecordActivity(ctx context.Context, userID ids.UserID) error
+}
+
+// TrackActivity records the activity of authenticated users for their online
+// status. Failures are ignored so that tracking never fails a request. It
+// must be used after RequireAuth.
+func TrackActivity(recorder ActivityRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = recorder.RecordActivity(c.Request.Context(), UserID(c))
+		c.Next()
+	}
+}
+
+// LocaleSource returns the locale a user chose for API responses, or an
+// empty string if they have not chosen one
+type LocaleSource interface {
+	StoredLocale(ctx context.Context, userID ids.UserID) (string, error)
+}
+
+// ApplyUserLocale answers authenticated requests in the locale the user
+// chose, in place of the one negotiated from Accept-Language. Lookup
+// failures keep the negotiated locale. It must be used after RequireAuth.
+func ApplyUserLocale(locales LocaleSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stored, err := locales.StoredLocale(c.Request.Context(), UserID(c))
+		if err == nil {
+			if locale, ok := i18n.ParseLocale(stored); ok {
+				i18n.SetLocale(c, locale)
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireRole rejects authenticated requests whose role is not allowed.
+// It must be used after RequireAuth.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := Role(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": i18n.Localize(c, "insufficient permissions"), "code": "FORBIDDEN"})
+	}
+}
+
+// UserID returns the authenticated user ID from the request context
+func UserID(c *gin.Context) ids.UserID {
+	userID, _ := c.Value(ContextUserID).(ids.UserID)
+	return userID
+}
+
+// Role returns the authenticated user role from the request context
+func Role(c *gin.Context) string {
+	return c.GetString(ContextRole)
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"time"
+
+	"dongome/pkg/clock"
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Claims represents the JWT claims issued to authenticated users
+type Claims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the authenticated user ID
+func (c *Claims) UserID() ids.UserID {
+	return ids.UserID(c.Subject)
+}
+
+// TokenManager issues and validates access tokens
+type TokenManager struct {
+	secret     []byte
+	expiration time.Duration
+	clock      clock.Clock
+}
+
+// NewTokenManager creates a new token manager. clk may be nil to use the
+// system clock.
+func NewTokenManager(secret string, expiration time.Duration, clk clock.Clock) *TokenManager {
+	return &TokenManager{
+		secret:     []byte(secret),
+		expiration: expiration,
+		clock:      clock.OrSystem(clk),
+	}
+}
+
+// Generate issues a signed access token for a user
+func (m *TokenManager) Generate(userID ids.UserID, role string) (string, time.Time, error) {
+	now := m.clock.Now()
+	expiresAt := now.Add(m.expiration)
+
+	claims := Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   userID.String(),
+			Issuer:    "dongome",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// Parse validates an access token and returns its claims
+func (m *TokenManager) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer("dongome"), jwt.WithTimeFunc(m.clock.Now))
+	if err != nil || !token.Valid {
+		return nil, errors.UnauthorizedError("invalid or expired token")
+	}
+	return claims, nil
+}
@@ -0,0 +1,66 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/pkg/auth"
+	"dongome/pkg/clock"
+	"dongome/pkg/ids"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenManager_GenerateAndParse(t *testing.T) {
+	tokens := auth.NewTokenManager("test-secret", time.Hour, nil)
+
+	token, expiresAt, err := tokens.Generate("user-1", "admin")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+	claims, err := tokens.Parse(token)
+	require.NoError(t, err)
+	assert.Equal(t, ids.UserID("user-1"), claims.UserID())
+	assert.Equal(t, "admin", claims.Role)
+}
+
+func TestTokenManager_RejectsInvalidTokens(t *testing.T) {
+	tokens := auth.NewTokenManager("test-secret", time.Hour, nil)
+
+	// Signed with another secret
+	other := auth.NewTokenManager("other-secret", time.Hour, nil)
+	token, _, err := other.Generate("user-1", "admin")
+	require.NoError(t, err)
+	_, err = tokens.Parse(token)
+	assert.Error(t, err)
+
+	// Expired
+	expired := auth.NewTokenManager("test-secret", -time.Minute, nil)
+	token, _, err = expired.Generate("user-1", "buyer")
+	require.NoError(t, err)
+	_, err = tokens.Parse(token)
+	assert.Error(t, err)
+
+	// Garbage
+	_, err = tokens.Parse("not-a-token")
+	assert.Error(t, err)
+}
+
+func TestTokenManager_ExpiresAfterExpiration(t *testing.T) {
+	clk := clock.NewFrozen(time.Now())
+	tokens := auth.NewTokenManager("test-secret", time.Hour, clk)
+
+	token, expiresAt, err := tokens.Generate("user-1", "buyer")
+	require.NoError(t, err)
+	assert.Equal(t, clk.Now().Add(time.Hour), expiresAt)
+
+	clk.Advance(59 * time.Minute)
+	_, err = tokens.Parse(token)
+	assert.NoError(t, err)
+
+	clk.Advance(2 * time.Minute)
+	_, err = tokens.Parse(token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package backup_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dongome/pkg/backup"
+	"dongome/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBackup creates a complete backup directory
+func writeBackup(t *testing.T, root string, createdAt time.Time, verified bool) *backup.Manifest {
+	t.Helper()
+	manifest := &backup.Manifest{ID: backup.NewID(createdAt), CreatedAt: createdAt}
+	dir := filepath.Join(root, manifest.ID)
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, backup.DumpFile), []byte("dump "+manifest.ID), 0o600))
+
+	// The empty manifest does not match the dump until the checksum is filled in
+	require.Error(t, backup.CheckDump(dir, manifest))
+	manifest.DumpSHA256, manifest.DumpSize = checksumOf(t, dir)
+	if verified {
+		manifest.VerifiedAt = &createdAt
+	}
+	require.NoError(t, backup.WriteManifest(dir, manifest))
+	require.NoError(t, backup.CheckDump(dir, manifest))
+	return manifest
+}
+
+func checksumOf(t *testing.T, dir string) (string, int64) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, backup.DumpFile))
+	require.NoError(t, err)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), int64(len(data))
+}
+
+func TestService_ListAndPrune(t *testing.T) {
+	root := t.TempDir()
+	start := time.Date(2026, 10, 1, 2, 0, 0, 0, time.UTC)
+	writeBackup(t, root, start, true)
+	writeBackup(t, root, start.AddDate(0, 0, 1), false)
+	writeBackup(t, root, start.AddDate(0, 0, 2), false)
+	writeBackup(t, root, start.AddDate(0, 0, 3), false)
+
+	// An interrupted backup has no manifest and is not listed
+	require.NoError(t, os.MkdirAll(filepath.Join(root, backup.NewID(start.AddDate(0, 0, 4))), 0o700))
+
+	service := backup.NewService(nil, &config.DatabaseConfig{Name: "dongome_db"}, &config.BackupConfig{Dir: root}, nil, "test")
+
+	manifests, err := service.List()
+	require.NoError(t, err)
+	require.Len(t, manifests, 4)
+	assert.Equal(t, "20261004T020000Z", manifests[0].ID)
+
+	// The newest two are kept, plus the only verified backup
+	deleted, err := service.Prune(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20261002T020000Z"}, deleted)
+
+	manifests, err = service.List()
+	require.NoError(t, err)
+	require.Len(t, manifests, 3)
+	assert.Equal(t, "20261001T020000Z", manifests[2].ID)
+	assert.NotNil(t, manifests[2].VerifiedAt)
+}
+
+func TestService_RestoreRejectsUnsafeIDs(t *testing.T) {
+	service := backup.NewService(nil, &config.DatabaseConfig{}, &config.BackupConfig{Dir: t.TempDir()}, nil, "test")
+
+	_, err := service.Restore(context.Background(), "../etc", false)
+	assert.Error(t, err)
+	_, err = service.Verify(context.Background(), "20261001T020000Z")
+	assert.Error(t, err, "verify needs a scratch database")
+}
+
+func TestCompareCounts(t *testing.T) {
+	want := map[string]int64{"users": 3, "listings": 5}
+
+	assert.NoError(t, backup.CompareCounts(want, map[string]int64{"users": 3, "listings": 5, "sla_windows": 1}))
+
+	err := backup.CompareCounts(want, map[string]int64{"users": 3})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "listings: want 5 rows, got 0")
+}
+
+func TestMissingImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone.jpg" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	manifest := &backup.Manifest{Images: []backup.ImageRef{
+		{Table: "listing_images", Column: "url", URL: server.URL + "/kept.jpg"},
+		{Table: "listing_images", Column: "url", URL: server.URL + "/gone.jpg"},
+		{Table: "users", Column: "avatar", URL: server.URL + "/gone.jpg"},
+	}}
+
+	missing := backup.MissingImages(context.Background(), server.Client(), manifest)
+	assert.Equal(t, []string{server.URL + "/gone.jpg"}, missing)
+}
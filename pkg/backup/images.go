@@ -0,0 +1,60 @@
+package backup
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// imageCheckConcurrency limits how many image requests are in flight at once
+const imageCheckConcurrency = 8
+
+// MissingImages requests every image in the manifest and returns the URLs that
+// object storage no longer serves. A restore is only complete if none are missing.
+func MissingImages(ctx context.Context, client *http.Client, manifest *Manifest) []string {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		missing []string
+	)
+
+	seen := make(map[string]bool)
+	slots := make(chan struct{}, imageCheckConcurrency)
+	for _, image := range manifest.Images {
+		if seen[image.URL] {
+			continue
+		}
+		seen[image.URL] = true
+
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			if !imageExists(ctx, client, url) {
+				mu.Lock()
+				missing = append(missing, url)
+				mu.Unlock()
+			}
+		}(image.URL)
+	}
+	wg.Wait()
+
+	sort.Strings(missing)
+	return missing
+}
+
+func imageExists(ctx context.Context, client *http.Client, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < http.StatusBadRequest
+}
@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File names inside a backup directory
+const (
+	ManifestFile = "manifest.json"
+	DumpFile     = "database.dump"
+)
+
+// Manifest describes a backup: the database dump, the row counts taken from
+// the same snapshot, and the object storage images the data refers to. Images
+// are not copied; the manifest lists what must be kept in object storage for
+// the backup to be complete.
+type Manifest struct {
+	ID          string           `json:"id"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Version     string           `json:"version"`
+	Database    string           `json:"database"`
+	DumpSHA256  string           `json:"dump_sha256"`
+	DumpSize    int64            `json:"dump_size"`
+	TableCounts map[string]int64 `json:"table_counts"`
+	Images      []ImageRef       `json:"images"`
+	VerifiedAt  *time.Time       `json:"verified_at,omitempty"`
+}
+
+// ImageRef is an image URL referenced by a database column
+type ImageRef struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	URL    string `json:"url"`
+}
+
+// ImageColumn names a column holding image URLs
+type ImageColumn struct {
+	Table  string
+	Column string
+}
+
+// DefaultImageColumns are the columns referring to images in object storage
+var DefaultImageColumns = []ImageColumn{
+	{Table: "users", Column: "avatar"},
+	{Table: "categories", Column: "image_url"},
+	{Table: "listing_images", Column: "url"},
+}
+
+// NewID returns the ID of a backup taken at t. IDs sort chronologically.
+func NewID(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// ReadManifest reads the manifest of the backup in dir
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// WriteManifest writes the manifest into dir, replacing any previous one atomically
+func WriteManifest(dir string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, ManifestFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, ManifestFile))
+}
+
+// fileChecksum returns the hex SHA-256 and size of a file
+func fileChecksum(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// CheckDump verifies that the dump in dir matches the checksum in its manifest
+func CheckDump(dir string, manifest *Manifest) error {
+	checksum, size, err := fileChecksum(filepath.Join(dir, DumpFile))
+	if err != nil {
+		return err
+	}
+	if checksum != manifest.DumpSHA256 || size != manifest.DumpSize {
+		return fmt.Errorf("backup %s: dump does not match its manifest checksum", manifest.ID)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"dongome/pkg/config"
+)
+
+// Runner runs the PostgreSQL client tools
+type Runner interface {
+	Run(ctx context.Context, name string, args []string, env []string) error
+}
+
+// ExecRunner runs commands found on the PATH
+type ExecRunner struct{}
+
+// Run runs the command and includes its error output in the returned error
+func (ExecRunner) Run(ctx context.Context, name string, args []string, env []string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// connectionArgs returns the pg_dump/pg_restore flags selecting the database
+func connectionArgs(cfg *config.DatabaseConfig) []string {
+	return []string{
+		"--host=" + cfg.Host,
+		"--port=" + cfg.Port,
+		"--username=" + cfg.User,
+		"--dbname=" + cfg.Name,
+	}
+}
+
+// connectionEnv passes the credentials through the environment so they do
+// not show up in the process list
+func connectionEnv(cfg *config.DatabaseConfig) []string {
+	return []string{
+		"PGPASSWORD=" + cfg.Password,
+		"PGSSLMODE=" + cfg.SSLMode,
+	}
+}
@@ -0,0 +1,344 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"dongome/pkg/config"
+	"dongome/pkg/db"
+	"dongome/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Service takes, restores and verifies logical backups of the marketplace database
+type Service struct {
+	db           *gorm.DB
+	dbCfg        config.DatabaseConfig
+	cfg          config.BackupConfig
+	runner       Runner
+	version      string
+	imageColumns []ImageColumn
+}
+
+// NewService creates a backup service for the database described by dbCfg
+func NewService(database *gorm.DB, dbCfg *config.DatabaseConfig, cfg *config.BackupConfig, runner Runner, version string) *Service {
+	return &Service{
+		db:           database,
+		dbCfg:        *dbCfg,
+		cfg:          *cfg,
+		runner:       runner,
+		version:      version,
+		imageColumns: DefaultImageColumns,
+	}
+}
+
+// Backup takes a consistent backup. Row counts and image references are read
+// in a repeatable-read transaction whose snapshot is shared with pg_dump, so
+// the manifest describes exactly the data in the dump.
+func (s *Service) Backup(ctx context.Context) (*Manifest, error) {
+	now := time.Now().UTC()
+	manifest := &Manifest{
+		ID:        NewID(now),
+		CreatedAt: now,
+		Version:   s.version,
+		Database:  s.dbCfg.Name,
+	}
+	dir := filepath.Join(s.cfg.Dir, manifest.ID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	err := s.dump(ctx, dir, manifest)
+	if err == nil {
+		manifest.DumpSHA256, manifest.DumpSize, err = fileChecksum(filepath.Join(dir, DumpFile))
+	}
+	if err == nil {
+		err = WriteManifest(dir, manifest)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("backup %s: %w", manifest.ID, err)
+	}
+
+	logger.Info("Database backup complete",
+		zap.String("backup_id", manifest.ID),
+		zap.Int64("size", manifest.DumpSize),
+		zap.Int("tables", len(manifest.TableCounts)),
+		zap.Int("images", len(manifest.Images)))
+	return manifest, nil
+}
+
+func (s *Service) dump(ctx context.Context, dir string, manifest *Manifest) error {
+	tx := s.db.WithContext(ctx).Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer tx.Rollback()
+
+	var snapshot string
+	if err := tx.Raw("SELECT pg_export_snapshot()").Scan(&snapshot).Error; err != nil {
+		return err
+	}
+
+	counts, err := tableCounts(tx)
+	if err != nil {
+		return err
+	}
+	manifest.TableCounts = counts
+
+	manifest.Images, err = s.imageRefs(tx, counts)
+	if err != nil {
+		return err
+	}
+
+	args := append([]string{
+		"--format=custom",
+		"--no-owner",
+		"--snapshot=" + snapshot,
+		"--file=" + filepath.Join(dir, DumpFile),
+	}, connectionArgs(&s.dbCfg)...)
+	return s.runner.Run(ctx, "pg_dump", args, connectionEnv(&s.dbCfg))
+}
+
+// Restore restores a backup into the configured database, preserving all IDs.
+// The database must be empty unless force is set, in which case its public
+// schema is dropped first.
+func (s *Service) Restore(ctx context.Context, id string, force bool) (*Manifest, error) {
+	dir, manifest, err := s.open(id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := tableNames(s.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		if !force {
+			return nil, fmt.Errorf("database %s is not empty (%d tables); restore into a fresh database or use force", s.dbCfg.Name, len(existing))
+		}
+		if err := resetSchema(ctx, s.db); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.restoreInto(ctx, s.db, &s.dbCfg, dir, manifest); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Database restore complete", zap.String("backup_id", id), zap.String("database", s.dbCfg.Name))
+	return manifest, nil
+}
+
+// Wipe drops all data from the configured database
+func (s *Service) Wipe(ctx context.Context) error {
+	return resetSchema(ctx, s.db)
+}
+
+// Verify restores a backup into the scratch database and checks that every
+// table holds the rows recorded in the manifest. A verified backup is marked
+// in its manifest.
+func (s *Service) Verify(ctx context.Context, id string) (*Manifest, error) {
+	if s.cfg.VerifyDatabase == "" {
+		return nil, fmt.Errorf("backup.verify_database is not configured")
+	}
+	if s.cfg.VerifyDatabase == s.dbCfg.Name {
+		return nil, fmt.Errorf("backup.verify_database must not be the live database")
+	}
+
+	dir, manifest, err := s.open(id)
+	if err != nil {
+		return nil, err
+	}
+
+	scratchCfg := s.dbCfg
+	scratchCfg.Name = s.cfg.VerifyDatabase
+	scratch, err := db.NewDatabase(&scratchCfg)
+	if err != nil {
+		return nil, err
+	}
+	defer scratch.Close()
+
+	if err := resetSchema(ctx, scratch.DB); err != nil {
+		return nil, err
+	}
+	if err := s.restoreInto(ctx, scratch.DB, &scratchCfg, dir, manifest); err != nil {
+		return nil, err
+	}
+
+	verifiedAt := time.Now().UTC()
+	manifest.VerifiedAt = &verifiedAt
+	if err := WriteManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Database backup verified", zap.String("backup_id", id))
+	return manifest, nil
+}
+
+// List returns the complete backups, newest first
+func (s *Service) List() ([]*Manifest, error) {
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var manifests []*Manifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifest, err := ReadManifest(filepath.Join(s.cfg.Dir, entry.Name()))
+		if err != nil {
+			// A backup without a manifest did not complete
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].ID > manifests[j].ID })
+	return manifests, nil
+}
+
+// Prune deletes all but the newest keep backups. The newest verified backup is
+// always kept, so there is a known-good restore point. It returns the IDs deleted.
+func (s *Service) Prune(keep int) ([]string, error) {
+	manifests, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	keepVerified := ""
+	for _, manifest := range manifests {
+		if manifest.VerifiedAt != nil {
+			keepVerified = manifest.ID
+			break
+		}
+	}
+
+	var deleted []string
+	for i, manifest := range manifests {
+		if i < keep || manifest.ID == keepVerified {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.cfg.Dir, manifest.ID)); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, manifest.ID)
+	}
+	return deleted, nil
+}
+
+// open reads a backup's manifest and checks its dump is intact
+func (s *Service) open(id string) (string, *Manifest, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return "", nil, fmt.Errorf("invalid backup id %q", id)
+	}
+	dir := filepath.Join(s.cfg.Dir, id)
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("backup %s: %w", id, err)
+	}
+	if err := CheckDump(dir, manifest); err != nil {
+		return "", nil, err
+	}
+	return dir, manifest, nil
+}
+
+// restoreInto runs pg_restore against target and compares its row counts with the manifest
+func (s *Service) restoreInto(ctx context.Context, target *gorm.DB, targetCfg *config.DatabaseConfig, dir string, manifest *Manifest) error {
+	args := append([]string{
+		"--no-owner",
+		"--exit-on-error",
+		"--single-transaction",
+	}, connectionArgs(targetCfg)...)
+	args = append(args, filepath.Join(dir, DumpFile))
+	if err := s.runner.Run(ctx, "pg_restore", args, connectionEnv(targetCfg)); err != nil {
+		return err
+	}
+
+	counts, err := tableCounts(target.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	return CompareCounts(manifest.TableCounts, counts)
+}
+
+// CompareCounts reports the tables whose restored row count differs from the backup
+func CompareCounts(want, got map[string]int64) error {
+	var mismatches []string
+	for table, count := range want {
+		if got[table] != count {
+			mismatches = append(mismatches, fmt.Sprintf("%s: want %d rows, got %d", table, count, got[table]))
+		}
+	}
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return fmt.Errorf("restored data does not match the backup: %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// tableNames lists the tables of the public schema
+func tableNames(tx *gorm.DB) ([]string, error) {
+	var names []string
+	err := tx.Raw("SELECT table_name FROM information_schema.tables WHERE table_schema = 'public' AND table_type = 'BASE TABLE'").
+		Scan(&names).Error
+	return names, err
+}
+
+// tableCounts counts the rows of every table in the public schema
+func tableCounts(tx *gorm.DB) (map[string]int64, error) {
+	names, err := tableNames(tx)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(names))
+	for _, name := range names {
+		var count int64
+		if err := tx.Table(name).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		counts[name] = count
+	}
+	return counts, nil
+}
+
+// imageRefs collects the distinct image URLs referenced by the image columns
+// of the tables present in the database
+func (s *Service) imageRefs(tx *gorm.DB, tables map[string]int64) ([]ImageRef, error) {
+	refs := []ImageRef{}
+	for _, column := range s.imageColumns {
+		if _, ok := tables[column.Table]; !ok {
+			continue
+		}
+		var urls []string
+		err := tx.Table(column.Table).
+			Where(column.Column+" IS NOT NULL AND "+column.Column+" <> ''").
+			Distinct(column.Column).
+			Order(column.Column).
+			Pluck(column.Column, &urls).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, url := range urls {
+			refs = append(refs, ImageRef{Table: column.Table, Column: column.Column, URL: url})
+		}
+	}
+	return refs, nil
+}
+
+// resetSchema drops and recreates the public schema of a database
+func resetSchema(ctx context.Context, database *gorm.DB) error {
+	return database.WithContext(ctx).Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public").Error
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"dongome/pkg/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCacheMiss is returned by Get when the key is not cached
+var ErrCacheMiss = stderrors.New("cache miss")
+
+// Cache defines the interface for a shared key/value cache. Values are stored as JSON.
+type Cache interface {
+	Get(ctx context.Context, key string, dest interface{}) error
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+	Close() error
+}
+
+// Counters defines the interface for shared counters kept in the fields of a
+// hash, added to concurrently and taken all at once
+type Counters interface {
+	// IncrementField adds n to a field of the hash at key
+	IncrementField(ctx context.Context, key, field string, n int64) error
+	// TakeFields returns the fields of the hash at key and removes it, in one
+	// step, so no increment is taken twice or lost in between
+	TakeFields(ctx context.Context, key string) (map[string]int64, error)
+}
+
+// RedisCache implements Cache and Counters using Redis
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a new Redis cache and verifies the connection
+func NewRedisCache(ctx context.Context, cfg *config.RedisConfig) (*RedisCache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisCache{client: client}, nil
+}
+
+// Get decodes the cached value of key into dest
+func (c *RedisCache) Get(ctx context.Context, key string, dest interface{}) error {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return ErrCacheMiss
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// Set caches value under key for ttl
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, key, data, ttl).Err()
+}
+
+// SetNX caches value under key for ttl unless key is already cached, and
+// reports whether it did. Concurrent callers cannot both succeed.
+func (c *RedisCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	return c.client.SetNX(ctx, key, data, ttl).Result()
+}
+
+// Delete removes keys from the cache
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// IncrementField adds n to a field of the hash at key
+func (c *RedisCache) IncrementField(ctx context.Context, key, field string, n int64) error {
+	return c.client.HIncrBy(ctx, key, field, n).Err()
+}
+
+// TakeFields returns the fields of the hash at key and removes it in one
+// transaction
+func (c *RedisCache) TakeFields(ctx context.Context, key string) (map[string]int64, error) {
+	pipe := c.client.TxPipeline()
+	fields := pipe.HGetAll(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(fields.Val()))
+	for field, value := range fields.Val() {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("counter %s of %s is not a number: %w", field, key, err)
+		}
+		counts[field] = count
+	}
+	return counts, nil
+}
+
+// Close closes the Redis connection
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
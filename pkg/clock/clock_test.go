@@ -0,0 +1,30 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrozen(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	frozen := clock.NewFrozen(start)
+	assert.Equal(t, start, frozen.Now())
+
+	frozen.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), frozen.Now())
+
+	frozen.Set(start)
+	assert.Equal(t, start, frozen.Now())
+}
+
+func TestOrSystem(t *testing.T) {
+	frozen := clock.NewFrozen(time.Unix(0, 0))
+	assert.Same(t, frozen, clock.OrSystem(frozen))
+
+	before := time.Now()
+	assert.False(t, clock.OrSystem(nil).Now().Before(before))
+}
@@ -1,20 +1,44 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	NATS     NATSConfig     `mapstructure:"nats"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	MoMo     MoMoConfig     `mapstructure:"momo"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Log        LogConfig        `mapstructure:"log"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Redis      RedisConfig      `mapstructure:"redis"`
+	NATS       NATSConfig       `mapstructure:"nats"`
+	JWT        JWTConfig        `mapstructure:"jwt"`
+	MoMo       MoMoConfig       `mapstructure:"momo"`
+	Payments   PaymentsConfig   `mapstructure:"payments"`
+	Exports    ExportsConfig    `mapstructure:"exports"`
+	Internal   InternalConfig   `mapstructure:"internal"`
+	Backup     BackupConfig     `mapstructure:"backup"`
+	Anonymize  AnonymizeConfig  `mapstructure:"anonymize"`
+	Reminders  RemindersConfig  `mapstructure:"reminders"`
+	Checkout   CheckoutConfig   `mapstructure:"checkout"`
+	Fraud      FraudConfig      `mapstructure:"fraud"`
+	Escrow     EscrowConfig     `mapstructure:"escrow"`
+	Payouts    PayoutsConfig    `mapstructure:"payouts"`
+	Schedule   ScheduleConfig   `mapstructure:"schedule"`
+	Uploads    UploadsConfig    `mapstructure:"uploads"`
+	Listings   ListingsConfig   `mapstructure:"listings"`
+	Webhooks   WebhooksConfig   `mapstructure:"webhooks"`
+	Rules      RulesConfig      `mapstructure:"rules"`
+	Search     SearchConfig     `mapstructure:"search"`
+	Currency   CurrencyConfig   `mapstructure:"currency"`
+	Sitemap    SitemapConfig    `mapstructure:"sitemap"`
+	ShareLinks ShareLinksConfig `mapstructure:"share_links"`
+	Status     StatusConfig     `mapstructure:"status"`
 }
 
 type ServerConfig struct {
@@ -23,13 +47,22 @@ type ServerConfig struct {
 	Mode string `mapstructure:"mode"`
 }
 
+// LogConfig selects the log format and level. The ecs format writes Elastic
+// Common Schema JSON; an empty format writes JSON in production and console
+// output elsewhere.
+type LogConfig struct {
+	Format string `mapstructure:"format"`
+	Level  string `mapstructure:"level"`
+}
+
 type DatabaseConfig struct {
-	Host     string `mapstructure:"host"`
-	Port     string `mapstructure:"port"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	Name     string `mapstructure:"name"`
-	SSLMode  string `mapstructure:"ssl_mode"`
+	Host           string `mapstructure:"host"`
+	Port           string `mapstructure:"port"`
+	User           string `mapstructure:"user"`
+	Password       string `mapstructure:"password"`
+	Name           string `mapstructure:"name"`
+	SSLMode        string `mapstructure:"ssl_mode"`
+	MigrationsPath string `mapstructure:"migrations_path"`
 }
 
 type RedisConfig struct {
@@ -49,10 +82,363 @@ type JWTConfig struct {
 }
 
 type MoMoConfig struct {
+	// APIKey and APISecret are the Collection API user and its key
 	APIKey      string `mapstructure:"api_key"`
 	APISecret   string `mapstructure:"api_secret"`
 	Environment string `mapstructure:"environment"`
-	CallbackURL string `mapstructure:"callback_url"`
+	// BaseURL is the MoMo API, MTN's sandbox or live API by
+	// TargetEnvironment when empty; payments are not requested until
+	// SubscriptionKey and APIKey are set
+	BaseURL         string `mapstructure:"base_url"`
+	SubscriptionKey string `mapstructure:"subscription_key"`
+	// DisbursementAPIKey, DisbursementAPISecret and
+	// DisbursementSubscriptionKey are the Disbursement API user, its key and
+	// subscription, used to send money to wallets
+	DisbursementAPIKey          string `mapstructure:"disbursement_api_key"`
+	DisbursementAPISecret       string `mapstructure:"disbursement_api_secret"`
+	DisbursementSubscriptionKey string `mapstructure:"disbursement_subscription_key"`
+	// TargetEnvironment is sandbox, or the live environment of the market,
+	// such as mtnghana
+	TargetEnvironment string        `mapstructure:"target_environment"`
+	Timeout           time.Duration `mapstructure:"timeout"`
+	CallbackURL       string        `mapstructure:"callback_url"`
+	// PromotionCallbackURL receives the results of listing promotion payments
+	PromotionCallbackURL string `mapstructure:"promotion_callback_url"`
+	// CallbackSecret verifies the signature of payment callbacks; callbacks
+	// are not accepted until it is set
+	CallbackSecret string `mapstructure:"callback_secret"`
+}
+
+// PaymentsConfig selects the payment provider taking each payment method
+// buyers choose at checkout, and configures the providers besides MoMo
+type PaymentsConfig struct {
+	// Methods maps each payment method offered, momo, card, vodafone_cash
+	// or airteltigo_money, to the provider taking it: momo, paystack or
+	// flutterwave. MoMo only takes MTN Mobile Money, which is the only
+	// method offered by default.
+	Methods map[string]string `mapstructure:"methods"`
+	// ReturnURL is where buyers come back to from a provider's checkout page
+	ReturnURL   string            `mapstructure:"return_url"`
+	Paystack    PaystackConfig    `mapstructure:"paystack"`
+	Flutterwave FlutterwaveConfig `mapstructure:"flutterwave"`
+}
+
+// PaystackConfig configures Paystack, which signs its webhooks with the
+// secret key. It takes no payments until SecretKey is set.
+type PaystackConfig struct {
+	// BaseURL is Paystack's API when empty
+	BaseURL   string        `mapstructure:"base_url"`
+	SecretKey string        `mapstructure:"secret_key"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
+
+// FlutterwaveConfig configures Flutterwave. It takes no payments until
+// SecretKey is set.
+type FlutterwaveConfig struct {
+	// BaseURL is Flutterwave's API when empty
+	BaseURL   string `mapstructure:"base_url"`
+	SecretKey string `mapstructure:"secret_key"`
+	// WebhookHash is the secret hash set on the Flutterwave dashboard, which
+	// it sends with every webhook
+	WebhookHash string        `mapstructure:"webhook_hash"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+}
+
+type ExportsConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// BackupConfig configures database backups
+type BackupConfig struct {
+	Dir string `mapstructure:"dir"`
+	// Interval between scheduled backups taken by the worker; zero disables them
+	Interval time.Duration `mapstructure:"interval"`
+	// Retention is how many backups are kept
+	Retention int `mapstructure:"retention"`
+	// VerifyDatabase is a scratch database that backups are test-restored into
+	VerifyDatabase string `mapstructure:"verify_database"`
+}
+
+// AnonymizeConfig configures pseudonymization of staging data
+type AnonymizeConfig struct {
+	// Key seeds the pseudonyms; keep it secret so originals cannot be guessed
+	Key string `mapstructure:"key"`
+}
+
+// RemindersConfig configures the email verification reminder sequence
+type RemindersConfig struct {
+	// Interval between runs of the worker job sending due reminders; zero disables reminders
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxPerUser caps how many reminders one user can receive
+	MaxPerUser int `mapstructure:"max_per_user"`
+}
+
+// CheckoutConfig configures order checkout
+type CheckoutConfig struct {
+	// PaymentTimeout is how long a buyer has to pay before the order is abandoned
+	PaymentTimeout time.Duration `mapstructure:"payment_timeout"`
+	// AbandonInterval between runs of the worker job abandoning unpaid orders; zero disables the job
+	AbandonInterval time.Duration `mapstructure:"abandon_interval"`
+	// ResumeURL is the deep link sent to buyers to finish paying; {order_id} is replaced with the order
+	ResumeURL string `mapstructure:"resume_url"`
+	// SagaGrace is how long after the payment deadline a checkout saga waits
+	// for the order to be paid or abandoned before it times out
+	SagaGrace time.Duration `mapstructure:"saga_grace"`
+	// SagaInterval between runs of the worker job timing out checkout sagas
+	// and retrying failed compensations; zero disables the job
+	SagaInterval time.Duration `mapstructure:"saga_interval"`
+	// PaymentCheckInterval between runs of the worker jobs looking up order
+	// payments whose callback is late, and refunds and wallet top-ups and
+	// withdrawals still pending; zero disables the jobs
+	PaymentCheckInterval time.Duration `mapstructure:"payment_check_interval"`
+}
+
+// FraudConfig configures how orders are checked for fraud as they are placed
+type FraudConfig struct {
+	// HoldScore is the fraud score from which orders are held for an
+	// administrator's review
+	HoldScore float64 `mapstructure:"hold_score"`
+	// ReviewWindow is how long a held order waits for review before it is cancelled
+	ReviewWindow time.Duration `mapstructure:"review_window"`
+}
+
+// EscrowConfig configures how the payments of orders are held for buyer protection
+type EscrowConfig struct {
+	// HoldPeriod is how long a payment is held before it is released to the
+	// seller without the buyer confirming delivery
+	HoldPeriod time.Duration `mapstructure:"hold_period"`
+	// ConfirmWindow is how long the buyer has to confirm delivery or cancel
+	// once the seller reports the order delivered
+	ConfirmWindow time.Duration `mapstructure:"confirm_window"`
+	// ReleaseInterval between runs of the worker job releasing payments whose
+	// hold ended; zero disables the job
+	ReleaseInterval time.Duration `mapstructure:"release_interval"`
+}
+
+// PayoutsConfig configures how sellers are paid the funds released to them
+type PayoutsConfig struct {
+	// DefaultSchedule is the schedule of sellers who register a payout
+	// number without choosing one: instant, daily or weekly
+	DefaultSchedule string `mapstructure:"default_schedule"`
+	// Weekday is the day sellers on the weekly schedule are paid, such as friday
+	Weekday string `mapstructure:"weekday"`
+	// Interval between runs of the worker jobs paying sellers whose payout
+	// is due and looking up payouts still pending; zero disables the jobs
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// WebhooksConfig configures how payment callbacks and partner webhooks are authenticated
+type WebhooksConfig struct {
+	// MaxClockSkew is how far a webhook's timestamp may be from the local
+	// clock; nonces are remembered for twice as long to reject replays
+	MaxClockSkew time.Duration `mapstructure:"max_clock_skew"`
+}
+
+// RulesConfig configures the business rules administrators can change
+type RulesConfig struct {
+	// ReloadInterval is how often each instance reloads rules, picking up
+	// changes made through other instances
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// ScheduleConfig configures publishing listings at a future time
+type ScheduleConfig struct {
+	// Interval between runs of the worker job publishing due listings; zero disables the job
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxScheduled caps how many listings a seller can have waiting to go live; zero means no cap
+	MaxScheduled int `mapstructure:"max_scheduled"`
+	// MaxLeadTime is how far ahead a listing can be scheduled; zero means no limit
+	MaxLeadTime time.Duration `mapstructure:"max_lead_time"`
+}
+
+// UploadsConfig configures photo uploads
+type UploadsConfig struct {
+	// Dir is where uploaded photos are stored; the API and worker must share it
+	Dir string `mapstructure:"dir"`
+	// BaseURL is the URL uploaded photos are served from
+	BaseURL string `mapstructure:"base_url"`
+	// CleanupInterval between runs of the worker job deleting photos never
+	// attached to a listing; zero disables the job
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+	// ProcessImages has the worker make resized, metadata-free WebP copies of
+	// listing photos; it needs libwebp's cwebp and dwebp tools
+	ProcessImages bool `mapstructure:"process_images"`
+	// WebPQuality of the copies, from 1 to 100
+	WebPQuality int `mapstructure:"webp_quality"`
+	// S3 stores photos in object storage instead of Dir when a bucket is set,
+	// and lets clients upload them directly
+	S3 S3UploadsConfig `mapstructure:"s3"`
+}
+
+// S3UploadsConfig locates the S3-compatible bucket photos are stored in
+type S3UploadsConfig struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	// PathStyle addresses the bucket in the path, as MinIO requires
+	PathStyle bool `mapstructure:"path_style"`
+	// PublicURL is where photos are served from, such as a CDN; by default
+	// they are served from the bucket
+	PublicURL string        `mapstructure:"public_url"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
+
+// ListingsConfig configures the rules listings must pass to go live and how
+// they are ranked
+type ListingsConfig struct {
+	// MaxActivePerSeller caps how many live listings a seller can have; zero means no cap
+	MaxActivePerSeller int `mapstructure:"max_active_per_seller"`
+	// RankingInterval between runs of the worker job demoting listings of stale
+	// or unresponsive sellers; zero disables the job
+	RankingInterval time.Duration `mapstructure:"ranking_interval"`
+	// RenewalWindow is how long before and after it expires a listing can be
+	// renewed; zero allows renewing at any time
+	RenewalWindow time.Duration `mapstructure:"renewal_window"`
+	// MaxRenewals is how many times a listing can be renewed before it has to
+	// be relisted; zero means no cap
+	MaxRenewals int `mapstructure:"max_renewals"`
+	// PromotionPackages are what sellers can pay to promote a listing for
+	PromotionPackages []PromotionPackageConfig `mapstructure:"promotion_packages"`
+	// PromotionInterval between runs of the worker job ending promotions
+	// whose paid time is up; zero disables the job
+	PromotionInterval time.Duration `mapstructure:"promotion_interval"`
+	// PromotedPerPage caps how many promoted listings are shown in the
+	// sponsored slots of a page of search results; zero shows none
+	PromotedPerPage int `mapstructure:"promoted_per_page"`
+	// BulkOperationInterval between runs of the worker job working through
+	// administrators' bulk operations; zero disables the job
+	BulkOperationInterval time.Duration `mapstructure:"bulk_operation_interval"`
+	// ExpiryInterval between runs of the worker job expiring live listings
+	// past the end of their lifetime; zero disables the job
+	ExpiryInterval time.Duration `mapstructure:"expiry_interval"`
+	// ReportThreshold is how many users must report a listing before it is
+	// taken down for review; zero leaves reported listings up
+	ReportThreshold int `mapstructure:"report_threshold"`
+	// ImportInterval between runs of the worker job turning the rows of
+	// sellers' import files into draft listings; zero disables the job
+	ImportInterval time.Duration `mapstructure:"import_interval"`
+	// DraftRetention is how long a draft can go without being saved before
+	// the worker deletes it
+	DraftRetention time.Duration `mapstructure:"draft_retention"`
+	// DraftCleanupInterval between runs of the worker job deleting stale
+	// drafts; zero disables the job
+	DraftCleanupInterval time.Duration `mapstructure:"draft_cleanup_interval"`
+	// ViewDedupWindow is how long repeated views of a listing by one viewer
+	// count as one; zero counts every view
+	ViewDedupWindow time.Duration `mapstructure:"view_dedup_window"`
+	// ViewFlushInterval between runs of the worker job writing the view
+	// counts buffered in Redis to the database; zero writes every view as
+	// it is counted
+	ViewFlushInterval time.Duration `mapstructure:"view_flush_interval"`
+	// OfferExpiryInterval between runs of the worker job closing offers that
+	// lapsed without a reply; zero disables the job
+	OfferExpiryInterval time.Duration `mapstructure:"offer_expiry_interval"`
+	// TrendingInterval between runs of the worker job ranking trending
+	// listings; zero disables the job, leaving the API to rank them when
+	// its cache runs dry
+	TrendingInterval time.Duration `mapstructure:"trending_interval"`
+}
+
+// PromotionPackageConfig is a length of time a listing can be promoted for
+// and its price
+type PromotionPackageConfig struct {
+	ID       string  `mapstructure:"id"`
+	Name     string  `mapstructure:"name"`
+	Days     int     `mapstructure:"days"`
+	Price    float64 `mapstructure:"price"`
+	Currency string  `mapstructure:"currency"`
+}
+
+// SearchConfig configures the optional Elasticsearch or OpenSearch cluster
+// listing search is served from
+type SearchConfig struct {
+	// URL of the cluster; empty serves search from the database
+	URL      string `mapstructure:"url"`
+	Index    string `mapstructure:"index"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Timeout bounds each request to the cluster
+	Timeout time.Duration `mapstructure:"timeout"`
+	// ReindexInterval between runs of the worker job copying every active
+	// listing into the index; zero disables the job
+	ReindexInterval time.Duration `mapstructure:"reindex_interval"`
+}
+
+// CurrencyConfig configures the exchange rates used to show prices in the
+// currency each buyer prefers
+type CurrencyConfig struct {
+	// RatesURL of the exchange rates API, called as <url>/<base currency>;
+	// empty shows prices only in the currency they are listed in
+	RatesURL string `mapstructure:"rates_url"`
+	// RatesTTL is how long fetched rates are used before they are fetched again
+	RatesTTL time.Duration `mapstructure:"rates_ttl"`
+	// Timeout bounds each request to the rates API
+	Timeout time.Duration `mapstructure:"timeout"`
+	// RefreshInterval between runs of the worker job fetching the latest
+	// rates; zero disables the job
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+// SitemapConfig configures the sitemap and listing feeds the worker
+// generates for search engines and feed readers
+type SitemapConfig struct {
+	// SiteURL is the website listing pages are linked on, as
+	// <url>/listings/<id>; empty disables generation
+	SiteURL string `mapstructure:"site_url"`
+	// Interval between runs of the worker job generating them; zero
+	// disables the job
+	Interval time.Duration `mapstructure:"interval"`
+	// FeedSize is how many of the newest listings the feeds show
+	FeedSize int `mapstructure:"feed_size"`
+}
+
+// ShareLinksConfig configures the short links listings are shared with. They
+// lead to listing pages on sitemap.site_url.
+type ShareLinksConfig struct {
+	// BaseURL is the public address of the API, where short links are served
+	// as <url>/l/<code>
+	BaseURL string `mapstructure:"base_url"`
+}
+
+// StatusConfig configures the health checks behind the public status page
+type StatusConfig struct {
+	// CheckInterval between the worker's rounds of health checks; zero
+	// disables them
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// APIURL is the API health endpoint the worker checks
+	APIURL string `mapstructure:"api_url"`
+	// Timeout bounds each check; slower answers count as down
+	Timeout time.Duration `mapstructure:"timeout"`
+	// SlowThreshold is how long an answer may take before the component is
+	// reported degraded
+	SlowThreshold time.Duration `mapstructure:"slow_threshold"`
+	// LagThreshold is how far event consumers may fall behind before event
+	// processing is reported degraded
+	LagThreshold time.Duration `mapstructure:"lag_threshold"`
+	// Retention is how long check history is kept; it must cover the 7 days
+	// uptime is reported over
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+// InternalConfig configures the listener for service-to-service calls
+type InternalConfig struct {
+	Port string            `mapstructure:"port"`
+	TLS  InternalTLSConfig `mapstructure:"tls"`
+	// AllowedCallers maps a caller's service identity to the routes it may
+	// call, written as "METHOD /route", for example "GET /internal/v1/users/:id"
+	AllowedCallers map[string][]string `mapstructure:"allowed_callers"`
+}
+
+// InternalTLSConfig configures mutual TLS between internal services
+type InternalTLSConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	CAFile   string `mapstructure:"ca_file"`
+	// ReloadInterval is how often the files are checked for rotated certificates
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
 }
 
 func LoadConfig() *Config {
@@ -83,6 +469,221 @@ func LoadConfig() *Config {
 	return &config
 }
 
+// Validate checks that the configuration is complete and consistent
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Server.Port == "" {
+		problems = append(problems, "server.port is required")
+	}
+	if c.Database.Host == "" {
+		problems = append(problems, "database.host is required")
+	}
+	if c.Database.Name == "" {
+		problems = append(problems, "database.name is required")
+	}
+	if c.Database.User == "" {
+		problems = append(problems, "database.user is required")
+	}
+	if c.NATS.URL == "" {
+		problems = append(problems, "nats.url is required")
+	}
+	if c.Log.Format != "" && c.Log.Format != "console" && c.Log.Format != "json" && c.Log.Format != "ecs" {
+		problems = append(problems, "log.format must be console, json or ecs")
+	}
+	if c.Log.Level != "" && c.Log.Level != "debug" && c.Log.Level != "info" && c.Log.Level != "warn" && c.Log.Level != "error" {
+		problems = append(problems, "log.level must be debug, info, warn or error")
+	}
+	if c.JWT.Secret == "" {
+		problems = append(problems, "jwt.secret is required")
+	}
+	if c.Server.Mode == "production" && c.JWT.Secret == "your-secret-key" {
+		problems = append(problems, "jwt.secret must be changed in production")
+	}
+	if c.MoMo.Environment != "sandbox" && c.MoMo.Environment != "live" {
+		problems = append(problems, "momo.environment must be sandbox or live")
+	}
+	if c.MoMo.Environment == "live" && c.MoMo.CallbackSecret == "" {
+		problems = append(problems, "momo.callback_secret is required in the live environment")
+	}
+	if (c.MoMo.Environment == "live") == (c.MoMo.TargetEnvironment == "sandbox") {
+		problems = append(problems, "momo.target_environment must be sandbox exactly when momo.environment is sandbox")
+	}
+	if c.Webhooks.MaxClockSkew <= 0 || c.Webhooks.MaxClockSkew > 15*time.Minute {
+		problems = append(problems, "webhooks.max_clock_skew must be positive and at most 15m")
+	}
+	if c.Rules.ReloadInterval <= 0 {
+		problems = append(problems, "rules.reload_interval must be positive")
+	}
+
+	if c.Backup.Retention < 1 {
+		problems = append(problems, "backup.retention must be at least 1")
+	}
+	if c.Backup.VerifyDatabase != "" && c.Backup.VerifyDatabase == c.Database.Name {
+		problems = append(problems, "backup.verify_database must not be the live database")
+	}
+	if c.Reminders.MaxPerUser < 0 {
+		problems = append(problems, "reminders.max_per_user must not be negative")
+	}
+	if c.Checkout.PaymentTimeout <= 0 {
+		problems = append(problems, "checkout.payment_timeout must be positive")
+	}
+	if c.Checkout.SagaGrace < 0 || c.Checkout.SagaInterval < 0 {
+		problems = append(problems, "checkout.saga_grace and checkout.saga_interval must not be negative")
+	}
+	if c.Checkout.PaymentCheckInterval < 0 {
+		problems = append(problems, "checkout.payment_check_interval must not be negative")
+	}
+	if c.Fraud.HoldScore <= 0 || c.Fraud.ReviewWindow <= 0 {
+		problems = append(problems, "fraud.hold_score and fraud.review_window must be positive")
+	}
+	if c.Escrow.HoldPeriod <= 0 || c.Escrow.ConfirmWindow <= 0 {
+		problems = append(problems, "escrow.hold_period and escrow.confirm_window must be positive")
+	}
+	if c.Escrow.ReleaseInterval < 0 {
+		problems = append(problems, "escrow.release_interval must not be negative")
+	}
+	if c.Payouts.DefaultSchedule != "instant" && c.Payouts.DefaultSchedule != "daily" && c.Payouts.DefaultSchedule != "weekly" {
+		problems = append(problems, "payouts.default_schedule must be instant, daily or weekly")
+	}
+	switch strings.ToLower(c.Payouts.Weekday) {
+	case "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday":
+	default:
+		problems = append(problems, "payouts.weekday must be a day of the week, such as friday")
+	}
+	if c.Payouts.Interval < 0 {
+		problems = append(problems, "payouts.interval must not be negative")
+	}
+	if c.Schedule.MaxScheduled < 0 || c.Schedule.MaxLeadTime < 0 {
+		problems = append(problems, "schedule.max_scheduled and schedule.max_lead_time must not be negative")
+	}
+	if c.Uploads.S3.Bucket == "" && (c.Uploads.Dir == "" || c.Uploads.BaseURL == "") {
+		problems = append(problems, "uploads.dir and uploads.base_url are required")
+	}
+	if c.Uploads.S3.Bucket != "" && (c.Uploads.S3.Endpoint == "" || c.Uploads.S3.Region == "" ||
+		c.Uploads.S3.AccessKey == "" || c.Uploads.S3.SecretKey == "" || c.Uploads.S3.Timeout <= 0) {
+		problems = append(problems, "uploads.s3 endpoint, region, access_key, secret_key and a positive timeout are required when uploads.s3.bucket is set")
+	}
+	if c.Uploads.ProcessImages && (c.Uploads.WebPQuality < 1 || c.Uploads.WebPQuality > 100) {
+		problems = append(problems, "uploads.webp_quality must be between 1 and 100")
+	}
+	if c.Listings.MaxActivePerSeller < 0 || c.Listings.RankingInterval < 0 {
+		problems = append(problems, "listings.max_active_per_seller and listings.ranking_interval must not be negative")
+	}
+	if c.Listings.RenewalWindow < 0 || c.Listings.MaxRenewals < 0 {
+		problems = append(problems, "listings.renewal_window and listings.max_renewals must not be negative")
+	}
+	if c.Listings.PromotionInterval < 0 {
+		problems = append(problems, "listings.promotion_interval must not be negative")
+	}
+	if c.Listings.PromotedPerPage < 0 {
+		problems = append(problems, "listings.promoted_per_page must not be negative")
+	}
+	if c.Listings.BulkOperationInterval < 0 {
+		problems = append(problems, "listings.bulk_operation_interval must not be negative")
+	}
+	if c.Listings.ExpiryInterval < 0 {
+		problems = append(problems, "listings.expiry_interval must not be negative")
+	}
+	if c.Listings.ReportThreshold < 0 {
+		problems = append(problems, "listings.report_threshold must not be negative")
+	}
+	if c.Listings.ImportInterval < 0 {
+		problems = append(problems, "listings.import_interval must not be negative")
+	}
+	if c.Listings.DraftCleanupInterval < 0 {
+		problems = append(problems, "listings.draft_cleanup_interval must not be negative")
+	}
+	if c.Listings.ViewDedupWindow < 0 || c.Listings.ViewFlushInterval < 0 {
+		problems = append(problems, "listings.view_dedup_window and listings.view_flush_interval must not be negative")
+	}
+	if c.Listings.OfferExpiryInterval < 0 {
+		problems = append(problems, "listings.offer_expiry_interval must not be negative")
+	}
+	if c.Listings.TrendingInterval < 0 {
+		problems = append(problems, "listings.trending_interval must not be negative")
+	}
+	if c.Listings.DraftCleanupInterval > 0 && c.Listings.DraftRetention <= 0 {
+		problems = append(problems, "listings.draft_retention must be positive when stale drafts are cleaned up")
+	}
+	packageIDs := make(map[string]bool)
+	for _, pkg := range c.Listings.PromotionPackages {
+		if pkg.ID == "" || packageIDs[pkg.ID] || pkg.Days <= 0 || pkg.Price <= 0 || len(pkg.Currency) != 3 {
+			problems = append(problems, "listings.promotion_packages need a unique id, positive days and price, and a currency code")
+			break
+		}
+		packageIDs[pkg.ID] = true
+	}
+	if c.MoMo.Timeout <= 0 {
+		problems = append(problems, "momo.timeout must be positive")
+	}
+	for method, provider := range c.Payments.Methods {
+		switch method {
+		case "momo", "card", "vodafone_cash", "airteltigo_money":
+		default:
+			problems = append(problems, "payments.methods may only select the providers of momo, card, vodafone_cash and airteltigo_money")
+			continue
+		}
+		switch provider {
+		case "momo":
+			if method != "momo" {
+				problems = append(problems, "payments.methods."+method+" must be paystack or flutterwave")
+			}
+		case "paystack":
+			if c.Payments.Paystack.SecretKey == "" {
+				problems = append(problems, "payments.paystack.secret_key is required when paystack takes "+method+" payments")
+			}
+		case "flutterwave":
+			if c.Payments.Flutterwave.SecretKey == "" || c.Payments.Flutterwave.WebhookHash == "" {
+				problems = append(problems, "payments.flutterwave.secret_key and webhook_hash are required when flutterwave takes "+method+" payments")
+			}
+		default:
+			problems = append(problems, "payments.methods."+method+" must be momo, paystack or flutterwave")
+		}
+	}
+	if c.Payments.Paystack.Timeout <= 0 || c.Payments.Flutterwave.Timeout <= 0 {
+		problems = append(problems, "payments.paystack.timeout and payments.flutterwave.timeout must be positive")
+	}
+	if c.Search.URL != "" && (c.Search.Index == "" || c.Search.Timeout <= 0) {
+		problems = append(problems, "search.index and a positive search.timeout are required when search.url is set")
+	}
+	if c.Search.ReindexInterval < 0 {
+		problems = append(problems, "search.reindex_interval must not be negative")
+	}
+	if c.Currency.RatesURL != "" && (c.Currency.RatesTTL <= 0 || c.Currency.Timeout <= 0) {
+		problems = append(problems, "a positive currency.rates_ttl and currency.timeout are required when currency.rates_url is set")
+	}
+	if c.Currency.RefreshInterval < 0 {
+		problems = append(problems, "currency.refresh_interval must not be negative")
+	}
+	if c.Sitemap.Interval < 0 {
+		problems = append(problems, "sitemap.interval must not be negative")
+	}
+	if c.Sitemap.SiteURL != "" && (c.Sitemap.FeedSize < 1 || c.Sitemap.FeedSize > 500) {
+		problems = append(problems, "sitemap.feed_size must be between 1 and 500 when sitemap.site_url is set")
+	}
+	if c.ShareLinks.BaseURL == "" {
+		problems = append(problems, "share_links.base_url is required")
+	}
+	if c.Status.CheckInterval < 0 {
+		problems = append(problems, "status.check_interval must not be negative")
+	}
+	if c.Status.CheckInterval > 0 && (c.Status.APIURL == "" || c.Status.Timeout <= 0 || c.Status.SlowThreshold <= 0 || c.Status.LagThreshold <= 0) {
+		problems = append(problems, "status.api_url and positive status.timeout, status.slow_threshold and status.lag_threshold are required when status checks are enabled")
+	}
+	if c.Status.Retention < 7*24*time.Hour {
+		problems = append(problems, "status.retention must be at least 168h")
+	}
+	if c.Internal.TLS.Enabled && (c.Internal.TLS.CertFile == "" || c.Internal.TLS.KeyFile == "" || c.Internal.TLS.CAFile == "") {
+		problems = append(problems, "internal.tls cert_file, key_file and ca_file are required when mTLS is enabled")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
 func setDefaults() {
 	viper.SetDefault("server.host", "localhost")
 	viper.SetDefault("server.port", "8080")
@@ -94,6 +695,7 @@ func setDefaults() {
 	viper.SetDefault("database.password", "password")
 	viper.SetDefault("database.name", "dongome_db")
 	viper.SetDefault("database.ssl_mode", "disable")
+	viper.SetDefault("database.migrations_path", "./migrations")
 
 	viper.SetDefault("redis.host", "localhost")
 	viper.SetDefault("redis.port", "6379")
@@ -106,6 +708,95 @@ func setDefaults() {
 	viper.SetDefault("jwt.expiration", 24) // 24 hours
 
 	viper.SetDefault("momo.environment", "sandbox")
+	viper.SetDefault("momo.base_url", "")
+	viper.SetDefault("momo.target_environment", "sandbox")
+	viper.SetDefault("momo.timeout", 30*time.Second)
+	viper.SetDefault("payments.methods", map[string]string{"momo": "momo"})
+	viper.SetDefault("payments.return_url", "")
+	viper.SetDefault("payments.paystack.timeout", 30*time.Second)
+	viper.SetDefault("payments.flutterwave.timeout", 30*time.Second)
+	viper.SetDefault("webhooks.max_clock_skew", 5*time.Minute)
+	viper.SetDefault("rules.reload_interval", 30*time.Second)
+
+	viper.SetDefault("exports.dir", "./data/exports")
+
+	viper.SetDefault("backup.dir", "./data/backups")
+	viper.SetDefault("backup.interval", 0)
+	viper.SetDefault("backup.retention", 7)
+
+	viper.SetDefault("reminders.interval", 15*time.Minute)
+	viper.SetDefault("reminders.max_per_user", 3)
+
+	viper.SetDefault("checkout.payment_timeout", 30*time.Minute)
+	viper.SetDefault("checkout.abandon_interval", 5*time.Minute)
+	viper.SetDefault("checkout.resume_url", "dongome://orders/{order_id}/pay")
+	viper.SetDefault("checkout.saga_grace", 15*time.Minute)
+	viper.SetDefault("checkout.saga_interval", time.Minute)
+	viper.SetDefault("checkout.payment_check_interval", time.Minute)
+	viper.SetDefault("fraud.hold_score", 60)
+	viper.SetDefault("fraud.review_window", 24*time.Hour)
+	viper.SetDefault("escrow.hold_period", 14*24*time.Hour)
+	viper.SetDefault("escrow.confirm_window", 72*time.Hour)
+	viper.SetDefault("escrow.release_interval", 15*time.Minute)
+	viper.SetDefault("payouts.default_schedule", "daily")
+	viper.SetDefault("payouts.weekday", "friday")
+	viper.SetDefault("payouts.interval", 15*time.Minute)
+
+	viper.SetDefault("schedule.interval", time.Minute)
+	viper.SetDefault("schedule.max_scheduled", 20)
+	viper.SetDefault("schedule.max_lead_time", 30*24*time.Hour)
+	viper.SetDefault("uploads.dir", "./data/uploads")
+	viper.SetDefault("uploads.base_url", "http://localhost:8080/media")
+	viper.SetDefault("uploads.cleanup_interval", time.Hour)
+	viper.SetDefault("uploads.process_images", true)
+	viper.SetDefault("uploads.webp_quality", 80)
+	viper.SetDefault("uploads.s3.region", "us-east-1")
+	viper.SetDefault("uploads.s3.timeout", 30*time.Second)
+	viper.SetDefault("listings.max_active_per_seller", 50)
+	viper.SetDefault("listings.ranking_interval", 6*time.Hour)
+	viper.SetDefault("listings.renewal_window", 7*24*time.Hour)
+	viper.SetDefault("listings.max_renewals", 3)
+	viper.SetDefault("listings.promotion_packages", []map[string]interface{}{
+		{"id": "week", "name": "7 days", "days": 7, "price": 10.0, "currency": "GHS"},
+		{"id": "fortnight", "name": "14 days", "days": 14, "price": 18.0, "currency": "GHS"},
+		{"id": "month", "name": "30 days", "days": 30, "price": 35.0, "currency": "GHS"},
+	})
+	viper.SetDefault("listings.promotion_interval", 5*time.Minute)
+	viper.SetDefault("listings.promoted_per_page", 2)
+	viper.SetDefault("listings.bulk_operation_interval", 10*time.Second)
+	viper.SetDefault("listings.expiry_interval", 10*time.Minute)
+	viper.SetDefault("listings.report_threshold", 3)
+	viper.SetDefault("listings.import_interval", 10*time.Second)
+	viper.SetDefault("listings.draft_retention", 30*24*time.Hour)
+	viper.SetDefault("listings.draft_cleanup_interval", time.Hour)
+	viper.SetDefault("listings.view_dedup_window", 30*time.Minute)
+	viper.SetDefault("listings.view_flush_interval", time.Minute)
+	viper.SetDefault("listings.offer_expiry_interval", 5*time.Minute)
+	viper.SetDefault("listings.trending_interval", 10*time.Minute)
+
+	viper.SetDefault("search.index", "listings")
+	viper.SetDefault("search.timeout", 5*time.Second)
+	viper.SetDefault("search.reindex_interval", 24*time.Hour)
+
+	viper.SetDefault("currency.rates_url", "https://open.er-api.com/v6/latest")
+	viper.SetDefault("currency.rates_ttl", time.Hour)
+	viper.SetDefault("currency.timeout", 10*time.Second)
+	viper.SetDefault("currency.refresh_interval", 30*time.Minute)
+	viper.SetDefault("sitemap.site_url", "")
+	viper.SetDefault("sitemap.interval", time.Hour)
+	viper.SetDefault("sitemap.feed_size", 50)
+	viper.SetDefault("share_links.base_url", "http://localhost:8080")
+
+	viper.SetDefault("status.check_interval", time.Minute)
+	viper.SetDefault("status.api_url", "http://localhost:8080/health")
+	viper.SetDefault("status.timeout", 10*time.Second)
+	viper.SetDefault("status.slow_threshold", 2*time.Second)
+	viper.SetDefault("status.lag_threshold", 2*time.Minute)
+	viper.SetDefault("status.retention", 8*24*time.Hour)
+
+	viper.SetDefault("internal.port", "9090")
+	viper.SetDefault("internal.tls.enabled", false)
+	viper.SetDefault("internal.tls.reload_interval", time.Minute)
 }
 
 func overrideWithEnv() {
@@ -115,6 +806,12 @@ func overrideWithEnv() {
 	if host := os.Getenv("SERVER_HOST"); host != "" {
 		viper.Set("server.host", host)
 	}
+	if logFormat := os.Getenv("LOG_FORMAT"); logFormat != "" {
+		viper.Set("log.format", logFormat)
+	}
+	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
+		viper.Set("log.level", logLevel)
+	}
 	if dbHost := os.Getenv("DB_HOST"); dbHost != "" {
 		viper.Set("database.host", dbHost)
 	}
@@ -150,10 +847,94 @@ func overrideWithEnv() {
 	if jwtSecret := os.Getenv("JWT_SECRET"); jwtSecret != "" {
 		viper.Set("jwt.secret", jwtSecret)
 	}
+	if exportsDir := os.Getenv("EXPORTS_DIR"); exportsDir != "" {
+		viper.Set("exports.dir", exportsDir)
+	}
+	if uploadsDir := os.Getenv("UPLOADS_DIR"); uploadsDir != "" {
+		viper.Set("uploads.dir", uploadsDir)
+	}
+	if accessKey := os.Getenv("UPLOADS_S3_ACCESS_KEY"); accessKey != "" {
+		viper.Set("uploads.s3.access_key", accessKey)
+	}
+	if secretKey := os.Getenv("UPLOADS_S3_SECRET_KEY"); secretKey != "" {
+		viper.Set("uploads.s3.secret_key", secretKey)
+	}
+	if backupDir := os.Getenv("BACKUP_DIR"); backupDir != "" {
+		viper.Set("backup.dir", backupDir)
+	}
+	if verifyDatabase := os.Getenv("BACKUP_VERIFY_DATABASE"); verifyDatabase != "" {
+		viper.Set("backup.verify_database", verifyDatabase)
+	}
+	if anonymizeKey := os.Getenv("ANONYMIZE_KEY"); anonymizeKey != "" {
+		viper.Set("anonymize.key", anonymizeKey)
+	}
+	if resumeURL := os.Getenv("CHECKOUT_RESUME_URL"); resumeURL != "" {
+		viper.Set("checkout.resume_url", resumeURL)
+	}
+	if searchURL := os.Getenv("SEARCH_URL"); searchURL != "" {
+		viper.Set("search.url", searchURL)
+	}
+	if searchUsername := os.Getenv("SEARCH_USERNAME"); searchUsername != "" {
+		viper.Set("search.username", searchUsername)
+	}
+	if searchPassword := os.Getenv("SEARCH_PASSWORD"); searchPassword != "" {
+		viper.Set("search.password", searchPassword)
+	}
+	// an empty CURRENCY_RATES_URL turns exchange rates off
+	if ratesURL, ok := os.LookupEnv("CURRENCY_RATES_URL"); ok {
+		viper.Set("currency.rates_url", ratesURL)
+	}
+	if siteURL := os.Getenv("SITEMAP_SITE_URL"); siteURL != "" {
+		viper.Set("sitemap.site_url", siteURL)
+	}
+	if baseURL := os.Getenv("SHARE_LINKS_BASE_URL"); baseURL != "" {
+		viper.Set("share_links.base_url", baseURL)
+	}
+	if internalPort := os.Getenv("INTERNAL_PORT"); internalPort != "" {
+		viper.Set("internal.port", internalPort)
+	}
+	if mtlsEnabled := os.Getenv("INTERNAL_TLS_ENABLED"); mtlsEnabled != "" {
+		if enabled, err := strconv.ParseBool(mtlsEnabled); err == nil {
+			viper.Set("internal.tls.enabled", enabled)
+		}
+	}
+	if certFile := os.Getenv("INTERNAL_TLS_CERT_FILE"); certFile != "" {
+		viper.Set("internal.tls.cert_file", certFile)
+	}
+	if keyFile := os.Getenv("INTERNAL_TLS_KEY_FILE"); keyFile != "" {
+		viper.Set("internal.tls.key_file", keyFile)
+	}
+	if caFile := os.Getenv("INTERNAL_TLS_CA_FILE"); caFile != "" {
+		viper.Set("internal.tls.ca_file", caFile)
+	}
 	if momoAPIKey := os.Getenv("MOMO_API_KEY"); momoAPIKey != "" {
 		viper.Set("momo.api_key", momoAPIKey)
 	}
 	if momoAPISecret := os.Getenv("MOMO_API_SECRET"); momoAPISecret != "" {
 		viper.Set("momo.api_secret", momoAPISecret)
 	}
+	if momoSubscriptionKey := os.Getenv("MOMO_SUBSCRIPTION_KEY"); momoSubscriptionKey != "" {
+		viper.Set("momo.subscription_key", momoSubscriptionKey)
+	}
+	if momoCallbackSecret := os.Getenv("MOMO_CALLBACK_SECRET"); momoCallbackSecret != "" {
+		viper.Set("momo.callback_secret", momoCallbackSecret)
+	}
+	if disbursementAPIKey := os.Getenv("MOMO_DISBURSEMENT_API_KEY"); disbursementAPIKey != "" {
+		viper.Set("momo.disbursement_api_key", disbursementAPIKey)
+	}
+	if disbursementAPISecret := os.Getenv("MOMO_DISBURSEMENT_API_SECRET"); disbursementAPISecret != "" {
+		viper.Set("momo.disbursement_api_secret", disbursementAPISecret)
+	}
+	if disbursementSubscriptionKey := os.Getenv("MOMO_DISBURSEMENT_SUBSCRIPTION_KEY"); disbursementSubscriptionKey != "" {
+		viper.Set("momo.disbursement_subscription_key", disbursementSubscriptionKey)
+	}
+	if paystackSecretKey := os.Getenv("PAYSTACK_SECRET_KEY"); paystackSecretKey != "" {
+		viper.Set("payments.paystack.secret_key", paystackSecretKey)
+	}
+	if flutterwaveSecretKey := os.Getenv("FLUTTERWAVE_SECRET_KEY"); flutterwaveSecretKey != "" {
+		viper.Set("payments.flutterwave.secret_key", flutterwaveSecretKey)
+	}
+	if flutterwaveWebhookHash := os.Getenv("FLUTTERWAVE_WEBHOOK_HASH"); flutterwaveWebhookHash != "" {
+		viper.Set("payments.flutterwave.webhook_hash", flutterwaveWebhookHash)
+	}
 }
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"database/sql/driver"
+	stderrors "errors"
+	"io"
+	"net"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PostgreSQL error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgLockNotAvailable     = "55P03"
+	pgUniqueViolation      = "23505"
+	pgForeignKeyViolation  = "23503"
+	pgCheckViolation       = "23514"
+	pgNotNullViolation     = "23502"
+	pgAdminShutdown        = "57P01"
+	pgCrashShutdown        = "57P02"
+	pgCannotConnectNow     = "57P03"
+	pgConnectionException  = "08"
+)
+
+// ClassifyError converts raw GORM/driver errors into the persistence error
+// taxonomy. Transient failures are returned as *errors.PersistenceError so
+// callers can retry them; everything else is mapped to a DomainError.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var domainErr *errors.DomainError
+	if stderrors.As(err, &domainErr) || stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	persistenceErr := errors.NewPersistenceError(classify(err), err)
+	if persistenceErr.Retryable() {
+		return persistenceErr
+	}
+
+	logger.Error("Database operation failed",
+		zap.String("kind", string(persistenceErr.Kind)),
+		zap.Error(err))
+
+	return persistenceErr.ToDomainError()
+}
+
+func classify(err error) errors.PersistenceErrorKind {
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == pgSerializationFailure:
+			return errors.PersistenceSerializationFailure
+		case pgErr.Code == pgDeadlockDetected:
+			return errors.PersistenceDeadlock
+		case pgErr.Code == pgLockNotAvailable:
+			return errors.PersistenceLockTimeout
+		case pgErr.Code == pgUniqueViolation:
+			return errors.PersistenceUniqueViolation
+		case pgErr.Code == pgForeignKeyViolation:
+			return errors.PersistenceForeignKeyViolation
+		case pgErr.Code == pgCheckViolation, pgErr.Code == pgNotNullViolation:
+			return errors.PersistenceCheckViolation
+		case pgErr.Code == pgAdminShutdown, pgErr.Code == pgCrashShutdown, pgErr.Code == pgCannotConnectNow,
+			len(pgErr.Code) == 5 && pgErr.Code[:2] == pgConnectionException:
+			return errors.PersistenceConnectionLost
+		}
+		return errors.PersistenceUnknown
+	}
+
+	var netErr net.Error
+	if stderrors.Is(err, driver.ErrBadConn) ||
+		stderrors.Is(err, io.ErrUnexpectedEOF) ||
+		stderrors.As(err, &netErr) ||
+		pgconn.Timeout(err) ||
+		pgconn.SafeToRetry(err) {
+		return errors.PersistenceConnectionLost
+	}
+
+	return errors.PersistenceUnknown
+}
@@ -0,0 +1,97 @@
+package db_test
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"testing"
+
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Initialize("test"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		code      errors.ErrorCode
+	}{
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, retryable: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: "40P01"}, retryable: true},
+		{name: "connection exception", err: &pgconn.PgError{Code: "08006"}, retryable: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, code: errors.ErrCodeConflict},
+		{name: "foreign key violation", err: &pgconn.PgError{Code: "23503"}, code: errors.ErrCodeValidation},
+		{name: "check violation", err: &pgconn.PgError{Code: "23514"}, code: errors.ErrCodeValidation},
+		{name: "unknown error", err: stderrors.New("boom"), code: errors.ErrCodeInternalServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.ClassifyError(tt.err)
+			require.Error(t, err)
+			assert.Equal(t, tt.retryable, errors.IsRetryable(err))
+
+			if !tt.retryable {
+				var domainErr *errors.DomainError
+				require.True(t, stderrors.As(err, &domainErr))
+				assert.Equal(t, tt.code, domainErr.Code)
+			}
+		})
+	}
+}
+
+func TestClassifyError_PassThrough(t *testing.T) {
+	assert.NoError(t, db.ClassifyError(nil))
+	assert.Equal(t, gorm.ErrRecordNotFound, db.ClassifyError(gorm.ErrRecordNotFound))
+
+	notFound := errors.NotFoundError("user not found")
+	assert.Equal(t, notFound, db.ClassifyError(notFound))
+}
+
+func TestWithRetry(t *testing.T) {
+	// Transient failures are retried until the operation succeeds
+	attempts := 0
+	err := db.WithRetry(context.Background(), func() error {
+		attempts++
+		if attempts < 2 {
+			return db.ClassifyError(&pgconn.PgError{Code: "40001"})
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	// Non-retryable failures are returned immediately
+	attempts = 0
+	err = db.WithRetry(context.Background(), func() error {
+		attempts++
+		return errors.ConflictError("resource already exists")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+
+	// Exhausted retries are mapped to an unavailable DomainError
+	attempts = 0
+	err = db.WithRetry(context.Background(), func() error {
+		attempts++
+		return db.ClassifyError(&pgconn.PgError{Code: "40P01"})
+	})
+	assert.Equal(t, db.DefaultRetryAttempts, attempts)
+	var domainErr *errors.DomainError
+	require.True(t, stderrors.As(err, &domainErr))
+	assert.Equal(t, errors.ErrCodeUnavailable, domainErr.Code)
+}
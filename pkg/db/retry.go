@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	stderrors "errors"
+	"math/rand"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRetryAttempts is the number of attempts made by WithRetry
+	DefaultRetryAttempts = 3
+
+	retryBaseDelay = 50 * time.Millisecond
+)
+
+// WithRetry runs fn and retries it with exponential backoff while it fails
+// with a retryable persistence error. When retries are exhausted the error is
+// mapped to a DomainError.
+func WithRetry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= DefaultRetryAttempts; attempt++ {
+		err = fn()
+		if err == nil || !errors.IsRetryable(err) {
+			return err
+		}
+
+		if attempt == DefaultRetryAttempts {
+			break
+		}
+
+		delay := retryBaseDelay << (attempt - 1)
+		delay += time.Duration(rand.Int63n(int64(delay)))
+
+		logger.Warn("Retrying database operation",
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	var persistenceErr *errors.PersistenceError
+	if stderrors.As(err, &persistenceErr) {
+		return persistenceErr.ToDomainError()
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"dongome/pkg/config"
+	"dongome/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// LoggerConfig returns the logger settings of a service, naming it on every
+// entry when logging in the ecs format
+func LoggerConfig(service string, cfg *config.Config) logger.Config {
+	return logger.Config{
+		Format:         cfg.Log.Format,
+		Level:          cfg.Log.Level,
+		ServiceName:    service,
+		ServiceVersion: Version,
+		Environment:    cfg.Server.Mode,
+	}
+}
+
+// BannerFields returns structured startup fields describing the running service.
+// Secrets are never included.
+func BannerFields(service string, cfg *config.Config) []zap.Field {
+	hostname, _ := os.Hostname()
+	return []zap.Field{
+		zap.String("service", service),
+		zap.String("version", Version),
+		zap.String("go_version", runtime.Version()),
+		zap.String("hostname", hostname),
+		zap.Int("pid", os.Getpid()),
+		zap.String("mode", cfg.Server.Mode),
+		zap.String("port", cfg.Server.Port),
+		zap.String("database", fmt.Sprintf("%s:%s/%s", cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)),
+		zap.String("redis", fmt.Sprintf("%s:%s/%d", cfg.Redis.Host, cfg.Redis.Port, cfg.Redis.DB)),
+		zap.String("nats_url", cfg.NATS.URL),
+		zap.String("internal_port", cfg.Internal.Port),
+		zap.Bool("internal_mtls", cfg.Internal.TLS.Enabled),
+	}
+}
+
+// RunSelfCheck runs the standard dependency checks for a service, writes the
+// report to w and returns the process exit code
+func RunSelfCheck(service string, cfg *config.Config, eventTypes []string, format string, w io.Writer) int {
+	runner := NewRunner(service)
+	runner.Add("config", ConfigCheck(cfg))
+	runner.Add("database", DatabaseCheck(&cfg.Database))
+	runner.Add("migrations", MigrationsCheck(&cfg.Database))
+	runner.Add("redis", RedisCheck(&cfg.Redis))
+	runner.Add("nats", NATSCheck(cfg.NATS.URL, eventTypes))
+	runner.Add("mtls", MTLSCheck(&cfg.Internal.TLS))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	report := runner.Run(ctx)
+
+	var err error
+	if format == "json" {
+		err = report.WriteJSON(w)
+	} else {
+		err = report.WriteText(w)
+	}
+	if err != nil {
+		return ExitFailed
+	}
+
+	return report.ExitCode()
+}
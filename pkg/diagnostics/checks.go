@@ -0,0 +1,233 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"dongome/pkg/cache"
+	"dongome/pkg/config"
+	"dongome/pkg/db"
+	"dongome/pkg/events"
+	"dongome/pkg/mtls"
+
+	"github.com/nats-io/nats.go"
+)
+
+// migrationFilePattern matches golang-migrate up migration files
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_.+\.up\.sql$`)
+
+// ConfigCheck validates the loaded configuration
+func ConfigCheck(cfg *config.Config) CheckFunc {
+	return func(ctx context.Context) Result {
+		if err := cfg.Validate(); err != nil {
+			return fail(err)
+		}
+		result := ok("configuration is valid")
+		result.Details = map[string]interface{}{
+			"mode": cfg.Server.Mode,
+			"port": cfg.Server.Port,
+		}
+		return result
+	}
+}
+
+// DatabaseCheck verifies the database is reachable
+func DatabaseCheck(cfg *config.DatabaseConfig) CheckFunc {
+	return func(ctx context.Context) Result {
+		database, err := db.NewDatabase(cfg)
+		if err != nil {
+			return fail(err)
+		}
+		defer database.Close()
+
+		sqlDB, err := database.DB.DB()
+		if err != nil {
+			return fail(err)
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return fail(err)
+		}
+
+		result := ok("database is reachable")
+		result.Details = map[string]interface{}{
+			"host": cfg.Host,
+			"name": cfg.Name,
+		}
+		return result
+	}
+}
+
+// MigrationsCheck detects migrations that have not been applied yet
+func MigrationsCheck(cfg *config.DatabaseConfig) CheckFunc {
+	return func(ctx context.Context) Result {
+		latest, err := latestMigrationVersion(cfg.MigrationsPath)
+		if err != nil {
+			return warn(fmt.Sprintf("cannot read migrations: %v", err))
+		}
+
+		database, err := db.NewDatabase(cfg)
+		if err != nil {
+			return fail(err)
+		}
+		defer database.Close()
+
+		if !database.DB.Migrator().HasTable("schema_migrations") {
+			return warn("no migration history found")
+		}
+
+		var state struct {
+			Version int64
+			Dirty   bool
+		}
+		err = database.DB.WithContext(ctx).
+			Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").
+			Scan(&state).Error
+		if err != nil {
+			return fail(err)
+		}
+
+		details := map[string]interface{}{
+			"applied": state.Version,
+			"latest":  latest,
+		}
+
+		var result Result
+		switch {
+		case state.Dirty:
+			result = fail(fmt.Errorf("migration %d is dirty", state.Version))
+		case state.Version < latest:
+			result = fail(fmt.Errorf("%d pending migration(s)", latest-state.Version))
+		default:
+			result = ok("schema is up to date")
+		}
+		result.Details = details
+		return result
+	}
+}
+
+// RedisCheck verifies the cache is reachable
+func RedisCheck(cfg *config.RedisConfig) CheckFunc {
+	return func(ctx context.Context) Result {
+		redisCache, err := cache.NewRedisCache(ctx, cfg)
+		if err != nil {
+			return fail(err)
+		}
+		defer redisCache.Close()
+
+		result := ok("redis is reachable")
+		result.Details = map[string]interface{}{
+			"address": fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+			"db":      cfg.DB,
+		}
+		return result
+	}
+}
+
+// NATSCheck verifies the event stream and the durable consumers of the given event types
+func NATSCheck(url string, eventTypes []string) CheckFunc {
+	return func(ctx context.Context) Result {
+		conn, err := nats.Connect(url, nats.Timeout(5*time.Second))
+		if err != nil {
+			return fail(err)
+		}
+		defer conn.Close()
+
+		js, err := conn.JetStream(nats.Context(ctx))
+		if err != nil {
+			return fail(err)
+		}
+
+		info, err := js.StreamInfo(events.StreamName)
+		if err != nil {
+			return fail(fmt.Errorf("stream %s: %w", events.StreamName, err))
+		}
+
+		var missing []string
+		for _, eventType := range eventTypes {
+			if _, err := js.ConsumerInfo(events.StreamName, events.DurableName(eventType)); err != nil {
+				missing = append(missing, events.DurableName(eventType))
+			}
+		}
+
+		details := map[string]interface{}{
+			"stream":    events.StreamName,
+			"messages":  info.State.Msgs,
+			"consumers": info.State.Consumers,
+		}
+
+		var result Result
+		if len(missing) > 0 {
+			// Consumers are created on first subscription, so a fresh deploy is not an error
+			result = warn(fmt.Sprintf("%d consumer(s) not created yet", len(missing)))
+			details["missing_consumers"] = missing
+		} else {
+			result = ok("stream and consumers exist")
+		}
+		result.Details = details
+		return result
+	}
+}
+
+// certExpiryWarning is how long before expiry a service certificate is reported
+const certExpiryWarning = 14 * 24 * time.Hour
+
+// MTLSCheck verifies the internal TLS certificates load and are not about to expire
+func MTLSCheck(cfg *config.InternalTLSConfig) CheckFunc {
+	return func(ctx context.Context) Result {
+		if !cfg.Enabled {
+			return ok("mTLS is disabled")
+		}
+
+		store, err := mtls.NewCertStore(cfg, mtls.NewMetrics())
+		if err != nil {
+			return fail(err)
+		}
+
+		leaf := store.Certificate().Leaf
+		details := map[string]interface{}{
+			"identity":   mtls.Identity(leaf),
+			"expires_at": leaf.NotAfter,
+		}
+
+		var result Result
+		switch remaining := time.Until(leaf.NotAfter); {
+		case remaining <= 0:
+			result = fail(fmt.Errorf("certificate expired at %s", leaf.NotAfter.Format(time.RFC3339)))
+		case remaining < certExpiryWarning:
+			result = warn(fmt.Sprintf("certificate expires in %s", remaining.Round(time.Hour)))
+		default:
+			result = ok("certificates are valid")
+		}
+		result.Details = details
+		return result
+	}
+}
+
+// latestMigrationVersion returns the highest migration version found in dir
+func latestMigrationVersion(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		matches := migrationFilePattern.FindStringSubmatch(filepath.Base(entry.Name()))
+		if matches == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest, nil
+}
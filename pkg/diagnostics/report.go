@@ -0,0 +1,143 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+)
+
+// Version is the application version reported by health checks and banners
+var Version = "1.0.0"
+
+// Status represents the outcome of a diagnostic check
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Exit codes returned by the self-check mode
+const (
+	ExitOK       = 0
+	ExitFailed   = 1
+	ExitDegraded = 2
+)
+
+// Result represents the outcome of a single check
+type Result struct {
+	Name       string                 `json:"name"`
+	Status     Status                 `json:"status"`
+	Message    string                 `json:"message"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+}
+
+// CheckFunc runs a single diagnostic check
+type CheckFunc func(ctx context.Context) Result
+
+// Report holds the results of a self-check run
+type Report struct {
+	Service   string    `json:"service"`
+	Version   string    `json:"version"`
+	GoVersion string    `json:"go_version"`
+	Hostname  string    `json:"hostname"`
+	Status    Status    `json:"status"`
+	Results   []Result  `json:"results"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Runner executes registered checks in order
+type Runner struct {
+	service string
+	names   []string
+	checks  []CheckFunc
+}
+
+// NewRunner creates a new check runner for a service
+func NewRunner(service string) *Runner {
+	return &Runner{service: service}
+}
+
+// Add registers a check
+func (r *Runner) Add(name string, check CheckFunc) {
+	r.names = append(r.names, name)
+	r.checks = append(r.checks, check)
+}
+
+// Run executes all checks and returns the report
+func (r *Runner) Run(ctx context.Context) *Report {
+	hostname, _ := os.Hostname()
+	report := &Report{
+		Service:   r.service,
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		Hostname:  hostname,
+		Status:    StatusOK,
+		StartedAt: time.Now().UTC(),
+	}
+
+	for i, check := range r.checks {
+		start := time.Now()
+		result := check(ctx)
+		result.Name = r.names[i]
+		result.DurationMs = time.Since(start).Milliseconds()
+		report.Results = append(report.Results, result)
+
+		switch {
+		case result.Status == StatusFail:
+			report.Status = StatusFail
+		case result.Status == StatusWarn && report.Status == StatusOK:
+			report.Status = StatusWarn
+		}
+	}
+
+	return report
+}
+
+// ExitCode maps the report status to a process exit code
+func (r *Report) ExitCode() int {
+	switch r.Status {
+	case StatusFail:
+		return ExitFailed
+	case StatusWarn:
+		return ExitDegraded
+	default:
+		return ExitOK
+	}
+}
+
+// WriteJSON writes the report as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report in a human readable format
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "%s %s (%s) on %s\n", r.Service, r.Version, r.GoVersion, r.Hostname)
+	for _, result := range r.Results {
+		fmt.Fprintf(w, "  [%-4s] %-12s %s (%dms)\n", result.Status, result.Name, result.Message, result.DurationMs)
+	}
+	_, err := fmt.Fprintf(w, "status: %s\n", r.Status)
+	return err
+}
+
+// ok, warn and fail build check results
+func ok(message string) Result {
+	return Result{Status: StatusOK, Message: message}
+}
+
+func warn(message string) Result {
+	return Result{Status: StatusWarn, Message: message}
+}
+
+func fail(err error) Result {
+	return Result{Status: StatusFail, Message: err.Error()}
+}
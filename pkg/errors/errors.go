@@ -16,6 +16,8 @@ const (
 	ErrCodeForbidden      ErrorCode = "FORBIDDEN"
 	ErrCodeConflict       ErrorCode = "CONFLICT"
 	ErrCodeInternalServer ErrorCode = "INTERNAL_SERVER_ERROR"
+	ErrCodeUnavailable    ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrCodeRateLimited    ErrorCode = "RATE_LIMITED"
 
 	// User domain errors
 	ErrCodeUserNotFound       ErrorCode = "USER_NOT_FOUND"
@@ -76,6 +78,10 @@ func (e *DomainError) HTTPStatusCode() int {
 		return http.StatusForbidden
 	case ErrCodeConflict, ErrCodeEmailExists:
 		return http.StatusConflict
+	case ErrCodeUnavailable:
+		return http.StatusServiceUnavailable
+	case ErrCodeRateLimited:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
@@ -94,6 +100,10 @@ func UnauthorizedError(message string) *DomainError {
 	return NewDomainError(ErrCodeUnauthorized, message)
 }
 
+func ForbiddenError(message string) *DomainError {
+	return NewDomainError(ErrCodeForbidden, message)
+}
+
 func ConflictError(message string) *DomainError {
 	return NewDomainError(ErrCodeConflict, message)
 }
@@ -101,3 +111,11 @@ func ConflictError(message string) *DomainError {
 func InternalError(message string) *DomainError {
 	return NewDomainError(ErrCodeInternalServer, message)
 }
+
+func UnavailableError(message string) *DomainError {
+	return NewDomainError(ErrCodeUnavailable, message)
+}
+
+func RateLimitedError(message string) *DomainError {
+	return NewDomainError(ErrCodeRateLimited, message)
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PersistenceErrorKind classifies failures reported by the database
+type PersistenceErrorKind string
+
+const (
+	PersistenceSerializationFailure PersistenceErrorKind = "serialization_failure"
+	PersistenceDeadlock             PersistenceErrorKind = "deadlock"
+	PersistenceLockTimeout          PersistenceErrorKind = "lock_timeout"
+	PersistenceConnectionLost       PersistenceErrorKind = "connection_lost"
+	PersistenceUniqueViolation      PersistenceErrorKind = "unique_violation"
+	PersistenceForeignKeyViolation  PersistenceErrorKind = "foreign_key_violation"
+	PersistenceCheckViolation       PersistenceErrorKind = "check_violation"
+	PersistenceUnknown              PersistenceErrorKind = "unknown"
+)
+
+// PersistenceError represents a classified database failure
+type PersistenceError struct {
+	Kind PersistenceErrorKind
+	Err  error
+}
+
+func (e *PersistenceError) Error() string {
+	return fmt.Sprintf("persistence error (%s): %v", e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying driver error
+func (e *PersistenceError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the operation may succeed if attempted again
+func (e *PersistenceError) Retryable() bool {
+	switch e.Kind {
+	case PersistenceSerializationFailure, PersistenceDeadlock, PersistenceLockTimeout, PersistenceConnectionLost:
+		return true
+	default:
+		return false
+	}
+}
+
+// ToDomainError maps the failure to a DomainError that is safe to return to clients
+func (e *PersistenceError) ToDomainError() *DomainError {
+	switch e.Kind {
+	case PersistenceUniqueViolation:
+		return ConflictError("resource already exists")
+	case PersistenceForeignKeyViolation:
+		return ValidationError("referenced resource does not exist")
+	case PersistenceCheckViolation:
+		return ValidationError("value is not allowed")
+	case PersistenceSerializationFailure, PersistenceDeadlock, PersistenceLockTimeout, PersistenceConnectionLost:
+		return UnavailableError("service temporarily unavailable, please retry")
+	default:
+		return InternalError("database error")
+	}
+}
+
+// NewPersistenceError creates a new persistence error
+func NewPersistenceError(kind PersistenceErrorKind, err error) *PersistenceError {
+	return &PersistenceError{
+		Kind: kind,
+		Err:  err,
+	}
+}
+
+// IsRetryable reports whether err is a transient persistence failure
+func IsRetryable(err error) bool {
+	var persistenceErr *PersistenceError
+	if errors.As(err, &persistenceErr) {
+		return persistenceErr.Retryable()
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"dongome/pkg/logger"
@@ -12,6 +13,13 @@ import (
 	"go.uber.org/zap"
 )
 
+// StreamName is the JetStream stream that carries all domain events
+const StreamName = "DOMAIN_EVENTS"
+
+// MetadataTraceID is the metadata key carrying the trace ID of the request
+// that caused an event, so the handlers' logs can be tied back to it
+const MetadataTraceID = "trace_id"
+
 // Event represents a domain event
 type Event struct {
 	ID          string            `json:"id"`
@@ -53,11 +61,10 @@ func NewNATSEventBus(url string) (*NATSEventBus, error) {
 	}
 
 	// Create stream if it doesn't exist
-	streamName := "DOMAIN_EVENTS"
-	_, err = js.StreamInfo(streamName)
+	_, err = js.StreamInfo(StreamName)
 	if err == nats.ErrStreamNotFound {
 		_, err = js.AddStream(&nats.StreamConfig{
-			Name:     streamName,
+			Name:     StreamName,
 			Subjects: []string{"events.>"},
 			Storage:  nats.FileStorage,
 			Replicas: 1,
@@ -86,6 +93,12 @@ func (eb *NATSEventBus) Publish(ctx context.Context, event *Event) error {
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now()
 	}
+	if traceID := logger.TraceIDFromContext(ctx); traceID != "" && event.Metadata[MetadataTraceID] == "" {
+		if event.Metadata == nil {
+			event.Metadata = make(map[string]string)
+		}
+		event.Metadata[MetadataTraceID] = traceID
+	}
 
 	// Serialize event
 	data, err := json.Marshal(event)
@@ -98,16 +111,18 @@ func (eb *NATSEventBus) Publish(ctx context.Context, event *Event) error {
 	_, err = eb.js.PublishAsync(subject, data)
 	if err != nil {
 		logger.Error("Failed to publish event",
-			zap.String("event_id", event.ID),
-			zap.String("event_type", event.Type),
+			logger.EventID(event.ID),
+			logger.EventAction(event.Type),
+			logger.TraceID(ctx),
 			zap.Error(err))
 		return err
 	}
 
 	logger.Info("Event published",
-		zap.String("event_id", event.ID),
-		zap.String("event_type", event.Type),
-		zap.String("aggregate_id", event.AggregateID))
+		logger.EventID(event.ID),
+		logger.EventAction(event.Type),
+		logger.AggregateID(event.AggregateID),
+		logger.TraceID(ctx))
 
 	return nil
 }
@@ -127,31 +142,38 @@ func (eb *NATSEventBus) Subscribe(eventType string, handler EventHandler) error
 			return
 		}
 
-		// Handle event with timeout context
+		// Handle event with timeout context, carrying the trace ID of the
+		// request that caused it
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
+		ctx = logger.ContextWithTraceID(ctx, event.Metadata[MetadataTraceID])
+		start := time.Now()
 
 		if err := handler(ctx, &event); err != nil {
 			logger.Error("Event handler failed",
-				zap.String("event_id", event.ID),
-				zap.String("event_type", event.Type),
+				logger.EventID(event.ID),
+				logger.EventAction(event.Type),
+				logger.TraceID(ctx),
+				zap.Duration(logger.FieldEventDuration, time.Since(start)),
 				zap.Error(err))
 			msg.Nak()
 			return
 		}
 
 		logger.Info("Event handled successfully",
-			zap.String("event_id", event.ID),
-			zap.String("event_type", event.Type))
+			logger.EventID(event.ID),
+			logger.EventAction(event.Type),
+			logger.TraceID(ctx),
+			zap.Duration(logger.FieldEventDuration, time.Since(start)))
 
 		msg.Ack()
-	}, nats.Durable("dongome-"+eventType))
+	}, nats.Durable(DurableName(eventType)))
 
 	if err != nil {
 		return err
 	}
 
-	logger.Info("Subscribed to event type", zap.String("event_type", eventType))
+	logger.Info("Subscribed to event type", logger.EventAction(eventType))
 	return nil
 }
 
@@ -163,6 +185,12 @@ func (eb *NATSEventBus) Close() error {
 	return nil
 }
 
+// DurableName returns the durable consumer name used for an event type.
+// JetStream does not allow dots in durable names.
+func DurableName(eventType string) string {
+	return "dongome-" + strings.ReplaceAll(eventType, ".", "_")
+}
+
 // NewEvent creates a new event
 func NewEvent(eventType string, aggregateID string, data interface{}) (*Event, error) {
 	dataBytes, err := json.Marshal(data)
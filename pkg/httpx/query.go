@@ -0,0 +1,135 @@
+// Package httpx binds the query parameters list and search endpoints share
+// into the query structs of the application services.
+package httpx
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"dongome/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// DefaultLimit is the page size services use when a query does not give one.
+// Pages are counted in it when a request gives page without limit.
+const DefaultLimit = 20
+
+// Operators filters may use, as in price[gte]=100
+const (
+	OpEq  = "eq"
+	OpGte = "gte"
+	OpLte = "lte"
+)
+
+// filterKey matches filter parameters such as price[gte]
+var filterKey = regexp.MustCompile(`^([a-z_]+)\[([a-z]+)\]$`)
+
+// BindQuery binds a request's query string into a struct with form and
+// binding tags, like gin's ShouldBindQuery, and adds what list endpoints share:
+//
+//   - page, counted from 1 in pages of limit, is an alternative to offset
+//   - field[op]=value sets the struct field tagged filter:"field,op", so
+//     price[gte]=100 can stand for min_price=100
+//
+// Values set through pages and filters are validated by the field's binding
+// tags, so limits keep their caps and sorts their allowed values. Filters the
+// struct does not declare are rejected rather than ignored.
+func BindQuery(c *gin.Context, obj interface{}) error {
+	values := c.Request.URL.Query()
+
+	if err := applyFilters(values, obj); err != nil {
+		return err
+	}
+	if err := applyPage(values); err != nil {
+		return err
+	}
+
+	if err := binding.MapFormWithTag(obj, values, "form"); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// applyFilters rewrites the filter parameters into the form parameters of the
+// fields they set
+func applyFilters(values url.Values, obj interface{}) error {
+	fields := filterFields(reflect.TypeOf(obj))
+	for key, value := range values {
+		match := filterKey.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		name, ok := fields[match[1]+","+match[2]]
+		if !ok {
+			return errors.ValidationError(fmt.Sprintf("%s is not a supported filter", key))
+		}
+		if _, ok := values[name]; ok {
+			return errors.ValidationError(fmt.Sprintf("%s and %s cannot be combined", key, name))
+		}
+		delete(values, key)
+		values[name] = value
+	}
+	return nil
+}
+
+// filterFields maps the filters a struct declares, as "field,op", to the
+// form names of the fields they set. Filters without an operator are equality
+// filters.
+func filterFields(t reflect.Type) map[string]string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	fields := make(map[string]string)
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		filter, ok := field.Tag.Lookup("filter")
+		if !ok {
+			continue
+		}
+		name, op, found := strings.Cut(filter, ",")
+		if !found {
+			op = OpEq
+		}
+		form, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		fields[name+","+op] = form
+	}
+	return fields
+}
+
+// applyPage rewrites page into the offset of that page
+func applyPage(values url.Values) error {
+	if !values.Has("page") {
+		return nil
+	}
+	if values.Has("offset") {
+		return errors.ValidationError("page and offset cannot be combined")
+	}
+	if values.Has("cursor") {
+		return errors.ValidationError("cursor and page cannot be combined")
+	}
+
+	page, err := strconv.Atoi(values.Get("page"))
+	if err != nil || page < 1 {
+		return errors.ValidationError("page must be at least 1")
+	}
+	limit := DefaultLimit
+	if values.Has("limit") {
+		// An invalid limit is reported when the struct is bound
+		if l, err := strconv.Atoi(values.Get("limit")); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	values.Del("page")
+	values.Set("offset", strconv.Itoa((page-1)*limit))
+	return nil
+}
@@ -0,0 +1,83 @@
+package httpx_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"dongome/pkg/httpx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listQuery struct {
+	MinPrice *float64 `form:"min_price" filter:"price,gte" binding:"omitempty,min=0"`
+	MaxPrice *float64 `form:"max_price" filter:"price,lte" binding:"omitempty,min=0"`
+	Status   string   `form:"status" filter:"status" binding:"omitempty,oneof=active sold"`
+	Sort     string   `form:"sort" binding:"omitempty,oneof=newest price_asc"`
+	Limit    int      `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset   int      `form:"offset" binding:"omitempty,min=0"`
+	Cursor   string   `form:"cursor"`
+}
+
+func bind(t *testing.T, rawQuery string) (listQuery, error) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/items?"+rawQuery, nil)
+
+	var query listQuery
+	err := httpx.BindQuery(c, &query)
+	return query, err
+}
+
+func TestBindQuery(t *testing.T) {
+	query, err := bind(t, "min_price=5&sort=newest&limit=10&offset=30")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, *query.MinPrice)
+	assert.Nil(t, query.MaxPrice)
+	assert.Equal(t, "newest", query.Sort)
+	assert.Equal(t, 10, query.Limit)
+	assert.Equal(t, 30, query.Offset)
+
+	_, err = bind(t, "sort=oldest")
+	assert.Error(t, err, "sorts are checked against the allowed values")
+	_, err = bind(t, "limit=500")
+	assert.Error(t, err, "limits keep their cap")
+}
+
+func TestBindQuery_Filters(t *testing.T) {
+	query, err := bind(t, "price[gte]=100&price[lte]=250&status[eq]=sold")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, *query.MinPrice)
+	assert.Equal(t, 250.0, *query.MaxPrice)
+	assert.Equal(t, "sold", query.Status)
+
+	for _, rawQuery := range []string{
+		"price[gt]=100",               // not declared
+		"views[gte]=3",                // not declared
+		"price[gte]=100&min_price=50", // the same field twice
+		"price[gte]=-1",               // validated like min_price
+		"price[gte]=cheap",            // not a number
+		"status[eq]=draft",            // validated like status
+	} {
+		_, err := bind(t, rawQuery)
+		assert.Error(t, err, rawQuery)
+	}
+}
+
+func TestBindQuery_Page(t *testing.T) {
+	query, err := bind(t, "page=3&limit=10")
+	require.NoError(t, err)
+	assert.Equal(t, 20, query.Offset)
+
+	query, err = bind(t, "page=2")
+	require.NoError(t, err)
+	assert.Equal(t, httpx.DefaultLimit, query.Offset)
+
+	for _, rawQuery := range []string{"page=0", "page=two", "page=2&offset=10", "page=2&cursor=abc"} {
+		_, err := bind(t, rawQuery)
+		assert.Error(t, err, rawQuery)
+	}
+}
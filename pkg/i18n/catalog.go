@@ -0,0 +1,125 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Translations are keyed by the English message. Keys containing verbs such
+// as %s or %d are templates: the text each verb matched is put, in order, in
+// place of the verbs of the translation.
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// verbPattern matches the formatting verbs allowed in template keys
+var verbPattern = regexp.MustCompile(`%[sdqv]`)
+
+// template is a translation of messages built with fmt
+type template struct {
+	pattern     *regexp.Regexp
+	translation string
+}
+
+// catalog holds the translations of one locale
+type catalog struct {
+	messages  map[string]string
+	templates []template
+}
+
+// catalogs holds the translations of every locale but DefaultLocale
+var catalogs = mustLoadCatalogs()
+
+// Translate returns a message in the given locale. Messages without a
+// translation are returned in English.
+func Translate(locale Locale, message string) string {
+	c, ok := catalogs[locale]
+	if !ok {
+		return message
+	}
+	if translated, ok := c.messages[message]; ok {
+		return translated
+	}
+	for _, t := range c.templates {
+		match := t.pattern.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		args := match[1:]
+		return verbPattern.ReplaceAllStringFunc(t.translation, func(string) string {
+			if len(args) == 0 {
+				return ""
+			}
+			arg := args[0]
+			args = args[1:]
+			return arg
+		})
+	}
+	return message
+}
+
+// mustLoadCatalogs reads the embedded locale files. They ship with the
+// binary, so a broken file is a programming error.
+func mustLoadCatalogs() map[Locale]*catalog {
+	catalogs := make(map[Locale]*catalog)
+	for _, locale := range SupportedLocales {
+		if locale == DefaultLocale {
+			continue
+		}
+		data, err := localeFiles.ReadFile(path.Join("locales", string(locale)+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", locale, err))
+		}
+		c, err := parseCatalog(data)
+		if err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", locale, err))
+		}
+		catalogs[locale] = c
+	}
+	return catalogs
+}
+
+// parseCatalog builds a catalog from a JSON object of translations
+func parseCatalog(data []byte) (*catalog, error) {
+	var entries map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	c := &catalog{messages: make(map[string]string, len(entries))}
+	keys := make([]string, 0, len(entries))
+	for key, translation := range entries {
+		if !verbPattern.MatchString(key) {
+			c.messages[key] = translation
+			continue
+		}
+		if len(verbPattern.FindAllString(key, -1)) != len(verbPattern.FindAllString(translation, -1)) {
+			return nil, fmt.Errorf("%q and its translation use different numbers of verbs", key)
+		}
+		keys = append(keys, key)
+	}
+
+	// Try longer templates first, so the most specific one wins
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		literals := verbPattern.Split(key, -1)
+		for i, literal := range literals {
+			literals[i] = regexp.QuoteMeta(literal)
+		}
+		c.templates = append(c.templates, template{
+			pattern:     regexp.MustCompile("^" + strings.Join(literals, "(.+?)") + "$"),
+			translation: entries[key],
+		})
+	}
+	return c, nil
+}
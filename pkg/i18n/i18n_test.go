@@ -0,0 +1,99 @@
+package i18n_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocale(t *testing.T) {
+	locale, ok := i18n.ParseLocale("fr-CI")
+	require.True(t, ok)
+	assert.Equal(t, i18n.French, locale)
+
+	locale, ok = i18n.ParseLocale("ak")
+	require.True(t, ok)
+	assert.Equal(t, i18n.Twi, locale, "Akan stands for Twi")
+
+	_, ok = i18n.ParseLocale("ha")
+	assert.False(t, ok)
+}
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, i18n.French, i18n.Negotiate("de-DE, fr;q=0.8, en;q=0.5"))
+	assert.Equal(t, i18n.Twi, i18n.Negotiate("en;q=0.4, tw"))
+	assert.Equal(t, i18n.English, i18n.Negotiate("fr;q=0, en-GB;q=0.1"))
+	assert.Equal(t, i18n.DefaultLocale, i18n.Negotiate(""))
+	assert.Equal(t, i18n.DefaultLocale, i18n.Negotiate("*, ha"))
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "utilisateur introuvable", i18n.Translate(i18n.French, "user not found"))
+	assert.Equal(t, "user not found", i18n.Translate(i18n.English, "user not found"))
+
+	// Messages built with fmt are matched against templates
+	assert.Equal(t, "price doit contenir au plus 10 caractères", i18n.Translate(i18n.French, "price must be at most 10 characters"))
+	assert.Equal(t, "price doit être au plus égal à 10", i18n.Translate(i18n.French, "price must be at most 10"))
+	assert.Equal(t, "région inconnue : Upper North", i18n.Translate(i18n.French, "unknown region Upper North"))
+
+	// Untranslated messages stay in English
+	assert.Equal(t, "something new happened", i18n.Translate(i18n.Twi, "something new happened"))
+}
+
+type bindingRequest struct {
+	BusinessName string   `json:"business_name" binding:"required"`
+	Email        string   `json:"email" binding:"omitempty,email"`
+	ImageIDs     []string `json:"image_ids" binding:"omitempty,max=2"`
+}
+
+func TestBindingError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(i18n.Middleware())
+	router.POST("/things", func(c *gin.Context) {
+		var req bindingRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	post := func(body, acceptLanguage string) (string, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", acceptLanguage)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var response struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &response)
+		return response.Error, rec
+	}
+
+	message, rec := post(`{}`, "en")
+	assert.Equal(t, "business_name is required", message)
+	assert.Equal(t, "en", rec.Header().Get("Content-Language"))
+
+	message, rec = post(`{}`, "fr-FR")
+	assert.Equal(t, "business_name est obligatoire", message)
+	assert.Equal(t, "fr", rec.Header().Get("Content-Language"))
+
+	message, _ = post(`{"business_name": "Ama's", "image_ids": ["a", "b", "c"]}`, "en")
+	assert.Equal(t, "image_ids must be at most 2 items", message)
+
+	message, _ = post(`{"business_name": "Ama's", "email": "nope"}`, "tw")
+	assert.Equal(t, "email no nyɛ email address a ɛteɛ", message)
+
+	message, _ = post(`{`, "fr")
+	assert.Equal(t, "la requête est invalide", message)
+}
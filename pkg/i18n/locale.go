@@ -0,0 +1,90 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale identifies a language API responses can be returned in
+type Locale string
+
+const (
+	English Locale = "en"
+	Twi     Locale = "tw"
+	French  Locale = "fr"
+)
+
+// DefaultLocale is used when neither the user nor the request names a
+// supported locale. Messages are written in it, so it needs no catalog.
+const DefaultLocale = English
+
+// SupportedLocales lists the locales API responses are translated into
+var SupportedLocales = []Locale{English, Twi, French}
+
+// aliases maps other language tags to the supported locale they stand for.
+// Twi is a dialect of Akan, so clients may send either.
+var aliases = map[string]Locale{
+	"ak":  Twi,
+	"twi": Twi,
+}
+
+// ParseLocale returns the supported locale a language tag such as "fr-CI"
+// or "tw" stands for
+func ParseLocale(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if locale, ok := aliases[tag]; ok {
+		return locale, true
+	}
+	for _, locale := range SupportedLocales {
+		if string(locale) == tag {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the supported locale the client prefers most from an
+// Accept-Language header, falling back to DefaultLocale
+func Negotiate(acceptLanguage string) Locale {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			value, found := strings.CutPrefix(strings.TrimSpace(param), "q=")
+			if !found {
+				continue
+			}
+			if q, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = q
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	for _, c := range candidates {
+		if locale, ok := ParseLocale(c.tag); ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
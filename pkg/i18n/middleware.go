@@ -0,0 +1,128 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	apperrors "dongome/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ContextLocale is the context key of the locale responses are written in
+const ContextLocale = "i18n.locale"
+
+// Middleware picks the response locale from the Accept-Language header.
+// Authenticated routes may replace it with the user's stored locale.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Language")
+		SetLocale(c, Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// SetLocale sets the locale the rest of the request is answered in
+func SetLocale(c *gin.Context, locale Locale) {
+	c.Set(ContextLocale, locale)
+	c.Header("Content-Language", string(locale))
+}
+
+// FromContext returns the locale the request is answered in
+func FromContext(c *gin.Context) Locale {
+	if locale, ok := c.Value(ContextLocale).(Locale); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Localize translates a message into the locale the request is answered in
+func Localize(c *gin.Context, message string) string {
+	return Translate(FromContext(c), message)
+}
+
+// BindingError describes why a request could not be bound, in the locale the
+// request is answered in. Only the first invalid field is reported.
+func BindingError(c *gin.Context, err error) string {
+	var domainErr *apperrors.DomainError
+	if errors.As(err, &domainErr) {
+		return Localize(c, domainErr.Message)
+	}
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) || len(fieldErrors) == 0 {
+		return Localize(c, "request is invalid")
+	}
+	return Localize(c, fieldMessage(fieldErrors[0]))
+}
+
+// fieldMessage describes a failed validation rule in English
+func fieldMessage(fe validator.FieldError) string {
+	field := fieldName(fe.Field())
+	param := fe.Param()
+
+	switch fe.Tag() {
+	case "required", "required_if", "required_unless":
+		return fmt.Sprintf("%s is required", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "uuid":
+		return fmt.Sprintf("%s must be a valid ID", field)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, strings.Join(strings.Fields(param), ", "))
+	case "min", "max", "len":
+		return lengthMessage(fe.Tag(), field, param, fe.Kind().String())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "gte":
+		return fmt.Sprintf("%s must be at least %s", field, param)
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, param)
+	case "lte":
+		return fmt.Sprintf("%s must be at most %s", field, param)
+	default:
+		return fmt.Sprintf("%s is invalid", field)
+	}
+}
+
+// lengthMessage describes a min, max or len rule, which limit the length of
+// strings, the size of lists and the value of numbers
+func lengthMessage(tag, field, param, kind string) string {
+	unit := ""
+	switch kind {
+	case "string":
+		unit = " characters"
+	case "slice", "array", "map":
+		unit = " items"
+	}
+
+	switch tag {
+	case "min":
+		return fmt.Sprintf("%s must be at least %s%s", field, param, unit)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s%s", field, param, unit)
+	default:
+		return fmt.Sprintf("%s must be exactly %s%s", field, param, unit)
+	}
+}
+
+// fieldName turns a Go field name such as BusinessName or UserID into the
+// snake_case name clients send. Elements of lists keep their index, as in
+// image_ids[0].
+func fieldName(name string) string {
+	runes := []rune(strings.ReplaceAll(name, "IDs", "Ids"))
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,149 @@
+// Package ids defines the identifiers of the marketplace's aggregates. Each is
+// a UUID with its own type, so a user ID cannot be passed where a listing ID
+// is expected. They are stored as uuid columns and marshalled as JSON strings.
+//
+// IDs read from the database, events and caches are trusted. IDs received from
+// clients are validated with the Parse functions or a uuid binding tag.
+package ids
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"dongome/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// UserID identifies a user
+type UserID string
+
+// ListingID identifies a listing
+type ListingID string
+
+// OrderID identifies an order, the transactions context's record of a purchase
+type OrderID string
+
+// NewUserID generates a user ID
+func NewUserID() UserID {
+	return UserID(uuid.New().String())
+}
+
+// ParseUserID validates a user ID received from a client
+func ParseUserID(s string) (UserID, error) {
+	id, err := parse(s, "user id is invalid")
+	return UserID(id), err
+}
+
+// String returns the ID as text
+func (id UserID) String() string {
+	return string(id)
+}
+
+// IsZero reports whether the ID is unset
+func (id UserID) IsZero() bool {
+	return id == ""
+}
+
+// Value stores the ID in a uuid column
+func (id UserID) Value() (driver.Value, error) {
+	return string(id), nil
+}
+
+// Scan reads the ID from a uuid column
+func (id *UserID) Scan(src interface{}) error {
+	s, err := scan(src)
+	*id = UserID(s)
+	return err
+}
+
+// NewListingID generates a listing ID
+func NewListingID() ListingID {
+	return ListingID(uuid.New().String())
+}
+
+// ParseListingID validates a listing ID received from a client
+func ParseListingID(s string) (ListingID, error) {
+	id, err := parse(s, "listing id is invalid")
+	return ListingID(id), err
+}
+
+// String returns the ID as text
+func (id ListingID) String() string {
+	return string(id)
+}
+
+// IsZero reports whether the ID is unset
+func (id ListingID) IsZero() bool {
+	return id == ""
+}
+
+// Value stores the ID in a uuid column
+func (id ListingID) Value() (driver.Value, error) {
+	return string(id), nil
+}
+
+// Scan reads the ID from a uuid column
+func (id *ListingID) Scan(src interface{}) error {
+	s, err := scan(src)
+	*id = ListingID(s)
+	return err
+}
+
+// NewOrderID generates an order ID
+func NewOrderID() OrderID {
+	return OrderID(uuid.New().String())
+}
+
+// ParseOrderID validates an order ID received from a client
+func ParseOrderID(s string) (OrderID, error) {
+	id, err := parse(s, "order id is invalid")
+	return OrderID(id), err
+}
+
+// String returns the ID as text
+func (id OrderID) String() string {
+	return string(id)
+}
+
+// IsZero reports whether the ID is unset
+func (id OrderID) IsZero() bool {
+	return id == ""
+}
+
+// Value stores the ID in a uuid column
+func (id OrderID) Value() (driver.Value, error) {
+	return string(id), nil
+}
+
+// Scan reads the ID from a uuid column
+func (id *OrderID) Scan(src interface{}) error {
+	s, err := scan(src)
+	*id = OrderID(s)
+	return err
+}
+
+// parse returns the canonical form of a UUID, so IDs compare equal whatever
+// case clients send them in
+func parse(s, message string) (string, error) {
+	parsed, err := uuid.Parse(s)
+	if err != nil {
+		return "", errors.ValidationError(message)
+	}
+	return parsed.String(), nil
+}
+
+// scan reads a uuid column, which drivers return as text or raw bytes
+func scan(src interface{}) (string, error) {
+	switch v := src.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case [16]byte:
+		return uuid.UUID(v).String(), nil
+	}
+	return "", fmt.Errorf("cannot scan %T into an ID", src)
+}
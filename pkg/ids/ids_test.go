@@ -0,0 +1,47 @@
+package ids_test
+
+import (
+	"testing"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/ids"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListingID_Canonicalizes(t *testing.T) {
+	id, err := ids.ParseListingID("6F9619FF-8B86-D011-B42D-00C04FC964FF")
+	require.NoError(t, err)
+	assert.Equal(t, ids.ListingID("6f9619ff-8b86-d011-b42d-00c04fc964ff"), id)
+}
+
+func TestParseUserID_RejectsInvalidIDs(t *testing.T) {
+	for _, value := range []string{"", "me", "user-1", "6f9619ff-8b86-d011-b42d"} {
+		_, err := ids.ParseUserID(value)
+		var domainErr *errors.DomainError
+		require.ErrorAs(t, err, &domainErr, value)
+		assert.Equal(t, errors.ErrCodeValidation, domainErr.Code)
+		assert.Equal(t, "user id is invalid", domainErr.Message)
+	}
+}
+
+func TestOrderID_ValueAndScan(t *testing.T) {
+	id := ids.NewOrderID()
+	value, err := id.Value()
+	require.NoError(t, err)
+	assert.Equal(t, id.String(), value)
+
+	var scanned ids.OrderID
+	require.NoError(t, scanned.Scan([]byte(id.String())))
+	assert.Equal(t, id, scanned)
+
+	raw := uuid.MustParse(id.String())
+	require.NoError(t, scanned.Scan([16]byte(raw)))
+	assert.Equal(t, id, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.True(t, scanned.IsZero())
+	assert.Error(t, scanned.Scan(42))
+}
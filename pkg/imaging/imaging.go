@@ -0,0 +1,81 @@
+// Package imaging decodes photos upright, resizes them and encodes them as
+// WebP. Re-encoding drops every piece of metadata the original carried,
+// including the EXIF GPS position phones record.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // register the JPEG decoder
+	_ "image/png"  // register the PNG decoder
+)
+
+// ErrUnsupportedFormat is returned for content that is not an image that can
+// be decoded
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// Decode decodes a JPEG or PNG image, turning JPEGs upright as their EXIF
+// orientation says
+func Decode(data []byte) (image.Image, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+	return img, nil
+}
+
+// Fit scales an image down so its longest edge is at most maxEdge pixels,
+// keeping its proportions. Smaller images are copied at their own size.
+// Each pixel is the average of the pixels it covers, which keeps fine detail
+// from turning into noise.
+func Fit(img image.Image, maxEdge int) *image.NRGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dstW, dstH := srcW, srcH
+	if srcW >= srcH && srcW > maxEdge {
+		dstW, dstH = maxEdge, max(1, srcH*maxEdge/srcW)
+	} else if srcH > srcW && srcH > maxEdge {
+		dstW, dstH = max(1, srcW*maxEdge/srcH), maxEdge
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+			dst.SetNRGBA(x, y, average(img, x0, y0, x1, y1))
+		}
+	}
+	return dst
+}
+
+// average returns the mean colour of the pixels in [x0,x1)x[y0,y1)
+func average(img image.Image, x0, y0, x1, y1 int) color.NRGBA {
+	var r, g, b, a uint64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			pr, pg, pb, pa := img.At(x, y).RGBA()
+			r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+		}
+	}
+	if a == 0 {
+		return color.NRGBA{}
+	}
+	// The sums are alpha-premultiplied, so dividing by the alpha sum
+	// un-premultiplies them
+	n := uint64((x1 - x0) * (y1 - y0))
+	return color.NRGBA{
+		R: uint8(r * 0xff / a),
+		G: uint8(g * 0xff / a),
+		B: uint8(b * 0xff / a),
+		A: uint8(a / n >> 8),
+	}
+}
@@ -0,0 +1,129 @@
+package imaging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"dongome/pkg/imaging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	red  = color.NRGBA{R: 255, A: 255}
+	blue = color.NRGBA{B: 255, A: 255}
+)
+
+// halves builds a 16x8 image, red on the left and blue on the right
+func halves() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			if x < 8 {
+				img.SetNRGBA(x, y, red)
+			} else {
+				img.SetNRGBA(x, y, blue)
+			}
+		}
+	}
+	return img
+}
+
+// withOrientation encodes an image as a JPEG carrying an EXIF orientation
+func withOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 95}))
+
+	// A big-endian TIFF header and a first IFD holding only the orientation
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], 0x0112)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(append(tiff, entry...), 0, 0, 0, 0)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	app1 := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	data := encoded.Bytes()
+	return append(append(append([]byte{}, data[:2]...), append(app1, segment...)...), data[2:]...)
+}
+
+// near checks a decoded colour against the expected one, allowing for JPEG loss
+func near(t *testing.T, want color.NRGBA, got color.Color) {
+	r, g, b, _ := got.RGBA()
+	assert.InDelta(t, want.R, r>>8, 40)
+	assert.InDelta(t, want.G, g>>8, 40)
+	assert.InDelta(t, want.B, b>>8, 40)
+}
+
+func TestDecode_Orientation(t *testing.T) {
+	// Upright photos are left as they are
+	img, err := imaging.Decode(withOrientation(t, halves(), 1))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 16, 8), img.Bounds())
+
+	// Taken with the phone turned clockwise: the left half ends up on top
+	img, err = imaging.Decode(withOrientation(t, halves(), 6))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 8, 16), img.Bounds())
+	near(t, red, img.At(4, 2))
+	near(t, blue, img.At(4, 13))
+
+	// Turned anticlockwise: the left half ends up at the bottom
+	img, err = imaging.Decode(withOrientation(t, halves(), 8))
+	require.NoError(t, err)
+	near(t, blue, img.At(4, 2))
+	near(t, red, img.At(4, 13))
+
+	// Upside down
+	img, err = imaging.Decode(withOrientation(t, halves(), 3))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 16, 8), img.Bounds())
+	near(t, blue, img.At(2, 4))
+	near(t, red, img.At(13, 4))
+
+	_, err = imaging.Decode([]byte("%PDF-1.4"))
+	assert.ErrorIs(t, err, imaging.ErrUnsupportedFormat)
+}
+
+func TestFit(t *testing.T) {
+	// The longest edge is scaled to fit, keeping proportions
+	fitted := imaging.Fit(halves(), 4)
+	assert.Equal(t, image.Rect(0, 0, 4, 2), fitted.Bounds())
+	assert.Equal(t, red, fitted.NRGBAAt(0, 0))
+	assert.Equal(t, blue, fitted.NRGBAAt(3, 1))
+
+	// Pixels straddling both halves average them
+	fitted = imaging.Fit(halves(), 1)
+	assert.Equal(t, image.Rect(0, 0, 1, 1), fitted.Bounds())
+	assert.Equal(t, color.NRGBA{R: 127, B: 127, A: 255}, fitted.NRGBAAt(0, 0))
+
+	// Small images are never enlarged
+	fitted = imaging.Fit(halves(), 100)
+	assert.Equal(t, image.Rect(0, 0, 16, 8), fitted.Bounds())
+}
+
+func TestWebPCodec(t *testing.T) {
+	codec := imaging.NewWebPCodec(80)
+	if err := codec.CheckTools(); err != nil {
+		t.Skip(err)
+	}
+	ctx := context.Background()
+
+	encoded, err := codec.Encode(ctx, halves())
+	require.NoError(t, err)
+	assert.True(t, imaging.IsWebP(encoded))
+
+	decoded, err := codec.Decode(ctx, encoded)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 16, 8), decoded.Bounds())
+	near(t, red, decoded.At(2, 4))
+}
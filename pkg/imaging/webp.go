@@ -0,0 +1,109 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WebP tools from libwebp, found on the PATH
+const (
+	cwebpTool = "cwebp"
+	dwebpTool = "dwebp"
+)
+
+// WebPCodec encodes and decodes WebP images with libwebp's cwebp and dwebp
+// tools, which must be installed where it runs
+type WebPCodec struct {
+	quality int
+}
+
+// NewWebPCodec creates a codec encoding at quality, from 1 to 100
+func NewWebPCodec(quality int) *WebPCodec {
+	return &WebPCodec{quality: quality}
+}
+
+// CheckTools reports whether cwebp and dwebp can be found
+func (c *WebPCodec) CheckTools() error {
+	for _, tool := range []string{cwebpTool, dwebpTool} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return fmt.Errorf("%s not found: %w", tool, err)
+		}
+	}
+	return nil
+}
+
+// IsWebP checks whether content is a WebP image
+func IsWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+// Encode encodes an image as lossy WebP without metadata
+func (c *WebPCodec) Encode(ctx context.Context, img image.Image) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "webp-encode-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var source bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := encoder.Encode(&source, img); err != nil {
+		return nil, err
+	}
+	in, out := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.webp")
+	if err := os.WriteFile(in, source.Bytes(), 0o600); err != nil {
+		return nil, err
+	}
+
+	args := []string{"-quiet", "-metadata", "none", "-q", strconv.Itoa(c.quality), in, "-o", out}
+	if err := run(ctx, cwebpTool, args...); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(out)
+}
+
+// Decode decodes a WebP image. Content dwebp cannot decode is reported as
+// ErrUnsupportedFormat.
+func (c *WebPCodec) Decode(ctx context.Context, data []byte) (image.Image, error) {
+	dir, err := os.MkdirTemp("", "webp-decode-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "in.webp"), filepath.Join(dir, "out.png")
+	if err := os.WriteFile(in, data, 0o600); err != nil {
+		return nil, err
+	}
+	if err := run(ctx, dwebpTool, "-quiet", in, "-o", out); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	decoded, err := os.ReadFile(out)
+	if err != nil {
+		return nil, err
+	}
+	return png.Decode(bytes.NewReader(decoded))
+}
+
+// run runs a tool and includes its error output in the returned error
+func run(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
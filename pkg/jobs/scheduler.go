@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"dongome/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// JobFunc defines the signature for scheduled jobs
+type JobFunc func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	fn       JobFunc
+}
+
+// Scheduler runs registered jobs at fixed intervals
+type Scheduler struct {
+	jobs   []job
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+// NewScheduler creates a new job scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every registers a job to run at the given interval
+func (s *Scheduler) Every(name string, interval time.Duration, fn JobFunc) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, fn: fn})
+}
+
+// Start runs all registered jobs in the background until Stop is called
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.run(ctx, j)
+	}
+
+	logger.Info("Job scheduler started", zap.Int("jobs", len(s.jobs)))
+}
+
+// Stop stops all jobs and waits for running executions to finish
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			start := time.Now()
+			if err := j.fn(ctx); err != nil {
+				logger.Error("Scheduled job failed",
+					zap.String("job", j.name),
+					zap.Error(err))
+				continue
+			}
+			logger.Debug("Scheduled job completed",
+				zap.String("job", j.name),
+				zap.Duration("duration", time.Since(start)))
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ECSVersion is the Elastic Common Schema version the ecs format follows
+const ECSVersion = "8.11.0"
+
+// ecsEncoderConfig names the entry keys as the Elastic Common Schema does.
+// The caller is written by ecsCore as log.origin fields instead.
+func ecsEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:        "@timestamp",
+		LevelKey:       "log.level",
+		NameKey:        "log.logger",
+		MessageKey:     "message",
+		StacktraceKey:  "error.stack_trace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     encodeECSTime,
+		EncodeDuration: zapcore.NanosDurationEncoder,
+	}
+}
+
+// encodeECSTime writes timestamps in UTC with millisecond precision
+func encodeECSTime(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(t.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+}
+
+// ecsServiceFields identifies the service on every entry
+func ecsServiceFields(cfg Config) []zap.Field {
+	fields := []zap.Field{zap.String("ecs.version", ECSVersion)}
+	if cfg.ServiceName != "" {
+		fields = append(fields, zap.String(FieldServiceName, cfg.ServiceName))
+	}
+	if cfg.ServiceVersion != "" {
+		fields = append(fields, zap.String(FieldServiceVersion, cfg.ServiceVersion))
+	}
+	if cfg.Environment != "" {
+		fields = append(fields, zap.String(FieldServiceEnvironment, cfg.Environment))
+	}
+	return fields
+}
+
+// ecsCore writes the caller as log.origin fields and errors as error.message,
+// where ECS expects them
+type ecsCore struct {
+	zapcore.Core
+}
+
+func newECSCore(core zapcore.Core) zapcore.Core {
+	return ecsCore{Core: core}
+}
+
+func (c ecsCore) With(fields []zapcore.Field) zapcore.Core {
+	return ecsCore{Core: c.Core.With(ecsFields(fields))}
+}
+
+func (c ecsCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c ecsCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	fields = ecsFields(fields)
+	if entry.Caller.Defined {
+		fields = append(fields,
+			zap.String("log.origin.file.name", entry.Caller.File),
+			zap.Int("log.origin.file.line", entry.Caller.Line),
+			zap.String("log.origin.function", entry.Caller.Function))
+	}
+	return c.Core.Write(entry, fields)
+}
+
+// ecsFields renames zap.Error fields to error.message
+func ecsFields(fields []zapcore.Field) []zapcore.Field {
+	var renamed []zapcore.Field
+	for n, field := range fields {
+		err, ok := field.Interface.(error)
+		if field.Type != zapcore.ErrorType || field.Key != "error" || !ok {
+			continue
+		}
+		if renamed == nil {
+			// The caller may reuse its slice, so change a copy
+			renamed = append([]zapcore.Field(nil), fields...)
+		}
+		renamed[n] = zap.String(FieldErrorMessage, err.Error())
+	}
+	if renamed == nil {
+		return fields
+	}
+	return renamed
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Standard field names, following the Elastic Common Schema so every service
+// logs the same fact under the same key whatever the format
+const (
+	FieldServiceName        = "service.name"
+	FieldServiceVersion     = "service.version"
+	FieldServiceEnvironment = "service.environment"
+	FieldTraceID            = "trace.id"
+	FieldUserID             = "user.id"
+	FieldErrorMessage       = "error.message"
+
+	FieldEventID       = "event.id"
+	FieldEventAction   = "event.action"
+	FieldEventDuration = "event.duration"
+	FieldAggregateID   = "event.aggregate_id"
+
+	FieldHTTPMethod    = "http.request.method"
+	FieldHTTPRoute     = "http.route"
+	FieldHTTPStatus    = "http.response.status_code"
+	FieldHTTPBodyBytes = "http.response.body.bytes"
+	FieldURLPath       = "url.path"
+	FieldClientIP      = "client.ip"
+	FieldUserAgent     = "user_agent.original"
+
+	FieldListingID     = "listing.id"
+	FieldOrderID       = "order.id"
+	FieldTransactionID = "transaction.id"
+)
+
+// TraceID logs the trace ID carried by ctx, if any
+func TraceID(ctx context.Context) zap.Field {
+	if traceID := TraceIDFromContext(ctx); traceID != "" {
+		return zap.String(FieldTraceID, traceID)
+	}
+	return zap.Skip()
+}
+
+// UserID logs the user an entry is about
+func UserID(userID string) zap.Field {
+	return zap.String(FieldUserID, userID)
+}
+
+// EventID logs the ID of a domain event
+func EventID(eventID string) zap.Field {
+	return zap.String(FieldEventID, eventID)
+}
+
+// EventAction logs the type of a domain event
+func EventAction(eventType string) zap.Field {
+	return zap.String(FieldEventAction, eventType)
+}
+
+// AggregateID logs the aggregate a domain event belongs to
+func AggregateID(aggregateID string) zap.Field {
+	return zap.String(FieldAggregateID, aggregateID)
+}
+
+// HTTPMethod logs a request's method
+func HTTPMethod(method string) zap.Field {
+	return zap.String(FieldHTTPMethod, method)
+}
+
+// HTTPRoute logs the route pattern a request matched
+func HTTPRoute(route string) zap.Field {
+	return zap.String(FieldHTTPRoute, route)
+}
+
+// HTTPStatus logs a response's status code
+func HTTPStatus(status int) zap.Field {
+	return zap.Int(FieldHTTPStatus, status)
+}
+
+// ListingID logs the listing an entry is about
+func ListingID(listingID string) zap.Field {
+	return zap.String(FieldListingID, listingID)
+}
+
+// OrderID logs the order an entry is about
+func OrderID(orderID string) zap.Field {
+	return zap.String(FieldOrderID, orderID)
+}
+
+// TransactionID logs the transaction an entry is about
+func TransactionID(transactionID string) zap.Field {
+	return zap.String(FieldTransactionID, transactionID)
+}
@@ -1,69 +1,121 @@
 package logger
 
 import (
+	"fmt"
 	"os"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Log output formats
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+	FormatECS     = "ecs"
 )
 
 var Logger *zap.Logger
 
+// logger backs the package level functions, skipping their own frame so log
+// entries point at the code that logged them
+var logger *zap.Logger
+
+// Config selects how log entries are written. An empty Format writes JSON in
+// production and console output elsewhere; an empty Level logs at info in
+// production and debug elsewhere.
+type Config struct {
+	Format         string
+	Level          string
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// Output is the file or URL entries are written to, stderr when empty
+	Output string
+}
+
 // Initialize sets up the logger based on the environment
 func Initialize(env string) error {
-	var config zap.Config
+	return Configure(Config{Environment: env, Level: os.Getenv("LOG_LEVEL")})
+}
 
-	if env == "production" {
-		config = zap.NewProductionConfig()
-	} else {
+// Configure sets up the logger with the given format, level and service
+func Configure(cfg Config) error {
+	production := cfg.Environment == "production"
+	format := cfg.Format
+	if format == "" {
+		format = FormatConsole
+		if production {
+			format = FormatJSON
+		}
+	}
+
+	var config zap.Config
+	var options []zap.Option
+	switch format {
+	case FormatConsole:
 		config = zap.NewDevelopmentConfig()
+	case FormatJSON:
+		config = zap.NewProductionConfig()
+	case FormatECS:
+		config = zap.NewProductionConfig()
+		config.EncoderConfig = ecsEncoderConfig()
+		options = append(options, zap.WrapCore(newECSCore))
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+	if !production {
+		config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+	}
+
+	if cfg.Output != "" {
+		config.OutputPaths = []string{cfg.Output}
 	}
 
-	// Override log level from environment
-	if level := os.Getenv("LOG_LEVEL"); level != "" {
-		switch level {
-		case "debug":
-			config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
-		case "info":
-			config.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
-		case "warn":
-			config.Level = zap.NewAtomicLevelAt(zap.WarnLevel)
-		case "error":
-			config.Level = zap.NewAtomicLevelAt(zap.ErrorLevel)
+	if cfg.Level != "" {
+		level, err := zapcore.ParseLevel(cfg.Level)
+		if err != nil {
+			return fmt.Errorf("unknown log level %q", cfg.Level)
 		}
+		config.Level = zap.NewAtomicLevelAt(level)
 	}
 
-	logger, err := config.Build()
+	built, err := config.Build(options...)
 	if err != nil {
 		return err
 	}
+	if format == FormatECS {
+		built = built.With(ecsServiceFields(cfg)...)
+	}
 
-	Logger = logger
+	Logger = built
+	logger = built.WithOptions(zap.AddCallerSkip(1))
 	return nil
 }
 
 // Info logs an info message
 func Info(msg string, fields ...zap.Field) {
-	Logger.Info(msg, fields...)
+	logger.Info(msg, fields...)
 }
 
 // Debug logs a debug message
 func Debug(msg string, fields ...zap.Field) {
-	Logger.Debug(msg, fields...)
+	logger.Debug(msg, fields...)
 }
 
 // Error logs an error message
 func Error(msg string, fields ...zap.Field) {
-	Logger.Error(msg, fields...)
+	logger.Error(msg, fields...)
 }
 
 // Warn logs a warning message
 func Warn(msg string, fields ...zap.Field) {
-	Logger.Warn(msg, fields...)
+	logger.Warn(msg, fields...)
 }
 
 // Fatal logs a fatal message and exits
 func Fatal(msg string, fields ...zap.Field) {
-	Logger.Fatal(msg, fields...)
+	logger.Fatal(msg, fields...)
 }
 
 // Sync flushes any buffered log entries
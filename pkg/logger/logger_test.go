@@ -0,0 +1,125 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dongome/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// configureECS sends ECS entries to a file and returns a function reading
+// back the entries written so far
+func configureECS(t *testing.T) func() []map[string]interface{} {
+	path := filepath.Join(t.TempDir(), "log.json")
+	require.NoError(t, logger.Configure(logger.Config{
+		Format:         logger.FormatECS,
+		ServiceName:    "dongome-test",
+		ServiceVersion: "1.2.3",
+		Environment:    "production",
+		Output:         path,
+	}))
+	t.Cleanup(func() { require.NoError(t, logger.Initialize("test")) })
+
+	return func() []map[string]interface{} {
+		logger.Sync()
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var entries []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+}
+
+func TestConfigure_ECS(t *testing.T) {
+	read := configureECS(t)
+	ctx := logger.ContextWithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	logger.Error("Event handler failed",
+		logger.EventID("evt-1"),
+		logger.EventAction("user.registered"),
+		logger.UserID("user-1"),
+		logger.TraceID(ctx),
+		logger.TraceID(context.Background()),
+		zap.Error(errors.New("database is down")))
+	logger.Debug("Not logged at the production level")
+
+	entries := read()
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "Event handler failed", entry["message"])
+	assert.Equal(t, "error", entry["log.level"])
+	assert.Contains(t, entry, "@timestamp")
+	assert.Equal(t, logger.ECSVersion, entry["ecs.version"])
+	assert.Equal(t, "dongome-test", entry["service.name"])
+	assert.Equal(t, "1.2.3", entry["service.version"])
+	assert.Equal(t, "production", entry["service.environment"])
+	assert.Equal(t, "evt-1", entry["event.id"])
+	assert.Equal(t, "user.registered", entry["event.action"])
+	assert.Equal(t, "user-1", entry["user.id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["trace.id"])
+	assert.Equal(t, "database is down", entry["error.message"])
+	assert.NotContains(t, entry, "error")
+	// The entry points at the code that logged it, not at the logger package
+	assert.True(t, strings.HasSuffix(entry["log.origin.file.name"].(string), "logger/logger_test.go"))
+}
+
+func TestConfigure_Invalid(t *testing.T) {
+	assert.Error(t, logger.Configure(logger.Config{Format: "xml"}))
+	assert.Error(t, logger.Configure(logger.Config{Level: "loud"}))
+	require.NoError(t, logger.Initialize("test"))
+}
+
+func TestMiddleware(t *testing.T) {
+	read := configureECS(t)
+	gin.SetMode(gin.TestMode)
+
+	var handled string
+	router := gin.New()
+	router.Use(logger.Middleware())
+	router.GET("/items/:id", func(c *gin.Context) {
+		handled = logger.TraceIDFromContext(c.Request.Context())
+		c.Status(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("User-Agent", "dongome-test")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rec.Header().Get(logger.TraceIDHeader))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", handled)
+
+	entries := read()
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "warn", entry["log.level"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["trace.id"])
+	assert.Equal(t, "GET", entry["http.request.method"])
+	assert.Equal(t, "/items/:id", entry["http.route"])
+	assert.Equal(t, "/items/1", entry["url.path"])
+	assert.Equal(t, float64(http.StatusNotFound), entry["http.response.status_code"])
+	assert.Equal(t, "dongome-test", entry["user_agent.original"])
+	assert.Contains(t, entry, "event.duration")
+
+	// Requests without a trace ID are given a new one
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/2", nil))
+	assert.Len(t, rec.Header().Get(logger.TraceIDHeader), 32)
+}
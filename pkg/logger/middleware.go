@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"time"
+
+	"dongome/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Middleware gives each request a trace ID, returned in the X-Trace-ID
+// header and carried by the request context, and logs every request once it
+// has been handled
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := TraceIDFromContext(c.Request.Context())
+		if traceID == "" {
+			traceID = TraceIDFromRequest(c.Request)
+			c.Request = c.Request.WithContext(ContextWithTraceID(c.Request.Context(), traceID))
+		}
+		c.Header(TraceIDHeader, traceID)
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String(FieldTraceID, traceID),
+			HTTPMethod(c.Request.Method),
+			zap.String(FieldURLPath, c.Request.URL.Path),
+			HTTPStatus(status),
+			zap.Int(FieldHTTPBodyBytes, max(c.Writer.Size(), 0)),
+			zap.Duration(FieldEventDuration, time.Since(start)),
+			zap.String(FieldClientIP, c.ClientIP()),
+			zap.String(FieldUserAgent, c.Request.UserAgent()),
+		}
+		if route := c.FullPath(); route != "" {
+			fields = append(fields, HTTPRoute(route))
+		}
+		if userID := auth.UserID(c); !userID.IsZero() {
+			fields = append(fields, UserID(userID.String()))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String(FieldErrorMessage, c.Errors.String()))
+		}
+
+		level := zapcore.InfoLevel
+		switch {
+		case status >= 500:
+			level = zapcore.ErrorLevel
+		case status >= 400:
+			level = zapcore.WarnLevel
+		}
+		if entry := Logger.Check(level, "HTTP request"); entry != nil {
+			entry.Write(fields...)
+		}
+	}
+}
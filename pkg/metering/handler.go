@@ -0,0 +1,112 @@
+package metering
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultUsageDays  = 30
+	defaultUsageLimit = 100
+)
+
+// defaultGroupBy groups usage per endpoint and API version when no grouping is requested
+var defaultGroupBy = []Dimension{DimensionAPIVersion, DimensionRoute}
+
+// validDimensions are the dimensions accepted in group_by
+var validDimensions = map[Dimension]bool{
+	DimensionUserID:     true,
+	DimensionAPIKey:     true,
+	DimensionAppVersion: true,
+	DimensionAPIVersion: true,
+	DimensionRoute:      true,
+	DimensionDay:        true,
+}
+
+// UsageQuery represents the query to summarize API usage
+type UsageQuery struct {
+	From       *time.Time `form:"from" time_format:"2006-01-02"`
+	To         *time.Time `form:"to" time_format:"2006-01-02"`
+	UserID     string     `form:"user_id"`
+	APIKey     string     `form:"api_key"`
+	AppVersion string     `form:"app_version"`
+	APIVersion string     `form:"api_version"`
+	Route      string     `form:"route"`
+	GroupBy    string     `form:"group_by"`
+	Limit      int        `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// UsageHandler serves API usage analytics
+type UsageHandler struct {
+	store Store
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(store Store) *UsageHandler {
+	return &UsageHandler{
+		store: store,
+	}
+}
+
+// RegisterRoutes registers the usage route. The group must be restricted to admins.
+func (h *UsageHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/api-usage", h.GetUsage)
+}
+
+// GetUsage handles summarizing API usage per client and endpoint
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	var query UsageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	groupBy := defaultGroupBy
+	if query.GroupBy != "" {
+		groupBy = nil
+		for _, value := range strings.Split(query.GroupBy, ",") {
+			dimension := Dimension(strings.TrimSpace(value))
+			if !validDimensions[dimension] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group_by dimension: " + string(dimension)})
+				return
+			}
+			groupBy = append(groupBy, dimension)
+		}
+	}
+
+	filter := UsageFilter{
+		From:       time.Now().UTC().AddDate(0, 0, -defaultUsageDays).Truncate(24 * time.Hour),
+		UserID:     query.UserID,
+		APIKey:     query.APIKey,
+		AppVersion: query.AppVersion,
+		APIVersion: query.APIVersion,
+		Route:      query.Route,
+	}
+	if query.From != nil {
+		filter.From = *query.From
+	}
+	if query.To != nil {
+		filter.To = *query.To
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultUsageLimit
+	}
+
+	rows, err := h.store.Summarize(filter, groupBy, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":     filter.From,
+		"to":       query.To,
+		"group_by": groupBy,
+		"usage":    rows,
+	})
+}
@@ -0,0 +1,82 @@
+package metering
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// UsageKey identifies the client and endpoint a request is counted against
+type UsageKey struct {
+	Day        time.Time
+	UserID     string
+	APIKey     string
+	AppVersion string
+	APIVersion string
+	Method     string
+	Route      string
+}
+
+// UsageCount holds the request and error counts of a usage key
+type UsageCount struct {
+	Requests     int64
+	Errors       int64
+	ServerErrors int64
+}
+
+// Meter aggregates API usage in memory until it is flushed to the store
+type Meter struct {
+	mu     sync.Mutex
+	counts map[UsageKey]*UsageCount
+}
+
+// NewMeter creates a new meter
+func NewMeter() *Meter {
+	return &Meter{
+		counts: make(map[UsageKey]*UsageCount),
+	}
+}
+
+// Record counts a completed request. Statuses of 400 and above count as
+// errors; 500 and above also count as server errors.
+func (m *Meter) Record(key UsageKey, status int) {
+	key.Day = key.Day.UTC().Truncate(24 * time.Hour)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count, ok := m.counts[key]
+	if !ok {
+		count = &UsageCount{}
+		m.counts[key] = count
+	}
+	count.Requests++
+	if status >= 400 {
+		count.Errors++
+	}
+	if status >= 500 {
+		count.ServerErrors++
+	}
+}
+
+// Drain returns the counts recorded since the previous drain and resets the meter
+func (m *Meter) Drain() map[UsageKey]UsageCount {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	drained := make(map[UsageKey]UsageCount, len(m.counts))
+	for key, count := range m.counts {
+		drained[key] = *count
+	}
+	m.counts = make(map[UsageKey]*UsageCount)
+	return drained
+}
+
+// APIVersion returns the API version segment of a route such as /api/v1/users
+func APIVersion(route string) string {
+	segments := strings.Split(strings.TrimPrefix(route, "/"), "/")
+	if len(segments) >= 2 && segments[0] == "api" {
+		return segments[1]
+	}
+	return ""
+}
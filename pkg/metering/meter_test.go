@@ -0,0 +1,80 @@
+package metering_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"dongome/pkg/auth"
+	"dongome/pkg/ids"
+	"dongome/pkg/metering"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeter_RecordAndDrain(t *testing.T) {
+	meter := metering.NewMeter()
+	key := metering.UsageKey{
+		Day:        time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC),
+		UserID:     "user-1",
+		APIVersion: "v1",
+		Method:     http.MethodGet,
+		Route:      "/api/v1/users/:id",
+	}
+
+	meter.Record(key, http.StatusOK)
+	meter.Record(key, http.StatusNotFound)
+	key.Day = key.Day.Add(time.Hour)
+	meter.Record(key, http.StatusInternalServerError)
+
+	counts := meter.Drain()
+	require.Len(t, counts, 1, "requests on the same day share a key")
+	for key, count := range counts {
+		assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), key.Day)
+		assert.Equal(t, int64(3), count.Requests)
+		assert.Equal(t, int64(2), count.Errors)
+		assert.Equal(t, int64(1), count.ServerErrors)
+	}
+
+	assert.Empty(t, meter.Drain())
+}
+
+func TestAPIVersion(t *testing.T) {
+	assert.Equal(t, "v1", metering.APIVersion("/api/v1/users/:id"))
+	assert.Equal(t, "v2", metering.APIVersion("/api/v2/listings"))
+	assert.Equal(t, "", metering.APIVersion("/health"))
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	meter := metering.NewMeter()
+
+	router := gin.New()
+	router.Use(metering.Middleware(meter))
+	router.GET("/api/v1/things/:id", func(c *gin.Context) {
+		c.Set(auth.ContextUserID, ids.UserID("user-1"))
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/things/42", nil)
+	req.Header.Set(metering.APIKeyHeader, "partner-secret-key")
+	req.Header.Set(metering.AppVersionHeader, "android/2.3.1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Unmatched routes are not metered
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+
+	counts := meter.Drain()
+	require.Len(t, counts, 1)
+	for key := range counts {
+		assert.Equal(t, "user-1", key.UserID)
+		assert.Equal(t, metering.APIKeyFingerprint("partner-secret-key"), key.APIKey)
+		assert.NotContains(t, key.APIKey, "secret")
+		assert.Equal(t, "android/2.3.1", key.AppVersion)
+		assert.Equal(t, "v1", key.APIVersion)
+		assert.Equal(t, "/api/v1/things/:id", key.Route)
+	}
+}
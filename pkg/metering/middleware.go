@@ -0,0 +1,56 @@
+package metering
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"dongome/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Client identification headers
+const (
+	APIKeyHeader     = "X-API-Key"
+	AppVersionHeader = "X-App-Version"
+)
+
+// Middleware counts every matched request against the calling user, API key and app version
+func Middleware(meter *Meter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+
+		meter.Record(UsageKey{
+			Day:        time.Now(),
+			UserID:     auth.UserID(c).String(),
+			APIKey:     APIKeyFingerprint(c.GetHeader(APIKeyHeader)),
+			AppVersion: truncate(c.GetHeader(AppVersionHeader), 50),
+			APIVersion: APIVersion(route),
+			Method:     c.Request.Method,
+			Route:      route,
+		}, c.Writer.Status())
+	}
+}
+
+// APIKeyFingerprint identifies an API key without storing the key itself
+func APIKeyFingerprint(key string) string {
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// truncate limits client-supplied values to the column size
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
@@ -0,0 +1,193 @@
+package metering
+
+import (
+	"time"
+
+	"dongome/pkg/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UsageRecord is the persisted daily usage of an endpoint by a client
+type UsageRecord struct {
+	Day              time.Time `gorm:"type:date;primaryKey" json:"day"`
+	UserID           string    `gorm:"primaryKey;size:36" json:"user_id"`
+	APIKey           string    `gorm:"column:api_key;primaryKey;size:16" json:"api_key"`
+	AppVersion       string    `gorm:"primaryKey;size:50" json:"app_version"`
+	APIVersion       string    `gorm:"column:api_version;primaryKey;size:10" json:"api_version"`
+	Method           string    `gorm:"primaryKey;size:10" json:"method"`
+	Route            string    `gorm:"primaryKey;size:255" json:"route"`
+	RequestCount     int64     `json:"request_count"`
+	ErrorCount       int64     `json:"error_count"`
+	ServerErrorCount int64     `json:"server_error_count"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name of usage records
+func (UsageRecord) TableName() string {
+	return "api_usage"
+}
+
+// UsageFilter narrows a usage query. Empty fields match everything.
+type UsageFilter struct {
+	From       time.Time
+	To         time.Time
+	UserID     string
+	APIKey     string
+	AppVersion string
+	APIVersion string
+	Route      string
+}
+
+// Dimension is a usage attribute results can be grouped by
+type Dimension string
+
+const (
+	DimensionUserID     Dimension = "user_id"
+	DimensionAPIKey     Dimension = "api_key"
+	DimensionAppVersion Dimension = "app_version"
+	DimensionAPIVersion Dimension = "api_version"
+	DimensionRoute      Dimension = "route"
+	DimensionDay        Dimension = "day"
+)
+
+// UsageRow is a usage total for one combination of the grouped dimensions
+type UsageRow struct {
+	UserID       string     `json:"user_id,omitempty"`
+	APIKey       string     `json:"api_key,omitempty"`
+	AppVersion   string     `json:"app_version,omitempty"`
+	APIVersion   string     `json:"api_version,omitempty"`
+	Route        string     `json:"route,omitempty"`
+	Day          *time.Time `json:"day,omitempty"`
+	Requests     int64      `json:"requests"`
+	Errors       int64      `json:"errors"`
+	ServerErrors int64      `json:"server_errors"`
+	ErrorRate    float64    `json:"error_rate"`
+}
+
+// Store defines the interface for API usage persistence
+type Store interface {
+	Add(counts map[UsageKey]UsageCount) error
+	Summarize(filter UsageFilter, groupBy []Dimension, limit int) ([]UsageRow, error)
+	CountRequests(filter UsageFilter) (int64, error)
+}
+
+// GORMStore implements Store using GORM
+type GORMStore struct {
+	db *gorm.DB
+}
+
+// NewGORMStore creates a new usage store
+func NewGORMStore(db *gorm.DB) *GORMStore {
+	return &GORMStore{
+		db: db,
+	}
+}
+
+// Add adds drained counts to the daily totals
+func (s *GORMStore) Add(counts map[UsageKey]UsageCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	records := make([]UsageRecord, 0, len(counts))
+	for key, count := range counts {
+		records = append(records, UsageRecord{
+			Day:              key.Day,
+			UserID:           key.UserID,
+			APIKey:           key.APIKey,
+			AppVersion:       key.AppVersion,
+			APIVersion:       key.APIVersion,
+			Method:           key.Method,
+			Route:            key.Route,
+			RequestCount:     count.Requests,
+			ErrorCount:       count.Errors,
+			ServerErrorCount: count.ServerErrors,
+			UpdatedAt:        now,
+		})
+	}
+
+	return db.ClassifyError(s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "day"}, {Name: "user_id"}, {Name: "api_key"}, {Name: "app_version"},
+			{Name: "api_version"}, {Name: "method"}, {Name: "route"},
+		},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "request_count"}, Value: gorm.Expr("api_usage.request_count + excluded.request_count")},
+			{Column: clause.Column{Name: "error_count"}, Value: gorm.Expr("api_usage.error_count + excluded.error_count")},
+			{Column: clause.Column{Name: "server_error_count"}, Value: gorm.Expr("api_usage.server_error_count + excluded.server_error_count")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+		},
+	}).Create(&records).Error)
+}
+
+// Summarize totals usage matching the filter per combination of the grouped dimensions, busiest first
+func (s *GORMStore) Summarize(filter UsageFilter, groupBy []Dimension, limit int) ([]UsageRow, error) {
+	columns := make([]string, 0, len(groupBy))
+	for _, dimension := range groupBy {
+		columns = append(columns, string(dimension))
+	}
+
+	selects := append(append([]string{}, columns...),
+		"SUM(request_count) AS requests",
+		"SUM(error_count) AS errors",
+		"SUM(server_error_count) AS server_errors",
+	)
+
+	q := s.filtered(filter).Select(selects)
+	for _, column := range columns {
+		q = q.Group(column)
+	}
+
+	var rows []UsageRow
+	err := q.Order("requests DESC").Limit(limit).Scan(&rows).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+
+	for i := range rows {
+		if rows[i].Requests > 0 {
+			rows[i].ErrorRate = float64(rows[i].Errors) / float64(rows[i].Requests)
+		}
+	}
+	return rows, nil
+}
+
+// CountRequests totals the requests matching the filter, for quota enforcement
+func (s *GORMStore) CountRequests(filter UsageFilter) (int64, error) {
+	var total int64
+	err := s.filtered(filter).Select("COALESCE(SUM(request_count), 0)").Scan(&total).Error
+	if err != nil {
+		return 0, db.ClassifyError(err)
+	}
+	return total, nil
+}
+
+// filtered returns a query over the usage records matching the filter
+func (s *GORMStore) filtered(filter UsageFilter) *gorm.DB {
+	q := s.db.Model(&UsageRecord{})
+	if !filter.From.IsZero() {
+		q = q.Where("day >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("day <= ?", filter.To)
+	}
+	if filter.UserID != "" {
+		q = q.Where("user_id = ?", filter.UserID)
+	}
+	if filter.APIKey != "" {
+		q = q.Where("api_key = ?", filter.APIKey)
+	}
+	if filter.AppVersion != "" {
+		q = q.Where("app_version = ?", filter.AppVersion)
+	}
+	if filter.APIVersion != "" {
+		q = q.Where("api_version = ?", filter.APIVersion)
+	}
+	if filter.Route != "" {
+		q = q.Where("route = ?", filter.Route)
+	}
+	return q
+}
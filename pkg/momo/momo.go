@@ -0,0 +1,404 @@
+// Package momo moves Mobile Money through the MTN MoMo API: it requests
+// payments through the Collection API and sends money through the
+// Disbursement API. A request only starts a transaction; its result arrives
+// later as a callback, or can be looked up by the transaction's reference.
+package momo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"dongome/pkg/money"
+
+	"github.com/google/uuid"
+)
+
+// Transaction statuses reported by callbacks and lookups
+const (
+	StatusSuccessful = "SUCCESSFUL"
+	StatusFailed     = "FAILED"
+	StatusPending    = "PENDING"
+)
+
+// API URLs of MTN's sandbox and live environments
+const (
+	SandboxBaseURL = "https://sandbox.momodeveloper.mtn.com"
+	LiveBaseURL    = "https://proxy.momoapi.mtn.com"
+)
+
+// The MoMo products the client uses, each with its own API user
+const (
+	collection   = "collection"
+	disbursement = "disbursement"
+)
+
+// ErrNotFound is returned when looking up a transaction the API does not know
+var ErrNotFound = errors.New("momo transaction not found")
+
+// tokenMargin is how long before it expires an access token is replaced, so
+// it does not lapse in flight
+const tokenMargin = time.Minute
+
+// Config locates the API and the credentials of the API users
+type Config struct {
+	// BaseURL is the API URL. Left empty, it is SandboxBaseURL in the
+	// sandbox target environment and LiveBaseURL in any other.
+	BaseURL string
+	// SubscriptionKey, APIUser and APIKey are those of the Collection product
+	SubscriptionKey string
+	APIUser         string
+	APIKey          string
+	// DisbursementSubscriptionKey, DisbursementAPIUser and
+	// DisbursementAPIKey are those of the Disbursement product; transfers
+	// fail until they are set
+	DisbursementSubscriptionKey string
+	DisbursementAPIUser         string
+	DisbursementAPIKey          string
+	// TargetEnvironment is sandbox, or the live environment of the market,
+	// such as mtnghana
+	TargetEnvironment string
+	Timeout           time.Duration
+}
+
+// PaymentRequest asks a payer to pay from their Mobile Money wallet
+type PaymentRequest struct {
+	// ExternalID is returned in the callback to tell what the payment was for
+	ExternalID string
+	Amount     money.Money
+	// Payer is the payer's phone number in international form, without the +
+	Payer        string
+	PayerMessage string
+	PayeeNote    string
+	// CallbackURL receives the result of the payment
+	CallbackURL string
+}
+
+// TransferRequest sends money to a payee's Mobile Money wallet
+type TransferRequest struct {
+	// ExternalID is returned in the callback to tell what the transfer was for
+	ExternalID string
+	Amount     money.Money
+	// Payee is the payee's phone number in international form, without the +
+	Payee        string
+	PayerMessage string
+	PayeeNote    string
+	// CallbackURL receives the result of the transfer
+	CallbackURL string
+}
+
+// Transaction is how a payment or transfer went, as looked up by its reference
+type Transaction struct {
+	ReferenceID            string
+	ExternalID             string
+	Amount                 money.Money
+	Status                 string
+	FinancialTransactionID string
+	// Reason is why a failed transaction failed, such as NOT_ENOUGH_FUNDS
+	Reason string
+}
+
+// accessToken is a product's token and when it is to be replaced
+type accessToken struct {
+	value  string
+	expiry time.Time
+}
+
+// Client requests payments through the Collection API and sends money
+// through the Disbursement API
+type Client struct {
+	config Config
+	client *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]accessToken
+}
+
+// NewClient creates a client for the configured API users
+func NewClient(config Config) *Client {
+	if config.BaseURL == "" {
+		config.BaseURL = LiveBaseURL
+		if config.TargetEnvironment == "sandbox" {
+			config.BaseURL = SandboxBaseURL
+		}
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	return &Client{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		tokens: make(map[string]accessToken),
+	}
+}
+
+// BaseURL returns the URL of the API the client calls
+func (c *Client) BaseURL() string {
+	return c.config.BaseURL
+}
+
+// RequestToPay asks the payer to approve a payment and returns the reference
+// the API tracks it under
+func (c *Client) RequestToPay(ctx context.Context, payment PaymentRequest) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"amount":     payment.Amount.MajorString(),
+		"currency":   payment.Amount.Currency,
+		"externalId": payment.ExternalID,
+		"payer": map[string]string{
+			"partyIdType": "MSISDN",
+			"partyId":     payment.Payer,
+		},
+		"payerMessage": payment.PayerMessage,
+		"payeeNote":    payment.PayeeNote,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	referenceID, err := c.start(ctx, collection, "/collection/v1_0/requesttopay", body, payment.CallbackURL)
+	if err != nil {
+		return "", fmt.Errorf("requesting momo payment: %w", err)
+	}
+	return referenceID, nil
+}
+
+// GetPayment looks up how a payment requested with RequestToPay went
+func (c *Client) GetPayment(ctx context.Context, referenceID string) (*Transaction, error) {
+	transaction, err := c.lookUp(ctx, collection, "/collection/v1_0/requesttopay/"+referenceID)
+	if err != nil {
+		return nil, fmt.Errorf("looking up momo payment: %w", err)
+	}
+	transaction.ReferenceID = referenceID
+	return transaction, nil
+}
+
+// Transfer sends money to a payee and returns the reference the API tracks
+// the transfer under
+func (c *Client) Transfer(ctx context.Context, transfer TransferRequest) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"amount":     transfer.Amount.MajorString(),
+		"currency":   transfer.Amount.Currency,
+		"externalId": transfer.ExternalID,
+		"payee": map[string]string{
+			"partyIdType": "MSISDN",
+			"partyId":     transfer.Payee,
+		},
+		"payerMessage": transfer.PayerMessage,
+		"payeeNote":    transfer.PayeeNote,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	referenceID, err := c.start(ctx, disbursement, "/disbursement/v1_0/transfer", body, transfer.CallbackURL)
+	if err != nil {
+		return "", fmt.Errorf("requesting momo transfer: %w", err)
+	}
+	return referenceID, nil
+}
+
+// GetTransfer looks up how a transfer sent with Transfer went
+func (c *Client) GetTransfer(ctx context.Context, referenceID string) (*Transaction, error) {
+	transaction, err := c.lookUp(ctx, disbursement, "/disbursement/v1_0/transfer/"+referenceID)
+	if err != nil {
+		return nil, fmt.Errorf("looking up momo transfer: %w", err)
+	}
+	transaction.ReferenceID = referenceID
+	return transaction, nil
+}
+
+// AccountHolderActive checks if a phone number, in international form
+// without the +, is an active wallet money can be sent to
+func (c *Client) AccountHolderActive(ctx context.Context, msisdn string) (bool, error) {
+	req, err := c.newRequest(ctx, disbursement, http.MethodGet, "/disbursement/v1_0/accountholder/msisdn/"+msisdn+"/active", nil)
+	if err != nil {
+		return false, fmt.Errorf("validating momo account holder: %w", err)
+	}
+
+	status, body, err := c.do(req)
+	if err != nil {
+		return false, fmt.Errorf("validating momo account holder: %w", err)
+	}
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	if status != http.StatusOK {
+		return false, fmt.Errorf("validating momo account holder: unexpected status %d: %s", status, body)
+	}
+
+	var result struct {
+		Result bool `json:"result"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return false, fmt.Errorf("validating momo account holder: unreadable response: %s", body)
+	}
+	return result.Result, nil
+}
+
+// start posts a new transaction to a product under a new reference, which
+// it returns once the API accepts the transaction
+func (c *Client) start(ctx context.Context, product, path string, body []byte, callbackURL string) (string, error) {
+	referenceID := uuid.New().String()
+	req, err := c.newRequest(ctx, product, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Reference-Id", referenceID)
+	if callbackURL != "" {
+		req.Header.Set("X-Callback-Url", callbackURL)
+	}
+
+	status, respBody, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusAccepted {
+		return "", fmt.Errorf("unexpected status %d: %s", status, respBody)
+	}
+	return referenceID, nil
+}
+
+// lookUp fetches a transaction of a product
+func (c *Client) lookUp(ctx context.Context, product, path string) (*Transaction, error) {
+	req, err := c.newRequest(ctx, product, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	status, body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", status, body)
+	}
+
+	var result struct {
+		Amount                 string          `json:"amount"`
+		Currency               string          `json:"currency"`
+		ExternalID             string          `json:"externalId"`
+		Status                 string          `json:"status"`
+		FinancialTransactionID string          `json:"financialTransactionId"`
+		Reason                 json.RawMessage `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unreadable response: %s", body)
+	}
+	amount, err := money.ParseMajor(result.Amount, result.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("unreadable amount %q %q", result.Amount, result.Currency)
+	}
+	return &Transaction{
+		ExternalID:             result.ExternalID,
+		Amount:                 amount,
+		Status:                 result.Status,
+		FinancialTransactionID: result.FinancialTransactionID,
+		Reason:                 failureReason(result.Reason),
+	}, nil
+}
+
+// failureReason reads the reason of a failed transaction, which the API
+// gives either as a code or as an object holding one
+func failureReason(raw json.RawMessage) string {
+	var code string
+	if json.Unmarshal(raw, &code) == nil {
+		return code
+	}
+	var reason struct {
+		Code string `json:"code"`
+	}
+	if json.Unmarshal(raw, &reason) == nil {
+		return reason.Code
+	}
+	return ""
+}
+
+// newRequest creates an authorized request to a product of the API
+func (c *Client) newRequest(ctx context.Context, product, method, path string, body io.Reader) (*http.Request, error) {
+	token, err := c.accessToken(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	subscriptionKey, _, _ := c.credentials(product)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Target-Environment", c.config.TargetEnvironment)
+	req.Header.Set("Ocp-Apim-Subscription-Key", subscriptionKey)
+	return req, nil
+}
+
+// credentials returns the subscription key, API user and API key of a product
+func (c *Client) credentials(product string) (string, string, string) {
+	if product == disbursement {
+		return c.config.DisbursementSubscriptionKey, c.config.DisbursementAPIUser, c.config.DisbursementAPIKey
+	}
+	return c.config.SubscriptionKey, c.config.APIUser, c.config.APIKey
+}
+
+// accessToken returns a token for a product, fetching a new one when the
+// last has expired
+func (c *Client) accessToken(ctx context.Context, product string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if token, ok := c.tokens[product]; ok && time.Now().Before(token.expiry) {
+		return token.value, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.BaseURL+"/"+product+"/token/", nil)
+	if err != nil {
+		return "", err
+	}
+	subscriptionKey, apiUser, apiKey := c.credentials(product)
+	req.SetBasicAuth(apiUser, apiKey)
+	req.Header.Set("Ocp-Apim-Subscription-Key", subscriptionKey)
+
+	status, body, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("fetching momo %s access token: unexpected status %d: %s", product, status, body)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("fetching momo %s access token: unreadable response: %s", product, body)
+	}
+
+	c.tokens[product] = accessToken{
+		value:  token.AccessToken,
+		expiry: time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenMargin),
+	}
+	return token.AccessToken, nil
+}
+
+// do sends a request and reads the response
+func (c *Client) do(req *http.Request) (int, []byte, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
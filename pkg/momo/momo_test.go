@@ -0,0 +1,210 @@
+package momo_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"dongome/pkg/momo"
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RequestToPay(t *testing.T) {
+	tokens := 0
+	var payment map[string]interface{}
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/collection/token/":
+			user, key, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "api-user", user)
+			assert.Equal(t, "api-key", key)
+			tokens++
+			w.Write([]byte(`{"access_token":"token-1","token_type":"access_token","expires_in":3600}`))
+		case "/collection/v1_0/requesttopay":
+			headers = r.Header
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payment))
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := momo.NewClient(momo.Config{
+		BaseURL:           server.URL + "/",
+		SubscriptionKey:   "subscription",
+		APIUser:           "api-user",
+		APIKey:            "api-key",
+		TargetEnvironment: "sandbox",
+		Timeout:           time.Second,
+	})
+	request := momo.PaymentRequest{
+		ExternalID:  "promotion-1",
+		Amount:      money.Cedis(25),
+		Payer:       "233241234567",
+		CallbackURL: "https://api.example.com/callback",
+	}
+
+	reference, err := client.RequestToPay(context.Background(), request)
+	require.NoError(t, err)
+	assert.Len(t, reference, 36)
+	assert.Equal(t, reference, headers.Get("X-Reference-Id"))
+	assert.Equal(t, "Bearer token-1", headers.Get("Authorization"))
+	assert.Equal(t, "sandbox", headers.Get("X-Target-Environment"))
+	assert.Equal(t, "subscription", headers.Get("Ocp-Apim-Subscription-Key"))
+	assert.Equal(t, "https://api.example.com/callback", headers.Get("X-Callback-Url"))
+	assert.Equal(t, "25.00", payment["amount"])
+	assert.Equal(t, "promotion-1", payment["externalId"])
+	assert.Equal(t, map[string]interface{}{"partyIdType": "MSISDN", "partyId": "233241234567"}, payment["payer"])
+
+	// The token is reused until it expires
+	_, err = client.RequestToPay(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 1, tokens)
+}
+
+func TestClient_RequestToPayRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/collection/token/" {
+			w.Write([]byte(`{"access_token":"token-1","expires_in":3600}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"INVALID_CALLBACK_URL_HOST"}`))
+	}))
+	defer server.Close()
+
+	client := momo.NewClient(momo.Config{BaseURL: server.URL, Timeout: time.Second})
+	_, err := client.RequestToPay(context.Background(), momo.PaymentRequest{ExternalID: "promotion-1", Amount: money.Cedis(25)})
+	assert.ErrorContains(t, err, "INVALID_CALLBACK_URL_HOST")
+}
+
+func TestClient_GetPayment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/collection/token/":
+			w.Write([]byte(`{"access_token":"token-1","expires_in":3600}`))
+		case "/collection/v1_0/requesttopay/reference-1":
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"amount":"25","currency":"GHS","externalId":"order-1","status":"SUCCESSFUL","financialTransactionId":"123456"}`))
+		case "/collection/v1_0/requesttopay/reference-2":
+			w.Write([]byte(`{"amount":"25","currency":"GHS","externalId":"order-2","status":"FAILED","reason":{"code":"NOT_ENOUGH_FUNDS","message":"Payer has not enough funds"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := momo.NewClient(momo.Config{BaseURL: server.URL, TargetEnvironment: "sandbox", Timeout: time.Second})
+	ctx := context.Background()
+
+	payment, err := client.GetPayment(ctx, "reference-1")
+	require.NoError(t, err)
+	assert.Equal(t, "reference-1", payment.ReferenceID)
+	assert.Equal(t, "order-1", payment.ExternalID)
+	assert.Equal(t, momo.StatusSuccessful, payment.Status)
+	assert.True(t, payment.Amount.Equal(money.Cedis(25)))
+	assert.Equal(t, "123456", payment.FinancialTransactionID)
+
+	payment, err = client.GetPayment(ctx, "reference-2")
+	require.NoError(t, err)
+	assert.Equal(t, momo.StatusFailed, payment.Status)
+	assert.Equal(t, "NOT_ENOUGH_FUNDS", payment.Reason)
+
+	_, err = client.GetPayment(ctx, "missing")
+	assert.ErrorIs(t, err, momo.ErrNotFound)
+}
+
+func TestClient_Transfer(t *testing.T) {
+	var transfer map[string]interface{}
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/disbursement/token/":
+			// Disbursements have their own API user
+			user, key, _ := r.BasicAuth()
+			assert.Equal(t, "payout-user", user)
+			assert.Equal(t, "payout-key", key)
+			assert.Equal(t, "payout-subscription", r.Header.Get("Ocp-Apim-Subscription-Key"))
+			w.Write([]byte(`{"access_token":"payout-token","expires_in":3600}`))
+		case "/disbursement/v1_0/transfer":
+			headers = r.Header
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&transfer))
+			w.WriteHeader(http.StatusAccepted)
+		case "/disbursement/v1_0/transfer/reference-1":
+			w.Write([]byte(`{"amount":"90.50","currency":"GHS","externalId":"payout-1","status":"PENDING"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := momo.NewClient(momo.Config{
+		BaseURL:                     server.URL,
+		SubscriptionKey:             "subscription",
+		APIUser:                     "api-user",
+		APIKey:                      "api-key",
+		DisbursementSubscriptionKey: "payout-subscription",
+		DisbursementAPIUser:         "payout-user",
+		DisbursementAPIKey:          "payout-key",
+		TargetEnvironment:           "mtnghana",
+		Timeout:                     time.Second,
+	})
+	ctx := context.Background()
+
+	reference, err := client.Transfer(ctx, momo.TransferRequest{
+		ExternalID: "payout-1",
+		Amount:     money.New(9050, "GHS"),
+		Payee:      "233241234567",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, reference, headers.Get("X-Reference-Id"))
+	assert.Equal(t, "Bearer payout-token", headers.Get("Authorization"))
+	assert.Equal(t, "mtnghana", headers.Get("X-Target-Environment"))
+	assert.Equal(t, "90.50", transfer["amount"])
+	assert.Equal(t, map[string]interface{}{"partyIdType": "MSISDN", "partyId": "233241234567"}, transfer["payee"])
+
+	status, err := client.GetTransfer(ctx, "reference-1")
+	require.NoError(t, err)
+	assert.Equal(t, momo.StatusPending, status.Status)
+	assert.Equal(t, "payout-1", status.ExternalID)
+}
+
+func TestClient_AccountHolderActive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/disbursement/token/":
+			w.Write([]byte(`{"access_token":"payout-token","expires_in":3600}`))
+		case "/disbursement/v1_0/accountholder/msisdn/233241234567/active":
+			assert.Equal(t, "Bearer payout-token", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"result":true}`))
+		case "/disbursement/v1_0/accountholder/msisdn/233501234567/active":
+			w.Write([]byte(`{"result":false}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := momo.NewClient(momo.Config{BaseURL: server.URL, TargetEnvironment: "sandbox", Timeout: time.Second})
+	ctx := context.Background()
+
+	active, err := client.AccountHolderActive(ctx, "233241234567")
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	active, err = client.AccountHolderActive(ctx, "233501234567")
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	// Numbers the API does not know are not wallets
+	active, err = client.AccountHolderActive(ctx, "233201234567")
+	require.NoError(t, err)
+	assert.False(t, active)
+}
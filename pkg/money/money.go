@@ -0,0 +1,237 @@
+// Package money keeps amounts of money exactly, as a whole number of the
+// currency's minor units: pesewas for Ghana cedis. Prices and payments are
+// Money so adding, comparing and storing them never rounds. Major units
+// (cedis) are only used at the edges, to read amounts people type and to
+// show amounts people read.
+//
+// Money is stored in two columns, embedded with a prefix:
+//
+//	Price money.Money `gorm:"embedded;embeddedPrefix:price_"`
+//
+// keeps the amount in price_minor and the currency in price_currency.
+package money
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"dongome/pkg/errors"
+)
+
+// DefaultCurrency is the currency of the marketplace
+const DefaultCurrency = "GHS"
+
+// SupportedCurrencies lists the currencies listings can be priced in and
+// prices can be displayed in
+var SupportedCurrencies = []string{"GHS", "USD", "EUR", "GBP", "NGN"}
+
+// IsSupported checks if the currency is one of SupportedCurrencies
+func IsSupported(currency string) bool {
+	for _, supported := range SupportedCurrencies {
+		if currency == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// minorPerMajor is how many minor units make a major unit. Every currency
+// the marketplace handles has 2 decimals.
+const minorPerMajor = 100
+
+// Money is an amount of a currency
+type Money struct {
+	// Amount is in minor units: pesewas for cedis
+	Amount   int64  `gorm:"column:minor;not null;default:0" json:"amount"`
+	Currency string `gorm:"column:currency;size:3;not null;default:'GHS'" json:"currency"`
+}
+
+// New returns an amount of minor units of the currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Cedis returns an amount of Ghana cedis given in cedis, rounded to the
+// nearest pesewa
+func Cedis(amount float64) Money {
+	return FromMajor(amount, DefaultCurrency)
+}
+
+// FromMajor returns an amount given in major units, rounded to the nearest
+// minor unit. It rounds the decimal the float was written as, so 0.285 cedis
+// is 29 pesewas even though the float is slightly below 0.285.
+func FromMajor(amount float64, currency string) Money {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return New(0, currency)
+	}
+	minor, _ := parseMinor(strconv.FormatFloat(amount, 'f', -1, 64), true)
+	return New(minor, currency)
+}
+
+// ParseMajor reads an amount written in major units, such as "12.50", with
+// at most 2 decimals
+func ParseMajor(s, currency string) (Money, error) {
+	minor, err := parseMinor(strings.TrimSpace(s), false)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(minor, currency), nil
+}
+
+// Major returns the amount in major units, for display and for rules that
+// weigh amounts. Arithmetic on it may round.
+func (m Money) Major() float64 {
+	return float64(m.Amount) / minorPerMajor
+}
+
+// MajorString writes the amount in major units with 2 decimals, such as "12.50"
+func (m Money) MajorString() string {
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/minorPerMajor, amount%minorPerMajor)
+}
+
+// String writes the amount with its currency, such as "GHS 12.50"
+func (m Money) String() string {
+	return m.Currency + " " + m.MajorString()
+}
+
+// IsZero checks if the amount is nothing
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive checks if the amount is more than nothing
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// IsNegative checks if the amount is less than nothing
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// SameCurrency checks if both amounts are of the same currency
+func (m Money) SameCurrency(other Money) bool {
+	return strings.EqualFold(m.Currency, other.Currency)
+}
+
+// Equal checks if both are the same amount of the same currency
+func (m Money) Equal(other Money) bool {
+	return m.Amount == other.Amount && m.SameCurrency(other)
+}
+
+// Cmp compares the amounts: -1 if m is less than other, 1 if it is more and
+// 0 if they are equal. Both must be of the same currency.
+func (m Money) Cmp(other Money) int {
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Add returns the sum of both amounts, which must be of the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, errors.ValidationError("amounts are in different currencies")
+	}
+	return New(m.Amount+other.Amount, m.Currency), nil
+}
+
+// Sub returns m less other, which must be of the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, errors.ValidationError("amounts are in different currencies")
+	}
+	return New(m.Amount-other.Amount, m.Currency), nil
+}
+
+// Multiply returns the amount n times over
+func (m Money) Multiply(n int64) Money {
+	return New(m.Amount*n, m.Currency)
+}
+
+// Scale returns the amount multiplied by factor, rounded to the nearest
+// minor unit. It is meant for bands and estimates, not for charging.
+func (m Money) Scale(factor float64) Money {
+	return New(int64(math.Round(float64(m.Amount)*factor)), m.Currency)
+}
+
+// Convert returns the amount in another currency, given how many units of
+// that currency one unit of this one buys, rounded to the nearest minor unit.
+// It is meant for showing prices, not for charging.
+func (m Money) Convert(currency string, rate float64) Money {
+	converted := m.Scale(rate)
+	converted.Currency = currency
+	return converted
+}
+
+// Split divides the amount into n parts that add up to it exactly. The
+// minor units left over go one each to the first parts.
+func (m Money) Split(n int) []Money {
+	if n <= 0 {
+		return nil
+	}
+	parts := make([]Money, n)
+	share, remainder := m.Amount/int64(n), m.Amount%int64(n)
+	for i := range parts {
+		amount := share
+		if int64(i) < remainder {
+			amount++
+		} else if remainder < 0 && int64(i) < -remainder {
+			amount--
+		}
+		parts[i] = New(amount, m.Currency)
+	}
+	return parts
+}
+
+// parseMinor reads a decimal amount in major units as minor units. With
+// round, extra decimals are rounded half away from zero; without, they are
+// rejected.
+func parseMinor(s string, round bool) (int64, error) {
+	invalid := errors.ValidationError("amount must be a number with at most 2 decimals")
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return 0, invalid
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	for _, digits := range []string{whole, fraction} {
+		for _, r := range digits {
+			if r < '0' || r > '9' {
+				return 0, invalid
+			}
+		}
+	}
+	if len(fraction) > 2 && !round {
+		return 0, invalid
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > math.MaxInt64/minorPerMajor-1 {
+		return 0, invalid
+	}
+	cents, _ := strconv.ParseInt((fraction + "00")[:2], 10, 64)
+	minor := units*minorPerMajor + cents
+	if len(fraction) > 2 && fraction[2] >= '5' {
+		minor++
+	}
+	if negative {
+		minor = -minor
+	}
+	return minor, nil
+}
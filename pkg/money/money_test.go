@@ -0,0 +1,79 @@
+package money_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"dongome/pkg/money"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromMajor_RoundsTheWrittenDecimal(t *testing.T) {
+	assert.Equal(t, int64(29), money.Cedis(0.285).Amount)
+	assert.Equal(t, int64(1999), money.Cedis(19.99).Amount)
+	assert.Equal(t, int64(30), money.Cedis(0.1+0.2).Amount)
+	assert.Equal(t, int64(-1250), money.Cedis(-12.5).Amount)
+	assert.Equal(t, money.DefaultCurrency, money.Cedis(1).Currency)
+}
+
+func TestParseMajor(t *testing.T) {
+	for input, want := range map[string]int64{"12.50": 1250, "12.5": 1250, "12": 1200, ".75": 75, " 3.04 ": 304} {
+		amount, err := money.ParseMajor(input, "GHS")
+		require.NoError(t, err, input)
+		assert.Equal(t, want, amount.Amount, input)
+	}
+
+	for _, input := range []string{"", "12.345", "1,000", "abc", "-", "1e3"} {
+		_, err := money.ParseMajor(input, "GHS")
+		assert.Error(t, err, input)
+	}
+}
+
+func TestMoney_Formatting(t *testing.T) {
+	assert.Equal(t, "12.05", money.New(1205, "GHS").MajorString())
+	assert.Equal(t, "-0.50", money.New(-50, "GHS").MajorString())
+	assert.Equal(t, "GHS 100.00", money.Cedis(100).String())
+	assert.Equal(t, 12.05, money.New(1205, "GHS").Major())
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	sum, err := money.Cedis(0.1).Add(money.Cedis(0.2))
+	require.NoError(t, err)
+	assert.True(t, sum.Equal(money.Cedis(0.3)))
+
+	difference, err := money.Cedis(10).Sub(money.Cedis(12.5))
+	require.NoError(t, err)
+	assert.True(t, difference.IsNegative())
+
+	_, err = money.Cedis(10).Add(money.New(100, "USD"))
+	assert.Error(t, err)
+
+	assert.Equal(t, int64(3000), money.Cedis(10).Multiply(3).Amount)
+	assert.Equal(t, int64(1500), money.Cedis(10).Scale(1.5).Amount)
+	assert.Equal(t, -1, money.Cedis(1).Cmp(money.Cedis(2)))
+	assert.Equal(t, 0, money.Cedis(2).Cmp(money.Cedis(2)))
+}
+
+func TestMoney_Convert(t *testing.T) {
+	converted := money.Cedis(100).Convert("USD", 0.0667)
+	assert.Equal(t, money.New(667, "USD"), converted)
+	assert.True(t, money.IsSupported("NGN"))
+	assert.False(t, money.IsSupported("XYZ"))
+}
+
+func TestMoney_Split(t *testing.T) {
+	parts := money.Cedis(1).Split(3)
+	require.Len(t, parts, 3)
+	assert.Equal(t, []int64{34, 33, 33}, []int64{parts[0].Amount, parts[1].Amount, parts[2].Amount})
+
+	negative := money.New(-7, "GHS").Split(3)
+	assert.Equal(t, int64(-7), negative[0].Amount+negative[1].Amount+negative[2].Amount)
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(money.Cedis(12.5))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 1250, "currency": "GHS"}`, string(data))
+}
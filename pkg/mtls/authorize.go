@@ -0,0 +1,71 @@
+package mtls
+
+import (
+	"net/http"
+	"strings"
+
+	"dongome/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// callerKey is the gin context key holding the calling service's identity
+const callerKey = "mtls.caller"
+
+// Policy lists the routes each calling service may use
+type Policy struct {
+	allowed map[string]map[string]bool
+}
+
+// NewPolicy builds a policy from a map of service identity to routes, written
+// as "METHOD /route" using gin route patterns. "*" allows every route.
+func NewPolicy(allowedCallers map[string][]string) *Policy {
+	policy := &Policy{allowed: make(map[string]map[string]bool)}
+	for service, routes := range allowedCallers {
+		policy.allowed[service] = make(map[string]bool)
+		for _, route := range routes {
+			policy.allowed[service][strings.Join(strings.Fields(route), " ")] = true
+		}
+	}
+	return policy
+}
+
+// Allows reports whether service may call the route
+func (p *Policy) Allows(service, method, route string) bool {
+	routes := p.allowed[service]
+	return routes["*"] || routes[method+" "+route]
+}
+
+// Authorize rejects requests whose client certificate identity is not
+// allowed to call the matched route. It must run behind a server that
+// requires client certificates.
+func Authorize(policy *Policy, metrics *Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+			metrics.denied.Add(1)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "client certificate required"})
+			return
+		}
+
+		caller := Identity(c.Request.TLS.PeerCertificates[0])
+		if !policy.Allows(caller, c.Request.Method, c.FullPath()) {
+			metrics.denied.Add(1)
+			logger.Warn("Internal call denied",
+				zap.String("caller", caller),
+				logger.HTTPMethod(c.Request.Method),
+				logger.HTTPRoute(c.FullPath()),
+				logger.TraceID(c.Request.Context()))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "caller is not allowed to use this route"})
+			return
+		}
+
+		c.Set(callerKey, caller)
+		c.Next()
+	}
+}
+
+// Caller returns the identity of the service that made an authorized request
+func Caller(c *gin.Context) string {
+	return c.GetString(callerKey)
+}
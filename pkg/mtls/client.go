@@ -0,0 +1,39 @@
+package mtls
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewClient creates an HTTP client for calling the internal listener of
+// another service. With a cert store it presents the service certificate and
+// verifies that the server is serverIdentity; a nil store makes plain calls
+// for local development. Every call is counted in metrics under serverIdentity.
+func NewClient(store *CertStore, serverIdentity string, timeout time.Duration, metrics *Metrics) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if store != nil {
+		transport.TLSClientConfig = ClientTLSConfig(store, serverIdentity)
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &meteredTransport{
+			next:    transport,
+			service: serverIdentity,
+			metrics: metrics,
+		},
+	}
+}
+
+// meteredTransport records the outcome of each outbound internal call
+type meteredTransport struct {
+	next    http.RoundTripper
+	service string
+	metrics *Metrics
+}
+
+func (t *meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	t.metrics.recordClientRequest(t.service, err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	return resp, err
+}
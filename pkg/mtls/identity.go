@@ -0,0 +1,27 @@
+package mtls
+
+import (
+	"crypto/x509"
+	"strings"
+)
+
+// TrustDomain is the SPIFFE trust domain of Dongome service certificates
+const TrustDomain = "dongome"
+
+// IdentityURI returns the SPIFFE ID to put in the URI SAN of a service's
+// certificate, such as spiffe://dongome/dongome-api
+func IdentityURI(service string) string {
+	return "spiffe://" + TrustDomain + "/" + service
+}
+
+// Identity returns the service name a certificate was issued to. It is read
+// from a spiffe://dongome/<service> URI SAN, falling back to the common name.
+func Identity(cert *x509.Certificate) string {
+	prefix := "spiffe://" + TrustDomain + "/"
+	for _, uri := range cert.URIs {
+		if service, ok := strings.CutPrefix(uri.String(), prefix); ok && service != "" {
+			return service
+		}
+	}
+	return cert.Subject.CommonName
+}
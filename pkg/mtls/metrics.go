@@ -0,0 +1,93 @@
+package mtls
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics counts the health of internal connections. It is safe for concurrent use.
+type Metrics struct {
+	activeConnections atomic.Int64
+	handshakes        atomic.Int64
+	handshakeFailures atomic.Int64
+	denied            atomic.Int64
+	reloads           atomic.Int64
+	reloadFailures    atomic.Int64
+
+	mu             sync.Mutex
+	certExpiresAt  time.Time
+	clientRequests map[string]*clientStats
+}
+
+type clientStats struct {
+	requests int64
+	failures int64
+}
+
+// NewMetrics creates empty connection metrics
+func NewMetrics() *Metrics {
+	return &Metrics{clientRequests: make(map[string]*clientStats)}
+}
+
+// ClientSnapshot reports the outbound calls made to one service
+type ClientSnapshot struct {
+	Requests int64 `json:"requests"`
+	Failures int64 `json:"failures"`
+}
+
+// Snapshot is a point-in-time copy of the metrics
+type Snapshot struct {
+	ActiveConnections int64                     `json:"active_connections"`
+	Handshakes        int64                     `json:"handshakes"`
+	HandshakeFailures int64                     `json:"handshake_failures"`
+	Denied            int64                     `json:"denied"`
+	Reloads           int64                     `json:"reloads"`
+	ReloadFailures    int64                     `json:"reload_failures"`
+	CertExpiresAt     *time.Time                `json:"cert_expires_at,omitempty"`
+	Clients           map[string]ClientSnapshot `json:"clients"`
+}
+
+// Snapshot copies the current metrics
+func (m *Metrics) Snapshot() Snapshot {
+	snapshot := Snapshot{
+		ActiveConnections: m.activeConnections.Load(),
+		Handshakes:        m.handshakes.Load(),
+		HandshakeFailures: m.handshakeFailures.Load(),
+		Denied:            m.denied.Load(),
+		Reloads:           m.reloads.Load(),
+		ReloadFailures:    m.reloadFailures.Load(),
+		Clients:           make(map[string]ClientSnapshot),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.certExpiresAt.IsZero() {
+		expiresAt := m.certExpiresAt
+		snapshot.CertExpiresAt = &expiresAt
+	}
+	for service, stats := range m.clientRequests {
+		snapshot.Clients[service] = ClientSnapshot{Requests: stats.requests, Failures: stats.failures}
+	}
+	return snapshot
+}
+
+func (m *Metrics) setCertExpiry(expiresAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.certExpiresAt = expiresAt
+}
+
+func (m *Metrics) recordClientRequest(service string, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats, ok := m.clientRequests[service]
+	if !ok {
+		stats = &clientStats{}
+		m.clientRequests[service] = stats
+	}
+	stats.requests++
+	if failed {
+		stats.failures++
+	}
+}
@@ -0,0 +1,193 @@
+package mtls_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dongome/pkg/config"
+	"dongome/pkg/logger"
+	"dongome/pkg/mtls"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Initialize("test"); err != nil {
+		panic(err)
+	}
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// testCA issues service certificates for tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dongome test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// writeServiceCert issues a certificate for service and writes it, its key
+// and the CA bundle to dir, returning the matching configuration
+func (ca *testCA) writeServiceCert(t *testing.T, dir, service string, serial int64) *config.InternalTLSConfig {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	identity, err := url.Parse(mtls.IdentityURI(service))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: service},
+		URIs:         []*url.URL{identity},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Duration(serial) * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	cfg := &config.InternalTLSConfig{
+		Enabled:  true,
+		CertFile: filepath.Join(dir, service+".crt"),
+		KeyFile:  filepath.Join(dir, service+".key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	}
+	require.NoError(t, os.WriteFile(cfg.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(cfg.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.WriteFile(cfg.CAFile, ca.pem, 0o600))
+	return cfg
+}
+
+func TestPolicy_Allows(t *testing.T) {
+	policy := mtls.NewPolicy(map[string][]string{
+		"dongome-worker": {"GET  /internal/v1/users/:id"},
+		"dongome-admin":  {"*"},
+	})
+
+	assert.True(t, policy.Allows("dongome-worker", "GET", "/internal/v1/users/:id"))
+	assert.False(t, policy.Allows("dongome-worker", "DELETE", "/internal/v1/users/:id"))
+	assert.True(t, policy.Allows("dongome-admin", "DELETE", "/internal/v1/users/:id"))
+	assert.False(t, policy.Allows("dongome-search", "GET", "/internal/v1/users/:id"))
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	serverCfg := ca.writeServiceCert(t, dir, "dongome-api", 2)
+	workerCfg := ca.writeServiceCert(t, dir, "dongome-worker", 3)
+	searchCfg := ca.writeServiceCert(t, dir, "dongome-search", 4)
+
+	metrics := mtls.NewMetrics()
+	store, err := mtls.NewCertStore(serverCfg, metrics)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(mtls.Authorize(mtls.NewPolicy(map[string][]string{
+		"dongome-worker": {"GET /internal/v1/users/:id"},
+	}), metrics))
+	router.GET("/internal/v1/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, mtls.Caller(c))
+	})
+
+	server := httptest.NewUnstartedServer(router)
+	server.TLS = mtls.ServerTLSConfig(store, metrics)
+	server.StartTLS()
+	defer server.Close()
+
+	clientFor := func(cfg *config.InternalTLSConfig, serverIdentity string) *http.Client {
+		store, err := mtls.NewCertStore(cfg, metrics)
+		require.NoError(t, err)
+		return mtls.NewClient(store, serverIdentity, 5*time.Second, metrics)
+	}
+
+	// An allowed caller gets through and is identified
+	resp, err := clientFor(workerCfg, "dongome-api").Get(server.URL + "/internal/v1/users/u1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// A trusted service without permission for the route is forbidden
+	resp, err = clientFor(searchCfg, "dongome-api").Get(server.URL + "/internal/v1/users/u1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// The client refuses a server with an unexpected identity
+	_, err = clientFor(workerCfg, "dongome-payments").Get(server.URL + "/internal/v1/users/u1")
+	assert.Error(t, err)
+
+	// A client without a certificate cannot complete the handshake
+	_, err = server.Client().Get(server.URL + "/internal/v1/users/u1")
+	assert.Error(t, err)
+
+	snapshot := metrics.Snapshot()
+	assert.EqualValues(t, 1, snapshot.Denied)
+	assert.GreaterOrEqual(t, snapshot.Handshakes, int64(2))
+	assert.EqualValues(t, 2, snapshot.Clients["dongome-api"].Requests)
+	assert.EqualValues(t, 1, snapshot.Clients["dongome-payments"].Failures)
+}
+
+func TestCertStore_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	cfg := ca.writeServiceCert(t, dir, "dongome-api", 2)
+	cfg.ReloadInterval = time.Millisecond
+
+	metrics := mtls.NewMetrics()
+	store, err := mtls.NewCertStore(cfg, metrics)
+	require.NoError(t, err)
+	first := store.Certificate().Leaf.SerialNumber
+
+	// Rotate the certificate and make sure the change is visible to stat
+	ca.writeServiceCert(t, dir, "dongome-api", 5)
+	later := time.Now().Add(time.Minute)
+	for _, path := range []string{cfg.CertFile, cfg.KeyFile, cfg.CAFile} {
+		require.NoError(t, os.Chtimes(path, later, later))
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	rotated := store.Certificate().Leaf
+	assert.NotEqual(t, first, rotated.SerialNumber)
+	assert.Equal(t, "dongome-api", mtls.Identity(rotated))
+
+	snapshot := metrics.Snapshot()
+	assert.EqualValues(t, 1, snapshot.Reloads)
+	require.NotNil(t, snapshot.CertExpiresAt)
+	assert.True(t, snapshot.CertExpiresAt.Equal(rotated.NotAfter))
+}
@@ -0,0 +1,108 @@
+package mtls
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"dongome/pkg/config"
+	"dongome/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Server is the listener for service-to-service calls. With mTLS enabled it
+// only accepts clients presenting a certificate from the trusted CA, and each
+// route is limited to the callers allowed by the policy.
+type Server struct {
+	server  *http.Server
+	store   *CertStore
+	metrics *Metrics
+}
+
+// NewServer creates an internal server. register adds the internal routes to
+// a group that already enforces the caller policy when mTLS is enabled.
+func NewServer(cfg *config.InternalConfig, metrics *Metrics, register func(r *gin.RouterGroup)) (*Server, error) {
+	s := &Server{metrics: metrics}
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.GET("/internal/health", s.Health)
+
+	group := router.Group("")
+	if cfg.TLS.Enabled {
+		store, err := NewCertStore(&cfg.TLS, metrics)
+		if err != nil {
+			return nil, err
+		}
+		s.store = store
+		group.Use(Authorize(NewPolicy(cfg.AllowedCallers), metrics))
+	} else {
+		logger.Warn("Internal listener is running without mTLS; internal routes are unauthenticated")
+	}
+	register(group)
+
+	s.server = &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      router,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+		ConnState:    s.trackConnection,
+		ErrorLog:     log.New(&handshakeErrorCounter{metrics: metrics}, "", 0),
+	}
+	if s.store != nil {
+		s.server.TLSConfig = ServerTLSConfig(s.store, metrics)
+	}
+	return s, nil
+}
+
+// ListenAndServe serves until the server is shut down
+func (s *Server) ListenAndServe() error {
+	if s.store != nil {
+		// The certificate comes from the TLS configuration
+		return s.server.ListenAndServeTLS("", "")
+	}
+	return s.server.ListenAndServe()
+}
+
+// Shutdown gracefully stops the server
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// Health reports connection metrics for the internal listener
+func (s *Server) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "healthy",
+		"mtls":    s.store != nil,
+		"metrics": s.metrics.Snapshot(),
+	})
+}
+
+func (s *Server) trackConnection(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		s.metrics.activeConnections.Add(1)
+	case http.StateHijacked, http.StateClosed:
+		s.metrics.activeConnections.Add(-1)
+	}
+}
+
+// handshakeErrorCounter receives the http.Server error log, which is where
+// net/http reports failed TLS handshakes, and counts them
+type handshakeErrorCounter struct {
+	metrics *Metrics
+}
+
+func (w *handshakeErrorCounter) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte("TLS handshake error")) {
+		w.metrics.handshakeFailures.Add(1)
+	}
+	logger.Warn("Internal server error", zap.String("message", string(bytes.TrimSpace(p))))
+	return len(p), nil
+}
@@ -0,0 +1,139 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"dongome/pkg/config"
+	"dongome/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// CertStore holds the service certificate and the CA pool used to verify
+// peers. Rotated files are picked up on the next handshake after the reload
+// interval, so certificates can be renewed without a restart.
+type CertStore struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	interval time.Duration
+	metrics  *Metrics
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  [3]time.Time
+	checkedAt time.Time
+}
+
+// NewCertStore loads the certificate, key and CA bundle named in cfg
+func NewCertStore(cfg *config.InternalTLSConfig, metrics *Metrics) (*CertStore, error) {
+	store := &CertStore{
+		certFile: cfg.CertFile,
+		keyFile:  cfg.KeyFile,
+		caFile:   cfg.CAFile,
+		interval: cfg.ReloadInterval,
+		metrics:  metrics,
+	}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Certificate returns the current service certificate
+func (s *CertStore) Certificate() *tls.Certificate {
+	s.maybeReload()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cert
+}
+
+// Pool returns the current pool of trusted CAs
+func (s *CertStore) Pool() *x509.CertPool {
+	s.maybeReload()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pool
+}
+
+// maybeReload reloads the files if the reload interval has passed and any of
+// them changed. A failed reload keeps the previous certificate in service.
+func (s *CertStore) maybeReload() {
+	s.mu.RLock()
+	due := s.interval > 0 && time.Since(s.checkedAt) >= s.interval
+	s.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	modTimes, err := s.statFiles()
+	s.mu.Lock()
+	s.checkedAt = time.Now()
+	changed := err == nil && modTimes != s.modTimes
+	s.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	if err := s.load(); err != nil {
+		s.metrics.reloadFailures.Add(1)
+		logger.Error("Failed to reload internal TLS certificates", zap.Error(err))
+		return
+	}
+	s.metrics.reloads.Add(1)
+	logger.Info("Reloaded internal TLS certificates", zap.String("cert_file", s.certFile))
+}
+
+func (s *CertStore) load() error {
+	modTimes, err := s.statFiles()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("parse certificate: %w", err)
+	}
+	cert.Leaf = leaf
+
+	caPEM, err := os.ReadFile(s.caFile)
+	if err != nil {
+		return fmt.Errorf("read CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("no certificates found in %s", s.caFile)
+	}
+
+	s.mu.Lock()
+	s.cert = &cert
+	s.pool = pool
+	s.modTimes = modTimes
+	s.checkedAt = time.Now()
+	s.mu.Unlock()
+
+	s.metrics.setCertExpiry(leaf.NotAfter)
+	return nil
+}
+
+func (s *CertStore) statFiles() ([3]time.Time, error) {
+	var modTimes [3]time.Time
+	for i, path := range []string{s.certFile, s.keyFile, s.caFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
@@ -0,0 +1,67 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// ServerTLSConfig returns a TLS configuration that requires clients to present
+// a certificate signed by the trusted CA. Each handshake uses the store's
+// current certificate and CA pool.
+func ServerTLSConfig(store *CertStore, metrics *Metrics) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return store.Certificate(), nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*store.Certificate()},
+				ClientCAs:    store.Pool(),
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				VerifyConnection: func(tls.ConnectionState) error {
+					metrics.handshakes.Add(1)
+					return nil
+				},
+			}, nil
+		},
+	}
+}
+
+// ClientTLSConfig returns a TLS configuration that presents the service
+// certificate and only accepts a server whose identity is serverIdentity
+func ClientTLSConfig(store *CertStore, serverIdentity string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return store.Certificate(), nil
+		},
+		// Verification is done in VerifyConnection against the current CA pool
+		// and the server's service identity rather than its host name
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return fmt.Errorf("server presented no certificate")
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range state.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			leaf := state.PeerCertificates[0]
+			_, err := leaf.Verify(x509.VerifyOptions{
+				Roots:         store.Pool(),
+				Intermediates: intermediates,
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			})
+			if err != nil {
+				return err
+			}
+			if identity := Identity(leaf); identity != serverIdentity {
+				return fmt.Errorf("server identity %q does not match %q", identity, serverIdentity)
+			}
+			return nil
+		},
+	}
+}
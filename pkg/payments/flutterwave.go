@@ -0,0 +1,264 @@
+package payments
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/logger"
+	"dongome/pkg/money"
+
+	"go.uber.org/zap"
+)
+
+// FlutterwaveBaseURL is the Flutterwave API
+const FlutterwaveBaseURL = "https://api.flutterwave.com/v3"
+
+// FlutterwaveConfig locates the Flutterwave API and the account's keys
+type FlutterwaveConfig struct {
+	// BaseURL is FlutterwaveBaseURL when empty
+	BaseURL   string
+	SecretKey string
+	// WebhookHash is the secret hash set on the dashboard, which Flutterwave
+	// sends in the verif-hash header of every webhook
+	WebhookHash string
+	// RedirectURL is where payers return once they paid on Flutterwave's page
+	RedirectURL string
+	Timeout     time.Duration
+}
+
+// Flutterwave takes payments on Flutterwave's checkout page, by card or from
+// any Ghanaian Mobile Money wallet. The page asks Vodafone Cash payers for
+// their voucher itself.
+type Flutterwave struct {
+	api         apiClient
+	webhookHash string
+	redirectURL string
+}
+
+// NewFlutterwave creates a Flutterwave provider
+func NewFlutterwave(config FlutterwaveConfig) *Flutterwave {
+	if config.BaseURL == "" {
+		config.BaseURL = FlutterwaveBaseURL
+	}
+	return &Flutterwave{
+		api:         newAPIClient(config.BaseURL, config.SecretKey, config.Timeout),
+		webhookHash: config.WebhookHash,
+		redirectURL: config.RedirectURL,
+	}
+}
+
+// Name identifies Flutterwave on the payments it took
+func (p *Flutterwave) Name() string {
+	return ProviderFlutterwave
+}
+
+// flutterwaveTransaction is a transaction as Flutterwave reports it, in
+// lookups and in webhooks. Amounts are in major units.
+type flutterwaveTransaction struct {
+	ID                int64       `json:"id"`
+	TxRef             string      `json:"tx_ref"`
+	Status            string      `json:"status"`
+	Amount            json.Number `json:"amount"`
+	Currency          string      `json:"currency"`
+	ProcessorResponse string      `json:"processor_response"`
+}
+
+// RequestPayment opens a checkout page for the payment, offering only the
+// payment's method
+func (p *Flutterwave) RequestPayment(ctx context.Context, payment Request) (*Payment, error) {
+	if payment.Email == "" {
+		return nil, errors.ValidationError("an email address is required for this payment method")
+	}
+	options := "card"
+	if payment.Method != MethodCard {
+		options = "mobilemoneyghana"
+	}
+
+	txRef := transactionReference(payment.Reference)
+	var resp struct {
+		Data struct {
+			Link string `json:"link"`
+		} `json:"data"`
+	}
+	_, err := p.api.call(ctx, http.MethodPost, "/payments", map[string]interface{}{
+		"tx_ref":          txRef,
+		"amount":          payment.Amount.MajorString(),
+		"currency":        payment.Amount.Currency,
+		"redirect_url":    p.redirectURL,
+		"payment_options": options,
+		"customer": map[string]string{
+			"email":       payment.Email,
+			"phonenumber": payment.Payer,
+		},
+		"customizations": map[string]string{"title": payment.Description},
+	}, &resp)
+	if err == nil && resp.Data.Link == "" {
+		err = fmt.Errorf("no checkout page was returned")
+	}
+	if err != nil {
+		logger.Warn("Failed to start Flutterwave payment", zap.Error(err), logger.TraceID(ctx))
+		return nil, errors.UnavailableError("payments are temporarily unavailable")
+	}
+	return &Payment{Reference: txRef, Action: ActionCheckout, CheckoutURL: resp.Data.Link}, nil
+}
+
+// PaymentResult verifies a transaction by its tx_ref. Flutterwave only
+// creates the transaction once the payer submits the checkout page, so
+// unknown references are still pending.
+func (p *Flutterwave) PaymentResult(ctx context.Context, reference string) (*Result, error) {
+	transaction, err := p.transaction(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return &Result{Status: StatusPending}, nil
+	}
+	return transaction.result()
+}
+
+// Refund refunds part or all of a transaction to the card or wallet it was
+// paid from. Flutterwave refunds transactions by their ID, so it is looked
+// up first.
+func (p *Flutterwave) Refund(ctx context.Context, refund RefundRequest) (string, error) {
+	transaction, err := p.transaction(ctx, refund.PaymentReference)
+	if err == nil && transaction == nil {
+		err = fmt.Errorf("transaction %s not found", refund.PaymentReference)
+	}
+
+	var resp struct {
+		Data struct {
+			ID int64 `json:"id"`
+		} `json:"data"`
+	}
+	if err == nil {
+		path := "/transactions/" + strconv.FormatInt(transaction.ID, 10) + "/refund"
+		_, err = p.api.call(ctx, http.MethodPost, path, map[string]interface{}{
+			"amount":   refund.Amount.MajorString(),
+			"comments": refund.Description,
+		}, &resp)
+	}
+	if err == nil && resp.Data.ID == 0 {
+		err = fmt.Errorf("no refund was returned")
+	}
+	if err != nil {
+		logger.Warn("Failed to send Flutterwave refund", zap.Error(err), logger.TraceID(ctx))
+		return "", errors.UnavailableError("refunds are temporarily unavailable")
+	}
+	return strconv.FormatInt(resp.Data.ID, 10), nil
+}
+
+// RefundResult looks up a refund by its ID
+func (p *Flutterwave) RefundResult(ctx context.Context, reference string) (*Result, error) {
+	var resp struct {
+		Data struct {
+			ID             int64       `json:"id"`
+			Status         string      `json:"status"`
+			AmountRefunded json.Number `json:"amount_refunded"`
+			Currency       string      `json:"currency"`
+		} `json:"data"`
+	}
+	status, err := p.api.call(ctx, http.MethodGet, "/refunds/"+reference, nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("looking up flutterwave refund: %w", err)
+	}
+	if status == http.StatusNotFound {
+		return &Result{Status: StatusFailed, Reason: "NOT_FOUND"}, nil
+	}
+
+	amount, err := money.ParseMajor(resp.Data.AmountRefunded.String(), resp.Data.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("looking up flutterwave refund: unreadable amount %q", resp.Data.AmountRefunded)
+	}
+	result := &Result{Amount: amount}
+	switch resp.Data.Status {
+	case "completed":
+		result.Status = StatusSuccessful
+		result.TransactionID = strconv.FormatInt(resp.Data.ID, 10)
+	case "failed":
+		result.Status = StatusFailed
+		result.Reason = "REFUND_FAILED"
+	default:
+		result.Status = StatusPending
+	}
+	return result, nil
+}
+
+// ParseWebhook checks the secret hash Flutterwave sends in the verif-hash
+// header, and reads charge events
+func (p *Flutterwave) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	hash := header.Get("verif-hash")
+	if p.webhookHash == "" || subtle.ConstantTimeCompare([]byte(hash), []byte(p.webhookHash)) != 1 {
+		return nil, errors.UnauthorizedError("webhook signature is invalid")
+	}
+
+	var webhook struct {
+		Event string                 `json:"event"`
+		Data  flutterwaveTransaction `json:"data"`
+	}
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, errors.ValidationError("the payment webhook is invalid")
+	}
+	if webhook.Event != "charge.completed" {
+		return nil, ErrIgnoredEvent
+	}
+	if webhook.Data.TxRef == "" {
+		return nil, errors.ValidationError("the payment webhook is invalid")
+	}
+
+	result, err := webhook.Data.result()
+	if err != nil {
+		return nil, errors.ValidationError("the payment webhook is invalid")
+	}
+	return &Event{
+		Provider:         ProviderFlutterwave,
+		Reference:        referenceOf(webhook.Data.TxRef),
+		PaymentReference: webhook.Data.TxRef,
+		Status:           result.Status,
+		Amount:           result.Amount,
+		TransactionID:    result.TransactionID,
+		Reason:           result.Reason,
+	}, nil
+}
+
+// transaction verifies a transaction by its tx_ref, or returns nil if
+// Flutterwave does not know of it
+func (p *Flutterwave) transaction(ctx context.Context, txRef string) (*flutterwaveTransaction, error) {
+	var resp struct {
+		Data flutterwaveTransaction `json:"data"`
+	}
+	status, err := p.api.call(ctx, http.MethodGet, "/transactions/verify_by_reference?tx_ref="+url.QueryEscape(txRef), nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("verifying flutterwave transaction: %w", err)
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	return &resp.Data, nil
+}
+
+// result normalizes a Flutterwave transaction
+func (t flutterwaveTransaction) result() (*Result, error) {
+	amount, err := money.ParseMajor(t.Amount.String(), t.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("unreadable flutterwave amount %q", t.Amount)
+	}
+	result := &Result{Amount: amount}
+	switch t.Status {
+	case "successful":
+		result.Status = StatusSuccessful
+		result.TransactionID = strconv.FormatInt(t.ID, 10)
+	case "failed", "cancelled":
+		result.Status = StatusFailed
+		result.Reason = t.ProcessorResponse
+	default:
+		result.Status = StatusPending
+	}
+	return result, nil
+}
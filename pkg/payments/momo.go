@@ -0,0 +1,151 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/logger"
+	"dongome/pkg/momo"
+	"dongome/pkg/money"
+
+	"go.uber.org/zap"
+)
+
+// MoMo takes payments with MTN MoMo directly: the payer approves each payment on
+// their phone through the Collection API, and refunds are sent to their
+// wallet through the Disbursement API
+type MoMo struct {
+	client      *momo.Client
+	callbackURL string
+}
+
+// NewMoMo creates a MoMo provider reporting payment results to callbackURL
+func NewMoMo(client *momo.Client, callbackURL string) *MoMo {
+	return &MoMo{
+		client:      client,
+		callbackURL: callbackURL,
+	}
+}
+
+// Name identifies MoMo on the payments it took
+func (p *MoMo) Name() string {
+	return ProviderMoMo
+}
+
+// RequestPayment asks the payer to approve the payment on their phone
+func (p *MoMo) RequestPayment(ctx context.Context, payment Request) (*Payment, error) {
+	if payment.Method != MethodMoMo {
+		return nil, errors.ValidationError("the payment method is not available")
+	}
+	reference, err := p.client.RequestToPay(ctx, momo.PaymentRequest{
+		ExternalID:   payment.Reference,
+		Amount:       payment.Amount,
+		Payer:        payment.Payer,
+		PayerMessage: payment.Description,
+		PayeeNote:    payment.Description,
+		CallbackURL:  p.callbackURL,
+	})
+	if err != nil {
+		logger.Warn("Failed to request MoMo payment", zap.Error(err), logger.TraceID(ctx))
+		return nil, errors.UnavailableError("mobile money payments are temporarily unavailable")
+	}
+	return &Payment{Reference: reference, Action: ActionApprove}, nil
+}
+
+// PaymentResult looks up how a payment went. Payments MoMo does not know of
+// never reached the payer.
+func (p *MoMo) PaymentResult(ctx context.Context, reference string) (*Result, error) {
+	payment, err := p.client.GetPayment(ctx, reference)
+	if stderrors.Is(err, momo.ErrNotFound) {
+		return &Result{Status: StatusFailed, Reason: "NOT_FOUND"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return momoResult(payment), nil
+}
+
+// Refund sends money back to the payer's wallet
+func (p *MoMo) Refund(ctx context.Context, refund RefundRequest) (string, error) {
+	reference, err := p.client.Transfer(ctx, momo.TransferRequest{
+		ExternalID:   refund.Reference,
+		Amount:       refund.Amount,
+		Payee:        refund.Payee,
+		PayerMessage: refund.Description,
+		PayeeNote:    refund.Description,
+	})
+	if err != nil {
+		logger.Warn("Failed to send MoMo refund", zap.Error(err), logger.TraceID(ctx))
+		return "", errors.UnavailableError("mobile money refunds are temporarily unavailable")
+	}
+	return reference, nil
+}
+
+// RefundResult looks up how a refund's transfer went. Transfers MoMo does
+// not know of never left.
+func (p *MoMo) RefundResult(ctx context.Context, reference string) (*Result, error) {
+	transfer, err := p.client.GetTransfer(ctx, reference)
+	if stderrors.Is(err, momo.ErrNotFound) {
+		return &Result{Status: StatusFailed, Reason: "NOT_FOUND"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return momoResult(transfer), nil
+}
+
+// ParseWebhook reads a payment callback. MoMo callbacks carry no signature
+// of their own, so they must be authenticated with webhookauth before they
+// get here.
+func (p *MoMo) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	var callback struct {
+		ExternalID             string `json:"externalId"`
+		FinancialTransactionID string `json:"financialTransactionId"`
+		Amount                 string `json:"amount"`
+		Currency               string `json:"currency"`
+		Status                 string `json:"status"`
+		Reason                 string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &callback); err != nil || callback.ExternalID == "" || callback.Status == "" {
+		return nil, errors.ValidationError("the payment webhook is invalid")
+	}
+	amount, err := money.ParseMajor(callback.Amount, callback.Currency)
+	if err != nil {
+		return nil, errors.ValidationError("the payment webhook is invalid")
+	}
+	return &Event{
+		Provider:      ProviderMoMo,
+		Reference:     callback.ExternalID,
+		Status:        momoStatus(callback.Status),
+		Amount:        amount,
+		TransactionID: callback.FinancialTransactionID,
+		Reason:        callback.Reason,
+	}, nil
+}
+
+// momoResult normalizes a looked up MoMo transaction
+func momoResult(transaction *momo.Transaction) *Result {
+	return &Result{
+		Status:        momoStatus(transaction.Status),
+		Amount:        transaction.Amount,
+		TransactionID: transaction.FinancialTransactionID,
+		Reason:        transaction.Reason,
+	}
+}
+
+// momoStatus normalizes a MoMo status. Rejected and expired transactions
+// failed; statuses MoMo may add later are treated as pending.
+func momoStatus(status string) string {
+	switch strings.ToUpper(status) {
+	case momo.StatusSuccessful:
+		return StatusSuccessful
+	case momo.StatusFailed, "REJECTED", "TIMEOUT", "EXPIRED":
+		return StatusFailed
+	default:
+		return StatusPending
+	}
+}
@@ -0,0 +1,245 @@
+// Package payments takes payments through interchangeable providers. Each
+// provider starts payments, looks up how they went, refunds them and turns
+// its webhooks into the same Event, so checkout does not depend on which
+// provider a buyer pays through. A Registry picks the provider of each
+// payment method.
+package payments
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/money"
+)
+
+// Method is how a buyer pays
+type Method string
+
+const (
+	// MethodMoMo pays from an MTN Mobile Money wallet
+	MethodMoMo Method = "momo"
+	// MethodCard pays by debit or credit card
+	MethodCard Method = "card"
+	// MethodVodafoneCash pays from a Vodafone Cash wallet. The payer
+	// authorizes each payment with a voucher they generate by dialling *110#.
+	MethodVodafoneCash Method = "vodafone_cash"
+	// MethodAirtelTigoMoney pays from an AirtelTigo Money wallet
+	MethodAirtelTigoMoney Method = "airteltigo_money"
+)
+
+// Actions the payer takes to complete a payment
+const (
+	// ActionApprove has the payer approve the payment on their phone
+	ActionApprove = "approve_on_phone"
+	// ActionCheckout has the payer complete the payment on the checkout page
+	ActionCheckout = "checkout"
+	// ActionEnterCode has the payer enter a code, such as a Vodafone Cash
+	// voucher or a one-time password sent to their phone, which is then
+	// confirmed with the provider
+	ActionEnterCode = "enter_code"
+)
+
+// Statuses of payments and refunds, as every provider's are reported
+const (
+	StatusSuccessful = "SUCCESSFUL"
+	StatusFailed     = "FAILED"
+	StatusPending    = "PENDING"
+)
+
+// Statuses of chargebacks, as every provider's are reported. A chargeback is
+// won when it is decided for the merchant, and lost when the payer gets the
+// amount back.
+const (
+	ChargebackOpen = "OPEN"
+	ChargebackWon  = "WON"
+	ChargebackLost = "LOST"
+)
+
+// Names of the providers
+const (
+	ProviderMoMo        = "momo"
+	ProviderPaystack    = "paystack"
+	ProviderFlutterwave = "flutterwave"
+)
+
+// ErrIgnoredEvent is returned for webhooks that report neither the outcome of
+// a payment nor a chargeback, such as a provider's notices about transfers
+var ErrIgnoredEvent = stderrors.New("payments: the webhook is not about a payment")
+
+// Request asks a payer to pay
+type Request struct {
+	// Reference is our ID of what is paid for, returned with every Event of
+	// the payment
+	Reference string
+	Method    Method
+	Amount    money.Money
+	// Payer is the payer's phone number in international form, without the +.
+	// Mobile Money payments require it.
+	Payer string
+	// Email is the payer's email address. Card payments require it.
+	Email       string
+	Description string
+}
+
+// Payment is a payment a provider started
+type Payment struct {
+	// Reference is the provider's reference of the payment, to look it up by
+	Reference string
+	// Action is what the payer does next to complete the payment, empty
+	// when there is nothing left to do
+	Action string
+	// CheckoutURL is the page the payer completes the payment on, for
+	// providers that take payment details themselves
+	CheckoutURL string
+	// Instructions are the provider's instructions to the payer, such as how
+	// to generate a voucher
+	Instructions string
+}
+
+// Result is how a payment or refund went
+type Result struct {
+	Status string
+	Amount money.Money
+	// TransactionID is the provider's ID of a successful payment or refund
+	TransactionID string
+	// Reason is why a failed payment or refund failed
+	Reason string
+}
+
+// RefundRequest sends part or all of a payment back to the payer
+type RefundRequest struct {
+	// Reference is our ID of the refund
+	Reference string
+	// PaymentReference is the provider's reference of the payment refunded
+	PaymentReference string
+	Amount           money.Money
+	// Payee is the phone number Mobile Money refunds are sent to, in
+	// international form without the +
+	Payee       string
+	Description string
+}
+
+// Event is a provider's webhook about the outcome of a payment, or about a
+// chargeback of it, in the same form whichever provider sent it
+type Event struct {
+	Provider string
+	// Reference is our ID of what was paid for, as given in the Request
+	Reference string
+	// PaymentReference is the provider's reference of the payment
+	PaymentReference string
+	Status           string
+	Amount           money.Money
+	TransactionID    string
+	Reason           string
+	// Chargeback is set when the webhook reports the payer disputing the
+	// payment with their bank or wallet rather than its outcome. Status and
+	// Amount are then those of the chargeback.
+	Chargeback *Chargeback
+}
+
+// Chargeback is a payer disputing a payment, which the provider reverses
+// unless the merchant contests it with evidence
+type Chargeback struct {
+	// ID is the provider's ID of the dispute
+	ID     string
+	Status string
+	// Amount is what the payer claims back
+	Amount money.Money
+	Reason string
+	// EvidenceDueAt is when the provider needs the merchant's evidence by,
+	// if it set a deadline
+	EvidenceDueAt *time.Time
+}
+
+// PaymentProvider takes payments through one payment service. Methods
+// starting a transaction report the provider being unreachable as an
+// unavailable error.
+type PaymentProvider interface {
+	// Name identifies the provider on the payments it took
+	Name() string
+	RequestPayment(ctx context.Context, payment Request) (*Payment, error)
+	// PaymentResult looks up a payment by the provider's reference.
+	// Payments the provider does not know of never reached the payer and are
+	// reported as failed, unless the payer has yet to submit a checkout page.
+	PaymentResult(ctx context.Context, reference string) (*Result, error)
+	// Refund starts a refund and returns the provider's reference of it
+	Refund(ctx context.Context, refund RefundRequest) (string, error)
+	RefundResult(ctx context.Context, reference string) (*Result, error)
+	// ParseWebhook authenticates a webhook and normalizes it into an Event.
+	// It returns ErrIgnoredEvent for webhooks about anything else.
+	ParseWebhook(header http.Header, body []byte) (*Event, error)
+}
+
+// CodeConfirmer is implemented by providers whose payments may need a code
+// from the payer. ConfirmPayment submits the code for the payment with the
+// given reference and returns what the payer does next; a code the provider
+// refuses is a validation error.
+type CodeConfirmer interface {
+	ConfirmPayment(ctx context.Context, reference, code string) (*Payment, error)
+}
+
+// Registry holds the providers payments are taken through, and which of
+// them takes each payment method
+type Registry struct {
+	providers map[string]PaymentProvider
+	methods   map[Method]PaymentProvider
+}
+
+// NewRegistry creates a registry without providers
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: make(map[string]PaymentProvider),
+		methods:   make(map[Method]PaymentProvider),
+	}
+}
+
+// Use has the provider take payments by the given methods. A provider
+// registered without methods still refunds and looks up the payments it
+// took before.
+func (r *Registry) Use(provider PaymentProvider, methods ...Method) {
+	r.providers[provider.Name()] = provider
+	for _, method := range methods {
+		r.methods[method] = provider
+	}
+}
+
+// ForMethod returns the provider taking payments by a method
+func (r *Registry) ForMethod(method Method) (PaymentProvider, error) {
+	provider, ok := r.methods[method]
+	if !ok {
+		return nil, errors.UnavailableError("the payment method is not available")
+	}
+	return provider, nil
+}
+
+// Provider returns a provider by name
+func (r *Registry) Provider(name string) (PaymentProvider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, errors.UnavailableError("the payment provider is not available")
+	}
+	return provider, nil
+}
+
+// transactionReference returns a new reference of a payment for reference.
+// Providers taking card payments refuse to reuse a reference, and a buyer
+// may pay for the same order again after a failed payment.
+func transactionReference(reference string) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return reference + "-" + hex.EncodeToString(suffix)
+}
+
+// referenceOf returns the reference a transaction reference was made for
+func referenceOf(transactionReference string) string {
+	if i := strings.LastIndex(transactionReference, "-"); i > 0 {
+		return transactionReference[:i]
+	}
+	return transactionReference
+}
@@ -0,0 +1,215 @@
+package payments_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"dongome/pkg/momo"
+	"dongome/pkg/money"
+	"dongome/pkg/payments"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := payments.NewRegistry()
+	paystack := payments.NewPaystack(payments.PaystackConfig{SecretKey: "sk_test"})
+	registry.Use(payments.NewMoMo(nil, ""), payments.MethodMoMo)
+	registry.Use(paystack, payments.MethodCard)
+
+	provider, err := registry.ForMethod(payments.MethodCard)
+	require.NoError(t, err)
+	assert.Equal(t, payments.ProviderPaystack, provider.Name())
+	provider, err = registry.Provider(payments.ProviderMoMo)
+	require.NoError(t, err)
+	assert.Equal(t, payments.ProviderMoMo, provider.Name())
+
+	_, err = registry.Provider(payments.ProviderFlutterwave)
+	assert.Error(t, err)
+	_, err = registry.ForMethod("bank")
+	assert.Error(t, err)
+}
+
+func TestPaystack_RequestPaymentAndResult(t *testing.T) {
+	var initialized map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sk_test", r.Header.Get("Authorization"))
+		switch {
+		case r.URL.Path == "/transaction/initialize":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&initialized))
+			w.Write([]byte(`{"status":true,"data":{"authorization_url":"https://checkout.paystack.com/abc","reference":"` + initialized["reference"].(string) + `"}}`))
+		case r.URL.Path == "/transaction/verify/unknown":
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasPrefix(r.URL.Path, "/transaction/verify/"):
+			w.Write([]byte(`{"status":true,"data":{"id":42,"status":"success","amount":10000,"currency":"GHS"}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	provider := payments.NewPaystack(payments.PaystackConfig{BaseURL: server.URL, SecretKey: "sk_test", CallbackURL: "https://dongome.example/orders", Timeout: time.Second})
+	ctx := context.Background()
+
+	_, err := provider.RequestPayment(ctx, payments.Request{Reference: "order-1", Method: payments.MethodCard, Amount: money.Cedis(100)})
+	assert.Error(t, err, "Paystack needs the payer's email address")
+
+	payment, err := provider.RequestPayment(ctx, payments.Request{Reference: "order-1", Method: payments.MethodCard, Amount: money.Cedis(100), Email: "buyer@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://checkout.paystack.com/abc", payment.CheckoutURL)
+	assert.True(t, strings.HasPrefix(payment.Reference, "order-1-"), "each attempt gets its own reference")
+	assert.Equal(t, float64(10000), initialized["amount"], "Paystack takes amounts in pesewas")
+	assert.Equal(t, []interface{}{"card"}, initialized["channels"])
+
+	result, err := provider.PaymentResult(ctx, payment.Reference)
+	require.NoError(t, err)
+	assert.Equal(t, payments.StatusSuccessful, result.Status)
+	assert.Equal(t, money.Cedis(100), result.Amount)
+	assert.Equal(t, "42", result.TransactionID)
+
+	result, err = provider.PaymentResult(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Equal(t, payments.StatusFailed, result.Status)
+}
+
+func TestPaystack_VodafoneCashVoucher(t *testing.T) {
+	var charged map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/charge":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&charged))
+			w.Write([]byte(`{"status":true,"data":{"reference":"` + charged["reference"].(string) + `","status":"send_otp","display_text":"Dial *110# to generate a voucher"}}`))
+		case "/charge/submit_otp":
+			var submitted map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&submitted))
+			if submitted["otp"] != "123456" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"status":false,"message":"Charge attempted"}`))
+				return
+			}
+			w.Write([]byte(`{"status":true,"data":{"reference":"` + submitted["reference"] + `","status":"pay_offline","display_text":"Approve the payment on your phone"}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	provider := payments.NewPaystack(payments.PaystackConfig{BaseURL: server.URL, SecretKey: "sk_test", Timeout: time.Second})
+	ctx := context.Background()
+
+	payment, err := provider.RequestPayment(ctx, payments.Request{Reference: "order-1", Method: payments.MethodVodafoneCash, Amount: money.Cedis(100), Payer: "233201234567", Email: "buyer@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, payments.ActionEnterCode, payment.Action)
+	assert.Equal(t, "Dial *110# to generate a voucher", payment.Instructions)
+	assert.Empty(t, payment.CheckoutURL)
+	assert.Equal(t, map[string]interface{}{"phone": "0201234567", "provider": "vod"}, charged["mobile_money"])
+
+	_, err = provider.ConfirmPayment(ctx, payment.Reference, "000000")
+	assert.Error(t, err, "wrong vouchers are refused")
+
+	payment, err = provider.ConfirmPayment(ctx, payment.Reference, "123456")
+	require.NoError(t, err)
+	assert.Equal(t, payments.ActionApprove, payment.Action)
+	assert.True(t, strings.HasPrefix(payment.Reference, "order-1-"))
+}
+
+func TestPaystack_ParseWebhook(t *testing.T) {
+	provider := payments.NewPaystack(payments.PaystackConfig{SecretKey: "sk_test"})
+	body := []byte(`{"event":"charge.success","data":{"id":42,"reference":"6f1c2d3e-0000-4000-8000-000000000001-a1b2c3d4","status":"success","amount":10000,"currency":"GHS"}}`)
+	sign := func(body []byte) http.Header {
+		mac := hmac.New(sha512.New, []byte("sk_test"))
+		mac.Write(body)
+		return http.Header{"X-Paystack-Signature": []string{hex.EncodeToString(mac.Sum(nil))}}
+	}
+
+	_, err := provider.ParseWebhook(http.Header{"X-Paystack-Signature": []string{"00"}}, body)
+	assert.Error(t, err, "webhooks must be signed with the secret key")
+
+	event, err := provider.ParseWebhook(sign(body), body)
+	require.NoError(t, err)
+	assert.Equal(t, payments.ProviderPaystack, event.Provider)
+	assert.Equal(t, "6f1c2d3e-0000-4000-8000-000000000001", event.Reference)
+	assert.Equal(t, "6f1c2d3e-0000-4000-8000-000000000001-a1b2c3d4", event.PaymentReference)
+	assert.Equal(t, payments.StatusSuccessful, event.Status)
+	assert.Equal(t, money.Cedis(100), event.Amount)
+
+	transfer := []byte(`{"event":"transfer.success","data":{}}`)
+	_, err = provider.ParseWebhook(sign(transfer), transfer)
+	assert.ErrorIs(t, err, payments.ErrIgnoredEvent)
+
+	dispute := []byte(`{"event":"charge.dispute.create","data":{"id":358950,"refund_amount":4000,"currency":"GHS","status":"awaiting-merchant-feedback","category":"fraud","due_at":"2026-05-20T00:00:00.000Z","transaction":{"id":42,"reference":"6f1c2d3e-0000-4000-8000-000000000001-a1b2c3d4","amount":10000,"currency":"GHS"}}}`)
+	event, err = provider.ParseWebhook(sign(dispute), dispute)
+	require.NoError(t, err)
+	require.NotNil(t, event.Chargeback)
+	assert.Equal(t, "6f1c2d3e-0000-4000-8000-000000000001", event.Reference)
+	assert.Equal(t, "358950", event.Chargeback.ID)
+	assert.Equal(t, payments.ChargebackOpen, event.Chargeback.Status)
+	assert.Equal(t, money.Cedis(40), event.Chargeback.Amount)
+	assert.Equal(t, "fraud", event.Chargeback.Reason)
+	require.NotNil(t, event.Chargeback.EvidenceDueAt)
+	assert.Equal(t, time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC), *event.Chargeback.EvidenceDueAt)
+
+	resolved := []byte(`{"event":"charge.dispute.resolve","data":{"id":358950,"refund_amount":4000,"currency":"GHS","status":"resolved","resolution":"declined","transaction":{"id":42,"reference":"6f1c2d3e-0000-4000-8000-000000000001-a1b2c3d4"}}}`)
+	event, err = provider.ParseWebhook(sign(resolved), resolved)
+	require.NoError(t, err)
+	assert.Equal(t, payments.ChargebackWon, event.Chargeback.Status, "disputes Paystack declined were won")
+}
+
+func TestFlutterwave_ParseWebhook(t *testing.T) {
+	provider := payments.NewFlutterwave(payments.FlutterwaveConfig{SecretKey: "FLWSECK_TEST", WebhookHash: "hash-1"})
+	body := []byte(`{"event":"charge.completed","data":{"id":7,"tx_ref":"order-1-a1b2c3d4","status":"failed","amount":100,"currency":"GHS","processor_response":"Declined"}}`)
+
+	_, err := provider.ParseWebhook(http.Header{"Verif-Hash": []string{"wrong"}}, body)
+	assert.Error(t, err, "webhooks must carry the secret hash")
+
+	event, err := provider.ParseWebhook(http.Header{"Verif-Hash": []string{"hash-1"}}, body)
+	require.NoError(t, err)
+	assert.Equal(t, "order-1", event.Reference)
+	assert.Equal(t, payments.StatusFailed, event.Status)
+	assert.Equal(t, "Declined", event.Reason)
+	assert.Equal(t, money.Cedis(100), event.Amount)
+}
+
+func TestFlutterwave_PaymentResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer FLWSECK_TEST", r.Header.Get("Authorization"))
+		if r.URL.Query().Get("tx_ref") == "order-1-a1b2c3d4" {
+			w.Write([]byte(`{"status":"success","data":{"id":7,"tx_ref":"order-1-a1b2c3d4","status":"successful","amount":100.5,"currency":"GHS"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	provider := payments.NewFlutterwave(payments.FlutterwaveConfig{BaseURL: server.URL, SecretKey: "FLWSECK_TEST", Timeout: time.Second})
+
+	result, err := provider.PaymentResult(context.Background(), "order-1-a1b2c3d4")
+	require.NoError(t, err)
+	assert.Equal(t, payments.StatusSuccessful, result.Status)
+	assert.Equal(t, money.New(10050, "GHS"), result.Amount)
+	assert.Equal(t, "7", result.TransactionID)
+
+	// The transaction only exists once the payer submits the checkout page
+	result, err = provider.PaymentResult(context.Background(), "order-2-a1b2c3d4")
+	require.NoError(t, err)
+	assert.Equal(t, payments.StatusPending, result.Status)
+}
+
+func TestMoMo_ParseWebhook(t *testing.T) {
+	provider := payments.NewMoMo(momo.NewClient(momo.Config{}), "")
+
+	event, err := provider.ParseWebhook(nil, []byte(`{"externalId":"order-1","financialTransactionId":"ftx-1","amount":"100.00","currency":"GHS","status":"REJECTED"}`))
+	require.NoError(t, err)
+	assert.Equal(t, payments.ProviderMoMo, event.Provider)
+	assert.Equal(t, "order-1", event.Reference)
+	assert.Equal(t, payments.StatusFailed, event.Status, "rejected payments failed")
+
+	_, err = provider.ParseWebhook(nil, []byte(`{"status":"SUCCESSFUL"}`))
+	assert.Error(t, err)
+}
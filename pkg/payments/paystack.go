@@ -0,0 +1,365 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"dongome/pkg/errors"
+	"dongome/pkg/logger"
+	"dongome/pkg/money"
+
+	"go.uber.org/zap"
+)
+
+// PaystackBaseURL is the Paystack API
+const PaystackBaseURL = "https://api.paystack.co"
+
+// PaystackConfig locates the Paystack API and the account's secret key
+type PaystackConfig struct {
+	// BaseURL is PaystackBaseURL when empty
+	BaseURL   string
+	SecretKey string
+	// CallbackURL is where payers return once they paid on Paystack's page
+	CallbackURL string
+	Timeout     time.Duration
+}
+
+// Paystack takes card and MTN Mobile Money payments on Paystack's checkout
+// page, and charges Vodafone Cash and AirtelTigo Money wallets directly so
+// the payer can be asked for a voucher or one-time password at checkout. Its
+// webhooks are signed with the account's secret key.
+type Paystack struct {
+	api         apiClient
+	callbackURL string
+}
+
+// NewPaystack creates a Paystack provider
+func NewPaystack(config PaystackConfig) *Paystack {
+	if config.BaseURL == "" {
+		config.BaseURL = PaystackBaseURL
+	}
+	return &Paystack{
+		api:         newAPIClient(config.BaseURL, config.SecretKey, config.Timeout),
+		callbackURL: config.CallbackURL,
+	}
+}
+
+// Name identifies Paystack on the payments it took
+func (p *Paystack) Name() string {
+	return ProviderPaystack
+}
+
+// paystackTransaction is a transaction as Paystack reports it, in lookups
+// and in webhooks. Amounts are in minor units.
+type paystackTransaction struct {
+	ID              int64  `json:"id"`
+	Reference       string `json:"reference"`
+	Status          string `json:"status"`
+	Amount          int64  `json:"amount"`
+	Currency        string `json:"currency"`
+	GatewayResponse string `json:"gateway_response"`
+}
+
+// paystackNetworks are Paystack's codes of the Mobile Money networks charged
+// directly
+var paystackNetworks = map[Method]string{
+	MethodVodafoneCash:    "vod",
+	MethodAirtelTigoMoney: "atl",
+}
+
+// RequestPayment opens a checkout page for the payment, offering only the
+// payment's method, or charges the payer's wallet directly on networks whose
+// payers may need to enter a code
+func (p *Paystack) RequestPayment(ctx context.Context, payment Request) (*Payment, error) {
+	if payment.Email == "" {
+		return nil, errors.ValidationError("an email address is required for this payment method")
+	}
+	if network, ok := paystackNetworks[payment.Method]; ok {
+		return p.charge(ctx, "/charge", map[string]interface{}{
+			"email":     payment.Email,
+			"amount":    payment.Amount.Amount,
+			"currency":  payment.Amount.Currency,
+			"reference": transactionReference(payment.Reference),
+			"mobile_money": map[string]string{
+				"phone":    localPhone(payment.Payer),
+				"provider": network,
+			},
+			"metadata": map[string]string{"description": payment.Description},
+		}, "the payment was declined")
+	}
+	channel := "card"
+	if payment.Method == MethodMoMo {
+		channel = "mobile_money"
+	}
+
+	var resp struct {
+		Data struct {
+			AuthorizationURL string `json:"authorization_url"`
+			Reference        string `json:"reference"`
+		} `json:"data"`
+	}
+	_, err := p.api.call(ctx, http.MethodPost, "/transaction/initialize", map[string]interface{}{
+		"email":        payment.Email,
+		"amount":       payment.Amount.Amount,
+		"currency":     payment.Amount.Currency,
+		"reference":    transactionReference(payment.Reference),
+		"callback_url": p.callbackURL,
+		"channels":     []string{channel},
+		"metadata":     map[string]string{"description": payment.Description},
+	}, &resp)
+	if err == nil && resp.Data.AuthorizationURL == "" {
+		err = fmt.Errorf("no checkout page was returned")
+	}
+	if err != nil {
+		logger.Warn("Failed to start Paystack payment", zap.Error(err), logger.TraceID(ctx))
+		return nil, errors.UnavailableError("payments are temporarily unavailable")
+	}
+	return &Payment{Reference: resp.Data.Reference, Action: ActionCheckout, CheckoutURL: resp.Data.AuthorizationURL}, nil
+}
+
+// ConfirmPayment submits the voucher or one-time password a direct charge
+// asked the payer for
+func (p *Paystack) ConfirmPayment(ctx context.Context, reference, code string) (*Payment, error) {
+	return p.charge(ctx, "/charge/submit_otp", map[string]string{
+		"reference": reference,
+		"otp":       code,
+	}, "the code was not accepted")
+}
+
+// charge starts a direct charge or continues it, and reads what the payer
+// does next. Paystack refuses declined charges and wrong codes with 400 Bad
+// Request, which are reported as a validation error with the declined
+// message.
+func (p *Paystack) charge(ctx context.Context, path string, body interface{}, declined string) (*Payment, error) {
+	var resp struct {
+		Data struct {
+			Reference   string `json:"reference"`
+			Status      string `json:"status"`
+			DisplayText string `json:"display_text"`
+		} `json:"data"`
+	}
+	status, err := p.api.call(ctx, http.MethodPost, path, body, &resp)
+	if status == http.StatusBadRequest || (err == nil && resp.Data.Status == "failed") {
+		return nil, errors.ValidationError(declined)
+	}
+	if err != nil {
+		logger.Warn("Failed to charge with Paystack", zap.Error(err), logger.TraceID(ctx))
+		return nil, errors.UnavailableError("payments are temporarily unavailable")
+	}
+
+	payment := &Payment{Reference: resp.Data.Reference, Instructions: resp.Data.DisplayText}
+	switch resp.Data.Status {
+	case "send_otp":
+		payment.Action = ActionEnterCode
+	case "success":
+		// Nothing is left to do; the webhook completes the payment
+	default:
+		// pay_offline and pending charges are approved on the payer's phone
+		payment.Action = ActionApprove
+	}
+	return payment, nil
+}
+
+// PaymentResult verifies a transaction by its reference
+func (p *Paystack) PaymentResult(ctx context.Context, reference string) (*Result, error) {
+	var resp struct {
+		Data paystackTransaction `json:"data"`
+	}
+	status, err := p.api.call(ctx, http.MethodGet, "/transaction/verify/"+reference, nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("verifying paystack transaction: %w", err)
+	}
+	if status == http.StatusNotFound {
+		return &Result{Status: StatusFailed, Reason: "NOT_FOUND"}, nil
+	}
+	return resp.Data.result(), nil
+}
+
+// Refund refunds part or all of a transaction to the card or wallet it was
+// paid from
+func (p *Paystack) Refund(ctx context.Context, refund RefundRequest) (string, error) {
+	var resp struct {
+		Data struct {
+			ID int64 `json:"id"`
+		} `json:"data"`
+	}
+	_, err := p.api.call(ctx, http.MethodPost, "/refund", map[string]interface{}{
+		"transaction":   refund.PaymentReference,
+		"amount":        refund.Amount.Amount,
+		"currency":      refund.Amount.Currency,
+		"merchant_note": refund.Description,
+	}, &resp)
+	if err == nil && resp.Data.ID == 0 {
+		err = fmt.Errorf("no refund was returned")
+	}
+	if err != nil {
+		logger.Warn("Failed to send Paystack refund", zap.Error(err), logger.TraceID(ctx))
+		return "", errors.UnavailableError("refunds are temporarily unavailable")
+	}
+	return strconv.FormatInt(resp.Data.ID, 10), nil
+}
+
+// RefundResult looks up a refund by its ID
+func (p *Paystack) RefundResult(ctx context.Context, reference string) (*Result, error) {
+	var resp struct {
+		Data struct {
+			ID       int64  `json:"id"`
+			Status   string `json:"status"`
+			Amount   int64  `json:"amount"`
+			Currency string `json:"currency"`
+		} `json:"data"`
+	}
+	status, err := p.api.call(ctx, http.MethodGet, "/refund/"+reference, nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("looking up paystack refund: %w", err)
+	}
+	if status == http.StatusNotFound {
+		return &Result{Status: StatusFailed, Reason: "NOT_FOUND"}, nil
+	}
+
+	result := &Result{Amount: money.New(resp.Data.Amount, resp.Data.Currency)}
+	switch resp.Data.Status {
+	case "processed":
+		result.Status = StatusSuccessful
+		result.TransactionID = strconv.FormatInt(resp.Data.ID, 10)
+	case "failed":
+		result.Status = StatusFailed
+		result.Reason = "REFUND_FAILED"
+	default:
+		result.Status = StatusPending
+	}
+	return result, nil
+}
+
+// ParseWebhook checks the HMAC-SHA512 signature Paystack computes over the
+// body with the secret key, and reads charge and dispute events
+func (p *Paystack) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	signature, err := hex.DecodeString(header.Get("X-Paystack-Signature"))
+	mac := hmac.New(sha512.New, []byte(p.api.secretKey))
+	mac.Write(body)
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.UnauthorizedError("webhook signature is invalid")
+	}
+
+	var webhook struct {
+		Event string          `json:"event"`
+		Data  json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, errors.ValidationError("the payment webhook is invalid")
+	}
+	if strings.HasPrefix(webhook.Event, "charge.dispute.") {
+		return parsePaystackDispute(webhook.Data)
+	}
+	if !strings.HasPrefix(webhook.Event, "charge.") {
+		return nil, ErrIgnoredEvent
+	}
+
+	var transaction paystackTransaction
+	if err := json.Unmarshal(webhook.Data, &transaction); err != nil {
+		return nil, errors.ValidationError("the payment webhook is invalid")
+	}
+	if transaction.Reference == "" {
+		return nil, errors.ValidationError("the payment webhook is invalid")
+	}
+
+	result := transaction.result()
+	return &Event{
+		Provider:         ProviderPaystack,
+		Reference:        referenceOf(transaction.Reference),
+		PaymentReference: transaction.Reference,
+		Status:           result.Status,
+		Amount:           result.Amount,
+		TransactionID:    result.TransactionID,
+		Reason:           result.Reason,
+	}, nil
+}
+
+// paystackDispute is the part of a Paystack dispute read from its webhooks
+type paystackDispute struct {
+	ID           int64               `json:"id"`
+	RefundAmount int64               `json:"refund_amount"`
+	Currency     string              `json:"currency"`
+	Status       string              `json:"status"`
+	Resolution   string              `json:"resolution"`
+	Category     string              `json:"category"`
+	DueAt        *time.Time          `json:"due_at"`
+	Transaction  paystackTransaction `json:"transaction"`
+}
+
+// parsePaystackDispute reads the charge.dispute events Paystack sends when a
+// payer disputes a payment, reminds of the evidence due, and resolves the
+// dispute. Disputes Paystack declined were won; those the merchant accepted,
+// or left unanswered, were lost.
+func parsePaystackDispute(data json.RawMessage) (*Event, error) {
+	var dispute paystackDispute
+	if err := json.Unmarshal(data, &dispute); err != nil || dispute.ID == 0 || dispute.Transaction.Reference == "" {
+		return nil, errors.ValidationError("the payment webhook is invalid")
+	}
+
+	chargeback := &Chargeback{
+		ID:            strconv.FormatInt(dispute.ID, 10),
+		Status:        ChargebackOpen,
+		Amount:        money.New(dispute.RefundAmount, dispute.Currency),
+		Reason:        dispute.Category,
+		EvidenceDueAt: dispute.DueAt,
+	}
+	if dispute.RefundAmount == 0 {
+		chargeback.Amount = money.New(dispute.Transaction.Amount, dispute.Transaction.Currency)
+	}
+	if chargeback.Reason == "" {
+		chargeback.Reason = "chargeback"
+	}
+	if dispute.Status == "resolved" {
+		chargeback.Status = ChargebackLost
+		if dispute.Resolution == "declined" {
+			chargeback.Status = ChargebackWon
+		}
+	}
+
+	return &Event{
+		Provider:         ProviderPaystack,
+		Reference:        referenceOf(dispute.Transaction.Reference),
+		PaymentReference: dispute.Transaction.Reference,
+		Status:           chargeback.Status,
+		Amount:           chargeback.Amount,
+		TransactionID:    strconv.FormatInt(dispute.Transaction.ID, 10),
+		Reason:           chargeback.Reason,
+		Chargeback:       chargeback,
+	}, nil
+}
+
+// localPhone turns a Ghanaian phone number in international form without the
+// +, as payers are given, into the local form Paystack charges
+func localPhone(phone string) string {
+	if strings.HasPrefix(phone, "233") {
+		return "0" + strings.TrimPrefix(phone, "233")
+	}
+	return phone
+}
+
+// result normalizes a Paystack transaction. Reversed transactions failed;
+// abandoned ones are still pending, as the payer may yet complete the
+// checkout page.
+func (t paystackTransaction) result() *Result {
+	result := &Result{Amount: money.New(t.Amount, t.Currency)}
+	switch t.Status {
+	case "success":
+		result.Status = StatusSuccessful
+		result.TransactionID = strconv.FormatInt(t.ID, 10)
+	case "failed", "reversed":
+		result.Status = StatusFailed
+		result.Reason = t.GatewayResponse
+	default:
+		result.Status = StatusPending
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limiter allows a fixed number of requests per key within a time window
+type Limiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]*fixedWindow
+}
+
+type fixedWindow struct {
+	start time.Time
+	count int
+}
+
+// NewLimiter creates a new limiter allowing limit requests per key per window
+func NewLimiter(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*fixedWindow),
+	}
+}
+
+// Allow records a request for key and reports whether it is within the limit.
+// When it is not, it also returns how long until the key may retry.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.evictExpired(now)
+
+	w, ok := l.windows[key]
+	if !ok {
+		w = &fixedWindow{start: now}
+		l.windows[key] = w
+	}
+
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// evictExpired drops the windows that have ended so memory stays bounded
+func (l *Limiter) evictExpired(now time.Time) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+}
+
+// PerClientIP rejects requests from a client IP that exceeded the limiter
+func PerClientIP(limiter *Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.Allow(c.ClientIP())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds()+1)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": i18n.Localize(c, "too many requests"), "code": "RATE_LIMITED"})
+			return
+		}
+		c.Next()
+	}
+}
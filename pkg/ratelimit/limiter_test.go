@@ -0,0 +1,40 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"dongome/pkg/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter := ratelimit.NewLimiter(2, time.Minute)
+
+	allowed, _ := limiter.Allow("10.0.0.1")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("10.0.0.1")
+	assert.True(t, allowed)
+
+	allowed, retryAfter := limiter.Allow("10.0.0.1")
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, time.Duration(0))
+
+	// Keys are limited independently
+	allowed, _ = limiter.Allow("10.0.0.2")
+	assert.True(t, allowed)
+}
+
+func TestLimiter_WindowResets(t *testing.T) {
+	limiter := ratelimit.NewLimiter(1, 20*time.Millisecond)
+
+	allowed, _ := limiter.Allow("client")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("client")
+	assert.False(t, allowed)
+
+	time.Sleep(30 * time.Millisecond)
+	allowed, _ = limiter.Allow("client")
+	assert.True(t, allowed)
+}
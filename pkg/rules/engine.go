@@ -0,0 +1,352 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"dongome/pkg/db"
+	"dongome/pkg/errors"
+	"dongome/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxSimulationCases caps how many historical cases a simulation replays
+	maxSimulationCases = 10000
+	// maxSimulationSamples caps how many changed cases a simulation returns
+	maxSimulationSamples = 20
+)
+
+// RuleView describes a rule and the expression in force
+type RuleView struct {
+	Key         string     `json:"key"`
+	Description string     `json:"description"`
+	Kind        Kind       `json:"kind"`
+	Variables   []Variable `json:"variables"`
+	Default     string     `json:"default"`
+	Expression  string     `json:"expression"`
+	// Custom is set when an administrator replaced the default
+	Custom      bool       `json:"custom"`
+	Simulatable bool       `json:"simulatable"`
+	UpdatedBy   string     `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// Simulation compares a candidate expression with the one in force over
+// historical cases
+type Simulation struct {
+	Key       string    `json:"key"`
+	Current   string    `json:"current"`
+	Candidate string    `json:"candidate"`
+	Since     time.Time `json:"since"`
+	Cases     int       `json:"cases"`
+	Changed   int       `json:"changed"`
+	Failed    int       `json:"failed"`
+	// CurrentMatches and CandidateMatches count the cases each expression
+	// holds for; they are only set for true/false rules
+	CurrentMatches   int             `json:"current_matches,omitempty"`
+	CandidateMatches int             `json:"candidate_matches,omitempty"`
+	Samples          []SimulatedCase `json:"samples"`
+}
+
+// SimulatedCase is a historical case whose outcome the candidate changes
+type SimulatedCase struct {
+	Case
+	Current   interface{} `json:"current"`
+	Candidate interface{} `json:"candidate"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// registered is a rule definition with its compiled expressions
+type registered struct {
+	definition Definition
+	history    History
+	fallback   *Expression
+	current    *Expression
+	rule       *Rule
+}
+
+// Engine evaluates rules for the services that consume them. Rules set by
+// administrators are loaded from the store by Reload, so every instance picks
+// up changes when it next reloads.
+type Engine struct {
+	store Store
+
+	mu    sync.RWMutex
+	rules map[string]*registered
+}
+
+// NewEngine creates an engine with no rules registered
+func NewEngine(store Store) *Engine {
+	return &Engine{
+		store: store,
+		rules: make(map[string]*registered),
+	}
+}
+
+// Register adds a rule a service consults. history may be nil if the rule
+// cannot be simulated. Register panics if the default expression is invalid,
+// since defaults ship with the code.
+func (e *Engine) Register(definition Definition, history History) {
+	fallback, err := compileFor(definition, definition.Default)
+	if err != nil {
+		panic(fmt.Sprintf("rules: invalid default for %s: %v", definition.Key, err))
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules[definition.Key] = &registered{
+		definition: definition,
+		history:    history,
+		fallback:   fallback,
+		current:    fallback,
+	}
+}
+
+// Reload loads the rules set by administrators. A stored expression that no
+// longer compiles, for example because a variable was removed, is skipped in
+// favor of the default.
+func (e *Engine) Reload(ctx context.Context) error {
+	stored, err := e.store.FindAll()
+	if err != nil {
+		return err
+	}
+	byKey := make(map[string]*Rule, len(stored))
+	for _, rule := range stored {
+		byKey[rule.Key] = rule
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for key, r := range e.rules {
+		rule, ok := byKey[key]
+		if !ok {
+			r.current, r.rule = r.fallback, nil
+			continue
+		}
+		expression, err := compileFor(r.definition, rule.Expression)
+		if err != nil {
+			logger.Error("Stored business rule is invalid, using its default", zap.String("rule", key), zap.Error(err))
+			r.current, r.rule = r.fallback, nil
+			continue
+		}
+		r.current, r.rule = expression, rule
+	}
+	return nil
+}
+
+// Number evaluates a number rule. If the expression in force fails, the
+// default is used; if that fails too, Number returns zero.
+func (e *Engine) Number(key string, facts Facts) float64 {
+	value, _ := e.evaluate(key, KindNumber, facts).(float64)
+	return value
+}
+
+// Bool evaluates a true/false rule. If the expression in force fails, the
+// default is used; if that fails too, Bool returns false.
+func (e *Engine) Bool(key string, facts Facts) bool {
+	value, _ := e.evaluate(key, KindBool, facts).(bool)
+	return value
+}
+
+// evaluate evaluates the expression in force for a rule, falling back to its default
+func (e *Engine) evaluate(key string, kind Kind, facts Facts) interface{} {
+	e.mu.RLock()
+	r, ok := e.rules[key]
+	var current, fallback *Expression
+	if ok {
+		current, fallback = r.current, r.fallback
+	}
+	e.mu.RUnlock()
+
+	if !ok || r.definition.Kind != kind {
+		logger.Error("Business rule is not registered", zap.String("rule", key), zap.String("kind", string(kind)))
+		return nil
+	}
+
+	value, err := current.Eval(facts)
+	if err == nil {
+		return value
+	}
+	logger.Warn("Business rule failed, using its default", zap.String("rule", key), zap.Error(err))
+	value, err = fallback.Eval(facts)
+	if err != nil {
+		logger.Error("Business rule default failed", zap.String("rule", key), zap.Error(err))
+		return nil
+	}
+	return value
+}
+
+// List describes every registered rule, ordered by key
+func (e *Engine) List() []*RuleView {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	views := make([]*RuleView, 0, len(e.rules))
+	for _, r := range e.rules {
+		views = append(views, r.view())
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Key < views[j].Key })
+	return views
+}
+
+// Get describes one rule
+func (e *Engine) Get(key string) (*RuleView, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	r, ok := e.rules[key]
+	if !ok {
+		return nil, errors.NotFoundError("rule not found")
+	}
+	return r.view(), nil
+}
+
+// Set replaces a rule's expression. It takes effect on this instance at once
+// and on the others when they next reload.
+func (e *Engine) Set(ctx context.Context, key, expression, updatedBy string) (*RuleView, error) {
+	r, err := e.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := compileFor(r.definition, expression)
+	if err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+
+	now := time.Now()
+	rule := &Rule{Key: key, Expression: compiled.String(), UpdatedBy: updatedBy, CreatedAt: now, UpdatedAt: now}
+	if err := db.WithRetry(ctx, func() error { return e.store.Save(rule) }); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	r.current, r.rule = compiled, rule
+	return r.view(), nil
+}
+
+// Reset removes an administrator's expression so the default applies again
+func (e *Engine) Reset(ctx context.Context, key string) (*RuleView, error) {
+	r, err := e.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithRetry(ctx, func() error { return e.store.Delete(key) }); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	r.current, r.rule = r.fallback, nil
+	return r.view(), nil
+}
+
+// Simulate evaluates a candidate expression and the one in force over the
+// rule's cases since the given time, without changing anything
+func (e *Engine) Simulate(ctx context.Context, key, expression string, since time.Time) (*Simulation, error) {
+	r, err := e.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	if r.history == nil {
+		return nil, errors.ValidationError("this rule cannot be simulated")
+	}
+	candidate, err := compileFor(r.definition, expression)
+	if err != nil {
+		return nil, errors.ValidationError(err.Error())
+	}
+
+	e.mu.RLock()
+	current := r.current
+	e.mu.RUnlock()
+
+	cases, err := r.history.Cases(ctx, since, maxSimulationCases)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &Simulation{
+		Key:       key,
+		Current:   current.String(),
+		Candidate: candidate.String(),
+		Since:     since,
+		Cases:     len(cases),
+		Samples:   []SimulatedCase{},
+	}
+	for _, c := range cases {
+		currentValue, _ := current.Eval(c.Facts)
+		candidateValue, err := candidate.Eval(c.Facts)
+		if err != nil {
+			simulation.Failed++
+		}
+		if r.definition.Kind == KindBool {
+			if matched, _ := currentValue.(bool); matched {
+				simulation.CurrentMatches++
+			}
+			if matched, _ := candidateValue.(bool); matched {
+				simulation.CandidateMatches++
+			}
+		}
+		if err == nil && currentValue == candidateValue {
+			continue
+		}
+
+		simulation.Changed++
+		if len(simulation.Samples) < maxSimulationSamples {
+			sample := SimulatedCase{Case: c, Current: currentValue, Candidate: candidateValue}
+			if err != nil {
+				sample.Error = err.Error()
+			}
+			simulation.Samples = append(simulation.Samples, sample)
+		}
+	}
+	return simulation, nil
+}
+
+// lookup finds a registered rule
+func (e *Engine) lookup(key string) (*registered, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	r, ok := e.rules[key]
+	if !ok {
+		return nil, errors.NotFoundError("rule not found")
+	}
+	return r, nil
+}
+
+// view describes the rule. The caller must hold the engine's lock.
+func (r *registered) view() *RuleView {
+	view := &RuleView{
+		Key:         r.definition.Key,
+		Description: r.definition.Description,
+		Kind:        r.definition.Kind,
+		Variables:   r.definition.Variables,
+		Default:     r.fallback.String(),
+		Expression:  r.current.String(),
+		Simulatable: r.history != nil,
+	}
+	if r.rule != nil {
+		view.Custom = true
+		view.UpdatedBy = r.rule.UpdatedBy
+		view.UpdatedAt = &r.rule.UpdatedAt
+	}
+	return view
+}
+
+// compileFor compiles an expression for a rule and checks its type
+func compileFor(definition Definition, source string) (*Expression, error) {
+	expression, err := Compile(source, definition.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule expression: %v", err)
+	}
+	if expression.Kind() != definition.Kind {
+		return nil, fmt.Errorf("rule expression must produce a %s", definition.Kind)
+	}
+	return expression, nil
+}
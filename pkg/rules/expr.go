@@ -0,0 +1,555 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kind is the type of a value in a rule expression
+type Kind string
+
+const (
+	KindNumber Kind = "number"
+	KindBool   Kind = "bool"
+	KindString Kind = "string"
+)
+
+// Facts are the values of a rule's variables for one evaluation
+type Facts map[string]interface{}
+
+// Variable is a fact a rule expression can refer to
+type Variable struct {
+	Name        string `json:"name"`
+	Kind        Kind   `json:"kind"`
+	Description string `json:"description"`
+}
+
+// MaxExpressionLength caps the length of a rule expression
+const MaxExpressionLength = 1000
+
+// Expression is a compiled rule expression. The language has numbers, quoted
+// strings, true and false, the rule's variables, arithmetic (+ - * / %),
+// comparisons, && || !, cond ? a : b, parentheses and min() and max().
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses and type checks an expression that may refer to variables
+func Compile(source string, variables []Variable) (*Expression, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	if len(source) > MaxExpressionLength {
+		return nil, fmt.Errorf("expression must be at most %d characters", MaxExpressionLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(map[string]Kind, len(variables))
+	for _, variable := range variables {
+		kinds[variable.Name] = variable.Kind
+	}
+
+	p := &parser{tokens: tokens, variables: kinds}
+	root, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// Kind returns the type of value the expression produces
+func (e *Expression) Kind() Kind {
+	return e.root.kind()
+}
+
+// String returns the expression's source
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression against facts. It fails if a fact the
+// expression uses is missing or of the wrong type, or on division by zero.
+func (e *Expression) Eval(facts Facts) (interface{}, error) {
+	return e.root.eval(facts)
+}
+
+// node is a typed expression tree node
+type node interface {
+	kind() Kind
+	eval(facts Facts) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+	typ   Kind
+}
+
+func (n *literal) kind() Kind                            { return n.typ }
+func (n *literal) eval(facts Facts) (interface{}, error) { return n.value, nil }
+
+type variable struct {
+	name string
+	typ  Kind
+}
+
+func (n *variable) kind() Kind { return n.typ }
+
+func (n *variable) eval(facts Facts) (interface{}, error) {
+	value, ok := facts[n.name]
+	if !ok {
+		return nil, fmt.Errorf("fact %q is missing", n.name)
+	}
+	switch v := value.(type) {
+	case float64:
+		if n.typ == KindNumber {
+			return v, nil
+		}
+	case int:
+		if n.typ == KindNumber {
+			return float64(v), nil
+		}
+	case int64:
+		if n.typ == KindNumber {
+			return float64(v), nil
+		}
+	case bool:
+		if n.typ == KindBool {
+			return v, nil
+		}
+	case string:
+		if n.typ == KindString {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("fact %q is not a %s", n.name, n.typ)
+}
+
+type unary struct {
+	op      string
+	operand node
+}
+
+func (n *unary) kind() Kind { return n.operand.kind() }
+
+func (n *unary) eval(facts Facts) (interface{}, error) {
+	value, err := n.operand.eval(facts)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		return !value.(bool), nil
+	}
+	return -value.(float64), nil
+}
+
+type binary struct {
+	op          string
+	left, right node
+	typ         Kind
+}
+
+func (n *binary) kind() Kind { return n.typ }
+
+func (n *binary) eval(facts Facts) (interface{}, error) {
+	left, err := n.left.eval(facts)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || only evaluate the right side when they need it
+	switch n.op {
+	case "&&":
+		if !left.(bool) {
+			return false, nil
+		}
+		return n.right.eval(facts)
+	case "||":
+		if left.(bool) {
+			return true, nil
+		}
+		return n.right.eval(facts)
+	}
+
+	right, err := n.right.eval(facts)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+
+	if l, ok := left.(string); ok {
+		r := right.(string)
+		switch n.op {
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		default:
+			return l >= r, nil
+		}
+	}
+
+	l, r := left.(float64), right.(float64)
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/", "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if n.op == "/" {
+			return l / r, nil
+		}
+		return float64(int64(l) % int64(r)), nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	default:
+		return l >= r, nil
+	}
+}
+
+type conditional struct {
+	cond, then, otherwise node
+}
+
+func (n *conditional) kind() Kind { return n.then.kind() }
+
+func (n *conditional) eval(facts Facts) (interface{}, error) {
+	cond, err := n.cond.eval(facts)
+	if err != nil {
+		return nil, err
+	}
+	if cond.(bool) {
+		return n.then.eval(facts)
+	}
+	return n.otherwise.eval(facts)
+}
+
+type call struct {
+	fn   string
+	args []node
+}
+
+func (n *call) kind() Kind { return KindNumber }
+
+func (n *call) eval(facts Facts) (interface{}, error) {
+	var result float64
+	for i, arg := range n.args {
+		value, err := arg.eval(facts)
+		if err != nil {
+			return nil, err
+		}
+		v := value.(float64)
+		if i == 0 || (n.fn == "min" && v < result) || (n.fn == "max" && v > result) {
+			result = v
+		}
+	}
+	return result, nil
+}
+
+// tokenKind classifies tokens
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators lists the operators, longest first so "<=" wins over "<"
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", ",", "?", ":"}
+
+// tokenize splits an expression into tokens
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(source) && unicode.IsDigit(rune(source[i+1]))):
+			start := i
+			for i < len(source) && (unicode.IsDigit(rune(source[i])) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:i], pos: start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(source) && (unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i])) || source[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[start:i], pos: start})
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(source[i+1:], source[i])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at position %d", i+1)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: source[i+1 : i+1+end], pos: i})
+			i += end + 2
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(source[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected %q at position %d", string(c), i+1)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, text: "end of expression", pos: len(source)}), nil
+}
+
+// parser builds a typed tree from tokens by recursive descent. From lowest
+// to highest precedence: ?:, ||, &&, == !=, < <= > >=, + -, * / %, ! and unary -.
+type parser struct {
+	tokens    []token
+	pos       int
+	variables map[string]Kind
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is one of the operators
+func (p *parser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		tok := p.peek()
+		return fmt.Errorf("expected %q at position %d, found %q", op, tok.pos+1, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) parseConditional() (node, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return cond, nil
+	}
+	if cond.kind() != KindBool {
+		return nil, fmt.Errorf("the condition before ? must be true or false")
+	}
+
+	then, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if then.kind() != otherwise.kind() {
+		return nil, fmt.Errorf("both sides of ?: must have the same type")
+	}
+	return &conditional{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+// binaryLevels lists the binary operators by increasing precedence
+var binaryLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(binaryLevels) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(binaryLevels[level]...)
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		typed, err := typeBinary(op, left, right)
+		if err != nil {
+			return nil, err
+		}
+		left = typed
+	}
+}
+
+// typeBinary checks the operand types of a binary operator
+func typeBinary(op string, left, right node) (node, error) {
+	l, r := left.kind(), right.kind()
+	switch op {
+	case "&&", "||":
+		if l != KindBool || r != KindBool {
+			return nil, fmt.Errorf("%s needs true or false on both sides", op)
+		}
+		return &binary{op: op, left: left, right: right, typ: KindBool}, nil
+	case "==", "!=":
+		if l != r {
+			return nil, fmt.Errorf("%s compares values of the same type", op)
+		}
+		return &binary{op: op, left: left, right: right, typ: KindBool}, nil
+	case "<", "<=", ">", ">=":
+		if l != r || l == KindBool {
+			return nil, fmt.Errorf("%s compares two numbers or two strings", op)
+		}
+		return &binary{op: op, left: left, right: right, typ: KindBool}, nil
+	default:
+		if l != KindNumber || r != KindNumber {
+			return nil, fmt.Errorf("%s needs numbers on both sides", op)
+		}
+		return &binary{op: op, left: left, right: right, typ: KindNumber}, nil
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	op, ok := p.accept("!", "-")
+	if !ok {
+		return p.parsePrimary()
+	}
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if op == "!" && operand.kind() != KindBool {
+		return nil, fmt.Errorf("! needs true or false")
+	}
+	if op == "-" && operand.kind() != KindNumber {
+		return nil, fmt.Errorf("- needs a number")
+	}
+	return &unary{op: op, operand: operand}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos+1)
+		}
+		return &literal{value: value, typ: KindNumber}, nil
+	case tokenString:
+		return &literal{value: tok.text, typ: KindString}, nil
+	case tokenIdent:
+		return p.parseIdent(tok)
+	case tokenOperator:
+		if tok.text == "(" {
+			inner, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+}
+
+func (p *parser) parseIdent(tok token) (node, error) {
+	switch tok.text {
+	case "true":
+		return &literal{value: true, typ: KindBool}, nil
+	case "false":
+		return &literal{value: false, typ: KindBool}, nil
+	case "min", "max":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		var args []node
+		for {
+			arg, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+			if arg.kind() != KindNumber {
+				return nil, fmt.Errorf("%s() takes numbers", tok.text)
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &call{fn: tok.text, args: args}, nil
+	}
+
+	kind, ok := p.variables[tok.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %q", tok.text)
+	}
+	return &variable{name: tok.text, typ: kind}, nil
+}
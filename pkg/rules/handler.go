@@ -0,0 +1,134 @@
+package rules
+
+import (
+	"net/http"
+	"time"
+
+	"dongome/pkg/auth"
+	"dongome/pkg/errors"
+	"dongome/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultSimulationDays is how far back simulations look when no range is given
+const defaultSimulationDays = 30
+
+// SetRuleCommand represents the command to replace a rule's expression
+type SetRuleCommand struct {
+	Expression string `json:"expression" binding:"required"`
+}
+
+// SimulateRuleCommand represents the command to dry-run an expression
+type SimulateRuleCommand struct {
+	Expression string `json:"expression" binding:"required"`
+	// Days is how many days of history to replay
+	Days int `json:"days" binding:"omitempty,min=1,max=365"`
+}
+
+// Handler serves rule administration
+type Handler struct {
+	engine *Engine
+}
+
+// NewHandler creates a new rule handler
+func NewHandler(engine *Engine) *Handler {
+	return &Handler{
+		engine: engine,
+	}
+}
+
+// RegisterRoutes registers rule administration routes. The group must be
+// protected by the admin role.
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	rules := r.Group("/rules")
+	{
+		rules.GET("", h.ListRules)
+		rules.GET("/:key", h.GetRule)
+		rules.PUT("/:key", h.SetRule)
+		rules.DELETE("/:key", h.ResetRule)
+		rules.POST("/:key/simulate", h.SimulateRule)
+	}
+}
+
+// ListRules handles listing every rule with the expression in force
+func (h *Handler) ListRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"rules": h.engine.List()})
+}
+
+// GetRule handles retrieving one rule
+func (h *Handler) GetRule(c *gin.Context) {
+	rule, err := h.engine.Get(c.Param("key"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// SetRule handles replacing a rule's expression
+func (h *Handler) SetRule(c *gin.Context) {
+	var cmd SetRuleCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+
+	rule, err := h.engine.Set(c.Request.Context(), c.Param("key"), cmd.Expression, auth.UserID(c).String())
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// ResetRule handles going back to a rule's default expression
+func (h *Handler) ResetRule(c *gin.Context) {
+	rule, err := h.engine.Reset(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// SimulateRule handles comparing a candidate expression with the one in
+// force over past cases
+func (h *Handler) SimulateRule(c *gin.Context) {
+	var cmd SimulateRuleCommand
+	if err := c.ShouldBindJSON(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": i18n.BindingError(c, err)})
+		return
+	}
+	if cmd.Days == 0 {
+		cmd.Days = defaultSimulationDays
+	}
+
+	since := time.Now().AddDate(0, 0, -cmd.Days)
+	simulation, err := h.engine.Simulate(c.Request.Context(), c.Param("key"), cmd.Expression, since)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok {
+			c.JSON(domainErr.HTTPStatusCode(), gin.H{"error": i18n.Localize(c, domainErr.Message), "code": domainErr.Code})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": i18n.Localize(c, "internal server error")})
+		return
+	}
+
+	c.JSON(http.StatusOK, simulation)
+}
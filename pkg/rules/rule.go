@@ -0,0 +1,97 @@
+// Package rules lets administrators change business policies, such as quotas
+// and automated actions, without a deploy. Each policy is a rule whose value
+// is an expression over facts the consuming service supplies. Services
+// declare their rules with a default expression; administrators may replace
+// it, after simulating the change against historical cases.
+package rules
+
+import (
+	"context"
+	"time"
+
+	"dongome/pkg/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Definition describes a rule a service consults
+type Definition struct {
+	// Key names the rule, e.g. "listings.max_active_per_seller"
+	Key         string
+	Description string
+	// Kind is the type the rule's expression must produce
+	Kind      Kind
+	Variables []Variable
+	// Default is the expression used until an administrator sets one
+	Default string
+}
+
+// Case is a historical situation a rule was, or would have been, applied to
+type Case struct {
+	// Subject identifies what the rule was applied to, such as a user or order
+	Subject    string    `json:"subject"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Facts      Facts     `json:"facts"`
+}
+
+// History provides the historical cases a rule change is simulated against
+type History interface {
+	Cases(ctx context.Context, since time.Time, limit int) ([]Case, error)
+}
+
+// Rule is an administrator's expression for a rule, replacing its default
+type Rule struct {
+	Key        string    `gorm:"primaryKey;size:100" json:"key"`
+	Expression string    `gorm:"type:text;not null" json:"expression"`
+	UpdatedBy  string    `gorm:"type:uuid" json:"updated_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name of rules
+func (Rule) TableName() string {
+	return "business_rules"
+}
+
+// Store defines the interface for rule persistence
+type Store interface {
+	FindAll() ([]*Rule, error)
+	// Save creates or replaces the rule with the same key
+	Save(rule *Rule) error
+	Delete(key string) error
+}
+
+// GORMStore implements Store using GORM
+type GORMStore struct {
+	db *gorm.DB
+}
+
+// NewGORMStore creates a new rule store
+func NewGORMStore(db *gorm.DB) *GORMStore {
+	return &GORMStore{
+		db: db,
+	}
+}
+
+// FindAll finds every rule set by an administrator
+func (s *GORMStore) FindAll() ([]*Rule, error) {
+	var rules []*Rule
+	if err := s.db.Order("key").Find(&rules).Error; err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return rules, nil
+}
+
+// Save creates or replaces a rule
+func (s *GORMStore) Save(rule *Rule) error {
+	return db.ClassifyError(s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expression", "updated_by", "updated_at"}),
+	}).Create(rule).Error)
+}
+
+// Delete removes a rule, so its default applies again
+func (s *GORMStore) Delete(key string) error {
+	return db.ClassifyError(s.db.Delete(&Rule{}, "key = ?", key).Error)
+}
@@ -0,0 +1,200 @@
+package rules_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"dongome/pkg/logger"
+	"dongome/pkg/rules"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Initialize("test"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+var sellerVariables = []rules.Variable{
+	{Name: "verified", Kind: rules.KindBool},
+	{Name: "total_reviews", Kind: rules.KindNumber},
+	{Name: "trust_level", Kind: rules.KindString},
+}
+
+func TestCompile_Eval(t *testing.T) {
+	facts := rules.Facts{"verified": false, "total_reviews": 12, "trust_level": "trusted"}
+
+	tests := []struct {
+		source string
+		want   interface{}
+	}{
+		{"20", 20.0},
+		{"1 + 2 * 3", 7.0},
+		{"(1 + 2) * 3", 9.0},
+		{"-total_reviews + 2", -10.0},
+		{"17 % 5", 2.0},
+		{"verified ? 200 : min(20, 5 + total_reviews)", 17.0},
+		{"max(total_reviews, 3, 4)", 12.0},
+		{"trust_level == 'trusted' && total_reviews >= 10", true},
+		{"!verified || total_reviews < 1", true},
+		{`trust_level != "new"`, true},
+		{"verified == false", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			expression, err := rules.Compile(tt.source, sellerVariables)
+			require.NoError(t, err)
+			got, err := expression.Eval(facts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, source := range []string{
+		"",
+		"1 +",
+		"unknown > 1",
+		"verified + 1",
+		"trust_level > 1",
+		"total_reviews ? 1 : 2",
+		"verified ? 1 : 'one'",
+		"min(verified)",
+		"'unterminated",
+		"1 2",
+	} {
+		_, err := rules.Compile(source, sellerVariables)
+		assert.Error(t, err, source)
+	}
+}
+
+func TestExpression_EvalErrors(t *testing.T) {
+	expression, err := rules.Compile("100 / total_reviews", sellerVariables)
+	require.NoError(t, err)
+
+	_, err = expression.Eval(rules.Facts{"total_reviews": 0})
+	assert.Error(t, err, "division by zero")
+	_, err = expression.Eval(rules.Facts{})
+	assert.Error(t, err, "missing fact")
+	_, err = expression.Eval(rules.Facts{"total_reviews": "many"})
+	assert.Error(t, err, "fact of the wrong type")
+}
+
+// memoryStore is an in-memory Store
+type memoryStore struct {
+	rules map[string]*rules.Rule
+}
+
+func (s *memoryStore) FindAll() ([]*rules.Rule, error) {
+	var all []*rules.Rule
+	for _, rule := range s.rules {
+		all = append(all, rule)
+	}
+	return all, nil
+}
+
+func (s *memoryStore) Save(rule *rules.Rule) error {
+	s.rules[rule.Key] = rule
+	return nil
+}
+
+func (s *memoryStore) Delete(key string) error {
+	delete(s.rules, key)
+	return nil
+}
+
+// fixedHistory returns the same cases whatever the period
+type fixedHistory []rules.Case
+
+func (h fixedHistory) Cases(ctx context.Context, since time.Time, limit int) ([]rules.Case, error) {
+	return h, nil
+}
+
+var quotaRule = rules.Definition{
+	Key:       "listings.max_active_per_seller",
+	Kind:      rules.KindNumber,
+	Variables: sellerVariables,
+	Default:   "50",
+}
+
+func TestEngine_SetResetAndReload(t *testing.T) {
+	store := &memoryStore{rules: make(map[string]*rules.Rule)}
+	engine := rules.NewEngine(store)
+	engine.Register(quotaRule, nil)
+	ctx := context.Background()
+	verified := rules.Facts{"verified": true, "total_reviews": 0, "trust_level": "new"}
+
+	assert.Equal(t, 50.0, engine.Number(quotaRule.Key, verified))
+
+	_, err := engine.Set(ctx, quotaRule.Key, "verified", "admin-1")
+	assert.Error(t, err, "a number rule cannot be set to true/false")
+	_, err = engine.Set(ctx, "unknown", "1", "admin-1")
+	assert.Error(t, err)
+
+	view, err := engine.Set(ctx, quotaRule.Key, "verified ? 200 : 20", "admin-1")
+	require.NoError(t, err)
+	assert.True(t, view.Custom)
+	assert.Equal(t, 200.0, engine.Number(quotaRule.Key, verified))
+
+	// Another instance picks the rule up when it reloads
+	other := rules.NewEngine(store)
+	other.Register(quotaRule, nil)
+	require.NoError(t, other.Reload(ctx))
+	assert.Equal(t, 200.0, other.Number(quotaRule.Key, verified))
+
+	// A stored expression that no longer compiles falls back to the default
+	store.rules[quotaRule.Key].Expression = "removed_variable * 2"
+	require.NoError(t, other.Reload(ctx))
+	assert.Equal(t, 50.0, other.Number(quotaRule.Key, verified))
+
+	// Failures at run time fall back to the default too
+	_, err = engine.Set(ctx, quotaRule.Key, "100 / total_reviews", "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, engine.Number(quotaRule.Key, verified))
+
+	view, err = engine.Reset(ctx, quotaRule.Key)
+	require.NoError(t, err)
+	assert.False(t, view.Custom)
+	assert.Empty(t, store.rules)
+}
+
+func TestEngine_Simulate(t *testing.T) {
+	suspendRule := rules.Definition{
+		Key:       "users.auto_suspend",
+		Kind:      rules.KindBool,
+		Variables: []rules.Variable{{Name: "blocks_received", Kind: rules.KindNumber}},
+		Default:   "false",
+	}
+	history := fixedHistory{
+		{Subject: "user-1", Facts: rules.Facts{"blocks_received": 1}},
+		{Subject: "user-2", Facts: rules.Facts{"blocks_received": 4}},
+		{Subject: "user-3", Facts: rules.Facts{"blocks_received": 7}},
+	}
+	engine := rules.NewEngine(&memoryStore{rules: make(map[string]*rules.Rule)})
+	engine.Register(suspendRule, history)
+	engine.Register(quotaRule, nil)
+	ctx := context.Background()
+
+	simulation, err := engine.Simulate(ctx, suspendRule.Key, "blocks_received >= 3", time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Equal(t, 3, simulation.Cases)
+	assert.Equal(t, 2, simulation.Changed)
+	assert.Zero(t, simulation.CurrentMatches)
+	assert.Equal(t, 2, simulation.CandidateMatches)
+	require.Len(t, simulation.Samples, 2)
+	assert.Equal(t, "user-2", simulation.Samples[0].Subject)
+
+	// Simulating changes nothing
+	assert.False(t, engine.Bool(suspendRule.Key, rules.Facts{"blocks_received": 7}))
+
+	_, err = engine.Simulate(ctx, quotaRule.Key, "10", time.Now())
+	assert.Error(t, err, "rules without history cannot be simulated")
+	_, err = engine.Simulate(ctx, suspendRule.Key, "blocks_received", time.Now())
+	assert.Error(t, err)
+}
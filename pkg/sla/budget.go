@@ -0,0 +1,44 @@
+package sla
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RouteClass groups routes that share a latency budget
+type RouteClass string
+
+const (
+	RouteClassSearch          RouteClass = "search"
+	RouteClassPaymentCallback RouteClass = "payment_callback"
+	RouteClassAdmin           RouteClass = "admin"
+	RouteClassRead            RouteClass = "read"
+	RouteClassWrite           RouteClass = "write"
+)
+
+// DefaultBudgets are the latency budgets of each route class
+var DefaultBudgets = map[RouteClass]time.Duration{
+	RouteClassSearch:          300 * time.Millisecond,
+	RouteClassPaymentCallback: time.Second,
+	RouteClassAdmin:           2 * time.Second,
+	RouteClassRead:            500 * time.Millisecond,
+	RouteClassWrite:           time.Second,
+}
+
+// Classify assigns a route class from the request method and the matched route pattern
+func Classify(method, route string) RouteClass {
+	switch {
+	case strings.Contains(route, "/payments/") && strings.Contains(route, "callback"),
+		strings.Contains(route, "/webhooks/"):
+		return RouteClassPaymentCallback
+	case strings.Contains(route, "/search"):
+		return RouteClassSearch
+	case strings.Contains(route, "/admin/"):
+		return RouteClassAdmin
+	case method == http.MethodGet || method == http.MethodHead:
+		return RouteClassRead
+	default:
+		return RouteClassWrite
+	}
+}
@@ -0,0 +1,58 @@
+package sla
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultReportDays = 7
+	maxReportDays     = 90
+)
+
+// ReportHandler serves the latency budget report
+type ReportHandler struct {
+	store   Store
+	budgets map[RouteClass]time.Duration
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(store Store, budgets map[RouteClass]time.Duration) *ReportHandler {
+	return &ReportHandler{
+		store:   store,
+		budgets: budgets,
+	}
+}
+
+// RegisterRoutes registers the report route. The group must be restricted to admins.
+func (h *ReportHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/sla/report", h.GetReport)
+}
+
+// GetReport handles summarizing budget violation rates per deploy
+func (h *ReportHandler) GetReport(c *gin.Context) {
+	days := defaultReportDays
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxReportDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+			return
+		}
+		days = parsed
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	records, err := h.store.FindSince(since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":   since,
+		"deploys": BuildReport(records, h.budgets),
+	})
+}
@@ -0,0 +1,52 @@
+package sla
+
+import (
+	"time"
+
+	"dongome/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TraceIDHeader is the response header carrying the request's trace ID
+const TraceIDHeader = logger.TraceIDHeader
+
+// Middleware measures each matched route against its latency budget and logs
+// budget violations as structured events with the request's trace ID as exemplar
+func Middleware(recorder *Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// The trace ID set by logger.Middleware, when it runs first
+		traceID := logger.TraceIDFromContext(c.Request.Context())
+		if traceID == "" {
+			traceID = logger.TraceIDFromRequest(c.Request)
+			c.Header(TraceIDHeader, traceID)
+		}
+
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		// Unmatched routes have no pattern and no budget
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+
+		violation := recorder.Record(c.Request.Method, route, c.Writer.Status(), latency, traceID)
+		if violation == nil {
+			return
+		}
+
+		logger.Warn("Latency budget exceeded",
+			logger.EventAction("sla.violation"),
+			logger.HTTPMethod(violation.Method),
+			logger.HTTPRoute(violation.Route),
+			zap.String("sla.route_class", string(violation.Class)),
+			logger.HTTPStatus(violation.Status),
+			zap.Duration("sla.budget", violation.Budget),
+			zap.Duration(logger.FieldEventDuration, violation.Latency),
+			zap.String(logger.FieldTraceID, violation.TraceID),
+			zap.String("sla.version", violation.Version))
+	}
+}
@@ -0,0 +1,139 @@
+package sla
+
+import (
+	"sync"
+	"time"
+)
+
+// maxExemplars limits how many trace IDs are kept per route and window
+const maxExemplars = 5
+
+// Violation describes a request that exceeded its latency budget
+type Violation struct {
+	Method  string
+	Route   string
+	Class   RouteClass
+	Status  int
+	Budget  time.Duration
+	Latency time.Duration
+	TraceID string
+	Version string
+}
+
+// RouteStats holds the latency statistics of one route within a window
+type RouteStats struct {
+	Method     string
+	Route      string
+	Class      RouteClass
+	Requests   int64
+	Violations int64
+	MaxLatency time.Duration
+	Exemplars  []string
+}
+
+// Window is the set of route statistics collected since the previous drain
+type Window struct {
+	Version string
+	Start   time.Time
+	End     time.Time
+	Routes  []RouteStats
+}
+
+type routeKey struct {
+	method string
+	route  string
+}
+
+// Recorder aggregates request latencies against the route budgets of a deploy
+type Recorder struct {
+	mu          sync.Mutex
+	version     string
+	budgets     map[RouteClass]time.Duration
+	windowStart time.Time
+	routes      map[routeKey]*RouteStats
+}
+
+// NewRecorder creates a new recorder for the given deploy version
+func NewRecorder(version string, budgets map[RouteClass]time.Duration) *Recorder {
+	return &Recorder{
+		version:     version,
+		budgets:     budgets,
+		windowStart: time.Now(),
+		routes:      make(map[routeKey]*RouteStats),
+	}
+}
+
+// Version returns the deploy version the recorder reports under
+func (r *Recorder) Version() string {
+	return r.version
+}
+
+// Budget returns the latency budget of a route class. Classes without a
+// configured budget fall back to the write budget.
+func (r *Recorder) Budget(class RouteClass) time.Duration {
+	if budget, ok := r.budgets[class]; ok {
+		return budget
+	}
+	return r.budgets[RouteClassWrite]
+}
+
+// Record adds a completed request and returns the violation when it exceeded its budget
+func (r *Recorder) Record(method, route string, status int, latency time.Duration, traceID string) *Violation {
+	class := Classify(method, route)
+	budget := r.Budget(class)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := routeKey{method: method, route: route}
+	stats, ok := r.routes[key]
+	if !ok {
+		stats = &RouteStats{Method: method, Route: route, Class: class}
+		r.routes[key] = stats
+	}
+
+	stats.Requests++
+	if latency > stats.MaxLatency {
+		stats.MaxLatency = latency
+	}
+	if budget <= 0 || latency <= budget {
+		return nil
+	}
+
+	stats.Violations++
+	if traceID != "" && len(stats.Exemplars) < maxExemplars {
+		stats.Exemplars = append(stats.Exemplars, traceID)
+	}
+
+	return &Violation{
+		Method:  method,
+		Route:   route,
+		Class:   class,
+		Status:  status,
+		Budget:  budget,
+		Latency: latency,
+		TraceID: traceID,
+		Version: r.version,
+	}
+}
+
+// Drain returns the statistics collected since the previous drain and starts a new window
+func (r *Recorder) Drain() *Window {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	window := &Window{
+		Version: r.version,
+		Start:   r.windowStart,
+		End:     now,
+		Routes:  make([]RouteStats, 0, len(r.routes)),
+	}
+	for _, stats := range r.routes {
+		window.Routes = append(window.Routes, *stats)
+	}
+
+	r.windowStart = now
+	r.routes = make(map[routeKey]*RouteStats)
+	return window
+}
@@ -0,0 +1,90 @@
+package sla
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClassReport summarizes the budget violations of a route class within a deploy
+type ClassReport struct {
+	Class         RouteClass `json:"class"`
+	BudgetMs      int64      `json:"budget_ms"`
+	Requests      int64      `json:"requests"`
+	Violations    int64      `json:"violations"`
+	ViolationRate float64    `json:"violation_rate"`
+	MaxLatencyMs  int64      `json:"max_latency_ms"`
+	Exemplars     []string   `json:"exemplars"`
+}
+
+// DeployReport summarizes the budget violations of a deploy
+type DeployReport struct {
+	Version       string        `json:"version"`
+	FirstSeen     time.Time     `json:"first_seen"`
+	LastSeen      time.Time     `json:"last_seen"`
+	Requests      int64         `json:"requests"`
+	Violations    int64         `json:"violations"`
+	ViolationRate float64       `json:"violation_rate"`
+	Classes       []ClassReport `json:"classes"`
+}
+
+// BuildReport aggregates window records per deploy and route class, most recent deploy first
+func BuildReport(records []WindowRecord, budgets map[RouteClass]time.Duration) []DeployReport {
+	deploys := make(map[string]*DeployReport)
+	classes := make(map[string]map[RouteClass]*ClassReport)
+
+	for _, record := range records {
+		deploy, ok := deploys[record.Version]
+		if !ok {
+			deploy = &DeployReport{Version: record.Version, FirstSeen: record.WindowStart, LastSeen: record.WindowEnd}
+			deploys[record.Version] = deploy
+			classes[record.Version] = make(map[RouteClass]*ClassReport)
+		}
+		if record.WindowStart.Before(deploy.FirstSeen) {
+			deploy.FirstSeen = record.WindowStart
+		}
+		if record.WindowEnd.After(deploy.LastSeen) {
+			deploy.LastSeen = record.WindowEnd
+		}
+		deploy.Requests += record.Requests
+		deploy.Violations += record.Violations
+
+		class, ok := classes[record.Version][record.RouteClass]
+		if !ok {
+			class = &ClassReport{Class: record.RouteClass, BudgetMs: budgets[record.RouteClass].Milliseconds()}
+			classes[record.Version][record.RouteClass] = class
+		}
+		class.Requests += record.Requests
+		class.Violations += record.Violations
+		if record.MaxLatencyMs > class.MaxLatencyMs {
+			class.MaxLatencyMs = record.MaxLatencyMs
+		}
+		for _, exemplar := range strings.Split(record.Exemplars, ",") {
+			if exemplar != "" && len(class.Exemplars) < maxExemplars {
+				class.Exemplars = append(class.Exemplars, exemplar)
+			}
+		}
+	}
+
+	reports := make([]DeployReport, 0, len(deploys))
+	for version, deploy := range deploys {
+		deploy.ViolationRate = rate(deploy.Violations, deploy.Requests)
+		for _, class := range classes[version] {
+			class.ViolationRate = rate(class.Violations, class.Requests)
+			deploy.Classes = append(deploy.Classes, *class)
+		}
+		sort.Slice(deploy.Classes, func(i, j int) bool { return deploy.Classes[i].Class < deploy.Classes[j].Class })
+		reports = append(reports, *deploy)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].LastSeen.After(reports[j].LastSeen) })
+
+	return reports
+}
+
+// rate returns the share of violations among requests
+func rate(violations, requests int64) float64 {
+	if requests == 0 {
+		return 0
+	}
+	return float64(violations) / float64(requests)
+}
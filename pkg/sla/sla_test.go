@@ -0,0 +1,114 @@
+package sla_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"dongome/pkg/logger"
+	"dongome/pkg/sla"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Initialize("test"); err != nil {
+		panic(err)
+	}
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		method string
+		route  string
+		class  sla.RouteClass
+	}{
+		{http.MethodGet, "/api/v1/sellers/:id/listings/search", sla.RouteClassSearch},
+		{http.MethodPost, "/api/v1/payments/momo/callback", sla.RouteClassPaymentCallback},
+		{http.MethodGet, "/api/v1/admin/users", sla.RouteClassAdmin},
+		{http.MethodGet, "/api/v1/locations/regions", sla.RouteClassRead},
+		{http.MethodPost, "/api/v1/blocks", sla.RouteClassWrite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			assert.Equal(t, tt.class, sla.Classify(tt.method, tt.route))
+		})
+	}
+}
+
+func TestRecorder_RecordAndDrain(t *testing.T) {
+	recorder := sla.NewRecorder("v1.2.0", sla.DefaultBudgets)
+	route := "/api/v1/sellers/:id/listings/search"
+
+	assert.Nil(t, recorder.Record(http.MethodGet, route, http.StatusOK, 100*time.Millisecond, "trace-1"))
+
+	violation := recorder.Record(http.MethodGet, route, http.StatusOK, 450*time.Millisecond, "trace-2")
+	require.NotNil(t, violation)
+	assert.Equal(t, sla.RouteClassSearch, violation.Class)
+	assert.Equal(t, 300*time.Millisecond, violation.Budget)
+	assert.Equal(t, "v1.2.0", violation.Version)
+
+	window := recorder.Drain()
+	require.Len(t, window.Routes, 1)
+	stats := window.Routes[0]
+	assert.Equal(t, int64(2), stats.Requests)
+	assert.Equal(t, int64(1), stats.Violations)
+	assert.Equal(t, 450*time.Millisecond, stats.MaxLatency)
+	assert.Equal(t, []string{"trace-2"}, stats.Exemplars)
+
+	// Draining starts a new window
+	assert.Empty(t, recorder.Drain().Routes)
+}
+
+func TestBuildReport(t *testing.T) {
+	now := time.Now()
+	records := []sla.WindowRecord{
+		{Version: "v1", RouteClass: sla.RouteClassSearch, Requests: 100, Violations: 10, MaxLatencyMs: 900,
+			Exemplars: "a,b", WindowStart: now.Add(-3 * time.Hour), WindowEnd: now.Add(-2 * time.Hour)},
+		{Version: "v2", RouteClass: sla.RouteClassSearch, Requests: 50, Violations: 1, MaxLatencyMs: 320,
+			Exemplars: "c", WindowStart: now.Add(-time.Hour), WindowEnd: now},
+		{Version: "v2", RouteClass: sla.RouteClassRead, Requests: 150, Violations: 0, MaxLatencyMs: 80,
+			WindowStart: now.Add(-time.Hour), WindowEnd: now},
+	}
+
+	reports := sla.BuildReport(records, sla.DefaultBudgets)
+	require.Len(t, reports, 2)
+
+	latest := reports[0]
+	assert.Equal(t, "v2", latest.Version)
+	assert.Equal(t, int64(200), latest.Requests)
+	assert.InDelta(t, 0.005, latest.ViolationRate, 1e-9)
+	require.Len(t, latest.Classes, 2)
+	assert.Equal(t, sla.RouteClassRead, latest.Classes[0].Class)
+	assert.Equal(t, int64(300), latest.Classes[1].BudgetMs)
+	assert.Equal(t, []string{"c"}, latest.Classes[1].Exemplars)
+
+	previous := reports[1]
+	assert.Equal(t, "v1", previous.Version)
+	assert.InDelta(t, 0.1, previous.ViolationRate, 1e-9)
+}
+
+func TestMiddleware_TraceID(t *testing.T) {
+	recorder := sla.NewRecorder("test", sla.DefaultBudgets)
+	router := gin.New()
+	router.Use(sla.Middleware(recorder))
+	router.GET("/items/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rec.Header().Get(sla.TraceIDHeader))
+
+	window := recorder.Drain()
+	require.Len(t, window.Routes, 1)
+	assert.Equal(t, "/items/:id", window.Routes[0].Route)
+}
@@ -0,0 +1,85 @@
+package sla
+
+import (
+	"strings"
+	"time"
+
+	"dongome/pkg/db"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WindowRecord is the persisted statistics of one route within a window
+type WindowRecord struct {
+	ID           string     `gorm:"type:uuid;primary_key" json:"id"`
+	Version      string     `gorm:"not null;index" json:"version"`
+	Method       string     `gorm:"not null" json:"method"`
+	Route        string     `gorm:"not null" json:"route"`
+	RouteClass   RouteClass `gorm:"not null" json:"route_class"`
+	Requests     int64      `json:"requests"`
+	Violations   int64      `json:"violations"`
+	MaxLatencyMs int64      `json:"max_latency_ms"`
+	Exemplars    string     `json:"exemplars"`
+	WindowStart  time.Time  `gorm:"not null" json:"window_start"`
+	WindowEnd    time.Time  `gorm:"not null;index" json:"window_end"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// TableName returns the table name of window records
+func (WindowRecord) TableName() string {
+	return "sla_windows"
+}
+
+// Store defines the interface for latency statistics persistence
+type Store interface {
+	SaveWindow(window *Window) error
+	FindSince(since time.Time) ([]WindowRecord, error)
+}
+
+// GORMStore implements Store using GORM
+type GORMStore struct {
+	db *gorm.DB
+}
+
+// NewGORMStore creates a new latency statistics store
+func NewGORMStore(db *gorm.DB) *GORMStore {
+	return &GORMStore{
+		db: db,
+	}
+}
+
+// SaveWindow saves one record per route of the window. Empty windows are skipped.
+func (s *GORMStore) SaveWindow(window *Window) error {
+	if len(window.Routes) == 0 {
+		return nil
+	}
+
+	records := make([]WindowRecord, 0, len(window.Routes))
+	for _, stats := range window.Routes {
+		records = append(records, WindowRecord{
+			ID:           uuid.New().String(),
+			Version:      window.Version,
+			Method:       stats.Method,
+			Route:        stats.Route,
+			RouteClass:   stats.Class,
+			Requests:     stats.Requests,
+			Violations:   stats.Violations,
+			MaxLatencyMs: stats.MaxLatency.Milliseconds(),
+			Exemplars:    strings.Join(stats.Exemplars, ","),
+			WindowStart:  window.Start,
+			WindowEnd:    window.End,
+		})
+	}
+	return db.ClassifyError(s.db.Create(&records).Error)
+}
+
+// FindSince finds the records of windows that ended after since
+func (s *GORMStore) FindSince(since time.Time) ([]WindowRecord, error) {
+	var records []WindowRecord
+	err := s.db.Where("window_end >= ?", since).Order("window_end ASC").Find(&records).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return records, nil
+}
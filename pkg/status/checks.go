@@ -0,0 +1,164 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"dongome/pkg/events"
+
+	"github.com/nats-io/nats.go"
+)
+
+// HTTPCheck checks that a health endpoint answers with a success status.
+// Answers slower than slow are reported as degraded.
+func HTTPCheck(client *http.Client, url string, slow time.Duration) Check {
+	return func(ctx context.Context) Result {
+		start := time.Now()
+		status, _, err := get(ctx, client, url, nil)
+		switch {
+		case err != nil:
+			return Result{State: StateDown, Message: err.Error()}
+		case status < 200 || status >= 300:
+			return Result{State: StateDown, Message: fmt.Sprintf("unexpected status %d", status)}
+		case time.Since(start) > slow:
+			return Result{State: StateDegraded, Message: fmt.Sprintf("answered in %s", time.Since(start).Round(time.Millisecond))}
+		}
+		return Result{State: StateOperational}
+	}
+}
+
+// ReachabilityCheck checks that a third-party API answers at all. Any answer
+// but a server error counts, as such APIs rarely offer an unauthenticated
+// health endpoint.
+func ReachabilityCheck(client *http.Client, url string, slow time.Duration) Check {
+	return func(ctx context.Context) Result {
+		start := time.Now()
+		status, _, err := get(ctx, client, url, nil)
+		switch {
+		case err != nil:
+			return Result{State: StateDown, Message: err.Error()}
+		case status >= 500:
+			return Result{State: StateDown, Message: fmt.Sprintf("unexpected status %d", status)}
+		case time.Since(start) > slow:
+			return Result{State: StateDegraded, Message: fmt.Sprintf("answered in %s", time.Since(start).Round(time.Millisecond))}
+		}
+		return Result{State: StateOperational}
+	}
+}
+
+// ClusterHealthCheck checks the health an Elasticsearch or OpenSearch
+// cluster reports: green is operational, yellow degraded and red down
+func ClusterHealthCheck(client *http.Client, baseURL, username, password string) Check {
+	url := strings.TrimSuffix(baseURL, "/") + "/_cluster/health"
+	return func(ctx context.Context) Result {
+		status, body, err := get(ctx, client, url, func(req *http.Request) {
+			if username != "" {
+				req.SetBasicAuth(username, password)
+			}
+		})
+		if err != nil {
+			return Result{State: StateDown, Message: err.Error()}
+		}
+		if status != http.StatusOK {
+			return Result{State: StateDown, Message: fmt.Sprintf("unexpected status %d", status)}
+		}
+
+		var health struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(body, &health); err != nil {
+			return Result{State: StateDown, Message: "unreadable cluster health"}
+		}
+		switch health.Status {
+		case "green":
+			return Result{State: StateOperational}
+		case "yellow":
+			return Result{State: StateDegraded, Message: "cluster health is yellow"}
+		}
+		return Result{State: StateDown, Message: fmt.Sprintf("cluster health is %s", health.Status)}
+	}
+}
+
+// EventLagCheck checks how far the consumers of the event stream have fallen
+// behind. A consumer lags from the last time it acknowledged an event while
+// more are waiting; above threshold event processing is degraded, and
+// above ten times threshold it is down.
+func EventLagCheck(url string, threshold time.Duration) Check {
+	return func(ctx context.Context) Result {
+		conn, err := nats.Connect(url, nats.Timeout(5*time.Second))
+		if err != nil {
+			return Result{State: StateDown, Message: err.Error()}
+		}
+		defer conn.Close()
+
+		js, err := conn.JetStream(nats.Context(ctx))
+		if err != nil {
+			return Result{State: StateDown, Message: err.Error()}
+		}
+
+		var consumers []*nats.ConsumerInfo
+		for info := range js.ConsumersInfo(events.StreamName, nats.Context(ctx)) {
+			consumers = append(consumers, info)
+		}
+		if ctx.Err() != nil {
+			return Result{State: StateDown, Message: ctx.Err().Error()}
+		}
+
+		lag, consumer := EventLag(consumers, time.Now())
+		switch {
+		case lag > 10*threshold:
+			return Result{State: StateDown, Message: fmt.Sprintf("%s is %s behind", consumer, lag.Round(time.Second))}
+		case lag > threshold:
+			return Result{State: StateDegraded, Message: fmt.Sprintf("%s is %s behind", consumer, lag.Round(time.Second))}
+		}
+		return Result{State: StateOperational}
+	}
+}
+
+// EventLag returns the lag of the consumer furthest behind, and its name.
+// Consumers with nothing waiting do not lag.
+func EventLag(consumers []*nats.ConsumerInfo, now time.Time) (time.Duration, string) {
+	var worst time.Duration
+	var name string
+	for _, info := range consumers {
+		if info.NumPending == 0 && info.NumAckPending == 0 {
+			continue
+		}
+		since := info.Created
+		if info.AckFloor.Last != nil {
+			since = *info.AckFloor.Last
+		}
+		if lag := now.Sub(since); lag > worst {
+			worst, name = lag, info.Name
+		}
+	}
+	return worst, name
+}
+
+// get sends a GET request and reads the response
+func get(ctx context.Context, client *http.Client, url string, prepare func(*http.Request)) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	if prepare != nil {
+		prepare(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
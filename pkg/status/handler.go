@@ -0,0 +1,78 @@
+package status
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reportCacheTTL is how long a built report is served before it is built
+// again, so a busy status page costs a few queries a minute
+const reportCacheTTL = 30 * time.Second
+
+// Handler serves the public status page data
+type Handler struct {
+	store      Store
+	staleAfter time.Duration
+
+	mu      sync.Mutex
+	report  *Report
+	builtAt time.Time
+}
+
+// NewHandler creates a new status handler. Components not checked within
+// staleAfter are reported unknown.
+func NewHandler(store Store, staleAfter time.Duration) *Handler {
+	return &Handler{
+		store:      store,
+		staleAfter: staleAfter,
+	}
+}
+
+// RegisterRoutes registers the status route. It is public.
+func (h *Handler) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/status", h.GetStatus)
+}
+
+// GetStatus handles reporting the health and uptime of each component
+func (h *Handler) GetStatus(c *gin.Context) {
+	report, err := h.currentReport()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=30")
+	c.JSON(http.StatusOK, report)
+}
+
+// currentReport returns the cached report, building it again once it is
+// older than reportCacheTTL
+func (h *Handler) currentReport() (*Report, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if h.report != nil && now.Sub(h.builtAt) < reportCacheTTL {
+		return h.report, nil
+	}
+
+	latest, err := h.store.FindLatest()
+	if err != nil {
+		return nil, err
+	}
+	day, err := h.store.CountSince(now.Add(-24 * time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	week, err := h.store.CountSince(now.AddDate(0, 0, -7))
+	if err != nil {
+		return nil, err
+	}
+
+	h.report = BuildReport(latest, day, week, h.staleAfter, now)
+	h.builtAt = now
+	return h.report, nil
+}
@@ -0,0 +1,117 @@
+// Package status checks the health of the components buyers and sellers
+// depend on and reports it, with uptime, for a public status page. The
+// worker runs the checks on a schedule and keeps their history; the API
+// summarizes it.
+package status
+
+import (
+	"context"
+	"time"
+)
+
+// maxMessageLength is the longest check message kept
+const maxMessageLength = 500
+
+// Component is a part of the service shown on the status page
+type Component string
+
+const (
+	ComponentAPI      Component = "api"
+	ComponentPayments Component = "payments"
+	ComponentSearch   Component = "search"
+	// ComponentEvents is the worker's processing of domain events, which
+	// notifications, search indexing and checkouts wait on
+	ComponentEvents Component = "event_processing"
+)
+
+// componentOrder is the order components are listed on the status page
+var componentOrder = []Component{ComponentAPI, ComponentPayments, ComponentSearch, ComponentEvents}
+
+// State is the health of a component
+type State string
+
+const (
+	StateOperational State = "operational"
+	// StateDegraded means the component works, but slowly or partly
+	StateDegraded State = "degraded"
+	StateDown     State = "down"
+	// StateUnknown means the component has not been checked recently
+	StateUnknown State = "unknown"
+)
+
+// severity ranks states from healthy to failed. Unknown ranks with
+// degraded: a component nobody is checking cannot be called operational.
+func (s State) severity() int {
+	switch s {
+	case StateOperational:
+		return 0
+	case StateDegraded, StateUnknown:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Result is the outcome of one check
+type Result struct {
+	State   State
+	Message string
+}
+
+// Check checks the health of a component
+type Check func(ctx context.Context) Result
+
+// Monitor runs the checks of each component and records their results
+type Monitor struct {
+	store     Store
+	checks    map[Component]Check
+	retention time.Duration
+}
+
+// NewMonitor creates a monitor that keeps retention of check history
+func NewMonitor(store Store, retention time.Duration) *Monitor {
+	return &Monitor{
+		store:     store,
+		checks:    make(map[Component]Check),
+		retention: retention,
+	}
+}
+
+// Add registers the check of a component, replacing any earlier one
+func (m *Monitor) Add(component Component, check Check) {
+	m.checks[component] = check
+}
+
+// Run checks every component, records the results and deletes history
+// older than the retention
+func (m *Monitor) Run(ctx context.Context) error {
+	records := make([]CheckRecord, 0, len(m.checks))
+	for _, component := range componentOrder {
+		check, ok := m.checks[component]
+		if !ok {
+			continue
+		}
+		start := time.Now()
+		result := check(ctx)
+		records = append(records, CheckRecord{
+			Component: component,
+			State:     result.State,
+			LatencyMs: time.Since(start).Milliseconds(),
+			Message:   truncate(result.Message, maxMessageLength),
+			CheckedAt: start,
+		})
+	}
+	if err := m.store.SaveChecks(records); err != nil {
+		return err
+	}
+
+	_, err := m.store.DeleteBefore(time.Now().Add(-m.retention))
+	return err
+}
+
+func truncate(message string, length int) string {
+	if len(message) <= length {
+		return message
+	}
+	return message[:length]
+}
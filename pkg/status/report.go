@@ -0,0 +1,74 @@
+package status
+
+import (
+	"math"
+	"time"
+)
+
+// ComponentStatus is the health of one component on the status page.
+// Uptime is the percentage of checks that did not find it down, and is nil
+// when it was not checked in the period.
+type ComponentStatus struct {
+	Name          Component  `json:"name"`
+	Status        State      `json:"status"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+	Uptime24h     *float64   `json:"uptime_24h"`
+	Uptime7d      *float64   `json:"uptime_7d"`
+}
+
+// Report is the data behind the public status page
+type Report struct {
+	// Status is the state of the component faring worst
+	Status      State             `json:"status"`
+	Components  []ComponentStatus `json:"components"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// BuildReport summarizes the latest checks and the check tallies of the last
+// 24 hours and 7 days. A component whose latest check is older than
+// staleAfter is reported unknown.
+func BuildReport(latest []CheckRecord, day, week map[Component]Tally, staleAfter time.Duration, now time.Time) *Report {
+	byComponent := make(map[Component]CheckRecord, len(latest))
+	for _, record := range latest {
+		byComponent[record.Component] = record
+	}
+
+	report := &Report{
+		Status:      StateUnknown,
+		Components:  make([]ComponentStatus, 0, len(byComponent)),
+		GeneratedAt: now,
+	}
+	for _, component := range componentOrder {
+		record, ok := byComponent[component]
+		if !ok {
+			continue
+		}
+
+		checkedAt := record.CheckedAt
+		status := ComponentStatus{
+			Name:          component,
+			Status:        record.State,
+			LastCheckedAt: &checkedAt,
+			Uptime24h:     uptime(day[component]),
+			Uptime7d:      uptime(week[component]),
+		}
+		if now.Sub(record.CheckedAt) > staleAfter {
+			status.Status = StateUnknown
+		}
+		if len(report.Components) == 0 || status.Status.severity() > report.Status.severity() {
+			report.Status = status.Status
+		}
+		report.Components = append(report.Components, status)
+	}
+	return report
+}
+
+// uptime returns the percentage of checks that did not find a component
+// down, to two decimal places
+func uptime(tally Tally) *float64 {
+	if tally.Checks == 0 {
+		return nil
+	}
+	percent := math.Floor(float64(tally.Up)/float64(tally.Checks)*10000) / 100
+	return &percent
+}
@@ -0,0 +1,213 @@
+package status_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"dongome/pkg/status"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory Store
+type memoryStore struct {
+	records []status.CheckRecord
+}
+
+func (s *memoryStore) SaveChecks(records []status.CheckRecord) error {
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *memoryStore) FindLatest() ([]status.CheckRecord, error) {
+	latest := make(map[status.Component]status.CheckRecord)
+	for _, record := range s.records {
+		if current, ok := latest[record.Component]; !ok || record.CheckedAt.After(current.CheckedAt) {
+			latest[record.Component] = record
+		}
+	}
+	records := make([]status.CheckRecord, 0, len(latest))
+	for _, record := range latest {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (s *memoryStore) CountSince(since time.Time) (map[status.Component]status.Tally, error) {
+	tallies := make(map[status.Component]status.Tally)
+	for _, record := range s.records {
+		if record.CheckedAt.Before(since) {
+			continue
+		}
+		tally := tallies[record.Component]
+		tally.Checks++
+		if record.State != status.StateDown {
+			tally.Up++
+		}
+		tallies[record.Component] = tally
+	}
+	return tallies, nil
+}
+
+func (s *memoryStore) DeleteBefore(before time.Time) (int64, error) {
+	var kept []status.CheckRecord
+	for _, record := range s.records {
+		if !record.CheckedAt.Before(before) {
+			kept = append(kept, record)
+		}
+	}
+	deleted := int64(len(s.records) - len(kept))
+	s.records = kept
+	return deleted, nil
+}
+
+func TestBuildReport(t *testing.T) {
+	now := time.Now()
+	latest := []status.CheckRecord{
+		{Component: status.ComponentEvents, State: status.StateOperational, CheckedAt: now.Add(-time.Minute)},
+		{Component: status.ComponentAPI, State: status.StateOperational, CheckedAt: now.Add(-time.Minute)},
+		{Component: status.ComponentPayments, State: status.StateDegraded, CheckedAt: now.Add(-time.Minute)},
+	}
+	day := map[status.Component]status.Tally{
+		status.ComponentAPI:      {Checks: 1440, Up: 1440},
+		status.ComponentPayments: {Checks: 1440, Up: 1430},
+	}
+	week := map[status.Component]status.Tally{
+		status.ComponentAPI:      {Checks: 3, Up: 2},
+		status.ComponentPayments: {Checks: 10080, Up: 10080},
+	}
+
+	report := status.BuildReport(latest, day, week, 5*time.Minute, now)
+	assert.Equal(t, status.StateDegraded, report.Status)
+	require.Len(t, report.Components, 3)
+
+	api := report.Components[0]
+	assert.Equal(t, status.ComponentAPI, api.Name)
+	require.NotNil(t, api.Uptime24h)
+	assert.Equal(t, 100.0, *api.Uptime24h)
+	require.NotNil(t, api.Uptime7d)
+	assert.Equal(t, 66.66, *api.Uptime7d)
+
+	payments := report.Components[1]
+	assert.Equal(t, status.StateDegraded, payments.Status)
+	assert.Equal(t, 99.3, *payments.Uptime24h)
+
+	events := report.Components[2]
+	assert.Equal(t, status.ComponentEvents, events.Name)
+	assert.Nil(t, events.Uptime24h, "no uptime without checks")
+}
+
+func TestBuildReport_StaleAndDown(t *testing.T) {
+	now := time.Now()
+	latest := []status.CheckRecord{
+		{Component: status.ComponentAPI, State: status.StateOperational, CheckedAt: now.Add(-time.Hour)},
+		{Component: status.ComponentSearch, State: status.StateDown, CheckedAt: now},
+	}
+
+	report := status.BuildReport(latest, nil, nil, 5*time.Minute, now)
+	assert.Equal(t, status.StateDown, report.Status)
+	assert.Equal(t, status.StateUnknown, report.Components[0].Status)
+
+	empty := status.BuildReport(nil, nil, nil, 5*time.Minute, now)
+	assert.Equal(t, status.StateUnknown, empty.Status)
+	assert.Empty(t, empty.Components)
+}
+
+func TestMonitor_RunRecordsChecksAndPrunesHistory(t *testing.T) {
+	store := &memoryStore{records: []status.CheckRecord{
+		{Component: status.ComponentAPI, State: status.StateDown, CheckedAt: time.Now().AddDate(0, 0, -9)},
+	}}
+	monitor := status.NewMonitor(store, 8*24*time.Hour)
+	monitor.Add(status.ComponentAPI, func(ctx context.Context) status.Result {
+		return status.Result{State: status.StateOperational}
+	})
+	monitor.Add(status.ComponentEvents, func(ctx context.Context) status.Result {
+		return status.Result{State: status.StateDegraded, Message: "behind"}
+	})
+
+	require.NoError(t, monitor.Run(context.Background()))
+	require.Len(t, store.records, 2)
+	assert.Equal(t, status.ComponentAPI, store.records[0].Component)
+	assert.Equal(t, status.ComponentEvents, store.records[1].Component)
+	assert.Equal(t, "behind", store.records[1].Message)
+}
+
+func TestHandler_GetStatus(t *testing.T) {
+	store := &memoryStore{records: []status.CheckRecord{
+		{Component: status.ComponentAPI, State: status.StateOperational, CheckedAt: time.Now()},
+	}}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	status.NewHandler(store, 3*time.Minute).RegisterRoutes(router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"operational"`)
+	assert.Contains(t, rec.Body.String(), `"uptime_24h":100`)
+}
+
+func TestHTTPCheck(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	ctx := context.Background()
+	client := &http.Client{Timeout: time.Second}
+	assert.Equal(t, status.StateOperational, status.HTTPCheck(client, healthy.URL, time.Second)(ctx).State)
+	assert.Equal(t, status.StateDegraded, status.HTTPCheck(client, healthy.URL, -1)(ctx).State)
+	assert.Equal(t, status.StateDown, status.HTTPCheck(client, failing.URL, time.Second)(ctx).State)
+	assert.Equal(t, status.StateDown, status.HTTPCheck(client, notFound.URL, time.Second)(ctx).State)
+
+	// A third-party API only has to answer
+	assert.Equal(t, status.StateOperational, status.ReachabilityCheck(client, notFound.URL, time.Second)(ctx).State)
+	assert.Equal(t, status.StateDown, status.ReachabilityCheck(client, failing.URL, time.Second)(ctx).State)
+}
+
+func TestClusterHealthCheck(t *testing.T) {
+	health := "yellow"
+	cluster := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cluster/health", r.URL.Path)
+		user, _, _ := r.BasicAuth()
+		assert.Equal(t, "elastic", user)
+		_, _ = w.Write([]byte(`{"status":"` + health + `"}`))
+	}))
+	defer cluster.Close()
+
+	check := status.ClusterHealthCheck(&http.Client{Timeout: time.Second}, cluster.URL+"/", "elastic", "secret")
+	assert.Equal(t, status.StateDegraded, check(context.Background()).State)
+	health = "green"
+	assert.Equal(t, status.StateOperational, check(context.Background()).State)
+	health = "red"
+	assert.Equal(t, status.StateDown, check(context.Background()).State)
+}
+
+func TestEventLag(t *testing.T) {
+	now := time.Now()
+	acked := now.Add(-5 * time.Minute)
+	recent := now.Add(-10 * time.Second)
+
+	lag, consumer := status.EventLag([]*nats.ConsumerInfo{
+		{Name: "idle", NumPending: 0, AckFloor: nats.SequenceInfo{Last: &acked}},
+		{Name: "busy", NumPending: 3, AckFloor: nats.SequenceInfo{Last: &recent}},
+		{Name: "stuck", NumAckPending: 1, AckFloor: nats.SequenceInfo{Last: &acked}},
+	}, now)
+	assert.Equal(t, 5*time.Minute, lag)
+	assert.Equal(t, "stuck", consumer)
+
+	lag, _ = status.EventLag(nil, now)
+	assert.Zero(t, lag)
+}
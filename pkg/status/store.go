@@ -0,0 +1,106 @@
+package status
+
+import (
+	"time"
+
+	"dongome/pkg/db"
+
+	"gorm.io/gorm"
+)
+
+// CheckRecord is the persisted result of one health check of a component
+type CheckRecord struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	Component Component `gorm:"size:50;not null" json:"component"`
+	State     State     `gorm:"size:20;not null" json:"state"`
+	LatencyMs int64     `gorm:"not null;default:0" json:"latency_ms"`
+	// Message explains a degraded or failed check. It may name internal
+	// hosts, so it is kept for operators and not shown on the status page.
+	Message   string    `gorm:"size:500" json:"message,omitempty"`
+	CheckedAt time.Time `gorm:"not null" json:"checked_at"`
+}
+
+// TableName returns the table name of check records
+func (CheckRecord) TableName() string {
+	return "status_checks"
+}
+
+// Tally counts the checks of a component, and those that did not find it down
+type Tally struct {
+	Checks int64
+	Up     int64
+}
+
+// Store defines the interface for health check history persistence
+type Store interface {
+	SaveChecks(records []CheckRecord) error
+	// FindLatest finds the latest check of each component
+	FindLatest() ([]CheckRecord, error)
+	// CountSince tallies each component's checks made since the given time
+	CountSince(since time.Time) (map[Component]Tally, error)
+	// DeleteBefore deletes checks made before the given time
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+// GORMStore implements Store using GORM
+type GORMStore struct {
+	db *gorm.DB
+}
+
+// NewGORMStore creates a new health check history store
+func NewGORMStore(db *gorm.DB) *GORMStore {
+	return &GORMStore{
+		db: db,
+	}
+}
+
+// SaveChecks saves the results of a round of checks
+func (s *GORMStore) SaveChecks(records []CheckRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return db.ClassifyError(s.db.Create(&records).Error)
+}
+
+// FindLatest finds the latest check of each component
+func (s *GORMStore) FindLatest() ([]CheckRecord, error) {
+	var records []CheckRecord
+	err := s.db.Raw("SELECT DISTINCT ON (component) * FROM status_checks ORDER BY component, checked_at DESC").
+		Scan(&records).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+	return records, nil
+}
+
+// CountSince tallies each component's checks made since the given time
+func (s *GORMStore) CountSince(since time.Time) (map[Component]Tally, error) {
+	var rows []struct {
+		Component Component
+		Checks    int64
+		Up        int64
+	}
+	err := s.db.Model(&CheckRecord{}).
+		Select("component, COUNT(*) AS checks, COUNT(*) FILTER (WHERE state <> ?) AS up", StateDown).
+		Where("checked_at >= ?", since).
+		Group("component").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, db.ClassifyError(err)
+	}
+
+	tallies := make(map[Component]Tally, len(rows))
+	for _, row := range rows {
+		tallies[row.Component] = Tally{Checks: row.Checks, Up: row.Up}
+	}
+	return tallies, nil
+}
+
+// DeleteBefore deletes checks made before the given time
+func (s *GORMStore) DeleteBefore(before time.Time) (int64, error) {
+	result := s.db.Where("checked_at < ?", before).Delete(&CheckRecord{})
+	if result.Error != nil {
+		return 0, db.ClassifyError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature Version 4 constants
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	amzDateFormat    = "20060102T150405Z"
+	signingService   = "s3"
+)
+
+// internalRequestExpiry is how long the signatures of the store's own requests are valid
+const internalRequestExpiry = 5 * time.Minute
+
+// S3Config locates a bucket and the credentials to sign requests to it
+type S3Config struct {
+	// Endpoint is the storage URL, such as https://s3.eu-west-1.amazonaws.com
+	// or http://minio:9000
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses the bucket in the path rather than the host name,
+	// as MinIO requires
+	PathStyle bool
+	// PublicURL is where stored objects are served from, such as a CDN; by
+	// default they are served from the bucket
+	PublicURL string
+}
+
+// S3Store stores objects in an S3-compatible bucket. Every request is signed
+// with Signature Version 4 in the query string, the same way as the URLs
+// handed to clients.
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Store creates a store for the configured bucket
+func NewS3Store(config S3Config, timeout time.Duration) (*S3Store, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage endpoint %q is not a valid URL", config.Endpoint)
+	}
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+
+	return &S3Store{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// URL returns the URL the object stored under key is served from
+func (s *S3Store) URL(key string) string {
+	if s.config.PublicURL != "" {
+		return s.config.PublicURL + "/" + encodePath(key)
+	}
+	host, path := s.location(key)
+	return s.endpoint.Scheme + "://" + host + path
+}
+
+// PresignPut returns a request that uploads an object of exactly size bytes
+// and the given content type under key, valid for expires
+func (s *S3Store) PresignPut(key, contentType string, size int64, expires time.Duration) *PresignedRequest {
+	now := time.Now().UTC()
+	headers := map[string]string{
+		"Content-Type":   contentType,
+		"Content-Length": strconv.FormatInt(size, 10),
+	}
+	return &PresignedRequest{
+		Method:    http.MethodPut,
+		URL:       s.PresignAt(http.MethodPut, key, headers, now, expires),
+		Headers:   headers,
+		ExpiresAt: now.Add(expires),
+	}
+}
+
+// Put uploads an object under key and returns the URL it is served from
+func (s *S3Store) Put(ctx context.Context, key, contentType string, content io.Reader) (string, error) {
+	// Object storage needs the length up front, so the content is buffered
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", err
+	}
+	headers := map[string]string{
+		"Content-Type":   contentType,
+		"Content-Length": strconv.Itoa(len(data)),
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, headers, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", responseError("storing", key, resp)
+	}
+	return s.URL(key), nil
+}
+
+// Open reads the object stored under key, or returns ErrNotFound
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, responseError("reading", key, resp)
+	}
+	return resp.Body, nil
+}
+
+// Stat describes the object stored under key, or returns ErrNotFound
+func (s *S3Store) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, responseError("reading", key, resp)
+	}
+	return &ObjectInfo{
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+// Delete removes the object stored under key. Objects that are already gone
+// are not an error.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusNotFound {
+		return responseError("deleting", key, resp)
+	}
+	return nil
+}
+
+// PresignAt signs a request for the object stored under key as of signedAt.
+// The headers must be sent with the request unchanged.
+func (s *S3Store) PresignAt(method, key string, headers map[string]string, signedAt time.Time, expires time.Duration) string {
+	signedAt = signedAt.UTC()
+	amzDate := signedAt.Format(amzDateFormat)
+	scope := strings.Join([]string{signedAt.Format("20060102"), s.config.Region, signingService, "aws4_request"}, "/")
+	host, path := s.location(key)
+
+	canonical := map[string]string{"host": host}
+	for name, value := range headers {
+		canonical[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	names := make([]string, 0, len(canonical))
+	for name := range canonical {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + canonical[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", signingAlgorithm)
+	query.Set("X-Amz-Credential", s.config.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	canonicalQuery := encodeQuery(query)
+
+	canonicalRequest := strings.Join([]string{
+		method, path, canonicalQuery, canonicalHeaders.String(), signedHeaders, unsignedPayload,
+	}, "\n")
+	stringToSign := strings.Join([]string{signingAlgorithm, amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := []byte("AWS4" + s.config.SecretKey)
+	for _, part := range []string{signedAt.Format("20060102"), s.config.Region, signingService, "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return s.endpoint.Scheme + "://" + host + path + "?" + canonicalQuery + "&X-Amz-Signature=" + signature
+}
+
+// location returns the host and path addressing the object stored under key
+func (s *S3Store) location(key string) (string, string) {
+	base := strings.TrimSuffix(s.endpoint.Path, "/")
+	if s.config.PathStyle {
+		return s.endpoint.Host, base + "/" + s.config.Bucket + "/" + encodePath(key)
+	}
+	return s.config.Bucket + "." + s.endpoint.Host, base + "/" + encodePath(key)
+}
+
+// do sends a presigned request for the object stored under key
+func (s *S3Store) do(ctx context.Context, method, key string, headers map[string]string, body io.Reader) (*http.Response, error) {
+	signed := s.PresignAt(method, key, headers, time.Now(), internalRequestExpiry)
+	req, err := http.NewRequestWithContext(ctx, method, signed, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		if strings.EqualFold(name, "Content-Length") {
+			req.ContentLength, _ = strconv.ParseInt(value, 10, 64)
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	return s.client.Do(req)
+}
+
+// responseError describes a failed storage request
+func responseError(action, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	return fmt.Errorf("%s object %s: unexpected status %d: %s", action, key, resp.StatusCode, body)
+}
+
+// encodeQuery encodes query parameters sorted by name, as signatures require
+func encodeQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, encode(name, true)+"="+encode(query.Get(name), true))
+	}
+	return strings.Join(parts, "&")
+}
+
+// encodePath encodes an object key for a URL path, keeping its slashes
+func encodePath(key string) string {
+	return encode(key, false)
+}
+
+// encode percent-encodes everything but unreserved characters, and slashes
+// unless encodeSlash is set
+func encode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
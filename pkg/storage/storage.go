@@ -0,0 +1,26 @@
+// Package storage keeps files in S3-compatible object storage such as AWS S3
+// or MinIO, and lets clients upload to it directly through presigned URLs.
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned for objects that do not exist
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// PresignedRequest is a request a client sends straight to object storage.
+// The headers are part of the signature and must be sent as given.
+type PresignedRequest struct {
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
@@ -0,0 +1,49 @@
+package webhookauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// SignFunc computes the signature a provider sends for a request
+type SignFunc func(secret []byte, timestamp, nonce string, body []byte) string
+
+// Provider describes how one sender signs its webhooks
+type Provider struct {
+	// Name identifies the provider in replay keys and logs
+	Name   string
+	Secret []byte
+	// SignatureHeader, TimestampHeader and NonceHeader name the headers
+	// carrying the signature, the Unix time in seconds and a unique value
+	SignatureHeader string
+	TimestampHeader string
+	NonceHeader     string
+	Sign            SignFunc
+}
+
+// MoMo returns the provider for Mobile Money payment callbacks, which sign
+// "timestamp\nnonce\nbody" with HMAC-SHA256 and send it base64 encoded
+func MoMo(secret string) Provider {
+	return Provider{
+		Name:            "momo",
+		Secret:          []byte(secret),
+		SignatureHeader: "X-MoMo-Signature",
+		TimestampHeader: "X-MoMo-Timestamp",
+		NonceHeader:     "X-MoMo-Nonce",
+		Sign:            SignHMACBase64,
+	}
+}
+
+// SignHMACBase64 is the MoMo signature scheme
+func SignHMACBase64(secret []byte, timestamp, nonce string, body []byte) string {
+	return base64.StdEncoding.EncodeToString(mac(secret, timestamp+"\n"+nonce+"\n", body))
+}
+
+// mac computes the HMAC-SHA256 of prefix followed by body
+func mac(secret []byte, prefix string, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(prefix))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,178 @@
+// Package webhookauth authenticates payment callbacks and webhooks. Every
+// request must carry a valid signature, a timestamp within the allowed clock
+// skew and a nonce that has not been seen before, so captured requests cannot
+// be replayed. Providers that sign only the body have the body recorded
+// instead of a nonce.
+package webhookauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dongome/pkg/cache"
+	"dongome/pkg/i18n"
+	"dongome/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// MaxBodyBytes caps the size of a webhook body
+const MaxBodyBytes = 1 << 20
+
+// maxNonceLength caps the length of a nonce kept in the replay cache
+const maxNonceLength = 128
+
+// bodyReplayWindow is how long the bodies of providers sending no timestamp
+// are remembered. Paystack and Flutterwave retry failed webhooks for up to
+// three days, so nothing older can be a legitimate delivery we missed.
+const bodyReplayWindow = 72 * time.Hour
+
+var (
+	// ErrMissingHeaders is returned when the signature, timestamp or nonce is missing
+	ErrMissingHeaders = stderrors.New("webhook signature headers are missing")
+	// ErrStaleTimestamp is returned when the timestamp is outside the allowed clock skew
+	ErrStaleTimestamp = stderrors.New("webhook timestamp is outside the allowed window")
+	// ErrInvalidSignature is returned when the signature does not match the request
+	ErrInvalidSignature = stderrors.New("webhook signature is invalid")
+	// ErrReplayed is returned when the nonce was already used
+	ErrReplayed = stderrors.New("webhook was already received")
+)
+
+// Verifier checks webhook signatures and rejects replays. Nonces are kept in
+// the cache, so every API instance sees the nonces the others accepted.
+type Verifier struct {
+	replays cache.Cache
+	maxSkew time.Duration
+}
+
+// NewVerifier creates a verifier accepting timestamps up to maxSkew away
+// from the local clock
+func NewVerifier(replays cache.Cache, maxSkew time.Duration) *Verifier {
+	return &Verifier{
+		replays: replays,
+		maxSkew: maxSkew,
+	}
+}
+
+// Verify authenticates a request from provider and records its nonce. The
+// signature is checked before the nonce is recorded, so forged requests
+// cannot use up nonces.
+func (v *Verifier) Verify(ctx context.Context, provider Provider, header http.Header, body []byte) error {
+	signature := header.Get(provider.SignatureHeader)
+	timestamp := header.Get(provider.TimestampHeader)
+	nonce := header.Get(provider.NonceHeader)
+	if signature == "" || timestamp == "" || nonce == "" || len(nonce) > maxNonceLength {
+		return ErrMissingHeaders
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrStaleTimestamp
+	}
+	skew := time.Since(time.Unix(seconds, 0))
+	if skew > v.maxSkew || skew < -v.maxSkew {
+		return ErrStaleTimestamp
+	}
+
+	expected := provider.Sign(provider.Secret, timestamp, nonce, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+
+	// A timestamp stays acceptable for up to twice the skew after it first
+	// arrives, so the nonce must be remembered that long
+	fresh, err := v.replays.SetNX(ctx, replayKey(provider, nonce), timestamp, 2*v.maxSkew)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrReplayed
+	}
+	return nil
+}
+
+// Forget removes a recorded nonce, so the provider can retry a request that
+// failed after it was verified
+func (v *Verifier) Forget(ctx context.Context, provider Provider, nonce string) error {
+	return v.replays.Delete(ctx, replayKey(provider, nonce))
+}
+
+// Middleware rejects requests that Verify refuses. Requests the handler
+// fails with a server error have their nonce forgotten so the provider's
+// retry is accepted.
+func (v *Verifier) Middleware(provider Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxBodyBytes))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": i18n.Localize(c, "request body is too large")})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		if err := v.Verify(ctx, provider, c.Request.Header, body); err != nil {
+			switch {
+			case stderrors.Is(err, ErrReplayed):
+				logger.Warn("Webhook replay rejected", zap.String("provider", provider.Name), logger.HTTPRoute(c.FullPath()), logger.TraceID(ctx))
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": i18n.Localize(c, err.Error()), "code": "REPLAYED"})
+			case stderrors.Is(err, ErrMissingHeaders), stderrors.Is(err, ErrStaleTimestamp), stderrors.Is(err, ErrInvalidSignature):
+				logger.Warn("Webhook rejected", zap.String("provider", provider.Name), logger.HTTPRoute(c.FullPath()), logger.TraceID(ctx), zap.Error(err))
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": i18n.Localize(c, err.Error()), "code": "UNAUTHORIZED"})
+			default:
+				// Fail closed: without the replay cache a replay cannot be told apart
+				logger.Error("Webhook verification failed", zap.String("provider", provider.Name), logger.TraceID(ctx), zap.Error(err))
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": i18n.Localize(c, "service temporarily unavailable")})
+			}
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			if err := v.Forget(ctx, provider, c.Request.Header.Get(provider.NonceHeader)); err != nil {
+				logger.Error("Failed to forget webhook nonce", zap.String("provider", provider.Name), logger.TraceID(ctx), zap.Error(err))
+			}
+		}
+	}
+}
+
+// RecordBody records the body of a webhook from a provider that signs only
+// the body and sends no timestamp or nonce, refusing with ErrReplayed a body
+// already received. Call it once the provider has checked the signature, so
+// forged requests cannot use up bodies.
+func (v *Verifier) RecordBody(ctx context.Context, provider string, body []byte) error {
+	fresh, err := v.replays.SetNX(ctx, bodyKey(provider, body), "1", bodyReplayWindow)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrReplayed
+	}
+	return nil
+}
+
+// ForgetBody removes a recorded body, so the provider can retry a request
+// that failed after it was recorded
+func (v *Verifier) ForgetBody(ctx context.Context, provider string, body []byte) error {
+	return v.replays.Delete(ctx, bodyKey(provider, body))
+}
+
+// replayKey is the cache key recording a provider's nonce
+func replayKey(provider Provider, nonce string) string {
+	return "webhook:nonce:" + provider.Name + ":" + nonce
+}
+
+// bodyKey is the cache key recording a body a provider sent
+func bodyKey(provider string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return "webhook:body:" + provider + ":" + hex.EncodeToString(sum[:])
+}
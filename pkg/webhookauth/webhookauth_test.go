@@ -0,0 +1,178 @@
+package webhookauth_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dongome/pkg/cache"
+	"dongome/pkg/logger"
+	"dongome/pkg/webhookauth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Initialize("test"); err != nil {
+		panic(err)
+	}
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// memoryCache is an in-memory Cache for the replay tests
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{entries: make(map[string][]byte)}
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.entries[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	_, err := c.set(key, value, true)
+	return err
+}
+
+func (c *memoryCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return c.set(key, value, false)
+}
+
+func (c *memoryCache) set(key string, value interface{}, overwrite bool) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok && !overwrite {
+		return false, nil
+	}
+	c.entries[key] = data
+	return true, nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+func (c *memoryCache) Close() error {
+	return nil
+}
+
+// signedHeader signs body for provider as if sent at sentAt
+func signedHeader(provider webhookauth.Provider, sentAt time.Time, nonce string, body []byte) http.Header {
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+	header := http.Header{}
+	header.Set(provider.TimestampHeader, timestamp)
+	header.Set(provider.NonceHeader, nonce)
+	header.Set(provider.SignatureHeader, provider.Sign(provider.Secret, timestamp, nonce, body))
+	return header
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	verifier := webhookauth.NewVerifier(newMemoryCache(), 5*time.Minute)
+	provider := webhookauth.MoMo("callback-secret")
+	body := []byte(`{"externalId":"order-1","status":"SUCCESSFUL"}`)
+	ctx := context.Background()
+
+	header := signedHeader(provider, time.Now(), "nonce-1", body)
+	require.NoError(t, verifier.Verify(ctx, provider, header, body))
+	assert.ErrorIs(t, verifier.Verify(ctx, provider, header, body), webhookauth.ErrReplayed)
+
+	// The nonce is per provider
+	other := webhookauth.MoMo("callback-secret")
+	other.Name = "momo-sandbox"
+	assert.NoError(t, verifier.Verify(ctx, other, signedHeader(other, time.Now(), "nonce-1", body), body))
+
+	stale := signedHeader(provider, time.Now().Add(-6*time.Minute), "nonce-2", body)
+	assert.ErrorIs(t, verifier.Verify(ctx, provider, stale, body), webhookauth.ErrStaleTimestamp)
+	future := signedHeader(provider, time.Now().Add(6*time.Minute), "nonce-3", body)
+	assert.ErrorIs(t, verifier.Verify(ctx, provider, future, body), webhookauth.ErrStaleTimestamp)
+
+	forged := signedHeader(webhookauth.MoMo("guessed-secret"), time.Now(), "nonce-4", body)
+	assert.ErrorIs(t, verifier.Verify(ctx, provider, forged, body), webhookauth.ErrInvalidSignature)
+	tampered := signedHeader(provider, time.Now(), "nonce-5", body)
+	assert.ErrorIs(t, verifier.Verify(ctx, provider, tampered, []byte(`{"externalId":"order-2"}`)), webhookauth.ErrInvalidSignature)
+
+	// A forged request does not use up the nonce it names
+	assert.NoError(t, verifier.Verify(ctx, provider, signedHeader(provider, time.Now(), "nonce-4", body), body))
+
+	assert.ErrorIs(t, verifier.Verify(ctx, provider, http.Header{}, body), webhookauth.ErrMissingHeaders)
+}
+
+func TestVerifier_MiddlewareForgetsFailedRequests(t *testing.T) {
+	verifier := webhookauth.NewVerifier(newMemoryCache(), 5*time.Minute)
+	provider := webhookauth.MoMo("callback-secret")
+
+	failing := true
+	router := gin.New()
+	router.POST("/payments/momo/callback", verifier.Middleware(provider), func(c *gin.Context) {
+		if failing {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	body := `{"externalId":"order-1","status":"SUCCESSFUL"}`
+	header := signedHeader(provider, time.Now(), "delivery-1", []byte(body))
+	send := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/payments/momo/callback", strings.NewReader(body))
+		req.Header = header.Clone()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// The provider's retry of a request we failed is accepted, but replaying a
+	// handled request is not
+	assert.Equal(t, http.StatusInternalServerError, send())
+	failing = false
+	assert.Equal(t, http.StatusOK, send())
+	assert.Equal(t, http.StatusConflict, send())
+
+	req := httptest.NewRequest(http.MethodPost, "/payments/momo/callback", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestVerifier_RecordBody(t *testing.T) {
+	verifier := webhookauth.NewVerifier(newMemoryCache(), 5*time.Minute)
+	body := []byte(`{"event":"charge.success","data":{"reference":"order-1"}}`)
+	ctx := context.Background()
+
+	require.NoError(t, verifier.RecordBody(ctx, "paystack", body))
+	assert.ErrorIs(t, verifier.RecordBody(ctx, "paystack", body), webhookauth.ErrReplayed)
+
+	// Bodies are per provider, and a forgotten body is accepted again
+	assert.NoError(t, verifier.RecordBody(ctx, "flutterwave", body))
+	require.NoError(t, verifier.ForgetBody(ctx, "paystack", body))
+	assert.NoError(t, verifier.RecordBody(ctx, "paystack", body))
+}